@@ -3,6 +3,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	golog "github.com/ipfs/go-log"
 	apiutil "github.com/qri-io/qri/api/util"
 	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/version"
 )
@@ -63,6 +65,9 @@ func (s Server) Serve(ctx context.Context) (err error) {
 	if err := s.Instance.Connect(ctx); err != nil {
 		return err
 	}
+	if err := s.Instance.ServeEventBridge(ctx); err != nil {
+		log.Warnf("serving event bridge: %s", err)
+	}
 
 	server := &http.Server{
 		Handler: s.Mux,
@@ -142,6 +147,40 @@ func HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{ "meta": { "code": 200, "status": "ok", "version":"` + APIVersion + `" }, "data": [] }`))
 }
 
+// HealthzHandler reports the API process is alive. Liveness doesn't depend on
+// any subsystem, use /readyz to check the node can serve requests
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	apiutil.WriteResponse(w, map[string]string{"status": "ok", "version": APIVersion})
+}
+
+// ReadyzHandler reports the health of subsystems the node depends on,
+// responding with 503 Service Unavailable until all required checks pass
+func (s *Server) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	res := s.Instance.Readiness(r.Context())
+	code := http.StatusOK
+	if !res.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	data, err := json.Marshal(apiutil.Response{
+		Meta: &apiutil.Meta{Code: code},
+		Data: res,
+	})
+	if err != nil {
+		apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// EventTypesHandler lists the catalog of event types, with a JSON schema
+// describing the payload of each type. Websocket consumers can use the
+// catalog to decode events
+func EventTypesHandler(w http.ResponseWriter, r *http.Request) {
+	apiutil.WriteResponse(w, event.Catalog())
+}
+
 // refRouteParams carry a config for a ref based route
 type refRouteParams struct {
 	Endpoint lib.APIEndpoint
@@ -210,16 +249,24 @@ func NewServerRoutes(s Server) *mux.Router {
 	if m == nil {
 		m = mux.NewRouter()
 	}
-	m.Use(corsMiddleware(cfg.API.AllowedOrigins))
+	// RPCHandler checks calls against the routes mounted here
+	s.Mux = m
+	m.Use(corsMiddleware(cfg.API, s.Instance.Methods()))
+	m.Use(compressMiddleware(cfg.API))
 	m.Use(muxVarsToQueryParamMiddleware)
 	m.Use(refStringMiddleware)
 	m.Use(token.OAuthTokenMiddleware)
+	m.Use(onBehalfOfMiddleware)
 
 	var routeParams refRouteParams
 
 	m.Handle(lib.AEHome.String(), s.NoLogMiddleware(s.HomeHandler))
 	m.Handle(lib.AEHealth.String(), s.NoLogMiddleware(HealthCheckHandler))
+	m.Handle(lib.AEHealthz.String(), s.NoLogMiddleware(HealthzHandler)).Methods(http.MethodGet)
+	m.Handle(lib.AEReadyz.String(), s.NoLogMiddleware(s.ReadyzHandler)).Methods(http.MethodGet)
 	m.Handle(lib.AEIPFS.String(), s.Middleware(s.HandleIPFSPath))
+	m.Handle(lib.AERPC.String(), http.HandlerFunc(s.RPCHandler)).Methods(http.MethodPost)
+	m.Handle(lib.AEEventTypes.String(), s.NoLogMiddleware(EventTypesHandler)).Methods(http.MethodGet)
 
 	proh := NewProfileHandlers(s.Instance, cfg.API.ReadOnly)
 	m.Handle(lib.AEMe.String(), s.Middleware(proh.ProfileHandler))
@@ -227,12 +274,30 @@ func NewServerRoutes(s Server) *mux.Router {
 	m.Handle(lib.AEProfilePhoto.String(), s.Middleware(proh.ProfilePhotoHandler))
 	m.Handle(lib.AEProfilePoster.String(), s.Middleware(proh.PosterHandler))
 
-	m.Handle(lib.AEPeers.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.list"))).Methods(http.MethodPost)
+	m.Handle(lib.AEPeers.String(), s.ListMiddleware(pageListSchema, lib.NewHTTPRequestHandler(s.Instance, "peer.list"))).Methods(http.MethodPost)
 	m.Handle(lib.AEPeer.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.info"))).Methods(http.MethodPost)
 	m.Handle(lib.AEConnect.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.connect"))).Methods(http.MethodPost)
 	m.Handle(lib.AEDisconnect.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.disconnect"))).Methods(http.MethodPost)
 	m.Handle(lib.AEConnections.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.connections"))).Methods(http.MethodPost)
 	m.Handle(lib.AEConnectedQriProfiles.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.connectedqriprofiles"))).Methods(http.MethodPost)
+	m.Handle(lib.AEPeersTop.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.top"))).Methods(http.MethodPost)
+	m.Handle(lib.AEPeersBandwidth.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.bandwidth"))).Methods(http.MethodPost)
+	m.Handle(lib.AEPeersSync.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.sync"))).Methods(http.MethodPost)
+	m.Handle(lib.AEPeersLocal.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.local"))).Methods(http.MethodPost)
+	m.Handle(lib.AEPeersTrust.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "peer.trust"))).Methods(http.MethodPost)
+
+	m.Handle(lib.AEAdminStatus.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.status"))).Methods(http.MethodPost)
+	m.Handle(lib.AEAdminReloadConfig.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.reloadconfig"))).Methods(http.MethodPost)
+	m.Handle(lib.AEAdminGC.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.gc"))).Methods(http.MethodPost)
+	m.Handle(lib.AEAdminConnections.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.connections"))).Methods(http.MethodPost)
+	m.Handle(lib.AEAdminConnect.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.connect"))).Methods(http.MethodPost)
+	m.Handle(lib.AEAdminDisconnect.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.disconnect"))).Methods(http.MethodPost)
+	m.Handle(lib.AEAdminLogLevel.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.setloglevel"))).Methods(http.MethodPost)
+	m.Handle(lib.AEAdminVerifyRepo.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.verifyrepo"))).Methods(http.MethodPost)
+	m.Handle(lib.AEAdminRepairRefs.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.repairrefs"))).Methods(http.MethodPost)
+	m.Handle(lib.AEAdminDoctor.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.doctor"))).Methods(http.MethodPost)
+	m.Handle(lib.AEAdminAudit.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.audit"))).Methods(http.MethodPost)
+	m.Handle(lib.AEMetrics.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "log.metrics"))).Methods(http.MethodGet, http.MethodPost)
 
 	if cfg.Remote != nil && cfg.Remote.Enabled {
 		log.Info("running in `remote` mode")
@@ -241,21 +306,38 @@ func NewServerRoutes(s Server) *mux.Router {
 		m.Handle(lib.AERemoteDSync.String(), s.Middleware(remh.DsyncHandler))
 		m.Handle(lib.AERemoteLogSync.String(), s.Middleware(remh.LogsyncHandler))
 		m.Handle(lib.AERemoteRefs.String(), s.Middleware(remh.RefsHandler))
+		m.Handle(lib.AERemoteHosted.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.hosted"))).Methods(http.MethodPost)
+		m.Handle(lib.AERemoteHostingStatus.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.hostingstatus"))).Methods(http.MethodPost)
+		m.Handle(lib.AERemoteStorage.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.hostingstorage"))).Methods(http.MethodPost)
+		m.Handle(lib.AERemoteDrop.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "admin.drophosted"))).Methods(http.MethodPost)
 	}
 
 	dsh := NewDatasetHandlers(s.Instance, cfg.API.ReadOnly)
-	m.Handle(lib.AEList.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.list"))).Methods(http.MethodPost, http.MethodGet)
+	m.Handle(lib.AEList.String(), s.ListMiddleware(datasetListSchema, lib.NewHTTPRequestHandler(s.Instance, "dataset.list"))).Methods(http.MethodPost, http.MethodGet)
 	m.Handle(lib.AEListRaw.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.listrawrefs"))).Methods(http.MethodPost, http.MethodGet)
-	m.Handle(lib.AEPeerList.String(), s.Middleware(dsh.PeerListHandler(lib.AEPeerList.String())))
+	m.Handle(lib.AEPeerList.String(), s.ListMiddleware(pageListSchema, dsh.PeerListHandler(lib.AEPeerList.String())))
 	routeParams = newrefRouteParams(lib.AESave, false, false, http.MethodPost, http.MethodPut)
 	handleRefRoute(m, routeParams, s.Middleware(dsh.SaveHandler(lib.AESave.String())))
 	routeParams = newrefRouteParams(lib.AEGet, false, true, http.MethodGet, http.MethodPost)
 	handleRefRoute(m, routeParams, s.Middleware(dsh.GetHandler(lib.AEGet.String())))
+	routeParams = newrefRouteParams(lib.AEEmbed, false, true, http.MethodGet)
+	handleRefRoute(m, routeParams, s.Middleware(s.embedTokenMiddleware(dsh.GetHandler(lib.AEEmbed.String()))))
 	routeParams = newrefRouteParams(lib.AERemove, false, false, http.MethodPost, http.MethodDelete)
 	handleRefRoute(m, routeParams, s.Middleware(dsh.RemoveHandler(lib.AERemove.String())))
+	m.Handle(lib.AERemoveMany.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.removemany"))).Methods(http.MethodPost)
+	m.Handle(lib.AETrash.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.trash"))).Methods(http.MethodPost)
+	m.Handle(lib.AETrashRestore.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.restorefromtrash"))).Methods(http.MethodPost)
+	m.Handle(lib.AETrashEmpty.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.emptytrash"))).Methods(http.MethodPost)
+	m.Handle(lib.AESquash.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.squash"))).Methods(http.MethodPost)
+	m.Handle(lib.AESyncStatus.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.syncstatus"))).Methods(http.MethodPost)
+	m.Handle(lib.AEPin.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.pin"))).Methods(http.MethodPost)
+	m.Handle(lib.AEPinStatus.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.pinstatus"))).Methods(http.MethodPost)
 	m.Handle(lib.AERename.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.rename"))).Methods(http.MethodPost, http.MethodPut)
 	routeParams = newrefRouteParams(lib.AEValidate, false, false, http.MethodGet, http.MethodPost)
 	handleRefRoute(m, routeParams, s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.validate")))
+	m.Handle(lib.AELint.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.lint"))).Methods(http.MethodPost)
+	m.Handle(lib.AEDatasetSettings.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "config.datasetsettings"))).Methods(http.MethodPost)
+	m.Handle(lib.AESetDatasetSetting.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "config.setdatasetsetting"))).Methods(http.MethodPost)
 	m.Handle(lib.AEDiff.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.diff"))).Methods(http.MethodPost, http.MethodGet)
 	m.Handle(lib.AEChanges.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.changereport"))).Methods(http.MethodPost, http.MethodGet)
 	m.Handle(lib.AEUnpack.String(), s.Middleware(dsh.UnpackHandler(lib.AEUnpack.NoTrailingSlash())))
@@ -264,18 +346,35 @@ func NewServerRoutes(s Server) *mux.Router {
 	m.Handle(lib.AEManifestMissing.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.manifestmissing"))).Methods(http.MethodPost)
 	routeParams = newrefRouteParams(lib.AEDAGInfo, false, false, http.MethodPost)
 	handleRefRoute(m, routeParams, s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.daginfo")))
+	m.Handle(lib.AEPatchMeta.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.patchmeta"))).Methods(http.MethodPost)
+	m.Handle(lib.AELifecycle.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.lifecycle"))).Methods(http.MethodPost)
+	m.Handle(lib.AEStatsHistory.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.statshistory"))).Methods(http.MethodPost)
 
 	remClientH := NewRemoteClientHandlers(s.Instance, cfg.API.ReadOnly)
 	routeParams = newrefRouteParams(lib.AEPush, false, false, http.MethodGet, http.MethodPost, http.MethodDelete)
 	handleRefRoute(m, routeParams, s.Middleware(remClientH.PushHandler))
+	m.Handle(lib.AEPublish.String(), s.Middleware(remClientH.PublishHandler)).Methods(http.MethodPost)
+	m.Handle(lib.AEPromote.String(), s.Middleware(remClientH.PromoteHandler)).Methods(http.MethodPost)
+	m.Handle(lib.AEPushQueue.String(), s.Middleware(remClientH.PushQueueHandler)).Methods(http.MethodGet, http.MethodPost)
+	m.Handle(lib.AEPushReceipts.String(), s.Middleware(remClientH.PushReceiptsHandler)).Methods(http.MethodGet, http.MethodPost)
+	m.Handle(lib.AEPushHooks.String(), s.Middleware(remClientH.PushHooksHandler)).Methods(http.MethodGet, http.MethodPost)
 	routeParams = newrefRouteParams(lib.AEPull, false, false, http.MethodPost, http.MethodPut)
 	handleRefRoute(m, routeParams, s.Middleware(dsh.PullHandler(lib.AEPull.NoTrailingSlash())))
+	m.Handle(lib.AEClone.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.clone"))).Methods(http.MethodPost)
+	m.Handle(lib.AECopy.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.copy"))).Methods(http.MethodPost)
+	m.Handle(lib.AEFollow.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "dataset.follow"))).Methods(http.MethodPost)
 	m.Handle(lib.AEFeeds.String(), s.Middleware(remClientH.FeedsHandler))
+	m.Handle(lib.AERemoteUsage.String(), s.Middleware(remClientH.UsageHandler)).Methods(http.MethodGet, http.MethodPost)
 	routeParams = newrefRouteParams(lib.AEPreview, false, false, http.MethodGet, http.MethodPost)
 	handleRefRoute(m, routeParams, s.Middleware(remClientH.DatasetPreviewHandler))
 
+	feedh := NewFeedHandlers(s.Instance)
+	m.Handle(lib.AEDatasetFeed.String(), s.Middleware(feedh.DatasetFeedHandler)).Methods(http.MethodGet, http.MethodHead)
+	m.Handle(lib.AEProfileFeed.String(), s.Middleware(feedh.ProfileFeedHandler)).Methods(http.MethodGet, http.MethodHead)
+
 	routeParams = newrefRouteParams(lib.AEStatus, false, false, http.MethodGet, http.MethodPost)
 	handleRefRoute(m, routeParams, s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "fsi.status")))
+	m.Handle(lib.AEStatusAll.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "fsi.statusall"))).Methods(http.MethodPost)
 	routeParams = newrefRouteParams(lib.AEWhatChanged, false, false, http.MethodGet, http.MethodPost)
 	handleRefRoute(m, routeParams, s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "fsi.whatchanged")))
 	routeParams = newrefRouteParams(lib.AEInit, true, false, http.MethodPost)
@@ -286,13 +385,17 @@ func NewServerRoutes(s Server) *mux.Router {
 	m.Handle(lib.AEFSIWrite.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "fsi.write"))).Methods(http.MethodPost)
 	m.Handle(lib.AEFSICreateLink.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "fsi.createlink"))).Methods(http.MethodPost)
 	m.Handle(lib.AEFSIUnlink.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "fsi.unlink"))).Methods(http.MethodPost)
+	m.Handle(lib.AEGitExport.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "fsi.gitexport"))).Methods(http.MethodPost)
+	m.Handle(lib.AEGitImport.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "fsi.gitimport"))).Methods(http.MethodPost)
 
 	renderh := NewRenderHandlers(s.Instance)
 	routeParams = newrefRouteParams(lib.AERender, false, false, http.MethodGet, http.MethodPost)
 	handleRefRoute(m, routeParams, s.Middleware(renderh.RenderHandler))
 
-	m.Handle(lib.AEHistory.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "log.history"))).Methods(http.MethodPost)
-	m.Handle(lib.AEEntries.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "log.entries"))).Methods(http.MethodPost)
+	m.Handle(lib.AEHistory.String(), s.ListMiddleware(pageListSchema, lib.NewHTTPRequestHandler(s.Instance, "log.history"))).Methods(http.MethodPost)
+	m.Handle(lib.AEEntries.String(), s.ListMiddleware(pageListSchema, lib.NewHTTPRequestHandler(s.Instance, "log.entries"))).Methods(http.MethodPost)
+	m.Handle(lib.AELogGraph.String(), s.ListMiddleware(pageListSchema, lib.NewHTTPRequestHandler(s.Instance, "log.graph"))).Methods(http.MethodPost)
+	m.Handle(lib.AELogComponents.String(), s.ListMiddleware(pageListSchema, lib.NewHTTPRequestHandler(s.Instance, "log.components"))).Methods(http.MethodPost)
 	m.Handle(lib.AERawLogbook.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "log.rawlogbook"))).Methods(http.MethodPost)
 	m.Handle(lib.AELogbookSummary.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "log.logbooksummary"))).Methods(http.MethodPost)
 
@@ -300,13 +403,32 @@ func NewServerRoutes(s Server) *mux.Router {
 	m.Handle(lib.AERegistryNew.String(), s.Middleware(rch.CreateProfileHandler))
 	m.Handle(lib.AERegistryProve.String(), s.Middleware(rch.ProveProfileKeyHandler))
 
-	m.Handle(lib.AESearch.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "search.search"))).Methods(http.MethodPost)
+	m.Handle(lib.AESearch.String(), s.ListMiddleware(pageListSchema, lib.NewHTTPRequestHandler(s.Instance, "search.search"))).Methods(http.MethodPost)
+	m.Handle(lib.AESearchIndexContent.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "search.indexcontent"))).Methods(http.MethodPost)
 
 	sqlh := NewSQLHandlers(s.Instance, cfg.API.ReadOnly)
 	m.Handle(lib.AESQL.String(), s.Middleware(sqlh.QueryHandler))
+	m.Handle(lib.AESQLExplain.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "sql.explain"))).Methods(http.MethodPost)
+
+	oah := NewOAuthHandlers(s.Instance)
+	m.Handle(lib.AEOAuthDeviceCode.String(), s.Middleware(oah.DeviceCodeHandler)).Methods(http.MethodPost)
+	m.Handle(lib.AEOAuthDeviceApprove.String(), s.AdminMiddleware(lib.NewHTTPRequestHandler(s.Instance, "access.approvedevice"))).Methods(http.MethodPost)
+	m.Handle(lib.AEOAuthToken.String(), s.Middleware(oah.TokenHandler)).Methods(http.MethodPost)
+	m.Handle(lib.AESessions.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "access.sessions"))).Methods(http.MethodPost)
+	m.Handle(lib.AETerminateSession.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "access.terminatesession"))).Methods(http.MethodPost)
+	m.Handle(lib.AECreateEmbedToken.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "access.createembedtoken"))).Methods(http.MethodPost)
+	m.Handle(lib.AEEmbedTokens.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "access.embedtokens"))).Methods(http.MethodPost)
+	m.Handle(lib.AERevokeEmbedToken.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "access.revokeembedtoken"))).Methods(http.MethodPost)
+	m.Handle(lib.AEProtection.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "access.protection"))).Methods(http.MethodPost)
+	m.Handle(lib.AEProtect.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "access.protect"))).Methods(http.MethodPost)
 
 	tfh := NewTransformHandlers(s.Instance)
 	m.Handle(lib.AEApply.String(), s.Middleware(tfh.ApplyHandler(lib.AEApply.NoTrailingSlash())))
+	m.Handle(lib.AEReproduce.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "transform.reproduce"))).Methods(http.MethodPost)
+	m.Handle(lib.AERunLog.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "automation.run"))).Methods(http.MethodGet)
+	m.Handle(lib.AEFreshness.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "automation.freshness"))).Methods(http.MethodPost)
+	m.Handle(lib.AEWorkflows.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "automation.workflows"))).Methods(http.MethodPost)
+	m.Handle(lib.AEResume.String(), s.Middleware(lib.NewHTTPRequestHandler(s.Instance, "automation.resume"))).Methods(http.MethodPost)
 
 	if !cfg.API.DisableWebui {
 		m.Handle(lib.AEWebUI.String(), s.Middleware(WebuiHandler))
@@ -247,6 +247,8 @@ func TestServerReadOnlyRoutes(t *testing.T) {
 
 		// active endpoints:
 		{"GET", "/health", 200},
+		{"GET", "/healthz", 200},
+		{"GET", "/events/types", 200},
 		{"GET", "/list/peer", 200},
 		// Cannot test connect endpoint until we have peers in this test suite
 		// {"GET", "/connect/QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt", 200},
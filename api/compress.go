@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/qri/config"
+)
+
+// incompressibleTypes are content types that are already compressed. Types
+// ending in "/" match any subtype
+var incompressibleTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"font/woff2",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-bzip2",
+	"application/x-xz",
+	"application/zstd",
+}
+
+// compressMiddleware gzips responses to requests that accept gzip encoding.
+// Output is compressed as it's written, so streamed bodies are never buffered
+// in full. A response is only compressed once MinSize bytes are written or the
+// handler flushes, smaller responses are sent as-is. Brotli isn't offered, so
+// requests that only accept "br" get uncompressed responses
+func compressMiddleware(cfg *config.API) func(http.Handler) http.Handler {
+	var c *config.Compression
+	if cfg != nil {
+		c = cfg.Compression
+	}
+	if c == nil {
+		c = config.DefaultCompression()
+	}
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	minSize := c.MinSize
+	if minSize <= 0 {
+		minSize = config.DefaultCompressionMinSize
+	}
+	skip := append(append([]string{}, incompressibleTypes...), c.SkipTypes...)
+	writers := &sync.Pool{New: func() interface{} {
+		gz, _ := gzip.NewWriterLevel(nil, level)
+		return gz
+	}}
+
+	return func(next http.Handler) http.Handler {
+		if c.Disabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				minSize:        minSize,
+				skip:           skip,
+				writers:        writers,
+				status:         http.StatusOK,
+			}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// acceptsGzip checks an Accept-Encoding header for gzip with a non-zero
+// quality value
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		accepted := true
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if q, err := strconv.ParseFloat(p[2:], 64); err == nil && q == 0 {
+					accepted = false
+				}
+			}
+		}
+		return accepted
+	}
+	return false
+}
+
+// compressWriter holds the first bytes of a response until it can decide
+// whether to compress, then either gzips or passes through everything written
+type compressWriter struct {
+	http.ResponseWriter
+	minSize int
+	skip    []string
+	writers *sync.Pool
+
+	status  int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer
+}
+
+var (
+	_ http.Flusher  = (*compressWriter)(nil)
+	_ http.Hijacker = (*compressWriter)(nil)
+)
+
+// WriteHeader records the status code, headers are sent once the response is
+// known to be compressed or not
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.decided {
+		return
+	}
+	cw.status = status
+	// responses that can't have a body, or declare a small one, are sent as-is
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified || !cw.compressible() {
+		cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) < cw.minSize {
+			return len(p), nil
+		}
+		if err := cw.start(cw.compressible()); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if cw.gz != nil {
+		return cw.gz.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush sends buffered output. Handlers flush when streaming, so an undecided
+// response is compressed regardless of size
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if err := cw.start(cw.compressible()); err != nil {
+			log.Debugw("flushing compressed response", "err", err)
+			return
+		}
+	}
+	if cw.gz != nil {
+		if err := cw.gz.Flush(); err != nil {
+			log.Debugw("flushing compressed response", "err", err)
+			return
+		}
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack passes connection hijacking to the underlying writer
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := cw.ResponseWriter.(http.Hijacker); ok {
+		cw.decided = true
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("response writer doesn't support hijacking")
+}
+
+// Close finishes the response, sending any held output
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		// the whole response is smaller than minSize
+		return cw.start(false)
+	}
+	if cw.gz != nil {
+		err := cw.gz.Close()
+		cw.gz.Reset(nil)
+		cw.writers.Put(cw.gz)
+		cw.gz = nil
+		return err
+	}
+	return nil
+}
+
+// compressible checks response headers set so far for a reason not to
+// compress
+func (cw *compressWriter) compressible() bool {
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	if cl := h.Get("Content-Length"); cl != "" {
+		if n, err := strconv.Atoi(cl); err == nil && n < cw.minSize {
+			return false
+		}
+	}
+	ct := h.Get("Content-Type")
+	if ct == "" {
+		return true
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return true
+	}
+	for _, t := range cw.skip {
+		if mt == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mt, t)) {
+			// svg images are text
+			return mt == "image/svg+xml"
+		}
+	}
+	return true
+}
+
+// start sends headers, then any held output, choosing to compress or not
+func (cw *compressWriter) start(compress bool) error {
+	cw.decided = true
+	if compress {
+		h := cw.Header()
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		cw.gz = cw.writers.Get().(*gzip.Writer)
+		cw.gz.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	buf := cw.buf
+	cw.buf = nil
+	var err error
+	if cw.gz != nil {
+		_, err = cw.gz.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
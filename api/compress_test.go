@@ -0,0 +1,96 @@
+package api
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+)
+
+func TestCompressMiddleware(t *testing.T) {
+	cfg := &config.API{
+		Compression: &config.Compression{MinSize: 100, SkipTypes: []string{"text/csv"}},
+	}
+	large := strings.Repeat("qri ", 100)
+
+	cases := []struct {
+		description    string
+		acceptEncoding string
+		contentType    string
+		body           string
+		flush          bool
+		gzipped        bool
+	}{
+		{"large json", "gzip, deflate", "application/json", large, false, true},
+		{"no accept-encoding", "", "application/json", large, false, false},
+		{"gzip refused", "gzip;q=0, br", "application/json", large, false, false},
+		{"brotli only", "br", "application/json", large, false, false},
+		{"small body", "gzip", "application/json", "{}", false, false},
+		{"already compressed", "gzip", "application/zip", large, false, false},
+		{"svg image", "gzip", "image/svg+xml", large, false, true},
+		{"png image", "gzip", "image/png", large, false, false},
+		{"configured skip type", "gzip", "text/csv; charset=utf-8", large, false, false},
+		{"flushed stream", "gzip", "application/json", "[1,", true, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			h := compressMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", c.contentType)
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(c.body))
+				if c.flush {
+					w.(http.Flusher).Flush()
+					w.Write([]byte("2]"))
+				}
+			}))
+
+			r := httptest.NewRequest("GET", "/", nil)
+			if c.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", c.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusCreated {
+				t.Errorf("expected status to pass through, got %d", w.Code)
+			}
+			expect := c.body
+			if c.flush {
+				expect += "2]"
+			}
+			got := w.Body.String()
+			if gzipped := w.Header().Get("Content-Encoding") == "gzip"; gzipped != c.gzipped {
+				t.Fatalf("expected gzipped=%t, got Content-Encoding %q", c.gzipped, w.Header().Get("Content-Encoding"))
+			} else if gzipped {
+				gz, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, err := ioutil.ReadAll(gz)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = string(data)
+			}
+			if got != expect {
+				t.Errorf("body mismatch. want: %q got: %q", expect, got)
+			}
+		})
+	}
+
+	disabled := compressMiddleware(&config.API{Compression: &config.Compression{Disabled: true}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(large + large + large))
+	}))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	disabled.ServeHTTP(w, r)
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Error("expected disabled compression to leave responses uncompressed")
+	}
+}
@@ -262,6 +262,10 @@ func extensionToMimeType(ext string) string {
 		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 	case ".zip":
 		return "application/zip"
+	case ".sql":
+		return "application/sql"
+	case ".parquet":
+		return "application/vnd.apache.parquet"
 	case ".txt":
 		return "text/plain"
 	default:
@@ -303,6 +307,10 @@ func (h *DatasetHandlers) replyWithGetResponse(w http.ResponseWriter, r *http.Re
 	if resultFormat == "" {
 		resultFormat = result.Dataset.Structure.Format
 	}
+	// jsonl exports are bundled into a zip archive of body partitions
+	if resultFormat == "jsonl" {
+		resultFormat = "zip"
+	}
 
 	if resultFormat == "json" {
 		// Convert components with scriptPaths (transform, readme, viz) in scriptBytes
@@ -343,7 +351,10 @@ func (h *DatasetHandlers) replyWithGetResponse(w http.ResponseWriter, r *http.Re
 			return
 		}
 		w.Header().Set("Content-Type", extensionToMimeType("."+resultFormat))
-		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+		// yaml responses are displayed, other formats are downloads
+		if resultFormat != "yaml" {
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+		}
 		w.Write(result.Bytes)
 	}
 }
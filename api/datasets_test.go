@@ -363,19 +363,76 @@ func TestParseGetParamsAcceptHeader(t *testing.T) {
 		t.Errorf("output mismatch (-want +got):\n%s", diff)
 	}
 
-	// Construct a request with format=json and "Accept: text/csv", which is an error
+	// Construct a request with format=json and "Accept: text/csv", the format
+	// param overrides the header
 	r, _ = http.NewRequest("GET", "/get/peer/my_ds?format=json", nil)
 	r.Header.Add("Accept", "text/csv")
 	r = mux.SetURLVars(r, map[string]string{"peername": "peer", "name": "my_ds"})
 	setRefStringFromMuxVars(r)
 	args = &lib.GetParams{}
-	err = lib.UnmarshalParams(r, args)
-	if err == nil {
-		t.Error("expected to get an error, but did not get one")
+	if err = lib.UnmarshalParams(r, args); err != nil {
+		t.Fatal(err)
+	}
+	expectArgs = &lib.GetParams{
+		Refstr: "peer/my_ds",
+		Format: "json",
+		All:    true,
+	}
+	if diff := cmp.Diff(expectArgs, args); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
 	}
-	expectErr := `format "json" conflicts with header "Accept: text/csv"`
-	if expectErr != err.Error() {
-		t.Errorf("error mismatch, expect: %q, got %q", expectErr, err)
+
+	cases := []struct {
+		description string
+		accept      string
+		muxVars     map[string]string
+		expectArgs  *lib.GetParams
+	}{
+		{
+			"yaml",
+			"application/x-yaml",
+			map[string]string{"peername": "peer", "name": "my_ds"},
+			&lib.GetParams{Refstr: "peer/my_ds", Format: "yaml", All: true},
+		},
+		{
+			"parquet selects the body",
+			"application/vnd.apache.parquet",
+			map[string]string{"peername": "peer", "name": "my_ds"},
+			&lib.GetParams{Refstr: "peer/my_ds", Selector: "body", Format: "parquet", All: true},
+		},
+		{
+			"quality values",
+			"text/csv;q=0.5, application/json;q=0.9, */*;q=0.1",
+			map[string]string{"peername": "peer", "name": "my_ds"},
+			&lib.GetParams{Refstr: "peer/my_ds", Format: "json", All: true},
+		},
+		{
+			"body formats skipped for components",
+			"text/csv, application/x-yaml;q=0.5",
+			map[string]string{"peername": "peer", "name": "my_ds", "selector": "meta"},
+			&lib.GetParams{Refstr: "peer/my_ds", Selector: "meta", Format: "yaml", All: true},
+		},
+		{
+			"unsupported types use the default",
+			"text/html",
+			map[string]string{"peername": "peer", "name": "my_ds"},
+			&lib.GetParams{Refstr: "peer/my_ds", Format: "json", All: true},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			r, _ := http.NewRequest("GET", "/get/peer/my_ds", nil)
+			r.Header.Add("Accept", c.accept)
+			r = mux.SetURLVars(r, c.muxVars)
+			setRefStringFromMuxVars(r)
+			args := &lib.GetParams{}
+			if err := lib.UnmarshalParams(r, args); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.expectArgs, args); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+		})
 	}
 }
 
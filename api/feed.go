@@ -0,0 +1,239 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/lib"
+)
+
+var (
+	// FeedCacheTTL is how long a generated feed is served before it's rebuilt.
+	// Cached feeds are also dropped whenever dataset history changes
+	FeedCacheTTL = time.Minute * 5
+	// FeedEntries caps the number of versions listed in a feed
+	FeedEntries = 50
+	// FeedDatasets caps the number of datasets included in a profile feed
+	FeedDatasets = 100
+)
+
+// FeedHandlers serves Atom feeds of dataset versions, built from logbook
+// history
+type FeedHandlers struct {
+	inst *lib.Instance
+
+	lk    sync.Mutex
+	cache map[string]cachedFeed
+}
+
+type cachedFeed struct {
+	data    []byte
+	updated time.Time
+	expires time.Time
+}
+
+// NewFeedHandlers allocates a FeedHandlers pointer
+func NewFeedHandlers(inst *lib.Instance) *FeedHandlers {
+	h := &FeedHandlers{
+		inst:  inst,
+		cache: map[string]cachedFeed{},
+	}
+	inst.Bus().SubscribeTypes(h.invalidate, event.ETDatasetCommitChange, event.ETDatasetDeleteAll, event.ETDatasetRename)
+	return h
+}
+
+func (h *FeedHandlers) invalidate(_ context.Context, _ event.Event) error {
+	h.lk.Lock()
+	h.cache = map[string]cachedFeed{}
+	h.lk.Unlock()
+	return nil
+}
+
+// DatasetFeedHandler serves an Atom feed of versions of a single dataset
+func (h *FeedHandlers) DatasetFeedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		util.NotFoundHandler(w, r)
+		return
+	}
+	vars := mux.Vars(r)
+	ref := dsref.Ref{Username: vars["peername"], Name: vars["name"]}
+	h.serve(w, r, func(ctx context.Context, base string) (*atomFeed, error) {
+		items, err := h.history(ctx, ref.Alias(), FeedEntries)
+		if err != nil {
+			return nil, err
+		}
+		return newAtomFeed(base, ref.Alias(), base+r.URL.Path, items), nil
+	})
+}
+
+// ProfileFeedHandler serves an Atom feed of versions of all datasets owned by
+// a profile
+func (h *FeedHandlers) ProfileFeedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		util.NotFoundHandler(w, r)
+		return
+	}
+	peername := mux.Vars(r)["peername"]
+	h.serve(w, r, func(ctx context.Context, base string) (*atomFeed, error) {
+		refs, err := h.inst.Dataset().List(ctx, &lib.ListParams{Peername: peername, Limit: FeedDatasets})
+		if err != nil {
+			return nil, err
+		}
+		items := []dsref.VersionInfo{}
+		for _, vi := range refs {
+			if vi.Username != peername {
+				continue
+			}
+			hist, err := h.history(ctx, vi.Alias(), FeedEntries)
+			if err != nil {
+				log.Debugw("profile feed history", "ref", vi.Alias(), "err", err)
+				continue
+			}
+			items = append(items, hist...)
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].CommitTime.After(items[j].CommitTime)
+		})
+		if len(items) > FeedEntries {
+			items = items[:FeedEntries]
+		}
+		return newAtomFeed(base, peername, base+r.URL.Path, items), nil
+	})
+}
+
+func (h *FeedHandlers) history(ctx context.Context, refstr string, limit int) ([]dsref.VersionInfo, error) {
+	p := &lib.HistoryParams{Ref: refstr}
+	p.Limit = limit
+	return h.inst.Log().History(ctx, p)
+}
+
+// serve writes a feed, building it with build if there's no cached copy
+func (h *FeedHandlers) serve(w http.ResponseWriter, r *http.Request, build func(ctx context.Context, base string) (*atomFeed, error)) {
+	base := requestBaseURL(r)
+	key := base + r.URL.Path
+
+	h.lk.Lock()
+	cached, ok := h.cache[key]
+	h.lk.Unlock()
+
+	if !ok || time.Now().After(cached.expires) {
+		feed, err := build(r.Context(), base)
+		if err != nil {
+			util.RespondWithError(w, err)
+			return
+		}
+		buf := &bytes.Buffer{}
+		buf.WriteString(xml.Header)
+		enc := xml.NewEncoder(buf)
+		enc.Indent("", "  ")
+		if err := enc.Encode(feed); err != nil {
+			util.WriteErrResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+		cached = cachedFeed{
+			data:    buf.Bytes(),
+			updated: feed.updated,
+			expires: time.Now().Add(FeedCacheTTL),
+		}
+		h.lk.Lock()
+		h.cache[key] = cached
+		h.lk.Unlock()
+	}
+
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !cached.updated.Truncate(time.Second).After(since) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("Last-Modified", cached.updated.UTC().Format(http.TimeFormat))
+	w.Write(cached.data)
+}
+
+// requestBaseURL reconstructs the scheme & host a request was made to
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+
+	updated time.Time
+}
+
+type atomEntry struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Author  atomAuthor `xml:"author"`
+	Summary string     `xml:"summary,omitempty"`
+	Links   []atomLink `xml:"link"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+// newAtomFeed builds a feed from version history. Each entry links to a
+// preview of the version & includes the version body as a CSV enclosure
+func newAtomFeed(base, title, self string, items []dsref.VersionInfo) *atomFeed {
+	f := &atomFeed{
+		ID:    self,
+		Title: title,
+		Links: []atomLink{{Rel: "self", Type: "application/atom+xml", Href: self}},
+	}
+	for _, vi := range items {
+		if vi.CommitTime.After(f.updated) {
+			f.updated = vi.CommitTime
+		}
+		at := fmt.Sprintf("%s/at%s", vi.Alias(), vi.Path)
+		entryTitle := vi.CommitTitle
+		if entryTitle == "" {
+			entryTitle = fmt.Sprintf("new version of %s", vi.Alias())
+		}
+		f.Entries = append(f.Entries, atomEntry{
+			ID:      base + lib.AEGet.String() + "/" + at,
+			Title:   entryTitle,
+			Updated: vi.CommitTime.UTC().Format(time.RFC3339),
+			Author:  atomAuthor{Name: vi.Username},
+			Summary: vi.CommitMessage,
+			Links: []atomLink{
+				{Rel: "alternate", Type: "application/json", Href: base + lib.AEPreview.String() + "/" + at},
+				{Rel: "enclosure", Type: "text/csv", Href: base + lib.AEGet.String() + "/" + at + "/body.csv", Length: int64(vi.BodySize)},
+			},
+		})
+	}
+	if f.updated.IsZero() {
+		f.updated = time.Unix(0, 0)
+	}
+	f.Updated = f.updated.UTC().Format(time.RFC3339)
+	return f
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	testcfg "github.com/qri-io/qri/config/test"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestFeedHandlers(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+	// feeds are invalidated by dataset events, use an instance with a bus wired
+	// to the repo
+	cfg := testcfg.DefaultMemConfigForTesting()
+	cfg.Profile = test.ProfileConfig()
+	ctx := context.WithValue(run.Ctx, lib.InstanceContextKey("RemoteClient"), "mock")
+	inst, err := lib.NewInstance(ctx, run.TmpDir, lib.OptConfig(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	run.Inst = inst
+
+	run.SaveDataset(&dataset.Dataset{Name: "cities"}, "testdata/cities/data.csv")
+	run.SaveDataset(&dataset.Dataset{Name: "cities", Commit: &dataset.Commit{Title: "update cities"}}, "testdata/cities/data_update.csv")
+	run.SaveDataset(&dataset.Dataset{Name: "movies"}, "testdata/cities/data.csv")
+
+	h := NewFeedHandlers(run.Inst)
+
+	status, body := APICall("/feed/ds/peer/cities", h.DatasetFeedHandler, map[string]string{"peername": "peer", "name": "cities"})
+	assertStatusCode(t, "dataset feed", status, 200)
+	feed := atomFeed{}
+	if err := xml.Unmarshal([]byte(body), &feed); err != nil {
+		t.Fatalf("parsing feed: %s\n%s", err, body)
+	}
+	if feed.Title != "peer/cities" {
+		t.Errorf("expected feed title %q, got %q", "peer/cities", feed.Title)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(feed.Entries))
+	}
+	latest := feed.Entries[0]
+	if latest.Title != "update cities" {
+		t.Errorf("expected entry title from commit, got %q", latest.Title)
+	}
+	if len(latest.Links) != 2 || latest.Links[1].Rel != "enclosure" || !strings.HasSuffix(latest.Links[1].Href, "/body.csv") {
+		t.Errorf("expected body enclosure link, got: %#v", latest.Links)
+	}
+
+	status, body = APICall("/feed/profile/peer", h.ProfileFeedHandler, map[string]string{"peername": "peer"})
+	assertStatusCode(t, "profile feed", status, 200)
+	feed = atomFeed{}
+	if err := xml.Unmarshal([]byte(body), &feed); err != nil {
+		t.Fatalf("parsing feed: %s\n%s", err, body)
+	}
+	if len(feed.Entries) != 3 {
+		t.Errorf("expected 3 entries, got %d", len(feed.Entries))
+	}
+	if !strings.Contains(feed.Entries[0].ID, "peer/movies") {
+		t.Errorf("expected newest version first, got %q", feed.Entries[0].ID)
+	}
+
+	// feeds are cached until dataset history changes
+	h.lk.Lock()
+	cached := len(h.cache)
+	h.lk.Unlock()
+	if cached != 2 {
+		t.Errorf("expected 2 cached feeds, got %d", cached)
+	}
+	run.SaveDataset(&dataset.Dataset{Name: "cities", Commit: &dataset.Commit{Title: "revert cities"}}, "testdata/cities/data.csv")
+	status, body = APICall("/feed/ds/peer/cities", h.DatasetFeedHandler, map[string]string{"peername": "peer", "name": "cities"})
+	assertStatusCode(t, "dataset feed after save", status, 200)
+	if !strings.Contains(body, "revert cities") {
+		t.Errorf("expected feed to include new version:\n%s", body)
+	}
+}
@@ -1,13 +1,18 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/lib"
 )
 
 // Middleware handles request logging
@@ -34,17 +39,172 @@ func (s Server) mwFunc(handler http.HandlerFunc, shouldLog bool) http.HandlerFun
 	}
 }
 
+// ListMiddleware parses the pagination, sorting & filtering params of a list
+// endpoint, rejecting requests with params the endpoint's schema doesn't
+// support. Handlers read the parsed params with util.ListParamsFromCtx
+func (s Server) ListMiddleware(schema util.ListSchema, handler http.HandlerFunc) http.HandlerFunc {
+	return s.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		lp, err := util.ParseListParams(r, schema)
+		if err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		handler(w, r.WithContext(util.WithListParams(r.Context(), lp)))
+	})
+}
+
+var (
+	// datasetListSchema is the list schema for endpoints that list datasets
+	datasetListSchema = util.ListSchema{
+		SortKeys: []string{"created"},
+		FilterKeys: map[string][]string{
+			"username": {util.FilterEq},
+			"name":     {util.FilterContains},
+		},
+		DefaultLimit: lib.DefaultPageSize,
+	}
+	// pageListSchema is the list schema for endpoints that only paginate
+	pageListSchema = util.ListSchema{
+		DefaultLimit: lib.DefaultPageSize,
+	}
+)
+
+// AdminMiddleware gates a handler to users with the operator role. Requests
+// must carry an access token, even when the owner is the only user of the
+// node. Admin requests skip the read-only check so nodes in read-only mode
+// can still be operated
+func (s Server) AdminMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Infof("%s %s %s", r.Method, r.URL.Path, time.Now())
+
+		if token.FromCtx(r.Context()) == "" {
+			util.WriteErrResponse(w, http.StatusUnauthorized, fmt.Errorf("admin endpoints require an access token"))
+			return
+		}
+		if err := s.Instance.CheckOperator(r.Context()); err != nil {
+			if errors.Is(err, lib.ErrNotOperator) {
+				util.WriteErrResponse(w, http.StatusForbidden, err)
+				return
+			}
+			util.WriteErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// onBehalfOfMiddleware adds the profile named in an On-Behalf-Of header to the
+// request context. Operators use the header to call methods as another
+// profile, requests without an access token can't use it
+func onBehalfOfMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		who := r.Header.Get(lib.OnBehalfOfHeader)
+		if who == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if token.FromCtx(r.Context()) == "" {
+			util.WriteErrResponse(w, http.StatusUnauthorized, fmt.Errorf("calls on behalf of another profile require an access token"))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(lib.WithOnBehalfOf(r.Context(), who)))
+	})
+}
+
+// embedTokenMiddleware requires an embed token passed as a "token" query
+// parameter, counting each accepted request as a use of the token. Embed
+// tokens only grant read access to the preview & body of a single dataset,
+// and are accepted from any origin so datasets can be embedded in web pages
+func (s Server) embedTokenMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("embed tokens are only accepted on GET requests"))
+			return
+		}
+		raw := r.URL.Query().Get("token")
+		if raw == "" {
+			util.WriteErrResponse(w, http.StatusUnauthorized, fmt.Errorf("%w: embed routes require a \"token\" query parameter", token.ErrInvalidToken))
+			return
+		}
+
+		params := &lib.GetParams{}
+		if err := lib.UnmarshalParams(r, params); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		if params.Selector != "" && params.Selector != "body" {
+			util.WriteErrResponse(w, http.StatusForbidden, fmt.Errorf("%w: only previews & bodies can be read with an embed token", lib.ErrEmbedTokenScope))
+			return
+		}
+		ctx, err := s.Instance.UseEmbedToken(r.Context(), raw, params.Refstr)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, lib.ErrEmbedTokenScope) {
+				status = http.StatusForbidden
+			}
+			util.WriteErrResponse(w, status, err)
+			return
+		}
+
+		if w.Header().Get("Access-Control-Allow-Origin") == "" {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
+		handler(w, r.WithContext(ctx))
+	}
+}
+
+var (
+	// defaultCORSMethods are methods cross-origin requests may use when
+	// api.cors.allowedmethods isn't set
+	defaultCORSMethods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS"}
+	// readCORSMethods are methods origins limited to reads may use. Read-only
+	// lib methods like history & sql are called with POST
+	readCORSMethods = []string{"GET", "HEAD", "POST", "OPTIONS"}
+	// defaultCORSHeaders are headers cross-origin requests may use when
+	// api.cors.allowedheaders isn't set
+	defaultCORSHeaders = []string{"Content-Type", "Authorization"}
+)
+
 // corsMiddleware adds Cross-Origin Resource Sharing headers for any request
-// who's origin matches one of allowedOrigins
-func corsMiddleware(allowedOrigins []string) mux.MiddlewareFunc {
+// who's origin the api config allows. Origins listed in api.allowedorigins &
+// api.cors.writeorigins are allowed on all routes, api.cors.readorigins only
+// on routes of read-only lib methods, and GET & HEAD requests to other routes
+func corsMiddleware(cfg *config.API, libMethods []lib.MethodInfo) mux.MiddlewareFunc {
+	routes := newReadRoutes(libMethods)
+	write := append([]string{}, cfg.AllowedOrigins...)
+	var read []string
+	methods := strings.Join(defaultCORSMethods, ", ")
+	headers := strings.Join(defaultCORSHeaders, ",")
+	if c := cfg.CORS; c != nil {
+		write = append(write, c.WriteOrigins...)
+		read = c.ReadOrigins
+		if len(c.AllowedMethods) > 0 {
+			methods = strings.Join(c.AllowedMethods, ", ")
+		}
+		if len(c.AllowedHeaders) > 0 {
+			headers = strings.Join(c.AllowedHeaders, ",")
+		}
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			origin := r.Header.Get("Origin")
-			for _, o := range allowedOrigins {
-				if origin == o {
+			if len(write)+len(read) > 0 {
+				// CORS headers depend on the request origin, caches must not serve
+				// a response to one origin for another
+				w.Header().Add("Vary", "Origin")
+			}
+
+			if origin := r.Header.Get("Origin"); origin != "" {
+				allowMethods := ""
+				if matchOrigin(origin, write) {
+					allowMethods = methods
+				} else if routes.isRead(r) && matchOrigin(origin, read) {
+					allowMethods = strings.Join(readCORSMethods, ", ")
+				}
+				if allowMethods != "" {
 					w.Header().Set("Access-Control-Allow-Origin", origin)
-					w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, POST, DELETE, OPTIONS")
-					w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization")
+					w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+					w.Header().Set("Access-Control-Allow-Headers", headers)
 					w.Header().Set("Access-Control-Allow-Credentials", "true")
 				}
 			}
@@ -60,6 +220,83 @@ func corsMiddleware(allowedOrigins []string) mux.MiddlewareFunc {
 	}
 }
 
+// readRoutes classifies requests as reads by the route group they address.
+// Each group is the REST endpoint of one or more lib methods, and is a read
+// only if every method that shares the endpoint is read-only
+type readRoutes map[string]bool
+
+func newReadRoutes(methods []lib.MethodInfo) readRoutes {
+	routes := readRoutes{}
+	for _, m := range methods {
+		ep := m.Endpoint.String()
+		if read, ok := routes[ep]; ok {
+			routes[ep] = read && m.ReadOnly
+			continue
+		}
+		routes[ep] = m.ReadOnly
+	}
+	return routes
+}
+
+// isRead reports if a request addresses a read-only route group. Routes that
+// don't belong to a lib method fall back to the http verb: GET & HEAD
+// requests, and preflight requests for either
+func (routes readRoutes) isRead(r *http.Request) bool {
+	if read, ok := routes.lookup(r.URL.Path); ok {
+		return read
+	}
+	method := r.Method
+	if method == http.MethodOptions {
+		method = r.Header.Get("Access-Control-Request-Method")
+	}
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// lookup finds the longest endpoint that is a path prefix of p. Endpoints
+// only match whole path segments, so /list doesn't match /listing
+func (routes readRoutes) lookup(p string) (read, ok bool) {
+	match := ""
+	for ep, r := range routes {
+		if (p == ep || strings.HasPrefix(p, ep+"/")) && len(ep) > len(match) {
+			match, read, ok = ep, r, true
+		}
+	}
+	return read, ok
+}
+
+// matchOrigin checks an origin against a list of allowed origins
+func matchOrigin(origin string, allowed []string) bool {
+	for _, pattern := range allowed {
+		if originMatches(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// originMatches checks an origin against a single pattern. A pattern host
+// that starts with "*." matches any subdomain, but not the bare domain:
+// "https://*.qri.io" matches "https://app.qri.io" but not "https://qri.io"
+func originMatches(pattern, origin string) bool {
+	if pattern == origin {
+		return true
+	}
+	i := strings.Index(pattern, "://*.")
+	if i < 0 {
+		return false
+	}
+	scheme, suffix := pattern[:i+len("://")], pattern[i+len("://*"):]
+	if !strings.HasPrefix(origin, scheme) {
+		return false
+	}
+	host := strings.TrimPrefix(origin, scheme)
+	if len(host) <= len(suffix) || !strings.HasSuffix(host, suffix) {
+		return false
+	}
+	sub := host[:len(host)-len(suffix)]
+	return !strings.ContainsAny(sub, "/:@")
+}
+
 func (s *Server) readOnlyCheck(r *http.Request) bool {
 	return !s.GetConfig().API.ReadOnly || r.Method == "GET" || r.Method == "OPTIONS"
 }
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	cfg := &config.API{
+		AllowedOrigins: []string{"https://app.qri.io"},
+		CORS: &config.CORS{
+			ReadOrigins:    []string{"https://*.example.com"},
+			WriteOrigins:   []string{"http://*.qri.local:3000"},
+			AllowedHeaders: []string{"Content-Type", "X-Qri-Client"},
+		},
+	}
+	methods := []lib.MethodInfo{
+		{Name: "dataset.get", Endpoint: "/get", Verb: "GET", ReadOnly: true},
+		{Name: "dataset.stats", Endpoint: "/get", Verb: "GET", ReadOnly: true},
+		{Name: "log.history", Endpoint: "/history", Verb: "POST", ReadOnly: true},
+		{Name: "dataset.save", Endpoint: "/ds/save", Verb: "POST"},
+		{Name: "access.createauthtoken", Endpoint: "/access/token", Verb: "GET"},
+	}
+	h := corsMiddleware(cfg, methods)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	readMethods := "GET, HEAD, POST, OPTIONS"
+	cases := []struct {
+		method, path, reqMethod, origin string
+		allowOrigin, allowMethods       string
+	}{
+		{"POST", "/ds/save", "", "https://app.qri.io", "https://app.qri.io", "GET, PUT, POST, DELETE, OPTIONS"},
+		{"POST", "/ds/save", "", "https://evil.io", "", ""},
+		{"GET", "/get/peer/cities", "", "https://data.example.com", "https://data.example.com", readMethods},
+		{"GET", "/get/peer/cities", "", "https://a.b.example.com", "https://a.b.example.com", readMethods},
+		{"GET", "/get/peer/cities", "", "https://example.com", "", ""},
+		{"GET", "/get/peer/cities", "", "http://data.example.com", "", ""},
+		{"GET", "/get/peer/cities", "", "https://data.example.com.evil.io", "", ""},
+		{"POST", "/ds/save", "", "https://data.example.com", "", ""},
+		// read-only methods are reads regardless of http verb
+		{"POST", "/history/peer/cities", "", "https://data.example.com", "https://data.example.com", readMethods},
+		{"OPTIONS", "/history/peer/cities", "POST", "https://data.example.com", "https://data.example.com", readMethods},
+		// GET routes of methods that aren't read-only are not reads
+		{"GET", "/access/token", "", "https://data.example.com", "", ""},
+		{"OPTIONS", "/access/token", "GET", "https://data.example.com", "", ""},
+		// routes without a lib method fall back to the http verb
+		{"GET", "/webui", "", "https://data.example.com", "https://data.example.com", readMethods},
+		{"OPTIONS", "/webui", "GET", "https://data.example.com", "https://data.example.com", readMethods},
+		{"OPTIONS", "/webui", "POST", "https://data.example.com", "", ""},
+		// endpoints match whole path segments
+		{"GET", "/getter", "", "https://data.example.com", "https://data.example.com", readMethods},
+		{"POST", "/getter", "", "https://data.example.com", "", ""},
+		{"POST", "/ds/save", "", "http://app.qri.local:3000", "http://app.qri.local:3000", "GET, PUT, POST, DELETE, OPTIONS"},
+		{"POST", "/ds/save", "", "http://app.qri.local:4000", "", ""},
+	}
+
+	for i, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		req.Header.Set("Origin", c.origin)
+		if c.reqMethod != "" {
+			req.Header.Set("Access-Control-Request-Method", c.reqMethod)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != c.allowOrigin {
+			t.Errorf("case %d %s %s %s: expected allowed origin %q, got %q", i, c.method, c.path, c.origin, c.allowOrigin, got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Methods"); got != c.allowMethods {
+			t.Errorf("case %d %s %s %s: expected allowed methods %q, got %q", i, c.method, c.path, c.origin, c.allowMethods, got)
+		}
+		if c.allowOrigin != "" && w.Header().Get("Access-Control-Allow-Headers") != "Content-Type,X-Qri-Client" {
+			t.Errorf("case %d: expected configured headers, got %q", i, w.Header().Get("Access-Control-Allow-Headers"))
+		}
+		if got := w.Header().Get("Vary"); got != "Origin" {
+			t.Errorf("case %d: expected Vary: Origin header, got %q", i, got)
+		}
+	}
+}
+
+func TestEmbedTokenMiddleware(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	run.SaveDataset(run.BuildDataset("embed_test"), "testdata/cities/data.csv")
+	run.SaveDataset(run.BuildDataset("embed_other"), "testdata/cities/data.csv")
+	et, err := run.Inst.Access().CreateEmbedToken(run.Ctx, &lib.CreateEmbedTokenParams{Ref: "peer/embed_test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Server{Instance: run.Inst}
+	h := s.embedTokenMiddleware(func(w http.ResponseWriter, r *http.Request) {})
+
+	cases := []struct {
+		description string
+		method      string
+		mvars       map[string]string
+		token       string
+		status      int
+	}{
+		{"no token", "GET", map[string]string{"peername": "peer", "name": "embed_test"}, "", http.StatusUnauthorized},
+		{"preview", "GET", map[string]string{"peername": "peer", "name": "embed_test"}, et.Token, http.StatusOK},
+		{"body", "GET", map[string]string{"peername": "peer", "name": "embed_test", "selector": "body"}, et.Token, http.StatusOK},
+		{"other component", "GET", map[string]string{"peername": "peer", "name": "embed_test", "selector": "meta"}, et.Token, http.StatusForbidden},
+		{"other dataset", "GET", map[string]string{"peername": "peer", "name": "embed_other"}, et.Token, http.StatusForbidden},
+		{"bad token", "GET", map[string]string{"peername": "peer", "name": "embed_test"}, "bad", http.StatusUnauthorized},
+		{"post", "POST", map[string]string{"peername": "peer", "name": "embed_test"}, et.Token, http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			url := "/embed"
+			if c.token != "" {
+				url += "?token=" + c.token
+			}
+			status, body := APICallWithParams(c.method, url, nil, h, c.mvars)
+			if status != c.status {
+				t.Errorf("expected status %d, got %d: %s", c.status, status, body)
+			}
+		})
+	}
+
+	tokens, err := run.Inst.Access().EmbedTokens(run.Ctx, &lib.EmbedTokensParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].Uses != 2 {
+		t.Errorf("expected token to be used twice, got: %#v", tokens)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/lib"
+)
+
+// OAuthHandlers serves OAuth 2.0 endpoints. Unlike other API handlers,
+// responses are not wrapped in a data/meta envelope, and follow the response
+// formats of RFC 6749 & RFC 8628 so standard OAuth clients can use them
+type OAuthHandlers struct {
+	inst *lib.Instance
+}
+
+// NewOAuthHandlers constructs an OAuthHandlers struct
+func NewOAuthHandlers(inst *lib.Instance) OAuthHandlers {
+	return OAuthHandlers{inst: inst}
+}
+
+// DeviceCodeHandler starts a device code flow
+func (h OAuthHandlers) DeviceCodeHandler(w http.ResponseWriter, r *http.Request) {
+	res, err := h.inst.Access().DeviceCode(r.Context(), &lib.DeviceCodeParams{})
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+	writeOAuthResponse(w, http.StatusOK, res)
+}
+
+// TokenHandler exchanges a grant for tokens
+func (h OAuthHandlers) TokenHandler(w http.ResponseWriter, r *http.Request) {
+	p := &lib.OAuthTokenParams{}
+	if err := lib.UnmarshalParams(r, p); err != nil {
+		writeOAuthResponse(w, http.StatusBadRequest, oauthError{Error: "invalid_request", Description: err.Error()})
+		return
+	}
+
+	res, err := h.inst.Access().Token(r.Context(), p)
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+	writeOAuthResponse(w, http.StatusOK, res)
+}
+
+// oauthError is an OAuth 2.0 error response body
+type oauthError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// writeOAuthError maps token exchange errors to OAuth 2.0 error codes
+func writeOAuthError(w http.ResponseWriter, err error) {
+	for _, known := range []error{
+		token.ErrAuthorizationPending,
+		token.ErrAccessDenied,
+		token.ErrExpiredToken,
+		token.ErrInvalidGrant,
+		token.ErrUnsupportedGrantType,
+	} {
+		if errors.Is(err, known) {
+			writeOAuthResponse(w, http.StatusBadRequest, oauthError{Error: known.Error(), Description: err.Error()})
+			return
+		}
+	}
+	writeOAuthResponse(w, http.StatusBadRequest, oauthError{Error: "invalid_request", Description: err.Error()})
+}
+
+func writeOAuthResponse(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	// token responses must never be cached
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Debugw("writing oauth response", "err", err)
+	}
+}
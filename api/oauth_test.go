@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/lib"
+)
+
+func TestOAuthDeviceCodeFlow(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+
+	h := NewOAuthHandlers(run.Inst)
+
+	w := httptest.NewRecorder()
+	h.DeviceCodeHandler(w, httptest.NewRequest(http.MethodPost, lib.AEOAuthDeviceCode.String(), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("device code status mismatch. want %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	da := &token.DeviceAuthorization{}
+	if err := json.NewDecoder(w.Body).Decode(da); err != nil {
+		t.Fatal(err)
+	}
+
+	requestToken := func(form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, lib.AEOAuthToken.String(), strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.TokenHandler(w, r)
+		return w
+	}
+	deviceForm := url.Values{
+		"grant_type":  {token.GrantTypeDeviceCode},
+		"device_code": {da.DeviceCode},
+	}
+
+	w = requestToken(deviceForm)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"error":"authorization_pending"`) {
+		t.Errorf("expected pending response, got %d: %s", w.Code, w.Body.String())
+	}
+
+	routes := NewServerRoutes(New(run.Inst))
+	approveBody := strings.NewReader(`{"userCode":"` + da.UserCode + `","granteeUsername":"me"}`)
+	r := httptest.NewRequest(http.MethodPost, lib.AEOAuthDeviceApprove.String(), approveBody)
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected approving without an access token to be unauthorized, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if err := run.Inst.Access().ApproveDevice(run.Ctx, &lib.ApproveDeviceParams{UserCode: da.UserCode, GranteeUsername: "me"}); err != nil {
+		t.Fatal(err)
+	}
+
+	w = requestToken(deviceForm)
+	if w.Code != http.StatusOK {
+		t.Fatalf("token status mismatch. want %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("expected token response to disable caching, got Cache-Control: %q", cc)
+	}
+	res := &token.Response{}
+	if err := json.NewDecoder(w.Body).Decode(res); err != nil {
+		t.Fatal(err)
+	}
+	if res.AccessToken == "" || res.RefreshToken == "" {
+		t.Fatalf("expected access & refresh tokens, got: %#v", res)
+	}
+
+	w = requestToken(url.Values{
+		"grant_type":    {token.GrantTypeRefreshToken},
+		"refresh_token": {res.RefreshToken},
+	})
+	if w.Code != http.StatusOK {
+		t.Errorf("refresh status mismatch. want %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	w = requestToken(url.Values{"grant_type": {"password"}})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"error":"unsupported_grant_type"`) {
+		t.Errorf("expected unsupported grant type response, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -65,6 +65,105 @@ func (h *RemoteClientHandlers) PushHandler(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// PublishHandler pushes a dataset version to many remotes at once
+func (h *RemoteClientHandlers) PublishHandler(w http.ResponseWriter, r *http.Request) {
+	if h.readOnly {
+		readOnlyResponse(w, lib.AEPublish.String())
+		return
+	}
+
+	params := lib.PublishParams{}
+	if err := lib.UnmarshalParams(r, &params); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Publish(r.Context(), &params)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// PushQueueHandler lists pushes waiting for their remote to become reachable
+func (h *RemoteClientHandlers) PushQueueHandler(w http.ResponseWriter, r *http.Request) {
+	res, err := h.PushQueue(r.Context(), &lib.PushQueueParams{})
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// PushReceiptsHandler verifies the receipts remotes issued for pushes &
+// unpublishes of a dataset
+func (h *RemoteClientHandlers) PushReceiptsHandler(w http.ResponseWriter, r *http.Request) {
+	params := lib.VerifyReceiptsParams{}
+	if err := lib.UnmarshalParams(r, &params); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := h.VerifyReceipts(r.Context(), &params)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// PromoteHandler renames a dataset & pushes it to a remote, rolling back the
+// rename if the push fails
+func (h *RemoteClientHandlers) PromoteHandler(w http.ResponseWriter, r *http.Request) {
+	if h.readOnly {
+		readOnlyResponse(w, lib.AEPromote.String())
+		return
+	}
+
+	params := lib.PromoteParams{}
+	if err := lib.UnmarshalParams(r, &params); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Promote(r.Context(), &params)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// PushHooksHandler fetches the status of hooks a remote ran after a push
+func (h *RemoteClientHandlers) PushHooksHandler(w http.ResponseWriter, r *http.Request) {
+	params := lib.HookStatusParams{}
+	if err := lib.UnmarshalParams(r, &params); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := h.HookStatus(r.Context(), &params)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+// UsageHandler fetches pull stats for a dataset from a remote
+func (h *RemoteClientHandlers) UsageHandler(w http.ResponseWriter, r *http.Request) {
+	params := lib.UsageParams{}
+	if err := lib.UnmarshalParams(r, &params); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := h.Usage(r.Context(), &params)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
 // FeedsHandler fetches an index of named feeds
 func (h *RemoteClientHandlers) FeedsHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	apiutil "github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/lib"
+)
+
+// JSON-RPC 2.0 error codes. Codes from -32000 to -32099 are reserved for
+// implementation-defined server errors
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+	// rpcServerError is returned for errors from method calls
+	rpcServerError = -32000
+	// rpcUnauthorized is returned for calls missing required credentials
+	rpcUnauthorized = -32001
+	// rpcForbidden is returned for calls the caller isn't allowed to make
+	rpcForbidden = -32003
+)
+
+// rpcMethodsMethod lists methods available over JSON-RPC. The "rpc." prefix
+// is reserved by the JSON-RPC spec for extensions
+const rpcMethodsMethod = "rpc.methods"
+
+// rpcRequest is a JSON-RPC 2.0 request object. Requests without an ID are
+// notifications, which get no response
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+func (r rpcRequest) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+// rpcResponse is a JSON-RPC 2.0 response object
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// rpcError is a JSON-RPC 2.0 error object
+type rpcError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    *rpcErrorData `json:"data,omitempty"`
+}
+
+// rpcErrorData carries the http status the equivalent REST call would
+// respond with
+type rpcErrorData struct {
+	Status int `json:"status"`
+}
+
+// RPCHandler serves the methods of the dispatch table over JSON-RPC 2.0.
+// Method names match dispatch names like "dataset.save", params are an object
+// matching the method's input params. Calls are checked the same way their
+// REST routes are, using the attributes methods are registered with: only
+// methods with a REST route mounted on this node can be called, operator
+// methods require an operator token, and read-only nodes only accept calls to
+// methods with a GET route. Calling "rpc.methods" lists available methods
+func (s Server) RPCHandler(w http.ResponseWriter, r *http.Request) {
+	log.Infof("%s %s %s", r.Method, r.URL.Path, time.Now())
+
+	body := &bytes.Buffer{}
+	if _, err := body.ReadFrom(r.Body); err != nil {
+		writeRPCResponse(w, newRPCErrorResponse(nil, rpcParseError, err.Error()))
+		return
+	}
+	data := bytes.TrimSpace(body.Bytes())
+
+	if len(data) > 0 && data[0] == '[' {
+		batch := []json.RawMessage{}
+		if err := json.Unmarshal(data, &batch); err != nil {
+			writeRPCResponse(w, newRPCErrorResponse(nil, rpcParseError, err.Error()))
+			return
+		}
+		if len(batch) == 0 {
+			writeRPCResponse(w, newRPCErrorResponse(nil, rpcInvalidRequest, "empty batch"))
+			return
+		}
+		res := []*rpcResponse{}
+		for _, raw := range batch {
+			if resp := s.rpcCall(r, raw); resp != nil {
+				res = append(res, resp)
+			}
+		}
+		if len(res) == 0 {
+			// a batch of notifications gets no response
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeRPCResponse(w, res)
+		return
+	}
+
+	resp := s.rpcCall(r, data)
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeRPCResponse(w, resp)
+}
+
+// rpcCall runs a single JSON-RPC request, returning nil for notifications
+func (s Server) rpcCall(r *http.Request, raw json.RawMessage) *rpcResponse {
+	req := rpcRequest{}
+	if err := json.Unmarshal(raw, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return newRPCErrorResponse(nil, rpcParseError, err.Error())
+		}
+		return newRPCErrorResponse(nil, rpcInvalidRequest, err.Error())
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return newRPCErrorResponse(req.ID, rpcInvalidRequest, `requests must set "jsonrpc" to "2.0" & name a method`)
+	}
+
+	res, rpcErr := s.rpcDispatch(r.Context(), req)
+	if req.isNotification() {
+		return nil
+	}
+	if rpcErr != nil {
+		return &rpcResponse{JSONRPC: "2.0", Error: rpcErr, ID: req.ID}
+	}
+	result, err := json.Marshal(res)
+	if err != nil {
+		return newRPCErrorResponse(req.ID, rpcInternalError, err.Error())
+	}
+	return &rpcResponse{JSONRPC: "2.0", Result: result, ID: req.ID}
+}
+
+// rpcDispatch checks a call is allowed, then dispatches it
+func (s Server) rpcDispatch(ctx context.Context, req rpcRequest) (interface{}, *rpcError) {
+	if req.Method == rpcMethodsMethod {
+		return s.rpcMethods(), nil
+	}
+
+	info, ok := s.Instance.MethodInfo(req.Method)
+	if !ok || !s.routeMounted(info) {
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+
+	if info.Operator {
+		// match AdminMiddleware
+		if token.FromCtx(ctx) == "" {
+			return nil, &rpcError{Code: rpcUnauthorized, Message: "operator methods require an access token"}
+		}
+		if err := s.Instance.CheckOperator(ctx); err != nil {
+			if errors.Is(err, lib.ErrNotOperator) {
+				return nil, &rpcError{Code: rpcForbidden, Message: err.Error()}
+			}
+			return nil, &rpcError{Code: rpcUnauthorized, Message: err.Error()}
+		}
+	} else if s.GetConfig().API.ReadOnly && info.Verb != http.MethodGet {
+		return nil, &rpcError{Code: rpcForbidden, Message: "qri server is in read-only mode, only methods with GET routes are allowed"}
+	}
+
+	p := s.Instance.NewInputParam(req.Method)
+	if len(req.Params) > 0 && !bytes.Equal(req.Params, []byte("null")) {
+		if req.Params[0] != '{' {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "params must be an object"}
+		}
+		if err := json.Unmarshal(req.Params, p); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+	}
+	if defSetter, ok := p.(lib.NZDefaultSetter); ok {
+		defSetter.SetNonZeroDefaults()
+	}
+
+	res, _, err := s.Instance.Dispatch(ctx, req.Method, p)
+	if err != nil {
+		return nil, &rpcError{
+			Code:    rpcServerError,
+			Message: err.Error(),
+			Data:    &rpcErrorData{Status: apiutil.ErrorStatus(err)},
+		}
+	}
+	return res, nil
+}
+
+// rpcMethods lists methods with a REST route mounted on this node
+func (s Server) rpcMethods() []lib.MethodInfo {
+	res := []lib.MethodInfo{}
+	for _, info := range s.Instance.Methods() {
+		if s.routeMounted(info) {
+			res = append(res, info)
+		}
+	}
+	return res
+}
+
+// routeMounted reports if this node serves the REST route of a method. Nodes
+// leave routes off by configuration, like remote hosting routes on nodes that
+// aren't remotes. Routes that exist but don't accept the method's verb are
+// mounted
+func (s Server) routeMounted(info lib.MethodInfo) bool {
+	if s.Mux == nil {
+		return false
+	}
+	r, err := http.NewRequest(info.Verb, info.Endpoint.String(), nil)
+	if err != nil {
+		return false
+	}
+	match := &mux.RouteMatch{}
+	if s.Mux.Match(r, match) {
+		return true
+	}
+	return errors.Is(match.MatchErr, mux.ErrMethodMismatch)
+}
+
+func newRPCErrorResponse(id json.RawMessage, code int, msg string) *rpcResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{
+		JSONRPC: "2.0",
+		Error:   &rpcError{Code: code, Message: msg},
+		ID:      id,
+	}
+}
+
+func writeRPCResponse(w http.ResponseWriter, res interface{}) {
+	data, err := json.Marshal(res)
+	if err != nil {
+		apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRPCHandler(t *testing.T) {
+	run := NewAPITestRunner(t)
+	defer run.Delete()
+	s := New(run.Inst)
+	s.Mux = NewServerRoutes(s)
+
+	call := func(body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		s.RPCHandler(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder, v interface{}) {
+		t.Helper()
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("decoding response %q: %s", w.Body.String(), err)
+		}
+	}
+
+	res := rpcResponse{}
+	decode(call(`{"jsonrpc":"2.0","method":"dataset.get","params":{"ref":"peer/movies","selector":"meta"},"id":1}`), &res)
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %#v", res.Error)
+	}
+	if string(res.ID) != "1" {
+		t.Errorf("expected response id to match request, got %s", res.ID)
+	}
+	if len(res.Result) == 0 || string(res.Result) == "null" {
+		t.Errorf("expected a result, got %q", res.Result)
+	}
+
+	errCases := []struct {
+		body string
+		code int
+	}{
+		{`{"jsonrpc":"2.0","method":"dataset.get"`, rpcParseError},
+		{`{"jsonrpc":"1.0","method":"dataset.get","id":1}`, rpcInvalidRequest},
+		{`{"jsonrpc":"2.0","method":"dataset.nope","id":1}`, rpcMethodNotFound},
+		{`{"jsonrpc":"2.0","method":"dataset.get","params":["peer/movies"],"id":1}`, rpcInvalidParams},
+		{`{"jsonrpc":"2.0","method":"dataset.get","params":{"ref":"peer/not_a_dataset"},"id":1}`, rpcServerError},
+		{`{"jsonrpc":"2.0","method":"admin.status","id":1}`, rpcUnauthorized},
+		{`{"jsonrpc":"2.0","method":"log.metrics","id":1}`, rpcUnauthorized},
+		// remote hosting routes aren't mounted on nodes that aren't remotes
+		{`{"jsonrpc":"2.0","method":"admin.hosted","id":1}`, rpcMethodNotFound},
+		{`[]`, rpcInvalidRequest},
+	}
+	for i, c := range errCases {
+		res := rpcResponse{}
+		decode(call(c.body), &res)
+		if res.Error == nil {
+			t.Errorf("case %d expected error code %d, got result %s", i, c.code, res.Result)
+			continue
+		}
+		if res.Error.Code != c.code {
+			t.Errorf("case %d error code mismatch. want: %d, got: %d (%s)", i, c.code, res.Error.Code, res.Error.Message)
+		}
+	}
+
+	notFound := rpcResponse{}
+	decode(call(`{"jsonrpc":"2.0","method":"dataset.get","params":{"ref":"peer/not_a_dataset"},"id":"a"}`), &notFound)
+	if notFound.Error == nil || notFound.Error.Data == nil || notFound.Error.Data.Status != http.StatusNotFound {
+		t.Errorf("expected error data to carry a 404 status, got: %#v", notFound.Error)
+	}
+
+	// notifications get no response
+	if w := call(`{"jsonrpc":"2.0","method":"dataset.get","params":{"ref":"peer/movies"}}`); w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("expected empty response to notification, got %d: %q", w.Code, w.Body.String())
+	}
+
+	batch := []rpcResponse{}
+	decode(call(`[
+		{"jsonrpc":"2.0","method":"dataset.get","params":{"ref":"peer/movies","selector":"meta"},"id":1},
+		{"jsonrpc":"2.0","method":"dataset.nope","id":2},
+		{"jsonrpc":"2.0","method":"dataset.get","params":{"ref":"peer/movies"}},
+		{"jsonrpc":"2.0","method":"rpc.methods","id":null}
+	]`), &batch)
+	if len(batch) != 3 {
+		t.Fatalf("expected 3 batch responses, got %d", len(batch))
+	}
+	if batch[0].Error != nil || batch[1].Error == nil || batch[2].Error != nil {
+		t.Errorf("unexpected batch results: %#v", batch)
+	}
+	if string(batch[2].ID) != "null" {
+		t.Errorf("expected null id to be echoed, got %s", batch[2].ID)
+	}
+
+	methods := []struct {
+		Name     string `json:"name"`
+		Endpoint string `json:"endpoint"`
+		Params   []struct {
+			Name string `json:"name"`
+		} `json:"params"`
+	}{}
+	if err := json.Unmarshal(batch[2].Result, &methods); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, m := range methods {
+		if m.Name == "dataset.save" {
+			found = true
+			if m.Endpoint != "/save" {
+				t.Errorf("expected dataset.save endpoint to be /save, got %q", m.Endpoint)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected rpc.methods to list dataset.save")
+	}
+}
@@ -11,7 +11,9 @@ import (
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/fsi"
+	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/transform/run"
 )
 
 var log = golog.Logger("qriapiutil")
@@ -34,48 +36,46 @@ func (err *APIError) Error() string {
 
 // RespondWithError writes the error, with meaningful text, to the http response
 func RespondWithError(w http.ResponseWriter, err error) {
+	WriteErrResponse(w, ErrorStatus(err), err)
+}
+
+// ErrorStatus maps an error returned by lib to an http status code
+func ErrorStatus(err error) int {
 	if errors.Is(err, dsref.ErrRefNotFound) || errors.Is(err, qfs.ErrNotFound) {
-		WriteErrResponse(w, http.StatusNotFound, err)
-		return
+		return http.StatusNotFound
 	}
-	if errors.Is(err, repo.ErrNotFound) {
-		WriteErrResponse(w, http.StatusNotFound, err)
-		return
+	if errors.Is(err, repo.ErrNotFound) || errors.Is(err, run.ErrLogNotFound) {
+		return http.StatusNotFound
 	}
 	if errors.Is(err, fsi.ErrNoLink) {
-		WriteErrResponse(w, http.StatusBadRequest, err)
-		return
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, logbook.ErrAccessDenied) {
+		return http.StatusForbidden
 	}
 	if errors.Is(err, repo.ErrNoHistory) {
-		WriteErrResponse(w, http.StatusUnprocessableEntity, err)
-		return
+		return http.StatusUnprocessableEntity
 	}
 	if errors.Is(err, dsref.ErrBadCaseShouldRename) || errors.Is(err, dsref.ErrDescribeValidName) || errors.Is(err, dsref.ErrDescribeValidUsername) {
-		WriteErrResponse(w, http.StatusBadRequest, err)
-		return
+		return http.StatusBadRequest
 	}
 	var perr *dsref.ParseError
 	if errors.As(err, &perr) {
-		WriteErrResponse(w, http.StatusBadRequest, err)
-		return
+		return http.StatusBadRequest
 	}
 	var aerr *APIError
 	if errors.As(err, &aerr) {
-		WriteErrResponse(w, aerr.Code, err)
-		return
+		return aerr.Code
 	}
 	if strings.HasPrefix(err.Error(), "invalid selection path: ") {
 		// This error comes from `pathValue` in base/select.go
-		WriteErrResponse(w, http.StatusBadRequest, err)
-		return
+		return http.StatusBadRequest
 	}
 	if strings.HasPrefix(err.Error(), "error loading dataset: error getting file bytes") {
-		WriteErrResponse(w, http.StatusNotFound, err)
-		return
+		return http.StatusNotFound
 	}
 	log.Errorf("%s: treating this as a 500 is a bug, see https://github.com/qri-io/qri/issues/959. The code path that generated this should return a known error type, which this function should map to a reasonable http status code", err)
-	WriteErrResponse(w, http.StatusInternalServerError, err)
-	return
+	return http.StatusInternalServerError
 }
 
 // RespondWithDispatchTypeError writes an error describing a type mismatch error from using dispatch
@@ -0,0 +1,259 @@
+package util
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Filter operators
+const (
+	// FilterEq matches values equal to the filter value
+	FilterEq = "eq"
+	// FilterNe matches values not equal to the filter value
+	FilterNe = "ne"
+	// FilterGt matches values greater than the filter value
+	FilterGt = "gt"
+	// FilterLt matches values less than the filter value
+	FilterLt = "lt"
+	// FilterContains matches values containing the filter value
+	FilterContains = "contains"
+)
+
+// ListSchema describes the pagination, sorting & filtering a list endpoint
+// supports. Requests with sort or filter keys outside the schema are rejected
+type ListSchema struct {
+	// SortKeys are the keys results can be ordered by
+	SortKeys []string
+	// FilterKeys maps keys results can be filtered by to the operators each
+	// key supports
+	FilterKeys map[string][]string
+	// DefaultLimit is the page size when none is given, zero uses
+	// DefaultPageSize
+	DefaultLimit int
+	// MaxLimit caps page size, zero is uncapped
+	MaxLimit int
+}
+
+// Filter is a single filter expression, written as "key:op:value" in a
+// request. "key:value" is shorthand for "key:eq:value"
+type Filter struct {
+	Key   string `json:"key"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
+}
+
+// String implements the stringer interface for Filter
+func (f Filter) String() string {
+	return fmt.Sprintf("%s:%s:%s", f.Key, f.Op, f.Value)
+}
+
+// ListParams are the pagination, sorting & filtering params of a request to a
+// list endpoint, validated against the endpoint's ListSchema
+type ListParams struct {
+	Offset  int      `json:"offset"`
+	Limit   int      `json:"limit"`
+	OrderBy OrderBy  `json:"orderBy,omitempty"`
+	Filters []Filter `json:"filters,omitempty"`
+	// Cursor is true when the request paginated with a cursor, responses
+	// link to other pages with cursors
+	Cursor bool `json:"-"`
+}
+
+// Page converts list params to a Page
+func (lp ListParams) Page() Page {
+	size := lp.Limit
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	return Page{Number: lp.Offset/size + 1, Size: size}
+}
+
+// Filter returns the first filter for a key
+func (lp ListParams) Filter(key string) (Filter, bool) {
+	for _, f := range lp.Filters {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return Filter{}, false
+}
+
+// EncodeCursor creates an opaque pagination cursor
+func EncodeCursor(offset, limit int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", offset, limit)))
+}
+
+// DecodeCursor reads the offset & limit of a cursor created with EncodeCursor
+func DecodeCursor(cursor string) (offset, limit int, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	parts := strings.Split(string(data), ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	if offset, err = strconv.Atoi(parts[0]); err != nil || offset < 0 {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	if limit, err = strconv.Atoi(parts[1]); err != nil || limit <= 0 {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	return offset, limit, nil
+}
+
+// ParseListParams reads pagination, sorting & filtering params from a request,
+// validating them against a schema. Pages are set with one of: a cursor from
+// a previous response, a zero-indexed offset & limit, or a one-indexed page &
+// pageSize. Results are ordered with orderBy, a comma separated list of
+// key,direction pairs, and filtered with any number of filter params
+func ParseListParams(r *http.Request, schema ListSchema) (ListParams, error) {
+	lp := ListParams{}
+	if err := r.ParseForm(); err != nil {
+		return lp, err
+	}
+
+	limit := schema.DefaultLimit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+
+	cursor := r.Form.Get("cursor")
+	usesOffset := r.Form.Get("offset") != "" || r.Form.Get("limit") != ""
+	usesPage := r.Form.Get("page") != "" || r.Form.Get("pageSize") != ""
+	switch {
+	case cursor != "" && (usesOffset || usesPage):
+		return lp, fmt.Errorf("cursor can't be combined with other pagination params")
+	case usesOffset && usesPage:
+		return lp, fmt.Errorf("use either offset & limit or page & pageSize, not both")
+	case cursor != "":
+		offset, l, err := DecodeCursor(cursor)
+		if err != nil {
+			return lp, err
+		}
+		lp.Offset, limit, lp.Cursor = offset, l, true
+	case usesOffset:
+		offset, err := formInt(r, "offset", 0, 0)
+		if err != nil {
+			return lp, err
+		}
+		if limit, err = formInt(r, "limit", limit, 1); err != nil {
+			return lp, err
+		}
+		lp.Offset = offset
+	case usesPage:
+		page, err := formInt(r, "page", 1, 1)
+		if err != nil {
+			return lp, err
+		}
+		if limit, err = formInt(r, "pageSize", limit, 1); err != nil {
+			return lp, err
+		}
+		lp.Offset = (page - 1) * limit
+	}
+	if schema.MaxLimit > 0 && limit > schema.MaxLimit {
+		return lp, fmt.Errorf("page size %d is larger than the maximum of %d", limit, schema.MaxLimit)
+	}
+	lp.Limit = limit
+
+	if orderBy := r.Form.Get("orderBy"); orderBy != "" {
+		lp.OrderBy = NewOrderByFromString(orderBy, nil)
+		for _, o := range lp.OrderBy {
+			if !containsString(schema.SortKeys, o.Key) {
+				return lp, fmt.Errorf("can't order by %q. %s", o.Key, keysHint("sort", schema.SortKeys))
+			}
+		}
+	}
+
+	for _, expr := range r.Form["filter"] {
+		f, err := parseFilter(expr)
+		if err != nil {
+			return lp, err
+		}
+		ops, ok := schema.FilterKeys[f.Key]
+		if !ok {
+			keys := make([]string, 0, len(schema.FilterKeys))
+			for k := range schema.FilterKeys {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return lp, fmt.Errorf("can't filter by %q. %s", f.Key, keysHint("filter", keys))
+		}
+		if !containsString(ops, f.Op) {
+			return lp, fmt.Errorf("%q filters don't support the %q operator. supported operators: %s", f.Key, f.Op, strings.Join(ops, ", "))
+		}
+		lp.Filters = append(lp.Filters, f)
+	}
+
+	return lp, nil
+}
+
+func parseFilter(expr string) (Filter, error) {
+	parts := strings.SplitN(expr, ":", 3)
+	switch {
+	case len(parts) == 2 && parts[0] != "":
+		return Filter{Key: parts[0], Op: FilterEq, Value: parts[1]}, nil
+	case len(parts) == 3 && parts[0] != "":
+		return Filter{Key: parts[0], Op: parts[1], Value: parts[2]}, nil
+	}
+	return Filter{}, fmt.Errorf("invalid filter %q. filters are written as key:op:value", expr)
+}
+
+// formInt reads an integer form value no smaller than min
+func formInt(r *http.Request, key string, def, min int) (int, error) {
+	v := r.Form.Get(key)
+	if v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < min {
+		return 0, fmt.Errorf("invalid %s %q. must be an integer of at least %d", key, v, min)
+	}
+	return i, nil
+}
+
+func keysHint(verb string, keys []string) string {
+	if len(keys) == 0 {
+		return fmt.Sprintf("this endpoint doesn't support %sing", verb)
+	}
+	return fmt.Sprintf("%s keys: %s", verb, strings.Join(keys, ", "))
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// cursorURL sets the cursor query param of a url
+func cursorURL(u *url.URL, offset, limit int) string {
+	cpy := *u
+	q := cpy.Query()
+	q.Set("cursor", EncodeCursor(offset, limit))
+	cpy.RawQuery = q.Encode()
+	return cpy.String()
+}
+
+// listParamsKey is the context key list params are stored under
+type listParamsKey struct{}
+
+// WithListParams attaches list params to a context
+func WithListParams(ctx context.Context, lp ListParams) context.Context {
+	return context.WithValue(ctx, listParamsKey{}, lp)
+}
+
+// ListParamsFromCtx extracts list params from a context, returning false if
+// none are set
+func ListParamsFromCtx(ctx context.Context) (ListParams, bool) {
+	lp, ok := ctx.Value(listParamsKey{}).(ListParams)
+	return lp, ok
+}
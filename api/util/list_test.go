@@ -0,0 +1,109 @@
+package util
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseListParams(t *testing.T) {
+	schema := ListSchema{
+		SortKeys: []string{"created", "name"},
+		FilterKeys: map[string][]string{
+			"username": {FilterEq},
+			"name":     {FilterEq, FilterContains},
+		},
+		DefaultLimit: 20,
+		MaxLimit:     100,
+	}
+
+	good := []struct {
+		description string
+		query       string
+		expect      ListParams
+	}{
+		{"defaults", "", ListParams{Limit: 20}},
+		{"offset & limit", "offset=30&limit=10", ListParams{Offset: 30, Limit: 10}},
+		{"page & pageSize", "page=3&pageSize=10", ListParams{Offset: 20, Limit: 10}},
+		{"page with default size", "page=2", ListParams{Offset: 20, Limit: 20}},
+		{"cursor", "cursor=" + EncodeCursor(40, 15), ListParams{Offset: 40, Limit: 15, Cursor: true}},
+		{"order by", "orderBy=name,asc", ListParams{Limit: 20, OrderBy: OrderBy{{Key: "name", Direction: OrderASC}}}},
+		{"filters", "filter=username:b5&filter=name:contains:city", ListParams{Limit: 20, Filters: []Filter{
+			{Key: "username", Op: FilterEq, Value: "b5"},
+			{Key: "name", Op: FilterContains, Value: "city"},
+		}}},
+	}
+
+	for _, c := range good {
+		t.Run(c.description, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?"+c.query, nil)
+			got, err := ParseListParams(r, schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.expect, got); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	bad := []struct {
+		description string
+		query       string
+		err         string
+	}{
+		{"cursor & offset", "cursor=" + EncodeCursor(0, 10) + "&offset=1", "cursor can't be combined with other pagination params"},
+		{"offset & page", "offset=1&page=2", "use either offset & limit or page & pageSize, not both"},
+		{"invalid cursor", "cursor=nope", "invalid cursor"},
+		{"negative offset", "offset=-1", `invalid offset "-1". must be an integer of at least 0`},
+		{"non-numeric page size", "pageSize=lots", `invalid pageSize "lots". must be an integer of at least 1`},
+		{"page size over max", "limit=1000", "page size 1000 is larger than the maximum of 100"},
+		{"unknown sort key", "orderBy=size", `can't order by "size". sort keys: created, name`},
+		{"unknown filter key", "filter=size:eq:10", `can't filter by "size". filter keys: name, username`},
+		{"unsupported operator", "filter=username:contains:b", `"username" filters don't support the "contains" operator. supported operators: eq`},
+		{"malformed filter", "filter=username", `invalid filter "username". filters are written as key:op:value`},
+	}
+
+	for _, c := range bad {
+		t.Run(c.description, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?"+c.query, nil)
+			_, err := ParseListParams(r, schema)
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if err.Error() != c.err {
+				t.Errorf("error mismatch.\nwant: %s\ngot:  %s", c.err, err)
+			}
+		})
+	}
+
+	r := httptest.NewRequest("GET", "/?orderBy=created", nil)
+	if _, err := ParseListParams(r, ListSchema{}); err == nil || err.Error() != `can't order by "created". this endpoint doesn't support sorting` {
+		t.Errorf("expected sorting an unsortable endpoint to error. got: %v", err)
+	}
+}
+
+func TestWritePageResponseCursor(t *testing.T) {
+	r := httptest.NewRequest("GET", "/list?cursor="+EncodeCursor(10, 10), nil)
+	lp, err := ParseListParams(r, ListSchema{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = r.WithContext(WithListParams(r.Context(), lp))
+
+	w := httptest.NewRecorder()
+	page := lp.Page()
+	page.ResultCount = 25
+	if err := WritePageResponse(w, []int{}, r, page); err != nil {
+		t.Fatal(err)
+	}
+
+	body := w.Body.String()
+	for _, cursor := range []string{EncodeCursor(0, 10), EncodeCursor(20, 10)} {
+		if !strings.Contains(body, "cursor="+cursor) {
+			t.Errorf("expected response to link to cursor %q. got: %s", cursor, body)
+		}
+	}
+}
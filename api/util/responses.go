@@ -33,11 +33,25 @@ func WriteResponse(w http.ResponseWriter, data interface{}) error {
 // WritePageResponse wraps response data and pagination information in an
 // envelope and writes it
 func WritePageResponse(w http.ResponseWriter, data interface{}, r *http.Request, p Page) error {
-	if p.PrevPageExists() {
-		p.PrevURL = p.Prev().SetQueryParams(r.URL).String()
-	}
-	if p.NextPageExists() {
-		p.NextURL = p.Next().SetQueryParams(r.URL).String()
+	if lp, ok := ListParamsFromCtx(r.Context()); ok && lp.Cursor {
+		// requests that paginate with a cursor link to pages with cursors
+		if lp.Offset > 0 {
+			prev := lp.Offset - lp.Limit
+			if prev < 0 {
+				prev = 0
+			}
+			p.PrevURL = cursorURL(r.URL, prev, lp.Limit)
+		}
+		if p.ResultCount <= 0 || lp.Offset+lp.Limit < p.ResultCount {
+			p.NextURL = cursorURL(r.URL, lp.Offset+lp.Limit, lp.Limit)
+		}
+	} else {
+		if p.PrevPageExists() {
+			p.PrevURL = p.Prev().SetQueryParams(r.URL).String()
+		}
+		if p.NextPageExists() {
+			p.NextURL = p.Next().SetQueryParams(r.URL).String()
+		}
 	}
 
 	env := Response{
@@ -0,0 +1,65 @@
+package key
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+)
+
+// ErrInvalidCredential is returned when a stored credential can't be decrypted
+var ErrInvalidCredential = fmt.Errorf("invalid credential")
+
+// EncryptCredential encrypts a secret like an authentication token for
+// storage. Encryption uses a symmetric key derived from a private key held in
+// the keystore, only the holder of pk can decrypt the result
+func EncryptCredential(pk crypto.PrivKey, secret string) (string, error) {
+	gcm, err := credentialCipher(pk)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptCredential decrypts a secret created with EncryptCredential
+func DecryptCredential(pk crypto.PrivKey, ciphertext string) (string, error) {
+	gcm, err := credentialCipher(pk)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", ErrInvalidCredential
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	secret, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", ErrInvalidCredential
+	}
+	return string(secret), nil
+}
+
+func credentialCipher(pk crypto.PrivKey) (cipher.AEAD, error) {
+	if pk == nil {
+		return nil, fmt.Errorf("private key is required")
+	}
+	raw, err := pk.Raw()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(append([]byte("qri credential key:"), raw...))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
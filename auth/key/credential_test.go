@@ -0,0 +1,34 @@
+package key_test
+
+import (
+	"testing"
+
+	"github.com/qri-io/qri/auth/key"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+)
+
+func TestCredentialRoundTrip(t *testing.T) {
+	pk := testkeys.GetKeyData(0).PrivKey
+	enc, err := key.EncryptCredential(pk, "secret_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc == "secret_token" {
+		t.Fatal("expected credential to be encrypted")
+	}
+
+	got, err := key.DecryptCredential(pk, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "secret_token" {
+		t.Errorf("decrypted credential mismatch. want %q, got %q", "secret_token", got)
+	}
+
+	if _, err := key.DecryptCredential(testkeys.GetKeyData(1).PrivKey, enc); err != key.ErrInvalidCredential {
+		t.Errorf("expected decrypting with another key to fail with ErrInvalidCredential. got: %v", err)
+	}
+	if _, err := key.DecryptCredential(pk, "not encrypted"); err != key.ErrInvalidCredential {
+		t.Errorf("expected decrypting garbage to fail with ErrInvalidCredential. got: %v", err)
+	}
+}
@@ -13,8 +13,13 @@ import (
 // package
 type CtxKey string
 
-// tokenCtxKey is the key for adding an access token to a context.Context
-const tokenCtxKey CtxKey = "Token"
+const (
+	// tokenCtxKey is the key for adding an access token to a context.Context
+	tokenCtxKey CtxKey = "Token"
+	// originCtxKey is the key for adding the origin of a request to a
+	// context.Context
+	originCtxKey CtxKey = "Origin"
+)
 
 // AddToContext adds a token string to a context
 func AddToContext(ctx context.Context, s string) context.Context {
@@ -31,6 +36,20 @@ func FromCtx(ctx context.Context) string {
 	return ""
 }
 
+// AddOriginToContext records where a request came from
+func AddOriginToContext(ctx context.Context, origin string) context.Context {
+	return context.WithValue(ctx, originCtxKey, origin)
+}
+
+// OriginFromCtx extracts the request origin from a context, returning the
+// empty string if none is set
+func OriginFromCtx(ctx context.Context) string {
+	if s, ok := ctx.Value(originCtxKey).(string); ok {
+		return s
+	}
+	return ""
+}
+
 const (
 	// httpAuthorizationHeader is the http header field to check for tokens,
 	// follows OAuth 2.0 spec
@@ -41,26 +60,29 @@ const (
 )
 
 // OAuthTokenMiddleware parses any "authorization" header containing a Bearer
-// token & adds it to the request context
+// token & adds it to the request context. Every request has its origin added
+// to the context, marking it as arriving over HTTP. The origin is the Origin
+// header if set, falling back to the remote address
 func OAuthTokenMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			origin = r.RemoteAddr
+		}
+		ctx := AddOriginToContext(r.Context(), origin)
+
 		reqToken := r.Header.Get(httpAuthorizationHeader)
 		if reqToken == "" && r.FormValue(httpAuthorizationHeader) != "" {
 			reqToken = r.FormValue(httpAuthorizationHeader)
 		}
-		if reqToken == "" {
-			next.ServeHTTP(w, r)
-			return
+		if reqToken != "" {
+			if !strings.HasPrefix(reqToken, httpAuthorizationBearerPrefix) {
+				util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("bad token"))
+				return
+			}
+			ctx = AddToContext(ctx, strings.TrimPrefix(reqToken, httpAuthorizationBearerPrefix))
 		}
 
-		if !strings.HasPrefix(reqToken, httpAuthorizationBearerPrefix) {
-			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("bad token"))
-			return
-		}
-
-		tokenStr := strings.TrimPrefix(reqToken, httpAuthorizationBearerPrefix)
-		ctx := AddToContext(r.Context(), tokenStr)
-
 		r = r.WithContext(ctx)
 		next.ServeHTTP(w, r)
 	})
@@ -0,0 +1,50 @@
+package token
+
+import (
+	"fmt"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// embedScope marks tokens that grant read access to a single dataset, so they
+// can't be mistaken for other tokens signed by the same source
+const embedScope = "embed"
+
+// NewEmbedToken creates a signed token granting read access to the dataset
+// with the given init ID, returning the raw token & the token ID. A zero ttl
+// creates a token that never expires
+func NewEmbedToken(src Source, initID string, ttl time.Duration) (raw, id string, err error) {
+	if id, err = randomHex(16); err != nil {
+		return "", "", err
+	}
+	raw, err = src.CreateTokenWithClaims(jwt.MapClaims{
+		"jti":    id,
+		"scope":  embedScope,
+		"initID": initID,
+	}, ttl)
+	return raw, id, err
+}
+
+// ParseEmbedToken verifies an embed token created by src, returning the token
+// ID & the init ID of the dataset it grants access to. Expired tokens are
+// rejected
+func ParseEmbedToken(raw string, src Source) (id, initID string, err error) {
+	t, err := Parse(raw, src)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+	claims, ok := t.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", "", ErrInvalidToken
+	}
+	if scope, _ := claims["scope"].(string); scope != embedScope {
+		return "", "", fmt.Errorf("%w: not an embed token", ErrInvalidToken)
+	}
+	id, _ = claims["jti"].(string)
+	initID, _ = claims["initID"].(string)
+	if id == "" || initID == "" {
+		return "", "", ErrInvalidToken
+	}
+	return id, initID, nil
+}
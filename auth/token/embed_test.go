@@ -0,0 +1,56 @@
+package token_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/auth/token"
+)
+
+func TestEmbedToken(t *testing.T) {
+	src, err := token.NewPrivKeySource(testkeys.GetKeyData(0).PrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := token.NewPrivKeySource(testkeys.GetKeyData(1).PrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, id, err := token.NewEmbedToken(src, "init_id", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotID, initID, err := token.ParseEmbedToken(raw, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotID != id || initID != "init_id" {
+		t.Errorf("claims mismatch. want id: %q initID: %q, got id: %q initID: %q", id, "init_id", gotID, initID)
+	}
+
+	if _, _, err := token.ParseEmbedToken(raw, other); !errors.Is(err, token.ErrInvalidToken) {
+		t.Errorf("expected token signed by another key to be invalid, got: %v", err)
+	}
+
+	notEmbed, err := src.CreateTokenWithClaims(map[string]interface{}{"jti": "refresh"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := token.ParseEmbedToken(notEmbed, src); !errors.Is(err, token.ErrInvalidToken) {
+		t.Errorf("expected token without embed scope to be invalid, got: %v", err)
+	}
+
+	prevTs := token.Timestamp
+	token.Timestamp = func() time.Time { return time.Now().Add(-time.Hour * 2) }
+	expired, _, err := token.NewEmbedToken(src, "init_id", time.Hour)
+	token.Timestamp = prevTs
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := token.ParseEmbedToken(expired, src); !errors.Is(err, token.ErrInvalidToken) {
+		t.Errorf("expected expired token to be invalid, got: %v", err)
+	}
+}
@@ -0,0 +1,357 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qri/auth/key"
+)
+
+const (
+	// GrantTypeDeviceCode is the OAuth 2.0 grant type for exchanging an
+	// approved device code for tokens. see https://tools.ietf.org/html/rfc8628
+	GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+	// GrantTypeRefreshToken is the OAuth 2.0 grant type for exchanging a
+	// refresh token for a new access token
+	GrantTypeRefreshToken = "refresh_token"
+)
+
+var (
+	// ErrAuthorizationPending indicates a device code hasn't been approved yet,
+	// clients should keep polling
+	ErrAuthorizationPending = errors.New("authorization_pending")
+	// ErrAccessDenied indicates a device code authorization was denied
+	ErrAccessDenied = errors.New("access_denied")
+	// ErrExpiredToken indicates a device code has expired
+	ErrExpiredToken = errors.New("expired_token")
+	// ErrInvalidGrant indicates a device code, user code or refresh token is
+	// invalid, expired, or has already been used
+	ErrInvalidGrant = errors.New("invalid_grant")
+	// ErrUnsupportedGrantType is returned for unknown grant types
+	ErrUnsupportedGrantType = errors.New("unsupported_grant_type")
+
+	// DefaultAccessTokenTTL is the default lifetime of access tokens issued by
+	// an Exchange. Access tokens are short-lived, clients use refresh tokens
+	// to get new ones
+	DefaultAccessTokenTTL = time.Minute * 15
+	// DefaultRefreshTokenTTL is the default lifetime of refresh tokens
+	DefaultRefreshTokenTTL = time.Hour * 24 * 30
+	// DefaultDeviceCodeTTL is the default lifetime of an unapproved device code
+	DefaultDeviceCodeTTL = time.Minute * 10
+	// DefaultDevicePollInterval is the minimum number of seconds clients should
+	// wait between token requests while a device code is pending
+	DefaultDevicePollInterval = 5
+)
+
+// DeviceAuthorization is the response to a device authorization request
+type DeviceAuthorization struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri,omitempty"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// Response is a successful OAuth 2.0 token response
+type Response struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type deviceGrant struct {
+	userCode  string
+	expires   time.Time
+	profileID string
+	denied    bool
+}
+
+type refreshGrant struct {
+	expires   time.Time
+	profileID string
+}
+
+// Exchange issues tokens through OAuth 2.0 grants. Access tokens are signed
+// with the private key of the grantee found in the keystore, so any node
+// with the grantee's public key can verify them. Refresh tokens are created
+// by a token Source, and can only be used once. All grant state is held in
+// memory, restarting an Exchange invalidates outstanding refresh tokens
+type Exchange struct {
+	source Source
+	keys   key.Store
+
+	lk        sync.Mutex
+	devices   map[string]*deviceGrant
+	userCodes map[string]string
+	refresh   map[string]refreshGrant
+
+	// AccessTokenTTL is the lifetime of issued access tokens
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is the lifetime of issued refresh tokens
+	RefreshTokenTTL time.Duration
+	// DeviceCodeTTL is the lifetime of device codes
+	DeviceCodeTTL time.Duration
+	// VerificationURI is where users are directed to approve device codes
+	VerificationURI string
+}
+
+// NewExchange creates an Exchange with default token lifetimes
+func NewExchange(source Source, keys key.Store) *Exchange {
+	return &Exchange{
+		source:    source,
+		keys:      keys,
+		devices:   map[string]*deviceGrant{},
+		userCodes: map[string]string{},
+		refresh:   map[string]refreshGrant{},
+
+		AccessTokenTTL:  DefaultAccessTokenTTL,
+		RefreshTokenTTL: DefaultRefreshTokenTTL,
+		DeviceCodeTTL:   DefaultDeviceCodeTTL,
+	}
+}
+
+// NewDeviceAuthorization starts a device code flow, returning codes for the
+// client to poll with & the user to approve
+func (x *Exchange) NewDeviceAuthorization() (*DeviceAuthorization, error) {
+	deviceCode, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	userCode, err := randomUserCode()
+	if err != nil {
+		return nil, err
+	}
+
+	x.lk.Lock()
+	defer x.lk.Unlock()
+	x.dropExpired()
+	x.devices[deviceCode] = &deviceGrant{
+		userCode: userCode,
+		expires:  Timestamp().Add(x.DeviceCodeTTL),
+	}
+	x.userCodes[userCode] = deviceCode
+
+	return &DeviceAuthorization{
+		DeviceCode:      deviceCode,
+		UserCode:        userCode,
+		VerificationURI: x.VerificationURI,
+		ExpiresIn:       int(x.DeviceCodeTTL.Seconds()),
+		Interval:        DefaultDevicePollInterval,
+	}, nil
+}
+
+// ApproveDevice grants a pending device code access as the given profile
+func (x *Exchange) ApproveDevice(userCode, profileID string) error {
+	if _, err := x.privKey(profileID); err != nil {
+		return err
+	}
+	return x.resolveDevice(userCode, func(g *deviceGrant) {
+		g.profileID = profileID
+	})
+}
+
+// DenyDevice rejects a pending device code
+func (x *Exchange) DenyDevice(userCode string) error {
+	return x.resolveDevice(userCode, func(g *deviceGrant) {
+		g.denied = true
+	})
+}
+
+func (x *Exchange) resolveDevice(userCode string, resolve func(g *deviceGrant)) error {
+	x.lk.Lock()
+	defer x.lk.Unlock()
+	x.dropExpired()
+
+	g, ok := x.devices[x.userCodes[normalizeUserCode(userCode)]]
+	if !ok {
+		return fmt.Errorf("%w: unknown or expired user code", ErrInvalidGrant)
+	}
+	if g.profileID != "" || g.denied {
+		return fmt.Errorf("%w: user code has already been used", ErrInvalidGrant)
+	}
+	resolve(g)
+	return nil
+}
+
+// Token exchanges a grant for tokens. code is the device code for device
+// code grants, and the refresh token for refresh grants
+func (x *Exchange) Token(grantType, code string) (*Response, error) {
+	switch grantType {
+	case GrantTypeDeviceCode:
+		return x.deviceToken(code)
+	case GrantTypeRefreshToken:
+		return x.refreshToken(code)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedGrantType, grantType)
+	}
+}
+
+func (x *Exchange) deviceToken(deviceCode string) (*Response, error) {
+	x.lk.Lock()
+	g, ok := x.devices[deviceCode]
+	if !ok {
+		x.lk.Unlock()
+		return nil, ErrInvalidGrant
+	}
+	if Timestamp().After(g.expires) {
+		x.removeDevice(deviceCode)
+		x.lk.Unlock()
+		return nil, ErrExpiredToken
+	}
+	if g.denied {
+		x.removeDevice(deviceCode)
+		x.lk.Unlock()
+		return nil, ErrAccessDenied
+	}
+	if g.profileID == "" {
+		x.lk.Unlock()
+		return nil, ErrAuthorizationPending
+	}
+	// device codes can only be exchanged once
+	x.removeDevice(deviceCode)
+	x.lk.Unlock()
+
+	return x.issue(g.profileID)
+}
+
+func (x *Exchange) refreshToken(raw string) (*Response, error) {
+	t, err := Parse(raw, x.source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGrant, err)
+	}
+	claims, ok := t.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidGrant
+	}
+	jti, _ := claims["jti"].(string)
+
+	x.lk.Lock()
+	g, ok := x.refresh[jti]
+	// refresh tokens rotate, each can only be used once
+	delete(x.refresh, jti)
+	x.lk.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: refresh token has already been used", ErrInvalidGrant)
+	}
+
+	return x.issue(g.profileID)
+}
+
+// issue creates an access & refresh token pair for a profile
+func (x *Exchange) issue(profileID string) (*Response, error) {
+	pk, err := x.privKey(profileID)
+	if err != nil {
+		return nil, err
+	}
+	access, err := NewPrivKeyAuthToken(pk, profileID, x.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	jti, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := x.source.CreateTokenWithClaims(jwt.MapClaims{
+		"sub": profileID,
+		"jti": jti,
+	}, x.RefreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	x.lk.Lock()
+	x.dropExpired()
+	x.refresh[jti] = refreshGrant{
+		expires:   Timestamp().Add(x.RefreshTokenTTL),
+		profileID: profileID,
+	}
+	x.lk.Unlock()
+
+	return &Response{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(x.AccessTokenTTL.Seconds()),
+		RefreshToken: refresh,
+	}, nil
+}
+
+// privKey fetches the private key for a profile from the keystore. profile
+// identifiers are key identifiers
+func (x *Exchange) privKey(profileID string) (crypto.PrivKey, error) {
+	id, err := key.DecodeID(profileID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile ID %q: %w", profileID, err)
+	}
+	pk := x.keys.PrivKey(id)
+	if pk == nil {
+		return nil, fmt.Errorf("cannot issue tokens for %q, private key is required", profileID)
+	}
+	return pk, nil
+}
+
+func (x *Exchange) removeDevice(deviceCode string) {
+	if g, ok := x.devices[deviceCode]; ok {
+		delete(x.userCodes, g.userCode)
+		delete(x.devices, deviceCode)
+	}
+}
+
+// dropExpired removes expired device codes & refresh tokens. must be called
+// with the lock held
+func (x *Exchange) dropExpired() {
+	now := Timestamp()
+	for code, g := range x.devices {
+		if now.After(g.expires) {
+			x.removeDevice(code)
+		}
+	}
+	for jti, g := range x.refresh {
+		if now.After(g.expires) {
+			delete(x.refresh, jti)
+		}
+	}
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// userCodeChars excludes vowels and easily-confused characters, as
+// recommended in https://tools.ietf.org/html/rfc8628#section-6.1
+const userCodeChars = "BCDFGHJKLMNPQRSTVWXZ"
+
+func randomUserCode() (string, error) {
+	code := make([]byte, 8)
+	max := big.NewInt(int64(len(userCodeChars)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeChars[n.Int64()]
+	}
+	return fmt.Sprintf("%s-%s", code[:4], code[4:]), nil
+}
+
+// normalizeUserCode accepts user codes without a dash & in lowercase
+func normalizeUserCode(code string) string {
+	code = strings.ToUpper(strings.Replace(strings.TrimSpace(code), "-", "", -1))
+	if len(code) != 8 {
+		return code
+	}
+	return fmt.Sprintf("%s-%s", code[:4], code[4:])
+}
@@ -0,0 +1,94 @@
+package token_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/auth/key"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/auth/token"
+)
+
+func TestExchange(t *testing.T) {
+	kd := testkeys.GetKeyData(0)
+	ks, err := key.NewMemStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.AddPrivKey(kd.KeyID, kd.PrivKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.AddPubKey(kd.KeyID, kd.PrivKey.GetPublic()); err != nil {
+		t.Fatal(err)
+	}
+	source, err := token.NewPrivKeySource(kd.PrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := token.NewExchange(source, ks)
+
+	if _, err := x.Token("password", "foo"); !errors.Is(err, token.ErrUnsupportedGrantType) {
+		t.Errorf("expected unsupported grant type error, got: %v", err)
+	}
+
+	da, err := x.NewDeviceAuthorization()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.Token(token.GrantTypeDeviceCode, da.DeviceCode); !errors.Is(err, token.ErrAuthorizationPending) {
+		t.Errorf("expected unapproved device code to be pending, got: %v", err)
+	}
+
+	// user codes are accepted in lowercase & without a dash
+	userCode := strings.ToLower(strings.Replace(da.UserCode, "-", "", 1))
+	if err := x.ApproveDevice(userCode, kd.KeyID.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.ApproveDevice(da.UserCode, kd.KeyID.String()); !errors.Is(err, token.ErrInvalidGrant) {
+		t.Errorf("expected approving a device twice to fail, got: %v", err)
+	}
+
+	res, err := x.Token(token.GrantTypeDeviceCode, da.DeviceCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TokenType != "Bearer" || res.AccessToken == "" || res.RefreshToken == "" {
+		t.Errorf("incomplete token response: %#v", res)
+	}
+	if _, err := token.ParseAuthToken(res.AccessToken, ks); err != nil {
+		t.Errorf("access token must be verifiable with the keystore: %s", err)
+	}
+	if _, err := x.Token(token.GrantTypeDeviceCode, da.DeviceCode); !errors.Is(err, token.ErrInvalidGrant) {
+		t.Errorf("expected device code to only be usable once, got: %v", err)
+	}
+
+	refreshed, err := x.Token(token.GrantTypeRefreshToken, res.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := token.ParseAuthToken(refreshed.AccessToken, ks); err != nil {
+		t.Errorf("refreshed access token must be verifiable with the keystore: %s", err)
+	}
+	if _, err := x.Token(token.GrantTypeRefreshToken, res.RefreshToken); !errors.Is(err, token.ErrInvalidGrant) {
+		t.Errorf("expected refresh token to only be usable once, got: %v", err)
+	}
+	if _, err := x.Token(token.GrantTypeRefreshToken, "not.a.token"); !errors.Is(err, token.ErrInvalidGrant) {
+		t.Errorf("expected invalid refresh token to error, got: %v", err)
+	}
+
+	denied, err := x.NewDeviceAuthorization()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := x.DenyDevice(denied.UserCode); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.Token(token.GrantTypeDeviceCode, denied.DeviceCode); !errors.Is(err, token.ErrAccessDenied) {
+		t.Errorf("expected denied device code to return access denied, got: %v", err)
+	}
+
+	if err := x.ApproveDevice("BCDF-GHJK", testkeys.GetKeyData(1).KeyID.String()); err == nil {
+		t.Error("expected approving as a profile without a private key to fail")
+	}
+}
@@ -0,0 +1,326 @@
+package token
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+var log = golog.Logger("token")
+
+var (
+	// ErrTokenRevoked indicates an access token belongs to a session that has
+	// been terminated
+	ErrTokenRevoked = errors.New("access token has been revoked")
+	// ErrSessionNotFound is returned when terminating an unknown session
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrTokenRequired is returned for requests without an access token once
+	// the profile they would act as has signed out everywhere
+	ErrTokenRequired = util.NewAPIError(http.StatusUnauthorized, "an access token is required, this profile has signed out everywhere")
+)
+
+// Session describes a client that has made authenticated requests with an
+// access token
+type Session struct {
+	// ID is the identifier of the token used by the session
+	ID        string    `json:"id"`
+	ProfileID string    `json:"profileID"`
+	Origin    string    `json:"origin,omitempty"`
+	Created   time.Time `json:"created"`
+	LastSeen  time.Time `json:"lastSeen"`
+	// Expires is the expiry of the session token, zero for tokens that never
+	// expire
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// TokenID gives a stable identifier for a token. Tokens with a "jti" claim
+// use that claim, otherwise the identifier is derived from the encoded token
+func TokenID(t *Token) string {
+	switch c := t.Claims.(type) {
+	case *Claims:
+		if c.StandardClaims != nil && c.Id != "" {
+			return c.Id
+		}
+	case jwt.MapClaims:
+		if jti, ok := c["jti"].(string); ok && jti != "" {
+			return jti
+		}
+	}
+	sum := sha256.Sum256([]byte(t.Raw))
+	return hex.EncodeToString(sum[:16])
+}
+
+// RawTokenID gives the identifier of an encoded token without verifying it
+func RawTokenID(raw string) (string, error) {
+	t, _, err := new(jwt.Parser).ParseUnverified(raw, &Claims{})
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	}
+	return TokenID(t), nil
+}
+
+// tokenExpiry reads the expiry of a token, returning the zero time for
+// tokens without an expiry
+func tokenExpiry(t *Token) time.Time {
+	var exp int64
+	switch c := t.Claims.(type) {
+	case *Claims:
+		if c.StandardClaims != nil {
+			exp = c.ExpiresAt
+		}
+	case jwt.MapClaims:
+		if f, ok := c["exp"].(float64); ok {
+			exp = int64(f)
+		}
+	}
+	if exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(exp, 0)
+}
+
+// SessionSaveInterval is how often Run writes session activity to disk.
+// Tracking a request only updates memory, revocations are written
+// immediately
+var SessionSaveInterval = time.Minute
+
+// Sessions is a registry of authenticated clients. Terminating a session
+// adds its token to a revocation list, tokens on the list are rejected until
+// they expire. Signing out everywhere also records that the profile must
+// present a token, ending requests that act as the profile without one.
+// Sessions, the revocation list & signed out profiles are persisted as JSON
+// to sessions.json, revoked_tokens.json & signed_out.json in a directory. An
+// empty directory keeps all three in memory
+type Sessions struct {
+	sessionsFile  jsonfile.File
+	revokedFile   jsonfile.File
+	signedOutFile jsonfile.File
+
+	lk       sync.Mutex
+	loaded   bool
+	dirty    bool
+	sessions map[string]*Session
+	// revoked maps token IDs to token expiry
+	revoked map[string]time.Time
+	// signedOut maps profile IDs to the time they signed out everywhere
+	signedOut map[string]time.Time
+}
+
+// NewSessions creates a session registry that persists to dir
+func NewSessions(dir string) *Sessions {
+	s := &Sessions{
+		sessions:  map[string]*Session{},
+		revoked:   map[string]time.Time{},
+		signedOut: map[string]time.Time{},
+	}
+	if dir != "" {
+		s.sessionsFile = jsonfile.New(filepath.Join(dir, "sessions.json"))
+		s.revokedFile = jsonfile.New(filepath.Join(dir, "revoked_tokens.json"))
+		s.signedOutFile = jsonfile.New(filepath.Join(dir, "signed_out.json"))
+	}
+	return s
+}
+
+// Run writes session activity to disk every SessionSaveInterval until the
+// context is cancelled, writing any remaining activity before returning
+func (s *Sessions) Run(ctx context.Context) {
+	t := time.NewTicker(SessionSaveInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := s.Flush(); err != nil {
+				log.Debugw("saving sessions", "err", err)
+			}
+		case <-ctx.Done():
+			if err := s.Flush(); err != nil {
+				log.Debugw("saving sessions", "err", err)
+			}
+			return
+		}
+	}
+}
+
+// Flush writes session activity tracked since the last write
+func (s *Sessions) Flush() error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	return s.save()
+}
+
+// TokenRequired reports whether requests acting as a profile must present an
+// access token, which is true once the profile has signed out everywhere
+func (s *Sessions) TokenRequired(profileID string) (bool, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if err := s.load(); err != nil {
+		return false, err
+	}
+	_, ok := s.signedOut[profileID]
+	return ok, nil
+}
+
+// Track records use of a token by a client, returning ErrTokenRevoked if the
+// token belongs to a terminated session. Activity is kept in memory until the
+// next Flush
+func (s *Sessions) Track(t *Token, profileID, origin string) error {
+	id := TokenID(t)
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.revoked[id]; ok {
+		return ErrTokenRevoked
+	}
+
+	now := Timestamp()
+	s.dirty = true
+	if sess, ok := s.sessions[id]; ok {
+		sess.LastSeen = now
+		if origin != "" {
+			sess.Origin = origin
+		}
+		return nil
+	}
+
+	s.dropExpired(now)
+	s.sessions[id] = &Session{
+		ID:        id,
+		ProfileID: profileID,
+		Origin:    origin,
+		Created:   now,
+		LastSeen:  now,
+		Expires:   tokenExpiry(t),
+	}
+	return nil
+}
+
+// List returns sessions for a profile, most recently seen first. An empty
+// profileID lists sessions for all profiles
+func (s *Sessions) List(profileID string) ([]Session, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	s.dropExpired(Timestamp())
+
+	res := []Session{}
+	for _, sess := range s.sessions {
+		if profileID == "" || sess.ProfileID == profileID {
+			res = append(res, *sess)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].LastSeen.After(res[j].LastSeen)
+	})
+	return res, nil
+}
+
+// Terminate ends a session, revoking its token
+func (s *Sessions) Terminate(id string) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrSessionNotFound, id)
+	}
+	delete(s.sessions, id)
+	s.revoked[id] = sess.Expires
+	return s.save()
+}
+
+// TerminateAll ends all sessions for a profile except the session with ID
+// keep, returning the number of terminated sessions. From then on requests
+// acting as the profile must present an access token
+func (s *Sessions) TerminateAll(profileID, keep string) (int, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for id, sess := range s.sessions {
+		if id == keep || sess.ProfileID != profileID {
+			continue
+		}
+		delete(s.sessions, id)
+		s.revoked[id] = sess.Expires
+		n++
+	}
+	s.signedOut[profileID] = Timestamp()
+	return n, s.save()
+}
+
+// dropExpired removes sessions & revocations for expired tokens, which can no
+// longer be used. must be called with the lock held
+func (s *Sessions) dropExpired(now time.Time) {
+	for id, sess := range s.sessions {
+		if !sess.Expires.IsZero() && now.After(sess.Expires) {
+			delete(s.sessions, id)
+		}
+	}
+	for id, exp := range s.revoked {
+		if !exp.IsZero() && now.After(exp) {
+			delete(s.revoked, id)
+		}
+	}
+}
+
+// load reads sessions, the revocation list & signed out profiles from disk
+// once. must be called with the lock held
+func (s *Sessions) load() error {
+	if s.loaded {
+		return nil
+	}
+	if err := s.sessionsFile.Load(&s.sessions); err != nil {
+		return fmt.Errorf("invalid session registry: %w", err)
+	}
+	if err := s.revokedFile.Load(&s.revoked); err != nil {
+		return fmt.Errorf("invalid token revocation list: %w", err)
+	}
+	if err := s.signedOutFile.Load(&s.signedOut); err != nil {
+		return fmt.Errorf("invalid signed out profile list: %w", err)
+	}
+	s.loaded = true
+	return nil
+}
+
+// save writes sessions, the revocation list & signed out profiles. must be
+// called with the lock held
+func (s *Sessions) save() error {
+	s.dropExpired(Timestamp())
+	if err := s.sessionsFile.Save(s.sessions); err != nil {
+		return err
+	}
+	if err := s.revokedFile.Save(s.revoked); err != nil {
+		return err
+	}
+	if err := s.signedOutFile.Save(s.signedOut); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
@@ -0,0 +1,120 @@
+package token_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/auth/key"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/auth/token"
+)
+
+func TestSessions(t *testing.T) {
+	kd := testkeys.GetKeyData(0)
+	ks, err := key.NewMemStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.AddPubKey(kd.KeyID, kd.PrivKey.GetPublic()); err != nil {
+		t.Fatal(err)
+	}
+	newToken := func() *token.Token {
+		raw, err := token.NewPrivKeyAuthToken(kd.PrivKey, kd.KeyID.String(), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tok, err := token.ParseAuthToken(raw, ks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id, err := token.RawTokenID(raw); err != nil || id != token.TokenID(tok) {
+			t.Fatalf("expected raw & parsed token IDs to match. err: %v", err)
+		}
+		return tok
+	}
+
+	dir, err := ioutil.TempDir("", "sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := token.NewSessions(dir)
+	a, b := newToken(), newToken()
+	if token.TokenID(a) == token.TokenID(b) {
+		t.Fatal("expected tokens to have distinct IDs")
+	}
+	if err := s.Track(a, kd.KeyID.String(), "desktop"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Track(b, kd.KeyID.String(), "cli"); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustList(t, s, kd.KeyID.String()); len(got) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(got))
+	}
+	if got := mustList(t, s, "other_profile"); len(got) != 0 {
+		t.Errorf("expected no sessions for another profile, got %d", len(got))
+	}
+
+	// sessions persist across registries once flushed
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	s = token.NewSessions(dir)
+	if got := mustList(t, s, kd.KeyID.String()); len(got) != 2 {
+		t.Fatalf("expected 2 sessions to persist, got %d", len(got))
+	}
+
+	if err := s.Terminate(token.TokenID(a)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Track(a, kd.KeyID.String(), "desktop"); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected terminated token to be revoked, got: %v", err)
+	}
+	if err := s.Terminate(token.TokenID(a)); !errors.Is(err, token.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got: %v", err)
+	}
+
+	// revocations persist across registries
+	s = token.NewSessions(dir)
+	if err := s.Track(a, kd.KeyID.String(), "desktop"); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected revocation to persist, got: %v", err)
+	}
+	if err := s.Track(b, kd.KeyID.String(), "cli"); err != nil {
+		t.Errorf("expected unrevoked token to be accepted, got: %v", err)
+	}
+
+	// terminating all sessions reaches sessions started by an earlier registry
+	s = token.NewSessions(dir)
+	n, err := s.TerminateAll(kd.KeyID.String(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 terminated session, got %d", n)
+	}
+	if err := token.NewSessions(dir).Track(b, kd.KeyID.String(), "cli"); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected terminated token to be revoked, got: %v", err)
+	}
+
+	// signing out everywhere requires tokens from then on
+	if required, err := token.NewSessions(dir).TokenRequired(kd.KeyID.String()); err != nil || !required {
+		t.Errorf("expected signing out everywhere to require tokens. required: %t, err: %v", required, err)
+	}
+	if required, err := token.NewSessions(dir).TokenRequired("other_profile"); err != nil || required {
+		t.Errorf("expected other profiles not to require tokens. required: %t, err: %v", required, err)
+	}
+}
+
+func mustList(t *testing.T, s *token.Sessions, profileID string) []token.Session {
+	t.Helper()
+	sessions, err := s.List(profileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sessions
+}
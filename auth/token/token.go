@@ -75,9 +75,17 @@ func NewPrivKeyAuthToken(pk crypto.PrivKey, profileID string, ttl time.Duration)
 		exp = Timestamp().Add(ttl).In(time.UTC).Unix()
 	}
 
+	// give each token a unique ID, so sessions using the token can be told
+	// apart & revoked
+	jti, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
 	// set our claims
 	t.Claims = &Claims{
 		StandardClaims: &jwt.StandardClaims{
+			Id:      jti,
 			Issuer:  id,
 			Subject: id,
 			// set the expire time
@@ -0,0 +1,222 @@
+package archive
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/warehouse"
+)
+
+// WriteSQLDump writes the body of an open tabular dataset as a SQL dump: a
+// CREATE TABLE statement built from the dataset structure, followed by one
+// INSERT statement per row. An empty table name is derived from the dataset
+// name
+func WriteSQLDump(ds *dataset.Dataset, table string, w io.Writer) error {
+	if ds == nil {
+		return fmt.Errorf("can't dump a nil dataset")
+	}
+	if table == "" {
+		table = warehouse.Identifier(ds.Name)
+	}
+	if table == "" {
+		return fmt.Errorf("a table name is required")
+	}
+
+	cols, err := warehouse.Columns(ds.Structure)
+	if err != nil {
+		return fmt.Errorf("dataset can't be exported as a SQL table: %w", err)
+	}
+	rows, err := warehouse.ReadRows(ds, cols)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(cols))
+	defs := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = sqlIdent(c.Name)
+		defs[i] = fmt.Sprintf("  %s %s", names[i], sqlType(c.Type))
+	}
+	if _, err := fmt.Fprintf(w, "CREATE TABLE %s (\n%s\n);\n\n", sqlIdent(table), strings.Join(defs, ",\n")); err != nil {
+		return err
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (", sqlIdent(table), strings.Join(names, ", "))
+	vals := make([]string, len(cols))
+	for _, row := range rows {
+		for i, v := range row {
+			vals[i] = sqlLiteral(v)
+		}
+		if _, err := fmt.Fprintf(w, "%s%s);\n", insert, strings.Join(vals, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sqlIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func sqlType(colType string) string {
+	switch colType {
+	case warehouse.ColumnInteger:
+		return "BIGINT"
+	case warehouse.ColumnNumber:
+		return "DOUBLE PRECISION"
+	case warehouse.ColumnBoolean:
+		return "BOOLEAN"
+	default:
+		// JSON columns are written as text to keep the dump portable across
+		// databases
+		return "TEXT"
+	}
+}
+
+func sqlLiteral(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if x {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case string:
+		return "'" + strings.Replace(x, "'", "''", -1) + "'"
+	default:
+		return "'" + strings.Replace(fmt.Sprintf("%v", x), "'", "''", -1) + "'"
+	}
+}
+
+// JSONLFilename returns the name of a JSONL body partition
+func JSONLFilename(part int, gz bool) string {
+	name := fmt.Sprintf("body-%05d.jsonl", part)
+	if gz {
+		name += ".gz"
+	}
+	return name
+}
+
+// WriteJSONL writes the body of an open dataset as newline-delimited JSON,
+// one entry per line. Entries are partitioned into files of at most
+// rowsPerFile lines each, a rowsPerFile of zero or less writes a single
+// file. create is called with a name from JSONLFilename to open each
+// partition, and the writer it returns is closed once the partition is
+// written if it implements io.Closer. Entries of object bodies are written as
+// single-key objects. WriteJSONL returns the names of all partitions written
+func WriteJSONL(ds *dataset.Dataset, rowsPerFile int, gz bool, create func(name string) (io.Writer, error)) ([]string, error) {
+	if ds == nil {
+		return nil, fmt.Errorf("can't export a nil dataset")
+	}
+	if ds.BodyFile() == nil {
+		return nil, fmt.Errorf("dataset has no body")
+	}
+	tlt, err := dsio.GetTopLevelType(ds.Structure)
+	if err != nil {
+		return nil, err
+	}
+	r, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var (
+		names = []string{}
+		p     *jsonlPartition
+		rows  int
+	)
+	for {
+		ent, err := r.ReadEntry()
+		if err != nil {
+			if err == io.EOF || err.Error() == "EOF" {
+				break
+			}
+			p.close()
+			return nil, err
+		}
+
+		if p == nil || (rowsPerFile > 0 && rows == rowsPerFile) {
+			if err := p.close(); err != nil {
+				return nil, err
+			}
+			name := JSONLFilename(len(names), gz)
+			if p, err = newJSONLPartition(name, gz, create); err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+			rows = 0
+		}
+
+		var val interface{} = ent.Value
+		if tlt == "object" {
+			val = map[string]interface{}{ent.Key: ent.Value}
+		}
+		if err := p.enc.Encode(val); err != nil {
+			p.close()
+			return nil, err
+		}
+		rows++
+	}
+
+	// an empty body is still exported as a single empty file
+	if p == nil {
+		name := JSONLFilename(0, gz)
+		if p, err = newJSONLPartition(name, gz, create); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := p.close(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// jsonlPartition is an open JSONL output file
+type jsonlPartition struct {
+	w   io.Writer
+	gzw *gzip.Writer
+	enc *json.Encoder
+}
+
+func newJSONLPartition(name string, gz bool, create func(name string) (io.Writer, error)) (*jsonlPartition, error) {
+	w, err := create(name)
+	if err != nil {
+		return nil, err
+	}
+	p := &jsonlPartition{w: w}
+	if gz {
+		p.gzw = gzip.NewWriter(w)
+		p.enc = json.NewEncoder(p.gzw)
+	} else {
+		p.enc = json.NewEncoder(w)
+	}
+	return p, nil
+}
+
+func (p *jsonlPartition) close() error {
+	if p == nil {
+		return nil
+	}
+	if p.gzw != nil {
+		if err := p.gzw.Close(); err != nil {
+			return err
+		}
+	}
+	if c, ok := p.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+func exportTestDataset() *dataset.Dataset {
+	ds := &dataset.Dataset{
+		Name: "movies",
+		Structure: &dataset.Structure{
+			Format: "csv",
+			FormatConfig: map[string]interface{}{
+				"headerRow": true,
+			},
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": []interface{}{
+						map[string]interface{}{"title": "movie", "type": "string"},
+						map[string]interface{}{"title": "year", "type": "integer"},
+					},
+				},
+			},
+		},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("/body.csv", []byte("movie,year\nup,2009\nwall-e,2008\ndon't look up,2021\n")))
+	return ds
+}
+
+func TestWriteSQLDump(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteSQLDump(exportTestDataset(), "", buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `CREATE TABLE "movies" (
+  "movie" TEXT,
+  "year" BIGINT
+);
+
+INSERT INTO "movies" ("movie", "year") VALUES ('up', 2009);
+INSERT INTO "movies" ("movie", "year") VALUES ('wall-e', 2008);
+INSERT INTO "movies" ("movie", "year") VALUES ('don''t look up', 2021);
+`
+	if diff := cmp.Diff(expect, buf.String()); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type nopCloseBuffer struct {
+	*bytes.Buffer
+}
+
+func (nopCloseBuffer) Close() error { return nil }
+
+func TestWriteJSONL(t *testing.T) {
+	files := map[string]*bytes.Buffer{}
+	create := func(name string) (io.Writer, error) {
+		files[name] = &bytes.Buffer{}
+		return nopCloseBuffer{files[name]}, nil
+	}
+
+	names, err := WriteJSONL(exportTestDataset(), 2, false, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"body-00000.jsonl", "body-00001.jsonl"}, names); diff != "" {
+		t.Errorf("partition names mismatch (-want +got):\n%s", diff)
+	}
+	expect := map[string]string{
+		"body-00000.jsonl": "[\"up\",2009]\n[\"wall-e\",2008]\n",
+		"body-00001.jsonl": "[\"don't look up\",2021]\n",
+	}
+	for name, data := range expect {
+		if diff := cmp.Diff(data, files[name].String()); diff != "" {
+			t.Errorf("partition %q mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+
+	files = map[string]*bytes.Buffer{}
+	names, err = WriteJSONL(exportTestDataset(), 0, true, create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"body-00000.jsonl.gz"}, names); diff != "" {
+		t.Errorf("partition names mismatch (-want +got):\n%s", diff)
+	}
+	gzr, err := gzip.NewReader(files["body-00000.jsonl.gz"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(gzr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("[\"up\",2009]\n[\"wall-e\",2008]\n[\"don't look up\",2021]\n", string(data)); diff != "" {
+		t.Errorf("gzipped partition mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,334 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/warehouse"
+)
+
+// parquetMagic opens & closes every parquet file
+const parquetMagic = "PAR1"
+
+// parquet physical types, repetitions, encodings & page types used by
+// WriteParquet. values come from the parquet format thrift definitions
+const (
+	pqTypeBoolean   = 0
+	pqTypeInt64     = 2
+	pqTypeDouble    = 5
+	pqTypeByteArray = 6
+
+	pqRepetitionOptional = 1
+
+	pqConvertedUTF8 = 0
+
+	pqEncodingPlain = 0
+	pqEncodingRLE   = 3
+
+	pqCodecUncompressed = 0
+
+	pqPageData = 0
+)
+
+// WriteParquet writes the body of an open tabular dataset as an uncompressed
+// parquet file with a single row group. Columns are derived from the dataset
+// structure & are all optional: integers are written as INT64, numbers as
+// DOUBLE, booleans as BOOLEAN, and all other values as UTF-8 strings. JSON
+// columns hold serialized JSON
+func WriteParquet(ds *dataset.Dataset, w io.Writer) error {
+	if ds == nil {
+		return fmt.Errorf("can't export a nil dataset")
+	}
+	cols, err := warehouse.Columns(ds.Structure)
+	if err != nil {
+		return fmt.Errorf("dataset can't be exported as parquet: %w", err)
+	}
+	rows, err := warehouse.ReadRows(ds, cols)
+	if err != nil {
+		return err
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(parquetMagic)
+
+	chunks := make([]*thriftCompact, len(cols))
+	var groupSize int64
+	for i, c := range cols {
+		offset := int64(buf.Len())
+		page := parquetPage(c, rows, i)
+		header := &thriftCompact{}
+		header.i32Field(1, pqPageData)
+		header.i32Field(2, int32(len(page)))
+		header.i32Field(3, int32(len(page)))
+		header.structField(5)
+		header.i32Field(1, int32(len(rows)))
+		header.i32Field(2, pqEncodingPlain)
+		header.i32Field(3, pqEncodingRLE)
+		header.i32Field(4, pqEncodingRLE)
+		header.endStruct()
+		header.stop()
+
+		buf.Write(header.Bytes())
+		buf.Write(page)
+		size := int64(buf.Len()) - offset
+		groupSize += size
+
+		chunk := &thriftCompact{}
+		chunk.i64Field(2, offset)
+		chunk.structField(3)
+		chunk.i32Field(1, parquetType(c.Type))
+		chunk.listField(2, thriftI32, 2)
+		chunk.i32(pqEncodingPlain)
+		chunk.i32(pqEncodingRLE)
+		chunk.listField(3, thriftBinary, 1)
+		chunk.binary([]byte(c.Name))
+		chunk.i32Field(4, pqCodecUncompressed)
+		chunk.i64Field(5, int64(len(rows)))
+		chunk.i64Field(6, size)
+		chunk.i64Field(7, size)
+		chunk.i64Field(9, offset)
+		chunk.endStruct()
+		chunk.stop()
+		chunks[i] = chunk
+	}
+
+	meta := &thriftCompact{}
+	meta.i32Field(1, 1)
+	meta.listField(2, thriftStruct, len(cols)+1)
+	meta.elem()
+	meta.binaryField(4, []byte("schema"))
+	meta.i32Field(5, int32(len(cols)))
+	meta.stop()
+	for _, c := range cols {
+		typ := parquetType(c.Type)
+		meta.elem()
+		meta.i32Field(1, typ)
+		meta.i32Field(3, pqRepetitionOptional)
+		meta.binaryField(4, []byte(c.Name))
+		if typ == pqTypeByteArray {
+			meta.i32Field(6, pqConvertedUTF8)
+		}
+		meta.stop()
+	}
+	meta.i64Field(3, int64(len(rows)))
+	meta.listField(4, thriftStruct, 1)
+	meta.elem()
+	meta.listField(1, thriftStruct, len(chunks))
+	for _, chunk := range chunks {
+		meta.Write(chunk.Bytes())
+	}
+	meta.i64Field(2, groupSize)
+	meta.i64Field(3, int64(len(rows)))
+	meta.stop()
+	meta.binaryField(6, []byte("qri"))
+	meta.stop()
+
+	buf.Write(meta.Bytes())
+	binary.Write(buf, binary.LittleEndian, int32(meta.Len()))
+	buf.WriteString(parquetMagic)
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// parquetType maps a warehouse column type to a parquet physical type
+func parquetType(colType string) int32 {
+	switch colType {
+	case warehouse.ColumnInteger:
+		return pqTypeInt64
+	case warehouse.ColumnNumber:
+		return pqTypeDouble
+	case warehouse.ColumnBoolean:
+		return pqTypeBoolean
+	default:
+		return pqTypeByteArray
+	}
+}
+
+// parquetPage encodes column col of rows as the body of a data page:
+// definition levels followed by PLAIN encoded non-null values
+func parquetPage(c warehouse.Column, rows [][]interface{}, col int) []byte {
+	levels := make([]bool, len(rows))
+	values := &bytes.Buffer{}
+	var bits []bool
+	for i, row := range rows {
+		v := row[col]
+		if v == nil {
+			continue
+		}
+		levels[i] = true
+		switch parquetType(c.Type) {
+		case pqTypeInt64:
+			var n int64
+			switch x := v.(type) {
+			case int64:
+				n = x
+			case float64:
+				n = int64(x)
+			case int:
+				n = int64(x)
+			}
+			binary.Write(values, binary.LittleEndian, n)
+		case pqTypeDouble:
+			var f float64
+			switch x := v.(type) {
+			case float64:
+				f = x
+			case int64:
+				f = float64(x)
+			case int:
+				f = float64(x)
+			}
+			binary.Write(values, binary.LittleEndian, math.Float64bits(f))
+		case pqTypeBoolean:
+			b, _ := v.(bool)
+			bits = append(bits, b)
+		default:
+			s, ok := v.(string)
+			if !ok {
+				s = fmt.Sprintf("%v", v)
+			}
+			binary.Write(values, binary.LittleEndian, int32(len(s)))
+			values.WriteString(s)
+		}
+	}
+	if bits != nil {
+		packed := make([]byte, (len(bits)+7)/8)
+		for i, b := range bits {
+			if b {
+				packed[i/8] |= 1 << uint(i%8)
+			}
+		}
+		values.Write(packed)
+	}
+
+	page := &bytes.Buffer{}
+	rle := rleLevels(levels)
+	binary.Write(page, binary.LittleEndian, int32(len(rle)))
+	page.Write(rle)
+	page.Write(values.Bytes())
+	return page.Bytes()
+}
+
+// rleLevels encodes definition levels with a bit width of one as runs of the
+// RLE / bit-packing hybrid encoding
+func rleLevels(levels []bool) []byte {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		writeUvarint(buf, uint64(j-i)<<1)
+		if levels[i] {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		i = j
+	}
+	return buf.Bytes()
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	tmp := make([]byte, binary.MaxVarintLen64)
+	buf.Write(tmp[:binary.PutUvarint(tmp, v)])
+}
+
+// thrift compact protocol type identifiers
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftCompact is a minimal writer for the thrift compact protocol, which
+// parquet uses for page headers & file metadata. Structs nested with
+// structField must be closed with endStruct. Struct list elements begin with
+// elem, and they & the top-level struct are terminated with stop
+type thriftCompact struct {
+	bytes.Buffer
+	lastField []int16
+}
+
+func (t *thriftCompact) field(id int16, typ byte) {
+	if len(t.lastField) == 0 {
+		t.lastField = []int16{0}
+	}
+	last := &t.lastField[len(t.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.WriteByte(typ)
+		writeUvarint(&t.Buffer, zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (t *thriftCompact) i32(v int32) {
+	writeUvarint(&t.Buffer, zigzag(int64(v)))
+}
+
+func (t *thriftCompact) binary(b []byte) {
+	writeUvarint(&t.Buffer, uint64(len(b)))
+	t.Write(b)
+}
+
+func (t *thriftCompact) i32Field(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.i32(v)
+}
+
+func (t *thriftCompact) i64Field(id int16, v int64) {
+	t.field(id, thriftI64)
+	writeUvarint(&t.Buffer, zigzag(v))
+}
+
+func (t *thriftCompact) binaryField(id int16, b []byte) {
+	t.field(id, thriftBinary)
+	t.binary(b)
+}
+
+// listField writes a list header. list elements are written directly after
+func (t *thriftCompact) listField(id int16, elemType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.WriteByte(0xf0 | elemType)
+		writeUvarint(&t.Buffer, uint64(size))
+	}
+}
+
+// elem begins a struct list element
+func (t *thriftCompact) elem() {
+	t.lastField = append(t.lastField, 0)
+}
+
+func (t *thriftCompact) structField(id int16) {
+	t.field(id, thriftStruct)
+	t.lastField = append(t.lastField, 0)
+}
+
+// endStruct closes a struct opened with structField
+func (t *thriftCompact) endStruct() {
+	t.stop()
+}
+
+// stop terminates the current struct
+func (t *thriftCompact) stop() {
+	t.WriteByte(0)
+	if len(t.lastField) > 0 {
+		t.lastField = t.lastField[:len(t.lastField)-1]
+	}
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
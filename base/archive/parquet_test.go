@@ -0,0 +1,68 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteParquet(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteParquet(exportTestDataset(), buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	if len(data) < 12 {
+		t.Fatalf("expected a parquet file, got %d bytes", len(data))
+	}
+	if string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Errorf("expected file to open & close with %q", parquetMagic)
+	}
+	metaLen := int(binary.LittleEndian.Uint32(data[len(data)-8 : len(data)-4]))
+	if metaLen <= 0 || metaLen > len(data)-12 {
+		t.Fatalf("invalid footer length: %d", metaLen)
+	}
+	meta := data[len(data)-8-metaLen : len(data)-8]
+	for _, name := range []string{"movie", "year", "schema"} {
+		if !bytes.Contains(meta, []byte(name)) {
+			t.Errorf("expected footer to contain column %q", name)
+		}
+	}
+	if !bytes.Contains(data, []byte("don't look up")) {
+		t.Error("expected file to contain string values")
+	}
+}
+
+func TestThriftCompact(t *testing.T) {
+	tc := &thriftCompact{}
+	tc.i32Field(1, 1)
+	tc.structField(3)
+	tc.i64Field(1, -1)
+	tc.endStruct()
+	tc.binaryField(20, []byte("a"))
+	tc.stop()
+
+	expect := []byte{
+		0x15, 0x02, // field 1, i32, zigzag(1)
+		0x2c,       // field 3 (delta 2), struct
+		0x16, 0x01, // field 1, i64, zigzag(-1)
+		0x00,             // end struct
+		0x08, 0x28, 0x01, // field 20 (long form), binary, zigzag(20), length 1
+		'a',
+		0x00, // stop
+	}
+	if diff := cmp.Diff(expect, tc.Bytes()); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRLELevels(t *testing.T) {
+	got := rleLevels([]bool{true, true, false, true})
+	expect := []byte{0x04, 0x01, 0x02, 0x00, 0x02, 0x01}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -39,7 +39,7 @@ var (
 	// a Filestore to open a file. Some filestores (like IPFS) fallback to a
 	// network request when it can't find a file locally. Setting a short timeout
 	// prevents waiting for a slow network response, at the expense of leaving
-	// files unresolved.
-	// TODO (b5) - allow -1 duration as a sentinel value for no timeout
+	// files unresolved. Per-filesystem timeouts set with dsfs.WithOpenTimeouts
+	// take precedence. Set to dsfs.NoTimeout to disable
 	OpenFileTimeoutDuration = time.Millisecond * 250
 )
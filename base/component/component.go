@@ -56,7 +56,7 @@ func ConvertDatasetToComponents(ds *dataset.Dataset, qfilesys qfs.Filesystem) Co
 	if ds.Readme != nil {
 		rc := ReadmeComponent{Resolver: qfilesys}
 		rc.Value = ds.Readme
-		rc.Format = "md"
+		rc.Format = readmeFileFormat(ds.Readme.Format)
 		dc.Subcomponents["readme"] = &rc
 	}
 	if ds.Transform != nil {
@@ -86,6 +86,16 @@ func ConvertDatasetToComponents(ds *dataset.Dataset, qfilesys qfs.Filesystem) Co
 	return &dc
 }
 
+// readmeFileFormat returns the file format to write a readme with, readmes in
+// formats without a known file extension are written as markdown
+func readmeFileFormat(format string) string {
+	switch format {
+	case "html", "ipynb":
+		return format
+	}
+	return "md"
+}
+
 // ToDataset converts a component to a dataset. Should only be used on a
 // component representing an entire dataset.
 func ToDataset(comp Component) (*dataset.Dataset, error) {
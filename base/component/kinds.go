@@ -631,6 +631,11 @@ func (rc *ReadmeComponent) LoadAndFill(ds *dataset.Dataset) error {
 		if err := fill.Struct(fields, rc.Value); err != nil {
 			return err
 		}
+		// readme files are stored as script bytes, the file extension is the
+		// readme format
+		if rc.Value.Format == "" && rc.Format != "json" && rc.Format != "yaml" {
+			rc.Value.Format = rc.Format
+		}
 	}
 	rc.Base().IsLoaded = true
 
@@ -781,7 +786,7 @@ func (bc *BaseComponent) LoadFile() (map[string]interface{}, error) {
 			return nil, err
 		}
 		return fields, nil
-	case "html", "md", "star":
+	case "html", "ipynb", "md", "star":
 		fields["ScriptBytes"] = data
 		return fields, nil
 	}
@@ -208,7 +208,7 @@ func assignField(target Component, componentName string, parent Component) Compo
 func GetKnownFilenames() map[string][]string {
 	componentExtensionTypes := []string{".json", ".yml", ".yaml"}
 	bodyExtensionTypes := []string{".csv", ".json", ".cbor", ".xlsx"}
-	readmeExtensionTypes := []string{".md", ".html"}
+	readmeExtensionTypes := []string{".md", ".html", ".ipynb"}
 	return map[string][]string{
 		"dataset":   componentExtensionTypes,
 		"commit":    componentExtensionTypes,
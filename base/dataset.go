@@ -78,7 +78,7 @@ func OpenDataset(ctx context.Context, fsys qfs.Filesystem, ds *dataset.Dataset)
 	}
 
 	if ds.Viz != nil && ds.Viz.RenderedFile() == nil {
-		vizRenderedTimeoutCtx, cancel := context.WithTimeout(ctx, OpenFileTimeoutDuration)
+		vizRenderedTimeoutCtx, cancel := dsfs.OpenTimeoutContext(ctx, ds.Viz.RenderedPath, OpenFileTimeoutDuration)
 		defer cancel()
 
 		if err = ds.Viz.OpenRenderedFile(vizRenderedTimeoutCtx, fsys); err != nil {
@@ -102,7 +102,7 @@ func isMerkleDagError(err error) bool {
 
 func openReadme(ctx context.Context, fsys qfs.Filesystem, ds *dataset.Dataset) error {
 	if ds.Readme != nil && ds.Readme.ScriptFile() == nil {
-		readmeTimeoutCtx, cancel := context.WithTimeout(ctx, OpenFileTimeoutDuration)
+		readmeTimeoutCtx, cancel := dsfs.OpenTimeoutContext(ctx, ds.Readme.ScriptPath, OpenFileTimeoutDuration)
 		defer cancel()
 
 		if err := ds.Readme.OpenScriptFile(readmeTimeoutCtx, fsys); err != nil {
@@ -216,9 +216,11 @@ func ListDatasets(ctx context.Context, r repo.Repo, term, profileID string, offs
 		}
 
 		if ref.Path != "" {
-			ds, err := dsfs.LoadDataset(ctx, fs, ref.Path)
+			// list with whatever components are available, a missing readme or
+			// viz block shouldn't hide a dataset
+			ds, _, err := dsfs.LoadDatasetPartial(ctx, fs, ref.Path)
 			if err != nil {
-				if strings.Contains(err.Error(), "not found") {
+				if errors.Is(err, dsfs.ErrNotLocal) || strings.Contains(err.Error(), "not found") {
 					ref.Foreign = true
 					err = nil
 					continue
@@ -1,19 +1,23 @@
 package base
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"path/filepath"
 	"strings"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/detect"
 	"github.com/qri-io/dataset/validate"
+	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/dsref"
 	qerr "github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/stats/infer"
 )
 
 // PrepareSaveRef works out a dataset reference for saving a dataset version.
@@ -32,7 +36,27 @@ func PrepareSaveRef(
 	bodyPathNameHint string,
 	wantNewName bool,
 ) (dsref.Ref, bool, error) {
-	log.Debugf("PrepareSaveRef refStr=%q bodyPathNameHint=%q wantNewName=%t", refStr, bodyPathNameHint, wantNewName)
+	ref, isNew, err := PreviewSaveRef(ctx, pro, resolver, refStr, bodyPathNameHint, wantNewName)
+	if err != nil || !isNew {
+		return ref, isNew, err
+	}
+
+	ref.InitID, err = book.WriteDatasetInit(ctx, ref.Name)
+	log.Debugf("PrepareSaveRef created new initID=%q ref.Username=%q ref.Name=%q", ref.InitID, ref.Username, ref.Name)
+	return ref, true, err
+}
+
+// PreviewSaveRef works out a dataset reference for saving like PrepareSaveRef,
+// without writing anything. References to new datasets have no InitID
+func PreviewSaveRef(
+	ctx context.Context,
+	pro *profile.Profile,
+	resolver dsref.Resolver,
+	refStr string,
+	bodyPathNameHint string,
+	wantNewName bool,
+) (dsref.Ref, bool, error) {
+	log.Debugf("PreviewSaveRef refStr=%q bodyPathNameHint=%q wantNewName=%t", refStr, bodyPathNameHint, wantNewName)
 
 	var badCaseErr error
 
@@ -95,7 +119,7 @@ func PrepareSaveRef(
 		}
 
 		// we have a valid previous reference & an initID, return!
-		log.Debugw("PrepareSaveRef found previous initID", "initID", ref.InitID, "path", ref.Path)
+		log.Debugw("PreviewSaveRef found previous initID", "initID", ref.InitID, "path", ref.Path)
 		return ref, false, nil
 	}
 
@@ -107,10 +131,7 @@ func PrepareSaveRef(
 	if !dsref.IsValidName(ref.Name) {
 		return ref, true, fmt.Errorf("invalid dataset name: %s", ref.Name)
 	}
-
-	ref.InitID, err = book.WriteDatasetInit(ctx, ref.Name)
-	log.Debugf("PrepareSaveRef created new initID=%q ref.Username=%q ref.Name=%q", ref.InitID, ref.Username, ref.Name)
-	return ref, true, err
+	return ref, true, nil
 }
 
 // GenerateAvailableName creates a name for the dataset that is not currently in
@@ -150,6 +171,33 @@ func InferValues(pro *profile.Profile, ds *dataset.Dataset) error {
 	return nil
 }
 
+// InferSchema replaces the column types of a dataset's inferred schema with
+// types determined by sampling the body. Datasets without a body, or with a
+// body format that can't be sampled keep the schema detected by InferValues
+func InferSchema(ds *dataset.Dataset, cfg infer.Config) error {
+	if !cfg.Enabled() || !infer.Supported(ds.Structure) {
+		return nil
+	}
+	file := ds.BodyFile()
+	if file == nil {
+		return nil
+	}
+
+	// sampling consumes the body, buffer it so it can be read again on write
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes(file.FileName(), data))
+
+	prov, err := infer.Structure(ds.Structure, bytes.NewReader(data), cfg)
+	if err != nil {
+		return err
+	}
+	log.Debugw("inferred schema", "sampler", prov.Sampler, "rowsSampled", prov.RowsSampled, "rowsTotal", prov.RowsTotal)
+	return nil
+}
+
 // ValidateDataset checks that a dataset is semantically valid
 func ValidateDataset(ds *dataset.Dataset) (err error) {
 	// Ensure that dataset structure is valid
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/stats/infer"
 )
 
 func TestPrepareSaveRef(t *testing.T) {
@@ -146,6 +148,41 @@ func TestInferValuesDontOverwriteSchema(t *testing.T) {
 	}
 }
 
+func TestInferSchema(t *testing.T) {
+	r := newTestRepo(t)
+	pro := r.Profiles().Owner()
+
+	// the "count" column is empty until the last row
+	body := "name,id,count\n"
+	for i := 0; i < 100; i++ {
+		body += fmt.Sprintf("row_%d,%d,\n", i, i)
+	}
+	body += "last,100,42\n"
+
+	ds := &dataset.Dataset{Name: "sparse"}
+	ds.SetBodyFile(qfs.NewMemfileBytes("sparse.csv", []byte(body)))
+	if err := InferValues(pro, ds); err != nil {
+		t.Fatal(err)
+	}
+	if err := InferSchema(ds, infer.Config{Sampler: infer.SamplerFull}); err != nil {
+		t.Fatal(err)
+	}
+
+	actual := datasetSchemaToJSON(ds)
+	expect := `{"items":{"items":[{"title":"name","type":"string"},{"title":"id","type":"integer"},{"title":"count","type":"integer"}],"type":"array"},"type":"array","x-qri-inference":{"rowsSampled":101,"rowsTotal":101,"sampler":"full"}}`
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Errorf("schema mismatch (-want +got):\n%s", diff)
+	}
+
+	data, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != body {
+		t.Errorf("expected body to be readable after inference")
+	}
+}
+
 func TestMaybeAddDefaultViz(t *testing.T) {
 	ds := &dataset.Dataset{
 		Name: "animals",
@@ -0,0 +1,288 @@
+package dsfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qfs/muxfs"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+var (
+	// BodyVerifyInterval is the delay between checks of recently saved bodies
+	BodyVerifyInterval = time.Hour * 6
+	// BodyVerifyRecent is the number of recently saved large bodies a
+	// BodyVerifier checks
+	BodyVerifyRecent = 20
+)
+
+// BodyCheck is the result of recomputing the checksum of a dataset body.
+// Bodies too big to diff are compared by checksum when saving & reporting
+// status, a checksum that no longer matches the stored body means the body
+// is corrupt
+type BodyCheck struct {
+	Ref      string `json:"ref,omitempty"`
+	Path     string `json:"path"`
+	BodyPath string `json:"bodyPath,omitempty"`
+	// Checksum is the checksum recorded in the dataset structure
+	Checksum string `json:"checksum,omitempty"`
+	// Computed is the checksum of the stored body. Computed is empty when the
+	// body is stored on a filesystem that didn't generate the recorded checksum
+	Computed string `json:"computed,omitempty"`
+	// Length is the body size recorded in the dataset structure
+	Length int `json:"length"`
+	// ComputedLength is the number of bytes read from the stored body
+	ComputedLength int       `json:"computedLength"`
+	Status         string    `json:"status"`
+	Error          string    `json:"error,omitempty"`
+	Checked        time.Time `json:"checked,omitempty"`
+}
+
+// VerifyBody reads the body of a dataset version, comparing its checksum &
+// length to those recorded in the version structure. Checksums are
+// recomputed by adding the body to fs, which doesn't duplicate data on a
+// content-addressed filesystem that already stores the body
+func VerifyBody(ctx context.Context, fs qfs.Filesystem, path string) BodyCheck {
+	bc := BodyCheck{Path: path, Status: VerifyOK, Checked: time.Now()}
+
+	local, err := hasLocal(ctx, fs, PackageFilepath(fs, path, PackageFileDataset))
+	if err != nil {
+		bc.Status, bc.Error = VerifyInvalid, err.Error()
+		return bc
+	}
+	if !local {
+		bc.Status = VerifyRemote
+		return bc
+	}
+	ds, err := LoadDataset(ctx, fs, path)
+	if err != nil {
+		bc.Status, bc.Error = VerifyInvalid, err.Error()
+		return bc
+	}
+	if ds.Structure == nil || ds.BodyPath == "" {
+		return bc
+	}
+	bc.BodyPath = ds.BodyPath
+	bc.Checksum = ds.Structure.Checksum
+	bc.Length = ds.Structure.Length
+
+	if local, err := hasLocal(ctx, fs, ds.BodyPath); err != nil {
+		bc.Status, bc.Error = VerifyInvalid, err.Error()
+		return bc
+	} else if !local {
+		bc.Status = VerifyRemote
+		return bc
+	}
+
+	f, err := fs.Get(ctx, ds.BodyPath)
+	if err != nil {
+		bc.Status, bc.Error = VerifyInvalid, err.Error()
+		return bc
+	}
+	defer f.Close()
+	cr := &countingReader{r: f}
+
+	// the recorded checksum of a body saved to a content-addressed filesystem
+	// is the body path. re-adding the body to a filesystem of the same type
+	// recomputes it
+	writeFS := fs
+	if mux, ok := fs.(*muxfs.Mux); ok {
+		writeFS = mux.DefaultWriteFS()
+	}
+	if _, ok := writeFS.(qfs.CAFS); ok && bc.Checksum != "" && pathFilesystem(bc.Checksum) == writeFS.Type() {
+		bc.Computed, err = writeFS.Put(ctx, qfs.NewMemfileReader(filepath.Base(ds.BodyPath), cr))
+	} else {
+		_, err = io.Copy(ioutil.Discard, cr)
+	}
+	if err != nil {
+		bc.Status, bc.Error = VerifyInvalid, fmt.Sprintf("reading body: %s", err)
+		return bc
+	}
+	bc.ComputedLength = cr.n
+
+	switch {
+	case bc.Computed != "" && bc.Computed != bc.Checksum:
+		bc.Status, bc.Error = VerifyInvalid, "body checksum doesn't match the recorded checksum"
+	case bc.Length != 0 && bc.ComputedLength != bc.Length:
+		bc.Status, bc.Error = VerifyInvalid, fmt.Sprintf("body is %d bytes, expected %d", bc.ComputedLength, bc.Length)
+	}
+	return bc
+}
+
+// pathFilesystem returns the filesystem prefix of a path, eg: "ipfs" for
+// "/ipfs/QmFoo"
+func pathFilesystem(path string) string {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0]
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += n
+	return n, err
+}
+
+// BodyVerifier periodically checks the bodies of recently saved large dataset
+// versions, publishing an ETDatasetBodyCorrupt event for each body that no
+// longer matches its checksum. Tracked versions & check results are persisted
+// as JSON to filename, an empty filename keeps them in memory
+type BodyVerifier struct {
+	file jsonfile.File
+	fs   qfs.Filesystem
+	pub  event.Publisher
+
+	lk sync.Mutex
+	// checks holds tracked versions, most recently saved first
+	checks []*BodyCheck
+}
+
+// NewBodyVerifier creates a body verifier, loading any checks persisted to
+// filename
+func NewBodyVerifier(filename string, fs qfs.Filesystem, pub event.Publisher) (*BodyVerifier, error) {
+	v := &BodyVerifier{
+		file:   jsonfile.New(filename),
+		fs:     fs,
+		pub:    pub,
+		checks: []*BodyCheck{},
+	}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// HandleSaveEvent tracks versions created by ETDatasetSaveCompleted events.
+// Only bodies too big to diff are tracked
+func (v *BodyVerifier) HandleSaveEvent(ctx context.Context, e event.Event) error {
+	p, ok := e.Payload.(event.DsSaveEvent)
+	if !ok || p.Error != nil || p.Path == "" {
+		return nil
+	}
+	if err := v.Track(ctx, fmt.Sprintf("%s/%s", p.Username, p.Name), p.Path); err != nil {
+		log.Debugw("tracking saved body", "path", p.Path, "err", err)
+	}
+	return nil
+}
+
+// Track adds a version to the set of checked bodies if the version body is too
+// big to diff. The oldest tracked version is dropped once BodyVerifyRecent
+// versions are tracked
+func (v *BodyVerifier) Track(ctx context.Context, ref, path string) error {
+	ds, err := LoadDataset(ctx, v.fs, path)
+	if err != nil {
+		return err
+	}
+	if ds.Structure == nil || ds.BodyPath == "" || ds.Structure.Length <= BodySizeSmallEnoughToDiff {
+		return nil
+	}
+
+	v.lk.Lock()
+	defer v.lk.Unlock()
+	checks := []*BodyCheck{{Ref: ref, Path: path}}
+	for _, bc := range v.checks {
+		// a new version replaces older versions of the same dataset
+		if bc.Ref != ref && len(checks) < BodyVerifyRecent {
+			checks = append(checks, bc)
+		}
+	}
+	v.checks = checks
+	return v.save()
+}
+
+// Checks returns the result of the last check of each tracked version, most
+// recently saved first. Versions that haven't been checked have a zero
+// Checked time
+func (v *BodyVerifier) Checks() []BodyCheck {
+	v.lk.Lock()
+	defer v.lk.Unlock()
+	res := make([]BodyCheck, len(v.checks))
+	for i, bc := range v.checks {
+		res[i] = *bc
+	}
+	return res
+}
+
+// VerifyAll checks every tracked body, returning the results
+func (v *BodyVerifier) VerifyAll(ctx context.Context) []BodyCheck {
+	tracked := v.Checks()
+	res := make([]BodyCheck, 0, len(tracked))
+	for _, t := range tracked {
+		bc := VerifyBody(ctx, v.fs, t.Path)
+		bc.Ref = t.Ref
+		if bc.Status == VerifyInvalid {
+			log.Errorw("dataset body is corrupt", "ref", bc.Ref, "path", bc.Path, "err", bc.Error)
+			if v.pub != nil {
+				err := v.pub.Publish(ctx, event.ETDatasetBodyCorrupt, event.DsBodyCorrupt{
+					Ref:      bc.Ref,
+					Path:     bc.Path,
+					BodyPath: bc.BodyPath,
+					Checksum: bc.Checksum,
+					Computed: bc.Computed,
+					Error:    bc.Error,
+				})
+				if err != nil {
+					log.Debugw("publishing body corrupt event", "err", err)
+				}
+			}
+		}
+		res = append(res, bc)
+	}
+
+	v.lk.Lock()
+	defer v.lk.Unlock()
+	for _, bc := range res {
+		for i, cur := range v.checks {
+			if cur.Path == bc.Path {
+				c := bc
+				v.checks[i] = &c
+			}
+		}
+	}
+	if err := v.save(); err != nil {
+		log.Debugw("saving body checks", "err", err)
+	}
+	return res
+}
+
+// Run checks tracked bodies every BodyVerifyInterval until the context is
+// cancelled
+func (v *BodyVerifier) Run(ctx context.Context) {
+	t := time.NewTicker(BodyVerifyInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			v.VerifyAll(ctx)
+		}
+	}
+}
+
+func (v *BodyVerifier) load() error {
+	if err := v.file.Load(&v.checks); err != nil {
+		return fmt.Errorf("reading body checks: %w", err)
+	}
+	return nil
+}
+
+// save writes tracked checks. must be called with the lock held
+func (v *BodyVerifier) save() error {
+	return v.file.Save(v.checks)
+}
@@ -0,0 +1,137 @@
+package dsfs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/event"
+)
+
+// corruptBodyFS returns different bytes for one path, standing in for a body
+// that's been damaged on disk
+type corruptBodyFS struct {
+	*qfs.MemFS
+	bodyPath string
+}
+
+func (fs corruptBodyFS) Get(ctx context.Context, path string) (qfs.File, error) {
+	if path == fs.bodyPath {
+		return qfs.NewMemfileBytes("body.json", []byte(`[1,2,3,4]`)), nil
+	}
+	return fs.MemFS.Get(ctx, path)
+}
+
+func TestVerifyBody(t *testing.T) {
+	ctx := context.Background()
+	fs := qfs.NewMemFS()
+	privKey := testkeys.GetKeyData(10).PrivKey
+
+	ds := &dataset.Dataset{
+		Commit:    &dataset.Commit{Title: "initial commit"},
+		Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("/body.json", []byte(`[1,2,3]`)))
+	path, err := CreateDataset(ctx, fs, fs, event.NilBus, ds, nil, privKey, SaveSwitches{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bc := VerifyBody(ctx, fs, path)
+	if bc.Status != VerifyOK {
+		t.Fatalf("expected intact body to verify. got status %q: %s", bc.Status, bc.Error)
+	}
+	if bc.ComputedLength != bc.Length {
+		t.Errorf("expected computed length %d to match recorded length %d", bc.ComputedLength, bc.Length)
+	}
+
+	bad := corruptBodyFS{MemFS: fs, bodyPath: bc.BodyPath}
+	if bc := VerifyBody(ctx, bad, path); bc.Status != VerifyInvalid {
+		t.Errorf("expected corrupt body to be invalid, got status %q", bc.Status)
+	}
+
+	if bc := VerifyBody(ctx, fs, "/mem/QmMissing"); bc.Status != VerifyRemote {
+		t.Errorf("expected missing version to be remote, got status %q", bc.Status)
+	}
+}
+
+func TestBodyVerifier(t *testing.T) {
+	prevSize := BodySizeSmallEnoughToDiff
+	BodySizeSmallEnoughToDiff = 4
+	defer func() { BodySizeSmallEnoughToDiff = prevSize }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fs := qfs.NewMemFS()
+	privKey := testkeys.GetKeyData(10).PrivKey
+
+	save := func(body string) string {
+		ds := &dataset.Dataset{
+			Commit:    &dataset.Commit{Title: "commit"},
+			Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+		}
+		ds.SetBodyFile(qfs.NewMemfileBytes("/body.json", []byte(body)))
+		path, err := CreateDataset(ctx, fs, fs, event.NilBus, ds, nil, privKey, SaveSwitches{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	small := save(`[1]`)
+	first := save(`[1,2,3]`)
+	second := save(`[4,5,6]`)
+
+	bus := event.NewBus(ctx)
+	corrupt := []event.DsBodyCorrupt{}
+	bus.SubscribeTypes(func(ctx context.Context, e event.Event) error {
+		corrupt = append(corrupt, e.Payload.(event.DsBodyCorrupt))
+		return nil
+	}, event.ETDatasetBodyCorrupt)
+
+	v, err := NewBodyVerifier("", fs, bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Track(ctx, "me/small", small); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Checks()) != 0 {
+		t.Errorf("expected body small enough to diff not to be tracked")
+	}
+	if err := v.Track(ctx, "me/big", first); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Track(ctx, "me/big", second); err != nil {
+		t.Fatal(err)
+	}
+	checks := v.Checks()
+	if len(checks) != 1 || checks[0].Path != second {
+		t.Fatalf("expected new version to replace the previous one, got: %#v", checks)
+	}
+	if !checks[0].Checked.IsZero() {
+		t.Errorf("expected tracked version to be unchecked")
+	}
+
+	if res := v.VerifyAll(ctx); len(res) != 1 || res[0].Status != VerifyOK {
+		t.Fatalf("expected intact body to verify, got: %#v", res)
+	}
+	if len(corrupt) != 0 {
+		t.Errorf("expected no corrupt body events, got %d", len(corrupt))
+	}
+
+	bc := VerifyBody(ctx, fs, second)
+	v.fs = corruptBodyFS{MemFS: fs, bodyPath: bc.BodyPath}
+	res := v.VerifyAll(ctx)
+	if len(res) != 1 || res[0].Status != VerifyInvalid || res[0].Ref != "me/big" {
+		t.Fatalf("expected corrupt body to be invalid, got: %#v", res)
+	}
+	if len(corrupt) != 1 || corrupt[0].Path != second {
+		t.Errorf("expected one corrupt body event for %s, got: %#v", second, corrupt)
+	}
+	if checks := v.Checks(); checks[0].Status != VerifyInvalid || checks[0].Checked.IsZero() {
+		t.Errorf("expected check result to be recorded, got: %#v", checks[0])
+	}
+}
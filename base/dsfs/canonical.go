@@ -0,0 +1,140 @@
+package dsfs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/qfs"
+)
+
+// CanonicalJSON encodes v as canonical JSON: object keys are sorted, there's no
+// insignificant whitespace or HTML escaping, integers are written without a
+// fraction or exponent, and other numbers use the shortest representation that
+// round-trips, switching to exponent notation outside [1e-6, 1e21). Equal
+// values always produce equal bytes, no matter which implementation or map
+// ordering produced them
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var val interface{}
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := writeCanonical(buf, val); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// IsCanonicalJSON reports whether data is canonically encoded JSON
+func IsCanonicalJSON(data []byte) bool {
+	canon, err := CanonicalJSON(json.RawMessage(data))
+	return err == nil && bytes.Equal(canon, data)
+}
+
+// CanonicalJSONFile creates a file from the canonical encoding of a
+// json.Marshaler
+func CanonicalJSONFile(name string, m json.Marshaler) (qfs.File, error) {
+	data, err := CanonicalJSON(m)
+	if err != nil {
+		log.Debug(err.Error())
+		return nil, err
+	}
+	return qfs.NewMemfileBytes(name, data), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case string:
+		writeCanonicalString(buf, x)
+	case json.Number:
+		s, err := canonicalNumber(x)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, el := range x {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, el); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, x[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected type %T in canonical JSON", v)
+	}
+	return nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	// encoding a string can't fail
+	enc.Encode(s)
+	// drop the newline Encode appends
+	buf.Truncate(buf.Len() - 1)
+}
+
+// canonicalNumber formats a number. Integers that fit in 64 bits are written
+// as is, so large IDs & counts keep their precision
+func canonicalNumber(n json.Number) (string, error) {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return strconv.FormatInt(i, 10), nil
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return strconv.FormatUint(u, 10), nil
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid number %q: %w", n, err)
+	}
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("number %q can't be represented in JSON", n)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	// 1e+06 -> 1e+6, 1e-07 -> 1e-7
+	mant, exp := s[:strings.IndexByte(s, 'e')+2], s[strings.IndexByte(s, 'e')+2:]
+	return mant + strings.TrimLeft(exp, "0"), nil
+}
@@ -0,0 +1,85 @@
+package dsfs
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/event"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	cases := []struct {
+		in, expect string
+	}{
+		{`{"b":1,"a":[true,null,"x"]}`, `{"a":[true,null,"x"],"b":1}`},
+		{`{ "z" : { "y": 2, "x": 1 } }`, `{"z":{"x":1,"y":2}}`},
+		{`[1.0, 1e3, -0, 0.5, 1.50]`, `[1,1000,0,0.5,1.5]`},
+		{`[1e21, 1.5e-7, 0.000001, 123456789012345678]`, `[1e+21,1.5e-7,0.000001,123456789012345678]`},
+		{`[18446744073709551615]`, `[18446744073709551615]`},
+		{`["<a & b>", "é"]`, `["<a & b>","é"]`},
+	}
+	for _, c := range cases {
+		got, err := CanonicalJSON(json.RawMessage(c.in))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", c.in, err)
+			continue
+		}
+		if string(got) != c.expect {
+			t.Errorf("%s: result mismatch.\nwant: %s\ngot:  %s", c.in, c.expect, got)
+		}
+		if !IsCanonicalJSON(got) {
+			t.Errorf("%s: expected result to be canonical", c.in)
+		}
+	}
+
+	if IsCanonicalJSON([]byte(`{"b":1,"a":2}`)) {
+		t.Error("expected unsorted keys not to be canonical")
+	}
+}
+
+func TestCreateDatasetCanonical(t *testing.T) {
+	ctx := context.Background()
+	fs := qfs.NewMemFS()
+	privKey := testkeys.GetKeyData(10).PrivKey
+
+	ds := &dataset.Dataset{
+		Commit:    &dataset.Commit{Title: "canonical"},
+		Meta:      &dataset.Meta{Title: "movies", Keywords: []string{"film"}, Description: "<durations>"},
+		Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("/body.json", []byte(`[1,2,3]`)))
+
+	path, err := CreateDataset(ctx, fs, fs, event.NilBus, ds, nil, privKey, SaveSwitches{Canonical: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, err := LoadDatasetRefs(ctx, fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := map[string]string{
+		"dataset":   PackageFilepath(fs, path, PackageFileDataset),
+		"commit":    refs.Commit.Path,
+		"meta":      refs.Meta.Path,
+		"structure": refs.Structure.Path,
+	}
+	for name, p := range paths {
+		f, err := fs.Get(ctx, p)
+		if err != nil {
+			t.Fatalf("getting %s: %s", name, err)
+		}
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsCanonicalJSON(data) {
+			t.Errorf("expected %s component to be canonical, got: %s", name, data)
+		}
+	}
+}
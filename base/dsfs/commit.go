@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"time"
 
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
@@ -90,6 +91,12 @@ func commitFileAddFunc(privKey crypto.PrivKey, pub event.Publisher) addWriteFile
 					log.Debugf("ensureCommitTitleAndMessage: %s", err)
 					return nil, fmt.Errorf("error saving: %w", err)
 				}
+				if cff.sw.CommitNote != "" {
+					cff.ds.Commit.Message = strings.TrimSpace(cff.ds.Commit.Message + "\n\n" + cff.sw.CommitNote)
+				}
+				if cff.sw.RecordEnvironment {
+					cff.ds.Commit.Message = strings.TrimSpace(cff.ds.Commit.Message + "\n\n" + CaptureEnvironment(cff.ds).String())
+				}
 			}
 
 			replaceComponentsWithRefs(ds, added, wfs.body.FullPath())
@@ -100,7 +107,7 @@ func commitFileAddFunc(privKey crypto.PrivKey, pub event.Publisher) addWriteFile
 				return nil, fmt.Errorf("error signing commit title: %w", err)
 			}
 			ds.Commit.Signature = base64.StdEncoding.EncodeToString(signedBytes)
-			return JSONFile(PackageFileCommit.Filename(), ds.Commit)
+			return wfs.jsonFile(PackageFileCommit.Filename(), ds.Commit)
 		}
 
 		wfs.commit = qfs.NewWriteHookFile(emptyFile(PackageFileCommit.Filename()), hook, filePaths(wfs.files())...)
@@ -15,6 +15,7 @@ import (
 	"github.com/qri-io/jsonschema"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/stats/binning"
 )
 
 type computeFieldsFile struct {
@@ -28,6 +29,8 @@ type computeFieldsFile struct {
 
 	// body statistics accumulator
 	acc *dsstats.Accumulator
+	// binner reshapes accumulated histograms & frequency counts
+	binner *binning.Binner
 
 	// buffer of entries for diffing small datasets. will be set to nil if
 	// body reads more than BodySizeSmallEnoughToDiff bytes
@@ -151,7 +154,7 @@ type statsComponentFile interface {
 func (cff *computeFieldsFile) StatsComponent() (*dataset.Stats, error) {
 	return &dataset.Stats{
 		Qri:   dataset.KindStats.String(),
-		Stats: dsstats.ToMap(cff.acc),
+		Stats: cff.binner.Apply(dsstats.ToMap(cff.acc)),
 	}, nil
 }
 
@@ -179,6 +182,11 @@ func (cff *computeFieldsFile) handleRows(ctx context.Context, pub event.Publishe
 		cff.ds.Commit.Timestamp = cff.ds.Commit.Timestamp.In(time.UTC)
 	}
 	cff.acc = dsstats.NewAccumulator(st)
+	if cff.binner, err = binning.NewBinner(st, cff.sw.Binning); err != nil {
+		cff.Unlock()
+		cff.done <- fmt.Errorf("binning stats: %w", err)
+		return
+	}
 	cff.Unlock()
 
 	jsch, err := st.JSONSchema()
@@ -234,6 +242,9 @@ func (cff *computeFieldsFile) handleRows(ctx context.Context, pub event.Publishe
 			if err := cff.acc.WriteEntry(ent); err != nil {
 				return err
 			}
+			if err := cff.binner.WriteEntry(ent); err != nil {
+				return err
+			}
 
 			if i%batchSize == 0 && i != 0 {
 				numValErrs, flushErr := cff.flushBatch(ctx, batchBuf, st, jsch)
@@ -2,6 +2,7 @@ package dsfs
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"sync"
@@ -11,7 +12,10 @@ import (
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/validate"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base/lint"
 	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/stats/binning"
+	"github.com/qri-io/qri/stats/infer"
 )
 
 // number of entries to per batch when processing body data in WriteDataset
@@ -24,8 +28,9 @@ var (
 	// a Filestore to open a file. Some filestores (like IPFS) fallback to a
 	// network request when it can't find a file locally. Setting a short timeout
 	// prevents waiting for a slow network response, at the expense of leaving
-	// files unresolved.
-	// TODO (b5) - allow -1 duration as a sentinel value for no timeout
+	// files unresolved. OpenFileTimeoutDuration is the default for filesystems
+	// that don't have a timeout set with WithOpenTimeouts. Set to NoTimeout to
+	// disable
 	OpenFileTimeoutDuration = time.Millisecond * 700
 )
 
@@ -42,13 +47,15 @@ func LoadDataset(ctx context.Context, store qfs.Filesystem, path string) (*datas
 	}
 
 	log.Debugf("LoadDataset path=%q", path)
+	// callers that only want local data (eg: listing & status checks) mark the
+	// context as local-only. Fail fast instead of waiting for a network fetch
+	if err := checkLocal(ctx, store, path); err != nil {
+		return nil, fmt.Errorf("loading dataset: %w", err)
+	}
 	// set a timeout to handle long-lived requests when connected to IPFS.
 	// if we don't have the dataset locally, IPFS will reach out onto the d.web to
 	// attempt to resolve previous hashes. capping the duration yeilds quicker results.
-	// TODO (b5) - The proper way to solve this is to feed a local-only IPFS store
-	// to this entire function, or have a mechanism for specifying that a fetch
-	// must be local
-	ctx, cancel := context.WithTimeout(ctx, OpenFileTimeoutDuration)
+	ctx, cancel := OpenTimeoutContext(ctx, path, OpenFileTimeoutDuration)
 	defer cancel()
 
 	ds, err := LoadDatasetRefs(ctx, store, path)
@@ -64,6 +71,76 @@ func LoadDataset(ctx context.Context, store qfs.Filesystem, path string) (*datas
 	return ds, nil
 }
 
+// ComponentLoadError records a dataset component LoadDatasetPartial couldn't
+// load. The component is left on the dataset as a reference to Path
+type ComponentLoadError struct {
+	Component string
+	Path      string
+	Err       error
+}
+
+// Error implements the error interface
+func (e ComponentLoadError) Error() string {
+	return fmt.Sprintf("loading %s %q: %s", e.Component, e.Path, e.Err)
+}
+
+// Unwrap returns the underlying load error
+func (e ComponentLoadError) Unwrap() error { return e.Err }
+
+// LoadDatasetPartial is a tolerant form of LoadDataset. Components that can't
+// be loaded, either because blocks are unavailable or don't parse, are left as
+// references & recorded in the returned slice instead of failing the load.
+// LoadDatasetPartial only errors if the dataset file itself can't be read
+func LoadDatasetPartial(ctx context.Context, store qfs.Filesystem, path string) (*dataset.Dataset, []ComponentLoadError, error) {
+	if store == nil {
+		return nil, nil, fmt.Errorf("loading dataset: store is nil")
+	}
+
+	log.Debugf("LoadDatasetPartial path=%q", path)
+	if err := checkLocal(ctx, store, path); err != nil {
+		return nil, nil, fmt.Errorf("loading dataset: %w", err)
+	}
+	ctx, cancel := OpenTimeoutContext(ctx, path, OpenFileTimeoutDuration)
+	defer cancel()
+
+	ds, err := LoadDatasetRefs(ctx, store, path)
+	if err != nil {
+		log.Debugf("loading dataset: %s", err)
+		return nil, nil, fmt.Errorf("loading dataset: %w", err)
+	}
+
+	var errs []ComponentLoadError
+	deref := func(component, path string, fn func(context.Context, qfs.Filesystem, *dataset.Dataset) error) {
+		if err := fn(ctx, store, ds); err != nil {
+			log.Warnw("partial dataset load", "dataset", ds.Path, "component", component, "path", path, "err", err)
+			errs = append(errs, ComponentLoadError{Component: component, Path: path, Err: err})
+		}
+	}
+	if ds.Meta != nil {
+		deref("meta", ds.Meta.Path, DerefMeta)
+	}
+	if ds.Structure != nil {
+		deref("structure", ds.Structure.Path, DerefStructure)
+	}
+	if ds.Transform != nil {
+		deref("transform", ds.Transform.Path, DerefTransform)
+	}
+	if ds.Viz != nil {
+		deref("viz", ds.Viz.Path, DerefViz)
+	}
+	if ds.Readme != nil {
+		deref("readme", ds.Readme.Path, DerefReadme)
+	}
+	if ds.Stats != nil {
+		deref("stats", ds.Stats.Path, DerefStats)
+	}
+	if ds.Commit != nil {
+		deref("commit", ds.Commit.Path, DerefCommit)
+	}
+
+	return ds, errs, nil
+}
+
 // LoadDatasetRefs reads a dataset from a content addressed filesystem without dereferencing
 // it's components
 func LoadDatasetRefs(ctx context.Context, fs qfs.Filesystem, path string) (*dataset.Dataset, error) {
@@ -124,6 +201,8 @@ type SaveSwitches struct {
 	Pin bool
 	// ConvertFormatToPrev is whether the body should be converted to match the previous format
 	ConvertFormatToPrev bool
+	// BodyFormat converts new bodies in any other format to this format
+	BodyFormat string
 	// ForceIfNoChanges is whether the save should be forced even if no changes are detected
 	ForceIfNoChanges bool
 	// ShouldRender is deprecated, controls whether viz should be rendered
@@ -134,6 +213,32 @@ type SaveSwitches struct {
 	FileHint string
 	// Drop is a string of components to remove before saving
 	Drop string
+	// Infer selects how the schema of a new body is inferred
+	Infer infer.Config
+	// Binning selects how stats histograms & frequency counts are calculated
+	Binning binning.Config
+	// Lint configures the lint checks run before a dataset is written
+	Lint lint.Config
+	// RequireCompatibleSchema rejects saves that remove or retype columns of
+	// the previous version's schema, unless AllowBreakingSchema is set
+	RequireCompatibleSchema bool
+	AllowBreakingSchema     bool
+	// CommitNote is appended to the commit message, after any generated
+	// description
+	CommitNote string
+	// RecordEnvironment appends the environment the version was saved with to
+	// the commit message
+	RecordEnvironment bool
+	// ExtractMeta fills empty meta fields & column descriptions from a
+	// datapackage.json alongside the body, or a second header row of a CSV
+	// body, noting what was extracted in the commit message
+	ExtractMeta bool
+	// Canonical writes JSON components with CanonicalJSON instead of their
+	// default encoding. Commit signatures cover the paths of a version's
+	// components, which are hashes of their encoded bytes, so canonical
+	// versions can be re-encoded & their signatures checked by any
+	// implementation that follows the same encoding
+	Canonical bool
 }
 
 // CreateDataset places a dataset into the store.
@@ -274,7 +379,8 @@ func WriteDataset(
 
 	wfs := &writeFiles{
 		// note: body ds.BodyFile() may return nil
-		body: ds.BodyFile(),
+		body:      ds.BodyFile(),
+		canonical: sw.Canonical,
 	}
 
 	// the call order of these functions is important, funcs later in the slice
@@ -318,6 +424,17 @@ type writeFiles struct {
 
 	commit  qfs.File // requires meta, transform, body, structure, stats, readme, vizScript, vizRendered if they exist
 	dataset qfs.File // requires all other components
+
+	// canonical encodes JSON components with CanonicalJSON
+	canonical bool
+}
+
+// jsonFile creates a JSON component file, using the canonical encoding if set
+func (wfs *writeFiles) jsonFile(name string, m json.Marshaler) (qfs.File, error) {
+	if wfs.canonical {
+		return CanonicalJSONFile(name, m)
+	}
+	return JSONFile(name, m)
 }
 
 // files returns all non-nil files as a slice
@@ -371,7 +488,7 @@ func addDatasetFile(ds *dataset.Dataset, wfs *writeFiles) error {
 			ds.Commit = dataset.NewCommitRef(path)
 		}
 
-		return JSONFile(PackageFileDataset.Filename(), ds)
+		return wfs.jsonFile(PackageFileDataset.Filename(), ds)
 	}
 
 	wfs.dataset = qfs.NewWriteHookFile(emptyFile(PackageFileDataset.Filename()), hook, filePaths(wfs.files())...)
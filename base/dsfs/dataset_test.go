@@ -116,6 +116,60 @@ func TestLoadDataset(t *testing.T) {
 	}
 }
 
+func TestLoadDatasetPartial(t *testing.T) {
+	ctx := context.Background()
+	fs := qfs.NewMemFS()
+	privKey := testkeys.GetKeyData(10).PrivKey
+
+	ds := &dataset.Dataset{
+		Commit:    &dataset.Commit{Title: "initial commit"},
+		Meta:      &dataset.Meta{Title: "partial"},
+		Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("/body.json", []byte(`[1,2,3]`)))
+	path, err := CreateDataset(ctx, fs, fs, event.NilBus, ds, nil, privKey, SaveSwitches{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, errs, err := LoadDatasetPartial(ctx, fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected complete dataset to load without component errors. got: %v", errs)
+	}
+	if got.Meta.Title != "partial" {
+		t.Errorf("expected meta to be loaded. got title: %q", got.Meta.Title)
+	}
+
+	metaPath := got.Meta.Path
+	if err := fs.Delete(ctx, metaPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDataset(ctx, fs, path); err == nil {
+		t.Errorf("expected LoadDataset to fail with a missing meta block")
+	}
+
+	got, errs, err = LoadDatasetPartial(ctx, fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Component != "meta" || errs[0].Path != metaPath {
+		t.Fatalf("expected a single meta component error. got: %v", errs)
+	}
+	if got.Meta == nil || got.Meta.Path != metaPath || !got.Meta.IsEmpty() {
+		t.Errorf("expected unavailable meta to be left as a reference. got: %#v", got.Meta)
+	}
+	if got.Commit.Title != "initial commit" || got.Structure.Format != "json" {
+		t.Errorf("expected available components to load")
+	}
+
+	if _, _, err := LoadDatasetPartial(ctx, fs, "/mem/QmMissing"); err == nil {
+		t.Errorf("expected missing dataset to error")
+	}
+}
+
 func TestCreateDataset(t *testing.T) {
 	ctx := context.Background()
 	fs := qfs.NewMemFS()
@@ -0,0 +1,161 @@
+package dsfs
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/version"
+)
+
+// environment trailers are appended to commit messages. dataset.Commit has no
+// field for arbitrary data, trailers keep the environment inside the signed
+// commit, so it travels with the version
+const (
+	envTrailerQriVersion = "Environment-Qri-Version"
+	envTrailerRuntime    = "Environment-Runtime"
+	envTrailerPlatform   = "Environment-Platform"
+	envTrailerResource   = "Environment-Resource"
+)
+
+// Environment describes the software & inputs a version was saved with, used
+// to reproduce a version & explain differences when reproducing
+type Environment struct {
+	QriVersion string `json:"qriVersion"`
+	// Runtime is the transform runtime & its version, empty for versions saved
+	// without a transform
+	Runtime string `json:"runtime,omitempty"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	// Resources are the dataset versions a transform loaded, sorted
+	Resources []string `json:"resources,omitempty"`
+}
+
+// CaptureEnvironment describes the current environment, taking the transform
+// runtime & resources from a dataset
+func CaptureEnvironment(ds *dataset.Dataset) *Environment {
+	env := &Environment{
+		QriVersion: version.Version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+	}
+	if ds == nil || ds.Transform == nil {
+		return env
+	}
+	if ds.Transform.Syntax != "" {
+		env.Runtime = ds.Transform.Syntax + " " + runtimeVersion(ds.Transform.Syntax)
+	}
+	for _, r := range ds.Transform.Resources {
+		if r != nil && r.Path != "" {
+			env.Resources = append(env.Resources, r.Path)
+		}
+	}
+	sort.Strings(env.Resources)
+	return env
+}
+
+// runtimeVersion looks up the module version of a transform runtime in the
+// build info of this binary
+func runtimeVersion(syntax string) string {
+	modules := map[string]string{
+		"starlark": "go.starlark.net",
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == modules[syntax] {
+				return dep.Version
+			}
+		}
+	}
+	return "unknown"
+}
+
+// String formats the environment as commit message trailers
+func (env *Environment) String() string {
+	lines := []string{
+		fmt.Sprintf("%s: %s", envTrailerQriVersion, env.QriVersion),
+		fmt.Sprintf("%s: %s/%s", envTrailerPlatform, env.OS, env.Arch),
+	}
+	if env.Runtime != "" {
+		lines = append(lines, fmt.Sprintf("%s: %s", envTrailerRuntime, env.Runtime))
+	}
+	for _, r := range env.Resources {
+		lines = append(lines, fmt.Sprintf("%s: %s", envTrailerResource, r))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseEnvironment reads the environment recorded in a commit message,
+// returning nil if the commit has no environment
+func ParseEnvironment(c *dataset.Commit) *Environment {
+	if c == nil {
+		return nil
+	}
+	var env *Environment
+	for _, line := range strings.Split(c.Message, "\n") {
+		pos := strings.Index(line, ": ")
+		if pos == -1 {
+			continue
+		}
+		key, val := line[:pos], strings.TrimSpace(line[pos+2:])
+		if !strings.HasPrefix(key, "Environment-") {
+			continue
+		}
+		if env == nil {
+			env = &Environment{}
+		}
+		switch key {
+		case envTrailerQriVersion:
+			env.QriVersion = val
+		case envTrailerRuntime:
+			env.Runtime = val
+		case envTrailerPlatform:
+			if slash := strings.Index(val, "/"); slash != -1 {
+				env.OS, env.Arch = val[:slash], val[slash+1:]
+			}
+		case envTrailerResource:
+			env.Resources = append(env.Resources, val)
+		}
+	}
+	return env
+}
+
+// Mismatches lists differences between a recorded environment & another,
+// described for warning users
+func (env *Environment) Mismatches(other *Environment) []string {
+	var res []string
+	diff := func(name, recorded, current string) {
+		if recorded != current {
+			res = append(res, fmt.Sprintf("%s: recorded %q, current %q", name, recorded, current))
+		}
+	}
+	diff("qri version", env.QriVersion, other.QriVersion)
+	diff("transform runtime", env.Runtime, other.Runtime)
+	diff("platform", env.OS+"/"+env.Arch, other.OS+"/"+other.Arch)
+
+	// resources are "alias@path" strings, compare versions by alias
+	current := map[string]string{}
+	for _, r := range other.Resources {
+		alias, path := splitResource(r)
+		current[alias] = path
+	}
+	for _, r := range env.Resources {
+		alias, path := splitResource(r)
+		if cur, ok := current[alias]; !ok {
+			res = append(res, fmt.Sprintf("resource %s: recorded %q, not loaded", alias, path))
+		} else {
+			diff("resource "+alias, path, cur)
+		}
+	}
+	return res
+}
+
+func splitResource(r string) (alias, path string) {
+	if pos := strings.Index(r, "@"); pos != -1 {
+		return r[:pos], r[pos+1:]
+	}
+	return r, ""
+}
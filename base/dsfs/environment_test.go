@@ -0,0 +1,66 @@
+package dsfs
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+)
+
+func TestEnvironmentRoundTrip(t *testing.T) {
+	ds := &dataset.Dataset{
+		Transform: &dataset.Transform{
+			Syntax: "starlark",
+			Resources: map[string]*dataset.TransformResource{
+				"/ipfs/QmB": {Path: "peer/b@/ipfs/QmB"},
+				"/ipfs/QmA": {Path: "peer/a@/ipfs/QmA"},
+			},
+		},
+	}
+	env := CaptureEnvironment(ds)
+	if env.OS != runtime.GOOS || env.Arch != runtime.GOARCH {
+		t.Errorf("expected current platform, got %s/%s", env.OS, env.Arch)
+	}
+	if env.Runtime == "" {
+		t.Error("expected transform runtime to be recorded")
+	}
+
+	c := &dataset.Commit{Message: "created dataset\n\n" + env.String()}
+	got := ParseEnvironment(c)
+	if diff := cmp.Diff(env, got); diff != "" {
+		t.Errorf("environment round trip mismatch (-want +got):\n%s", diff)
+	}
+
+	if ParseEnvironment(&dataset.Commit{Message: "created dataset"}) != nil {
+		t.Error("expected commit without trailers to have no environment")
+	}
+}
+
+func TestEnvironmentMismatches(t *testing.T) {
+	recorded := &Environment{
+		QriVersion: "0.10.0",
+		Runtime:    "starlark v1",
+		OS:         "linux",
+		Arch:       "amd64",
+		Resources:  []string{"peer/a@/ipfs/QmA", "peer/b@/ipfs/QmB"},
+	}
+	current := &Environment{
+		QriVersion: "0.10.0",
+		Runtime:    "starlark v2",
+		OS:         "linux",
+		Arch:       "amd64",
+		Resources:  []string{"peer/a@/ipfs/QmA2"},
+	}
+	expect := []string{
+		`transform runtime: recorded "starlark v1", current "starlark v2"`,
+		`resource peer/a: recorded "/ipfs/QmA", current "/ipfs/QmA2"`,
+		`resource peer/b: recorded "/ipfs/QmB", not loaded`,
+	}
+	if diff := cmp.Diff(expect, recorded.Mismatches(current)); diff != "" {
+		t.Errorf("mismatches (-want +got):\n%s", diff)
+	}
+	if got := recorded.Mismatches(recorded); len(got) != 0 {
+		t.Errorf("expected no mismatches comparing an environment to itself, got: %v", got)
+	}
+}
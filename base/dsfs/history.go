@@ -0,0 +1,96 @@
+package dsfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+var (
+	// ErrHistoryCycle indicates a chain of PreviousPath links refers back to a
+	// version that has already been visited
+	ErrHistoryCycle = fmt.Errorf("dataset history contains a cycle")
+	// ErrHistoryTimeout indicates walking a dataset history took longer than
+	// HistoryTimeoutDuration
+	ErrHistoryTimeout = fmt.Errorf("timed out walking dataset history")
+
+	// HistoryTimeoutDuration caps the total time History spends walking a chain
+	// of versions. Each version is also subject to OpenFileTimeoutDuration
+	HistoryTimeoutDuration = time.Second * 10
+)
+
+// History walks the commit ancestry of a dataset, starting at headPath and
+// following PreviousPath links until the initial version is reached or depth
+// versions are loaded. A depth of -1 walks the entire history. Versions are
+// returned newest-first, with only the commit & structure components loaded.
+//
+// To page through long histories, call History again with the PreviousPath of
+// the last returned version as headPath.
+//
+// History returns the versions it loaded alongside any error, so callers can
+// use a partial history when an ancestor is missing, a cycle is found, or the
+// walk times out
+func History(ctx context.Context, fs qfs.Filesystem, headPath string, depth int) ([]*dataset.Dataset, error) {
+	if fs == nil {
+		return nil, fmt.Errorf("loading history: filesystem is nil")
+	}
+	return walkHistory(ctx, headPath, depth, func(ctx context.Context, path string) (*dataset.Dataset, error) {
+		return loadHistoryVersion(ctx, fs, path)
+	})
+}
+
+func walkHistory(ctx context.Context, headPath string, depth int, load func(ctx context.Context, path string) (*dataset.Dataset, error)) ([]*dataset.Dataset, error) {
+	ctx, cancel := context.WithTimeout(ctx, HistoryTimeoutDuration)
+	defer cancel()
+
+	var (
+		history = []*dataset.Dataset{}
+		visited = map[string]struct{}{}
+		path    = headPath
+	)
+
+	for path != "" && (depth < 0 || len(history) < depth) {
+		if _, ok := visited[path]; ok {
+			return history, fmt.Errorf("%w: %q", ErrHistoryCycle, path)
+		}
+		visited[path] = struct{}{}
+
+		ds, err := load(ctx, path)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return history, ErrHistoryTimeout
+			}
+			return history, err
+		}
+		history = append(history, ds)
+		path = ds.PreviousPath
+	}
+
+	return history, nil
+}
+
+// loadHistoryVersion loads a single version with commit & structure
+// components dereferenced
+func loadHistoryVersion(ctx context.Context, fs qfs.Filesystem, path string) (*dataset.Dataset, error) {
+	if err := checkLocal(ctx, fs, path); err != nil {
+		return nil, err
+	}
+	ctx, cancel := OpenTimeoutContext(ctx, path, OpenFileTimeoutDuration)
+	defer cancel()
+
+	ds, err := LoadDatasetRefs(ctx, fs, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := DerefCommit(ctx, fs, ds); err != nil {
+		return nil, err
+	}
+	if err := DerefStructure(ctx, fs, ds); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
@@ -0,0 +1,104 @@
+package dsfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/event"
+)
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+	fs := qfs.NewMemFS()
+	privKey := testkeys.GetKeyData(10).PrivKey
+
+	var (
+		prev  *dataset.Dataset
+		paths []string
+	)
+	for i := 0; i < 3; i++ {
+		ds := &dataset.Dataset{
+			Commit:    &dataset.Commit{Title: fmt.Sprintf("version %d", i), Message: "update body"},
+			Meta:      &dataset.Meta{Title: "history"},
+			Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+		}
+		if prev != nil {
+			ds.PreviousPath = prev.Path
+		}
+		ds.SetBodyFile(qfs.NewMemfileBytes("/body.json", []byte(fmt.Sprintf(`[%d]`, i))))
+
+		path, err := CreateDataset(ctx, fs, fs, event.NilBus, ds, prev, privKey, SaveSwitches{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev, err = LoadDataset(ctx, fs, path); err != nil {
+			t.Fatal(err)
+		}
+		paths = append([]string{path}, paths...)
+	}
+
+	versions, err := History(ctx, fs, paths[0], -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+	for i, ds := range versions {
+		if ds.Path != paths[i] {
+			t.Errorf("version %d path mismatch. want %q, got %q", i, paths[i], ds.Path)
+		}
+		if expect := fmt.Sprintf("version %d", 2-i); ds.Commit.Title != expect {
+			t.Errorf("version %d commit title mismatch. want %q, got %q", i, expect, ds.Commit.Title)
+		}
+		if ds.Structure.Format != "json" {
+			t.Errorf("version %d: expected structure to be loaded", i)
+		}
+		if ds.Meta != nil && ds.Meta.Title != "" {
+			t.Errorf("version %d: expected meta to not be loaded", i)
+		}
+	}
+
+	// page through history two versions at a time
+	page, err := History(ctx, fs, paths[0], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 {
+		t.Fatalf("expected first page to have 2 versions, got %d", len(page))
+	}
+	if page, err = History(ctx, fs, page[1].PreviousPath, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 || page[0].Path != paths[2] {
+		t.Errorf("expected second page to contain the initial version, got %d versions", len(page))
+	}
+
+	if _, err := History(ctx, fs, "/mem/not_a_path", -1); err == nil {
+		t.Error("expected missing version to error")
+	}
+}
+
+func TestWalkHistoryCycle(t *testing.T) {
+	versions := map[string]*dataset.Dataset{
+		"/a": {Path: "/a", PreviousPath: "/b"},
+		"/b": {Path: "/b", PreviousPath: "/c"},
+		"/c": {Path: "/c", PreviousPath: "/a"},
+	}
+	load := func(ctx context.Context, path string) (*dataset.Dataset, error) {
+		return versions[path], nil
+	}
+
+	got, err := walkHistory(context.Background(), "/a", -1, load)
+	if !errors.Is(err, ErrHistoryCycle) {
+		t.Errorf("expected ErrHistoryCycle, got: %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("expected versions walked before the cycle to be returned, got %d", len(got))
+	}
+}
@@ -41,7 +41,7 @@ func addMetaFile(ds *dataset.Dataset, wfs *writeFiles) error {
 	}
 
 	ds.Meta.DropTransientValues()
-	md, err := JSONFile(PackageFileMeta.Filename(), ds.Meta)
+	md, err := wfs.jsonFile(PackageFileMeta.Filename(), ds.Meta)
 	if err != nil {
 		return err
 	}
@@ -53,7 +53,7 @@ func addStatsFile(ds *dataset.Dataset, wfs *writeFiles) error {
 			return nil, err
 		}
 		ds.Stats = sa
-		return JSONFile(f.FullPath(), sa)
+		return wfs.jsonFile(f.FullPath(), sa)
 	}
 
 	wfs.stats = qfs.NewWriteHookFile(qfs.NewMemfileBytes(PackageFileStats.Filename(), []byte{}), hook, wfs.structure.FullPath())
@@ -45,7 +45,7 @@ func structureFileAddFunc(destFS qfs.Filesystem) addWriteFileFunc {
 
 		if wfs.body == nil {
 			log.Debugf("body is nil, using json structure file")
-			wfs.structure, err = JSONFile(PackageFileStructure.Filename(), ds.Structure)
+			wfs.structure, err = wfs.jsonFile(PackageFileStructure.Filename(), ds.Structure)
 			return err
 		}
 
@@ -65,7 +65,7 @@ func structureFileAddFunc(destFS qfs.Filesystem) addWriteFileFunc {
 				}
 			}
 
-			return JSONFile(f.FullPath(), ds.Structure)
+			return wfs.jsonFile(f.FullPath(), ds.Structure)
 		}
 
 		wfs.structure = qfs.NewWriteHookFile(emptyFile(PackageFileStructure.Filename()), hook, wfs.body.FullPath())
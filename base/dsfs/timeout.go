@@ -0,0 +1,103 @@
+package dsfs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qri-io/qfs"
+)
+
+// NoTimeout is a sentinel duration that disables open timeouts. Use it with
+// WithOpenTimeout for fetches that are expected to hit the network, or as a
+// per-filesystem timeout
+const NoTimeout time.Duration = -1
+
+// ErrNotLocal is returned by loads that are restricted to local data when a
+// dataset isn't stored locally
+var ErrNotLocal = fmt.Errorf("dataset isn't stored locally")
+
+type ctxKey string
+
+const (
+	openTimeoutsKey ctxKey = "openTimeouts"
+	openTimeoutKey  ctxKey = "openTimeout"
+	localOnlyKey    ctxKey = "localOnly"
+)
+
+// WithOpenTimeouts returns a context that sets open timeouts per filesystem
+// type, keyed by qfs.PathKind (eg: "ipfs", "local"). Filesystem types without
+// an entry fall back to package defaults
+func WithOpenTimeouts(ctx context.Context, timeouts map[string]time.Duration) context.Context {
+	return context.WithValue(ctx, openTimeoutsKey, timeouts)
+}
+
+// WithOpenTimeout returns a context that sets the open timeout for all
+// filesystems, overriding any per-filesystem timeouts. Pass NoTimeout to
+// wait as long as the context allows
+func WithOpenTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, openTimeoutKey, d)
+}
+
+// WithLocalOnly returns a context that restricts dataset loads to data that
+// is already stored locally. Loads of datasets that would require a network
+// fetch fail with ErrNotLocal instead of waiting on a timeout
+func WithLocalOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, localOnlyKey, true)
+}
+
+// LocalOnly returns true if a context restricts loads to local data
+func LocalOnly(ctx context.Context) bool {
+	localOnly, _ := ctx.Value(localOnlyKey).(bool)
+	return localOnly
+}
+
+// OpenTimeout determines how long to wait to open a path, checking context
+// overrides, then per-filesystem timeouts, finally using fallback
+func OpenTimeout(ctx context.Context, path string, fallback time.Duration) time.Duration {
+	if d, ok := ctx.Value(openTimeoutKey).(time.Duration); ok {
+		return d
+	}
+	if timeouts, ok := ctx.Value(openTimeoutsKey).(map[string]time.Duration); ok {
+		if d, ok := timeouts[qfs.PathKind(path)]; ok {
+			return d
+		}
+	}
+	return fallback
+}
+
+// OpenTimeoutContext derives a context that is cancelled after the open
+// timeout for path. Timeouts of NoTimeout (or any negative duration) only
+// add cancellation
+func OpenTimeoutContext(ctx context.Context, path string, fallback time.Duration) (context.Context, context.CancelFunc) {
+	d := OpenTimeout(ctx, path, fallback)
+	if d < 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+type hasser interface {
+	Has(ctx context.Context, path string) (bool, error)
+}
+
+// checkLocal returns ErrNotLocal if the context restricts loads to local data
+// and the dataset at path isn't stored by fs. Filesystems that can't report
+// local storage are assumed to be local
+func checkLocal(ctx context.Context, fs qfs.Filesystem, path string) error {
+	if !LocalOnly(ctx) {
+		return nil
+	}
+	h, ok := fs.(hasser)
+	if !ok {
+		return nil
+	}
+	has, err := h.Has(ctx, path)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNotLocal, err)
+	}
+	if !has {
+		return fmt.Errorf("%w: %q", ErrNotLocal, path)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package dsfs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qfs"
+)
+
+func TestOpenTimeout(t *testing.T) {
+	ctx := context.Background()
+	fallback := time.Millisecond * 100
+	timeouts := map[string]time.Duration{
+		"ipfs":  time.Second,
+		"local": NoTimeout,
+	}
+
+	cases := []struct {
+		description string
+		ctx         context.Context
+		path        string
+		expect      time.Duration
+	}{
+		{"no config uses fallback", ctx, "/ipfs/QmFoo", fallback},
+		{"filesystem timeout", WithOpenTimeouts(ctx, timeouts), "/ipfs/QmFoo", time.Second},
+		{"filesystem without a timeout uses fallback", WithOpenTimeouts(ctx, timeouts), "/mem/QmFoo", fallback},
+		{"no timeout sentinel", WithOpenTimeouts(ctx, timeouts), "/path/to/file.json", NoTimeout},
+		{"override beats filesystem timeout", WithOpenTimeout(WithOpenTimeouts(ctx, timeouts), NoTimeout), "/ipfs/QmFoo", NoTimeout},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			got := OpenTimeout(c.ctx, c.path, fallback)
+			if got != c.expect {
+				t.Errorf("timeout mismatch. want: %s got: %s", c.expect, got)
+			}
+		})
+	}
+
+	noTimeoutCtx, cancel := OpenTimeoutContext(WithOpenTimeout(ctx, NoTimeout), "/ipfs/QmFoo", fallback)
+	defer cancel()
+	if _, ok := noTimeoutCtx.Deadline(); ok {
+		t.Error("expected NoTimeout context to have no deadline")
+	}
+}
+
+// remoteFS is a filesystem that reports it has nothing stored locally
+type remoteFS struct {
+	*qfs.MemFS
+}
+
+func (remoteFS) Has(ctx context.Context, path string) (bool, error) {
+	return false, nil
+}
+
+func TestLoadDatasetLocalOnly(t *testing.T) {
+	ctx := context.Background()
+	fs := remoteFS{qfs.NewMemFS()}
+
+	_, err := LoadDataset(WithLocalOnly(ctx), fs, "/mem/QmNotLocal")
+	if !errors.Is(err, ErrNotLocal) {
+		t.Errorf("expected local-only load to fail with ErrNotLocal, got: %v", err)
+	}
+
+	_, err = LoadDataset(ctx, fs, "/mem/QmNotLocal")
+	if err == nil || errors.Is(err, ErrNotLocal) {
+		t.Errorf("expected load without local-only to attempt a fetch, got: %v", err)
+	}
+}
@@ -72,12 +72,12 @@ func addTransformFile(ds *dataset.Dataset, wfs *writeFiles) (err error) {
 	if wfs.transformScript != nil {
 		hook := func(ctx context.Context, f qfs.File, pathMap map[string]string) (io.Reader, error) {
 			ds.Transform.ScriptPath = pathMap[transformScriptFilename]
-			return JSONFile(PackageFileTransform.Filename(), ds.Transform)
+			return wfs.jsonFile(PackageFileTransform.Filename(), ds.Transform)
 		}
 		wfs.transform = qfs.NewWriteHookFile(emptyFile(PackageFileTransform.Filename()), hook, transformScriptFilename)
 		return nil
 	}
 
-	wfs.transform, err = JSONFile(PackageFileTransform.Filename(), ds.Transform)
+	wfs.transform, err = wfs.jsonFile(PackageFileTransform.Filename(), ds.Transform)
 	return err
 }
@@ -0,0 +1,139 @@
+package dsfs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/qri-io/dataset/validate"
+	"github.com/qri-io/qfs"
+)
+
+const (
+	// VerifyOK marks data that is stored locally & valid
+	VerifyOK = "ok"
+	// VerifyRemote marks data that isn't stored locally. remote-only data
+	// may be fetched from the network
+	VerifyRemote = "remote"
+	// VerifyInvalid marks data that is stored locally, but can't be read or
+	// doesn't match its schema
+	VerifyInvalid = "invalid"
+)
+
+// ComponentCheck is the result of verifying a single component of a version
+type ComponentCheck struct {
+	Component string `json:"component"`
+	Path      string `json:"path"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// VersionCheck is the result of verifying a dataset version. Status is the
+// worst status of the version's components: invalid, then remote, then ok
+type VersionCheck struct {
+	Path       string           `json:"path"`
+	Status     string           `json:"status"`
+	Error      string           `json:"error,omitempty"`
+	Components []ComponentCheck `json:"components,omitempty"`
+}
+
+// VerifyVersion checks that every block of a dataset version is stored by fs
+// and that components parse. Versions with all components stored locally are
+// also validated as a whole. VerifyVersion never fetches data from the
+// network, filesystems that can't report local storage are assumed to be
+// local
+func VerifyVersion(ctx context.Context, fs qfs.Filesystem, path string) VersionCheck {
+	vc := VersionCheck{Path: path, Status: VerifyOK}
+
+	local, err := hasLocal(ctx, fs, PackageFilepath(fs, path, PackageFileDataset))
+	if err != nil {
+		vc.Status, vc.Error = VerifyInvalid, err.Error()
+		return vc
+	}
+	if !local {
+		vc.Status = VerifyRemote
+		return vc
+	}
+
+	ds, err := LoadDatasetRefs(ctx, fs, path)
+	if err != nil {
+		vc.Status, vc.Error = VerifyInvalid, err.Error()
+		return vc
+	}
+
+	check := func(name, path string, load func() error) {
+		if path == "" {
+			return
+		}
+		cc := ComponentCheck{Component: name, Path: path, Status: VerifyOK}
+		if local, err := hasLocal(ctx, fs, path); err != nil {
+			cc.Status, cc.Error = VerifyInvalid, err.Error()
+		} else if !local {
+			cc.Status = VerifyRemote
+		} else if load != nil {
+			if err := load(); err != nil {
+				cc.Status, cc.Error = VerifyInvalid, err.Error()
+			}
+		}
+		vc.Components = append(vc.Components, cc)
+	}
+
+	if ds.Commit != nil {
+		check("commit", ds.Commit.Path, func() error { return DerefCommit(ctx, fs, ds) })
+	}
+	if ds.Meta != nil {
+		check("meta", ds.Meta.Path, func() error { return DerefMeta(ctx, fs, ds) })
+	}
+	if ds.Structure != nil {
+		check("structure", ds.Structure.Path, func() error { return DerefStructure(ctx, fs, ds) })
+	}
+	if ds.Readme != nil {
+		check("readme", ds.Readme.Path, func() error { return DerefReadme(ctx, fs, ds) })
+	}
+	if ds.Transform != nil {
+		check("transform", ds.Transform.Path, func() error { return DerefTransform(ctx, fs, ds) })
+	}
+	if ds.Viz != nil {
+		check("viz", ds.Viz.Path, func() error { return DerefViz(ctx, fs, ds) })
+	}
+	if ds.Stats != nil {
+		check("stats", ds.Stats.Path, func() error { return DerefStats(ctx, fs, ds) })
+	}
+	check("body", ds.BodyPath, nil)
+
+	// scripts & rendered files are only known once their component is loaded
+	if ds.Readme != nil {
+		check("readme script", ds.Readme.ScriptPath, nil)
+		check("rendered readme", ds.Readme.RenderedPath, nil)
+	}
+	if ds.Transform != nil {
+		check("transform script", ds.Transform.ScriptPath, nil)
+	}
+	if ds.Viz != nil {
+		check("viz script", ds.Viz.ScriptPath, nil)
+		check("rendered viz", ds.Viz.RenderedPath, nil)
+	}
+
+	for _, cc := range vc.Components {
+		switch {
+		case cc.Status == VerifyInvalid:
+			vc.Status = VerifyInvalid
+		case cc.Status == VerifyRemote && vc.Status == VerifyOK:
+			vc.Status = VerifyRemote
+		}
+	}
+	if vc.Status == VerifyOK {
+		if err := validate.Dataset(ds); err != nil {
+			vc.Status, vc.Error = VerifyInvalid, fmt.Sprintf("invalid dataset: %s", err)
+		}
+	}
+	return vc
+}
+
+// hasLocal reports whether fs stores path
+func hasLocal(ctx context.Context, fs qfs.Filesystem, path string) (bool, error) {
+	h, ok := fs.(hasser)
+	if !ok {
+		return true, nil
+	}
+	return h.Has(ctx, path)
+}
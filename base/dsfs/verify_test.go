@@ -0,0 +1,63 @@
+package dsfs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/event"
+)
+
+func TestVerifyVersion(t *testing.T) {
+	ctx := context.Background()
+	fs := qfs.NewMemFS()
+	privKey := testkeys.GetKeyData(10).PrivKey
+
+	ds := &dataset.Dataset{
+		Commit:    &dataset.Commit{Title: "initial commit"},
+		Meta:      &dataset.Meta{Title: "verify"},
+		Structure: &dataset.Structure{Format: "json", Schema: dataset.BaseSchemaArray},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("/body.json", []byte(`[1,2,3]`)))
+	path, err := CreateDataset(ctx, fs, fs, event.NilBus, ds, nil, privKey, SaveSwitches{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vc := VerifyVersion(ctx, fs, path)
+	if vc.Status != VerifyOK {
+		t.Fatalf("expected new version to verify. got status %q: %s", vc.Status, vc.Error)
+	}
+	components := map[string]bool{}
+	for _, c := range vc.Components {
+		components[c.Component] = true
+	}
+	for _, name := range []string{"commit", "meta", "structure", "body"} {
+		if !components[name] {
+			t.Errorf("expected %s component to be checked", name)
+		}
+	}
+
+	refs, err := LoadDatasetRefs(ctx, fs, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Delete(ctx, refs.Meta.Path); err != nil {
+		t.Fatal(err)
+	}
+	vc = VerifyVersion(ctx, fs, path)
+	if vc.Status == VerifyOK {
+		t.Errorf("expected version with a missing meta block to fail verification")
+	}
+	for _, c := range vc.Components {
+		if c.Component == "meta" && c.Status == VerifyOK {
+			t.Errorf("expected missing meta block to be reported")
+		}
+	}
+
+	if vc := VerifyVersion(ctx, fs, "/mem/QmMissing"); vc.Status == VerifyOK {
+		t.Errorf("expected unknown version to fail verification")
+	}
+}
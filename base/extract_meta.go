@@ -0,0 +1,334 @@
+package base
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+// DatapackageFilename is the name of the frictionless data package descriptor
+// looked for alongside body files
+const DatapackageFilename = "datapackage.json"
+
+// descriptionRowSource names the second header row of a CSV as a source of
+// extracted metadata
+const descriptionRowSource = "the second header row"
+
+// ExtractedMeta is metadata found alongside or inside a body file
+type ExtractedMeta struct {
+	// Meta holds values read from a data package descriptor
+	Meta *dataset.Meta
+	// Columns maps column titles to descriptions
+	Columns map[string]string
+	// Sources lists where metadata was found
+	Sources []string
+}
+
+// ExtractMeta looks for metadata describing the body of a dataset that's about
+// to be saved: a frictionless datapackage.json in the directory of a local
+// body file, and column descriptions in the second row of a CSV body. A
+// description row is removed from the body. ExtractMeta returns nil if no
+// metadata is found
+func ExtractMeta(ds *dataset.Dataset) (*ExtractedMeta, error) {
+	file := ds.BodyFile()
+	if file == nil {
+		return nil, nil
+	}
+	path := ds.BodyPath
+	if path == "" {
+		path = file.FullPath()
+	}
+
+	em := &ExtractedMeta{Columns: map[string]string{}}
+	if qfs.PathKind(path) == "local" {
+		if err := em.readDatapackage(path); err != nil {
+			return nil, err
+		}
+	}
+	if (ds.Structure != nil && ds.Structure.Format == "csv") || strings.EqualFold(filepath.Ext(path), ".csv") {
+		if err := em.readDescriptionRow(ds); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(em.Sources) == 0 {
+		return nil, nil
+	}
+	return em, nil
+}
+
+// Apply fills empty meta fields & column descriptions of a dataset with
+// extracted values, returning a note describing what was filled for the
+// commit message. Values already set on the dataset are kept
+func (em *ExtractedMeta) Apply(ds *dataset.Dataset) string {
+	var filled []string
+	if m := em.Meta; m != nil {
+		if ds.Meta == nil {
+			ds.Meta = &dataset.Meta{}
+		}
+		if ds.Meta.Title == "" && m.Title != "" {
+			ds.Meta.Title = m.Title
+			filled = append(filled, "title")
+		}
+		if ds.Meta.Description == "" && m.Description != "" {
+			ds.Meta.Description = m.Description
+			filled = append(filled, "description")
+		}
+		if len(ds.Meta.Keywords) == 0 && len(m.Keywords) > 0 {
+			ds.Meta.Keywords = m.Keywords
+			filled = append(filled, "keywords")
+		}
+		if ds.Meta.License == nil && m.License != nil {
+			ds.Meta.License = m.License
+			filled = append(filled, "license")
+		}
+		if ds.Meta.HomeURL == "" && m.HomeURL != "" {
+			ds.Meta.HomeURL = m.HomeURL
+			filled = append(filled, "home url")
+		}
+		if ds.Meta.Version == "" && m.Version != "" {
+			ds.Meta.Version = m.Version
+			filled = append(filled, "version")
+		}
+		if len(ds.Meta.Citations) == 0 && len(m.Citations) > 0 {
+			ds.Meta.Citations = m.Citations
+			filled = append(filled, "citations")
+		}
+	}
+
+	described := 0
+	for _, col := range schemaColumns(ds.Structure) {
+		title, _ := col["title"].(string)
+		desc := em.Columns[title]
+		if _, ok := col["description"]; ok || desc == "" {
+			continue
+		}
+		col["description"] = desc
+		described++
+	}
+	if described == 1 {
+		filled = append(filled, "1 column description")
+	} else if described > 1 {
+		filled = append(filled, fmt.Sprintf("%d column descriptions", described))
+	}
+
+	if len(filled) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("extracted meta: %s from %s", strings.Join(filled, ", "), strings.Join(em.Sources, " & "))
+}
+
+// schemaColumns returns the column definitions of a tabular schema
+func schemaColumns(st *dataset.Structure) []map[string]interface{} {
+	if st == nil || st.Schema == nil {
+		return nil
+	}
+	items, _ := st.Schema["items"].(map[string]interface{})
+	list, _ := items["items"].([]interface{})
+	cols := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if col, ok := item.(map[string]interface{}); ok {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// datapackage is the subset of a frictionless data package descriptor qri
+// reads. See https://specs.frictionlessdata.io/data-package/
+type datapackage struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Keywords    []string `json:"keywords"`
+	Homepage    string   `json:"homepage"`
+	Version     string   `json:"version"`
+	Licenses    []struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+	} `json:"licenses"`
+	Sources []struct {
+		Title string `json:"title"`
+		Path  string `json:"path"`
+		Email string `json:"email"`
+	} `json:"sources"`
+	Resources []datapackageResource `json:"resources"`
+}
+
+type datapackageResource struct {
+	// Path is a string, or a list of strings for resources split into parts
+	Path        interface{} `json:"path"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Schema      struct {
+		Fields []struct {
+			Name        string `json:"name"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		} `json:"fields"`
+	} `json:"schema"`
+}
+
+// resource finds the resource describing a body file, falling back to the
+// only resource of single-resource packages
+func (dp datapackage) resource(bodyFilename string) *datapackageResource {
+	for i, res := range dp.Resources {
+		if p, ok := res.Path.(string); ok && filepath.Base(p) == bodyFilename {
+			return &dp.Resources[i]
+		}
+	}
+	if len(dp.Resources) == 1 {
+		return &dp.Resources[0]
+	}
+	return nil
+}
+
+func (em *ExtractedMeta) readDatapackage(bodyPath string) error {
+	filename := filepath.Join(filepath.Dir(bodyPath), DatapackageFilename)
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	dp := datapackage{}
+	if err := json.Unmarshal(data, &dp); err != nil {
+		return fmt.Errorf("reading %s: %w", DatapackageFilename, err)
+	}
+
+	md := &dataset.Meta{
+		Title:       dp.Title,
+		Description: dp.Description,
+		Keywords:    dp.Keywords,
+		HomeURL:     dp.Homepage,
+		Version:     dp.Version,
+	}
+	if len(dp.Licenses) > 0 {
+		md.License = &dataset.License{Type: dp.Licenses[0].Name, URL: dp.Licenses[0].Path}
+	}
+	for _, src := range dp.Sources {
+		md.Citations = append(md.Citations, &dataset.Citation{Name: src.Title, URL: src.Path, Email: src.Email})
+	}
+	if res := dp.resource(filepath.Base(bodyPath)); res != nil {
+		// resource values describe the body more closely than the package
+		if res.Title != "" {
+			md.Title = res.Title
+		}
+		if res.Description != "" {
+			md.Description = res.Description
+		}
+		for _, f := range res.Schema.Fields {
+			desc := f.Description
+			if desc == "" {
+				desc = f.Title
+			}
+			if desc != "" {
+				em.Columns[f.Name] = desc
+			}
+		}
+	}
+
+	em.Meta = md
+	em.Sources = append(em.Sources, DatapackageFilename)
+	return nil
+}
+
+// readDescriptionRow looks for a row of column descriptions following the
+// header row of a CSV body, removing the row from the body if it's found
+func (em *ExtractedMeta) readDescriptionRow(ds *dataset.Dataset) error {
+	if ds.Structure != nil && ds.Structure.FormatConfig != nil {
+		if hr, ok := ds.Structure.FormatConfig["headerRow"].(bool); ok && !hr {
+			return nil
+		}
+	}
+
+	file := ds.BodyFile()
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	// reading consumes the body, buffer it so it can be read again
+	ds.SetBodyFile(qfs.NewMemfileBytes(file.FileName(), data))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	rows, err := r.ReadAll()
+	if err != nil || len(rows) < 3 || !isDescriptionRow(rows[0], rows[1], rows[2:]) {
+		// bodies that can't be parsed are left for structure detection to report
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Write(rows[0])
+	w.WriteAll(rows[2:])
+	if err := w.Error(); err != nil {
+		return err
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes(file.FileName(), buf.Bytes()))
+
+	for i, title := range rows[0] {
+		if _, ok := em.Columns[title]; !ok {
+			em.Columns[title] = strings.TrimSpace(rows[1][i])
+		}
+	}
+	em.Sources = append(em.Sources, descriptionRowSource)
+	return nil
+}
+
+// descriptionRowSampleSize is the number of data rows checked when detecting
+// a description row
+const descriptionRowSampleSize = 50
+
+// isDescriptionRow reports whether the row following a header holds column
+// descriptions rather than data: every cell is text that doesn't repeat the
+// header, above at least one column that holds only numbers
+func isDescriptionRow(header, row []string, data [][]string) bool {
+	if len(row) != len(header) {
+		return false
+	}
+	for i, cell := range row {
+		cell = strings.TrimSpace(cell)
+		if cell == "" || cell == strings.TrimSpace(header[i]) || isNumber(cell) {
+			return false
+		}
+		if _, err := strconv.ParseBool(cell); err == nil {
+			return false
+		}
+	}
+
+	if len(data) > descriptionRowSampleSize {
+		data = data[:descriptionRowSampleSize]
+	}
+	for i := range row {
+		numbers := 0
+		for _, d := range data {
+			if i >= len(d) || strings.TrimSpace(d[i]) == "" {
+				continue
+			}
+			if !isNumber(strings.TrimSpace(d[i])) {
+				numbers = -1
+				break
+			}
+			numbers++
+		}
+		if numbers > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
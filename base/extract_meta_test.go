@@ -0,0 +1,107 @@
+package base
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+const extractMetaDatapackage = `{
+  "title": "Movie Durations",
+  "description": "package description",
+  "keywords": ["film"],
+  "licenses": [{ "name": "ODC-PDDL-1.0", "path": "http://opendatacommons.org/licenses/pddl/" }],
+  "sources": [{ "title": "IMDB", "path": "https://imdb.com" }],
+  "resources": [{
+    "path": "data/movies.csv",
+    "description": "Durations of popular movies",
+    "schema": { "fields": [
+      { "name": "title", "type": "string", "description": "movie title" },
+      { "name": "duration", "type": "integer", "title": "duration in minutes" }
+    ]}
+  }]
+}`
+
+func TestExtractMetaDatapackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extract_meta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, DatapackageFilename), []byte(extractMetaDatapackage), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bodyPath := filepath.Join(dir, "movies.csv")
+	ds := &dataset.Dataset{BodyPath: bodyPath}
+	ds.SetBodyFile(qfs.NewMemfileBytes(bodyPath, []byte("title,duration\nAvatar,178\n")))
+
+	em, err := ExtractMeta(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if em == nil {
+		t.Fatal("expected metadata to be extracted")
+	}
+
+	ds.Meta = &dataset.Meta{Title: "keep this title"}
+	ds.Structure = tabularStructure(col("title", "string"), col("duration", "integer"))
+	note := em.Apply(ds)
+
+	expect := "extracted meta: description, keywords, license, citations, 2 column descriptions from datapackage.json"
+	if note != expect {
+		t.Errorf("note mismatch.\nwant: %q\ngot:  %q", expect, note)
+	}
+	if ds.Meta.Title != "keep this title" {
+		t.Errorf("expected existing title to be kept, got: %q", ds.Meta.Title)
+	}
+	if ds.Meta.Description != "Durations of popular movies" {
+		t.Errorf("expected resource description, got: %q", ds.Meta.Description)
+	}
+	cols := schemaColumns(ds.Structure)
+	if cols[0]["description"] != "movie title" || cols[1]["description"] != "duration in minutes" {
+		t.Errorf("column descriptions mismatch, got: %v", cols)
+	}
+}
+
+func TestExtractMetaDescriptionRow(t *testing.T) {
+	body := "title,duration\nthe movie's title,length in minutes\nAvatar,178\nSpectre,148\n"
+	ds := &dataset.Dataset{BodyPath: "movies.csv"}
+	ds.SetBodyFile(qfs.NewMemfileBytes("movies.csv", []byte(body)))
+
+	em, err := ExtractMeta(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if em == nil {
+		t.Fatal("expected a description row to be found")
+	}
+	data, err := ioutil.ReadAll(ds.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "title,duration\nAvatar,178\nSpectre,148\n"; string(data) != expect {
+		t.Errorf("expected description row to be removed from the body.\nwant: %q\ngot:  %q", expect, string(data))
+	}
+	if em.Columns["duration"] != "length in minutes" {
+		t.Errorf("expected duration description, got: %q", em.Columns["duration"])
+	}
+
+	// a second row of data isn't a description row
+	body = "title,duration\nAvatar,178\nSpectre,148\n"
+	ds = &dataset.Dataset{BodyPath: "movies.csv"}
+	ds.SetBodyFile(qfs.NewMemfileBytes("movies.csv", []byte(body)))
+	if em, err = ExtractMeta(ds); err != nil {
+		t.Fatal(err)
+	}
+	if em != nil {
+		t.Errorf("expected no metadata, got: %#v", em)
+	}
+	if data, _ = ioutil.ReadAll(ds.BodyFile()); string(data) != body {
+		t.Errorf("expected body to be unchanged, got: %q", string(data))
+	}
+}
@@ -0,0 +1,178 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/qri-io/qfs"
+)
+
+// ErrBodyURLNotModified is returned by FetchBodyURL when the remote server
+// reports a body hasn't changed since the last recorded fetch
+var ErrBodyURLNotModified = errors.New("body url has not been modified since last fetch")
+
+// ErrBodyURLTooLarge is returned by FetchBodyURL when a response body is
+// larger than MaxBodyFetchSize
+var ErrBodyURLTooLarge = errors.New("body url response is too large")
+
+// BodyFetchClient is the http client used to download body URLs. tests may
+// override
+var BodyFetchClient = http.DefaultClient
+
+// MaxBodyFetchSize is the largest body, in bytes, FetchBodyURL will download
+var MaxBodyFetchSize int64 = 1 << 30
+
+// BodyFetchInfo records the conditional-request state of a body fetched from a
+// URL, used to detect changes on subsequent fetches & record provenance
+type BodyFetchInfo struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+}
+
+// Map returns the fetch info as a map suitable for storing in dataset
+// metadata. FetchedAt is left out, it changes on every fetch & would make
+// every fetch a new version
+func (fi *BodyFetchInfo) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"url": fi.URL,
+	}
+	if fi.ETag != "" {
+		m["etag"] = fi.ETag
+	}
+	if fi.LastModified != "" {
+		m["lastModified"] = fi.LastModified
+	}
+	return m
+}
+
+// FetchBodyURL downloads a dataset body from a URL. If prev is non-nil and
+// refers to the same URL, the request is made conditional on the recorded
+// ETag & Last-Modified values, returning ErrBodyURLNotModified if the server
+// responds that nothing has changed. The returned file is named after the
+// last element of the URL path so body format can be detected from the file
+// extension
+func FetchBodyURL(ctx context.Context, bodyURL string, prev *BodyFetchInfo) (qfs.File, *BodyFetchInfo, error) {
+	u, err := url.Parse(bodyURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing body url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, nil, fmt.Errorf("body url must use http or https, got %q", bodyURL)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, bodyURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req = req.WithContext(ctx)
+	if prev != nil && prev.URL == bodyURL {
+		if prev.ETag != "" {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+		if prev.LastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.LastModified)
+		}
+	}
+
+	res, err := BodyFetchClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching body url: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		return nil, prev, ErrBodyURLNotModified
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("fetching body url: unexpected response status %d", res.StatusCode)
+	}
+
+	if res.ContentLength > MaxBodyFetchSize {
+		return nil, nil, fmt.Errorf("%w: %d bytes, max is %d", ErrBodyURLTooLarge, res.ContentLength, MaxBodyFetchSize)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(res.Body, MaxBodyFetchSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading body url response: %w", err)
+	}
+	if int64(len(data)) > MaxBodyFetchSize {
+		return nil, nil, fmt.Errorf("%w: max is %d bytes", ErrBodyURLTooLarge, MaxBodyFetchSize)
+	}
+
+	info := &BodyFetchInfo{
+		URL:          bodyURL,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now().UTC(),
+	}
+
+	filename := path.Base(u.Path)
+	if filename == "/" || filename == "." {
+		filename = "body"
+	}
+	return qfs.NewMemfileBytes(filename, data), info, nil
+}
+
+// CheckBodyURL makes a HEAD request to check whether a body URL has changed
+// since a previous fetch without downloading it, returning
+// ErrBodyURLNotModified if it hasn't. Servers that don't report an ETag or
+// Last-Modified date can't be checked, & are always considered changed
+func CheckBodyURL(ctx context.Context, bodyURL string, prev *BodyFetchInfo) (*BodyFetchInfo, error) {
+	u, err := url.Parse(bodyURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing body url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("body url must use http or https, got %q", bodyURL)
+	}
+
+	req, err := http.NewRequest(http.MethodHead, bodyURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	sameURL := prev != nil && prev.URL == bodyURL
+	if sameURL {
+		if prev.ETag != "" {
+			req.Header.Set("If-None-Match", prev.ETag)
+		}
+		if prev.LastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.LastModified)
+		}
+	}
+
+	res, err := BodyFetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("checking body url: %w", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		return prev, ErrBodyURLNotModified
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("checking body url: unexpected response status %d", res.StatusCode)
+	}
+
+	info := &BodyFetchInfo{
+		URL:          bodyURL,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now().UTC(),
+	}
+	// some servers ignore conditional headers on HEAD requests, compare
+	// validators directly
+	if sameURL && (info.ETag != "" || info.LastModified != "") &&
+		info.ETag == prev.ETag && info.LastModified == prev.LastModified {
+		return prev, ErrBodyURLNotModified
+	}
+	return info, nil
+}
@@ -0,0 +1,114 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchBodyURL(t *testing.T) {
+	ctx := context.Background()
+	const etag = `"abc123"`
+	const lastMod = "Wed, 21 Oct 2015 07:28:00 GMT"
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastMod)
+		w.Write([]byte("a,b,c\n1,2,3\n"))
+	}))
+	defer s.Close()
+
+	bodyURL := s.URL + "/data/body.csv"
+	f, info, err := FetchBodyURL(ctx, bodyURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.FileName() != "body.csv" {
+		t.Errorf("filename mismatch. want %q, got %q", "body.csv", f.FileName())
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a,b,c\n1,2,3\n" {
+		t.Errorf("body mismatch. got: %q", string(data))
+	}
+	if info.URL != bodyURL {
+		t.Errorf("url mismatch. want %q, got %q", bodyURL, info.URL)
+	}
+	if info.ETag != etag {
+		t.Errorf("etag mismatch. want %q, got %q", etag, info.ETag)
+	}
+	if info.LastModified != lastMod {
+		t.Errorf("last modified mismatch. want %q, got %q", lastMod, info.LastModified)
+	}
+
+	if _, _, err := FetchBodyURL(ctx, bodyURL, info); !errors.Is(err, ErrBodyURLNotModified) {
+		t.Errorf("expected conditional fetch to return ErrBodyURLNotModified, got: %v", err)
+	}
+
+	// a different url shouldn't send conditional headers
+	if _, _, err := FetchBodyURL(ctx, s.URL+"/other.csv", info); err != nil {
+		t.Errorf("expected fetching a different url to succeed, got: %s", err)
+	}
+
+	if _, _, err := FetchBodyURL(ctx, "ftp://example.com/body.csv", nil); err == nil {
+		t.Error("expected non-http url to error")
+	}
+
+	if _, ok := info.Map()["fetchedAt"]; ok {
+		t.Error("expected fetch time to be left out of metadata")
+	}
+
+	prevMax := MaxBodyFetchSize
+	MaxBodyFetchSize = 4
+	defer func() { MaxBodyFetchSize = prevMax }()
+	if _, _, err := FetchBodyURL(ctx, s.URL+"/other.csv", nil); !errors.Is(err, ErrBodyURLTooLarge) {
+		t.Errorf("expected oversized body to return ErrBodyURLTooLarge, got: %v", err)
+	}
+}
+
+func TestCheckBodyURL(t *testing.T) {
+	ctx := context.Background()
+	etag := `"v1"`
+	gets := 0
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			gets++
+		}
+		// ignore conditional headers, like some servers do for HEAD requests
+		w.Header().Set("ETag", etag)
+	}))
+	defer s.Close()
+
+	bodyURL := s.URL + "/body.csv"
+	info, err := CheckBodyURL(ctx, bodyURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ETag != etag {
+		t.Errorf("etag mismatch. want %q, got %q", etag, info.ETag)
+	}
+	if _, err := CheckBodyURL(ctx, bodyURL, info); !errors.Is(err, ErrBodyURLNotModified) {
+		t.Errorf("expected unchanged etag to return ErrBodyURLNotModified, got: %v", err)
+	}
+
+	etag = `"v2"`
+	if info, err = CheckBodyURL(ctx, bodyURL, info); err != nil {
+		t.Fatalf("expected changed etag to report a change, got: %v", err)
+	}
+	if info.ETag != etag {
+		t.Errorf("etag mismatch. want %q, got %q", etag, info.ETag)
+	}
+	if gets != 0 {
+		t.Errorf("expected checks to only make HEAD requests, got %d other requests", gets)
+	}
+}
@@ -0,0 +1,197 @@
+// Package lint checks datasets against a set of style & completeness rules,
+// like "meta.title is required" or "column names are snake_case". Rules are
+// pluggable: packages can Register additional rules, and the severity of each
+// rule can be adjusted or turned off with a Config
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/dataset"
+)
+
+// Severity is how serious a rule violation is
+type Severity string
+
+const (
+	// SeverityOff disables a rule
+	SeverityOff = Severity("off")
+	// SeverityInfo marks suggestions
+	SeverityInfo = Severity("info")
+	// SeverityWarning marks problems that should be fixed
+	SeverityWarning = Severity("warning")
+	// SeverityError marks problems that must be fixed. Errors block saves when
+	// Config.BlockSaves is set
+	SeverityError = Severity("error")
+)
+
+// ParseSeverity checks a string is a valid severity
+func ParseSeverity(s string) (Severity, error) {
+	switch sev := Severity(s); sev {
+	case SeverityOff, SeverityInfo, SeverityWarning, SeverityError:
+		return sev, nil
+	default:
+		return "", fmt.Errorf("invalid lint severity %q, must be one of off, info, warning, or error", s)
+	}
+}
+
+// ErrLintFailed is wrapped by errors returned when lint errors block a save
+var ErrLintFailed = errors.New("dataset failed lint checks")
+
+// Finding is a single rule violation reported by a check
+type Finding struct {
+	// Component is the dataset component the finding is about, eg: "meta"
+	Component string `json:"component"`
+	Message   string `json:"message"`
+}
+
+// CheckFunc inspects a dataset, returning any findings
+type CheckFunc func(ds *dataset.Dataset) []Finding
+
+// Rule is a named check with a default severity
+type Rule struct {
+	ID          string
+	Description string
+	Severity    Severity
+	Check       CheckFunc
+}
+
+var (
+	rulesLk sync.Mutex
+	rules   = map[string]Rule{}
+)
+
+// Register adds a rule to the set every linter runs. Registering a rule with
+// an ID that's already in use panics
+func Register(r Rule) {
+	rulesLk.Lock()
+	defer rulesLk.Unlock()
+	if _, exists := rules[r.ID]; exists {
+		panic(fmt.Sprintf("lint rule %q is already registered", r.ID))
+	}
+	rules[r.ID] = r
+}
+
+// Rules lists registered rules, ordered by ID
+func Rules() []Rule {
+	rulesLk.Lock()
+	defer rulesLk.Unlock()
+	res := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// Config adjusts linting
+type Config struct {
+	// Severities overrides the default severity of rules, keyed by rule ID
+	Severities map[string]Severity
+	// BlockSaves makes saves fail when linting finds any errors
+	BlockSaves bool
+}
+
+// Validate returns an error if the config references unknown rules
+func (c Config) Validate() error {
+	rulesLk.Lock()
+	defer rulesLk.Unlock()
+	for id, sev := range c.Severities {
+		if _, ok := rules[id]; !ok {
+			return fmt.Errorf("unknown lint rule %q", id)
+		}
+		if _, err := ParseSeverity(string(sev)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Problem is a finding from a rule at the rule's configured severity
+type Problem struct {
+	Rule      string   `json:"rule"`
+	Severity  Severity `json:"severity"`
+	Component string   `json:"component"`
+	Message   string   `json:"message"`
+}
+
+// Report is the result of linting a dataset
+type Report struct {
+	Ref      string    `json:"ref,omitempty"`
+	Problems []Problem `json:"problems"`
+}
+
+// Count returns the number of problems with a given severity
+func (r *Report) Count(sev Severity) int {
+	n := 0
+	for _, p := range r.Problems {
+		if p.Severity == sev {
+			n++
+		}
+	}
+	return n
+}
+
+// HasErrors returns true if the report contains any error-level problems
+func (r *Report) HasErrors() bool {
+	return r.Count(SeverityError) > 0
+}
+
+// Err returns an error wrapping ErrLintFailed that lists error-level problems,
+// or nil if the report has no errors
+func (r *Report) Err() error {
+	if !r.HasErrors() {
+		return nil
+	}
+	msgs := []string{}
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", p.Rule, p.Message))
+		}
+	}
+	return fmt.Errorf("%w:\n  %s", ErrLintFailed, strings.Join(msgs, "\n  "))
+}
+
+// Lint runs all registered rules against a dataset. Problems are ordered by
+// severity, most severe first, then by rule ID
+func Lint(ds *dataset.Dataset, cfg Config) *Report {
+	report := &Report{Problems: []Problem{}}
+	for _, rule := range Rules() {
+		sev := rule.Severity
+		if s, ok := cfg.Severities[rule.ID]; ok {
+			sev = s
+		}
+		if sev == SeverityOff {
+			continue
+		}
+		for _, f := range rule.Check(ds) {
+			report.Problems = append(report.Problems, Problem{
+				Rule:      rule.ID,
+				Severity:  sev,
+				Component: f.Component,
+				Message:   f.Message,
+			})
+		}
+	}
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		return rank(report.Problems[i].Severity) > rank(report.Problems[j].Severity)
+	})
+	return report
+}
+
+func rank(s Severity) int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,94 @@
+package lint
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+)
+
+func TestLint(t *testing.T) {
+	ds := &dataset.Dataset{
+		Meta: &dataset.Meta{Title: "Cities", Description: "cities of the world"},
+		Structure: &dataset.Structure{
+			Format: "csv",
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": []interface{}{
+						map[string]interface{}{"title": "city_name", "type": "string", "description": "name of the city"},
+						map[string]interface{}{"title": "Population", "type": "integer", "description": " "},
+					},
+				},
+			},
+		},
+	}
+
+	report := Lint(ds, Config{})
+	expect := []Problem{
+		{Rule: "meta-license", Severity: SeverityWarning, Component: "meta", Message: "license must be set"},
+		{Rule: "no-empty-descriptions", Severity: SeverityWarning, Component: "structure", Message: "column Population has an empty description"},
+		{Rule: "column-names-snake-case", Severity: SeverityInfo, Component: "structure", Message: `column name "Population" isn't snake_case`},
+	}
+	if diff := cmp.Diff(expect, report.Problems); diff != "" {
+		t.Errorf("problems mismatch (-want +got):\n%s", diff)
+	}
+	if report.HasErrors() || report.Err() != nil {
+		t.Errorf("expected default severities to produce no errors")
+	}
+
+	cfg := Config{Severities: map[string]Severity{
+		"meta-license":            SeverityError,
+		"column-names-snake-case": SeverityOff,
+	}}
+	report = Lint(ds, cfg)
+	if len(report.Problems) != 2 || report.Problems[0].Rule != "meta-license" || report.Count(SeverityError) != 1 {
+		t.Errorf("expected severity overrides to apply, got: %#v", report.Problems)
+	}
+	if err := report.Err(); !errors.Is(err, ErrLintFailed) {
+		t.Errorf("expected error wrapping ErrLintFailed, got: %v", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (Config{Severities: map[string]Severity{"meta-title": SeverityError}}).Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := (Config{Severities: map[string]Severity{"not-a-rule": SeverityError}}).Validate(); err == nil {
+		t.Error("expected unknown rule to error")
+	}
+	if err := (Config{Severities: map[string]Severity{"meta-title": "fatal"}}).Validate(); err == nil {
+		t.Error("expected invalid severity to error")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(Rule{
+		ID:       "test-no-readme",
+		Severity: SeverityError,
+		Check: func(ds *dataset.Dataset) []Finding {
+			if ds.Readme == nil {
+				return []Finding{{Component: "readme", Message: "readme is missing"}}
+			}
+			return nil
+		},
+	})
+	defer func() {
+		rulesLk.Lock()
+		delete(rules, "test-no-readme")
+		rulesLk.Unlock()
+	}()
+
+	if report := Lint(&dataset.Dataset{}, Config{}); !report.HasErrors() {
+		t.Errorf("expected registered rule to run")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a duplicate rule to panic")
+		}
+	}()
+	Register(Rule{ID: "test-no-readme"})
+}
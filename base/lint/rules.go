@@ -0,0 +1,109 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/qri-io/dataset"
+)
+
+func init() {
+	Register(Rule{
+		ID:          "meta-title",
+		Description: "meta.title is required",
+		Severity:    SeverityWarning,
+		Check:       checkMetaTitle,
+	})
+	Register(Rule{
+		ID:          "meta-license",
+		Description: "a license must be set",
+		Severity:    SeverityWarning,
+		Check:       checkMetaLicense,
+	})
+	Register(Rule{
+		ID:          "column-names-snake-case",
+		Description: "column names are snake_case",
+		Severity:    SeverityInfo,
+		Check:       checkColumnNames,
+	})
+	Register(Rule{
+		ID:          "no-empty-descriptions",
+		Description: "meta & column descriptions aren't empty",
+		Severity:    SeverityWarning,
+		Check:       checkDescriptions,
+	})
+}
+
+func checkMetaTitle(ds *dataset.Dataset) []Finding {
+	if ds.Meta == nil || strings.TrimSpace(ds.Meta.Title) == "" {
+		return []Finding{{Component: "meta", Message: "meta.title is required"}}
+	}
+	return nil
+}
+
+func checkMetaLicense(ds *dataset.Dataset) []Finding {
+	if ds.Meta == nil || ds.Meta.License == nil || (ds.Meta.License.Type == "" && ds.Meta.License.URL == "") {
+		return []Finding{{Component: "meta", Message: "license must be set"}}
+	}
+	return nil
+}
+
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+func checkColumnNames(ds *dataset.Dataset) []Finding {
+	var res []Finding
+	for i, col := range schemaColumns(ds) {
+		title, _ := col["title"].(string)
+		if title == "" {
+			res = append(res, Finding{Component: "structure", Message: fmt.Sprintf("column %d has no name", i)})
+		} else if !snakeCase.MatchString(title) {
+			res = append(res, Finding{Component: "structure", Message: fmt.Sprintf("column name %q isn't snake_case", title)})
+		}
+	}
+	return res
+}
+
+func checkDescriptions(ds *dataset.Dataset) []Finding {
+	var res []Finding
+	if ds.Meta == nil || strings.TrimSpace(ds.Meta.Description) == "" {
+		res = append(res, Finding{Component: "meta", Message: "meta.description is empty"})
+	}
+	for i, col := range schemaColumns(ds) {
+		desc, ok := col["description"]
+		if !ok {
+			continue
+		}
+		if s, _ := desc.(string); strings.TrimSpace(s) == "" {
+			name, _ := col["title"].(string)
+			if name == "" {
+				name = fmt.Sprintf("%d", i)
+			}
+			res = append(res, Finding{Component: "structure", Message: fmt.Sprintf("column %s has an empty description", name)})
+		}
+	}
+	return res
+}
+
+// schemaColumns returns the column schemas of a tabular dataset, in order.
+// Non-tabular datasets have no columns
+func schemaColumns(ds *dataset.Dataset) []map[string]interface{} {
+	if ds.Structure == nil || ds.Structure.Schema == nil {
+		return nil
+	}
+	items, ok := ds.Structure.Schema["items"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	list, ok := items["items"].([]interface{})
+	if !ok {
+		return nil
+	}
+	cols := make([]map[string]interface{}, 0, len(list))
+	for _, c := range list {
+		if col, ok := c.(map[string]interface{}); ok {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
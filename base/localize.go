@@ -0,0 +1,139 @@
+package base
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+// MetaLocalizedKey is the meta field that stores language-tagged variants of
+// a dataset's title, description & readme, keyed by language tag
+const MetaLocalizedKey = "localized"
+
+// LocalizedFields are the fields a localized variant can set
+var LocalizedFields = []string{"title", "description", "readme"}
+
+// Localized is a language-tagged variant of a dataset's human-readable text.
+// Empty fields fall back to the text stored in the dataset
+type Localized struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Readme      string `json:"readme,omitempty"`
+}
+
+// LocalizedVariants reads the language-tagged variants stored in a meta
+// component
+func LocalizedVariants(md *dataset.Meta) map[string]Localized {
+	if md == nil {
+		return nil
+	}
+	data, err := json.Marshal(md)
+	if err != nil {
+		return nil
+	}
+	v := struct {
+		Localized map[string]Localized `json:"localized"`
+	}{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		log.Debugf("reading localized meta: %s", err)
+		return nil
+	}
+	return v.Localized
+}
+
+// ParseAcceptLanguage reads language tags from an Accept-Language header
+// value, ordered from most to least preferred. A plain tag like "es" parses as
+// a single preference
+func ParseAcceptLanguage(header string) []string {
+	type pref struct {
+		tag string
+		q   float64
+	}
+	prefs := []pref{}
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(params[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && kv[0] == "q" {
+				if v, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			prefs = append(prefs, pref{tag, q})
+		}
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+
+	tags := make([]string, len(prefs))
+	for i, p := range prefs {
+		tags[i] = p.tag
+	}
+	return tags
+}
+
+// MatchLanguage picks the available language that best satisfies a list of
+// preferences. Each preference first matches exactly, then by primary
+// language, so "es-MX" matches an "es" variant. Returns the empty string if
+// no language matches
+func MatchLanguage(prefs, available []string) string {
+	for _, p := range prefs {
+		for _, a := range available {
+			if strings.EqualFold(p, a) {
+				return a
+			}
+		}
+		primary := strings.SplitN(p, "-", 2)[0]
+		for _, a := range available {
+			if strings.EqualFold(primary, strings.SplitN(a, "-", 2)[0]) {
+				return a
+			}
+		}
+	}
+	return ""
+}
+
+// Localize replaces a dataset's title, description & readme with the stored
+// variant that best matches a list of language preferences, returning the
+// language applied. Datasets without a matching variant are not modified
+func Localize(ds *dataset.Dataset, prefs []string) string {
+	if ds == nil || len(prefs) == 0 {
+		return ""
+	}
+	variants := LocalizedVariants(ds.Meta)
+	available := make([]string, 0, len(variants))
+	for lang := range variants {
+		available = append(available, lang)
+	}
+	sort.Strings(available)
+	lang := MatchLanguage(prefs, available)
+	if lang == "" {
+		return ""
+	}
+
+	v := variants[lang]
+	if v.Title != "" {
+		ds.Meta.Title = v.Title
+	}
+	if v.Description != "" {
+		ds.Meta.Description = v.Description
+	}
+	if v.Readme != "" {
+		if ds.Readme == nil {
+			ds.Readme = &dataset.Readme{Format: "md"}
+		}
+		ds.Readme.ScriptBytes = []byte(v.Readme)
+		ds.Readme.SetScriptFile(qfs.NewMemfileBytes("readme.md", []byte(v.Readme)))
+	}
+	return lang
+}
@@ -0,0 +1,89 @@
+package base
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"es", []string{"es"}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5", []string{"fr-ch", "fr", "en"}},
+		{"en;q=0.5, es-MX, de;q=0", []string{"es-mx", "en"}},
+	}
+	for _, c := range cases {
+		if diff := cmp.Diff(c.want, ParseAcceptLanguage(c.header)); diff != "" {
+			t.Errorf("%q result mismatch (-want +got):\n%s", c.header, diff)
+		}
+	}
+}
+
+func TestMatchLanguage(t *testing.T) {
+	available := []string{"en", "es", "pt-BR"}
+	cases := []struct {
+		prefs []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"de"}, ""},
+		{[]string{"es"}, "es"},
+		{[]string{"es-mx"}, "es"},
+		{[]string{"pt-br"}, "pt-BR"},
+		{[]string{"pt"}, "pt-BR"},
+		{[]string{"de", "en"}, "en"},
+	}
+	for _, c := range cases {
+		if got := MatchLanguage(c.prefs, available); got != c.want {
+			t.Errorf("%v: want %q, got %q", c.prefs, c.want, got)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	md, err := PatchMeta(&dataset.Meta{Title: "Rainfall", Description: "monthly rainfall"}, map[string]interface{}{
+		MetaLocalizedKey: map[string]interface{}{
+			"es": map[string]interface{}{
+				"title":  "Lluvia",
+				"readme": "# Lluvia mensual",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds := &dataset.Dataset{Meta: md}
+	if lang := Localize(ds, []string{"de"}); lang != "" {
+		t.Errorf("expected no language to match, got %q", lang)
+	}
+	if ds.Meta.Title != "Rainfall" {
+		t.Errorf("expected unmatched localize to leave title unchanged, got %q", ds.Meta.Title)
+	}
+
+	if lang := Localize(ds, []string{"es-ES", "en"}); lang != "es" {
+		t.Errorf("expected language %q, got %q", "es", lang)
+	}
+	if ds.Meta.Title != "Lluvia" {
+		t.Errorf("title mismatch. want %q, got %q", "Lluvia", ds.Meta.Title)
+	}
+	if ds.Meta.Description != "monthly rainfall" {
+		t.Errorf("expected description without a variant to fall back, got %q", ds.Meta.Description)
+	}
+	if ds.Readme == nil {
+		t.Fatal("expected localize to add a readme")
+	}
+	data, err := ioutil.ReadAll(ds.Readme.ScriptFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "# Lluvia mensual" {
+		t.Errorf("readme mismatch. got %q", data)
+	}
+}
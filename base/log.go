@@ -17,7 +17,9 @@ const TimeoutDuration = 100 * time.Millisecond
 // ErrDatasetLogTimeout is an error for when getting the datasetLog times out
 var ErrDatasetLogTimeout = fmt.Errorf("datasetLog: timeout")
 
-// DatasetLog fetches the change version history of a dataset
+// DatasetLog fetches the change version history of a dataset. When the
+// logbook has no history for ref, history is read by walking commit ancestry
+// from ref.Path with dsfs.History
 func DatasetLog(ctx context.Context, r repo.Repo, ref dsref.Ref, limit, offset int, loadDatasets bool) ([]dsref.VersionInfo, error) {
 	if book := r.Logbook(); book != nil {
 		if items, err := book.Items(ctx, ref, offset, limit); err == nil {
@@ -53,12 +55,33 @@ func DatasetLog(ctx context.Context, r repo.Repo, ref dsref.Ref, limit, offset i
 		return nil, fmt.Errorf("cannot build history: %w", dsref.ErrPathRequired)
 	}
 
-	datasets, err := StoredHistoricalDatasets(ctx, r, ref.Path, offset, limit, loadDatasets)
+	// without logbook data, walk commit ancestry. dsfs.History loads commit &
+	// structure components, the rest are loaded when loadDatasets is true
+	depth := -1
+	if limit >= 0 {
+		depth = offset + limit
+	}
+	datasets, err := dsfs.History(ctx, r.Filesystem(), ref.Path, depth)
 	if err != nil {
-		return nil, err
+		log.Debugw("walking dataset history", "path", ref.Path, "err", err)
+		if len(datasets) == 0 {
+			// versions that can't be loaded are usually foreign, and would only be
+			// found by fetching from the network
+			return nil, ErrDatasetLogTimeout
+		}
+	}
+	if offset > len(datasets) {
+		offset = len(datasets)
 	}
+	datasets = datasets[offset:]
+
 	items := make([]dsref.VersionInfo, len(datasets))
 	for i, ds := range datasets {
+		if loadDatasets {
+			if derefErr := dsfs.DerefMeta(ctx, r.Filesystem(), ds); derefErr != nil {
+				log.Debugw("loading history meta", "path", ds.Path, "err", derefErr)
+			}
+		}
 		ds.Name = ref.Name
 		ds.Peername = ref.Username
 		ds.ProfileID = ref.ProfileID
@@ -78,6 +101,125 @@ func DatasetLog(ctx context.Context, r repo.Repo, ref dsref.Ref, limit, offset i
 	return items, err
 }
 
+// Component change statuses
+const (
+	// ComponentAdded marks a component that isn't in the previous version
+	ComponentAdded = "add"
+	// ComponentModified marks a component with different content than the
+	// previous version
+	ComponentModified = "modified"
+	// ComponentRemoved marks a component that was in the previous version
+	ComponentRemoved = "removed"
+)
+
+// ComponentChange is a component that differs from the previous version
+type ComponentChange struct {
+	Component string `json:"component"`
+	Status    string `json:"status"`
+}
+
+// VersionComponents is a dataset version & the components it changed
+type VersionComponents struct {
+	dsref.VersionInfo
+	// Changes lists changed components in the order meta, structure, body,
+	// readme, transform, viz. Changes is nil when the previous version couldn't
+	// be loaded
+	Changes []ComponentChange `json:"changes"`
+}
+
+// ComponentHistory lists which components each version of a dataset changed,
+// newest first. Components are content-addressed, so a component that didn't
+// change keeps the same path across versions, and versions are compared by
+// component path alone, without loading component contents
+func ComponentHistory(ctx context.Context, r repo.Repo, ref dsref.Ref, limit, offset int) ([]VersionComponents, error) {
+	if ref.Path == "" {
+		return nil, fmt.Errorf("cannot build component history: %w", dsref.ErrPathRequired)
+	}
+
+	// load one version past the requested page to compare the oldest version
+	// against
+	depth := -1
+	if limit >= 0 {
+		depth = offset + limit + 1
+	}
+	datasets, err := dsfs.History(ctx, r.Filesystem(), ref.Path, depth)
+	if err != nil {
+		log.Debugw("walking dataset history", "path", ref.Path, "err", err)
+		if len(datasets) == 0 {
+			return nil, err
+		}
+	}
+
+	items := []VersionComponents{}
+	for i := offset; i < len(datasets); i++ {
+		if limit >= 0 && len(items) == limit {
+			break
+		}
+		ds := datasets[i]
+		ds.Name = ref.Name
+		ds.Peername = ref.Username
+		ds.ProfileID = ref.ProfileID
+		item := VersionComponents{VersionInfo: dsref.ConvertDatasetToVersionInfo(ds)}
+		switch {
+		case i+1 < len(datasets):
+			item.Changes = componentChanges(datasets[i+1], ds)
+		case ds.PreviousPath == "":
+			item.Changes = componentChanges(nil, ds)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// componentPaths maps component names to paths for every component a dataset
+// version has. Commits are left out, every version has a new one
+func componentPaths(ds *dataset.Dataset) map[string]string {
+	paths := map[string]string{}
+	if ds == nil {
+		return paths
+	}
+	if ds.Meta != nil {
+		paths["meta"] = ds.Meta.Path
+	}
+	if ds.Structure != nil {
+		paths["structure"] = ds.Structure.Path
+	}
+	if ds.BodyPath != "" {
+		paths["body"] = ds.BodyPath
+	}
+	if ds.Readme != nil {
+		paths["readme"] = ds.Readme.Path
+	}
+	if ds.Transform != nil {
+		paths["transform"] = ds.Transform.Path
+	}
+	if ds.Viz != nil {
+		paths["viz"] = ds.Viz.Path
+	}
+	return paths
+}
+
+// componentChanges compares the components of two versions. prev is nil for
+// the initial version, where every component is added
+func componentChanges(prev, ds *dataset.Dataset) []ComponentChange {
+	before, after := componentPaths(prev), componentPaths(ds)
+	changes := []ComponentChange{}
+	for _, name := range []string{"meta", "structure", "body", "readme", "transform", "viz"} {
+		was, hadIt := before[name]
+		is, hasIt := after[name]
+		switch {
+		case hasIt && !hadIt:
+			changes = append(changes, ComponentChange{Component: name, Status: ComponentAdded})
+		case hadIt && !hasIt:
+			changes = append(changes, ComponentChange{Component: name, Status: ComponentRemoved})
+		case hasIt && was != is:
+			changes = append(changes, ComponentChange{Component: name, Status: ComponentModified})
+		}
+	}
+	return changes
+}
+
 // StoredHistoricalDatasets fetches the history of changes to a dataset by walking
 // backwards through dataset commits. if loadDatasets is true, dataset
 // information will be populated
@@ -140,6 +282,12 @@ func constructDatasetLogFromHistory(ctx context.Context, r repo.Repo, ref dsref.
 		return err
 	}
 
+	// history is newest first, logs are constructed oldest first
+	for i := len(history)/2 - 1; i >= 0; i-- {
+		opp := len(history) - 1 - i
+		history[i], history[opp] = history[opp], history[i]
+	}
+
 	book := r.Logbook()
 	return book.ConstructDatasetLog(ctx, ref, history)
 }
@@ -160,6 +160,50 @@ func TestDatasetLogForeignTimeout(t *testing.T) {
 	}
 }
 
+func TestComponentHistory(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	addCitiesDataset(t, r)
+	head := updateCitiesDataset(t, r, "")
+
+	items, err := ComponentHistory(ctx, r, head, -1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("history length mismatch. expected: 2, got: %d", len(items))
+	}
+
+	expect := []ComponentChange{{Component: "meta", Status: ComponentModified}}
+	if diff := cmp.Diff(expect, items[0].Changes); diff != "" {
+		t.Errorf("head version changes mismatch (-want +got):\n%s", diff)
+	}
+	for _, ch := range items[1].Changes {
+		if ch.Status != ComponentAdded {
+			t.Errorf("expected initial version to add every component. got %q %s", ch.Component, ch.Status)
+		}
+	}
+	if len(items[1].Changes) == 0 || items[1].Changes[0].Component != "meta" {
+		t.Errorf("expected initial version to add meta first. got: %v", items[1].Changes)
+	}
+
+	// the last version of a page is still compared to its predecessor
+	page, err := ComponentHistory(ctx, r, head, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 1 {
+		t.Fatalf("page length mismatch. expected: 1, got: %d", len(page))
+	}
+	if diff := cmp.Diff(expect, page[0].Changes); diff != "" {
+		t.Errorf("paged changes mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := ComponentHistory(ctx, r, dsref.Ref{Username: "peer", Name: "cities"}, -1, 0); err == nil {
+		t.Error("expected a reference without a path to error")
+	}
+}
+
 func TestStoredHistoricalDatasets(t *testing.T) {
 	ctx := context.Background()
 	r := newTestRepo(t)
@@ -0,0 +1,66 @@
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/dataset"
+)
+
+// PatchMeta applies a JSON merge patch (RFC 7396) to a meta component,
+// returning a new meta component. The input meta is not modified. Patching
+// with an explicit null removes a field
+func PatchMeta(md *dataset.Meta, patch map[string]interface{}) (*dataset.Meta, error) {
+	if patch == nil {
+		return nil, fmt.Errorf("patch is required")
+	}
+
+	target := map[string]interface{}{}
+	if md != nil {
+		data, err := json.Marshal(md)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &target); err != nil {
+			return nil, err
+		}
+	}
+
+	merged, ok := mergePatch(target, patch).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("patch must be an object")
+	}
+	// meta components are always identified by the "md" qri kind
+	merged["qri"] = dataset.KindMeta.String()
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	res := &dataset.Meta{}
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, fmt.Errorf("invalid meta patch: %w", err)
+	}
+	return res, nil
+}
+
+// mergePatch implements the JSON merge patch algorithm, as described in
+// https://tools.ietf.org/html/rfc7396#section-2
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, val := range patchObj {
+		if val == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergePatch(targetObj[key], val)
+	}
+	return targetObj
+}
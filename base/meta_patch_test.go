@@ -0,0 +1,76 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+)
+
+func TestPatchMeta(t *testing.T) {
+	prev := &dataset.Meta{
+		Title:       "Airport Codes",
+		Description: "codes for airports",
+		Keywords:    []string{"airports"},
+		License:     &dataset.License{Type: "PDDL-1.0"},
+	}
+
+	got, err := PatchMeta(prev, map[string]interface{}{
+		"description": nil,
+		"keywords":    []interface{}{"airports", "travel"},
+		"license":     map[string]interface{}{"type": "CC0-1.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Title != "Airport Codes" {
+		t.Errorf("title mismatch. want %q, got %q", "Airport Codes", got.Title)
+	}
+	if got.Description != "" {
+		t.Errorf("expected description to be removed, got %q", got.Description)
+	}
+	if diff := cmp.Diff([]string{"airports", "travel"}, got.Keywords); diff != "" {
+		t.Errorf("keywords mismatch (-want +got):\n%s", diff)
+	}
+	if got.License == nil || got.License.Type != "CC0-1.0" {
+		t.Errorf("expected license type to be patched to %q, got: %v", "CC0-1.0", got.License)
+	}
+
+	if prev.Description != "codes for airports" {
+		t.Errorf("expected input meta to be unmodified")
+	}
+
+	if _, err := PatchMeta(prev, nil); err == nil {
+		t.Error("expected nil patch to error")
+	}
+
+	got, err = PatchMeta(nil, map[string]interface{}{"title": "from nothing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "from nothing" {
+		t.Errorf("expected patching a nil meta to set title, got: %q", got.Title)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		target, patch, expect interface{}
+	}{
+		{map[string]interface{}{"a": "b"}, map[string]interface{}{"a": "c"}, map[string]interface{}{"a": "c"}},
+		{map[string]interface{}{"a": "b"}, map[string]interface{}{"b": "c"}, map[string]interface{}{"a": "b", "b": "c"}},
+		{map[string]interface{}{"a": "b"}, map[string]interface{}{"a": nil}, map[string]interface{}{}},
+		{map[string]interface{}{"a": []interface{}{"b"}}, map[string]interface{}{"a": "c"}, map[string]interface{}{"a": "c"}},
+		{map[string]interface{}{"a": "foo"}, "bar", "bar"},
+		{map[string]interface{}{"e": nil}, map[string]interface{}{"a": 1}, map[string]interface{}{"e": nil, "a": 1}},
+		{map[string]interface{}{}, map[string]interface{}{"a": map[string]interface{}{"bb": map[string]interface{}{"ccc": nil}}}, map[string]interface{}{"a": map[string]interface{}{"bb": map[string]interface{}{}}}},
+	}
+
+	for i, c := range cases {
+		got := mergePatch(c.target, c.patch)
+		if diff := cmp.Diff(c.expect, got); diff != "" {
+			t.Errorf("case %d result mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+}
@@ -0,0 +1,600 @@
+// Package query evaluates JSONPath-style queries over decoded JSON values.
+// Queries extend dotted selectors like "commit.author" with brackets for
+// indexing, slicing, projecting & filtering lists:
+//
+//	meta.keywords[0]              first element of a list
+//	body[-1]                      last element of a list
+//	body[10:20]                   elements 10 through 19
+//	body[*].title                 the title field of every element
+//	body[?duration > 150].title   titles of elements matching a filter
+//	meta['access url']            fields that aren't plain identifiers
+//
+// Filters compare fields of each element, or the element itself as "@", to
+// numbers, 'strings', true, false & null with ==, !=, <, <=, > & >=, and can
+// be combined with &&, || & !. Like JMESPath, steps that follow a projection
+// apply to each projected element, dropping elements that evaluate to null
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IsQuery reports whether a selector must be evaluated as a query, rather
+// than looked up as a dotted path
+func IsQuery(selector string) bool {
+	return strings.Contains(selector, "[")
+}
+
+// Query is a parsed query
+type Query struct {
+	expr  string
+	steps []step
+}
+
+// Parse parses a query expression
+func Parse(expr string) (*Query, error) {
+	p := &parser{s: expr}
+	steps, err := p.path(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	return &Query{expr: expr, steps: steps}, nil
+}
+
+// String returns the query expression
+func (q *Query) String() string {
+	return q.expr
+}
+
+// Root returns the field a query starts with
+func (q *Query) Root() string {
+	return q.steps[0].field
+}
+
+// Eval applies a query to a value decoded from JSON, returning nil if nothing
+// matches
+func (q *Query) Eval(v interface{}) interface{} {
+	return eval(q.steps, v)
+}
+
+type stepKind int
+
+const (
+	fieldStep stepKind = iota
+	indexStep
+	sliceStep
+	wildcardStep
+	filterStep
+)
+
+type step struct {
+	kind       stepKind
+	field      string
+	index      int
+	start, end *int
+	filter     expr
+}
+
+// projects returns true for steps that produce a list remaining steps are
+// applied to element by element
+func (s step) projects() bool {
+	return s.kind == sliceStep || s.kind == wildcardStep || s.kind == filterStep
+}
+
+func eval(steps []step, v interface{}) interface{} {
+	for i, s := range steps {
+		if v == nil {
+			return nil
+		}
+		if s.projects() {
+			res := []interface{}{}
+			for _, el := range project(s, v) {
+				if got := eval(steps[i+1:], el); got != nil {
+					res = append(res, got)
+				}
+			}
+			return res
+		}
+
+		switch s.kind {
+		case fieldStep:
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			v = obj[s.field]
+		case indexStep:
+			list, ok := v.([]interface{})
+			if !ok {
+				return nil
+			}
+			i := s.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i < 0 || i >= len(list) {
+				return nil
+			}
+			v = list[i]
+		}
+	}
+	return v
+}
+
+// project returns the elements a projecting step selects
+func project(s step, v interface{}) []interface{} {
+	var list []interface{}
+	switch x := v.(type) {
+	case []interface{}:
+		list = x
+	case map[string]interface{}:
+		if s.kind == sliceStep {
+			return nil
+		}
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			list = append(list, x[k])
+		}
+	default:
+		return nil
+	}
+
+	switch s.kind {
+	case sliceStep:
+		start, end := 0, len(list)
+		if s.start != nil {
+			start = clamp(*s.start, len(list))
+		}
+		if s.end != nil {
+			end = clamp(*s.end, len(list))
+		}
+		if start >= end {
+			return nil
+		}
+		return list[start:end]
+	case filterStep:
+		var res []interface{}
+		for _, el := range list {
+			if truthy(s.filter.eval(el)) {
+				res = append(res, el)
+			}
+		}
+		return res
+	}
+	return list
+}
+
+// clamp converts a possibly negative slice bound to an index within a list
+func clamp(i, length int) int {
+	if i < 0 {
+		i += length
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > length {
+		return length
+	}
+	return i
+}
+
+// truthy follows JMESPath: null, false, empty strings, lists & objects are
+// false, everything else is true
+func truthy(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case string:
+		return x != ""
+	case []interface{}:
+		return len(x) > 0
+	case map[string]interface{}:
+		return len(x) > 0
+	}
+	return true
+}
+
+// expr is a filter expression
+type expr interface {
+	eval(v interface{}) interface{}
+}
+
+// pathExpr selects a value relative to the element being filtered
+type pathExpr []step
+
+func (e pathExpr) eval(v interface{}) interface{} {
+	return eval(e, v)
+}
+
+type literalExpr struct {
+	value interface{}
+}
+
+func (e literalExpr) eval(interface{}) interface{} {
+	return e.value
+}
+
+type notExpr struct {
+	x expr
+}
+
+func (e notExpr) eval(v interface{}) interface{} {
+	return !truthy(e.x.eval(v))
+}
+
+type logicExpr struct {
+	and  bool
+	l, r expr
+}
+
+func (e logicExpr) eval(v interface{}) interface{} {
+	if e.and {
+		return truthy(e.l.eval(v)) && truthy(e.r.eval(v))
+	}
+	return truthy(e.l.eval(v)) || truthy(e.r.eval(v))
+}
+
+type compareExpr struct {
+	op   string
+	l, r expr
+}
+
+func (e compareExpr) eval(v interface{}) interface{} {
+	l, r := e.l.eval(v), e.r.eval(v)
+	lf, lnum := toFloat(l)
+	rf, rnum := toFloat(r)
+
+	switch e.op {
+	case "==":
+		return equal(l, r)
+	case "!=":
+		return !equal(l, r)
+	}
+
+	var cmp int
+	if lnum && rnum {
+		switch {
+		case lf < rf:
+			cmp = -1
+		case lf > rf:
+			cmp = 1
+		}
+	} else if ls, ok := l.(string); ok {
+		rs, ok := r.(string)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(ls, rs)
+	} else {
+		return false
+	}
+
+	switch e.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+func equal(l, r interface{}) bool {
+	lf, lnum := toFloat(l)
+	rf, rnum := toFloat(r)
+	if lnum || rnum {
+		return lnum && rnum && lf == rf
+	}
+	return reflect.DeepEqual(l, r)
+}
+
+// toFloat converts the number types decoders produce to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid query %q at position %d: %s", p.s, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// path parses a sequence of steps. Relative paths used in filters may start
+// with "@", which refers to the element being filtered
+func (p *parser) path(relative bool) ([]step, error) {
+	var steps []step
+	if relative && p.peek() == '@' {
+		p.pos++
+	} else {
+		name := p.ident()
+		if name == "" {
+			return nil, p.errorf("expected a field name")
+		}
+		steps = append(steps, step{kind: fieldStep, field: name})
+	}
+
+	for {
+		switch p.peek() {
+		case '.':
+			p.pos++
+			name := p.ident()
+			if name == "" {
+				return nil, p.errorf("expected a field name")
+			}
+			steps = append(steps, step{kind: fieldStep, field: name})
+		case '[':
+			p.pos++
+			s, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, s)
+		default:
+			return steps, nil
+		}
+	}
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *parser) ident() string {
+	start := p.pos
+	for p.pos < len(p.s) && isIdentByte(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// bracket parses the contents of a bracketed step, after the opening bracket
+func (p *parser) bracket() (s step, err error) {
+	p.skipSpace()
+	switch c := p.peek(); {
+	case c == ']':
+		s = step{kind: wildcardStep}
+	case c == '*':
+		p.pos++
+		s = step{kind: wildcardStep}
+	case c == '?':
+		p.pos++
+		s.kind = filterStep
+		if s.filter, err = p.or(); err != nil {
+			return s, err
+		}
+	case c == '\'' || c == '"':
+		s.kind = fieldStep
+		if s.field, err = p.str(); err != nil {
+			return s, err
+		}
+	default:
+		if s, err = p.indexOrSlice(); err != nil {
+			return s, err
+		}
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return s, p.errorf("expected ']'")
+	}
+	p.pos++
+	return s, nil
+}
+
+func (p *parser) indexOrSlice() (step, error) {
+	start, err := p.optInt()
+	if err != nil {
+		return step{}, err
+	}
+	if p.peek() != ':' {
+		if start == nil {
+			return step{}, p.errorf("expected an index, slice, '*' or filter")
+		}
+		return step{kind: indexStep, index: *start}, nil
+	}
+	p.pos++
+	end, err := p.optInt()
+	if err != nil {
+		return step{}, err
+	}
+	return step{kind: sliceStep, start: start, end: end}, nil
+}
+
+func (p *parser) optInt() (*int, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, nil
+	}
+	i, err := strconv.Atoi(p.s[start:p.pos])
+	if err != nil {
+		return nil, p.errorf("invalid index %q", p.s[start:p.pos])
+	}
+	p.skipSpace()
+	return &i, nil
+}
+
+// str parses a single or double quoted string
+func (p *parser) str() (string, error) {
+	quote := p.s[p.pos]
+	end := strings.IndexByte(p.s[p.pos+1:], quote)
+	if end < 0 {
+		return "", p.errorf("unterminated string")
+	}
+	str := p.s[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return str, nil
+}
+
+func (p *parser) or() (expr, error) {
+	l, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); strings.HasPrefix(p.s[p.pos:], "||"); p.skipSpace() {
+		p.pos += 2
+		r, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l = logicExpr{l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) and() (expr, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpace(); strings.HasPrefix(p.s[p.pos:], "&&"); p.skipSpace() {
+		p.pos += 2
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l = logicExpr{and: true, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) unary() (expr, error) {
+	p.skipSpace()
+	switch p.peek() {
+	case '!':
+		if strings.HasPrefix(p.s[p.pos:], "!=") {
+			return nil, p.errorf("expected an operand")
+		}
+		p.pos++
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{x: x}, nil
+	case '(':
+		p.pos++
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return x, nil
+	}
+	return p.comparison()
+}
+
+var compareOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *parser) comparison() (expr, error) {
+	l, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range compareOps {
+		if strings.HasPrefix(p.s[p.pos:], op) {
+			p.pos += len(op)
+			r, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			return compareExpr{op: op, l: l, r: r}, nil
+		}
+	}
+	return l, nil
+}
+
+func (p *parser) operand() (expr, error) {
+	p.skipSpace()
+	c := p.peek()
+	switch {
+	case c == '\'' || c == '"':
+		s, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		return literalExpr{value: s}, nil
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.s) && strings.IndexByte("-+.eE0123456789", p.s[p.pos]) >= 0 {
+			p.pos++
+		}
+		f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.s[start:p.pos])
+		}
+		return literalExpr{value: f}, nil
+	case c == '@' || isIdentByte(c):
+		start := p.pos
+		switch p.ident() {
+		case "true":
+			return literalExpr{value: true}, nil
+		case "false":
+			return literalExpr{value: false}, nil
+		case "null":
+			return literalExpr{value: nil}, nil
+		}
+		p.pos = start
+		steps, err := p.path(true)
+		if err != nil {
+			return nil, err
+		}
+		return pathExpr(steps), nil
+	}
+	return nil, p.errorf("expected an operand")
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const movies = `{
+	"meta": { "title": "movies", "keywords": ["film", "imdb"], "access url": "https://example.com" },
+	"body": [
+		{ "movie_title": "Avatar", "duration": 178, "color": true },
+		{ "movie_title": "Spectre", "duration": 148, "color": true },
+		{ "movie_title": "Metropolis", "duration": 153, "color": false },
+		{ "movie_title": "Tangled", "duration": 100 }
+	]
+}`
+
+func TestEval(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(movies), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		query  string
+		expect string
+	}{
+		{"meta.title", `"movies"`},
+		{"meta.keywords[0]", `"film"`},
+		{"meta.keywords[-1]", `"imdb"`},
+		{"meta.keywords[5]", `null`},
+		{"meta['access url']", `"https://example.com"`},
+		{"body[1].movie_title", `"Spectre"`},
+		{"body[1:3].movie_title", `["Spectre","Metropolis"]`},
+		{"body[-1:].duration", `[100]`},
+		{"body[*].color", `[true,true,false]`},
+		{"body[].duration", `[178,148,153,100]`},
+		{"body[?duration>150].movie_title", `["Avatar","Metropolis"]`},
+		{"body[?duration >= 148 && color].movie_title", `["Avatar","Spectre"]`},
+		{"body[?!color].movie_title", `["Metropolis","Tangled"]`},
+		{"body[?movie_title == 'Tangled' || (duration < 150 && color == true)].duration", `[148,100]`},
+		{"body[?movie_title > 'S'].movie_title", `["Spectre","Tangled"]`},
+		{"body[?color != null].movie_title", `["Avatar","Spectre","Metropolis"]`},
+		{"meta.keywords[?@ == 'imdb']", `["imdb"]`},
+		{"meta[*]", `["https://example.com",["film","imdb"],"movies"]`},
+		{"body[?duration > 1000]", `[]`},
+		{"meta.title[0]", `null`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			q, err := Parse(c.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(q.Eval(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != c.expect {
+				t.Errorf("result mismatch.\nwant: %s\ngot:  %s", c.expect, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	bad := []string{
+		"",
+		"[0]",
+		"body[",
+		"body[?duration >]",
+		"body[?(duration > 1]",
+		"body['title]",
+		"body[x]",
+		"body.",
+		"body[0]extra",
+	}
+	for _, s := range bad {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("expected %q to fail to parse", s)
+		}
+	}
+}
+
+func TestIsQuery(t *testing.T) {
+	if IsQuery("commit.author") {
+		t.Error("expected dotted selector not to be a query")
+	}
+	if !IsQuery("body[0]") {
+		t.Error("expected bracketed selector to be a query")
+	}
+}
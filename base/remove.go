@@ -30,6 +30,34 @@ func RemoveEntireDataset(ctx context.Context, r repo.Repo, ref dsref.Ref, histor
 			removeErr = err
 		}
 	}
+	didRemoveRef, err := removeDatasetRef(ctx, r, ref)
+	if didRemoveRef != "" {
+		didRemove = appendString(didRemove, didRemoveRef)
+	}
+	if err != nil {
+		removeErr = err
+	}
+	return didRemove, removeErr
+}
+
+// TrashDataset removes the reference & history of a dataset, keeping stored
+// versions so the dataset can be brought back with RestoreDataset. Callers
+// are responsible for eventually removing stored versions with PurgeVersions
+func TrashDataset(ctx context.Context, r repo.Repo, ref dsref.Ref) (didRemove string, removeErr error) {
+	if _, err := r.Logbook().RefToInitID(ref); err == logbook.ErrNotFound {
+		// datasets without a log have no history to record the removal in, and
+		// RestoreDataset reconstructs history from stored versions
+		if _, err := repo.DeleteVersionInfoShim(ctx, r, ref); err != nil {
+			return "", err
+		}
+		return "refstore", nil
+	}
+	return removeDatasetRef(ctx, r, ref)
+}
+
+// removeDatasetRef writes the removal of a dataset to the logbook & removes
+// the dataset reference from the refstore, continuing on when an error occurs
+func removeDatasetRef(ctx context.Context, r repo.Repo, ref dsref.Ref) (didRemove string, removeErr error) {
 	// Write the deletion to the logbook.
 	book := r.Logbook()
 	// TODO(dustmop): When we switch to initIDs, use the initID passed to this function, retrieved
@@ -67,6 +95,69 @@ func RemoveEntireDataset(ctx context.Context, r repo.Repo, ref dsref.Ref, histor
 	return didRemove, removeErr
 }
 
+// RestoreDataset brings back a dataset removed with TrashDataset, where
+// ref.Path is the latest version when the dataset was removed. History is
+// reconstructed from stored versions
+func RestoreDataset(ctx context.Context, r repo.Repo, ref dsref.Ref) (*dsref.VersionInfo, error) {
+	if ref.Path == "" {
+		return nil, fmt.Errorf("need a dataset reference with a path")
+	}
+	ds, err := dsfs.LoadDataset(ctx, r.Filesystem(), ref.Path)
+	if err != nil {
+		return nil, fmt.Errorf("loading removed dataset: %w", err)
+	}
+
+	book := r.Logbook()
+	if ref.Username == book.Username() {
+		// drop the log that marks the dataset as removed. The logs of datasets
+		// owned by others were dropped on removal
+		if err := book.RemoveLog(ctx, ref); err != nil {
+			log.Debugw("restore: removing log", "ref", ref.Alias(), "err", err)
+		}
+		if err := constructDatasetLogFromHistory(ctx, r, ref); err != nil {
+			return nil, fmt.Errorf("restoring history: %w", err)
+		}
+	}
+
+	vi := dsref.ConvertDatasetToVersionInfo(ds)
+	vi.Username = ref.Username
+	vi.ProfileID = ref.ProfileID
+	vi.Name = ref.Name
+	vi.Path = ref.Path
+	if vi.InitID, err = book.RefToInitID(ref); err != nil {
+		log.Debugw("restore: getting init id", "ref", ref.Alias(), "err", err)
+	}
+	if err := repo.PutVersionInfoShim(ctx, r, &vi); err != nil {
+		return nil, err
+	}
+	return &vi, nil
+}
+
+// PurgeVersions removes stored data for every locally stored version of a
+// dataset, starting with the version at path & following previous versions.
+// PurgeVersions doesn't touch the dataset reference or history, and is meant
+// for datasets removed with TrashDataset. Returns the paths of removed
+// versions
+func PurgeVersions(ctx context.Context, r repo.Repo, path string) ([]string, error) {
+	fs := r.Filesystem()
+	purged := []string{}
+	for path != "" {
+		if local, err := fs.Has(ctx, path); err != nil || !local {
+			break
+		}
+		ds, err := dsfs.LoadDatasetRefs(ctx, fs, path)
+		if err != nil {
+			return purged, err
+		}
+		if err := fs.Delete(ctx, path); err != nil {
+			return purged, err
+		}
+		purged = append(purged, path)
+		path = ds.PreviousPath
+	}
+	return purged, nil
+}
+
 // RemoveNVersionsFromStore removes n versions of a dataset from the store starting with
 // the most recent version
 // when n == -1, remove all versions
@@ -156,6 +247,41 @@ func RemoveNVersionsFromStore(ctx context.Context, r repo.Repo, curr dsref.Ref,
 	return info, nil
 }
 
+// PruneVersions removes stored data for all but the keep most recent versions
+// of a dataset. History is kept, pruned versions remain in the logbook & can
+// be fetched again from a remote. PruneVersions returns the paths of removed
+// versions
+func PruneVersions(ctx context.Context, r repo.Repo, ref dsref.Ref, keep int) ([]string, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("must keep at least one version")
+	}
+	items, err := DatasetLog(ctx, r, ref, -1, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := r.Filesystem()
+	pruned := []string{}
+	versions := 0
+	for _, item := range items {
+		// skip transform runs that didn't create a version
+		if item.Path == "" {
+			continue
+		}
+		if versions++; versions <= keep {
+			continue
+		}
+		if local, err := fs.Has(ctx, item.Path); err != nil || !local {
+			continue
+		}
+		if err := fs.Delete(ctx, item.Path); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, item.Path)
+	}
+	return pruned, nil
+}
+
 // This is inefficient and not great style, use it here just as a convenience.
 func appendString(first, second string) string {
 	if first == "" {
@@ -203,3 +203,88 @@ ref "user/fake_ref_2@/mem/2" should exist in the store, but does NOT`
 		t.Errorf("case 'expect empty refs to exist' response mismatch: (-want +got):\n %s", diff)
 	}
 }
+
+func TestPruneVersions(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	refs := []dsref.Ref{addCitiesDataset(t, r)}
+	for i := 2; i <= 4; i++ {
+		refs = append(refs, updateCitiesDataset(t, r, fmt.Sprintf("example city data version %d", i)))
+	}
+
+	if _, err := PruneVersions(ctx, r, refs[3], 0); err == nil {
+		t.Error("expected pruning every version to error")
+	}
+
+	pruned, err := PruneVersions(ctx, r, refs[3], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{refs[1].Path, refs[0].Path}
+	if diff := cmp.Diff(expect, pruned); diff != "" {
+		t.Errorf("pruned paths mismatch (-want +got):\n%s", diff)
+	}
+	for i, ref := range refs {
+		local, err := r.Filesystem().Has(ctx, ref.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i >= 2; local != want {
+			t.Errorf("version %d: expected local to be %t", i, want)
+		}
+	}
+}
+
+func TestTrashRestoreDataset(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+
+	refs := []dsref.Ref{addCitiesDataset(t, r)}
+	for i := 2; i <= 3; i++ {
+		refs = append(refs, updateCitiesDataset(t, r, fmt.Sprintf("example city data version %d", i)))
+	}
+	head := refs[len(refs)-1]
+
+	if _, err := TrashDataset(ctx, r, head); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetVersionInfoShim(r, head); err == nil {
+		t.Error("expected trashed dataset reference to be removed")
+	}
+	if s := verifyRefsRemoved(ctx, r.Filesystem(), refs, 0); s != "" {
+		t.Errorf("expected trashed versions to be kept: %s", s)
+	}
+
+	vi, err := RestoreDataset(ctx, r, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vi.Path != head.Path {
+		t.Errorf("restored path mismatch. want: %q got: %q", head.Path, vi.Path)
+	}
+	if vi.InitID == "" {
+		t.Error("expected restored dataset to have an init id")
+	}
+	items, err := DatasetLog(ctx, r, head, -1, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != len(refs) {
+		t.Errorf("expected restored history to have %d versions, got %d", len(refs), len(items))
+	}
+
+	if _, err := TrashDataset(ctx, r, head); err != nil {
+		t.Fatal(err)
+	}
+	purged, err := PurgeVersions(ctx, r, head.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(purged) != len(refs) {
+		t.Errorf("expected %d purged versions, got %d", len(refs), len(purged))
+	}
+	if s := verifyRefsRemoved(ctx, r.Filesystem(), refs, len(refs)); s != "" {
+		t.Errorf("expected purged versions to be removed: %s", s)
+	}
+}
@@ -1,21 +1,189 @@
 package base
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"html"
 	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/qri-io/qfs"
 	"github.com/russross/blackfriday/v2"
 )
 
-// RenderReadme converts the markdown from the file into html.
+// ReadmeFormats lists the readme formats that can be rendered as html
+var ReadmeFormats = []string{"md", "html", "ipynb"}
+
+// ReadmeFormat returns the readme format of a filename, detected from the file
+// extension. Unknown extensions are assumed to be markdown
+func ReadmeFormat(filename string) string {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	for _, f := range ReadmeFormats {
+		if ext == f {
+			return f
+		}
+	}
+	return "md"
+}
+
+// RenderReadme converts the readme from the file into html, detecting the
+// readme format from the filename
 func RenderReadme(ctx context.Context, file qfs.File) (string, error) {
+	return RenderReadmeFormat(ctx, file, ReadmeFormat(file.FileName()))
+}
+
+// codeLanguageClass matches the class notebook code cells use to name their
+// language
+var codeLanguageClass = regexp.MustCompile(`^language-[\w+-]+$`)
+
+// RenderReadmeFormat converts a readme file of the given format into html.
+// Markdown is rendered, html is passed through & jupyter notebooks have their
+// cells & outputs converted. All output is sanitized, removing scripts &
+// other unsafe content. An empty format is treated as markdown
+func RenderReadmeFormat(ctx context.Context, file qfs.File, format string) (string, error) {
 	data, err := ioutil.ReadAll(file)
 	if err != nil {
 		return "", err
 	}
-	unsafe := blackfriday.Run(data)
-	htmlBytes := bluemonday.UGCPolicy().SanitizeBytes(unsafe)
-	return string(htmlBytes), nil
+
+	switch format {
+	case "", "md":
+		unsafe := blackfriday.Run(data)
+		return string(bluemonday.UGCPolicy().SanitizeBytes(unsafe)), nil
+	case "html":
+		return string(bluemonday.UGCPolicy().SanitizeBytes(data)), nil
+	case "ipynb":
+		unsafe, err := renderNotebook(data)
+		if err != nil {
+			return "", err
+		}
+		// notebook outputs commonly include inline images, code cells are
+		// marked with their language
+		policy := bluemonday.UGCPolicy()
+		policy.AllowDataURIImages()
+		policy.AllowAttrs("class").Matching(codeLanguageClass).OnElements("code")
+		return string(policy.SanitizeBytes(unsafe)), nil
+	}
+	return "", fmt.Errorf("unsupported readme format %q", format)
+}
+
+// notebook is the subset of the jupyter notebook format needed to render
+// a notebook as html
+type notebook struct {
+	Metadata struct {
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+	Cells []notebookCell `json:"cells"`
+}
+
+type notebookCell struct {
+	CellType string           `json:"cell_type"`
+	Source   notebookText     `json:"source"`
+	Outputs  []notebookOutput `json:"outputs"`
+}
+
+type notebookOutput struct {
+	OutputType string                     `json:"output_type"`
+	Text       notebookText               `json:"text"`
+	Data       map[string]json.RawMessage `json:"data"`
+	EName      string                     `json:"ename"`
+	EValue     string                     `json:"evalue"`
+}
+
+// notebookText is multiline text in a notebook, which may be stored as
+// either a string or a list of lines
+type notebookText string
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = notebookText(s)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return fmt.Errorf("notebook text must be a string or list of strings")
+	}
+	*t = notebookText(strings.Join(lines, ""))
+	return nil
+}
+
+// renderNotebook converts jupyter notebook JSON to unsanitized html
+func renderNotebook(data []byte) ([]byte, error) {
+	nb := &notebook{}
+	if err := json.Unmarshal(data, nb); err != nil {
+		return nil, fmt.Errorf("invalid notebook: %w", err)
+	}
+
+	lang := nb.Metadata.LanguageInfo.Name
+	buf := &bytes.Buffer{}
+	for _, cell := range nb.Cells {
+		switch cell.CellType {
+		case "markdown":
+			buf.Write(blackfriday.Run([]byte(cell.Source)))
+		case "code":
+			if lang != "" {
+				fmt.Fprintf(buf, "<pre><code class=\"language-%s\">", html.EscapeString(lang))
+			} else {
+				buf.WriteString("<pre><code>")
+			}
+			buf.WriteString(html.EscapeString(string(cell.Source)))
+			buf.WriteString("</code></pre>\n")
+			for _, out := range cell.Outputs {
+				writeNotebookOutput(buf, out)
+			}
+		default:
+			// raw cells have no defined rendering, show them as preformatted text
+			if cell.Source != "" {
+				fmt.Fprintf(buf, "<pre>%s</pre>\n", html.EscapeString(string(cell.Source)))
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeNotebookOutput writes the richest displayable representation of
+// a code cell output
+func writeNotebookOutput(buf *bytes.Buffer, out notebookOutput) {
+	switch out.OutputType {
+	case "stream":
+		fmt.Fprintf(buf, "<pre>%s</pre>\n", html.EscapeString(string(out.Text)))
+		return
+	case "error":
+		fmt.Fprintf(buf, "<pre>%s: %s</pre>\n", html.EscapeString(out.EName), html.EscapeString(out.EValue))
+		return
+	}
+
+	text := func(mimeType string) (string, bool) {
+		raw, ok := out.Data[mimeType]
+		if !ok {
+			return "", false
+		}
+		var t notebookText
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return "", false
+		}
+		return string(t), true
+	}
+
+	if s, ok := text("text/html"); ok {
+		buf.WriteString(s)
+		buf.WriteString("\n")
+	} else if s, ok := text("image/png"); ok {
+		fmt.Fprintf(buf, "<img src=\"data:image/png;base64,%s\"/>\n", strings.Join(strings.Fields(s), ""))
+	} else if s, ok := text("image/jpeg"); ok {
+		fmt.Fprintf(buf, "<img src=\"data:image/jpeg;base64,%s\"/>\n", strings.Join(strings.Fields(s), ""))
+	} else if s, ok := text("text/markdown"); ok {
+		buf.Write(blackfriday.Run([]byte(s)))
+	} else if s, ok := text("text/plain"); ok {
+		fmt.Fprintf(buf, "<pre>%s</pre>\n", html.EscapeString(s))
+	}
 }
@@ -59,3 +59,87 @@ done`))
 		t.Errorf("body component (-want +got):\n%s", diff)
 	}
 }
+
+func TestRenderReadmeFormat(t *testing.T) {
+	ctx := context.Background()
+
+	notebook := `{
+  "metadata": {"language_info": {"name": "python"}},
+  "cells": [
+    {"cell_type": "markdown", "source": ["# notebook\n", "\n", "some *text*"]},
+    {
+      "cell_type": "code",
+      "source": "print(1 < 2)",
+      "outputs": [
+        {"output_type": "stream", "name": "stdout", "text": ["True\n"]},
+        {"output_type": "display_data", "data": {"image/png": "aGk=\n", "text/plain": ["<Figure>"]}},
+        {"output_type": "execute_result", "data": {"text/html": ["<b>bold</b><script>alert('hi')</script>"]}}
+      ]
+    }
+  ]
+}`
+
+	cases := []struct {
+		description string
+		filename    string
+		format      string
+		input       string
+		expect      string
+	}{
+		{"html is sanitized", "readme.html", "html",
+			`<h1>hi</h1><script>alert('hi');</script><p onclick="alert('hi')">done</p>`,
+			`<h1>hi</h1><p>done</p>`,
+		},
+		{"notebook cells & outputs", "readme.ipynb", "ipynb",
+			notebook,
+			`<h1>notebook</h1>
+
+<p>some <em>text</em></p>
+<pre><code class="language-python">print(1 &lt; 2)</code></pre>
+<pre>True
+</pre>
+<img src="data:image/png;base64,aGk="/>
+<b>bold</b>
+`,
+		},
+		{"empty format is markdown", "readme", "",
+			`*hi*`,
+			"<p><em>hi</em></p>\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			got, err := RenderReadmeFormat(ctx, qfs.NewMemfileBytes(c.filename, []byte(c.input)), c.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.expect, got); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := RenderReadmeFormat(ctx, qfs.NewMemfileBytes("readme.txt", []byte("hi")), "txt"); err == nil {
+		t.Error("expected unsupported format to error")
+	}
+	if _, err := RenderReadmeFormat(ctx, qfs.NewMemfileBytes("readme.ipynb", []byte("not json")), "ipynb"); err == nil {
+		t.Error("expected invalid notebook to error")
+	}
+}
+
+func TestReadmeFormat(t *testing.T) {
+	cases := map[string]string{
+		"readme.md":    "md",
+		"README.HTML":  "html",
+		"notes.ipynb":  "ipynb",
+		"readme":       "md",
+		"readme.txt":   "md",
+		"/a/b/c.ipynb": "ipynb",
+	}
+	for filename, expect := range cases {
+		if got := ReadmeFormat(filename); got != expect {
+			t.Errorf("%q: expected %q, got %q", filename, expect, got)
+		}
+	}
+}
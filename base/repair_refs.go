@@ -0,0 +1,111 @@
+package base
+
+import (
+	"context"
+
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+const (
+	// RefRepaired marks a dataset reference moved to an earlier intact version
+	RefRepaired = "repaired"
+	// RefIrrecoverable marks a dataset with no intact version stored locally.
+	// Irrecoverable datasets may still be fetched from the network
+	RefIrrecoverable = "irrecoverable"
+)
+
+// RefRepair describes a dataset reference that points at a version that
+// isn't intact
+type RefRepair struct {
+	Ref dsref.Ref `json:"ref"`
+	// PrevPath is the broken version the reference pointed at
+	PrevPath string `json:"prevPath"`
+	// Path is the version the reference points at after repair, empty for
+	// irrecoverable datasets
+	Path   string `json:"path,omitempty"`
+	Status string `json:"status"`
+	// Skipped is the number of newer broken versions passed over
+	Skipped int    `json:"skipped"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RefRepairReport lists the dataset references repairing changed or couldn't
+// fix
+type RefRepairReport struct {
+	Checked       int         `json:"checked"`
+	Repaired      int         `json:"repaired"`
+	Irrecoverable int         `json:"irrecoverable"`
+	Repairs       []RefRepair `json:"repairs"`
+}
+
+// RepairRefs finds dataset references whose head version is missing blocks
+// or fails to load, like references left behind when the IPFS repo is
+// garbage collected outside of qri. Each broken reference is moved to the
+// latest version in the dataset's logbook history that is fully stored
+// locally. The logbook is not modified, so missing versions can be fetched
+// again later. With dryRun the report is built without updating references
+func RepairRefs(ctx context.Context, r repo.Repo, dryRun bool) (*RefRepairReport, error) {
+	count, err := r.RefCount()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := r.References(0, count)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := r.Filesystem()
+	book := r.Logbook()
+	report := &RefRepairReport{Repairs: []RefRepair{}}
+	for _, rr := range refs {
+		// foreign references are expected to point at versions stored elsewhere
+		if rr.Path == "" || rr.Foreign {
+			continue
+		}
+		report.Checked++
+		head := dsfs.VerifyVersion(ctx, fs, rr.Path)
+		if head.Status == dsfs.VerifyOK {
+			continue
+		}
+
+		ref := reporef.ConvertToVersionInfo(&rr).SimpleRef()
+		repair := RefRepair{Ref: ref, PrevPath: rr.Path, Status: RefIrrecoverable, Error: head.Error}
+		if book != nil {
+			if items, err := book.Items(ctx, ref, 0, -1); err == nil {
+				// items are ordered newest first
+				for _, item := range items {
+					if item.Path == "" || item.Path == rr.Path {
+						continue
+					}
+					if dsfs.VerifyVersion(ctx, fs, item.Path).Status != dsfs.VerifyOK {
+						repair.Skipped++
+						continue
+					}
+					repair.Path = item.Path
+					repair.Status = RefRepaired
+					repair.Error = ""
+					break
+				}
+			} else {
+				log.Debugf("repair refs: reading history of %s: %s", ref.Alias(), err)
+			}
+		}
+
+		if repair.Status == RefRepaired {
+			report.Repaired++
+			if !dryRun {
+				rr.Path = repair.Path
+				if err := r.PutRef(rr); err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			report.Irrecoverable++
+		}
+		report.Repairs = append(report.Repairs, repair)
+	}
+	return report, nil
+}
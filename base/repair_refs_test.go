@@ -0,0 +1,84 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func TestRepairRefs(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+	ctx, r := run.Context, run.Repo
+
+	// repairs follow logbook history, save versions with a logbook entry
+	ds := run.BuildDataset("repair_test", "json")
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`[1]`)))
+	first, err := run.SaveDataset(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds = run.BuildDataset("repair_test", "json")
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`[1,2]`)))
+	head, err := run.SaveDataset(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := RepairRefs(ctx, r, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 1 || len(report.Repairs) != 0 {
+		t.Errorf("expected intact repo to need no repairs, got: %#v", report)
+	}
+
+	// simulate an external garbage collection removing the head version block
+	fs := r.Filesystem()
+	if err := fs.Delete(ctx, head.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	if report, err = RepairRefs(ctx, r, true); err != nil {
+		t.Fatal(err)
+	}
+	if report.Repaired != 1 {
+		t.Fatalf("expected dry run to report 1 repair, got: %#v", report)
+	}
+	if got := resolvePath(t, r, head.Name); got != head.Path {
+		t.Errorf("expected dry run to leave ref at %q, got %q", head.Path, got)
+	}
+
+	if report, err = RepairRefs(ctx, r, false); err != nil {
+		t.Fatal(err)
+	}
+	rep := report.Repairs[0]
+	if rep.Status != RefRepaired || rep.PrevPath != head.Path || rep.Path != first.Path {
+		t.Errorf("unexpected repair: %#v", rep)
+	}
+	if got := resolvePath(t, r, head.Name); got != first.Path {
+		t.Errorf("expected ref to be repaired to %q, got %q", first.Path, got)
+	}
+
+	// with no intact versions left the dataset is irrecoverable
+	if err := fs.Delete(ctx, first.Path); err != nil {
+		t.Fatal(err)
+	}
+	if report, err = RepairRefs(ctx, r, false); err != nil {
+		t.Fatal(err)
+	}
+	if report.Irrecoverable != 1 || report.Repairs[0].Status != RefIrrecoverable {
+		t.Errorf("expected dataset to be irrecoverable, got: %#v", report)
+	}
+}
+
+func resolvePath(t *testing.T, r repo.Repo, name string) string {
+	t.Helper()
+	rr, err := r.GetRef(reporef.DatasetRef{Peername: testPeerProfile.Peername, Name: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rr.Path
+}
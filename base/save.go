@@ -7,7 +7,9 @@ import (
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/base/lint"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/transform/run"
 )
@@ -30,12 +32,87 @@ func SaveDataset(
 	sw SaveSwitches,
 ) (ds *dataset.Dataset, err error) {
 	log.Debugf("SaveDataset initID=%q prevPath=%q", initID, prevPath)
-	pro := r.Profiles().Owner()
-	log.Debugw("owner", "peername", pro.Peername, "privKeyIsNil", pro.PrivKey == nil, "privKey", pro.PrivKey)
 	if initID == "" {
 		return nil, fmt.Errorf("SaveDataset requires an initID")
 	}
 
+	changes, prev, err := prepareSave(ctx, r, prevPath, changes, &sw)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkSchemaCompatibility(prev, changes, &sw); err != nil {
+		return nil, err
+	}
+
+	// Write the dataset to storage and get back the new path
+	ds, err = CreateDataset(ctx, r, writeDest, changes, prev, sw)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write the save to logbook
+	if err = r.Logbook().WriteVersionSave(ctx, initID, ds, runState); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// PreviewSave computes the version SaveDataset would create from a set of
+// changes without writing anything to the repo. The new version is written to
+// a throwaway in-memory filesystem, so the returned dataset has a commit with
+// a title & message describing the changes, and freshly computed stats. The
+// previous version is returned alongside the preview, and is empty when
+// prevPath is empty
+func PreviewSave(ctx context.Context, r repo.Repo, prevPath string, changes *dataset.Dataset, sw SaveSwitches) (ds, prev *dataset.Dataset, err error) {
+	log.Debugf("PreviewSave prevPath=%q", prevPath)
+	changes, prev, err = prepareSave(ctx, r, prevPath, changes, &sw)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = checkSchemaCompatibility(prev, changes, &sw); err != nil {
+		return nil, nil, err
+	}
+	if err = Drop(changes, sw.Drop); err != nil {
+		return nil, nil, err
+	}
+	if err = ValidateDataset(changes); err != nil {
+		return nil, nil, err
+	}
+
+	sw.Pin = false
+	mem := qfs.NewMemFS()
+	pro := r.Profiles().Owner()
+	path, err := dsfs.CreateDataset(ctx, r.Filesystem(), mem, event.NilBus, changes, prev, pro.PrivKey, sw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ds, err = dsfs.LoadDataset(ctx, mem, path); err != nil {
+		return nil, nil, err
+	}
+	if err = dsfs.DerefDataset(ctx, mem, ds); err != nil {
+		return nil, nil, err
+	}
+	body, err := dsfs.LoadBody(ctx, mem, ds)
+	if err != nil {
+		return nil, nil, err
+	}
+	ds.SetBodyFile(body)
+	ds.ProfileID = pro.ID.String()
+	ds.Name = changes.Name
+	ds.Peername = pro.Peername
+	// the preview path only exists in the throwaway filesystem
+	ds.Path = ""
+	return ds, prev, nil
+}
+
+// prepareSave loads the previous version of a dataset & folds changes into
+// it, inferring missing values & linting the result. It returns the complete
+// dataset to write & the previous version
+func prepareSave(ctx context.Context, r repo.Repo, prevPath string, changes *dataset.Dataset, sw *SaveSwitches) (*dataset.Dataset, *dataset.Dataset, error) {
+	var err error
+	pro := r.Profiles().Owner()
+	log.Debugw("owner", "peername", pro.Peername, "privKeyIsNil", pro.PrivKey == nil, "privKey", pro.PrivKey)
 	prev := &dataset.Dataset{}
 	mutable := &dataset.Dataset{}
 	fs := r.Filesystem()
@@ -43,20 +120,20 @@ func SaveDataset(
 		// Load the dataset's most recent version, which will become the previous version after
 		// this save operation completes.
 		if prev, err = dsfs.LoadDataset(ctx, fs, prevPath); err != nil {
-			return
+			return nil, nil, err
 		}
 		if prev.BodyPath != "" {
 			var body qfs.File
 			body, err = dsfs.LoadBody(ctx, fs, prev)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			prev.SetBodyFile(body)
 		}
 		// Load a mutable copy of the dataset because most of the save path assuming we are doing
 		// a patch update to the current head, and not a full replacement.
 		if mutable, err = dsfs.LoadDataset(ctx, fs, prevPath); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// remove the commit. commit must be created from scratch with each new version
@@ -67,7 +144,16 @@ func SaveDataset(
 	// TODO(dustmop): Saving with only a structure is currently broken. See TestSaveBasicCommands
 	// in cmd/save_test.go
 	if prevPath == "" && changes.BodyFile() == nil && changes.Structure == nil {
-		return nil, fmt.Errorf("creating a new dataset requires a structure or a body")
+		return nil, nil, fmt.Errorf("creating a new dataset requires a structure or a body")
+	}
+
+	// extract metadata before the body is inspected, removing any description
+	// row that would otherwise be read as data
+	var extracted *ExtractedMeta
+	if sw.ExtractMeta {
+		if extracted, err = ExtractMeta(changes); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Handle a change in structure format.
@@ -78,18 +164,24 @@ func SaveDataset(
 			var f qfs.File
 			f, err = ConvertBodyFormat(changes.BodyFile(), changes.Structure, prev.Structure)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			// Set the new format on the change structure.
 			changes.Structure.Format = prev.Structure.Format
 			changes.SetBodyFile(f)
-		} else {
+		} else if sw.BodyFormat == "" {
+			// refuse format changes unless the body is converted to sw.BodyFormat
 			err = fmt.Errorf("Refusing to change structure from %s to %s",
 				prev.Structure.Format, changes.Structure.Format)
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
+	// only sample bodies that arrive without a schema, a schema provided with
+	// the changes always wins
+	sampleSchema := sw.Infer.Enabled() && changes.BodyFile() != nil && (changes.Structure == nil || changes.Structure.Schema == nil)
+	convertBody := sw.BodyFormat != "" && changes.BodyFile() != nil
+
 	if !sw.Replace {
 		// Treat the changes as a set of patches applied to the previous dataset
 		mutable.Assign(changes)
@@ -98,23 +190,40 @@ func SaveDataset(
 
 	// infer missing values
 	if err = InferValues(pro, changes); err != nil {
-		return
+		return nil, nil, err
 	}
-
-	// let's make history, if it exists
-	changes.PreviousPath = prevPath
-
-	// Write the dataset to storage and get back the new path
-	ds, err = CreateDataset(ctx, r, writeDest, changes, prev, sw)
-	if err != nil {
-		return nil, err
+	if sampleSchema {
+		if err = InferSchema(changes, sw.Infer); err != nil {
+			return nil, nil, err
+		}
+	}
+	if extracted != nil {
+		addCommitNote(sw, extracted.Apply(changes))
+	}
+	if convertBody && changes.Structure != nil && changes.Structure.Format != sw.BodyFormat {
+		log.Debugf("converting body format from=%q to=%q", changes.Structure.Format, sw.BodyFormat)
+		to := &dataset.Structure{Format: sw.BodyFormat, Schema: changes.Structure.Schema}
+		f, err := ConvertBodyFormat(changes.BodyFile(), changes.Structure, to)
+		if err != nil {
+			return nil, nil, fmt.Errorf("converting body to %s: %w", sw.BodyFormat, err)
+		}
+		changes.Structure.Format = sw.BodyFormat
+		changes.Structure.FormatConfig = nil
+		changes.SetBodyFile(f)
 	}
 
-	// Write the save to logbook
-	if err = r.Logbook().WriteVersionSave(ctx, initID, ds, runState); err != nil {
-		return nil, err
+	// lint the complete dataset before anything is written
+	report := lint.Lint(changes, sw.Lint)
+	if sw.Lint.BlockSaves && report.HasErrors() {
+		return nil, nil, report.Err()
 	}
-	return ds, nil
+	if len(report.Problems) > 0 {
+		log.Debugw("lint problems", "errors", report.Count(lint.SeverityError), "warnings", report.Count(lint.SeverityWarning))
+	}
+
+	// let's make history, if it exists
+	changes.PreviousPath = prevPath
+	return changes, prev, nil
 }
 
 // CreateDataset uses dsfs to add a dataset to a repo's store, updating the refstore
@@ -7,6 +7,7 @@ import (
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qfs/muxfs"
+	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/repo"
 )
@@ -46,6 +47,46 @@ func TestSaveDataset(t *testing.T) {
 	// path and replaced with apply.
 }
 
+func TestPreviewSave(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+
+	ds := run.BuildDataset("preview_test", "json")
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`[1,2,3]`)))
+	ref, err := run.SaveDataset(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := &dataset.Dataset{Name: "preview_test", Peername: "peer"}
+	changes.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`[1,2,3,4]`)))
+	preview, prev, err := PreviewSave(run.Context, run.Repo, ref.Path, changes, SaveSwitches{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Path != "" {
+		t.Errorf("expected preview to have no path, got %q", preview.Path)
+	}
+	if prev.Path != ref.Path {
+		t.Errorf("previous path mismatch. want: %q got: %q", ref.Path, prev.Path)
+	}
+	if preview.Commit == nil || preview.Commit.Title == "" {
+		t.Errorf("expected preview to have a generated commit title")
+	}
+	if preview.Stats == nil {
+		t.Errorf("expected preview to have stats")
+	}
+
+	// the repo head must be unchanged
+	head := dsref.Ref{Username: "peer", Name: "preview_test"}
+	if _, err := run.Repo.Logbook().ResolveRef(run.Context, &head); err != nil {
+		t.Fatal(err)
+	}
+	if head.Path != ref.Path {
+		t.Errorf("expected preview not to change the head. want: %q got: %q", ref.Path, head.Path)
+	}
+}
+
 func TestSaveDatasetWithoutStructureOrBody(t *testing.T) {
 	run := newTestRunner(t)
 	defer run.Delete()
@@ -0,0 +1,165 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/tabular"
+)
+
+// ErrIncompatibleSchema is wrapped by errors returned when a save requires a
+// backward compatible schema & the new schema removes or retypes columns
+var ErrIncompatibleSchema = errors.New("schema isn't backward compatible with the previous version")
+
+const (
+	// SchemaChangeRemoved marks a column missing from the new schema
+	SchemaChangeRemoved = "removed"
+	// SchemaChangeRetyped marks a column that no longer accepts all values of
+	// its previous type
+	SchemaChangeRetyped = "retyped"
+)
+
+// SchemaChange is a backward incompatible change to a column
+type SchemaChange struct {
+	Column string `json:"column"`
+	Kind   string `json:"kind"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+func (c SchemaChange) String() string {
+	if c.Kind == SchemaChangeRetyped {
+		return fmt.Sprintf("column %q changed type from %s to %s", c.Column, c.From, c.To)
+	}
+	return fmt.Sprintf("column %q was %s", c.Column, c.Kind)
+}
+
+// SchemaCompatibility is the verdict of comparing a schema to the schema of
+// the previous version. A schema is backward compatible if every previous
+// column still exists and accepts all values of its previous type. Adding
+// columns & widening types, like integer to number, are compatible
+type SchemaCompatibility struct {
+	Compatible bool           `json:"compatible"`
+	Changes    []SchemaChange `json:"changes,omitempty"`
+	// Skipped explains why schemas weren't compared, if they weren't
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// String formats the verdict for recording in a commit message
+func (sc SchemaCompatibility) String() string {
+	if sc.Skipped != "" {
+		return "schema compatibility: not checked, " + sc.Skipped
+	}
+	if sc.Compatible {
+		return "schema compatibility: compatible with the previous version"
+	}
+	changes := make([]string, len(sc.Changes))
+	for i, c := range sc.Changes {
+		changes[i] = c.String()
+	}
+	return "schema compatibility: breaking change. " + strings.Join(changes, ", ")
+}
+
+// CheckSchemaCompatibility compares the tabular schema of a structure with
+// the schema of the previous version. Non-tabular schemas aren't compared &
+// are considered compatible
+func CheckSchemaCompatibility(prev, next *dataset.Structure) SchemaCompatibility {
+	if prev == nil || prev.Schema == nil {
+		return SchemaCompatibility{Compatible: true, Skipped: "previous version has no schema"}
+	}
+	prevCols, _, err := tabular.ColumnsFromJSONSchema(prev.Schema)
+	if err != nil {
+		return SchemaCompatibility{Compatible: true, Skipped: "previous schema isn't tabular"}
+	}
+	// dropping the schema removes every column
+	var nextCols tabular.Columns
+	if next != nil && next.Schema != nil {
+		if nextCols, _, err = tabular.ColumnsFromJSONSchema(next.Schema); err != nil {
+			return SchemaCompatibility{Compatible: true, Skipped: "schema isn't tabular"}
+		}
+	}
+
+	nextByTitle := map[string]tabular.Column{}
+	for _, c := range nextCols {
+		nextByTitle[c.Title] = c
+	}
+
+	res := SchemaCompatibility{Compatible: true}
+	for _, pc := range prevCols {
+		nc, ok := nextByTitle[pc.Title]
+		if !ok {
+			res.Changes = append(res.Changes, SchemaChange{Column: pc.Title, Kind: SchemaChangeRemoved})
+			continue
+		}
+		if !acceptsColType(nc.Type, pc.Type) {
+			res.Changes = append(res.Changes, SchemaChange{
+				Column: pc.Title,
+				Kind:   SchemaChangeRetyped,
+				From:   colTypeString(pc.Type),
+				To:     colTypeString(nc.Type),
+			})
+		}
+	}
+	res.Compatible = len(res.Changes) == 0
+	return res
+}
+
+// acceptsColType checks a column type accepts every value of a previous type.
+// A missing type accepts any value
+func acceptsColType(next, prev *tabular.ColType) bool {
+	if next == nil {
+		return true
+	}
+	if prev == nil {
+		return false
+	}
+	for _, t := range *prev {
+		if next.HasType(t) {
+			continue
+		}
+		// numbers are a superset of integers
+		if t == "integer" && next.HasType("number") {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func colTypeString(t *tabular.ColType) string {
+	if t == nil || len(*t) == 0 {
+		return "any"
+	}
+	return strings.Join(*t, "|")
+}
+
+// checkSchemaCompatibility gates a save on schema compatibility if switches
+// require it, recording the verdict as a note on the commit message
+func checkSchemaCompatibility(prev, changes *dataset.Dataset, sw *SaveSwitches) error {
+	if !sw.RequireCompatibleSchema || prev == nil || prev.Structure == nil {
+		return nil
+	}
+	verdict := CheckSchemaCompatibility(prev.Structure, changes.Structure)
+	if !verdict.Compatible && !sw.AllowBreakingSchema {
+		msgs := make([]string, len(verdict.Changes))
+		for i, c := range verdict.Changes {
+			msgs[i] = c.String()
+		}
+		return fmt.Errorf("%w: %s", ErrIncompatibleSchema, strings.Join(msgs, ", "))
+	}
+	addCommitNote(sw, verdict.String())
+	return nil
+}
+
+// addCommitNote appends a line to the note added to the commit message
+func addCommitNote(sw *SaveSwitches, note string) {
+	if note == "" {
+		return
+	}
+	if sw.CommitNote != "" {
+		sw.CommitNote += "\n"
+	}
+	sw.CommitNote += note
+}
@@ -0,0 +1,85 @@
+package base
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func tabularStructure(cols ...map[string]interface{}) *dataset.Structure {
+	items := make([]interface{}, len(cols))
+	for i, c := range cols {
+		items[i] = c
+	}
+	return &dataset.Structure{
+		Format: "csv",
+		Schema: map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "array", "items": items},
+		},
+	}
+}
+
+func col(title string, typ interface{}) map[string]interface{} {
+	return map[string]interface{}{"title": title, "type": typ}
+}
+
+func TestCheckSchemaCompatibility(t *testing.T) {
+	prev := tabularStructure(col("name", "string"), col("count", "integer"))
+
+	cases := []struct {
+		description string
+		next        *dataset.Structure
+		compatible  bool
+		changes     int
+	}{
+		{"unchanged", tabularStructure(col("name", "string"), col("count", "integer")), true, 0},
+		{"added column", tabularStructure(col("name", "string"), col("count", "integer"), col("notes", "string")), true, 0},
+		{"reordered columns", tabularStructure(col("count", "integer"), col("name", "string")), true, 0},
+		{"widened to number", tabularStructure(col("name", "string"), col("count", "number")), true, 0},
+		{"made nullable", tabularStructure(col("name", "string"), col("count", []interface{}{"integer", "null"})), true, 0},
+		{"removed column", tabularStructure(col("name", "string")), false, 1},
+		{"renamed column", tabularStructure(col("title", "string"), col("count", "integer")), false, 1},
+		{"retyped column", tabularStructure(col("name", "string"), col("count", "string")), false, 1},
+		{"dropped schema", &dataset.Structure{Format: "csv"}, false, 2},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			got := CheckSchemaCompatibility(prev, c.next)
+			if got.Compatible != c.compatible || len(got.Changes) != c.changes {
+				t.Errorf("expected compatible=%t with %d changes, got: %#v", c.compatible, c.changes, got)
+			}
+		})
+	}
+
+	got := CheckSchemaCompatibility(&dataset.Structure{Format: "json", Schema: dataset.BaseSchemaObject}, prev)
+	if !got.Compatible || got.Skipped == "" {
+		t.Errorf("expected non-tabular schemas to be skipped, got: %#v", got)
+	}
+}
+
+func TestCheckSchemaCompatibilitySwitches(t *testing.T) {
+	prev := &dataset.Dataset{Structure: tabularStructure(col("name", "string"), col("count", "integer"))}
+	next := &dataset.Dataset{Structure: tabularStructure(col("name", "string"))}
+
+	sw := SaveSwitches{}
+	if err := checkSchemaCompatibility(prev, next, &sw); err != nil || sw.CommitNote != "" {
+		t.Errorf("expected no check without RequireCompatibleSchema. err: %v note: %q", err, sw.CommitNote)
+	}
+
+	sw = SaveSwitches{RequireCompatibleSchema: true}
+	if err := checkSchemaCompatibility(prev, next, &sw); !errors.Is(err, ErrIncompatibleSchema) {
+		t.Errorf("expected ErrIncompatibleSchema, got: %v", err)
+	}
+
+	sw = SaveSwitches{RequireCompatibleSchema: true, AllowBreakingSchema: true}
+	if err := checkSchemaCompatibility(prev, next, &sw); err != nil {
+		t.Fatal(err)
+	}
+	expect := `schema compatibility: breaking change. column "count" was removed`
+	if sw.CommitNote != expect {
+		t.Errorf("commit note mismatch.\nwant: %q\ngot:  %q", expect, sw.CommitNote)
+	}
+}
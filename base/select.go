@@ -2,17 +2,26 @@ package base
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/base/query"
 	"github.com/qri-io/qri/dsref"
 )
 
+// ErrQueryTooLarge indicates a query reads or returns more data than allowed
+var ErrQueryTooLarge = errors.New("query is too large")
+
 // Select loads a dataset value specified by case.Sensitve.dot.separated.paths
 func Select(ctx context.Context, fs qfs.Filesystem, ref dsref.Ref, valuePath string) (interface{}, error) {
 	ds, err := dsfs.LoadDataset(ctx, fs, ref.Path)
@@ -78,3 +87,87 @@ func pathValue(ds *dataset.Dataset, path string) (elem reflect.Value, err error)
 
 	return elem, nil
 }
+
+// QueryDataset evaluates a JSONPath-style query over the components of an
+// opened dataset, like "meta.keywords[0]" or "body[?duration > 150].title".
+// Queries starting with "body" read up to maxEntries body entries. Rows of
+// tabular bodies are queried as objects keyed by column title
+func QueryDataset(ds *dataset.Dataset, selector string, maxEntries int) (interface{}, error) {
+	q, err := query.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if root := q.Root(); root == "body" || root == "bd" {
+		body, err := queryBody(ds, maxEntries)
+		if err != nil {
+			return nil, err
+		}
+		doc = map[string]interface{}{root: body}
+	} else {
+		// round trip through JSON so fields are named by their JSON keys
+		data, err := json.Marshal(ds)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+	}
+	return q.Eval(doc), nil
+}
+
+// queryBody reads body entries into native values, converting tabular rows
+// to objects
+func queryBody(ds *dataset.Dataset, maxEntries int) (interface{}, error) {
+	if ds.Structure == nil || ds.BodyFile() == nil {
+		return nil, fmt.Errorf("no body to query")
+	}
+	if ds.Structure.Entries > maxEntries {
+		return nil, fmt.Errorf("%w: body has %d entries, queries can read at most %d", ErrQueryTooLarge, ds.Structure.Entries, maxEntries)
+	}
+	r, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var titles []string
+	if cols, _, err := tabular.ColumnsFromJSONSchema(ds.Structure.Schema); err == nil {
+		titles = cols.Titles()
+	}
+
+	obj := map[string]interface{}{}
+	array := []interface{}{}
+	for i := 0; ; i++ {
+		ent, err := r.ReadEntry()
+		if err != nil {
+			if err == io.EOF || err.Error() == "EOF" {
+				break
+			}
+			return nil, err
+		}
+		if i >= maxEntries {
+			return nil, fmt.Errorf("%w: queries can read at most %d body entries", ErrQueryTooLarge, maxEntries)
+		}
+		if ent.Key != "" {
+			obj[ent.Key] = ent.Value
+			continue
+		}
+		if row, ok := ent.Value.([]interface{}); ok && titles != nil {
+			rowObj := make(map[string]interface{}, len(row))
+			for j, v := range row {
+				if j < len(titles) {
+					rowObj[titles[j]] = v
+				}
+			}
+			ent.Value = rowObj
+		}
+		array = append(array, ent.Value)
+	}
+	if len(obj) > 0 {
+		return obj, nil
+	}
+	return array, nil
+}
@@ -0,0 +1,178 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+)
+
+// ErrSquashPublished indicates a squash would rewrite versions that have been
+// pushed to a remote
+var ErrSquashPublished = errors.New("cannot squash published versions")
+
+// SquashParams configure collapsing the latest versions of a dataset
+type SquashParams struct {
+	// Count is the number of versions to collapse, starting from the latest
+	Count int
+	// Title & Message of the squashed version. Title defaults to the title of
+	// the oldest squashed version, message defaults to a list of the titles &
+	// messages of every squashed version
+	Title   string
+	Message string
+	// Unpin removes the stored data of squashed versions
+	Unpin bool
+	// Force squashes versions that have been published
+	Force bool
+}
+
+// SquashResult describes a completed squash
+type SquashResult struct {
+	// Dataset is the new head version
+	Dataset *dataset.Dataset
+	// Squashed lists the paths of collapsed versions, newest first
+	Squashed []string
+	// Unpinned lists the paths of squashed versions removed from the store
+	Unpinned []string
+}
+
+// SquashVersions collapses the latest versions of a dataset into a single
+// new version with the contents of the current head. The new version follows
+// the version before the oldest squashed version. Squashed versions are
+// removed from the logbook, and history can't be rewritten once pushed
+// without p.Force
+func SquashVersions(ctx context.Context, r repo.Repo, writeDest qfs.Filesystem, ref dsref.Ref, p SquashParams) (*SquashResult, error) {
+	if p.Count < 2 {
+		return nil, fmt.Errorf("squash requires at least 2 versions, got %d", p.Count)
+	}
+	book := r.Logbook()
+	if ref.InitID == "" {
+		initID, err := book.RefToInitID(ref)
+		if err != nil {
+			return nil, err
+		}
+		ref.InitID = initID
+	}
+	pr, err := book.DatasetProtection(ctx, ref.InitID)
+	if err != nil {
+		return nil, err
+	}
+	if err := pr.CheckRewrite(); err != nil {
+		return nil, err
+	}
+
+	items, err := DatasetLog(ctx, r, ref, -1, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// collect versions newest first. transform runs recorded between squashed
+	// versions are dropped from history along with them
+	var (
+		squashed  []dsref.VersionInfo
+		removeOps int
+		prevPath  string
+	)
+	for i, item := range items {
+		if item.Path == "" {
+			continue
+		}
+		if len(squashed) == p.Count {
+			prevPath = item.Path
+			break
+		}
+		squashed = append(squashed, item)
+		removeOps = i + 1
+	}
+	if len(squashed) < p.Count {
+		return nil, fmt.Errorf("cannot squash %d versions, %s has %d", p.Count, ref.Alias(), len(squashed))
+	}
+	if !p.Force {
+		for _, vi := range squashed {
+			if vi.Published {
+				return nil, fmt.Errorf("%w: version %s has been pushed", ErrSquashPublished, vi.Path)
+			}
+		}
+	}
+
+	fs := r.Filesystem()
+	head, err := dsfs.LoadDataset(ctx, fs, squashed[0].Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := OpenDataset(ctx, fs, head); err != nil {
+		return nil, err
+	}
+
+	title, message := squashDescriptions(squashed)
+	if p.Title != "" {
+		title = p.Title
+	}
+	if p.Message != "" {
+		message = p.Message
+	}
+	head.Peername = ref.Username
+	head.Name = ref.Name
+	head.Path = ""
+	head.PreviousPath = ""
+	head.Commit = &dataset.Commit{Title: title, Message: message}
+
+	sw := SaveSwitches{
+		Replace:          true,
+		Pin:              true,
+		ForceIfNoChanges: true,
+	}
+	changes, prev, err := prepareSave(ctx, r, prevPath, head, &sw)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := CreateDataset(ctx, r, writeDest, changes, prev, sw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := book.WriteVersionDelete(ctx, ref.InitID, removeOps); err != nil {
+		return nil, err
+	}
+	if err := book.WriteVersionSave(ctx, ref.InitID, ds, nil); err != nil {
+		return nil, err
+	}
+
+	res := &SquashResult{Dataset: ds, Squashed: make([]string, len(squashed))}
+	for i, vi := range squashed {
+		res.Squashed[i] = vi.Path
+	}
+	if p.Unpin {
+		for _, path := range res.Squashed {
+			if err := fs.Delete(ctx, path); err != nil {
+				log.Debugf("squash: unpinning %s: %s", path, err)
+				continue
+			}
+			res.Unpinned = append(res.Unpinned, path)
+		}
+	}
+	return res, nil
+}
+
+// squashDescriptions combines the commit descriptions of versions ordered
+// newest first into a title & message
+func squashDescriptions(versions []dsref.VersionInfo) (title, message string) {
+	title = versions[len(versions)-1].CommitTitle
+	lines := make([]string, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		vi := versions[i]
+		line := "* " + vi.CommitTitle
+		if vi.CommitMessage != "" && vi.CommitMessage != vi.CommitTitle {
+			line += "\n  " + strings.Replace(vi.CommitMessage, "\n", "\n  ", -1)
+		}
+		lines = append(lines, line)
+	}
+	message = fmt.Sprintf("squashed %d versions:\n%s", len(versions), strings.Join(lines, "\n"))
+	return title, message
+}
@@ -0,0 +1,91 @@
+package base
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/dsref"
+)
+
+func TestSquashVersions(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+	ctx, r := run.Context, run.Repo
+
+	var refs []dsref.Ref
+	for i := 1; i <= 4; i++ {
+		ds := run.BuildDataset("squash_test", "json")
+		ds.Commit = &dataset.Commit{Title: fmt.Sprintf("version %d", i), Message: fmt.Sprintf("added row %d", i)}
+		body := strings.Repeat("1,", i)
+		ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte("["+body[:len(body)-1]+"]")))
+		ref, err := run.SaveDataset(ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		refs = append(refs, ref)
+	}
+	ref := dsref.Ref{Username: "peer", Name: "squash_test"}
+
+	if _, err := SquashVersions(ctx, r, r.Filesystem().DefaultWriteFS(), ref, SquashParams{Count: 1}); err == nil {
+		t.Error("expected squashing a single version to error")
+	}
+	if _, err := SquashVersions(ctx, r, r.Filesystem().DefaultWriteFS(), ref, SquashParams{Count: 5}); err == nil {
+		t.Error("expected squashing more versions than history to error")
+	}
+
+	res, err := SquashVersions(ctx, r, r.Filesystem().DefaultWriteFS(), ref, SquashParams{Count: 3, Unpin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Dataset.PreviousPath != refs[0].Path {
+		t.Errorf("expected squashed version to follow %q, got %q", refs[0].Path, res.Dataset.PreviousPath)
+	}
+	if res.Dataset.BodyPath == "" || res.Dataset.Structure.Entries != 4 {
+		t.Errorf("expected squashed version to have the head body, got %d entries", res.Dataset.Structure.Entries)
+	}
+	if res.Dataset.Commit.Title != "version 2" {
+		t.Errorf("expected title of oldest squashed version, got %q", res.Dataset.Commit.Title)
+	}
+	for _, s := range []string{"squashed 3 versions", "version 3", "added row 4"} {
+		if !strings.Contains(res.Dataset.Commit.Message, s) {
+			t.Errorf("expected combined message to contain %q, got %q", s, res.Dataset.Commit.Message)
+		}
+	}
+	if len(res.Squashed) != 3 || res.Squashed[0] != refs[3].Path {
+		t.Errorf("unexpected squashed paths: %v", res.Squashed)
+	}
+	if len(res.Unpinned) != 3 {
+		t.Errorf("expected 3 unpinned versions, got: %v", res.Unpinned)
+	}
+
+	items, err := DatasetLog(ctx, r, ref, -1, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Path != res.Dataset.Path || items[1].Path != refs[0].Path {
+		t.Errorf("expected history of squashed version & first version, got: %#v", items)
+	}
+
+	// published versions can only be squashed with force
+	initID, err := r.Logbook().RefToInitID(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.Logbook().WriteRemotePush(ctx, initID, 2, "https://registry.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SquashVersions(ctx, r, r.Filesystem().DefaultWriteFS(), ref, SquashParams{Count: 2}); !errors.Is(err, ErrSquashPublished) {
+		t.Errorf("expected ErrSquashPublished, got: %v", err)
+	}
+	res, err = SquashVersions(ctx, r, r.Filesystem().DefaultWriteFS(), ref, SquashParams{Count: 2, Title: "all of it", Force: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Dataset.PreviousPath != "" || res.Dataset.Commit.Title != "all of it" {
+		t.Errorf("expected a squashed initial version titled %q, got previous %q title %q", "all of it", res.Dataset.PreviousPath, res.Dataset.Commit.Title)
+	}
+}
@@ -0,0 +1,105 @@
+package base
+
+import (
+	"context"
+	"sort"
+
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// VersionReport is the result of verifying a single version a repo references.
+// Ref is empty for versions the logbook references that don't belong to a
+// dataset in the refstore
+type VersionReport struct {
+	Ref dsref.Ref `json:"ref"`
+	dsfs.VersionCheck
+	// Repaired is true if a version was re-fetched after failing verification
+	Repaired bool `json:"repaired,omitempty"`
+}
+
+// RepoReport is the result of verifying every dataset version a repo
+// references
+type RepoReport struct {
+	Versions []VersionReport `json:"versions"`
+	OK       int             `json:"ok"`
+	Remote   int             `json:"remote"`
+	Invalid  int             `json:"invalid"`
+}
+
+// Tally recounts report totals from version statuses
+func (rr *RepoReport) Tally() {
+	rr.OK, rr.Remote, rr.Invalid = 0, 0, 0
+	for _, v := range rr.Versions {
+		switch v.Status {
+		case dsfs.VerifyOK:
+			rr.OK++
+		case dsfs.VerifyRemote:
+			rr.Remote++
+		case dsfs.VerifyInvalid:
+			rr.Invalid++
+		}
+	}
+}
+
+// VerifyRepo walks every dataset version a repo references, checking each
+// with dsfs.VerifyVersion. Versions are gathered from the history of each
+// dataset in the refstore, followed by any other paths the logbook references
+func VerifyRepo(ctx context.Context, r repo.Repo) (*RepoReport, error) {
+	count, err := r.RefCount()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := r.References(0, count)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := r.Filesystem()
+	book := r.Logbook()
+	report := &RepoReport{Versions: []VersionReport{}}
+	seen := map[string]struct{}{}
+	verify := func(ref dsref.Ref, path string) {
+		if _, ok := seen[path]; ok || path == "" {
+			return
+		}
+		seen[path] = struct{}{}
+		ref.Path = path
+		report.Versions = append(report.Versions, VersionReport{
+			Ref:          ref,
+			VersionCheck: dsfs.VerifyVersion(ctx, fs, path),
+		})
+	}
+
+	for _, rr := range refs {
+		ref := reporef.ConvertToVersionInfo(&rr).SimpleRef()
+		if book != nil {
+			if items, err := book.Items(ctx, ref, 0, -1); err == nil {
+				for _, item := range items {
+					verify(ref, item.Path)
+				}
+			}
+		}
+		verify(ref, ref.Path)
+	}
+
+	if book != nil {
+		paths, err := book.AllReferencedDatasetPaths(ctx)
+		if err != nil {
+			return nil, err
+		}
+		unowned := make([]string, 0, len(paths))
+		for p := range paths {
+			unowned = append(unowned, p)
+		}
+		sort.Strings(unowned)
+		for _, p := range unowned {
+			verify(dsref.Ref{}, p)
+		}
+	}
+
+	report.Tally()
+	return report, nil
+}
@@ -2,9 +2,15 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/logbook"
 	"github.com/spf13/cobra"
 )
 
@@ -46,7 +52,116 @@ here for crafting API requests in external progrmas`[1:],
 	tokenCmd.Flags().StringVar(&o.GranteeUsername, "for", "", "user to create access token for")
 	tokenCmd.MarkFlagRequired("for")
 
-	cmd.AddCommand(tokenCmd)
+	approveCmd := &cobra.Command{
+		Use:   "approve USER_CODE",
+		Short: "approve a device login request",
+		Long: `
+approve grants a pending device login request, identified by the user code
+shown on the device. Devices like browser apps start a login request through
+the /oauth/device/code API endpoint, then poll /oauth/token until the request
+is approved. Once approved, the device receives a short-lived access token and
+a refresh token.`[1:],
+		Example: `
+  # approve a login request as yourself:
+  $ qri access approve BCDF-GHJK
+
+  # reject a login request:
+  $ qri access approve BCDF-GHJK --deny
+`[1:],
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			ctx := context.TODO()
+			return o.ApproveDevice(ctx, args[0])
+		},
+	}
+	approveCmd.Flags().StringVar(&o.GranteeUsername, "for", "me", "user to log the device in as, \"me\" is the active user")
+	approveCmd.Flags().BoolVar(&o.Deny, "deny", false, "reject the login request")
+
+	embedCmd := &cobra.Command{
+		Use:   "embed [DATASET]",
+		Short: "create & manage tokens for embedding dataset previews",
+		Long: `
+embed creates a read token for a single dataset, for embedding the dataset
+preview & body in a web page. Pass the token to the /embed endpoint as a
+"token" query parameter:
+
+  http://localhost:2503/embed/me/dataset/body?token=TOKEN
+
+Embed tokens expire after 30 days unless a different --ttl is given. Each
+request made with a token is counted, list tokens to see how often they're
+used. Revoked tokens are rejected, but stay listed.`[1:],
+		Example: `
+  # create a token to embed a dataset in a blog post:
+  $ qri access embed me/population --note "population blog post"
+
+  # create a token that expires in a week:
+  $ qri access embed me/population --ttl 168h
+
+  # list tokens for a dataset & how often they've been used:
+  $ qri access embed me/population --list
+
+  # revoke a token:
+  $ qri access embed --revoke 9d3a61c2f00e7b4a1c95e2d8b6f7a310
+`[1:],
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			ctx := context.TODO()
+			return o.Embed(ctx, args)
+		},
+	}
+	embedCmd.Flags().DurationVar(&o.TTL, "ttl", lib.DefaultEmbedTokenTTL, "how long the token is valid for, 0 never expires")
+	embedCmd.Flags().StringVar(&o.Note, "note", "", "describe where the token is used")
+	embedCmd.Flags().BoolVar(&o.List, "list", false, "list embed tokens")
+	embedCmd.Flags().StringVar(&o.Revoke, "revoke", "", "id of a token to revoke")
+
+	protectCmd := &cobra.Command{
+		Use:   "protect DATASET",
+		Short: "show or set dataset protection rules",
+		Long: `
+protect guards the history of a dataset with a set of rules:
+
+  --no-rewrite               versions can't be amended, deleted or squashed
+  --require-successful-runs  versions must be created by a transform run that
+                             succeeded
+  --no-delete                the dataset can't be removed
+
+Rules are recorded in the dataset log. They're checked when writing to the
+log, and when merging logs pushed by other peers, so remotes holding the
+dataset enforce them too. Setting rules replaces any earlier rules, use
+--clear to remove all protection. Without flags, protect shows the current
+rules.`[1:],
+		Example: `
+  # keep the history of a dataset from being rewritten or removed:
+  $ qri access protect me/population --no-rewrite --no-delete
+
+  # show protection rules:
+  $ qri access protect me/population
+
+  # remove protection:
+  $ qri access protect me/population --clear
+`[1:],
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			ctx := context.TODO()
+			set := cmd.Flags().Changed("no-rewrite") || cmd.Flags().Changed("require-successful-runs") || cmd.Flags().Changed("no-delete")
+			return o.Protect(ctx, args[0], set)
+		},
+	}
+	protectCmd.Flags().BoolVar(&o.NoRewrite, "no-rewrite", false, "forbid amending & deleting versions")
+	protectCmd.Flags().BoolVar(&o.RequireSuccessfulRuns, "require-successful-runs", false, "only allow versions created by a successful transform run")
+	protectCmd.Flags().BoolVar(&o.NoDelete, "no-delete", false, "forbid removing the dataset")
+	protectCmd.Flags().BoolVar(&o.Clear, "clear", false, "remove all protection rules")
+
+	cmd.AddCommand(tokenCmd, approveCmd, embedCmd, protectCmd)
 	return cmd
 }
 
@@ -56,6 +171,17 @@ type AccessOptions struct {
 	Instance *lib.Instance
 
 	GranteeUsername string
+	Deny            bool
+
+	TTL    time.Duration
+	Note   string
+	List   bool
+	Revoke string
+
+	NoRewrite             bool
+	RequireSuccessfulRuns bool
+	NoDelete              bool
+	Clear                 bool
 }
 
 // Complete adds any missing configuration that can only be added just before calling Run
@@ -78,3 +204,129 @@ func (o *AccessOptions) CreateAccessToken(ctx context.Context) error {
 	printInfo(o.Out, token)
 	return nil
 }
+
+// ApproveDevice grants or rejects a pending device login request
+func (o *AccessOptions) ApproveDevice(ctx context.Context, userCode string) error {
+	p := &lib.ApproveDeviceParams{
+		UserCode:        userCode,
+		GranteeUsername: o.GranteeUsername,
+		Deny:            o.Deny,
+	}
+	if err := o.Instance.Access().ApproveDevice(ctx, p); err != nil {
+		return err
+	}
+
+	if o.Deny {
+		printSuccess(o.Out, "denied login request %s", userCode)
+		return nil
+	}
+	printSuccess(o.Out, "approved login request %s", userCode)
+	return nil
+}
+
+// Embed creates, lists or revokes embed tokens
+func (o *AccessOptions) Embed(ctx context.Context, args []string) error {
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+
+	if o.Revoke != "" {
+		et, err := o.Instance.Access().RevokeEmbedToken(ctx, &lib.RevokeEmbedTokenParams{ID: o.Revoke})
+		if err != nil {
+			return err
+		}
+		printSuccess(o.Out, "revoked embed token %s for %s", et.ID, et.Ref)
+		return nil
+	}
+
+	if o.List {
+		tokens, err := o.Instance.Access().EmbedTokens(ctx, &lib.EmbedTokensParams{Ref: ref})
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			printInfo(o.Out, "no embed tokens")
+			return nil
+		}
+		w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tDATASET\tEXPIRES\tUSES\tLAST USED\tNOTE")
+		for _, et := range tokens {
+			expires := "never"
+			if et.Revoked {
+				expires = "revoked"
+			} else if et.Expired() {
+				expires = "expired"
+			} else if !et.Expires.IsZero() {
+				expires = humanize.Time(et.Expires)
+			}
+			lastUsed := "never"
+			if !et.LastUsed.IsZero() {
+				lastUsed = humanize.Time(et.LastUsed)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", et.ID, et.Ref, expires, et.Uses, lastUsed, et.Note)
+		}
+		return w.Flush()
+	}
+
+	if ref == "" {
+		return fmt.Errorf("%w: a dataset reference is required to create an embed token", lib.ErrBadArgs)
+	}
+	p := &lib.CreateEmbedTokenParams{
+		Ref:      ref,
+		TTL:      o.TTL,
+		NoExpiry: o.TTL == 0,
+		Note:     o.Note,
+	}
+	et, err := o.Instance.Access().CreateEmbedToken(ctx, p)
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, "created embed token %s for %s", et.ID, et.Ref)
+	printInfo(o.Out, et.Token)
+	return nil
+}
+
+// Protect shows the protection rules of a dataset, or sets them when set is
+// true or rules are being cleared
+func (o *AccessOptions) Protect(ctx context.Context, ref string, set bool) error {
+	if o.Clear && set {
+		return fmt.Errorf("%w: --clear can't be combined with rule flags", lib.ErrBadArgs)
+	}
+
+	var (
+		pr  *logbook.Protection
+		err error
+	)
+	if set || o.Clear {
+		p := &lib.ProtectParams{
+			Ref:                   ref,
+			NoRewrite:             o.NoRewrite,
+			RequireSuccessfulRuns: o.RequireSuccessfulRuns,
+			NoDelete:              o.NoDelete,
+		}
+		if pr, err = o.Instance.Access().Protect(ctx, p); err != nil {
+			return err
+		}
+		printSuccess(o.Out, "updated protection for %s", ref)
+	} else if pr, err = o.Instance.Access().Protection(ctx, &lib.ProtectionParams{Ref: ref}); err != nil {
+		return err
+	}
+
+	if pr.IsZero() {
+		printInfo(o.Out, "%s is not protected", ref)
+		return nil
+	}
+	rules := []string{}
+	if pr.NoRewrite {
+		rules = append(rules, "no-rewrite")
+	}
+	if pr.RequireSuccessfulRuns {
+		rules = append(rules, "require-successful-runs")
+	}
+	if pr.NoDelete {
+		rules = append(rules, "no-delete")
+	}
+	printInfo(o.Out, "%s rules: %s", ref, strings.Join(rules, ", "))
+	return nil
+}
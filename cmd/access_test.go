@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -11,3 +12,27 @@ func TestAccessCreateToken(t *testing.T) {
 	run.MustExec(t, "qri access token --for me")
 	run.MustExec(t, "qri access token --for peer")
 }
+
+func TestAccessApproveDevice(t *testing.T) {
+	run := NewTestRunner(t, "peer", "cmd_test_access_approve")
+	defer run.Delete()
+
+	if err := run.ExecCommand("qri access approve BCDF-GHJK"); err == nil {
+		t.Error("expected approving an unknown user code to error")
+	}
+}
+
+func TestAccessEmbed(t *testing.T) {
+	run := NewTestRunner(t, "peer", "cmd_test_access_embed")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body testdata/movies/body_ten.csv me/movies")
+	run.MustExec(t, "qri access embed me/movies --note blog")
+	output := run.MustExec(t, "qri access embed --list")
+	if !strings.Contains(output, "peer/movies") || !strings.Contains(output, "blog") {
+		t.Errorf("expected embed token to be listed, got:\n%s", output)
+	}
+	if err := run.ExecCommand("qri access embed --revoke unknown"); err == nil {
+		t.Error("expected revoking an unknown token to error")
+	}
+}
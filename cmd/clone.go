@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewCloneCommand creates a `qri clone` command that pulls a dataset, creates
+// a linked working directory & follows the dataset for new versions
+func NewCloneCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &CloneOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "clone DATASET [DIRECTORY]",
+		Short: "pull a dataset, check it out & follow new versions",
+		Long: `Clone gets a dataset ready to work on in one step. Clone pulls a dataset,
+writes it to a linked working directory, and follows the dataset so new
+versions are pulled automatically.
+
+By default clone pulls the latest version. Use --depth to pull more history,
+a depth of 0 pulls every version. The working directory defaults to the
+dataset name in the current directory.`,
+		Example: `  # clone a dataset into the ./world_bank_population directory
+  $ qri clone b5/world_bank_population
+
+  # clone the last 10 versions into a chosen directory, without following
+  $ qri clone b5/world_bank_population ./population --depth 10 --no-follow`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Remote, "remote", "", "location to pull from")
+	cmd.Flags().IntVar(&o.Depth, "depth", 1, "number of versions to pull, 0 pulls all versions")
+	cmd.Flags().BoolVar(&o.NoFollow, "no-follow", false, "don't pull new versions automatically")
+	cmd.Flags().BoolVar(&o.NoCheckout, "no-checkout", false, "don't create a working directory")
+
+	return cmd
+}
+
+// CloneOptions encapsulates state for the clone command
+type CloneOptions struct {
+	ioes.IOStreams
+
+	Ref        string
+	Dir        string
+	Remote     string
+	Depth      int
+	NoFollow   bool
+	NoCheckout bool
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *CloneOptions) Complete(f Factory, args []string) (err error) {
+	o.Ref = args[0]
+	if len(args) == 2 {
+		o.Dir = args[1]
+	}
+	if o.Dir != "" && o.NoCheckout {
+		return fmt.Errorf("cannot provide a directory with --no-checkout")
+	}
+	if o.Dir == "" && !o.NoCheckout {
+		pos := strings.Index(o.Ref, "/")
+		if pos == -1 {
+			return fmt.Errorf("expect '/' in dataset ref")
+		}
+		name := o.Ref[pos+1:]
+		if at := strings.Index(name, "@"); at != -1 {
+			name = name[:at]
+		}
+		o.Dir = dsref.GenerateName(name, "")
+	}
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Run clones the dataset
+func (o *CloneOptions) Run() error {
+	ctx := context.TODO()
+	p := &lib.CloneParams{
+		Ref:    o.Ref,
+		Remote: o.Remote,
+		Depth:  o.Depth,
+		Dir:    o.Dir,
+		Follow: !o.NoFollow,
+	}
+	res, err := o.inst.WithSource("network").Dataset().Clone(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	alias := fmt.Sprintf("%s/%s", res.Dataset.Peername, res.Dataset.Name)
+	if res.Versions == 1 {
+		printSuccess(o.Out, "pulled %s", alias)
+	} else {
+		printSuccess(o.Out, "pulled %d versions of %s", res.Versions, alias)
+	}
+	if res.Dir != "" {
+		printSuccess(o.Out, "created and linked working directory %s", res.Dir)
+	}
+	if res.Following {
+		printInfo(o.Out, "following %s, new versions will be pulled automatically", alias)
+	}
+	return nil
+}
@@ -64,12 +64,34 @@ const (
 	ExitCodeErr
 	// ExitCodeNeedMigration indicates a required migration
 	ExitCodeNeedMigration
+	// ExitCodeDiffErr indicates `qri diff --stat` failed. diff uses ExitCodeErr
+	// to report changes when run with --stat
+	ExitCodeDiffErr
 )
 
+// exitCodeError overrides the exit code of an error
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e exitCodeError) Error() string { return e.err.Error() }
+func (e exitCodeError) Unwrap() error { return e.err }
+
 // ErrExit writes an error to the given io.Writer & exits
 func ErrExit(w io.Writer, err error) {
 	exitCode := ExitCodeErr
 
+	if errors.Is(err, ErrDiffChanged) {
+		// differences have already been printed, nothing went wrong
+		os.Exit(ExitCodeErr)
+	}
+
+	var codeErr exitCodeError
+	if errors.As(err, &codeErr) {
+		exitCode = codeErr.code
+	}
+
 	if errors.Is(err, migrate.ErrMigrationSucceeded) {
 		// migration success is a good thing. exit with status 0
 		printSuccess(w, "migration succeeded, re-run your command to continue")
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/base/component"
@@ -15,13 +16,16 @@ import (
 // NewAutocompleteCommand creates a new `qri complete` cobra command that prints autocomplete scripts
 func NewAutocompleteCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 	o := &AutocompleteOptions{IOStreams: ioStreams}
-	cfgOpt := ConfigOptions{IOStreams: ioStreams}
 	cmd := &cobra.Command{
-		Use:   "completion [bash|zsh]",
+		Use:   "completion [bash|zsh|fish|powershell]",
 		Short: "generate shell auto-completion scripts",
-		Long: `Completion generates auto-completion scripts for Bash or Zsh
-which you can then source in your terminal or save to your profile to have it
-run on each terminal session.`,
+		Long: `Completion generates auto-completion scripts for Bash, Zsh, Fish or
+PowerShell which you can then source in your terminal or save to your profile
+to have it run on each terminal session.
+
+Bash, Zsh & Fish scripts complete dataset references, peer names, config keys
+& components by asking qri for suggestions as you type. PowerShell scripts
+complete commands & flags only.`,
 		Example: `  # load auto-completion for a single session
   $ source <(qri completion [bash|zsh])
 
@@ -30,6 +34,9 @@ run on each terminal session.`,
 
   $ source <(qri completion [bash|zsh])
 
+  # load auto-completion for fish
+  $ qri completion fish > ~/.config/fish/completions/qri.fish
+
   # alternatively you can pipe the output to a local script and
   # reference that as the source for faster loading.
 `,
@@ -40,72 +47,12 @@ run on each terminal session.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.Run(cmd, args)
 		},
-		ValidArgs: []string{"bash", "zsh"},
-	}
-
-	configCompletion := &cobra.Command{
-		Use:    "config [FIELD]",
-		Hidden: true,
-		Short:  "get configuration keys",
-		Long:   `'qri completion config' is a util function for auto-completion of config keys, ignores private data`,
-		Args:   cobra.MaximumNArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := cfgOpt.CompleteConfig(f); err != nil {
-				return err
-			}
-			return cfgOpt.GetConfig(args)
-		},
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
 	}
 
-	structureCompletion := &cobra.Command{
-		Use:       "structure [FIELD]",
-		Hidden:    true,
-		Short:     "get structure keys",
-		Long:      `'qri completion structure' is a util function for auto-completion of structure keys`,
-		Args:      cobra.MaximumNArgs(1),
-		ValidArgs: component.DatasetFields,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			for _, structureArg := range component.DatasetFields {
-				fmt.Fprintln(ioStreams.Out, structureArg)
-			}
-			return nil
-		},
-	}
-
-	cmd.AddCommand(configCompletion)
-	cmd.AddCommand(structureCompletion)
-
 	return cmd
 }
 
-// CompleteConfig adds any missing configuration that can only be added just before calling GetConfig
-func (o *ConfigOptions) CompleteConfig(f Factory) (err error) {
-	if o.inst, err = f.Instance(); err != nil {
-		return
-	}
-
-	o.ProfileMethods, err = f.ProfileMethods()
-	return
-}
-
-// GetConfig gets configuration keys based on a partial key supplied
-func (o *ConfigOptions) GetConfig(args []string) (err error) {
-	params := &lib.GetConfigParams{}
-	if len(args) == 1 {
-		params.Field = args[0]
-	}
-
-	ctx := context.TODO()
-
-	data, err := o.inst.Config().GetConfigKeys(ctx, params)
-	if err != nil {
-		return err
-	}
-
-	fmt.Fprintln(o.Out, string(data))
-	return
-}
-
 // AutocompleteOptions encapsulates completion options
 type AutocompleteOptions struct {
 	ioes.IOStreams
@@ -119,128 +66,151 @@ func (o *AutocompleteOptions) Run(cmd *cobra.Command, args []string) (err error)
 	if len(args) > 1 {
 		return fmt.Errorf("too many arguments, expected only the shell type")
 	}
-	if args[0] == "bash" {
-		cmd.Parent().GenBashCompletion(o.Out)
-	}
-	if args[0] == "zsh" {
+	root := cmd.Root()
+	switch args[0] {
+	case "bash":
+		return root.GenBashCompletion(o.Out)
+	case "zsh":
+		// cobra's zsh scripts don't ask the command for suggestions, so zsh
+		// sources the bash script, which does
 		zshBody := bytes.Buffer{}
-		cmd.Parent().GenBashCompletion(&zshBody)
+		if err := root.GenBashCompletion(&zshBody); err != nil {
+			return err
+		}
 		io.WriteString(o.Out, zshHead)
 		o.Out.Write(zshBody.Bytes())
 		io.WriteString(o.Out, zshTail)
+		return nil
+	case "fish":
+		return root.GenFishCompletion(o.Out, true)
+	case "powershell":
+		return root.GenPowerShellCompletion(o.Out)
+	default:
+		return fmt.Errorf("unsupported shell %q, expected one of bash, zsh, fish or powershell", args[0])
 	}
-	return nil
 }
 
-const (
-	bashCompletionFunc = `
-# arg completions
+// completionFunc is the signature of cobra's dynamic completion functions
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
 
-__qri_parse_list()
-{
-    local qri_output out
-    if qri_output=$(qri list --format=simple --no-prompt --no-color 2>/dev/null); then
-        echo "${qri_output}"
-        return 1
-    fi
-    return 0
+// completeRefs suggests the aliases of local datasets for every positional
+// argument
+func completeRefs(f Factory) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return datasetAliases(f, toComplete)
+	}
 }
 
-__qri_parse_search()
-{
-    local qri_output out
-    if qri_output=$(qri search $cur --format=simple --no-prompt --no-color 2>/dev/null); then
-        echo "${qri_output}"
-        return 1
-    fi
-    return 0
+// completeRef suggests the alias of a local dataset for the first positional
+// argument, leaving later arguments to the shell's default completion
+func completeRef(f Factory) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return datasetAliases(f, toComplete)
+	}
 }
 
-__qri_parse_config()
-{
-    local qri_output out
-    if qri_output=$(qri completion config $cur --no-prompt --no-color 2>/dev/null); then
-        echo "${qri_output}"
-        return 1
-    fi
-    return 0
+// completeGetArgs suggests a component selector or dataset for the first
+// argument of get, and a dataset for the second
+func completeGetArgs(f Factory) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 1 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		aliases, dir := datasetAliases(f, toComplete)
+		if len(args) == 1 || dir == cobra.ShellCompDirectiveError {
+			return aliases, dir
+		}
+		return append(withPrefix(component.DatasetFields, toComplete), aliases...), dir
+	}
 }
 
-__qri_parse_structure_args()
-{
-    local qri_output out
-    if qri_output=$(qri completion structure --no-prompt --no-color 2>/dev/null); then
-        echo "${qri_output}"
-        return 1
-    fi
-    return 0
+// completePeer suggests the name of a known peer for the first positional
+// argument only
+func completePeer(f Factory) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return peerNames(f, toComplete)
+	}
 }
 
-__qri_parse_peers()
-{
-    local qri_output out
-    if qri_output=$(qri peers list --format=simple --no-prompt --no-color 2>/dev/null); then
-        echo "${qri_output}"
-        return 1
-    fi
-    return 0
+// completePeers suggests the names of known peers, for flags that take a peer
+func completePeers(f Factory) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return peerNames(f, toComplete)
+	}
 }
 
-__qri_join_completions()
-{
-    local q1=($1)
-    local q2=($2)
-    echo "${q1[*]} ${q2[*]}"
-    return 1
+// completeConfigKeys suggests configuration keys, ignoring private data. Keys
+// are suggested for every other positional argument, matching both
+// "config get FIELD" and "config set FIELD VALUE [FIELD VALUE ...]"
+func completeConfigKeys(f Factory) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args)%2 == 1 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		inst, err := f.Instance()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		data, err := inst.Config().GetConfigKeys(context.TODO(), &lib.GetConfigParams{Field: toComplete})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return strings.Fields(string(data)), cobra.ShellCompDirectiveNoFileComp
+	}
 }
 
-__qri_suggest_completion()
-{
-	local res=($1)
-    if test -z "${res}"; then
-        return 0
-    fi
-    COMPREPLY=( $( compgen -W "${res[*]}" -- "$cur" ) )
-    return 1
+// datasetAliases lists local datasets whose alias starts with toComplete
+func datasetAliases(f Factory, toComplete string) ([]string, cobra.ShellCompDirective) {
+	inst, err := f.Instance()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	refs, err := inst.Dataset().List(context.TODO(), &lib.ListParams{Limit: -1})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	aliases := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		aliases = append(aliases, ref.Alias())
+	}
+	return withPrefix(aliases, toComplete), cobra.ShellCompDirectiveNoFileComp
 }
 
-__qri_custom_func() {
-    local out
-    case ${last_command} in
-        qri_checkout | qri_export | qri_log | qri_logbook | qri_publish | qri_remove | qri_rename | qri_render | qri_save | qri_stats | qri_use | qri_validate | qri_whatchanged | qri_workdir_link | qri_workdir_unlink)
-            __qri_suggest_completion "$(__qri_parse_list)"
-            return
-            ;;
-        qri_add | qri_fetch | qri_search)
-        	__qri_suggest_completion "$(__qri_parse_search)"
-        	return
-        	;;
-        qri_config_get | qri_config_set)
-        	__qri_suggest_completion "$(__qri_parse_config)"
-        	return
-        	;;
-        qri_get)
-            local completions=$(__qri_join_completions "$(__qri_parse_structure_args)" "$(__qri_parse_list)")
-        	__qri_suggest_completion "${completions}"
-        	return
-        	;;
-        qri_peers_info | qri_peers_connect | qri_peers_disconnect)
-            __qri_suggest_completion "$(__qri_parse_peers)"
-            return
-            ;;
-        *)
-            ;;
-    esac
+// peerNames lists known peers whose name starts with toComplete
+func peerNames(f Factory, toComplete string) ([]string, cobra.ShellCompDirective) {
+	inst, err := f.Instance()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	peers, err := inst.Peer().List(context.TODO(), &lib.PeerListParams{Cached: true})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := make([]string, 0, len(peers))
+	for _, p := range peers {
+		names = append(names, p.Peername)
+	}
+	return withPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
 }
 
-# flag completions
-
-__qri_get_peer_flag_suggestions()
-{
-	__qri_suggest_completion "$(__qri_parse_peers)"
+// withPrefix filters suggestions to those starting with prefix
+func withPrefix(suggestions []string, prefix string) []string {
+	res := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		if strings.HasPrefix(s, prefix) {
+			res = append(res, s)
+		}
+	}
+	return res
 }
-`
 
+const (
 	zshHead = `# reference kubectl completion zsh
 
 __qri_bash_source() {
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompletionScripts(t *testing.T) {
+	run := NewTestRunner(t, "peer", "cmd_test_completion_scripts")
+	defer run.Delete()
+
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		if output := run.MustExec(t, "qri completion "+shell); output == "" {
+			t.Errorf("expected %s completion script, got no output", shell)
+		}
+	}
+	if err := run.ExecCommand("qri completion tcsh"); err == nil {
+		t.Error("expected unsupported shell to error")
+	}
+}
+
+func TestDynamicCompletion(t *testing.T) {
+	run := NewTestRunner(t, "peer", "cmd_test_dynamic_completion")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body testdata/movies/body_ten.csv me/movies")
+
+	output := run.MustExec(t, "qri __complete log peer/")
+	if !strings.Contains(output, "peer/movies") {
+		t.Errorf("expected dataset reference suggestion, got:\n%s", output)
+	}
+
+	output = run.MustExec(t, "qri __complete get st")
+	if !strings.Contains(output, "structure") || strings.Contains(output, "peer/movies") {
+		t.Errorf("expected component suggestions only, got:\n%s", output)
+	}
+
+	output = run.MustExec(t, "qri __complete config get profile.")
+	if !strings.Contains(output, "profile.peername") {
+		t.Errorf("expected config key suggestions, got:\n%s", output)
+	}
+}
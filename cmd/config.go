@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/config"
@@ -67,7 +73,8 @@ Anyone with your private keys can impersonate you on qri.`,
 
   # Get the profile description:
   $ qri config get profile.description`,
-		Args: cobra.MaximumNArgs(1),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeConfigKeys(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f); err != nil {
 				return err
@@ -104,6 +111,7 @@ field checkout: https://github.com/qri-io/qri/blob/master/config/readme.md`,
 			}
 			return nil
 		},
+		ValidArgsFunction: completeConfigKeys(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
 			if err := o.Complete(f); err != nil {
@@ -113,12 +121,101 @@ field checkout: https://github.com/qri-io/qri/blob/master/config/readme.md`,
 		},
 	}
 
+	dataset := &cobra.Command{
+		Use:   "dataset DATASET [KEY [VALUE]]",
+		Short: "get and set settings for a single dataset",
+		Long: `'qri config dataset' reads & changes defaults that apply to one dataset.
+Dataset settings are stored in your repo, not your config file. Settings are:
+
+  bodyformat   format saved bodies are converted to, eg: csv or json
+  remote       remote to push to when none is given
+  lintprofile  profile from the lint.profiles config field to lint with
+  retention    number of versions to keep stored locally, 0 keeps all
+
+Without a KEY all settings are shown. Setting a value to "" unsets it.`,
+		Example: `  # show settings for a dataset:
+  $ qri config dataset me/annual_pop
+
+  # always push to the "staging" remote:
+  $ qri config dataset me/annual_pop remote staging
+
+  # keep data for the 10 most recent versions:
+  $ qri config dataset me/annual_pop retention 10`,
+		Args:              cobra.RangeArgs(1, 3),
+		ValidArgsFunction: completeRef(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Dataset(args)
+		},
+	}
+
+	validate := &cobra.Command{
+		Use:   "validate [FILE | FIELD VALUE ...]",
+		Short: "check configuration for errors",
+		Long: `'qri config validate' checks configuration against the config schema,
+listing every section with a problem instead of stopping at the first.
+
+Without arguments the current configuration is checked. Given a single FILE,
+a yaml configuration file is checked. Given FIELD VALUE pairs, the current
+configuration is checked with those values set, without saving them. Use it
+to try changes before running 'qri config set'.`,
+		Example: `  # Check your current configuration:
+  $ qri config validate
+
+  # Check a configuration file:
+  $ qri config validate ~/config.prod.yaml
+
+  # Check configuration with a new API port, without saving it:
+  $ qri config validate api.port 4444`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 && len(args)%2 != 0 {
+				return fmt.Errorf("wrong number of arguments. arguments must be a file or in the form: [path value]")
+			}
+			return nil
+		},
+		ValidArgsFunction: completeConfigKeys(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Validate(args)
+		},
+	}
+
+	edit := &cobra.Command{
+		Use:   "edit",
+		Short: "edit configuration in a text editor",
+		Long: `'qri config edit' opens your configuration in a text editor, taken from
+the $VISUAL or $EDITOR environment variables, defaulting to vi. Private keys
+are left out of the file you edit & kept as they are.
+
+Configuration is checked when the editor exits. Invalid configuration is never
+saved: problems are listed and you're asked to re-open the editor to fix them.
+If you choose not to, your edits are left in a temporary file.`,
+		Example: `  # Edit configuration with nano:
+  $ EDITOR=nano qri config edit`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Edit()
+		},
+	}
+
 	get.Flags().BoolVar(&o.WithPrivateKeys, "with-private-keys", false, "include private keys in export")
 	get.Flags().BoolVarP(&o.Concise, "concise", "c", false, "print output without indentation, only applies to json format")
 	get.Flags().StringVarP(&o.Format, "format", "f", "yaml", "data format to export. either json or yaml")
 	get.Flags().StringVarP(&o.Output, "output", "o", "", "path to export to")
 	cmd.AddCommand(get)
 	cmd.AddCommand(set)
+	cmd.AddCommand(dataset)
+	cmd.AddCommand(validate)
+	cmd.AddCommand(edit)
 
 	return cmd
 }
@@ -240,6 +337,200 @@ func (o *ConfigOptions) Set(args []string) (err error) {
 	return nil
 }
 
+// Dataset gets or sets dataset settings
+func (o *ConfigOptions) Dataset(args []string) error {
+	ctx := context.TODO()
+	p := &lib.DatasetSettingsParams{Ref: args[0]}
+
+	var (
+		settings *lib.DatasetSettings
+		err      error
+	)
+	if len(args) == 3 {
+		p.Key, p.Value = args[1], args[2]
+		settings, err = o.inst.Config().SetDatasetSetting(ctx, p)
+	} else {
+		settings, err = o.inst.Config().DatasetSettings(ctx, p)
+	}
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{
+		"bodyformat":  settings.BodyFormat,
+		"remote":      settings.Remote,
+		"lintprofile": settings.LintProfile,
+		"retention":   strconv.Itoa(settings.Retention),
+	}
+	switch len(args) {
+	case 1:
+		for _, key := range lib.DatasetSettingKeys {
+			printInfo(o.Out, "%s: %s", key, values[key])
+		}
+		if settings.Shallow > 0 {
+			printInfo(o.Out, "shallow: data for the latest %d versions is stored locally", settings.Shallow)
+		}
+	case 2:
+		val, ok := values[strings.ToLower(args[1])]
+		if !ok {
+			return fmt.Errorf("unknown dataset setting %q. settings: %s", args[1], strings.Join(lib.DatasetSettingKeys, ", "))
+		}
+		printInfo(o.Out, "%s", val)
+	case 3:
+		printSuccess(o.Out, "dataset settings updated")
+	}
+	return nil
+}
+
+// Validate checks the current configuration, a configuration file, or the
+// current configuration with field values set
+func (o *ConfigOptions) Validate(args []string) error {
+	var cfg *config.Config
+	switch len(args) {
+	case 0:
+		cfg = o.inst.GetConfig()
+	case 1:
+		var err error
+		if cfg, err = config.ReadFromFile(args[0]); err != nil {
+			return fmt.Errorf("reading %s: %w", args[0], err)
+		}
+	default:
+		cfg = o.inst.GetConfig().Copy()
+		for i := 0; i < len(args)-1; i = i + 2 {
+			path := strings.ToLower(args[i])
+			if config.ImmutablePaths()[path] {
+				return fmt.Errorf("cannot set path %s", path)
+			}
+			if err := cfg.Set(path, args[i+1]); err != nil {
+				return fmt.Errorf("setting %s: %w", path, err)
+			}
+		}
+	}
+
+	if errs := cfg.ValidateAll(); len(errs) > 0 {
+		printConfigErrors(o.ErrOut, errs)
+		return fmt.Errorf("configuration is invalid")
+	}
+	printSuccess(o.Out, "configuration is valid")
+	return nil
+}
+
+// Edit opens configuration in a text editor, saving it once it's valid
+func (o *ConfigOptions) Edit() error {
+	ctx := context.TODO()
+	current := o.inst.GetConfig()
+
+	f, err := ioutil.TempFile("", "qri-config-*.yaml")
+	if err != nil {
+		return err
+	}
+	filename := f.Name()
+	f.Close()
+	if err := current.WithoutPrivateValues().WriteToFile(filename); err != nil {
+		os.Remove(filename)
+		return err
+	}
+
+	var edited *config.Config
+	for {
+		if err := openEditor(o.In, o.Out, o.ErrOut, filename); err != nil {
+			return fmt.Errorf("running editor: %w. your edits are in %s", err, filename)
+		}
+
+		var errs []config.ValidationError
+		if edited, err = config.ReadFromFile(filename); err != nil {
+			errs = []config.ValidationError{{Err: err}}
+		} else {
+			// private values are left out of the file, restore them before
+			// validating
+			edited = edited.WithPrivateValues(current)
+			errs = edited.ValidateAll()
+			for path := range config.ImmutablePaths() {
+				prev, _ := current.Get(path)
+				next, _ := edited.Get(path)
+				if !sameConfigValue(prev, next) {
+					errs = append(errs, config.ValidationError{Err: fmt.Errorf("cannot set path %s", path)})
+				}
+			}
+		}
+		if len(errs) == 0 {
+			break
+		}
+		printConfigErrors(o.ErrOut, errs)
+		if !confirm(o.Out, o.In, "re-open editor to fix configuration?", false) {
+			return fmt.Errorf("configuration is invalid & wasn't saved. your edits are in %s", filename)
+		}
+	}
+	os.Remove(filename)
+
+	profileChanged := !sameProfile(current.Profile, edited.Profile)
+	edited.SetPath(current.Path())
+	if _, err := o.inst.Config().SetConfig(ctx, edited); err != nil {
+		if errors.Is(err, lib.ErrUnsupportedRPC) {
+			return fmt.Errorf("%w - this could mean you're running qri connect in another terminal or application", err)
+		}
+		return err
+	}
+	if profileChanged {
+		if _, err := o.ProfileMethods.SaveProfile(ctx, o.inst.GetConfig().Profile); err != nil {
+			return err
+		}
+	}
+
+	printSuccess(o.Out, "config updated")
+	return nil
+}
+
+func sameConfigValue(a, b interface{}) bool {
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// sameProfile compares the fields of profiles that are written to the config
+// file
+func sameProfile(a, b *config.ProfilePod) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	a, b = a.Copy(), b.Copy()
+	for _, p := range []*config.ProfilePod{a, b} {
+		p.Online = false
+		p.PeerIDs = nil
+		p.NetworkAddrs = nil
+	}
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+func printConfigErrors(w io.Writer, errs []config.ValidationError) {
+	for _, err := range errs {
+		printErr(w, err)
+	}
+	printInfo(w, "for details on each config field see: https://github.com/qri-io/qri/blob/master/config/readme.md")
+}
+
+// openEditor opens a file in the editor set by $VISUAL or $EDITOR, waiting
+// for the editor to exit
+func openEditor(in io.Reader, out, errOut io.Writer, filename string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// pass the filename as an argument so paths with spaces aren't split
+	cmd := exec.Command("/bin/sh", "-c", editor+` "$1"`, "qri-editor", filename)
+	cmd.Stdin = in
+	cmd.Stdout = out
+	cmd.Stderr = errOut
+	return cmd.Run()
+}
+
 func setPhotoPath(ctx context.Context, m *lib.ProfileMethods, proppath, filepath string) error {
 	f, err := loadFileIfPath(filepath)
 	if err != nil {
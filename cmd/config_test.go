@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_config_validate", "qri_test_config_validate")
+	defer run.Delete()
+
+	if out := run.MustExec(t, "qri config validate"); !strings.Contains(out, "configuration is valid") {
+		t.Errorf("expected current config to be valid, got: %q", out)
+	}
+	if out := run.MustExec(t, "qri config validate api.readonly true"); !strings.Contains(out, "configuration is valid") {
+		t.Errorf("expected config with proposed values to be valid, got: %q", out)
+	}
+	if err := run.ExecCommand("qri config validate repo.type badType"); err == nil {
+		t.Error("expected invalid proposed value to error")
+	} else if errOut := run.GetCommandErrOutput(); !strings.Contains(errOut, "repo:") {
+		t.Errorf("expected error output to name the invalid section, got: %q", errOut)
+	}
+	if err := run.ExecCommand("qri config validate profile.id QmFoo"); err == nil {
+		t.Error("expected setting an immutable path to error")
+	}
+
+	dir := run.MakeTmpDir(t, "config_validate")
+	filename := filepath.Join(dir, "config.yaml")
+	run.MustWriteFile(t, filename, "repo:\n  type: badType\n")
+	if err := run.ExecCommand("qri config validate " + filename); err == nil {
+		t.Error("expected invalid config file to error")
+	}
+
+	// proposed values aren't saved
+	if out := run.MustExec(t, "qri config get api.readonly"); !strings.Contains(out, "false") {
+		t.Errorf("expected validating not to change config, got: %q", out)
+	}
+}
+
+func TestConfigEdit(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_config_edit", "qri_test_config_edit")
+	defer run.Delete()
+
+	prevVisual, prevEditor := os.Getenv("VISUAL"), os.Getenv("EDITOR")
+	defer func() {
+		os.Setenv("VISUAL", prevVisual)
+		os.Setenv("EDITOR", prevEditor)
+	}()
+	os.Setenv("VISUAL", "")
+
+	// an invalid edit isn't saved
+	os.Setenv("EDITOR", "sed -i.bak -e 's/readonly: false/readonly: nope/'")
+	if err := run.ExecCommand("qri config edit --no-prompt"); err == nil {
+		t.Error("expected invalid edit to error")
+	} else if !strings.Contains(err.Error(), "wasn't saved") {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if out := run.MustExec(t, "qri config get api.readonly"); !strings.Contains(out, "false") {
+		t.Errorf("expected invalid edit not to be saved, got: %q", out)
+	}
+
+	os.Setenv("EDITOR", "sed -i.bak -e 's/readonly: false/readonly: true/'")
+	run.MustExec(t, "qri config edit")
+	if out := run.MustExec(t, "qri config get api.readonly"); !strings.Contains(out, "true") {
+		t.Errorf("expected edit to be saved, got: %q", out)
+	}
+}
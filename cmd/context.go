@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewContextCommand creates a new `qri context` cobra command for switching
+// between repos
+func NewContextCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &ContextOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "context",
+		Short: "switch between qri repos",
+		Annotations: map[string]string{
+			"group": "other",
+		},
+		Long: `A context is a name for a qri repo, along with the remote and config profile
+to use with it. Contexts make it easy to keep separate repos, for example one
+for work and one for personal datasets, and switch between them.
+
+The current context is used by every command unless the repo is set with the
+` + "`--repo`" + ` flag or $QRI_PATH. The ` + "`--context`" + ` flag uses a context for a single
+command. Contexts are stored in $HOME/.qri_contexts.yaml, or the file at
+$QRI_CONTEXTS_PATH.`,
+		Example: `  # Create a context for a work repo that pushes to a company remote:
+  $ qri context set work --path ~/qri-work --remote company
+
+  # Switch to the work context:
+  $ qri context use work
+
+  # List contexts:
+  $ qri context list
+
+  # Get a dataset from the personal context without switching:
+  $ qri get --context personal me/cities`,
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "list contexts",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Complete()
+			return o.List()
+		},
+	}
+
+	show := &cobra.Command{
+		Use:   "show [NAME]",
+		Short: "show the details of a context, defaulting to the current context",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Complete()
+			return o.Show(args)
+		},
+	}
+
+	use := &cobra.Command{
+		Use:   "use NAME",
+		Short: "set the current context",
+		Long: `Set the context used by all commands. Use the ` + "`--none`" + ` flag to stop using
+contexts, going back to the repo at $QRI_PATH or $HOME/.qri`,
+		Example: `  # Switch to the personal context:
+  $ qri context use personal
+
+  # Stop using contexts:
+  $ qri context use --none`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Complete()
+			return o.Use(args)
+		},
+	}
+
+	set := &cobra.Command{
+		Use:   "set NAME",
+		Short: "create or replace a context",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Complete()
+			return o.Set(args)
+		},
+	}
+
+	remove := &cobra.Command{
+		Use:     "remove NAME",
+		Aliases: []string{"rm"},
+		Short:   "remove a context, leaving the repo it names untouched",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Complete()
+			return o.Remove(args)
+		},
+	}
+
+	use.Flags().BoolVar(&o.None, "none", false, "stop using contexts")
+	set.Flags().StringVar(&o.Path, "path", "", "filepath of the qri repo")
+	set.Flags().StringVar(&o.Remote, "remote", "", "name of the remote to use when none is given")
+	set.Flags().StringVar(&o.ConfigProfile, "config-profile", "", "name of a configuration profile to merge over the repo config")
+	set.MarkFlagRequired("path")
+
+	cmd.AddCommand(list, show, use, set, remove)
+	return cmd
+}
+
+// ContextOptions encapsulates state for the context command
+type ContextOptions struct {
+	ioes.IOStreams
+
+	None          bool
+	Path          string
+	Remote        string
+	ConfigProfile string
+
+	contextsPath string
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ContextOptions) Complete() {
+	o.contextsPath = StandardContextsPath()
+}
+
+// List prints all contexts, marking the current context
+func (o *ContextOptions) List() error {
+	rcs, err := lib.LoadRepoContexts(o.contextsPath)
+	if err != nil {
+		return err
+	}
+	if len(rcs.Contexts) == 0 {
+		printInfo(o.Out, "no contexts. create one with `qri context set`")
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	for _, rc := range rcs.Contexts {
+		marker := " "
+		if rc.Name == rcs.Current {
+			marker = "*"
+		}
+		fmt.Fprintf(buf, "%s %s\t%s\n", marker, rc.Name, rc.RepoPath)
+	}
+	return printToPager(o.Out, buf)
+}
+
+// Show prints the details of a context
+func (o *ContextOptions) Show(args []string) error {
+	rcs, err := lib.LoadRepoContexts(o.contextsPath)
+	if err != nil {
+		return err
+	}
+
+	var rc *lib.RepoContext
+	if len(args) == 1 {
+		rc, err = rcs.Get(args[0])
+	} else {
+		rc, err = rcs.CurrentContext()
+	}
+	if err != nil {
+		return err
+	}
+	if rc == nil {
+		printInfo(o.Out, "no current context")
+		return nil
+	}
+
+	remote := rc.Remote
+	if remote == "" {
+		remote = "registry"
+	}
+	fmt.Fprintf(o.Out, "name:           %s\n", rc.Name)
+	fmt.Fprintf(o.Out, "repo:           %s\n", rc.RepoPath)
+	fmt.Fprintf(o.Out, "remote:         %s\n", remote)
+	if rc.ConfigProfile != "" {
+		fmt.Fprintf(o.Out, "config profile: %s\n", rc.ConfigProfile)
+	}
+	return nil
+}
+
+// Use sets the current context
+func (o *ContextOptions) Use(args []string) error {
+	name := ""
+	if o.None {
+		if len(args) > 0 {
+			return errors.New(lib.ErrBadArgs, "please provide either a context name or the --none flag")
+		}
+	} else if len(args) == 0 {
+		return errors.New(lib.ErrBadArgs, "please provide a context name, or the --none flag to stop using contexts")
+	} else {
+		name = args[0]
+	}
+
+	rcs, err := lib.LoadRepoContexts(o.contextsPath)
+	if err != nil {
+		return err
+	}
+	if err := rcs.Use(name); err != nil {
+		return err
+	}
+	if err := rcs.Save(o.contextsPath); err != nil {
+		return err
+	}
+
+	if name == "" {
+		printSuccess(o.Out, "stopped using contexts")
+	} else {
+		printSuccess(o.Out, "switched to context %q", name)
+	}
+	return nil
+}
+
+// Set creates or replaces a context
+func (o *ContextOptions) Set(args []string) error {
+	path, err := filepath.Abs(o.Path)
+	if err != nil {
+		return err
+	}
+
+	rcs, err := lib.LoadRepoContexts(o.contextsPath)
+	if err != nil {
+		return err
+	}
+	err = rcs.Set(&lib.RepoContext{
+		Name:          args[0],
+		RepoPath:      path,
+		Remote:        o.Remote,
+		ConfigProfile: o.ConfigProfile,
+	})
+	if err != nil {
+		return err
+	}
+	if err := rcs.Save(o.contextsPath); err != nil {
+		return err
+	}
+
+	printSuccess(o.Out, "set context %q", args[0])
+	if err := lib.QriRepoExists(path); err != nil {
+		printWarning(o.Out, "no qri repo exists at %s. create one with `qri setup --context %s`", path, args[0])
+	}
+	return nil
+}
+
+// Remove deletes a context
+func (o *ContextOptions) Remove(args []string) error {
+	rcs, err := lib.LoadRepoContexts(o.contextsPath)
+	if err != nil {
+		return err
+	}
+	if err := rcs.Remove(args[0]); err != nil {
+		return err
+	}
+	if err := rcs.Save(o.contextsPath); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "removed context %q", args[0])
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewCopyCommand creates a `qri copy` command that copies a dataset from
+// another qri repo on the same machine
+func NewCopyCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &CopyOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "copy --from-repo PATH DATASET",
+		Short: "copy a dataset from another local repo",
+		Long: `Copy moves a dataset between two qri repos on the same machine without
+going through a remote. The repo given with --from-repo is opened without
+connecting to the network, and the dataset's stored versions & history are
+copied into your repo.
+
+Versions the source repo doesn't store locally are copied as history only.
+The source repo can't be in use by a running qri process while copying.`,
+		Example: `  # copy a dataset from a repo in another directory:
+  $ qri copy --from-repo ~/old_qri me/annual_pop`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.FromRepo, "from-repo", "", "path to the qri repo to copy from")
+	cmd.MarkFlagRequired("from-repo")
+
+	return cmd
+}
+
+// CopyOptions encapsulates state for the copy command
+type CopyOptions struct {
+	ioes.IOStreams
+
+	Ref      string
+	FromRepo string
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *CopyOptions) Complete(f Factory, args []string) (err error) {
+	o.Ref = args[0]
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Run copies the dataset
+func (o *CopyOptions) Run() error {
+	ctx := context.TODO()
+	p := &lib.CopyParams{
+		Ref:      o.Ref,
+		FromRepo: o.FromRepo,
+	}
+	res, err := o.inst.Dataset().Copy(ctx, p)
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, "copied %s: %d versions, %d blocks", res.Ref.Alias(), res.Versions, res.Blocks)
+	return nil
+}
@@ -3,11 +3,14 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"path/filepath"
 	"strings"
 
+	"github.com/qri-io/deepdiff"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/base/component"
+	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/lib"
 	"github.com/spf13/cobra"
 )
@@ -31,7 +34,17 @@ qri diff works on structured data. qri diffs are measured in elements
 (think cells in a spreadsheet), each change is either an insert (added 
 elements), delete (removed elements), or update (changed values).
 
-Each change has a path that locates it within the document`,
+Each change has a path that locates it within the document
+
+Diffing a dataset against a file parses the file with the dataset's
+structure & compares it to the dataset body without saving anything, which
+is handy for checking changes before committing them.
+
+Use --stat to print a summary of changes per component, with totals of rows
+added, removed & modified in the body. With --stat, diff exits with status 0
+when there are no differences, 1 when there are differences, and a status
+greater than 1 if an error occurs, so scripts & CI jobs can gate on data
+changes.`,
 		Example: `  # Diff between a latest version & the next one back:
   $ qri diff me/annual_pop
 
@@ -48,20 +61,27 @@ Each change has a path that locates it within the document`,
   $ qri diff a.json b.json
 
   # Diff a json & csv file:
-  $ qri diff some_table.csv b.json`,
+  $ qri diff some_table.csv b.json
+
+  # Diff a dataset body against a file before saving it:
+  $ qri diff me/annual_pop body.csv
+
+  # Fail a CI job if a dataset changed between two versions:
+  $ qri diff --stat me/population_2016 me/population_2017`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
-				return err
+				return o.statError(err)
 			}
-			return o.Run()
+			return o.statError(o.Run())
 		},
 	}
 
 	cmd.Flags().StringVarP(&o.Format, "format", "f", "pretty", "output format. one of [json,pretty]")
 	cmd.Flags().BoolVar(&o.Summary, "summary", false, "just output the summary")
+	cmd.Flags().BoolVar(&o.Stat, "stat", false, "output per-component change counts & exit with status 1 if sources differ")
 
 	return cmd
 }
@@ -74,6 +94,7 @@ type DiffOptions struct {
 	Selector string
 	Format   string
 	Summary  bool
+	Stat     bool
 
 	inst *lib.Instance
 }
@@ -141,6 +162,10 @@ func (o *DiffOptions) Run() (err error) {
 		return err
 	}
 
+	if o.Stat {
+		return o.runStat(ctx, p, res)
+	}
+
 	if o.Format == "json" {
 		json.NewEncoder(o.Out).Encode(res)
 		return
@@ -148,3 +173,153 @@ func (o *DiffOptions) Run() (err error) {
 
 	return printDiff(o.Out, res, o.Summary)
 }
+
+// ErrDiffChanged is returned by `qri diff --stat` when the compared sources
+// differ. It isn't a failure, and exits with status 1 without printing
+var ErrDiffChanged = errors.New("sources differ")
+
+// DiffStatSummary summarizes the changes in a diff
+type DiffStatSummary struct {
+	// Components maps component names to the number of changes in each
+	// component. only components that changed are included
+	Components map[string]int `json:"components"`
+	// Rows counts changes to the rows of a body. nil if the body wasn't diffed
+	Rows *DiffRowStat `json:"rows,omitempty"`
+}
+
+// DiffRowStat counts row-level changes to a dataset body
+type DiffRowStat struct {
+	Added    int `json:"added"`
+	Removed  int `json:"removed"`
+	Modified int `json:"modified"`
+}
+
+// Changed returns true if the summary contains any changes
+func (s *DiffStatSummary) Changed() bool {
+	if len(s.Components) > 0 {
+		return true
+	}
+	return s.Rows != nil && (s.Rows.Added+s.Rows.Removed+s.Rows.Modified) > 0
+}
+
+// runStat prints a summary of a diff, returning ErrDiffChanged if there are
+// any differences
+func (o *DiffOptions) runStat(ctx context.Context, p *lib.DiffParams, res *lib.DiffResponse) error {
+	sum := &DiffStatSummary{Components: map[string]int{}}
+
+	if o.Selector == "" && !o.comparesFile() {
+		// a full dataset diff describes components
+		for _, d := range res.Diff {
+			if n := countDeltaChanges(d); n > 0 {
+				sum.Components[d.Path.String()] = n
+			}
+		}
+		// diffing a dataset compares body paths, diff the body separately to
+		// count row changes
+		bp := *p
+		bp.Selector = "body"
+		bodyRes, err := o.inst.Dataset().Diff(ctx, &bp)
+		if err != nil {
+			return err
+		}
+		sum.Rows = countRowChanges(bodyRes.Diff)
+	} else {
+		name := o.Selector
+		if name == "" {
+			name = "body"
+		}
+		n := 0
+		for _, d := range res.Diff {
+			n += countDeltaChanges(d)
+		}
+		if n > 0 {
+			sum.Components[name] = n
+		}
+		if name == "body" {
+			sum.Rows = countRowChanges(res.Diff)
+		}
+	}
+
+	if o.Format == "json" {
+		if err := json.NewEncoder(o.Out).Encode(sum); err != nil {
+			return err
+		}
+	} else {
+		printDiffStat(o.Out, sum)
+	}
+
+	if sum.Changed() {
+		return ErrDiffChanged
+	}
+	return nil
+}
+
+// comparesFile returns true when the right side of the diff is a file, which
+// compares bodies instead of datasets
+func (o *DiffOptions) comparesFile() bool {
+	refs := o.Refs.RefList()
+	return len(refs) == 2 && !dsref.IsRefString(refs[1])
+}
+
+// statError assigns a distinct exit code to errors when running with --stat,
+// so they can't be confused with ErrDiffChanged
+func (o *DiffOptions) statError(err error) error {
+	if err == nil || !o.Stat || errors.Is(err, ErrDiffChanged) {
+		return err
+	}
+	return exitCodeError{code: ExitCodeDiffErr, err: err}
+}
+
+// countDeltaChanges counts leaf-level changes in a delta, combining a delete
+// followed by an insert at the same path into a single change
+func countDeltaChanges(d *lib.Delta) int {
+	if d.Type != deepdiff.DTContext {
+		return 1
+	}
+	n := 0
+	for i, child := range d.Deltas {
+		if isReplace(d.Deltas, i) {
+			continue
+		}
+		n += countDeltaChanges(child)
+	}
+	return n
+}
+
+// countRowChanges tallies row-level changes from the top level deltas of a
+// body diff
+func countRowChanges(deltas []*lib.Delta) *DiffRowStat {
+	rows := &DiffRowStat{}
+	for i, d := range deltas {
+		if isReplace(deltas, i) {
+			continue
+		}
+		switch d.Type {
+		case deepdiff.DTInsert:
+			if i > 0 && deltas[i-1].Type == deepdiff.DTDelete && deltas[i-1].Path.String() == d.Path.String() {
+				rows.Modified++
+			} else {
+				rows.Added++
+			}
+		case deepdiff.DTDelete:
+			rows.Removed++
+		case deepdiff.DTContext:
+			if countDeltaChanges(d) > 0 {
+				rows.Modified++
+			}
+		default:
+			rows.Modified++
+		}
+	}
+	return rows
+}
+
+// isReplace returns true if deltas[i] is a delete immediately followed by an
+// insert at the same path. the pair is counted once, as the insert
+func isReplace(deltas []*lib.Delta, i int) bool {
+	if i+1 >= len(deltas) {
+		return false
+	}
+	d, next := deltas[i], deltas[i+1]
+	return d.Type == deepdiff.DTDelete && next.Type == deepdiff.DTInsert && d.Path.String() == next.Path.String()
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -256,3 +257,59 @@ func TestDiffKnownFilenameComponent(t *testing.T) {
 		t.Errorf("output mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestDiffStat(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_diff_stat", "qri_test_diff_stat")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+	run.MustExec(t, "qri save --body=testdata/movies/body_twenty.csv me/test_movies")
+
+	err := run.ExecCommand("qri diff --stat body me/test_movies")
+	if !errors.Is(err, ErrDiffChanged) {
+		t.Fatalf("expected changed datasets to return ErrDiffChanged, got: %v", err)
+	}
+	expect := "rows: 10 added, 0 removed, 0 modified"
+	if output := run.GetCommandOutput(); !strings.Contains(output, expect) {
+		t.Errorf("expected output to contain %q, got:\n%s", expect, output)
+	}
+
+	run.IOReset()
+	if err := run.ExecCommand("qri diff --stat testdata/movies/body_ten.csv testdata/movies/body_ten.csv"); err != nil {
+		t.Fatalf("expected identical sources to not error, got: %s", err)
+	}
+	if output := run.GetCommandOutput(); !strings.Contains(output, "no changes") {
+		t.Errorf("expected identical sources to print no changes, got:\n%s", output)
+	}
+
+	run.IOReset()
+	err = run.ExecCommand("qri diff --stat me/not_a_dataset me/test_movies")
+	var codeErr exitCodeError
+	if !errors.As(err, &codeErr) || codeErr.code != ExitCodeDiffErr {
+		t.Errorf("expected error to exit with code %d, got: %v", ExitCodeDiffErr, err)
+	}
+}
+
+func TestDiffBodyFile(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_diff_body_file", "qri_test_diff_body_file")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+
+	err := run.ExecCommand("qri diff --stat me/test_movies testdata/movies/body_twenty.csv")
+	if !errors.Is(err, ErrDiffChanged) {
+		t.Fatalf("expected a changed body file to return ErrDiffChanged, got: %v", err)
+	}
+	expect := "rows: 10 added, 0 removed, 0 modified"
+	if output := run.GetCommandOutput(); !strings.Contains(output, expect) {
+		t.Errorf("expected output to contain %q, got:\n%s", expect, output)
+	}
+
+	run.IOReset()
+	if err := run.ExecCommand("qri diff --stat me/test_movies testdata/movies/body_ten.csv"); err != nil {
+		t.Fatalf("expected the saved body file to not differ, got: %s", err)
+	}
+	if output := run.GetCommandOutput(); !strings.Contains(output, "no changes") {
+		t.Errorf("expected saved body file to print no changes, got:\n%s", output)
+	}
+}
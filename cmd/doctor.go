@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo/backup"
+	"github.com/spf13/cobra"
+)
+
+// NewDoctorCommand creates a new `qri doctor` command that checks instance
+// health & recently saved dataset bodies
+func NewDoctorCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &DoctorOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "check the health of your qri instance",
+		Long: `Doctor checks each subsystem qri depends on, then recomputes checksums of
+recently saved large dataset bodies.
+
+When qri starts it checks the logbook & dscache files for damage left by a
+crash, restoring the most recent intact backup of a damaged file. Doctor lists
+any files that were restored. Damaged files are kept with a ".corrupt" suffix.
+
+Bodies too big to diff are compared by checksum, so a body that's been
+corrupted on disk can go unnoticed. Qri checks bodies of the most recent
+large saves in the background, doctor runs the check right away. Doctor
+exits with an error when any body doesn't match its checksum. Use
+` + "`qri verify-repo`" + ` to check every stored version.`,
+		Example: `  # check your instance:
+  $ qri doctor
+
+  # print a machine-readable report:
+  $ qri doctor --json`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.JSON, "json", false, "print the report as JSON")
+
+	return cmd
+}
+
+// DoctorOptions encapsulates state for the doctor command
+type DoctorOptions struct {
+	ioes.IOStreams
+
+	JSON bool
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *DoctorOptions) Complete(f Factory, args []string) (err error) {
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Run checks the instance & prints the report
+func (o *DoctorOptions) Run() error {
+	ctx := context.TODO()
+	report, err := o.inst.Admin().Doctor(ctx, &lib.DoctorParams{})
+	if err != nil {
+		return err
+	}
+
+	if o.JSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		printInfo(o.Out, "%s", data)
+	} else {
+		for _, hc := range report.Checks {
+			if hc.OK {
+				printSuccess(o.Out, "ok\t%s", hc.Name)
+			} else {
+				printWarning(o.Out, "failed\t%s\t%s", hc.Name, hc.Detail)
+			}
+		}
+		for _, bc := range report.Bodies {
+			msg := fmt.Sprintf("%s\t%s\t%s", bc.Status, bc.Ref, bc.BodyPath)
+			if bc.Error != "" {
+				msg += "\t" + bc.Error
+			}
+			if bc.Status == dsfs.VerifyInvalid {
+				printWarning(o.Out, "%s", msg)
+			} else {
+				printSuccess(o.Out, "%s", msg)
+			}
+		}
+		for _, chk := range report.Integrity {
+			switch chk.Status {
+			case backup.StatusRecovered:
+				printWarning(o.Out, "%s\t%s\trestored from %s\t%s", chk.Status, chk.Filename, chk.Backup, chk.Error)
+			case backup.StatusUnrecoverable:
+				printWarning(o.Out, "%s\t%s\t%s", chk.Status, chk.Filename, chk.Error)
+			}
+		}
+		printInfo(o.Out, "\n%d large bodies checked: %d corrupt", len(report.Bodies), report.Corrupt)
+	}
+
+	if report.Corrupt > 0 {
+		return fmt.Errorf("%d corrupt dataset bodies", report.Corrupt)
+	}
+	if !report.Ready {
+		return fmt.Errorf("required health checks failed")
+	}
+	return nil
+}
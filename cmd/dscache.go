@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/dscache/build"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewDscacheCommand creates a `qri dscache` command for moving the dataset
+// cache between machines
+func NewDscacheCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &DscacheOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:    "dscache",
+		Hidden: true,
+		Short:  "export & import the dataset cache",
+		Long: `The dataset cache (dscache) is built from the logbook. Rebuilding it from a
+large logbook is slow, so a cache exported on one machine can be imported on
+another that shares the same logbook. Imports are checked against logbook
+heads, and the cache is rebuilt if they don't match.`,
+	}
+
+	export := &cobra.Command{
+		Use:   "export FILE",
+		Short: "write a portable copy of the dataset cache",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Export(args[0])
+		},
+	}
+
+	imp := &cobra.Command{
+		Use:   "import FILE",
+		Short: "load an exported dataset cache, rebuilding on mismatch",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Import(args[0])
+		},
+	}
+
+	cmd.AddCommand(export, imp)
+	return cmd
+}
+
+// DscacheOptions encapsulates state for the dscache command
+type DscacheOptions struct {
+	ioes.IOStreams
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *DscacheOptions) Complete(f Factory) (err error) {
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Export writes the dataset cache to a file
+func (o *DscacheOptions) Export(filename string) error {
+	cache := o.inst.Dscache()
+	if cache.IsEmpty() {
+		return fmt.Errorf("dataset cache is empty, nothing to export")
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := cache.Export(f); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "exported dataset cache to %s", filename)
+	return nil
+}
+
+// Import replaces the dataset cache with an export, rebuilding from the
+// logbook if the export doesn't match
+func (o *DscacheOptions) Import(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	built, rebuilt, err := build.DscacheFromExportOrRepo(context.TODO(), o.inst.Repo(), f)
+	if err != nil {
+		return err
+	}
+	if err := o.inst.Dscache().Assign(built); err != nil {
+		return err
+	}
+
+	if rebuilt {
+		printWarning(o.Out, "export didn't match the logbook, rebuilt the dataset cache instead")
+		return nil
+	}
+	printSuccess(o.Out, "imported dataset cache from %s", filename)
+	return nil
+}
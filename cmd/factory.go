@@ -45,3 +45,17 @@ func StandardRepoPath() string {
 
 	return qriRepoPath
 }
+
+// StandardContextsPath returns the path to the contexts file based on the
+// QRI_CONTEXTS_PATH environment variable falling back to the default:
+// $HOME/.qri_contexts.yaml
+func StandardContextsPath() string {
+	if path := os.Getenv("QRI_CONTEXTS_PATH"); path != "" {
+		return path
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(home, ".qri_contexts.yaml")
+}
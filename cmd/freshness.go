@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewFreshnessCommand creates a `qri freshness` command that manages checks
+// for new data at the source of a dataset body
+func NewFreshnessCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &FreshnessOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "freshness [DATASET]",
+		Short: "check dataset sources for new data before saving",
+		Long: `Freshness checks look for new data at the url a dataset body is downloaded
+from, saving a new version only when the source has changed. Checks run in the
+background every hour while qri is connected, making a lightweight HEAD request
+instead of downloading the body. Each check publishes a dataset:Freshness
+event, whether the source changed or not.
+
+Sources that don't report an ETag or Last-Modified date can't be checked
+without downloading, for those a full download runs every check, but a
+version is still only saved if the body changed.
+
+Checks can also be defined by workflows kept in datasets, giving them the
+same history as data. Workflow datasets are datasets in your namespace named
+with the "workflow_" prefix. The body of a workflow dataset is an array of
+workflows, each an object with "type", "ref", "bodyURL" & "active" fields.
+The only workflow type is "freshness". Active workflows are scheduled before
+each round of checks, and checks are removed when their workflow is removed or
+deactivated. Use --workflows to list workflows & schedule them right away.
+
+Workflows can also set a failure policy. "maxAttempts" retries a failed check,
+waiting "backoff" (like "30s") before the first retry & doubling the wait for
+each later one. "webhooks" lists urls notified with a POST request when a
+check fails. After "pauseAfter" consecutive failed checks (5 by default, -1
+never pauses) a check is paused & skipped until it's resumed with --resume.
+
+With no arguments freshness lists checks.`,
+		Example: `  # check a dataset source every hour
+  $ qri freshness me/population --body-url https://example.com/population.csv
+
+  # check every dataset source now
+  $ qri freshness --run
+
+  # stop checking a dataset source
+  $ qri freshness me/population --remove
+
+  # resume a check paused after repeated failures
+  $ qri freshness me/population --resume
+
+  # define checks in a workflow dataset, then schedule them
+  $ qri save me/workflow_sources --body workflows.json
+  $ qri freshness --workflows`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.BodyURL, "body-url", "", "http(s) url to check for new data")
+	cmd.Flags().BoolVar(&o.Remove, "remove", false, "stop checking the dataset source")
+	cmd.Flags().BoolVar(&o.RunNow, "run", false, "check now instead of waiting for the next scheduled check")
+	cmd.Flags().BoolVar(&o.Workflows, "workflows", false, "list & schedule workflows defined in workflow datasets")
+	cmd.Flags().BoolVar(&o.Resume, "resume", false, "resume a check paused after repeated failures")
+
+	return cmd
+}
+
+// FreshnessOptions encapsulates state for the freshness command
+type FreshnessOptions struct {
+	ioes.IOStreams
+
+	Ref       string
+	BodyURL   string
+	Remove    bool
+	RunNow    bool
+	Workflows bool
+	Resume    bool
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *FreshnessOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Ref = args[0]
+	}
+	if o.Ref == "" && (o.BodyURL != "" || o.Remove || o.Resume) {
+		return fmt.Errorf("a dataset reference is required")
+	}
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Run adds, removes, runs or lists freshness checks
+func (o *FreshnessOptions) Run() error {
+	ctx := context.TODO()
+	if o.Workflows {
+		return o.listWorkflows(ctx)
+	}
+	if o.Resume {
+		if _, err := o.inst.Automation().Resume(ctx, &lib.ResumeParams{Ref: o.Ref}); err != nil {
+			return err
+		}
+		printSuccess(o.Out, "resumed checking %s", o.Ref)
+		return nil
+	}
+	p := &lib.FreshnessParams{
+		Ref:     o.Ref,
+		BodyURL: o.BodyURL,
+		Remove:  o.Remove,
+		Run:     o.RunNow,
+		List:    o.Ref == "" && !o.RunNow,
+	}
+	checks, err := o.inst.Automation().Freshness(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case o.Remove:
+		printSuccess(o.Out, "stopped checking %s", o.Ref)
+		return nil
+	case o.BodyURL != "" && !o.RunNow:
+		printSuccess(o.Out, "checking %s for new data", o.BodyURL)
+		return nil
+	}
+
+	if len(checks) == 0 {
+		printInfo(o.Out, "no freshness checks")
+		return nil
+	}
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "DATASET\tSOURCE\tLAST CHECKED\tLAST CHANGED\tFAILURES\tPAUSED\tERROR\n")
+	for _, c := range checks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%t\t%s\n", c.Ref, c.BodyURL, freshnessTime(c.LastChecked), freshnessTime(c.LastChanged), c.Failures, c.Paused, c.LastError)
+	}
+	return w.Flush()
+}
+
+func (o *FreshnessOptions) listWorkflows(ctx context.Context) error {
+	workflows, err := o.inst.Automation().Workflows(ctx, &lib.WorkflowsParams{})
+	if err != nil {
+		return err
+	}
+	if len(workflows) == 0 {
+		printInfo(o.Out, "no workflows")
+		return nil
+	}
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "WORKFLOW\tTYPE\tDATASET\tSOURCE\tACTIVE\tERROR\n")
+	for _, wf := range workflows {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\n", wf.Dataset, wf.Type, wf.Ref, wf.BodyURL, wf.Active, wf.Error)
+	}
+	return w.Flush()
+}
+
+func freshnessTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -27,7 +27,8 @@ func NewFSICommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 		Short: "link a dataset to a directory on disk",
 		Example: `  # Link a dataset to the current working directory:
   $ qri workdir link peername/dataset .`,
-		Args: cobra.ExactArgs(2),
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeRef(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -40,7 +41,8 @@ func NewFSICommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 		Use:   "unlink DATASET",
 		Short: "unlink a dataset from a directory on disk",
 		// Use max instead of exact args so we can provide a nicer error.
-		Args: cobra.MaximumNArgs(1),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeRef(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -50,7 +52,52 @@ func NewFSICommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 		},
 	}
 
-	cmd.AddCommand(link, unlink)
+	git := &cobra.Command{
+		Use:   "git",
+		Short: "mirror dataset components into a git repository",
+		Long: `Git exchanges dataset versions with a branch of a git repository, so changes
+to dataset components can be reviewed with git tools. Export commits each qri
+version to the branch, one commit per version. Import saves changes committed
+to the branch in git as a new dataset version.
+
+Branches default to 'qri/' followed by the dataset name. Qri writes branches
+without touching the repository working directory.`,
+	}
+
+	gitExport := &cobra.Command{
+		Use:   "export DATASET REPO_PATH",
+		Short: "commit dataset versions to a git branch",
+		Example: `  # commit all versions of a dataset to the qri/annual_pop branch:
+  $ qri workdir git export me/annual_pop ~/code/population`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeRef(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.GitExport(context.TODO())
+		},
+	}
+	gitExport.Flags().StringVar(&o.Branch, "branch", "", "git branch to export to")
+
+	gitImport := &cobra.Command{
+		Use:   "import DATASET REPO_PATH",
+		Short: "save the tip of a git branch as a dataset version",
+		Example: `  # save changes committed to the qri/annual_pop branch:
+  $ qri workdir git import me/annual_pop ~/code/population`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeRef(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.GitImport(context.TODO())
+		},
+	}
+	gitImport.Flags().StringVar(&o.Branch, "branch", "", "git branch to import from")
+
+	git.AddCommand(gitExport, gitImport)
+	cmd.AddCommand(link, unlink, git)
 	return cmd
 }
 
@@ -60,8 +107,9 @@ type FSIOptions struct {
 
 	Instance *lib.Instance
 
-	Refs *RefSelect
-	Path string
+	Refs   *RefSelect
+	Path   string
+	Branch string
 }
 
 // Complete adds any missing configuration that can only be added just before
@@ -122,3 +170,39 @@ func (o *FSIOptions) Unlink(ctx context.Context) error {
 	}
 	return nil
 }
+
+// GitExport commits dataset versions to a git branch
+func (o *FSIOptions) GitExport(ctx context.Context) error {
+	res, err := o.Instance.Filesys().GitExport(ctx, o.gitParams())
+	if err != nil {
+		return err
+	}
+	if res.Exported == 0 {
+		printInfo(o.Out, "branch %s is up to date", res.Branch)
+		return nil
+	}
+	printSuccess(o.Out, "exported %d versions to branch %s", res.Exported, res.Branch)
+	return nil
+}
+
+// GitImport saves the tip of a git branch as a new dataset version
+func (o *FSIOptions) GitImport(ctx context.Context) error {
+	res, err := o.Instance.Filesys().GitImport(ctx, o.gitParams())
+	if err != nil {
+		return err
+	}
+	if res.Path == "" {
+		printInfo(o.Out, "no changes to import from branch %s", res.Branch)
+		return nil
+	}
+	printSuccess(o.Out, "saved commit %s from branch %s as version %s", res.Head, res.Branch, res.Path)
+	return nil
+}
+
+func (o *FSIOptions) gitParams() *lib.GitParams {
+	return &lib.GitParams{
+		Refstr: o.Refs.Ref(),
+		Dir:    o.Path,
+		Branch: o.Branch,
+	}
+}
@@ -364,6 +364,27 @@ func TestStatusCannotUseRef(t *testing.T) {
 	}
 }
 
+// Test that status --all reports on every linked working directory
+func TestStatusAll(t *testing.T) {
+	run := NewFSITestRunner(t, "test_peer_status_all", "qri_test_status_all")
+	defer run.Delete()
+
+	_ = run.CreateAndChdirToWorkDir("status_all")
+	run.MustExec(t, "qri init --name status_all --format csv")
+
+	output := run.MustExec(t, "qri status --all --offline")
+	if !strings.Contains(output, "test_peer_status_all/status_all") {
+		t.Errorf("expected status --all to list linked dataset, got:\n%s", output)
+	}
+	if !strings.Contains(output, "body add") {
+		t.Errorf("expected status --all to report added body, got:\n%s", output)
+	}
+
+	if err := run.ExecCommand("qri status --all me/status_all"); err == nil {
+		t.Error("expected error passing a reference with --all")
+	}
+}
+
 // Test that we can get the body even if structure has been deleted.
 func TestGetBodyWithoutStructure(t *testing.T) {
 	run := NewFSITestRunner(t, "test_peer_get_body_without_structure", "qri_test_get_body_without_structure")
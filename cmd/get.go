@@ -9,6 +9,7 @@ import (
 	"github.com/qri-io/ioes"
 	apiutil "github.com/qri-io/qri/api/util"
 	"github.com/qri-io/qri/base/component"
+	"github.com/qri-io/qri/base/query"
 	"github.com/qri-io/qri/lib"
 	"github.com/spf13/cobra"
 )
@@ -24,6 +25,13 @@ the dataset: meta, structure, viz, transform, and commit. To narrow down
 further to specific fields in each section, use dot notation. The get 
 command prints to the console in yaml format, by default.
 
+Selectors can also be JSONPath-style queries into components & the body.
+Brackets index lists (body[0], body[-1]), slice them (body[10:20]), project
+fields from every element (body[*].title) or filter elements
+(body[?duration > 150 && color]). Rows of tabular bodies are queried as
+objects keyed by column title. Quote queries to keep your shell from
+interpreting brackets.
+
 Check out https://qri.io/docs/reference/dataset/ to learn about each section of the 
 dataset and its fields.`,
 		Example: `  # Print the entire dataset to the console:
@@ -33,11 +41,33 @@ dataset and its fields.`,
   $ qri get meta me/annual_pop
 
   # Print the dataset body size to the console:
-  $ qri get structure.length me/annual_pop`,
+  $ qri get structure.length me/annual_pop
+
+  # Print the body of the latest version saved on or before a date:
+  $ qri get body me/annual_pop@2023-06-01
+
+  # Print the readme converted to html:
+  $ qri get readme.html me/annual_pop
+
+  # Print the environment recorded when the latest version was saved:
+  $ qri get environment me/annual_pop
+
+  # Query the body, printing titles of movies longer than 150 minutes:
+  $ qri get 'body[?duration > 150].movie_title' me/movies
+
+  # Print the last three keywords:
+  $ qri get 'meta.keywords[-3:]' me/movies
+
+  # Export the body as a SQL dump:
+  $ qri get body --format sql --table annual_pop me/annual_pop > annual_pop.sql
+
+  # Export the body as gzipped JSONL files of 10000 rows each:
+  $ qri get body --format jsonl --partition-rows 10000 --gzip -o annual_pop me/annual_pop`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
-		Args: cobra.MaximumNArgs(2),
+		Args:              cobra.MaximumNArgs(2),
+		ValidArgsFunction: completeGetArgs(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Special case for --pretty, check if it was passed vs if the default was used.
 			if cmd.Flags().Changed("pretty") {
@@ -50,12 +80,16 @@ dataset and its fields.`,
 		},
 	}
 
-	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "set output format [json, yaml]")
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "set output format [json, yaml, csv, zip, sql, jsonl, parquet]")
 	cmd.Flags().BoolVar(&o.Pretty, "pretty", false, "whether to print output with indentation, only for json format")
 	cmd.Flags().IntVar(&o.PageSize, "page-size", -1, "for body, limit how many entries to get per page")
 	cmd.Flags().IntVar(&o.Page, "page", -1, "for body, page at which to get entries")
 	cmd.Flags().BoolVarP(&o.All, "all", "a", true, "for body, whether to get all entries")
 	cmd.Flags().StringVarP(&o.Outfile, "outfile", "o", "", "file to write output to")
+	cmd.Flags().StringVar(&o.Table, "table", "", "for sql format, name of the table to create")
+	cmd.Flags().IntVar(&o.PartitionRows, "partition-rows", 0, "for jsonl format, number of rows written to each file")
+	cmd.Flags().BoolVar(&o.Gzip, "gzip", false, "for jsonl format, gzip each file")
+	cmd.Flags().StringVar(&o.Lang, "lang", "", "show the translated title, description & readme for a language")
 
 	cmd.Flags().BoolVar(&o.Offline, "offline", false, "prevent network access")
 	cmd.Flags().StringVar(&o.Remote, "remote", "", "name to get any remote data from")
@@ -79,8 +113,13 @@ type GetOptions struct {
 	HasPretty bool
 	Outfile   string
 
+	Table         string
+	PartitionRows int
+	Gzip          bool
+
 	Offline bool
 	Remote  string
+	Lang    string
 
 	inst *lib.Instance
 }
@@ -92,7 +131,7 @@ func (o *GetOptions) Complete(f Factory, args []string) (err error) {
 	}
 
 	if len(args) > 0 {
-		if component.IsDatasetField.MatchString(args[0]) {
+		if component.IsDatasetField.MatchString(args[0]) || query.IsQuery(args[0]) {
 			o.Selector = args[0]
 			args = args[1:]
 		}
@@ -122,6 +161,13 @@ func (o *GetOptions) Complete(f Factory, args []string) (err error) {
 		}
 	}
 
+	if o.Table != "" && o.Format != "sql" {
+		return fmt.Errorf("can only use --table flag with sql format")
+	}
+	if (o.PartitionRows != 0 || o.Gzip) && o.Format != "jsonl" {
+		return fmt.Errorf("can only use --partition-rows and --gzip flags with jsonl format")
+	}
+
 	return
 }
 
@@ -164,9 +210,13 @@ func (o *GetOptions) Run() (err error) {
 		All:          o.All,
 		Outfile:      o.Outfile,
 		// Generate a filename only if we're outputting to a terminal (not a pipe), and we're
-		// outputting a zip. lib.Get will also check that we're outputting a zip, this check is
-		// repeated here for clarity.
-		GenFilename: o.Outfile == "" && stdoutIsTerminal() && o.Format == "zip",
+		// outputting a zip or jsonl files. lib.Get will also check that we're outputting a zip,
+		// this check is repeated here for clarity.
+		GenFilename:   o.Outfile == "" && stdoutIsTerminal() && (o.Format == "zip" || o.Format == "jsonl" || o.Format == "parquet"),
+		Table:         o.Table,
+		PartitionRows: o.PartitionRows,
+		Gzip:          o.Gzip,
+		Lang:          o.Lang,
 	}
 	ctx := context.TODO()
 	res, err := o.inst.WithSource(o.Remote).Dataset().Get(ctx, &p)
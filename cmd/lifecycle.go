@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewLifecycleCommand creates a new `qri lifecycle` cobra command for
+// deprecating & archiving datasets
+func NewLifecycleCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &LifecycleOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "lifecycle DATASET STATE",
+		Short: "deprecate or archive a dataset",
+		Long: `Lifecycle sets the state of a dataset to one of:
+
+  active      the default state, the dataset is maintained
+  deprecated  the dataset still works, but shouldn't be used by new consumers
+  archived    the dataset is no longer maintained
+
+Deprecated and archived datasets are flagged in dataset listings & previews.
+Transforms that load a deprecated or archived dataset print a warning.
+Use --successor to point consumers to a dataset that replaces this one.`,
+		Example: `  # Deprecate a dataset in favour of a newer one:
+  $ qri lifecycle me/annual_pop deprecated --reason "moved" --successor me/annual_population
+
+  # Mark a dataset as active again:
+  $ qri lifecycle me/annual_pop active`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Reason, "reason", "", "explanation for the state change")
+	cmd.Flags().StringVar(&o.Successor, "successor", "", "reference to a dataset that replaces this one")
+
+	return cmd
+}
+
+// LifecycleOptions encapsulates state for the lifecycle command
+type LifecycleOptions struct {
+	ioes.IOStreams
+
+	Ref       string
+	State     string
+	Reason    string
+	Successor string
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *LifecycleOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) == 2 {
+		o.Ref = args[0]
+		o.State = args[1]
+	}
+	o.inst, err = f.Instance()
+	return
+}
+
+// Validate checks that all user input is valid
+func (o *LifecycleOptions) Validate() error {
+	if o.Ref == "" || o.State == "" {
+		return errors.New(lib.ErrBadArgs, "please provide a dataset name and a lifecycle state, for example:\n    $ qri lifecycle me/dataset_name deprecated\nsee `qri lifecycle --help` for more details")
+	}
+	return nil
+}
+
+// Run executes the lifecycle command
+func (o *LifecycleOptions) Run() error {
+	p := &lib.LifecycleParams{
+		Ref:       o.Ref,
+		State:     o.State,
+		Reason:    o.Reason,
+		Successor: o.Successor,
+	}
+	ctx := context.TODO()
+	res, err := o.inst.Dataset().Lifecycle(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if res.Lifecycle == "" {
+		printSuccess(o.Out, "%s is active", res.Alias())
+		return nil
+	}
+	printSuccess(o.Out, "%s is %s", res.Alias(), res.Lifecycle)
+	return nil
+}
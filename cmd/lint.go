@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/base/lint"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewLintCommand creates a new `qri lint` command that checks a dataset
+// against lint rules
+func NewLintCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &LintOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "lint [DATASET]",
+		Short: "check a dataset for style & completeness problems",
+		Long: `Lint checks a dataset against a set of rules, like "meta.title is required" or
+"column names are snake_case", and prints any problems found. Each problem has
+a severity of info, warning, or error.
+
+The severity of each rule can be changed, or a rule turned off, in the lint
+section of your config:
+
+  $ qri config set lint.rules.meta-license error
+
+Setting lint.blocksaves to true makes saves fail when linting finds errors.
+Lint exits with an error when any error-level problems are found. Use --rules
+to list available rules.`,
+		Example: `  # check a dataset for problems:
+  $ qri lint me/annual_pop
+
+  # print problems as JSON:
+  $ qri lint me/annual_pop --json`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if o.ListRules {
+				return o.PrintRules()
+			}
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.JSON, "json", false, "print the lint report as JSON")
+	cmd.Flags().BoolVar(&o.ListRules, "rules", false, "list available lint rules")
+
+	return cmd
+}
+
+// LintOptions encapsulates state for the lint command
+type LintOptions struct {
+	ioes.IOStreams
+
+	Refs      *RefSelect
+	JSON      bool
+	ListRules bool
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *LintOptions) Complete(f Factory, args []string) (err error) {
+	if o.inst, err = f.Instance(); err != nil {
+		return err
+	}
+	o.Refs, err = GetCurrentRefSelect(f, args, 1, EnsureFSIAgrees(o.inst))
+	return err
+}
+
+// Run lints a dataset & prints the report
+func (o *LintOptions) Run() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	ctx := context.TODO()
+	report, err := o.inst.Dataset().Lint(ctx, &lib.LintParams{Ref: o.Refs.Ref()})
+	if err != nil {
+		return err
+	}
+
+	if o.JSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		printInfo(o.Out, "%s", data)
+	} else if len(report.Problems) == 0 {
+		printSuccess(o.Out, "no problems found")
+	} else {
+		for _, p := range report.Problems {
+			msg := fmt.Sprintf("%s\t%s\t%s: %s", p.Severity, p.Rule, p.Component, p.Message)
+			if p.Severity == lint.SeverityInfo {
+				printInfo(o.Out, "%s", msg)
+			} else {
+				printWarning(o.Out, "%s", msg)
+			}
+		}
+		printInfo(o.Out, "\n%d errors, %d warnings, %d info", report.Count(lint.SeverityError), report.Count(lint.SeverityWarning), report.Count(lint.SeverityInfo))
+	}
+
+	if report.HasErrors() {
+		return fmt.Errorf("%d lint errors", report.Count(lint.SeverityError))
+	}
+	return nil
+}
+
+// PrintRules lists registered lint rules
+func (o *LintOptions) PrintRules() error {
+	for _, r := range lint.Rules() {
+		printInfo(o.Out, "%s\t%s\t%s", r.ID, r.Severity, r.Description)
+	}
+	return nil
+}
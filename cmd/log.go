@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"text/tabwriter"
 
+	"github.com/dustin/go-humanize"
 	"github.com/qri-io/ioes"
 	apiutil "github.com/qri-io/qri/api/util"
 	"github.com/qri-io/qri/dsref"
@@ -34,6 +36,15 @@ working backwards in time.
 
 The log command can get the list of versions for a local dataset or a dataset
 on the network at a remote.
+
+By default log only shows versions that are part of a dataset's current
+history. Use the --graph flag (or its alias --all) to show every operation
+on a local dataset, including transform runs, publishes to remotes, and
+deleted versions. Runs that saved a version are drawn beneath the version
+they created. Deleted & amended versions are marked with an "x".
+
+The --components flag lists which components (meta, structure, body, readme,
+transform, viz) each version of a local dataset added, modified, or removed.
 `,
 		Example: `  # Show log for the local dataset b5/precip:
   $ qri log b5/precip
@@ -42,11 +53,21 @@ on the network at a remote.
   $ qri log ramfox/league_stats
 	
   # Show log for a dataset chriswhong/nyc_parking_tickets on a remote named "nycdatacollection"
-  $ qri log chriswhong/nyc_parking_tickets --remote nycdatacollection`,
+  $ qri log chriswhong/nyc_parking_tickets --remote nycdatacollection
+
+  # Show every operation on b5/precip, including runs, pushes, and deletes
+  $ qri log b5/precip --graph
+
+  # Get the full operation graph as JSON
+  $ qri log b5/precip --graph --format json
+
+  # Show which components each version of b5/precip changed
+  $ qri log b5/precip --components`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
-		Args: cobra.MaximumNArgs(1),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeRef(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -55,7 +76,10 @@ on the network at a remote.
 		},
 	}
 
-	// cmd.Flags().StringVarP(&o.Format, "format", "f", "", "set output format [json]")
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "set output format [json]")
+	cmd.Flags().BoolVar(&o.Graph, "graph", false, "show all operations, including runs, pushes, and deleted versions")
+	cmd.Flags().BoolVar(&o.Graph, "all", false, "alias for --graph")
+	cmd.Flags().BoolVar(&o.Components, "components", false, "show the components each version changed")
 	cmd.Flags().IntVar(&o.PageSize, "page-size", 25, "page size of results, default 25")
 	cmd.Flags().IntVar(&o.Page, "page", 1, "page number of results, default 1")
 	cmd.Flags().StringVarP(&o.RemoteName, "remote", "", "", "name of remote to fetch from, disables local actions. `registry` will search the default qri registry")
@@ -74,6 +98,10 @@ type LogOptions struct {
 	Refs     *RefSelect
 	Local    bool
 	Pull     bool
+	Format   string
+	Graph    bool
+	// Components lists the components each version changed
+	Components bool
 
 	// remote fetching specific flags
 	RemoteName string
@@ -93,6 +121,15 @@ func (o *LogOptions) Complete(f Factory, args []string) (err error) {
 	if o.Local && (o.RemoteName != "" || o.Pull) {
 		return errors.New(err, "cannot use 'local' flag with either the 'remote' or 'pull' flags")
 	}
+	if o.Graph && (o.RemoteName != "" || o.Pull) {
+		return fmt.Errorf("the graph flag only works with local datasets, and can't be combined with the 'remote' or 'pull' flags")
+	}
+	if o.Components && (o.Graph || o.RemoteName != "" || o.Pull) {
+		return fmt.Errorf("the components flag only works with local datasets, and can't be combined with the 'graph', 'remote' or 'pull' flags")
+	}
+	if o.Format != "" && o.Format != "json" {
+		return fmt.Errorf("invalid format %q, only 'json' is supported", o.Format)
+	}
 
 	if o.Refs, err = GetCurrentRefSelect(f, args, AnyNumberOfReferences, nil); err != nil {
 		if err == repo.ErrEmptyRef {
@@ -110,6 +147,13 @@ func (o *LogOptions) Run() error {
 	// convert Page and PageSize to Limit and Offset
 	page := apiutil.NewPage(o.Page, o.PageSize)
 
+	if o.Graph {
+		return o.runGraph(page)
+	}
+	if o.Components {
+		return o.runComponents(page)
+	}
+
 	ctx := context.TODO()
 	p := &lib.HistoryParams{
 		Ref:    o.Refs.Ref(),
@@ -126,10 +170,65 @@ func (o *LogOptions) Run() error {
 		return err
 	}
 
+	if o.Format == "json" {
+		return printJSON(o.Out, res)
+	}
 	makeItemsAndPrint(res, o.Out, page)
 	return nil
 }
 
+// runGraph prints every operation on a dataset branch
+func (o *LogOptions) runGraph(page apiutil.Page) error {
+	ctx := context.TODO()
+	p := &lib.RefListParams{
+		Ref:    o.Refs.Ref(),
+		Offset: page.Offset(),
+		Limit:  page.Limit(),
+	}
+	res, err := o.Instance.Log().Graph(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		return printJSON(o.Out, res)
+	}
+	printToPager(o.Out, bytes.NewBufferString(logGraphString(res)))
+	return nil
+}
+
+// runComponents prints the components each version of a dataset changed
+func (o *LogOptions) runComponents(page apiutil.Page) error {
+	ctx := context.TODO()
+	p := &lib.RefListParams{
+		Ref:    o.Refs.Ref(),
+		Offset: page.Offset(),
+		Limit:  page.Limit(),
+	}
+	res, err := o.Instance.Log().Components(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		return printJSON(o.Out, res)
+	}
+	items := make([]fmt.Stringer, len(res))
+	for i, r := range res {
+		items[i] = componentLogItemStringer(r)
+	}
+	return printItems(o.Out, items, page.Offset())
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
 func makeItemsAndPrint(refs []dsref.VersionInfo, out io.Writer, page apiutil.Page) {
 	items := make([]fmt.Stringer, len(refs))
 	for i, r := range refs {
@@ -155,6 +254,7 @@ The logbook command shows entries for a dataset, from newest to oldest.`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
+		ValidArgsFunction: completeRef(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -173,6 +273,31 @@ The logbook command shows entries for a dataset, from newest to oldest.`,
 	cmd.Flags().BoolVar(&o.Raw, "raw", false, "full logbook in raw JSON format. overrides all other flags")
 	cmd.Flags().BoolVar(&o.Summary, "summary", false, "print one oplog per line in the format 'MODEL ID OPCOUNT NAME'. overrides all other flags")
 
+	stats := &cobra.Command{
+		Use:   "stats",
+		Short: "show logbook size & growth",
+		Long: `Stats lists the size of each dataset log in your logbook, largest first,
+along with the number of operations written each month. Logs with many
+operations, or many recent operations, are the best candidates for
+compaction.`,
+		Example: `  # show the 10 largest dataset logs:
+  $ qri logbook stats
+
+  # show every dataset log as JSON:
+  $ qri logbook stats --limit -1 --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if o.Instance, err = f.Instance(); err != nil {
+				return err
+			}
+			return o.Stats()
+		},
+	}
+	stats.Flags().IntVar(&o.Limit, "limit", 10, "number of dataset logs to show, -1 shows all logs")
+	stats.Flags().BoolVar(&o.JSON, "json", false, "print stats as JSON")
+	cmd.AddCommand(stats)
+
 	return cmd
 }
 
@@ -184,6 +309,8 @@ type LogbookOptions struct {
 	Page         int
 	Refs         *RefSelect
 	Raw, Summary bool
+	Limit        int
+	JSON         bool
 
 	Instance *lib.Instance
 }
@@ -273,3 +400,44 @@ func (o *LogbookOptions) LogbookSummary() error {
 	printToPager(o.Out, bytes.NewBufferString(*res))
 	return nil
 }
+
+// Stats prints logbook size & growth metrics
+func (o *LogbookOptions) Stats() error {
+	ctx := context.TODO()
+	res, err := o.Instance.Log().Metrics(ctx, &lib.LogbookMetricsParams{})
+	if err != nil {
+		return err
+	}
+	if o.Limit >= 0 && len(res.Datasets) > o.Limit {
+		res.Datasets = res.Datasets[:o.Limit]
+	}
+
+	if o.JSON {
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		printInfo(o.Out, "%s", data)
+		return nil
+	}
+
+	printInfo(o.Out, "%d dataset logs, %d operations, %s\n", res.Logs, res.Ops, humanize.Bytes(uint64(res.Bytes)))
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "DATASET\tOPS\tSIZE\tRECENT OPS\tLAST OP\n")
+	for _, lm := range res.Datasets {
+		ref := lm.Ref
+		if lm.Removed {
+			ref += " (removed)"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\n", ref, lm.Ops, humanize.Bytes(uint64(lm.Bytes)), lm.Recent, lm.Last.Format("2006-01-02"))
+	}
+	w.Flush()
+
+	fmt.Fprintln(o.Out)
+	w = tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "MONTH\tOPS\tNEW LOGS\n")
+	for _, g := range res.Growth {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", g.Start.Format("2006-01"), g.Ops, g.Logs)
+	}
+	return w.Flush()
+}
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
 	"regexp"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/qri-io/dataset/dstest"
+	"github.com/qri-io/qri/lib"
 )
 
 func TestLogbookCommand(t *testing.T) {
@@ -42,3 +44,72 @@ func TestLogbookCommand(t *testing.T) {
 		t.Errorf("unexpected (-want +got):\n%s", diff)
 	}
 }
+
+func TestLogGraph(t *testing.T) {
+	r := NewTestRunner(t, "test_peer_log_graph", "qri_test_log_graph")
+	defer r.Delete()
+
+	r.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+	r.MustExec(t, "qri save --body=testdata/movies/body_thirty.csv me/test_movies")
+	r.MustExec(t, "qri remove me/test_movies --revisions 1")
+
+	if err := r.ExecCommand("qri log me/test_movies --graph --remote registry"); err == nil {
+		t.Error("expected combining graph & remote flags to error")
+	}
+
+	output := r.MustExec(t, "qri log me/test_movies --graph --format json")
+	entries := []lib.LogGraphEntry{}
+	if err := json.Unmarshal([]byte(output), &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	types := make([]string, len(entries))
+	deleted := make([]bool, len(entries))
+	for i, e := range entries {
+		types[i] = e.Type
+		deleted[i] = e.Deleted
+	}
+	if diff := cmp.Diff([]string{"remove", "save", "save"}, types); diff != "" {
+		t.Errorf("entry type mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]bool{false, true, false}, deleted); diff != "" {
+		t.Errorf("tombstone mismatch (-want +got):\n%s", diff)
+	}
+
+	// --all is an alias for --graph
+	if output := r.MustExec(t, "qri log me/test_movies --all"); output == "" {
+		t.Error("expected --all to print the operation graph")
+	}
+}
+
+func TestLogComponents(t *testing.T) {
+	r := NewTestRunner(t, "test_peer_log_components", "qri_test_log_components")
+	defer r.Delete()
+
+	r.MustExec(t, "qri save --body=testdata/movies/body_ten.csv me/test_movies")
+	r.MustExec(t, "qri save --body=testdata/movies/body_thirty.csv me/test_movies")
+
+	if err := r.ExecCommand("qri log me/test_movies --components --graph"); err == nil {
+		t.Error("expected combining components & graph flags to error")
+	}
+
+	output := r.MustExec(t, "qri log me/test_movies --components --format json")
+	versions := []lib.VersionComponents{}
+	if err := json.Unmarshal([]byte(output), &versions); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(versions))
+	}
+
+	changed := map[string]string{}
+	for _, ch := range versions[0].Changes {
+		changed[ch.Component] = ch.Status
+	}
+	if changed["body"] != "modified" {
+		t.Errorf("expected head version to modify the body. got: %v", versions[0].Changes)
+	}
+	if _, ok := changed["meta"]; ok {
+		t.Errorf("expected head version to leave meta unchanged. got: %v", versions[0].Changes)
+	}
+}
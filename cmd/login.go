@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"syscall"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// NewLoginCommand creates a `qri login` command that stores credentials for
+// a remote
+func NewLoginCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &LoginOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "login [REMOTE]",
+		Short: "store an authentication token for a remote",
+		Long: `Login saves a token for authenticating with a remote that requires credentials.
+Tokens are encrypted with your private key and kept in the credentials section
+of your config. Once logged in, requests to the remote include the token.
+
+REMOTE is the name of a remote in your config, or "registry" for the configured
+registry, which is the default. Without --token, login prompts for a token.`,
+		Example: `  # log in to the registry:
+  $ qri login
+
+  # log in to a configured remote:
+  $ qri login my_remote --token $REMOTE_TOKEN`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Login()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Token, "token", "", "authentication token")
+
+	return cmd
+}
+
+// NewLogoutCommand creates a `qri logout` command that removes credentials
+// for a remote
+func NewLogoutCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &LoginOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "logout [REMOTE]",
+		Short: "remove the authentication token for a remote",
+		Long:  `Logout removes the stored token for a remote, defaulting to the registry.`,
+		Example: `  # log out of a configured remote:
+  $ qri logout my_remote`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Logout()
+		},
+	}
+
+	return cmd
+}
+
+// LoginOptions encapsulates state for the login & logout commands
+type LoginOptions struct {
+	ioes.IOStreams
+
+	Remote string
+	Token  string
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *LoginOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Remote = args[0]
+	}
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Login stores a token for a remote
+func (o *LoginOptions) Login() error {
+	if o.Token == "" {
+		tok, err := o.promptForToken()
+		if err != nil {
+			return err
+		}
+		o.Token = strings.TrimSpace(tok)
+	}
+
+	ctx := context.TODO()
+	if err := o.inst.Config().Login(ctx, &lib.LoginParams{Remote: o.Remote, Token: o.Token}); err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "logged in to %s", o.remoteName())
+	return nil
+}
+
+// Logout removes the token for a remote
+func (o *LoginOptions) Logout() error {
+	ctx := context.TODO()
+	if err := o.inst.Config().Logout(ctx, &lib.LogoutParams{Remote: o.Remote}); err != nil {
+		return err
+	}
+	printSuccess(o.ErrOut, "logged out of %s", o.remoteName())
+	return nil
+}
+
+func (o *LoginOptions) remoteName() string {
+	if o.Remote == "" {
+		return "registry"
+	}
+	return o.Remote
+}
+
+// promptForToken reads a token without echoing it to the screen
+func (o *LoginOptions) promptForToken() (string, error) {
+	io.WriteString(o.Out, "token: ")
+	tok, err := terminal.ReadPassword(int(syscall.Stdin))
+	io.WriteString(o.Out, "\n")
+	if err != nil {
+		// reading from a non-terminal fails with an error mentioning the device,
+		// fall back to reading input directly
+		if strings.Contains(err.Error(), "device") {
+			tok, err = ioutil.ReadAll(o.In)
+		} else {
+			return "", err
+		}
+	}
+	return string(tok), nil
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/ioes"
+	qrierr "github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewMetaCommand creates a new `qri meta` cobra command for working with the
+// meta component of many datasets at once
+func NewMetaCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &MetaOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "meta",
+		Short: "edit dataset metadata in bulk",
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	set := &cobra.Command{
+		Use:   "set FIELD VALUE [FIELD VALUE ...]",
+		Short: "set metadata fields on one or more datasets",
+		Long: `'qri meta set' updates fields of the meta component on each selected
+dataset, creating one new version per dataset that changes.
+
+Fields use dot notation to address nested values. Arrays, objects, booleans
+and null are parsed as JSON, all other values are treated as strings. Setting
+a field to null removes it.
+
+Datasets are selected with one or more --dataset flags, or with
+--all-matching, which selects every dataset in your collection with a name
+that contains the given term.
+
+Use --lang to set a translation of the title, description or readme instead
+of the fields themselves. Translations are stored alongside the meta
+component, and are shown to users who ask for that language with
+` + "`qri get --lang`" + ` or an Accept-Language header.`,
+		Example: `  # Set the license on two datasets:
+  $ qri meta set license.type CC0-1.0 --dataset me/annual_pop --dataset me/cities
+
+  # Replace keywords on all datasets with "nyc" in the name:
+  $ qri meta set keywords '["nyc","open data"]' --all-matching nyc
+
+  # Remove the description field:
+  $ qri meta set description null --dataset me/annual_pop
+
+  # Add a spanish title & description:
+  $ qri meta set title "Población anual" description "Población por año" --lang es --dataset me/annual_pop`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args)%2 != 0 {
+				return fmt.Errorf("wrong number of arguments. arguments must be in the form: [field value]")
+			} else if len(args) < 2 {
+				return fmt.Errorf("please provide at least one field-value pair to set")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Set()
+		},
+	}
+
+	set.Flags().StringSliceVarP(&o.Refs, "dataset", "d", nil, "dataset to update, may be provided more than once")
+	set.Flags().StringVar(&o.AllMatching, "all-matching", "", "update all datasets with a name containing this term")
+	set.Flags().StringVarP(&o.Title, "title", "t", "", "title of commit message for each save")
+	set.Flags().StringVarP(&o.Message, "message", "m", "", "commit message for each save")
+	set.Flags().StringVar(&o.Lang, "lang", "", "language tag to set translated title, description & readme for")
+	cmd.AddCommand(set)
+
+	return cmd
+}
+
+// MetaOptions encapsulates state for the meta command
+type MetaOptions struct {
+	ioes.IOStreams
+
+	Refs        []string
+	AllMatching string
+	Title       string
+	Message     string
+	Lang        string
+	Patch       map[string]interface{}
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *MetaOptions) Complete(f Factory, args []string) (err error) {
+	if o.Patch, err = metaPatchFromArgs(args); err != nil {
+		return err
+	}
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Validate checks that all user input is valid
+func (o *MetaOptions) Validate() error {
+	if len(o.Refs) == 0 && o.AllMatching == "" {
+		return qrierr.New(lib.ErrBadArgs, "please provide datasets to update with --dataset or --all-matching\nsee `qri meta set --help` for more details")
+	}
+	if len(o.Refs) > 0 && o.AllMatching != "" {
+		return fmt.Errorf("cannot use both --dataset and --all-matching flags")
+	}
+	return nil
+}
+
+// Set executes the meta set command
+func (o *MetaOptions) Set() error {
+	ctx := context.TODO()
+	refs := o.Refs
+	if o.AllMatching != "" {
+		var err error
+		if refs, err = o.matchingRefs(ctx); err != nil {
+			return err
+		}
+		if len(refs) == 0 {
+			printInfo(o.Out, "no datasets match %q", o.AllMatching)
+			return nil
+		}
+	}
+
+	p := &lib.MetaPatchParams{
+		Refs:    refs,
+		Patch:   o.Patch,
+		Title:   o.Title,
+		Message: o.Message,
+		Lang:    o.Lang,
+	}
+	res, err := o.inst.Dataset().PatchMeta(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range res.Results {
+		switch {
+		case r.Error != "":
+			printErr(o.ErrOut, fmt.Errorf("%s: %s", r.Ref, r.Error))
+		case r.Unchanged:
+			printInfo(o.Out, "%s: no changes", r.Ref)
+		default:
+			printSuccess(o.Out, "%s: saved %s", r.Ref, r.Path)
+		}
+	}
+	printInfo(o.Out, "%d saved, %d unchanged, %d failed", res.Saved, res.Unchanged, res.Failed)
+	if res.Failed > 0 {
+		return fmt.Errorf("failed to update %d of %d datasets", res.Failed, len(res.Results))
+	}
+	return nil
+}
+
+func (o *MetaOptions) matchingRefs(ctx context.Context) ([]string, error) {
+	const pageSize = 100
+	refs := []string{}
+	for offset := 0; ; offset += pageSize {
+		infos, err := o.inst.Dataset().List(ctx, &lib.ListParams{
+			Term:   o.AllMatching,
+			Limit:  pageSize,
+			Offset: offset,
+		})
+		if err != nil && !errors.Is(err, lib.ErrListWarning) {
+			return nil, err
+		}
+		for _, vi := range infos {
+			refs = append(refs, vi.SimpleRef().Alias())
+		}
+		if len(infos) < pageSize {
+			return refs, nil
+		}
+	}
+}
+
+// metaPatchFromArgs converts a list of field-value pairs into a JSON merge
+// patch. dot-separated field names create nested objects. Values that look
+// like JSON arrays, objects, null or booleans are decoded, everything else is
+// a string
+func metaPatchFromArgs(args []string) (map[string]interface{}, error) {
+	patch := map[string]interface{}{}
+	for i := 0; i+1 < len(args); i += 2 {
+		field, raw := args[i], args[i+1]
+		var val interface{} = raw
+		if looksLikeJSON(raw) {
+			if err := json.Unmarshal([]byte(raw), &val); err != nil {
+				val = raw
+			}
+		}
+
+		path := strings.Split(field, ".")
+		obj := patch
+		for _, key := range path[:len(path)-1] {
+			next, ok := obj[key].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				obj[key] = next
+			}
+			obj = next
+		}
+		obj[path[len(path)-1]] = val
+	}
+	return patch, nil
+}
+
+// looksLikeJSON reports whether a meta value should be decoded as JSON. Only
+// values that can't be meant as plain text are decoded, so values like
+// "2020" stay strings
+func looksLikeJSON(raw string) bool {
+	raw = strings.TrimSpace(raw)
+	switch raw {
+	case "null", "true", "false":
+		return true
+	}
+	return strings.HasPrefix(raw, "[") || strings.HasPrefix(raw, "{")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+)
+
+func TestMetaPatchFromArgs(t *testing.T) {
+	got, err := metaPatchFromArgs([]string{
+		"title", "my dataset",
+		"license.type", "CC0-1.0",
+		"license.url", "https://creativecommons.org/publicdomain/zero/1.0/",
+		"keywords", `["a","b"]`,
+		"description", "null",
+		"version", "2020",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{
+		"title": "my dataset",
+		"license": map[string]interface{}{
+			"type": "CC0-1.0",
+			"url":  "https://creativecommons.org/publicdomain/zero/1.0/",
+		},
+		"keywords":    []interface{}{"a", "b"},
+		"description": nil,
+		"version":     "2020",
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMetaSet(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_meta_set", "qri_test_meta_set")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body testdata/movies/body_ten.csv me/movies")
+	run.MustExec(t, "qri save --body testdata/movies/body_two.json me/movies_two")
+	run.MustExec(t, "qri save --body testdata/movies/body_ten.csv me/other")
+
+	run.MustExec(t, "qri meta set license.type CC0-1.0 keywords [\"film\"] --all-matching movies")
+
+	loadDataset := func(ref string) *dataset.Dataset {
+		vi := run.LookupVersionInfo(t, ref)
+		if vi == nil {
+			t.Fatalf("%s: reference not found", ref)
+		}
+		return run.MustLoadDataset(t, vi.Path)
+	}
+
+	for _, ref := range []string{"me/movies", "me/movies_two"} {
+		ds := loadDataset(ref)
+		if ds.Meta == nil || ds.Meta.License == nil || ds.Meta.License.Type != "CC0-1.0" {
+			t.Errorf("%s: expected license to be set", ref)
+		}
+		if diff := cmp.Diff([]string{"film"}, ds.Meta.Keywords); diff != "" {
+			t.Errorf("%s: keywords mismatch (-want +got):\n%s", ref, diff)
+		}
+	}
+
+	ds := loadDataset("me/other")
+	if ds.Meta != nil && ds.Meta.License != nil {
+		t.Errorf("expected unmatched dataset to be unchanged")
+	}
+
+	if err := run.ExecCommand("qri meta set title foo"); err == nil {
+		t.Error("expected meta set without datasets to error")
+	}
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewOpenCommand creates a `qri open` command for viewing a dataset in the
+// browser or querying it in a SQL shell
+func NewOpenCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &OpenOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "open [DATASET]",
+		Short: "open a dataset preview in the browser, or in a SQL shell",
+		Long: `Open shows a rendered preview of a dataset in your default web browser. The
+preview is served by the local qri API, which must be running: start it with
+'qri connect' in another terminal.
+
+With '--sql', open starts an interactive SQL shell instead, with the dataset
+loaded as a table named after the dataset. Use '--as' to pick a different
+table name. Other datasets can be queried by reference as usual, see
+'qri sql --help' for details. End statements with a semicolon, type 'exit' or
+press Ctrl-D to leave the shell.`,
+		Example: `  # preview a dataset in the browser
+  $ qri open me/annual_pop
+
+  # query a dataset in a SQL shell
+  $ qri open me/annual_pop --sql
+  annual_pop> SELECT country, year_2020 FROM annual_pop LIMIT 10;
+
+  # use a shorter table name
+  $ qri open me/annual_pop --sql --as pop`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if o.SQL {
+				return o.Shell()
+			}
+			return o.Browse()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.SQL, "sql", false, "open an interactive SQL shell")
+	cmd.Flags().StringVar(&o.Table, "as", "", "table name for the dataset in the SQL shell")
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "table", "SQL shell output format [table|csv|json]")
+
+	return cmd
+}
+
+// OpenOptions encapsulates state for the open command
+type OpenOptions struct {
+	ioes.IOStreams
+
+	Refs   *RefSelect
+	SQL    bool
+	Table  string
+	Format string
+
+	inst *lib.Instance
+	http *lib.HTTPClient
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *OpenOptions) Complete(f Factory, args []string) (err error) {
+	if o.inst, err = f.Instance(); err != nil {
+		return err
+	}
+	if o.Refs, err = GetCurrentRefSelect(f, args, 1, nil); err != nil {
+		return err
+	}
+	if !o.SQL {
+		o.http = f.HTTPClient()
+	}
+	return nil
+}
+
+// openURL opens a URL in the default browser. Tests replace it to avoid
+// launching a browser
+var openURL = func(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// Browse opens the local API preview of the dataset in the default browser
+func (o *OpenOptions) Browse() error {
+	if o.http == nil {
+		return fmt.Errorf("the qri API isn't running. start it with 'qri connect', or use '--sql' to open a SQL shell")
+	}
+	ref, err := dsref.Parse(o.Refs.Ref())
+	if err != nil {
+		return err
+	}
+	url := previewURL(o.http, ref)
+	printInfo(o.Out, "opening %s", url)
+	return openURL(url)
+}
+
+// previewURL is the local API route that renders a dataset as HTML
+func previewURL(c *lib.HTTPClient, ref dsref.Ref) string {
+	url := fmt.Sprintf("%s://%s%s/%s/%s", c.Protocol, c.Address, lib.AERender, ref.Username, ref.Name)
+	if ref.Path != "" {
+		url += "/at" + ref.Path
+	}
+	return url
+}
+
+// Shell runs an interactive SQL shell with the dataset loaded as a table
+func (o *OpenOptions) Shell() error {
+	ctx := context.TODO()
+	ref, err := dsref.Parse(o.Refs.Ref())
+	if err != nil {
+		return err
+	}
+	table := o.Table
+	if table == "" {
+		table = ref.Name
+	}
+
+	printInfo(o.ErrOut, "%s is loaded as table %q. end statements with ';', type 'exit' to quit", o.Refs.Ref(), table)
+	return runSQLShell(o.In, o.ErrOut, table+"> ", func(query string) error {
+		res, err := o.inst.SQL().Exec(ctx, &lib.SQLQueryParams{
+			Query:  query,
+			Format: o.Format,
+			Tables: map[string]string{table: o.Refs.Ref()},
+		})
+		if err != nil {
+			// query errors don't end the shell
+			printErr(o.ErrOut, err)
+			return nil
+		}
+		fmt.Fprintln(o.Out, strings.TrimRight(string(res), "\n"))
+		return nil
+	})
+}
+
+// runSQLShell reads statements from r, calling run with each one. Statements
+// end with a semicolon & can span lines. The shell ends at EOF, or when the
+// user types "exit" or "quit"
+func runSQLShell(r io.Reader, w io.Writer, ps string, run func(query string) error) error {
+	scanner := bufio.NewScanner(r)
+	stmt := &strings.Builder{}
+	for {
+		if stmt.Len() == 0 {
+			fmt.Fprint(w, ps)
+		} else {
+			fmt.Fprint(w, strings.Repeat(" ", len(ps)-2)+"> ")
+		}
+		if !scanner.Scan() {
+			fmt.Fprintln(w)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if stmt.Len() == 0 {
+			switch strings.TrimSuffix(strings.ToLower(line), ";") {
+			case "":
+				continue
+			case "exit", "quit", `\q`:
+				return nil
+			}
+		}
+
+		stmt.WriteString(line)
+		if !strings.HasSuffix(line, ";") {
+			stmt.WriteString("\n")
+			continue
+		}
+		query := strings.TrimSuffix(strings.TrimSpace(stmt.String()), ";")
+		stmt.Reset()
+		if err := run(query); err != nil {
+			return err
+		}
+	}
+}
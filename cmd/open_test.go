@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/lib"
+)
+
+func TestRunSQLShell(t *testing.T) {
+	in := strings.NewReader(`
+SELECT * FROM pop;
+select name
+  from pop
+  limit 1;
+exit
+SELECT 'unreachable' FROM pop;
+`)
+	got := []string{}
+	err := runSQLShell(in, &bytes.Buffer{}, "pop> ", func(q string) error {
+		got = append(got, q)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{
+		"SELECT * FROM pop",
+		"select name\nfrom pop\nlimit 1",
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("statements mismatch (-want +got):\n%s", diff)
+	}
+
+	errStop := errors.New("stop")
+	err = runSQLShell(strings.NewReader("SELECT 1;\nSELECT 2;\n"), &bytes.Buffer{}, "> ", func(q string) error {
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected run error to end the shell, got: %v", err)
+	}
+}
+
+func TestPreviewURL(t *testing.T) {
+	c := &lib.HTTPClient{Protocol: "http", Address: "127.0.0.1:2503"}
+	cases := []struct {
+		ref    dsref.Ref
+		expect string
+	}{
+		{dsref.Ref{Username: "peer", Name: "movies"}, "http://127.0.0.1:2503/render/peer/movies"},
+		{dsref.Ref{Username: "peer", Name: "movies", Path: "/ipfs/QmFoo"}, "http://127.0.0.1:2503/render/peer/movies/at/ipfs/QmFoo"},
+	}
+	for i, tc := range cases {
+		if got := previewURL(c, tc.ref); got != tc.expect {
+			t.Errorf("case %d url mismatch. want: %q, got: %q", i, tc.expect, got)
+		}
+	}
+}
+
+func TestOpenSQLShell(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_open_sql", "qri_test_open_sql")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save me/one_ds --body testdata/movies/body_ten.csv")
+
+	stdin := "SELECT m.movie_title FROM movies as m LIMIT 1;\nexit\n"
+	if err := run.ExecCommandWithStdin(run.Context, "qri open me/one_ds --sql --as movies --format csv", stdin); err != nil {
+		t.Fatal(err)
+	}
+	if got := run.GetCommandOutput(); !strings.Contains(got, "Avatar") {
+		t.Errorf("expected query result in output, got: %q", got)
+	}
+}
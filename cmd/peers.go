@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"text/tabwriter"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/ghodss/yaml"
 	"github.com/qri-io/ioes"
 	apiutil "github.com/qri-io/qri/api/util"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/p2p"
 	"github.com/spf13/cobra"
 )
 
@@ -44,7 +48,8 @@ You must have ` + "`qri connect`" + ` running in another terminal.`,
 
   # Show info in json:
   $ qri peers info b5 --format json`,
-		Args: cobra.ExactArgs(1),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePeer(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -64,7 +69,14 @@ You must have ` + "`qri connect`" + ` running in another terminal.`,
 You must have ` + "`qri connect`" + ` running in another terminal.
 
 To find peers that are not online, but to which your node has previously been 
-connected, use the ` + "`--cached`" + ` flag.`,
+connected, use the ` + "`--cached`" + ` flag.
+
+To find qri nodes on your local network without the registry, use the
+` + "`--local`" + ` flag. Local peers must be trusted before they can exchange
+logbook data with you. The p2p.localpeertrust config setting picks how:
+"prompt" asks whether to trust each new local peer when listing, "auto"
+trusts every local peer, and "manual" only trusts peers added with
+` + "`qri peers trust`" + `.`,
 		Example: `  # Spin up a Qri node:
   $ qri connect
 
@@ -72,7 +84,10 @@ connected, use the ` + "`--cached`" + ` flag.`,
   $ qri peers list
 
   # To ensure you get a cached version of the list:
-  $ qri peers list --cached`,
+  $ qri peers list --cached
+
+  # List qri nodes on your local network:
+  $ qri peers list --local`,
 		Aliases: []string{"ls"},
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -84,6 +99,7 @@ connected, use the ` + "`--cached`" + ` flag.`,
 	}
 
 	list.Flags().BoolVarP(&o.Cached, "cached", "c", false, "show peers that aren't online, but previously seen")
+	list.Flags().BoolVar(&o.Local, "local", false, "show peers discovered on the local network")
 	list.Flags().StringVarP(&o.Network, "network", "n", "", "specify network to show peers from (qri|ipfs) (defaults to qri)")
 	list.Flags().StringVarP(&o.Format, "format", "", "", "output format. formats: simple")
 	// TODO (ramfox): when we determine the best way to order and paginate peers, restore!
@@ -106,7 +122,8 @@ the most sure-fire way to connect to a peer.`,
 
   # In a separate terminal, connect to a specific peer:
   $ qri peers connect /ip4/192.168.0.194/tcp/4001/ipfs/QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn`,
-		Args: cobra.ExactArgs(1),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePeer(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -134,6 +151,7 @@ connection.
 You must have ` + "`qri connect`" + ` running in another terminal.`,
 		Example: `  # Disconnect from a peer using a multiaddr:
   $ qri peers disconnect /ip4/192.168.0.194/tcp/4001/ipfs/QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn`,
+		ValidArgsFunction: completePeer(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -142,7 +160,117 @@ You must have ` + "`qri connect`" + ` running in another terminal.`,
 		},
 	}
 
-	cmd.AddCommand(info, list, connect, disconnect)
+	top := &cobra.Command{
+		Use:   "top",
+		Short: "list connected peers by connection score",
+		Long: `Top lists peers your node is connected to, ordered by connection score.
+
+Qri limits the number of open peer connections. When the number of connections
+passes the configured high watermark (p2p.connmgrhighwater), qri closes
+connections to the lowest-scoring peers until it reaches the low watermark
+(p2p.connmgrlowwater). Peers score higher by speaking the qri protocol,
+sharing datasets, answering requests, and having low latency. Connections to
+configured remotes are protected, and never closed.
+
+You must have ` + "`qri connect`" + ` running in another terminal.`,
+		Example: `  # Show the ten highest scoring peers:
+  $ qri peers top --limit 10`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Top()
+		},
+	}
+
+	top.Flags().IntVar(&o.Limit, "limit", 25, "max number of peers to show, 0 shows all")
+	top.Flags().StringVarP(&o.Format, "format", "", "", "output format. formats: json")
+
+	bandwidth := &cobra.Command{
+		Use:   "bandwidth",
+		Short: "show data transferred by peer & protocol",
+		Long: `Bandwidth shows the data your node has transferred since it went online, in
+total, for each peer, and for each protocol. Rates are bytes per second,
+averaged over recent transfers.
+
+Set p2p.bandwidthpeercap to limit the bytes per second transferred with each
+peer, and p2p.bandwidthprotocolcaps to limit the bytes per second of a
+protocol across all peers. Caps apply when serving & fetching dataset data.
+
+You must have ` + "`qri connect`" + ` running in another terminal.`,
+		Example: `  # Show bandwidth used by the ten busiest peers:
+  $ qri peers bandwidth --limit 10
+
+  # Limit each peer to 1MB per second:
+  $ qri config set p2p.bandwidthpeercap 1000000`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Bandwidth()
+		},
+	}
+
+	bandwidth.Flags().IntVar(&o.Limit, "limit", 25, "max number of peers to show, 0 shows all")
+	bandwidth.Flags().StringVarP(&o.Format, "format", "", "", "output format. formats: json")
+
+	sync := &cobra.Command{
+		Use:   "sync PEER [DATASET...]",
+		Short: "exchange dataset history with a peer",
+		Long: `Sync connects to a peer and exchanges logbook data with them, pulling their
+history of each dataset and pushing yours. Without dataset arguments, you and
+the peer swap lists of followed datasets and sync all of them.
+
+Sync reports each dataset whose latest version changed. Use ` + "`--fetch`" + ` to also
+pull the data of new versions. Sync runs the same exchange qri performs
+with peers in the background, on demand and with a single peer.
+
+You must have ` + "`qri connect`" + ` running in another terminal.`,
+		Example: `  # Sync every dataset you or peer b5 follows:
+  $ qri peers sync b5
+
+  # Sync a single dataset, pulling the data of any new version:
+  $ qri peers sync b5 b5/world_bank_population --fetch`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completePeer(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Sync(args[1:])
+		},
+	}
+
+	sync.Flags().BoolVar(&o.Fetch, "fetch", false, "pull data for new versions")
+	sync.Flags().StringVarP(&o.Format, "format", "", "", "output format. formats: json")
+
+	trust := &cobra.Command{
+		Use:   "trust PEER_ID",
+		Short: "trust a local peer to exchange logbook data",
+		Long: `Trust allows a peer discovered on your local network to exchange logbook
+data with you. Until a local peer is trusted, syncing with it fails and it
+isn't told which datasets you follow. Trusted peers are saved to config.
+
+Peers that weren't discovered on the local network don't need to be trusted.`,
+		Example: `  # Trust a peer listed by qri peers list --local:
+  $ qri peers trust QmPeerID
+
+  # Stop trusting a peer:
+  $ qri peers trust QmPeerID --remove`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Trust()
+		},
+	}
+
+	trust.Flags().BoolVar(&o.Untrust, "remove", false, "stop trusting the peer")
+
+	cmd.AddCommand(info, list, connect, disconnect, top, bandwidth, sync, trust)
 
 	return cmd
 }
@@ -158,6 +286,10 @@ type PeersOptions struct {
 	Network  string
 	PageSize int
 	Page     int
+	Limit    int
+	Fetch    bool
+	Local    bool
+	Untrust  bool
 
 	UsingRPC bool
 	Instance *lib.Instance
@@ -211,6 +343,10 @@ func (o *PeersOptions) Info() (err error) {
 
 // List shows a list of peers
 func (o *PeersOptions) List() (err error) {
+	if o.Local {
+		return o.ListLocal()
+	}
+
 	// convert Page and PageSize to Limit and Offset
 	page := apiutil.NewPage(o.Page, o.PageSize)
 
@@ -282,3 +418,181 @@ func (o *PeersOptions) Disconnect() (err error) {
 	printSuccess(o.Out, "disconnected")
 	return nil
 }
+
+// Top lists connected peers by connection score
+func (o *PeersOptions) Top() error {
+	ctx := context.TODO()
+	res, err := o.Instance.Peer().Top(ctx, &lib.PeerTopParams{Limit: o.Limit})
+	if err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+		return nil
+	}
+
+	if len(res) == 0 {
+		printInfo(o.Out, "no connected peers")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCORE\tPEER\tQRI\tDATASETS\tRESPONSES\tLATENCY\tPROTECTED")
+	for _, s := range res {
+		fmt.Fprintf(w, "%d\t%s\t%t\t%d\t%d/%d\t%s\t%t\n", s.Score, s.PeerID, s.QriPeer, s.DatasetsShared, s.Responses, s.Responses+s.Failures, s.Latency.Round(time.Millisecond), s.Protected)
+	}
+	return w.Flush()
+}
+
+// Bandwidth prints data transferred by peer & protocol
+func (o *PeersOptions) Bandwidth() error {
+	ctx := context.TODO()
+	res, err := o.Instance.Peer().Bandwidth(ctx, &lib.PeerBandwidthParams{Limit: o.Limit})
+	if err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tIN\tOUT\tRATE IN\tRATE OUT\tCAP")
+	row := func(name string, s p2p.BandwidthStats) {
+		limit := "none"
+		if s.Cap > 0 {
+			limit = humanize.Bytes(uint64(s.Cap)) + "/s"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/s\t%s/s\t%s\n", name, humanize.Bytes(uint64(s.TotalIn)), humanize.Bytes(uint64(s.TotalOut)), humanize.Bytes(uint64(s.RateIn)), humanize.Bytes(uint64(s.RateOut)), limit)
+	}
+	row("total", res.Totals)
+	if len(res.Protocols) > 0 {
+		fmt.Fprintln(w, "\nPROTOCOL")
+		for _, p := range res.Protocols {
+			row(p.Protocol, p.BandwidthStats)
+		}
+	}
+	if len(res.Peers) > 0 {
+		fmt.Fprintln(w, "\nPEER")
+		for _, p := range res.Peers {
+			row(p.PeerID, p.BandwidthStats)
+		}
+	}
+	return w.Flush()
+}
+
+// Sync exchanges logbook data with a peer, printing changed datasets
+func (o *PeersOptions) Sync(refs []string) error {
+	ctx := context.TODO()
+	res, err := o.Instance.Peer().Sync(ctx, &lib.PeerSyncParams{
+		Peer:  o.Peername,
+		Refs:  refs,
+		Fetch: o.Fetch,
+	})
+	if err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+		return nil
+	}
+
+	changed := 0
+	for _, d := range res.Datasets {
+		switch {
+		case d.Error != "":
+			printWarning(o.ErrOut, "%s: %s", d.Ref, d.Error)
+		case d.Changed():
+			changed++
+			msg := fmt.Sprintf("%s: %s -> %s", d.Ref, shortPath(d.Prev), shortPath(d.Head))
+			if d.Fetched {
+				msg += " (fetched)"
+			}
+			printInfo(o.Out, "%s", msg)
+		}
+	}
+	printSuccess(o.Out, "synced %d datasets with %s, %d changed", len(res.Datasets), res.Peer, changed)
+	return nil
+}
+
+// ListLocal shows peers discovered on the local network, asking to trust new
+// peers if config says to prompt
+func (o *PeersOptions) ListLocal() error {
+	ctx := context.TODO()
+	peers, err := o.Instance.Peer().Local(ctx, &lib.PeerLocalParams{})
+	if err != nil {
+		return err
+	}
+	if len(peers) == 0 {
+		printInfo(o.Out, "no peers found on the local network")
+		return nil
+	}
+
+	prompt := true
+	if cfg := o.Instance.GetConfig(); cfg != nil && cfg.P2P != nil && cfg.P2P.LocalPeerTrust != "" {
+		prompt = cfg.P2P.LocalPeerTrust == config.LocalPeerTrustPrompt
+	}
+	for i, lp := range peers {
+		if lp.Trusted || !prompt || lp.Profile == nil {
+			continue
+		}
+		msg := fmt.Sprintf("trust %s (%s) to exchange logbook data?", lp.Profile.Peername, lp.ID)
+		if confirm(o.ErrOut, o.In, msg, false) {
+			if err := o.Instance.Peer().Trust(ctx, &lib.PeerTrustParams{Peer: lp.ID}); err != nil {
+				return err
+			}
+			peers[i].Trusted = true
+		}
+	}
+
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "PEER ID\tNAME\tTRUSTED\tFOUND\n")
+	for _, lp := range peers {
+		name := "-"
+		if lp.Profile != nil {
+			name = lp.Profile.Peername
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", lp.ID, name, lp.Trusted, humanize.Time(lp.FoundAt))
+	}
+	return w.Flush()
+}
+
+// Trust trusts or stops trusting a local peer
+func (o *PeersOptions) Trust() error {
+	ctx := context.TODO()
+	if err := o.Instance.Peer().Trust(ctx, &lib.PeerTrustParams{Peer: o.Peername, Untrust: o.Untrust}); err != nil {
+		return err
+	}
+	if o.Untrust {
+		printSuccess(o.Out, "stopped trusting %s", o.Peername)
+	} else {
+		printSuccess(o.Out, "trusted %s to exchange logbook data", o.Peername)
+	}
+	return nil
+}
+
+// shortPath abbreviates a version path for display
+func shortPath(p string) string {
+	if p == "" {
+		return "none"
+	}
+	if len(p) > 12 {
+		return "..." + p[len(p)-8:]
+	}
+	return p
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/pinning"
+	"github.com/spf13/cobra"
+)
+
+// NewPinCommand creates a `qri pin` command for pinning dataset versions to
+// remote pinning services & reporting pin status
+func NewPinCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &PinOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "pin",
+		Short: "pin dataset versions to remote pinning services",
+		Long: `Pinning services keep copies of dataset versions on the IPFS network, so
+published versions stay available while your node is offline. Services
+implementing the IPFS pinning service API, like Pinata or web3.storage, are
+configured in the integrations.pinningservices config field:
+
+  integrations:
+    pinningservices:
+      - name: pinata
+        endpoint: https://api.pinata.cloud/psa
+        token: YOUR_ACCESS_TOKEN
+        datasets: [me/annual_pop]
+
+Leave datasets empty to pin every published dataset. Publishing a version with
+'qri push' or 'qri publish' pins it to every service enabled for the dataset.
+Services fetch version blocks in the background, 'qri pin status' lists the
+state of each pin request.`,
+		Example: `  # list pin statuses for all datasets
+  $ qri pin status
+
+  # ask services for the latest state of a dataset's pins
+  $ qri pin status me/annual_pop --refresh
+
+  # retry pinning the latest version of a dataset
+  $ qri pin add me/annual_pop`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+	}
+
+	status := &cobra.Command{
+		Use:   "status [DATASET]",
+		Short: "list the state of pinned versions",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			ref := ""
+			if len(args) > 0 {
+				ref = args[0]
+			}
+			return o.Status(ref)
+		},
+	}
+	status.Flags().BoolVar(&o.Refresh, "refresh", false, "ask services for the current state of unfinished pins")
+
+	add := &cobra.Command{
+		Use:   "add DATASET",
+		Short: "pin a dataset version",
+		Long: `'qri pin add' asks every pinning service enabled for a dataset to pin a
+version, retrying failed pins. Versions must be published or otherwise
+reachable on the IPFS network for services to fetch them.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Add(args[0])
+		},
+	}
+
+	cmd.AddCommand(status)
+	cmd.AddCommand(add)
+	return cmd
+}
+
+// PinOptions encapsulates state for the pin command
+type PinOptions struct {
+	ioes.IOStreams
+
+	Refresh bool
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *PinOptions) Complete(f Factory) (err error) {
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Status prints pin statuses
+func (o *PinOptions) Status(ref string) error {
+	statuses, err := o.inst.Dataset().PinStatus(context.TODO(), &lib.PinStatusParams{Ref: ref, Refresh: o.Refresh})
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		printInfo(o.Out, "no pinned versions")
+		return nil
+	}
+	return o.printStatuses(statuses)
+}
+
+// Add pins a dataset version
+func (o *PinOptions) Add(ref string) error {
+	statuses, err := o.inst.Dataset().Pin(context.TODO(), &lib.PinParams{Ref: ref})
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		printInfo(o.Out, "no pinning services are enabled for %s", ref)
+		return nil
+	}
+	return o.printStatuses(statuses)
+}
+
+func (o *PinOptions) printStatuses(statuses []pinning.Status) error {
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "DATASET\tPATH\tSERVICE\tSTATE\tUPDATED\n")
+	for _, st := range statuses {
+		state := st.State
+		if st.Error != "" {
+			state = fmt.Sprintf("%s: %s", st.State, st.Error)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", st.Dataset, st.Path, st.Service, state, st.Time.Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
@@ -10,6 +10,8 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -211,6 +213,29 @@ func printDiff(w io.Writer, res *lib.DiffResponse, summaryOnly bool) (err error)
 	return nil
 }
 
+func printDiffStat(w io.Writer, sum *DiffStatSummary) {
+	if !sum.Changed() {
+		fmt.Fprintln(w, "no changes")
+		return
+	}
+
+	names := make([]string, 0, len(sum.Components))
+	for name := range sum.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	data := make([][]string, 0, len(names))
+	for _, name := range names {
+		data = append(data, []string{name, strconv.Itoa(sum.Components[name])})
+	}
+	renderTable(w, []string{"component", "changes"}, data)
+
+	if sum.Rows != nil {
+		fmt.Fprintf(w, "\nrows: %d added, %d removed, %d modified\n", sum.Rows.Added, sum.Rows.Removed, sum.Rows.Modified)
+	}
+}
+
 func printRefSelect(w io.Writer, refset *RefSelect) {
 	if refset.IsExplicit() {
 		return
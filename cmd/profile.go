@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/qri-io/ioes"
+	qerr "github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewProfileCommand creates a `qri profile` subcommand for exporting &
+// deactivating the active user profile
+func NewProfileCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &ProfileOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "export or deactivate your profile",
+		Long: `Profile commands manage the data qri keeps on your behalf. To edit profile
+details use ` + "`qri config set profile.[field] [value]`" + `.`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+	}
+
+	export := &cobra.Command{
+		Use:   "export",
+		Short: "write an archive of your profile, datasets & logbook",
+		Long: `Export writes a zip archive of everything your repo holds on your behalf:
+your profile, the latest version of each of your datasets, your logbook, and
+a list of the sessions you've signed in with. The archive doesn't contain
+your private key or any access tokens.
+
+Datasets that aren't stored locally are skipped.`,
+		Example: `  # export your profile to [username]_qri_export.zip
+  $ qri profile export
+
+  # export to a specific file
+  $ qri profile export --output ~/backups/qri.zip`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Export()
+		},
+	}
+	export.Flags().StringVarP(&o.Output, "output", "o", "", "path to write the archive to")
+
+	deactivate := &cobra.Command{
+		Use:   "deactivate",
+		Short: "unpublish your datasets & deactivate your registry profile",
+		Long: `Deactivate removes your datasets from the registry and replaces your registry
+profile with a tombstone. The tombstone keeps your username reserved so no one
+else can claim it, but drops all other profile details.
+
+Deactivation doesn't delete anything from your local repo. Run
+` + "`qri profile export`" + ` first to keep a copy of your data in a single archive.
+
+If a dataset can't be unpublished your profile stays active, and you can run
+deactivate again.`,
+		Example: `  # deactivate the profile of user "b5"
+  $ qri profile deactivate --confirm b5`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Deactivate()
+		},
+	}
+	deactivate.Flags().StringVar(&o.Confirm, "confirm", "", "your username, confirming deactivation")
+	deactivate.Flags().StringVar(&o.Remote, "remote", "", "remote to unpublish datasets from, defaults to the registry")
+
+	cmd.AddCommand(export, deactivate)
+	return cmd
+}
+
+// ProfileOptions encapsulates state for the profile command & subcommands
+type ProfileOptions struct {
+	ioes.IOStreams
+
+	Output  string
+	Confirm string
+	Remote  string
+
+	ProfileMethods *lib.ProfileMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ProfileOptions) Complete(f Factory) (err error) {
+	o.ProfileMethods, err = f.ProfileMethods()
+	return
+}
+
+// Export writes a profile archive
+func (o *ProfileOptions) Export() error {
+	ctx := context.TODO()
+	res, err := o.ProfileMethods.Export(ctx, &lib.ProfileExportParams{Path: o.Output})
+	if err != nil {
+		return err
+	}
+	for ref, reason := range res.Skipped {
+		printWarning(o.ErrOut, "skipped %s: %s", ref, reason)
+	}
+	printSuccess(o.Out, "exported %d datasets to %s", len(res.Datasets), res.Path)
+	return nil
+}
+
+// Deactivate unpublishes datasets & deactivates the registry profile
+func (o *ProfileOptions) Deactivate() error {
+	if o.Confirm == "" {
+		return qerr.New(lib.ErrBadArgs, "confirm deactivation with --confirm [your username]")
+	}
+
+	ctx := context.TODO()
+	res, err := o.ProfileMethods.Deactivate(ctx, &lib.ProfileDeactivateParams{
+		Confirm: o.Confirm,
+		Remote:  o.Remote,
+	})
+	if res != nil {
+		for _, ref := range res.Unpublished {
+			printInfo(o.Out, "unpublished %s", ref)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, "deactivated profile %s", o.Confirm)
+	return nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewPromoteCommand creates a `qri promote` subcommand
+func NewPromoteCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &PromoteOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "promote DATASET NEW_NAME [flags]",
+		Short: "rename a dataset & push it to a remote",
+		Long: `Promote moves a dataset from a scratch name to its public name, then pushes
+it to a remote. If the push fails the rename is rolled back, so the dataset is
+never left renamed but unpublished.
+
+If no remote is specified, qri pushes to the registry.`,
+		Example: `  # promote a scratch dataset to its public name on the registry:
+  $ qri promote me/scratch_pop me/annual_population
+
+  # promote to a named remote:
+  $ qri promote me/scratch_pop me/annual_population --remote staging`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.RemoteName, "remote", "", "", "name of remote to push to")
+
+	return cmd
+}
+
+// PromoteOptions encapsulates state for the promote command
+type PromoteOptions struct {
+	ioes.IOStreams
+
+	Current    string
+	Next       string
+	RemoteName string
+
+	RemoteMethods *lib.RemoteMethods
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *PromoteOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) == 2 {
+		o.Current = args[0]
+		o.Next = args[1]
+	}
+	o.RemoteMethods, err = f.RemoteMethods()
+	return
+}
+
+// Validate checks that all user input is valid
+func (o *PromoteOptions) Validate() error {
+	if o.Current == "" || o.Next == "" {
+		return errors.New(lib.ErrBadArgs, "please provide the current & new names of the dataset, for example:\n    $ qri promote me/scratch_name me/public_name\nsee `qri promote --help` for more details")
+	}
+	return nil
+}
+
+// Run executes the promote command
+func (o *PromoteOptions) Run() error {
+	p := &lib.PromoteParams{
+		Ref:    o.Current,
+		Next:   o.Next,
+		Remote: o.RemoteName,
+	}
+
+	res, err := o.RemoteMethods.Promote(context.TODO(), p)
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, "promoted %s to %s", o.Current, res.Alias())
+	return nil
+}
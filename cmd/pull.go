@@ -15,19 +15,36 @@ func NewPullCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 	o := &PullOptions{IOStreams: ioStreams}
 	cmd := &cobra.Command{
 		Use:     "pull DATASET [DATASET...]",
-		Aliases: []string{"add"},
+		Aliases: []string{"add", "fetch"},
 		Short:   "fetch & store datasets from other peers",
 		Long: `Pull downloads datasets and stores them locally, fetching the dataset log and
 dataset version(s). By default pull fetches the latest version of a dataset.
+
+With --meta-only pull fetches the dataset log & every component of the
+version except the body. The body is fetched from the same remote the first
+time the version is loaded.
+
+With --depth pull fetches data for the latest N versions, and the complete
+dataset log. Datasets left without data for older versions are marked
+shallow. Use --deepen to fetch data for more versions of a shallow dataset
+later. Pulling with '--depth 0' fetches data for every version.
 `,
 		Example: `  # download a dataset log and latest version
   $ qri pull b5/world_bank_population
 
   # pull a specific version from a remote by hash
-  $ qri pull ramfox b5/world_bank_population@/ipfs/QmFoo...`,
+  $ qri pull ramfox b5/world_bank_population@/ipfs/QmFoo...
+
+  # mirror history & metadata, leaving body data on the remote
+  $ qri pull --meta-only b5/world_bank_population
+
+  # fetch data for the latest 5 versions, then 10 more versions later
+  $ qri fetch --depth 5 b5/world_bank_population
+  $ qri fetch --deepen 10 b5/world_bank_population`,
 		Annotations: map[string]string{
 			"group": "network",
 		},
+		ValidArgsFunction: completeRefs(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f); err != nil {
 				return err
@@ -40,6 +57,9 @@ dataset version(s). By default pull fetches the latest version of a dataset.
 	cmd.Flags().StringVar(&o.Remote, "remote", "", "location to pull from")
 	cmd.MarkFlagFilename("link")
 	cmd.Flags().BoolVar(&o.LogsOnly, "logs-only", false, "only fetch logs, skipping HEAD data")
+	cmd.Flags().BoolVar(&o.MetaOnly, "meta-only", false, "fetch logs & HEAD components, skipping body data")
+	cmd.Flags().IntVar(&o.Depth, "depth", 1, "number of versions to fetch data for, 0 fetches all versions")
+	cmd.Flags().IntVar(&o.Deepen, "deepen", 0, "fetch data for this many more versions of a shallow dataset")
 
 	return cmd
 }
@@ -50,6 +70,9 @@ type PullOptions struct {
 	LinkDir  string
 	Remote   string
 	LogsOnly bool
+	MetaOnly bool
+	Depth    int
+	Deepen   int
 
 	inst *lib.Instance
 }
@@ -77,8 +100,16 @@ func (o *PullOptions) Run(args []string) error {
 			Ref:      arg,
 			LinkDir:  o.LinkDir,
 			LogsOnly: o.LogsOnly,
+			MetaOnly: o.MetaOnly,
 			Remote:   o.Remote,
 		}
+		if o.Deepen > 0 {
+			p.Deepen = o.Deepen
+		} else if o.Depth == 0 {
+			p.Depth = -1
+		} else if o.Depth != 1 {
+			p.Depth = o.Depth
+		}
 
 		res, err := o.inst.WithSource("network").Dataset().Pull(ctx, p)
 		if err != nil {
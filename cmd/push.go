@@ -2,9 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/remote"
 	"github.com/spf13/cobra"
 )
 
@@ -19,16 +24,58 @@ func NewPushCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 remote and sends one version of dataset data to the remote. To push multiple
 dataset versions, run push multiple times, specifying the version hash to push.
 
-If no remote is specified, qri pushes to the registry.`,
+If no remote is specified, qri pushes to the dataset's default remote, set with
+'qri config dataset DATASET remote NAME', falling back to the registry.
+
+If the remote can't be reached the push is queued. Queued pushes are retried
+in the background while qri is connected, and whenever qri comes back online.
+Use --status to list pushes that are waiting to be sent.
+
+Remotes reachable over HTTP return a receipt signed with the remote's key for
+each push & unpublish, which is stored in the dataset log. Use --verify to
+check the receipts recorded for a dataset.
+
+Remotes may process pushed datasets in the background, for example to update
+a search index or notify a webhook. Use --hooks to check the status of that
+processing for a version you pushed.
+
+Use --meta-only to send the dataset log & every component except the body.
+Metadata-only pushes suit mirrors that only keep history & metadata. Remotes
+must be reachable over HTTP for metadata-only pushes.
+
+Use --check-remote to make sure the remote has no versions you haven't pulled
+before pushing. Pushing a dataset that's behind its remote diverges the two
+histories. Use --force to push anyway.
+
+Use --all-remotes to push to the registry & every configured remote at once.
+Remotes are pushed to in parallel. A failed push to one remote doesn't stop
+pushes to the others, push reports the outcome for each remote and fails if
+any remote didn't accept the push.`,
 		Example: `  # push a dataset to the registry
   $ qri push me/dataset
 
   # push a specific version of a dataset to the registry:
-  $ qri push me/dataset@/ipfs/QmHashOfVersion`,
+  $ qri push me/dataset@/ipfs/QmHashOfVersion
+
+  # list pushes waiting for a remote to become reachable:
+  $ qri push --status
+
+  # verify receipts remotes issued for pushes of a dataset:
+  $ qri push --verify me/dataset
+
+  # check processing the registry ran after a push:
+  $ qri push --hooks me/dataset
+
+  # push only if the remote has no versions missing locally:
+  $ qri push --check-remote me/dataset
+
+  # push to the registry & every configured remote:
+  $ qri push --all-remotes me/dataset`,
 		Annotations: map[string]string{
 			"group": "network",
 		},
-		Args: cobra.MaximumNArgs(1),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeRefs(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -39,6 +86,13 @@ If no remote is specified, qri pushes to the registry.`,
 
 	cmd.Flags().BoolVarP(&o.Logs, "logs", "", false, "send only dataset history")
 	cmd.Flags().StringVarP(&o.RemoteName, "remote", "", "", "name of remote to push to")
+	cmd.Flags().BoolVarP(&o.Status, "status", "", false, "list queued pushes")
+	cmd.Flags().BoolVarP(&o.Verify, "verify", "", false, "verify receipts for pushes & unpublishes of a dataset")
+	cmd.Flags().BoolVarP(&o.Hooks, "hooks", "", false, "show the status of processing a remote ran after a push")
+	cmd.Flags().BoolVarP(&o.MetaOnly, "meta-only", "", false, "send history & components, skipping body data")
+	cmd.Flags().BoolVarP(&o.CheckRemote, "check-remote", "", false, "fail if the remote has versions that aren't saved locally")
+	cmd.Flags().BoolVarP(&o.Force, "force", "", false, "push even if the remote has versions that aren't saved locally")
+	cmd.Flags().BoolVarP(&o.AllRemotes, "all-remotes", "", false, "push to the registry & every configured remote")
 
 	return cmd
 }
@@ -50,12 +104,24 @@ type PushOptions struct {
 	Refs       *RefSelect
 	Logs       bool
 	RemoteName string
+	Status     bool
+	Verify     bool
+	Hooks      bool
+	MetaOnly   bool
+	AllRemotes bool
+
+	CheckRemote bool
+	Force       bool
 
 	RemoteMethods *lib.RemoteMethods
 }
 
 // Complete adds any missing configuration that can only be added just before calling Run
 func (o *PushOptions) Complete(f Factory, args []string) (err error) {
+	if o.Status {
+		o.RemoteMethods, err = f.RemoteMethods()
+		return
+	}
 	if o.Refs, err = GetCurrentRefSelect(f, args, 1, nil); err != nil {
 		return
 	}
@@ -66,14 +132,34 @@ func (o *PushOptions) Complete(f Factory, args []string) (err error) {
 // Run executes the push command
 func (o *PushOptions) Run() error {
 	ctx := context.TODO()
+	if o.Status {
+		return o.printQueue(ctx)
+	}
+	if o.Verify {
+		return o.verifyReceipts(ctx)
+	}
+	if o.Hooks {
+		return o.printHooks(ctx)
+	}
+	if o.AllRemotes {
+		return o.publish(ctx)
+	}
+
 	for _, ref := range o.Refs.RefList() {
 		p := lib.PushParams{
-			Ref:    ref,
-			Remote: o.RemoteName,
+			Ref:      ref,
+			Remote:   o.RemoteName,
+			MetaOnly: o.MetaOnly,
+
+			CheckRemote: o.CheckRemote,
+			Force:       o.Force,
 		}
 
 		res, err := o.RemoteMethods.Push(ctx, &p)
-		if err != nil {
+		if isPushQueued(err) {
+			printWarning(o.ErrOut, "%s\npush of %s queued, it will be retried when the remote is reachable", err, ref)
+			continue
+		} else if err != nil {
 			return err
 		}
 		printInfo(o.Out, "pushed dataset %s", res)
@@ -81,3 +167,135 @@ func (o *PushOptions) Run() error {
 
 	return nil
 }
+
+func (o *PushOptions) publish(ctx context.Context) error {
+	if o.RemoteName != "" {
+		return fmt.Errorf("--remote and --all-remotes can't be used together")
+	}
+
+	failed := 0
+	for _, ref := range o.Refs.RefList() {
+		p := lib.PublishParams{
+			Ref:      ref,
+			MetaOnly: o.MetaOnly,
+
+			CheckRemote: o.CheckRemote,
+			Force:       o.Force,
+		}
+		report, err := o.RemoteMethods.Publish(ctx, &p)
+		if err != nil {
+			return err
+		}
+
+		for _, res := range report.Results {
+			switch {
+			case res.Queued:
+				printWarning(o.ErrOut, "%s -> %s queued, it will be retried when the remote is reachable: %s", report.Ref.Human(), res.Remote, res.Error)
+			case res.Error != "":
+				printWarning(o.ErrOut, "%s -> %s failed: %s", report.Ref.Human(), res.Remote, res.Error)
+			default:
+				printSuccess(o.Out, "%s -> %s", report.Ref.Human(), res.Remote)
+			}
+		}
+		if err := report.Err(); err != nil {
+			failed++
+			printWarning(o.ErrOut, "%s", err)
+			continue
+		}
+		printInfo(o.Out, "pushed dataset %s to %d remotes", report.Ref.Human(), len(report.Results))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w for %d dataset(s)", remote.ErrPartialPush, failed)
+	}
+	return nil
+}
+
+func (o *PushOptions) verifyReceipts(ctx context.Context) error {
+	ref := o.Refs.Ref()
+	checks, err := o.RemoteMethods.VerifyReceipts(ctx, &lib.VerifyReceiptsParams{Ref: ref})
+	if err != nil {
+		return err
+	}
+	if len(checks) == 0 {
+		printInfo(o.Out, "no receipts recorded for %s", ref)
+		return nil
+	}
+
+	invalid := 0
+	for _, c := range checks {
+		if c.Receipt == nil {
+			invalid++
+			printWarning(o.Out, "invalid receipt: %s", c.Error)
+			continue
+		}
+		rcpt := c.Receipt
+		if !c.Valid {
+			invalid++
+			printWarning(o.Out, "invalid %s receipt from %s: %s", rcpt.Action, rcpt.RemoteID, c.Error)
+			continue
+		}
+		printSuccess(o.Out, "%s %s accepted by %s at %s", rcpt.Action, rcpt.Head, rcpt.RemoteID, rcpt.Timestamp.Format(time.RFC3339))
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d receipts failed verification", invalid, len(checks))
+	}
+	return nil
+}
+
+func (o *PushOptions) printHooks(ctx context.Context) error {
+	ref := o.Refs.Ref()
+	statuses, err := o.RemoteMethods.HookStatus(ctx, &lib.HookStatusParams{Ref: ref, Remote: o.RemoteName})
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		printInfo(o.Out, "no hooks ran for %s", ref)
+		return nil
+	}
+
+	failed := 0
+	for _, st := range statuses {
+		switch st.Status {
+		case remote.HookStatusSucceeded:
+			printSuccess(o.Out, "%s\t%s\t%s", st.Status, st.Hook, st.Finished.Sub(st.Started))
+		case remote.HookStatusFailed:
+			failed++
+			printWarning(o.Out, "%s\t%s\t%s", st.Status, st.Hook, st.Error)
+		default:
+			printInfo(o.Out, "%s\t%s\tstarted %s", st.Status, st.Hook, st.Started.Format(time.RFC3339))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d hooks failed", failed, len(statuses))
+	}
+	return nil
+}
+
+func (o *PushOptions) printQueue(ctx context.Context) error {
+	queued, err := o.RemoteMethods.PushQueue(ctx, &lib.PushQueueParams{})
+	if err != nil {
+		return err
+	}
+	if len(queued) == 0 {
+		printInfo(o.Out, "no queued pushes")
+		return nil
+	}
+	for _, qp := range queued {
+		remoteName := qp.Remote
+		if remoteName == "" {
+			remoteName = "registry"
+		}
+		printInfo(o.Out, "%s -> %s\n  queued %s, %d attempts, next attempt %s", qp.Ref.Alias(), remoteName, qp.Queued.Format(time.RFC3339), qp.Attempts, qp.NextAttempt.Format(time.RFC3339))
+		if qp.LastError != "" {
+			printInfo(o.Out, "  last error: %s", qp.LastError)
+		}
+	}
+	return nil
+}
+
+// isPushQueued checks for a push that was queued. Errors returned over HTTP
+// lose their type, so the error message is checked as well
+func isPushQueued(err error) bool {
+	return err != nil && (errors.Is(err, remote.ErrPushQueued) || strings.Contains(err.Error(), remote.ErrPushQueued.Error()))
+}
@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/registry"
 	"github.com/qri-io/qri/registry/regserver"
+	"github.com/qri-io/qri/remote"
 	repotest "github.com/qri-io/qri/repo/test"
 )
 
@@ -57,4 +61,20 @@ func TestPush(t *testing.T) {
 	if results[0].Name != "one_ds" {
 		t.Errorf("expected: dataset named \"one_ds\", got %q", results[0].Name)
 	}
+
+	// push to the registry & a remote that can't be reached
+	run.RepoRoot.GetConfig().Remotes = &config.Remotes{"offline": "http://127.0.0.1:1"}
+	if err := run.RepoRoot.WriteConfigFile(); err != nil {
+		t.Fatal(err)
+	}
+	err = run.ExecCommand("qri push --all-remotes me/one_ds")
+	if !errors.Is(err, remote.ErrPartialPush) {
+		t.Fatalf("expected partial push error, got: %v", err)
+	}
+	if out := run.GetCommandOutput(); !strings.Contains(out, "test_peer_registry_push/one_ds -> registry") {
+		t.Errorf("expected successful push to the registry, got:\n%s", out)
+	}
+	if errOut := run.ErrStream.String(); !strings.Contains(errOut, "-> offline queued") {
+		t.Errorf("expected push to the offline remote to be queued, got:\n%s", errOut)
+	}
 }
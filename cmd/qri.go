@@ -30,8 +30,8 @@ func NewQriCommand(ctx context.Context, repoPath string, generator key.CryptoGen
 Feedback, questions, bug reports, and contributions are welcome! 
 https://github.com/qri-io/qri/issues`,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			opt.repoFlagSet = cmd.Flags().Changed("repo")
 		},
-		BashCompletionFunction: bashCompletionFunc,
 	}
 
 	cmd.SetUsageTemplate(rootUsageTemplate)
@@ -40,39 +40,61 @@ https://github.com/qri-io/qri/issues`,
 	cmd.PersistentFlags().BoolVarP(&opt.NoColor, "no-color", "", false, "disable colorized output")
 	cmd.PersistentFlags().StringVar(&opt.repoPath, "repo", repoPath, "filepath to load qri data from")
 	cmd.PersistentFlags().BoolVarP(&opt.LogAll, "log-all", "", false, "log all activity")
+	cmd.PersistentFlags().StringVar(&opt.Context, "context", os.Getenv("QRI_CONTEXT"), "name of the context to use, overriding the current context. defaults to $QRI_CONTEXT")
+	cmd.PersistentFlags().StringVar(&opt.ConfigProfile, "config-profile", os.Getenv(config.EnvConfigProfile), "name of a configuration profile to merge over the repo config. defaults to $"+config.EnvConfigProfile)
 
 	cmd.AddCommand(
 		NewAccessCommand(opt, ioStreams),
 		NewApplyCommand(opt, ioStreams),
 		NewAutocompleteCommand(opt, ioStreams),
 		NewCheckoutCommand(opt, ioStreams),
+		NewCloneCommand(opt, ioStreams),
 		NewConfigCommand(opt, ioStreams),
 		NewConnectCommand(opt, ioStreams),
+		NewCopyCommand(opt, ioStreams),
+		NewContextCommand(opt, ioStreams),
 		NewDAGCommand(opt, ioStreams),
 		NewDiffCommand(opt, ioStreams),
+		NewDoctorCommand(opt, ioStreams),
+		NewDscacheCommand(opt, ioStreams),
 		NewFSICommand(opt, ioStreams),
+		NewFreshnessCommand(opt, ioStreams),
 		NewGetCommand(opt, ioStreams),
 		NewInitCommand(opt, ioStreams),
+		NewLifecycleCommand(opt, ioStreams),
+		NewLintCommand(opt, ioStreams),
 		NewListCommand(opt, ioStreams),
 		NewLogCommand(opt, ioStreams),
 		NewLogbookCommand(opt, ioStreams),
+		NewLoginCommand(opt, ioStreams),
+		NewLogoutCommand(opt, ioStreams),
+		NewMetaCommand(opt, ioStreams),
+		NewOpenCommand(opt, ioStreams),
+		NewPinCommand(opt, ioStreams),
 		NewPushCommand(opt, ioStreams),
 		NewPullCommand(opt, ioStreams),
 		NewPeersCommand(opt, ioStreams),
 		NewPreviewCommand(opt, ioStreams),
+		NewProfileCommand(opt, ioStreams),
+		NewPromoteCommand(opt, ioStreams),
 		NewRegistryCommand(opt, ioStreams),
 		NewRemoveCommand(opt, ioStreams),
 		NewRenameCommand(opt, ioStreams),
 		NewRenderCommand(opt, ioStreams),
+		NewRepairRefsCommand(opt, ioStreams),
+		NewReproduceCommand(opt, ioStreams),
 		NewRestoreCommand(opt, ioStreams),
 		NewSaveCommand(opt, ioStreams),
 		NewSearchCommand(opt, ioStreams),
 		NewSetupCommand(opt, ioStreams),
+		NewSquashCommand(opt, ioStreams),
 		NewStatsCommand(opt, ioStreams),
 		NewStatusCommand(opt, ioStreams),
 		NewSQLCommand(opt, ioStreams),
+		NewTrashCommand(opt, ioStreams),
 		NewUseCommand(opt, ioStreams),
 		NewValidateCommand(opt, ioStreams),
+		NewVerifyRepoCommand(opt, ioStreams),
 		NewVersionCommand(opt, ioStreams),
 		NewWhatChangedCommand(opt, ioStreams),
 	)
@@ -96,6 +118,16 @@ type QriOptions struct {
 
 	// path to the qri data directory
 	repoPath string
+	// repoFlagSet is true when the repo path was given with the --repo flag
+	repoFlagSet bool
+	// name of the context to use, overriding the current context
+	Context string
+	// path to the contexts file
+	contextsPath string
+	// context the repo path & defaults were chosen from, if any
+	repoContext     *lib.RepoContext
+	contextResolved bool
+	contextErr      error
 	// generator is source of generating cryptographic info
 	generator key.CryptoGenerator
 	// automatically run migrations if necessary
@@ -108,6 +140,8 @@ type QriOptions struct {
 	ConfigPath string
 	// Whether to log all activity by enabling logging for all packages
 	LogAll bool
+	// name of a configuration profile to merge over the repo config
+	ConfigProfile string
 	// inst is the Instance that holds state needed by qri's methods
 	inst *lib.Instance
 }
@@ -115,14 +149,50 @@ type QriOptions struct {
 // NewQriOptions creates an options object
 func NewQriOptions(ctx context.Context, repoPath string, generator key.CryptoGenerator, ioStreams ioes.IOStreams) *QriOptions {
 	return &QriOptions{
-		IOStreams: ioStreams,
-		ctx:       ctx,
-		doneCh:    make(chan struct{}),
-		repoPath:  repoPath,
-		generator: generator,
+		IOStreams:    ioStreams,
+		ctx:          ctx,
+		doneCh:       make(chan struct{}),
+		repoPath:     repoPath,
+		contextsPath: StandardContextsPath(),
+		generator:    generator,
 	}
 }
 
+// resolveContext picks the context to use & switches to its repo. A context
+// named with --context is always used. The current context is used only when
+// the repo path hasn't been set with --repo or $QRI_PATH
+func (o *QriOptions) resolveContext() error {
+	if !o.contextResolved {
+		o.contextResolved = true
+		o.contextErr = o.loadContext()
+	}
+	return o.contextErr
+}
+
+func (o *QriOptions) loadContext() error {
+	name := o.Context
+	if name == "" && (o.repoFlagSet || os.Getenv("QRI_PATH") != "" || o.repoPath != StandardRepoPath()) {
+		return nil
+	}
+
+	rcs, err := lib.LoadRepoContexts(o.contextsPath)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		if o.repoContext, err = rcs.CurrentContext(); err != nil {
+			return fmt.Errorf("current %w", err)
+		}
+	} else if o.repoContext, err = rcs.Get(name); err != nil {
+		return err
+	}
+
+	if o.repoContext != nil {
+		o.repoPath = o.repoContext.RepoPath
+	}
+	return nil
+}
+
 // Init will initialize the internal state before any command is run (excluding `qri setup`)
 func (o *QriOptions) Init() (err error) {
 	if o.inst != nil {
@@ -130,6 +200,10 @@ func (o *QriOptions) Init() (err error) {
 	}
 	setNoPrompt(o.NoPrompt)
 
+	if err = o.resolveContext(); err != nil {
+		return err
+	}
+
 	repoErr := lib.QriRepoExists(o.repoPath)
 	if repoErr != nil {
 		return errors.New("no qri repo exists\nhave you run 'qri setup'?")
@@ -139,12 +213,17 @@ func (o *QriOptions) Init() (err error) {
 		lib.OptIOStreams(o.IOStreams), // transfer iostreams to instance
 		lib.OptCheckConfigMigrations(o.migrationApproval, (!o.Migrate && !o.NoPrompt)),
 		lib.OptSetLogAll(o.LogAll),
+		lib.OptConfigProfile(o.ConfigProfile),
 		lib.OptRemoteOptions([]remote.OptionsFunc{
 			// look for a remote policy
 			remote.OptLoadPolicyFileIfExists(filepath.Join(o.repoPath, access.DefaultAccessControlPolicyFilename)),
 		}),
 	}
 
+	if o.repoContext != nil {
+		opts = append(opts, lib.OptRepoContext(o.repoContext))
+	}
+
 	o.inst, err = lib.NewInstance(o.ctx, o.repoPath, opts...)
 	if err != nil {
 		return
@@ -167,10 +246,8 @@ func (o *QriOptions) Init() (err error) {
 	// being false also disables progress bars, which may be what we want (ahem: TTY
 	// detection), but even if so, isn't the right use of this variable name
 	if shouldColorOutput {
-		// TODO(ramfox): we guard for a nil bus in `PrintProgressBarsOnEvents`
-		// but noting here that no requests that go through http rpc will have
-		// a working bus, so we won't get any progress bars when working over
-		// http rpc until this is adjusted (once we get the events "rpc-ified")
+		// requests that go through http rpc get events from the connected
+		// instance's event bridge
 		PrintProgressBarsOnEvents(o.IOStreams.ErrOut, o.inst.Bus())
 	}
 
@@ -203,6 +280,9 @@ func (o *QriOptions) Instance() (*lib.Instance, error) {
 
 // RepoPath returns the path to the qri data directory
 func (o *QriOptions) RepoPath() string {
+	if err := o.resolveContext(); err != nil {
+		log.Debugf("resolving context: %s", err)
+	}
 	return o.repoPath
 }
 
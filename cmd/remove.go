@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/dsref"
 	qerr "github.com/qri-io/qri/errors"
@@ -17,7 +19,7 @@ import (
 func NewRemoveCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 	o := &RemoveOptions{IOStreams: ioStreams}
 	cmd := &cobra.Command{
-		Use:     "remove [DATASET]",
+		Use:     "remove [DATASET...]",
 		Aliases: []string{"rm", "delete"},
 		Short:   "remove a dataset from your local repository",
 		Long: `Remove deletes datasets from qri.
@@ -38,7 +40,17 @@ reporting the results of attempting to remove on the destination remote.
 The remote flag can only be used to completely remove a dataset from a remote.
 To edit history on a remote, run delete locally and use 'qri push' to send the
 updated history to the remote. Any command run with the remote flag has no
-effect on local data.`,
+effect on local data.
+
+Removing an entire dataset moves it to the trash, where stored versions are
+kept for a grace period & the dataset can be brought back with
+'qri trash restore'. Pass '--purge' to remove stored versions right away.
+
+Passing more than one dataset, or a pattern like 'me/tmp_*', removes many
+datasets at once. Bulk removes always remove entire datasets & require the
+'--all' flag. Qri lists the datasets, versions, and storage that will be
+removed and asks for confirmation once before removing anything. Use
+'--dry-run' to see the list without removing.`,
 		Example: `  # delete a dataset cloned from another user
   $ qri remove user/world_bank_population
 
@@ -51,13 +63,18 @@ effect on local data.`,
   # destroy a dataset named 'annual_pop'
   $ qri remove --all me/annual_pop
 
+  # bring it back from the trash
+  $ qri trash restore me/annual_pop
+
   # ask the registry to delete a dataset
-  $ qri remove --remote registry me/annual_pop`,
+  $ qri remove --remote registry me/annual_pop
+
+  # see what removing all datasets starting with 'tmp_' would do
+  $ qri remove --all --dry-run 'me/tmp_*'`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
-		// Use *max* so we can print a nicer message for no or malformed args
-		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeRefs(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -74,6 +91,9 @@ effect on local data.`,
 	cmd.Flags().BoolVar(&o.KeepFiles, "keep-files", false, "don't modify files in working directory")
 	cmd.Flags().BoolVarP(&o.Force, "force", "f", false, "remove files even if a working directory is dirty")
 	cmd.Flags().StringVar(&o.Remote, "remote", "", "remote address to remove from")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "list datasets a bulk remove would delete without removing them")
+	cmd.Flags().BoolVarP(&o.Yes, "yes", "y", false, "skip confirmation of bulk removes")
+	cmd.Flags().BoolVar(&o.Purge, "purge", false, "remove stored versions right away instead of moving the dataset to the trash")
 
 	return cmd
 }
@@ -83,6 +103,8 @@ type RemoveOptions struct {
 	ioes.IOStreams
 
 	Refs *RefSelect
+	// Patterns holds arguments for bulk removes
+	Patterns []string
 
 	Remote        string
 	RevisionsText string
@@ -90,6 +112,9 @@ type RemoveOptions struct {
 	All           bool
 	KeepFiles     bool
 	Force         bool
+	DryRun        bool
+	Yes           bool
+	Purge         bool
 
 	RemoteMethods *lib.RemoteMethods
 	inst          *lib.Instance
@@ -104,6 +129,14 @@ func (o *RemoveOptions) Complete(f Factory, args []string) (err error) {
 	if o.RemoteMethods, err = f.RemoteMethods(); err != nil {
 		return
 	}
+	if isBulkRemove(args) {
+		if !o.All {
+			return fmt.Errorf("removing many datasets requires --all")
+		}
+		o.Patterns = args
+		o.Revision = dsref.NewAllRevisions()
+		return nil
+	}
 	if o.Refs, err = GetCurrentRefSelect(f, args, 1, nil); err != nil {
 		// This error will be handled during validation
 		if err != repo.ErrEmptyRef {
@@ -134,6 +167,12 @@ func (o *RemoveOptions) Complete(f Factory, args []string) (err error) {
 
 // Validate checks that all user input is valid
 func (o *RemoveOptions) Validate() error {
+	if len(o.Patterns) > 0 {
+		if o.Remote != "" {
+			return fmt.Errorf("can't remove many datasets from a remote")
+		}
+		return nil
+	}
 	if o.Refs.Ref() == "" {
 		return qerr.New(lib.ErrBadArgs, "please specify a dataset path or name you would like to remove from your qri node")
 	}
@@ -142,6 +181,9 @@ func (o *RemoveOptions) Validate() error {
 
 // Run executes the remove command
 func (o *RemoveOptions) Run() (err error) {
+	if len(o.Patterns) > 0 {
+		return o.RemoveMany()
+	}
 	printRefSelect(o.ErrOut, o.Refs)
 
 	if o.Remote != "" {
@@ -153,6 +195,7 @@ func (o *RemoveOptions) Run() (err error) {
 		Revision:  o.Revision,
 		KeepFiles: o.KeepFiles,
 		Force:     o.Force,
+		Purge:     o.Purge,
 	}
 
 	ctx := context.TODO()
@@ -170,7 +213,9 @@ func (o *RemoveOptions) Run() (err error) {
 		return err
 	}
 
-	if res.NumDeleted == dsref.AllGenerations {
+	if res.NumDeleted == dsref.AllGenerations && res.Trashed {
+		printSuccess(o.Out, "moved dataset '%s' to the trash", res.Ref)
+	} else if res.NumDeleted == dsref.AllGenerations {
 		printSuccess(o.Out, "removed entire dataset '%s'", res.Ref)
 	} else if res.NumDeleted != 0 {
 		printSuccess(o.Out, "removed %d revisions of dataset '%s'", res.NumDeleted, res.Ref)
@@ -199,3 +244,53 @@ func (o *RemoveOptions) RemoveRemote() error {
 	printSuccess(o.Out, "removed dataset %s from remote %s", res, o.Remote)
 	return nil
 }
+
+// RemoveMany removes every dataset matching a list of references & patterns,
+// confirming once before removing
+func (o *RemoveOptions) RemoveMany() error {
+	ctx := context.TODO()
+	p := &lib.RemoveManyParams{
+		Refs:      o.Patterns,
+		DryRun:    true,
+		KeepFiles: o.KeepFiles,
+		Force:     o.Force,
+		Purge:     o.Purge,
+	}
+	report, err := o.inst.Dataset().RemoveMany(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range report.Datasets {
+		printInfo(o.Out, "%s\t%d versions\t%s", item.Ref, item.Versions, humanize.Bytes(item.PinnedBytes))
+	}
+	printInfo(o.Out, "%d datasets, %d versions, up to %s", len(report.Datasets), report.Versions, humanize.Bytes(report.PinnedBytes))
+	if o.DryRun {
+		return nil
+	}
+
+	if !o.Yes && !confirm(o.ErrOut, o.In, fmt.Sprintf("remove %d datasets?", len(report.Datasets)), false) {
+		return fmt.Errorf("datasets not removed")
+	}
+
+	p.DryRun = false
+	if report, err = o.inst.Dataset().RemoveMany(ctx, p); err != nil {
+		return err
+	}
+	printSuccess(o.Out, "removed %d datasets", len(report.Datasets))
+	return nil
+}
+
+// isBulkRemove returns true when remove arguments name more than one dataset
+// or include a pattern
+func isBulkRemove(args []string) bool {
+	if len(args) > 1 {
+		return true
+	}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "*?[") {
+			return true
+		}
+	}
+	return false
+}
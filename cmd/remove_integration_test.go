@@ -82,6 +82,39 @@ func TestRemoveAllRevisionsFromRepo(t *testing.T) {
 	}
 }
 
+// Test removing a dataset moves it to the trash, where it can be restored
+func TestRemoveThenRestoreFromTrash(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_remove_restore_trash", "qri_test_remove_restore_trash")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body=testdata/movies/body_two.json me/remove_test")
+	run.MustExec(t, "qri save --body=testdata/movies/body_four.json me/remove_test")
+	dsPath := run.GetPathForDataset(t, 0)
+
+	output := run.MustExec(t, "qri remove --all me/remove_test")
+	if !strings.Contains(output, "to the trash") {
+		t.Errorf("expected remove to report moving the dataset to the trash, got: %q", output)
+	}
+	if got := run.GetPathForDataset(t, 0); got != "" {
+		t.Fatalf("after remove, dataset should not exist, got: %s", got)
+	}
+	output = run.MustExec(t, "qri trash list")
+	if !strings.Contains(output, "test_peer_remove_restore_trash/remove_test") || !strings.Contains(output, dsPath) {
+		t.Errorf("expected trash to list the removed dataset, got: %q", output)
+	}
+
+	run.MustExec(t, "qri trash restore me/remove_test")
+	if got := run.GetPathForDataset(t, 0); got != dsPath {
+		t.Errorf("after restore, dataset path mismatch. want: %s got: %s", dsPath, got)
+	}
+
+	run.MustExec(t, "qri remove --all me/remove_test")
+	run.MustExec(t, "qri trash empty --yes")
+	if err := run.ExecCommand("qri trash restore me/remove_test"); err == nil {
+		t.Error("expected restoring a purged dataset to fail")
+	}
+}
+
 // Test that remove from a repo can't be used with --keep-files flag
 func TestRemoveRepoCantUseKeepFiles(t *testing.T) {
 	run := NewTestRunner(t, "test_peer_remove_repo_cant_use_keep_files", "qri_test_remove_repo_cant_use_keep_files")
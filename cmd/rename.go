@@ -28,6 +28,7 @@ renames to a minimum.`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
+		ValidArgsFunction: completeRef(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -83,5 +84,15 @@ func (o *RenameOptions) Run() error {
 	}
 
 	printSuccess(o.Out, "renamed dataset to %s", res.Name)
+	for _, u := range res.Updated {
+		if u.Path != "" {
+			printInfo(o.Out, "updated %s reference in %s: %s", u.Kind, u.Ref, u.Path)
+		} else {
+			printInfo(o.Out, "updated %s reference in %s", u.Kind, u.Ref)
+		}
+	}
+	for _, u := range res.Stale {
+		printWarning(o.ErrOut, "the transform of %s loads the previous name, save a new version to update it", u.Ref)
+	}
 	return nil
 }
@@ -36,6 +36,7 @@ provided, Qri will render the dataset with a default template.`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
+		ValidArgsFunction: completeRef(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewRepairRefsCommand creates a new `qri repair-refs` command that moves
+// broken dataset references to intact versions
+func NewRepairRefsCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &RepairRefsOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "repair-refs",
+		Short: "point datasets with missing data at their latest intact version",
+		Long: `Repair-refs finds datasets whose latest version is missing data or can't be
+read. This happens when the IPFS repo qri stores data in is garbage collected
+outside of qri, or moved.
+
+Each broken dataset is pointed at the latest version in its history that is
+still fully stored. History isn't changed, so missing versions can be fetched
+again later with ` + "`qri verify-repo --repair`" + `. Datasets with no intact
+version are reported as irrecoverable.
+
+Repairs also run automatically after garbage collection requested through
+the admin API.`,
+		Example: `  # show which datasets would be repaired:
+  $ qri repair-refs --dry-run
+
+  # repair broken datasets:
+  $ qri repair-refs`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "report broken datasets without changing them")
+	cmd.Flags().BoolVar(&o.JSON, "json", false, "print the report as JSON")
+
+	return cmd
+}
+
+// RepairRefsOptions encapsulates state for the repair-refs command
+type RepairRefsOptions struct {
+	ioes.IOStreams
+
+	DryRun bool
+	JSON   bool
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *RepairRefsOptions) Complete(f Factory, args []string) (err error) {
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Run repairs references & prints the report
+func (o *RepairRefsOptions) Run() error {
+	ctx := context.TODO()
+	report, err := o.inst.Admin().RepairRefs(ctx, &lib.RepairRefsParams{DryRun: o.DryRun})
+	if err != nil {
+		return err
+	}
+
+	if o.JSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		printInfo(o.Out, "%s", data)
+		return nil
+	}
+
+	for _, r := range report.Repairs {
+		if r.Status == base.RefRepaired {
+			verb := "repaired"
+			if o.DryRun {
+				verb = "would repair"
+			}
+			printSuccess(o.Out, "%s %s: %s -> %s", verb, r.Ref.Alias(), r.PrevPath, r.Path)
+			continue
+		}
+		msg := fmt.Sprintf("irrecoverable %s: no intact version stored locally", r.Ref.Alias())
+		if r.Error != "" {
+			msg += ". " + r.Error
+		}
+		printWarning(o.Out, "%s", msg)
+	}
+	printInfo(o.Out, "%d datasets checked: %d repaired, %d irrecoverable", report.Checked, report.Repaired, report.Irrecoverable)
+	return nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewReproduceCommand creates a `qri reproduce` command that re-runs the
+// transform of a dataset version
+func NewReproduceCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &ReproduceOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "reproduce DATASET",
+		Short: "re-run the transform of a dataset version",
+		Long: `Reproduce re-runs the transform of a dataset version without saving, and
+prints the result. If the environment the version was saved with was recorded,
+reproduce warns about any difference from the current environment: the qri
+version, transform runtime, platform, and the versions of datasets the
+transform loaded. Record environments by saving with --record-env.`,
+		Example: `  # Re-run the transform of the latest version:
+  $ qri reproduce me/tf_dataset
+
+  # Re-run the transform of a specific version:
+  $ qri reproduce me/tf_dataset@/ipfs/QmVersion`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&o.Secrets, "secrets", nil, "transform secrets as comma separated key,value,key,value,... sequence")
+
+	return cmd
+}
+
+// ReproduceOptions encapsulates state for the reproduce command
+type ReproduceOptions struct {
+	ioes.IOStreams
+
+	Ref     string
+	Secrets []string
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ReproduceOptions) Complete(f Factory, args []string) (err error) {
+	o.Ref = args[0]
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Run re-runs the transform, warning about environment mismatches
+func (o *ReproduceOptions) Run() (err error) {
+	ctx := context.TODO()
+	p := &lib.ReproduceParams{
+		Ref:          o.Ref,
+		ScriptOutput: o.ErrOut,
+	}
+	if len(o.Secrets) > 0 {
+		if p.Secrets, err = parseSecrets(o.Secrets...); err != nil {
+			return err
+		}
+	}
+
+	res, err := o.inst.Transform().Reproduce(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if res.Recorded == nil {
+		printWarning(o.ErrOut, "no environment was recorded for %s, results may differ", res.Ref)
+	}
+	for _, m := range res.Mismatches {
+		printWarning(o.ErrOut, "environment mismatch. %s", m)
+	}
+
+	data, err := json.MarshalIndent(res.Data, "", " ")
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, string(data))
+	return nil
+}
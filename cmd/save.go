@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/deepdiff"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/lib"
@@ -39,19 +43,57 @@ commit message and title to the save.
 When you make an update and save a dataset that you originally added from a 
 different peer, the dataset gets renamed from ` + "`peers_name/dataset_name`" +
 			` to
-` + "`my_name/dataset_name`" + `.`,
+` + "`my_name/dataset_name`" + `.
+
+If you share a dataset with collaborators through a remote, use --check-remote
+to make sure nobody has pushed a version you haven't pulled. The save fails if
+the dataset's remote has versions that aren't saved locally, pull them first
+or save anyway with --allow-behind-remote.
+
+Automated pipelines can use --require-compatible to reject versions that
+remove columns, or change a column's type so it no longer accepts values it
+used to. Adding columns & widening types are allowed. Pass --breaking-change
+to save an incompatible schema on purpose. Either way the verdict is recorded
+in the commit message.
+
+Use --record-env to record the qri version, transform runtime, platform and
+the versions of datasets a transform loaded in the commit, or set
+repo.recordenvironment in config to record them on every save. View them with
+` + "`qri get environment`" + `, and ` + "`qri reproduce`" + ` warns when the
+current environment doesn't match.
+
+Use --extract-meta to fill empty meta fields & column descriptions from a
+frictionless datapackage.json in the same directory as the body file, or from
+a second header row of column descriptions in a CSV body. The description row
+is removed from the body, and the extracted fields are listed in the commit
+message.
+
+Use --canonical to write components with a canonical JSON encoding: sorted
+keys, no extra whitespace & fixed number formatting. The same dataset always
+encodes to the same bytes, so component hashes & the commit signature that
+covers them can be recomputed by other tools.`,
 		Example: `  # Save updated data to dataset annual_pop:
   $ qri save --body /path/to/data.csv me/annual_pop
 
+  # Save data downloaded from a url, skipping the save if it hasn't changed:
+  $ qri save --body-url https://example.com/data.csv me/annual_pop
+
   # Save updated dataset (no data) to annual_pop:
   $ qri save --file /path/to/dataset.yaml me/annual_pop
   
   # Re-execute the latest transform from history:
-  $ qri save --apply me/tf_dataset`,
+  $ qri save --apply me/tf_dataset
+
+  # Preview the version a transform would create, without saving:
+  $ qri save --apply --dry-run me/tf_dataset
+
+  # Save only if the dataset's remote has no versions missing locally:
+  $ qri save --check-remote --body /path/to/data.csv me/annual_pop`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
-		Args: cobra.MaximumNArgs(1),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeRef(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -69,18 +111,27 @@ different peer, the dataset gets renamed from ` + "`peers_name/dataset_name`" +
 	cmd.Flags().StringVarP(&o.Message, "message", "m", "", "commit message for save")
 	cmd.Flags().StringVarP(&o.BodyPath, "body", "", "", "path to file or url of data to add as dataset contents")
 	cmd.MarkFlagFilename("body")
+	cmd.Flags().StringVar(&o.BodyURL, "body-url", "", "http(s) url to download dataset contents from, skipping the save if unchanged since the last download")
 	// cmd.Flags().BoolVarP(&o.ShowValidation, "show-validation", "s", false, "display a list of validation errors upon adding")
 	cmd.Flags().BoolVar(&o.Apply, "apply", false, "apply a transformation and save the result")
 	cmd.Flags().BoolVar(&o.NoApply, "no-apply", false, "don't apply any transforms that are added")
 	cmd.Flags().StringSliceVar(&o.Secrets, "secrets", nil, "transform secrets as comma separated key,value,key,value,... sequence")
-	cmd.Flags().BoolVar(&o.DeprecatedDryRun, "dry-run", false, "deprecated: use `qri apply` instead")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "with --apply, preview the version a save would create without saving")
 	cmd.Flags().BoolVar(&o.Force, "force", false, "force a new commit, even if no changes are detected")
+	cmd.Flags().BoolVar(&o.CheckRemote, "check-remote", false, "fail if the dataset's remote has versions that aren't saved locally")
+	cmd.Flags().BoolVar(&o.AllowBehindRemote, "allow-behind-remote", false, "with --check-remote, save even if the dataset is behind its remote")
+	cmd.Flags().BoolVar(&o.RequireCompatible, "require-compatible", false, "fail if the schema removes or retypes columns of the previous version")
+	cmd.Flags().BoolVar(&o.BreakingChange, "breaking-change", false, "with --require-compatible, allow an incompatible schema")
+	cmd.Flags().BoolVar(&o.RecordEnvironment, "record-env", false, "record the qri version, transform runtime, platform & resource versions in the commit")
+	cmd.Flags().BoolVar(&o.ExtractMeta, "extract-meta", false, "fill empty meta & column descriptions from a datapackage.json or csv description row")
+	cmd.Flags().BoolVar(&o.Canonical, "canonical", false, "write components with a deterministic json encoding")
 	cmd.Flags().BoolVarP(&o.KeepFormat, "keep-format", "k", false, "convert incoming data to stored data format")
 	// TODO(dustmop): --no-render is deprecated, viz are being phased out, in favor of readme.
 	cmd.Flags().BoolVar(&o.NoRender, "no-render", false, "don't store a rendered version of the the visualization")
 	cmd.Flags().BoolVarP(&o.NewName, "new", "n", false, "save a new dataset only, using an available name")
 	cmd.Flags().BoolVarP(&o.UseDscache, "use-dscache", "", false, "experimental: build and use dscache if none exists")
 	cmd.Flags().StringVar(&o.Drop, "drop", "", "comma-separated list of components to remove")
+	cmd.Flags().StringVar(&o.InferSampler, "infer-sampler", "", "sampling used to infer the schema of a new body. one of [prefix,full,reservoir,stratified]")
 
 	return cmd
 }
@@ -92,15 +143,16 @@ type SaveOptions struct {
 	Refs      *RefSelect
 	FilePaths []string
 	BodyPath  string
+	BodyURL   string
 	Drop      string
 
 	Title   string
 	Message string
 
-	Apply            bool
-	NoApply          bool
-	DeprecatedDryRun bool
-	Secrets          []string
+	Apply   bool
+	NoApply bool
+	DryRun  bool
+	Secrets []string
 
 	Replace        bool
 	ShowValidation bool
@@ -109,14 +161,24 @@ type SaveOptions struct {
 	NoRender       bool
 	NewName        bool
 	UseDscache     bool
+	InferSampler   string
+
+	CheckRemote       bool
+	AllowBehindRemote bool
+
+	RequireCompatible bool
+	BreakingChange    bool
+	RecordEnvironment bool
+	ExtractMeta       bool
+	Canonical         bool
 
 	inst *lib.Instance
 }
 
 // Complete adds any missing configuration that can only be added just before calling Run
 func (o *SaveOptions) Complete(f Factory, args []string) (err error) {
-	if o.DeprecatedDryRun {
-		return fmt.Errorf("--dry-run has been removed, use `qri apply` command instead")
+	if o.DryRun && !o.Apply {
+		return fmt.Errorf("--dry-run requires --apply, use `qri apply` to run a transform without a dataset")
 	}
 
 	if o.inst, err = f.Instance(); err != nil {
@@ -135,6 +197,9 @@ func (o *SaveOptions) Complete(f Factory, args []string) (err error) {
 
 // Validate checks that all user input is valid
 func (o *SaveOptions) Validate() error {
+	if o.BodyPath != "" && o.BodyURL != "" {
+		return fmt.Errorf("cannot use both --body and --body-url flags")
+	}
 	return nil
 }
 
@@ -145,6 +210,7 @@ func (o *SaveOptions) Run() (err error) {
 	p := &lib.SaveParams{
 		Ref:      o.Refs.Ref(),
 		BodyPath: o.BodyPath,
+		BodyURL:  o.BodyURL,
 		Title:    o.Title,
 		Message:  o.Message,
 
@@ -156,10 +222,20 @@ func (o *SaveOptions) Run() (err error) {
 
 		ConvertFormatToPrev: o.KeepFormat,
 		Force:               o.Force,
+		CheckRemote:         o.CheckRemote,
+		AllowBehindRemote:   o.AllowBehindRemote,
+
+		RequireCompatibleSchema: o.RequireCompatible,
+		BreakingChange:          o.BreakingChange,
+		RecordEnvironment:       o.RecordEnvironment,
+		ExtractMeta:             o.ExtractMeta,
+		Canonical:               o.Canonical,
 
 		ShouldRender: !o.NoRender,
 		NewName:      o.NewName,
 		UseDscache:   o.UseDscache,
+		InferSampler: o.InferSampler,
+		DryRun:       o.DryRun,
 	}
 
 	// Check if file ends in '.star'. If so, either Apply or NoApply is required.
@@ -196,6 +272,10 @@ continue?`, true) {
 		return err
 	}
 
+	if o.DryRun {
+		return o.printDryRun(ctx, res)
+	}
+
 	ref := dsref.ConvertDatasetToVersionInfo(res).SimpleRef()
 	ref.ProfileID = ""
 	printSuccess(o.ErrOut, "dataset saved: %s", ref.String())
@@ -205,3 +285,66 @@ continue?`, true) {
 
 	return nil
 }
+
+// printDryRun describes the version a dry run save would create, showing
+// changes to stats when there's a previous version to compare against
+func (o *SaveOptions) printDryRun(ctx context.Context, res *dataset.Dataset) error {
+	ref := dsref.ConvertDatasetToVersionInfo(res).SimpleRef()
+	ref.ProfileID = ""
+	printSuccess(o.ErrOut, "dry run, nothing saved: %s", ref.Alias())
+	if res.Commit != nil {
+		printInfo(o.Out, res.Commit.Title)
+		if res.Commit.Message != "" && res.Commit.Message != res.Commit.Title {
+			printInfo(o.Out, res.Commit.Message)
+		}
+	}
+
+	if res.PreviousPath == "" || res.Stats == nil {
+		return nil
+	}
+	prev, err := o.inst.Dataset().Stats(ctx, &lib.StatsParams{Refstr: ref.Alias()})
+	if err != nil {
+		printWarning(o.ErrOut, fmt.Sprintf("couldn't load previous stats: %s", err))
+		return nil
+	}
+	diff, err := statsDiff(ctx, prev, res.Stats)
+	if err != nil {
+		return err
+	}
+	if diff == nil {
+		printInfo(o.Out, "\nstats unchanged")
+		return nil
+	}
+	printInfo(o.Out, "\nstats changes:")
+	return printDiff(o.Out, diff, false)
+}
+
+// statsDiff compares two stats components, returning nil if they're equal
+func statsDiff(ctx context.Context, prev, next *dataset.Stats) (*lib.DiffResponse, error) {
+	a, err := toGeneric(prev.Stats)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toGeneric(next.Stats)
+	if err != nil {
+		return nil, err
+	}
+	if reflect.DeepEqual(a, b) {
+		return nil, nil
+	}
+	res := &lib.DiffResponse{}
+	res.Diff, res.Stat, err = deepdiff.New().StatDiff(ctx, a, b)
+	return res, err
+}
+
+// toGeneric round-trips a value through JSON, so it's made of plain maps,
+// slices, and scalars
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
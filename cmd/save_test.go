@@ -76,18 +76,22 @@ func TestSaveValidate(t *testing.T) {
 		ref      string
 		filepath string
 		bodypath string
+		bodyurl  string
 		err      string
 		msg      string
 	}{
-		{"me/test", "test/path.yaml", "", "", ""},
-		{"me/test", "", "test/bodypath.yaml", "", ""},
-		{"me/test", "test/filepath.yaml", "test/bodypath.yaml", "", ""},
+		{"me/test", "test/path.yaml", "", "", "", ""},
+		{"me/test", "", "test/bodypath.yaml", "", "", ""},
+		{"me/test", "test/filepath.yaml", "test/bodypath.yaml", "", "", ""},
+		{"me/test", "", "", "https://example.com/body.csv", "", ""},
+		{"me/test", "", "test/bodypath.yaml", "https://example.com/body.csv", "cannot use both --body and --body-url flags", ""},
 	}
 	for i, c := range cases {
 		opt := &SaveOptions{
 			Refs:      NewExplicitRefSelect(c.ref),
 			FilePaths: []string{c.filepath},
 			BodyPath:  c.bodypath,
+			BodyURL:   c.bodyurl,
 		}
 
 		err := opt.Validate()
@@ -1137,7 +1141,7 @@ func TestSaveTransformConflictWithMeta(t *testing.T) {
 	}
 }
 
-func TestDryRunIsAnError(t *testing.T) {
+func TestDryRunRequiresApply(t *testing.T) {
 	run := NewTestRunner(t, "test_peer_dry_run_err", "qri_test_dry_run_err")
 	defer run.Delete()
 
@@ -1145,12 +1149,29 @@ func TestDryRunIsAnError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expectd error trying to dry run, did not get an error")
 	}
-	expectErr := "--dry-run has been removed, use `qri apply` command instead"
+	expectErr := "--dry-run requires --apply, use `qri apply` to run a transform without a dataset"
 	if diff := cmp.Diff(expectErr, err.Error()); diff != "" {
 		t.Errorf("error mismatch (-want +got):%s\n", diff)
 	}
 }
 
+func TestSaveApplyDryRun(t *testing.T) {
+	run := NewTestRunner(t, "test_peer_save_dry_run", "qri_test_save_dry_run")
+	defer run.Delete()
+
+	run.MustExec(t, "qri save --body testdata/movies/body_ten.csv me/dry_run")
+	body := run.MustExec(t, "qri get body me/dry_run")
+
+	output := run.MustExec(t, "qri save --apply --dry-run --file testdata/movies/tf_one_movie.star me/dry_run")
+	if !strings.Contains(output, "stats changes:") {
+		t.Errorf("expected dry run output to show stats changes, got:\n%s", output)
+	}
+
+	if diff := cmp.Diff(body, run.MustExec(t, "qri get body me/dry_run")); diff != "" {
+		t.Errorf("expected dry run not to change the body (-want +got):\n%s", diff)
+	}
+}
+
 func TestSaveApply(t *testing.T) {
 	run := NewTestRunner(t, "test_peer_save_apply", "qri_test_save_apply")
 	defer run.Delete()
@@ -22,9 +22,20 @@ func NewSearchCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 		Short: "search the registry for datasets",
 		Long: `Search datasets & peers that match your query. Search pings the qri registry. 
 
-Any dataset that has been pushed to the registry is available for search.`,
+Any dataset that has been pushed to the registry is available for search.
+
+Use --body to search the values of local datasets instead. Body search only
+covers datasets marked as content indexed with --index, which adds the values
+of string columns in the dataset body to a local index that is updated each
+time the dataset is saved. Large bodies are only partially indexed.`,
 		Example: `  # Search for datasets featuring "annual population":
-  $ qri search "annual population"`,
+  $ qri search "annual population"
+
+  # Index the body of a local dataset:
+  $ qri search --index me/annual_pop
+
+  # Search indexed datasets for a body value:
+  $ qri search --body "new zealand"`,
 		Annotations: map[string]string{
 			"group": "network",
 		},
@@ -43,6 +54,9 @@ Any dataset that has been pushed to the registry is available for search.`,
 	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "set output format [json|simple]")
 	cmd.Flags().IntVar(&o.PageSize, "page-size", 25, "page size of results, default 25")
 	cmd.Flags().IntVar(&o.Page, "page", 1, "page number of results, default 1")
+	cmd.Flags().BoolVar(&o.Body, "body", false, "search values of content indexed local datasets")
+	cmd.Flags().StringVar(&o.Index, "index", "", "add the body of a local dataset to the search index")
+	cmd.Flags().StringVar(&o.Unindex, "unindex", "", "remove the body of a local dataset from the search index")
 
 	return cmd
 }
@@ -55,6 +69,9 @@ type SearchOptions struct {
 	Format   string
 	PageSize int
 	Page     int
+	Body     bool
+	Index    string
+	Unindex  string
 	// Reindex bool
 
 	Instance *lib.Instance
@@ -73,12 +90,43 @@ func (o *SearchOptions) Complete(f Factory, args []string) (err error) {
 
 // Validate checks that any user inputs are valid
 func (o *SearchOptions) Validate() error {
+	if o.Index != "" && o.Unindex != "" {
+		return errors.New(lib.ErrBadArgs, "cannot use --index and --unindex together")
+	}
+	if o.Index != "" || o.Unindex != "" {
+		return nil
+	}
 	if o.Query == "" {
 		return errors.New(lib.ErrBadArgs, "please provide search parameters, for example:\n    $ qri search census\n    $ qri search 'census 2018'\nsee `qri search --help` for more information")
 	}
 	return nil
 }
 
+func (o *SearchOptions) indexContent(ctx context.Context) error {
+	p := &lib.IndexContentParams{Ref: o.Index}
+	if o.Unindex != "" {
+		p = &lib.IndexContentParams{Ref: o.Unindex, Disable: true}
+	}
+	status, err := o.Instance.Search().IndexContent(ctx, p)
+	if err != nil {
+		return err
+	}
+	o.StopSpinner()
+	if !status.Enabled {
+		printSuccess(o.Out, "removed %s from the search index", status.Ref)
+		return nil
+	}
+	if status.Error != "" {
+		printWarning(o.ErrOut, "%s will be indexed on save, indexing the current version failed: %s", status.Ref, status.Error)
+		return nil
+	}
+	printSuccess(o.Out, "indexed %d terms from %s", status.Tokens, status.Ref)
+	if status.Truncated {
+		printWarning(o.ErrOut, "body is larger than the index size limit, only part of it is searchable")
+	}
+	return nil
+}
+
 // Run executes the search command
 func (o *SearchOptions) Run() (err error) {
 	ctx := context.TODO()
@@ -87,6 +135,10 @@ func (o *SearchOptions) Run() (err error) {
 	o.StartSpinner()
 	defer o.StopSpinner()
 
+	if o.Index != "" || o.Unindex != "" {
+		return o.indexContent(ctx)
+	}
+
 	// TODO: add reindex option back in
 
 	// convert Page and PageSize to Limit and Offset
@@ -96,6 +148,7 @@ func (o *SearchOptions) Run() (err error) {
 		Query:  o.Query,
 		Limit:  page.Limit(),
 		Offset: page.Offset(),
+		Body:   o.Body,
 	}
 
 	results, err := inst.Search().Search(ctx, p)
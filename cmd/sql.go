@@ -3,9 +3,15 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
 
+	"github.com/dustin/go-humanize"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/sql"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +35,11 @@ PostgreSQL in a few ways:
   * For a dataset to be queryable it's schema must be properly configured to
     describe a tabular structure, with valid column names & types
   * Referencing columns that do not exist will return null values instead of
-    throwing an error`,
+    throwing an error
+
+Use --explain to see how a query will run without running it. Explain lists
+the datasets a query loads, whether each dataset is read in full, the order
+joins run in, and an estimate of the number of result rows.`,
 		Example: `  # first, fetch the dataset b5/world_bank_population:
   $ qri add b5/world_bank_population
   $ qri sql "SELECT 
@@ -43,7 +53,10 @@ PostgreSQL in a few ways:
     cc.official_name_en, wbp.year_2010, wbp.year_2011 
     FROM b5/world_bank_population as wbp
     LEFT JOIN b5/country_codes as cc 
-    ON cc.iso_3166_1_alpha_3 = wbp.country_code"`,
+    ON cc.iso_3166_1_alpha_3 = wbp.country_code"
+
+  # show how a query will be executed
+  $ qri sql --explain "SELECT * FROM b5/world_bank_population as wbp LIMIT 10"`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -58,6 +71,7 @@ PostgreSQL in a few ways:
 
 	cmd.Flags().StringVarP(&o.Format, "format", "f", "table", "set output format [table]")
 	cmd.Flags().BoolVar(&o.Offline, "offline", false, "prevent network access")
+	cmd.Flags().BoolVar(&o.Explain, "explain", false, "show how the query will be executed without running it")
 
 	return cmd
 }
@@ -69,6 +83,7 @@ type SQLOptions struct {
 	Query   string
 	Format  string
 	Offline bool
+	Explain bool
 
 	Instance *lib.Instance
 }
@@ -101,6 +116,24 @@ func (o *SQLOptions) Run() (err error) {
 		Format: o.Format,
 	}
 
+	if o.Explain {
+		plan, err := inst.WithSource(source).SQL().Explain(ctx, p)
+		o.StopSpinner()
+		if err != nil {
+			return err
+		}
+		if o.Format == "json" {
+			data, err := json.MarshalIndent(plan, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(o.Out, string(data))
+			return nil
+		}
+		printSQLPlan(o.Out, plan)
+		return nil
+	}
+
 	res, err := inst.WithSource(source).SQL().Exec(ctx, p)
 	o.StopSpinner()
 	if err != nil {
@@ -110,3 +143,32 @@ func (o *SQLOptions) Run() (err error) {
 	printToPager(o.Out, bytes.NewBuffer(res))
 	return nil
 }
+
+// printSQLPlan writes a human readable query plan
+func printSQLPlan(w io.Writer, plan *lib.SQLPlan) {
+	fmt.Fprintln(w, "sources:")
+	for _, src := range plan.Sources {
+		scan := "full scan"
+		if src.Scan == sql.ScanLimit {
+			scan = fmt.Sprintf("stops after %d rows", src.Limit)
+		}
+		fmt.Fprintf(w, "  %s as %s\t%d rows\t%s\t%s\n", src.Ref, src.Alias, src.Rows, humanize.Bytes(uint64(src.Length)), scan)
+		if src.Error != "" {
+			fmt.Fprintf(w, "    error: %s\n", src.Error)
+		}
+	}
+
+	fmt.Fprintln(w, "\nsteps:")
+	for i, step := range plan.Steps {
+		indent := strings.Repeat("  ", step.Depth+1)
+		fmt.Fprintf(w, "%s%d. %s %s\t(~%d rows)\n", indent, i+1, step.Op, step.Detail, step.Rows)
+	}
+	fmt.Fprintf(w, "\nestimated rows: %d\n", plan.EstimatedRows)
+
+	if len(plan.Notes) > 0 {
+		fmt.Fprintln(w, "\nnotes:")
+		for _, note := range plan.Notes {
+			fmt.Fprintf(w, "  * %s\n", note)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/base"
+	qerr "github.com/qri-io/qri/errors"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/spf13/cobra"
+)
+
+// NewSquashCommand creates a new `qri squash` cobra command for collapsing
+// the latest versions of a dataset into one
+func NewSquashCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &SquashOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "squash DATASET",
+		Short: "collapse the latest versions of a dataset into one",
+		Long: `Squash replaces the latest versions of a dataset with a single version that
+has the contents of the current head. Use squash to tidy up long exploratory
+histories before sharing a dataset.
+
+The squashed version is titled after the oldest squashed version, and its
+message lists the titles & messages of every squashed version. Set either
+with the '--title' and '--message' flags.
+
+Squashed versions are dropped from history, but their data stays in your
+repo until it's garbage collected. Pass '--unpin' to remove it right away.
+
+Squash refuses to rewrite versions that have been pushed to a remote, because
+anyone who pulled them keeps the old history. Use '--force' to squash them
+anyway.`,
+		Example: `  # collapse the latest 3 versions of a dataset into one
+  $ qri squash me/annual_pop --count 3
+
+  # squash with a custom title, removing squashed version data
+  $ qri squash me/annual_pop -n 5 --title "add 2020 figures" --unpin`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().IntVarP(&o.Count, "count", "n", 0, "number of versions to squash, starting from the latest")
+	cmd.Flags().StringVarP(&o.Title, "title", "t", "", "title of the squashed version")
+	cmd.Flags().StringVarP(&o.Message, "message", "m", "", "message of the squashed version")
+	cmd.Flags().BoolVar(&o.Unpin, "unpin", false, "remove the data of squashed versions")
+	cmd.Flags().BoolVarP(&o.Force, "force", "f", false, "squash versions that have been pushed")
+
+	return cmd
+}
+
+// SquashOptions encapsulates state for the squash command
+type SquashOptions struct {
+	ioes.IOStreams
+
+	Refs    *RefSelect
+	Count   int
+	Title   string
+	Message string
+	Unpin   bool
+	Force   bool
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *SquashOptions) Complete(f Factory, args []string) (err error) {
+	if o.inst, err = f.Instance(); err != nil {
+		return
+	}
+	if o.Refs, err = GetCurrentRefSelect(f, args, 1, nil); err != nil {
+		// This error will be handled during validation
+		if err != repo.ErrEmptyRef {
+			return
+		}
+		err = nil
+	}
+	return
+}
+
+// Validate checks that all user input is valid
+func (o *SquashOptions) Validate() error {
+	if o.Refs.Ref() == "" {
+		return qerr.New(lib.ErrBadArgs, "please specify a dataset to squash")
+	}
+	if o.Count < 2 {
+		return qerr.New(lib.ErrBadArgs, "use --count to squash at least 2 versions")
+	}
+	return nil
+}
+
+// Run executes the squash command
+func (o *SquashOptions) Run() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	ctx := context.TODO()
+	res, err := o.inst.Dataset().Squash(ctx, &lib.SquashParams{
+		Ref:     o.Refs.Ref(),
+		Count:   o.Count,
+		Title:   o.Title,
+		Message: o.Message,
+		Unpin:   o.Unpin,
+		Force:   o.Force,
+	})
+	if err != nil {
+		if errors.Is(err, base.ErrSquashPublished) {
+			return qerr.New(err, fmt.Sprintf("%s\nuse --force to squash published versions", err))
+		}
+		return err
+	}
+
+	printSuccess(o.Out, "squashed %d versions of %s into %s", len(res.Squashed), res.Ref, res.Path)
+	if len(res.Unpinned) > 0 {
+		printInfo(o.Out, "unpinned %d versions", len(res.Unpinned))
+	}
+	return nil
+}
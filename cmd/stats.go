@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/lib"
 	"github.com/spf13/cobra"
@@ -16,13 +19,21 @@ func NewStatsCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "stats DATASET",
 		Short: "get aggregated stats for a dataset",
-		Long:  `Run the ` + "`stats`" + ` to generate and view stats for a dataset using a dataset reference.`,
+		Long: `Run the ` + "`stats`" + ` to generate and view stats for a dataset using a dataset reference.
+
+The ` + "`--history`" + ` flag summarizes stats across versions of a dataset instead:
+the row count of each version, and the percentage of missing values & the
+minimum & maximum of each column. Use ` + "`--columns`" + ` to pick columns.`,
 		Example: `  # Get stats for me/dataset_name:
-  $ qri stats me/dataset_name`,
+  $ qri stats me/dataset_name
+
+  # Show how the pop & city columns changed over the last 10 versions:
+  $ qri stats me/dataset_name --history --columns pop,city --limit 10`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
-		Args: cobra.MaximumNArgs(1),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeRef(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -35,7 +46,42 @@ func NewStatsCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&o.Pretty, "pretty", "p", false, "whether to print output with indentation")
+	cmd.Flags().BoolVar(&o.History, "history", false, "summarize stats across versions")
+	cmd.Flags().StringSliceVar(&o.Columns, "columns", nil, "columns to include in the history, defaults to all columns")
+	cmd.Flags().IntVar(&o.Limit, "limit", 25, "number of versions to include in the history")
+	cmd.Flags().BoolVar(&o.JSON, "json", false, "print history as JSON")
 
+	remoteCmd := &cobra.Command{
+		Use:   "remote DATASET",
+		Short: "show how often a dataset is pulled from a remote",
+		Long: `Remote shows usage stats a remote has recorded for one of your datasets:
+how many times each version has been pulled, by how many unique peers, and how
+many bytes the remote has served. Remotes only record usage stats when
+configured to with remote.usagestats, and only share them with the dataset
+owner.`,
+		Example: `  # show how often me/dataset_name is pulled from the registry:
+  $ qri stats remote me/dataset_name
+
+  # show usage from a named remote as JSON:
+  $ qri stats remote me/dataset_name --remote my_remote --json`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeRef(f),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			rm, err := f.RemoteMethods()
+			if err != nil {
+				return err
+			}
+			o.RemoteMethods = rm
+			return o.RunRemote()
+		},
+	}
+	remoteCmd.Flags().StringVar(&o.Remote, "remote", "", "name of remote to fetch stats from, defaults to the registry")
+	remoteCmd.Flags().BoolVar(&o.JSON, "json", false, "print usage stats as JSON")
+
+	cmd.AddCommand(remoteCmd)
 	return cmd
 }
 
@@ -43,8 +89,15 @@ func NewStatsCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
 type StatsOptions struct {
 	ioes.IOStreams
 
-	Refs   *RefSelect
-	Pretty bool
+	Refs    *RefSelect
+	Pretty  bool
+	Remote  string
+	JSON    bool
+	History bool
+	Columns []string
+	Limit   int
+
+	RemoteMethods *lib.RemoteMethods
 
 	inst *lib.Instance
 }
@@ -60,12 +113,18 @@ func (o *StatsOptions) Complete(f Factory, args []string) (err error) {
 
 // Validate checks that any user input is valid
 func (o *StatsOptions) Validate() error {
+	if !o.History && len(o.Columns) > 0 {
+		return fmt.Errorf("--columns only applies to --history")
+	}
 	return nil
 }
 
 // Run executes the stats command
 func (o *StatsOptions) Run() (err error) {
 	printRefSelect(o.ErrOut, o.Refs)
+	if o.History {
+		return o.RunHistory()
+	}
 
 	ctx := context.TODO()
 	p := &lib.StatsParams{
@@ -93,3 +152,75 @@ func (o *StatsOptions) Run() (err error) {
 	printInfo(o.Out, string(buffer))
 	return nil
 }
+
+// RunRemote fetches & prints usage stats for a dataset from a remote
+func (o *StatsOptions) RunRemote() error {
+	printRefSelect(o.ErrOut, o.Refs)
+
+	ctx := context.TODO()
+	p := &lib.UsageParams{
+		Ref:    o.Refs.Ref(),
+		Remote: o.Remote,
+	}
+	usage, err := o.RemoteMethods.Usage(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if o.JSON {
+		data, err := json.MarshalIndent(usage, "", "  ")
+		if err != nil {
+			return err
+		}
+		printInfo(o.Out, string(data))
+		return nil
+	}
+
+	printInfo(o.Out, "%s: %d pulls by %d peers, %s served", usage.Ref, usage.Pulls, usage.UniquePeers, humanize.Bytes(usage.BytesServed))
+	for _, v := range usage.Versions {
+		printInfo(o.Out, "%s\t%d pulls\t%d peers\t%s\tlast pulled %s", v.Path, v.Pulls, v.UniquePeers, humanize.Bytes(v.BytesServed), humanize.Time(v.LastPulled))
+	}
+	return nil
+}
+
+// RunHistory prints summary stats for each version of a dataset
+func (o *StatsOptions) RunHistory() error {
+	ctx := context.TODO()
+	p := &lib.StatsHistoryParams{
+		Ref:     o.Refs.Ref(),
+		Columns: o.Columns,
+		Limit:   o.Limit,
+	}
+	hist, err := o.inst.Dataset().StatsHistory(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if o.JSON {
+		data, err := json.MarshalIndent(hist, "", "  ")
+		if err != nil {
+			return err
+		}
+		printInfo(o.Out, string(data))
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	for _, v := range hist.Versions {
+		fmt.Fprintf(buf, "%s\t%s\t%s\n", v.Path, v.CommitTime.Format(time.RFC3339), v.CommitTitle)
+		if v.Error != "" {
+			fmt.Fprintf(buf, "    no stats: %s\n\n", v.Error)
+			continue
+		}
+		fmt.Fprintf(buf, "    rows: %d\n", v.Rows)
+		for _, c := range v.Columns {
+			fmt.Fprintf(buf, "    %s: %.1f%% missing", c.Name, c.NullPercent)
+			if c.Min != nil && c.Max != nil {
+				fmt.Fprintf(buf, ", min %g, max %g", *c.Min, *c.Max)
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	}
+	return printToPager(o.Out, buf)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/qri-io/ioes"
 	"github.com/qri-io/qri/fsi"
@@ -33,7 +35,13 @@ a commit alongside a dataset like:
   $ qri status me/my_dataset@/ipfs/Qmuabcd
   
   # List what changed in the latest commit of the working directory:
-  $ qri status $(cat .qri-ref)`,
+  $ qri status $(cat .qri-ref)
+
+  # Report on every dataset linked to a working directory:
+  $ qri status --all
+
+  # Report on linked datasets without contacting the registry:
+  $ qri status --all --offline`,
 		Annotations: map[string]string{
 			"group": "workdir",
 		},
@@ -47,6 +55,10 @@ a commit alongside a dataset like:
 	}
 
 	cmd.Flags().BoolVar(&o.ShowMtime, "show-mtime", false, "whether to show mtime for each component")
+	cmd.Flags().BoolVarP(&o.All, "all", "a", false, "report on every dataset linked to a working directory")
+	cmd.Flags().StringVar(&o.Remote, "remote", "", "with --all, remote to compare versions against, defaults to the registry")
+	cmd.Flags().BoolVar(&o.Offline, "offline", false, "with --all, don't compare versions with a remote")
+	cmd.Flags().StringVar(&o.Format, "format", "", "with --all, output format. one of [json]")
 
 	return cmd
 }
@@ -59,6 +71,11 @@ type StatusOptions struct {
 
 	Refs      *RefSelect
 	ShowMtime bool
+
+	All     bool
+	Remote  string
+	Offline bool
+	Format  string
 }
 
 // Complete adds any missing configuration that can only be added just before calling Run
@@ -67,6 +84,19 @@ func (o *StatusOptions) Complete(f Factory, args []string) (err error) {
 		return err
 	}
 
+	if o.All {
+		if len(args) > 0 {
+			return fmt.Errorf("cannot pass a dataset reference with --all")
+		}
+		if o.Format != "" && o.Format != "json" {
+			return fmt.Errorf("unknown format %q. only json is supported", o.Format)
+		}
+		return nil
+	}
+	if o.Remote != "" || o.Offline || o.Format != "" {
+		return fmt.Errorf("--remote, --offline, and --format flags require --all")
+	}
+
 	// Cannot pass explicit reference, must be run in a working directory
 	if len(args) > 0 {
 		// TODO(dustmop): Fix this, see issue https://github.com/qri-io/qri/issues/1246
@@ -82,6 +112,9 @@ const ColumnPositionForMtime = 40
 
 // Run executes the status command
 func (o *StatusOptions) Run() (err error) {
+	if o.All {
+		return o.RunAll()
+	}
 	printRefSelect(o.ErrOut, o.Refs)
 
 	ctx := context.TODO()
@@ -134,3 +167,46 @@ func (o *StatusOptions) Run() (err error) {
 	}
 	return nil
 }
+
+// RunAll reports on every dataset linked to a working directory
+func (o *StatusOptions) RunAll() error {
+	ctx := context.TODO()
+	p := &lib.StatusAllParams{Remote: o.Remote, Offline: o.Offline}
+	res, err := o.Instance.Filesys().StatusAll(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if o.Format == "json" {
+		return printJSON(o.Out, res)
+	}
+
+	if len(res) == 0 {
+		printInfo(o.Out, "no datasets are linked to working directories")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATASET\tCHANGES\tAHEAD\tBEHIND\tQUEUED\tDIRECTORY")
+	for _, st := range res {
+		changes := "clean"
+		if st.StatusError != "" {
+			changes = "error: " + st.StatusError
+		} else if len(st.Changes) > 0 {
+			comps := make([]string, len(st.Changes))
+			for i, si := range st.Changes {
+				comps[i] = fmt.Sprintf("%s %s", si.Component, si.Type)
+			}
+			changes = strings.Join(comps, ", ")
+		}
+
+		ahead, behind := strconv.Itoa(st.Ahead), strconv.Itoa(st.Behind)
+		if o.Offline {
+			ahead, behind = "-", "-"
+		} else if st.RemoteError != "" {
+			ahead, behind = "?", "?"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", st.Ref, changes, ahead, behind, len(st.Queued), st.FSIPath)
+	}
+	return w.Flush()
+}
@@ -8,9 +8,11 @@ import (
 
 	"github.com/dustin/go-humanize"
 	"github.com/fatih/color"
+	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/logbook"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
 
@@ -128,6 +130,15 @@ func (vis versionInfoStringer) String() string {
 	if vis.Foreign {
 		fmt.Fprintf(w, "\n%s", warn("foreign"))
 	}
+	if vis.Lifecycle != "" {
+		fmt.Fprintf(w, "\n%s", warn(vis.Lifecycle))
+		if vis.LifecycleReason != "" {
+			fmt.Fprintf(w, ": %s", vis.LifecycleReason)
+		}
+		if vis.Successor != "" {
+			fmt.Fprintf(w, " (successor: %s)", vis.Successor)
+		}
+	}
 	fmt.Fprintf(w, "\n%s", humanize.Bytes(uint64(vis.BodySize)))
 	if vis.BodyRows == 1 {
 		fmt.Fprintf(w, ", %d entry", vis.BodyRows)
@@ -262,3 +273,98 @@ func (s dslogItemStringer) String() string {
 
 	return msg
 }
+
+type componentLogItemStringer lib.VersionComponents
+
+func (s componentLogItemStringer) String() string {
+	faint := color.New(color.Faint).SprintFunc()
+
+	msg := dslogItemStringer(s.VersionInfo).String()
+	if s.Changes == nil {
+		return msg + fmt.Sprintf("%s%s\n\n", faint("Changed: "), faint("unknown, previous version isn't available"))
+	}
+	if len(s.Changes) == 0 {
+		return msg + fmt.Sprintf("%s%s\n\n", faint("Changed: "), "no components")
+	}
+
+	changes := make([]string, len(s.Changes))
+	for i, ch := range s.Changes {
+		changes[i] = componentChangeString(ch)
+	}
+	return msg + fmt.Sprintf("%s%s\n\n", faint("Changed: "), strings.Join(changes, ", "))
+}
+
+func componentChangeString(ch base.ComponentChange) string {
+	switch ch.Status {
+	case base.ComponentAdded:
+		return color.New(color.FgGreen).Sprintf("+%s", ch.Component)
+	case base.ComponentRemoved:
+		return color.New(color.FgRed).Sprintf("-%s", ch.Component)
+	default:
+		return color.New(color.FgYellow).Sprintf("~%s", ch.Component)
+	}
+}
+
+// logGraphString draws the full operation history of a dataset, newest
+// first. Runs that created a version are drawn beneath that version
+func logGraphString(entries []lib.LogGraphEntry) string {
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+
+	runs := map[string]lib.LogGraphEntry{}
+	for _, e := range entries {
+		if e.Type == logbook.GraphRun {
+			runs[e.RunID] = e
+		}
+	}
+	linked := map[string]bool{}
+
+	versions := func(n int) string {
+		if n == 1 {
+			return "1 version"
+		}
+		return fmt.Sprintf("%d versions", n)
+	}
+	runString := func(e lib.LogGraphEntry) string {
+		return fmt.Sprintf("run %s %s in %s", e.RunID, e.RunStatus, time.Duration(e.RunDuration).Round(time.Millisecond))
+	}
+
+	w := &strings.Builder{}
+	for _, e := range entries {
+		ts := faint(e.Timestamp.In(StringerLocation).Format(time.Stamp))
+		switch e.Type {
+		case logbook.GraphSave, logbook.GraphAmend:
+			mark, path := "*", yellow(e.Path)
+			if e.Deleted {
+				mark, path = red("x"), red(e.Path)
+			}
+			fmt.Fprintf(w, "%s %-9s %s  %s  %s", mark, e.Type, ts, path, e.Title)
+			if e.Published {
+				fmt.Fprintf(w, " %s", green("(published)"))
+			}
+			fmt.Fprintln(w)
+			if run, ok := runs[e.RunID]; ok && e.RunID != "" {
+				linked[e.RunID] = true
+				fmt.Fprintf(w, "|   %s\n", faint(runString(run)))
+			}
+		case logbook.GraphRun:
+			if linked[e.RunID] {
+				continue
+			}
+			fmt.Fprintf(w, "o %-9s %s  %s\n", e.Type, ts, runString(e))
+		case logbook.GraphPublish:
+			fmt.Fprintf(w, "^ %-9s %s  %s to %s\n", e.Type, ts, versions(e.Revisions), e.Remote)
+		case logbook.GraphUnpublish:
+			fmt.Fprintf(w, "v %-9s %s  %s from %s\n", e.Type, ts, versions(e.Revisions), e.Remote)
+		case logbook.GraphRemove:
+			fmt.Fprintf(w, "%s %-9s %s  %s\n", red("-"), e.Type, ts, versions(e.Revisions))
+		case logbook.GraphDelete:
+			fmt.Fprintf(w, "%s %-9s %s  %s\n", red("#"), e.Type, ts, red("dataset deleted"))
+		case logbook.GraphGap:
+			fmt.Fprintf(w, ": %-9s %s  %s\n", e.Type, ts, faint("missing history"))
+		}
+	}
+	return w.String()
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewTrashCommand creates a `qri trash` command for listing, restoring &
+// purging removed datasets
+func NewTrashCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &TrashOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "list, restore & purge removed datasets",
+		Long: `Removing an entire dataset moves it to the trash. Trashed datasets are gone
+from your repo, but their stored versions are kept for a grace period, during
+which they can be restored. Once the grace period expires, trashed versions are
+purged the next time garbage collection runs.
+
+The grace period defaults to 7 days, and is set with the repo.trashgraceperiod
+config field as a duration like "72h". Set it to "-1" to disable the trash.
+Pass '--purge' to 'qri remove' to skip the trash for a single dataset.
+
+Restored datasets get back the history of their stored versions. Restoring
+fails if a dataset with the same name has been created since removal.`,
+		Example: `  # list datasets in the trash
+  $ qri trash list
+
+  # bring back a removed dataset
+  $ qri trash restore me/annual_pop
+
+  # purge every dataset in the trash
+  $ qri trash empty
+
+  # purge datasets with an expired grace period
+  $ qri trash empty --expired`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "list removed datasets in the trash",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	restore := &cobra.Command{
+		Use:   "restore DATASET",
+		Short: "bring back a removed dataset",
+		Long: `'qri trash restore' brings back a removed dataset by name or by the path of
+its latest version. Names removed more than once restore the most recently
+removed dataset.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			return o.Restore(args[0])
+		},
+	}
+
+	empty := &cobra.Command{
+		Use:   "empty [DATASET]",
+		Short: "purge removed datasets",
+		Long: `'qri trash empty' purges the stored versions of datasets in the trash.
+Purged datasets can't be restored. Given a DATASET, only that dataset is
+purged. Storage is freed the next time garbage collection runs.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f); err != nil {
+				return err
+			}
+			ref := ""
+			if len(args) > 0 {
+				ref = args[0]
+			}
+			return o.Empty(ref)
+		},
+	}
+	empty.Flags().BoolVar(&o.Expired, "expired", false, "only purge datasets with an expired grace period")
+	empty.Flags().BoolVarP(&o.Yes, "yes", "y", false, "skip confirmation")
+
+	cmd.AddCommand(list)
+	cmd.AddCommand(restore)
+	cmd.AddCommand(empty)
+	return cmd
+}
+
+// TrashOptions encapsulates state for the trash command
+type TrashOptions struct {
+	ioes.IOStreams
+
+	Expired bool
+	Yes     bool
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *TrashOptions) Complete(f Factory) (err error) {
+	o.inst, err = f.Instance()
+	return err
+}
+
+// List prints datasets in the trash
+func (o *TrashOptions) List() error {
+	trashed, err := o.inst.Dataset().Trash(context.TODO(), &lib.TrashParams{})
+	if err != nil {
+		return err
+	}
+	if len(trashed) == 0 {
+		printInfo(o.Out, "trash is empty")
+		return nil
+	}
+	now := time.Now()
+	w := tabwriter.NewWriter(o.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "DATASET\tPATH\tREMOVED\tEXPIRES\n")
+	for _, td := range trashed {
+		expires := td.Expires.Format("2006-01-02 15:04")
+		if td.Expired(now) {
+			expires = "expired"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", td.Ref, td.Path, td.Removed.Format("2006-01-02 15:04"), expires)
+	}
+	return w.Flush()
+}
+
+// Restore brings back a dataset from the trash
+func (o *TrashOptions) Restore(ref string) error {
+	vi, err := o.inst.Dataset().RestoreFromTrash(context.TODO(), &lib.RestoreFromTrashParams{Ref: ref})
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, "restored %s", vi.SimpleRef().Alias())
+	return nil
+}
+
+// Empty purges datasets in the trash, confirming before purging
+func (o *TrashOptions) Empty(ref string) error {
+	if !o.Yes {
+		what := "every dataset in the trash"
+		if ref != "" {
+			what = ref
+		} else if o.Expired {
+			what = "expired datasets in the trash"
+		}
+		if !confirm(o.ErrOut, o.In, fmt.Sprintf("purge %s? purged datasets can't be restored", what), false) {
+			return fmt.Errorf("trash not emptied")
+		}
+	}
+	purged, err := o.inst.Dataset().EmptyTrash(context.TODO(), &lib.EmptyTrashParams{Ref: ref, Expired: o.Expired})
+	if err != nil {
+		return err
+	}
+	printSuccess(o.Out, "purged %d datasets", len(purged))
+	return nil
+}
@@ -50,6 +50,7 @@ Qri to explore a dataset.`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
+		ValidArgsFunction: completeRefs(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
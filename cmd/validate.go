@@ -67,7 +67,8 @@ if these flags are provided.`,
 
   # Validate data against a new schema:
   $ qri validate --body data.csv --schema schema.json`,
-		Args: cobra.MaximumNArgs(1),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeRef(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/lib"
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyRepoCommand creates a new `qri verify-repo` command that checks
+// the structural integrity of stored datasets
+func NewVerifyRepoCommand(f Factory, ioStreams ioes.IOStreams) *cobra.Command {
+	o := &VerifyRepoOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "verify-repo",
+		Short: "check stored datasets for missing or corrupt data",
+		Long: `Verify-repo walks every dataset version your repo references, confirming
+all component blocks are stored locally and that each component parses and
+validates. Each version is reported with one of three statuses:
+
+  ok       all data is stored locally and valid
+  remote   some data isn't stored locally, and must be fetched from the network
+  invalid  stored data can't be read or doesn't match its schema
+
+With --repair, remote-only and invalid versions of named datasets are
+re-fetched from the network, or the remote given with --remote, then checked
+again. Verify-repo exits with an error when any invalid versions remain.`,
+		Example: `  # check all stored datasets:
+  $ qri verify-repo
+
+  # fetch missing data from the registry:
+  $ qri verify-repo --repair --remote registry
+
+  # print a machine-readable report:
+  $ qri verify-repo --json`,
+		Annotations: map[string]string{
+			"group": "other",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.JSON, "json", false, "print the report as JSON")
+	cmd.Flags().BoolVar(&o.Repair, "repair", false, "re-fetch remote-only & invalid versions")
+	cmd.Flags().StringVar(&o.Remote, "remote", "", "location to repair from, defaults to the network")
+
+	return cmd
+}
+
+// VerifyRepoOptions encapsulates state for the verify-repo command
+type VerifyRepoOptions struct {
+	ioes.IOStreams
+
+	JSON   bool
+	Repair bool
+	Remote string
+
+	inst *lib.Instance
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *VerifyRepoOptions) Complete(f Factory, args []string) (err error) {
+	if o.Remote != "" && !o.Repair {
+		return fmt.Errorf("--remote can only be used with --repair")
+	}
+	o.inst, err = f.Instance()
+	return err
+}
+
+// Run verifies the repo & prints the report
+func (o *VerifyRepoOptions) Run() error {
+	ctx := context.TODO()
+	p := &lib.VerifyRepoParams{Repair: o.Repair, Remote: o.Remote}
+	report, err := o.inst.Admin().VerifyRepo(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	if o.JSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		printInfo(o.Out, "%s", data)
+	} else {
+		for _, v := range report.Versions {
+			if v.Status == dsfs.VerifyOK && !v.Repaired {
+				continue
+			}
+			name := v.Ref.Alias()
+			if name == "" {
+				name = "(unnamed)"
+			}
+			msg := fmt.Sprintf("%s\t%s\t%s", v.Status, name, v.Path)
+			if v.Repaired {
+				msg += "\trepaired"
+			}
+			if v.Error != "" {
+				msg += "\t" + v.Error
+			}
+			for _, c := range v.Components {
+				if c.Status != dsfs.VerifyOK {
+					msg += fmt.Sprintf("\n\t%s %s: %s %s", c.Status, c.Component, c.Path, c.Error)
+				}
+			}
+			if v.Status == dsfs.VerifyOK {
+				printSuccess(o.Out, "%s", msg)
+			} else {
+				printWarning(o.Out, "%s", msg)
+			}
+		}
+		printInfo(o.Out, "\n%d versions: %d ok, %d remote, %d invalid", len(report.Versions), report.OK, report.Remote, report.Invalid)
+	}
+
+	if report.Invalid > 0 {
+		return fmt.Errorf("%d invalid versions", report.Invalid)
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ were added, modified or removed. This is analogous to the status command,
 except only available for dataset versions in history.`,
 		Example: `  # Show what changed for the head commit
   $ qri whatchanged me/dataset_name`,
+		ValidArgsFunction: completeRef(f),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
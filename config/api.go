@@ -26,6 +26,11 @@ type API struct {
 	DisconnectAfter int `json:"disconnectafter,omitempty"`
 	// support CORS signing from a list of origins
 	AllowedOrigins []string `json:"allowedorigins"`
+	// CORS configures cross-origin requests beyond AllowedOrigins
+	CORS *CORS `json:"cors,omitempty"`
+	// Compression configures gzip response compression. nil uses
+	// DefaultCompression
+	Compression *Compression `json:"compression,omitempty"`
 	// whether to allow requests from addresses other than localhost
 	ServeRemoteTraffic bool `json:"serveremotetraffic"`
 	// Deprecated - web sockets now use the same port as the Address field
@@ -36,6 +41,16 @@ type API struct {
 	// TODO (ramfox): when we next have a config migration, we should probably rename this to
 	// EnableWebui and default to true. the double negative here can be confusing.
 	DisableWebui bool `json:"disablewebui"`
+	// Operators is a list of profile IDs allowed to call /admin endpoints. The
+	// repo owner is always an operator
+	Operators []string `json:"operators,omitempty"`
+	// Readiness lists the subsystem checks that must pass for the /readyz
+	// endpoint to report ready. Empty requires every check that applies
+	Readiness []string `json:"readiness,omitempty"`
+	// BodyURLHosts lists the hosts API callers may ask qri to download dataset
+	// bodies from. Entries may start with "*." to match subdomains. Empty
+	// refuses body URLs sent over the API. In-process callers aren't limited
+	BodyURLHosts []string `json:"bodyurlhosts,omitempty"`
 }
 
 // SetArbitrary is an interface implementation of base/fill/struct in order to safely
@@ -84,6 +99,80 @@ func (a API) Validate() error {
         "items": {
           "type": "string"
         }
+      },
+      "cors": {
+        "description": "Cross-origin request configuration",
+        "type": "object",
+        "properties": {
+          "readorigins": {
+            "description": "Origins allowed to call read-only methods",
+            "type": "array",
+            "items": { "type": "string" }
+          },
+          "writeorigins": {
+            "description": "Origins allowed to make requests of any method",
+            "type": "array",
+            "items": { "type": "string" }
+          },
+          "allowedheaders": {
+            "description": "Request headers cross-origin requests may use",
+            "type": "array",
+            "items": { "type": "string" }
+          },
+          "allowedmethods": {
+            "description": "Methods cross-origin requests may use",
+            "type": "array",
+            "items": { "type": "string" }
+          }
+        }
+      },
+      "compression": {
+        "description": "Response compression configuration",
+        "type": "object",
+        "properties": {
+          "disabled": {
+            "description": "When true, responses are never compressed",
+            "type": "boolean"
+          },
+          "level": {
+            "description": "gzip compression level from 1 (fastest) to 9 (smallest), 0 uses the default level",
+            "type": "integer",
+            "minimum": 0,
+            "maximum": 9
+          },
+          "minsize": {
+            "description": "Smallest response in bytes to compress",
+            "type": "integer",
+            "minimum": 0
+          },
+          "skiptypes": {
+            "description": "Content types never compressed, in addition to already-compressed formats",
+            "type": "array",
+            "items": { "type": "string" }
+          }
+        }
+      },
+      "operators": {
+        "description": "Profile IDs allowed to call admin endpoints",
+        "type": "array",
+        "items": {
+          "type": "string"
+        }
+      },
+      "readiness": {
+        "description": "Subsystem checks required for the api to report ready",
+        "type": "array",
+        "items": {
+          "type": "string",
+          "enum": ["qfs", "logbook", "p2p"]
+        }
+      },
+      "bodyurlhosts": {
+        "description": "Hosts api callers may download dataset bodies from",
+        "type": "array",
+        "items": {
+          "type": "string"
+        }
       }
     }
   }`)
@@ -120,5 +209,96 @@ func (a *API) Copy() *API {
 		res.AllowedOrigins = make([]string, len(a.AllowedOrigins))
 		reflect.Copy(reflect.ValueOf(res.AllowedOrigins), reflect.ValueOf(a.AllowedOrigins))
 	}
+	if a.CORS != nil {
+		res.CORS = a.CORS.Copy()
+	}
+	if a.Compression != nil {
+		res.Compression = a.Compression.Copy()
+	}
+	if a.Operators != nil {
+		res.Operators = make([]string, len(a.Operators))
+		copy(res.Operators, a.Operators)
+	}
+	if a.Readiness != nil {
+		res.Readiness = make([]string, len(a.Readiness))
+		copy(res.Readiness, a.Readiness)
+	}
+	if a.BodyURLHosts != nil {
+		res.BodyURLHosts = make([]string, len(a.BodyURLHosts))
+		copy(res.BodyURLHosts, a.BodyURLHosts)
+	}
+	return res
+}
+
+// CORS configures Cross-Origin Resource Sharing for the api. Origins match
+// exactly, or match any subdomain when the host starts with a wildcard, like
+// "https://*.qri.io". Origins listed in api.allowedorigins may make any
+// request
+type CORS struct {
+	// ReadOrigins may only call read-only methods, like get & history. Routes
+	// that aren't lib methods are limited to GET & HEAD requests
+	ReadOrigins []string `json:"readorigins,omitempty"`
+	// WriteOrigins may make requests of any method
+	WriteOrigins []string `json:"writeorigins,omitempty"`
+	// AllowedHeaders overrides the default list of request headers cross-origin
+	// requests may use
+	AllowedHeaders []string `json:"allowedheaders,omitempty"`
+	// AllowedMethods overrides the default list of methods cross-origin
+	// requests may use
+	AllowedMethods []string `json:"allowedmethods,omitempty"`
+}
+
+// Copy returns a deep copy of a CORS struct
+func (c *CORS) Copy() *CORS {
+	return &CORS{
+		ReadOrigins:    copyStrings(c.ReadOrigins),
+		WriteOrigins:   copyStrings(c.WriteOrigins),
+		AllowedHeaders: copyStrings(c.AllowedHeaders),
+		AllowedMethods: copyStrings(c.AllowedMethods),
+	}
+}
+
+// DefaultCompressionMinSize is the smallest response compressed by default.
+// Compressing smaller responses rarely saves a network round trip
+const DefaultCompressionMinSize = 1024
+
+// Compression configures gzip compression of api responses. Responses are
+// compressed only when requests accept gzip encoding. Already-compressed
+// formats like images & zip archives are never compressed
+type Compression struct {
+	// Disabled turns off response compression
+	Disabled bool `json:"disabled,omitempty"`
+	// Level is the gzip compression level, from 1 (fastest) to 9 (smallest).
+	// 0 uses the default level
+	Level int `json:"level,omitempty"`
+	// MinSize is the smallest response in bytes that's compressed. 0 uses
+	// DefaultCompressionMinSize
+	MinSize int `json:"minsize,omitempty"`
+	// SkipTypes lists content types that are never compressed, in addition to
+	// already-compressed formats. Types ending in "/" match any subtype
+	SkipTypes []string `json:"skiptypes,omitempty"`
+}
+
+// DefaultCompression returns the default response compression configuration
+func DefaultCompression() *Compression {
+	return &Compression{MinSize: DefaultCompressionMinSize}
+}
+
+// Copy returns a deep copy of a Compression struct
+func (c *Compression) Copy() *Compression {
+	return &Compression{
+		Disabled:  c.Disabled,
+		Level:     c.Level,
+		MinSize:   c.MinSize,
+		SkipTypes: copyStrings(c.SkipTypes),
+	}
+}
+
+func copyStrings(strs []string) []string {
+	if strs == nil {
+		return nil
+	}
+	res := make([]string, len(strs))
+	copy(res, strs)
 	return res
 }
@@ -10,6 +10,12 @@ func TestAPIValidate(t *testing.T) {
 	if err != nil {
 		t.Errorf("error validating default api: %s", err)
 	}
+
+	api := DefaultAPI()
+	api.Readiness = []string{"qfs", "disk"}
+	if err := api.Validate(); err == nil {
+		t.Errorf("expected unknown readiness check to fail validation")
+	}
 }
 
 func TestAPICopy(t *testing.T) {
@@ -23,6 +29,28 @@ func TestAPICopy(t *testing.T) {
 			ReadOnly:           true,
 			ServeRemoteTraffic: true,
 		}},
+		{"operators", &API{
+			Operators: []string{"QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt"},
+		}},
+		{"readiness", &API{
+			Readiness: []string{"qfs", "logbook"},
+		}},
+		{"body url hosts", &API{
+			BodyURLHosts: []string{"*.example.com"},
+		}},
+		{"cors", &API{
+			CORS: &CORS{
+				ReadOrigins:    []string{"https://*.qri.io"},
+				AllowedHeaders: []string{"Content-Type"},
+			},
+		}},
+		{"compression", &API{
+			Compression: &Compression{
+				Level:     9,
+				MinSize:   512,
+				SkipTypes: []string{"text/csv"},
+			},
+		}},
 	}
 	for i, c := range cases {
 		cpy := c.api.Copy()
@@ -37,5 +65,40 @@ func TestAPICopy(t *testing.T) {
 				continue
 			}
 		}
+		if cpy.Readiness != nil {
+			cpy.Readiness[0] = ""
+			if reflect.DeepEqual(cpy, c.api) {
+				t.Errorf("API Copy test case %d '%s', editing one api struct should not affect the other: \ncopy: %v, \noriginal: %v", i, c.description, cpy, c.api)
+				continue
+			}
+		}
+		if cpy.BodyURLHosts != nil {
+			cpy.BodyURLHosts[0] = ""
+			if reflect.DeepEqual(cpy, c.api) {
+				t.Errorf("API Copy test case %d '%s', editing one api struct should not affect the other: \ncopy: %v, \noriginal: %v", i, c.description, cpy, c.api)
+				continue
+			}
+		}
+		if cpy.CORS != nil {
+			cpy.CORS.ReadOrigins[0] = ""
+			if reflect.DeepEqual(cpy, c.api) {
+				t.Errorf("API Copy test case %d '%s', editing one api struct should not affect the other: \ncopy: %v, \noriginal: %v", i, c.description, cpy, c.api)
+				continue
+			}
+		}
+		if cpy.Compression != nil {
+			cpy.Compression.SkipTypes[0] = ""
+			if reflect.DeepEqual(cpy, c.api) {
+				t.Errorf("API Copy test case %d '%s', editing one api struct should not affect the other: \ncopy: %v, \noriginal: %v", i, c.description, cpy, c.api)
+				continue
+			}
+		}
+		if cpy.Operators != nil {
+			cpy.Operators[0] = ""
+			if reflect.DeepEqual(cpy, c.api) {
+				t.Errorf("API Copy test case %d '%s', editing one api struct should not affect the other: \ncopy: %v, \noriginal: %v", i, c.description, cpy, c.api)
+				continue
+			}
+		}
 	}
 }
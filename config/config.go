@@ -23,6 +23,8 @@ const CurrentConfigRevision = 3
 // Config encapsulates all configuration details for qri
 type Config struct {
 	path string
+	// name of the configuration profile overlay merged into this config
+	overlay string
 
 	Revision    int
 	Profile     *ProfilePod
@@ -30,10 +32,14 @@ type Config struct {
 	Filesystems []qfs.Config
 	P2P         *P2P
 	Stats       *Stats
+	Lint        *Lint
 
-	Registry *Registry
-	Remotes  *Remotes
-	Remote   *Remote
+	Registry    *Registry
+	Remotes     *Remotes
+	Remote      *Remote
+	Credentials *Credentials
+
+	Integrations *Integrations
 
 	CLI     *CLI
 	API     *API
@@ -82,6 +88,9 @@ func DefaultConfig() *Config {
 // running. we should move this elsewhere
 func (cfg Config) SummaryString() (summary string) {
 	summary = "\n"
+	if cfg.overlay != "" {
+		summary += fmt.Sprintf("config profile:\t%s\n", cfg.overlay)
+	}
 	if cfg.Profile != nil {
 		summary += fmt.Sprintf("peername:\t%s\nprofileID:\t%s\n", cfg.Profile.Peername, cfg.Profile.ID)
 	}
@@ -196,6 +205,36 @@ type validator interface {
 // Validate validates each section of the config struct,
 // returning the first error
 func (cfg Config) Validate() error {
+	if errs := cfg.ValidateAll(); len(errs) > 0 {
+		return errs[0].Err
+	}
+	return nil
+}
+
+// ValidationError describes a section of a config that fails validation
+type ValidationError struct {
+	// Section is the lower-case name of the config field that failed, empty
+	// when the config as a whole is invalid
+	Section string
+	Err     error
+}
+
+// Error implements the error interface
+func (e ValidationError) Error() string {
+	if e.Section == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Section, e.Err)
+}
+
+// Unwrap returns the underlying validation error
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateAll validates each section of the config struct, returning an error
+// for every section that fails instead of stopping at the first
+func (cfg Config) ValidateAll() []ValidationError {
 	schema := jsonschema.Must(`{
     "$schema": "http://json-schema.org/draft-06/schema#",
     "title": "config",
@@ -212,33 +251,39 @@ func (cfg Config) Validate() error {
 			"RPC" : { "type":"object" }
     }
   }`)
+	var errs []ValidationError
 	if err := validate(schema, &cfg); err != nil {
-		return fmt.Errorf("config validation error: %s", err)
+		errs = append(errs, ValidationError{Err: fmt.Errorf("config validation error: %s", err)})
 	}
 
-	validators := []validator{
-		cfg.Profile,
-		cfg.Repo,
-		cfg.P2P,
-		cfg.CLI,
-		cfg.API,
-		cfg.RPC,
-		cfg.Logging,
+	validators := []struct {
+		section string
+		val     validator
+	}{
+		{"profile", cfg.Profile},
+		{"repo", cfg.Repo},
+		{"p2p", cfg.P2P},
+		{"cli", cfg.CLI},
+		{"api", cfg.API},
+		{"rpc", cfg.RPC},
+		{"logging", cfg.Logging},
+		{"integrations", cfg.Integrations},
+		{"lint", cfg.Lint},
 	}
-	for _, val := range validators {
+	for _, v := range validators {
 		// we need to check here because we're potentially calling methods on nil
 		// values that don't handle a nil receiver gracefully.
 		// https://tour.golang.org/methods/12
 		// https://groups.google.com/forum/#!topic/golang-nuts/wnH302gBa4I/discussion
 		// TODO (b5) - make validate methods handle being nil
-		if !reflect.ValueOf(val).IsNil() {
-			if err := val.Validate(); err != nil {
-				return err
+		if !reflect.ValueOf(v.val).IsNil() {
+			if err := v.val.Validate(); err != nil {
+				errs = append(errs, ValidationError{Section: v.section, Err: err})
 			}
 		}
 	}
 
-	return nil
+	return errs
 }
 
 // Copy returns a deep copy of the Config struct
@@ -249,6 +294,7 @@ func (cfg *Config) Copy() *Config {
 	if cfg.path != "" {
 		res.path = cfg.path
 	}
+	res.overlay = cfg.overlay
 	if cfg.Profile != nil {
 		res.Profile = cfg.Profile.Copy()
 	}
@@ -276,12 +322,21 @@ func (cfg *Config) Copy() *Config {
 	if cfg.Remotes != nil {
 		res.Remotes = cfg.Remotes.Copy()
 	}
+	if cfg.Credentials != nil {
+		res.Credentials = cfg.Credentials.Copy()
+	}
 	if cfg.Logging != nil {
 		res.Logging = cfg.Logging.Copy()
 	}
 	if cfg.Stats != nil {
 		res.Stats = cfg.Stats.Copy()
 	}
+	if cfg.Integrations != nil {
+		res.Integrations = cfg.Integrations.Copy()
+	}
+	if cfg.Lint != nil {
+		res.Lint = cfg.Lint.Copy()
+	}
 	if cfg.Filesystems != nil {
 		for _, fs := range cfg.Filesystems {
 			res.Filesystems = append(res.Filesystems, fs)
@@ -297,6 +352,11 @@ func (cfg *Config) WithoutPrivateValues() *Config {
 
 	res.Profile.PrivKey = ""
 	res.P2P.PrivKey = ""
+	res.P2P.SwarmKey = ""
+	res.Credentials = nil
+	if res.Integrations != nil {
+		res.Integrations.stripPrivateValues()
+	}
 
 	return res
 }
@@ -308,6 +368,13 @@ func (cfg *Config) WithPrivateValues(p *Config) *Config {
 
 	res.Profile.PrivKey = p.Profile.PrivKey
 	res.P2P.PrivKey = p.P2P.PrivKey
+	res.P2P.SwarmKey = p.P2P.SwarmKey
+	if p.Credentials != nil {
+		res.Credentials = p.Credentials.Copy()
+	}
+	if res.Integrations != nil && p.Integrations != nil {
+		res.Integrations.restorePrivateValues(p.Integrations)
+	}
 
 	return res
 }
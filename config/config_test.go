@@ -200,6 +200,26 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
+func TestConfigValidateAll(t *testing.T) {
+	if errs := testcfg.DefaultConfigForTesting().ValidateAll(); len(errs) != 0 {
+		t.Errorf("expected default config to be valid, got: %v", errs)
+	}
+
+	cfg := testcfg.DefaultConfigForTesting()
+	cfg.Repo.Type = "badType"
+	cfg.Logging.Levels["qriapi"] = "badType"
+	errs := cfg.ValidateAll()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+	if errs[0].Section != "repo" || errs[1].Section != "logging" {
+		t.Errorf("expected repo & logging sections to fail, got: %q, %q", errs[0].Section, errs[1].Section)
+	}
+	if err := cfg.Validate(); err == nil || err.Error() != errs[0].Err.Error() {
+		t.Errorf("expected Validate to return the first error. want: %s, got: %v", errs[0].Err, err)
+	}
+}
+
 func TestConfigCopy(t *testing.T) {
 	cases := []struct {
 		config *config.Config
@@ -219,3 +239,35 @@ func TestConfigCopy(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigWithoutPrivateValues(t *testing.T) {
+	cfg := testcfg.DefaultConfigForTesting()
+	cfg.P2P.SwarmKey = "/key/swarm/psk/1.0.0/"
+	cfg.Integrations = &config.Integrations{
+		Sinks:           []*config.Sink{{Name: "events", Type: config.SinkTypeRabbitMQHTTP, Password: "sink_password"}},
+		Warehouses:      []*config.Warehouse{{Name: "bq", Type: config.WarehouseTypeBigQuery, Token: "bigquery_token"}},
+		PinningServices: []*config.PinningService{{Name: "pinata", Endpoint: "https://api.pinata.cloud/psa", Token: "pinning_token"}},
+	}
+
+	stripped := cfg.WithoutPrivateValues()
+	if stripped.Profile.PrivKey != "" || stripped.P2P.PrivKey != "" || stripped.P2P.SwarmKey != "" {
+		t.Error("expected private keys to be removed")
+	}
+	if pw := stripped.Integrations.Sinks[0].Password; pw != "" {
+		t.Errorf("expected sink password to be removed, got: %q", pw)
+	}
+	if tok := stripped.Integrations.Warehouses[0].Token; tok != "" {
+		t.Errorf("expected warehouse token to be removed, got: %q", tok)
+	}
+	if tok := stripped.Integrations.PinningServices[0].Token; tok != "" {
+		t.Errorf("expected pinning service token to be removed, got: %q", tok)
+	}
+	if cfg.Integrations.Sinks[0].Password == "" {
+		t.Error("expected original config to keep private values")
+	}
+
+	restored := stripped.WithPrivateValues(cfg)
+	if !reflect.DeepEqual(cfg, restored) {
+		t.Errorf("expected restored config to match original.\nwant: %v\ngot:  %v", cfg, restored)
+	}
+}
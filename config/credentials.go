@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+)
+
+// Credentials maps remote names to authentication tokens. Tokens are
+// encrypted with a key from the keystore, see key.EncryptCredential.
+// Credentials are private values, and are removed by WithoutPrivateValues
+type Credentials map[string]string
+
+// SetArbitrary is for implementing the ArbitrarySetter interface defined by base/fill_struct.go
+func (c *Credentials) SetArbitrary(key string, val interface{}) (err error) {
+	str, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("invalid credential value: %s", val)
+	}
+	(*c)[key] = str
+	return nil
+}
+
+// Get retrieves the encrypted token for a remote name
+func (c *Credentials) Get(name string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	token, ok := (*c)[name]
+	return token, ok
+}
+
+// Copy creates a copy of a Credentials struct
+func (c *Credentials) Copy() *Credentials {
+	res := make(map[string]string)
+	for k, v := range *c {
+		res[k] = v
+	}
+	return (*Credentials)(&res)
+}
@@ -0,0 +1,408 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/qri-io/jsonschema"
+)
+
+const (
+	// SinkTypeNATS publishes to a NATS subject over the NATS protocol
+	SinkTypeNATS = "nats"
+	// SinkTypeKafkaREST publishes to a kafka topic through an HTTP Kafka REST
+	// Proxy. qri doesn't speak the kafka protocol, a proxy must be running
+	SinkTypeKafkaREST = "kafka-rest"
+	// SinkTypeRabbitMQHTTP publishes to an exchange through the RabbitMQ
+	// management HTTP API. qri doesn't speak AMQP, the API is meant for low
+	// volumes of messages
+	SinkTypeRabbitMQHTTP = "rabbitmq-http"
+
+	// SinkEventDatasetSaved fires when a new dataset version is saved
+	SinkEventDatasetSaved = "dataset:saved"
+	// SinkEventRunFailed fires when a transform run fails
+	SinkEventRunFailed = "run:failed"
+
+	// SinkFormatJSON serializes messages as plain JSON. This is the default
+	SinkFormatJSON = "json"
+	// SinkFormatCloudEvents serializes messages as CloudEvents 1.0 JSON
+	SinkFormatCloudEvents = "cloudevents"
+
+	// WarehouseTypePostgres syncs to a Postgres table through database/sql
+	WarehouseTypePostgres = "postgres"
+	// WarehouseTypeBigQuery syncs to a BigQuery table through the BigQuery REST
+	// API
+	WarehouseTypeBigQuery = "bigquery"
+
+	// WarehouseModeAuto appends new rows when a version only adds rows to the
+	// end of the previously synced body, and replaces the table otherwise. This
+	// is the default
+	WarehouseModeAuto = "auto"
+	// WarehouseModeReplace replaces the table on every sync
+	WarehouseModeReplace = "replace"
+	// WarehouseModeAppend always appends rows added since the last sync
+	WarehouseModeAppend = "append"
+)
+
+// Integrations configures connections between qri and external systems
+type Integrations struct {
+	// Sinks forward dataset events to message queues
+	Sinks []*Sink `json:"sinks,omitempty"`
+	// Warehouses mirror dataset bodies into SQL warehouse tables
+	Warehouses []*Warehouse `json:"warehouses,omitempty"`
+	// PinningServices pin published dataset versions to remote pinning
+	// services
+	PinningServices []*PinningService `json:"pinningservices,omitempty"`
+}
+
+// SetArbitrary is an interface implementation of base/fill/struct in order to safely
+// consume config files that have definitions beyond those specified in the struct.
+// This simply ignores all additional fields at read time.
+func (cfg *Integrations) SetArbitrary(key string, val interface{}) error {
+	return nil
+}
+
+// Sink configures forwarding of events to a message queue topic
+type Sink struct {
+	// Name identifies the sink
+	Name string `json:"name"`
+	// Type is one of "nats", "kafka-rest", or "rabbitmq-http". kafka-rest &
+	// rabbitmq-http are HTTP bridges, not kafka or AMQP clients
+	Type string `json:"type"`
+	// Address of the queue. A host:port pair for NATS, the URL of a Kafka REST
+	// Proxy for kafka-rest, or the URL of the RabbitMQ management API for
+	// rabbitmq-http
+	Address string `json:"address"`
+	// Topic is the NATS subject, kafka topic, or RabbitMQ exchange to publish
+	// to
+	Topic string `json:"topic"`
+	// VHost is the RabbitMQ virtual host, defaults to "/"
+	VHost    string `json:"vhost,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Events lists events to forward, defaults to all events
+	Events []string `json:"events,omitempty"`
+	// Format selects message serialization, either "json" or "cloudevents"
+	Format string `json:"format,omitempty"`
+	// Datasets lists dataset references the sink is enabled for, like
+	// "peer/dataset". Events for other datasets aren't forwarded
+	Datasets []string `json:"datasets,omitempty"`
+	// Disabled turns the sink off without removing its configuration
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Warehouse configures syncing dataset bodies to a SQL warehouse
+type Warehouse struct {
+	// Name identifies the warehouse
+	Name string `json:"name"`
+	// Type is one of "postgres" or "bigquery"
+	Type string `json:"type"`
+	// Address is a connection string for postgres. For bigquery it overrides
+	// the API base URL
+	Address string `json:"address,omitempty"`
+	// Driver is the database/sql driver name used to connect to postgres,
+	// defaults to "postgres", the lib/pq driver qri links. Other drivers must
+	// be linked into the qri binary
+	Driver string `json:"driver,omitempty"`
+	// Project & DatasetID locate tables in bigquery
+	Project   string `json:"project,omitempty"`
+	DatasetID string `json:"datasetID,omitempty"`
+	// Token is an OAuth access token for the bigquery API
+	Token string `json:"token,omitempty"`
+	// Table names the destination table. Defaults to the dataset name
+	Table string `json:"table,omitempty"`
+	// Mode is one of "auto", "replace", or "append"
+	Mode string `json:"mode,omitempty"`
+	// Datasets lists dataset references to sync, like "peer/dataset"
+	Datasets []string `json:"datasets,omitempty"`
+	// Disabled turns syncing off without removing the configuration
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// PinningService configures pinning published dataset versions to a service
+// that implements the IPFS pinning service API, like Pinata or web3.storage
+type PinningService struct {
+	// Name identifies the service
+	Name string `json:"name"`
+	// Endpoint is the base URL of the pinning service API, eg:
+	// "https://api.pinata.cloud/psa"
+	Endpoint string `json:"endpoint"`
+	// Token is the service access token, sent as a bearer token
+	Token string `json:"token,omitempty"`
+	// Datasets lists dataset references to pin, like "peer/dataset". Leave
+	// empty to pin every published dataset
+	Datasets []string `json:"datasets,omitempty"`
+	// Disabled turns pinning off without removing the configuration
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Validate validates all fields of integrations returning the first error
+// found
+func (cfg Integrations) Validate() error {
+	for _, s := range cfg.Sinks {
+		// earlier versions named the HTTP bridge sinks after the protocols they
+		// don't speak
+		switch s.Type {
+		case "kafka":
+			return fmt.Errorf("sink %q: type \"kafka\" is now %q, it publishes through a Kafka REST Proxy", s.Name, SinkTypeKafkaREST)
+		case "amqp":
+			return fmt.Errorf("sink %q: type \"amqp\" is now %q, it publishes through the RabbitMQ management HTTP API", s.Name, SinkTypeRabbitMQHTTP)
+		}
+	}
+
+	schema := jsonschema.Must(`{
+    "$schema": "http://json-schema.org/draft-06/schema#",
+    "title": "Integrations",
+    "description": "Config for connections to external systems",
+    "type": "object",
+    "properties": {
+      "sinks": {
+        "description": "Message queue topics to forward dataset events to",
+        "type": ["array", "null"],
+        "items": {
+          "type": "object",
+          "required": ["name", "type", "address", "topic"],
+          "properties": {
+            "name": { "type": "string", "minLength": 1 },
+            "type": { "type": "string", "enum": ["nats", "kafka-rest", "rabbitmq-http"] },
+            "address": { "type": "string", "minLength": 1 },
+            "topic": { "type": "string", "minLength": 1 },
+            "events": {
+              "type": ["array", "null"],
+              "items": { "type": "string", "enum": ["dataset:saved", "run:failed"] }
+            },
+            "format": { "type": "string", "enum": ["", "json", "cloudevents"] },
+            "datasets": {
+              "type": ["array", "null"],
+              "items": { "type": "string" }
+            }
+          }
+        }
+      },
+      "warehouses": {
+        "description": "SQL warehouses to mirror dataset bodies into",
+        "type": ["array", "null"],
+        "items": {
+          "type": "object",
+          "required": ["name", "type"],
+          "properties": {
+            "name": { "type": "string", "minLength": 1 },
+            "type": { "type": "string", "enum": ["postgres", "bigquery"] },
+            "mode": { "type": "string", "enum": ["", "auto", "replace", "append"] },
+            "datasets": {
+              "type": ["array", "null"],
+              "items": { "type": "string" }
+            }
+          }
+        }
+      },
+      "pinningservices": {
+        "description": "Remote pinning services to pin published dataset versions to",
+        "type": ["array", "null"],
+        "items": {
+          "type": "object",
+          "required": ["name", "endpoint"],
+          "properties": {
+            "name": { "type": "string", "minLength": 1 },
+            "endpoint": { "type": "string", "minLength": 1 },
+            "token": { "type": "string" },
+            "datasets": {
+              "type": ["array", "null"],
+              "items": { "type": "string" }
+            }
+          }
+        }
+      }
+    }
+  }`)
+	if err := validate(schema, &cfg); err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+	for _, s := range cfg.Sinks {
+		if names[s.Name] {
+			return fmt.Errorf("duplicate sink name %q", s.Name)
+		}
+		names[s.Name] = true
+	}
+
+	names = map[string]bool{}
+	for _, w := range cfg.Warehouses {
+		if names[w.Name] {
+			return fmt.Errorf("duplicate warehouse name %q", w.Name)
+		}
+		names[w.Name] = true
+		switch w.Type {
+		case WarehouseTypePostgres:
+			if w.Address == "" {
+				return fmt.Errorf("warehouse %q: postgres connection address is required", w.Name)
+			}
+		case WarehouseTypeBigQuery:
+			if w.Project == "" || w.DatasetID == "" {
+				return fmt.Errorf("warehouse %q: bigquery project and datasetID are required", w.Name)
+			}
+		}
+	}
+
+	names = map[string]bool{}
+	for _, ps := range cfg.PinningServices {
+		if names[ps.Name] {
+			return fmt.Errorf("duplicate pinning service name %q", ps.Name)
+		}
+		names[ps.Name] = true
+		if u, err := url.Parse(ps.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("pinning service %q: endpoint must be an absolute URL", ps.Name)
+		}
+	}
+	return nil
+}
+
+// Copy makes a deep copy of the Integrations struct
+func (cfg *Integrations) Copy() *Integrations {
+	res := &Integrations{}
+	if cfg.Sinks != nil {
+		res.Sinks = make([]*Sink, 0, len(cfg.Sinks))
+		for _, s := range cfg.Sinks {
+			res.Sinks = append(res.Sinks, s.Copy())
+		}
+	}
+	if cfg.Warehouses != nil {
+		res.Warehouses = make([]*Warehouse, 0, len(cfg.Warehouses))
+		for _, w := range cfg.Warehouses {
+			res.Warehouses = append(res.Warehouses, w.Copy())
+		}
+	}
+	if cfg.PinningServices != nil {
+		res.PinningServices = make([]*PinningService, 0, len(cfg.PinningServices))
+		for _, ps := range cfg.PinningServices {
+			res.PinningServices = append(res.PinningServices, ps.Copy())
+		}
+	}
+	return res
+}
+
+// stripPrivateValues removes secrets from integrations, in place
+func (cfg *Integrations) stripPrivateValues() {
+	for _, s := range cfg.Sinks {
+		s.Password = ""
+	}
+	for _, w := range cfg.Warehouses {
+		w.Token = ""
+	}
+	for _, ps := range cfg.PinningServices {
+		ps.Token = ""
+	}
+}
+
+// restorePrivateValues fills secrets that are missing from integrations with
+// values from integrations of the same name in p, in place
+func (cfg *Integrations) restorePrivateValues(p *Integrations) {
+	for _, s := range cfg.Sinks {
+		for _, prev := range p.Sinks {
+			if prev.Name == s.Name && s.Password == "" {
+				s.Password = prev.Password
+			}
+		}
+	}
+	for _, w := range cfg.Warehouses {
+		for _, prev := range p.Warehouses {
+			if prev.Name == w.Name && w.Token == "" {
+				w.Token = prev.Token
+			}
+		}
+	}
+	for _, ps := range cfg.PinningServices {
+		for _, prev := range p.PinningServices {
+			if prev.Name == ps.Name && ps.Token == "" {
+				ps.Token = prev.Token
+			}
+		}
+	}
+}
+
+// Copy makes a deep copy of a Sink
+func (s *Sink) Copy() *Sink {
+	res := *s
+	if s.Events != nil {
+		res.Events = append([]string{}, s.Events...)
+	}
+	if s.Datasets != nil {
+		res.Datasets = append([]string{}, s.Datasets...)
+	}
+	return &res
+}
+
+// WantsEvent returns true if the sink forwards an event
+func (s *Sink) WantsEvent(evt string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == evt {
+			return true
+		}
+	}
+	return false
+}
+
+// EnabledFor returns true if the sink is enabled for a dataset
+func (s *Sink) EnabledFor(alias string) bool {
+	if s.Disabled {
+		return false
+	}
+	for _, ds := range s.Datasets {
+		if ds == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy makes a deep copy of a Warehouse
+func (w *Warehouse) Copy() *Warehouse {
+	res := *w
+	if w.Datasets != nil {
+		res.Datasets = append([]string{}, w.Datasets...)
+	}
+	return &res
+}
+
+// EnabledFor returns true if the warehouse syncs a dataset
+func (w *Warehouse) EnabledFor(alias string) bool {
+	if w.Disabled {
+		return false
+	}
+	for _, ds := range w.Datasets {
+		if ds == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy makes a deep copy of a PinningService
+func (ps *PinningService) Copy() *PinningService {
+	res := *ps
+	if ps.Datasets != nil {
+		res.Datasets = append([]string{}, ps.Datasets...)
+	}
+	return &res
+}
+
+// EnabledFor returns true if the service pins a dataset. Services that don't
+// list datasets pin every dataset
+func (ps *PinningService) EnabledFor(alias string) bool {
+	if ps.Disabled {
+		return false
+	}
+	if len(ps.Datasets) == 0 {
+		return true
+	}
+	for _, ds := range ps.Datasets {
+		if ds == alias {
+			return true
+		}
+	}
+	return false
+}
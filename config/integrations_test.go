@@ -0,0 +1,98 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIntegrationsValidate(t *testing.T) {
+	valid := &Integrations{Sinks: []*Sink{
+		{Name: "events", Type: SinkTypeNATS, Address: "localhost:4222", Topic: "qri.events"},
+		{Name: "warehouse", Type: SinkTypeKafkaREST, Address: "http://localhost:8082", Topic: "qri", Events: []string{SinkEventDatasetSaved}, Format: SinkFormatCloudEvents},
+	}, Warehouses: []*Warehouse{
+		{Name: "pg", Type: WarehouseTypePostgres, Address: "postgres://localhost/qri", Mode: WarehouseModeAppend},
+		{Name: "bq", Type: WarehouseTypeBigQuery, Project: "proj", DatasetID: "qri"},
+	}, PinningServices: []*PinningService{
+		{Name: "pinata", Endpoint: "https://api.pinata.cloud/psa", Token: "secret"},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("unexpected error validating integrations: %s", err)
+	}
+
+	bad := []*Integrations{
+		{Sinks: []*Sink{{Name: "a", Type: "carrier_pigeon", Address: "roof", Topic: "seeds"}}},
+		{Sinks: []*Sink{{Name: "a", Type: SinkTypeNATS, Address: "localhost:4222"}}},
+		{Sinks: []*Sink{{Name: "a", Type: SinkTypeNATS, Address: "localhost:4222", Topic: "t", Events: []string{"dataset:exploded"}}}},
+		{Sinks: []*Sink{
+			{Name: "a", Type: SinkTypeNATS, Address: "localhost:4222", Topic: "t"},
+			{Name: "a", Type: SinkTypeRabbitMQHTTP, Address: "http://localhost:15672", Topic: "t"},
+		}},
+		{Sinks: []*Sink{{Name: "a", Type: "kafka", Address: "http://localhost:8082", Topic: "t"}}},
+	}
+	bad = append(bad,
+		&Integrations{Warehouses: []*Warehouse{{Name: "a", Type: "spreadsheet"}}},
+		&Integrations{Warehouses: []*Warehouse{{Name: "a", Type: WarehouseTypePostgres}}},
+		&Integrations{Warehouses: []*Warehouse{{Name: "a", Type: WarehouseTypeBigQuery, Project: "p"}}},
+		&Integrations{Warehouses: []*Warehouse{{Name: "a", Type: WarehouseTypePostgres, Address: "postgres://localhost", Mode: "upsert"}}},
+		&Integrations{PinningServices: []*PinningService{{Name: "a"}}},
+		&Integrations{PinningServices: []*PinningService{{Name: "a", Endpoint: "api.pinata.cloud"}}},
+		&Integrations{PinningServices: []*PinningService{
+			{Name: "a", Endpoint: "https://a.example.com"},
+			{Name: "a", Endpoint: "https://b.example.com"},
+		}},
+	)
+	for i, cfg := range bad {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("case %d: expected error, got nil", i)
+		}
+	}
+}
+
+func TestIntegrationsCopy(t *testing.T) {
+	cfg := &Integrations{Sinks: []*Sink{
+		{Name: "events", Type: SinkTypeNATS, Address: "localhost:4222", Topic: "qri.events", Datasets: []string{"peer/cities"}},
+	}, Warehouses: []*Warehouse{
+		{Name: "pg", Type: WarehouseTypePostgres, Address: "postgres://localhost/qri", Datasets: []string{"peer/cities"}},
+	}, PinningServices: []*PinningService{
+		{Name: "pinata", Endpoint: "https://api.pinata.cloud/psa", Datasets: []string{"peer/cities"}},
+	}}
+	cpy := cfg.Copy()
+	if !reflect.DeepEqual(cpy, cfg) {
+		t.Errorf("integrations copy mismatch. \ncopy: %v, \noriginal: %v", cpy, cfg)
+	}
+	cpy.Sinks[0].Datasets[0] = "peer/changed"
+	cpy.Warehouses[0].Datasets[0] = "peer/changed"
+	cpy.PinningServices[0].Datasets[0] = "peer/changed"
+	if cfg.Sinks[0].Datasets[0] != "peer/cities" || cfg.Warehouses[0].Datasets[0] != "peer/cities" || cfg.PinningServices[0].Datasets[0] != "peer/cities" {
+		t.Errorf("expected copy to not share dataset lists")
+	}
+}
+
+func TestSinkEnabledFor(t *testing.T) {
+	s := &Sink{Datasets: []string{"peer/cities"}, Events: []string{SinkEventRunFailed}}
+	if !s.EnabledFor("peer/cities") || s.EnabledFor("peer/movies") {
+		t.Errorf("expected sink to be enabled only for listed datasets")
+	}
+	if s.WantsEvent(SinkEventDatasetSaved) || !s.WantsEvent(SinkEventRunFailed) {
+		t.Errorf("expected sink to only want listed events")
+	}
+	s.Disabled = true
+	if s.EnabledFor("peer/cities") {
+		t.Errorf("expected disabled sink to be enabled for no datasets")
+	}
+}
+
+func TestPinningServiceEnabledFor(t *testing.T) {
+	ps := &PinningService{}
+	if !ps.EnabledFor("peer/cities") {
+		t.Errorf("expected service without listed datasets to pin every dataset")
+	}
+	ps.Datasets = []string{"peer/cities"}
+	if !ps.EnabledFor("peer/cities") || ps.EnabledFor("peer/movies") {
+		t.Errorf("expected service to pin only listed datasets")
+	}
+	ps.Disabled = true
+	if ps.EnabledFor("peer/cities") {
+		t.Errorf("expected disabled service to pin no datasets")
+	}
+}
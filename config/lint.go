@@ -0,0 +1,82 @@
+package config
+
+import (
+	"github.com/qri-io/jsonschema"
+)
+
+// Lint configures the checks run against datasets when they're saved & by
+// qri lint
+type Lint struct {
+	// Rules overrides the severity of lint rules, keyed by rule ID. Severity is
+	// one of "off", "info", "warning", or "error"
+	Rules map[string]string `json:"rules,omitempty"`
+	// Profiles are named sets of rule severities. A dataset that uses a
+	// profile has the profile's severities applied over Rules
+	Profiles map[string]map[string]string `json:"profiles,omitempty"`
+	// BlockSaves makes saves fail when linting finds error-level problems
+	BlockSaves bool `json:"blocksaves"`
+}
+
+// SetArbitrary is an interface implementation of base/fill/struct in order to safely
+// consume config files that have definitions beyond those specified in the struct.
+// This simply ignores all additional fields at read time.
+func (cfg *Lint) SetArbitrary(key string, val interface{}) error {
+	return nil
+}
+
+// Validate validates all fields of lint returning all errors found.
+func (cfg Lint) Validate() error {
+	schema := jsonschema.Must(`{
+    "$schema": "http://json-schema.org/draft-06/schema#",
+    "title": "Lint",
+    "description": "Config for dataset lint checks",
+    "type": "object",
+    "properties": {
+      "rules": {
+        "description": "severity of lint rules, keyed by rule ID",
+        "type": "object",
+        "additionalProperties": {
+          "type": "string",
+          "enum": ["off", "info", "warning", "error"]
+        }
+      },
+      "profiles": {
+        "description": "named sets of rule severities",
+        "type": "object",
+        "additionalProperties": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string",
+            "enum": ["off", "info", "warning", "error"]
+          }
+        }
+      },
+      "blocksaves": {
+        "description": "fail saves when linting finds errors",
+        "type": "boolean"
+      }
+    }
+  }`)
+	return validate(schema, &cfg)
+}
+
+// Copy returns a deep copy of the Lint struct
+func (cfg *Lint) Copy() *Lint {
+	res := &Lint{BlockSaves: cfg.BlockSaves}
+	if cfg.Rules != nil {
+		res.Rules = make(map[string]string, len(cfg.Rules))
+		for id, sev := range cfg.Rules {
+			res.Rules[id] = sev
+		}
+	}
+	if cfg.Profiles != nil {
+		res.Profiles = make(map[string]map[string]string, len(cfg.Profiles))
+		for name, rules := range cfg.Profiles {
+			res.Profiles[name] = make(map[string]string, len(rules))
+			for id, sev := range rules {
+				res.Profiles[name][id] = sev
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintValidate(t *testing.T) {
+	if err := (&Lint{Rules: map[string]string{"meta-title": "error"}}).Validate(); err != nil {
+		t.Errorf("error validating lint config: %s", err)
+	}
+	if err := (&Lint{Rules: map[string]string{"meta-title": "fatal"}}).Validate(); err == nil {
+		t.Error("expected invalid severity to error")
+	}
+	if err := (&Lint{Profiles: map[string]map[string]string{"strict": {"meta-title": "fatal"}}}).Validate(); err == nil {
+		t.Error("expected invalid profile severity to error")
+	}
+}
+
+func TestLintCopy(t *testing.T) {
+	cases := []struct {
+		lint *Lint
+	}{
+		{&Lint{}},
+		{&Lint{BlockSaves: true, Rules: map[string]string{"meta-license": "error"}}},
+		{&Lint{Rules: map[string]string{"meta-license": "info"}, Profiles: map[string]map[string]string{"strict": {"meta-license": "error"}}}},
+	}
+	for i, c := range cases {
+		cpy := c.lint.Copy()
+		if !reflect.DeepEqual(cpy, c.lint) {
+			t.Errorf("Lint Copy test case %v, lint structs are not equal: \ncopy: %v, \noriginal: %v", i, cpy, c.lint)
+			continue
+		}
+		cpy.BlockSaves = !cpy.BlockSaves
+		if reflect.DeepEqual(cpy, c.lint) {
+			t.Errorf("Lint Copy test case %v, editing one lint struct should not affect the other", i)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/qri-io/qri/base/fill"
+)
+
+// EnvConfigProfile is the environment variable that selects a configuration
+// profile. Configuration profiles are named overlays that are merged over the
+// base configuration at runtime, letting one repo run with different settings
+// (eg: "dev", "staging", "prod") without editing config.yaml
+const EnvConfigProfile = "QRI_CONFIG_PROFILE"
+
+var validOverlayName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// OverlayPath gives the location of a named configuration profile.
+// Profiles are stored in the repo directory alongside the base configuration,
+// as "config.[name].yaml"
+func OverlayPath(repoPath, name string) string {
+	return filepath.Join(repoPath, fmt.Sprintf("config.%s.yaml", name))
+}
+
+// ReadOverlay reads a named configuration profile overlay from a repo
+// directory. Profiles are partial YAML configurations, only values that differ
+// from the base configuration need to be set. eg: a profile containing
+// "api: {address: /ip4/127.0.0.1/tcp/2504}" only changes the API address
+func ReadOverlay(repoPath, name string) (map[string]interface{}, error) {
+	if !validOverlayName.MatchString(name) {
+		return nil, fmt.Errorf("invalid config profile name %q. names may only contain letters, numbers, dashes & underscores", name)
+	}
+	data, err := ioutil.ReadFile(OverlayPath(repoPath, name))
+	if err != nil {
+		return nil, fmt.Errorf("reading config profile %q: %w", name, err)
+	}
+
+	overlay := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("reading config profile %q: %w", name, err)
+	}
+	return overlay, nil
+}
+
+// WithOverlay returns a copy of the configuration with a profile
+// overlay merged over it. Objects are merged recursively, all other values in
+// the overlay replace values in the base configuration. Field names are
+// matched case-insensitively
+func (cfg *Config) WithOverlay(name string, overlay map[string]interface{}) (*Config, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	mergeFields(fields, overlay)
+
+	res := &Config{path: cfg.path, overlay: name}
+	if err := fill.Struct(fields, res); err != nil {
+		return nil, fmt.Errorf("applying config profile %q: %w", name, err)
+	}
+	return res, nil
+}
+
+// Overlay returns the name of the configuration profile merged into this
+// configuration, if any
+func (cfg Config) Overlay() string {
+	return cfg.overlay
+}
+
+// mergeFields recursively merges src into dst
+func mergeFields(dst, src map[string]interface{}) {
+	for key, val := range src {
+		dstKey := key
+		for k := range dst {
+			if strings.EqualFold(k, key) {
+				dstKey = k
+				break
+			}
+		}
+
+		srcMap, srcIsMap := val.(map[string]interface{})
+		dstMap, dstIsMap := dst[dstKey].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeFields(dstMap, srcMap)
+			continue
+		}
+		dst[dstKey] = val
+	}
+}
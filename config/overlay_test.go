@@ -0,0 +1,57 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	testcfg "github.com/qri-io/qri/config/test"
+)
+
+func TestWithOverlay(t *testing.T) {
+	base := testcfg.DefaultConfigForTesting()
+	baseAddress := base.API.Address
+
+	overlay, err := config.ReadOverlay("testdata/overlay", "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := base.WithOverlay("dev", overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Overlay() != "dev" {
+		t.Errorf("expected overlay name to be %q, got %q", "dev", cfg.Overlay())
+	}
+	if expect := "/ip4/127.0.0.1/tcp/2504"; cfg.API.Address != expect {
+		t.Errorf("expected API address to be overridden. want %q, got %q", expect, cfg.API.Address)
+	}
+	if cfg.API.Enabled != base.API.Enabled {
+		t.Errorf("expected values not in the overlay to be kept")
+	}
+	if addr, ok := cfg.Remotes.Get("staging"); !ok || addr != "/ip4/127.0.0.1/tcp/3000" {
+		t.Errorf("expected overlay to add a staging remote, got: %v", cfg.Remotes)
+	}
+	if cfg.Logging.Levels["qriapi"] != "debug" {
+		t.Errorf("expected qriapi log level to be debug, got %q", cfg.Logging.Levels["qriapi"])
+	}
+	for name, lvl := range base.Logging.Levels {
+		if name != "qriapi" && cfg.Logging.Levels[name] != lvl {
+			t.Errorf("expected %s log level to be kept. want %q, got %q", name, lvl, cfg.Logging.Levels[name])
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected merged config to be valid: %s", err)
+	}
+
+	if base.API.Address != baseAddress || base.Overlay() != "" {
+		t.Errorf("expected base config to be unmodified")
+	}
+
+	if _, err := config.ReadOverlay("testdata/overlay", "missing"); err == nil {
+		t.Error("expected reading a missing profile to error")
+	}
+	if _, err := config.ReadOverlay("testdata/overlay", "../overlay/config.dev"); err == nil {
+		t.Error("expected invalid profile name to error")
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	peer "github.com/libp2p/go-libp2p-core/peer"
+	pnet "github.com/libp2p/go-libp2p-core/pnet"
 	ma "github.com/multiformats/go-multiaddr"
 
 	"github.com/qri-io/jsonschema"
@@ -38,8 +41,70 @@ type P2P struct {
 
 	// Enable AutoNAT service. unless you're hosting a server, leave this as false
 	AutoNAT bool `json:"autoNAT"`
+
+	// SwarmKey is a pre-shared key that restricts this node to a private
+	// network, in the same format as an IPFS swarm.key file. Nodes with a
+	// swarm key only connect to peers that share the key, and only bootstrap
+	// from the addresses listed in BootstrapAddrs
+	SwarmKey string `json:"swarmkey,omitempty"`
+
+	// ConnMgrLowWater is the number of peer connections the connection manager
+	// trims down to. zero uses the p2p package default
+	ConnMgrLowWater int `json:"connmgrlowwater,omitempty"`
+	// ConnMgrHighWater is the number of peer connections that triggers a trim.
+	// zero uses the p2p package default
+	ConnMgrHighWater int `json:"connmgrhighwater,omitempty"`
+
+	// BandwidthPeerCap limits the bytes per second transferred to & from each
+	// peer when serving & fetching dataset data. zero is unlimited
+	BandwidthPeerCap int64 `json:"bandwidthpeercap,omitempty"`
+	// BandwidthProtocolCaps limits the bytes per second transferred over a
+	// protocol across all peers, keyed by libp2p protocol ID or remote HTTP
+	// path, eg: "/remote/dsync". zero is unlimited
+	BandwidthProtocolCaps map[string]int64 `json:"bandwidthprotocolcaps,omitempty"`
+
+	// LocalPeerTrust sets how peers discovered on the local network are trusted
+	// to exchange logbook data. One of "prompt", "auto" or "manual". empty
+	// means "prompt"
+	LocalPeerTrust string `json:"localpeertrust,omitempty"`
+	// TrustedPeers lists the peer IDs of local peers trusted to exchange
+	// logbook data
+	TrustedPeers []string `json:"trustedpeers,omitempty"`
+
+	// MailboxTTL is how long messages to unreachable peers are queued before
+	// they're dropped, as a duration string like "72h". Empty uses
+	// DefaultMailboxTTL
+	MailboxTTL string `json:"mailboxttl,omitempty"`
+	// MailboxMaxMessages limits the number of messages queued for each peer.
+	// zero uses the p2p package default
+	MailboxMaxMessages int `json:"mailboxmaxmessages,omitempty"`
+	// MailboxMaxMessageSize limits the size of a queued message body in bytes.
+	// zero uses the p2p package default
+	MailboxMaxMessageSize int `json:"mailboxmaxmessagesize,omitempty"`
+	// MailboxRelay is the multiaddress of a peer, usually a remote, that holds
+	// messages for this node & the peers it messages while they're offline,
+	// eg: "/ip4/1.2.3.4/tcp/4001/ipfs/QmPeerID"
+	MailboxRelay string `json:"mailboxrelay,omitempty"`
+	// MailboxHost lets peers leave messages on this node for other peers,
+	// delivering them when the recipient connects
+	MailboxHost bool `json:"mailboxhost,omitempty"`
 }
 
+// DefaultMailboxTTL is how long messages to unreachable peers are queued when
+// the p2p config doesn't set a TTL
+const DefaultMailboxTTL = time.Hour * 72
+
+const (
+	// LocalPeerTrustPrompt asks before trusting each peer discovered on the
+	// local network
+	LocalPeerTrustPrompt = "prompt"
+	// LocalPeerTrustAuto trusts every peer discovered on the local network
+	LocalPeerTrustAuto = "auto"
+	// LocalPeerTrustManual only trusts local peers listed in TrustedPeers,
+	// without asking
+	LocalPeerTrustManual = "manual"
+)
+
 // SetArbitrary is an interface implementation of base/fill/struct in order to safely
 // consume config files that have definitions beyond those specified in the struct.
 // This simply ignores all additional fields at read time.
@@ -93,6 +158,25 @@ func (cfg *P2P) DecodePeerID() (peer.ID, error) {
 	return peer.IDB58Decode(cfg.PeerID)
 }
 
+// PrivateNetwork returns true if this node is configured to only communicate
+// with peers that share a swarm key
+func (cfg *P2P) PrivateNetwork() bool {
+	return cfg.SwarmKey != ""
+}
+
+// DecodeSwarmKey parses SwarmKey into a pre-shared key. returns a nil key if
+// no swarm key is configured
+func (cfg *P2P) DecodeSwarmKey() (pnet.PSK, error) {
+	if cfg.SwarmKey == "" {
+		return nil, nil
+	}
+	psk, err := pnet.DecodeV1PSK(strings.NewReader(cfg.SwarmKey))
+	if err != nil {
+		return nil, fmt.Errorf("decoding swarm key: %w", err)
+	}
+	return psk, nil
+}
+
 // Validate validates all fields of p2p returning all errors found.
 func (cfg P2P) Validate() error {
 	schema := jsonschema.Must(`{
@@ -144,19 +228,134 @@ func (cfg P2P) Validate() error {
         "items": {
           "type": "string"
         }
+      },
+      "swarmkey": {
+        "description": "Pre-shared key that restricts this node to a private network",
+        "type": "string"
+      },
+      "connmgrlowwater": {
+        "description": "Number of peer connections the connection manager trims down to",
+        "type": "integer",
+        "minimum": 0
+      },
+      "connmgrhighwater": {
+        "description": "Number of peer connections that triggers the connection manager to trim",
+        "type": "integer",
+        "minimum": 0
+      },
+      "bandwidthpeercap": {
+        "description": "Bytes per second transferred to & from each peer, zero is unlimited",
+        "type": "integer",
+        "minimum": 0
+      },
+      "bandwidthprotocolcaps": {
+        "description": "Bytes per second transferred over each protocol, keyed by protocol",
+        "type": "object",
+        "additionalProperties": {
+          "type": "integer",
+          "minimum": 0
+        }
+      },
+      "localpeertrust": {
+        "description": "How peers discovered on the local network are trusted to exchange logbook data",
+        "type": "string",
+        "enum": ["", "prompt", "auto", "manual"]
+      },
+      "trustedpeers": {
+        "description": "Peer IDs of local peers trusted to exchange logbook data",
+        "anyOf": [
+          {"type": "array"},
+          {"type": "null"}
+        ],
+        "items": {
+          "type": "string"
+        }
+      },
+      "mailboxttl": {
+        "description": "How long messages to unreachable peers are queued, as a duration like \"72h\"",
+        "type": "string"
+      },
+      "mailboxmaxmessages": {
+        "description": "Number of messages queued for each unreachable peer",
+        "type": "integer",
+        "minimum": 0
+      },
+      "mailboxmaxmessagesize": {
+        "description": "Size of a queued message body in bytes",
+        "type": "integer",
+        "minimum": 0
+      },
+      "mailboxrelay": {
+        "description": "Multiaddress of a peer that holds messages while peers are offline",
+        "type": "string"
+      },
+      "mailboxhost": {
+        "description": "When true, peers may leave messages on this node for other peers",
+        "type": "boolean"
       }
     }
   }`)
-	return validate(schema, &cfg)
+	if err := validate(schema, &cfg); err != nil {
+		return err
+	}
+	if _, err := cfg.DecodeSwarmKey(); err != nil {
+		return err
+	}
+	if cfg.ConnMgrHighWater != 0 && cfg.ConnMgrHighWater < cfg.ConnMgrLowWater {
+		return fmt.Errorf("connmgrhighwater (%d) must be greater than connmgrlowwater (%d)", cfg.ConnMgrHighWater, cfg.ConnMgrLowWater)
+	}
+	if _, err := cfg.MailboxTTLDuration(); err != nil {
+		return err
+	}
+	if cfg.MailboxRelay != "" {
+		if _, err := ma.NewMultiaddr(cfg.MailboxRelay); err != nil {
+			return fmt.Errorf("invalid mailbox relay address %q: %w", cfg.MailboxRelay, err)
+		}
+	}
+	return nil
+}
+
+// MailboxTTLDuration parses MailboxTTL
+func (cfg *P2P) MailboxTTLDuration() (time.Duration, error) {
+	if cfg.MailboxTTL == "" {
+		return DefaultMailboxTTL, nil
+	}
+	d, err := time.ParseDuration(cfg.MailboxTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mailbox ttl %q: %w", cfg.MailboxTTL, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid mailbox ttl %q: must be positive", cfg.MailboxTTL)
+	}
+	return d, nil
 }
 
 // Copy returns a deep copy of a p2p struct
 func (cfg *P2P) Copy() *P2P {
 	res := &P2P{
-		Enabled: cfg.Enabled,
-		PeerID:  cfg.PeerID,
-		PrivKey: cfg.PrivKey,
-		Port:    cfg.Port,
+		Enabled:  cfg.Enabled,
+		PeerID:   cfg.PeerID,
+		PrivKey:  cfg.PrivKey,
+		Port:     cfg.Port,
+		SwarmKey: cfg.SwarmKey,
+
+		ConnMgrLowWater:  cfg.ConnMgrLowWater,
+		ConnMgrHighWater: cfg.ConnMgrHighWater,
+		BandwidthPeerCap: cfg.BandwidthPeerCap,
+		LocalPeerTrust:   cfg.LocalPeerTrust,
+
+		MailboxTTL:            cfg.MailboxTTL,
+		MailboxMaxMessages:    cfg.MailboxMaxMessages,
+		MailboxMaxMessageSize: cfg.MailboxMaxMessageSize,
+		MailboxRelay:          cfg.MailboxRelay,
+		MailboxHost:           cfg.MailboxHost,
+	}
+
+	if cfg.BandwidthProtocolCaps != nil {
+		res.BandwidthProtocolCaps = make(map[string]int64, len(cfg.BandwidthProtocolCaps))
+		for proto, limit := range cfg.BandwidthProtocolCaps {
+			res.BandwidthProtocolCaps[proto] = limit
+		}
 	}
 
 	if cfg.QriBootstrapAddrs != nil {
@@ -169,5 +368,10 @@ func (cfg *P2P) Copy() *P2P {
 		reflect.Copy(reflect.ValueOf(res.BootstrapAddrs), reflect.ValueOf(cfg.BootstrapAddrs))
 	}
 
+	if cfg.TrustedPeers != nil {
+		res.TrustedPeers = make([]string, len(cfg.TrustedPeers))
+		copy(res.TrustedPeers, cfg.TrustedPeers)
+	}
+
 	return res
 }
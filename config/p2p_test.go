@@ -2,7 +2,9 @@ package config_test
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/qri-io/qri/config"
 	testcfg "github.com/qri-io/qri/config/test"
@@ -39,6 +41,56 @@ func TestP2PValidate(t *testing.T) {
 	if err != nil {
 		t.Errorf("error validating default p2p: %s", err)
 	}
+
+	p := testcfg.DefaultP2PForTesting()
+	p.ConnMgrLowWater = 100
+	p.ConnMgrHighWater = 50
+	if err := p.Validate(); err == nil {
+		t.Error("expected high water below low water to error")
+	}
+
+	p = testcfg.DefaultP2PForTesting()
+	p.BandwidthProtocolCaps = map[string]int64{"/remote/dsync": -1}
+	if err := p.Validate(); err == nil {
+		t.Error("expected a negative bandwidth cap to error")
+	}
+
+	p = testcfg.DefaultP2PForTesting()
+	p.LocalPeerTrust = "sometimes"
+	if err := p.Validate(); err == nil {
+		t.Error("expected an unknown local peer trust policy to error")
+	}
+
+	p = testcfg.DefaultP2PForTesting()
+	p.MailboxTTL = "-1h"
+	if err := p.Validate(); err == nil {
+		t.Error("expected a negative mailbox ttl to error")
+	}
+
+	p = testcfg.DefaultP2PForTesting()
+	p.MailboxRelay = "not a multiaddr"
+	if err := p.Validate(); err == nil {
+		t.Error("expected an invalid mailbox relay address to error")
+	}
+}
+
+func TestP2PMailboxTTLDuration(t *testing.T) {
+	p := testcfg.DefaultP2PForTesting()
+	d, err := p.MailboxTTLDuration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != config.DefaultMailboxTTL {
+		t.Errorf("expected empty ttl to use the default. got: %s", d)
+	}
+
+	p.MailboxTTL = "90m"
+	if d, err = p.MailboxTTLDuration(); err != nil {
+		t.Fatal(err)
+	}
+	if d != time.Minute*90 {
+		t.Errorf("expected 90m ttl. got: %s", d)
+	}
 }
 
 func TestP2PCopy(t *testing.T) {
@@ -46,6 +98,20 @@ func TestP2PCopy(t *testing.T) {
 		p2p *config.P2P
 	}{
 		{testcfg.DefaultP2PForTesting()},
+		{func() *config.P2P {
+			p := testcfg.DefaultP2PForTesting()
+			p.LocalPeerTrust = config.LocalPeerTrustManual
+			p.TrustedPeers = []string{"QmTrusted"}
+			return p
+		}()},
+		{func() *config.P2P {
+			p := testcfg.DefaultP2PForTesting()
+			p.MailboxTTL = "24h"
+			p.MailboxMaxMessages = 10
+			p.MailboxRelay = "/ip4/127.0.0.1/tcp/4001"
+			p.MailboxHost = true
+			return p
+		}()},
 	}
 	for i, c := range cases {
 		cpy := c.p2p.Copy()
@@ -60,3 +126,36 @@ func TestP2PCopy(t *testing.T) {
 		}
 	}
 }
+
+func TestP2PDecodeSwarmKey(t *testing.T) {
+	p := testcfg.DefaultP2PForTesting()
+	psk, err := p.DecodeSwarmKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if psk != nil || p.PrivateNetwork() {
+		t.Errorf("expected default config to not use a private network")
+	}
+
+	p.SwarmKey = "/key/swarm/psk/1.0.0/\n/base16/\n" + strings.Repeat("0f", 32)
+	if psk, err = p.DecodeSwarmKey(); err != nil {
+		t.Fatal(err)
+	}
+	if len(psk) != 32 {
+		t.Errorf("expected a 32 byte key, got %d bytes", len(psk))
+	}
+	if !p.PrivateNetwork() {
+		t.Errorf("expected config with a swarm key to use a private network")
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected validation error: %s", err)
+	}
+
+	p.SwarmKey = "not a swarm key"
+	if _, err := p.DecodeSwarmKey(); err == nil {
+		t.Error("expected invalid swarm key to error")
+	}
+	if err := p.Validate(); err == nil {
+		t.Error("expected invalid swarm key to fail validation")
+	}
+}
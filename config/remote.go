@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
+	"path"
 	"time"
 
 	"github.com/qri-io/jsonschema"
@@ -19,6 +22,31 @@ type Remote struct {
 	RequireAllBlocks bool `json:"requireallblocks"`
 	// allow clients to request unpins for their own pushes
 	AllowRemoves bool `json:"allowremoves"`
+
+	// PullThrough makes the remote act as a caching mirror. When a client
+	// requests a dataset the remote doesn't have, the remote pulls it from
+	// PullThroughOrigin, pins it, and serves it
+	PullThrough bool `json:"pullthrough,omitempty"`
+	// PullThroughOrigin is the remote name or address to pull datasets from.
+	// The empty string pulls from the configured registry
+	PullThroughOrigin string `json:"pullthroughorigin,omitempty"`
+	// CacheSizeMax caps the total size in bytes of datasets pulled through the
+	// cache. When exceeded, the least-recently-used datasets are evicted. Zero
+	// or less means no limit
+	CacheSizeMax int64 `json:"cachesizemax,omitempty"`
+	// CacheAllow is a list of patterns datasets must match to be pulled
+	// through, matched against "username/name" with path.Match syntax, eg:
+	// "b5/*" or "*/world_bank_*". An empty list allows all datasets
+	CacheAllow []string `json:"cacheallow,omitempty"`
+
+	// UsageStats opts in to recording which datasets clients pull: pull counts,
+	// unique peers & bytes served per dataset version. Dataset owners can
+	// request usage stats for their datasets
+	UsageStats bool `json:"usagestats,omitempty"`
+
+	// Webhooks are URLs notified with a POST request after each accepted
+	// push. Webhooks run in the background, pushers can check their status
+	Webhooks []string `json:"webhooks,omitempty"`
 }
 
 // SetArbitrary is an interface implementation of base/fill/struct in order to safely
@@ -30,6 +58,17 @@ func (cfg *Remote) SetArbitrary(key string, val interface{}) error {
 
 // Validate validates all fields of render returning all errors found.
 func (cfg Remote) Validate() error {
+	for _, u := range cfg.Webhooks {
+		if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fmt.Errorf("invalid webhook url %q: must be an http or https URL", u)
+		}
+	}
+	for _, pattern := range cfg.CacheAllow {
+		if err := validMatchPattern(pattern); err != nil {
+			return fmt.Errorf("invalid cacheallow pattern %q: %w", pattern, err)
+		}
+	}
+
 	schema := jsonschema.Must(`{
     "$schema": "http://json-schema.org/draft-06/schema#",
     "title": "Remote",
@@ -43,12 +82,88 @@ func (cfg Remote) Validate() error {
       "defaultTemplateHash": {
         "description": "A hash of the compiled render. This is fetched and replaced via dsnlink when the render server starts. The value provided here is just a sensible fallback for when dnslink lookup fails.",
         "type": "string"
+      },
+      "pullthrough": {
+        "description": "act as a caching mirror, pulling requested datasets from an origin",
+        "type": "boolean"
+      },
+      "pullthroughorigin": {
+        "description": "remote name or address to pull datasets from, defaults to the registry",
+        "type": "string"
+      },
+      "cachesizemax": {
+        "description": "maximum total size in bytes of pulled-through datasets",
+        "type": "integer"
+      },
+      "cacheallow": {
+        "description": "patterns datasets must match to be pulled through",
+        "type": "array",
+        "items": {
+          "type": "string"
+        }
+      },
+      "usagestats": {
+        "description": "record pull counts, unique peers & bytes served for each dataset",
+        "type": "boolean"
+      },
+      "webhooks": {
+        "description": "URLs notified after each accepted push",
+        "type": "array",
+        "items": {
+          "type": "string"
+        }
       }
     }
   }`)
 	return validate(schema, &cfg)
 }
 
+// validMatchPattern checks the syntax of a path.Match pattern. path.Match
+// only reports ErrBadPattern for the parts of a pattern it reaches while
+// matching a name, so the whole pattern is checked here
+func validMatchPattern(pattern string) error {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if i++; i == len(pattern) {
+				return path.ErrBadPattern
+			}
+		case '[':
+			i++
+			if i < len(pattern) && pattern[i] == '^' {
+				i++
+			}
+			// a character class holds at least one character or range
+			for n := 0; i == len(pattern) || pattern[i] != ']' || n == 0; n++ {
+				var err error
+				if i, err = classChar(pattern, i); err != nil {
+					return err
+				}
+				if i < len(pattern) && pattern[i] == '-' {
+					if i, err = classChar(pattern, i+1); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// classChar checks the possibly escaped character class character at index i
+// of pattern, returning the index that follows it
+func classChar(pattern string, i int) (int, error) {
+	if i == len(pattern) || pattern[i] == '-' || pattern[i] == ']' {
+		return i, path.ErrBadPattern
+	}
+	if pattern[i] == '\\' {
+		if i++; i == len(pattern) {
+			return i, path.ErrBadPattern
+		}
+	}
+	return i + 1, nil
+}
+
 // Copy returns a deep copy of the Remote struct
 func (cfg *Remote) Copy() *Remote {
 	res := &Remote{
@@ -57,6 +172,20 @@ func (cfg *Remote) Copy() *Remote {
 		AcceptTimeoutMs:  cfg.AcceptTimeoutMs,
 		RequireAllBlocks: cfg.RequireAllBlocks,
 		AllowRemoves:     cfg.AllowRemoves,
+
+		PullThrough:       cfg.PullThrough,
+		PullThroughOrigin: cfg.PullThroughOrigin,
+		CacheSizeMax:      cfg.CacheSizeMax,
+
+		UsageStats: cfg.UsageStats,
+	}
+	if cfg.CacheAllow != nil {
+		res.CacheAllow = make([]string, len(cfg.CacheAllow))
+		copy(res.CacheAllow, cfg.CacheAllow)
+	}
+	if cfg.Webhooks != nil {
+		res.Webhooks = make([]string, len(cfg.Webhooks))
+		copy(res.Webhooks, cfg.Webhooks)
 	}
 
 	return res
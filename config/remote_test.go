@@ -11,6 +11,24 @@ func TestRemoteValidate(t *testing.T) {
 	if err != nil {
 		t.Errorf("error validating default remote: %s", err)
 	}
+
+	for _, pattern := range []string{"b5/*", "b5/[a-c]*", "b5/[^x]?", "b5/\\*", "*/[\\]]"} {
+		rem = &Remote{PullThrough: true, CacheAllow: []string{pattern}}
+		if err := rem.Validate(); err != nil {
+			t.Errorf("expected cacheallow pattern %q to be valid, got: %s", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"[bad", "b5/[bad", "b5/\\", "b5/[]", "b5/[^]", "b5/[a-]", "b5/[-a]"} {
+		rem = &Remote{PullThrough: true, CacheAllow: []string{"b5/*", pattern}}
+		if err := rem.Validate(); err == nil {
+			t.Errorf("expected invalid cacheallow pattern %q to error", pattern)
+		}
+	}
+
+	rem = &Remote{Webhooks: []string{"https://example.com/hook", "ftp://example.com"}}
+	if err := rem.Validate(); err == nil {
+		t.Error("expected non-http webhook to error")
+	}
 }
 
 func TestRemoteCopy(t *testing.T) {
@@ -18,6 +36,9 @@ func TestRemoteCopy(t *testing.T) {
 		remote *Remote
 	}{
 		{&Remote{}},
+		{&Remote{PullThrough: true, CacheSizeMax: 1024, CacheAllow: []string{"b5/*"}}},
+		{&Remote{UsageStats: true}},
+		{&Remote{Webhooks: []string{"https://example.com/hook"}}},
 	}
 	for i, c := range cases {
 		cpy := c.remote.Copy()
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/qri-io/jsonschema"
 )
 
@@ -8,8 +11,28 @@ import (
 type Repo struct {
 	Type string `json:"type"`
 	Path string `json:"path,omitempty"`
+	// OpenTimeouts sets how long to wait when opening dataset files, keyed by
+	// filesystem type (eg: "ipfs", "local"). Values are duration strings like
+	// "700ms". A value of "-1" disables the timeout for that filesystem.
+	// filesystem types without an entry use the default timeout
+	OpenTimeouts map[string]string `json:"opentimeouts,omitempty"`
+	// RecordEnvironment records the qri version, transform runtime, platform &
+	// transform resource versions in the commit of every saved version
+	RecordEnvironment bool `json:"recordenvironment,omitempty"`
+	// Backups is the number of rotated copies of the logbook & dscache files
+	// to keep for recovering from partial writes. 0 keeps the default number
+	// of copies, -1 keeps none
+	Backups int `json:"backups,omitempty"`
+	// TrashGracePeriod is how long removed datasets are kept in the trash
+	// before their stored versions are purged, as a duration string like
+	// "72h". Empty uses DefaultTrashGracePeriod, "-1" disables the trash
+	TrashGracePeriod string `json:"trashgraceperiod,omitempty"`
 }
 
+// DefaultTrashGracePeriod is how long removed datasets are kept in the trash
+// when the repo config doesn't set a grace period
+const DefaultTrashGracePeriod = time.Hour * 24 * 7
+
 // SetArbitrary is an interface implementation of base/fill/struct in order to safely
 // consume config files that have definitions beyond those specified in the struct.
 // This simply ignores all additional fields at read time.
@@ -40,16 +63,90 @@ func (cfg Repo) Validate() error {
           "fs",
           "mem"
         ]
+      },
+      "opentimeouts": {
+        "description": "Per-filesystem timeouts for opening dataset files",
+        "type": "object",
+        "additionalProperties": { "type": "string" }
+      },
+      "recordenvironment": {
+        "description": "Record the environment of each save in the commit",
+        "type": "boolean"
+      },
+      "backups": {
+        "description": "Number of logbook & dscache backups to keep, -1 keeps none",
+        "type": "integer",
+        "minimum": -1
+      },
+      "trashgraceperiod": {
+        "description": "How long removed datasets are kept in the trash, -1 disables the trash",
+        "type": "string"
       }
     }
   }`)
-	return validate(schema, &cfg)
+	if err := validate(schema, &cfg); err != nil {
+		return err
+	}
+	if _, err := cfg.OpenTimeoutDurations(); err != nil {
+		return err
+	}
+	_, err := cfg.TrashGracePeriodDuration()
+	return err
+}
+
+// TrashGracePeriodDuration parses TrashGracePeriod. "-1" parses to a negative
+// duration, which disables the trash
+func (cfg *Repo) TrashGracePeriodDuration() (time.Duration, error) {
+	switch cfg.TrashGracePeriod {
+	case "":
+		return DefaultTrashGracePeriod, nil
+	case "-1":
+		return -1, nil
+	}
+	d, err := time.ParseDuration(cfg.TrashGracePeriod)
+	if err != nil {
+		return 0, fmt.Errorf("invalid trash grace period %q: %w", cfg.TrashGracePeriod, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid trash grace period %q: use \"-1\" to disable the trash", cfg.TrashGracePeriod)
+	}
+	return d, nil
+}
+
+// OpenTimeoutDurations parses OpenTimeouts into durations. "-1" parses to a
+// negative duration, which disables the timeout
+func (cfg *Repo) OpenTimeoutDurations() (map[string]time.Duration, error) {
+	if len(cfg.OpenTimeouts) == 0 {
+		return nil, nil
+	}
+	res := make(map[string]time.Duration, len(cfg.OpenTimeouts))
+	for fsType, str := range cfg.OpenTimeouts {
+		if str == "-1" {
+			res[fsType] = -1
+			continue
+		}
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q open timeout %q: %w", fsType, str, err)
+		}
+		res[fsType] = d
+	}
+	return res, nil
 }
 
 // Copy returns a deep copy of the Repo struct
 func (cfg *Repo) Copy() *Repo {
 	res := &Repo{
-		Type: cfg.Type,
+		Type:              cfg.Type,
+		RecordEnvironment: cfg.RecordEnvironment,
+		Backups:           cfg.Backups,
+		TrashGracePeriod:  cfg.TrashGracePeriod,
+	}
+	if cfg.OpenTimeouts != nil {
+		res.OpenTimeouts = make(map[string]string, len(cfg.OpenTimeouts))
+		for k, v := range cfg.OpenTimeouts {
+			res.OpenTimeouts[k] = v
+		}
 	}
 
 	return res
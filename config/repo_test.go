@@ -3,6 +3,7 @@ package config
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestRepoValidate(t *testing.T) {
@@ -12,6 +13,67 @@ func TestRepoValidate(t *testing.T) {
 	}
 }
 
+func TestRepoOpenTimeoutDurations(t *testing.T) {
+	r := DefaultRepo()
+	r.OpenTimeouts = map[string]string{
+		"ipfs":  "2s",
+		"local": "-1",
+	}
+	if err := r.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.OpenTimeoutDurations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]time.Duration{
+		"ipfs":  time.Second * 2,
+		"local": -1,
+	}
+	if !reflect.DeepEqual(expect, got) {
+		t.Errorf("result mismatch. want: %v got: %v", expect, got)
+	}
+
+	r.OpenTimeouts["http"] = "soon"
+	if err := r.Validate(); err == nil {
+		t.Error("expected invalid duration to fail validation")
+	}
+}
+
+func TestRepoTrashGracePeriodDuration(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect time.Duration
+	}{
+		{"", DefaultTrashGracePeriod},
+		{"-1", -1},
+		{"72h", time.Hour * 72},
+		{"0s", 0},
+	}
+	for _, c := range cases {
+		r := &Repo{Type: "fs", TrashGracePeriod: c.in}
+		if err := r.Validate(); err != nil {
+			t.Errorf("%q: unexpected validation error: %s", c.in, err)
+			continue
+		}
+		got, err := r.TrashGracePeriodDuration()
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", c.in, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("%q: duration mismatch. want: %s got: %s", c.in, c.expect, got)
+		}
+	}
+
+	for _, bad := range []string{"a week", "-2h"} {
+		r := &Repo{Type: "fs", TrashGracePeriod: bad}
+		if err := r.Validate(); err == nil {
+			t.Errorf("%q: expected validation to fail", bad)
+		}
+	}
+}
+
 func TestRepoCopy(t *testing.T) {
 	// build off DefaultRepo so we can test that the repo Copy
 	// actually copies over correctly (ie, deeply)
@@ -21,6 +83,9 @@ func TestRepoCopy(t *testing.T) {
 		repo *Repo
 	}{
 		{r},
+		{&Repo{Type: "fs", RecordEnvironment: true}},
+		{&Repo{Type: "fs", Backups: 5}},
+		{&Repo{Type: "fs", TrashGracePeriod: "24h"}},
 	}
 	for i, c := range cases {
 		cpy := c.repo.Copy()
@@ -7,6 +7,14 @@ import (
 // Stats configures qri statistical metadata calculation
 type Stats struct {
 	Cache cache `json:"cache"`
+	// Inference selects how schemas are inferred from new bodies. nil uses the
+	// default of reading a prefix of the body
+	Inference *Inference `json:"inference,omitempty"`
+	// Binning selects how numeric columns are binned into histograms & caps
+	// frequency counts of categorical columns. nil keeps the default
+	// heuristics. Datasets can override binning in their structure schema.
+	// See the stats/binning package
+	Binning *Binning `json:"binning,omitempty"`
 	// For later addition:
 	// StopFreqCountThreshold int
 }
@@ -25,6 +33,26 @@ type cache struct {
 	Path    string `json:"path,omitempty"`
 }
 
+// Inference configures schema inference sampling. See the stats/infer package
+// for sampler details
+type Inference struct {
+	// Sampler is one of "prefix", "full", "reservoir", or "stratified"
+	Sampler    string `json:"sampler"`
+	SampleSize int    `json:"samplesize,omitempty"`
+	ChunkSize  int    `json:"chunksize,omitempty"`
+	Seed       int64  `json:"seed,omitempty"`
+}
+
+// Binning configures stats histograms & frequency counts. See the
+// stats/binning package for strategy details
+type Binning struct {
+	// Strategy is one of "default", "sturges", "fixed", or "quantile"
+	Strategy string  `json:"strategy"`
+	Bins     int     `json:"bins,omitempty"`
+	BinWidth float64 `json:"binwidth,omitempty"`
+	TopK     int     `json:"topk,omitempty"`
+}
+
 // DefaultStats creates & returns a new default stats configuration
 func DefaultStats() *Stats {
 	return &Stats{
@@ -73,6 +101,70 @@ func (cfg Stats) Validate() error {
             ]
           }
         }
+      },
+      "inference": {
+        "description": "How schemas are inferred from new bodies",
+        "type": "object",
+        "properties": {
+          "sampler": {
+            "description": "Sampling strategy used to infer column types",
+            "type": "string",
+            "enum": [
+              "",
+              "prefix",
+              "full",
+              "reservoir",
+              "stratified"
+            ]
+          },
+          "samplesize": {
+            "description": "Rows in a reservoir sample, or rows sampled per chunk when stratified",
+            "type": "integer",
+            "minimum": 0
+          },
+          "chunksize": {
+            "description": "Rows in each chunk of a stratified sample",
+            "type": "integer",
+            "minimum": 0
+          },
+          "seed": {
+            "description": "Seed for the reservoir sampler",
+            "type": "integer"
+          }
+        }
+      },
+      "binning": {
+        "description": "How numeric columns are binned & categorical frequencies are capped",
+        "type": "object",
+        "properties": {
+          "strategy": {
+            "description": "Strategy used to bin numeric columns into histograms",
+            "type": "string",
+            "enum": [
+              "",
+              "default",
+              "sturges",
+              "fixed",
+              "quantile"
+            ]
+          },
+          "bins": {
+            "description": "Number of bins for the fixed & quantile strategies",
+            "type": "integer",
+            "minimum": 0,
+            "maximum": 1000
+          },
+          "binwidth": {
+            "description": "Width of each bin for the fixed strategy",
+            "type": "number",
+            "minimum": 0
+          },
+          "topk": {
+            "description": "Number of most common values to keep in frequency counts, zero keeps every value",
+            "type": "integer",
+            "minimum": 0
+          }
+        }
       }
     }
   }`)
@@ -81,11 +173,20 @@ func (cfg Stats) Validate() error {
 
 // Copy returns a deep copy of the Stats struct
 func (cfg *Stats) Copy() *Stats {
-	return &Stats{
+	res := &Stats{
 		Cache: cache{
 			Type:    cfg.Cache.Type,
 			MaxSize: cfg.Cache.MaxSize,
 			Path:    cfg.Cache.Path,
 		},
 	}
+	if cfg.Inference != nil {
+		inf := *cfg.Inference
+		res.Inference = &inf
+	}
+	if cfg.Binning != nil {
+		b := *cfg.Binning
+		res.Binning = &b
+	}
+	return res
 }
@@ -10,16 +10,28 @@ func TestStatsValidate(t *testing.T) {
 	if err != nil {
 		t.Errorf("error validating default stats: %s", err)
 	}
+
+	s := DefaultStats()
+	s.Binning = &Binning{Strategy: "logarithmic"}
+	if err := s.Validate(); err == nil {
+		t.Error("expected an unknown binning strategy to error")
+	}
 }
 
 func TestStatsCopy(t *testing.T) {
 	// build off DefaultStats so we can test that the stats Copy
 	// actually copies over correctly
 	s := DefaultStats()
+	withInference := DefaultStats()
+	withInference.Inference = &Inference{Sampler: "reservoir", SampleSize: 500, Seed: 7}
+	withBinning := DefaultStats()
+	withBinning.Binning = &Binning{Strategy: "quantile", Bins: 5, TopK: 20}
 	cases := []struct {
 		stats *Stats
 	}{
 		{s},
+		{withInference},
+		{withBinning},
 	}
 	for i, c := range cases {
 		cpy := c.stats.Copy()
@@ -27,5 +39,11 @@ func TestStatsCopy(t *testing.T) {
 			t.Errorf("Stats Copy test case %v, stats structs are not equal: \ncopy: %v, \noriginal: %v", i, cpy, c.stats)
 			continue
 		}
+		if cpy.Inference != nil && cpy.Inference == c.stats.Inference {
+			t.Errorf("Stats Copy test case %v, expected inference config to be copied, not shared", i)
+		}
+		if cpy.Binning != nil && cpy.Binning == c.stats.Binning {
+			t.Errorf("Stats Copy test case %v, expected binning config to be copied, not shared", i)
+		}
 	}
 }
@@ -0,0 +1,36 @@
+package build
+
+import (
+	"context"
+	"io"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/dscache"
+	"github.com/qri-io/qri/repo"
+)
+
+var log = golog.Logger("dscache")
+
+// DscacheFromExportOrRepo imports an exported dscache, validating it against
+// the repo's logbook. If the export can't be read or doesn't match the logbook
+// the dscache is rebuilt from the repo instead. rebuilt reports which path
+// was taken
+func DscacheFromExportOrRepo(ctx context.Context, r repo.Repo, export io.Reader) (cache *dscache.Dscache, rebuilt bool, err error) {
+	num, err := r.RefCount()
+	if err != nil {
+		return nil, false, err
+	}
+	refs, err := r.References(0, num)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cache, err = dscache.Import(ctx, export, r.Logbook(), refs)
+	if err == nil {
+		return cache, false, nil
+	}
+	log.Infof("dscache import failed, rebuilding from repo: %s", err)
+
+	cache, err = DscacheFromRepo(ctx, r)
+	return cache, true, err
+}
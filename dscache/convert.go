@@ -210,6 +210,10 @@ func convertDatasetHistoryToDsInfo(dsLog oplog.Log) *entryInfo {
 	// Get the final pretty name, most recently ammended.
 	prettyName := ""
 	for _, op := range dsLog.Ops {
+		if op.Model == logbook.LifecycleModel {
+			// lifecycle state isn't cached, skip
+			continue
+		}
 		if op.Model != logbook.DatasetModel {
 			log.Errorf("expected to be at the dataset level, got model number %d", op.Model)
 			return nil
@@ -15,6 +15,7 @@ import (
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/repo/backup"
 	reporef "github.com/qri-io/qri/repo/ref"
 )
 
@@ -28,7 +29,10 @@ var (
 	ErrInvalidProfileID = fmt.Errorf("invalid profileID")
 )
 
-// Dscache represents an in-memory serialized dscache flatbuffer
+// Dscache represents an in-memory serialized dscache flatbuffer. A single
+// cache holds datasets of every user the instance knows about, each user is a
+// namespace of dataset names. Multi-tenant instances use ListRefsForProfile to
+// list the datasets of one profile
 type Dscache struct {
 	Filename            string
 	Root                *dscachefb.Dscache
@@ -36,6 +40,8 @@ type Dscache struct {
 	CreateNewEnabled    bool
 	ProfileIDToUsername map[string]string
 	DefaultUsername     string
+	// Backups is the number of rotated copies of the dscache file to keep
+	Backups int
 }
 
 // NewDscache will construct a dscache from the given filename, or will construct an empty dscache
@@ -80,6 +86,8 @@ func (d *Dscache) Assign(other *Dscache) error {
 	}
 	d.Root = other.Root
 	d.Buffer = other.Buffer
+	// users may have changed, rebuild the username lookup when next needed
+	d.ProfileIDToUsername = nil
 	return d.save()
 }
 
@@ -139,6 +147,21 @@ func (d *Dscache) VerboseString(showEmpty bool) string {
 
 // ListRefs returns references to each dataset in the cache
 func (d *Dscache) ListRefs() ([]reporef.DatasetRef, error) {
+	return d.listRefs("")
+}
+
+// ListRefsForProfile returns references to each dataset in the cache owned by
+// a profile
+func (d *Dscache) ListRefsForProfile(profileID string) ([]reporef.DatasetRef, error) {
+	if !d.validateProfileID(profileID) {
+		return nil, ErrInvalidProfileID
+	}
+	return d.listRefs(profileID)
+}
+
+// listRefs returns references to datasets in the cache, an empty profileID
+// lists datasets of all users
+func (d *Dscache) listRefs(profileID string) ([]reporef.DatasetRef, error) {
 	if d.IsEmpty() {
 		return nil, ErrNoDscache
 	}
@@ -149,6 +172,9 @@ func (d *Dscache) ListRefs() ([]reporef.DatasetRef, error) {
 		d.Root.Refs(&refCache, i)
 
 		proIDStr := string(refCache.ProfileID())
+		if profileID != "" && proIDStr != profileID {
+			continue
+		}
 		profileID, err := profile.NewB58ID(proIDStr)
 		if err != nil {
 			log.Errorf("could not parse profileID %q", proIDStr)
@@ -292,14 +318,24 @@ func (d *Dscache) updateInitDataset(act event.DsChange) error {
 		return nil
 	}
 	builder := NewBuilder()
-	// copy users
+	// copy users, adding the dataset author if they're new to the cache
+	knownUser := false
 	for i := 0; i < d.Root.UsersLength(); i++ {
 		up := dscachefb.UserAssoc{}
 		d.Root.Users(&up, i)
 		builder.AddUser(string(up.Username()), string(up.ProfileID()))
+		if string(up.ProfileID()) == act.ProfileID {
+			knownUser = true
+		}
+	}
+	if !knownUser {
+		if !d.validateProfileID(act.ProfileID) {
+			return ErrInvalidProfileID
+		}
+		builder.AddUser(act.Username, act.ProfileID)
 	}
 	// copy ds versions
-	for i := 0; i < d.Root.UsersLength(); i++ {
+	for i := 0; i < d.Root.RefsLength(); i++ {
 		r := dscachefb.RefEntryInfo{}
 		d.Root.Refs(&r, i)
 		builder.AddDsVersionInfoWithIndexes(convertEntryToVersionInfo(&r), int(r.TopIndex()), int(r.CursorIndex()))
@@ -387,6 +423,13 @@ func (d *Dscache) updateCreateLink(act event.DsChange) error {
 	if d.IsEmpty() {
 		return ErrNoDscache
 	}
+	// links without an initID are matched by name within the user's namespace
+	username := act.Username
+	if username == "" {
+		username = d.DefaultUsername
+	}
+	linkProfileID := d.profileIDForUsername(username)
+
 	// Flatbuffers for go do not allow mutation (for complex types like strings). So we construct
 	// a new flatbuffer entirely, copying the old one while replacing the entry we care to change.
 	builder := flatbuffers.NewBuilder(0)
@@ -398,7 +441,7 @@ func (d *Dscache) updateCreateLink(act event.DsChange) error {
 			if act.InitID != "" {
 				return string(r.InitID()) == act.InitID
 			}
-			return d.DefaultUsername == act.Username && string(r.PrettyName()) == act.PrettyName
+			return string(r.ProfileID()) == linkProfileID && string(r.PrettyName()) == act.PrettyName
 		},
 		// Function to replace the matching entry
 		func(refStartMutationFunc func(builder *flatbuffers.Builder)) {
@@ -450,11 +493,57 @@ func (d *Dscache) ensureProToUserMap() {
 	}
 }
 
+// profileIDForUsername returns the profileID associated with a username, or
+// an empty string if the username isn't in the cache
+func (d *Dscache) profileIDForUsername(username string) string {
+	d.ensureProToUserMap()
+	for pid, name := range d.ProfileIDToUsername {
+		if name == username {
+			return pid
+		}
+	}
+	return ""
+}
+
 // save writes the serialized bytes to the given filename
 func (d *Dscache) save() error {
 	if d.Filename == "" {
 		log.Infof("dscache: no filename set, will not save")
 		return nil
 	}
+	if err := backup.Rotate(d.Filename, d.Backups); err != nil {
+		log.Errorf("dscache: rotating backups: %s", err)
+	}
 	return ioutil.WriteFile(d.Filename, d.Buffer, 0644)
 }
+
+// ValidateFile checks the bytes of a dscache file decode, returning an error
+// for truncated or corrupt files
+func ValidateFile(data []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("dscache: decoding file: %v", r)
+		}
+	}()
+
+	root := dscachefb.GetRootAsDscache(data, 0)
+	user := &dscachefb.UserAssoc{}
+	for i := 0; i < root.UsersLength(); i++ {
+		if !root.Users(user, i) {
+			return fmt.Errorf("dscache: reading user %d", i)
+		}
+		user.Username()
+		user.ProfileID()
+	}
+	ref := &dscachefb.RefEntryInfo{}
+	for i := 0; i < root.RefsLength(); i++ {
+		if !root.Refs(ref, i) {
+			return fmt.Errorf("dscache: reading ref %d", i)
+		}
+		ref.InitID()
+		ref.ProfileID()
+		ref.PrettyName()
+		ref.HeadRef()
+	}
+	return nil
+}
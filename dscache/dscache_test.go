@@ -164,3 +164,68 @@ func TestCacheRefConsistency(t *testing.T) {
 		t.Errorf("inconsistent resolution between dscache & logbook:\n%s", err)
 	}
 }
+
+func TestListRefsForProfile(t *testing.T) {
+	ctx := context.Background()
+	aliceID := profile.IDFromPeerID(testkeys.GetKeyData(0).PeerID).String()
+	bobID := profile.IDFromPeerID(testkeys.GetKeyData(1).PeerID).String()
+
+	builder := NewBuilder()
+	builder.AddUser("alice", aliceID)
+	builder.AddDsVersionInfo(dsref.VersionInfo{InitID: "abcd1", ProfileID: aliceID, Name: "cities"})
+	builder.AddDsVersionInfo(dsref.VersionInfo{InitID: "efgh2", ProfileID: aliceID, Name: "parks"})
+	dsc := NewDscache(ctx, qfs.NewMemFS(), event.NilBus, "alice", "")
+	if err := dsc.Assign(builder.Build()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a dataset created by a user new to the cache adds the user's namespace
+	err := dsc.handler(ctx, event.Event{
+		Type: event.ETDatasetNameInit,
+		Payload: event.DsChange{
+			InitID:     "ijkl3",
+			ProfileID:  bobID,
+			Username:   "bob",
+			PrettyName: "cities",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := dsc.ListRefs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 refs in the cache, got %d", len(all))
+	}
+
+	refs, err := dsc.ListRefsForProfile(bobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].AliasString() != "bob/cities" {
+		t.Errorf("expected only bob/cities, got: %v", refs)
+	}
+	refs, err = dsc.ListRefsForProfile(aliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 2 {
+		t.Errorf("expected 2 refs for alice, got: %v", refs)
+	}
+
+	// names resolve within each user's namespace
+	ref := dsref.Ref{Username: "bob", Name: "cities"}
+	if _, err := dsc.ResolveRef(ctx, &ref); err != nil {
+		t.Fatal(err)
+	}
+	if ref.InitID != "ijkl3" {
+		t.Errorf("expected bob/cities to resolve to bob's dataset, got initID %q", ref.InitID)
+	}
+
+	if _, err := dsc.ListRefsForProfile("not_a_profile"); err != ErrInvalidProfileID {
+		t.Errorf("expected ErrInvalidProfileID, got: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package dscache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/qri-io/qri/dscache/dscachefb"
+	"github.com/qri-io/qri/logbook"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// ExportVersion is the version of the dscache export format
+const ExportVersion = 1
+
+// ErrExportMismatch indicates an exported dscache doesn't agree with the
+// logbook it's being imported against
+var ErrExportMismatch = fmt.Errorf("dscache export doesn't match logbook")
+
+// export is the portable form of a dscache. Working directory links are
+// machine-specific, and aren't included
+type export struct {
+	Version int               `json:"version"`
+	Created time.Time         `json:"created"`
+	Users   []userProfilePair `json:"users"`
+	Entries []*entryInfo      `json:"entries"`
+}
+
+// Export writes a portable copy of the dscache to w. Importing an export on
+// another machine with the same logbook skips rebuilding the dscache
+func (d *Dscache) Export(w io.Writer) error {
+	if d.IsEmpty() {
+		return ErrNoDscache
+	}
+
+	exp := export{
+		Version: ExportVersion,
+		Created: time.Now(),
+		Users:   make([]userProfilePair, 0, d.Root.UsersLength()),
+		Entries: make([]*entryInfo, 0, d.Root.RefsLength()),
+	}
+	for i := 0; i < d.Root.UsersLength(); i++ {
+		userAssoc := dscachefb.UserAssoc{}
+		d.Root.Users(&userAssoc, i)
+		exp.Users = append(exp.Users, userProfilePair{
+			Username:  string(userAssoc.Username()),
+			ProfileID: string(userAssoc.ProfileID()),
+		})
+	}
+	for i := 0; i < d.Root.RefsLength(); i++ {
+		r := dscachefb.RefEntryInfo{}
+		d.Root.Refs(&r, i)
+		vi := convertEntryToVersionInfo(&r)
+		vi.FSIPath = ""
+		exp.Entries = append(exp.Entries, &entryInfo{
+			VersionInfo: vi,
+			TopIndex:    int(r.TopIndex()),
+			CursorIndex: int(r.CursorIndex()),
+		})
+	}
+
+	return json.NewEncoder(w).Encode(exp)
+}
+
+// Import reads an exported dscache, checking every dataset head in the export
+// matches the head recorded in the logbook. Imports that don't match return
+// an error wrapping ErrExportMismatch, and should be rebuilt from the logbook
+// instead. Working directory links are restored from refs
+func Import(ctx context.Context, r io.Reader, book *logbook.Book, refs []reporef.DatasetRef) (*Dscache, error) {
+	exp := export{}
+	if err := json.NewDecoder(r).Decode(&exp); err != nil {
+		return nil, fmt.Errorf("reading dscache export: %w", err)
+	}
+	if exp.Version != ExportVersion {
+		return nil, fmt.Errorf("%w: unsupported export version %d", ErrExportMismatch, exp.Version)
+	}
+
+	// only log data is needed to compare heads, skip loading datasets
+	heads, err := convertLogbookAndRefs(ctx, book, nil)
+	if err != nil {
+		return nil, err
+	}
+	logHeads := make(map[string]string, len(heads))
+	for _, info := range heads {
+		logHeads[info.InitID] = info.Path
+	}
+
+	for _, e := range exp.Entries {
+		// entries without an initID came from refs that aren't in the logbook
+		if e.InitID != "" {
+			head, ok := logHeads[e.InitID]
+			if !ok {
+				return nil, fmt.Errorf("%w: dataset %s isn't in the logbook", ErrExportMismatch, e.InitID)
+			}
+			if head != e.Path {
+				return nil, fmt.Errorf("%w: dataset %s head is %q, logbook head is %q", ErrExportMismatch, e.InitID, e.Path, head)
+			}
+			delete(logHeads, e.InitID)
+		}
+	}
+	if len(logHeads) > 0 {
+		return nil, fmt.Errorf("%w: %d logbook datasets are missing from export", ErrExportMismatch, len(logHeads))
+	}
+
+	for _, ref := range refs {
+		if info := findMatchingInfo(ref, exp.Entries); info != nil {
+			info.FSIPath = ref.FSIPath
+		}
+	}
+
+	return buildDscacheFlatbuffer(exp.Users, exp.Entries), nil
+}
@@ -0,0 +1,77 @@
+package dscache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/qri-io/qfs"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	dsrefspec "github.com/qri-io/qri/dsref/spec"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logbook"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	fsys := qfs.NewMemFS()
+	keyData := testkeys.GetKeyData(0)
+	book, err := logbook.NewJournal(keyData.PrivKey, "local_user", event.NilBus, fsys, "/mem/logbook.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := dsrefspec.GenerateExampleOplog(ctx, book, "first", "/ipfs/QmFirstExample"); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := convertLogbookAndRefs(ctx, book, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos[0].FSIPath = "/path/to/first"
+	cache := buildDscacheFlatbuffer([]userProfilePair{{Username: "local_user", ProfileID: infos[0].ProfileID}}, infos)
+
+	buf := &bytes.Buffer{}
+	if err := cache.Export(buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	imported, err := Import(ctx, bytes.NewReader(data), book, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, err := imported.ListRefs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Peername != "local_user" || refs[0].Name != "first" || refs[0].Path != "/ipfs/QmFirstExample" {
+		t.Fatalf("unexpected imported refs: %v", refs)
+	}
+	if refs[0].FSIPath != "" {
+		t.Errorf("expected working directory links to be left out of exports, got %q", refs[0].FSIPath)
+	}
+
+	// working directory links are restored from local refs
+	local := []reporef.DatasetRef{{ProfileID: refs[0].ProfileID, Name: "first", FSIPath: "/here/first"}}
+	if imported, err = Import(ctx, bytes.NewReader(data), book, local); err != nil {
+		t.Fatal(err)
+	}
+	if refs, _ = imported.ListRefs(); refs[0].FSIPath != "/here/first" {
+		t.Errorf("expected FSIPath to be restored, got %q", refs[0].FSIPath)
+	}
+
+	// a logbook that has moved on since the export no longer matches
+	if _, _, err := dsrefspec.GenerateExampleOplog(ctx, book, "second", "/ipfs/QmSecondExample"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(ctx, bytes.NewReader(data), book, nil); !errors.Is(err, ErrExportMismatch) {
+		t.Errorf("expected ErrExportMismatch, got: %v", err)
+	}
+
+	if err := (&Dscache{}).Export(buf); err != ErrNoDscache {
+		t.Errorf("expected exporting an empty dscache to return ErrNoDscache, got: %v", err)
+	}
+}
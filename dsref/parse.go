@@ -3,6 +3,7 @@ package dsref
 import (
 	"fmt"
 	"regexp"
+	"time"
 	"unicode"
 )
 
@@ -29,6 +30,16 @@ import (
 //     username/dataset@QmProfile4ID5/ipfs/QmSome1Commit2Hash3
 // An invalid reference:
 //     /ipfs/QmSome1Commit2Hash3
+//
+// ParseAtTime additionally accepts a human-friendly reference followed by a
+// point in time, which selects the latest version saved at or before then:
+//
+//  <timeRef> = <humanFriendlyPortion> '@' <timestamp>
+//
+// Timestamps are RFC3339, or a date in YYYY-MM-DD form which refers to
+// midnight UTC at the start of that day:
+//     username/dataset@2023-06-01
+//     username/dataset@2023-06-01T15:04:05Z
 
 const (
 	alphaNumeric       = `[a-zA-Z][\w-]*`
@@ -41,6 +52,7 @@ var (
 	validName      = regexp.MustCompile(`^` + alphaNumeric)
 	dsNameCheck    = regexp.MustCompile(`^` + alphaNumericDsname + `$`)
 	concreteRef    = regexp.MustCompile(`^@(` + b32LogbookID + `|` + b58Id + `)?\/(` + alphaNumeric + `)\/(` + b58Id + `)`)
+	timeSuffix     = regexp.MustCompile(`@(\d{4}-\d{2}-\d{2}(T[\d:.]+(Z|[+-]\d{2}:\d{2})?)?)$`)
 	b58StrictCheck = regexp.MustCompile(`^Qm[1-9A-HJ-NP-Za-km-z]*$`)
 	b32LowerCheck  = regexp.MustCompile(`^[a-z2-7]*$`)
 
@@ -122,6 +134,42 @@ func parse(text string, peerRef bool) (Ref, error) {
 	return r, nil
 }
 
+// ParseAtTime parses a reference that may select a version by time. If text
+// has no timestamp suffix ParseAtTime behaves like Parse, returning the zero
+// time. Otherwise the returned reference only has a username and name, and
+// callers are expected to resolve the path for the returned time
+func ParseAtTime(text string) (Ref, time.Time, error) {
+	loc := timeSuffix.FindStringSubmatchIndex(text)
+	if loc == nil {
+		ref, err := Parse(text)
+		return ref, time.Time{}, err
+	}
+
+	at, err := parseTimestamp(text[loc[2]:loc[3]])
+	if err != nil {
+		return Ref{}, at, err
+	}
+	ref, err := ParseHumanFriendly(text[:loc[0]])
+	return ref, at, err
+}
+
+// timestampLayouts are the accepted formats for reference timestamps, in
+// order of precedence
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func parseTimestamp(str string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, NewParseError("invalid timestamp %q, expected a date (2006-01-02) or RFC3339 timestamp (2006-01-02T15:04:05Z)", str)
+}
+
 // ParseHumanFriendly parses a reference that only has a username and a dataset name
 func ParseHumanFriendly(text string) (Ref, error) {
 	var r Ref
@@ -2,6 +2,7 @@ package dsref
 
 import (
 	"testing"
+	"time"
 )
 
 func TestParseFull(t *testing.T) {
@@ -66,6 +67,58 @@ func TestParseBadUpperCase(t *testing.T) {
 	}
 }
 
+func TestParseAtTime(t *testing.T) {
+	goodCases := []struct {
+		description string
+		text        string
+		expect      Ref
+		expectTime  string
+	}{
+		{"no time", "abc/my_dataset@/ipfs/QmSecond", Ref{Username: "abc", Name: "my_dataset", Path: "/ipfs/QmSecond"}, ""},
+		{"date", "abc/my_dataset@2023-06-01", Ref{Username: "abc", Name: "my_dataset"}, "2023-06-01T00:00:00Z"},
+		{"timestamp", "abc/my_dataset@2023-06-01T15:04:05Z", Ref{Username: "abc", Name: "my_dataset"}, "2023-06-01T15:04:05Z"},
+		{"timestamp with offset", "abc/my_dataset@2023-06-01T15:04:05-05:00", Ref{Username: "abc", Name: "my_dataset"}, "2023-06-01T20:04:05Z"},
+		{"timestamp without zone", "abc/my_dataset@2023-06-01T15:04:05", Ref{Username: "abc", Name: "my_dataset"}, "2023-06-01T15:04:05Z"},
+	}
+	for i, c := range goodCases {
+		ref, at, err := ParseAtTime(c.text)
+		if err != nil {
+			t.Errorf("case %d %q error: %s", i, c.description, err)
+			continue
+		}
+		if !ref.Equals(c.expect) {
+			t.Errorf("case %d %q mismatch: expect %q, got %q", i, c.description, c.expect, ref)
+		}
+		gotTime := ""
+		if !at.IsZero() {
+			gotTime = at.UTC().Format(time.RFC3339)
+		}
+		if gotTime != c.expectTime {
+			t.Errorf("case %d %q time mismatch: expect %q, got %q", i, c.description, c.expectTime, gotTime)
+		}
+	}
+
+	badCases := []struct {
+		description string
+		text        string
+		expectErr   string
+	}{
+		{"invalid date", "abc/my_dataset@2023-13-01", `invalid timestamp "2023-13-01", expected a date (2006-01-02) or RFC3339 timestamp (2006-01-02T15:04:05Z)`},
+		{"with path", "abc/my_dataset@/ipfs/QmSecond@2023-06-01", ErrNotHumanFriendly.Error()},
+		{"no name", "@2023-06-01", ErrEmptyRef.Error()},
+	}
+	for i, c := range badCases {
+		_, _, err := ParseAtTime(c.text)
+		if err == nil || err.Error() != c.expectErr {
+			t.Errorf("case %d %q expected error: %q, got %q", i, c.description, c.expectErr, err)
+		}
+	}
+
+	if _, err := Parse("abc/my_dataset@2023-06-01"); err == nil {
+		t.Error("expected Parse to reject a time reference")
+	}
+}
+
 func TestParseHumanFriendly(t *testing.T) {
 	goodCases := []struct {
 		description string
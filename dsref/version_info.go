@@ -47,6 +47,9 @@ type VersionInfo struct {
 	// If true, this reference doesn't exist locally. Only makes sense if path is set, as this
 	// flag refers to specific versions, not to entire dataset histories.
 	Foreign bool `json:"foreign,omitempty"`
+	// If true, this version's head components are stored locally but its body
+	// isn't. Body data is fetched from a remote when the version is loaded
+	BodyForeign bool `json:"bodyForeign,omitempty"`
 	//
 	// Meta fields
 	//
@@ -99,6 +102,20 @@ type VersionInfo struct {
 	// RunDuration is not stored on a dataset version, and instead must come from
 	// either run state or a cache of run state
 	RunDuration int64 `json:"runDuration,omitempty"`
+	//
+	// Lifecycle fields
+	//
+	// Lifecycle is the lifecycle state of the dataset. This value will always
+	// be one of:
+	//    ""|"active"|"deprecated"|"archived"
+	// Lifecycle is not stored on a dataset version, and instead must come from
+	// the logbook
+	Lifecycle string `json:"lifecycle,omitempty"`
+	// LifecycleReason explains why the lifecycle state was set
+	LifecycleReason string `json:"lifecycleReason,omitempty"`
+	// Successor is a reference to a dataset that replaces a deprecated or
+	// archived dataset
+	Successor string `json:"successor,omitempty"`
 }
 
 // NewVersionInfoFromRef creates a sparse-populated VersionInfo from a dsref.Ref
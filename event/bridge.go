@@ -0,0 +1,276 @@
+package event
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// bridgeBufferSize is the number of events buffered for each bridge
+// connection. Events sent to a connection with a full buffer are dropped
+const bridgeBufferSize = 256
+
+var (
+	bridgeTypesLk sync.RWMutex
+	bridgeTypes   = map[Type]reflect.Type{}
+)
+
+// RegisterBridgePayload records the go type of an event type's payload, so
+// events received over a bridge carry the same payload type as events
+// published locally. Payloads of unregistered types are decoded from JSON
+// into generic values
+func RegisterBridgePayload(typ Type, payload interface{}) {
+	bridgeTypesLk.Lock()
+	defer bridgeTypesLk.Unlock()
+	bridgeTypes[typ] = reflect.TypeOf(payload)
+}
+
+// BridgeSubscription selects the events a bridge connection receives. An
+// empty subscription receives all events
+type BridgeSubscription struct {
+	Types     []Type `json:"types,omitempty"`
+	SessionID string `json:"sessionID,omitempty"`
+}
+
+func (s BridgeSubscription) matches(e Event) bool {
+	if s.SessionID != "" && s.SessionID != e.SessionID {
+		return false
+	}
+	if len(s.Types) == 0 {
+		return true
+	}
+	for _, t := range s.Types {
+		if t == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// bridgeEvent is the wire format of an event sent over a bridge
+type bridgeEvent struct {
+	Type      Type            `json:"type"`
+	Timestamp int64           `json:"ts"`
+	SessionID string          `json:"sessionID,omitempty"`
+	Payload   json.RawMessage `json:"data,omitempty"`
+}
+
+// ServeBridge exposes the events of a bus to other processes on a unix socket
+// at sockPath. Clients connect with ConnectBridge. Any stale socket file at
+// sockPath is replaced. The bridge stops & removes the socket file when ctx
+// is cancelled
+func ServeBridge(ctx context.Context, bus Bus, sockPath string) error {
+	if fi, err := os.Stat(sockPath); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(sockPath); err != nil {
+			return err
+		}
+	}
+	l, err := net.Listen("unix", sockPath)
+	if err != nil {
+		return err
+	}
+
+	var (
+		lk    sync.Mutex
+		conns = map[*bridgeConn]struct{}{}
+	)
+	bus.SubscribeAll(func(_ context.Context, e Event) error {
+		lk.Lock()
+		defer lk.Unlock()
+		for c := range conns {
+			c.send(e)
+		}
+		return nil
+	})
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+		os.Remove(sockPath)
+	}()
+
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				log.Debugf("event bridge stopped accepting connections: %s", err)
+				return
+			}
+			c, err := newBridgeConn(nc)
+			if err != nil {
+				log.Debugf("event bridge connection: %s", err)
+				nc.Close()
+				continue
+			}
+			lk.Lock()
+			conns[c] = struct{}{}
+			lk.Unlock()
+			go func() {
+				c.writeLoop(ctx)
+				lk.Lock()
+				delete(conns, c)
+				lk.Unlock()
+			}()
+		}
+	}()
+	return nil
+}
+
+// bridgeConn is a client connected to a bridge server
+type bridgeConn struct {
+	conn net.Conn
+	sub  BridgeSubscription
+	ch   chan Event
+}
+
+// newBridgeConn reads the subscription a client sends on connecting
+func newBridgeConn(nc net.Conn) (*bridgeConn, error) {
+	line, err := bufio.NewReader(nc).ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("reading subscription: %w", err)
+	}
+	c := &bridgeConn{conn: nc, ch: make(chan Event, bridgeBufferSize)}
+	if err := json.Unmarshal(line, &c.sub); err != nil {
+		return nil, fmt.Errorf("invalid subscription: %w", err)
+	}
+	return c, nil
+}
+
+func (c *bridgeConn) send(e Event) {
+	if !c.sub.matches(e) {
+		return
+	}
+	select {
+	case c.ch <- e:
+	default:
+		log.Debugf("event bridge connection buffer full, dropping %q event", e.Type)
+	}
+}
+
+// writeLoop writes events to the connection until ctx is cancelled or a
+// write fails
+func (c *bridgeConn) writeLoop(ctx context.Context) {
+	defer c.conn.Close()
+	enc := json.NewEncoder(c.conn)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-c.ch:
+			data, err := json.Marshal(e.Payload)
+			if err != nil {
+				log.Debugf("event bridge encoding %q payload: %s", e.Type, err)
+				continue
+			}
+			be := bridgeEvent{Type: e.Type, Timestamp: e.Timestamp, SessionID: e.SessionID, Payload: data}
+			if err := enc.Encode(be); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ConnectBridge subscribes to events from a bridge served at sockPath,
+// republishing received events on bus. Events arrive until ctx is cancelled
+// or the bridge closes
+func ConnectBridge(ctx context.Context, sockPath string, bus Bus, sub BridgeSubscription) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", sockPath)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(sub)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		conn.Close()
+		return err
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	go func() {
+		defer conn.Close()
+		dec := json.NewDecoder(conn)
+		for {
+			be := bridgeEvent{}
+			if err := dec.Decode(&be); err != nil {
+				log.Debugf("event bridge closed: %s", err)
+				return
+			}
+			payload, err := decodeBridgePayload(be.Type, be.Payload)
+			if err != nil {
+				log.Debugf("event bridge decoding %q payload: %s", be.Type, err)
+				continue
+			}
+			if err := bus.PublishID(ctx, be.Type, be.SessionID, payload); err != nil {
+				log.Debugf("event bridge publishing %q: %s", be.Type, err)
+			}
+		}
+	}()
+	return nil
+}
+
+// decodeBridgePayload decodes a payload into the go type registered for typ.
+// Payloads that don't decode into the registered type, like payloads with
+// error fields, are decoded into generic values
+func decodeBridgePayload(typ Type, data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	bridgeTypesLk.RLock()
+	t, ok := bridgeTypes[typ]
+	bridgeTypesLk.RUnlock()
+	if ok {
+		v := reflect.New(t)
+		if err := json.Unmarshal(data, v.Interface()); err == nil {
+			return v.Elem().Interface(), nil
+		}
+	}
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+func init() {
+	for _, t := range []Type{ETDatasetNameInit, ETDatasetCommitChange, ETDatasetDeleteAll, ETDatasetRename, ETDatasetCreateLink} {
+		RegisterBridgePayload(t, DsChange{})
+	}
+	for _, t := range []Type{ETDatasetSaveStarted, ETDatasetSaveProgress, ETDatasetSaveCompleted} {
+		RegisterBridgePayload(t, DsSaveEvent{})
+	}
+	RegisterBridgePayload(ETDatasetBodyCorrupt, DsBodyCorrupt{})
+	RegisterBridgePayload(ETDatasetFreshness, DsFreshness{})
+	for _, t := range []Type{
+		ETRemoteClientPushVersionProgress, ETRemoteClientPushVersionCompleted, ETRemoteClientPushDatasetCompleted,
+		ETRemoteClientPullVersionProgress, ETRemoteClientPullVersionCompleted, ETRemoteClientPullDatasetCompleted,
+		ETRemoteClientRemoveDatasetCompleted,
+	} {
+		RegisterBridgePayload(t, RemoteEvent{})
+	}
+	for _, t := range []Type{ETTransformStart, ETTransformStop} {
+		RegisterBridgePayload(t, TransformLifecycle{})
+	}
+	for _, t := range []Type{ETTransformStepStart, ETTransformStepStop, ETTransformStepSkip} {
+		RegisterBridgePayload(t, TransformStepLifecycle{})
+	}
+	RegisterBridgePayload(ETTransformStepOutput, TransformStepOutput{})
+	RegisterBridgePayload(ETTransformFetch, TransformFetch{})
+	RegisterBridgePayload(ETTransformDatasetLoad, TransformDatasetLoad{})
+	RegisterBridgePayload(ETTransformPrint, TransformMessage{})
+	RegisterBridgePayload(ETTransformError, TransformMessage{})
+	RegisterBridgePayload(ETFSICreateLinkEvent, FSICreateLinkEvent{})
+	for _, t := range []Type{ETCreatedNewFile, ETModifiedFile, ETDeletedFile, ETRenamedFolder, ETRemovedFolder} {
+		RegisterBridgePayload(t, WatchfsChange{})
+	}
+}
@@ -0,0 +1,66 @@
+package event
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBridge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "event_bridge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sockPath := filepath.Join(dir, "events.sock")
+
+	server := NewBus(ctx)
+	if err := ServeBridge(ctx, server, sockPath); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewBus(ctx)
+	got := make(chan Event, 2)
+	client.SubscribeAll(func(_ context.Context, e Event) error {
+		got <- e
+		return nil
+	})
+	sub := BridgeSubscription{Types: []Type{ETDatasetSaveProgress}}
+	if err := ConnectBridge(ctx, sockPath, client, sub); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait for the server to register the connection
+	time.Sleep(50 * time.Millisecond)
+
+	if err := server.Publish(ctx, ETTransformPrint, TransformMessage{Msg: "not subscribed"}); err != nil {
+		t.Fatal(err)
+	}
+	expect := DsSaveEvent{Username: "peer", Name: "movies", Completion: 0.5}
+	if err := server.PublishID(ctx, ETDatasetSaveProgress, "session", expect); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case e := <-got:
+		if e.Type != ETDatasetSaveProgress {
+			t.Errorf("expected %q event, got: %q", ETDatasetSaveProgress, e.Type)
+		}
+		if e.SessionID != "session" {
+			t.Errorf("expected session ID to be bridged, got: %q", e.SessionID)
+		}
+		if diff := cmp.Diff(expect, e.Payload); diff != "" {
+			t.Errorf("payload mismatch. (-want +got):\n%s", diff)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for bridged event")
+	}
+}
@@ -33,6 +33,14 @@ const (
 	// ETDatasetSaveCompleted indicates creating a dataset version finished
 	// payload will be a DsSaveEvent
 	ETDatasetSaveCompleted = Type("dataset:SaveCompleted")
+	// ETDatasetBodyCorrupt fires when a recomputed body checksum doesn't match
+	// the checksum recorded when the version was saved
+	// payload will be a DsBodyCorrupt
+	ETDatasetBodyCorrupt = Type("dataset:BodyCorrupt")
+	// ETDatasetFreshness fires when a freshness check finishes looking for
+	// new data at a dataset's source
+	// payload will be a DsFreshness
+	ETDatasetFreshness = Type("dataset:Freshness")
 )
 
 // DsChange represents the result of a change to a dataset
@@ -61,3 +69,99 @@ type DsSaveEvent struct {
 	// only populated on successful ETDatasetSaveCompleted
 	Path string `json:"path,omitempty"`
 }
+
+// DsBodyCorrupt describes a dataset body that no longer matches the checksum
+// recorded in its structure
+type DsBodyCorrupt struct {
+	Ref      string `json:"ref,omitempty"`
+	Path     string `json:"path"`
+	BodyPath string `json:"bodyPath"`
+	// Checksum is the checksum recorded when the version was saved
+	Checksum string `json:"checksum,omitempty"`
+	// Computed is the checksum of the body as it's stored now
+	Computed string `json:"computed,omitempty"`
+	Error    string `json:"error"`
+}
+
+// DsFreshness is the result of checking a dataset's source for new data
+type DsFreshness struct {
+	Ref     string `json:"ref"`
+	BodyURL string `json:"bodyURL"`
+	// Changed is true if the source has new data
+	Changed bool `json:"changed"`
+	// Saved is the path of the version saved because the source changed
+	Saved string `json:"saved,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Attempts is the number of times the check ran, including retries
+	Attempts int `json:"attempts,omitempty"`
+	// Failures counts consecutive failed checks, zero after a success
+	Failures int `json:"failures,omitempty"`
+	// Paused is true when repeated failures paused the check
+	Paused bool `json:"paused,omitempty"`
+}
+
+func init() {
+	dsChange := `{
+		"type": "object",
+		"required": ["initID", "topIndex", "profileID", "username", "prettyName", "headRef", "dir"],
+		"properties": {
+			"initID": { "type": "string" },
+			"topIndex": { "type": "integer" },
+			"profileID": { "type": "string" },
+			"username": { "type": "string" },
+			"prettyName": { "type": "string" },
+			"headRef": { "type": "string" },
+			"info": { "type": ["object", "null"] },
+			"dir": { "type": "string" }
+		}
+	}`
+	RegisterPayload(ETDatasetNameInit, "a dataset is initialized", dsChange)
+	RegisterPayload(ETDatasetCommitChange, "a dataset changes its newest commit", dsChange)
+	RegisterPayload(ETDatasetDeleteAll, "a dataset is entirely deleted", dsChange)
+	RegisterPayload(ETDatasetRename, "a dataset is renamed", dsChange)
+	RegisterPayload(ETDatasetCreateLink, "a dataset is linked to a working directory", dsChange)
+
+	dsSave := `{
+		"type": "object",
+		"required": ["username", "name", "message", "complete"],
+		"properties": {
+			"username": { "type": "string" },
+			"name": { "type": "string" },
+			"message": { "type": "string" },
+			"error": {},
+			"complete": { "type": "number", "minimum": 0, "maximum": 1 },
+			"path": { "type": "string" }
+		}
+	}`
+	RegisterPayload(ETDatasetSaveStarted, "saving a dataset version started", dsSave)
+	RegisterPayload(ETDatasetSaveProgress, "progress saving a dataset version changed", dsSave)
+	RegisterPayload(ETDatasetSaveCompleted, "saving a dataset version finished", dsSave)
+
+	RegisterPayload(ETDatasetBodyCorrupt, "a dataset body doesn't match its recorded checksum", `{
+		"type": "object",
+		"required": ["path", "bodyPath", "error"],
+		"properties": {
+			"ref": { "type": "string" },
+			"path": { "type": "string" },
+			"bodyPath": { "type": "string" },
+			"checksum": { "type": "string" },
+			"computed": { "type": "string" },
+			"error": { "type": "string" }
+		}
+	}`)
+
+	RegisterPayload(ETDatasetFreshness, "a dataset source was checked for new data", `{
+		"type": "object",
+		"required": ["ref", "bodyURL", "changed"],
+		"properties": {
+			"ref": { "type": "string" },
+			"bodyURL": { "type": "string" },
+			"changed": { "type": "boolean" },
+			"saved": { "type": "string" },
+			"error": { "type": "string" },
+			"attempts": { "type": "integer" },
+			"failures": { "type": "integer" },
+			"paused": { "type": "boolean" }
+		}
+	}`)
+}
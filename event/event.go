@@ -157,6 +157,13 @@ func (b *bus) publish(ctx context.Context, typ Type, sessionID string, payload i
 		return ErrBusClosed
 	}
 
+	if ValidatePayloads {
+		if err := ValidatePayload(typ, payload); err != nil {
+			log.Errorf("publishing event: %s", err)
+			return err
+		}
+	}
+
 	e := Event{
 		Type:      typ,
 		Timestamp: NowFunc().UnixNano(),
@@ -38,3 +38,32 @@ type WatchfsChange struct {
 	Destination string    `json:"destination"`
 	Time        time.Time `json:"time"`
 }
+
+func init() {
+	RegisterPayload(ETFSICreateLinkEvent, "a dataset is linked to a working directory", `{
+		"type": "object",
+		"required": ["fsiPath", "username", "dsName"],
+		"properties": {
+			"fsiPath": { "type": "string" },
+			"username": { "type": "string" },
+			"dsName": { "type": "string" }
+		}
+	}`)
+
+	watchfsChange := `{
+		"type": "object",
+		"required": ["username", "dsName", "source", "destination", "time"],
+		"properties": {
+			"username": { "type": "string" },
+			"dsName": { "type": "string" },
+			"source": { "type": "string" },
+			"destination": { "type": "string" },
+			"time": { "type": "string" }
+		}
+	}`
+	RegisterPayload(ETCreatedNewFile, "a file in a working directory is created", watchfsChange)
+	RegisterPayload(ETModifiedFile, "a file in a working directory is modified", watchfsChange)
+	RegisterPayload(ETDeletedFile, "a file in a working directory is deleted", watchfsChange)
+	RegisterPayload(ETRenamedFolder, "a working directory is renamed", watchfsChange)
+	RegisterPayload(ETRemovedFolder, "a working directory is removed", watchfsChange)
+}
@@ -3,3 +3,7 @@ package event
 // ETInstanceConstructed is fired once a node is created
 // payload is nil
 var ETInstanceConstructed = Type("lib:InstanceConstructed")
+
+func init() {
+	RegisterPayload(ETInstanceConstructed, "a node is created", `{ "type": "null" }`)
+}
@@ -30,4 +30,41 @@ var (
 	// from a Qri peer
 	// payload will be a p2p.Message
 	ETP2PMessageReceived = Type("p2p:MessageReceived")
+	// ETP2PLocalPeerFound occurs when a peer is discovered on the local network
+	// payload will be a libp2p.peerInfo
+	ETP2PLocalPeerFound = Type("p2p:LocalPeerFound")
+	// ETP2PLetterReceived occurs when a letter from a peer is delivered to this
+	// node, either directly or through a mailbox relay
+	// payload will be a p2p.Letter
+	ETP2PLetterReceived = Type("p2p:LetterReceived")
 )
+
+func init() {
+	RegisterPayload(ETP2PGoneOnline, "this node opened up for peer-2-peer connections. payload is a list of listening addresses", `{ "type": ["array", "null"] }`)
+	RegisterPayload(ETP2PGoneOffline, "this node disconnected from the peer-2-peer network", `{ "type": "null" }`)
+
+	profile := `{ "type": ["object", "null"] }`
+	RegisterPayload(ETP2PQriPeerConnected, "a peer that speaks the qri protocol connected. payload is the peer's profile", profile)
+	RegisterPayload(ETP2PQriPeerDisconnected, "a qri peer disconnected. payload is the peer's profile, or null if the profile is unknown", profile)
+
+	peerInfo := `{
+		"type": "object",
+		"properties": {
+			"ID": { "type": "string" },
+			"Addrs": { "type": ["array", "null"] }
+		}
+	}`
+	RegisterPayload(ETP2PPeerConnected, "a peer connected", peerInfo)
+	RegisterPayload(ETP2PPeerDisconnected, "a peer disconnected", peerInfo)
+	RegisterPayload(ETP2PLocalPeerFound, "a peer was discovered on the local network", peerInfo)
+	RegisterPayload(ETP2PMessageReceived, "a message from a qri peer was received", `{ "type": "object" }`)
+	RegisterPayload(ETP2PLetterReceived, "a letter from a peer was delivered. payload is the letter", `{
+		"type": "object",
+		"properties": {
+			"id": { "type": "string" },
+			"type": { "type": "string" },
+			"from": { "type": "string" },
+			"to": { "type": "string" }
+		}
+	}`)
+}
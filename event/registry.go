@@ -0,0 +1,111 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// EnvValidatePayloads is the environment variable that turns on payload
+// validation when set to "true"
+const EnvValidatePayloads = "QRI_VALIDATE_EVENTS"
+
+var (
+	// ValidatePayloads checks every published payload against the schema
+	// registered for its event type, failing Publish when a payload doesn't
+	// match. Validation marshals each payload to JSON, and is meant for
+	// development & tests
+	ValidatePayloads = os.Getenv(EnvValidatePayloads) == "true"
+
+	// ErrInvalidPayload is returned by Publish when payload validation is on
+	// and a payload doesn't match the schema registered for its type
+	ErrInvalidPayload = fmt.Errorf("invalid event payload")
+
+	registryLk sync.RWMutex
+	registry   = map[Type]*PayloadType{}
+)
+
+// PayloadType describes the payload of an event type. Payloads are delivered
+// to websocket & webhook consumers as JSON, Schema describes the shape of
+// that JSON
+type PayloadType struct {
+	Type        Type            `json:"type"`
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+
+	schema *jsonschema.Schema
+}
+
+// RegisterPayload records the JSON schema for an event type's payload.
+// Registration is expected to happen in package init functions, and panics
+// if schema isn't a valid JSON schema. Registering a type twice replaces the
+// prior definition
+func RegisterPayload(typ Type, description, schema string) {
+	rs := &jsonschema.Schema{}
+	if err := json.Unmarshal([]byte(schema), rs); err != nil {
+		panic(fmt.Sprintf("invalid payload schema for event type %q: %s", typ, err))
+	}
+
+	registryLk.Lock()
+	defer registryLk.Unlock()
+	registry[typ] = &PayloadType{
+		Type:        typ,
+		Description: description,
+		Schema:      json.RawMessage(schema),
+		schema:      rs,
+	}
+}
+
+// Payload returns the registered payload definition for an event type
+func Payload(typ Type) (PayloadType, bool) {
+	registryLk.RLock()
+	defer registryLk.RUnlock()
+	pt, ok := registry[typ]
+	if !ok {
+		return PayloadType{}, false
+	}
+	return *pt, true
+}
+
+// Catalog lists all registered payload definitions, sorted by type
+func Catalog() []PayloadType {
+	registryLk.RLock()
+	defer registryLk.RUnlock()
+	types := make([]PayloadType, 0, len(registry))
+	for _, pt := range registry {
+		types = append(types, *pt)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Type < types[j].Type
+	})
+	return types
+}
+
+// ValidatePayload checks a payload against the schema registered for an
+// event type. Types without a registered schema are not checked
+func ValidatePayload(typ Type, payload interface{}) error {
+	registryLk.RLock()
+	pt, ok := registry[typ]
+	registryLk.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%w: %q payload can't be encoded as JSON: %s", ErrInvalidPayload, typ, err)
+	}
+	keyErrs, err := pt.schema.ValidateBytes(context.Background(), data)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %s", ErrInvalidPayload, typ, err)
+	}
+	if len(keyErrs) > 0 {
+		return fmt.Errorf("%w: %q: %s", ErrInvalidPayload, typ, keyErrs[0])
+	}
+	return nil
+}
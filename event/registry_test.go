@@ -0,0 +1,99 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidatePayload(t *testing.T) {
+	good := []struct {
+		typ     Type
+		payload interface{}
+	}{
+		{ETInstanceConstructed, nil},
+		{ETDatasetCommitChange, DsChange{InitID: "abc", Username: "peer", PrettyName: "movies"}},
+		{ETDatasetSaveProgress, DsSaveEvent{Username: "peer", Name: "movies", Completion: 0.5}},
+		{ETModifiedFile, WatchfsChange{Username: "peer", Dsname: "movies", Time: time.Now()}},
+		{ETTransformPrint, TransformMessage{Lvl: TransformMsgLvlInfo, Msg: "hello"}},
+		{ETTransformStepStart, TransformStepLifecycle{Name: "transform", Category: "setup"}},
+		{ETTransformStepOutput, TransformStepOutput{Step: "clean", Type: "DataFrame", Rows: 10, Storage: "memory"}},
+		{ETTransformFetch, TransformFetch{URL: "https://example.com", Method: "GET", Status: 200, Attempts: 1}},
+		{ETRemoteClientPushVersionCompleted, RemoteEvent{RemoteAddr: "https://registry.qri.cloud"}},
+		// unregistered types aren't checked
+		{ETMainSaidHello, "hello"},
+	}
+	for _, c := range good {
+		if err := ValidatePayload(c.typ, c.payload); err != nil {
+			t.Errorf("%s payload unexpected error: %s", c.typ, err)
+		}
+	}
+
+	bad := []struct {
+		typ     Type
+		payload interface{}
+	}{
+		{ETInstanceConstructed, "not nil"},
+		{ETDatasetCommitChange, TransformMessage{Msg: "wrong payload"}},
+		{ETDatasetSaveProgress, DsSaveEvent{Username: "peer", Name: "movies", Completion: 2}},
+		{ETTransformPrint, TransformMessage{Lvl: "shout", Msg: "hello"}},
+		{ETTransformStart, nil},
+		{ETTransformStepOutput, TransformStepOutput{Step: "clean", Type: "DataFrame", Storage: "cloud"}},
+	}
+	for _, c := range bad {
+		if err := ValidatePayload(c.typ, c.payload); !errors.Is(err, ErrInvalidPayload) {
+			t.Errorf("%s payload %#v expected ErrInvalidPayload, got: %v", c.typ, c.payload, err)
+		}
+	}
+}
+
+func TestPublishValidatesPayloads(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	prev := ValidatePayloads
+	defer func() { ValidatePayloads = prev }()
+
+	bus := NewBus(ctx)
+	called := false
+	bus.SubscribeTypes(func(_ context.Context, _ Event) error {
+		called = true
+		return nil
+	}, ETTransformPrint)
+
+	ValidatePayloads = false
+	if err := bus.Publish(ctx, ETTransformPrint, "not a message"); err != nil {
+		t.Errorf("expected no error with validation off, got: %s", err)
+	}
+	if !called {
+		t.Error("expected handler to be called with validation off")
+	}
+
+	called = false
+	ValidatePayloads = true
+	if err := bus.Publish(ctx, ETTransformPrint, "not a message"); !errors.Is(err, ErrInvalidPayload) {
+		t.Errorf("expected ErrInvalidPayload, got: %v", err)
+	}
+	if called {
+		t.Error("expected handler not to be called for an invalid payload")
+	}
+	if err := bus.Publish(ctx, ETTransformPrint, TransformMessage{Msg: "hi"}); err != nil {
+		t.Errorf("unexpected error publishing a valid payload: %s", err)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	cat := Catalog()
+	for i, pt := range cat {
+		if i > 0 && cat[i-1].Type >= pt.Type {
+			t.Errorf("expected catalog to be sorted by type. %q came before %q", cat[i-1].Type, pt.Type)
+		}
+		if pt.Description == "" {
+			t.Errorf("%s has no description", pt.Type)
+		}
+	}
+	if _, ok := Payload(ETDatasetCommitChange); !ok {
+		t.Errorf("expected %s to be registered", ETDatasetCommitChange)
+	}
+}
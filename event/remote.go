@@ -45,3 +45,32 @@ type RemoteEvent struct {
 	Progress   dag.Completion `json:"progress"`
 	Error      error          `json:"error,omitempty"`
 }
+
+func init() {
+	remoteEvent := `{
+		"type": "object",
+		"required": ["ref", "remoteAddr", "progress"],
+		"properties": {
+			"ref": {
+				"type": "object",
+				"properties": {
+					"initID": { "type": "string" },
+					"username": { "type": "string" },
+					"profileID": { "type": "string" },
+					"name": { "type": "string" },
+					"path": { "type": "string" }
+				}
+			},
+			"remoteAddr": { "type": "string" },
+			"progress": { "type": ["array", "null"], "items": { "type": "integer" } },
+			"error": {}
+		}
+	}`
+	RegisterPayload(ETRemoteClientPushVersionProgress, "progress pushing a dataset version changed", remoteEvent)
+	RegisterPayload(ETRemoteClientPushVersionCompleted, "a dataset version was pushed to a remote", remoteEvent)
+	RegisterPayload(ETRemoteClientPushDatasetCompleted, "pushing a dataset (logbook & versions) completed", remoteEvent)
+	RegisterPayload(ETRemoteClientPullVersionProgress, "progress pulling a dataset version changed", remoteEvent)
+	RegisterPayload(ETRemoteClientPullVersionCompleted, "a dataset version was pulled from a remote", remoteEvent)
+	RegisterPayload(ETRemoteClientPullDatasetCompleted, "pulling a dataset (logbook & versions) completed", remoteEvent)
+	RegisterPayload(ETRemoteClientRemoveDatasetCompleted, "removing a dataset (logbook & versions) from a remote completed", remoteEvent)
+}
@@ -17,6 +17,15 @@ const (
 	// ETTransformStepSkip signals a step was skipped.
 	// Payload will be a TransformStepLifecycle
 	ETTransformStepSkip = Type("tf:StepSkip")
+	// ETTransformStepOutput signals a step produced an output later steps can
+	// read. Payload will be a TransformStepOutput
+	ETTransformStepOutput = Type("tf:StepOutput")
+	// ETTransformFetch signals a transform made an HTTP request with the fetch
+	// module. Payload will be a TransformFetch
+	ETTransformFetch = Type("tf:Fetch")
+	// ETTransformDatasetLoad signals a transform loaded a dataset with
+	// load_dataset. Payload will be a TransformDatasetLoad
+	ETTransformDatasetLoad = Type("tf:DatasetLoad")
 
 	// ETTransformPrint is sent by print commands.
 	// Payload will be a Message
@@ -46,6 +55,48 @@ type TransformStepLifecycle struct {
 	Status   string `json:"status,omitempty"`
 }
 
+// TransformStepOutput describes a value a transform step passes to later steps
+// payload for ETTransformStepOutput
+type TransformStepOutput struct {
+	// Step is the name of the step that produced the output
+	Step string `json:"step"`
+	// Type is the starlark type of the output
+	Type string `json:"type"`
+	// Rows is the number of rows in a DataFrame output
+	Rows int `json:"rows,omitempty"`
+	// Storage is where a DataFrame output is kept, either "memory" or "disk"
+	Storage string `json:"storage,omitempty"`
+}
+
+// TransformFetch records an HTTP request made by a transform, providing
+// provenance for fetched data
+// payload for ETTransformFetch
+type TransformFetch struct {
+	URL    string `json:"url"`
+	Method string `json:"method"`
+	// Status is the response status code, zero if the request failed
+	Status int `json:"status,omitempty"`
+	// Cached is true if the response was read from the cache
+	Cached bool `json:"cached,omitempty"`
+	// Attempts is the number of times the request was sent
+	Attempts int `json:"attempts,omitempty"`
+	// Duration is the time the request took in nanoseconds
+	Duration int `json:"duration"`
+	// Hash is the hex-encoded sha256 hash of the response body
+	Hash  string `json:"hash,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// TransformDatasetLoad records a dataset read by a transform, providing
+// provenance for the inputs of a run
+// payload for ETTransformDatasetLoad
+type TransformDatasetLoad struct {
+	// Ref is the reference string the transform passed to load_dataset
+	Ref string `json:"ref"`
+	// Path is the resolved version path of the loaded dataset
+	Path string `json:"path"`
+}
+
 // TransformMsgLvl is an enumeration of all possible degrees of message
 // logging in an implicit hierarchy (levels)
 type TransformMsgLvl string
@@ -68,3 +119,75 @@ type TransformMessage struct {
 	Lvl TransformMsgLvl `json:"lvl"`
 	Msg string          `json:"msg"`
 }
+
+func init() {
+	lifecycle := `{
+		"type": "object",
+		"required": ["stepCount"],
+		"properties": {
+			"stepCount": { "type": "integer" },
+			"status": { "type": "string" }
+		}
+	}`
+	RegisterPayload(ETTransformStart, "a transform started", lifecycle)
+	RegisterPayload(ETTransformStop, "a transform stopped", lifecycle)
+
+	stepLifecycle := `{
+		"type": "object",
+		"required": ["name", "category"],
+		"properties": {
+			"name": { "type": "string" },
+			"category": { "type": "string" },
+			"status": { "type": "string" }
+		}
+	}`
+	RegisterPayload(ETTransformStepStart, "a transform step started", stepLifecycle)
+	RegisterPayload(ETTransformStepStop, "a transform step stopped", stepLifecycle)
+	RegisterPayload(ETTransformStepSkip, "a transform step was skipped", stepLifecycle)
+	RegisterPayload(ETTransformStepOutput, "a transform step produced an output", `{
+		"type": "object",
+		"required": ["step", "type"],
+		"properties": {
+			"step": { "type": "string" },
+			"type": { "type": "string" },
+			"rows": { "type": "integer" },
+			"storage": { "type": "string", "enum": ["", "memory", "disk"] }
+		}
+	}`)
+
+	RegisterPayload(ETTransformFetch, "a transform made an HTTP request", `{
+		"type": "object",
+		"required": ["url", "method"],
+		"properties": {
+			"url": { "type": "string" },
+			"method": { "type": "string" },
+			"status": { "type": "integer" },
+			"cached": { "type": "boolean" },
+			"attempts": { "type": "integer" },
+			"duration": { "type": "integer" },
+			"hash": { "type": "string" },
+			"error": { "type": "string" }
+		}
+	}`)
+
+	RegisterPayload(ETTransformDatasetLoad, "a transform loaded a dataset", `{
+		"type": "object",
+		"required": ["ref", "path"],
+		"properties": {
+			"ref": { "type": "string" },
+			"path": { "type": "string" }
+		}
+	}`)
+
+	message := `{
+		"type": "object",
+		"required": ["lvl", "msg"],
+		"properties": {
+			"lvl": { "type": "string", "enum": ["", "debug", "info", "warn", "error"] },
+			"msg": { "type": "string" }
+		}
+	}`
+	RegisterPayload(ETTransformPrint, "a transform printed a message", message)
+	RegisterPayload(ETTransformError, "a transform raised an error", message)
+	RegisterPayload(ETTransformDatasetPreview, "an abbreviated preview of the dataset a transform is producing", `{ "type": "object" }`)
+}
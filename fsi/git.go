@@ -0,0 +1,289 @@
+package fsi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+// GitVersionTrailer is the git commit trailer that records the qri version a
+// commit was exported from
+const GitVersionTrailer = "Qri-Version"
+
+// ErrNoGit indicates the git executable couldn't be found
+var ErrNoGit = fmt.Errorf("git executable not found")
+
+// GitBridge mirrors dataset components into a branch of a git repository.
+// Each exported qri version becomes one commit on the branch, containing the
+// same component files a working directory would. Commits are written with
+// git plumbing commands against a temporary index, so the working tree and
+// checked out branch of the repository are never touched
+type GitBridge struct {
+	gitDir string
+	branch string
+}
+
+// GitAuthor identifies the author of an exported version
+type GitAuthor struct {
+	Name  string
+	Email string
+}
+
+// GitCommit describes a commit on a bridged branch
+type GitCommit struct {
+	Hash    string
+	Author  GitAuthor
+	Time    time.Time
+	Title   string
+	Message string
+	// QriVersion is the path of the qri version the commit was exported from,
+	// empty for commits made in git
+	QriVersion string
+}
+
+// NewGitBridge creates a bridge to a branch of the git repository at dir
+func NewGitBridge(ctx context.Context, dir, branch string) (*GitBridge, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, ErrNoGit
+	}
+	if branch == "" {
+		return nil, fmt.Errorf("git branch name is required")
+	}
+	g := &GitBridge{branch: branch}
+	out, err := g.git(ctx, nil, "-C", dir, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a git repository: %w", dir, err)
+	}
+	g.gitDir = out
+	if _, err := g.git(ctx, nil, "check-ref-format", "--branch", branch); err != nil {
+		return nil, fmt.Errorf("invalid branch name %q", branch)
+	}
+	return g, nil
+}
+
+// Branch returns the name of the bridged branch
+func (g *GitBridge) Branch() string {
+	return g.branch
+}
+
+// Head returns the most recent commit on the branch, nil if the branch doesn't
+// exist yet
+func (g *GitBridge) Head(ctx context.Context) (*GitCommit, error) {
+	hash, err := g.headHash(ctx)
+	if err != nil || hash == "" {
+		return nil, err
+	}
+	out, err := g.git(ctx, nil, "log", "-1", "--format=%H%x00%an%x00%ae%x00%at%x00%B", hash)
+	if err != nil {
+		return nil, err
+	}
+	return parseGitCommit(out)
+}
+
+// Exported maps qri version paths to the commits they were exported to
+func (g *GitBridge) Exported(ctx context.Context) (map[string]string, error) {
+	res := map[string]string{}
+	hash, err := g.headHash(ctx)
+	if err != nil || hash == "" {
+		return res, err
+	}
+	out, err := g.git(ctx, nil, "log", "--format=%H%x00%an%x00%ae%x00%at%x00%B%x1e", hash)
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range strings.Split(out, "\x1e") {
+		if rec = strings.TrimSpace(rec); rec == "" {
+			continue
+		}
+		c, err := parseGitCommit(rec)
+		if err != nil {
+			return nil, err
+		}
+		if c.QriVersion != "" {
+			res[c.QriVersion] = c.Hash
+		}
+	}
+	return res, nil
+}
+
+// Export commits the components of a dataset version to the branch, returning
+// the new commit hash. ds must be open, so the body can be read
+func (g *GitBridge) Export(ctx context.Context, ds *dataset.Dataset, fs qfs.Filesystem, author GitAuthor) (string, error) {
+	tmp, err := ioutil.TempDir("", "qri_git_export")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	worktree := filepath.Join(tmp, "tree")
+	if err := os.Mkdir(worktree, os.ModePerm); err != nil {
+		return "", err
+	}
+	// writing components drops derived values like the version path, write a
+	// copy to keep them
+	written := *ds
+	if err := WriteComponents(&written, worktree, fs); err != nil {
+		return "", err
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, "index")}
+	if _, err := g.git(ctx, env, "-C", worktree, "--work-tree="+worktree, "add", "--all", "."); err != nil {
+		return "", err
+	}
+	tree, err := g.git(ctx, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"commit-tree", tree, "-m", gitMessage(ds)}
+	parent, err := g.headHash(ctx)
+	if err != nil {
+		return "", err
+	}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	if author.Name != "" {
+		env = append(env, "GIT_AUTHOR_NAME="+author.Name)
+	}
+	if author.Email != "" {
+		env = append(env, "GIT_AUTHOR_EMAIL="+author.Email)
+	}
+	if ds.Commit != nil && !ds.Commit.Timestamp.IsZero() {
+		// git only reads unix timestamps with a leading "@"
+		date := fmt.Sprintf("@%d +0000", ds.Commit.Timestamp.Unix())
+		env = append(env, "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	}
+	commit, err := g.git(ctx, env, args...)
+	if err != nil {
+		return "", err
+	}
+
+	// passing the previous head makes the update fail if the branch moved
+	// while this version was being written
+	if _, err := g.git(ctx, nil, "update-ref", "refs/heads/"+g.branch, commit, parent); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// ReadCommit reads the components of a commit into a dataset. Files are read
+// from a temporary directory that is removed before ReadCommit returns, so the
+// body is read into memory
+func (g *GitBridge) ReadCommit(ctx context.Context, hash string) (*dataset.Dataset, error) {
+	tmp, err := ioutil.TempDir("", "qri_git_import")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	worktree := filepath.Join(tmp, "tree")
+	if err := os.Mkdir(worktree, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, "index")}
+	if _, err := g.git(ctx, env, "read-tree", hash); err != nil {
+		return nil, err
+	}
+	if _, err := g.git(ctx, env, "--work-tree="+worktree, "checkout-index", "--all"); err != nil {
+		return nil, err
+	}
+
+	ds, err := ReadDir(worktree)
+	if err != nil {
+		return nil, err
+	}
+	ds.Path = ""
+	if ds.BodyPath != "" {
+		if ds.BodyBytes, err = ioutil.ReadFile(ds.BodyPath); err != nil {
+			return nil, err
+		}
+		ds.BodyPath = ""
+	}
+	return ds, nil
+}
+
+func (g *GitBridge) headHash(ctx context.Context) (string, error) {
+	out, err := g.git(ctx, nil, "for-each-ref", "--format=%(objectname)", "refs/heads/"+g.branch)
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// git runs a git command, returning trimmed output
+func (g *GitBridge) git(ctx context.Context, env []string, args ...string) (string, error) {
+	if g.gitDir != "" {
+		args = append([]string{"--git-dir=" + g.gitDir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), env...)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git: %s", msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// gitMessage maps a version commit to a git commit message, recording the
+// version path in a trailer
+func gitMessage(ds *dataset.Dataset) string {
+	msg := &strings.Builder{}
+	if ds.Commit != nil && ds.Commit.Title != "" {
+		msg.WriteString(ds.Commit.Title)
+	} else {
+		msg.WriteString("qri version")
+	}
+	if ds.Commit != nil && ds.Commit.Message != "" {
+		msg.WriteString("\n\n")
+		msg.WriteString(ds.Commit.Message)
+	}
+	fmt.Fprintf(msg, "\n\n%s: %s\n", GitVersionTrailer, ds.Path)
+	return msg.String()
+}
+
+// parseGitCommit parses the null-separated output of git log with the format
+// "%H%x00%an%x00%ae%x00%at%x00%B"
+func parseGitCommit(out string) (*GitCommit, error) {
+	fields := strings.SplitN(out, "\x00", 5)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("unexpected git log output")
+	}
+	ts, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid git commit time: %w", err)
+	}
+	c := &GitCommit{
+		Hash:   fields[0],
+		Author: GitAuthor{Name: fields[1], Email: fields[2]},
+		Time:   time.Unix(ts, 0),
+	}
+
+	lines := strings.Split(strings.TrimSpace(fields[4]), "\n")
+	body := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.HasPrefix(line, GitVersionTrailer+": ") {
+			c.QriVersion = strings.TrimPrefix(line, GitVersionTrailer+": ")
+			continue
+		}
+		body = append(body, line)
+	}
+	if len(body) > 0 {
+		c.Title = body[0]
+		c.Message = strings.TrimSpace(strings.Join(body[1:], "\n"))
+	}
+	return c, nil
+}
@@ -0,0 +1,109 @@
+package fsi
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+func TestGitBridge(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git executable not found")
+	}
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "qri_test_git_bridge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "tester"},
+		{"config", "user.email", "tester@example.com"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s", args, out)
+		}
+	}
+
+	if _, err := NewGitBridge(ctx, os.TempDir(), "qri/cities"); err == nil {
+		t.Errorf("expected error bridging to a directory that isn't a git repository")
+	}
+	if _, err := NewGitBridge(ctx, dir, "bad..branch"); err == nil {
+		t.Errorf("expected error for invalid branch name")
+	}
+
+	g, err := NewGitBridge(ctx, dir, "qri/cities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head, err := g.Head(ctx); err != nil || head != nil {
+		t.Fatalf("expected no head for a new branch, got: %v, %v", head, err)
+	}
+
+	fs := qfs.NewMemFS()
+	versions := []*dataset.Dataset{
+		{
+			Path:   "/mem/QmVersionOne",
+			Meta:   &dataset.Meta{Title: "cities"},
+			Commit: &dataset.Commit{Title: "created dataset", Timestamp: time.Unix(1000, 0)},
+		},
+		{
+			Path:   "/mem/QmVersionTwo",
+			Meta:   &dataset.Meta{Title: "world cities"},
+			Commit: &dataset.Commit{Title: "meta changed", Message: "title:\n\tchanged", Timestamp: time.Unix(2000, 0)},
+		},
+	}
+	commits := []string{}
+	for _, ds := range versions {
+		hash, err := g.Export(ctx, ds, fs, GitAuthor{Name: "peer", Email: "peer@example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits = append(commits, hash)
+	}
+
+	exported, err := g.Exported(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ds := range versions {
+		if exported[ds.Path] != commits[i] {
+			t.Errorf("version %s: expected commit %s, got %q", ds.Path, commits[i], exported[ds.Path])
+		}
+	}
+
+	head, err := g.Head(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Hash != commits[1] || head.QriVersion != "/mem/QmVersionTwo" {
+		t.Errorf("head mismatch. got hash %s, version %q", head.Hash, head.QriVersion)
+	}
+	if head.Title != "meta changed" || head.Message != "title:\n\tchanged" {
+		t.Errorf("commit message mismatch. got title %q, message %q", head.Title, head.Message)
+	}
+	if head.Author.Name != "peer" || head.Author.Email != "peer@example.com" || head.Time.Unix() != 2000 {
+		t.Errorf("author mismatch. got %v at %s", head.Author, head.Time)
+	}
+
+	ds, err := g.ReadCommit(ctx, commits[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Meta == nil || ds.Meta.Title != "cities" {
+		t.Errorf("expected to read meta from commit, got: %v", ds.Meta)
+	}
+
+	// exporting must leave the repository working tree & HEAD alone
+	if out, err := exec.Command("git", "-C", dir, "status", "--porcelain").CombinedOutput(); err != nil || len(out) != 0 {
+		t.Errorf("expected clean working tree, got %q (%v)", out, err)
+	}
+}
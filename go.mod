@@ -24,6 +24,7 @@ require (
 	github.com/ipfs/go-log v1.0.4
 	github.com/ipfs/interface-go-ipfs-core v0.3.0
 	github.com/jinzhu/copier v0.0.0-20190924061706-b57f9002281a
+	github.com/lib/pq v1.10.9
 	github.com/libp2p/go-libp2p v0.11.0
 	github.com/libp2p/go-libp2p-circuit v0.3.1
 	github.com/libp2p/go-libp2p-connmgr v0.2.4
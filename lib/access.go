@@ -3,9 +3,12 @@ package lib
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/qri-io/qri/auth/key"
 	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/profile"
 )
 
@@ -22,7 +25,17 @@ func (m AccessMethods) Name() string {
 // Attributes defines attributes for each method
 func (m AccessMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
-		"createauthtoken": {AECreateAuthToken, "GET"},
+		"createauthtoken":  {endpoint: AECreateAuthToken, verb: "GET"},
+		"devicecode":       {endpoint: AEOAuthDeviceCode, verb: "POST"},
+		"approvedevice":    {endpoint: AEOAuthDeviceApprove, verb: "POST", operator: true},
+		"token":            {endpoint: AEOAuthToken, verb: "POST"},
+		"sessions":         {endpoint: AESessions, verb: "POST"},
+		"terminatesession": {endpoint: AETerminateSession, verb: "POST"},
+		"createembedtoken": {endpoint: AECreateEmbedToken, verb: "POST"},
+		"embedtokens":      {endpoint: AEEmbedTokens, verb: "POST"},
+		"revokeembedtoken": {endpoint: AERevokeEmbedToken, verb: "POST"},
+		"protection":       {endpoint: AEProtection, verb: "POST"},
+		"protect":          {endpoint: AEProtect, verb: "POST"},
 	}
 }
 
@@ -60,6 +73,263 @@ func (m AccessMethods) CreateAuthToken(ctx context.Context, p *CreateAuthTokenPa
 	return "", err
 }
 
+// DeviceCodeParams are input parameters for Access().DeviceCode
+type DeviceCodeParams struct{}
+
+// DeviceCode starts an OAuth 2.0 device code flow. The returned user code is
+// approved with Access().ApproveDevice, while the client polls Access().Token
+// with the device code
+func (m AccessMethods) DeviceCode(ctx context.Context, p *DeviceCodeParams) (*token.DeviceAuthorization, error) {
+	res, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "devicecode"), p)
+	if da, ok := res.(*token.DeviceAuthorization); ok {
+		return da, err
+	}
+	return nil, dispatchReturnError(res, err)
+}
+
+// ApproveDeviceParams are input parameters for Access().ApproveDevice
+type ApproveDeviceParams struct {
+	UserCode        string
+	GranteeUsername string
+	Deny            bool
+}
+
+// Validate returns an error if input params are invalid
+func (p *ApproveDeviceParams) Validate() error {
+	if p.UserCode == "" {
+		return fmt.Errorf("user code is required")
+	}
+	if p.GranteeUsername == "" && !p.Deny {
+		return fmt.Errorf("grantee username is required to approve a device, use \"me\" for the active user")
+	}
+	return nil
+}
+
+// ApproveDevice grants, or with Deny set, rejects a pending device code.
+// Approving a device hands out an access token, only operators may approve
+func (m AccessMethods) ApproveDevice(ctx context.Context, p *ApproveDeviceParams) error {
+	_, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "approvedevice"), p)
+	return err
+}
+
+// OAuthTokenParams are input parameters for Access().Token, field names
+// follow OAuth 2.0 token requests
+type OAuthTokenParams struct {
+	GrantType    string `json:"grant_type"`
+	DeviceCode   string `json:"device_code"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// UnmarshalFromRequest implements a custom deserialization-from-HTTP request
+func (p *OAuthTokenParams) UnmarshalFromRequest(r *http.Request) error {
+	p.GrantType = r.FormValue("grant_type")
+	p.DeviceCode = r.FormValue("device_code")
+	p.RefreshToken = r.FormValue("refresh_token")
+	return nil
+}
+
+// Validate returns an error if input params are invalid
+func (p *OAuthTokenParams) Validate() error {
+	if p.GrantType == "" {
+		return fmt.Errorf("grant_type is required")
+	}
+	return nil
+}
+
+// Token exchanges an approved device code or a refresh token for a
+// short-lived access token and a new refresh token
+func (m AccessMethods) Token(ctx context.Context, p *OAuthTokenParams) (*token.Response, error) {
+	res, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "token"), p)
+	if tr, ok := res.(*token.Response); ok {
+		return tr, err
+	}
+	return nil, dispatchReturnError(res, err)
+}
+
+// SessionsParams are input parameters for Access().Sessions
+type SessionsParams struct{}
+
+// Sessions lists clients that have made authenticated requests as the active
+// user, most recently seen first
+func (m AccessMethods) Sessions(ctx context.Context, p *SessionsParams) ([]token.Session, error) {
+	res, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "sessions"), p)
+	if sessions, ok := res.([]token.Session); ok {
+		return sessions, err
+	}
+	return nil, dispatchReturnError(res, err)
+}
+
+// TerminateSessionParams are input parameters for Access().TerminateSession
+type TerminateSessionParams struct {
+	// ID of the session to terminate
+	ID string `json:"id"`
+	// All terminates every session for the active user except the session
+	// making the request, signing out everywhere else. API requests without
+	// an access token can no longer act as the user
+	All bool `json:"all"`
+}
+
+// Validate returns an error if input params are invalid
+func (p *TerminateSessionParams) Validate() error {
+	if p.ID == "" && !p.All {
+		return fmt.Errorf("either a session id or all is required")
+	}
+	if p.ID != "" && p.All {
+		return fmt.Errorf("cannot terminate a single session and all sessions at once")
+	}
+	return nil
+}
+
+// TerminateSessionResponse reports how many sessions were terminated
+type TerminateSessionResponse struct {
+	Terminated int `json:"terminated"`
+}
+
+// TerminateSession ends client sessions, revoking their access tokens. Further
+// requests made with a revoked token are rejected
+func (m AccessMethods) TerminateSession(ctx context.Context, p *TerminateSessionParams) (*TerminateSessionResponse, error) {
+	res, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "terminatesession"), p)
+	if tr, ok := res.(*TerminateSessionResponse); ok {
+		return tr, err
+	}
+	return nil, dispatchReturnError(res, err)
+}
+
+// CreateEmbedTokenParams are input parameters for Access().CreateEmbedToken
+type CreateEmbedTokenParams struct {
+	// Ref of the dataset the token grants access to
+	Ref string `json:"ref"`
+	// TTL is how long the token is valid for, defaults to DefaultEmbedTokenTTL
+	TTL time.Duration `json:"ttl"`
+	// NoExpiry creates a token that's valid until revoked
+	NoExpiry bool `json:"noExpiry"`
+	// Note describes where the token is used, like the page it's embedded in
+	Note string `json:"note"`
+}
+
+// SetNonZeroDefaults uses the default embed token time-to-live if one isn't
+// set
+func (p *CreateEmbedTokenParams) SetNonZeroDefaults() {
+	if p.TTL == 0 && !p.NoExpiry {
+		p.TTL = DefaultEmbedTokenTTL
+	}
+}
+
+// Validate returns an error if input params are invalid
+func (p *CreateEmbedTokenParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("%w: a dataset reference is required", ErrBadArgs)
+	}
+	if p.TTL < 0 {
+		return fmt.Errorf("%w: ttl cannot be negative", ErrBadArgs)
+	}
+	return nil
+}
+
+// CreateEmbedToken issues a signed, expiring token that grants read access to
+// the preview & body of a single dataset through the API. Tokens are passed
+// as a "token" query parameter, so they can be embedded in web pages
+func (m AccessMethods) CreateEmbedToken(ctx context.Context, p *CreateEmbedTokenParams) (*EmbedToken, error) {
+	res, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "createembedtoken"), p)
+	if t, ok := res.(*EmbedToken); ok {
+		return t, err
+	}
+	return nil, dispatchReturnError(res, err)
+}
+
+// EmbedTokensParams are input parameters for Access().EmbedTokens
+type EmbedTokensParams struct {
+	// Ref limits results to tokens for a single dataset
+	Ref string `json:"ref"`
+}
+
+// EmbedTokens lists issued embed tokens with their usage counts, most
+// recently issued first. Signed token strings aren't included
+func (m AccessMethods) EmbedTokens(ctx context.Context, p *EmbedTokensParams) ([]EmbedToken, error) {
+	res, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "embedtokens"), p)
+	if ts, ok := res.([]EmbedToken); ok {
+		return ts, err
+	}
+	return nil, dispatchReturnError(res, err)
+}
+
+// RevokeEmbedTokenParams are input parameters for Access().RevokeEmbedToken
+type RevokeEmbedTokenParams struct {
+	ID string `json:"id"`
+}
+
+// Validate returns an error if input params are invalid
+func (p *RevokeEmbedTokenParams) Validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("%w: a token id is required", ErrBadArgs)
+	}
+	return nil
+}
+
+// RevokeEmbedToken revokes an embed token. Further reads made with the token
+// are rejected
+func (m AccessMethods) RevokeEmbedToken(ctx context.Context, p *RevokeEmbedTokenParams) (*EmbedToken, error) {
+	res, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "revokeembedtoken"), p)
+	if t, ok := res.(*EmbedToken); ok {
+		return t, err
+	}
+	return nil, dispatchReturnError(res, err)
+}
+
+// ProtectionParams are input parameters for Access().Protection
+type ProtectionParams struct {
+	Ref string `json:"ref"`
+}
+
+// Validate returns an error if input params are invalid
+func (p *ProtectionParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("%w: dataset reference is required", ErrBadArgs)
+	}
+	return nil
+}
+
+// Protection gets the protection rules of a dataset
+func (m AccessMethods) Protection(ctx context.Context, p *ProtectionParams) (*logbook.Protection, error) {
+	res, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "protection"), p)
+	if pr, ok := res.(*logbook.Protection); ok {
+		return pr, err
+	}
+	return nil, dispatchReturnError(res, err)
+}
+
+// ProtectParams are input parameters for Access().Protect
+type ProtectParams struct {
+	Ref string `json:"ref"`
+	// NoRewrite forbids amending & deleting versions
+	NoRewrite bool `json:"noRewrite"`
+	// RequireSuccessfulRuns only allows versions created by a successful
+	// transform run
+	RequireSuccessfulRuns bool `json:"requireSuccessfulRuns"`
+	// NoDelete forbids deleting the dataset
+	NoDelete bool `json:"noDelete"`
+}
+
+// Validate returns an error if input params are invalid
+func (p *ProtectParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("%w: dataset reference is required", ErrBadArgs)
+	}
+	return nil
+}
+
+// Protect sets the protection rules of a dataset, replacing any earlier
+// rules. Protected datasets reject operations that break the rules, both when
+// written locally & when merged from logs pushed by other peers. Setting no
+// rules clears protection
+func (m AccessMethods) Protect(ctx context.Context, p *ProtectParams) (*logbook.Protection, error) {
+	res, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "protect"), p)
+	if pr, ok := res.(*logbook.Protection); ok {
+		return pr, err
+	}
+	return nil, dispatchReturnError(res, err)
+}
+
 // accessImpl is the backing implementation for AccessMethods
 type accessImpl struct{}
 
@@ -93,3 +363,213 @@ func (accessImpl) CreateAuthToken(scp scope, p *CreateAuthTokenParams) (string,
 
 	return token.NewPrivKeyAuthToken(pk, grantee.ID.String(), p.TTL)
 }
+
+func (accessImpl) DeviceCode(scp scope, p *DeviceCodeParams) (*token.DeviceAuthorization, error) {
+	x, err := scp.inst.oauthExchange()
+	if err != nil {
+		return nil, err
+	}
+	return x.NewDeviceAuthorization()
+}
+
+func (accessImpl) ApproveDevice(scp scope, p *ApproveDeviceParams) error {
+	x, err := scp.inst.oauthExchange()
+	if err != nil {
+		return err
+	}
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return err
+	}
+	if p.Deny {
+		return x.DenyDevice(p.UserCode)
+	}
+
+	grantee := scp.ActiveProfile()
+	if p.GranteeUsername != "me" {
+		if grantee, err = profile.ResolveUsername(scp.Profiles(), p.GranteeUsername); err != nil {
+			return err
+		}
+	}
+	return x.ApproveDevice(p.UserCode, grantee.ID.String())
+}
+
+func (accessImpl) Token(scp scope, p *OAuthTokenParams) (*token.Response, error) {
+	x, err := scp.inst.oauthExchange()
+	if err != nil {
+		return nil, err
+	}
+	code := p.DeviceCode
+	if p.GrantType == token.GrantTypeRefreshToken {
+		code = p.RefreshToken
+	}
+	return x.Token(p.GrantType, code)
+}
+
+func (accessImpl) Sessions(scp scope, p *SessionsParams) ([]token.Session, error) {
+	return scp.inst.sessions.List(scp.ActiveProfile().ID.String())
+}
+
+func (accessImpl) TerminateSession(scp scope, p *TerminateSessionParams) (*TerminateSessionResponse, error) {
+	profileID := scp.ActiveProfile().ID.String()
+
+	if p.All {
+		// keep the session making this request
+		current := ""
+		if raw := token.FromCtx(scp.Context()); raw != "" {
+			current, _ = token.RawTokenID(raw)
+		}
+		n, err := scp.inst.sessions.TerminateAll(profileID, current)
+		if err != nil {
+			return nil, err
+		}
+		return &TerminateSessionResponse{Terminated: n}, nil
+	}
+
+	// users can only terminate their own sessions
+	sessions, err := scp.inst.sessions.List(profileID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sess := range sessions {
+		if sess.ID == p.ID {
+			if err := scp.inst.sessions.Terminate(p.ID); err != nil {
+				return nil, err
+			}
+			return &TerminateSessionResponse{Terminated: 1}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", token.ErrSessionNotFound, p.ID)
+}
+
+// embed tokens read datasets as the node owner, so only the owner & operators
+// can create, list & revoke them
+func (accessImpl) CreateEmbedToken(scp scope, p *CreateEmbedTokenParams) (*EmbedToken, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+	ref, _, err := scp.ParseAndResolveRef(scp.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	src, err := scp.inst.embedTokenSource()
+	if err != nil {
+		return nil, err
+	}
+	// in-process calls skip SetNonZeroDefaults
+	ttl := p.TTL
+	if ttl == 0 && !p.NoExpiry {
+		ttl = DefaultEmbedTokenTTL
+	}
+	raw, id, err := token.NewEmbedToken(src, ref.InitID, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	t := EmbedToken{
+		ID:     id,
+		Ref:    ref.Alias(),
+		InitID: ref.InitID,
+		Note:   p.Note,
+		Issued: token.Timestamp(),
+	}
+	if ttl != 0 {
+		t.Expires = t.Issued.Add(ttl)
+	}
+	if err := scp.inst.embedTokens.Put(t); err != nil {
+		return nil, err
+	}
+	t.Token = raw
+	return &t, nil
+}
+
+func (accessImpl) EmbedTokens(scp scope, p *EmbedTokensParams) ([]EmbedToken, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+	initID := ""
+	if p.Ref != "" {
+		ref, _, err := scp.ParseAndResolveRef(scp.Context(), p.Ref, "local")
+		if err != nil {
+			return nil, err
+		}
+		initID = ref.InitID
+	}
+	return scp.inst.embedTokens.List(initID)
+}
+
+func (accessImpl) RevokeEmbedToken(scp scope, p *RevokeEmbedTokenParams) (*EmbedToken, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+	return scp.inst.embedTokens.Revoke(p.ID)
+}
+
+func (accessImpl) Protection(scp scope, p *ProtectionParams) (*logbook.Protection, error) {
+	ref, _, err := scp.ParseAndResolveRef(scp.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	pr, err := scp.Logbook().DatasetProtection(scp.Context(), ref.InitID)
+	if err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// protection rules are written to the owner's logbook, only the owner &
+// operators can change them
+func (accessImpl) Protect(scp scope, p *ProtectParams) (*logbook.Protection, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+	ref, _, err := scp.ParseAndResolveRef(scp.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	pr := logbook.Protection{
+		NoRewrite:             p.NoRewrite,
+		RequireSuccessfulRuns: p.RequireSuccessfulRuns,
+		NoDelete:              p.NoDelete,
+	}
+	if err := scp.Logbook().WriteDatasetProtection(scp.Context(), ref.InitID, pr); err != nil {
+		return nil, err
+	}
+	if pr, err = scp.Logbook().DatasetProtection(scp.Context(), ref.InitID); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// oauthExchange returns the OAuth token exchange for this instance, creating
+// it on first use. Refresh tokens are signed with the owner's key
+func (inst *Instance) oauthExchange() (*token.Exchange, error) {
+	inst.oauthLk.Lock()
+	defer inst.oauthLk.Unlock()
+	if inst.oauth != nil {
+		return inst.oauth, nil
+	}
+
+	owner := inst.profiles.Owner()
+	if owner == nil || owner.PrivKey == nil {
+		return nil, fmt.Errorf("issuing tokens requires an owner profile with a private key")
+	}
+	source, err := token.NewPrivKeySource(owner.PrivKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := inst.keystore
+	if keys == nil {
+		// instances created without a keystore can only issue tokens for the
+		// owner
+		if keys, err = key.NewMemStore(); err != nil {
+			return nil, err
+		}
+		if err := keys.AddPrivKey(key.ID(owner.ID), owner.PrivKey); err != nil {
+			return nil, err
+		}
+	}
+
+	inst.oauth = token.NewExchange(source, keys)
+	return inst.oauth, nil
+}
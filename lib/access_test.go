@@ -2,11 +2,33 @@ package lib
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
+	testkeys "github.com/qri-io/qri/auth/key/test"
 	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/profile"
 )
 
+// nonOperatorCtx adds a profile that isn't the owner or an operator to inst,
+// returning a context carrying an access token for it
+func nonOperatorCtx(ctx context.Context, t *testing.T, inst *Instance) context.Context {
+	t.Helper()
+	kd := testkeys.GetKeyData(5)
+	user := &profile.Profile{ID: profile.IDFromPeerID(kd.PeerID), PrivKey: kd.PrivKey, PubKey: kd.PrivKey.GetPublic(), Peername: "marjorie"}
+	if err := inst.profiles.PutProfile(user); err != nil {
+		t.Fatal(err)
+	}
+	s, err := inst.Access().CreateAuthToken(ctx, &CreateAuthTokenParams{GranteeUsername: user.Peername})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token.AddToContext(ctx, s)
+}
+
 func TestAccessCreateAuthToken(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -45,3 +67,200 @@ func TestAccessValidationFailure(t *testing.T) {
 		t.Errorf("error mismatch, expect: %s, got: %s", expectErr, err)
 	}
 }
+
+func TestAccessSessions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inst, cleanup := NewMemTestInstance(ctx, t)
+	defer cleanup()
+
+	newSessionCtx := func(origin string) context.Context {
+		s, err := inst.Access().CreateAuthToken(ctx, &CreateAuthTokenParams{GranteeUsername: "me"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token.AddOriginToContext(token.AddToContext(ctx, s), origin)
+	}
+	desktop := newSessionCtx("desktop")
+	laptop := newSessionCtx("laptop")
+	phone := newSessionCtx("phone")
+
+	if _, err := inst.Access().Sessions(laptop, &SessionsParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inst.Access().Sessions(phone, &SessionsParams{}); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := inst.Access().Sessions(desktop, &SessionsParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %d", len(sessions))
+	}
+	if sessions[0].Origin != "desktop" {
+		t.Errorf("expected most recently seen session to come first, got origin %q", sessions[0].Origin)
+	}
+
+	// terminate the laptop session
+	var laptopID string
+	for _, sess := range sessions {
+		if sess.Origin == "laptop" {
+			laptopID = sess.ID
+		}
+	}
+	res, err := inst.Access().TerminateSession(desktop, &TerminateSessionParams{ID: laptopID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Terminated != 1 {
+		t.Errorf("expected 1 terminated session, got %d", res.Terminated)
+	}
+	if _, err := inst.Access().Sessions(laptop, &SessionsParams{}); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected terminated session to be rejected with ErrTokenRevoked, got: %v", err)
+	}
+
+	// sign out everywhere else
+	if res, err = inst.Access().TerminateSession(desktop, &TerminateSessionParams{All: true}); err != nil {
+		t.Fatal(err)
+	}
+	if res.Terminated != 1 {
+		t.Errorf("expected 1 terminated session, got %d", res.Terminated)
+	}
+	if _, err := inst.Access().Sessions(phone, &SessionsParams{}); !errors.Is(err, token.ErrTokenRevoked) {
+		t.Errorf("expected terminated session to be rejected with ErrTokenRevoked, got: %v", err)
+	}
+	if sessions, err = inst.Access().Sessions(desktop, &SessionsParams{}); err != nil {
+		t.Fatalf("expected requesting session to remain active: %s", err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("expected 1 remaining session, got %d", len(sessions))
+	}
+	tokenless := token.AddOriginToContext(ctx, "browser")
+	if _, err := inst.Access().Sessions(tokenless, &SessionsParams{}); !errors.Is(err, token.ErrTokenRequired) {
+		t.Errorf("expected tokenless requests to be rejected with ErrTokenRequired after signing out everywhere, got: %v", err)
+	}
+	if _, err := inst.Access().Sessions(ctx, &SessionsParams{}); err != nil {
+		t.Errorf("expected in-process calls to act as the owner, got: %s", err)
+	}
+
+	if _, err := inst.Access().TerminateSession(desktop, &TerminateSessionParams{ID: "unknown"}); !errors.Is(err, token.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got: %v", err)
+	}
+}
+
+func TestAccessEmbedTokens(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+	ctx, inst := tr.Ctx, tr.Instance
+
+	movies := tr.MustSaveFromBody(t, "movies", tr.MustWriteTmpFile(t, "movies.csv", "title,duration\nAvatar,178\n"))
+	cities := tr.MustSaveFromBody(t, "cities", tr.MustWriteTmpFile(t, "cities.csv", "city,pop\ntoronto,40000000\n"))
+	moviesRef := fmt.Sprintf("%s/%s", movies.Peername, movies.Name)
+	citiesRef := fmt.Sprintf("%s/%s", cities.Peername, cities.Name)
+
+	if _, err := inst.Access().CreateEmbedToken(ctx, &CreateEmbedTokenParams{}); !errors.Is(err, ErrBadArgs) {
+		t.Errorf("expected missing ref to fail with ErrBadArgs, got: %v", err)
+	}
+
+	et, err := inst.Access().CreateEmbedToken(ctx, &CreateEmbedTokenParams{Ref: moviesRef, Note: "blog post"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if et.Token == "" || et.Expires.IsZero() {
+		t.Fatalf("expected a signed, expiring token. got: %#v", et)
+	}
+
+	if _, err := inst.UseEmbedToken(ctx, et.Token, moviesRef); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inst.UseEmbedToken(ctx, et.Token, moviesRef); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inst.UseEmbedToken(ctx, et.Token, citiesRef); !errors.Is(err, ErrEmbedTokenScope) {
+		t.Errorf("expected using a token for another dataset to fail with ErrEmbedTokenScope, got: %v", err)
+	}
+	if _, err := inst.UseEmbedToken(ctx, "not.a.token", moviesRef); !errors.Is(err, token.ErrInvalidToken) {
+		t.Errorf("expected malformed token to fail with ErrInvalidToken, got: %v", err)
+	}
+
+	tokens, err := inst.Access().EmbedTokens(ctx, &EmbedTokensParams{Ref: moviesRef})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].Uses != 2 || tokens[0].Token != "" || tokens[0].Note != "blog post" {
+		t.Fatalf("expected one token used twice without the signed token string, got: %#v", tokens)
+	}
+	if tokens, err = inst.Access().EmbedTokens(ctx, &EmbedTokensParams{Ref: citiesRef}); err != nil {
+		t.Fatal(err)
+	} else if len(tokens) != 0 {
+		t.Errorf("expected no tokens for cities, got %d", len(tokens))
+	}
+
+	if _, err := inst.Access().RevokeEmbedToken(ctx, &RevokeEmbedTokenParams{ID: et.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inst.UseEmbedToken(ctx, et.Token, moviesRef); !errors.Is(err, ErrEmbedTokenRevoked) {
+		t.Errorf("expected revoked token to fail with ErrEmbedTokenRevoked, got: %v", err)
+	}
+	if _, err := inst.Access().RevokeEmbedToken(ctx, &RevokeEmbedTokenParams{ID: "unknown"}); !errors.Is(err, token.ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound, got: %v", err)
+	}
+
+	// embed tokens read as the owner, other profiles can't manage them
+	other := nonOperatorCtx(ctx, t, inst)
+	if _, err := inst.Access().CreateEmbedToken(other, &CreateEmbedTokenParams{Ref: moviesRef}); !errors.Is(err, ErrNotOperator) {
+		t.Errorf("expected non-operator create to fail with ErrNotOperator, got: %v", err)
+	}
+	if _, err := inst.Access().EmbedTokens(other, &EmbedTokensParams{}); !errors.Is(err, ErrNotOperator) {
+		t.Errorf("expected non-operator list to fail with ErrNotOperator, got: %v", err)
+	}
+	if _, err := inst.Access().RevokeEmbedToken(other, &RevokeEmbedTokenParams{ID: et.ID}); !errors.Is(err, ErrNotOperator) {
+		t.Errorf("expected non-operator revoke to fail with ErrNotOperator, got: %v", err)
+	}
+}
+
+func TestAccessProtection(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+	ctx, inst := tr.Ctx, tr.Instance
+
+	movies := tr.MustSaveFromBody(t, "movies", tr.MustWriteTmpFile(t, "movies.csv", "title,duration\nAvatar,178\n"))
+	moviesRef := fmt.Sprintf("%s/%s", movies.Peername, movies.Name)
+
+	if _, err := inst.Access().Protect(ctx, &ProtectParams{NoDelete: true}); !errors.Is(err, ErrBadArgs) {
+		t.Errorf("expected missing ref to fail with ErrBadArgs, got: %v", err)
+	}
+
+	pr, err := inst.Access().Protection(ctx, &ProtectionParams{Ref: moviesRef})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pr.IsZero() {
+		t.Errorf("expected dataset to start unprotected, got: %#v", pr)
+	}
+
+	if pr, err = inst.Access().Protect(ctx, &ProtectParams{Ref: moviesRef, NoRewrite: true, NoDelete: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !pr.NoRewrite || !pr.NoDelete || pr.RequireSuccessfulRuns {
+		t.Errorf("protection mismatch, got: %#v", pr)
+	}
+
+	_, err = inst.Dataset().Remove(ctx, &RemoveParams{Ref: moviesRef, Revision: dsref.NewAllRevisions()})
+	if !errors.Is(err, logbook.ErrProtected) {
+		t.Errorf("expected removing a protected dataset to fail with ErrProtected, got: %v", err)
+	}
+
+	// other profiles can't lift protections
+	other := nonOperatorCtx(ctx, t, inst)
+	if _, err := inst.Access().Protect(other, &ProtectParams{Ref: moviesRef}); !errors.Is(err, ErrNotOperator) {
+		t.Errorf("expected non-operator to be refused with ErrNotOperator, got: %v", err)
+	}
+	if pr, err = inst.Access().Protection(ctx, &ProtectionParams{Ref: moviesRef}); err != nil {
+		t.Fatal(err)
+	}
+	if !pr.NoRewrite || !pr.NoDelete {
+		t.Errorf("expected refused call to leave protections in place, got: %#v", pr)
+	}
+}
@@ -0,0 +1,684 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/ipfs/go-ipfs/core/corerepo"
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qfs/qipfs"
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/remote"
+	"github.com/qri-io/qri/repo/backup"
+	"github.com/qri-io/qri/version"
+)
+
+var (
+	// ErrNotOperator is returned when a user without the operator role calls
+	// an admin method
+	ErrNotOperator = errors.New("admin methods require the operator role")
+	// ErrGCUnsupported is returned when the instance filesystem can't be
+	// garbage collected
+	ErrGCUnsupported = errors.New("garbage collection requires an IPFS filesystem")
+	// ErrNotRemote is returned when remote hosting methods are called on an
+	// instance that isn't running as a remote
+	ErrNotRemote = errors.New("instance isn't running as a remote")
+)
+
+// AdminMethods groups methods for operating a running node. Admin methods
+// require the operator role: the repo owner, or a profile listed in the
+// api.operators config field
+type AdminMethods struct {
+	d dispatcher
+}
+
+// Name returns the name of this method group
+func (m AdminMethods) Name() string {
+	return "admin"
+}
+
+// Attributes defines attributes for each method
+func (m AdminMethods) Attributes() map[string]AttributeSet {
+	return map[string]AttributeSet{
+		"status":         {endpoint: AEAdminStatus, verb: "POST", operator: true},
+		"reloadconfig":   {endpoint: AEAdminReloadConfig, verb: "POST", operator: true},
+		"gc":             {endpoint: AEAdminGC, verb: "POST", operator: true},
+		"connections":    {endpoint: AEAdminConnections, verb: "POST", operator: true},
+		"connect":        {endpoint: AEAdminConnect, verb: "POST", operator: true},
+		"disconnect":     {endpoint: AEAdminDisconnect, verb: "POST", operator: true},
+		"setloglevel":    {endpoint: AEAdminLogLevel, verb: "POST", operator: true},
+		"verifyrepo":     {endpoint: AEAdminVerifyRepo, verb: "POST", operator: true},
+		"repairrefs":     {endpoint: AEAdminRepairRefs, verb: "POST", operator: true},
+		"doctor":         {endpoint: AEAdminDoctor, verb: "POST", operator: true},
+		"audit":          {endpoint: AEAdminAudit, verb: "POST", operator: true},
+		"hosted":         {endpoint: AERemoteHosted, verb: "POST", operator: true},
+		"hostingstatus":  {endpoint: AERemoteHostingStatus, verb: "POST", operator: true},
+		"hostingstorage": {endpoint: AERemoteStorage, verb: "POST", operator: true},
+		"drophosted":     {endpoint: AERemoteDrop, verb: "POST", operator: true},
+	}
+}
+
+// AdminStatusParams are input parameters for Admin().Status
+type AdminStatusParams struct{}
+
+// AdminStatus describes a running instance
+type AdminStatus struct {
+	Version       string    `json:"version"`
+	ProfileID     string    `json:"profileID"`
+	Peername      string    `json:"peername"`
+	Started       time.Time `json:"started"`
+	Uptime        string    `json:"uptime"`
+	Online        bool      `json:"online"`
+	PeerID        string    `json:"peerID,omitempty"`
+	Addresses     []string  `json:"addresses,omitempty"`
+	Connections   int       `json:"connections"`
+	Datasets      int       `json:"datasets"`
+	ReadOnly      bool      `json:"readOnly"`
+	ConfigProfile string    `json:"configProfile,omitempty"`
+	Goroutines    int       `json:"goroutines"`
+	MemoryAlloc   uint64    `json:"memoryAlloc"`
+}
+
+// Status reports the state of the instance
+func (m AdminMethods) Status(ctx context.Context, p *AdminStatusParams) (*AdminStatus, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "status"), p)
+	if res, ok := got.(*AdminStatus); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// ReloadConfigParams are input parameters for Admin().ReloadConfig
+type ReloadConfigParams struct{}
+
+// ReloadConfig re-reads configuration from disk, applying the active config
+// profile if one is set. Logging levels take effect immediately, other
+// settings apply to subsequent method calls. Services that read
+// configuration at startup, like p2p & remote, need a restart to pick up
+// changes. The new configuration is returned without private values
+func (m AdminMethods) ReloadConfig(ctx context.Context, p *ReloadConfigParams) (*config.Config, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "reloadconfig"), p)
+	if res, ok := got.(*config.Config); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// GCParams are input parameters for Admin().GC
+type GCParams struct{}
+
+// GCResult reports the outcome of garbage collection
+type GCResult struct {
+	SizeBefore uint64 `json:"sizeBefore"`
+	SizeAfter  uint64 `json:"sizeAfter"`
+	Duration   string `json:"duration"`
+	// RefRepairs reports dataset references repaired after collecting
+	RefRepairs *RefRepairReport `json:"refRepairs,omitempty"`
+	// TrashPurged lists removed datasets purged from the trash before
+	// collecting, after their grace period expired
+	TrashPurged []TrashedDataset `json:"trashPurged,omitempty"`
+}
+
+// GC removes unpinned blocks from the IPFS repo, first purging removed
+// datasets whose trash grace period has expired
+func (m AdminMethods) GC(ctx context.Context, p *GCParams) (*GCResult, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "gc"), p)
+	if res, ok := got.(*GCResult); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// Connections lists peers the node is connected to
+func (m AdminMethods) Connections(ctx context.Context, p *ConnectionsParams) ([]string, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "connections"), p)
+	if res, ok := got.([]string); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// Connect opens a connection to a peer
+func (m AdminMethods) Connect(ctx context.Context, p *ConnectParamsPod) (*config.ProfilePod, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "connect"), p)
+	if res, ok := got.(*config.ProfilePod); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// Disconnect closes a connection to a peer
+func (m AdminMethods) Disconnect(ctx context.Context, p *ConnectParamsPod) error {
+	_, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "disconnect"), p)
+	return err
+}
+
+// SetLogLevelParams are input parameters for Admin().SetLogLevel
+type SetLogLevelParams struct {
+	// Subsystem is the logger to change, eg: "lib". "*" changes all loggers
+	Subsystem string `json:"subsystem"`
+	// Level is one of debug, info, warn, error, dpanic, panic, or fatal
+	Level string `json:"level"`
+}
+
+// Validate returns an error if input params are invalid
+func (p *SetLogLevelParams) Validate() error {
+	if p.Subsystem == "" {
+		return fmt.Errorf("subsystem is required")
+	}
+	if p.Level == "" {
+		return fmt.Errorf("level is required")
+	}
+	return nil
+}
+
+// SetLogLevel changes the level of a logger while the node is running. The
+// change isn't saved to configuration
+func (m AdminMethods) SetLogLevel(ctx context.Context, p *SetLogLevelParams) error {
+	_, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "setloglevel"), p)
+	return err
+}
+
+// VerifyRepoParams are input parameters for Admin().VerifyRepo
+type VerifyRepoParams struct {
+	// Repair re-fetches versions that are remote-only or invalid
+	Repair bool `json:"repair"`
+	// Remote is the location to repair from, defaults to the network
+	Remote string `json:"remote"`
+}
+
+// RepoReport is the result of verifying a repo
+type RepoReport = base.RepoReport
+
+// VerifyRepo checks the structural integrity of every dataset version the
+// repo references: that all component blocks are stored locally, and that
+// components parse & validate. Repairing re-fetches bad versions of named
+// datasets, versions are re-checked after fetching
+func (m AdminMethods) VerifyRepo(ctx context.Context, p *VerifyRepoParams) (*RepoReport, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "verifyrepo"), p)
+	if res, ok := got.(*RepoReport); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// RepairRefsParams are input parameters for Admin().RepairRefs
+type RepairRefsParams struct {
+	// DryRun reports broken references without changing them
+	DryRun bool `json:"dryRun"`
+}
+
+// RefRepairReport lists repaired & irrecoverable dataset references
+type RefRepairReport = base.RefRepairReport
+
+// RepairRefs finds dataset references that point at versions with missing
+// or corrupt blocks, like references left behind after the IPFS repo is
+// garbage collected outside of qri, and moves each to the latest intact
+// version in the dataset's history. Datasets without an intact version are
+// reported as irrecoverable
+func (m AdminMethods) RepairRefs(ctx context.Context, p *RepairRefsParams) (*RefRepairReport, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "repairrefs"), p)
+	if res, ok := got.(*RefRepairReport); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// DoctorParams are input parameters for Admin().Doctor
+type DoctorParams struct{}
+
+// DoctorReport describes the health of an instance
+type DoctorReport struct {
+	Readiness
+	// Bodies lists checks of recently saved bodies too big to diff
+	Bodies []dsfs.BodyCheck `json:"bodies"`
+	// Corrupt counts bodies that don't match their checksum
+	Corrupt int `json:"corrupt"`
+	// Integrity lists logbook & dscache file checks made at startup,
+	// including files restored from backup
+	Integrity []backup.Check `json:"integrity"`
+}
+
+// Doctor checks the subsystems an instance depends on, and immediately
+// recomputes checksums of recently saved large dataset bodies that are
+// otherwise checked in the background
+func (m AdminMethods) Doctor(ctx context.Context, p *DoctorParams) (*DoctorReport, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "doctor"), p)
+	if res, ok := got.(*DoctorReport); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// AuditParams are input parameters for Admin().Audit
+type AuditParams struct {
+	// Limit caps the number of records returned, defaults to DefaultPageSize.
+	// -1 returns every record
+	Limit int `json:"limit"`
+}
+
+// SetNonZeroDefaults sets a default limit
+func (p *AuditParams) SetNonZeroDefaults() {
+	if p.Limit == 0 {
+		p.Limit = DefaultPageSize
+	}
+}
+
+// Audit lists calls operators made on behalf of other profiles, most recent
+// first. Refused calls are listed with an error
+func (m AdminMethods) Audit(ctx context.Context, p *AuditParams) ([]AuditRecord, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "audit"), p)
+	if res, ok := got.([]AuditRecord); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// HostedParams are input parameters for Admin().Hosted
+type HostedParams struct {
+	Offset int `json:"offset"`
+	// Limit defaults to DefaultPageSize. -1 lists every dataset
+	Limit int `json:"limit"`
+}
+
+// SetNonZeroDefaults sets a default limit
+func (p *HostedParams) SetNonZeroDefaults() {
+	if p.Limit == 0 {
+		p.Limit = DefaultPageSize
+	}
+}
+
+// Hosted lists datasets stored by an instance running as a remote, with the
+// hosting status & size of each
+func (m AdminMethods) Hosted(ctx context.Context, p *HostedParams) ([]remote.HostedDataset, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "hosted"), p)
+	if res, ok := got.([]remote.HostedDataset); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// HostingRefParams are input parameters for admin methods that act on a
+// single hosted dataset
+type HostingRefParams struct {
+	Ref string `json:"ref"`
+}
+
+// Validate requires a dataset reference
+func (p *HostingRefParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("ref is required")
+	}
+	return nil
+}
+
+// HostingStatus reports how a dataset is stored by an instance running as a
+// remote
+func (m AdminMethods) HostingStatus(ctx context.Context, p *HostingRefParams) (*remote.HostedDataset, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "hostingstatus"), p)
+	if res, ok := got.(*remote.HostedDataset); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// HostingStorageParams are input parameters for Admin().HostingStorage
+type HostingStorageParams struct {
+	// Limit caps the number of largest datasets listed, defaults to 10. -1
+	// lists every dataset
+	Limit int `json:"limit"`
+}
+
+// SetNonZeroDefaults sets a default limit
+func (p *HostingStorageParams) SetNonZeroDefaults() {
+	if p.Limit == 0 {
+		p.Limit = 10
+	}
+}
+
+// HostingStorage summarizes storage consumed by datasets an instance running
+// as a remote stores, listing the largest datasets
+func (m AdminMethods) HostingStorage(ctx context.Context, p *HostingStorageParams) (*remote.HostingStorage, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "hostingstorage"), p)
+	if res, ok := got.(*remote.HostingStorage); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// DropHosted removes all stored data & history of a dataset from an instance
+// running as a remote. Dropping isn't subject to the remote access policy
+func (m AdminMethods) DropHosted(ctx context.Context, p *HostingRefParams) error {
+	_, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "drophosted"), p)
+	return err
+}
+
+// CheckOperator returns an error wrapping ErrNotOperator if the user making
+// a request doesn't have the operator role
+func (inst *Instance) CheckOperator(ctx context.Context) error {
+	pro, err := inst.activeProfile(ctx)
+	if err != nil {
+		return err
+	}
+	if owner := inst.profiles.Owner(); owner != nil && owner.ID == pro.ID {
+		return nil
+	}
+	if inst.cfg != nil && inst.cfg.API != nil {
+		for _, id := range inst.cfg.API.Operators {
+			if id == pro.ID.String() {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %q isn't an operator", ErrNotOperator, pro.Peername)
+}
+
+// adminImpl holds the method implementations for AdminMethods
+type adminImpl struct{}
+
+func (adminImpl) Status(scp scope, p *AdminStatusParams) (*AdminStatus, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+
+	mem := runtime.MemStats{}
+	runtime.ReadMemStats(&mem)
+	cfg := scp.Config()
+	owner := scp.Profiles().Owner()
+
+	res := &AdminStatus{
+		Version:       version.Version,
+		ProfileID:     owner.ID.String(),
+		Peername:      owner.Peername,
+		Started:       scp.inst.started,
+		Uptime:        time.Since(scp.inst.started).Round(time.Second).String(),
+		ConfigProfile: cfg.Overlay(),
+		Goroutines:    runtime.NumGoroutine(),
+		MemoryAlloc:   mem.Alloc,
+	}
+	if cfg.API != nil {
+		res.ReadOnly = cfg.API.ReadOnly
+	}
+	if n, err := scp.Repo().RefCount(); err == nil {
+		res.Datasets = n
+	}
+	if node := scp.Node(); node != nil {
+		res.Online = node.Online
+		if node.Online {
+			res.PeerID = node.ID.Pretty()
+			for _, addr := range node.EncapsulatedAddresses() {
+				res.Addresses = append(res.Addresses, addr.String())
+			}
+			res.Connections = len(node.ConnectedPeers())
+		}
+	}
+	return res, nil
+}
+
+func (adminImpl) ReloadConfig(scp scope, p *ReloadConfigParams) (*config.Config, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+
+	inst := scp.inst
+	path := inst.cfg.Path()
+	if path == "" {
+		if inst.repoPath == "" {
+			return nil, fmt.Errorf("instance has no config file to reload")
+		}
+		path = filepath.Join(inst.repoPath, "config.yaml")
+	}
+	cfg, err := config.ReadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if name := inst.cfg.Overlay(); name != "" {
+		overlay, err := config.ReadOverlay(inst.repoPath, name)
+		if err != nil {
+			return nil, err
+		}
+		if cfg, err = cfg.WithOverlay(name, overlay); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	inst.cfg = cfg
+	if cfg.Logging != nil {
+		for name, level := range cfg.Logging.Levels {
+			golog.SetLogLevel(name, level)
+		}
+	}
+	log.Infow("reloaded config", "path", path)
+	return cfg.WithoutPrivateValues(), nil
+}
+
+func (adminImpl) GC(scp scope, p *GCParams) (*GCResult, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+
+	fs, ok := scp.Filesystem().Filesystem(qipfs.FilestoreType).(*qipfs.Filestore)
+	if !ok || fs.Node() == nil {
+		return nil, ErrGCUnsupported
+	}
+	ctx := scp.Context()
+	node := fs.Node()
+
+	res := &GCResult{}
+	if st, err := corerepo.RepoStat(ctx, node); err == nil {
+		res.SizeBefore = st.RepoSize
+	}
+	// unpin expired trash so collecting frees its blocks
+	purged, err := scp.inst.purgeExpiredTrash(ctx, scp.Repo())
+	if err != nil {
+		log.Debugw("gc: purging expired trash", "err", err)
+	}
+	if len(purged) > 0 {
+		res.TrashPurged = purged
+	}
+	start := time.Now()
+	if err := corerepo.GarbageCollect(node, ctx); err != nil {
+		return nil, err
+	}
+	res.Duration = time.Since(start).String()
+	if st, err := corerepo.RepoStat(ctx, node); err == nil {
+		res.SizeAfter = st.RepoSize
+	}
+	// collecting only removes unpinned blocks, but a repo that was collected
+	// before can still hold references to missing versions
+	if rep, err := base.RepairRefs(ctx, scp.Repo(), false); err != nil {
+		log.Debugw("gc: repairing refs", "err", err)
+	} else if rep.Repaired > 0 || rep.Irrecoverable > 0 {
+		res.RefRepairs = rep
+	}
+	return res, nil
+}
+
+func (adminImpl) RepairRefs(scp scope, p *RepairRefsParams) (*RefRepairReport, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+	rep, err := base.RepairRefs(scp.Context(), scp.Repo(), p.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if !p.DryRun && rep.Repaired > 0 {
+		for _, r := range rep.Repairs {
+			if r.Status == base.RefRepaired {
+				log.Infow("repaired dataset reference", "ref", r.Ref.Alias(), "from", r.PrevPath, "to", r.Path)
+			}
+		}
+	}
+	return rep, nil
+}
+
+func (adminImpl) Connections(scp scope, p *ConnectionsParams) ([]string, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+	return peerImpl{}.Connections(scp, p)
+}
+
+func (adminImpl) Connect(scp scope, p *ConnectParamsPod) (*config.ProfilePod, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+	return peerImpl{}.Connect(scp, p)
+}
+
+func (adminImpl) Disconnect(scp scope, p *ConnectParamsPod) error {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return err
+	}
+	return peerImpl{}.Disconnect(scp, p)
+}
+
+func (adminImpl) SetLogLevel(scp scope, p *SetLogLevelParams) error {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return err
+	}
+	if err := golog.SetLogLevel(p.Subsystem, p.Level); err != nil {
+		return err
+	}
+	log.Infow("set log level", "subsystem", p.Subsystem, "level", p.Level, "by", token.OriginFromCtx(scp.Context()))
+	return nil
+}
+
+func (adminImpl) VerifyRepo(scp scope, p *VerifyRepoParams) (*RepoReport, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+
+	report, err := base.VerifyRepo(scp.Context(), scp.Repo())
+	if err != nil {
+		return nil, err
+	}
+	if !p.Repair {
+		return report, nil
+	}
+
+	source := p.Remote
+	if source == "" {
+		source = "network"
+	}
+	// repairing is an intentional network fetch, don't time out opening files
+	ctx := dsfs.WithOpenTimeout(scp.Context(), dsfs.NoTimeout)
+	for i, v := range report.Versions {
+		if v.Status == dsfs.VerifyOK || v.Ref.Name == "" {
+			continue
+		}
+		ref, location, err := scp.ParseAndResolveRef(scp.Context(), fmt.Sprintf("%s@%s", v.Ref.Alias(), v.Path), source)
+		if err != nil {
+			log.Debugw("verify repo: resolving version to repair", "path", v.Path, "err", err)
+			continue
+		}
+		if _, err := scp.RemoteClient().PullDataset(ctx, &ref, location); err != nil {
+			log.Debugw("verify repo: fetching version", "path", v.Path, "err", err)
+			continue
+		}
+		report.Versions[i].VersionCheck = dsfs.VerifyVersion(scp.Context(), scp.Filesystem(), v.Path)
+		report.Versions[i].Repaired = report.Versions[i].Status == dsfs.VerifyOK
+	}
+	report.Tally()
+	return report, nil
+}
+
+func (adminImpl) Doctor(scp scope, p *DoctorParams) (*DoctorReport, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+
+	res := &DoctorReport{
+		Readiness: *scp.inst.Readiness(scp.Context()),
+		Bodies:    []dsfs.BodyCheck{},
+		Integrity: append([]backup.Check{}, scp.inst.integrity...),
+	}
+	if v := scp.inst.bodyVerifier; v != nil {
+		res.Bodies = v.VerifyAll(scp.Context())
+	}
+	for _, bc := range res.Bodies {
+		if bc.Status == dsfs.VerifyInvalid {
+			res.Corrupt++
+		}
+	}
+	return res, nil
+}
+
+func (adminImpl) Audit(scp scope, p *AuditParams) ([]AuditRecord, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+	limit := p.Limit
+	if limit == 0 {
+		// in-process calls skip SetNonZeroDefaults
+		limit = DefaultPageSize
+	}
+	return scp.inst.audit.List(limit)
+}
+
+// hostingRemote returns the remote hosting methods act on, checking the
+// caller is an operator
+func hostingRemote(scp scope) (*remote.Remote, error) {
+	if err := scp.inst.CheckOperator(scp.Context()); err != nil {
+		return nil, err
+	}
+	if scp.inst.remote == nil {
+		return nil, ErrNotRemote
+	}
+	return scp.inst.remote, nil
+}
+
+func (adminImpl) Hosted(scp scope, p *HostedParams) ([]remote.HostedDataset, error) {
+	rem, err := hostingRemote(scp)
+	if err != nil {
+		return nil, err
+	}
+	return rem.HostedDatasets(scp.Context(), p.Offset, p.Limit)
+}
+
+func (adminImpl) HostingStatus(scp scope, p *HostingRefParams) (*remote.HostedDataset, error) {
+	rem, err := hostingRemote(scp)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := dsref.Parse(p.Ref)
+	if err != nil {
+		return nil, err
+	}
+	return rem.HostingStatus(scp.Context(), ref)
+}
+
+func (adminImpl) HostingStorage(scp scope, p *HostingStorageParams) (*remote.HostingStorage, error) {
+	rem, err := hostingRemote(scp)
+	if err != nil {
+		return nil, err
+	}
+	return rem.Storage(scp.Context(), p.Limit)
+}
+
+func (adminImpl) DropHosted(scp scope, p *HostingRefParams) error {
+	rem, err := hostingRemote(scp)
+	if err != nil {
+		return err
+	}
+	ref, err := dsref.Parse(p.Ref)
+	if err != nil {
+		return err
+	}
+	if err := rem.DropDataset(scp.Context(), ref); err != nil {
+		return err
+	}
+	log.Infow("dropped hosted dataset", "ref", ref.Alias(), "by", token.OriginFromCtx(scp.Context()))
+	return nil
+}
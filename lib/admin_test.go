@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/profile"
+)
+
+func TestAdminMethods(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inst, cleanup := NewMemTestInstance(ctx, t)
+	defer cleanup()
+
+	status, err := inst.Admin().Status(ctx, &AdminStatusParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Peername != inst.cfg.Profile.Peername {
+		t.Errorf("peername mismatch. want: %q, got: %q", inst.cfg.Profile.Peername, status.Peername)
+	}
+	if status.Started.IsZero() {
+		t.Error("expected start time to be set")
+	}
+	if status.Goroutines == 0 {
+		t.Error("expected goroutine count to be set")
+	}
+
+	if err := inst.Admin().SetLogLevel(ctx, &SetLogLevelParams{Subsystem: "lib", Level: "info"}); err != nil {
+		t.Error(err)
+	}
+	if err := inst.Admin().SetLogLevel(ctx, &SetLogLevelParams{Subsystem: "lib"}); err == nil {
+		t.Error("expected missing level to error")
+	}
+
+	if _, err := inst.Admin().GC(ctx, &GCParams{}); !errors.Is(err, ErrGCUnsupported) {
+		t.Errorf("expected GC on an in-memory filesystem to return ErrGCUnsupported, got: %v", err)
+	}
+
+	if _, err := inst.Admin().Hosted(ctx, &HostedParams{}); !errors.Is(err, ErrNotRemote) {
+		t.Errorf("expected listing hosted datasets without a remote to return ErrNotRemote, got: %v", err)
+	}
+	if err := inst.Admin().DropHosted(ctx, &HostingRefParams{}); err == nil {
+		t.Error("expected dropping without a ref to error")
+	}
+}
+
+func TestOnBehalfOf(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inst, cleanup := NewMemTestInstance(ctx, t)
+	defer cleanup()
+
+	kd := testkeys.GetKeyData(5)
+	user := &profile.Profile{ID: profile.IDFromPeerID(kd.PeerID), PrivKey: kd.PrivKey, PubKey: kd.PrivKey.GetPublic(), Peername: "marjorie"}
+	if err := inst.profiles.PutProfile(user); err != nil {
+		t.Fatal(err)
+	}
+	tokenCtx := func(username string) context.Context {
+		s, err := inst.Access().CreateAuthToken(ctx, &CreateAuthTokenParams{GranteeUsername: username})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token.AddToContext(ctx, s)
+	}
+	operator := tokenCtx("me")
+
+	sessions, err := inst.Access().Sessions(operator, &SessionsParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("expected the operator to have one session, got %d", len(sessions))
+	}
+	// acting as marjorie lists marjorie's sessions
+	if sessions, err = inst.Access().Sessions(WithOnBehalfOf(operator, "marjorie"), &SessionsParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("expected no sessions for marjorie, got %d", len(sessions))
+	}
+
+	// only operators can act on behalf of other profiles
+	_, err = inst.Access().Sessions(WithOnBehalfOf(tokenCtx("marjorie"), inst.cfg.Profile.Peername), &SessionsParams{})
+	if !errors.Is(err, ErrNotOperator) {
+		t.Errorf("expected non-operator to be refused with ErrNotOperator, got: %v", err)
+	}
+	// methods that aren't dispatched can't be called on behalf of others
+	if _, err := inst.activeProfile(WithOnBehalfOf(operator, "marjorie")); !errors.Is(err, ErrOnBehalfOfUnsupported) {
+		t.Errorf("expected ErrOnBehalfOfUnsupported, got: %v", err)
+	}
+
+	records, err := inst.Admin().Audit(operator, &AuditParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 audit records, got: %#v", records)
+	}
+	if records[0].Error == "" || records[0].OperatorName != "marjorie" {
+		t.Errorf("expected most recent record to be the refused call, got: %#v", records[0])
+	}
+	rec := records[1]
+	if rec.Error != "" || rec.AsID != user.ID.String() || rec.Method != "access.sessions" || rec.ParamsDigest == "" {
+		t.Errorf("unexpected audit record: %#v", rec)
+	}
+}
@@ -33,10 +33,18 @@ const (
 
 	// AEHome is the / endpoint
 	AEHome = APIEndpoint("/")
+	// AERPC serves dispatch methods over JSON-RPC 2.0
+	AERPC = APIEndpoint("/rpc")
 	// AEHealth is the service health check endpoint
 	AEHealth = APIEndpoint("/health")
+	// AEHealthz is the liveness check endpoint
+	AEHealthz = APIEndpoint("/healthz")
+	// AEReadyz is the readiness check endpoint, reporting subsystem health
+	AEReadyz = APIEndpoint("/readyz")
 	// AEIPFS is the IPFS endpoint
 	AEIPFS = APIEndpoint("/ipfs/{path:.*}")
+	// AEEventTypes lists event types & the JSON schema of their payloads
+	AEEventTypes = APIEndpoint("/events/types")
 
 	// profile enpoints
 
@@ -63,6 +71,41 @@ const (
 	AEConnections = APIEndpoint("/connections")
 	// AEConnectedQriProfiles lists qri profile connections
 	AEConnectedQriProfiles = APIEndpoint("/connections/qri")
+	// AEPeersTop lists connected peers by connection score
+	AEPeersTop = APIEndpoint("/peers/top")
+	// AEPeersBandwidth reports data transferred by peer & protocol
+	AEPeersBandwidth = APIEndpoint("/peers/bandwidth")
+	// AEPeersSync exchanges logbooks with a peer
+	AEPeersSync = APIEndpoint("/peers/sync")
+	// AEPeersLocal lists peers discovered on the local network
+	AEPeersLocal = APIEndpoint("/peers/local")
+	// AEPeersTrust trusts a local peer to exchange logbooks
+	AEPeersTrust = APIEndpoint("/peers/trust")
+
+	// admin endpoints
+
+	// AEAdminStatus reports the state of a running node
+	AEAdminStatus = APIEndpoint("/admin/status")
+	// AEAdminReloadConfig re-reads configuration from disk
+	AEAdminReloadConfig = APIEndpoint("/admin/config/reload")
+	// AEAdminGC triggers IPFS garbage collection
+	AEAdminGC = APIEndpoint("/admin/gc")
+	// AEAdminConnections lists peer connections
+	AEAdminConnections = APIEndpoint("/admin/peers")
+	// AEAdminConnect opens a connection to a peer
+	AEAdminConnect = APIEndpoint("/admin/peers/connect")
+	// AEAdminDisconnect closes a connection to a peer
+	AEAdminDisconnect = APIEndpoint("/admin/peers/disconnect")
+	// AEAdminLogLevel changes a logger's level
+	AEAdminLogLevel = APIEndpoint("/admin/loglevel")
+	// AEAdminVerifyRepo checks the integrity of stored datasets
+	AEAdminVerifyRepo = APIEndpoint("/admin/verifyrepo")
+	// AEAdminRepairRefs moves broken dataset references to intact versions
+	AEAdminRepairRefs = APIEndpoint("/admin/repairrefs")
+	// AEAdminDoctor checks instance health & recently saved dataset bodies
+	AEAdminDoctor = APIEndpoint("/admin/doctor")
+	// AEAdminAudit lists calls operators made on behalf of other profiles
+	AEAdminAudit = APIEndpoint("/admin/audit")
 
 	// remote endpoints
 
@@ -86,12 +129,32 @@ const (
 	AESave = APIEndpoint("/save")
 	// AERemove exposes the dataset remove mechanics
 	AERemove = APIEndpoint("/remove")
+	// AERemoveMany removes datasets matching a list of references & patterns
+	AERemoveMany = APIEndpoint("/removemany")
+	// AETrash lists removed datasets kept in the trash
+	AETrash = APIEndpoint("/trash")
+	// AETrashRestore brings back a dataset from the trash
+	AETrashRestore = APIEndpoint("/trash/restore")
+	// AETrashEmpty purges datasets in the trash
+	AETrashEmpty = APIEndpoint("/trash/empty")
+	// AESquash collapses the latest versions of a dataset into one
+	AESquash = APIEndpoint("/squash")
+	// AESyncStatus lists the outcome of syncing dataset versions to warehouses
+	AESyncStatus = APIEndpoint("/sync/status")
+	// AEPin pins a dataset version to configured pinning services
+	AEPin = APIEndpoint("/pin")
+	// AEPinStatus lists the state of pinning dataset versions
+	AEPinStatus = APIEndpoint("/pin/status")
 	// AEGet is an endpoint for fetch individual dataset components
 	AEGet = APIEndpoint("/get")
+	// AEEmbed reads a dataset with an embed token
+	AEEmbed = APIEndpoint("/embed")
 	// AERename is an endpoint for renaming datasets
 	AERename = APIEndpoint("/rename")
 	// AEValidate is an endpoint for validating datasets
 	AEValidate = APIEndpoint("/validate")
+	// AELint checks a dataset against lint rules
+	AELint = APIEndpoint("/lint")
 	// AEDiff is an endpoint for generating dataset diffs
 	AEDiff = APIEndpoint("/diff")
 	// AEChanges is an endpoint for generating dataset change reports
@@ -104,22 +167,65 @@ const (
 	AEManifestMissing = APIEndpoint("/manifest/missing")
 	// AEDAGInfo generates a dag.Info for a dataset path
 	AEDAGInfo = APIEndpoint("/dag/info")
+	// AEPatchMeta applies a merge patch to the meta component of many datasets
+	AEPatchMeta = APIEndpoint("/meta/patch")
+	// AELifecycle sets the lifecycle state of a dataset
+	AELifecycle = APIEndpoint("/lifecycle")
+	// AEStatsHistory extracts stats across the history of a dataset
+	AEStatsHistory = APIEndpoint("/stats/history")
+	// AEDatasetSettings gets the settings of a dataset
+	AEDatasetSettings = APIEndpoint("/settings")
+	// AESetDatasetSetting changes a setting of a dataset
+	AESetDatasetSetting = APIEndpoint("/settings/set")
 
 	// remote client endpoints
 
 	// AEPush facilitates dataset push requests to a remote
 	AEPush = APIEndpoint("/push")
+	// AEPublish pushes a dataset version to many remotes at once
+	AEPublish = APIEndpoint("/push/all")
+	// AEPromote renames a dataset & pushes it to a remote in one step
+	AEPromote = APIEndpoint("/promote")
+	// AEPushQueue lists pushes waiting for their remote to become reachable
+	AEPushQueue = APIEndpoint("/pushqueue")
+	// AEPushReceipts verifies receipts remotes issued for pushes & unpublishes
+	AEPushReceipts = APIEndpoint("/push/receipts")
+	// AEPushHooks fetches the status of hooks a remote ran after a push
+	AEPushHooks = APIEndpoint("/push/hooks")
 	// AEPull facilittates dataset pull requests from a remote
 	AEPull = APIEndpoint("/pull")
+	// AEClone pulls a dataset, creates a linked working directory & follows it
+	AEClone = APIEndpoint("/clone")
+	// AECopy copies a dataset from another repo on the same machine
+	AECopy = APIEndpoint("/copy")
+	// AEFollow adds & removes datasets that are pulled automatically
+	AEFollow = APIEndpoint("/follow")
 	// AEFeeds fetches and index of named feeds
 	AEFeeds = APIEndpoint("/feeds")
 	// AEPreview fetches a dataset preview from the registry
 	AEPreview = APIEndpoint("/preview")
+	// AERemoteUsage fetches pull stats for a dataset from a remote
+	AERemoteUsage = APIEndpoint("/remote/usage")
+	// AERemoteHosted lists datasets stored by this remote
+	AERemoteHosted = APIEndpoint("/remote/hosted")
+	// AERemoteHostingStatus reports how a dataset is stored by this remote
+	AERemoteHostingStatus = APIEndpoint("/remote/hosted/status")
+	// AERemoteStorage summarizes storage used by datasets this remote stores
+	AERemoteStorage = APIEndpoint("/remote/storage")
+	// AERemoteDrop removes a dataset stored by this remote
+	AERemoteDrop = APIEndpoint("/remote/hosted/drop")
+	// AEDatasetFeed serves an Atom feed of versions of a dataset
+	AEDatasetFeed = APIEndpoint("/feed/ds/{peername}/{name}")
+	// AEProfileFeed serves an Atom feed of versions of a profile's datasets
+	AEProfileFeed = APIEndpoint("/feed/profile/{peername}")
 
 	// fsi endpoints
 
 	// AEStatus returns the filesystem dataset status
 	AEStatus = APIEndpoint("/status")
+	// AEStatusAll reports the status of every dataset linked to a working
+	// directory
+	AEStatusAll = APIEndpoint("/statusall")
 	// AEWhatChanged returns what changed for a specific commit
 	AEWhatChanged = APIEndpoint("/whatchanged")
 	// AEInit invokes a dataset initialization on the filesystem
@@ -138,11 +244,35 @@ const (
 	AEFSIUnlink = APIEndpoint("/fsi/unlink")
 	// AEEnsureRef ensures that the ref is fsi linked
 	AEEnsureRef = APIEndpoint("/fsi/ensureref")
+	// AEGitExport commits dataset versions to a git branch
+	AEGitExport = APIEndpoint("/fsi/git/export")
+	// AEGitImport saves the tip of a git branch as a dataset version
+	AEGitImport = APIEndpoint("/fsi/git/import")
 
 	// auth endpoints
 
 	// AECreateAuthToken creates an auth token for a user
 	AECreateAuthToken = APIEndpoint("/auth/createauthtoken")
+	// AEOAuthDeviceCode starts an OAuth 2.0 device code flow
+	AEOAuthDeviceCode = APIEndpoint("/oauth/device/code")
+	// AEOAuthDeviceApprove approves or denies a pending device code
+	AEOAuthDeviceApprove = APIEndpoint("/oauth/device/approve")
+	// AEOAuthToken exchanges device codes & refresh tokens for access tokens
+	AEOAuthToken = APIEndpoint("/oauth/token")
+	// AESessions lists authenticated client sessions
+	AESessions = APIEndpoint("/auth/sessions")
+	// AETerminateSession ends client sessions, revoking their tokens
+	AETerminateSession = APIEndpoint("/auth/sessions/terminate")
+	// AECreateEmbedToken issues a read token for embedding a dataset preview
+	AECreateEmbedToken = APIEndpoint("/auth/embed/create")
+	// AEEmbedTokens lists issued embed tokens & their usage
+	AEEmbedTokens = APIEndpoint("/auth/embed")
+	// AERevokeEmbedToken revokes an embed token
+	AERevokeEmbedToken = APIEndpoint("/auth/embed/revoke")
+	// AEProtection gets the protection rules of a dataset
+	AEProtection = APIEndpoint("/auth/protection")
+	// AEProtect sets the protection rules of a dataset
+	AEProtect = APIEndpoint("/auth/protection/set")
 
 	// other endpoints
 
@@ -150,10 +280,17 @@ const (
 	AEHistory = APIEndpoint("/history")
 	// AEEntries lists log entries for actions taken on a given dataset
 	AEEntries = APIEndpoint("/log")
+	// AELogGraph lists every operation on a dataset, including runs, pushes,
+	// and deletes
+	AELogGraph = APIEndpoint("/log/graph")
+	// AELogComponents lists the components each version of a dataset changed
+	AELogComponents = APIEndpoint("/log/components")
 	// AERawLogbook returns the full logbook encoded as human-oriented json
 	AERawLogbook = APIEndpoint("/logbook")
 	// AELogbookSummary returns a string overview of the logbook
 	AELogbookSummary = APIEndpoint("/logbook/summary")
+	// AEMetrics reports node metrics for operators
+	AEMetrics = APIEndpoint("/metrics")
 	// AERender renders the current dataset ref
 	AERender = APIEndpoint("/render")
 	// AERegistryNew creates a new user on the registry
@@ -163,10 +300,24 @@ const (
 	AERegistryProve = APIEndpoint("/registry/profile/prove")
 	// AESearch returns a list of dataset search results
 	AESearch = APIEndpoint("/search")
+	// AESearchIndexContent enables & disables content indexing for a dataset
+	AESearchIndexContent = APIEndpoint("/search/index")
 	// AESQL executes SQL commands
 	AESQL = APIEndpoint("/sql")
+	// AESQLExplain reports how an SQL query will be executed
+	AESQLExplain = APIEndpoint("/sql/explain")
 	// AEApply invokes a transform apply
 	AEApply = APIEndpoint("/apply")
+	// AEReproduce re-runs the transform of a dataset version
+	AEReproduce = APIEndpoint("/reproduce")
+	// AERunLog fetches the log of a transform run
+	AERunLog = APIEndpoint("/runs/{id}/log")
+	// AEFreshness adds, removes & runs checks for new data at dataset sources
+	AEFreshness = APIEndpoint("/freshness")
+	// AEWorkflows loads & schedules workflows defined in workflow datasets
+	AEWorkflows = APIEndpoint("/workflows")
+	// AEResume resumes a workflow paused by repeated failures
+	AEResume = APIEndpoint("/workflows/resume")
 	// AEWebUI serves the remote WebUI
 	AEWebUI = APIEndpoint("/webui")
 
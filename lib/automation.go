@@ -0,0 +1,215 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/qri-io/qri/transform/run"
+)
+
+// AutomationMethods groups together methods for inspecting automated
+// transform runs
+type AutomationMethods struct {
+	d dispatcher
+}
+
+// Name returns the name of this method group
+func (m AutomationMethods) Name() string {
+	return "automation"
+}
+
+// Attributes defines attributes for each method
+func (m AutomationMethods) Attributes() map[string]AttributeSet {
+	return map[string]AttributeSet{
+		"run":       {endpoint: AERunLog, verb: "GET"},
+		"freshness": {endpoint: AEFreshness, verb: "POST"},
+		"workflows": {endpoint: AEWorkflows, verb: "POST"},
+		"resume":    {endpoint: AEResume, verb: "POST"},
+	}
+}
+
+// RunParams are parameters for fetching a transform run log
+type RunParams struct {
+	ID string `json:"id"`
+}
+
+// UnmarshalFromRequest implements a custom deserialization-from-HTTP request
+func (p *RunParams) UnmarshalFromRequest(r *http.Request) error {
+	if p.ID == "" {
+		p.ID = r.FormValue("id")
+	}
+	return nil
+}
+
+// Validate returns an error if RunParams fields are in an invalid state
+func (p *RunParams) Validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("run ID is required")
+	}
+	return nil
+}
+
+// Run fetches the log of a transform run: run state, events, printed output
+// and errors. Logs are only kept for recent runs, older runs return
+// run.ErrLogNotFound
+func (m AutomationMethods) Run(ctx context.Context, p *RunParams) (*run.Log, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "run"), p)
+	if res, ok := got.(*run.Log); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// FreshnessParams are parameters for managing dataset freshness checks
+type FreshnessParams struct {
+	Ref string `json:"ref"`
+	// BodyURL is the source to check for new data
+	BodyURL string `json:"bodyURL"`
+	// Remove stops checking the dataset
+	Remove bool `json:"remove"`
+	// Run checks immediately instead of waiting for the next scheduled check.
+	// With no Ref every dataset that isn't paused is checked
+	Run bool `json:"run"`
+	// List only lists checks, ignoring other parameters
+	List bool `json:"list"`
+}
+
+// Freshness adds, removes & runs checks that periodically look for new data at
+// the source of a dataset body with a lightweight HEAD request. A new version
+// is only saved when the source has changed, each check publishes an
+// ETDatasetFreshness event. Returns the list of freshness checks
+func (m AutomationMethods) Freshness(ctx context.Context, p *FreshnessParams) ([]FreshnessCheck, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "freshness"), p)
+	if res, ok := got.([]FreshnessCheck); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// WorkflowsParams are parameters for listing workflows
+type WorkflowsParams struct{}
+
+// Workflows loads workflows from workflow datasets & schedules the active
+// ones, without waiting for the scheduler. Workflow datasets are datasets in
+// the local user's namespace named with WorkflowPrefix, their bodies list
+// workflows. Returns every workflow found, active or not
+func (m AutomationMethods) Workflows(ctx context.Context, p *WorkflowsParams) ([]Workflow, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "workflows"), p)
+	if res, ok := got.([]Workflow); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// ResumeParams are parameters for resuming a paused workflow
+type ResumeParams struct {
+	// Ref is the dataset a paused freshness check runs against
+	Ref string `json:"ref"`
+}
+
+// Validate returns an error if ResumeParams fields are in an invalid state
+func (p *ResumeParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("%w: dataset reference is required", ErrBadArgs)
+	}
+	return nil
+}
+
+// Resume restarts the freshness check of a dataset that was paused after
+// repeated failures, resetting its failure count. Returns the resumed check
+func (m AutomationMethods) Resume(ctx context.Context, p *ResumeParams) (*FreshnessCheck, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "resume"), p)
+	if res, ok := got.(*FreshnessCheck); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// Implementations for automation methods follow
+
+// automationImpl holds the method implementations for automation
+type automationImpl struct{}
+
+// Run fetches the log of a transform run
+func (automationImpl) Run(scope scope, p *RunParams) (*run.Log, error) {
+	return scope.RunLogs().Get(p.ID)
+}
+
+// Freshness adds, removes & runs freshness checks
+func (automationImpl) Freshness(scope scope, p *FreshnessParams) ([]FreshnessCheck, error) {
+	if p.List {
+		return scope.inst.freshness.List()
+	}
+	var alias string
+	if p.Ref != "" {
+		ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "")
+		if err != nil {
+			return nil, err
+		}
+		alias = ref.Alias()
+		switch {
+		case p.Remove:
+			err = scope.inst.freshness.Remove(alias)
+		case p.BodyURL != "":
+			if err = checkBodyURLHost(scope, p.BodyURL); err != nil {
+				return nil, err
+			}
+			err = scope.inst.freshness.Put(&FreshnessCheck{Ref: alias, InitID: ref.InitID, BodyURL: p.BodyURL, Since: time.Now()})
+		case !p.Run:
+			err = fmt.Errorf("%w: body url is required", ErrBadArgs)
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else if !p.Run {
+		return nil, fmt.Errorf("%w: dataset reference is required", ErrBadArgs)
+	}
+
+	if p.Run && !p.Remove {
+		checks, err := scope.inst.freshness.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range checks {
+			if (alias == "" && !c.Paused) || c.Ref == alias {
+				c := c
+				runFreshnessCheck(scope, &c)
+			}
+		}
+	}
+	return scope.inst.freshness.List()
+}
+
+// Workflows loads & schedules workflows
+func (automationImpl) Workflows(scope scope, p *WorkflowsParams) ([]Workflow, error) {
+	return syncWorkflows(scope)
+}
+
+// Resume unpauses a freshness check
+func (automationImpl) Resume(scope scope, p *ResumeParams) (*FreshnessCheck, error) {
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "")
+	if err != nil {
+		return nil, err
+	}
+	return scope.inst.freshness.Resume(ref.Alias())
+}
+
+// newRunLogStore creates a store for transform run logs in the "runs"
+// directory of a repo. an empty repoPath keeps run logs in memory
+func newRunLogStore(repoPath string) *run.LogStore {
+	if repoPath == "" {
+		return run.NewLogStore("")
+	}
+	return run.NewLogStore(filepath.Join(repoPath, "runs"))
+}
+
+// putRunLog persists a transform run log. Failing to record a log must never
+// fail the run itself, so errors are only logged
+func putRunLog(scope scope, l *run.Log) {
+	if err := scope.RunLogs().Put(l); err != nil {
+		log.Debugw("writing transform run log", "runID", l.ID, "err", err)
+	}
+}
@@ -0,0 +1,288 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	testcfg "github.com/qri-io/qri/config/test"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/transform/run"
+)
+
+func TestAutomationRun(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	if _, err := tr.SaveWithParams(&SaveParams{
+		Ref:       "me/printer",
+		FilePaths: []string{"testdata/tf/print.star"},
+		Apply:     true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ds := tr.MustGet(t, "me/printer")
+	if ds.Commit == nil || ds.Commit.RunID == "" {
+		t.Fatal("expected saved commit to have a runID")
+	}
+
+	l, err := tr.Instance.Automation().Run(tr.Ctx, &RunParams{ID: ds.Commit.RunID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.ID != ds.Commit.RunID {
+		t.Errorf("run ID mismatch. want %q, got %q", ds.Commit.RunID, l.ID)
+	}
+	if l.Stdout != "hello from transform\n" {
+		t.Errorf("stdout mismatch. got: %q", l.Stdout)
+	}
+	if l.State == nil || l.State.Status != run.RSSucceeded {
+		t.Errorf("expected run to have succeeded, got state: %#v", l.State)
+	}
+	if len(l.Events) == 0 {
+		t.Error("expected run log to record events")
+	}
+
+	if _, err := tr.Instance.Automation().Run(tr.Ctx, &RunParams{ID: "not-a-run"}); !errors.Is(err, run.ErrLogNotFound) {
+		t.Errorf("expected unknown run to return ErrLogNotFound, got: %v", err)
+	}
+	if _, err := tr.Instance.Automation().Run(tr.Ctx, &RunParams{}); err == nil {
+		t.Error("expected missing run ID to error")
+	}
+}
+
+func TestAutomationFreshness(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	body := "city,pop\ntoronto,40000000\n"
+	gets := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"%d"`, len(body))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		if r.Method == http.MethodGet {
+			gets++
+			w.Write([]byte(body))
+		}
+	}))
+	defer s.Close()
+
+	node := newTestQriNode(t)
+	bus := event.NewBus(ctx)
+	inst := NewInstanceFromConfigAndNodeAndBus(ctx, testcfg.DefaultConfigForTesting(), node, bus)
+	bodyURL := s.URL + "/cities.csv"
+	if _, err := inst.Dataset().Save(ctx, &SaveParams{Ref: "me/fresh_cities", BodyURL: bodyURL}); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []event.DsFreshness
+	bus.SubscribeTypes(func(ctx context.Context, e event.Event) error {
+		events = append(events, e.Payload.(event.DsFreshness))
+		return nil
+	}, event.ETDatasetFreshness)
+
+	checks, err := inst.Automation().Freshness(ctx, &FreshnessParams{Ref: "me/fresh_cities", BodyURL: bodyURL, Run: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 1 || checks[0].LastChecked.IsZero() || checks[0].LastPath != "" {
+		t.Fatalf("expected one unchanged check, got: %#v", checks)
+	}
+	if gets != 1 {
+		t.Errorf("expected unchanged source not to be downloaded, got %d downloads", gets)
+	}
+
+	body = "city,pop\ntoronto,40000000\nnew york,8500000\n"
+	if checks, err = inst.Automation().Freshness(ctx, &FreshnessParams{Run: true}); err != nil {
+		t.Fatal(err)
+	}
+	if checks[0].LastPath == "" || checks[0].LastError != "" {
+		t.Errorf("expected changed source to save a version, got: %#v", checks[0])
+	}
+
+	if len(events) != 2 || events[0].Changed || !events[1].Changed || events[1].Saved != checks[0].LastPath {
+		t.Errorf("unexpected freshness events: %#v", events)
+	}
+
+	if checks, err = inst.Automation().Freshness(ctx, &FreshnessParams{Ref: "me/fresh_cities", Remove: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 0 {
+		t.Errorf("expected check to be removed, got: %#v", checks)
+	}
+	if _, err = inst.Automation().Freshness(ctx, &FreshnessParams{}); !errors.Is(err, ErrBadArgs) {
+		t.Errorf("expected missing ref to return ErrBadArgs, got: %v", err)
+	}
+}
+
+func TestAutomationFreshnessFailurePolicy(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	checked := 0
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checked++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer source.Close()
+
+	var notified []event.DsFreshness
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		evt := event.DsFreshness{}
+		if err := json.NewDecoder(r.Body).Decode(&evt); err != nil {
+			t.Errorf("decoding webhook payload: %s", err)
+		}
+		notified = append(notified, evt)
+	}))
+	defer hook.Close()
+
+	ds := tr.MustSaveFromBody(t, "failing_source", "testdata/cities_2/body.csv")
+	tr.MustSaveFromBody(t, "cities", "testdata/cities_2/body.csv")
+	check := &FreshnessCheck{
+		Ref:     tr.MustOwner(t).Peername + "/failing_source",
+		InitID:  ds.ID,
+		BodyURL: source.URL + "/cities.csv",
+		FailurePolicy: FailurePolicy{
+			MaxAttempts: 2,
+			Backoff:     "1ms",
+			PauseAfter:  2,
+			Webhooks:    []string{hook.URL},
+		},
+	}
+	if err := tr.Instance.freshness.Put(check); err != nil {
+		t.Fatal(err)
+	}
+
+	// a failed attempt with attempts left schedules a retry
+	checks, err := tr.Instance.Automation().Freshness(tr.Ctx, &FreshnessParams{Run: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked != 1 {
+		t.Errorf("expected one attempt, got %d", checked)
+	}
+	if checks[0].Failures != 0 || checks[0].Attempts != 1 || checks[0].RetryAt.IsZero() || checks[0].LastError == "" {
+		t.Errorf("expected a scheduled retry, got: %#v", checks[0])
+	}
+	if len(notified) != 0 {
+		t.Errorf("expected a retry not to notify, got: %#v", notified)
+	}
+
+	// running out of attempts counts a failure
+	if checks, err = tr.Instance.Automation().Freshness(tr.Ctx, &FreshnessParams{Run: true}); err != nil {
+		t.Fatal(err)
+	}
+	if checked != 2 {
+		t.Errorf("expected a failed check to be retried once, got %d attempts", checked)
+	}
+	if checks[0].Failures != 1 || checks[0].Paused || checks[0].Attempts != 0 || !checks[0].RetryAt.IsZero() {
+		t.Errorf("expected one unpaused failure, got: %#v", checks[0])
+	}
+
+	for i := 0; i < 2; i++ {
+		if checks, err = tr.Instance.Automation().Freshness(tr.Ctx, &FreshnessParams{Run: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if checks[0].Failures != 2 || !checks[0].Paused {
+		t.Errorf("expected repeated failures to pause the check, got: %#v", checks[0])
+	}
+	if len(notified) != 2 || notified[0].Attempts != 2 || !notified[1].Paused {
+		t.Errorf("unexpected failure notifications: %#v", notified)
+	}
+
+	// paused checks are skipped
+	if _, err = tr.Instance.Automation().Freshness(tr.Ctx, &FreshnessParams{Run: true}); err != nil {
+		t.Fatal(err)
+	}
+	if checked != 4 {
+		t.Errorf("expected paused check not to run, got %d attempts", checked)
+	}
+
+	resumed, err := tr.Instance.Automation().Resume(tr.Ctx, &ResumeParams{Ref: "me/failing_source"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Paused || resumed.Failures != 0 {
+		t.Errorf("expected resumed check to be unpaused, got: %#v", resumed)
+	}
+	if _, err := tr.Instance.Automation().Resume(tr.Ctx, &ResumeParams{Ref: "me/cities"}); !errors.Is(err, ErrNoFreshnessCheck) {
+		t.Errorf("expected resuming an unchecked dataset to return ErrNoFreshnessCheck, got: %v", err)
+	}
+}
+
+func TestAutomationWorkflows(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	username := tr.MustOwner(t).Peername
+	tr.MustSaveFromBody(t, "cities", "testdata/cities_2/body.csv")
+	tr.MustSaveFromBody(t, "not_a_workflow", "testdata/cities_2/body.csv")
+	workflows := `[
+		{ "type": "freshness", "ref": "me/cities", "bodyURL": "https://example.com/cities.csv", "active": true },
+		{ "type": "freshness", "ref": "me/not_a_workflow", "bodyURL": "https://example.com/other.csv", "active": false },
+		{ "type": "webhook", "ref": "me/cities", "active": true },
+		{ "type": "freshness", "ref": "me/not_a_workflow", "bodyURL": "https://example.com/other.csv", "active": true, "backoff": "soon" }
+	]`
+	tr.MustSaveFromBody(t, "workflow_sources", tr.MustWriteTmpFile(t, "workflows.json", workflows))
+
+	got, err := tr.Instance.Automation().Workflows(tr.Ctx, &WorkflowsParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 workflows, got: %#v", got)
+	}
+	if got[0].Dataset != username+"/workflow_sources" || got[0].Error != "" {
+		t.Errorf("unexpected workflow: %#v", got[0])
+	}
+	if got[2].Error == "" {
+		t.Errorf("expected unsupported workflow type to report an error, got: %#v", got[2])
+	}
+	if got[3].Error == "" {
+		t.Errorf("expected invalid failure policy to report an error, got: %#v", got[3])
+	}
+
+	checks, err := tr.Instance.Automation().Freshness(tr.Ctx, &FreshnessParams{List: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 1 || checks[0].Ref != username+"/cities" || checks[0].Workflow != username+"/workflow_sources" {
+		t.Fatalf("expected one check scheduled by the workflow, got: %#v", checks)
+	}
+
+	// policy changes update the scheduled check
+	workflows = `[{ "type": "freshness", "ref": "me/cities", "bodyURL": "https://example.com/cities.csv", "active": true, "maxAttempts": 3 }]`
+	tr.MustSaveFromBody(t, "workflow_sources", tr.MustWriteTmpFile(t, "workflows.json", workflows))
+	if _, err := tr.Instance.Automation().Workflows(tr.Ctx, &WorkflowsParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if checks, err = tr.Instance.Automation().Freshness(tr.Ctx, &FreshnessParams{List: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 1 || checks[0].MaxAttempts != 3 {
+		t.Errorf("expected workflow policy to be applied to its check, got: %#v", checks)
+	}
+
+	// deactivating the workflow in a new version removes its check
+	workflows = `[{ "type": "freshness", "ref": "me/cities", "bodyURL": "https://example.com/cities.csv", "active": false }]`
+	tr.MustSaveFromBody(t, "workflow_sources", tr.MustWriteTmpFile(t, "workflows.json", workflows))
+	if _, err := tr.Instance.Automation().Workflows(tr.Ctx, &WorkflowsParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if checks, err = tr.Instance.Automation().Freshness(tr.Ctx, &FreshnessParams{List: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 0 {
+		t.Errorf("expected deactivated workflow's check to be removed, got: %#v", checks)
+	}
+}
@@ -0,0 +1,28 @@
+package lib
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/event"
+)
+
+// startBodyVerifier tracks large bodies as datasets are saved, checking them
+// in the background
+func (inst *Instance) startBodyVerifier(ctx context.Context, repoPath string) (err error) {
+	filename := ""
+	if repoPath != "" {
+		filename = filepath.Join(repoPath, "body_checks.json")
+	}
+	if inst.bodyVerifier, err = dsfs.NewBodyVerifier(filename, inst.qfs, inst.bus); err != nil {
+		return err
+	}
+	inst.bus.SubscribeTypes(inst.bodyVerifier.HandleSaveEvent, event.ETDatasetSaveCompleted)
+	inst.releasers.Add(1)
+	go func() {
+		inst.bodyVerifier.Run(ctx)
+		inst.releasers.Done()
+	}()
+	return nil
+}
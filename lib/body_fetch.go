@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+// ErrBodyURLHostNotAllowed is returned when a body URL sent over the API names
+// a host missing from the api.bodyurlhosts config
+var ErrBodyURLHostNotAllowed = util.NewAPIError(http.StatusForbidden, "body url host is not allowed")
+
+// checkBodyURLHost limits body URLs sent over the API to hosts listed in the
+// api.bodyurlhosts config, so API callers can't have the node download from
+// hosts only it can reach. In-process callers may use any host
+func checkBodyURLHost(scope scope, bodyURL string) error {
+	if token.OriginFromCtx(scope.Context()) == "" {
+		return nil
+	}
+	u, err := url.Parse(bodyURL)
+	if err != nil {
+		return fmt.Errorf("%w: parsing body url: %s", ErrBadArgs, err)
+	}
+	host := strings.ToLower(u.Hostname())
+	if cfg := scope.Config(); cfg != nil && cfg.API != nil {
+		for _, allowed := range cfg.API.BodyURLHosts {
+			if bodyURLHostMatches(strings.ToLower(allowed), host) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrBodyURLHostNotAllowed, host)
+}
+
+// bodyURLHostMatches reports whether host matches an allowed host pattern. A
+// pattern starting with "*." matches any subdomain, but not the bare domain
+func bodyURLHostMatches(pattern, host string) bool {
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return pattern == host
+}
+
+// bodyFetchStore persists the conditional-request state of body URLs, keyed
+// by dataset initID. A store with an empty filename keeps state in memory
+type bodyFetchStore struct {
+	sync.Mutex
+	file    jsonfile.File
+	fetches map[string]*base.BodyFetchInfo
+}
+
+func newBodyFetchStore(repoPath string) *bodyFetchStore {
+	s := &bodyFetchStore{fetches: map[string]*base.BodyFetchInfo{}}
+	if repoPath != "" {
+		s.file = jsonfile.New(filepath.Join(repoPath, "body_fetches.json"))
+	}
+	return s
+}
+
+// Get returns the recorded fetch info for a dataset, nil if none exists
+func (s *bodyFetchStore) Get(initID string) *base.BodyFetchInfo {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		log.Debugw("loading body fetch store", "err", err)
+		return nil
+	}
+	return s.fetches[initID]
+}
+
+// Put records fetch info for a dataset
+func (s *bodyFetchStore) Put(initID string, info *base.BodyFetchInfo) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.fetches[initID] = info
+	return s.save()
+}
+
+func (s *bodyFetchStore) load() error {
+	return s.file.Load(&s.fetches)
+}
+
+func (s *bodyFetchStore) save() error {
+	return s.file.Save(s.fetches)
+}
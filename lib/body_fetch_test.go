@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/config"
+)
+
+func TestCheckBodyURLHost(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.API.BodyURLHosts = []string{"data.example.com", "*.qri.io"}
+	inst := &Instance{cfg: cfg}
+
+	inProcess := scope{ctx: context.Background(), inst: inst}
+	if err := checkBodyURLHost(inProcess, "http://localhost:8080/body.csv"); err != nil {
+		t.Errorf("in-process calls should fetch from any host, got: %s", err)
+	}
+
+	overAPI := scope{ctx: token.AddOriginToContext(context.Background(), "127.0.0.1:5000"), inst: inst}
+	allowed := []string{
+		"https://data.example.com/body.csv",
+		"https://DATA.example.com:8443/body.csv",
+		"https://registry.qri.io/body.json",
+	}
+	for _, u := range allowed {
+		if err := checkBodyURLHost(overAPI, u); err != nil {
+			t.Errorf("expected %q to be allowed, got: %s", u, err)
+		}
+	}
+
+	refused := []string{
+		"http://localhost:8080/body.csv",
+		"http://169.254.169.254/latest/meta-data",
+		"https://qri.io/body.json",
+		"https://example.com/body.csv",
+	}
+	for _, u := range refused {
+		if err := checkBodyURLHost(overAPI, u); !errors.Is(err, ErrBodyURLHostNotAllowed) {
+			t.Errorf("expected %q to be refused with ErrBodyURLHostNotAllowed, got: %v", u, err)
+		}
+	}
+}
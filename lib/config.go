@@ -8,7 +8,10 @@ import (
 	"strings"
 
 	"github.com/ghodss/yaml"
+	"github.com/qri-io/qri/auth/key"
 	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/remote"
 )
 
 // ConfigMethods encapsulates changes to a qri configuration
@@ -25,9 +28,14 @@ func (m ConfigMethods) Name() string {
 func (m ConfigMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
 		// config methods are not allowed over HTTP nor RPC
-		"getconfig":     {denyRPC, ""},
-		"getconfigkeys": {denyRPC, ""},
-		"setconfig":     {denyRPC, ""},
+		"getconfig":     {endpoint: denyRPC, verb: ""},
+		"getconfigkeys": {endpoint: denyRPC, verb: ""},
+		"setconfig":     {endpoint: denyRPC, verb: ""},
+		"login":         {endpoint: denyRPC, verb: ""},
+		"logout":        {endpoint: denyRPC, verb: ""},
+		// dataset settings are stored in the repo, not the config file
+		"datasetsettings":   {endpoint: AEDatasetSettings, verb: "POST"},
+		"setdatasetsetting": {endpoint: AESetDatasetSetting, verb: "POST"},
 	}
 }
 
@@ -69,6 +77,43 @@ func (m ConfigMethods) SetConfig(ctx context.Context, update *config.Config) (*b
 	return nil, dispatchReturnError(got, err)
 }
 
+// LoginParams are input parameters for Config().Login
+type LoginParams struct {
+	// Remote is the name of a configured remote, defaults to "registry"
+	Remote string
+	// Token is the authentication token to use with the remote
+	Token string
+}
+
+// Validate returns an error if input params are invalid
+func (p *LoginParams) Validate() error {
+	if p.Token == "" {
+		return fmt.Errorf("token is required")
+	}
+	return nil
+}
+
+// Login stores an authentication token for a remote. Tokens are encrypted
+// with the owner's private key before being written to the credentials
+// section of the config. Registry & remote clients send stored tokens with
+// every HTTP request to the remote
+func (m ConfigMethods) Login(ctx context.Context, p *LoginParams) error {
+	_, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "login"), p)
+	return err
+}
+
+// LogoutParams are input parameters for Config().Logout
+type LogoutParams struct {
+	// Remote is the name of a configured remote, defaults to "registry"
+	Remote string
+}
+
+// Logout removes the stored authentication token for a remote
+func (m ConfigMethods) Logout(ctx context.Context, p *LogoutParams) error {
+	_, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "logout"), p)
+	return err
+}
+
 // configImpl holds the method implementations for ConfigMethod
 type configImpl struct{}
 
@@ -197,3 +242,77 @@ func (configImpl) SetConfig(scope scope, update *config.Config) (*bool, error) {
 	res = true
 	return &res, nil
 }
+
+// Login stores an encrypted authentication token for a remote
+func (configImpl) Login(scope scope, p *LoginParams) error {
+	name := p.Remote
+	if name == "" {
+		name = "registry"
+	}
+	cfg := scope.Config()
+	if _, err := remote.Address(cfg, name); err != nil {
+		return err
+	}
+
+	enc, err := key.EncryptCredential(scope.Profiles().Owner().PrivKey, p.Token)
+	if err != nil {
+		return err
+	}
+	update := cfg.Copy()
+	if update.Credentials == nil {
+		update.Credentials = &config.Credentials{}
+	}
+	(*update.Credentials)[name] = enc
+	return scope.inst.saveConfig(update)
+}
+
+// Logout removes the stored authentication token for a remote
+func (configImpl) Logout(scope scope, p *LogoutParams) error {
+	name := p.Remote
+	if name == "" {
+		name = "registry"
+	}
+	cfg := scope.Config()
+	if _, ok := cfg.Credentials.Get(name); !ok {
+		return fmt.Errorf("not logged in to %q", name)
+	}
+
+	update := cfg.Copy()
+	delete(*update.Credentials, name)
+	return scope.inst.saveConfig(update)
+}
+
+// credentialToken decrypts the stored token for a remote name, returning the
+// empty string if no valid token is stored
+func credentialToken(cfg *config.Config, pro *profile.Profile, name string) string {
+	enc, ok := cfg.Credentials.Get(name)
+	if !ok || pro == nil {
+		return ""
+	}
+	tok, err := key.DecryptCredential(pro.PrivKey, enc)
+	if err != nil {
+		log.Debugw("decrypting credential", "remote", name, "err", err)
+		return ""
+	}
+	return tok
+}
+
+// withCredentials configures a remote client to authenticate with stored
+// credentials
+func (inst *Instance) withCredentials(o *remote.ClientOptions) {
+	o.Credentials = inst.remoteCredential
+}
+
+// remoteCredential returns the stored token for a remote address
+func (inst *Instance) remoteCredential(addr string) string {
+	cfg := inst.GetConfig()
+	if cfg == nil || cfg.Credentials == nil || inst.profiles == nil {
+		return ""
+	}
+	for name := range *cfg.Credentials {
+		if a, err := remote.Address(cfg, name); err == nil && strings.TrimSuffix(a, "/") == addr {
+			return credentialToken(cfg, inst.profiles.Owner(), name)
+		}
+	}
+	return ""
+}
@@ -104,3 +104,48 @@ func TestSetConfig(t *testing.T) {
 		t.Errorf("response mismatch. got %s", string(res))
 	}
 }
+
+func TestLoginLogout(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	cfg := testcfg.DefaultConfigForTesting()
+	cfg.Remotes = &config.Remotes{"staging": "http://localhost:2503/"}
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err)
+	}
+	node, err := p2p.NewQriNode(mr, cfg.P2P, event.NilBus, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inst := NewInstanceFromConfigAndNode(ctx, cfg, node)
+	m := inst.Config()
+
+	if err := m.Login(ctx, &LoginParams{Remote: "unknown", Token: "secret"}); err == nil {
+		t.Errorf("expected logging in to an unknown remote to fail")
+	}
+	if err := m.Login(ctx, &LoginParams{Remote: "staging", Token: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if enc, _ := inst.GetConfig().Credentials.Get("staging"); enc == "" || enc == "secret" {
+		t.Errorf("expected stored credential to be encrypted. got: %q", enc)
+	}
+	if got := inst.remoteCredential("http://localhost:2503"); got != "secret" {
+		t.Errorf("credential mismatch. want %q, got %q", "secret", got)
+	}
+	if inst.GetConfig().WithoutPrivateValues().Credentials != nil {
+		t.Errorf("expected credentials to be private")
+	}
+
+	if err := m.Logout(ctx, &LogoutParams{Remote: "staging"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := inst.remoteCredential("http://localhost:2503"); got != "" {
+		t.Errorf("expected logout to remove credential. got %q", got)
+	}
+	if err := m.Logout(ctx, &LogoutParams{Remote: "staging"}); err == nil {
+		t.Errorf("expected logging out twice to fail")
+	}
+}
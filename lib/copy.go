@@ -0,0 +1,198 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/interface-go-ipfs-core/path"
+	"github.com/qri-io/dag"
+	"github.com/qri-io/ioes"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook/oplog"
+	"github.com/qri-io/qri/repo"
+)
+
+// CopyParams are input parameters for copying a dataset from another local
+// repo
+type CopyParams struct {
+	Ref string `json:"ref"`
+	// FromRepo is the path to the qri repo to copy from
+	FromRepo string `json:"fromRepo" qri:"fspath"`
+}
+
+// CopyResult describes a copied dataset
+type CopyResult struct {
+	Ref dsref.Ref `json:"ref"`
+	// Versions is the number of versions with data copied. Versions the source
+	// repo doesn't store locally are copied as history only
+	Versions int `json:"versions"`
+	// Blocks is the number of blocks copied
+	Blocks int `json:"blocks"`
+}
+
+// Copy transfers a dataset from another repo on this machine without going
+// through a remote. Blocks for every locally stored version & the dataset's
+// logbook are copied, then merged into this repo
+func (m DatasetMethods) Copy(ctx context.Context, p *CopyParams) (*CopyResult, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "copy"), p)
+	if res, ok := got.(*CopyResult); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// Copy transfers a dataset from another local repo
+func (datasetImpl) Copy(scope scope, p *CopyParams) (*CopyResult, error) {
+	ctx := scope.Context()
+	if p.Ref == "" {
+		return nil, fmt.Errorf("%w: a dataset reference is required", dsref.ErrEmptyRef)
+	}
+	if p.FromRepo == "" {
+		return nil, fmt.Errorf("a repo path to copy from is required")
+	}
+	fromPath, err := filepath.Abs(p.FromRepo)
+	if err != nil {
+		return nil, err
+	}
+	if destPath, err := filepath.Abs(scope.inst.repoPath); err == nil && destPath == fromPath {
+		return nil, fmt.Errorf("can't copy a dataset from a repo into itself")
+	}
+
+	src, err := openSourceRepo(ctx, fromPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := <-src.Shutdown(); err != nil {
+			log.Debugw("closing copy source repo", "path", fromPath, "err", err)
+		}
+	}()
+
+	ref, _, err := src.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q in %s: %w", p.Ref, fromPath, err)
+	}
+
+	res := &CopyResult{Ref: ref}
+	items, err := src.logbook.Items(ctx, ref, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if dsfs.VerifyVersion(ctx, src.qfs, item.Path).Status != dsfs.VerifyOK {
+			log.Debugw("copy skipping version not stored in source repo", "path", item.Path)
+			continue
+		}
+		n, err := copyVersionBlocks(ctx, src, scope.inst, item.Path)
+		if err != nil {
+			return nil, fmt.Errorf("copying version %s: %w", item.Path, err)
+		}
+		res.Versions++
+		res.Blocks += n
+	}
+
+	if err := copyDatasetLogs(ctx, src, scope.inst, ref.InitID); err != nil {
+		return nil, fmt.Errorf("copying logbook: %w", err)
+	}
+
+	vi := dsref.NewVersionInfoFromRef(ref)
+	if err := repo.PutVersionInfoShim(ctx, scope.Repo(), &vi); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// openSourceRepo opens an instance for a repo to copy from. The instance
+// doesn't connect to the network, and is only read from
+func openSourceRepo(ctx context.Context, repoPath string) (*Instance, error) {
+	cfg, err := loadRepoConfig(repoPath)
+	if err != nil {
+		return nil, err
+	} else if cfg == nil {
+		return nil, fmt.Errorf("no qri repo found at %q", repoPath)
+	}
+	cfg = cfg.Copy()
+	if cfg.P2P != nil {
+		cfg.P2P.Enabled = false
+	}
+	if cfg.Remote != nil {
+		cfg.Remote.Enabled = false
+	}
+	return NewInstance(ctx, repoPath,
+		OptConfig(cfg),
+		OptNoBootstrap(),
+		OptIOStreams(ioes.NewDiscardIOStreams()),
+	)
+}
+
+// copyVersionBlocks adds every block of a dataset version stored by src to
+// dest, pinning the version in dest. Both repos must be IPFS-backed
+func copyVersionBlocks(ctx context.Context, src, dest *Instance, versionPath string) (int, error) {
+	srcAPI, err := src.node.IPFSCoreAPI()
+	if err != nil {
+		return 0, fmt.Errorf("source repo: %w", err)
+	}
+	destAPI, err := dest.node.IPFSCoreAPI()
+	if err != nil {
+		return 0, fmt.Errorf("destination repo: %w", err)
+	}
+
+	id, err := cid.Parse(versionPath)
+	if err != nil {
+		return 0, err
+	}
+	mf, err := dag.NewManifest(ctx, dag.NewNodeGetter(srcAPI.Dag()), id)
+	if err != nil {
+		return 0, err
+	}
+
+	// add children before parents, so dest never holds a partial DAG for a
+	// block it has
+	for i := len(mf.Nodes) - 1; i >= 0; i-- {
+		c, err := cid.Parse(mf.Nodes[i])
+		if err != nil {
+			return 0, err
+		}
+		nd, err := srcAPI.Dag().Get(ctx, c)
+		if err != nil {
+			return 0, err
+		}
+		if err := destAPI.Dag().Add(ctx, nd); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := destAPI.Pin().Add(ctx, path.IpfsPath(id)); err != nil {
+		return 0, err
+	}
+	return len(mf.Nodes), nil
+}
+
+// copyDatasetLogs merges the logbook of a dataset from src into dest. The
+// dataset encryption key is copied first, so dest can decrypt the log
+func copyDatasetLogs(ctx context.Context, src, dest *Instance, initID string) error {
+	if key, ok, err := src.logbook.DatasetKey(initID); err != nil {
+		return err
+	} else if ok {
+		if err := dest.logbook.SetDatasetKey(initID, key); err != nil {
+			return err
+		}
+	}
+
+	l, err := src.logbook.UserDatasetBranchesLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	data, err := src.logbook.LogBytes(l)
+	if err != nil {
+		return err
+	}
+	lg, err := oplog.FromFlatbufferBytes(data)
+	if err != nil {
+		return err
+	}
+	return dest.logbook.MergeLog(ctx, src.logbook.Author(), lg)
+}
@@ -0,0 +1,27 @@
+package lib
+
+import (
+	"testing"
+)
+
+func TestCopyErrors(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	cases := []struct {
+		description string
+		params      *CopyParams
+	}{
+		{"no ref", &CopyParams{FromRepo: tr.TmpDir}},
+		{"no repo", &CopyParams{Ref: "me/ds"}},
+		{"repo doesn't exist", &CopyParams{Ref: "me/ds", FromRepo: tr.TmpDir}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if _, err := tr.Instance.Dataset().Copy(tr.Ctx, c.params); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
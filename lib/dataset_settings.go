@@ -0,0 +1,209 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/remote"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+// DatasetSettings are defaults that apply to a single dataset. Settings are
+// stored in the repo, keyed by dataset initID
+type DatasetSettings struct {
+	// BodyFormat converts saved bodies to this format, eg: "csv"
+	BodyFormat string `json:"bodyformat,omitempty"`
+	// Remote is the name of the remote to push to when none is given
+	Remote string `json:"remote,omitempty"`
+	// LintProfile names a set of lint severities from the lint.profiles config
+	// field, applied when saving & linting the dataset
+	LintProfile string `json:"lintprofile,omitempty"`
+	// Retention is the number of versions to keep stored locally. After each
+	// save data for older versions is removed, keeping their history. Zero
+	// keeps all versions
+	Retention int `json:"retention,omitempty"`
+	// Shallow is the number of versions stored by a shallow pull. Older
+	// versions have history but no data until the dataset is deepened. Zero
+	// for datasets that weren't pulled shallow. Shallow is set by pulls & can't
+	// be changed with Set
+	Shallow int `json:"shallow,omitempty"`
+}
+
+// DatasetSettingKeys lists the keys of settings that can be set with
+// Config().SetDatasetSetting
+var DatasetSettingKeys = []string{"bodyformat", "remote", "lintprofile", "retention"}
+
+// IsEmpty returns true if no settings are set
+func (s DatasetSettings) IsEmpty() bool {
+	return s == DatasetSettings{}
+}
+
+// Set changes a setting by key, validating the value against configuration.
+// The empty string unsets a value
+func (s *DatasetSettings) Set(cfg *config.Config, key, value string) error {
+	switch strings.ToLower(key) {
+	case "bodyformat":
+		if value != "" {
+			if _, err := dataset.ParseDataFormatString(value); err != nil {
+				return err
+			}
+		}
+		s.BodyFormat = value
+	case "remote":
+		if value != "" {
+			if _, err := remote.Address(cfg, value); err != nil {
+				return err
+			}
+		}
+		s.Remote = value
+	case "lintprofile":
+		if value != "" {
+			if cfg.Lint == nil || cfg.Lint.Profiles[value] == nil {
+				return fmt.Errorf("lint profile %q not found. add profiles to the lint.profiles config field", value)
+			}
+		}
+		s.LintProfile = value
+	case "retention":
+		n := 0
+		if value != "" {
+			var err error
+			if n, err = strconv.Atoi(value); err != nil || n < 0 {
+				return fmt.Errorf("invalid retention %q. must be a number of versions, 0 keeps all versions", value)
+			}
+		}
+		s.Retention = n
+	default:
+		return fmt.Errorf("unknown dataset setting %q. settings: %s", key, strings.Join(DatasetSettingKeys, ", "))
+	}
+	return nil
+}
+
+// datasetSettingsStore persists dataset settings, keyed by initID. A store
+// with an empty filename keeps settings in memory
+type datasetSettingsStore struct {
+	sync.Mutex
+	file     jsonfile.File
+	settings map[string]DatasetSettings
+}
+
+func newDatasetSettingsStore(repoPath string) *datasetSettingsStore {
+	s := &datasetSettingsStore{settings: map[string]DatasetSettings{}}
+	if repoPath != "" {
+		s.file = jsonfile.New(filepath.Join(repoPath, "dataset_settings.json"))
+	}
+	return s
+}
+
+// Get returns the settings for a dataset, datasets without settings return
+// empty settings
+func (s *datasetSettingsStore) Get(initID string) (DatasetSettings, error) {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return DatasetSettings{}, err
+	}
+	return s.settings[initID], nil
+}
+
+// Put replaces the settings for a dataset, empty settings are removed
+func (s *datasetSettingsStore) Put(initID string, ds DatasetSettings) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if ds.IsEmpty() {
+		delete(s.settings, initID)
+	} else {
+		s.settings[initID] = ds
+	}
+	return s.save()
+}
+
+func (s *datasetSettingsStore) load() error {
+	return s.file.Load(&s.settings)
+}
+
+func (s *datasetSettingsStore) save() error {
+	return s.file.Save(s.settings)
+}
+
+// datasetSettings returns settings for a dataset, logging instead of failing
+// if settings can't be read
+func (inst *Instance) datasetSettings(initID string) DatasetSettings {
+	if inst.dsSettings == nil || initID == "" {
+		return DatasetSettings{}
+	}
+	s, err := inst.dsSettings.Get(initID)
+	if err != nil {
+		log.Debugw("reading dataset settings", "initID", initID, "err", err)
+	}
+	return s
+}
+
+// DatasetSettingsParams are input parameters for reading & changing dataset
+// settings
+type DatasetSettingsParams struct {
+	Ref string `json:"ref"`
+	// Key & Value are the setting to change, only used by SetDatasetSetting.
+	// an empty value unsets a setting
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// DatasetSettings returns the settings of a dataset
+func (m ConfigMethods) DatasetSettings(ctx context.Context, p *DatasetSettingsParams) (*DatasetSettings, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "datasetsettings"), p)
+	if res, ok := got.(*DatasetSettings); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// SetDatasetSetting changes a setting of a dataset, returning the updated
+// settings
+func (m ConfigMethods) SetDatasetSetting(ctx context.Context, p *DatasetSettingsParams) (*DatasetSettings, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "setdatasetsetting"), p)
+	if res, ok := got.(*DatasetSettings); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// DatasetSettings returns the settings of a dataset
+func (configImpl) DatasetSettings(scope scope, p *DatasetSettingsParams) (*DatasetSettings, error) {
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	s, err := scope.inst.dsSettings.Get(ref.InitID)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SetDatasetSetting changes a setting of a dataset
+func (configImpl) SetDatasetSetting(scope scope, p *DatasetSettingsParams) (*DatasetSettings, error) {
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	s, err := scope.inst.dsSettings.Get(ref.InitID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Set(scope.Config(), p.Key, p.Value); err != nil {
+		return nil, err
+	}
+	if err := scope.inst.dsSettings.Put(ref.InitID, s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,86 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/qri-io/qri/config"
+)
+
+func TestDatasetSettingsSet(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Remotes = &config.Remotes{"staging": "http://localhost:2503"}
+	cfg.Lint = &config.Lint{Profiles: map[string]map[string]string{"strict": {"meta-title": "error"}}}
+
+	good := []struct {
+		key, value string
+		expect     DatasetSettings
+	}{
+		{"bodyformat", "csv", DatasetSettings{BodyFormat: "csv"}},
+		{"remote", "staging", DatasetSettings{Remote: "staging"}},
+		{"LintProfile", "strict", DatasetSettings{LintProfile: "strict"}},
+		{"retention", "5", DatasetSettings{Retention: 5}},
+	}
+	for _, c := range good {
+		s := DatasetSettings{}
+		if err := s.Set(cfg, c.key, c.value); err != nil {
+			t.Errorf("setting %s: %s", c.key, err)
+			continue
+		}
+		if s != c.expect {
+			t.Errorf("setting %s: want %#v, got %#v", c.key, c.expect, s)
+		}
+		if err := s.Set(cfg, c.key, ""); err != nil || !s.IsEmpty() {
+			t.Errorf("expected unsetting %s to empty settings. got %#v, err: %v", c.key, s, err)
+		}
+	}
+
+	bad := []struct {
+		key, value string
+	}{
+		{"bodyformat", "docx"},
+		{"remote", "unknown"},
+		{"lintprofile", "unknown"},
+		{"retention", "-1"},
+		{"retention", "lots"},
+		{"color", "blue"},
+	}
+	for _, c := range bad {
+		s := DatasetSettings{}
+		if err := s.Set(cfg, c.key, c.value); err == nil {
+			t.Errorf("expected setting %s to %q to error", c.key, c.value)
+		}
+	}
+}
+
+func TestDatasetSettingsSave(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	first := tr.MustSaveFromBody(t, "settings_test", tr.MustWriteTmpFile(t, "body.csv", "a,b\n1,2\n"))
+	m := tr.Instance.Config()
+	for key, value := range map[string]string{"bodyformat": "json", "retention": "1"} {
+		if _, err := m.SetDatasetSetting(tr.Ctx, &DatasetSettingsParams{Ref: "me/settings_test", Key: key, Value: value}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settings, err := m.DatasetSettings(tr.Ctx, &DatasetSettingsParams{Ref: "me/settings_test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := (DatasetSettings{BodyFormat: "json", Retention: 1}); *settings != expect {
+		t.Errorf("settings mismatch. want %#v, got %#v", expect, *settings)
+	}
+
+	second := tr.MustSaveFromBody(t, "settings_test", tr.MustWriteTmpFile(t, "body2.csv", "a,b\n3,4\n"))
+	if second.Structure.Format != "json" {
+		t.Errorf("expected body to be converted to json. got format %q", second.Structure.Format)
+	}
+
+	local, err := tr.Instance.Repo().Filesystem().Has(tr.Ctx, first.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local {
+		t.Errorf("expected retention to prune data for the first version")
+	}
+}
@@ -1,6 +1,7 @@
 package lib
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -10,8 +11,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +23,7 @@ import (
 	"github.com/qri-io/dag"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/detect"
+	"github.com/qri-io/dataset/tabular"
 	"github.com/qri-io/jsonschema"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qfs/localfs"
@@ -27,6 +31,9 @@ import (
 	"github.com/qri-io/qri/base/archive"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/base/fill"
+	"github.com/qri-io/qri/base/lint"
+	"github.com/qri-io/qri/base/query"
+	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/dscache/build"
 	"github.com/qri-io/qri/dsref"
 	qrierr "github.com/qri-io/qri/errors"
@@ -34,10 +41,14 @@ import (
 	"github.com/qri-io/qri/fsi"
 	"github.com/qri-io/qri/fsi/linkfile"
 	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/pinning"
 	"github.com/qri-io/qri/repo"
 	reporef "github.com/qri-io/qri/repo/ref"
+	"github.com/qri-io/qri/stats/binning"
+	"github.com/qri-io/qri/stats/infer"
 	"github.com/qri-io/qri/transform"
 	"github.com/qri-io/qri/transform/run"
+	"github.com/qri-io/qri/warehouse"
 )
 
 // DatasetMethods encapsulates business logic for working with Datasets on Qri
@@ -53,22 +64,37 @@ func (m DatasetMethods) Name() string {
 // Attributes defines attributes for each method
 func (m DatasetMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
-		"changereport": {AEChanges, "POST"},
-		"daginfo":      {AEDAGInfo, "GET"},
-		"diff":         {AEDiff, "GET"},
-		"get":          {AEGet, "GET"},
-		"list":         {AEList, "GET"},
+		"changereport": {endpoint: AEChanges, verb: "POST", readOnly: true},
+		"clone":        {endpoint: AEClone, verb: "POST"},
+		"copy":         {endpoint: AECopy, verb: "POST"},
+		"daginfo":      {endpoint: AEDAGInfo, verb: "GET", readOnly: true},
+		"diff":         {endpoint: AEDiff, verb: "GET", readOnly: true},
+		"emptytrash":   {endpoint: AETrashEmpty, verb: "POST"},
+		"follow":       {endpoint: AEFollow, verb: "POST"},
+		"get":          {endpoint: AEGet, verb: "GET", readOnly: true},
+		"lifecycle":    {endpoint: AELifecycle, verb: "POST"},
+		"lint":         {endpoint: AELint, verb: "POST", readOnly: true},
+		"list":         {endpoint: AEList, verb: "GET", readOnly: true},
 		// TODO(dustmop): Needs its own endpoint
-		"listrawrefs":     {AEList, "GET"},
-		"manifest":        {AEManifest, "GET"},
-		"manifestmissing": {AEManifestMissing, "GET"},
-		"pull":            {AEPull, "POST"},
-		"remove":          {AERemove, "POST"},
-		"rename":          {AERename, "POST"},
-		"save":            {AESave, "POST"},
+		"listrawrefs":      {endpoint: AEList, verb: "GET", readOnly: true},
+		"manifest":         {endpoint: AEManifest, verb: "GET", readOnly: true},
+		"manifestmissing":  {endpoint: AEManifestMissing, verb: "GET", readOnly: true},
+		"patchmeta":        {endpoint: AEPatchMeta, verb: "POST"},
+		"pin":              {endpoint: AEPin, verb: "POST"},
+		"pinstatus":        {endpoint: AEPinStatus, verb: "POST", readOnly: true},
+		"pull":             {endpoint: AEPull, verb: "POST"},
+		"remove":           {endpoint: AERemove, verb: "POST"},
+		"removemany":       {endpoint: AERemoveMany, verb: "POST"},
+		"rename":           {endpoint: AERename, verb: "POST"},
+		"restorefromtrash": {endpoint: AETrashRestore, verb: "POST"},
+		"save":             {endpoint: AESave, verb: "POST"},
+		"squash":           {endpoint: AESquash, verb: "POST"},
 		// TODO(dustmop): Needs its own endpoint
-		"stats":    {AEGet, "GET"},
-		"validate": {AEValidate, "GET"},
+		"stats":        {endpoint: AEGet, verb: "GET", readOnly: true},
+		"statshistory": {endpoint: AEStatsHistory, verb: "POST", readOnly: true},
+		"syncstatus":   {endpoint: AESyncStatus, verb: "POST", readOnly: true},
+		"trash":        {endpoint: AETrash, verb: "POST"},
+		"validate":     {endpoint: AEValidate, verb: "GET", readOnly: true},
 	}
 }
 
@@ -93,10 +119,22 @@ func (m DatasetMethods) ListRawRefs(ctx context.Context, p *ListParams) (string,
 	return "", dispatchReturnError(got, err)
 }
 
+var (
+	// QueryMaxBodyEntries is the largest body, in entries, selector queries
+	// can be evaluated over
+	QueryMaxBodyEntries = 1000000
+	// QueryMaxResultSize is the largest encoded result, in bytes, a selector
+	// query can return
+	QueryMaxResultSize = 10 << 20
+)
+
 // GetParams defines parameters for looking up the head or body of a dataset
 type GetParams struct {
 	Refstr string `json:"ref"`
 
+	// Selector picks a component or field with a dotted path like
+	// "commit.author", or queries components & the body with a JSONPath-style
+	// expression like "body[?duration > 150].movie_title"
 	Selector string `json:"selector"`
 
 	// read from a filesystem link instead of stored version
@@ -112,6 +150,19 @@ type GetParams struct {
 	// whether to generate a filename from the dataset name instead
 	GenFilename bool   `json:"genfilename"`
 	Remote      string `json:"remote"`
+
+	// Table names the table created by "sql" format exports, defaults to the
+	// dataset name
+	Table string `json:"table"`
+	// PartitionRows is the number of body rows written to each file of a
+	// "jsonl" format export. Zero writes a single file
+	PartitionRows int `json:"partitionRows"`
+	// Gzip compresses each file of a "jsonl" format export
+	Gzip bool `json:"gzip"`
+	// Lang picks a localized variant of the title, description & readme. Lang
+	// is a language tag like "es", or a list of preferences in Accept-Language
+	// header format
+	Lang string `json:"lang"`
 }
 
 // SetNonZeroDefaults assigns default values
@@ -139,6 +190,18 @@ func parseSelector(selector string) (string, string, error) {
 	if strings.HasSuffix(selector, ".zip") {
 		format = "zip"
 	}
+	if strings.HasSuffix(selector, ".sql") {
+		format = "sql"
+	}
+	if strings.HasSuffix(selector, ".jsonl") {
+		format = "jsonl"
+	}
+	if strings.HasSuffix(selector, ".yaml") {
+		format = "yaml"
+	}
+	if strings.HasSuffix(selector, ".parquet") {
+		format = "parquet"
+	}
 
 	if format != "" {
 		selector = selector[:len(selector)-len(format)-1]
@@ -151,6 +214,59 @@ func parseSelector(selector string) (string, string, error) {
 	return selector, format, nil
 }
 
+// getFormats are the formats Get can respond with
+var getFormats = []string{"json", "yaml", "csv", "zip", "sql", "jsonl", "parquet"}
+
+// bodyOnlyFormats can only encode a dataset body
+var bodyOnlyFormats = map[string]bool{
+	"csv":     true,
+	"parquet": true,
+}
+
+// acceptMediaTypes maps media types clients can request with an HTTP Accept
+// header to Get formats
+var acceptMediaTypes = map[string]string{
+	"application/json":               "json",
+	"application/x-yaml":             "yaml",
+	"application/yaml":               "yaml",
+	"text/yaml":                      "yaml",
+	"text/csv":                       "csv",
+	"application/zip":                "zip",
+	"application/vnd.apache.parquet": "parquet",
+}
+
+// acceptFormat picks the format a client prefers from the values of an HTTP
+// Accept header, respecting quality values. Ties go to the media type listed
+// first. Body-only formats are skipped unless bodyOK is true. Returns the
+// empty string if no acceptable media type is supported
+func acceptFormat(accept []string, bodyOK bool) string {
+	format := ""
+	best := 0.0
+	for _, header := range accept {
+		for _, mediaRange := range strings.Split(header, ",") {
+			parts := strings.Split(mediaRange, ";")
+			f, ok := acceptMediaTypes[strings.ToLower(strings.TrimSpace(parts[0]))]
+			if !ok || (bodyOnlyFormats[f] && !bodyOK) {
+				continue
+			}
+			q := 1.0
+			for _, param := range parts[1:] {
+				kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+				if len(kv) == 2 && kv[0] == "q" {
+					if v, err := strconv.ParseFloat(kv[1], 64); err == nil {
+						q = v
+					}
+				}
+			}
+			if q > best {
+				format = f
+				best = q
+			}
+		}
+	}
+	return format
+}
+
 func arrayContains(subject []string, target string) bool {
 	for _, v := range subject {
 		if v == target {
@@ -181,6 +297,11 @@ func (p *GetParams) UnmarshalFromRequest(r *http.Request) error {
 		return fmt.Errorf("username \"me\" not allowed")
 	}
 
+	// queries can't be expressed as a path selector, and are passed as a
+	// "query" parameter instead
+	if params.Selector == "" {
+		params.Selector = r.FormValue("query")
+	}
 	if sel, ok := mvars["selector"]; ok && params.Selector == "" {
 		selector, format, err := parseSelector(sel)
 		if err != nil {
@@ -194,22 +315,45 @@ func (p *GetParams) UnmarshalFromRequest(r *http.Request) error {
 		params.Format = r.FormValue("format")
 	}
 
-	// This HTTP header sets the format to csv, and removes the json wrapper
-	if arrayContains(r.Header["Accept"], "text/csv") {
-		if params.Format != "" && params.Format != "csv" {
-			return fmt.Errorf("format %q conflicts with header \"Accept: text/csv\"", params.Format)
+	// without an explicit format, the Accept header picks one. body-only
+	// formats select the body, removing the json wrapper
+	if params.Format == "" {
+		bodyOK := params.Selector == "" || params.Selector == "body"
+		if format := acceptFormat(r.Header["Accept"], bodyOK); format != "" {
+			params.Format = format
 		}
-		params.Format = "csv"
+	}
+	if bodyOnlyFormats[params.Format] && params.Selector == "" {
 		params.Selector = "body"
 	}
 
-	if params.Format != "" && params.Format != "json" && params.Format != "csv" && params.Format != "zip" {
+	if params.Format != "" && !arrayContains(getFormats, params.Format) {
 		return fmt.Errorf("invalid extension format")
 	}
 
+	if params.Table == "" {
+		params.Table = r.FormValue("table")
+	}
+	if params.PartitionRows == 0 {
+		if rows := r.FormValue("partitionRows"); rows != "" {
+			if params.PartitionRows, err = strconv.Atoi(rows); err != nil {
+				return fmt.Errorf("invalid partitionRows: %w", err)
+			}
+		}
+	}
+	if !params.Gzip {
+		params.Gzip = r.FormValue("gzip") == "true"
+	}
+
 	if params.Remote == "" {
 		params.Remote = r.FormValue("remote")
 	}
+	if params.Lang == "" {
+		params.Lang = r.FormValue("lang")
+	}
+	if params.Lang == "" {
+		params.Lang = r.Header.Get("Accept-Language")
+	}
 
 	// TODO(arqu): we default to true but should implement a guard and/or respect the page params
 	params.All = true
@@ -267,6 +411,65 @@ func maybeWriteOutfile(p *GetParams, res *GetResult) error {
 	return nil
 }
 
+// exportBody writes the body of an open dataset in a format for loading into
+// other systems. "sql" exports are a single SQL dump, and "parquet" exports a
+// single parquet file. "jsonl" exports may span several files: when an
+// outfile is set it names the directory files are written to, otherwise
+// files are bundled into a zip archive
+func exportBody(p *GetParams, ds *dataset.Dataset, res *GetResult) error {
+	if p.Outfile == "" && p.GenFilename {
+		p.Outfile = ds.Name
+		if p.Format == "sql" || p.Format == "parquet" {
+			p.Outfile += "." + p.Format
+		}
+	}
+
+	switch p.Format {
+	case "sql":
+		buf := &bytes.Buffer{}
+		if err := archive.WriteSQLDump(ds, p.Table, buf); err != nil {
+			return err
+		}
+		res.Bytes = buf.Bytes()
+		return maybeWriteOutfile(p, res)
+	case "parquet":
+		buf := &bytes.Buffer{}
+		if err := archive.WriteParquet(ds, buf); err != nil {
+			return err
+		}
+		res.Bytes = buf.Bytes()
+		return maybeWriteOutfile(p, res)
+	case "jsonl":
+		if p.Outfile != "" {
+			if err := os.MkdirAll(p.Outfile, os.ModePerm); err != nil {
+				return err
+			}
+			names, err := archive.WriteJSONL(ds, p.PartitionRows, p.Gzip, func(name string) (io.Writer, error) {
+				return os.Create(filepath.Join(p.Outfile, name))
+			})
+			if err != nil {
+				return err
+			}
+			res.Message = fmt.Sprintf("Wrote %d file(s) to %s", len(names), p.Outfile)
+			return nil
+		}
+
+		buf := &bytes.Buffer{}
+		zw := zip.NewWriter(buf)
+		if _, err := archive.WriteJSONL(ds, p.PartitionRows, p.Gzip, func(name string) (io.Writer, error) {
+			return zw.Create(name)
+		}); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		res.Bytes = buf.Bytes()
+		return nil
+	}
+	return fmt.Errorf("unknown export format: %q", p.Format)
+}
+
 func scriptFileSelection(ds *dataset.Dataset, selector string) (qfs.File, bool) {
 	parts := strings.Split(selector, ".")
 	if len(parts) != 2 {
@@ -301,6 +504,10 @@ type SaveParams struct {
 	Message string
 	// path to body data
 	BodyPath string `qri:"fspath"`
+	// http(s) URL to download body data from. Downloads are conditional on the
+	// ETag & Last-Modified values of the previous fetch, saving is skipped when
+	// the remote body hasn't changed unless Force is true
+	BodyURL string
 	// absolute path or URL to the list of dataset files or components to load
 	FilePaths []string `qri:"fspath"`
 	// secrets for transform execution
@@ -326,12 +533,43 @@ type SaveParams struct {
 	Drop string
 	// force a new commit, even if no changes are detected
 	Force bool
+	// CheckRemote fails the save with ErrBehindRemote if the remote configured
+	// for the dataset has versions that aren't saved locally, unless
+	// AllowBehindRemote is true
+	CheckRemote       bool
+	AllowBehindRemote bool
+	// RequireCompatibleSchema rejects saves that remove or retype columns of
+	// the previous version, unless BreakingChange is set. The compatibility
+	// verdict is recorded in the commit message
+	RequireCompatibleSchema bool
+	// BreakingChange allows a save that requires a compatible schema to
+	// change the schema incompatibly
+	BreakingChange bool
+	// RecordEnvironment records the qri version, transform runtime, platform
+	// & transform resource versions in the commit. Always on if the repo
+	// config sets recordenvironment
+	RecordEnvironment bool
+	// ExtractMeta fills empty meta fields & column descriptions from a
+	// frictionless datapackage.json alongside the body, or a second header row
+	// of a CSV body, listing extracted fields in the commit message
+	ExtractMeta bool
+	// Canonical writes components with a deterministic JSON encoding, so the
+	// same dataset always hashes & signs the same way
+	Canonical bool
 	// save a rendered version of the template along with the dataset
 	ShouldRender bool
 	// new dataset only, don't create a commit on an existing dataset, name will be unused
 	NewName bool
 	// whether to create a new dscache if none exists
 	UseDscache bool
+	// sampler used to infer the schema of a new body, one of "prefix", "full",
+	// "reservoir" or "stratified". defaults to the stats inference config
+	InferSampler string
+	// DryRun computes the version a save would create without writing
+	// anything. The returned dataset has no path, its commit title & message
+	// summarize changes from the previous version, and its stats component
+	// reflects the would-be body
+	DryRun bool
 }
 
 // UnmarshalFromRequest implements a custom deserialization-from-HTTP request
@@ -398,9 +636,21 @@ func (p *SaveParams) UnmarshalFromRequest(r *http.Request) error {
 	if v := r.FormValue("bodypath"); v != "" {
 		p.BodyPath = v
 	}
+	if v := r.FormValue("bodyurl"); v != "" {
+		p.BodyURL = v
+	}
 	if v := r.FormValue("drop"); v != "" {
 		p.Drop = v
 	}
+	if v := r.FormValue("dry_run"); v != "" {
+		p.DryRun = v == "true"
+	}
+	if v := r.FormValue("extract_meta"); v != "" {
+		p.ExtractMeta = v == "true"
+	}
+	if v := r.FormValue("canonical"); v != "" {
+		p.Canonical = v == "true"
+	}
 
 	if r.FormValue("secrets") != "" {
 		p.Secrets = map[string]string{}
@@ -420,6 +670,14 @@ func (p *SaveParams) SetNonZeroDefaults() {
 	p.ConvertFormatToPrev = true
 }
 
+// Validate returns an error if SaveParams fields are in an invalid state
+func (p *SaveParams) Validate() error {
+	if p.BodyURL != "" && p.BodyPath != "" {
+		return fmt.Errorf("cannot provide both a body path and a body url")
+	}
+	return infer.Config{Sampler: p.InferSampler}.Validate()
+}
+
 // Save adds a history entry, updating a dataset
 func (m DatasetMethods) Save(ctx context.Context, p *SaveParams) (*dataset.Dataset, error) {
 	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "save"), p)
@@ -434,10 +692,13 @@ type RenameParams struct {
 	Current, Next string
 }
 
-// Rename changes a user's given name for a dataset
-func (m DatasetMethods) Rename(ctx context.Context, p *RenameParams) (*dsref.VersionInfo, error) {
+// Rename changes a user's given name for a dataset. References to the
+// previous name are moved to the new name: a linked working directory, queued
+// pushes, the content index entry, and transform scripts of linked working
+// directories that load the dataset
+func (m DatasetMethods) Rename(ctx context.Context, p *RenameParams) (*RenameResult, error) {
 	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "rename"), p)
-	if res, ok := got.(*dsref.VersionInfo); ok {
+	if res, ok := got.(*RenameResult); ok {
 		return res, err
 	}
 	return nil, dispatchReturnError(got, err)
@@ -450,6 +711,9 @@ type RemoveParams struct {
 	KeepFiles bool
 	Force     bool
 	Remote    string
+	// Purge removes stored versions right away when removing an entire
+	// dataset, instead of keeping them in the trash
+	Purge bool
 }
 
 // RemoveResponse gives the results of a remove
@@ -458,6 +722,9 @@ type RemoveResponse struct {
 	NumDeleted int
 	Message    string
 	Unlinked   bool
+	// Trashed is true when stored versions of a removed dataset are kept in
+	// the trash, where the dataset can be restored from
+	Trashed bool
 }
 
 // UnmarshalFromRequest implements a custom deserialization-from-HTTP request
@@ -478,6 +745,9 @@ func (p *RemoveParams) UnmarshalFromRequest(r *http.Request) error {
 	if p.Force == false {
 		p.Force = r.FormValue("force") == "true"
 	}
+	if p.Purge == false {
+		p.Purge = r.FormValue("purge") == "true"
+	}
 
 	if r.FormValue("all") == "true" {
 		p.Revision = dsref.NewAllRevisions()
@@ -505,12 +775,245 @@ func (m DatasetMethods) Remove(ctx context.Context, p *RemoveParams) (*RemoveRes
 	return nil, dispatchReturnError(got, err)
 }
 
+// RemoveManyParams defines parameters for removing many datasets at once
+type RemoveManyParams struct {
+	// Refs are dataset references or patterns matched against dataset aliases,
+	// like "me/tmp_*". Patterns use the syntax of path.Match
+	Refs []string
+	// DryRun reports what would be removed without removing anything
+	DryRun    bool
+	KeepFiles bool
+	Force     bool
+	// Purge removes stored versions right away instead of keeping removed
+	// datasets in the trash
+	Purge bool
+}
+
+// Validate returns an error if RemoveManyParams fields are in an invalid state
+func (p *RemoveManyParams) Validate() error {
+	if len(p.Refs) == 0 {
+		return fmt.Errorf("at least one dataset reference or pattern is required")
+	}
+	for _, pattern := range p.Refs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// RemoveManyItem describes the impact of removing a single dataset
+type RemoveManyItem struct {
+	Ref      string `json:"ref"`
+	Versions int    `json:"versions"`
+	// PinnedBytes is the size of all versions of the dataset. Versions share
+	// blocks, so this is an upper bound on the space freed by removal
+	PinnedBytes uint64 `json:"pinnedBytes"`
+	FSIPath     string `json:"fsiPath,omitempty"`
+	Unlinked    bool   `json:"unlinked,omitempty"`
+}
+
+// RemoveManyReport summarizes the impact of a RemoveMany call
+type RemoveManyReport struct {
+	Datasets    []RemoveManyItem `json:"datasets"`
+	Versions    int              `json:"versions"`
+	PinnedBytes uint64           `json:"pinnedBytes"`
+	// Removed is false for dry runs
+	Removed bool `json:"removed"`
+}
+
+// RemoveMany removes all versions of every dataset matching p.Refs. Every
+// dataset is checked before any are removed, so a dataset that can't be
+// removed (for example one with a dirty working directory) stops the whole
+// operation without changing anything. A dry run returns the same report
+// without removing datasets, clients show the report & ask for a single
+// confirmation before removing
+func (m DatasetMethods) RemoveMany(ctx context.Context, p *RemoveManyParams) (*RemoveManyReport, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "removemany"), p)
+	if res, ok := got.(*RemoveManyReport); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// TrashParams defines parameters for listing the trash
+type TrashParams struct{}
+
+// Trash lists removed datasets that are kept in the trash, most recently
+// removed first. Removing an entire dataset keeps its stored versions in the
+// trash until the repo's trash grace period expires, when garbage collection
+// purges them
+func (m DatasetMethods) Trash(ctx context.Context, p *TrashParams) ([]TrashedDataset, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "trash"), p)
+	if res, ok := got.([]TrashedDataset); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// RestoreFromTrashParams defines parameters for restoring a dataset from the
+// trash
+type RestoreFromTrashParams struct {
+	// Ref is the alias or latest version path of a removed dataset. Aliases
+	// removed more than once restore the most recently removed dataset
+	Ref string `json:"ref"`
+}
+
+// Validate returns an error if RestoreFromTrashParams fields are in an
+// invalid state
+func (p *RestoreFromTrashParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("dataset reference is required")
+	}
+	return nil
+}
+
+// RestoreFromTrash brings back a dataset from the trash. History is
+// reconstructed from stored versions. Restoring fails if a dataset with the
+// same name has been created since removal
+func (m DatasetMethods) RestoreFromTrash(ctx context.Context, p *RestoreFromTrashParams) (*dsref.VersionInfo, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "restorefromtrash"), p)
+	if res, ok := got.(*dsref.VersionInfo); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// EmptyTrashParams defines parameters for emptying the trash
+type EmptyTrashParams struct {
+	// Ref limits purging to a single removed dataset, by alias or latest
+	// version path
+	Ref string `json:"ref,omitempty"`
+	// Expired limits purging to datasets with an expired grace period
+	Expired bool `json:"expired,omitempty"`
+}
+
+// EmptyTrash purges stored versions of datasets in the trash, returning the
+// purged datasets. Purged datasets can't be restored
+func (m DatasetMethods) EmptyTrash(ctx context.Context, p *EmptyTrashParams) ([]TrashedDataset, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "emptytrash"), p)
+	if res, ok := got.([]TrashedDataset); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// SquashParams defines parameters for collapsing the latest versions of a
+// dataset into a single version
+type SquashParams struct {
+	Ref string `json:"ref"`
+	// Count is the number of versions to collapse, starting from the latest
+	Count int `json:"count"`
+	// Title & Message of the squashed version. Defaults combine the commit
+	// descriptions of squashed versions
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	// Unpin removes the stored data of squashed versions
+	Unpin bool `json:"unpin"`
+	// Force squashes versions that have been pushed to a remote
+	Force bool `json:"force"`
+}
+
+// Validate returns an error if SquashParams fields are in an invalid state
+func (p *SquashParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("dataset reference is required")
+	}
+	if p.Count < 2 {
+		return fmt.Errorf("squash requires at least 2 versions")
+	}
+	return nil
+}
+
+// SquashResult describes the version created by a squash
+type SquashResult struct {
+	Ref          string `json:"ref"`
+	Path         string `json:"path"`
+	PreviousPath string `json:"previousPath,omitempty"`
+	// Squashed lists the paths of collapsed versions, newest first
+	Squashed []string `json:"squashed"`
+	// Unpinned lists the paths of squashed versions removed from the store
+	Unpinned []string `json:"unpinned,omitempty"`
+}
+
+// Squash collapses the latest p.Count versions of a dataset into a single
+// version with the contents of the current head. Squashed versions are
+// dropped from history. Versions that have been pushed to a remote are only
+// squashed with p.Force, peers that pulled them keep the old history
+func (m DatasetMethods) Squash(ctx context.Context, p *SquashParams) (*SquashResult, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "squash"), p)
+	if res, ok := got.(*SquashResult); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// SyncStatusParams defines parameters for listing warehouse sync statuses
+type SyncStatusParams struct {
+	// Ref limits statuses to a single dataset, all datasets if empty
+	Ref string `json:"ref"`
+}
+
+// SyncStatus lists the outcome of syncing dataset versions to configured
+// warehouses, newest first
+func (m DatasetMethods) SyncStatus(ctx context.Context, p *SyncStatusParams) ([]warehouse.Status, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "syncstatus"), p)
+	if res, ok := got.([]warehouse.Status); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// PinParams defines parameters for pinning a dataset version to configured
+// pinning services
+type PinParams struct {
+	Ref string `json:"ref"`
+}
+
+// Pin asks pinning services enabled for a dataset to pin a version. Published
+// versions are pinned automatically, Pin retries failed or missing pins
+func (m DatasetMethods) Pin(ctx context.Context, p *PinParams) ([]pinning.Status, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "pin"), p)
+	if res, ok := got.([]pinning.Status); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// PinStatusParams defines parameters for listing pin statuses
+type PinStatusParams struct {
+	// Ref limits statuses to a single dataset, all datasets if empty
+	Ref string `json:"ref"`
+	// Refresh asks services for the current state of unfinished pins
+	Refresh bool `json:"refresh"`
+}
+
+// PinStatus lists the state of pinning dataset versions to configured
+// pinning services, newest first
+func (m DatasetMethods) PinStatus(ctx context.Context, p *PinStatusParams) ([]pinning.Status, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "pinstatus"), p)
+	if res, ok := got.([]pinning.Status); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
 // PullParams encapsulates parameters to the add command
 type PullParams struct {
 	Ref      string
 	LinkDir  string `qri:"fspath"`
 	Remote   string // remote to attempt to pull from
 	LogsOnly bool   // only fetch logbook data
+	// MetaOnly fetches logbook data & head components, skipping body data.
+	// The body is fetched when the dataset is loaded
+	MetaOnly bool
+	// Depth pulls data for the latest Depth versions, marking the dataset as
+	// shallow when older versions are left without data. Logbook data for
+	// every version is always pulled. Negative values pull data for every
+	// version, zero pulls the latest version without marking the dataset
+	Depth int
+	// Deepen pulls data for Deepen more versions of a shallow dataset
+	Deepen int
 }
 
 // UnmarshalFromRequest implements a custom deserialization-from-HTTP request
@@ -522,6 +1025,20 @@ func (p *PullParams) UnmarshalFromRequest(r *http.Request) error {
 	return nil
 }
 
+// Validate returns an error if PullParams fields are in an invalid state
+func (p *PullParams) Validate() error {
+	if p.Deepen < 0 {
+		return fmt.Errorf("deepen must be a positive number of versions")
+	}
+	if p.Depth != 0 && p.Deepen > 0 {
+		return fmt.Errorf("can't pull with both depth & deepen")
+	}
+	if (p.Depth != 0 || p.Deepen > 0) && (p.LogsOnly || p.MetaOnly) {
+		return fmt.Errorf("depth can't be combined with logs-only or meta-only pulls")
+	}
+	return nil
+}
+
 // Pull downloads and stores an existing dataset to a peer's repository via
 // a network connection
 func (m DatasetMethods) Pull(ctx context.Context, p *PullParams) (*dataset.Dataset, error) {
@@ -532,6 +1049,67 @@ func (m DatasetMethods) Pull(ctx context.Context, p *PullParams) (*dataset.Datas
 	return nil, dispatchReturnError(got, err)
 }
 
+// CloneParams defines parameters for cloning a dataset
+type CloneParams struct {
+	Ref    string
+	Remote string // remote to pull from
+	// Depth is the number of versions to pull, starting from the latest.
+	// Values less than one pull all versions
+	Depth int
+	// Dir is the working directory to check the dataset out to. No working
+	// directory is created if Dir is empty
+	Dir string `qri:"fspath"`
+	// Follow pulls new versions of the dataset as they're published
+	Follow bool
+}
+
+// Validate returns an error if CloneParams fields are in an invalid state
+func (p *CloneParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("dataset reference is required")
+	}
+	return nil
+}
+
+// CloneResult describes a cloned dataset
+type CloneResult struct {
+	Dataset *dataset.Dataset `json:"dataset"`
+	// Versions is the number of versions stored locally
+	Versions  int    `json:"versions"`
+	Dir       string `json:"dir,omitempty"`
+	Following bool   `json:"following"`
+}
+
+// Clone pulls a dataset with a chosen number of versions, creates a linked
+// working directory, and optionally follows the dataset for new versions
+func (m DatasetMethods) Clone(ctx context.Context, p *CloneParams) (*CloneResult, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "clone"), p)
+	if res, ok := got.(*CloneResult); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// FollowParams defines parameters for following a dataset
+type FollowParams struct {
+	Ref    string
+	Remote string // remote to pull new versions from
+	// Unfollow stops following the dataset
+	Unfollow bool
+	// List only lists followed datasets, ignoring other parameters
+	List bool
+}
+
+// Follow starts or stops automatically pulling new versions of a dataset,
+// returning the list of followed datasets
+func (m DatasetMethods) Follow(ctx context.Context, p *FollowParams) ([]Follow, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "follow"), p)
+	if res, ok := got.([]Follow); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
 // ValidateParams defines parameters for dataset data validation
 type ValidateParams struct {
 	Ref               string
@@ -566,6 +1144,21 @@ func (m DatasetMethods) Validate(ctx context.Context, p *ValidateParams) (*Valid
 	return nil, dispatchReturnError(got, err)
 }
 
+// LintParams defines parameters for linting a dataset
+type LintParams struct {
+	Ref string `json:"ref"`
+}
+
+// Lint checks a dataset against lint rules, using the rule severities set in
+// the lint configuration
+func (m DatasetMethods) Lint(ctx context.Context, p *LintParams) (*lint.Report, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "lint"), p)
+	if res, ok := got.(*lint.Report); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
 // ManifestParams encapsulates parameters to the manifest command
 type ManifestParams struct {
 	Refstr string
@@ -626,66 +1219,331 @@ func (m DatasetMethods) Stats(ctx context.Context, p *StatsParams) (*dataset.Sta
 	return nil, dispatchReturnError(got, err)
 }
 
-// formFileDataset extracts a dataset document from a http Request
-func formFileDataset(r *http.Request, ds *dataset.Dataset) (err error) {
-	datafile, dataHeader, err := r.FormFile("file")
-	if err == http.ErrMissingFile {
-		err = nil
-	} else if err != nil {
-		err = fmt.Errorf("error opening dataset file: %s", err)
-		return
-	}
-	if datafile != nil {
-		switch strings.ToLower(filepath.Ext(dataHeader.Filename)) {
-		case ".yaml", ".yml":
-			var data []byte
-			data, err = ioutil.ReadAll(datafile)
-			if err != nil {
-				err = fmt.Errorf("reading dataset file: %w", err)
-				return
-			}
-			fields := &map[string]interface{}{}
-			if err = yaml.Unmarshal(data, fields); err != nil {
-				err = fmt.Errorf("deserializing YAML file: %w", err)
-				return
-			}
-			if err = fill.Struct(*fields, ds); err != nil {
-				return
-			}
-		case ".json":
-			if err = json.NewDecoder(datafile).Decode(ds); err != nil {
-				err = fmt.Errorf("error decoding json file: %s", err)
-				return
-			}
-		}
+// StatsHistoryParams defines parameters for extracting stats across the
+// history of a dataset
+type StatsHistoryParams struct {
+	Ref string `json:"ref"`
+	// Columns limits the columns included in the history, empty includes all
+	// columns
+	Columns []string `json:"columns"`
+	// Limit is the number of versions to include, starting from the latest.
+	// 0 uses the default limit, -1 includes every version
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// defaultStatsHistoryLimit is the number of versions included when no limit
+// is given
+const defaultStatsHistoryLimit = 25
+
+// SetNonZeroDefaults sets a default limit
+func (p *StatsHistoryParams) SetNonZeroDefaults() {
+	if p.Limit == 0 {
+		p.Limit = defaultStatsHistoryLimit
 	}
+}
 
-	if peername := r.FormValue("peername"); peername != "" {
-		ds.Peername = peername
+// Validate returns an error if StatsHistoryParams fields are in an invalid state
+func (p *StatsHistoryParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("dataset reference is required")
 	}
-	if name := r.FormValue("name"); name != "" {
-		ds.Name = name
+	if p.Offset < 0 {
+		return fmt.Errorf("offset can't be negative")
 	}
-	if bp := r.FormValue("body_path"); bp != "" {
-		ds.BodyPath = bp
+	return nil
+}
+
+// StatsHistory is a time series of summary stats, with one entry for each
+// version of a dataset
+type StatsHistory struct {
+	Ref string `json:"ref"`
+	// Versions are ordered from newest to oldest
+	Versions []StatsHistoryVersion `json:"versions"`
+}
+
+// StatsHistoryVersion holds summary stats for a single dataset version
+type StatsHistoryVersion struct {
+	Path        string    `json:"path"`
+	CommitTime  time.Time `json:"commitTime"`
+	CommitTitle string    `json:"commitTitle,omitempty"`
+	// Rows is the number of entries in the body
+	Rows    int                  `json:"rows"`
+	Columns []StatsHistoryColumn `json:"columns,omitempty"`
+	// Error describes why stats couldn't be calculated for the version,
+	// for example because it isn't stored locally
+	Error string `json:"error,omitempty"`
+}
+
+// StatsHistoryColumn holds summary stats for a single column of a version
+type StatsHistoryColumn struct {
+	Name string `json:"name"`
+	// Type is the stats type of the column, one of "numeric", "string" or
+	// "boolean"
+	Type string `json:"type,omitempty"`
+	// Count is the number of values of the column type
+	Count int `json:"count"`
+	// NullPercent is the percentage of rows without a value of the column type
+	NullPercent float64 `json:"nullPercent"`
+	// Min & Max are only set for numeric columns
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// StatsHistory extracts summary stats from each version of a dataset into a
+// time series
+func (m DatasetMethods) StatsHistory(ctx context.Context, p *StatsHistoryParams) (*StatsHistory, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "statshistory"), p)
+	if res, ok := got.(*StatsHistory); ok {
+		return res, err
 	}
+	return nil, dispatchReturnError(got, err)
+}
 
-	tfFile, tfHeader, err := r.FormFile("transform")
-	if err == http.ErrMissingFile {
-		err = nil
-	} else if err != nil {
-		err = fmt.Errorf("error opening transform file: %s", err)
-		return
+// MetaPatchParams defines parameters for patching the meta component of many
+// datasets at once
+type MetaPatchParams struct {
+	// references of datasets to patch
+	Refs []string
+	// JSON merge patch (RFC 7396) to apply to each meta component
+	Patch map[string]interface{}
+	// commit title & message, title defaults to "patch meta"
+	Title   string
+	Message string
+	// Lang applies the patch to the localized variant for a language tag
+	// instead of the meta component itself. Localized patches can only set
+	// title, description & readme
+	Lang string
+}
+
+// Validate returns an error if MetaPatchParams fields are in an invalid state
+func (p *MetaPatchParams) Validate() error {
+	if len(p.Refs) == 0 {
+		return fmt.Errorf("at least one dataset reference is required")
 	}
-	if tfFile != nil {
-		var tfData []byte
-		if tfData, err = ioutil.ReadAll(tfFile); err != nil {
-			return
-		}
-		if ds.Transform == nil {
-			ds.Transform = &dataset.Transform{}
+	if len(p.Patch) == 0 {
+		return fmt.Errorf("patch is required")
+	}
+	if p.Lang != "" {
+		for field := range p.Patch {
+			if !arrayContains(base.LocalizedFields, field) {
+				return fmt.Errorf("field %q can't be localized. localizable fields: %s", field, strings.Join(base.LocalizedFields, ", "))
+			}
 		}
-		ds.Transform.Syntax = "starlark"
+	}
+	return nil
+}
+
+// MetaPatchResult describes the outcome of patching a single dataset
+type MetaPatchResult struct {
+	Ref string `json:"ref"`
+	// Path of the newly created version, empty if no version was saved
+	Path string `json:"path,omitempty"`
+	// Unchanged is true when applying the patch produced no changes
+	Unchanged bool   `json:"unchanged,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// MetaPatchReport summarizes the results of a PatchMeta call
+type MetaPatchReport struct {
+	Results   []MetaPatchResult `json:"results"`
+	Saved     int               `json:"saved"`
+	Unchanged int               `json:"unchanged"`
+	Failed    int               `json:"failed"`
+}
+
+// PatchMeta applies a JSON merge patch to the meta component of each dataset
+// in p.Refs, creating one commit per changed dataset. Failing to patch a single
+// dataset doesn't stop the operation, per-dataset outcomes are reported in the
+// returned summary
+func (m DatasetMethods) PatchMeta(ctx context.Context, p *MetaPatchParams) (*MetaPatchReport, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "patchmeta"), p)
+	if res, ok := got.(*MetaPatchReport); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// LifecycleParams defines parameters for setting the lifecycle state of a
+// dataset
+type LifecycleParams struct {
+	Ref string `json:"ref"`
+	// State is one of "active", "deprecated" or "archived"
+	State string `json:"state"`
+	// Reason is a human-readable explanation for the change
+	Reason string `json:"reason"`
+	// Successor is an optional reference to a dataset that replaces this one
+	Successor string `json:"successor"`
+}
+
+// Validate returns an error if LifecycleParams fields are in an invalid state
+func (p *LifecycleParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("dataset reference is required")
+	}
+	if !logbook.IsValidLifecycleState(p.State) {
+		return fmt.Errorf("invalid lifecycle state %q, must be one of %q, %q or %q", p.State, logbook.LifecycleActive, logbook.LifecycleDeprecated, logbook.LifecycleArchived)
+	}
+	if p.Successor != "" {
+		if p.State == logbook.LifecycleActive {
+			return fmt.Errorf("active datasets can't have a successor")
+		}
+		if _, err := dsref.Parse(p.Successor); err != nil {
+			return fmt.Errorf("successor: %w", err)
+		}
+	}
+	return nil
+}
+
+// Lifecycle sets the lifecycle state of a dataset. Deprecated datasets still
+// work, but are flagged in listings & warned about when loaded by transforms.
+// Archived datasets are no longer maintained. Setting the state to "active"
+// clears any earlier state
+func (m DatasetMethods) Lifecycle(ctx context.Context, p *LifecycleParams) (*dsref.VersionInfo, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "lifecycle"), p)
+	if res, ok := got.(*dsref.VersionInfo); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// checkRemoveProtection returns logbook.ErrProtected if the protection rules of
+// a dataset forbid removing versions, or with all set, the whole dataset.
+// Removing datasets owned by others only drops the local copy, and is always
+// allowed
+func checkRemoveProtection(scope scope, ref dsref.Ref, all bool) error {
+	book := scope.Logbook()
+	if ref.InitID == "" || ref.Username != book.Username() {
+		return nil
+	}
+	pr, err := book.DatasetProtection(scope.Context(), ref.InitID)
+	if errors.Is(err, logbook.ErrNotFound) || errors.Is(err, logbook.ErrNoLogbook) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if all {
+		return pr.CheckDelete()
+	}
+	return pr.CheckRewrite()
+}
+
+// inferConfig builds schema inference settings from configuration, with a
+// non-empty sampler overriding the configured sampler
+func inferConfig(cfg *config.Config, sampler string) infer.Config {
+	ic := infer.Config{}
+	if cfg != nil && cfg.Stats != nil && cfg.Stats.Inference != nil {
+		inf := cfg.Stats.Inference
+		ic = infer.Config{
+			Sampler:    inf.Sampler,
+			SampleSize: inf.SampleSize,
+			ChunkSize:  inf.ChunkSize,
+			Seed:       inf.Seed,
+		}
+	}
+	if sampler != "" {
+		ic.Sampler = sampler
+	}
+	return ic
+}
+
+// binningConfig builds stats binning settings from configuration
+func binningConfig(cfg *config.Config) binning.Config {
+	if cfg == nil || cfg.Stats == nil || cfg.Stats.Binning == nil {
+		return binning.Config{}
+	}
+	b := cfg.Stats.Binning
+	return binning.Config{
+		Strategy: b.Strategy,
+		Bins:     b.Bins,
+		BinWidth: b.BinWidth,
+		TopK:     b.TopK,
+	}
+}
+
+// recordEnvironment checks if the repo config records environments in commits
+func recordEnvironment(cfg *config.Config) bool {
+	return cfg != nil && cfg.Repo != nil && cfg.Repo.RecordEnvironment
+}
+
+// lintConfig builds lint settings from configuration, applying the named
+// lint profile if one is given
+func lintConfig(cfg *config.Config, profile string) lint.Config {
+	lc := lint.Config{}
+	if cfg == nil || cfg.Lint == nil {
+		return lc
+	}
+	lc.BlockSaves = cfg.Lint.BlockSaves
+	lc.Severities = make(map[string]lint.Severity, len(cfg.Lint.Rules))
+	for id, sev := range cfg.Lint.Rules {
+		lc.Severities[id] = lint.Severity(sev)
+	}
+	for id, sev := range cfg.Lint.Profiles[profile] {
+		lc.Severities[id] = lint.Severity(sev)
+	}
+	return lc
+}
+
+// formFileDataset extracts a dataset document from a http Request
+func formFileDataset(r *http.Request, ds *dataset.Dataset) (err error) {
+	datafile, dataHeader, err := r.FormFile("file")
+	if err == http.ErrMissingFile {
+		err = nil
+	} else if err != nil {
+		err = fmt.Errorf("error opening dataset file: %s", err)
+		return
+	}
+	if datafile != nil {
+		switch strings.ToLower(filepath.Ext(dataHeader.Filename)) {
+		case ".yaml", ".yml":
+			var data []byte
+			data, err = ioutil.ReadAll(datafile)
+			if err != nil {
+				err = fmt.Errorf("reading dataset file: %w", err)
+				return
+			}
+			fields := &map[string]interface{}{}
+			if err = yaml.Unmarshal(data, fields); err != nil {
+				err = fmt.Errorf("deserializing YAML file: %w", err)
+				return
+			}
+			if err = fill.Struct(*fields, ds); err != nil {
+				return
+			}
+		case ".json":
+			if err = json.NewDecoder(datafile).Decode(ds); err != nil {
+				err = fmt.Errorf("error decoding json file: %s", err)
+				return
+			}
+		}
+	}
+
+	if peername := r.FormValue("peername"); peername != "" {
+		ds.Peername = peername
+	}
+	if name := r.FormValue("name"); name != "" {
+		ds.Name = name
+	}
+	if bp := r.FormValue("body_path"); bp != "" {
+		ds.BodyPath = bp
+	}
+
+	tfFile, tfHeader, err := r.FormFile("transform")
+	if err == http.ErrMissingFile {
+		err = nil
+	} else if err != nil {
+		err = fmt.Errorf("error opening transform file: %s", err)
+		return
+	}
+	if tfFile != nil {
+		var tfData []byte
+		if tfData, err = ioutil.ReadAll(tfFile); err != nil {
+			return
+		}
+		if ds.Transform == nil {
+			ds.Transform = &dataset.Transform{}
+		}
+		ds.Transform.Syntax = "starlark"
 		ds.Transform.ScriptBytes = tfData
 		ds.Transform.ScriptPath = tfHeader.Filename
 	}
@@ -785,7 +1643,14 @@ func (datasetImpl) List(scope scope, p *ListParams) ([]dsref.VersionInfo, error)
 				log.Error(err)
 			}
 		}
-		refs, err := c.ListRefs()
+		// the cache holds datasets of every profile in a multi-tenant instance.
+		// profiles other than the owner only list their own datasets
+		var refs []reporef.DatasetRef
+		if pro := scope.ActiveProfile(); pro != nil && pro.ID != reqProfile.ID {
+			refs, err = c.ListRefsForProfile(pro.ID.String())
+		} else {
+			refs, err = c.ListRefs()
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -818,7 +1683,9 @@ func (datasetImpl) List(scope scope, p *ListParams) ([]dsref.VersionInfo, error)
 			infos[i] = reporef.ConvertToVersionInfo(&r)
 		}
 	} else if listProfile.Peername == "" || reqProfile.Peername == listProfile.Peername {
-		infos, err = base.ListDatasets(scope.Context(), scope.Repo(), p.Term, restrictPid, p.Offset, p.Limit, p.RPC, p.Public, p.ShowNumVersions)
+		// listing only reports on local data, don't wait on network fetches
+		ctx := dsfs.WithLocalOnly(scope.Context())
+		infos, err = base.ListDatasets(ctx, scope.Repo(), p.Term, restrictPid, p.Offset, p.Limit, p.RPC, p.Public, p.ShowNumVersions)
 		if errors.Is(err, ErrListWarning) {
 			listWarning = err
 			err = nil
@@ -851,6 +1718,8 @@ func (datasetImpl) List(scope scope, p *ListParams) ([]dsref.VersionInfo, error)
 		}
 	}
 
+	fillLifecycles(scope, infos)
+
 	if listWarning != nil {
 		return nil, listWarning
 	}
@@ -938,6 +1807,20 @@ func (datasetImpl) Get(scope scope, p *GetParams) (*GetResult, error) {
 		return res, nil
 	}
 
+	if p.Lang != "" {
+		base.Localize(ds, base.ParseAcceptLanguage(p.Lang))
+	}
+
+	if p.Format == "sql" || p.Format == "jsonl" || p.Format == "parquet" {
+		if p.Selector != "" && p.Selector != "body" {
+			return nil, fmt.Errorf("%s format can only export the dataset body", p.Format)
+		}
+		if err := exportBody(p, ds, res); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
 	if p.Selector == "body" {
 		// `qri get body` loads the body
 		if !p.All && (p.Limit < 0 || p.Offset < 0) {
@@ -974,6 +1857,20 @@ func (datasetImpl) Get(scope scope, p *GetParams) (*GetResult, error) {
 			return nil, err
 		}
 		return res, nil
+	} else if p.Selector == "readme.html" {
+		// render the readme as html, converting from the readme format
+		if ds.Readme == nil || ds.Readme.ScriptFile() == nil {
+			return nil, fmt.Errorf("no readme to render")
+		}
+		rendered, err := base.RenderReadmeFormat(scope.Context(), ds.Readme.ScriptFile(), ds.Readme.Format)
+		if err != nil {
+			return nil, err
+		}
+		res.Bytes = []byte(rendered)
+		if err = maybeWriteOutfile(p, res); err != nil {
+			return nil, err
+		}
+		return res, nil
 	} else if scriptFile, ok := scriptFileSelection(ds, p.Selector); ok {
 		// Fields that have qfs.File types should be read and returned
 		res.Bytes, err = ioutil.ReadAll(scriptFile)
@@ -1005,6 +1902,18 @@ func (datasetImpl) Get(scope scope, p *GetParams) (*GetResult, error) {
 	if p.Selector == "" {
 		// `qri get` without a selector loads only the dataset head
 		value = res.Dataset
+	} else if p.Selector == "environment" {
+		// the environment is recorded in the commit message
+		env := dsfs.ParseEnvironment(ds.Commit)
+		if env == nil {
+			return nil, fmt.Errorf("no environment was recorded for this version")
+		}
+		value = env
+	} else if query.IsQuery(p.Selector) {
+		// `qri get 'body[?duration > 150]'` evaluates a query
+		if value, err = base.QueryDataset(ds, p.Selector, QueryMaxBodyEntries); err != nil {
+			return nil, err
+		}
 	} else {
 		// `qri get <selector>` loads only the applicable component / field
 		value, err = base.ApplyPath(res.Dataset, p.Selector)
@@ -1041,6 +1950,9 @@ func (datasetImpl) Get(scope scope, p *GetParams) (*GetResult, error) {
 	default:
 		return nil, fmt.Errorf("unknown format: \"%s\"", p.Format)
 	}
+	if query.IsQuery(p.Selector) && len(res.Bytes) > QueryMaxResultSize {
+		return nil, fmt.Errorf("%w: result is %d bytes, queries can return at most %d", base.ErrQueryTooLarge, len(res.Bytes), QueryMaxResultSize)
+	}
 	err = maybeWriteOutfile(p, res)
 	if err != nil {
 		return nil, err
@@ -1050,7 +1962,7 @@ func (datasetImpl) Get(scope scope, p *GetParams) (*GetResult, error) {
 
 // Save adds a history entry, updating a dataset
 func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
-	log.Debugw("DatasetMethods.Save", "ref", p.Ref, "apply", p.Apply)
+	log.Debugw("DatasetMethods.Save", "ref", p.Ref, "apply", p.Apply, "dryRun", p.DryRun)
 	res := &dataset.Dataset{}
 
 	var (
@@ -1101,7 +2013,21 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 		return nil, err
 	}
 
-	ref, isNew, err := base.PrepareSaveRef(scope.Context(), pro, scope.Logbook(), resolver, p.Ref, ds.BodyPath, p.NewName)
+	bodyPathNameHint := ds.BodyPath
+	if p.BodyURL != "" {
+		bodyPathNameHint = p.BodyURL
+	}
+
+	var (
+		ref   dsref.Ref
+		isNew bool
+	)
+	if p.DryRun {
+		// dry runs don't create a log for new datasets
+		ref, isNew, err = base.PreviewSaveRef(scope.Context(), pro, resolver, p.Ref, bodyPathNameHint, p.NewName)
+	} else {
+		ref, isNew, err = base.PrepareSaveRef(scope.Context(), pro, scope.Logbook(), resolver, p.Ref, bodyPathNameHint, p.NewName)
+	}
 	if err != nil {
 		log.Debugw("save PrepareSaveRef", "refParam", p.Ref, "wantNewName", p.NewName, "err", err)
 		return nil, err
@@ -1110,7 +2036,7 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 	success := false
 	defer func() {
 		// if creating a new dataset fails, we need to remove the dataset
-		if isNew && !success {
+		if isNew && !success && !p.DryRun {
 			log.Debugf("removing unused log for new dataset %s", ref)
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
 			if err := scope.Logbook().RemoveLog(ctx, ref); err != nil {
@@ -1136,11 +2062,49 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 			fsiDs.Assign(ds)
 			ds = fsiDs
 		}
+
+		if p.CheckRemote && !p.AllowBehindRemote {
+			if err := checkRemoteHead(scope, ref, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// bodyFetch records the state of a body downloaded from p.BodyURL
+	var bodyFetch *base.BodyFetchInfo
+	if p.BodyURL != "" {
+		if err := checkBodyURLHost(scope, p.BodyURL); err != nil {
+			return nil, err
+		}
+		var prevFetch *base.BodyFetchInfo
+		if !isNew && !p.Force {
+			prevFetch = scope.BodyFetches().Get(ref.InitID)
+		}
+		bodyFile, info, err := base.FetchBodyURL(scope.Context(), p.BodyURL, prevFetch)
+		if err != nil {
+			if errors.Is(err, base.ErrBodyURLNotModified) {
+				return nil, fmt.Errorf("%w: %s", dsfs.ErrNoChanges, err)
+			}
+			return nil, err
+		}
+		bodyFetch = info
+		ds.BodyPath = ""
+		ds.SetBodyFile(bodyFile)
+
+		// record body provenance in dataset metadata
+		if ds.Meta == nil {
+			ds.Meta = &dataset.Meta{}
+		}
+		ds.Meta.DownloadURL = p.BodyURL
+		if err := ds.Meta.Set("bodyFetch", info.Map()); err != nil {
+			return nil, err
+		}
 	}
 
 	if !p.Force &&
 		!p.Apply &&
 		p.Drop == "" &&
+		bodyFetch == nil &&
 		ds.BodyPath == "" &&
 		ds.Body == nil &&
 		ds.BodyBytes == nil &&
@@ -1160,6 +2124,9 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 	// runState holds the results of transform application. will be non-nil if a
 	// transform is applied while saving
 	var runState *run.State
+	// runLog records events & output of transform application, persisted after
+	// the run completes. non-nil whenever runState is non-nil
+	var runLog *run.Log
 
 	// If applying a transform, execute its script before saving
 	if p.Apply {
@@ -1183,15 +2150,27 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 		// runState
 		runID := run.NewID()
 		runState = run.NewState(runID)
+		if scope.inst.sinks != nil && !p.DryRun {
+			scope.inst.sinks.TrackRun(runID, ref.Alias())
+		}
+		runLog = run.NewLog(runState, ref.InitID)
+		if runState.Provenance, err = run.NewProvenance(ds.Transform, secrets); err != nil {
+			return nil, fmt.Errorf("reading transform script: %w", err)
+		}
 		// create a loader so transforms can call `load_dataset`
 		// TODO(b5) - add a ResolverMode save parameter and call m.d.resolverForMode
 		// on the passed in mode string instead of just using the default resolver
 		// cmd can then define "remote" and "offline" flags, that set the ResolverMode
 		// string and control how transform functions
-		loader := scope.ParseResolveFunc()
+		loader := warnLifecycleLoader(scope, runID, ref, scope.ParseResolveFunc())
+		if isNew && p.DryRun {
+			// dry runs don't create new datasets, make sure the transform doesn't
+			// load another source for the name
+			loader = newDatasetLoader(ref, loader)
+		}
 
 		scope.Bus().SubscribeID(func(ctx context.Context, e event.Event) error {
-			runState.AddTransformEvent(e)
+			runLog.AddTransformEvent(e)
 			if e.Type == event.ETTransformPrint {
 				if msg, ok := e.Payload.(event.TransformMessage); ok {
 					if p.ScriptOutput != nil {
@@ -1206,9 +2185,18 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 		// apply the transform
 		shouldWait := true
 		transformer := transform.NewTransformer(scope.AppContext(), loader, scope.Bus())
+		transformer.SetHTTPCacheDir(scope.inst.httpCacheDir())
+		if fsiPath != "" {
+			transformer.SetModuleDir(fsiPath)
+		}
 		if err := transformer.Apply(scope.Context(), ds, runID, shouldWait, scriptOut, secrets); err != nil {
 			log.Errorw("transform run error", "err", err.Error())
+			if p.DryRun {
+				return nil, err
+			}
 			runState.Message = err.Error()
+			runLog.AddError(err)
+			putRunLog(scope, runLog)
 			if err := scope.Logbook().WriteTransformRun(scope.Context(), ref.InitID, runState); err != nil {
 				log.Debugw("writing errored transform run to logbook:", "err", err.Error())
 				return nil, err
@@ -1225,10 +2213,14 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 	}
 
 	fileHint := p.BodyPath
+	if p.BodyURL != "" {
+		fileHint = p.BodyURL
+	}
 	if len(p.FilePaths) > 0 {
 		fileHint = p.FilePaths[0]
 	}
 
+	settings := scope.inst.datasetSettings(ref.InitID)
 	switches := base.SaveSwitches{
 		FileHint:            fileHint,
 		Replace:             p.Replace,
@@ -1238,7 +2230,31 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 		ShouldRender:        p.ShouldRender,
 		NewName:             p.NewName,
 		Drop:                p.Drop,
+		Infer:               inferConfig(scope.Config(), p.InferSampler),
+		Binning:             binningConfig(scope.Config()),
+		Lint:                lintConfig(scope.Config(), settings.LintProfile),
+		BodyFormat:          settings.BodyFormat,
+
+		RequireCompatibleSchema: p.RequireCompatibleSchema,
+		AllowBreakingSchema:     p.BreakingChange,
+		RecordEnvironment:       p.RecordEnvironment || recordEnvironment(scope.Config()),
+		ExtractMeta:             p.ExtractMeta,
+		Canonical:               p.Canonical,
+	}
+
+	if p.DryRun {
+		// runs that only preview a version aren't recorded
+		ds.Commit.RunID = ""
+		preview, _, err := base.PreviewSave(scope.Context(), scope.Repo(), ref.Path, ds, switches)
+		if err != nil {
+			log.Debugw("save base.PreviewSave", "err", err)
+			return nil, err
+		}
+		preview.Peername = ref.Username
+		preview.Name = ref.Name
+		return preview, nil
 	}
+
 	savedDs, err := base.SaveDataset(scope.Context(), scope.Repo(), writeDest, ref.InitID, ref.Path, ds, runState, switches)
 	if err != nil {
 		// datasets that are unchanged & have a runState record a record of no-changes
@@ -1246,6 +2262,7 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 		if errors.Is(err, dsfs.ErrNoChanges) && runState != nil {
 			runState.Status = run.RSUnchanged
 			runState.Message = err.Error()
+			putRunLog(scope, runLog)
 			if err := scope.Logbook().WriteTransformRun(scope.Context(), ref.InitID, runState); err != nil {
 				log.Debugw("writing unchanged transform run to logbook:", "err", err.Error())
 				return nil, err
@@ -1259,6 +2276,32 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 	success = true
 	*res = *savedDs
 
+	if settings.Retention > 0 {
+		pruneRef := dsref.ConvertDatasetToVersionInfo(savedDs).SimpleRef()
+		pruneRef.InitID = ref.InitID
+		if pruned, err := base.PruneVersions(scope.Context(), scope.Repo(), pruneRef, settings.Retention); err != nil {
+			log.Debugw("save pruning versions", "ref", pruneRef.Alias(), "err", err)
+		} else if len(pruned) > 0 {
+			log.Debugw("save pruned versions", "ref", pruneRef.Alias(), "pruned", len(pruned))
+		}
+	}
+
+	if runLog != nil {
+		if runState.Provenance != nil {
+			runState.Provenance.Output = &run.DatasetVersion{
+				Ref:  fmt.Sprintf("%s/%s", savedDs.Peername, savedDs.Name),
+				Path: savedDs.Path,
+			}
+		}
+		putRunLog(scope, runLog)
+	}
+
+	if bodyFetch != nil {
+		if err := scope.BodyFetches().Put(ref.InitID, bodyFetch); err != nil {
+			return nil, fmt.Errorf("recording body fetch: %w", err)
+		}
+	}
+
 	// TODO (b5) - this should be integrated into base.SaveDataset
 	if fsiPath != "" {
 		vi := dsref.ConvertDatasetToVersionInfo(savedDs)
@@ -1277,8 +2320,186 @@ func (datasetImpl) Save(scope scope, p *SaveParams) (*dataset.Dataset, error) {
 	return res, nil
 }
 
+// PatchMeta applies a JSON merge patch to the meta component of many datasets
+func (datasetImpl) PatchMeta(scope scope, p *MetaPatchParams) (*MetaPatchReport, error) {
+	report := &MetaPatchReport{Results: make([]MetaPatchResult, 0, len(p.Refs))}
+	for _, refstr := range p.Refs {
+		res := MetaPatchResult{Ref: refstr}
+		ds, err := patchDatasetMeta(scope, refstr, p)
+		if errors.Is(err, dsfs.ErrNoChanges) {
+			res.Unchanged = true
+			report.Unchanged++
+		} else if err != nil {
+			log.Debugw("PatchMeta", "ref", refstr, "err", err)
+			res.Error = err.Error()
+			report.Failed++
+		} else {
+			res.Path = ds.Path
+			report.Saved++
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report, nil
+}
+
+func patchDatasetMeta(scope scope, refstr string, p *MetaPatchParams) (*dataset.Dataset, error) {
+	ctx := scope.Context()
+	ref, _, err := scope.ParseAndResolveRef(ctx, refstr, "local")
+	if err != nil {
+		return nil, err
+	}
+	if ref.Path == "" {
+		return nil, dsref.ErrNoHistory
+	}
+	ds, err := scope.Loader().LoadDataset(ctx, ref, "")
+	if err != nil {
+		return nil, err
+	}
+	patch := p.Patch
+	if p.Lang != "" {
+		patch = map[string]interface{}{
+			base.MetaLocalizedKey: map[string]interface{}{p.Lang: p.Patch},
+		}
+	}
+	md, err := base.PatchMeta(ds.Meta, patch)
+	if err != nil {
+		return nil, err
+	}
+	prevData, _ := json.Marshal(ds.Meta)
+	nextData, _ := json.Marshal(md)
+	if ds.Meta != nil && bytes.Equal(prevData, nextData) {
+		return nil, dsfs.ErrNoChanges
+	}
+	ds.Meta = md
+	// write the entire dataset as a new version, so fields removed by the patch
+	// aren't restored from the previous version
+	ds.Commit = nil
+	ds.Path = ""
+
+	title := p.Title
+	if title == "" {
+		title = "patch meta"
+	}
+	return scope.inst.Dataset().Save(ctx, &SaveParams{
+		Ref:     ref.Alias(),
+		Dataset: ds,
+		Title:   title,
+		Message: p.Message,
+		Replace: true,
+	})
+}
+
+// Lifecycle sets the lifecycle state of a dataset
+func (datasetImpl) Lifecycle(scope scope, p *LifecycleParams) (*dsref.VersionInfo, error) {
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+
+	lc := logbook.Lifecycle{
+		State:     p.State,
+		Reason:    p.Reason,
+		Successor: p.Successor,
+	}
+	if err := scope.Logbook().WriteDatasetLifecycle(scope.Context(), ref.InitID, lc); err != nil {
+		return nil, err
+	}
+
+	vi := dsref.NewVersionInfoFromRef(ref)
+	setLifecycle(&vi, lc)
+	return &vi, nil
+}
+
+// setLifecycle copies lifecycle state onto a VersionInfo. active datasets
+// leave lifecycle fields empty
+func setLifecycle(vi *dsref.VersionInfo, lc logbook.Lifecycle) {
+	if lc.State == logbook.LifecycleActive {
+		vi.Lifecycle, vi.LifecycleReason, vi.Successor = "", "", ""
+		return
+	}
+	vi.Lifecycle = lc.State
+	vi.LifecycleReason = lc.Reason
+	vi.Successor = lc.Successor
+}
+
+// fillLifecycles adds lifecycle state from the logbook to a list of infos
+func fillLifecycles(scope scope, infos []dsref.VersionInfo) {
+	book := scope.Logbook()
+	if book == nil {
+		return
+	}
+	for i, vi := range infos {
+		initID := vi.InitID
+		if initID == "" {
+			var err error
+			if initID, err = book.RefToInitID(vi.SimpleRef()); err != nil {
+				continue
+			}
+		}
+		lc, err := book.DatasetLifecycle(scope.Context(), initID)
+		if err != nil {
+			log.Debugw("reading dataset lifecycle", "ref", vi.Alias(), "err", err)
+			continue
+		}
+		setLifecycle(&infos[i], lc)
+	}
+}
+
+// warnLifecycleLoader wraps a transform dataset loader, publishing a warning
+// print event for runID whenever the transform loads a deprecated or archived
+// dataset. Loading the dataset the transform is writing to never warns
+func warnLifecycleLoader(scope scope, runID string, target dsref.Ref, load dsref.ParseResolveLoad) dsref.ParseResolveLoad {
+	return func(ctx context.Context, refStr string) (*dataset.Dataset, error) {
+		ds, err := load(ctx, refStr)
+		if err != nil || scope.Logbook() == nil {
+			return ds, err
+		}
+		if ds.Peername == target.Username && ds.Name == target.Name {
+			return ds, nil
+		}
+
+		ref := dsref.Ref{Username: ds.Peername, Name: ds.Name}
+		initID, err := scope.Logbook().RefToInitID(ref)
+		if err != nil {
+			return ds, nil
+		}
+		lc, err := scope.Logbook().DatasetLifecycle(ctx, initID)
+		if err != nil || lc.State == logbook.LifecycleActive {
+			return ds, nil
+		}
+
+		msg := fmt.Sprintf("warning: dataset %s is %s", ref.Alias(), lc.State)
+		if lc.Reason != "" {
+			msg += fmt.Sprintf(": %s", lc.Reason)
+		}
+		if lc.Successor != "" {
+			msg += fmt.Sprintf(". use %s instead", lc.Successor)
+		}
+		err = scope.Bus().PublishID(ctx, event.ETTransformPrint, runID, event.TransformMessage{
+			Lvl: event.TransformMsgLvlWarn,
+			Msg: msg,
+		})
+		if err != nil {
+			log.Debugw("publishing lifecycle warning", "err", err)
+		}
+		return ds, nil
+	}
+}
+
+// newDatasetLoader wraps a loader, reporting references to a dataset that
+// hasn't been created yet as not found
+func newDatasetLoader(target dsref.Ref, load dsref.ParseResolveLoad) dsref.ParseResolveLoad {
+	return func(ctx context.Context, refStr string) (*dataset.Dataset, error) {
+		if ref, err := dsref.Parse(refStr); err == nil && ref.Path == "" && ref.Name == target.Name &&
+			(ref.Username == "me" || ref.Username == target.Username) {
+			return nil, dsref.ErrRefNotFound
+		}
+		return load(ctx, refStr)
+	}
+}
+
 // Rename changes a user's given name for a dataset
-func (datasetImpl) Rename(scope scope, p *RenameParams) (*dsref.VersionInfo, error) {
+func (datasetImpl) Rename(scope scope, p *RenameParams) (*RenameResult, error) {
 	if p.Current == "" {
 		return nil, fmt.Errorf("current name is required to rename a dataset")
 	}
@@ -1309,13 +2530,18 @@ func (datasetImpl) Rename(scope scope, p *RenameParams) (*dsref.VersionInfo, err
 		return nil, err
 	}
 
+	res := &RenameResult{VersionInfo: *vi, Updated: []RenameUpdate{}}
+
 	// If the dataset is linked to a working directory, update the ref
 	if vi.FSIPath != "" {
 		if _, err = scope.FSISubsystem().ModifyLinkReference(vi.FSIPath, vi.SimpleRef()); err != nil {
 			return nil, err
 		}
+		res.Updated = append(res.Updated, RenameUpdate{Kind: RenameUpdateFSI, Ref: vi.Alias(), Path: vi.FSIPath})
 	}
-	return vi, nil
+
+	updateRenamedReferences(scope, ref, vi, res)
+	return res, nil
 }
 
 // Remove a dataset entirely or remove a certain number of revisions
@@ -1399,6 +2625,10 @@ func (datasetImpl) Remove(scope scope, p *RemoveParams) (*RemoveResponse, error)
 		history = []dsref.VersionInfo{}
 	}
 
+	if err := checkRemoveProtection(scope, ref, p.Revision.Gen == dsref.AllGenerations); err != nil {
+		return nil, err
+	}
+
 	if p.Revision.Gen == dsref.AllGenerations {
 		// removing all revisions of a dataset must unlink it
 		if fsiPath != "" {
@@ -1409,7 +2639,15 @@ func (datasetImpl) Remove(scope scope, p *RemoveParams) (*RemoveResponse, error)
 			}
 		}
 
-		didRemove, _ := base.RemoveEntireDataset(scope.Context(), scope.Repo(), ref, history)
+		var didRemove string
+		if !p.Purge {
+			if didRemove, res.Trashed, err = trashDataset(scope, ref); err != nil {
+				return nil, err
+			}
+		}
+		if !res.Trashed {
+			didRemove, _ = base.RemoveEntireDataset(scope.Context(), scope.Repo(), ref, history)
+		}
 		res.NumDeleted = dsref.AllGenerations
 		res.Message = didRemove
 
@@ -1488,6 +2726,254 @@ func (datasetImpl) Remove(scope scope, p *RemoveParams) (*RemoveResponse, error)
 
 }
 
+// RemoveMany removes all versions of many datasets
+func (datasetImpl) RemoveMany(scope scope, p *RemoveManyParams) (*RemoveManyReport, error) {
+	ctx := scope.Context()
+	refs, err := matchDatasetRefs(scope, p.Refs)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("%w: no datasets match %s", dsref.ErrRefNotFound, strings.Join(p.Refs, ", "))
+	}
+
+	report := &RemoveManyReport{Datasets: make([]RemoveManyItem, 0, len(refs))}
+	for _, ref := range refs {
+		item := RemoveManyItem{Ref: ref.Alias()}
+		if fsi.IsFSIPath(ref.Path) {
+			item.FSIPath = fsi.FilesystemPathToLocal(ref.Path)
+			if !(p.KeepFiles || p.Force) {
+				if err := scope.FSISubsystem().IsWorkingDirectoryClean(ctx, item.FSIPath); err == fsi.ErrWorkingDirectoryDirty {
+					return nil, fmt.Errorf("%s: %w", item.Ref, ErrCantRemoveDirectoryDirty)
+				}
+			}
+		}
+
+		history, err := base.DatasetLog(ctx, scope.Repo(), ref, -1, 0, false)
+		if err != nil && err != repo.ErrNoHistory && !errors.Is(err, dsref.ErrPathRequired) {
+			return nil, fmt.Errorf("%s: %w", item.Ref, err)
+		}
+		item.Versions = len(history)
+		for _, vi := range history {
+			item.PinnedBytes += versionSize(scope, vi)
+		}
+
+		report.Versions += item.Versions
+		report.PinnedBytes += item.PinnedBytes
+		report.Datasets = append(report.Datasets, item)
+	}
+
+	if p.DryRun {
+		return report, nil
+	}
+
+	for i, item := range report.Datasets {
+		res, err := datasetImpl{}.Remove(scope, &RemoveParams{
+			Ref:       item.Ref,
+			Revision:  dsref.NewAllRevisions(),
+			KeepFiles: p.KeepFiles,
+			Force:     p.Force,
+			Purge:     p.Purge,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("removing %s after removing %d other datasets: %w", item.Ref, i, err)
+		}
+		report.Datasets[i].Unlinked = res.Unlinked
+	}
+	report.Removed = true
+	return report, nil
+}
+
+// Trash lists removed datasets kept in the trash
+func (datasetImpl) Trash(scope scope, p *TrashParams) ([]TrashedDataset, error) {
+	return scope.inst.trash.List()
+}
+
+// RestoreFromTrash brings back a dataset from the trash
+func (datasetImpl) RestoreFromTrash(scope scope, p *RestoreFromTrashParams) (*dsref.VersionInfo, error) {
+	td, err := scope.inst.trash.Find(expandTrashRef(scope, p.Ref))
+	if err != nil {
+		return nil, err
+	}
+	return restoreTrashed(scope, td)
+}
+
+// EmptyTrash purges stored versions of datasets in the trash
+func (datasetImpl) EmptyTrash(scope scope, p *EmptyTrashParams) ([]TrashedDataset, error) {
+	ctx := scope.Context()
+	if p.Ref != "" {
+		td, err := scope.inst.trash.Find(expandTrashRef(scope, p.Ref))
+		if err != nil {
+			return nil, err
+		}
+		if p.Expired && !td.Expired(time.Now()) {
+			return []TrashedDataset{}, nil
+		}
+		return scope.inst.purgeTrash(ctx, scope.Repo(), []TrashedDataset{*td})
+	}
+	if p.Expired {
+		return scope.inst.purgeExpiredTrash(ctx, scope.Repo())
+	}
+	list, err := scope.inst.trash.List()
+	if err != nil {
+		return nil, err
+	}
+	return scope.inst.purgeTrash(ctx, scope.Repo(), list)
+}
+
+// Squash collapses the latest versions of a dataset into a single version
+func (datasetImpl) Squash(scope scope, p *SquashParams) (*SquashResult, error) {
+	ctx := scope.Context()
+	ref, _, err := scope.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	// a missing refstore entry only means there's no working directory to keep
+	vi, _ := repo.GetVersionInfoShim(scope.Repo(), ref)
+
+	squashed, err := base.SquashVersions(ctx, scope.Repo(), scope.Filesystem().DefaultWriteFS(), ref, base.SquashParams{
+		Count:   p.Count,
+		Title:   p.Title,
+		Message: p.Message,
+		Unpin:   p.Unpin,
+		Force:   p.Force,
+	})
+	if err != nil {
+		log.Debugw("squash", "ref", ref.Alias(), "err", err)
+		return nil, err
+	}
+
+	// the squashed version has the same contents as the previous head, linked
+	// working directories stay in sync
+	if vi != nil && vi.FSIPath != "" {
+		next := dsref.ConvertDatasetToVersionInfo(squashed.Dataset)
+		next.FSIPath = vi.FSIPath
+		if err := repo.PutVersionInfoShim(ctx, scope.Repo(), &next); err != nil {
+			return nil, err
+		}
+	}
+
+	ds := squashed.Dataset
+	return &SquashResult{
+		Ref:          dsref.ConvertDatasetToVersionInfo(ds).SimpleRef().String(),
+		Path:         ds.Path,
+		PreviousPath: ds.PreviousPath,
+		Squashed:     squashed.Squashed,
+		Unpinned:     squashed.Unpinned,
+	}, nil
+}
+
+// matchDatasetRefs resolves a list of references & patterns to the local
+// datasets they identify. Patterns are matched against dataset aliases, with
+// "me" standing in for the username of the repo owner
+func matchDatasetRefs(scope scope, patterns []string) ([]dsref.Ref, error) {
+	ctx := scope.Context()
+	var (
+		res  []dsref.Ref
+		seen = map[string]bool{}
+		add  = func(ref dsref.Ref) {
+			if !seen[ref.Alias()] {
+				seen[ref.Alias()] = true
+				res = append(res, ref)
+			}
+		}
+		all []reporef.DatasetRef
+	)
+
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[") {
+			ref, _, err := scope.ParseAndResolveRefWithWorkingDir(ctx, pattern, "local")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", pattern, err)
+			}
+			add(ref)
+			continue
+		}
+
+		if all == nil {
+			num, err := scope.Repo().RefCount()
+			if err != nil {
+				return nil, err
+			}
+			if all, err = scope.Repo().References(0, num); err != nil {
+				return nil, err
+			}
+		}
+		if strings.HasPrefix(pattern, "me/") {
+			pattern = scope.ActiveProfile().Peername + strings.TrimPrefix(pattern, "me")
+		}
+		for _, r := range all {
+			if ok, _ := path.Match(pattern, r.AliasString()); !ok {
+				continue
+			}
+			ref, _, err := scope.ParseAndResolveRefWithWorkingDir(ctx, r.AliasString(), "local")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", r.AliasString(), err)
+			}
+			add(ref)
+		}
+	}
+	return res, nil
+}
+
+// versionSize returns the total size of a dataset version, falling back to
+// body size when the version DAG can't be inspected
+func versionSize(scope scope, vi dsref.VersionInfo) uint64 {
+	if node := scope.Node(); node != nil {
+		if info, err := node.NewDAGInfo(scope.Context(), vi.Path, ""); err == nil && len(info.Sizes) > 0 {
+			return info.Sizes[0]
+		}
+	}
+	return uint64(vi.BodySize)
+}
+
+// SyncStatus lists warehouse sync statuses
+func (datasetImpl) SyncStatus(scope scope, p *SyncStatusParams) ([]warehouse.Status, error) {
+	if scope.inst.warehouses == nil {
+		return []warehouse.Status{}, nil
+	}
+	alias := ""
+	if p.Ref != "" {
+		ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+		if err != nil {
+			return nil, err
+		}
+		alias = ref.Alias()
+	}
+	return scope.inst.warehouses.Statuses(alias), nil
+}
+
+// Pin pins a dataset version to configured pinning services
+func (datasetImpl) Pin(scope scope, p *PinParams) ([]pinning.Status, error) {
+	if scope.inst.pins == nil {
+		return nil, fmt.Errorf("no pinning services configured")
+	}
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	return scope.inst.pinPublished(scope.Context(), ref), nil
+}
+
+// PinStatus lists pin statuses
+func (datasetImpl) PinStatus(scope scope, p *PinStatusParams) ([]pinning.Status, error) {
+	if scope.inst.pins == nil {
+		return []pinning.Status{}, nil
+	}
+	alias := ""
+	if p.Ref != "" {
+		ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+		if err != nil {
+			return nil, err
+		}
+		alias = ref.Alias()
+	}
+	if p.Refresh {
+		return scope.inst.pins.Refresh(scope.Context(), alias), nil
+	}
+	return scope.inst.pins.Statuses(alias), nil
+}
+
 // Pull downloads and stores an existing dataset to a peer's repository via
 // a network connection
 func (datasetImpl) Pull(scope scope, p *PullParams) (*dataset.Dataset, error) {
@@ -1506,7 +2992,15 @@ func (datasetImpl) Pull(scope scope, p *PullParams) (*dataset.Dataset, error) {
 	}
 	log.Infof("pulling dataset from location: %s", location)
 
-	ds, err := scope.RemoteClient().PullDataset(scope.Context(), &ref, location)
+	// pulling is an intentional network fetch, don't time out opening files
+	ctx := dsfs.WithOpenTimeout(scope.Context(), dsfs.NoTimeout)
+	if p.MetaOnly {
+		if p.LinkDir != "" {
+			return nil, fmt.Errorf("can't link a dataset pulled without body data")
+		}
+		return pullHead(ctx, scope, ref, location)
+	}
+	ds, err := scope.RemoteClient().PullDataset(ctx, &ref, location)
 	if err != nil {
 		log.Debugf("pulling dataset: %s", err)
 		return nil, err
@@ -1514,6 +3008,12 @@ func (datasetImpl) Pull(scope scope, p *PullParams) (*dataset.Dataset, error) {
 
 	*res = *ds
 
+	if p.Depth != 0 || p.Deepen > 0 {
+		if err := pullShallow(ctx, scope, ref, location, p.Depth, p.Deepen); err != nil {
+			return nil, err
+		}
+	}
+
 	if p.LinkDir != "" {
 		checkoutp := &LinkParams{
 			Refstr: ref.Human(),
@@ -1532,6 +3032,139 @@ func (datasetImpl) Pull(scope scope, p *PullParams) (*dataset.Dataset, error) {
 	return res, nil
 }
 
+// pullShallow pulls data for the latest depth versions of a dataset, or with
+// deepen, for deepen more versions than are stored locally. Depths less than
+// one pull every version. The dataset is marked shallow while older versions
+// are left without data
+func pullShallow(ctx context.Context, scope scope, ref dsref.Ref, location string, depth, deepen int) error {
+	if deepen > 0 {
+		items, err := base.DatasetLog(ctx, scope.Repo(), dsref.Ref{Username: ref.Username, Name: ref.Name}, -1, 0, false)
+		if err != nil {
+			return err
+		}
+		// deepen from the contiguous run of local versions starting at head
+		for _, item := range items {
+			if item.Path == "" {
+				continue
+			}
+			if item.Foreign {
+				break
+			}
+			depth++
+		}
+		depth += deepen
+	}
+
+	pulled, total, err := pullVersions(ctx, scope, ref, location, depth)
+	if err != nil {
+		return err
+	}
+	if scope.inst.dsSettings == nil || ref.InitID == "" {
+		return nil
+	}
+	settings := scope.inst.datasetSettings(ref.InitID)
+	settings.Shallow = 0
+	if pulled < total {
+		settings.Shallow = pulled
+	}
+	return scope.inst.dsSettings.Put(ref.InitID, settings)
+}
+
+// pullVersions pulls data for versions of a dataset that aren't stored
+// locally, starting at the latest version. depth limits the number of
+// versions pulled, values less than one pull every version. pullVersions
+// returns the number of versions within depth & the total number of versions
+// in history
+func pullVersions(ctx context.Context, scope scope, ref dsref.Ref, location string, depth int) (pulled, total int, err error) {
+	items, err := base.DatasetLog(ctx, scope.Repo(), dsref.Ref{Username: ref.Username, Name: ref.Name}, -1, 0, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, item := range items {
+		// skip transform runs that didn't create a version
+		if item.Path == "" {
+			continue
+		}
+		total++
+		if depth > 0 && total > depth {
+			continue
+		}
+		if item.Foreign {
+			vref := item.SimpleRef()
+			vref.InitID = ref.InitID
+			vref.ProfileID = ref.ProfileID
+			if _, err := scope.RemoteClient().PullDataset(ctx, &vref, location); err != nil {
+				return pulled, total, fmt.Errorf("pulling version %s: %w", item.Path, err)
+			}
+		}
+		pulled++
+	}
+	return pulled, total, nil
+}
+
+// Clone pulls a dataset, checks it out, and follows it
+func (datasetImpl) Clone(scope scope, p *CloneParams) (*CloneResult, error) {
+	source := p.Remote
+	if source == "" {
+		source = "network"
+	}
+	ref, location, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, source)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := dsfs.WithOpenTimeout(scope.Context(), dsfs.NoTimeout)
+	ds, err := scope.RemoteClient().PullDataset(ctx, &ref, location)
+	if err != nil {
+		return nil, err
+	}
+	res := &CloneResult{Dataset: ds, Versions: 1}
+
+	if p.Depth != 1 {
+		if res.Versions, _, err = pullVersions(ctx, scope, ref, location, p.Depth); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Dir != "" {
+		if err := scope.inst.Filesys().Checkout(scope.Context(), &LinkParams{Refstr: ref.Human(), Dir: p.Dir}); err != nil {
+			return nil, err
+		}
+		res.Dir = p.Dir
+	}
+
+	if p.Follow {
+		f := &Follow{Ref: ref.Alias(), Remote: p.Remote, Since: time.Now(), LastPath: ds.Path}
+		if err := scope.inst.follows.Put(f); err != nil {
+			return nil, err
+		}
+		res.Following = true
+	}
+	return res, nil
+}
+
+// Follow adds or removes a followed dataset
+func (datasetImpl) Follow(scope scope, p *FollowParams) ([]Follow, error) {
+	if !p.List {
+		if p.Ref == "" {
+			return nil, fmt.Errorf("dataset reference is required")
+		}
+		ref, err := dsref.Parse(p.Ref)
+		if err != nil {
+			return nil, err
+		}
+		if p.Unfollow {
+			err = scope.inst.follows.Remove(ref.Alias())
+		} else {
+			err = scope.inst.follows.Put(&Follow{Ref: ref.Alias(), Remote: p.Remote, Since: time.Now()})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return scope.inst.follows.List()
+}
+
 // Validate gives a dataset of errors and issues for a given dataset
 func (datasetImpl) Validate(scope scope, p *ValidateParams) (*ValidateResponse, error) {
 	res := &ValidateResponse{}
@@ -1705,6 +3338,26 @@ func (datasetImpl) DAGInfo(scope scope, p *DAGInfoParams) (*dag.Info, error) {
 	return res, nil
 }
 
+// Lint checks a dataset against lint rules
+func (datasetImpl) Lint(scope scope, p *LintParams) (*lint.Report, error) {
+	ref, source, err := scope.ParseAndResolveRefWithWorkingDir(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	ds, err := scope.LoadDataset(scope.Context(), ref, source)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := lintConfig(scope.Config(), scope.inst.datasetSettings(ref.InitID).LintProfile)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	report := lint.Lint(ds, cfg)
+	report.Ref = ref.Alias()
+	return report, nil
+}
+
 // Stats generates stats for a dataset
 func (datasetImpl) Stats(scope scope, p *StatsParams) (*dataset.Stats, error) {
 	if p.Refstr == "" && p.Dataset == nil {
@@ -1725,3 +3378,108 @@ func (datasetImpl) Stats(scope scope, p *StatsParams) (*dataset.Stats, error) {
 
 	return scope.Stats().Stats(scope.Context(), ds)
 }
+
+// StatsHistory extracts summary stats from each version of a dataset. Stats
+// are cached per version, so only versions without cached stats read a body
+func (datasetImpl) StatsHistory(scope scope, p *StatsHistoryParams) (*StatsHistory, error) {
+	ctx := scope.Context()
+	ref, _, err := scope.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+
+	// in-process calls skip SetNonZeroDefaults
+	limit := p.Limit
+	if limit == 0 {
+		limit = defaultStatsHistoryLimit
+	}
+	items, err := base.DatasetLog(ctx, scope.Repo(), ref, limit, p.Offset, false)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &StatsHistory{
+		Ref:      ref.Alias(),
+		Versions: make([]StatsHistoryVersion, 0, len(items)),
+	}
+	for _, item := range items {
+		v := StatsHistoryVersion{
+			Path:        item.Path,
+			CommitTime:  item.CommitTime,
+			CommitTitle: item.CommitTitle,
+		}
+		if err := statsHistoryVersion(scope, ref, &v, p.Columns); err != nil {
+			v.Error = err.Error()
+		}
+		res.Versions = append(res.Versions, v)
+	}
+	return res, nil
+}
+
+// statsHistoryVersion fills in summary stats for a version
+func statsHistoryVersion(scope scope, ref dsref.Ref, v *StatsHistoryVersion, columns []string) error {
+	ctx := scope.Context()
+	if has, err := scope.Filesystem().Has(ctx, v.Path); err != nil || !has {
+		return fmt.Errorf("version isn't stored locally")
+	}
+	ref.Path = v.Path
+	ds, err := scope.LoadDataset(ctx, ref, "")
+	if err != nil {
+		return err
+	}
+	if ds.Structure == nil {
+		return fmt.Errorf("version has no structure")
+	}
+	v.Rows = ds.Structure.Entries
+
+	cols, _, err := tabular.ColumnsFromJSONSchema(ds.Structure.Schema)
+	if err != nil {
+		return err
+	}
+	sa, err := scope.Stats().Stats(ctx, ds)
+	if err != nil {
+		return err
+	}
+	// stats are a list of objects that parallels the body columns. round trip
+	// through JSON to read fields regardless of how they were calculated
+	data, err := json.Marshal(sa.Stats)
+	if err != nil {
+		return err
+	}
+	colStats := []struct {
+		Type  string   `json:"type"`
+		Count int      `json:"count"`
+		Min   *float64 `json:"min"`
+		Max   *float64 `json:"max"`
+	}{}
+	if err := json.Unmarshal(data, &colStats); err != nil {
+		return fmt.Errorf("stats aren't tabular: %w", err)
+	}
+
+	include := map[string]bool{}
+	for _, c := range columns {
+		include[c] = true
+	}
+	for i, col := range cols {
+		if i >= len(colStats) {
+			break
+		}
+		if len(include) > 0 && !include[col.Title] {
+			continue
+		}
+		cs := colStats[i]
+		c := StatsHistoryColumn{
+			Name:  col.Title,
+			Type:  cs.Type,
+			Count: cs.Count,
+		}
+		if v.Rows > 0 && cs.Count < v.Rows {
+			c.NullPercent = float64(v.Rows-cs.Count) / float64(v.Rows) * 100
+		}
+		if cs.Type == "numeric" {
+			c.Min, c.Max = cs.Min, cs.Max
+		}
+		v.Columns = append(v.Columns, c)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -13,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -167,6 +169,255 @@ func TestDatasetRequestsForceSave(t *testing.T) {
 	}
 }
 
+func TestDatasetRequestsSaveBodyURL(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	body := "city,pop\ntoronto,40000000\nnew york,8500000\n"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"%d"`, len(body))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(body))
+	}))
+	defer s.Close()
+
+	node := newTestQriNode(t)
+	inst := NewInstanceFromConfigAndNode(ctx, testcfg.DefaultConfigForTesting(), node)
+
+	bodyURL := s.URL + "/cities.csv"
+	res, err := inst.Dataset().Save(ctx, &SaveParams{Ref: "me/fetched_cities", BodyURL: bodyURL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Meta == nil || res.Meta.DownloadURL != bodyURL {
+		t.Errorf("expected meta download url to be recorded as %q", bodyURL)
+	}
+
+	if _, err = inst.Dataset().Save(ctx, &SaveParams{Ref: "me/fetched_cities", BodyURL: bodyURL}); !errors.Is(err, dsfs.ErrNoChanges) {
+		t.Errorf("expected unchanged body url save to return ErrNoChanges, got: %v", err)
+	}
+
+	body = "city,pop\ntoronto,40000000\nnew york,8500000\nchicago,300000\n"
+	if _, err = inst.Dataset().Save(ctx, &SaveParams{Ref: "me/fetched_cities", BodyURL: bodyURL}); err != nil {
+		t.Errorf("expected changed body url to save. got: %s", err)
+	}
+
+	if _, err = inst.Dataset().Save(ctx, &SaveParams{Ref: "me/fetched_cities", BodyURL: bodyURL, BodyPath: "body.csv"}); err == nil {
+		t.Error("expected providing both body url and body path to error")
+	}
+
+	// servers without validators are fetched in full, an unchanged body
+	// mustn't create a new version
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer plain.Close()
+	plainURL := plain.URL + "/cities.csv"
+	if _, err = inst.Dataset().Save(ctx, &SaveParams{Ref: "me/plain_cities", BodyURL: plainURL}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = inst.Dataset().Save(ctx, &SaveParams{Ref: "me/plain_cities", BodyURL: plainURL}); !errors.Is(err, dsfs.ErrNoChanges) {
+		t.Errorf("expected refetching an unchanged body to return ErrNoChanges, got: %v", err)
+	}
+}
+
+func TestDatasetRequestsRemoveMany(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(mr, testcfg.DefaultP2PForTesting(), event.NilBus, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	inst := NewInstanceFromConfigAndNode(ctx, testcfg.DefaultConfigForTesting(), node)
+
+	if _, err := inst.Dataset().RemoveMany(ctx, &RemoveManyParams{Refs: []string{"me/nope_*"}}); !errors.Is(err, dsref.ErrRefNotFound) {
+		t.Errorf("expected unmatched pattern to return ErrRefNotFound, got: %v", err)
+	}
+	if _, err := inst.Dataset().RemoveMany(ctx, &RemoveManyParams{Refs: []string{"me/[c"}}); err == nil {
+		t.Errorf("expected malformed pattern to error")
+	}
+
+	p := &RemoveManyParams{Refs: []string{"me/c*", "peer/cities", "peer/movies"}, DryRun: true}
+	report, err := inst.Dataset().RemoveMany(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, item := range report.Datasets {
+		got = append(got, item.Ref)
+	}
+	sort.Strings(got)
+	expect := []string{"peer/cities", "peer/counter", "peer/craigslist", "peer/movies"}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("matched datasets mismatch (-want +got):\n%s", diff)
+	}
+	if report.Removed {
+		t.Errorf("expected dry run not to remove datasets")
+	}
+	if report.Versions < len(expect) || report.PinnedBytes == 0 {
+		t.Errorf("expected report to count versions & bytes, got %d versions, %d bytes", report.Versions, report.PinnedBytes)
+	}
+
+	refs, err := inst.Dataset().List(ctx, &ListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 5 {
+		t.Fatalf("expected dry run to leave 5 datasets, got %d", len(refs))
+	}
+
+	p.DryRun = false
+	if report, err = inst.Dataset().RemoveMany(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if !report.Removed {
+		t.Errorf("expected report to mark datasets removed")
+	}
+	if refs, err = inst.Dataset().List(ctx, &ListParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0].Name != "sitemap" {
+		t.Errorf("expected only sitemap to remain, got %v", refs)
+	}
+}
+
+func TestDatasetRequestsPatchMeta(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	node := newTestQriNode(t)
+	ref := addCitiesDataset(t, node)
+	inst := NewInstanceFromConfigAndNode(ctx, testcfg.DefaultConfigForTesting(), node)
+
+	p := &MetaPatchParams{
+		Refs:  []string{ref.Alias(), "me/not_a_dataset"},
+		Patch: map[string]interface{}{"keywords": []interface{}{"cities", "population"}},
+	}
+	res, err := inst.Dataset().PatchMeta(ctx, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Saved != 1 || res.Failed != 1 || res.Unchanged != 0 {
+		t.Errorf("report count mismatch. want 1 saved, 1 failed, 0 unchanged. got: %d saved, %d failed, %d unchanged", res.Saved, res.Failed, res.Unchanged)
+	}
+
+	ds, err := inst.Dataset().Get(ctx, &GetParams{Refstr: ref.Alias()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ds.Dataset
+	if diff := cmp.Diff([]string{"cities", "population"}, got.Meta.Keywords); diff != "" {
+		t.Errorf("keywords mismatch (-want +got):\n%s", diff)
+	}
+	if got.Commit.Title != "patch meta" {
+		t.Errorf("commit title mismatch. want %q, got %q", "patch meta", got.Commit.Title)
+	}
+
+	// applying the same patch again should produce no changes
+	p.Refs = []string{ref.Alias()}
+	if res, err = inst.Dataset().PatchMeta(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if res.Unchanged != 1 {
+		t.Errorf("expected re-applying patch to report 1 unchanged dataset, got: %d", res.Unchanged)
+	}
+
+	if _, err := inst.Dataset().PatchMeta(ctx, &MetaPatchParams{Refs: []string{ref.Alias()}}); err == nil {
+		t.Error("expected empty patch to error")
+	}
+
+	// localized patches set language variants, shown when getting by language
+	p = &MetaPatchParams{
+		Refs:  []string{ref.Alias()},
+		Patch: map[string]interface{}{"title": "ciudades"},
+		Lang:  "es",
+	}
+	if res, err = inst.Dataset().PatchMeta(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if res.Saved != 1 {
+		t.Errorf("expected localized patch to save 1 dataset, got: %d", res.Saved)
+	}
+	if ds, err = inst.Dataset().Get(ctx, &GetParams{Refstr: ref.Alias(), Lang: "es-MX,en;q=0.5"}); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Dataset.Meta.Title != "ciudades" {
+		t.Errorf("localized title mismatch. want %q, got %q", "ciudades", ds.Dataset.Meta.Title)
+	}
+
+	p.Patch = map[string]interface{}{"keywords": []interface{}{"ciudades"}}
+	if _, err := inst.Dataset().PatchMeta(ctx, p); err == nil {
+		t.Error("expected localized patch of a non-localizable field to error")
+	}
+}
+
+func TestDatasetRequestsLifecycle(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	if _, err := tr.SaveWithParams(&SaveParams{
+		Ref:      "me/cities",
+		BodyPath: "testdata/cities_2/body.csv",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tr.Instance.Dataset().Lifecycle(tr.Ctx, &LifecycleParams{Ref: "me/cities", State: "retired"}); err == nil {
+		t.Error("expected invalid lifecycle state to error")
+	}
+	if _, err := tr.Instance.Dataset().Lifecycle(tr.Ctx, &LifecycleParams{Ref: "me/cities", State: "active", Successor: "me/new_cities"}); err == nil {
+		t.Error("expected active state with a successor to error")
+	}
+
+	vi, err := tr.Instance.Dataset().Lifecycle(tr.Ctx, &LifecycleParams{
+		Ref:       "me/cities",
+		State:     "deprecated",
+		Reason:    "use the new one",
+		Successor: "me/new_cities",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vi.Lifecycle != "deprecated" || vi.Successor != "me/new_cities" {
+		t.Errorf("unexpected lifecycle result: %#v", vi)
+	}
+
+	infos, err := tr.Instance.Dataset().List(tr.Ctx, &ListParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 {
+		t.Fatalf("expected 1 dataset, got %d", len(infos))
+	}
+	if infos[0].Lifecycle != "deprecated" || infos[0].LifecycleReason != "use the new one" {
+		t.Errorf("expected list to include lifecycle state, got: %#v", infos[0])
+	}
+
+	// transforms that load a deprecated dataset print a warning
+	scriptOut := &bytes.Buffer{}
+	if _, err := tr.SaveWithParams(&SaveParams{
+		Ref:          "me/hello",
+		FilePaths:    []string{"testdata/tf/load_cities.star"},
+		Apply:        true,
+		ScriptOutput: scriptOut,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expect := "is deprecated: use the new one. use me/new_cities instead"
+	if !strings.Contains(scriptOut.String(), expect) {
+		t.Errorf("expected script output to contain %q, got: %q", expect, scriptOut.String())
+	}
+}
+
 func TestDatasetRequestsSaveZip(t *testing.T) {
 	ctx, done := context.WithCancel(context.Background())
 	defer done()
@@ -278,6 +529,58 @@ func TestDatasetRequestsSaveApply(t *testing.T) {
 	}
 }
 
+func TestDatasetRequestsSaveDryRun(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+
+	_, err := run.SaveWithParams(&SaveParams{
+		Ref:      "me/dry_run_ds",
+		BodyPath: "testdata/cities_2/body.csv",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := run.MustGet(t, "me/dry_run_ds")
+
+	res, err := run.Instance.Dataset().Save(run.Ctx, &SaveParams{
+		Ref:       "me/dry_run_ds",
+		FilePaths: []string{"testdata/cities_2/add_city.star"},
+		Apply:     true,
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Path != "" {
+		t.Errorf("expected dry run result to have no path, got %q", res.Path)
+	}
+	if res.PreviousPath != head.Path {
+		t.Errorf("previous path mismatch. want: %q got: %q", head.Path, res.PreviousPath)
+	}
+	if res.Commit == nil || res.Commit.Title == "" {
+		t.Error("expected dry run to generate a commit title")
+	}
+
+	after := run.MustGet(t, "me/dry_run_ds")
+	if after.Path != head.Path {
+		t.Errorf("expected dry run not to create a version. want head %q got %q", head.Path, after.Path)
+	}
+
+	// dry runs for a new dataset don't leave a log behind
+	_, err = run.Instance.Dataset().Save(run.Ctx, &SaveParams{
+		Ref:       "me/dry_run_new",
+		FilePaths: []string{"testdata/tf/transform.star"},
+		Apply:     true,
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run.Instance.WithSource("local").Dataset().Get(run.Ctx, &GetParams{Refstr: "me/dry_run_new"}); err == nil {
+		t.Error("expected dry run of a new dataset not to create it")
+	}
+}
+
 func TestDatasetRequestsList(t *testing.T) {
 	ctx, done := context.WithCancel(context.Background())
 	defer done()
@@ -544,6 +847,10 @@ func TestDatasetRequestsGet(t *testing.T) {
 			&GetParams{Refstr: "peer/movies", Selector: "body", Format: "json",
 				FormatConfig: prettyJSONConfig, Limit: 3, Offset: 0, All: false},
 			bodyToPrettyString(moviesBody[:3])},
+
+		{"sql format of a component other than body",
+			&GetParams{Refstr: "peer/movies", Selector: "commit", Format: "sql"},
+			"sql format can only export the dataset body"},
 	}
 
 	for _, c := range cases {
@@ -564,6 +871,97 @@ func TestDatasetRequestsGet(t *testing.T) {
 	}
 }
 
+func TestDatasetRequestsGetQuery(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(mr, testcfg.DefaultP2PForTesting(), event.NilBus, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	inst := NewInstanceFromConfigAndNode(ctx, testcfg.DefaultConfigForTesting(), node)
+
+	ref, err := mr.GetRef(reporef.DatasetRef{Peername: "peer", Name: "movies"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	moviesDs, err := dsfs.LoadDataset(ctx, mr.Filesystem(), ref.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moviesDs.OpenBodyFile(ctx, node.Repo.Filesystem())
+	reader, err := dsio.NewCSVReader(moviesDs.Structure, moviesDs.BodyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := mustBeArray(base.ReadEntries(reader))
+	// csv values may be read as strings, convert durations to compare them
+	durations := make([]int64, len(rows))
+	titles := make([]interface{}, len(rows))
+	for i, row := range rows {
+		vals, ok := row.([]interface{})
+		if !ok || len(vals) < 2 {
+			t.Fatalf("expected row %d to be an array of two values, got: %#v", i, row)
+		}
+		titles[i] = vals[0]
+		str := strings.TrimSpace(fmt.Sprint(vals[1]))
+		if str == "" {
+			// some movies have no listed duration
+			durations[i] = -1
+			continue
+		}
+		d, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			t.Fatalf("row %d duration: %s", i, err)
+		}
+		durations[i] = d
+	}
+	long := []interface{}{}
+	for i, d := range durations {
+		if d > 150 {
+			long = append(long, titles[i])
+		}
+	}
+
+	cases := []struct {
+		description string
+		selector    string
+		expect      string
+	}{
+		{"filtered body", "body[?duration > 150].title", bodyToString(long)},
+		{"tabular rows as objects", "body[0:1]", bodyToString([]interface{}{map[string]interface{}{"title": titles[0], "duration": durations[0]}})},
+		{"component index", "structure.schema.items.items[-1].title", `"duration"`},
+		{"invalid query", "body[?duration >]", `invalid query "body[?duration >]" at position 16: expected an operand`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			p := &GetParams{Refstr: "peer/movies", Selector: c.selector, Format: "json", FormatConfig: &dataset.JSONOptions{Options: map[string]interface{}{"pretty": false}}}
+			got, err := inst.Dataset().Get(ctx, p)
+			if err != nil {
+				if err.Error() != c.expect {
+					t.Errorf("error mismatch: expected: %s, got: %s", c.expect, err)
+				}
+				return
+			}
+			if string(got.Bytes) != c.expect {
+				t.Errorf("result mismatch expected:\n%q, got:\n%q", c.expect, string(got.Bytes))
+			}
+		})
+	}
+
+	prevLimit := QueryMaxResultSize
+	QueryMaxResultSize = 10
+	defer func() { QueryMaxResultSize = prevLimit }()
+	if _, err := inst.Dataset().Get(ctx, &GetParams{Refstr: "peer/movies", Selector: "body[*]", Format: "json"}); !errors.Is(err, base.ErrQueryTooLarge) {
+		t.Errorf("expected result over the size limit to fail with ErrQueryTooLarge, got: %v", err)
+	}
+}
+
 func TestDatasetRequestsGetFSIPath(t *testing.T) {
 	ctx, done := context.WithCancel(context.Background())
 	defer done()
@@ -824,6 +1222,50 @@ func TestRenameNoHistory(t *testing.T) {
 	}
 }
 
+func TestRenameUpdatesDependents(t *testing.T) {
+	ctx := context.Background()
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	username := tr.MustOwner(t).Peername
+	for _, name := range []string{"rename_source", "rename_dependent"} {
+		workDir := tr.CreateAndChdirToWorkDir(name)
+		if _, err := tr.Instance.Filesys().Init(ctx, &InitDatasetParams{TargetDir: workDir, Name: name, Format: "csv"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script := `load_dataset("me/rename_source")
+load_dataset('%s/rename_source@/ipfs/QmFoo')
+load_dataset("me/rename_source_two")
+`
+	tfPath := filepath.Join(tr.WorkDir, "transform.star")
+	tr.MustWriteFile(t, tfPath, fmt.Sprintf(script, username))
+
+	res, err := tr.Instance.Dataset().Rename(ctx, &RenameParams{
+		Current: "me/rename_source",
+		Next:    "me/rename_target",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := map[string]bool{}
+	for _, u := range res.Updated {
+		kinds[u.Kind] = true
+	}
+	if !kinds[RenameUpdateFSI] || !kinds[RenameUpdateTransform] {
+		t.Errorf("expected working directory link & transform to be updated, got: %#v", res.Updated)
+	}
+
+	expect := fmt.Sprintf(`load_dataset("me/rename_target")
+load_dataset('%s/rename_target@/ipfs/QmFoo')
+load_dataset("me/rename_source_two")
+`, username)
+	if diff := cmp.Diff(expect, tr.MustReadFile(t, tfPath)); diff != "" {
+		t.Errorf("transform script mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestDatasetRequestsRemove(t *testing.T) {
 	ctx, done := context.WithCancel(context.Background())
 	defer done()
@@ -940,6 +1382,84 @@ func TestDatasetRequestsRemove(t *testing.T) {
 	}
 }
 
+func TestDatasetTrash(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	mr, err := testrepo.NewTestRepo()
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(mr, testcfg.DefaultP2PForTesting(), event.NilBus, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	inst := NewInstanceFromConfigAndNode(ctx, testcfg.DefaultConfigForTesting(), node)
+
+	res, err := inst.Dataset().Remove(ctx, &RemoveParams{Ref: "peer/movies", Revision: dsref.NewAllRevisions()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Trashed {
+		t.Errorf("expected removed dataset to be kept in the trash")
+	}
+	trashed, err := inst.Dataset().Trash(ctx, &TrashParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trashed) != 1 || trashed[0].Ref != "peer/movies" {
+		t.Fatalf("expected peer/movies in the trash, got: %v", trashed)
+	}
+	if _, err := inst.Dataset().Get(ctx, &GetParams{Refstr: "peer/movies"}); err == nil {
+		t.Errorf("expected trashed dataset to be removed")
+	}
+
+	vi, err := inst.Dataset().RestoreFromTrash(ctx, &RestoreFromTrashParams{Ref: "me/movies"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vi.Path != trashed[0].Path {
+		t.Errorf("restored path mismatch. want: %q got: %q", trashed[0].Path, vi.Path)
+	}
+	if _, err := inst.Dataset().Get(ctx, &GetParams{Refstr: "peer/movies"}); err != nil {
+		t.Errorf("expected restored dataset to load, got: %s", err)
+	}
+	if trashed, _ = inst.Dataset().Trash(ctx, &TrashParams{}); len(trashed) != 0 {
+		t.Errorf("expected restoring to empty the trash, got: %v", trashed)
+	}
+
+	if _, err := inst.Dataset().Remove(ctx, &RemoveParams{Ref: "peer/movies", Revision: dsref.NewAllRevisions()}); err != nil {
+		t.Fatal(err)
+	}
+	purged, err := inst.Dataset().EmptyTrash(ctx, &EmptyTrashParams{Expired: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(purged) != 0 {
+		t.Errorf("expected no datasets with an expired grace period, got: %v", purged)
+	}
+	if purged, err = inst.Dataset().EmptyTrash(ctx, &EmptyTrashParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(purged) != 1 {
+		t.Fatalf("expected 1 purged dataset, got: %v", purged)
+	}
+	if has, _ := mr.Filesystem().Has(ctx, purged[0].Path); has {
+		t.Errorf("expected purged dataset version to be removed")
+	}
+	if _, err := inst.Dataset().RestoreFromTrash(ctx, &RestoreFromTrashParams{Ref: "me/movies"}); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("expected restoring a purged dataset to fail with ErrNotInTrash, got: %v", err)
+	}
+
+	res, err = inst.Dataset().Remove(ctx, &RemoveParams{Ref: "peer/cities", Revision: dsref.NewAllRevisions(), Purge: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Trashed {
+		t.Errorf("expected purged dataset to skip the trash")
+	}
+}
+
 func TestDatasetRequestsPull(t *testing.T) {
 	ctx, done := context.WithCancel(context.Background())
 	defer done()
@@ -1203,6 +1723,88 @@ func TestDatasetRequestsStats(t *testing.T) {
 	}
 }
 
+func TestStatsHistory(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+
+	run.MustSaveFromBody(t, "cities", run.MustWriteTmpFile(t, "v1.csv", "city,pop\ntoronto,40\nchicago,25\n"))
+	run.MustSaveFromBody(t, "cities", run.MustWriteTmpFile(t, "v2.csv", "city,pop\ntoronto,40\nchicago,25\nraleigh,100\n"))
+
+	m := run.Instance.Dataset()
+	if _, err := m.StatsHistory(run.Ctx, &StatsHistoryParams{}); err == nil {
+		t.Error("expected missing reference to error")
+	}
+
+	hist, err := m.StatsHistory(run.Ctx, &StatsHistoryParams{Ref: "me/cities", Columns: []string{"pop"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hist.Versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(hist.Versions))
+	}
+
+	f := func(v float64) *float64 { return &v }
+	expect := [][]StatsHistoryColumn{
+		{{Name: "pop", Type: "numeric", Count: 3, Min: f(25), Max: f(100)}},
+		{{Name: "pop", Type: "numeric", Count: 2, Min: f(25), Max: f(40)}},
+	}
+	for i, v := range hist.Versions {
+		if v.Error != "" {
+			t.Errorf("version %d: unexpected error: %s", i, v.Error)
+		}
+		if v.Rows != 3-i {
+			t.Errorf("version %d: expected %d rows, got %d", i, 3-i, v.Rows)
+		}
+		if diff := cmp.Diff(expect[i], v.Columns); diff != "" {
+			t.Errorf("version %d: columns mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+
+	if hist, err = m.StatsHistory(run.Ctx, &StatsHistoryParams{Ref: "me/cities", Limit: 1}); err != nil {
+		t.Fatal(err)
+	} else if len(hist.Versions) != 1 {
+		t.Errorf("expected a limit of 1 to include 1 version, got %d", len(hist.Versions))
+	}
+	if hist, err = m.StatsHistory(run.Ctx, &StatsHistoryParams{Ref: "me/cities", Limit: -1}); err != nil {
+		t.Fatal(err)
+	} else if len(hist.Versions) != 2 {
+		t.Errorf("expected a limit of -1 to include every version, got %d", len(hist.Versions))
+	}
+}
+
+func TestSquash(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+
+	first := run.MustSaveFromBody(t, "cities", run.MustWriteTmpFile(t, "v1.csv", "city,pop\ntoronto,40\n"))
+	run.MustSaveFromBody(t, "cities", run.MustWriteTmpFile(t, "v2.csv", "city,pop\ntoronto,40\nchicago,25\n"))
+	run.MustSaveFromBody(t, "cities", run.MustWriteTmpFile(t, "v3.csv", "city,pop\ntoronto,40\nchicago,25\nraleigh,100\n"))
+
+	m := run.Instance.Dataset()
+	if _, err := m.Squash(run.Ctx, &SquashParams{Ref: "me/cities", Count: 1}); err == nil {
+		t.Error("expected squashing a single version to error")
+	}
+
+	res, err := m.Squash(run.Ctx, &SquashParams{Ref: "me/cities", Count: 2, Title: "add cities"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.PreviousPath != first.Path {
+		t.Errorf("expected squashed version to follow %q, got %q", first.Path, res.PreviousPath)
+	}
+	if len(res.Squashed) != 2 {
+		t.Errorf("expected 2 squashed versions, got: %v", res.Squashed)
+	}
+
+	items, err := run.Instance.Log().History(run.Ctx, &HistoryParams{Ref: "me/cities", ListParams: ListParams{Limit: -1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Path != res.Path || items[0].CommitTitle != "add cities" {
+		t.Errorf("expected history to end in the squashed version, got: %#v", items)
+	}
+}
+
 // Convert the interface value into an array, or panic if not possible
 func mustBeArray(i interface{}, err error) []interface{} {
 	if err != nil {
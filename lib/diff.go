@@ -1,12 +1,19 @@
 package lib
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
 
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/detect"
 	"github.com/qri-io/dataset/tabular"
 	"github.com/qri-io/deepdiff"
+	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/base/component"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/dsref"
@@ -37,8 +44,55 @@ type DiffParams struct {
 	Selector string
 
 	Remote string
+
+	// RightBody is the content of a body file to compare against the dataset
+	// named by LeftSide. RightSide is used as the filename of the body, which
+	// determines the body format. Populated from multipart uploads to the API
+	RightBody []byte `json:"-" schema:"-"`
+}
+
+// UnmarshalFromRequest implements a custom deserialization-from-HTTP request,
+// reading a body file to compare against a dataset from a multipart "file"
+// field
+func (p *DiffParams) UnmarshalFromRequest(r *http.Request) error {
+	if p == nil {
+		p = &DiffParams{}
+	}
+
+	if err := r.ParseMultipartForm(maxDiffBodySize); err != nil && err != http.ErrNotMultipart {
+		return err
+	}
+	params := *p
+	if err := decoder.Decode(&params, r.Form); err != nil {
+		return err
+	}
+
+	f, header, err := r.FormFile("file")
+	if err == http.ErrMissingFile || err == http.ErrNotMultipart {
+		// body files are read from the local filesystem when diffing in-process,
+		// over HTTP they must be uploaded
+		if dsref.IsRefString(params.LeftSide) && isFilePath(params.RightSide) {
+			return fmt.Errorf("%w: comparing a dataset to a body file requires uploading the file", ErrBadArgs)
+		}
+		*p = params
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("opening body file: %w", err)
+	}
+	defer f.Close()
+
+	if params.RightBody, err = ioutil.ReadAll(f); err != nil {
+		return fmt.Errorf("reading body file: %w", err)
+	}
+	params.RightSide = header.Filename
+	*p = params
+	return nil
 }
 
+// maxDiffBodySize caps the memory used to hold an uploaded body file, larger
+// files are buffered to disk
+const maxDiffBodySize = 32 << 20
+
 // diffMode determinse
 func (p *DiffParams) diffMode() (DiffMode, error) {
 	// Check parameters to make sure they fit one of the three cases that diff allows.
@@ -52,8 +106,13 @@ func (p *DiffParams) diffMode() (DiffMode, error) {
 			diffMode = DatasetRefDiffMode
 		} else if isFilePath(p.LeftSide) && isFilePath(p.RightSide) {
 			diffMode = FilepathDiffMode
+		} else if dsref.IsRefString(p.LeftSide) && isFilePath(p.RightSide) {
+			diffMode = BodyFileDiffMode
 		} else {
-			return InvalidDiffMode, fmt.Errorf("cannot compare a file to dataset, must compare similar things")
+			return InvalidDiffMode, fmt.Errorf("cannot compare a file to dataset, the dataset must come first")
+		}
+		if len(p.RightBody) > 0 && diffMode != BodyFileDiffMode {
+			return diffMode, fmt.Errorf("body files can only be compared to a dataset")
 		}
 		// Neither of the flags should be set.
 		if p.WorkingDir != "" {
@@ -102,6 +161,9 @@ const (
 	WorkingDirectoryDiffMode
 	// PrevVersionDiffMode will diff a dataset head against its previous version
 	PrevVersionDiffMode
+	// BodyFileDiffMode will diff a dataset head body against an external file,
+	// parsed using the dataset's structure
+	BodyFileDiffMode
 )
 
 // Diff computes the diff of two sources
@@ -184,6 +246,9 @@ func (datasetImpl) Diff(scope scope, p *DiffParams) (*DiffResponse, error) {
 		}
 		return nil, err
 	}
+	if diffMode == BodyFileDiffMode {
+		return diffBodyFile(scope, ds, p)
+	}
 	// TODO (b5) - setting name & peername to zero values makes tests pass, but
 	// calling ds.DropDerivedValues is overzealous. investigate the right solution
 	ds.Name = ""
@@ -317,3 +382,64 @@ func (datasetImpl) Diff(scope scope, p *DiffParams) (*DiffResponse, error) {
 	}
 	return res, nil
 }
+
+// diffBodyFile compares the body of a dataset to an external body file. The
+// file is parsed with the dataset's structure so values are typed the same way
+// they would be if the file were saved as the next version. Nothing is saved
+func diffBodyFile(scope scope, ds *dataset.Dataset, p *DiffParams) (*DiffResponse, error) {
+	if p.Selector != "" && p.Selector != "body" {
+		return nil, fmt.Errorf("only the body component can be compared to a file")
+	}
+	if ds.Structure == nil {
+		return nil, fmt.Errorf("dataset %s has no structure to parse a body file with", p.LeftSide)
+	}
+
+	data := p.RightBody
+	if len(data) == 0 {
+		var err error
+		if data, err = ioutil.ReadFile(p.RightSide); err != nil {
+			return nil, err
+		}
+	}
+
+	st := &dataset.Structure{}
+	st.Assign(ds.Structure)
+	format, err := detect.ExtensionDataFormat(p.RightSide)
+	if err != nil {
+		return nil, err
+	}
+	if format.String() != st.Format {
+		// keep the dataset schema, but use format details from the file
+		detected, _, err := detect.FromReader(format, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		st.Format = detected.Format
+		st.FormatConfig = detected.FormatConfig
+	}
+
+	leftComp := component.ConvertDatasetToComponents(ds, scope.Filesystem()).Base().GetSubcomponent("body")
+	if leftComp == nil {
+		return nil, fmt.Errorf("dataset %s has no body", p.LeftSide)
+	}
+	leftData, err := leftComp.StructuredData()
+	if err != nil {
+		return nil, err
+	}
+
+	rightComp := &component.BodyComponent{
+		BodyFile:  qfs.NewMemfileBytes(filepath.Base(p.RightSide), data),
+		Structure: st,
+	}
+	rightData, err := rightComp.StructuredData()
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s with the structure of %s: %w", filepath.Base(p.RightSide), p.LeftSide, err)
+	}
+
+	res := &DiffResponse{}
+	dd := deepdiff.New()
+	if res.Diff, res.Stat, err = dd.StatDiff(scope.Context(), leftData, rightData); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
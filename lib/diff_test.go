@@ -1,6 +1,11 @@
 package lib
 
 import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -293,9 +298,9 @@ func TestDiffErrors(t *testing.T) {
 	// Save a different dataset with one version
 	run.MustSaveFromBody(t, "test_more", "testdata/cities_2/body_more.csv")
 
-	// Error to compare a dataset ref to a file
-	_, err := run.Diff("me/test_cities", "testdata/cities_2/body_even_more.csv", "")
-	expectErr := `cannot compare a file to dataset, must compare similar things`
+	// Error to compare a file to a dataset ref
+	_, err := run.Diff("testdata/cities_2/body_even_more.csv", "me/test_cities", "")
+	expectErr := `cannot compare a file to dataset, the dataset must come first`
 	if diff := cmp.Diff(expectErr, errorMessage(err)); diff != "" {
 		t.Errorf("output mismatch (-want +got):\n%s", diff)
 	}
@@ -350,6 +355,82 @@ func TestDiffErrors(t *testing.T) {
 	}
 }
 
+// Test that a dataset body can be compared to an external file
+func TestDiffBodyFile(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+
+	run.MustSaveFromBody(t, "test_cities", "testdata/cities_2/body.csv")
+
+	output, err := run.Diff("me/test_cities", "testdata/cities_2/body_more.csv", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"stat":{"leftNodes":26,"rightNodes":36,"leftWeight":344,"rightWeight":510,"inserts":2},"diff":[[" ",0,["toronto",50000000,55.5,false]],[" ",1,["new york",8500000,44.4,true]],["+",2,["los angeles",3990000,42.7,true]],[" ",3,["chicago",300000,44.4,true]],[" ",4,["chatham",35000,65.25,true]],["+",5,["mexico city",70000000,28.6,false]],[" ",6,["raleigh",250000,50.65,true]]]}`
+	if diff := cmp.Diff(expect, output); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+
+	// uploaded body files are used in place of reading RightSide
+	data, err := ioutil.ReadFile("testdata/cities_2/body_more.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploaded, err := run.DiffWithParams(&DiffParams{
+		LeftSide:  "me/test_cities",
+		RightSide: "upload.csv",
+		RightBody: data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect, uploaded); diff != "" {
+		t.Errorf("uploaded output mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := run.Diff("me/test_cities", "testdata/cities_2/body_more.csv", "meta"); err == nil {
+		t.Error("expected comparing a file to a non-body component to error")
+	}
+}
+
+func TestDiffParamsUnmarshalFromRequest(t *testing.T) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	w.WriteField("leftPath", "me/test_cities")
+	fw, err := w.CreateFormFile("file", "body.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte("city,pop\ntoronto,50000000\n"))
+	w.Close()
+
+	r := httptest.NewRequest("POST", "/diff", body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	p := &DiffParams{}
+	if err := p.UnmarshalFromRequest(r); err != nil {
+		t.Fatal(err)
+	}
+	if p.LeftSide != "me/test_cities" || p.RightSide != "body.csv" || len(p.RightBody) == 0 {
+		t.Errorf("unexpected params: left=%q right=%q body length=%d", p.LeftSide, p.RightSide, len(p.RightBody))
+	}
+
+	r = httptest.NewRequest("GET", "/diff?leftPath=me/a&rightPath=me/b", nil)
+	p = &DiffParams{}
+	if err := p.UnmarshalFromRequest(r); err != nil {
+		t.Fatal(err)
+	}
+	if p.LeftSide != "me/a" || p.RightSide != "me/b" {
+		t.Errorf("unexpected params: left=%q right=%q", p.LeftSide, p.RightSide)
+	}
+
+	r = httptest.NewRequest("GET", "/diff?leftPath=me/a&rightPath=/etc/passwd.csv", nil)
+	p = &DiffParams{}
+	if err := p.UnmarshalFromRequest(r); !errors.Is(err, ErrBadArgs) {
+		t.Errorf("expected diffing a server file path to fail with ErrBadArgs, got: %v", err)
+	}
+}
+
 // TODO(dustmop): Test comparing a dataset in FSI, with a modification in the working directory
 // TODO(dustmop): Test comparing a dataset in FSI, using selector
 
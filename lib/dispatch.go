@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -41,6 +42,12 @@ type MethodSet interface {
 type AttributeSet struct {
 	endpoint APIEndpoint
 	verb     string
+	// readOnly methods don't change repo state & may be called by read-only
+	// CORS origins, regardless of http verb
+	readOnly bool
+	// operator methods may only be called over HTTP with an access token for
+	// the node owner or a profile with the operator role
+	operator bool
 }
 
 // Dispatch is a system for handling calls to lib. Should only be called by top-level lib methods.
@@ -141,6 +148,15 @@ func (inst *Instance) dispatchMethodCall(ctx context.Context, method string, par
 		// or use copy-on-write semantics, so that one method running at the same time as
 		// another cannot modify the out-of-scope data of the other. This will mostly
 		// involve making copies of the right things
+		// calls made by operators on behalf of another profile are checked &
+		// audited once, methods they call in turn act as the same profile
+		if who := OnBehalfOfFromCtx(ctx); who != "" {
+			if _, ok := ctx.Value(impersonatedCtxKey{}).(*profile.Profile); !ok {
+				if ctx, err = inst.impersonate(ctx, method, param, who); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
 		scope, err := newScope(ctx, inst, source)
 		if err != nil {
 			return nil, nil, err
@@ -205,6 +221,87 @@ func (inst *Instance) NewInputParam(method string) interface{} {
 	return nil
 }
 
+// MethodInfo describes a registered method that can be called over HTTP
+type MethodInfo struct {
+	// Name is the dispatch name of the method, like "dataset.save"
+	Name string `json:"name"`
+	// Endpoint & Verb are the REST route for the method
+	Endpoint APIEndpoint `json:"endpoint"`
+	Verb     string      `json:"verb"`
+	// Params lists fields of the method input struct
+	Params []ParamInfo `json:"params"`
+	// Paginated is true if the method returns a cursor
+	Paginated bool `json:"paginated,omitempty"`
+	// ReadOnly is true if the method doesn't change repo state
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Operator is true if the method requires an operator access token
+	Operator bool `json:"operator,omitempty"`
+}
+
+// ParamInfo describes a field of a method input struct
+type ParamInfo struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Methods lists registered methods that can be called over HTTP, sorted by
+// name. Methods without an endpoint are only available in-process
+func (inst *Instance) Methods() []MethodInfo {
+	res := make([]MethodInfo, 0, len(inst.regMethods.reg))
+	for name := range inst.regMethods.reg {
+		if info, ok := inst.MethodInfo(name); ok {
+			res = append(res, info)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// MethodInfo describes a registered method. ok is false if the method doesn't
+// exist or can't be called over HTTP
+func (inst *Instance) MethodInfo(method string) (info MethodInfo, ok bool) {
+	c, ok := inst.regMethods.lookup(method)
+	if !ok || c.Endpoint == "" {
+		return info, false
+	}
+	return MethodInfo{
+		Name:      method,
+		Endpoint:  c.Endpoint,
+		Verb:      c.Verb,
+		Params:    paramInfo(c.InType),
+		Paginated: c.RetCursor,
+		ReadOnly:  c.ReadOnly,
+		Operator:  c.Operator,
+	}, true
+}
+
+// paramInfo lists the JSON fields of an input struct
+func paramInfo(t reflect.Type) []ParamInfo {
+	res := []ParamInfo{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			// fields of embedded structs are flattened in JSON
+			res = append(res, paramInfo(f.Type)...)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		res = append(res, ParamInfo{Name: name, Type: f.Type.String()})
+	}
+	return res
+}
+
 // regMethodSet represents a set of registered methods
 type regMethodSet struct {
 	reg map[string]callable
@@ -226,12 +323,16 @@ type callable struct {
 	RetCursor bool
 	Endpoint  APIEndpoint
 	Verb      string
+	ReadOnly  bool
+	Operator  bool
 }
 
 // RegisterMethods iterates the methods provided by the lib API, and makes them visible to dispatch
 func (inst *Instance) RegisterMethods() {
 	reg := make(map[string]callable)
 	inst.registerOne("access", inst.Access(), accessImpl{}, reg)
+	inst.registerOne("admin", inst.Admin(), adminImpl{}, reg)
+	inst.registerOne("automation", inst.Automation(), automationImpl{}, reg)
 	inst.registerOne("config", inst.Config(), configImpl{}, reg)
 	inst.registerOne("dataset", inst.Dataset(), datasetImpl{}, reg)
 	inst.registerOne("fsi", inst.Filesys(), fsiImpl{}, reg)
@@ -397,6 +498,8 @@ func (inst *Instance) registerOne(ourName string, methods MethodSet, impl interf
 			RetCursor: returnsCursor,
 			Endpoint:  endpoint,
 			Verb:      httpVerb,
+			ReadOnly:  methodAttrs.readOnly,
+			Operator:  methodAttrs.operator,
 		}
 		log.Debugf("%d: registered %s(*%s) %v", k, funcName, inType, outType)
 	}
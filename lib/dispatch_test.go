@@ -243,8 +243,8 @@ func (m *animalMethods) Name() string {
 
 func (m *animalMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
-		"cat": {denyRPC, ""},
-		"dog": {denyRPC, ""},
+		"cat": {endpoint: denyRPC, verb: ""},
+		"dog": {endpoint: denyRPC, verb: ""},
 	}
 }
 
@@ -341,12 +341,12 @@ func (m *fruitMethods) Name() string {
 
 func (m *fruitMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
-		"apple":  {"/apple", "GET"},
-		"banana": {"/banana", "GET"},
-		"cherry": {"/cherry", "GET"},
-		"date":   {"/date", "GET"},
+		"apple":  {endpoint: "/apple", verb: "GET"},
+		"banana": {endpoint: "/banana", verb: "GET"},
+		"cherry": {endpoint: "/cherry", verb: "GET"},
+		"date":   {endpoint: "/date", verb: "GET"},
 		// entawak cannot be called over RPC
-		"entawak": {denyRPC, ""},
+		"entawak": {endpoint: denyRPC, verb: ""},
 	}
 }
 
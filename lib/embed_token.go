@@ -0,0 +1,200 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+var (
+	// DefaultEmbedTokenTTL is how long embed tokens are valid when created
+	// without a time-to-live
+	DefaultEmbedTokenTTL = time.Hour * 24 * 30
+	// ErrEmbedTokenRevoked indicates an embed token has been revoked
+	ErrEmbedTokenRevoked = errors.New("embed token has been revoked")
+	// ErrEmbedTokenScope indicates an embed token was used to read a dataset it
+	// doesn't grant access to
+	ErrEmbedTokenScope = errors.New("embed token doesn't grant access to this dataset")
+)
+
+// EmbedToken grants read access to the preview & body of a single dataset,
+// for embedding in web pages. Embed tokens are passed to the API as a "token"
+// query parameter
+type EmbedToken struct {
+	ID string `json:"id"`
+	// Ref is the alias of the dataset when the token was created
+	Ref    string    `json:"ref"`
+	InitID string    `json:"initID"`
+	Note   string    `json:"note,omitempty"`
+	Issued time.Time `json:"issued"`
+	// Expires is the zero time for tokens that never expire
+	Expires  time.Time `json:"expires,omitempty"`
+	Revoked  bool      `json:"revoked,omitempty"`
+	Uses     int       `json:"uses"`
+	LastUsed time.Time `json:"lastUsed,omitempty"`
+	// Token is the signed token string, only returned when the token is
+	// created
+	Token string `json:"token,omitempty"`
+}
+
+// Expired returns true if the token has passed its expiry time
+func (t EmbedToken) Expired() bool {
+	return !t.Expires.IsZero() && token.Timestamp().After(t.Expires)
+}
+
+// embedTokenStore persists embed tokens, keyed by token ID. Signed token
+// strings aren't stored. A store with an empty filename keeps tokens in memory
+type embedTokenStore struct {
+	sync.Mutex
+	file   jsonfile.File
+	tokens map[string]*EmbedToken
+}
+
+func newEmbedTokenStore(repoPath string) *embedTokenStore {
+	s := &embedTokenStore{tokens: map[string]*EmbedToken{}}
+	if repoPath != "" {
+		s.file = jsonfile.NewPrivate(filepath.Join(repoPath, "embed_tokens.json"))
+	}
+	return s
+}
+
+// Put adds a token
+func (s *embedTokenStore) Put(t EmbedToken) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	t.Token = ""
+	s.tokens[t.ID] = &t
+	return s.save()
+}
+
+// Use records a read made with a token, returning an error if the token is
+// unknown, revoked or expired
+func (s *embedTokenStore) Use(id string) (*EmbedToken, error) {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	t, ok := s.tokens[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown embed token", token.ErrInvalidToken)
+	}
+	if t.Revoked {
+		return nil, ErrEmbedTokenRevoked
+	}
+	if t.Expired() {
+		return nil, fmt.Errorf("%w: embed token has expired", token.ErrInvalidToken)
+	}
+	t.Uses++
+	t.LastUsed = token.Timestamp()
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	cp := *t
+	return &cp, nil
+}
+
+// Revoke marks a token as revoked. Revoked tokens are kept so their usage
+// remains listed
+func (s *embedTokenStore) Revoke(id string) (*EmbedToken, error) {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	t, ok := s.tokens[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", token.ErrTokenNotFound, id)
+	}
+	t.Revoked = true
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	cp := *t
+	return &cp, nil
+}
+
+// List returns tokens for a dataset, or all tokens if initID is empty, most
+// recently issued first
+func (s *embedTokenStore) List(initID string) ([]EmbedToken, error) {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	res := make([]EmbedToken, 0, len(s.tokens))
+	for _, t := range s.tokens {
+		if initID == "" || t.InitID == initID {
+			res = append(res, *t)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Issued.After(res[j].Issued) })
+	return res, nil
+}
+
+func (s *embedTokenStore) load() error {
+	return s.file.Load(&s.tokens)
+}
+
+func (s *embedTokenStore) save() error {
+	return s.file.Save(s.tokens)
+}
+
+// embedTokenSource returns the token source embed tokens are signed with.
+// Embed tokens are signed with the owner's key
+func (inst *Instance) embedTokenSource() (token.Source, error) {
+	owner := inst.profiles.Owner()
+	if owner == nil || owner.PrivKey == nil {
+		return nil, fmt.Errorf("embed tokens require an owner profile with a private key")
+	}
+	return token.NewPrivKeySource(owner.PrivKey)
+}
+
+// embedReadCtxKey holds the InitID of the dataset an embed token granted read
+// access to
+type embedReadCtxKey struct{}
+
+// UseEmbedToken checks a raw embed token grants read access to the dataset
+// refstr resolves to, counting the read as a use of the token. The returned
+// context reads the dataset as the node owner, even if the owner has signed
+// out. Errors wrap token.ErrInvalidToken, ErrEmbedTokenRevoked or
+// ErrEmbedTokenScope
+func (inst *Instance) UseEmbedToken(ctx context.Context, raw, refstr string) (context.Context, error) {
+	src, err := inst.embedTokenSource()
+	if err != nil {
+		return nil, err
+	}
+	id, initID, err := token.ParseEmbedToken(raw, src)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, _, err := inst.ParseAndResolveRef(ctx, refstr, "local")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrEmbedTokenScope, err)
+	}
+	if ref.InitID != initID {
+		return nil, ErrEmbedTokenScope
+	}
+	if _, err = inst.embedTokens.Use(id); err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, embedReadCtxKey{}, initID), nil
+}
+
+// embedReadFromCtx returns the InitID of the dataset an embed token granted
+// read access to, or the empty string
+func embedReadFromCtx(ctx context.Context) string {
+	initID, _ := ctx.Value(embedReadCtxKey{}).(string)
+	return initID
+}
@@ -0,0 +1,35 @@
+package lib
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/qri-io/qri/event"
+)
+
+// eventBridgeFilename is the name of the unix socket a connected instance
+// serves events on, relative to the repo path
+const eventBridgeFilename = "events.sock"
+
+// ServeEventBridge exposes the instance event bus to other processes on a
+// unix socket in the repo directory. Instances created while the bridge is
+// served operate over RPC, and republish bridged events on their own bus
+func (inst *Instance) ServeEventBridge(ctx context.Context) error {
+	if inst.repoPath == "" || inst.bus == nil {
+		return nil
+	}
+	return event.ServeBridge(ctx, inst.bus, filepath.Join(inst.repoPath, eventBridgeFilename))
+}
+
+// connectEventBridge subscribes to events from an instance serving an event
+// bridge. Failing to connect isn't an error, instances serving RPC may not
+// serve a bridge
+func (inst *Instance) connectEventBridge(ctx context.Context) {
+	if inst.repoPath == "" {
+		return
+	}
+	sockPath := filepath.Join(inst.repoPath, eventBridgeFilename)
+	if err := event.ConnectBridge(ctx, sockPath, inst.bus, event.BridgeSubscription{}); err != nil {
+		log.Debugw("connecting to event bridge", "path", sockPath, "err", err)
+	}
+}
@@ -121,8 +121,14 @@ func readSingleFile(path string) (*dataset.Dataset, string, error) {
 			return &ds, "tf", nil
 
 		case ".html":
-			// html files are assumped to be a viz script with no additional viz
-			// component details
+			if strings.ToLower(filepath.Base(path)) == "readme.html" {
+				ds.Readme = &dataset.Readme{ScriptPath: path}
+				ds.Readme.Format = "html"
+				ds.Readme.SetScriptFile(qfs.NewMemfileReader("readme.html", f))
+				return &ds, "rm", nil
+			}
+			// other html files are assumped to be a viz script with no additional
+			// viz component details
 			// TODO(dlong): Deprecate viz, assume "html" is a readme
 			ds.Viz = &dataset.Viz{ScriptPath: path}
 			ds.Viz.Format = "html"
@@ -136,6 +142,13 @@ func readSingleFile(path string) (*dataset.Dataset, string, error) {
 			ds.Readme.SetScriptFile(qfs.NewMemfileReader("readme.md", f))
 			return &ds, "rm", nil
 
+		case ".ipynb":
+			// jupyter notebooks are assumed to be a readme file
+			ds.Readme = &dataset.Readme{ScriptPath: path}
+			ds.Readme.Format = "ipynb"
+			ds.Readme.SetScriptFile(qfs.NewMemfileReader("readme.ipynb", f))
+			return &ds, "rm", nil
+
 		default:
 			return nil, "", fmt.Errorf("error, unrecognized file extension: \"%s\"", fileExt)
 		}
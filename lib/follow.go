@@ -0,0 +1,169 @@
+package lib
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+// FollowInterval is how often followed datasets are checked for new versions
+var FollowInterval = time.Hour
+
+// Follow is a dataset whose new versions are pulled automatically
+type Follow struct {
+	// Ref is the alias of the followed dataset, like "peer/dataset"
+	Ref string `json:"ref"`
+	// Remote to pull from, empty pulls from the default remote
+	Remote      string    `json:"remote,omitempty"`
+	Since       time.Time `json:"since"`
+	LastChecked time.Time `json:"lastChecked,omitempty"`
+	// LastPath is the most recent version pulled
+	LastPath  string `json:"lastPath,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// followStore persists followed datasets, keyed by alias. A store with an
+// empty filename keeps follows in memory
+type followStore struct {
+	sync.Mutex
+	file    jsonfile.File
+	follows map[string]*Follow
+}
+
+func newFollowStore(repoPath string) *followStore {
+	s := &followStore{follows: map[string]*Follow{}}
+	if repoPath != "" {
+		s.file = jsonfile.New(filepath.Join(repoPath, "follows.json"))
+	}
+	return s
+}
+
+// Put adds or replaces a follow
+func (s *followStore) Put(f *Follow) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.follows[f.Ref] = f
+	return s.save()
+}
+
+// Update replaces a follow, unless the dataset was unfollowed
+func (s *followStore) Update(f *Follow) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.follows[f.Ref]; !ok {
+		return nil
+	}
+	s.follows[f.Ref] = f
+	return s.save()
+}
+
+// Remove stops following a dataset
+func (s *followStore) Remove(alias string) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	delete(s.follows, alias)
+	return s.save()
+}
+
+// List returns follows ordered by alias
+func (s *followStore) List() ([]Follow, error) {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	res := make([]Follow, 0, len(s.follows))
+	for _, f := range s.follows {
+		res = append(res, *f)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Ref < res[j].Ref })
+	return res, nil
+}
+
+func (s *followStore) load() error {
+	return s.file.Load(&s.follows)
+}
+
+func (s *followStore) save() error {
+	return s.file.Save(s.follows)
+}
+
+// followAliases lists the aliases of followed datasets
+func (inst *Instance) followAliases(ctx context.Context) ([]string, error) {
+	follows, err := inst.follows.List()
+	if err != nil {
+		return nil, err
+	}
+	aliases := make([]string, 0, len(follows))
+	for _, f := range follows {
+		aliases = append(aliases, f.Ref)
+	}
+	return aliases, nil
+}
+
+// startFollowing pulls new versions of followed datasets in the background
+// every FollowInterval, and whenever the node comes online
+func (inst *Instance) startFollowing(ctx context.Context) {
+	online := make(chan struct{}, 1)
+	inst.bus.SubscribeTypes(func(ctx context.Context, e event.Event) error {
+		select {
+		case online <- struct{}{}:
+		default:
+		}
+		return nil
+	}, event.ETP2PGoneOnline)
+
+	inst.releasers.Add(1)
+	go func() {
+		defer inst.releasers.Done()
+		ticker := time.NewTicker(FollowInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-online:
+			case <-ticker.C:
+			}
+			inst.pullFollows(ctx)
+		}
+	}()
+}
+
+// pullFollows pulls the latest version of every followed dataset
+func (inst *Instance) pullFollows(ctx context.Context) {
+	follows, err := inst.follows.List()
+	if err != nil {
+		log.Debugw("listing follows", "err", err)
+		return
+	}
+	for _, f := range follows {
+		f := f
+		f.LastChecked = time.Now()
+		f.LastError = ""
+		ds, err := inst.WithSource("network").Dataset().Pull(ctx, &PullParams{Ref: f.Ref, Remote: f.Remote})
+		if err != nil {
+			log.Debugw("pulling followed dataset", "ref", f.Ref, "err", err)
+			f.LastError = err.Error()
+		} else {
+			f.LastPath = ds.Path
+		}
+		if err := inst.follows.Update(&f); err != nil {
+			log.Debugw("saving follow", "ref", f.Ref, "err", err)
+		}
+	}
+}
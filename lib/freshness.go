@@ -0,0 +1,301 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/remote"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+// FreshnessInterval is how often dataset sources are checked for new data
+var FreshnessInterval = time.Hour
+
+// ErrNoFreshnessCheck is returned when a dataset isn't checked for freshness
+var ErrNoFreshnessCheck = errors.New("dataset has no freshness check")
+
+// FreshnessCheck periodically checks the source of a dataset body for new
+// data with a HEAD request, only saving a new version when the source has
+// changed
+type FreshnessCheck struct {
+	// Ref is the alias of the checked dataset, like "peer/dataset"
+	Ref    string `json:"ref"`
+	InitID string `json:"initID"`
+	// BodyURL is the source checked for new data
+	BodyURL     string    `json:"bodyURL"`
+	Since       time.Time `json:"since"`
+	LastChecked time.Time `json:"lastChecked,omitempty"`
+	// LastChanged is the last time a check found new data
+	LastChanged time.Time `json:"lastChanged,omitempty"`
+	// LastPath is the most recent version saved by a check
+	LastPath  string `json:"lastPath,omitempty"`
+	LastError string `json:"lastError,omitempty"`
+	// Workflow is the alias of the workflow dataset that defines the check.
+	// Checks defined by workflows are added & removed as workflows change
+	Workflow string `json:"workflow,omitempty"`
+	// FailurePolicy sets how failed checks are retried, reported & paused
+	FailurePolicy
+	// Failures counts consecutive failed checks, zero after a success
+	Failures int `json:"failures,omitempty"`
+	// Attempts counts tries of the current check. Checks that fail with
+	// attempts left are retried at RetryAt instead of counting as a failure
+	Attempts int       `json:"attempts,omitempty"`
+	RetryAt  time.Time `json:"retryAt,omitempty"`
+	// Paused checks are skipped by the scheduler until they're resumed
+	Paused bool `json:"paused,omitempty"`
+}
+
+// freshnessStore persists freshness checks, keyed by alias. A store with an
+// empty filename keeps checks in memory
+type freshnessStore struct {
+	sync.Mutex
+	file   jsonfile.File
+	checks map[string]*FreshnessCheck
+}
+
+func newFreshnessStore(repoPath string) *freshnessStore {
+	s := &freshnessStore{checks: map[string]*FreshnessCheck{}}
+	if repoPath != "" {
+		s.file = jsonfile.New(filepath.Join(repoPath, "freshness.json"))
+	}
+	return s
+}
+
+// Put adds or replaces a check
+func (s *freshnessStore) Put(c *FreshnessCheck) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.checks[c.Ref] = c
+	return s.save()
+}
+
+// Update replaces a check, unless the check was removed
+func (s *freshnessStore) Update(c *FreshnessCheck) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.checks[c.Ref]; !ok {
+		return nil
+	}
+	s.checks[c.Ref] = c
+	return s.save()
+}
+
+// Remove stops checking a dataset
+func (s *freshnessStore) Remove(alias string) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	delete(s.checks, alias)
+	return s.save()
+}
+
+// Resume unpauses a check & resets its failure count & pending retry
+func (s *freshnessStore) Resume(alias string) (*FreshnessCheck, error) {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	c, ok := s.checks[alias]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoFreshnessCheck, alias)
+	}
+	c.Paused = false
+	c.Failures = 0
+	c.Attempts = 0
+	c.RetryAt = time.Time{}
+	res := *c
+	return &res, s.save()
+}
+
+// List returns checks ordered by alias
+func (s *freshnessStore) List() ([]FreshnessCheck, error) {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	res := make([]FreshnessCheck, 0, len(s.checks))
+	for _, c := range s.checks {
+		res = append(res, *c)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Ref < res[j].Ref })
+	return res, nil
+}
+
+// nextRetry returns a channel that fires when the earliest retry of an
+// unpaused check is due, nil if no retries are pending
+func (s *freshnessStore) nextRetry() <-chan time.Time {
+	checks, err := s.List()
+	if err != nil {
+		log.Debugw("listing freshness checks", "err", err)
+		return nil
+	}
+	var next time.Time
+	for _, c := range checks {
+		if !c.Paused && !c.RetryAt.IsZero() && (next.IsZero() || c.RetryAt.Before(next)) {
+			next = c.RetryAt
+		}
+	}
+	if next.IsZero() {
+		return nil
+	}
+	return time.After(time.Until(next))
+}
+
+func (s *freshnessStore) load() error {
+	return s.file.Load(&s.checks)
+}
+
+func (s *freshnessStore) save() error {
+	return s.file.Save(s.checks)
+}
+
+// startFreshnessChecks checks dataset sources for new data in the background
+// every FreshnessInterval, scheduling active workflows before each round.
+// Failed checks with attempts left are retried when their backoff elapses,
+// between rounds. Paused checks are skipped
+func (inst *Instance) startFreshnessChecks(ctx context.Context) {
+	inst.releasers.Add(1)
+	go func() {
+		defer inst.releasers.Done()
+		ticker := time.NewTicker(FreshnessInterval)
+		defer ticker.Stop()
+		// retry fires when the earliest pending retry is due
+		retry := inst.freshness.nextRetry()
+		for {
+			round := false
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				round = true
+			case <-retry:
+			}
+			scope, err := newScope(ctx, inst, "local")
+			if err != nil {
+				log.Debugw("creating freshness check scope", "err", err)
+				continue
+			}
+			if round {
+				if _, err := syncWorkflows(scope); err != nil {
+					log.Debugw("scheduling workflows", "err", err)
+				}
+			}
+			checks, err := inst.freshness.List()
+			if err != nil {
+				log.Debugw("listing freshness checks", "err", err)
+				continue
+			}
+			for _, c := range checks {
+				if c.Paused {
+					continue
+				}
+				// checks waiting on a retry run when it's due, not with the round
+				if (c.RetryAt.IsZero() && round) || (!c.RetryAt.IsZero() && !time.Now().Before(c.RetryAt)) {
+					c := c
+					runFreshnessCheck(scope, &c)
+				}
+			}
+			retry = inst.freshness.nextRetry()
+		}
+	}()
+}
+
+// runFreshnessCheck makes one attempt at checking a dataset source for new
+// data, saving a new version only if the source has changed. A failed attempt
+// with attempts left in the check's FailurePolicy schedules a retry at
+// RetryAt, waiting longer after each attempt. Once attempts run out the check
+// counts a failure, & repeated failures pause it. Results are published as an
+// ETDatasetFreshness event & recorded on the check
+func runFreshnessCheck(scope scope, c *FreshnessCheck) {
+	ctx := scope.Context()
+	evt := event.DsFreshness{Ref: c.Ref, BodyURL: c.BodyURL}
+
+	c.Attempts++
+	evt.Attempts = c.Attempts
+	err := checkFreshness(scope, c, &evt)
+	if err != nil {
+		log.Debugw("checking dataset freshness", "ref", c.Ref, "attempt", c.Attempts, "err", err)
+		if c.Attempts < c.attempts() {
+			// the first retry waits the backoff, doubling for each later one
+			c.RetryAt = time.Now().Add(c.backoff() << uint(c.Attempts-1))
+			c.LastError = err.Error()
+			if err := scope.inst.freshness.Update(c); err != nil {
+				log.Debugw("saving freshness check", "ref", c.Ref, "err", err)
+			}
+			return
+		}
+	}
+	c.Attempts = 0
+	c.RetryAt = time.Time{}
+
+	c.LastError = ""
+	if err != nil {
+		evt.Error = err.Error()
+		c.LastError = err.Error()
+		c.Failures++
+		if n := c.pauseAfter(); n > 0 && c.Failures >= n {
+			c.Paused = true
+		}
+	} else {
+		c.Failures = 0
+	}
+	evt.Failures = c.Failures
+	evt.Paused = c.Paused
+
+	if err := scope.Bus().Publish(ctx, event.ETDatasetFreshness, evt); err != nil {
+		log.Debugw("publishing freshness event", "ref", c.Ref, "err", err)
+	}
+	if err != nil {
+		for _, hook := range c.Webhooks {
+			if err := remote.PostWebhook(ctx, hook, evt); err != nil {
+				log.Debugw("notifying freshness failure webhook", "ref", c.Ref, "webhook", hook, "err", err)
+			}
+		}
+	}
+	if err := scope.inst.freshness.Update(c); err != nil {
+		log.Debugw("saving freshness check", "ref", c.Ref, "err", err)
+	}
+}
+
+// checkFreshness makes one attempt at checking a dataset source, recording
+// changes on the check & event. Unchanged sources aren't an error
+func checkFreshness(scope scope, c *FreshnessCheck, evt *event.DsFreshness) error {
+	ctx := scope.Context()
+	c.LastChecked = time.Now()
+
+	_, err := base.CheckBodyURL(ctx, c.BodyURL, scope.BodyFetches().Get(c.InitID))
+	if err == nil {
+		// save makes a conditional download, sources that can't be checked
+		// with HEAD requests may still turn out to be unchanged
+		var ds *dataset.Dataset
+		if ds, err = scope.inst.Dataset().Save(ctx, &SaveParams{Ref: c.Ref, BodyURL: c.BodyURL}); err == nil {
+			evt.Changed = true
+			evt.Saved = ds.Path
+			c.LastChanged = c.LastChecked
+			c.LastPath = ds.Path
+		}
+	}
+	if errors.Is(err, base.ErrBodyURLNotModified) || errors.Is(err, dsfs.ErrNoChanges) {
+		return nil
+	}
+	return err
+}
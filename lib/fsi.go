@@ -2,6 +2,7 @@ package lib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -14,6 +15,8 @@ import (
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/fsi"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/remote"
 	"github.com/qri-io/qri/repo"
 )
 
@@ -30,16 +33,19 @@ func (m FSIMethods) Name() string {
 // Attributes defines attributes for each method
 func (m FSIMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
-		"createlink":            {AEFSICreateLink, "POST"},
-		"unlink":                {AEFSIUnlink, "POST"},
-		"status":                {AEStatus, "GET"},
-		"whatchanged":           {AEWhatChanged, "GET"},
-		"checkout":              {AECheckout, "POST"},
-		"write":                 {AEFSIWrite, "POST"},
-		"restore":               {AERestore, "POST"},
-		"init":                  {AEInit, "POST"},
-		"caninitdatasetworkdir": {AECanInitDatasetWorkDir, "GET"},
-		"ensureref":             {AEEnsureRef, "POST"},
+		"createlink":            {endpoint: AEFSICreateLink, verb: "POST"},
+		"unlink":                {endpoint: AEFSIUnlink, verb: "POST"},
+		"status":                {endpoint: AEStatus, verb: "GET", readOnly: true},
+		"statusall":             {endpoint: AEStatusAll, verb: "POST", readOnly: true},
+		"whatchanged":           {endpoint: AEWhatChanged, verb: "GET", readOnly: true},
+		"checkout":              {endpoint: AECheckout, verb: "POST"},
+		"write":                 {endpoint: AEFSIWrite, verb: "POST"},
+		"restore":               {endpoint: AERestore, verb: "POST"},
+		"init":                  {endpoint: AEInit, verb: "POST"},
+		"caninitdatasetworkdir": {endpoint: AECanInitDatasetWorkDir, verb: "GET"},
+		"ensureref":             {endpoint: AEEnsureRef, verb: "POST"},
+		"gitexport":             {endpoint: AEGitExport, verb: "POST"},
+		"gitimport":             {endpoint: AEGitImport, verb: "POST"},
 	}
 }
 
@@ -106,6 +112,50 @@ func (m FSIMethods) Status(ctx context.Context, p *LinkParams) ([]StatusItem, er
 	return nil, dispatchReturnError(got, err)
 }
 
+// StatusAllParams provides arguments to the StatusAll method
+type StatusAllParams struct {
+	// Remote to compare versions against, defaults to the registry
+	Remote string `json:"remote"`
+	// Offline skips comparing versions with the remote
+	Offline bool `json:"offline"`
+}
+
+// DatasetStatus summarizes the state of a dataset linked to a working
+// directory
+type DatasetStatus struct {
+	Ref     string `json:"ref"`
+	FSIPath string `json:"fsiPath"`
+	// Changes lists components of the working directory that differ from the
+	// last saved version
+	Changes []StatusItem `json:"changes"`
+	// StatusError is set when the working directory couldn't be checked
+	StatusError string `json:"statusError,omitempty"`
+	// Ahead counts saved versions the remote doesn't have
+	Ahead int `json:"ahead"`
+	// Behind counts versions on the remote that aren't saved locally
+	Behind int `json:"behind"`
+	// RemoteError is set when versions couldn't be compared with the remote
+	RemoteError string `json:"remoteError,omitempty"`
+	// Queued lists pushes of the dataset waiting for their remote to become
+	// reachable
+	Queued []remote.QueuedPush `json:"queued,omitempty"`
+}
+
+// Dirty is true when a linked working directory has unsaved changes
+func (s DatasetStatus) Dirty() bool {
+	return len(s.Changes) > 0 || s.StatusError != ""
+}
+
+// StatusAll reports the status of every dataset linked to a working directory:
+// unsaved changes, versions not yet synced with a remote, and queued pushes
+func (m FSIMethods) StatusAll(ctx context.Context, p *StatusAllParams) ([]DatasetStatus, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "statusall"), p)
+	if res, ok := got.([]DatasetStatus); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
 // WhatChanged gets changes that happened at a particular version in the history of the given
 // dataset reference.
 func (m FSIMethods) WhatChanged(ctx context.Context, p *LinkParams) ([]StatusItem, error) {
@@ -161,6 +211,63 @@ func (m FSIMethods) EnsureRef(ctx context.Context, p *LinkParams) (*dsref.Versio
 	return nil, dispatchReturnError(got, err)
 }
 
+// GitParams encapsulates parameters for exchanging dataset versions with a
+// git repository
+type GitParams struct {
+	Refstr string
+	// Dir is a directory within the git repository
+	Dir string `qri:"fspath"`
+	// Branch to mirror versions to, defaults to "qri/" followed by the
+	// dataset name
+	Branch string
+}
+
+// Validate returns an error if GitParams fields are in an invalid state
+func (p *GitParams) Validate() error {
+	if p.Refstr == "" {
+		return fmt.Errorf("dataset reference is required")
+	}
+	if p.Dir == "" {
+		return fmt.Errorf("git repository directory is required")
+	}
+	return nil
+}
+
+// GitResult describes the outcome of a git export or import
+type GitResult struct {
+	Branch string `json:"branch"`
+	// Head is the commit at the tip of the branch
+	Head string `json:"head"`
+	// Exported counts versions committed to the branch by an export
+	Exported int `json:"exported"`
+	// Path of the version created by an import, empty if the branch had no
+	// changes to import
+	Path string `json:"path,omitempty"`
+}
+
+// GitExport commits every version of a dataset that isn't yet on the branch,
+// one git commit per version, oldest first. Commit messages & authors are
+// mapped from version commits, and each git commit records the version it
+// was exported from in a trailer so later exports only add new versions
+func (m FSIMethods) GitExport(ctx context.Context, p *GitParams) (*GitResult, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "gitexport"), p)
+	if res, ok := got.(*GitResult); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// GitImport saves the components at the tip of the branch as a new version
+// of the dataset, using the git commit message as the version commit. If the
+// tip of the branch was exported from qri there's nothing to import
+func (m FSIMethods) GitImport(ctx context.Context, p *GitParams) (*GitResult, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "gitimport"), p)
+	if res, ok := got.(*GitResult); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
 // Implementations for FSI methods follow
 
 // fsiImpl holds the method implementations for FSI
@@ -218,7 +325,8 @@ func (fsiImpl) Unlink(scope scope, p *LinkParams) (string, error) {
 // Status checks for any modifications or errors in a linked directory against its previous
 // version in the repo. Must only be called if FSI is enabled for this dataset.
 func (fsiImpl) Status(scope scope, p *LinkParams) ([]StatusItem, error) {
-	ctx := scope.Context()
+	// status compares against stored versions, which are always local
+	ctx := dsfs.WithLocalOnly(scope.Context())
 
 	if p.Dir == "" && p.Refstr == "" {
 		return nil, fmt.Errorf("either Dir or Refstr required for status")
@@ -242,6 +350,118 @@ func (fsiImpl) Status(scope scope, p *LinkParams) ([]StatusItem, error) {
 	return scope.FSISubsystem().Status(ctx, vi.FSIPath)
 }
 
+// StatusAll reports the status of every dataset linked to a working directory
+func (fsiImpl) StatusAll(scope scope, p *StatusAllParams) ([]DatasetStatus, error) {
+	// working directory status compares against stored versions, which are
+	// always local
+	localCtx := dsfs.WithLocalOnly(scope.Context())
+
+	links, err := scope.FSISubsystem().ListLinks(0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := ""
+	if !p.Offline && scope.RemoteClient() != nil {
+		if addr, err = scope.inst.remoteAddress(p.Remote); err != nil {
+			return nil, err
+		}
+	}
+
+	var queued []remote.QueuedPush
+	if scope.inst.pushQueue != nil {
+		queued = scope.inst.pushQueue.List()
+	}
+
+	res := make([]DatasetStatus, 0, len(links))
+	for _, vi := range links {
+		ref := vi.SimpleRef()
+		if ref.InitID == "" {
+			ref.InitID, _ = scope.Logbook().RefToInitID(dsref.Ref{Username: ref.Username, Name: ref.Name})
+		}
+		st := DatasetStatus{
+			Ref:     ref.Human(),
+			FSIPath: vi.FSIPath,
+			Changes: []StatusItem{},
+		}
+
+		items, err := scope.FSISubsystem().Status(localCtx, vi.FSIPath)
+		if err != nil {
+			st.StatusError = err.Error()
+		}
+		for _, si := range items {
+			if si.Type != fsi.STUnmodified {
+				st.Changes = append(st.Changes, si)
+			}
+		}
+
+		if addr != "" {
+			if st.Ahead, st.Behind, err = compareRemoteVersions(scope, ref, addr); err != nil {
+				st.RemoteError = err.Error()
+			}
+		}
+
+		for _, q := range queued {
+			if (ref.InitID != "" && q.Ref.InitID == ref.InitID) || (q.Ref.Username == ref.Username && q.Ref.Name == ref.Name) {
+				st.Queued = append(st.Queued, q)
+			}
+		}
+		res = append(res, st)
+	}
+	return res, nil
+}
+
+// compareRemoteVersions counts saved versions of a dataset missing from a
+// remote (ahead) and versions on the remote missing locally (behind)
+func compareRemoteVersions(scope scope, ref dsref.Ref, addr string) (ahead, behind int, err error) {
+	local, err := scope.Logbook().Items(scope.Context(), ref, 0, -1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	logs, err := scope.RemoteClient().FetchLogs(scope.Context(), ref, addr)
+	if err != nil {
+		if errors.Is(err, logbook.ErrNotFound) {
+			// the remote has never seen this dataset
+			return len(versionPaths(local)), 0, nil
+		}
+		return 0, 0, err
+	}
+	// descend from the user log to the branch log, as in log.History
+	if len(logs.Logs) > 0 {
+		logs = logs.Logs[0]
+		if len(logs.Logs) > 0 {
+			logs = logs.Logs[0]
+		}
+	}
+
+	localPaths := versionPaths(local)
+	remotePaths := versionPaths(logbook.ConvertLogsToVersionInfos(logs, ref))
+	for path := range localPaths {
+		if !remotePaths[path] {
+			ahead++
+		}
+	}
+	for path := range remotePaths {
+		if !localPaths[path] {
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// versionPaths gets the set of version paths in a history, skipping runs that
+// didn't save a version
+func versionPaths(items []dsref.VersionInfo) map[string]bool {
+	paths := map[string]bool{}
+	for _, item := range items {
+		if item.Path != "" {
+			paths[item.Path] = true
+		}
+	}
+	return paths
+}
+
 // WhatChanged gets changes that happened at a particular version in the history of the given
 // dataset reference.
 func (fsiImpl) WhatChanged(scope scope, p *LinkParams) ([]StatusItem, error) {
@@ -446,6 +666,110 @@ func (fsiImpl) EnsureRef(scope scope, p *LinkParams) (*dsref.VersionInfo, error)
 	return scope.FSISubsystem().ModifyLinkDirectory(ctx, p.Dir, ref)
 }
 
+// GitExport commits dataset versions to a git branch
+func (fsiImpl) GitExport(scope scope, p *GitParams) (*GitResult, error) {
+	ctx := scope.Context()
+	ref, bridge, err := openGitBridge(scope, p)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := base.DatasetLog(ctx, scope.Repo(), ref, -1, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	exported, err := bridge.Exported(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &GitResult{Branch: bridge.Branch()}
+	pro := scope.ActiveProfile()
+	// history is ordered newest first
+	for i := len(history) - 1; i >= 0; i-- {
+		vi := history[i]
+		if vi.Path == "" || exported[vi.Path] != "" {
+			continue
+		}
+		ds, err := dsfs.LoadDataset(ctx, scope.Filesystem(), vi.Path)
+		if err != nil {
+			return nil, err
+		}
+		ds.Peername = ref.Username
+		ds.Name = ref.Name
+		if err := base.OpenDataset(ctx, scope.Filesystem(), ds); err != nil {
+			return nil, err
+		}
+
+		author := fsi.GitAuthor{Name: ref.Username}
+		if ds.Commit != nil && ds.Commit.Author != nil && pro != nil && ds.Commit.Author.ID == pro.ID.String() {
+			author.Email = pro.Email
+		}
+		if res.Head, err = bridge.Export(ctx, ds, scope.Filesystem(), author); err != nil {
+			return nil, fmt.Errorf("exporting version %s: %w", vi.Path, err)
+		}
+		res.Exported++
+	}
+
+	if res.Head == "" {
+		if head, err := bridge.Head(ctx); err == nil && head != nil {
+			res.Head = head.Hash
+		}
+	}
+	return res, nil
+}
+
+// GitImport saves the tip of a git branch as a dataset version
+func (fsiImpl) GitImport(scope scope, p *GitParams) (*GitResult, error) {
+	ctx := scope.Context()
+	ref, bridge, err := openGitBridge(scope, p)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := bridge.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, fmt.Errorf("git branch %q doesn't exist", bridge.Branch())
+	}
+	res := &GitResult{Branch: bridge.Branch(), Head: head.Hash}
+	if head.QriVersion != "" {
+		return res, nil
+	}
+
+	ds, err := bridge.ReadCommit(ctx, head.Hash)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := scope.inst.Dataset().Save(ctx, &SaveParams{
+		Ref:     ref.Alias(),
+		Dataset: ds,
+		Title:   head.Title,
+		Message: head.Message,
+		Replace: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.Path = saved.Path
+	return res, nil
+}
+
+func openGitBridge(scope scope, p *GitParams) (dsref.Ref, *fsi.GitBridge, error) {
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Refstr, "local")
+	if err != nil {
+		return ref, nil, err
+	}
+	branch := p.Branch
+	if branch == "" {
+		branch = "qri/" + ref.Name
+	}
+	bridge, err := fsi.NewGitBridge(scope.Context(), p.Dir, branch)
+	return ref, bridge, err
+}
+
 // PathJoinPosix joins two paths, and makes it explicitly clear we want POSIX slashes
 func PathJoinPosix(left, right string) string {
 	return path.Join(left, right)
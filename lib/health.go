@@ -0,0 +1,103 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// HealthCheckQFS checks the instance filesystem is open
+	HealthCheckQFS = "qfs"
+	// HealthCheckLogbook checks the logbook is loaded
+	HealthCheckLogbook = "logbook"
+	// HealthCheckP2P checks the p2p node is listening. Only applies when p2p
+	// is enabled
+	HealthCheckP2P = "p2p"
+)
+
+// HealthChecks lists the names of all subsystem checks
+var HealthChecks = []string{HealthCheckQFS, HealthCheckLogbook, HealthCheckP2P}
+
+// HealthCheck is the result of checking a single subsystem
+type HealthCheck struct {
+	Name string `json:"name"`
+	OK   bool   `json:"ok"`
+	// Required is true if the check must pass for the instance to be ready
+	Required bool   `json:"required"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// Readiness aggregates subsystem checks. An instance is ready when all
+// required checks pass
+type Readiness struct {
+	Ready  bool          `json:"ready"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// Readiness checks each subsystem an instance depends on. Checks listed in
+// the api.readiness config field are required, when the field is empty every
+// check that applies is required
+func (inst *Instance) Readiness(ctx context.Context) *Readiness {
+	required := map[string]bool{}
+	if cfg := inst.GetConfig(); cfg != nil && cfg.API != nil {
+		for _, name := range cfg.API.Readiness {
+			required[name] = true
+		}
+	}
+
+	res := &Readiness{Ready: true, Checks: []HealthCheck{}}
+	for _, name := range HealthChecks {
+		applies, err := inst.healthCheck(ctx, name)
+		if !applies {
+			continue
+		}
+		hc := HealthCheck{
+			Name:     name,
+			OK:       err == nil,
+			Required: len(required) == 0 || required[name],
+		}
+		if err != nil {
+			hc.Detail = err.Error()
+			if hc.Required {
+				res.Ready = false
+			}
+		}
+		res.Checks = append(res.Checks, hc)
+	}
+	return res
+}
+
+// healthCheck runs a named check, reporting if the check applies to this
+// instance and any problem found
+func (inst *Instance) healthCheck(ctx context.Context, name string) (applies bool, err error) {
+	switch name {
+	case HealthCheckQFS:
+		if inst.qfs == nil {
+			return true, fmt.Errorf("filesystem not open")
+		}
+		if inst.qfs.Filesystem("ipfs") != nil {
+			if _, err := inst.node.IPFSCoreAPI(); err != nil {
+				return true, fmt.Errorf("ipfs unavailable: %w", err)
+			}
+		}
+		return true, nil
+	case HealthCheckLogbook:
+		if inst.logbook == nil || inst.logbook.AuthorID() == "" {
+			return true, fmt.Errorf("logbook not loaded")
+		}
+		return true, nil
+	case HealthCheckP2P:
+		cfg := inst.GetConfig()
+		if cfg == nil || cfg.P2P == nil || !cfg.P2P.Enabled {
+			return false, nil
+		}
+		if inst.node == nil || !inst.node.Online {
+			return true, fmt.Errorf("p2p node offline")
+		}
+		if len(inst.node.EncapsulatedAddresses()) == 0 {
+			return true, fmt.Errorf("p2p node isn't listening on any addresses")
+		}
+		return true, nil
+	}
+	return false, nil
+}
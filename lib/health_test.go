@@ -0,0 +1,46 @@
+package lib
+
+import (
+	"testing"
+)
+
+func TestReadiness(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	cfg := tr.Instance.GetConfig()
+	cfg.P2P.Enabled = true
+
+	res := tr.Instance.Readiness(tr.Ctx)
+	checks := map[string]HealthCheck{}
+	for _, c := range res.Checks {
+		checks[c.Name] = c
+	}
+	if !checks[HealthCheckQFS].OK || !checks[HealthCheckLogbook].OK {
+		t.Errorf("expected qfs & logbook checks to pass. got: %#v", res.Checks)
+	}
+	if p2p, ok := checks[HealthCheckP2P]; !ok || p2p.OK || p2p.Detail == "" {
+		t.Errorf("expected p2p check to fail with detail for an offline node. got: %#v", p2p)
+	}
+	if res.Ready {
+		t.Errorf("expected offline node not to be ready when all checks are required")
+	}
+
+	cfg.API.Readiness = []string{HealthCheckQFS, HealthCheckLogbook}
+	res = tr.Instance.Readiness(tr.Ctx)
+	if !res.Ready {
+		t.Errorf("expected node to be ready when p2p isn't required. got: %#v", res.Checks)
+	}
+	for _, c := range res.Checks {
+		if c.Name == HealthCheckP2P && c.Required {
+			t.Errorf("expected p2p check not to be required")
+		}
+	}
+
+	cfg.P2P.Enabled = false
+	for _, c := range tr.Instance.Readiness(tr.Ctx).Checks {
+		if c.Name == HealthCheckP2P {
+			t.Errorf("expected p2p check to be skipped when p2p is disabled")
+		}
+	}
+}
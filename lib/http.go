@@ -144,6 +144,9 @@ func (c HTTPClient) do(ctx context.Context, addr string, httpMethod string, mime
 	req.Header.Set("Content-Type", mimeType)
 	req.Header.Set("Accept", mimeType)
 
+	if who := OnBehalfOfFromCtx(ctx); who != "" {
+		req.Header.Set(OnBehalfOfHeader, who)
+	}
 	req, added := token.AddContextTokenToRequest(ctx, req)
 	if !added {
 		log.Debugw("No token was set on an http client request. Unauthenticated requests may fail", "httpMethod", httpMethod, "addr", addr)
@@ -240,7 +243,11 @@ func NewHTTPRequestHandler(inst *Instance, libMethod string) http.HandlerFunc {
 		}
 
 		if cursor != nil {
-			apiutil.WritePageResponse(w, res, r, apiutil.PageFromRequest(r))
+			page := apiutil.PageFromRequest(r)
+			if lp, ok := apiutil.ListParamsFromCtx(r.Context()); ok {
+				page = lp.Page()
+			}
+			apiutil.WritePageResponse(w, res, r, page)
 			return
 		}
 
@@ -0,0 +1,225 @@
+package lib
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/profile"
+)
+
+// OnBehalfOfHeader is the HTTP header operators set to call a method as
+// another profile. The value is a username or profile ID
+const OnBehalfOfHeader = "On-Behalf-Of"
+
+// ErrOnBehalfOfUnsupported is returned when a method that isn't dispatched is
+// called on behalf of another profile. Only dispatched methods are audited
+var ErrOnBehalfOfUnsupported = errors.New("method can't be called on behalf of another profile")
+
+type onBehalfOfCtxKey struct{}
+
+// impersonatedCtxKey holds the profile a dispatched call acts as, after the
+// caller has been checked for the operator role
+type impersonatedCtxKey struct{}
+
+// WithOnBehalfOf returns a context for calls an operator makes on behalf of
+// another profile, identified by username or profile ID. Calls fail unless the
+// profile making them has the operator role, and every call is recorded in
+// the audit log
+func WithOnBehalfOf(ctx context.Context, who string) context.Context {
+	return context.WithValue(ctx, onBehalfOfCtxKey{}, who)
+}
+
+// OnBehalfOfFromCtx returns the profile a call is made on behalf of, or the
+// empty string for calls made as the caller
+func OnBehalfOfFromCtx(ctx context.Context) string {
+	who, _ := ctx.Value(onBehalfOfCtxKey{}).(string)
+	return who
+}
+
+// AuditRecord describes a method call an operator made on behalf of another
+// profile
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// OperatorID is the profile ID of the operator making the call
+	OperatorID   string `json:"operatorID"`
+	OperatorName string `json:"operatorName"`
+	// AsID is the profile ID the call was made as, empty if it couldn't be
+	// resolved
+	AsID   string `json:"asID,omitempty"`
+	AsName string `json:"asName"`
+	Method string `json:"method"`
+	// ParamsDigest is the hex-encoded sha256 digest of the JSON-encoded method
+	// parameters. Parameters aren't stored, they can include private values
+	ParamsDigest string `json:"paramsDigest"`
+	Origin       string `json:"origin,omitempty"`
+	// Error is set when the call was refused
+	Error string `json:"error,omitempty"`
+}
+
+// impersonate checks the profile making a call has the operator role &
+// resolves the profile the call is made on behalf of, recording the attempt
+// in the audit log. The returned context acts as the resolved profile
+func (inst *Instance) impersonate(ctx context.Context, method string, param interface{}, who string) (context.Context, error) {
+	rec := AuditRecord{
+		Time:         time.Now(),
+		AsName:       who,
+		Method:       method,
+		ParamsDigest: paramsDigest(param),
+		Origin:       token.OriginFromCtx(ctx),
+	}
+
+	as, err := inst.resolveOnBehalfOf(ctx, who, &rec)
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	if auditErr := inst.audit.Append(rec); auditErr != nil {
+		// calls that can't be audited must not run
+		return nil, fmt.Errorf("recording audit log: %w", auditErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Infow("operator call on behalf of profile", "operator", rec.OperatorName, "as", rec.AsName, "method", method)
+	return context.WithValue(ctx, impersonatedCtxKey{}, as), nil
+}
+
+func (inst *Instance) resolveOnBehalfOf(ctx context.Context, who string, rec *AuditRecord) (*profile.Profile, error) {
+	// check the caller as themselves
+	ctx = WithOnBehalfOf(ctx, "")
+	op, err := inst.activeProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rec.OperatorID = op.ID.String()
+	rec.OperatorName = op.Peername
+	if err := inst.CheckOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	as, err := inst.profiles.GetProfile(profile.IDB58DecodeOrEmpty(who))
+	if err != nil {
+		if as, err = profile.ResolveUsername(inst.profiles, who); err != nil {
+			return nil, fmt.Errorf("resolving profile %q to act on behalf of: %w", who, err)
+		}
+	}
+	rec.AsID = as.ID.String()
+	rec.AsName = as.Peername
+	return as, nil
+}
+
+// impersonatedProfile returns the profile a call acts as. ok is false for
+// calls that aren't made on behalf of another profile
+func impersonatedProfile(ctx context.Context) (pro *profile.Profile, ok bool, err error) {
+	if pro, ok := ctx.Value(impersonatedCtxKey{}).(*profile.Profile); ok {
+		return pro, true, nil
+	}
+	if who := OnBehalfOfFromCtx(ctx); who != "" {
+		// calls that bypass dispatch would otherwise run as the operator
+		return nil, false, ErrOnBehalfOfUnsupported
+	}
+	return nil, false, nil
+}
+
+// paramsDigest hashes method parameters for the audit log
+func paramsDigest(param interface{}) string {
+	data, err := json.Marshal(param)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%#v", param))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// auditLog is an append-only record of calls operators make on behalf of
+// other profiles, stored as newline-delimited JSON. A log with an empty
+// filename keeps records in memory
+type auditLog struct {
+	sync.Mutex
+	filename string
+	records  []AuditRecord
+}
+
+func newAuditLog(repoPath string) *auditLog {
+	l := &auditLog{}
+	if repoPath != "" {
+		l.filename = filepath.Join(repoPath, "audit.jsonl")
+	}
+	return l
+}
+
+// Append adds a record to the log
+func (l *auditLog) Append(rec AuditRecord) error {
+	l.Lock()
+	defer l.Unlock()
+	if l.filename == "" {
+		l.records = append(l.records, rec)
+		return nil
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// List returns up to limit records, most recent first. A negative limit
+// returns all records
+func (l *auditLog) List(limit int) ([]AuditRecord, error) {
+	l.Lock()
+	defer l.Unlock()
+	records := l.records
+	if l.filename != "" {
+		var err error
+		if records, err = l.read(); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]AuditRecord, 0, len(records))
+	for i := len(records) - 1; i >= 0 && (limit < 0 || len(res) < limit); i-- {
+		res = append(res, records[i])
+	}
+	return res, nil
+}
+
+func (l *auditLog) read() ([]AuditRecord, error) {
+	f, err := os.Open(l.filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := []AuditRecord{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		rec := AuditRecord{}
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("reading audit log: %w", err)
+		}
+		records = append(records, rec)
+	}
+	return records, sc.Err()
+}
@@ -2,6 +2,7 @@ package lib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http/httptest"
 	"path/filepath"
@@ -95,6 +96,88 @@ func TestTwoActorRegistryIntegration(t *testing.T) {
 	)
 }
 
+func TestPromoteIntegration(t *testing.T) {
+	tr := NewNetworkIntegrationTestRunner(t, "integration_promote")
+	defer tr.Cleanup()
+
+	nasim := tr.InitNasim(t)
+	ref := InitWorldBankDataset(tr.Ctx, t, nasim)
+
+	// a failed push rolls back the rename
+	_, err := NewRemoteMethods(nasim).Promote(tr.Ctx, &PromoteParams{
+		Ref:    ref.Alias(),
+		Next:   "me/wbp_public",
+		Remote: "not_a_remote",
+	})
+	if err == nil {
+		t.Fatal("expected promoting to an unknown remote to fail")
+	}
+	if _, err := nasim.ResolveReference(tr.Ctx, &dsref.Ref{Username: ref.Username, Name: ref.Name}, "local"); err != nil {
+		t.Errorf("expected dataset to keep its original name after rollback: %s", err)
+	}
+
+	res, err := NewRemoteMethods(nasim).Promote(tr.Ctx, &PromoteParams{
+		Ref:  ref.Alias(),
+		Next: "me/wbp_public",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Name != "wbp_public" || !res.Published {
+		t.Errorf("expected promoted dataset to be renamed & published, got name %q published %t", res.Name, res.Published)
+	}
+
+	promoted := dsref.Ref{Username: ref.Username, Name: "wbp_public"}
+	if _, err := tr.RegistryInst.ResolveReference(tr.Ctx, &promoted, "local"); err != nil {
+		t.Errorf("expected registry to have the promoted dataset: %s", err)
+	}
+}
+
+func TestCheckRemoteIntegration(t *testing.T) {
+	tr := NewNetworkIntegrationTestRunner(t, "integration_check_remote")
+	defer tr.Cleanup()
+
+	nasim := tr.InitNasim(t)
+	refstr := InitWorldBankDataset(tr.Ctx, t, nasim).Alias()
+	version := func(body string) *dataset.Dataset {
+		return &dataset.Dataset{BodyPath: "body.csv", BodyBytes: []byte(body)}
+	}
+
+	// the registry has never seen the dataset, nothing to be behind
+	if _, err := nasim.Dataset().Save(tr.Ctx, &SaveParams{Ref: refstr, CheckRemote: true, Dataset: version("a,b,c,true,2\nd,e,f,false,4")}); err != nil {
+		t.Fatalf("saving a dataset the remote has never seen: %s", err)
+	}
+	if _, err := NewRemoteMethods(nasim).Push(tr.Ctx, &PushParams{Ref: refstr, CheckRemote: true}); err != nil {
+		t.Fatalf("pushing a dataset the remote has never seen: %s", err)
+	}
+
+	// dropping the latest version leaves nasim one version behind the registry
+	if _, err := nasim.Dataset().Remove(tr.Ctx, &RemoveParams{Ref: refstr, Revision: &dsref.Rev{Field: "ds", Gen: 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := nasim.Dataset().Save(tr.Ctx, &SaveParams{Ref: refstr, CheckRemote: true, Dataset: version("a,b,c,true,2\nd,e,f,false,5")})
+	if !errors.Is(err, ErrBehindRemote) {
+		t.Errorf("expected saving while behind to fail with ErrBehindRemote, got: %v", err)
+	}
+	_, err = nasim.Dataset().Save(tr.Ctx, &SaveParams{Ref: refstr, CheckRemote: true, Force: true, Dataset: version("a,b,c,true,2\nd,e,f,false,5")})
+	if !errors.Is(err, ErrBehindRemote) {
+		t.Errorf("expected forcing a commit not to skip the remote check, got: %v", err)
+	}
+	_, err = NewRemoteMethods(nasim).Push(tr.Ctx, &PushParams{Ref: refstr, CheckRemote: true})
+	if !errors.Is(err, ErrBehindRemote) {
+		t.Errorf("expected pushing while behind to fail with ErrBehindRemote, got: %v", err)
+	}
+
+	// overriding the check saves & pushes anyway
+	if _, err := nasim.Dataset().Save(tr.Ctx, &SaveParams{Ref: refstr, CheckRemote: true, AllowBehindRemote: true, Dataset: version("a,b,c,true,2\nd,e,f,false,5")}); err != nil {
+		t.Errorf("expected AllowBehindRemote to skip the remote check, got: %s", err)
+	}
+	if _, err := NewRemoteMethods(nasim).Push(tr.Ctx, &PushParams{Ref: refstr, CheckRemote: true, Force: true}); errors.Is(err, ErrBehindRemote) {
+		t.Errorf("expected Force to skip the remote check, got: %s", err)
+	}
+}
+
 func TestAddCheckoutIntegration(t *testing.T) {
 	tr := NewNetworkIntegrationTestRunner(t, "integration_add_checkout")
 	defer tr.Cleanup()
@@ -117,6 +200,98 @@ func TestAddCheckoutIntegration(t *testing.T) {
 	}
 }
 
+func TestCloneIntegration(t *testing.T) {
+	tr := NewNetworkIntegrationTestRunner(t, "integration_clone")
+	defer tr.Cleanup()
+
+	nasim := tr.InitNasim(t)
+	// push each version, pushes only send the head version
+	ref := InitWorldBankDataset(tr.Ctx, t, nasim)
+	PushToRegistry(tr.Ctx, t, nasim, ref.Alias())
+	ref = Commit2WorldBank(tr.Ctx, t, nasim)
+	PushToRegistry(tr.Ctx, t, nasim, ref.Alias())
+
+	hinshun := tr.InitHinshun(t)
+
+	dir := filepath.Join(tr.hinshunRepo.RootPath, "wbp")
+	res, err := hinshun.WithSource("network").Dataset().Clone(tr.Ctx, &CloneParams{
+		Ref:    ref.Alias(),
+		Depth:  0,
+		Dir:    dir,
+		Follow: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Versions != 2 {
+		t.Errorf("expected clone to pull 2 versions, got %d", res.Versions)
+	}
+	if res.Dir != dir || !res.Following {
+		t.Errorf("expected clone to check out & follow, got dir %q following %t", res.Dir, res.Following)
+	}
+
+	follows, err := hinshun.Dataset().Follow(tr.Ctx, &FollowParams{List: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 1 || follows[0].Ref != ref.Alias() {
+		t.Errorf("expected hinshun to follow %s, got: %v", ref.Alias(), follows)
+	}
+	if follows, err = hinshun.Dataset().Follow(tr.Ctx, &FollowParams{Ref: ref.Alias(), Unfollow: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 0 {
+		t.Errorf("expected unfollow to remove follow, got: %v", follows)
+	}
+}
+
+func TestShallowPullIntegration(t *testing.T) {
+	tr := NewNetworkIntegrationTestRunner(t, "integration_shallow_pull")
+	defer tr.Cleanup()
+
+	nasim := tr.InitNasim(t)
+	// push each version, pushes only send the head version
+	ref := InitWorldBankDataset(tr.Ctx, t, nasim)
+	PushToRegistry(tr.Ctx, t, nasim, ref.Alias())
+	ref = Commit2WorldBank(tr.Ctx, t, nasim)
+	PushToRegistry(tr.Ctx, t, nasim, ref.Alias())
+
+	hinshun := tr.InitHinshun(t)
+	m := hinshun.WithSource("network").Dataset()
+	if _, err := m.Pull(tr.Ctx, &PullParams{Ref: ref.Alias(), Depth: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	history := func() []dsref.VersionInfo {
+		items, err := hinshun.Log().History(tr.Ctx, &HistoryParams{Ref: ref.Alias()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return items
+	}
+	items := history()
+	if len(items) != 2 || items[0].Foreign || !items[1].Foreign {
+		t.Errorf("expected shallow pull to store only the latest version, got: %#v", items)
+	}
+	resolved := dsref.Ref{Username: ref.Username, Name: ref.Name}
+	if _, err := hinshun.ResolveReference(tr.Ctx, &resolved, "local"); err != nil {
+		t.Fatal(err)
+	}
+	if s := hinshun.datasetSettings(resolved.InitID); s.Shallow != 1 {
+		t.Errorf("expected dataset to be marked shallow at depth 1, got: %d", s.Shallow)
+	}
+
+	if _, err := m.Pull(tr.Ctx, &PullParams{Ref: ref.Alias(), Deepen: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if items := history(); items[1].Foreign {
+		t.Errorf("expected deepen to store the previous version")
+	}
+	if s := hinshun.datasetSettings(resolved.InitID); s.Shallow != 0 {
+		t.Errorf("expected deepened dataset to no longer be shallow, got: %d", s.Shallow)
+	}
+}
+
 func TestReferencePulling(t *testing.T) {
 	tr := NewNetworkIntegrationTestRunner(t, "integration_reference_pulling")
 	defer tr.Cleanup()
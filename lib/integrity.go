@@ -0,0 +1,67 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dscache"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/repo/backup"
+)
+
+// repoBackups returns the number of logbook & dscache backups to keep
+func repoBackups(cfg *config.Config) int {
+	if cfg == nil || cfg.Repo == nil || cfg.Repo.Backups == 0 {
+		return backup.DefaultCount
+	}
+	if cfg.Repo.Backups < 0 {
+		return 0
+	}
+	return cfg.Repo.Backups
+}
+
+// checkIntegrity validates the logbook & dscache files before they're loaded,
+// restoring the latest intact backup of any file a crash left partially
+// written. The dscache is rebuilt if no backup is intact, a logbook without
+// an intact backup is an error
+func (inst *Instance) checkIntegrity(ctx context.Context, pro *profile.Profile) error {
+	n := repoBackups(inst.cfg)
+
+	bookFile := filepath.Join(inst.repoPath, "logbook.qfb")
+	chk, err := backup.Recover(bookFile, n, func(data []byte) error {
+		return logbook.ValidateFile(ctx, pro.PrivKey, data)
+	})
+	if err != nil {
+		return fmt.Errorf("checking logbook: %w", err)
+	}
+	if chk.Status == backup.StatusUnrecoverable {
+		// put the logbook back, starting with an empty logbook would lose
+		// dataset histories
+		if err := os.Rename(chk.Moved, chk.Filename); err != nil {
+			log.Debugw("restoring corrupt logbook", "err", err)
+		}
+		return fmt.Errorf("logbook file %q is corrupt & no intact backup was found: %s", bookFile, chk.Error)
+	}
+	inst.reportIntegrity(chk)
+
+	cacheFile := filepath.Join(inst.repoPath, "dscache.qfb")
+	if chk, err = backup.Recover(cacheFile, n, dscache.ValidateFile); err != nil {
+		return fmt.Errorf("checking dscache: %w", err)
+	}
+	inst.reportIntegrity(chk)
+	return nil
+}
+
+func (inst *Instance) reportIntegrity(chk backup.Check) {
+	switch chk.Status {
+	case backup.StatusRecovered:
+		log.Warnw("restored corrupt file from backup", "file", chk.Filename, "backup", chk.Backup, "corrupt", chk.Moved, "err", chk.Error)
+	case backup.StatusUnrecoverable:
+		log.Warnw("removed corrupt file without an intact backup", "file", chk.Filename, "corrupt", chk.Moved, "err", chk.Error)
+	}
+	inst.integrity = append(inst.integrity, chk)
+}
@@ -0,0 +1,69 @@
+package lib
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qri-io/qri/repo/backup"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inst, cleanup := NewMemTestInstance(ctx, t)
+	defer cleanup()
+
+	// each logbook write keeps a backup of the previous version
+	if _, err := inst.logbook.WriteDatasetInit(ctx, "integrity_test"); err != nil {
+		t.Fatal(err)
+	}
+	bookFile := filepath.Join(inst.repoPath, "logbook.qfb")
+	if _, err := os.Stat(backup.Filename(bookFile, 1)); err != nil {
+		t.Fatalf("expected a logbook backup: %s", err)
+	}
+
+	// simulate a crash partway through writing the logbook & dscache
+	data, err := ioutil.ReadFile(bookFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(bookFile, data[:len(data)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+	cacheFile := filepath.Join(inst.repoPath, "dscache.qfb")
+	if err := ioutil.WriteFile(cacheFile, []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := NewInstance(ctx, inst.repoPath)
+	if err != nil {
+		t.Fatalf("expected instance to start with a restored logbook: %s", err)
+	}
+	if len(restarted.integrity) != 2 {
+		t.Fatalf("expected 2 integrity checks, got: %#v", restarted.integrity)
+	}
+	if chk := restarted.integrity[0]; chk.Status != backup.StatusRecovered || chk.Backup != backup.Filename(bookFile, 1) {
+		t.Errorf("expected logbook to be restored from the first backup, got: %#v", chk)
+	}
+	if chk := restarted.integrity[1]; chk.Status != backup.StatusUnrecoverable || chk.Moved != cacheFile+".corrupt" {
+		t.Errorf("expected corrupt dscache without backups to be moved aside, got: %#v", chk)
+	}
+
+	// a corrupt logbook without an intact backup stops the instance from
+	// starting, keeping the file in place
+	for i := 1; i <= backup.DefaultCount; i++ {
+		os.Remove(backup.Filename(bookFile, i))
+	}
+	if err := ioutil.WriteFile(bookFile, data[:len(data)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewInstance(ctx, inst.repoPath); err == nil {
+		t.Error("expected a corrupt logbook without backups to error")
+	}
+	if _, err := os.Stat(bookFile); err != nil {
+		t.Errorf("expected corrupt logbook to be left in place: %s", err)
+	}
+}
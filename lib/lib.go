@@ -23,6 +23,7 @@ import (
 	"github.com/qri-io/qfs/qipfs"
 	"github.com/qri-io/qri/auth/key"
 	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/config/migrate"
@@ -35,12 +36,17 @@ import (
 	"github.com/qri-io/qri/fsi/watchfs"
 	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/p2p"
+	"github.com/qri-io/qri/pinning"
 	"github.com/qri-io/qri/profile"
 	"github.com/qri-io/qri/registry/regclient"
 	"github.com/qri-io/qri/remote"
 	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/backup"
 	"github.com/qri-io/qri/repo/buildrepo"
+	"github.com/qri-io/qri/sink"
 	"github.com/qri-io/qri/stats"
+	"github.com/qri-io/qri/transform/run"
+	"github.com/qri-io/qri/warehouse"
 )
 
 var (
@@ -81,6 +87,10 @@ type InstanceOptions struct {
 	profiles   profile.Store
 	bus        event.Bus
 	logAll     bool
+	// name of a configuration profile to merge over the repo configuration
+	configProfile string
+	// context supplying defaults for the repo
+	repoContext *RepoContext
 
 	remoteMockClient bool
 	// use OptRemoteOptions to set this
@@ -105,6 +115,16 @@ func OptConfig(cfg *config.Config) Option {
 	}
 }
 
+// OptConfigProfile merges a named configuration profile over the repo
+// configuration. Profiles are read from config.[name].yaml in the repo
+// directory. An empty name uses the base configuration
+func OptConfigProfile(name string) Option {
+	return func(o *InstanceOptions) error {
+		o.configProfile = name
+		return nil
+	}
+}
+
 // OptSetIPFSPath sets the directory to read IPFS from.
 // Passing the empty string adjusts qri to use the go-ipfs default:
 // first checking the IPFS_PATH env variable, then falling back to $HOME/.ipfs
@@ -169,7 +189,9 @@ func OptStdIOStreams() Option {
 	}
 }
 
-// OptSetOpenFileTimeout sets a timeout duration for opening files
+// OptSetOpenFileTimeout sets the default timeout duration for opening files.
+// Filesystems with a timeout in the Repo.OpenTimeouts config use that value
+// instead. Pass dsfs.NoTimeout to disable the default timeout
 func OptSetOpenFileTimeout(d time.Duration) Option {
 	return func(_ *InstanceOptions) error {
 		dsfs.OpenFileTimeoutDuration = d
@@ -371,7 +393,30 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		// so qri needs to be set up
 		err = fmt.Errorf("no qri repo found, please run `qri setup`")
 		return
-	} else if err = cfg.Validate(); err != nil {
+	}
+
+	if rc := o.repoContext; rc != nil {
+		if rc.RepoPath != "" && filepath.Clean(rc.RepoPath) != filepath.Clean(repoPath) {
+			return nil, fmt.Errorf("context %q uses repo %q, not %q", rc.Name, rc.RepoPath, repoPath)
+		}
+		if o.configProfile == "" {
+			o.configProfile = rc.ConfigProfile
+		}
+		log.Debugf("using context %q", rc.Name)
+	}
+
+	if o.configProfile != "" {
+		overlay, overlayErr := config.ReadOverlay(repoPath, o.configProfile)
+		if overlayErr != nil {
+			return nil, overlayErr
+		}
+		if cfg, err = cfg.WithOverlay(o.configProfile, overlay); err != nil {
+			return
+		}
+		log.Debugf("using config profile %q", o.configProfile)
+	}
+
+	if err = cfg.Validate(); err != nil {
 		return
 	}
 
@@ -385,8 +430,9 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		cancel: cancel,
 		doneCh: make(chan struct{}),
 
-		repoPath: repoPath,
-		cfg:      cfg,
+		repoPath:    repoPath,
+		repoContext: o.repoContext,
+		cfg:         cfg,
 
 		qfs:      o.qfs,
 		repo:     o.repo,
@@ -398,6 +444,19 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		profiles: o.profiles,
 		bus:      o.bus,
 		appCtx:   ctx,
+		started:  time.Now(),
+
+		bodyFetches:  newBodyFetchStore(repoPath),
+		follows:      newFollowStore(repoPath),
+		trash:        newTrashStore(repoPath),
+		freshness:    newFreshnessStore(repoPath),
+		dsSettings:   newDatasetSettingsStore(repoPath),
+		heads:        newHeadStore(repoPath),
+		contentIndex: newContentIndex(repoPath),
+		runLogs:      newRunLogStore(repoPath),
+		sessions:     token.NewSessions(repoPath),
+		embedTokens:  newEmbedTokenStore(repoPath),
+		audit:        newAuditLog(repoPath),
 	}
 	qri = inst
 
@@ -433,6 +492,12 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 			if err != nil {
 				return nil, err
 			}
+			// mirror events from the instance we're connected to on a local bus,
+			// so subscribers like progress bars work over RPC
+			if inst.bus == nil {
+				inst.bus = newEventBus(ctx)
+			}
+			inst.connectEventBridge(ctx)
 
 			go inst.waitForAllDone()
 			return qri, err
@@ -447,6 +512,24 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		inst.bus.SubscribeTypes(o.eventHandler, o.events...)
 	}
 
+	if cfg.Integrations != nil && len(cfg.Integrations.Sinks) > 0 {
+		if inst.sinks, err = sink.New(cfg.Integrations); err != nil {
+			return nil, fmt.Errorf("initializing sinks: %w", err)
+		}
+		inst.sinks.Subscribe(inst.bus)
+		inst.releasers.Add(1)
+		go func() {
+			inst.sinks.Run(ctx)
+			inst.releasers.Done()
+		}()
+	}
+
+	inst.releasers.Add(1)
+	go func() {
+		inst.sessions.Run(ctx)
+		inst.releasers.Done()
+	}()
+
 	if inst.qfs == nil {
 		inst.qfs, err = buildrepo.NewFilesystem(ctx, cfg)
 		if err != nil {
@@ -461,6 +544,36 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		}()
 	}
 
+	if cfg.Integrations != nil && len(cfg.Integrations.Warehouses) > 0 {
+		filename := ""
+		if inst.repoPath != "" {
+			filename = filepath.Join(inst.repoPath, "warehouse_sync.json")
+		}
+		if inst.warehouses, err = warehouse.New(cfg.Integrations, base.NewLocalDatasetLoader(inst.qfs), filename); err != nil {
+			return nil, fmt.Errorf("initializing warehouses: %w", err)
+		}
+		inst.warehouses.Subscribe(inst.bus)
+		inst.releasers.Add(1)
+		go func() {
+			inst.warehouses.Run(ctx)
+			inst.releasers.Done()
+		}()
+	}
+	if cfg.Integrations != nil && len(cfg.Integrations.PinningServices) > 0 {
+		filename := ""
+		if inst.repoPath != "" {
+			filename = filepath.Join(inst.repoPath, "pin_status.json")
+		}
+		if inst.pins, err = pinning.New(cfg.Integrations, filename); err != nil {
+			return nil, fmt.Errorf("initializing pinning services: %w", err)
+		}
+	}
+	inst.subscribeContentIndex()
+
+	if err = inst.startBodyVerifier(ctx, inst.repoPath); err != nil {
+		return nil, fmt.Errorf("initializing body verifier: %w", err)
+	}
+
 	if inst.keystore == nil {
 		inst.keystore, err = key.NewStore(cfg)
 		if err != nil {
@@ -477,6 +590,12 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 
 	pro := inst.profiles.Owner()
 
+	if inst.logbook == nil && inst.repoPath != "" {
+		if err = inst.checkIntegrity(ctx, pro); err != nil {
+			return nil, err
+		}
+	}
+
 	if inst.logbook == nil {
 		inst.logbook, err = newLogbook(inst.qfs, cfg, inst.bus, pro, inst.repoPath)
 		if err != nil {
@@ -485,7 +604,7 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 	}
 
 	if inst.registry == nil {
-		inst.registry = newRegClient(ctx, cfg)
+		inst.registry = newRegClient(ctx, cfg, pro)
 	}
 
 	if inst.dscache == nil {
@@ -494,6 +613,7 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 			log.Error("initalizing dscache:", err.Error())
 			return nil, fmt.Errorf("newDsache: %w", err)
 		}
+		inst.dscache.Backups = repoBackups(cfg)
 	}
 
 	if inst.repo == nil {
@@ -521,6 +641,7 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 			return nil, err
 		}
 	}
+	inst.stats.SetBinning(binningConfig(cfg))
 
 	if inst.node == nil {
 		var localResolver dsref.Resolver
@@ -532,6 +653,15 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 			log.Error("intializing p2p:", err.Error())
 			return
 		}
+		// never trim connections to configured remotes
+		if cfg.Remotes != nil {
+			for _, addr := range *cfg.Remotes {
+				inst.node.ProtectPeers(addr)
+			}
+		}
+	}
+	if inst.node != nil {
+		inst.node.SetFollowsFunc(inst.followAliases)
 	}
 
 	// Check if this is coming from a test, which is requesting a MockRemoteClient.
@@ -551,7 +681,7 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 		inst.node.LocalStreams = inst.streams
 
 		if _, e := inst.node.IPFSCoreAPI(); e == nil {
-			if inst.remoteClient, err = remote.NewClient(ctx, inst.node, inst.bus); err != nil {
+			if inst.remoteClient, err = remote.NewClient(ctx, inst.node, inst.bus, inst.withCredentials); err != nil {
 				log.Error("initializing remote client:", err.Error())
 				return
 			}
@@ -562,6 +692,13 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 			}()
 		}
 
+		if err = inst.startPushQueue(ctx, inst.repoPath); err != nil {
+			log.Error("initializing push queue:", err.Error())
+			return
+		}
+		inst.startFollowing(ctx)
+		inst.startFreshnessChecks(ctx)
+
 		if cfg.Remote != nil && cfg.Remote.Enabled {
 			if o.remoteOptsFuncs == nil {
 				o.remoteOptsFuncs = []remote.OptionsFunc{}
@@ -572,7 +709,7 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 				return nil, resolverErr
 			}
 
-			if inst.remote, err = remote.NewRemote(inst.node, cfg.Remote, localResolver, o.remoteOptsFuncs...); err != nil {
+			if inst.remote, err = remote.NewRemote(inst.node, cfg.Remote, localResolver, append(inst.withWebhooks(inst.withUsageStats(inst.withPullThrough(o.remoteOptsFuncs))), remote.OptHeads(inst.heads))...); err != nil {
 				log.Error("intializing remote:", err.Error())
 				return
 			}
@@ -595,6 +732,50 @@ func NewInstance(ctx context.Context, repoPath string, opts ...Option) (qri *Ins
 	return
 }
 
+// withPullThrough adds pull-through cache options to a set of remote options
+// when the remote is configured as a pull-through cache. The remote client
+// changes when an instance goes online, so these options are never stored
+func (inst *Instance) withPullThrough(opts []remote.OptionsFunc) []remote.OptionsFunc {
+	cfg := inst.cfg
+	if cfg.Remote == nil || !cfg.Remote.PullThrough {
+		return opts
+	}
+
+	// origin may be a named remote, an address, or empty to use the registry
+	origin := cfg.Remote.PullThroughOrigin
+	if addr, err := remote.Address(cfg, origin); err == nil {
+		origin = addr
+	}
+
+	indexPath := ""
+	if inst.repoPath != "" {
+		indexPath = filepath.Join(inst.repoPath, "remote_cache.json")
+	}
+
+	return append(append([]remote.OptionsFunc{}, opts...), remote.OptPullThrough(inst.remoteClient, origin, indexPath))
+}
+
+// withUsageStats adds the usage stats file to a set of remote options when
+// the remote is configured to record usage stats
+func (inst *Instance) withUsageStats(opts []remote.OptionsFunc) []remote.OptionsFunc {
+	if inst.cfg.Remote == nil || !inst.cfg.Remote.UsageStats || inst.repoPath == "" {
+		return opts
+	}
+	return append(append([]remote.OptionsFunc{}, opts...), remote.OptUsageStats(filepath.Join(inst.repoPath, "remote_usage.json")))
+}
+
+// withWebhooks adds a post-receive hook notifying each configured webhook
+func (inst *Instance) withWebhooks(opts []remote.OptionsFunc) []remote.OptionsFunc {
+	if inst.cfg.Remote == nil || len(inst.cfg.Remote.Webhooks) == 0 {
+		return opts
+	}
+	res := append([]remote.OptionsFunc{}, opts...)
+	for _, u := range inst.cfg.Remote.Webhooks {
+		res = append(res, remote.OptPostReceiveHook("webhook "+u, remote.WebhookHook(remote.HookStagePush, u)))
+	}
+	return res
+}
+
 // TODO (b5): this is a repo layout assertion, move to repo package?
 func loadRepoConfig(repoPath string) (*config.Config, error) {
 	path := filepath.Join(repoPath, "config.yaml")
@@ -606,7 +787,7 @@ func loadRepoConfig(repoPath string) (*config.Config, error) {
 	return config.ReadFromFile(path)
 }
 
-func newRegClient(ctx context.Context, cfg *config.Config) (rc *regclient.Client) {
+func newRegClient(ctx context.Context, cfg *config.Config, pro *profile.Profile) (rc *regclient.Client) {
 	if cfg.Registry != nil {
 		switch cfg.Registry.Location {
 		case "":
@@ -614,6 +795,7 @@ func newRegClient(ctx context.Context, cfg *config.Config) (rc *regclient.Client
 		default:
 			return regclient.NewClient(&regclient.Config{
 				Location: cfg.Registry.Location,
+				Token:    credentialToken(cfg, pro, "registry"),
 			})
 		}
 	}
@@ -623,7 +805,12 @@ func newRegClient(ctx context.Context, cfg *config.Config) (rc *regclient.Client
 
 func newLogbook(fs qfs.Filesystem, cfg *config.Config, bus event.Bus, pro *profile.Profile, repoPath string) (book *logbook.Book, err error) {
 	logbookPath := filepath.Join(repoPath, "logbook.qfb")
-	return logbook.NewJournal(pro.PrivKey, pro.Peername, bus, fs, logbookPath)
+	if book, err = logbook.NewJournal(pro.PrivKey, pro.Peername, bus, fs, logbookPath); err != nil {
+		return nil, err
+	}
+	book.SetDatasetKeys(logbook.NewDatasetKeys(filepath.Join(repoPath, "logbook_keys.json")))
+	book.SetBackups(repoBackups(cfg))
+	return book, nil
 }
 
 func newDscache(ctx context.Context, fs qfs.Filesystem, bus event.Bus, username, repoPath string) (*dscache.Dscache, error) {
@@ -693,6 +880,19 @@ func NewInstanceFromConfigAndNodeAndBus(ctx context.Context, cfg *config.Config,
 		logbook:  r.Logbook(),
 		profiles: r.Profiles(),
 		appCtx:   ctx,
+		started:  time.Now(),
+
+		bodyFetches:  newBodyFetchStore(""),
+		follows:      newFollowStore(""),
+		trash:        newTrashStore(""),
+		freshness:    newFreshnessStore(""),
+		dsSettings:   newDatasetSettingsStore(""),
+		heads:        newHeadStore(""),
+		contentIndex: newContentIndex(""),
+		runLogs:      newRunLogStore(""),
+		sessions:     token.NewSessions(""),
+		embedTokens:  newEmbedTokenStore(""),
+		audit:        newAuditLog(""),
 	}
 	inst.RegisterMethods()
 
@@ -704,9 +904,12 @@ func NewInstanceFromConfigAndNodeAndBus(ctx context.Context, cfg *config.Config,
 		inst.fsi = fsint
 		inst.qfs = r.Filesystem()
 	}
+	if inst.bus != nil {
+		inst.subscribeContentIndex()
+	}
 
 	var err error
-	inst.remoteClient, err = remote.NewClient(ctx, node, inst.bus)
+	inst.remoteClient, err = remote.NewClient(ctx, node, inst.bus, inst.withCredentials)
 	if err != nil {
 		cancel()
 		panic(err)
@@ -727,8 +930,9 @@ func NewInstanceFromConfigAndNodeAndBus(ctx context.Context, cfg *config.Config,
 // contain qri business logic. Think of instance as the "core" of the qri
 // ecosystem. Create an Instance pointer with NewInstance
 type Instance struct {
-	repoPath string
-	cfg      *config.Config
+	repoPath    string
+	repoContext *RepoContext
+	cfg         *config.Config
 
 	regMethods *regMethodSet
 
@@ -746,10 +950,36 @@ type Instance struct {
 	bus          event.Bus
 	watcher      *watchfs.FilesysWatcher
 	appCtx       context.Context
+	started      time.Time
 
 	profiles profile.Store
 	keystore key.Store
 
+	oauthLk  sync.Mutex
+	oauth    *token.Exchange
+	sessions *token.Sessions
+	// embedTokens tracks issued embed tokens & their usage
+	embedTokens *embedTokenStore
+	// audit records calls operators make on behalf of other profiles
+	audit *auditLog
+
+	pushQueue    *remote.PushQueue
+	sinks        *sink.Service
+	warehouses   *warehouse.Service
+	pins         *pinning.Service
+	bodyVerifier *dsfs.BodyVerifier
+
+	bodyFetches  *bodyFetchStore
+	follows      *followStore
+	trash        *trashStore
+	freshness    *freshnessStore
+	dsSettings   *datasetSettingsStore
+	heads        *remote.HeadStore
+	contentIndex *contentIndex
+	runLogs      *run.LogStore
+	// integrity holds the results of checking repo files at startup
+	integrity []backup.Check
+
 	remoteOptsFuncs []remote.OptionsFunc
 
 	http *HTTPClient
@@ -780,7 +1010,7 @@ func (inst *Instance) Connect(ctx context.Context) (err error) {
 	// `Connect` function. The instance is responsible for cleaning up the
 	// remoteClient, since it cannot rely on this context to cancel at the same
 	// time as the context of the instance does
-	if inst.remoteClient, err = remote.NewClient(ctx, inst.node, inst.bus); err != nil {
+	if inst.remoteClient, err = remote.NewClient(ctx, inst.node, inst.bus, inst.withCredentials); err != nil {
 		log.Debugf("remote.NewClient error=%q", err)
 		return
 	}
@@ -795,7 +1025,7 @@ func (inst *Instance) Connect(ctx context.Context) (err error) {
 		if err != nil {
 			return err
 		}
-		if inst.remote, err = remote.NewRemote(inst.node, inst.cfg.Remote, localResolver, inst.remoteOptsFuncs...); err != nil {
+		if inst.remote, err = remote.NewRemote(inst.node, inst.cfg.Remote, localResolver, inst.withUsageStats(inst.withPullThrough(inst.remoteOptsFuncs))...); err != nil {
 			log.Debugf("remote.NewRemote error=%q", err)
 			return err
 		}
@@ -813,6 +1043,16 @@ func (inst *Instance) Access() AccessMethods {
 	return AccessMethods{d: inst}
 }
 
+// Admin returns the AdminMethods that Instance has registered
+func (inst *Instance) Admin() AdminMethods {
+	return AdminMethods{d: inst}
+}
+
+// Automation returns the AutomationMethods that Instance has registered
+func (inst *Instance) Automation() AutomationMethods {
+	return AutomationMethods{d: inst}
+}
+
 // Config returns the ConfigMethods that Instance has registered
 func (inst *Instance) Config() ConfigMethods {
 	return ConfigMethods{d: inst}
@@ -922,7 +1162,15 @@ func (inst *Instance) FSI() *fsi.FSI {
 
 // ChangeConfig implements the ConfigSetter interface
 func (inst *Instance) ChangeConfig(cfg *config.Config) (err error) {
-	cfg = cfg.WithPrivateValues(inst.cfg)
+	return inst.saveConfig(cfg.WithPrivateValues(inst.cfg))
+}
+
+// saveConfig writes a config to disk & sets it as the instance config. Unlike
+// ChangeConfig, private values are taken from cfg
+func (inst *Instance) saveConfig(cfg *config.Config) (err error) {
+	if name := inst.cfg.Overlay(); name != "" {
+		return fmt.Errorf("cannot save configuration changes while config profile %q is active. edit %s instead", name, config.OverlayPath(inst.repoPath, name))
+	}
 
 	if path := inst.cfg.Path(); path != "" {
 		if err = cfg.WriteToFile(path); err != nil {
@@ -963,6 +1211,24 @@ func (inst *Instance) RepoPath() string {
 	return inst.repoPath
 }
 
+// RepoContext returns the context the instance was created with, nil if the
+// instance doesn't use a context
+func (inst *Instance) RepoContext() *RepoContext {
+	if inst == nil {
+		return nil
+	}
+	return inst.repoContext
+}
+
+// remoteAddress resolves the address of a remote by name. An empty name uses
+// the remote of the instance context, falling back to the registry
+func (inst *Instance) remoteAddress(name string) (string, error) {
+	if name == "" && inst.repoContext != nil {
+		name = inst.repoContext.Remote
+	}
+	return remote.Address(inst.GetConfig(), name)
+}
+
 // Dscache returns the dscache that the instance has
 func (inst *Instance) Dscache() *dscache.Dscache {
 	if inst == nil {
@@ -1010,6 +1276,10 @@ func (inst *Instance) activeProfile(ctx context.Context) (pro *profile.Profile,
 		return nil, fmt.Errorf("no instance")
 	}
 
+	if pro, ok, err := impersonatedProfile(ctx); ok || err != nil {
+		return pro, err
+	}
+
 	if tokenString := token.FromCtx(ctx); tokenString != "" {
 		tok, err := token.ParseAuthToken(tokenString, inst.keystore)
 		if err != nil {
@@ -1017,6 +1287,11 @@ func (inst *Instance) activeProfile(ctx context.Context) (pro *profile.Profile,
 		}
 
 		if claims, ok := tok.Claims.(*token.Claims); ok {
+			if inst.sessions != nil {
+				if err := inst.sessions.Track(tok, claims.ProfileID, token.OriginFromCtx(ctx)); err != nil {
+					return nil, err
+				}
+			}
 			// TODO(b5): at this point we have a valid signature of a profileID string
 			// but no proof that this profile is owned by the key that signed the
 			// token. We either need ProfileID == KeyID, or we need a UCAN. we need to
@@ -1031,7 +1306,20 @@ func (inst *Instance) activeProfile(ctx context.Context) (pro *profile.Profile,
 	}
 
 	if inst.profiles != nil {
-		return inst.profiles.Owner(), nil
+		owner := inst.profiles.Owner()
+		// requests that arrived over HTTP carry an origin. once the owner signs
+		// out everywhere they can no longer act as the owner without a token.
+		// embed token reads are checked when the token is used
+		if inst.sessions != nil && owner != nil && token.OriginFromCtx(ctx) != "" && embedReadFromCtx(ctx) == "" {
+			required, err := inst.sessions.TokenRequired(owner.ID.String())
+			if err != nil {
+				return nil, err
+			}
+			if required {
+				return nil, token.ErrTokenRequired
+			}
+		}
+		return owner, nil
 	}
 
 	if pro == nil {
@@ -11,6 +11,7 @@ import (
 	"github.com/qri-io/qri/dsref"
 	qerr "github.com/qri-io/qri/errors"
 	"github.com/qri-io/qri/fsi"
+	"github.com/qri-io/qri/logbook"
 )
 
 // LoadDataset fetches, dereferences and opens a dataset from a reference
@@ -65,7 +66,9 @@ func (inst *Instance) loadLocalDataset(ctx context.Context, ref dsref.Ref) (*dat
 	} else {
 		// Load from dsfs
 		if ds, err = dsfs.LoadDataset(ctx, inst.qfs, ref.Path); err != nil {
-			return nil, err
+			if ds, err = inst.fetchBody(ctx, ref, err); err != nil {
+				return nil, err
+			}
 		}
 	}
 	// Set transient info on the returned dataset
@@ -87,15 +90,21 @@ func (inst *Instance) NewParseResolveLoadFunc(remote string) (dsref.ParseResolve
 	if err != nil {
 		return nil, err
 	}
-	return NewParseResolveLoadFunc(inst.cfg.Profile.Peername, resolver, inst), nil
+	return newParseResolveLoadFunc(inst.cfg.Profile.Peername, resolver, inst, inst.logbook), nil
 }
 
 // NewParseResolveLoadFunc composes a username, resolver, and loader into a
 // higher-order function that converts strings to full datasets
 // pass the empty string as a username to disable the "me" keyword in references
 func NewParseResolveLoadFunc(username string, resolver dsref.Resolver, loader dsref.Loader) dsref.ParseResolveLoad {
+	return newParseResolveLoadFunc(username, resolver, loader, nil)
+}
+
+// newParseResolveLoadFunc extends NewParseResolveLoadFunc with a logbook,
+// enabling references that select a version by time
+func newParseResolveLoadFunc(username string, resolver dsref.Resolver, loader dsref.Loader, book *logbook.Book) dsref.ParseResolveLoad {
 	return func(ctx context.Context, refStr string) (*dataset.Dataset, error) {
-		ref, err := dsref.Parse(refStr)
+		ref, at, err := dsref.ParseAtTime(refStr)
 		if err != nil {
 			return nil, err
 		}
@@ -117,6 +126,12 @@ Replace "me" with your username for the reference:
 			return nil, err
 		}
 
+		if !at.IsZero() {
+			if err := resolvePathAtTime(ctx, book, &ref, at); err != nil {
+				return nil, err
+			}
+		}
+
 		if ref.Path == "" {
 			err = qerr.New(dsref.ErrNoHistory, fmt.Sprintf("can't load dataset %q, it has no saved versions", ref.Human()))
 			return nil, err
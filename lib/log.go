@@ -2,6 +2,7 @@ package lib
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 
@@ -27,10 +28,17 @@ func (m LogMethods) Name() string {
 // Attributes defines attributes for each method
 func (m LogMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
-		"history":        {AEHistory, "POST"},
-		"entries":        {AEEntries, "POST"},
-		"rawlogbook":     {denyRPC, ""},
-		"logbooksummary": {denyRPC, ""},
+		"history":        {endpoint: AEHistory, verb: "POST", readOnly: true},
+		"entries":        {endpoint: AEEntries, verb: "POST", readOnly: true},
+		"graph":          {endpoint: AELogGraph, verb: "POST", readOnly: true},
+		"components":     {endpoint: AELogComponents, verb: "POST", readOnly: true},
+		"rawlogbook":     {endpoint: denyRPC, verb: ""},
+		"logbooksummary": {endpoint: denyRPC, verb: ""},
+		"metrics":        {endpoint: AEMetrics, verb: "POST", operator: true},
+		// dataset keys decrypt logs & aren't served over HTTP
+		"key":         {endpoint: denyRPC, verb: ""},
+		"generatekey": {endpoint: denyRPC, verb: ""},
+		"setkey":      {endpoint: denyRPC, verb: ""},
 	}
 }
 
@@ -118,11 +126,15 @@ func (p *RefListParams) UnmarshalFromRequest(r *http.Request) error {
 		return err
 	}
 
-	if i := util.ReqParamInt(r, "offset", 0); i != 0 {
-		params.Offset = i
-	}
-	if i := util.ReqParamInt(r, "limit", 0); i != 0 {
-		params.Limit = i
+	if lp, ok := util.ListParamsFromCtx(r.Context()); ok {
+		params.Offset, params.Limit = lp.Offset, lp.Limit
+	} else {
+		if i := util.ReqParamInt(r, "offset", 0); i != 0 {
+			params.Offset = i
+		}
+		if i := util.ReqParamInt(r, "limit", 0); i != 0 {
+			params.Limit = i
+		}
 	}
 
 	*p = params
@@ -141,6 +153,34 @@ func (m LogMethods) Entries(ctx context.Context, p *RefListParams) ([]LogEntry,
 	return nil, dispatchReturnError(got, err)
 }
 
+// LogGraphEntry is one operation in the full history of a dataset, including
+// runs, pushes, and deleted versions
+type LogGraphEntry = logbook.GraphEntry
+
+// Graph lists every operation on a dataset branch, newest first. Unlike
+// History, Graph keeps removed & amended versions as tombstones, and includes
+// transform runs, publishes, unpublishes, and deletes
+func (m LogMethods) Graph(ctx context.Context, p *RefListParams) ([]LogGraphEntry, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "graph"), p)
+	if res, ok := got.([]LogGraphEntry); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// VersionComponents is a dataset version & the components it changed
+type VersionComponents = base.VersionComponents
+
+// Components lists which components (meta, structure, body, readme,
+// transform, viz) each version of a local dataset changed, newest first
+func (m LogMethods) Components(ctx context.Context, p *RefListParams) ([]VersionComponents, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "components"), p)
+	if res, ok := got.([]VersionComponents); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
 // RawLogbookParams enapsulates parameters for the RawLogbook methods
 type RawLogbookParams struct {
 	// no options yet
@@ -167,6 +207,81 @@ func (m LogMethods) LogbookSummary(ctx context.Context, p *struct{}) (*string, e
 	return nil, dispatchReturnError(got, err)
 }
 
+// LogbookMetricsParams enapsulates parameters for the Metrics method
+type LogbookMetricsParams struct {
+	// no options yet
+}
+
+// Metrics reports the size of each dataset log & how quickly the logbook is
+// growing, for finding logs that need compaction
+func (m LogMethods) Metrics(ctx context.Context, p *LogbookMetricsParams) (*logbook.Metrics, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "metrics"), p)
+	if res, ok := got.(*logbook.Metrics); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// LogKeyParams defines parameters for the Key & GenerateKey methods
+type LogKeyParams struct {
+	Ref string `json:"ref"`
+}
+
+// LogKey is a base64-encoded dataset log encryption key
+type LogKey struct {
+	Ref string `json:"ref"`
+	Key string `json:"key"`
+}
+
+// Key returns the key used to encrypt a dataset's log when it's pushed to a
+// remote. Share the key with collaborators to let them read the log
+func (m LogMethods) Key(ctx context.Context, p *LogKeyParams) (*LogKey, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "key"), p)
+	if res, ok := got.(*LogKey); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// GenerateKey creates a key to encrypt a dataset's log, failing if the
+// dataset already has one
+func (m LogMethods) GenerateKey(ctx context.Context, p *LogKeyParams) (*LogKey, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "generatekey"), p)
+	if res, ok := got.(*LogKey); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// SetLogKeyParams defines parameters for the SetKey method
+type SetLogKeyParams struct {
+	Ref string `json:"ref"`
+	// Key is a base64-encoded dataset key
+	Key string `json:"key"`
+	// Remove disables log encryption for the dataset
+	Remove bool `json:"remove"`
+}
+
+// Validate returns an error if SetLogKeyParams fields are in an invalid state
+func (p *SetLogKeyParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("ref is required")
+	}
+	if p.Key == "" && !p.Remove {
+		return fmt.Errorf("either a key or remove is required")
+	}
+	if p.Key != "" && p.Remove {
+		return fmt.Errorf("cannot set and remove a key at once")
+	}
+	return nil
+}
+
+// SetKey sets or removes the key used to encrypt & decrypt a dataset's log
+func (m LogMethods) SetKey(ctx context.Context, p *SetLogKeyParams) error {
+	_, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "setkey"), p)
+	return err
+}
+
 // logImpl holds the method implementations for LogMethods
 type logImpl struct{}
 
@@ -197,7 +312,12 @@ func (logImpl) History(scope scope, params *HistoryParams) ([]dsref.VersionInfo,
 
 	if source == "" {
 		// local resolution
-		return base.DatasetLog(scope.Context(), scope.Repo(), ref, params.Limit, params.Offset, true)
+		items, err := base.DatasetLog(scope.Context(), scope.Repo(), ref, params.Limit, params.Offset, true)
+		if err != nil {
+			return nil, err
+		}
+		markBodyForeign(scope.inst.heads, items)
+		return items, nil
 	}
 
 	logs, err := scope.RemoteClient().FetchLogs(scope.Context(), ref, source)
@@ -259,6 +379,38 @@ func (logImpl) Entries(scope scope, p *RefListParams) ([]LogEntry, error) {
 	return res, nil
 }
 
+// Graph lists every operation on a dataset branch
+func (logImpl) Graph(scope scope, p *RefListParams) ([]LogGraphEntry, error) {
+	if p.Limit <= 0 {
+		p.Limit = -1
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	return scope.Logbook().Graph(scope.Context(), ref, p.Offset, p.Limit)
+}
+
+// Components lists which components each version of a dataset changed
+func (logImpl) Components(scope scope, p *RefListParams) ([]VersionComponents, error) {
+	if p.Limit <= 0 {
+		p.Limit = 25
+	}
+	if p.Offset < 0 {
+		p.Offset = 0
+	}
+
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	return base.ComponentHistory(scope.Context(), scope.Repo(), ref, p.Limit, p.Offset)
+}
+
 // RawLogbook encodes the full logbook as human-oriented json
 func (logImpl) RawLogbook(scope scope, p *RawLogbookParams) (*RawLogs, error) {
 	res := &RawLogs{}
@@ -277,3 +429,85 @@ func (logImpl) LogbookSummary(scope scope, p *struct{}) (*string, error) {
 	res = scope.Logbook().SummaryString(scope.Context())
 	return &res, nil
 }
+
+// Metrics reports logbook size & growth
+func (logImpl) Metrics(scope scope, p *LogbookMetricsParams) (*logbook.Metrics, error) {
+	if err := scope.inst.CheckOperator(scope.Context()); err != nil {
+		return nil, err
+	}
+	return scope.Logbook().Metrics(scope.Context())
+}
+
+// Key returns the key used to encrypt a dataset's log
+func (logImpl) Key(scope scope, p *LogKeyParams) (*LogKey, error) {
+	if err := scope.inst.CheckOperator(scope.Context()); err != nil {
+		return nil, err
+	}
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok, err := scope.Logbook().DatasetKey(ref.InitID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s has no log encryption key", ref.Alias())
+	}
+	return &LogKey{
+		Ref: ref.Alias(),
+		Key: base64.StdEncoding.EncodeToString(key),
+	}, nil
+}
+
+// GenerateKey creates & stores a key to encrypt a dataset's log
+func (logImpl) GenerateKey(scope scope, p *LogKeyParams) (*LogKey, error) {
+	if err := scope.inst.CheckOperator(scope.Context()); err != nil {
+		return nil, err
+	}
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+
+	book := scope.Logbook()
+	if _, ok, err := book.DatasetKey(ref.InitID); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, fmt.Errorf("%s already has a log encryption key", ref.Alias())
+	}
+	key, err := logbook.NewDatasetKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := book.SetDatasetKey(ref.InitID, key); err != nil {
+		return nil, err
+	}
+	return &LogKey{
+		Ref: ref.Alias(),
+		Key: base64.StdEncoding.EncodeToString(key),
+	}, nil
+}
+
+// SetKey sets or removes the key used to encrypt a dataset's log
+func (logImpl) SetKey(scope scope, p *SetLogKeyParams) error {
+	if err := scope.inst.CheckOperator(scope.Context()); err != nil {
+		return err
+	}
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return err
+	}
+
+	book := scope.Logbook()
+	if p.Remove {
+		return book.RemoveDatasetKey(ref.InitID)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(p.Key)
+	if err != nil {
+		return fmt.Errorf("invalid key: %w", err)
+	}
+	return book.SetDatasetKey(ref.InitID, key)
+}
@@ -69,15 +69,15 @@ func (p *ListParams) UnmarshalFromRequest(r *http.Request) error {
 	} else {
 		lp.Raw = p.Raw
 	}
-	if p.Peername == "" {
-		lp.Peername = r.FormValue("peername")
-	} else {
+	if p.Peername != "" {
 		lp.Peername = p.Peername
+	} else if peername := r.FormValue("peername"); peername != "" {
+		lp.Peername = peername
 	}
-	if p.Term == "" {
-		lp.Term = r.FormValue("term")
-	} else {
+	if p.Term != "" {
 		lp.Term = p.Term
+	} else if term := r.FormValue("term"); term != "" {
+		lp.Term = term
 	}
 	*p = lp
 	return nil
@@ -98,8 +98,13 @@ func NewListParams(orderBy string, page, pageSize int) ListParams {
 	}
 }
 
-// ListParamsFromRequest extracts ListParams from an http.Request pointer
+// ListParamsFromRequest extracts ListParams from an http.Request pointer.
+// Params parsed by API list middleware take precedence over query params
 func ListParamsFromRequest(r *http.Request) ListParams {
+	if lp, ok := util.ListParamsFromCtx(r.Context()); ok {
+		return listParamsFromAPI(lp)
+	}
+
 	var page, pageSize int
 	if i := util.ReqParamInt(r, "page", 0); i != 0 {
 		page = i
@@ -110,6 +115,25 @@ func ListParamsFromRequest(r *http.Request) ListParams {
 	return NewListParams(r.FormValue("orderBy"), page, pageSize)
 }
 
+// listParamsFromAPI converts list params parsed by API middleware. Dataset
+// lists can be filtered by username & name
+func listParamsFromAPI(lp util.ListParams) ListParams {
+	p := ListParams{Limit: lp.Limit, Offset: lp.Offset}
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageSize
+	}
+	if len(lp.OrderBy) > 0 {
+		p.OrderBy = lp.OrderBy[0].Key
+	}
+	if f, ok := lp.Filter("username"); ok {
+		p.Peername = f.Value
+	}
+	if f, ok := lp.Filter("name"); ok {
+		p.Term = f.Value
+	}
+	return p
+}
+
 // Page converts a ListParams struct to a util.Page struct
 func (p ListParams) Page() util.Page {
 	var number, size int
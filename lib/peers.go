@@ -2,6 +2,7 @@ package lib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,10 +10,12 @@ import (
 
 	"github.com/qri-io/qri/api/util"
 	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/profile"
 	"github.com/qri-io/qri/repo"
 
+	"github.com/libp2p/go-libp2p-core/network"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	ma "github.com/multiformats/go-multiaddr"
 )
@@ -29,12 +32,17 @@ func (m PeerMethods) Name() string { return "peer" }
 // Attributes defines attributes for each method
 func (m PeerMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
-		"list":                 {AEPeers, "POST"},
-		"info":                 {AEPeer, "POST"},
-		"connect":              {AEConnect, "POST"},
-		"disconnect":           {AEDisconnect, "POST"},
-		"connections":          {AEConnections, "POST"},
-		"connectedqriprofiles": {AEConnectedQriProfiles, "POST"},
+		"list":                 {endpoint: AEPeers, verb: "POST"},
+		"info":                 {endpoint: AEPeer, verb: "POST"},
+		"connect":              {endpoint: AEConnect, verb: "POST"},
+		"disconnect":           {endpoint: AEDisconnect, verb: "POST"},
+		"connections":          {endpoint: AEConnections, verb: "POST"},
+		"connectedqriprofiles": {endpoint: AEConnectedQriProfiles, verb: "POST"},
+		"top":                  {endpoint: AEPeersTop, verb: "POST"},
+		"bandwidth":            {endpoint: AEPeersBandwidth, verb: "POST"},
+		"sync":                 {endpoint: AEPeersSync, verb: "POST"},
+		"local":                {endpoint: AEPeersLocal, verb: "POST"},
+		"trust":                {endpoint: AEPeersTrust, verb: "POST"},
 	}
 }
 
@@ -54,7 +62,10 @@ func (p *PeerListParams) UnmarshalFromRequest(r *http.Request) error {
 
 	params := *p
 
-	if r.FormValue("limit") != "" && r.FormValue("offset") != "" {
+	if lp, ok := util.ListParamsFromCtx(r.Context()); ok {
+		params.Limit = lp.Limit
+		params.Offset = lp.Offset
+	} else if r.FormValue("limit") != "" && r.FormValue("offset") != "" {
 		params.Limit = util.ReqParamInt(r, "limit", 0)
 		params.Offset = util.ReqParamInt(r, "offset", 0)
 	} else {
@@ -187,6 +198,121 @@ func (m PeerMethods) ConnectedQriProfiles(ctx context.Context, p *ConnectionsPar
 	return nil, dispatchReturnError(got, err)
 }
 
+// PeerTopParams defines parameters for the Top method
+type PeerTopParams struct {
+	// Limit caps the number of scores returned, zero returns all peers
+	Limit int `json:"limit"`
+}
+
+// Top lists connected peers by connection manager score, highest first.
+// When the node has too many open connections, the lowest-scoring peers are
+// disconnected first
+func (m PeerMethods) Top(ctx context.Context, p *PeerTopParams) ([]p2p.PeerScore, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "top"), p)
+	if res, ok := got.([]p2p.PeerScore); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// PeerBandwidthParams defines parameters for the Bandwidth method
+type PeerBandwidthParams struct {
+	// Limit caps the number of peers listed, zero lists all peers
+	Limit int `json:"limit"`
+}
+
+// Bandwidth reports data transferred since the node went online, in total,
+// by peer & by protocol, along with configured bandwidth caps
+func (m PeerMethods) Bandwidth(ctx context.Context, p *PeerBandwidthParams) (*p2p.BandwidthReport, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "bandwidth"), p)
+	if res, ok := got.(*p2p.BandwidthReport); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// ErrUntrustedPeer is returned when exchanging logbook data with a peer that
+// was discovered on the local network, but hasn't been trusted
+var ErrUntrustedPeer = errors.New("peer was discovered on the local network and isn't trusted, trust it with `qri peers trust`")
+
+// PeerLocalParams defines parameters for the Local method
+type PeerLocalParams struct{}
+
+// Local lists peers discovered on the local network, and whether each is
+// trusted to exchange logbook data
+func (m PeerMethods) Local(ctx context.Context, p *PeerLocalParams) ([]p2p.LocalPeer, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "local"), p)
+	if res, ok := got.([]p2p.LocalPeer); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// PeerTrustParams defines parameters for the Trust method
+type PeerTrustParams struct {
+	// Peer is the peer ID to trust
+	Peer string `json:"peer"`
+	// Untrust stops trusting the peer
+	Untrust bool `json:"untrust"`
+}
+
+// Trust trusts or stops trusting a peer discovered on the local network to
+// exchange logbook data. Trusted peers are saved to config
+func (m PeerMethods) Trust(ctx context.Context, p *PeerTrustParams) error {
+	_, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "trust"), p)
+	return err
+}
+
+// PeerSyncParams defines parameters for the Sync method
+type PeerSyncParams struct {
+	// Peer to sync with, as a peername, profile ID, peer ID or multiaddr
+	Peer string `json:"peer"`
+	// Refs are the datasets to sync. Syncing without refs syncs every dataset
+	// either peer follows
+	Refs []string `json:"refs"`
+	// Fetch pulls version data for datasets with a new head
+	Fetch bool `json:"fetch"`
+}
+
+// PeerSyncResult describes the outcome of syncing with a peer
+type PeerSyncResult struct {
+	// Peer is the ID of the synced peer
+	Peer     string            `json:"peer"`
+	Datasets []PeerSyncDataset `json:"datasets"`
+}
+
+// PeerSyncDataset describes how syncing changed a single dataset
+type PeerSyncDataset struct {
+	Ref string `json:"ref"`
+	// Prev & Head are the dataset head paths before & after syncing
+	Prev string `json:"prev,omitempty"`
+	Head string `json:"head,omitempty"`
+	// Pulled & Pushed are true if logbook data was received from & sent to
+	// the peer
+	Pulled bool `json:"pulled"`
+	Pushed bool `json:"pushed"`
+	// Fetched is true if version data for a new head was pulled
+	Fetched bool   `json:"fetched"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Changed is true if syncing moved the dataset head
+func (d PeerSyncDataset) Changed() bool {
+	return d.Prev != d.Head
+}
+
+// Sync connects to a peer & exchanges logbook data for a set of datasets in
+// both directions, optionally fetching version data for updated heads. It's
+// a manual, single-peer analogue of follow gossip. Without refs, peers
+// exchange lists of followed datasets & sync all of them
+func (m PeerMethods) Sync(ctx context.Context, p *PeerSyncParams) (*PeerSyncResult, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "sync"), p)
+	if res, ok := got.(*PeerSyncResult); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
 // ConnectParamsPod defines parameters for defining a connection
 // to a peer as plain-old-data
 type ConnectParamsPod struct {
@@ -421,6 +547,191 @@ func (peerImpl) ConnectedQriProfiles(scope scope, p *ConnectionsParams) ([]*conf
 	return build, nil
 }
 
+// Top lists connected peers by connection manager score
+func (peerImpl) Top(scope scope, p *PeerTopParams) ([]p2p.PeerScore, error) {
+	if scope.Node() == nil || !scope.Node().Online {
+		return nil, fmt.Errorf("error: not connected, run `qri connect` in another window")
+	}
+	scores := scope.Node().PeerScores()
+	if p.Limit > 0 && len(scores) > p.Limit {
+		scores = scores[:p.Limit]
+	}
+	return scores, nil
+}
+
+// Bandwidth reports data transferred by the node
+func (peerImpl) Bandwidth(scope scope, p *PeerBandwidthParams) (*p2p.BandwidthReport, error) {
+	if scope.Node() == nil || !scope.Node().Online {
+		return nil, fmt.Errorf("error: not connected, run `qri connect` in another window")
+	}
+	rep := scope.Node().Bandwidth().Report()
+	if p.Limit > 0 && len(rep.Peers) > p.Limit {
+		rep.Peers = rep.Peers[:p.Limit]
+	}
+	return rep, nil
+}
+
+// Sync exchanges logbook data with a peer
+func (peerImpl) Sync(scope scope, p *PeerSyncParams) (*PeerSyncResult, error) {
+	ctx := scope.Context()
+	node := scope.Node()
+	if node == nil || !node.Online {
+		return nil, fmt.Errorf("error: not connected, run `qri connect` in another window")
+	}
+	if p.Peer == "" {
+		return nil, fmt.Errorf("a peer to sync with is required")
+	}
+
+	pcp, err := NewConnectParamsPod(p.Peer).Decode()
+	if err != nil {
+		return nil, err
+	}
+	pro, err := node.ConnectToPeer(ctx, pcp)
+	if err != nil {
+		return nil, err
+	}
+	pid := pcp.PeerID
+	if pid == "" {
+		for _, id := range pro.PeerIDs {
+			if node.Host().Network().Connectedness(id) == network.Connected {
+				pid = id
+				break
+			}
+		}
+	}
+	if pid == "" {
+		return nil, fmt.Errorf("no connection to peer %q", p.Peer)
+	}
+	if !node.PeerTrusted(pid) {
+		return nil, ErrUntrustedPeer
+	}
+
+	refs := p.Refs
+	if len(refs) == 0 {
+		if refs, err = syncFollows(ctx, scope, pid); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &PeerSyncResult{Peer: pid.Pretty(), Datasets: make([]PeerSyncDataset, 0, len(refs))}
+	for _, refStr := range refs {
+		res.Datasets = append(res.Datasets, syncDataset(ctx, scope, refStr, pid.Pretty(), p.Fetch))
+	}
+	return res, nil
+}
+
+// Local lists peers discovered on the local network
+func (peerImpl) Local(scope scope, p *PeerLocalParams) ([]p2p.LocalPeer, error) {
+	if scope.Node() == nil || !scope.Node().Online {
+		return nil, fmt.Errorf("error: not connected, run `qri connect` in another window")
+	}
+	return scope.Node().LocalPeers(), nil
+}
+
+// Trust trusts or stops trusting a local peer, saving trusted peers to config
+func (peerImpl) Trust(scope scope, p *PeerTrustParams) error {
+	pid, err := peer.Decode(p.Peer)
+	if err != nil {
+		return fmt.Errorf("%w: invalid peer ID %q", ErrBadArgs, p.Peer)
+	}
+
+	cfg := scope.Config().Copy()
+	if cfg.P2P == nil {
+		return fmt.Errorf("p2p isn't configured")
+	}
+	trusted := make([]string, 0, len(cfg.P2P.TrustedPeers)+1)
+	for _, id := range cfg.P2P.TrustedPeers {
+		if id != pid.Pretty() {
+			trusted = append(trusted, id)
+		}
+	}
+	if !p.Untrust {
+		trusted = append(trusted, pid.Pretty())
+	}
+	cfg.P2P.TrustedPeers = trusted
+	if err := scope.ChangeConfig(cfg); err != nil {
+		return err
+	}
+
+	if node := scope.Node(); node != nil {
+		node.SetPeerTrusted(pid, !p.Untrust)
+	}
+	return nil
+}
+
+// syncFollows exchanges follow lists with a peer, returning the union of
+// datasets either side follows
+func syncFollows(ctx context.Context, scope scope, pid peer.ID) ([]string, error) {
+	theirs, err := scope.Node().ExchangeFollows(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	ours, err := scope.inst.followAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	refs := []string{}
+	for _, alias := range append(ours, theirs...) {
+		if !seen[alias] {
+			seen[alias] = true
+			refs = append(refs, alias)
+		}
+	}
+	return refs, nil
+}
+
+// syncDataset pulls & pushes logbook data for a single dataset, recording
+// failures on the result instead of stopping the sync
+func syncDataset(ctx context.Context, scope scope, refStr, addr string, fetch bool) PeerSyncDataset {
+	res := PeerSyncDataset{Ref: refStr}
+	ref, err := dsref.Parse(refStr)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	local, _, err := scope.inst.ParseAndResolveRef(ctx, refStr, "local")
+	if err == nil {
+		ref = local
+		res.Prev = local.Path
+	}
+
+	rc := scope.RemoteClient()
+	pullErr := rc.PullLogs(ctx, ref, addr)
+	if pullErr != nil {
+		log.Debugw("sync pulling logs", "ref", refStr, "peer", addr, "err", pullErr)
+	} else {
+		res.Pulled = true
+	}
+	// only datasets we have logs for can be pushed
+	if res.Prev != "" {
+		if err := rc.PushLogs(ctx, ref, addr); err != nil {
+			log.Debugw("sync pushing logs", "ref", refStr, "peer", addr, "err", err)
+		} else {
+			res.Pushed = true
+		}
+	}
+	if !res.Pulled && !res.Pushed {
+		res.Error = pullErr.Error()
+		return res
+	}
+
+	head, _, err := scope.inst.ParseAndResolveRef(ctx, refStr, "local")
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Head = head.Path
+	if fetch && res.Changed() {
+		if _, err := rc.PullDataset(ctx, &head, addr); err != nil {
+			res.Error = fmt.Sprintf("fetching version %s: %s", head.Path, err)
+		} else {
+			res.Fetched = true
+		}
+	}
+	return res
+}
+
 func intMin(a, b int) int {
 	if a < b {
 		return a
@@ -31,6 +31,37 @@ func TestPeerMethodsListNoConnection(t *testing.T) {
 	}
 }
 
+func TestPeerMethodsSyncNoConnection(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	node := newTestQriNode(t)
+	inst := NewInstanceFromConfigAndNode(ctx, testcfg.DefaultConfigForTesting(), node)
+	_, err := inst.Peer().Sync(ctx, &PeerSyncParams{Peer: "peer"})
+	if err == nil {
+		t.Errorf("expected syncing while offline to fail")
+	} else if !strings.HasPrefix(err.Error(), "error: not connected") {
+		t.Errorf("unexpected error message: %s", err.Error())
+	}
+}
+
+func TestPeerSyncDatasetChanged(t *testing.T) {
+	cases := []struct {
+		d    PeerSyncDataset
+		want bool
+	}{
+		{PeerSyncDataset{}, false},
+		{PeerSyncDataset{Prev: "/ipfs/QmA", Head: "/ipfs/QmA"}, false},
+		{PeerSyncDataset{Prev: "", Head: "/ipfs/QmA"}, true},
+		{PeerSyncDataset{Prev: "/ipfs/QmA", Head: "/ipfs/QmB"}, true},
+	}
+	for i, c := range cases {
+		if got := c.d.Changed(); got != c.want {
+			t.Errorf("case %d: want %t, got %t", i, c.want, got)
+		}
+	}
+}
+
 func TestPeerMethodsList(t *testing.T) {
 	ctx, done := context.WithCancel(context.Background())
 	defer done()
@@ -0,0 +1,260 @@
+package lib
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/qri-io/qri/auth/token"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/archive"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/registry"
+	"github.com/qri-io/qri/remote"
+	"github.com/qri-io/qri/repo"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// ProfileExportParams are input parameters for ProfileMethods.Export
+type ProfileExportParams struct {
+	// Path to write the archive to. defaults to "[username]_qri_export.zip" in
+	// the working directory
+	Path string
+}
+
+// ProfileExportResult describes a written profile archive
+type ProfileExportResult struct {
+	Path string `json:"path"`
+	// Datasets lists the datasets written to the archive
+	Datasets []string `json:"datasets"`
+	// Skipped maps datasets that couldn't be exported to the reason why,
+	// usually because dataset versions aren't stored locally
+	Skipped map[string]string `json:"skipped,omitempty"`
+}
+
+// Export writes a zip archive of everything this repo holds on behalf of the
+// active user: their profile, the latest version of each of their datasets,
+// their logbook, and metadata of authenticated sessions. The archive never
+// contains private keys or tokens
+//
+// archive layout:
+// profile.json - the user profile
+// logbook.json - the logbook, as plain logs
+// sessions.json - authenticated sessions of the user
+// datasets/[name].zip - one dataset archive for each dataset
+func (m *ProfileMethods) Export(ctx context.Context, p *ProfileExportParams) (*ProfileExportResult, error) {
+	if m.inst.http != nil {
+		return nil, ErrUnsupportedRPC
+	}
+
+	r := m.inst.repo
+	pro := r.Profiles().Owner()
+	res := &ProfileExportResult{
+		Path:     p.Path,
+		Datasets: []string{},
+		Skipped:  map[string]string{},
+	}
+	if res.Path == "" {
+		res.Path = fmt.Sprintf("%s_qri_export.zip", pro.Peername)
+	}
+
+	f, err := os.Create(res.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+
+	pod, err := pro.Encode()
+	if err != nil {
+		return nil, err
+	}
+	pod.PrivKey = ""
+	if err := writeZipJSON(zw, "profile.json", pod); err != nil {
+		return nil, err
+	}
+
+	logs, err := r.Logbook().PlainLogs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading logbook: %w", err)
+	}
+	if err := writeZipJSON(zw, "logbook.json", logs); err != nil {
+		return nil, err
+	}
+
+	sessions := []token.Session{}
+	if m.inst.sessions != nil {
+		if sessions, err = m.inst.sessions.List(pro.ID.String()); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeZipJSON(zw, "sessions.json", sessions); err != nil {
+		return nil, err
+	}
+
+	refs, err := ownedRefs(r, pro)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		alias := ref.AliasString()
+		buf := &bytes.Buffer{}
+		if err := writeDatasetZip(ctx, r, ref, buf); err != nil {
+			log.Debugw("exporting dataset", "ref", alias, "err", err)
+			res.Skipped[alias] = err.Error()
+			continue
+		}
+		w, err := zw.Create(fmt.Sprintf("datasets/%s.zip", ref.Name))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return nil, err
+		}
+		res.Datasets = append(res.Datasets, alias)
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// writeDatasetZip writes an archive of the head version of a dataset
+func writeDatasetZip(ctx context.Context, r repo.Repo, ref reporef.DatasetRef, buf *bytes.Buffer) error {
+	if ref.Path == "" {
+		return fmt.Errorf("dataset has no versions")
+	}
+	fs := r.Filesystem()
+	ds, err := dsfs.LoadDataset(ctx, fs, ref.Path)
+	if err != nil {
+		return err
+	}
+	if err := base.OpenDataset(ctx, fs, ds); err != nil {
+		return err
+	}
+	dr := dsref.Ref{Username: ref.Peername, Name: ref.Name}
+	initID, err := r.Logbook().RefToInitID(dr)
+	if err != nil {
+		return err
+	}
+	return archive.WriteZip(ctx, fs, ds, "json", initID, dr, buf)
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// ownedRefs lists references to datasets owned by a profile
+func ownedRefs(r repo.Repo, pro *profile.Profile) ([]reporef.DatasetRef, error) {
+	num, err := r.RefCount()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := r.References(0, num)
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]reporef.DatasetRef, 0, len(refs))
+	for _, ref := range refs {
+		if ref.ProfileID == pro.ID || (ref.ProfileID == "" && ref.Peername == pro.Peername) {
+			owned = append(owned, ref)
+		}
+	}
+	return owned, nil
+}
+
+// ProfileDeactivateParams are input parameters for ProfileMethods.Deactivate
+type ProfileDeactivateParams struct {
+	// Confirm must match the active username
+	Confirm string
+	// Remote to unpublish datasets from, defaults to the configured registry
+	Remote string
+}
+
+// ProfileDeactivateResult describes a deactivated profile
+type ProfileDeactivateResult struct {
+	// Unpublished lists datasets removed from the remote
+	Unpublished []string `json:"unpublished"`
+	// Tombstone is the record the registry keeps of the deactivated profile
+	Tombstone *registry.Profile `json:"tombstone,omitempty"`
+}
+
+// Deactivate unpublishes the active user's datasets & replaces their registry
+// profile with a tombstone that keeps the username reserved. Local data is
+// left in place, use Export to keep a copy before removing a repo. If any
+// dataset can't be unpublished the profile stays active, and deactivation can
+// be retried
+func (m *ProfileMethods) Deactivate(ctx context.Context, p *ProfileDeactivateParams) (*ProfileDeactivateResult, error) {
+	if m.inst.http != nil {
+		return nil, ErrUnsupportedRPC
+	}
+
+	r := m.inst.repo
+	pro := r.Profiles().Owner()
+	if p.Confirm != pro.Peername {
+		return nil, fmt.Errorf("%w: confirm deactivation with your username, %q", ErrBadArgs, pro.Peername)
+	}
+
+	refs, err := ownedRefs(r, pro)
+	if err != nil {
+		return nil, err
+	}
+	res := &ProfileDeactivateResult{Unpublished: []string{}}
+	var published []dsref.Ref
+	for _, ref := range refs {
+		if ref.Published {
+			published = append(published, reporef.ConvertToDsref(ref))
+		}
+	}
+	if len(published) > 0 {
+		addr, err := m.inst.remoteAddress(p.Remote)
+		if err != nil {
+			return nil, err
+		}
+		failed := 0
+		for _, ref := range published {
+			if err := m.unpublish(ctx, ref, addr); err != nil {
+				log.Debugw("deactivate unpublishing dataset", "ref", ref.Alias(), "err", err)
+				failed++
+				continue
+			}
+			res.Unpublished = append(res.Unpublished, ref.Alias())
+		}
+		if failed > 0 {
+			return res, fmt.Errorf("couldn't unpublish %d of %d datasets, profile is still active", failed, len(published))
+		}
+	}
+
+	if m.inst.registry != nil {
+		res.Tombstone, err = m.inst.registry.DeactivateProfile(&registry.Profile{Username: pro.Peername}, pro.PrivKey)
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func (m *ProfileMethods) unpublish(ctx context.Context, ref dsref.Ref, addr string) error {
+	if m.inst.RemoteClient() == nil {
+		return remote.ErrNoRemoteClient
+	}
+	if _, err := m.inst.ResolveReference(ctx, &ref, "local"); err != nil {
+		return err
+	}
+	if err := m.inst.RemoteClient().RemoveDataset(ctx, ref, addr); err != nil {
+		return err
+	}
+	return base.SetPublishStatus(ctx, m.inst.repo, ref, false)
+}
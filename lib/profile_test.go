@@ -1,7 +1,10 @@
 package lib
 
 import (
+	"archive/zip"
 	"context"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -369,3 +372,52 @@ func TestProfileRequestsSetPosterPhoto(t *testing.T) {
 		}
 	}
 }
+
+func TestProfileExport(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+
+	run.MustSaveFromBody(t, "export_test", run.MustWriteTmpFile(t, "body.csv", "a,b\n1,2\n"))
+
+	m := NewProfileMethods(run.Instance)
+	res, err := m.Export(run.Ctx, &ProfileExportParams{Path: run.MakeTmpFilename("export.zip")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	username := run.MustOwner(t).Peername
+	if diff := cmp.Diff([]string{username + "/export_test"}, res.Datasets); diff != "" {
+		t.Errorf("exported datasets mismatch (-want +got):\n%s", diff)
+	}
+
+	zr, err := zip.OpenReader(res.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	got := []string{}
+	for _, f := range zr.File {
+		got = append(got, f.Name)
+		if f.Name == "profile.json" {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			pod := &config.ProfilePod{}
+			if err := json.NewDecoder(rc).Decode(pod); err != nil {
+				t.Fatal(err)
+			}
+			rc.Close()
+			if pod.PrivKey != "" {
+				t.Error("expected exported profile to omit the private key")
+			}
+		}
+	}
+	expect := []string{"profile.json", "logbook.json", "sessions.json", "datasets/export_test.zip"}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("archive contents mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := m.Deactivate(run.Ctx, &ProfileDeactivateParams{Confirm: "someone_else"}); !errors.Is(err, ErrBadArgs) {
+		t.Errorf("expected deactivating without confirming the username to fail, got: %v", err)
+	}
+}
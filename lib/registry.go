@@ -180,3 +180,58 @@ func (m RegistryClientMethods) updateConfig(ctx context.Context, pro *registry.P
 
 	return m.inst.ChangeConfig(cfg)
 }
+
+// RegistryOrganization is a team account stored on a registry
+type RegistryOrganization = registry.Organization
+
+// OrganizationMemberParams are input parameters for changing the members of
+// a registry organization
+type OrganizationMemberParams struct {
+	Org    string
+	Member string
+	// Role is one of "owner" or "member", defaults to "member" when adding
+	Role string
+}
+
+// GetOrganization fetches an organization from the registry
+func (m RegistryClientMethods) GetOrganization(ctx context.Context, username string) (*RegistryOrganization, error) {
+	if m.inst.http != nil {
+		return nil, ErrUnsupportedRPC
+	}
+	return m.inst.registry.GetOrganization(username)
+}
+
+// CreateOrganization registers an organization with the registry, making
+// the owner profile its first owner
+func (m RegistryClientMethods) CreateOrganization(ctx context.Context, o *RegistryOrganization) (*RegistryOrganization, error) {
+	if m.inst.http != nil {
+		return nil, ErrUnsupportedRPC
+	}
+	pk := m.inst.repo.Profiles().Owner().PrivKey
+	return m.inst.registry.CreateOrganization(o, m.requester(), pk)
+}
+
+// AddOrganizationMember adds a registered profile to an organization, or
+// changes the role of an existing member
+func (m RegistryClientMethods) AddOrganizationMember(ctx context.Context, p *OrganizationMemberParams) (*RegistryOrganization, error) {
+	if m.inst.http != nil {
+		return nil, ErrUnsupportedRPC
+	}
+	pk := m.inst.repo.Profiles().Owner().PrivKey
+	return m.inst.registry.AddOrganizationMember(p.Org, p.Member, p.Role, m.requester(), pk)
+}
+
+// RemoveOrganizationMember removes a profile from an organization
+func (m RegistryClientMethods) RemoveOrganizationMember(ctx context.Context, p *OrganizationMemberParams) (*RegistryOrganization, error) {
+	if m.inst.http != nil {
+		return nil, ErrUnsupportedRPC
+	}
+	pk := m.inst.repo.Profiles().Owner().PrivKey
+	return m.inst.registry.RemoveOrganizationMember(p.Org, p.Member, m.requester(), pk)
+}
+
+// requester returns a registry profile for signing requests as the owner
+// TODO(arqu): this should use the active profile once multi tenancy is supported
+func (m RegistryClientMethods) requester() *registry.Profile {
+	return &registry.Profile{Username: m.inst.cfg.Profile.Peername}
+}
@@ -2,18 +2,28 @@ package lib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"path/filepath"
+	"sort"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/pinning"
+	"github.com/qri-io/qri/registry"
 	"github.com/qri-io/qri/remote"
-	reporef "github.com/qri-io/qri/repo/ref"
 )
 
 const allowedDagInfoSize uint64 = 10 * 1024 * 1024
 
+// ErrBehindRemote indicates a remote has versions of a dataset that aren't
+// saved locally. Saving or pushing a dataset that's behind its remote
+// diverges the local history from the remote history
+var ErrBehindRemote = errors.New("dataset is behind remote")
+
 // RemoteMethods encapsulates business logic of remote operation
 // TODO (b5): switch to using an Instance instead of separate fields
 type RemoteMethods struct {
@@ -47,7 +57,7 @@ func (r *RemoteMethods) Feeds(ctx context.Context, p *FeedsParams) (map[string][
 		return res, nil
 	}
 
-	addr, err := remote.Address(r.inst.GetConfig(), p.Remote)
+	addr, err := r.inst.remoteAddress(p.Remote)
 	if err != nil {
 		return nil, err
 	}
@@ -63,6 +73,9 @@ func (r *RemoteMethods) Feeds(ctx context.Context, p *FeedsParams) (map[string][
 type PreviewParams struct {
 	Remote string
 	Ref    string
+	// Lang picks a localized variant of the title, description & readme, as
+	// a language tag or Accept-Language header value
+	Lang string
 }
 
 // UnmarshalFromRequest implements a custom deserialization-from-HTTP request
@@ -78,6 +91,12 @@ func (p *PreviewParams) UnmarshalFromRequest(r *http.Request) error {
 	if params.Remote == "" {
 		params.Remote = r.FormValue("remote")
 	}
+	if params.Lang == "" {
+		params.Lang = r.FormValue("lang")
+	}
+	if params.Lang == "" {
+		params.Lang = r.Header.Get("Accept-Language")
+	}
 
 	*p = params
 	return nil
@@ -99,7 +118,7 @@ func (r *RemoteMethods) Preview(ctx context.Context, p *PreviewParams) (*dataset
 		return nil, err
 	}
 
-	addr, err := remote.Address(r.inst.GetConfig(), p.Remote)
+	addr, err := r.inst.remoteAddress(p.Remote)
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +127,9 @@ func (r *RemoteMethods) Preview(ctx context.Context, p *PreviewParams) (*dataset
 	if err != nil {
 		return nil, err
 	}
+	if p.Lang != "" {
+		base.Localize(res, base.ParseAcceptLanguage(p.Lang))
+	}
 
 	return res, nil
 }
@@ -119,6 +141,12 @@ type PushParams struct {
 	// All indicates all versions of a dataset and the dataset namespace should
 	// be either published or removed
 	All bool
+	// MetaOnly pushes logbook data & head components, skipping body data
+	MetaOnly bool
+	// CheckRemote fails the push with ErrBehindRemote if the remote has
+	// versions that aren't saved locally, unless Force is true
+	CheckRemote bool
+	Force       bool
 }
 
 // Push posts a dataset version to a remote
@@ -132,29 +160,402 @@ func (r *RemoteMethods) Push(ctx context.Context, p *PushParams) (*dsref.Ref, er
 		return res, nil
 	}
 
+	return r.push(ctx, p, true)
+}
+
+// push sends a dataset to a remote. If queue is true and the remote can't be
+// reached the push is added to the instance push queue, returning the
+// resolved reference and an error wrapping remote.ErrPushQueued
+func (r *RemoteMethods) push(ctx context.Context, p *PushParams, queue bool) (*dsref.Ref, error) {
 	ref, _, err := r.inst.ParseAndResolveRef(ctx, p.Ref, "local")
 	if err != nil {
 		return nil, err
 	}
 
-	addr, err := remote.Address(r.inst.GetConfig(), p.Remote)
+	remoteName := p.Remote
+	if remoteName == "" {
+		remoteName = r.inst.datasetSettings(ref.InitID).Remote
+	}
+	addr, err := r.inst.remoteAddress(remoteName)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = r.inst.RemoteClient().PushDataset(ctx, ref, addr); err != nil {
-		return nil, err
+	if p.CheckRemote && !p.Force {
+		scope, err := newScope(ctx, r.inst, "local")
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRemoteHead(scope, ref, addr); err != nil {
+			return nil, err
+		}
 	}
 
-	datasetRef := reporef.RefFromDsref(ref)
-	datasetRef.Published = true
-	if err = base.SetPublishStatus(ctx, r.inst.node.Repo, ref, true); err != nil {
+	push := r.inst.pushDataset
+	if p.MetaOnly {
+		push = r.inst.pushDatasetHead
+	}
+	if err = push(ctx, ref, addr); err != nil {
+		// the push queue only retries full pushes
+		if queue && !p.MetaOnly && r.inst.pushQueue != nil && remote.IsUnreachable(err) {
+			log.Debugw("remote unreachable, queueing push", "ref", ref.String(), "addr", addr, "err", err)
+			if qErr := r.inst.pushQueue.Enqueue(ref, remoteName, addr, err); qErr != nil {
+				return nil, fmt.Errorf("pushing: %s. queueing push also failed: %w", err, qErr)
+			}
+			return &ref, fmt.Errorf("%w: %s", remote.ErrPushQueued, err)
+		}
 		return nil, err
 	}
 
+	if r.inst.isRegistryAddress(addr) {
+		if err := r.inst.putRegistrySnapshot(ctx, ref); err != nil {
+			log.Warnw("uploading registry snapshot", "ref", ref.String(), "err", err)
+		}
+	}
+	if !p.MetaOnly {
+		r.inst.pinPublished(ctx, ref)
+	}
+
 	return &ref, nil
 }
 
+// PublishParams encapsulates parameters for pushing a dataset version to
+// many remotes at once
+type PublishParams struct {
+	Ref string `schema:"refstr" json:"refstr"`
+	// Remotes names the remotes to push to. Leave empty to push to the
+	// registry & every configured remote
+	Remotes []string `json:"remotes"`
+	// MetaOnly pushes logbook data & head components, skipping body data
+	MetaOnly bool `json:"metaOnly"`
+	// CheckRemote skips remotes that have versions that aren't saved locally,
+	// unless Force is true
+	CheckRemote bool `json:"checkRemote"`
+	Force       bool `json:"force"`
+}
+
+// Publish pushes a dataset version to a set of remotes in parallel. Failing
+// to push to one remote doesn't stop pushes to the others. Unreachable remotes
+// are added to the push queue. The returned report lists the outcome for each
+// remote, use the report's Err method to check for partial failure
+func (r *RemoteMethods) Publish(ctx context.Context, p *PublishParams) (*remote.FanoutReport, error) {
+	if r.inst.http != nil {
+		res := &remote.FanoutReport{}
+		err := r.inst.http.Call(ctx, AEPublish, p, res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	ref, _, err := r.inst.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	if r.inst.RemoteClient() == nil {
+		return nil, remote.ErrNoRemoteClient
+	}
+	targets, err := r.inst.publishTargets(p.Remotes)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := newScope(ctx, r.inst, "local")
+	if err != nil {
+		return nil, err
+	}
+	cli := r.inst.RemoteClient()
+	report := remote.FanoutPush(ctx, ref, targets, func(ctx context.Context, t remote.PushTarget) error {
+		if p.CheckRemote && !p.Force {
+			if err := checkRemoteHead(scope, ref, t.Addr); err != nil {
+				return err
+			}
+		}
+		if p.MetaOnly {
+			return cli.PushDatasetHead(ctx, ref, t.Addr)
+		}
+		return cli.PushDataset(ctx, ref, t.Addr)
+	})
+
+	for i, res := range report.Results {
+		if res.Error == "" {
+			if r.inst.isRegistryAddress(res.Addr) {
+				if err := r.inst.putRegistrySnapshot(ctx, ref); err != nil {
+					log.Warnw("uploading registry snapshot", "ref", ref.String(), "err", err)
+				}
+			}
+			continue
+		}
+		// the push queue only retries full pushes
+		if !p.MetaOnly && r.inst.pushQueue != nil && remote.IsUnreachable(res.Err()) {
+			name := res.Remote
+			if name == "registry" {
+				name = ""
+			}
+			if err := r.inst.pushQueue.Enqueue(ref, name, res.Addr, res.Err()); err != nil {
+				log.Warnw("queueing push", "ref", ref.String(), "addr", res.Addr, "err", err)
+				continue
+			}
+			report.Results[i].Queued = true
+		}
+	}
+
+	if report.Pushed() > 0 {
+		if err := base.SetPublishStatus(ctx, r.inst.node.Repo, ref, true); err != nil {
+			return nil, err
+		}
+		if !p.MetaOnly {
+			r.inst.pinPublished(ctx, ref)
+		}
+	}
+	return report, nil
+}
+
+// publishTargets resolves remote names to push targets. No names resolves the
+// registry & every configured remote. Remotes that share an address are only
+// pushed to once
+func (inst *Instance) publishTargets(names []string) ([]remote.PushTarget, error) {
+	cfg := inst.GetConfig()
+	if len(names) == 0 {
+		if cfg.Registry != nil && cfg.Registry.Location != "" {
+			names = append(names, "registry")
+		}
+		if cfg.Remotes != nil {
+			configured := make([]string, 0, len(*cfg.Remotes))
+			for name := range *cfg.Remotes {
+				configured = append(configured, name)
+			}
+			sort.Strings(configured)
+			names = append(names, configured...)
+		}
+	}
+
+	targets := make([]remote.PushTarget, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		addr, err := remote.Address(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		targets = append(targets, remote.PushTarget{Name: name, Addr: addr})
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no remotes configured to push to")
+	}
+	return targets, nil
+}
+
+// PushQueueParams provides arguments to the PushQueue method
+type PushQueueParams struct{}
+
+// PushQueue lists pushes waiting for their remote to become reachable
+func (r *RemoteMethods) PushQueue(ctx context.Context, p *PushQueueParams) ([]remote.QueuedPush, error) {
+	if r.inst.http != nil {
+		res := []remote.QueuedPush{}
+		err := r.inst.http.Call(ctx, AEPushQueue, p, &res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	if r.inst.pushQueue == nil {
+		return []remote.QueuedPush{}, nil
+	}
+	return r.inst.pushQueue.List(), nil
+}
+
+// VerifyReceiptsParams provides arguments to the VerifyReceipts method
+type VerifyReceiptsParams struct {
+	Ref string `json:"refstr"`
+}
+
+// ReceiptCheck is a receipt a remote issued for a push or unpublish & the
+// result of verifying it
+type ReceiptCheck struct {
+	Receipt *remote.Receipt `json:"receipt"`
+	Valid   bool            `json:"valid"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// VerifyReceipts checks the signatures of receipts recorded for pushes &
+// unpublishes of a dataset, oldest first. A receipt is valid if it was signed
+// by the remote it names & matches the dataset
+func (r *RemoteMethods) VerifyReceipts(ctx context.Context, p *VerifyReceiptsParams) ([]ReceiptCheck, error) {
+	if r.inst.http != nil {
+		res := []ReceiptCheck{}
+		err := r.inst.http.Call(ctx, AEPushReceipts, p, &res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	ref, _, err := r.inst.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := r.inst.logbook.RemoteReceipts(ctx, ref.InitID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ReceiptCheck, 0, len(encoded))
+	for _, enc := range encoded {
+		rcpt, err := remote.DecodeReceipt(enc)
+		if err != nil {
+			res = append(res, ReceiptCheck{Error: err.Error()})
+			continue
+		}
+		check := ReceiptCheck{Receipt: rcpt}
+		if err := rcpt.Verify(); err != nil {
+			check.Error = err.Error()
+		} else if rcpt.InitID != ref.InitID {
+			check.Error = "receipt is for a different dataset"
+		} else {
+			check.Valid = true
+		}
+		res = append(res, check)
+	}
+	return res, nil
+}
+
+// HookStatusParams provides arguments to the HookStatus method
+type HookStatusParams struct {
+	Ref string `json:"refstr"`
+	// Remote to fetch hook status from, defaults to the registry
+	Remote string `json:"remote"`
+}
+
+// HookStatus fetches the status of processing hooks a remote ran after a
+// dataset version was pushed. Only the profile that pushed the version can
+// view hook status
+func (r *RemoteMethods) HookStatus(ctx context.Context, p *HookStatusParams) ([]remote.HookStatus, error) {
+	if r.inst.http != nil {
+		res := []remote.HookStatus{}
+		err := r.inst.http.Call(ctx, AEPushHooks, p, &res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	ref, _, err := r.inst.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := r.inst.remoteAddress(p.Remote)
+	if err != nil {
+		return nil, err
+	}
+	if r.inst.RemoteClient() == nil {
+		return nil, remote.ErrNoRemoteClient
+	}
+	return r.inst.RemoteClient().HookStatuses(ctx, ref, addr)
+}
+
+// PromoteParams encapsulates parameters for promoting a dataset
+type PromoteParams struct {
+	// Ref is the current, scratch name of the dataset
+	Ref string `json:"refstr"`
+	// Next is the public name to rename the dataset to before pushing
+	Next string `json:"next"`
+	// Remote to push to, defaults to the registry
+	Remote string `json:"remote"`
+}
+
+// Validate returns an error if input params are invalid
+func (p *PromoteParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("dataset reference is required")
+	}
+	if p.Next == "" {
+		return fmt.Errorf("name to promote to is required")
+	}
+	return nil
+}
+
+// Promote moves a dataset from a scratch name to its public name & pushes it
+// to a remote. Promote renames the dataset, updating any linked working
+// directory, then pushes. If the push fails the rename is rolled back, leaving
+// the dataset under its original name
+func (r *RemoteMethods) Promote(ctx context.Context, p *PromoteParams) (*dsref.VersionInfo, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	if r.inst.http != nil {
+		res := &dsref.VersionInfo{}
+		err := r.inst.http.Call(ctx, AEPromote, p, res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	// parse the original name before renaming, so a bad reference can't leave
+	// a renamed dataset that can't be rolled back
+	prev, err := dsref.ParseHumanFriendly(p.Ref)
+	if err != nil && err != dsref.ErrBadCaseName {
+		return nil, err
+	}
+	renamed, err := r.inst.Dataset().Rename(ctx, &RenameParams{Current: p.Ref, Next: p.Next})
+	if err != nil {
+		return nil, fmt.Errorf("renaming: %w", err)
+	}
+	vi := &renamed.VersionInfo
+
+	// don't queue pushes that fail, a queued push would outlive the rollback
+	if _, pushErr := r.push(ctx, &PushParams{Ref: vi.Alias(), Remote: p.Remote}, false); pushErr != nil {
+		log.Debugw("promote push failed, rolling back rename", "ref", vi.Alias(), "err", pushErr)
+		if _, err := r.inst.Dataset().Rename(ctx, &RenameParams{Current: vi.Alias(), Next: prev.Alias()}); err != nil {
+			return nil, fmt.Errorf("pushing: %s. rolling back rename from %s to %s also failed: %w", pushErr, vi.Alias(), prev.Alias(), err)
+		}
+		return nil, fmt.Errorf("pushing %s failed, rename rolled back: %w", vi.Alias(), pushErr)
+	}
+
+	vi.Published = true
+	return vi, nil
+}
+
+// UsageParams provides arguments to the usage method
+type UsageParams struct {
+	Ref string `json:"refstr"`
+	// Remote to fetch stats from, defaults to the registry
+	Remote string `json:"remote"`
+}
+
+// Usage fetches pull counts, unique peers & bytes served for a dataset from a
+// remote that records usage stats. Only the dataset owner can view usage
+func (r *RemoteMethods) Usage(ctx context.Context, p *UsageParams) (*remote.DatasetUsage, error) {
+	if r.inst.http != nil {
+		res := &remote.DatasetUsage{}
+		err := r.inst.http.Call(ctx, AERemoteUsage, p, res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	ref, _, err := r.inst.ParseAndResolveRef(ctx, p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := r.inst.remoteAddress(p.Remote)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.inst.RemoteClient().DatasetUsage(ctx, ref, addr)
+}
+
 // Remove asks a remote to remove a dataset
 func (r *RemoteMethods) Remove(ctx context.Context, p *PushParams) (*dsref.Ref, error) {
 	if r.inst.http != nil {
@@ -182,7 +583,7 @@ func (r *RemoteMethods) Remove(ctx context.Context, p *PushParams) (*dsref.Ref,
 		return nil, err
 	}
 
-	addr, err := remote.Address(r.inst.GetConfig(), p.Remote)
+	addr, err := r.inst.remoteAddress(p.Remote)
 	if err != nil {
 		return nil, err
 	}
@@ -197,3 +598,180 @@ func (r *RemoteMethods) Remove(ctx context.Context, p *PushParams) (*dsref.Ref,
 
 	return &ref, nil
 }
+
+// pushDataset sends a dataset to a remote address & marks it published
+func (inst *Instance) pushDataset(ctx context.Context, ref dsref.Ref, addr string) error {
+	if inst.RemoteClient() == nil {
+		return remote.ErrNoRemoteClient
+	}
+	if err := inst.RemoteClient().PushDataset(ctx, ref, addr); err != nil {
+		return err
+	}
+	return base.SetPublishStatus(ctx, inst.node.Repo, ref, true)
+}
+
+// pushDatasetHead sends logbook data & the head components of a dataset to a
+// remote address, skipping body data, & marks it published
+func (inst *Instance) pushDatasetHead(ctx context.Context, ref dsref.Ref, addr string) error {
+	if inst.RemoteClient() == nil {
+		return remote.ErrNoRemoteClient
+	}
+	if err := inst.RemoteClient().PushDatasetHead(ctx, ref, addr); err != nil {
+		return err
+	}
+	return base.SetPublishStatus(ctx, inst.node.Repo, ref, true)
+}
+
+// isRegistryAddress reports if a remote address is the configured registry
+func (inst *Instance) isRegistryAddress(addr string) bool {
+	cfg := inst.GetConfig()
+	return cfg.Registry != nil && cfg.Registry.Location != "" && cfg.Registry.Location == addr
+}
+
+// putRegistrySnapshot uploads a signed metadata snapshot of a dataset version
+// to the registry, letting the registry list, search & preview the dataset
+// without pulling blocks. Snapshots are refreshed on every push
+func (inst *Instance) putRegistrySnapshot(ctx context.Context, ref dsref.Ref) error {
+	if inst.registry == nil {
+		return nil
+	}
+	ds, err := dsfs.LoadDataset(ctx, inst.qfs, ref.Path)
+	if err != nil {
+		return err
+	}
+	pro := inst.repo.Profiles().Owner()
+	ds.Peername = ref.Username
+	ds.Name = ref.Name
+	ds.ProfileID = pro.ID.String()
+	ds.Path = ref.Path
+
+	_, err = inst.registry.PutSnapshot(registry.NewSnapshot(ds), pro.PrivKey)
+	return err
+}
+
+// pinPublished asks configured pinning services to pin a published version.
+// Failures are recorded as pin statuses & don't fail the publish
+func (inst *Instance) pinPublished(ctx context.Context, ref dsref.Ref) []pinning.Status {
+	if inst.pins == nil {
+		return nil
+	}
+	origins := []string{}
+	if inst.node != nil && inst.node.Online {
+		for _, addr := range inst.node.EncapsulatedAddresses() {
+			origins = append(origins, addr.String())
+		}
+	}
+	statuses := inst.pins.Pin(ctx, ref, origins)
+	for _, st := range statuses {
+		if st.State == pinning.PinFailed {
+			log.Warnw("pinning version", "service", st.Service, "ref", ref.String(), "err", st.Error)
+		}
+	}
+	return statuses
+}
+
+// newHeadStore creates the store for dataset heads synced metadata-only
+func newHeadStore(repoPath string) *remote.HeadStore {
+	if repoPath == "" {
+		return remote.NewHeadStore("")
+	}
+	return remote.NewHeadStore(filepath.Join(repoPath, "remote_heads.json"))
+}
+
+// pullHead fetches logbook data & the head components of a dataset version,
+// keeping the head in the instance head store until body data is needed
+func pullHead(ctx context.Context, scope scope, ref dsref.Ref, location string) (*dataset.Dataset, error) {
+	ds, err := scope.RemoteClient().PullDatasetHead(ctx, &ref, location)
+	if err != nil {
+		return nil, err
+	}
+	if err := scope.inst.heads.Put(&remote.Head{Source: location, Dataset: ds}); err != nil {
+		return nil, err
+	}
+	res := &dataset.Dataset{}
+	res.Assign(ds)
+	res.Name = ref.Name
+	res.Peername = ref.Username
+	return res, nil
+}
+
+// fetchBody pulls the full dataset version of a version synced metadata-only
+// from the remote its head came from. loadErr is returned unchanged for any
+// other version, and for contexts restricted to local data
+func (inst *Instance) fetchBody(ctx context.Context, ref dsref.Ref, loadErr error) (*dataset.Dataset, error) {
+	h, err := inst.heads.Get(ref.Path)
+	if err != nil || h.Source == "" || inst.remoteClient == nil || dsfs.LocalOnly(ctx) {
+		return nil, loadErr
+	}
+	log.Debugw("fetching body of metadata-only version", "ref", ref.String(), "source", h.Source)
+	ctx = dsfs.WithOpenTimeout(ctx, dsfs.NoTimeout)
+	ds, err := inst.remoteClient.PullDataset(ctx, &ref, h.Source)
+	if err != nil {
+		return nil, fmt.Errorf("fetching body from %s: %w", h.Source, err)
+	}
+	if err := inst.heads.Delete(ref.Path); err != nil {
+		log.Debugw("removing head of fetched version", "ref", ref.String(), "err", err)
+	}
+	return ds, nil
+}
+
+// markBodyForeign flags versions with a stored head, filling in commit
+// messages from the head
+func markBodyForeign(heads *remote.HeadStore, items []dsref.VersionInfo) {
+	for i, item := range items {
+		if !item.Foreign || item.Path == "" {
+			continue
+		}
+		h, err := heads.Get(item.Path)
+		if err != nil {
+			continue
+		}
+		items[i].Foreign = false
+		items[i].BodyForeign = true
+		if h.Dataset.Commit != nil {
+			items[i].CommitMessage = h.Dataset.Commit.Message
+		}
+	}
+}
+
+// startPushQueue creates the instance push queue & retries queued pushes in
+// the background until the instance context is cancelled
+func (inst *Instance) startPushQueue(ctx context.Context, repoPath string) (err error) {
+	filename := ""
+	if repoPath != "" {
+		filename = filepath.Join(repoPath, "push_queue.json")
+	}
+	if inst.pushQueue, err = remote.NewPushQueue(filename, inst.pushDataset); err != nil {
+		return err
+	}
+	inst.releasers.Add(1)
+	go func() {
+		inst.pushQueue.Run(ctx, inst.bus)
+		inst.releasers.Done()
+	}()
+	return nil
+}
+
+// checkRemoteHead returns ErrBehindRemote if the remote at addr has versions
+// of a dataset that aren't saved locally. An empty addr checks the remote
+// configured for the dataset
+func checkRemoteHead(scope scope, ref dsref.Ref, addr string) error {
+	if scope.RemoteClient() == nil {
+		return remote.ErrNoRemoteClient
+	}
+	if addr == "" {
+		var err error
+		if addr, err = scope.inst.remoteAddress(scope.inst.datasetSettings(ref.InitID).Remote); err != nil {
+			return err
+		}
+	}
+
+	_, behind, err := compareRemoteVersions(scope, ref, addr)
+	if err != nil {
+		return fmt.Errorf("checking remote versions: %w", err)
+	}
+	if behind > 0 {
+		return fmt.Errorf("%w: %s has %d version(s) on %s that aren't saved locally. pull the latest version first, or override the check to continue", ErrBehindRemote, ref.Human(), behind, addr)
+	}
+	return nil
+}
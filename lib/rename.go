@@ -0,0 +1,153 @@
+package lib
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/dsref"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+const (
+	// RenameUpdateFSI is a working directory link file
+	RenameUpdateFSI = "fsi"
+	// RenameUpdatePush is a push waiting in the push queue
+	RenameUpdatePush = "push"
+	// RenameUpdateIndex is a content search index entry
+	RenameUpdateIndex = "index"
+	// RenameUpdateTransform is a transform script that loads the renamed
+	// dataset
+	RenameUpdateTransform = "transform"
+)
+
+// RenameResult describes a renamed dataset & the references to it that were
+// moved to the new name
+type RenameResult struct {
+	dsref.VersionInfo
+	// Updated lists references that now use the new name
+	Updated []RenameUpdate `json:"updated"`
+	// Stale lists transforms of saved versions that still load the dataset by
+	// its previous name. Versions can't be changed, saving a new version with
+	// an updated script fixes the transform
+	Stale []RenameUpdate `json:"stale,omitempty"`
+}
+
+// RenameUpdate is a single reference to a renamed dataset
+type RenameUpdate struct {
+	// Kind is one of the RenameUpdate constants
+	Kind string `json:"kind"`
+	// Ref is the dataset that holds the reference
+	Ref string `json:"ref"`
+	// Path is the file or version holding the reference, if any
+	Path string `json:"path,omitempty"`
+}
+
+// updateRenamedReferences moves references to prev, a dataset that's been
+// renamed to vi, recording each update. Failing to update a reference never
+// fails the rename
+func updateRenamedReferences(scope scope, prev dsref.Ref, vi *dsref.VersionInfo, res *RenameResult) {
+	ctx := scope.Context()
+	inst := scope.inst
+	next := vi.SimpleRef()
+
+	if inst.pushQueue != nil && vi.InitID != "" {
+		if n := inst.pushQueue.Rename(vi.InitID, vi.Name); n > 0 {
+			res.Updated = append(res.Updated, RenameUpdate{Kind: RenameUpdatePush, Ref: next.Alias()})
+		}
+	}
+
+	if inst.contentIndex != nil {
+		if moved, err := inst.contentIndex.Rename(prev.Alias(), next.Alias()); err != nil {
+			log.Debugw("rename moving content index entry", "ref", prev.Alias(), "err", err)
+		} else if moved {
+			res.Updated = append(res.Updated, RenameUpdate{Kind: RenameUpdateIndex, Ref: next.Alias()})
+		}
+	}
+
+	r := scope.Repo()
+	num, err := r.RefCount()
+	if err != nil {
+		log.Debugw("rename counting dependent datasets", "err", err)
+		return
+	}
+	refs, err := r.References(0, num)
+	if err != nil {
+		log.Debugw("rename listing dependent datasets", "err", err)
+		return
+	}
+	rx := renamedRefRegexp(prev)
+	for _, ref := range refs {
+		if err := updateRenamedTransform(ctx, scope, ref, rx, next, res); err != nil {
+			log.Debugw("rename updating transform", "ref", ref.AliasString(), "err", err)
+		}
+	}
+}
+
+// renamedRefRegexp matches quoted references to a dataset, with an optional
+// version path. "me" is matched as an alias for the owner
+func renamedRefRegexp(prev dsref.Ref) *regexp.Regexp {
+	users := regexp.QuoteMeta(prev.Username) + "|me"
+	return regexp.MustCompile(`(["'])(` + users + `)/` + regexp.QuoteMeta(prev.Name) + `((?:@[^"']*)?["'])`)
+}
+
+// updateRenamedTransform rewrites references in the transform script of a
+// linked working directory. Transforms of saved versions are reported as
+// stale
+func updateRenamedTransform(ctx context.Context, scope scope, ref reporef.DatasetRef, rx *regexp.Regexp, next dsref.Ref, res *RenameResult) error {
+	repl := "${1}${2}/" + next.Name + "${3}"
+
+	if ref.FSIPath != "" {
+		filename := filepath.Join(ref.FSIPath, "transform.star")
+		data, err := ioutil.ReadFile(filename)
+		if err == nil {
+			if !rx.Match(data) {
+				return nil
+			}
+			if err := ioutil.WriteFile(filename, rx.ReplaceAll(data, []byte(repl)), 0644); err != nil {
+				return err
+			}
+			res.Updated = append(res.Updated, RenameUpdate{Kind: RenameUpdateTransform, Ref: ref.AliasString(), Path: filename})
+			return nil
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		// without a transform file the working directory uses the transform of
+		// the head version
+	}
+
+	if ref.Path == "" {
+		return nil
+	}
+	fs := scope.Filesystem()
+	ds, err := dsfs.LoadDataset(ctx, fs, ref.Path)
+	if err != nil || ds.Transform == nil {
+		// versions that aren't stored locally can't be checked
+		return nil
+	}
+	found := false
+	for _, step := range ds.Transform.Steps {
+		if str, ok := step.Script.(string); ok && rx.MatchString(str) {
+			found = true
+		}
+	}
+	if !found && ds.Transform.ScriptPath != "" {
+		f, err := fs.Get(ctx, ds.Transform.ScriptPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		found = rx.Match(data)
+	}
+	if found {
+		res.Stale = append(res.Stale, RenameUpdate{Kind: RenameUpdateTransform, Ref: ref.AliasString(), Path: ref.Path})
+	}
+	return nil
+}
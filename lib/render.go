@@ -173,7 +173,7 @@ func (m *RenderMethods) RenderReadme(ctx context.Context, p *RenderParams) ([]by
 		return nil, fmt.Errorf("no readme to render")
 	}
 
-	res, err := base.RenderReadme(ctx, ds.Readme.ScriptFile())
+	res, err := base.RenderReadmeFormat(ctx, ds.Readme.ScriptFile(), ds.Readme.Format)
 	if err != nil {
 		return nil, err
 	}
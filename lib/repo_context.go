@@ -0,0 +1,140 @@
+package lib
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/ghodss/yaml"
+)
+
+// RepoContext is a named set of defaults for working with a qri repo. Contexts
+// let a user with more than one repo switch between them by name
+type RepoContext struct {
+	// Name of the context
+	Name string `json:"name"`
+	// RepoPath is the filepath of the qri repo the context uses
+	RepoPath string `json:"repoPath"`
+	// Remote is the name of the remote to use when none is given, empty uses
+	// the registry
+	Remote string `json:"remote,omitempty"`
+	// ConfigProfile is the name of a configuration profile to merge over the
+	// repo config
+	ConfigProfile string `json:"configProfile,omitempty"`
+}
+
+var validRepoContextName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// RepoContexts is a list of contexts & the name of the context in use. Contexts
+// are stored independently of any repo, usually in the user's home directory
+type RepoContexts struct {
+	// Current is the name of the context in use, empty if no context is used
+	Current  string         `json:"current,omitempty"`
+	Contexts []*RepoContext `json:"contexts"`
+}
+
+// LoadRepoContexts reads contexts from a YAML file. A missing file is an empty
+// list of contexts
+func LoadRepoContexts(path string) (*RepoContexts, error) {
+	rcs := &RepoContexts{Contexts: []*RepoContext{}}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return rcs, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, rcs); err != nil {
+		return nil, fmt.Errorf("reading contexts file %q: %w", path, err)
+	}
+	return rcs, nil
+}
+
+// Save writes contexts to a YAML file
+func (rcs *RepoContexts) Save(path string) error {
+	data, err := yaml.Marshal(rcs)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Get finds a context by name
+func (rcs *RepoContexts) Get(name string) (*RepoContext, error) {
+	for _, rc := range rcs.Contexts {
+		if rc.Name == name {
+			return rc, nil
+		}
+	}
+	return nil, fmt.Errorf("context %q not found", name)
+}
+
+// CurrentContext returns the context in use, or nil if no context is used
+func (rcs *RepoContexts) CurrentContext() (*RepoContext, error) {
+	if rcs.Current == "" {
+		return nil, nil
+	}
+	return rcs.Get(rcs.Current)
+}
+
+// Set adds a context, replacing any existing context with the same name.
+// Contexts are kept ordered by name
+func (rcs *RepoContexts) Set(rc *RepoContext) error {
+	if !validRepoContextName.MatchString(rc.Name) {
+		return fmt.Errorf("invalid context name %q. names may only contain letters, numbers, dashes & underscores", rc.Name)
+	}
+	if rc.RepoPath == "" {
+		return fmt.Errorf("context %q requires a repo path", rc.Name)
+	}
+
+	for i, c := range rcs.Contexts {
+		if c.Name == rc.Name {
+			rcs.Contexts[i] = rc
+			return nil
+		}
+	}
+	rcs.Contexts = append(rcs.Contexts, rc)
+	sort.Slice(rcs.Contexts, func(i, j int) bool { return rcs.Contexts[i].Name < rcs.Contexts[j].Name })
+	return nil
+}
+
+// Remove deletes a context. Removing the current context leaves no context
+// in use
+func (rcs *RepoContexts) Remove(name string) error {
+	for i, c := range rcs.Contexts {
+		if c.Name == name {
+			rcs.Contexts = append(rcs.Contexts[:i], rcs.Contexts[i+1:]...)
+			if rcs.Current == name {
+				rcs.Current = ""
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("context %q not found", name)
+}
+
+// Use sets the current context. An empty name stops using contexts
+func (rcs *RepoContexts) Use(name string) error {
+	if name != "" {
+		if _, err := rcs.Get(name); err != nil {
+			return err
+		}
+	}
+	rcs.Current = name
+	return nil
+}
+
+// OptRepoContext configures an instance with the defaults of a context. The
+// repo path given to NewInstance must be the context's repo path. Config
+// profiles set with OptConfigProfile take precedence over the context's profile
+func OptRepoContext(rc *RepoContext) Option {
+	return func(o *InstanceOptions) error {
+		o.repoContext = rc
+		return nil
+	}
+}
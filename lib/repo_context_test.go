@@ -0,0 +1,73 @@
+package lib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRepoContexts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repo_contexts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "contexts.yaml")
+
+	rcs, err := LoadRepoContexts(path)
+	if err != nil {
+		t.Fatalf("loading missing file should be an empty list. got: %s", err)
+	}
+	if rc, err := rcs.CurrentContext(); err != nil || rc != nil {
+		t.Errorf("expected no current context. got: %v, %v", rc, err)
+	}
+
+	if err := rcs.Set(&RepoContext{Name: "bad name", RepoPath: "/a"}); err == nil {
+		t.Error("expected invalid name to error")
+	}
+	if err := rcs.Set(&RepoContext{Name: "work"}); err == nil {
+		t.Error("expected missing repo path to error")
+	}
+
+	work := &RepoContext{Name: "work", RepoPath: "/repos/work", Remote: "corp"}
+	personal := &RepoContext{Name: "personal", RepoPath: "/repos/personal", ConfigProfile: "offline"}
+	for _, rc := range []*RepoContext{work, personal} {
+		if err := rcs.Set(rc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rcs.Use("nope"); err == nil {
+		t.Error("expected using an unknown context to error")
+	}
+	if err := rcs.Use("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rcs.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadRepoContexts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &RepoContexts{
+		Current:  "work",
+		Contexts: []*RepoContext{personal, work},
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("loaded contexts mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := got.Remove("work"); err != nil {
+		t.Fatal(err)
+	}
+	if got.Current != "" {
+		t.Errorf("expected removing the current context to clear it. got: %q", got.Current)
+	}
+	if err := got.Remove("work"); err == nil {
+		t.Error("expected removing an unknown context to error")
+	}
+}
@@ -3,9 +3,11 @@ package lib
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/fsi"
+	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/remote"
 )
 
@@ -25,18 +27,24 @@ func (inst *Instance) ParseAndResolveRef(ctx context.Context, refStr, source str
 }
 
 // ParseAndResolveRefWithWorkingDir combines reference parsing and resolution,
-// including setting default Path to a linked working directory if one exists
+// including setting default Path to a linked working directory if one exists.
+// References may select a version by time, eg: peer/name@2023-06-01
 func (inst *Instance) ParseAndResolveRefWithWorkingDir(ctx context.Context, refStr, source string) (dsref.Ref, string, error) {
-	ref, err := dsref.Parse(refStr)
+	ref, at, err := dsref.ParseAtTime(refStr)
 	if err != nil && err != dsref.ErrBadCaseName {
 		return ref, "", fmt.Errorf("%q is not a valid dataset reference: %w", refStr, err)
 	}
 
-	pathProvided := ref.Path != ""
+	pathProvided := ref.Path != "" || !at.IsZero()
 	resolvedSource, err := inst.ResolveReference(ctx, &ref, source)
 	if err != nil {
 		return ref, resolvedSource, err
 	}
+	if !at.IsZero() {
+		if err = resolvePathAtTime(ctx, inst.logbook, &ref, at); err != nil {
+			return ref, resolvedSource, err
+		}
+	}
 	if !pathProvided {
 		err = inst.fsi.ResolvedPath(&ref)
 		if err == fsi.ErrNoLink {
@@ -46,6 +54,21 @@ func (inst *Instance) ParseAndResolveRefWithWorkingDir(ctx context.Context, refS
 	return ref, resolvedSource, err
 }
 
+// resolvePathAtTime sets the path of a resolved reference to the latest
+// version saved at or before a point in time, walking the commit timestamps
+// in the dataset's logbook
+func resolvePathAtTime(ctx context.Context, book *logbook.Book, ref *dsref.Ref, at time.Time) error {
+	if book == nil {
+		return fmt.Errorf("resolving %s by time requires a logbook", ref.Human())
+	}
+	vi, err := book.VersionAtTime(ctx, *ref, at)
+	if err != nil {
+		return err
+	}
+	ref.Path = vi.Path
+	return nil
+}
+
 // ResolveReference finds the identifier & HEAD path for a dataset reference.
 // the mode parameter determines which subsystems of Qri to use when resolving
 func (inst *Instance) ResolveReference(ctx context.Context, ref *dsref.Ref, mode string) (string, error) {
@@ -112,6 +135,9 @@ func (inst *Instance) defaultResolver() dsref.Resolver {
 }
 
 func (inst *Instance) registryResolver() dsref.Resolver {
+	if inst.remoteClient == nil {
+		return errResolver{fmt.Errorf("%w: no registry client to resolve with", dsref.ErrRefNotFound)}
+	}
 	var location string
 	if inst.cfg.Registry != nil {
 		location = inst.cfg.Registry.Location
@@ -119,6 +145,15 @@ func (inst *Instance) registryResolver() dsref.Resolver {
 	return inst.remoteClient.NewRemoteRefResolver(location)
 }
 
+// errResolver fails every resolution with an error
+type errResolver struct {
+	err error
+}
+
+func (r errResolver) ResolveRef(ctx context.Context, ref *dsref.Ref) (string, error) {
+	return "", r.err
+}
+
 func (inst *Instance) p2pResolver() dsref.Resolver {
 	return inst.node.NewP2PRefResolver()
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs/muxfs"
+	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/dscache"
 	"github.com/qri-io/qri/dsref"
@@ -17,6 +18,7 @@ import (
 	"github.com/qri-io/qri/remote"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/stats"
+	"github.com/qri-io/qri/transform/run"
 )
 
 // scope represents the lifetime of a method call, abstractly connected to the caller of
@@ -38,6 +40,16 @@ func newScope(ctx context.Context, inst *Instance, source string) (scope, error)
 		return scope{}, err
 	}
 
+	if inst.cfg != nil && inst.cfg.Repo != nil {
+		timeouts, err := inst.cfg.Repo.OpenTimeoutDurations()
+		if err != nil {
+			return scope{}, err
+		}
+		if timeouts != nil {
+			ctx = dsfs.WithOpenTimeouts(ctx, timeouts)
+		}
+	}
+
 	return scope{
 		ctx:    ctx,
 		inst:   inst,
@@ -50,6 +62,16 @@ func (s *scope) ActiveProfile() *profile.Profile {
 	return s.pro
 }
 
+// BodyFetches returns the store of conditional-request state for body URLs
+func (s *scope) BodyFetches() *bodyFetchStore {
+	return s.inst.bodyFetches
+}
+
+// RunLogs returns the store of transform run logs
+func (s *scope) RunLogs() *run.LogStore {
+	return s.inst.runLogs
+}
+
 // Bus returns the event bus
 func (s *scope) Bus() event.Bus {
 	// TODO(dustmop): Filter only events for this scope.
@@ -139,7 +161,7 @@ func (s *scope) ParseAndResolveRefWithWorkingDir(ctx context.Context, refstr, _
 // dsref.Loader interface, see https://github.com/qri-io/qri/issues/1704
 func (s *scope) ParseResolveFunc() dsref.ParseResolveLoad {
 	resolver, _ := s.inst.resolverForMode(s.source)
-	return NewParseResolveLoadFunc(s.ActiveProfile().Peername, resolver, s.inst)
+	return newParseResolveLoadFunc(s.ActiveProfile().Peername, resolver, s.inst, s.inst.logbook)
 }
 
 // Profiles accesses the profile store
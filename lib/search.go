@@ -22,7 +22,8 @@ func (m SearchMethods) Name() string {
 // Attributes defines attributes for each method
 func (m SearchMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
-		"search": {AESearch, "POST"},
+		"search":       {endpoint: AESearch, verb: "POST", readOnly: true},
+		"indexcontent": {endpoint: AESearchIndexContent, verb: "POST"},
 	}
 }
 
@@ -31,6 +32,9 @@ type SearchParams struct {
 	Query  string `json:"q"`
 	Limit  int    `json:"limit,omitempty"`
 	Offset int    `json:"offset,omitempty"`
+	// Body searches the local index of dataset body values instead of the
+	// registry. Only datasets with content indexing enabled are searched
+	Body bool `json:"body,omitempty"`
 }
 
 // UnmarshalFromRequest implements a custom deserialization-from-HTTP request
@@ -50,6 +54,9 @@ func (p *SearchParams) UnmarshalFromRequest(r *http.Request) error {
 	if p.Query == "" {
 		p.Query = r.FormValue("q")
 	}
+	if !p.Body {
+		p.Body = r.FormValue("body") == "true"
+	}
 
 	return nil
 }
@@ -70,6 +77,39 @@ func (m SearchMethods) Search(ctx context.Context, p *SearchParams) ([]SearchRes
 	return nil, dispatchReturnError(got, err)
 }
 
+// IndexContentParams defines parameters for the IndexContent method
+type IndexContentParams struct {
+	Ref string `json:"ref"`
+	// Disable stops indexing the dataset body, removing it from the index
+	Disable bool `json:"disable,omitempty"`
+}
+
+// ContentIndexStatus describes the content index state of a dataset
+type ContentIndexStatus struct {
+	Ref     string `json:"ref"`
+	Enabled bool   `json:"enabled"`
+	// Path is the indexed version
+	Path string `json:"path,omitempty"`
+	// Tokens is the number of distinct tokens indexed
+	Tokens int `json:"tokens"`
+	// Truncated is true when size limits stopped indexing before the whole
+	// body was read
+	Truncated bool   `json:"truncated,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// IndexContent marks a dataset as content indexed, tokenizing the values of
+// string columns in its body into the local search index. Content indexed
+// datasets are re-indexed each time a version is saved. Indexing reads at
+// most ContentIndexMaxRows rows of the body
+func (m SearchMethods) IndexContent(ctx context.Context, p *IndexContentParams) (*ContentIndexStatus, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "indexcontent"), p)
+	if res, ok := got.(*ContentIndexStatus); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
 // Implementations for FSI methods follow
 
 // searchImpl holds the method implementations for search
@@ -77,6 +117,9 @@ type searchImpl struct{}
 
 // Search queries for items on qri related to given parameters
 func (searchImpl) Search(scope scope, p *SearchParams) ([]SearchResult, error) {
+	if p.Body {
+		return searchContent(scope, p)
+	}
 	client := scope.RegistryClient()
 	if client == nil {
 		return nil, repo.ErrNoRegistry
@@ -100,3 +143,61 @@ func (searchImpl) Search(scope scope, p *SearchParams) ([]SearchResult, error) {
 	}
 	return searchResults, nil
 }
+
+// searchContent queries the local content index
+func searchContent(scope scope, p *SearchParams) ([]SearchResult, error) {
+	refs, err := scope.inst.contentIndex.Search(p.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Offset > len(refs) {
+		p.Offset = len(refs)
+	}
+	refs = refs[p.Offset:]
+	if p.Limit > 0 && p.Limit < len(refs) {
+		refs = refs[:p.Limit]
+	}
+
+	res := make([]SearchResult, len(refs))
+	for i, ref := range refs {
+		res[i].Type = "dataset"
+		res[i].ID = ref.Path
+		res[i].Value = &dataset.Dataset{
+			Peername: ref.Username,
+			Name:     ref.Name,
+			Path:     ref.Path,
+		}
+	}
+	return res, nil
+}
+
+// IndexContent enables or disables content indexing for a dataset
+func (searchImpl) IndexContent(scope scope, p *IndexContentParams) (*ContentIndexStatus, error) {
+	ref, _, err := scope.ParseAndResolveRef(scope.Context(), p.Ref, "local")
+	if err != nil {
+		return nil, err
+	}
+	alias := ref.Alias()
+	if p.Disable {
+		if err := scope.inst.contentIndex.Remove(alias); err != nil {
+			return nil, err
+		}
+		return &ContentIndexStatus{Ref: alias}, nil
+	}
+
+	ic, err := scope.inst.updateContentIndex(scope.Context(), ref)
+	if ic == nil {
+		return nil, err
+	}
+	// failing to index leaves the dataset enabled, it's indexed again on the
+	// next save
+	return &ContentIndexStatus{
+		Ref:       alias,
+		Enabled:   true,
+		Path:      ic.Path,
+		Tokens:    len(ic.Tokens),
+		Truncated: ic.Truncated,
+		Error:     ic.Error,
+	}, nil
+}
@@ -0,0 +1,286 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/repo/jsonfile"
+	"github.com/qri-io/qri/warehouse"
+)
+
+var (
+	// ContentIndexMaxRows is the number of body rows read when indexing
+	// a dataset's content. Rows past the limit aren't searchable
+	ContentIndexMaxRows = 100000
+	// ContentIndexMaxTokens is the number of distinct tokens kept for each
+	// content indexed dataset
+	ContentIndexMaxTokens = 50000
+	// ContentIndexMaxTokenLength is the length in bytes of the longest token
+	// that is indexed, longer tokens are skipped
+	ContentIndexMaxTokenLength = 64
+)
+
+// indexedContent is the content index entry for a single dataset
+type indexedContent struct {
+	// Path of the indexed version, empty until the dataset is first indexed
+	Path string `json:"path,omitempty"`
+	// Tokens are the distinct tokens of string values in the body
+	Tokens []string `json:"tokens,omitempty"`
+	// Truncated is true when size limits stopped indexing before the whole
+	// body was read
+	Truncated bool   `json:"truncated,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// contentIndex persists tokenized body values of datasets that have opted in
+// to content indexing, keyed by alias. An index with an empty filename is
+// kept in memory
+type contentIndex struct {
+	sync.Mutex
+	file     jsonfile.File
+	datasets map[string]*indexedContent
+}
+
+func newContentIndex(repoPath string) *contentIndex {
+	idx := &contentIndex{datasets: map[string]*indexedContent{}}
+	if repoPath != "" {
+		idx.file = jsonfile.New(filepath.Join(repoPath, "content_index.json"))
+	}
+	return idx
+}
+
+// Enabled reports whether a dataset is content indexed
+func (idx *contentIndex) Enabled(alias string) (bool, error) {
+	idx.Lock()
+	defer idx.Unlock()
+	if err := idx.load(); err != nil {
+		return false, err
+	}
+	_, ok := idx.datasets[alias]
+	return ok, nil
+}
+
+// Put sets the index entry for a dataset, enabling content indexing
+func (idx *contentIndex) Put(alias string, ic *indexedContent) error {
+	idx.Lock()
+	defer idx.Unlock()
+	if err := idx.load(); err != nil {
+		return err
+	}
+	idx.datasets[alias] = ic
+	return idx.save()
+}
+
+// Remove disables content indexing for a dataset, dropping its tokens
+func (idx *contentIndex) Remove(alias string) error {
+	idx.Lock()
+	defer idx.Unlock()
+	if err := idx.load(); err != nil {
+		return err
+	}
+	delete(idx.datasets, alias)
+	return idx.save()
+}
+
+// Rename moves the index entry of a renamed dataset, returning false if the
+// dataset isn't content indexed
+func (idx *contentIndex) Rename(from, to string) (bool, error) {
+	idx.Lock()
+	defer idx.Unlock()
+	if err := idx.load(); err != nil {
+		return false, err
+	}
+	ic, ok := idx.datasets[from]
+	if !ok {
+		return false, nil
+	}
+	delete(idx.datasets, from)
+	idx.datasets[to] = ic
+	return true, idx.save()
+}
+
+// Search finds datasets whose body contains every token in the query,
+// returning matching aliases & the path of the version they were found in,
+// ordered by alias
+func (idx *contentIndex) Search(query string) ([]dsref.Ref, error) {
+	idx.Lock()
+	defer idx.Unlock()
+	if err := idx.load(); err != nil {
+		return nil, err
+	}
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query has no searchable terms")
+	}
+
+	res := []dsref.Ref{}
+	for alias, ic := range idx.datasets {
+		if ic.Path == "" || !containsTokens(ic.Tokens, terms) {
+			continue
+		}
+		ref, err := dsref.ParseHumanFriendly(alias)
+		if err != nil {
+			continue
+		}
+		ref.Path = ic.Path
+		res = append(res, ref)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Alias() < res[j].Alias() })
+	return res, nil
+}
+
+func (idx *contentIndex) load() error {
+	return idx.file.Load(&idx.datasets)
+}
+
+func (idx *contentIndex) save() error {
+	return idx.file.Save(idx.datasets)
+}
+
+// containsTokens checks a sorted list of tokens has every term
+func containsTokens(tokens, terms []string) bool {
+	for _, term := range terms {
+		i := sort.SearchStrings(tokens, term)
+		if i == len(tokens) || tokens[i] != term {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenize splits text into lowercase runs of letters & digits
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// indexContent tokenizes the string column values of an open dataset body,
+// reading at most ContentIndexMaxRows rows & keeping at most
+// ContentIndexMaxTokens distinct tokens
+func indexContent(ds *dataset.Dataset) (*indexedContent, error) {
+	if ds.BodyFile() == nil {
+		return nil, fmt.Errorf("dataset has no body")
+	}
+	cols, err := warehouse.Columns(ds.Structure)
+	if err != nil {
+		return nil, fmt.Errorf("content indexing requires a tabular body: %w", err)
+	}
+	strCols := map[int]bool{}
+	strNames := map[string]bool{}
+	for i, c := range cols {
+		if c.Type == warehouse.ColumnString {
+			strCols[i] = true
+			strNames[c.Name] = true
+		}
+	}
+
+	r, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	ic := &indexedContent{Path: ds.Path}
+	seen := map[string]bool{}
+	add := func(v interface{}) {
+		s, ok := v.(string)
+		if !ok {
+			return
+		}
+		for _, tok := range tokenize(s) {
+			if len(tok) > ContentIndexMaxTokenLength || seen[tok] {
+				continue
+			}
+			if len(seen) >= ContentIndexMaxTokens {
+				ic.Truncated = true
+				return
+			}
+			seen[tok] = true
+		}
+	}
+
+	for rows := 0; ; rows++ {
+		if rows >= ContentIndexMaxRows {
+			ic.Truncated = true
+			break
+		}
+		ent, err := r.ReadEntry()
+		if err != nil {
+			if err == io.EOF || err.Error() == "EOF" {
+				break
+			}
+			return nil, err
+		}
+		switch row := ent.Value.(type) {
+		case []interface{}:
+			for i, v := range row {
+				if strCols[i] {
+					add(v)
+				}
+			}
+		case map[string]interface{}:
+			for k, v := range row {
+				if strNames[warehouse.Identifier(k)] {
+					add(v)
+				}
+			}
+		}
+	}
+
+	ic.Tokens = make([]string, 0, len(seen))
+	for tok := range seen {
+		ic.Tokens = append(ic.Tokens, tok)
+	}
+	sort.Strings(ic.Tokens)
+	return ic, nil
+}
+
+// subscribeContentIndex re-indexes content indexed datasets as new versions
+// are saved
+func (inst *Instance) subscribeContentIndex() {
+	inst.bus.SubscribeTypes(func(ctx context.Context, e event.Event) error {
+		p, ok := e.Payload.(event.DsSaveEvent)
+		if !ok || p.Error != nil || p.Path == "" {
+			return nil
+		}
+		ref := dsref.Ref{Username: p.Username, Name: p.Name, Path: p.Path}
+		if enabled, err := inst.contentIndex.Enabled(ref.Alias()); err != nil || !enabled {
+			return nil
+		}
+		// indexing problems are recorded in the index, they never fail a save
+		if _, err := inst.updateContentIndex(ctx, ref); err != nil {
+			log.Debugw("indexing dataset content", "ref", ref.Alias(), "err", err)
+		}
+		return nil
+	}, event.ETDatasetSaveCompleted)
+}
+
+// updateContentIndex indexes the body of a dataset version, returning the
+// stored index entry. Failures are stored in the entry's Error field
+func (inst *Instance) updateContentIndex(ctx context.Context, ref dsref.Ref) (*indexedContent, error) {
+	ic, err := func() (*indexedContent, error) {
+		ds, err := inst.LoadDataset(ctx, ref, "")
+		if err != nil {
+			return nil, err
+		}
+		return indexContent(ds)
+	}()
+	if err != nil {
+		ic = &indexedContent{Path: ref.Path, Error: err.Error()}
+	}
+	if putErr := inst.contentIndex.Put(ref.Alias(), ic); putErr != nil {
+		return nil, putErr
+	}
+	return ic, err
+}
@@ -6,6 +6,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/config"
 	testcfg "github.com/qri-io/qri/config/test"
 	"github.com/qri-io/qri/event"
@@ -34,7 +37,7 @@ func TestSearch(t *testing.T) {
 	inst := NewInstanceFromConfigAndNode(ctx, config.DefaultConfig(), node)
 	inst.registry = rc
 
-	p := &SearchParams{"nuun", 0, 100}
+	p := &SearchParams{Query: "nuun", Limit: 0, Offset: 100}
 	got, err := inst.Search().Search(ctx, p)
 	if err != nil {
 		t.Error(err)
@@ -45,6 +48,109 @@ func TestSearch(t *testing.T) {
 	}
 }
 
+func TestSearchContent(t *testing.T) {
+	run := newTestRunner(t)
+	defer run.Delete()
+
+	ctx := run.Ctx
+	body := run.MustWriteTmpFile(t, "cities.csv", "city,pop\nToronto,50000\nNew York,8500000\n")
+	if _, err := run.SaveWithParams(&SaveParams{Ref: "me/cities", BodyPath: body}); err != nil {
+		t.Fatal(err)
+	}
+	other := run.MustWriteTmpFile(t, "ports.csv", "port,ships\nRotterdam,12\n")
+	if _, err := run.SaveWithParams(&SaveParams{Ref: "me/ports", BodyPath: other}); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(q string) []string {
+		t.Helper()
+		res, err := run.Instance.Search().Search(ctx, &SearchParams{Query: q, Body: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, r := range res {
+			names = append(names, r.Value.Name)
+		}
+		return names
+	}
+
+	// datasets aren't content indexed until they opt in
+	if got := search("toronto"); len(got) != 0 {
+		t.Errorf("expected no results before indexing, got: %v", got)
+	}
+
+	status, err := run.Instance.Search().IndexContent(ctx, &IndexContentParams{Ref: "me/cities"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Enabled || status.Tokens != 3 || status.Error != "" {
+		t.Errorf("unexpected index status: %#v", status)
+	}
+
+	if diff := cmp.Diff([]string{"cities"}, search("new YORK")); diff != "" {
+		t.Errorf("search result mismatch (-want +got):\n%s", diff)
+	}
+	// numeric columns aren't indexed
+	if got := search("50000"); len(got) != 0 {
+		t.Errorf("expected numeric values not to be indexed, got: %v", got)
+	}
+
+	// saving a new version re-indexes the body
+	body = run.MustWriteTmpFile(t, "cities.csv", "city,pop\nToronto,50000\nChicago,2700000\n")
+	if _, err := run.SaveWithParams(&SaveParams{Ref: "me/cities", BodyPath: body}); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("york"); len(got) != 0 {
+		t.Errorf("expected removed values not to match after saving, got: %v", got)
+	}
+	if diff := cmp.Diff([]string{"cities"}, search("chicago")); diff != "" {
+		t.Errorf("search result mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := run.Instance.Search().IndexContent(ctx, &IndexContentParams{Ref: "me/cities", Disable: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("chicago"); len(got) != 0 {
+		t.Errorf("expected no results after disabling indexing, got: %v", got)
+	}
+}
+
+func TestIndexContentLimits(t *testing.T) {
+	prevRows := ContentIndexMaxRows
+	defer func() { ContentIndexMaxRows = prevRows }()
+	ContentIndexMaxRows = 1
+
+	ds := &dataset.Dataset{
+		Path: "/mem/QmVersion",
+		Structure: &dataset.Structure{
+			Format: "json",
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": []interface{}{
+						map[string]interface{}{"title": "name", "type": "string"},
+						map[string]interface{}{"title": "count", "type": "integer"},
+					},
+				},
+			},
+		},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(`[["first row",1],["second row",2]]`)))
+
+	ic, err := indexContent(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"first", "row"}, ic.Tokens); diff != "" {
+		t.Errorf("tokens mismatch (-want +got):\n%s", diff)
+	}
+	if !ic.Truncated {
+		t.Error("expected index to be marked truncated")
+	}
+}
+
 var mockResponse = []byte(`{"data":[
   {
     "Type": "dataset",
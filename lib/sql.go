@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/sql"
 )
 
@@ -18,7 +20,8 @@ func (m SQLMethods) Name() string { return "sql" }
 // Attributes defines attributes for each method
 func (m SQLMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
-		"exec": {AESQL, "POST"},
+		"exec":    {endpoint: AESQL, verb: "POST", readOnly: true},
+		"explain": {endpoint: AESQLExplain, verb: "POST", readOnly: true},
 	}
 }
 
@@ -26,6 +29,22 @@ func (m SQLMethods) Attributes() map[string]AttributeSet {
 type SQLQueryParams struct {
 	Query  string
 	Format string
+	// Tables maps table names to dataset references, letting queries use
+	// short names like "FROM pop" in place of "FROM me/annual_pop"
+	Tables map[string]string
+}
+
+// loader wraps a dataset loader, substituting references for table names
+func (p *SQLQueryParams) loader(load dsref.ParseResolveLoad) dsref.ParseResolveLoad {
+	if len(p.Tables) == 0 {
+		return load
+	}
+	return func(ctx context.Context, refStr string) (*dataset.Dataset, error) {
+		if ref, ok := p.Tables[refStr]; ok {
+			refStr = ref
+		}
+		return load(ctx, refStr)
+	}
 }
 
 // SetNonZeroDefaults sets format to "json" if it's value is an empty string
@@ -44,6 +63,20 @@ func (m SQLMethods) Exec(ctx context.Context, p *SQLQueryParams) ([]byte, error)
 	return nil, dispatchReturnError(got, err)
 }
 
+// SQLPlan describes how a query will be executed
+type SQLPlan = sql.Plan
+
+// Explain reports how an SQL query will be executed without running it,
+// listing the datasets it loads, how each dataset is scanned, join order &
+// estimated row counts
+func (m SQLMethods) Explain(ctx context.Context, p *SQLQueryParams) (*SQLPlan, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "explain"), p)
+	if res, ok := got.(*SQLPlan); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
 // Implementations for SQL methods follow
 
 // sqlImpl holds the method implementations for SQL
@@ -51,7 +84,7 @@ type sqlImpl struct{}
 
 // Exec runs an SQL query
 func (sqlImpl) Exec(scope scope, p *SQLQueryParams) ([]byte, error) {
-	loadFunc := scope.ParseResolveFunc()
+	loadFunc := p.loader(scope.ParseResolveFunc())
 	svc := sql.New(scope.Repo(), loadFunc)
 
 	buf := &bytes.Buffer{}
@@ -61,3 +94,9 @@ func (sqlImpl) Exec(scope scope, p *SQLQueryParams) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+// Explain reports how an SQL query will be executed
+func (sqlImpl) Explain(scope scope, p *SQLQueryParams) (*SQLPlan, error) {
+	svc := sql.New(scope.Repo(), p.loader(scope.ParseResolveFunc()))
+	return svc.Explain(scope.Context(), p.Query)
+}
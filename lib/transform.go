@@ -4,15 +4,27 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path/filepath"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/preview"
+	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/fsi"
 	"github.com/qri-io/qri/transform"
 	"github.com/qri-io/qri/transform/run"
 )
 
+// httpCacheDir is the directory transform scripts cache fetched responses in.
+// instances without a repo path don't cache
+func (inst *Instance) httpCacheDir() string {
+	if inst.repoPath == "" {
+		return ""
+	}
+	return filepath.Join(inst.repoPath, "http_cache")
+}
+
 // TransformMethods groups together methods for transforms
 type TransformMethods struct {
 	d dispatcher
@@ -26,7 +38,8 @@ func (m TransformMethods) Name() string {
 // Attributes defines attributes for each method
 func (m TransformMethods) Attributes() map[string]AttributeSet {
 	return map[string]AttributeSet{
-		"apply": {AEApply, "POST"},
+		"apply":     {endpoint: AEApply, verb: "POST"},
+		"reproduce": {endpoint: AEReproduce, verb: "POST"},
 	}
 }
 
@@ -111,9 +124,13 @@ func (transformImpl) Apply(scp scope, p *ApplyParams) (*ApplyResult, error) {
 	}, runID)
 
 	scriptOut := p.ScriptOutput
-	loader := scp.ParseResolveFunc()
+	loader := warnLifecycleLoader(scp, runID, ref, scp.ParseResolveFunc())
 
 	transformer := transform.NewTransformer(scp.AppContext(), loader, scp.Bus())
+	transformer.SetHTTPCacheDir(scp.inst.httpCacheDir())
+	if fsi.IsFSIPath(ref.Path) {
+		transformer.SetModuleDir(fsi.FilesystemPathToLocal(ref.Path))
+	}
 	if err = transformer.Apply(ctx, ds, runID, p.Wait, scriptOut, p.Secrets); err != nil {
 		return nil, err
 	}
@@ -129,3 +146,88 @@ func (transformImpl) Apply(scp scope, p *ApplyParams) (*ApplyResult, error) {
 	res.RunID = runID
 	return res, nil
 }
+
+// ReproduceParams are parameters for reproducing a dataset version
+type ReproduceParams struct {
+	Ref     string            `json:"ref"`
+	Secrets map[string]string `json:"secrets,omitempty"`
+	// ScriptOutput receives transform print output
+	ScriptOutput io.Writer `json:"-"`
+}
+
+// Validate returns an error if ReproduceParams fields are in an invalid state
+func (p *ReproduceParams) Validate() error {
+	if p.Ref == "" {
+		return fmt.Errorf("%w: dataset reference is required", ErrBadArgs)
+	}
+	return nil
+}
+
+// ReproduceResult is the outcome of re-running the transform of a version
+type ReproduceResult struct {
+	Ref string `json:"ref"`
+	// Recorded is the environment saved in the version commit, nil if the
+	// version didn't record one
+	Recorded *dsfs.Environment `json:"recorded,omitempty"`
+	Current  *dsfs.Environment `json:"current"`
+	// Mismatches describe differences between the recorded & current
+	// environment that may keep the version from being reproduced exactly
+	Mismatches []string         `json:"mismatches,omitempty"`
+	Data       *dataset.Dataset `json:"data"`
+	RunID      string           `json:"runID"`
+}
+
+// Reproduce re-runs the transform of a dataset version without saving,
+// comparing the environment recorded when the version was saved to the
+// current environment
+func (m TransformMethods) Reproduce(ctx context.Context, p *ReproduceParams) (*ReproduceResult, error) {
+	got, _, err := m.d.Dispatch(ctx, dispatchMethodName(m, "reproduce"), p)
+	if res, ok := got.(*ReproduceResult); ok {
+		return res, err
+	}
+	return nil, dispatchReturnError(got, err)
+}
+
+// Reproduce re-runs the transform of a dataset version
+func (transformImpl) Reproduce(scp scope, p *ReproduceParams) (*ReproduceResult, error) {
+	ctx := scp.Context()
+	ref, _, err := scp.ParseAndResolveRef(ctx, p.Ref, "")
+	if err != nil {
+		return nil, err
+	}
+	ds, err := scp.LoadDataset(ctx, ref, "")
+	if err != nil {
+		return nil, err
+	}
+	if ds.Transform == nil {
+		return nil, fmt.Errorf("%s has no transform to reproduce", ref.Human())
+	}
+
+	applied, err := transformImpl{}.Apply(scp, &ApplyParams{
+		Refstr:       ref.Human(),
+		Transform:    ds.Transform,
+		Secrets:      p.Secrets,
+		Wait:         true,
+		ScriptOutput: p.ScriptOutput,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the current environment takes resources from the re-run transform
+	tf := &dataset.Transform{Syntax: ds.Transform.Syntax}
+	if applied.Data != nil && applied.Data.Transform != nil {
+		tf.Resources = applied.Data.Transform.Resources
+	}
+	res := &ReproduceResult{
+		Ref:      ref.String(),
+		Recorded: dsfs.ParseEnvironment(ds.Commit),
+		Current:  dsfs.CaptureEnvironment(&dataset.Dataset{Transform: tf}),
+		Data:     applied.Data,
+		RunID:    applied.RunID,
+	}
+	if res.Recorded != nil {
+		res.Mismatches = res.Recorded.Mismatches(res.Current)
+	}
+	return res, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/base/dsfs"
 )
 
 func TestApplyTransform(t *testing.T) {
@@ -82,3 +83,43 @@ func TestApplyTransformValidationFailure(t *testing.T) {
 		t.Errorf("error mismatch, expect: %s, got: %s", expectErr, err)
 	}
 }
+
+func TestReproduceTransform(t *testing.T) {
+	tr := newTestRunner(t)
+	defer tr.Delete()
+
+	if _, err := tr.SaveWithParams(&SaveParams{
+		Ref:               "me/printer",
+		FilePaths:         []string{"testdata/tf/print.star"},
+		Apply:             true,
+		RecordEnvironment: true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := tr.Instance.Dataset().Get(tr.Ctx, &GetParams{Refstr: "me/printer", Selector: "environment", Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := dsfs.Environment{}
+	if err := json.Unmarshal(got.Bytes, &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.OS == "" || env.Runtime == "" {
+		t.Errorf("expected recorded environment to include platform & runtime, got: %#v", env)
+	}
+
+	res, err := tr.Instance.Transform().Reproduce(tr.Ctx, &ReproduceParams{Ref: "me/printer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Recorded == nil {
+		t.Fatal("expected reproduce to read the recorded environment")
+	}
+	if len(res.Mismatches) != 0 {
+		t.Errorf("expected no environment mismatches, got: %v", res.Mismatches)
+	}
+	if res.Data == nil {
+		t.Error("expected reproduce to return the transform result")
+	}
+}
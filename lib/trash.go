@@ -0,0 +1,257 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/fsi"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+// ErrNotInTrash is returned when a dataset can't be found in the trash
+var ErrNotInTrash = fmt.Errorf("dataset isn't in the trash")
+
+// TrashedDataset is a removed dataset whose stored versions are kept until
+// the trash grace period expires
+type TrashedDataset struct {
+	// Ref is the alias of the removed dataset, like "peer/dataset"
+	Ref       string `json:"ref"`
+	ProfileID string `json:"profileID,omitempty"`
+	// Path is the latest version when the dataset was removed
+	Path    string    `json:"path"`
+	Removed time.Time `json:"removed"`
+	// Expires is when stored versions may be purged by garbage collection
+	Expires time.Time `json:"expires"`
+}
+
+// Expired reports whether the grace period of a trashed dataset has passed
+func (td TrashedDataset) Expired(now time.Time) bool {
+	return !now.Before(td.Expires)
+}
+
+// trashStore persists trashed datasets, keyed by path. A store with an empty
+// filename keeps trashed datasets in memory
+type trashStore struct {
+	sync.Mutex
+	file     jsonfile.File
+	datasets map[string]*TrashedDataset
+}
+
+func newTrashStore(repoPath string) *trashStore {
+	s := &trashStore{datasets: map[string]*TrashedDataset{}}
+	if repoPath != "" {
+		s.file = jsonfile.New(filepath.Join(repoPath, "trash.json"))
+	}
+	return s
+}
+
+// Put adds or replaces a trashed dataset
+func (s *trashStore) Put(td *TrashedDataset) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.datasets[td.Path] = td
+	return s.save()
+}
+
+// Remove drops a trashed dataset from the store
+func (s *trashStore) Remove(path string) error {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	delete(s.datasets, path)
+	return s.save()
+}
+
+// List returns trashed datasets, most recently removed first
+func (s *trashStore) List() ([]TrashedDataset, error) {
+	s.Lock()
+	defer s.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	res := make([]TrashedDataset, 0, len(s.datasets))
+	for _, td := range s.datasets {
+		res = append(res, *td)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Removed.After(res[j].Removed) })
+	return res, nil
+}
+
+// Find returns the most recently removed dataset matching an alias or path
+func (s *trashStore) Find(refOrPath string) (*TrashedDataset, error) {
+	list, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, td := range list {
+		if td.Ref == refOrPath || td.Path == refOrPath {
+			return &td, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotInTrash, refOrPath)
+}
+
+func (s *trashStore) load() error {
+	return s.file.Load(&s.datasets)
+}
+
+func (s *trashStore) save() error {
+	return s.file.Save(s.datasets)
+}
+
+// trashGracePeriod returns how long removed datasets are kept in the trash. A
+// negative duration means the trash is disabled
+func trashGracePeriod(cfg *config.Config) (time.Duration, error) {
+	if cfg == nil || cfg.Repo == nil {
+		return config.DefaultTrashGracePeriod, nil
+	}
+	return cfg.Repo.TrashGracePeriodDuration()
+}
+
+// trashDataset moves a dataset to the trash, removing the reference & history
+// but keeping stored versions. ok is false when the trash is disabled or the
+// dataset has no stored versions to keep, and the caller should remove the
+// dataset outright
+func trashDataset(scope scope, ref dsref.Ref) (didRemove string, ok bool, err error) {
+	grace, err := trashGracePeriod(scope.Config())
+	if err != nil {
+		return "", false, err
+	}
+	if grace < 0 {
+		return "", false, nil
+	}
+
+	ctx := scope.Context()
+	if ref.Path == "" || fsi.IsFSIPath(ref.Path) {
+		qfsRef := ref.Copy()
+		qfsRef.Path = ""
+		if _, err := scope.ResolveReference(ctx, &qfsRef, "local"); err != nil || qfsRef.Path == "" || fsi.IsFSIPath(qfsRef.Path) {
+			return "", false, nil
+		}
+		ref.Path = qfsRef.Path
+	}
+
+	now := time.Now()
+	td := &TrashedDataset{
+		Ref:       ref.Alias(),
+		ProfileID: ref.ProfileID,
+		Path:      ref.Path,
+		Removed:   now,
+		Expires:   now.Add(grace),
+	}
+	if err := scope.inst.trash.Put(td); err != nil {
+		return "", false, err
+	}
+	didRemove, err = base.TrashDataset(ctx, scope.Repo(), ref)
+	if err != nil {
+		log.Debugw("trashing dataset", "ref", td.Ref, "err", err)
+	}
+	return didRemove, true, nil
+}
+
+// expandTrashRef replaces the "me" shorthand in an alias with the active
+// profile's username
+func expandTrashRef(scope scope, refOrPath string) string {
+	if strings.HasPrefix(refOrPath, "me/") {
+		return scope.ActiveProfile().Peername + strings.TrimPrefix(refOrPath, "me")
+	}
+	return refOrPath
+}
+
+// restoreTrashed brings back a dataset from the trash
+func restoreTrashed(scope scope, td *TrashedDataset) (*dsref.VersionInfo, error) {
+	ctx := scope.Context()
+	ref, err := dsref.Parse(td.Ref)
+	if err != nil {
+		return nil, err
+	}
+	existing := dsref.Ref{Username: ref.Username, Name: ref.Name}
+	if _, err := scope.ResolveReference(ctx, &existing, "local"); err == nil {
+		return nil, fmt.Errorf("can't restore %s: a dataset with that name already exists, rename it first", td.Ref)
+	}
+
+	ref.ProfileID = td.ProfileID
+	ref.Path = td.Path
+	vi, err := base.RestoreDataset(ctx, scope.Repo(), ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := scope.inst.trash.Remove(td.Path); err != nil {
+		return nil, err
+	}
+	return vi, nil
+}
+
+// purgeTrash removes the stored versions of trashed datasets & drops them
+// from the trash. Versions that a dataset saved or pulled under the same name
+// since removal still uses are left in place
+func (inst *Instance) purgeTrash(ctx context.Context, r repo.Repo, trashed []TrashedDataset) ([]TrashedDataset, error) {
+	purged := make([]TrashedDataset, 0, len(trashed))
+	for _, td := range trashed {
+		if !trashedVersionsInUse(ctx, r, td) {
+			if _, err := base.PurgeVersions(ctx, r, td.Path); err != nil {
+				return purged, fmt.Errorf("purging %s: %w", td.Ref, err)
+			}
+		}
+		if err := inst.trash.Remove(td.Path); err != nil {
+			return purged, err
+		}
+		purged = append(purged, td)
+	}
+	return purged, nil
+}
+
+// purgeExpiredTrash purges trashed datasets with an expired grace period
+func (inst *Instance) purgeExpiredTrash(ctx context.Context, r repo.Repo) ([]TrashedDataset, error) {
+	list, err := inst.trash.List()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	expired := make([]TrashedDataset, 0, len(list))
+	for _, td := range list {
+		if td.Expired(now) {
+			expired = append(expired, td)
+		}
+	}
+	return inst.purgeTrash(ctx, r, expired)
+}
+
+// trashedVersionsInUse reports whether the latest version of a trashed
+// dataset belongs to the history of a dataset currently in the repo under the
+// same name
+func trashedVersionsInUse(ctx context.Context, r repo.Repo, td TrashedDataset) bool {
+	ref, err := dsref.Parse(td.Ref)
+	if err != nil {
+		return false
+	}
+	vi, err := repo.GetVersionInfoShim(r, ref)
+	if err != nil {
+		return false
+	}
+	items, err := base.DatasetLog(ctx, r, vi.SimpleRef(), -1, 0, false)
+	if err != nil {
+		// can't tell, keep versions
+		return true
+	}
+	for _, item := range items {
+		if item.Path == td.Path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/repo"
+)
+
+// WorkflowPrefix marks datasets that define workflows. The scheduler loads
+// workflows from datasets in the local user's namespace with names that start
+// with this prefix, like "me/workflow_sources"
+var WorkflowPrefix = "workflow_"
+
+// WorkflowTypeFreshness is a workflow that checks the source of a dataset body
+// for new data. See FreshnessCheck
+const WorkflowTypeFreshness = "freshness"
+
+var (
+	// DefaultWorkflowBackoff is the wait before the first retry of a failed
+	// workflow run, used when a FailurePolicy doesn't set one
+	DefaultWorkflowBackoff = time.Minute
+	// DefaultWorkflowPauseAfter is the number of consecutive failed runs that
+	// pause a workflow, used when a FailurePolicy doesn't set one
+	DefaultWorkflowPauseAfter = 5
+)
+
+// FailurePolicy controls how a workflow responds to failed runs: how often a
+// run is retried, who is notified when it fails, and when repeated failures
+// pause the workflow. The zero value runs once, notifies nobody & pauses
+// after DefaultWorkflowPauseAfter failures
+type FailurePolicy struct {
+	// MaxAttempts is the number of times a run is tried before it fails.
+	// Defaults to 1, which never retries
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// Backoff is the wait before the first retry as a duration string like
+	// "30s", doubling for each later retry. Defaults to DefaultWorkflowBackoff
+	Backoff string `json:"backoff,omitempty"`
+	// PauseAfter is the number of consecutive failed runs that pause the
+	// workflow. Defaults to DefaultWorkflowPauseAfter, negative values never
+	// pause
+	PauseAfter int `json:"pauseAfter,omitempty"`
+	// Webhooks are URLs notified with a JSON POST request of the
+	// ETDatasetFreshness event payload when a run fails
+	Webhooks []string `json:"webhooks,omitempty"`
+}
+
+// Validate returns an error if the policy can't be applied
+func (p FailurePolicy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("maxAttempts can't be negative")
+	}
+	if p.Backoff != "" {
+		if d, err := time.ParseDuration(p.Backoff); err != nil {
+			return fmt.Errorf("invalid backoff: %w", err)
+		} else if d < 0 {
+			return fmt.Errorf("backoff can't be negative")
+		}
+	}
+	for _, hook := range p.Webhooks {
+		if u, err := url.Parse(hook); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("webhook %q must be an http(s) url", hook)
+		}
+	}
+	return nil
+}
+
+// attempts is the number of times a run is tried
+func (p FailurePolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff is the wait before the first retry. Invalid policies fall back to
+// the default, Validate reports them
+func (p FailurePolicy) backoff() time.Duration {
+	if d, err := time.ParseDuration(p.Backoff); err == nil && d >= 0 {
+		return d
+	}
+	return DefaultWorkflowBackoff
+}
+
+// pauseAfter is the number of consecutive failures that pause a workflow,
+// zero never pauses
+func (p FailurePolicy) pauseAfter() int {
+	switch {
+	case p.PauseAfter < 0:
+		return 0
+	case p.PauseAfter == 0:
+		return DefaultWorkflowPauseAfter
+	}
+	return p.PauseAfter
+}
+
+// Workflow is an automation defined by a row in the body of a workflow
+// dataset. Keeping workflows in datasets gives automation config the same
+// history as data: changes to workflows are versioned, can be diffed, and can
+// be pushed & pulled like any other dataset
+type Workflow struct {
+	// Dataset is the alias of the workflow dataset that defines the workflow
+	Dataset string `json:"dataset"`
+	// Type of workflow, only "freshness" is supported
+	Type string `json:"type"`
+	// Ref is the dataset the workflow runs against
+	Ref string `json:"ref"`
+	// BodyURL is the source freshness workflows check for new data
+	BodyURL string `json:"bodyURL,omitempty"`
+	// Active workflows are run by the scheduler
+	Active bool `json:"active"`
+	// FailurePolicy sets how failed runs are retried, reported & paused
+	FailurePolicy
+	// Error describes why a workflow can't be scheduled
+	Error string `json:"error,omitempty"`
+}
+
+// IsWorkflowDataset reports whether a dataset name marks a workflow dataset
+func IsWorkflowDataset(name string) bool {
+	return strings.HasPrefix(name, WorkflowPrefix)
+}
+
+// loadWorkflows reads workflows from the bodies of workflow datasets in the
+// local user's namespace. Each body is an array of workflow objects. Datasets
+// that can't be read are logged & skipped
+func loadWorkflows(scope scope) ([]Workflow, error) {
+	ctx := scope.Context()
+	num, err := scope.Repo().RefCount()
+	if err != nil {
+		return nil, err
+	}
+	infos, err := repo.ListVersionInfoShim(scope.Repo(), 0, num)
+	if err != nil {
+		return nil, err
+	}
+
+	username := scope.ActiveProfile().Peername
+	workflows := []Workflow{}
+	for _, vi := range infos {
+		if vi.Username != username || !IsWorkflowDataset(vi.Name) {
+			continue
+		}
+		ref := vi.SimpleRef()
+		ds, err := scope.LoadDataset(ctx, ref, "")
+		if err != nil {
+			log.Debugw("loading workflow dataset", "ref", ref.Alias(), "err", err)
+			continue
+		}
+		defined, err := readWorkflows(ds)
+		if err != nil {
+			log.Debugw("reading workflow dataset body", "ref", ref.Alias(), "err", err)
+			continue
+		}
+		for _, wf := range defined {
+			wf.Dataset = ref.Alias()
+			workflows = append(workflows, wf)
+		}
+	}
+
+	sort.SliceStable(workflows, func(i, j int) bool { return workflows[i].Dataset < workflows[j].Dataset })
+	return workflows, nil
+}
+
+// readWorkflows decodes the body of a workflow dataset
+func readWorkflows(ds *dataset.Dataset) ([]Workflow, error) {
+	if ds.BodyFile() == nil {
+		return nil, fmt.Errorf("workflow dataset has no body")
+	}
+	defer ds.BodyFile().Close()
+	r, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return nil, err
+	}
+	entries, err := base.ReadEntries(r)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := entries.([]interface{}); !ok {
+		return nil, fmt.Errorf("workflow dataset body must be an array")
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	workflows := []Workflow{}
+	if err := json.Unmarshal(data, &workflows); err != nil {
+		return nil, fmt.Errorf("decoding workflows: %w", err)
+	}
+	return workflows, nil
+}
+
+// syncWorkflows schedules active workflows, adding a freshness check for each
+// active freshness workflow & removing checks for workflows that were removed
+// or deactivated. Checks added by hand are left as is. Returns the loaded
+// workflows, with an error set on workflows that can't be scheduled
+func syncWorkflows(scope scope) ([]Workflow, error) {
+	workflows, err := loadWorkflows(scope)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := scope.inst.freshness.List()
+	if err != nil {
+		return nil, err
+	}
+	existing := map[string]FreshnessCheck{}
+	for _, c := range checks {
+		existing[c.Ref] = c
+	}
+
+	scheduled := map[string]bool{}
+	for i, wf := range workflows {
+		if !wf.Active {
+			continue
+		}
+		if wf.Type != WorkflowTypeFreshness {
+			workflows[i].Error = fmt.Sprintf("unsupported workflow type %q", wf.Type)
+			continue
+		}
+		if wf.BodyURL == "" {
+			workflows[i].Error = "freshness workflows require a bodyURL"
+			continue
+		}
+		if err := wf.FailurePolicy.Validate(); err != nil {
+			workflows[i].Error = err.Error()
+			continue
+		}
+		ref, _, err := scope.ParseAndResolveRef(scope.Context(), wf.Ref, "local")
+		if err != nil {
+			workflows[i].Error = err.Error()
+			continue
+		}
+		alias := ref.Alias()
+		if scheduled[alias] {
+			workflows[i].Error = fmt.Sprintf("%s is already checked by another workflow", alias)
+			continue
+		}
+		scheduled[alias] = true
+
+		if c, ok := existing[alias]; ok && c.Workflow == wf.Dataset && c.BodyURL == wf.BodyURL {
+			// policy changes keep the failure count & paused state of a check
+			if !reflect.DeepEqual(c.FailurePolicy, wf.FailurePolicy) {
+				c.FailurePolicy = wf.FailurePolicy
+				if err := scope.inst.freshness.Update(&c); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		check := &FreshnessCheck{Ref: alias, InitID: ref.InitID, BodyURL: wf.BodyURL, Since: time.Now(), Workflow: wf.Dataset, FailurePolicy: wf.FailurePolicy}
+		if err := scope.inst.freshness.Put(check); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range checks {
+		if c.Workflow != "" && !scheduled[c.Ref] {
+			if err := scope.inst.freshness.Remove(c.Ref); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return workflows, nil
+}
@@ -0,0 +1,308 @@
+package logbook
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/qri/logbook/oplog"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+// DatasetKeySize is the length in bytes of a dataset encryption key
+const DatasetKeySize = 32
+
+// encryptedPrefix marks an op field value as ciphertext
+const encryptedPrefix = "enc:"
+
+var (
+	// ErrInvalidDatasetKey indicates a dataset key is the wrong length
+	ErrInvalidDatasetKey = fmt.Errorf("logbook: dataset key must be %d bytes", DatasetKeySize)
+	// ErrDecrypt indicates a log contains ciphertext that couldn't be decrypted
+	// with the dataset key held by this book
+	ErrDecrypt = fmt.Errorf("logbook: cannot decrypt log")
+)
+
+// NewDatasetKey creates a random key for encrypting a dataset log. Any
+// collaborator holding the key can read the encrypted log
+func NewDatasetKey() ([]byte, error) {
+	key := make([]byte, DatasetKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DatasetKeys is a keystore of dataset encryption keys, indexed by dataset
+// init ID. Keys are persisted as JSON to filename, an empty filename keeps
+// keys in memory
+type DatasetKeys struct {
+	file jsonfile.File
+
+	lk     sync.Mutex
+	loaded bool
+	keys   map[string][]byte
+}
+
+// NewDatasetKeys creates a dataset keystore
+func NewDatasetKeys(filename string) *DatasetKeys {
+	return &DatasetKeys{
+		file: jsonfile.NewPrivate(filename),
+		keys: map[string][]byte{},
+	}
+}
+
+// Get returns the key for a dataset
+func (k *DatasetKeys) Get(initID string) ([]byte, bool, error) {
+	k.lk.Lock()
+	defer k.lk.Unlock()
+	if err := k.load(); err != nil {
+		return nil, false, err
+	}
+	key, ok := k.keys[initID]
+	return key, ok, nil
+}
+
+// Set stores the key for a dataset, replacing any existing key
+func (k *DatasetKeys) Set(initID string, key []byte) error {
+	if len(key) != DatasetKeySize {
+		return ErrInvalidDatasetKey
+	}
+	k.lk.Lock()
+	defer k.lk.Unlock()
+	if err := k.load(); err != nil {
+		return err
+	}
+	k.keys[initID] = key
+	return k.save()
+}
+
+// Remove drops the key for a dataset. Subsequent pushes of the dataset log
+// will be sent in plaintext
+func (k *DatasetKeys) Remove(initID string) error {
+	k.lk.Lock()
+	defer k.lk.Unlock()
+	if err := k.load(); err != nil {
+		return err
+	}
+	if _, ok := k.keys[initID]; !ok {
+		return nil
+	}
+	delete(k.keys, initID)
+	return k.save()
+}
+
+// load reads keys from disk once. must be called with the lock held
+func (k *DatasetKeys) load() error {
+	if k.loaded {
+		return nil
+	}
+	if err := k.file.Load(&k.keys); err != nil {
+		return fmt.Errorf("reading dataset keys: %w", err)
+	}
+	k.loaded = true
+	return nil
+}
+
+// save writes keys to disk. must be called with the lock held
+func (k *DatasetKeys) save() error {
+	return k.file.Save(k.keys)
+}
+
+// SetDatasetKeys replaces the keystore of dataset encryption keys
+func (book *Book) SetDatasetKeys(keys *DatasetKeys) {
+	book.keys = keys
+}
+
+// SetDatasetKey enables log encryption for a dataset. Logs for the dataset
+// are encrypted with key before they're sent to a remote
+func (book *Book) SetDatasetKey(initID string, key []byte) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if book.keys == nil {
+		book.keys = NewDatasetKeys("")
+	}
+	return book.keys.Set(initID, key)
+}
+
+// RemoveDatasetKey disables log encryption for a dataset
+func (book *Book) RemoveDatasetKey(initID string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if book.keys == nil {
+		return nil
+	}
+	return book.keys.Remove(initID)
+}
+
+// DatasetKey returns the encryption key for a dataset, if one is set
+func (book Book) DatasetKey(initID string) ([]byte, bool, error) {
+	if book.keys == nil {
+		return nil, false, nil
+	}
+	return book.keys.Get(initID)
+}
+
+// encryptForSync returns a copy of an author log with the payloads of any
+// dataset logs this book holds keys for encrypted. Logs without keys are
+// returned as-is
+func (book Book) encryptForSync(lg *oplog.Log) (*oplog.Log, error) {
+	var cp *oplog.Log
+	for i, dsLog := range lg.Logs {
+		key, ok, err := book.DatasetKey(dsLog.ID())
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		if cp == nil {
+			cp = lg.DeepCopy()
+		}
+		if err := encryptLog(cp.Logs[i], key); err != nil {
+			return nil, err
+		}
+	}
+	if cp == nil {
+		return lg, nil
+	}
+	return cp, nil
+}
+
+// DecryptLog decrypts in place the payloads of any dataset logs within an
+// author log this book holds keys for. Encrypted logs this book doesn't hold
+// a key for are left as ciphertext
+func (book Book) DecryptLog(lg *oplog.Log) error {
+	for _, dsLog := range lg.Logs {
+		key, ok, err := book.DatasetKey(dsLog.ID())
+		if err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+		if err := DecryptLog(dsLog, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncryptLog returns a copy of a log with the Ref, Name & Note fields of all
+// operations in the log and its descendants encrypted with a dataset key.
+// Initialization operations are left in plaintext, keeping log IDs stable,
+// as are names of the log itself so a remote can resolve the dataset by name.
+//
+// Encryption is deterministic for an op field, so repeatedly encrypting the
+// same log produces the same ciphertext
+func EncryptLog(lg *oplog.Log, key []byte) (*oplog.Log, error) {
+	cp := lg.DeepCopy()
+	if err := encryptLog(cp, key); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// DecryptLog decrypts in place a log encrypted with EncryptLog. Plaintext
+// fields are left unchanged
+func DecryptLog(lg *oplog.Log, key []byte) error {
+	gcm, err := datasetCipher(key)
+	if err != nil {
+		return err
+	}
+	return transformOps(lg, func(o *oplog.Op, field string, val string) (string, error) {
+		return decryptField(gcm, field, val)
+	})
+}
+
+func encryptLog(lg *oplog.Log, key []byte) error {
+	gcm, err := datasetCipher(key)
+	if err != nil {
+		return err
+	}
+	return transformOps(lg, func(o *oplog.Op, field string, val string) (string, error) {
+		return encryptField(gcm, key, o.Timestamp, field, val)
+	})
+}
+
+// transformOps applies fn to each payload field of each non-init op in a log
+// and its descendants
+func transformOps(lg *oplog.Log, fn func(o *oplog.Op, field, val string) (string, error)) (err error) {
+	m := lg.Model()
+	for i := range lg.Ops {
+		if i == 0 {
+			continue
+		}
+		o := &lg.Ops[i]
+		if o.Ref, err = fn(o, "ref", o.Ref); err != nil {
+			return err
+		}
+		if o.Model != m {
+			if o.Name, err = fn(o, "name", o.Name); err != nil {
+				return err
+			}
+		}
+		if o.Note, err = fn(o, "note", o.Note); err != nil {
+			return err
+		}
+	}
+	for _, l := range lg.Logs {
+		if err := transformOps(l, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func datasetCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != DatasetKeySize {
+		return nil, ErrInvalidDatasetKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encryptField(gcm cipher.AEAD, key []byte, ts int64, field, val string) (string, error) {
+	if val == "" || strings.HasPrefix(val, encryptedPrefix) {
+		return val, nil
+	}
+
+	// derive the nonce from the op timestamp & field value so re-encrypting
+	// an op gives the same result, otherwise each push would rewrite the log
+	mac := hmac.New(sha256.New, key)
+	tsBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(tsBytes, uint64(ts))
+	mac.Write(tsBytes)
+	mac.Write([]byte(field))
+	mac.Write([]byte(val))
+	nonce := mac.Sum(nil)[:gcm.NonceSize()]
+
+	ciphertext := gcm.Seal(nonce, nonce, []byte(val), []byte(field))
+	return encryptedPrefix + base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+func decryptField(gcm cipher.AEAD, field, val string) (string, error) {
+	if !strings.HasPrefix(val, encryptedPrefix) {
+		return val, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(val, encryptedPrefix))
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("%w: invalid %s ciphertext", ErrDecrypt, field)
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrDecrypt, err)
+	}
+	return string(plaintext), nil
+}
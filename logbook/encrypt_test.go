@@ -0,0 +1,66 @@
+package logbook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+func TestEncryptLog(t *testing.T) {
+	key, err := NewDatasetKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lg := oplog.InitLog(oplog.Op{Type: oplog.OpTypeInit, Model: DatasetModel, Name: "cities", Timestamp: 1})
+	lg.Append(oplog.Op{Type: oplog.OpTypeAmend, Model: DatasetModel, Name: "cities_renamed", Timestamp: 2})
+	branch := oplog.InitLog(oplog.Op{Type: oplog.OpTypeInit, Model: BranchModel, Name: DefaultBranchName, Timestamp: 3})
+	branch.Append(oplog.Op{Type: oplog.OpTypeInit, Model: CommitModel, Ref: "/ipfs/QmVersion", Note: "initial commit", Timestamp: 4})
+	lg.AddChild(branch)
+	expect := lg.DeepCopy()
+
+	enc, err := EncryptLog(lg, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect.FlatbufferBytes(), lg.FlatbufferBytes()); diff != "" {
+		t.Errorf("expected encryption to leave the input log unmodified")
+	}
+	if enc.ID() != lg.ID() || enc.Name() != "cities_renamed" {
+		t.Errorf("expected log ID & name to remain plaintext, got id=%q name=%q", enc.ID(), enc.Name())
+	}
+	commit := enc.Logs[0].Ops[1]
+	if !strings.HasPrefix(commit.Ref, encryptedPrefix) || !strings.HasPrefix(commit.Note, encryptedPrefix) {
+		t.Errorf("expected commit op payload to be encrypted, got ref=%q note=%q", commit.Ref, commit.Note)
+	}
+
+	again, err := EncryptLog(lg, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Logs[0].Ops[1].Ref != commit.Ref {
+		t.Errorf("expected encryption to be deterministic")
+	}
+
+	other, err := NewDatasetKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DecryptLog(enc.DeepCopy(), other); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected decrypting with the wrong key to return ErrDecrypt, got: %v", err)
+	}
+
+	if err := DecryptLog(enc, key); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect.Logs[0].Ops, enc.Logs[0].Ops); diff != "" {
+		t.Errorf("decrypted log mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := EncryptLog(lg, []byte("too short")); !errors.Is(err, ErrInvalidDatasetKey) {
+		t.Errorf("expected invalid key error, got: %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package logbook
+
+import (
+	"context"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+const (
+	// GraphSave is a graph entry for a saved version
+	GraphSave = "save"
+	// GraphAmend is a graph entry for a version that replaced the one before it
+	GraphAmend = "amend"
+	// GraphRemove is a graph entry for deleting versions from HEAD
+	GraphRemove = "remove"
+	// GraphRun is a graph entry for a transform run
+	GraphRun = "run"
+	// GraphPublish is a graph entry for pushing versions to a remote
+	GraphPublish = "publish"
+	// GraphUnpublish is a graph entry for removing versions from a remote
+	GraphUnpublish = "unpublish"
+	// GraphDelete is a graph entry for deleting an entire dataset
+	GraphDelete = "delete"
+	// GraphGap is a graph entry for missing versions in an imported history
+	GraphGap = "gap"
+)
+
+// GraphEntry is one operation in the full history of a dataset branch.
+// Unlike the items returned by Book.Items, graph entries keep versions that
+// have been removed or amended as tombstones, and include runs, pushes, and
+// deletes as entries of their own
+type GraphEntry struct {
+	// Type is one of the Graph* constants
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	// Path is the version path of save & amend entries. Gap entries set Path
+	// to the newest missing version
+	Path     string `json:"path,omitempty"`
+	Title    string `json:"title,omitempty"`
+	BodySize int    `json:"bodySize,omitempty"`
+	// RunID is set on run entries, and on save entries created by a run.
+	// a save & a run with the same RunID describe the same operation
+	RunID       string `json:"runID,omitempty"`
+	RunStatus   string `json:"runStatus,omitempty"`
+	RunDuration int64  `json:"runDuration,omitempty"`
+	// Remote is the address publish & unpublish entries were pushed to
+	Remote string `json:"remote,omitempty"`
+	// Revisions is the number of versions affected by remove, publish & unpublish
+	// entries
+	Revisions int `json:"revisions,omitempty"`
+	// Published is true for versions currently available on a remote
+	Published bool `json:"published,omitempty"`
+	// Deleted marks save & amend entries as tombstones: versions that are no
+	// longer part of the dataset history
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// Graph returns the full sequence of operations on a dataset branch, newest
+// first. A limit of -1 returns all entries
+func (book *Book) Graph(ctx context.Context, ref dsref.Ref, offset, limit int) ([]GraphEntry, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	initID := ref.InitID
+	if initID == "" {
+		var err error
+		if initID, err = book.RefToInitID(dsref.Ref{Username: ref.Username, Name: ref.Name}); err != nil {
+			return nil, err
+		}
+	}
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return nil, err
+	}
+	branchLog, err := book.branchLog(ctx, initID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := branchToGraph(branchLog)
+	for _, op := range dsLog.l.Ops {
+		if op.Model == DatasetModel && op.Type == oplog.OpTypeRemove {
+			entries = append(entries, GraphEntry{
+				Type:      GraphDelete,
+				Timestamp: time.Unix(0, op.Timestamp),
+			})
+			for i := range entries {
+				entries[i].Deleted = entries[i].Deleted || entries[i].Type == GraphSave || entries[i].Type == GraphAmend
+			}
+		}
+	}
+
+	// reverse the slice, placing newest first
+	for i := len(entries)/2 - 1; i >= 0; i-- {
+		opp := len(entries) - 1 - i
+		entries[i], entries[opp] = entries[opp], entries[i]
+	}
+
+	if offset > len(entries) {
+		offset = len(entries)
+	}
+	entries = entries[offset:]
+	if limit < len(entries) && limit != -1 {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// branchToGraph plays branch operations forward, oldest first. versions
+// stays a stack of indexes of live versions, so removes & pushes that count
+// back from HEAD apply to the right entries
+func branchToGraph(blog *BranchLog) []GraphEntry {
+	entries := []GraphEntry{}
+	versions := []int{}
+
+	headN := func(n int) []int {
+		if n > len(versions) {
+			n = len(versions)
+		}
+		return versions[len(versions)-n:]
+	}
+
+	for _, op := range blog.Ops() {
+		ts := time.Unix(0, op.Timestamp)
+		switch op.Model {
+		case CommitModel:
+			switch op.Type {
+			case oplog.OpTypeInit:
+				versions = append(versions, len(entries))
+				entries = append(entries, GraphEntry{
+					Type:      GraphSave,
+					Timestamp: ts,
+					Path:      op.Ref,
+					Title:     op.Note,
+					BodySize:  int(op.Size),
+					RunID:     commitOpRunID(op),
+				})
+			case oplog.OpTypeAmend:
+				if len(versions) > 0 {
+					entries[versions[len(versions)-1]].Deleted = true
+					versions = versions[:len(versions)-1]
+				}
+				versions = append(versions, len(entries))
+				entries = append(entries, GraphEntry{
+					Type:      GraphAmend,
+					Timestamp: ts,
+					Path:      op.Ref,
+					Title:     op.Note,
+					BodySize:  int(op.Size),
+				})
+			case oplog.OpTypeRemove:
+				removed := headN(int(op.Size))
+				for _, i := range removed {
+					entries[i].Deleted = true
+				}
+				versions = versions[:len(versions)-len(removed)]
+				entries = append(entries, GraphEntry{
+					Type:      GraphRemove,
+					Timestamp: ts,
+					Revisions: int(op.Size),
+				})
+			}
+		case GapModel:
+			entries = append(entries, GraphEntry{
+				Type:      GraphGap,
+				Timestamp: ts,
+				Path:      op.Ref,
+				Title:     op.Note,
+			})
+		case RunModel:
+			entries = append(entries, GraphEntry{
+				Type:        GraphRun,
+				Timestamp:   ts,
+				RunID:       op.Ref,
+				RunStatus:   op.Note,
+				RunDuration: int64(op.Size),
+			})
+		case PushModel:
+			e := GraphEntry{
+				Type:      GraphPublish,
+				Timestamp: ts,
+				Revisions: int(op.Size),
+			}
+			if len(op.Relations) > 0 {
+				e.Remote = op.Relations[0]
+			}
+			published := true
+			if op.Type == oplog.OpTypeRemove {
+				e.Type = GraphUnpublish
+				published = false
+			}
+			for _, i := range headN(int(op.Size)) {
+				entries[i].Published = published
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,426 @@
+package logbook
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/auth/key"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logbook/oplog"
+	"github.com/qri-io/qri/transform/run"
+)
+
+// delegationRelPrefix is a string prefix for op.Relations on ACL ops that
+// record a delegation issued by a dataset owner
+const delegationRelPrefix = "delegation:"
+
+// Delegation is a dataset owner's signed permission for another author to
+// construct the history of a dataset in the owner's namespace. Delegations
+// carry the owner's public key, anyone holding a delegation can check it was
+// signed by the owner it names
+type Delegation struct {
+	// Username & OwnerID identify the dataset owner
+	Username string `json:"username"`
+	OwnerID  string `json:"ownerID"`
+	// Dataset is the name of the dataset the delegation applies to
+	Dataset string `json:"dataset"`
+	// DelegateID is the profile ID of the author permitted to write history
+	DelegateID string    `json:"delegateID"`
+	Timestamp  time.Time `json:"timestamp"`
+	// PubKey is the base64 encoded public key of the owner
+	PubKey string `json:"pubKey"`
+	// Signature is a base64 encoded signature of the username, dataset,
+	// delegate & timestamp made with the owner's private key
+	Signature string `json:"signature"`
+}
+
+// NewDelegation creates a delegation for the dataset username/dsName to the
+// author with profile ID delegateID, signed with the owner's private key
+func NewDelegation(owner crypto.PrivKey, username, dsName, delegateID string) (*Delegation, error) {
+	if owner == nil {
+		return nil, fmt.Errorf("a private key is required to sign a delegation")
+	}
+	ownerID, err := key.IDFromPrivKey(owner)
+	if err != nil {
+		return nil, err
+	}
+	pkBytes, err := crypto.MarshalPublicKey(owner.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Delegation{
+		Username:   username,
+		OwnerID:    ownerID,
+		Dataset:    dsName,
+		DelegateID: delegateID,
+		Timestamp:  time.Unix(0, NewTimestamp()).In(time.UTC),
+		PubKey:     base64.StdEncoding.EncodeToString(pkBytes),
+	}
+	sig, err := owner.Sign([]byte(d.signingString()))
+	if err != nil {
+		return nil, err
+	}
+	d.Signature = base64.StdEncoding.EncodeToString(sig)
+	return d, nil
+}
+
+func (d *Delegation) signingString() string {
+	return fmt.Sprintf("%s.%s.%s.%d", d.Username, d.Dataset, d.DelegateID, d.Timestamp.UnixNano())
+}
+
+// Verify checks the delegation signature was made by the key of the owner the
+// delegation names
+func (d *Delegation) Verify() error {
+	pkBytes, err := base64.StdEncoding.DecodeString(d.PubKey)
+	if err != nil {
+		return fmt.Errorf("decoding public key: %w", err)
+	}
+	pubKey, err := crypto.UnmarshalPublicKey(pkBytes)
+	if err != nil {
+		return fmt.Errorf("decoding public key: %w", err)
+	}
+	if keyID, err := key.IDFromPubKey(pubKey); err != nil || keyID != d.OwnerID {
+		return fmt.Errorf("public key doesn't match owner ID")
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(d.Signature)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+	if ok, err := pubKey.Verify([]byte(d.signingString()), sigBytes); err != nil || !ok {
+		return fmt.Errorf("invalid delegation signature")
+	}
+	return nil
+}
+
+// Encode serializes a delegation to a string for storage
+func (d *Delegation) Encode() (string, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeDelegation deserializes a delegation created with Encode
+func DecodeDelegation(s string) (*Delegation, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decoding delegation: %w", err)
+	}
+	d := &Delegation{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, fmt.Errorf("decoding delegation: %w", err)
+	}
+	return d, nil
+}
+
+// ImportedPublish records a push of versions to a remote, or an unpublish
+type ImportedPublish struct {
+	Remote string
+	// Revisions is the number of versions from HEAD at the time of the publish
+	Revisions int
+	Timestamp time.Time
+	Unpublish bool
+}
+
+// DatasetImport is a dataset history created outside of qri
+type DatasetImport struct {
+	// Versions MUST be ordered from oldest to newest. When a version's
+	// PreviousPath isn't the path of the version before it, the versions in
+	// between are missing, and a gap is recorded in the log. An initial
+	// version with a PreviousPath starts the log with a gap
+	Versions []*dataset.Dataset
+	// Runs are transform executions. A run is written right before the version
+	// with a matching Commit.RunID. Runs that didn't save a version are
+	// placed by start time, like publishes
+	Runs []*run.State
+	// Publishes are placed after the last version committed before them
+	Publishes []ImportedPublish
+	// Delegation is required to import a dataset into a namespace other than
+	// the book author's, and must name the book author as the delegate
+	Delegation *Delegation
+}
+
+// ImportDatasetLog creates a log for a dataset history created outside of
+// qri where no prior log exists. Datasets owned by another user are written
+// to that user's namespace, with the owner's delegation recorded in the
+// dataset log. The book author can't write further operations to a dataset it
+// doesn't own
+func (book *Book) ImportDatasetLog(ctx context.Context, ref dsref.Ref, imp *DatasetImport) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if imp == nil {
+		return fmt.Errorf("logbook: import is required")
+	}
+
+	if _, err := book.RefToInitID(ref); err == nil {
+		// if the log already exists, it will either as-or-more rich than this log,
+		// refuse to overwrite
+		return ErrLogTooShort
+	}
+
+	ops, err := importOps(imp)
+	if err != nil {
+		return err
+	}
+
+	var initID string
+	if ref.Username == "" || ref.Username == book.Username() {
+		if imp.Delegation != nil {
+			return fmt.Errorf("logbook: delegations are only used to import datasets owned by other users")
+		}
+		if initID, err = book.WriteDatasetInit(ctx, ref.Name); err != nil {
+			return err
+		}
+	} else if initID, err = book.writeDelegatedDatasetInit(ctx, ref, imp.Delegation); err != nil {
+		return err
+	}
+
+	branchLog, err := book.branchLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		branchLog.Append(op)
+	}
+	return book.save(ctx)
+}
+
+// writeDelegatedDatasetInit initializes a dataset in another user's
+// namespace, creating a log for the owner if the book doesn't have one
+func (book *Book) writeDelegatedDatasetInit(ctx context.Context, ref dsref.Ref, d *Delegation) (string, error) {
+	if d == nil {
+		return "", fmt.Errorf("%w: a delegation from %q is required to import datasets into their namespace", ErrAccessDenied, ref.Username)
+	}
+	if err := d.Verify(); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrAccessDenied, err)
+	}
+	if d.Username != ref.Username || d.Dataset != ref.Name {
+		return "", fmt.Errorf("%w: delegation is for %s/%s, not %s/%s", ErrAccessDenied, d.Username, d.Dataset, ref.Username, ref.Name)
+	}
+	if ref.ProfileID != "" && ref.ProfileID != d.OwnerID {
+		return "", fmt.Errorf("%w: delegation owner doesn't match profile ID %q", ErrAccessDenied, ref.ProfileID)
+	}
+	authorLog, err := book.authorLog(ctx)
+	if err != nil {
+		return "", err
+	}
+	if d.DelegateID != authorLog.ProfileID() {
+		return "", fmt.Errorf("%w: delegation wasn't issued to this author", ErrAccessDenied)
+	}
+	if !dsref.IsValidName(ref.Name) {
+		return "", fmt.Errorf("logbook: dataset name %q invalid", ref.Name)
+	}
+	encoded, err := d.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	// use the log this book already holds for the owner, if any
+	var userLog *oplog.Log
+	logs, err := book.store.Logs(ctx, 0, -1)
+	if err != nil {
+		return "", err
+	}
+	for _, lg := range logs {
+		if lg.Model() == AuthorModel && lg.FirstOpAuthorID() == d.OwnerID {
+			userLog = lg
+			break
+		}
+	}
+	isNew := userLog == nil
+	if isNew {
+		userLog = oplog.InitLog(oplog.Op{
+			Type:      oplog.OpTypeInit,
+			Model:     AuthorModel,
+			Name:      d.Username,
+			AuthorID:  d.OwnerID,
+			Timestamp: d.Timestamp.UnixNano(),
+		})
+	}
+	ownerLogID := userLog.ID()
+
+	dsLog := oplog.InitLog(oplog.Op{
+		Type:      oplog.OpTypeInit,
+		Model:     DatasetModel,
+		AuthorID:  ownerLogID,
+		Name:      ref.Name,
+		Timestamp: NewTimestamp(),
+	})
+	dsLog.Append(oplog.Op{
+		Type:      oplog.OpTypeInit,
+		Model:     ACLModel,
+		Ref:       d.DelegateID,
+		Relations: []string{delegationRelPrefix + encoded},
+		Timestamp: NewTimestamp(),
+		Note:      "delegated history import",
+	})
+	dsLog.AddChild(oplog.InitLog(oplog.Op{
+		Type:      oplog.OpTypeInit,
+		Model:     BranchModel,
+		AuthorID:  ownerLogID,
+		Name:      DefaultBranchName,
+		Timestamp: NewTimestamp(),
+	}))
+	userLog.AddChild(dsLog)
+
+	if isNew {
+		if err := book.store.MergeLog(ctx, userLog); err != nil {
+			return "", err
+		}
+	}
+
+	initID := dsLog.ID()
+	err = book.publisher.Publish(ctx, event.ETDatasetNameInit, event.DsChange{
+		InitID:     initID,
+		Username:   d.Username,
+		ProfileID:  d.OwnerID,
+		PrettyName: ref.Name,
+	})
+	if err != nil {
+		log.Error(err)
+	}
+
+	return initID, book.save(ctx)
+}
+
+// Delegation returns the delegation a dataset log was imported with, if any
+func (book *Book) Delegation(ctx context.Context, initID string) (*Delegation, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range dsLog.l.Ops {
+		if op.Model != ACLModel {
+			continue
+		}
+		for _, rel := range op.Relations {
+			if strings.HasPrefix(rel, delegationRelPrefix) {
+				return DecodeDelegation(strings.TrimPrefix(rel, delegationRelPrefix))
+			}
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// importOps converts an import to branch operations, oldest first
+func importOps(imp *DatasetImport) ([]oplog.Op, error) {
+	runs := map[string]*run.State{}
+	for _, rs := range imp.Runs {
+		if rs == nil || rs.ID == "" {
+			return nil, fmt.Errorf("logbook: imported runs must have an ID")
+		}
+		runs[rs.ID] = rs
+	}
+
+	// timed ops are placed after the last version committed before them
+	type timedOp struct {
+		ts      time.Time
+		run     *run.State
+		publish *ImportedPublish
+	}
+	timed := []timedOp{}
+	saved := map[string]bool{}
+	for _, ds := range imp.Versions {
+		if ds != nil && ds.Commit != nil && ds.Commit.RunID != "" {
+			saved[ds.Commit.RunID] = true
+		}
+	}
+	for _, rs := range imp.Runs {
+		if saved[rs.ID] {
+			continue
+		}
+		if rs.StartTime == nil {
+			return nil, fmt.Errorf("logbook: run %q has no start time and didn't save a version", rs.ID)
+		}
+		timed = append(timed, timedOp{ts: *rs.StartTime, run: rs})
+	}
+	for i := range imp.Publishes {
+		pub := &imp.Publishes[i]
+		if pub.Remote == "" {
+			return nil, fmt.Errorf("logbook: imported publishes must name a remote")
+		}
+		if pub.Revisions <= 0 {
+			return nil, fmt.Errorf("logbook: imported publishes must include at least one version")
+		}
+		timed = append(timed, timedOp{ts: pub.Timestamp, publish: pub})
+	}
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].ts.Before(timed[j].ts) })
+
+	ops := []oplog.Op{}
+	versions := 0
+	flush := func(before *time.Time) error {
+		for len(timed) > 0 && (before == nil || timed[0].ts.Before(*before)) {
+			t := timed[0]
+			timed = timed[1:]
+			if t.run != nil {
+				ops = append(ops, transformRunOp(t.run))
+				continue
+			}
+			if versions == 0 {
+				return fmt.Errorf("logbook: publish to %q at %s comes before any version", t.publish.Remote, t.ts.Format(time.RFC3339))
+			}
+			op := oplog.Op{
+				Type:      oplog.OpTypeInit,
+				Model:     PushModel,
+				Timestamp: t.ts.UnixNano(),
+				Size:      int64(t.publish.Revisions),
+				Relations: []string{t.publish.Remote},
+			}
+			if t.publish.Unpublish {
+				op.Type = oplog.OpTypeRemove
+			}
+			if op.Size > int64(versions) {
+				op.Size = int64(versions)
+			}
+			ops = append(ops, op)
+		}
+		return nil
+	}
+
+	prevPath := ""
+	for i, ds := range imp.Versions {
+		if ds == nil || ds.Commit == nil {
+			return nil, fmt.Errorf("logbook: imported version %d has no commit", i)
+		}
+		if ds.Path == "" {
+			return nil, fmt.Errorf("logbook: imported version %d has no path", i)
+		}
+		if err := flush(&ds.Commit.Timestamp); err != nil {
+			return nil, err
+		}
+		if ds.PreviousPath != prevPath {
+			ops = append(ops, oplog.Op{
+				Type:      oplog.OpTypeInit,
+				Model:     GapModel,
+				Ref:       ds.PreviousPath,
+				Prev:      prevPath,
+				Timestamp: ds.Commit.Timestamp.UnixNano(),
+				Note:      "missing history",
+			})
+		}
+		if rs, ok := runs[ds.Commit.RunID]; ok && ds.Commit.RunID != "" {
+			ops = append(ops, transformRunOp(rs))
+		}
+		ops = append(ops, versionSaveOp(ds))
+		versions++
+		prevPath = ds.Path
+	}
+	if err := flush(nil); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
@@ -0,0 +1,169 @@
+package logbook_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/auth/key"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/transform/run"
+)
+
+func importTestVersions() []*dataset.Dataset {
+	day := func(d int) time.Time { return time.Date(2000, time.January, d, 0, 0, 0, 0, time.UTC) }
+	return []*dataset.Dataset{
+		{Commit: &dataset.Commit{Timestamp: day(1), Title: "initial commit"}, Path: "HashOfVersion1"},
+		{Commit: &dataset.Commit{Timestamp: day(2), Title: "commit 2", RunID: "run_1"}, Path: "HashOfVersion2", PreviousPath: "HashOfVersion1"},
+		// version 3 is missing
+		{Commit: &dataset.Commit{Timestamp: day(4), Title: "commit 4"}, Path: "HashOfVersion4", PreviousPath: "HashOfVersion3"},
+	}
+}
+
+func TestImportDatasetLog(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	runStart := time.Date(2000, time.January, 3, 0, 0, 0, 0, time.UTC)
+	imp := &logbook.DatasetImport{
+		Versions: importTestVersions(),
+		Runs: []*run.State{
+			{ID: "run_1", Number: 1, Status: run.RSSucceeded},
+			{ID: "run_2", Number: 2, Status: run.RSFailed, StartTime: &runStart},
+		},
+		Publishes: []logbook.ImportedPublish{
+			{Remote: "registry", Revisions: 2, Timestamp: time.Date(2000, time.January, 5, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	ref := dsref.Ref{Username: tr.Username, Name: "imported"}
+	if err := tr.Book.ImportDatasetLog(tr.Ctx, ref, imp); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.ImportDatasetLog(tr.Ctx, ref, imp); err != logbook.ErrLogTooShort {
+		t.Errorf("expected importing over an existing log to return ErrLogTooShort. got: %v", err)
+	}
+
+	graph, err := tr.Book.Graph(tr.Ctx, ref, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := make([]string, len(graph))
+	for i, e := range graph {
+		types[i] = e.Type
+	}
+	expect := []string{"publish", "save", "gap", "run", "save", "run", "save"}
+	if diff := cmp.Diff(expect, types); diff != "" {
+		t.Errorf("graph entry type mismatch (-want +got):\n%s", diff)
+	}
+	if graph[2].Path != "HashOfVersion3" {
+		t.Errorf("expected gap to name the missing version. got: %q", graph[2].Path)
+	}
+	if !graph[1].Published || !graph[4].Published || graph[6].Published {
+		t.Errorf("expected publish to apply to the two latest versions")
+	}
+
+	items, err := tr.Book.Items(tr.Ctx, ref, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 {
+		t.Errorf("expected 3 versions & 1 run that didn't save. got %d items", len(items))
+	}
+
+	bad := []struct {
+		description string
+		imp         *logbook.DatasetImport
+	}{
+		{"version without commit", &logbook.DatasetImport{Versions: []*dataset.Dataset{{Path: "a"}}}},
+		{"run without start time", &logbook.DatasetImport{Runs: []*run.State{{ID: "unplaced"}}}},
+		{"publish without versions", &logbook.DatasetImport{Publishes: []logbook.ImportedPublish{{Remote: "registry", Revisions: 1}}}},
+	}
+	for _, c := range bad {
+		t.Run(c.description, func(t *testing.T) {
+			if err := tr.Book.ImportDatasetLog(tr.Ctx, dsref.Ref{Username: tr.Username, Name: "bad_import"}, c.imp); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestImportDatasetLogDelegated(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	delegateID, err := key.IDFromPrivKey(testPrivKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ownerKey := testPrivKey2(t)
+	ownerID, err := key.IDFromPrivKey(ownerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := dsref.Ref{Username: "foreign_owner", Name: "migrated"}
+
+	if err := tr.Book.ImportDatasetLog(tr.Ctx, ref, &logbook.DatasetImport{Versions: importTestVersions()}); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected importing into another namespace without a delegation to be denied. got: %v", err)
+	}
+
+	wrongDelegate, err := logbook.NewDelegation(ownerKey, ref.Username, ref.Name, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.ImportDatasetLog(tr.Ctx, ref, &logbook.DatasetImport{Versions: importTestVersions(), Delegation: wrongDelegate}); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected a delegation issued to another author to be denied. got: %v", err)
+	}
+
+	forged, err := logbook.NewDelegation(ownerKey, ref.Username, ref.Name, delegateID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged.Dataset = "other_dataset"
+	if err := tr.Book.ImportDatasetLog(tr.Ctx, dsref.Ref{Username: ref.Username, Name: "other_dataset"}, &logbook.DatasetImport{Versions: importTestVersions(), Delegation: forged}); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected an altered delegation to be denied. got: %v", err)
+	}
+
+	d, err := logbook.NewDelegation(ownerKey, ref.Username, ref.Name, delegateID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.ImportDatasetLog(tr.Ctx, ref, &logbook.DatasetImport{Versions: importTestVersions(), Delegation: d}); err != nil {
+		t.Fatal(err)
+	}
+
+	initID, err := tr.Book.RefToInitID(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved := dsref.Ref{Username: ref.Username, Name: ref.Name}
+	if _, err := tr.Book.ResolveRef(tr.Ctx, &resolved); err != nil {
+		t.Fatal(err)
+	}
+	if resolved.ProfileID != ownerID {
+		t.Errorf("expected imported dataset to be owned by %q. got: %q", ownerID, resolved.ProfileID)
+	}
+	if resolved.Path != "HashOfVersion4" {
+		t.Errorf("head path mismatch. got: %q", resolved.Path)
+	}
+
+	got, err := tr.Book.Delegation(tr.Ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(d, got); diff != "" {
+		t.Errorf("recorded delegation mismatch (-want +got):\n%s", diff)
+	}
+	if err := got.Verify(); err != nil {
+		t.Errorf("expected recorded delegation to verify. got: %s", err)
+	}
+
+	// delegations only cover the import, the owner's log stays read-only
+	ds := &dataset.Dataset{Commit: &dataset.Commit{Timestamp: time.Now(), Title: "more"}, Path: "HashOfVersion5", PreviousPath: "HashOfVersion4"}
+	if err := tr.Book.WriteVersionSave(tr.Ctx, initID, ds, nil); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected writing to an imported foreign log to be denied. got: %v", err)
+	}
+}
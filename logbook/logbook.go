@@ -9,6 +9,7 @@ package logbook
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"strings"
@@ -24,6 +25,7 @@ import (
 	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/logbook/oplog"
 	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/repo/backup"
 	"github.com/qri-io/qri/transform/run"
 )
 
@@ -62,6 +64,11 @@ const (
 	RunModel
 	// ACLModel is the enum for a acl model
 	ACLModel
+	// LifecycleModel is the enum for dataset lifecycle state changes
+	LifecycleModel
+	// GapModel is the enum for a stretch of missing versions in an imported
+	// history
+	GapModel
 )
 
 const (
@@ -74,6 +81,22 @@ const (
 	// related runID will have op.Relations = [...,"runID:run-uuid-string",...],
 	// This prefix disambiguates from other types of identifiers
 	runIDRelPrefix = "runID:"
+	// successorRelPrefix is a string prefix for op.Relations when recording
+	// lifecycle ops that name a successor dataset reference
+	successorRelPrefix = "successor:"
+	// receiptRelPrefix is a string prefix for op.Relations on push & unpublish
+	// ops that record a receipt issued by the remote
+	receiptRelPrefix = "receipt:"
+)
+
+const (
+	// LifecycleActive is the default lifecycle state of a dataset
+	LifecycleActive = "active"
+	// LifecycleDeprecated marks a dataset that is still maintained, but
+	// shouldn't be used by new consumers
+	LifecycleDeprecated = "deprecated"
+	// LifecycleArchived marks a dataset that is no longer maintained
+	LifecycleArchived = "archived"
 )
 
 // ModelString gets a unique string descriptor for an integral model identifier
@@ -93,6 +116,10 @@ func ModelString(m uint32) string {
 		return "acl"
 	case RunModel:
 		return "run"
+	case LifecycleModel:
+		return "lifecycle"
+	case GapModel:
+		return "gap"
 	default:
 		return ""
 	}
@@ -108,13 +135,21 @@ type Book struct {
 
 	fsLocation string
 	fs         qfs.Filesystem
+	// backups is the number of rotated copies of the book file to keep
+	backups int
+
+	// keys holds dataset log encryption keys
+	keys *DatasetKeys
 
 	publisher event.Publisher
+
+	// observers receive notifications of persisted operations
+	observers *observers
 }
 
 // NewBook creates a book with a user-provided logstore
 func NewBook(pk crypto.PrivKey, store oplog.Logstore) *Book {
-	return &Book{pk: pk, store: store}
+	return &Book{pk: pk, store: store, observers: newObservers()}
 }
 
 // NewJournal initializes a logbook owned by a single author, reading any
@@ -142,6 +177,7 @@ func NewJournal(pk crypto.PrivKey, username string, bus event.Bus, fs qfs.Filesy
 		authorName: username,
 		fsLocation: location,
 		publisher:  bus,
+		observers:  newObservers(),
 	}
 
 	if err := book.load(ctx); err != nil {
@@ -189,6 +225,7 @@ func NewJournalOverwriteWithProfileID(pk crypto.PrivKey, username string, bus ev
 		authorName: username,
 		fsLocation: location,
 		publisher:  bus,
+		observers:  newObservers(),
 	}
 
 	err := book.initialize(ctx, profileID)
@@ -278,12 +315,38 @@ func (book *Book) save(ctx context.Context) (err error) {
 			return err
 		}
 
+		if book.backups > 0 {
+			if err := backup.Rotate(book.fsLocation, book.backups); err != nil {
+				log.Errorf("logbook: rotating backups: %s", err)
+			}
+		}
+
 		file := qfs.NewMemfileBytes(book.fsLocation, ciphertext)
-		book.fsLocation, err = book.fs.Put(ctx, file)
+		if book.fsLocation, err = book.fs.Put(ctx, file); err != nil {
+			return err
+		}
 	}
+	book.notifyObservers(ctx)
 	return err
 }
 
+// SetBackups keeps n rotated copies of the book file, copying the file before
+// each save. Backups are only kept for books stored on the local filesystem
+func (book *Book) SetBackups(n int) {
+	book.backups = n
+}
+
+// ValidateFile checks the bytes of a book file can be decrypted with pk &
+// decoded, returning an error for truncated or corrupt files
+func ValidateFile(ctx context.Context, pk crypto.PrivKey, data []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("logbook: decoding file: %v", r)
+		}
+	}()
+	return (&oplog.Journal{}).UnmarshalFlatbufferCipher(ctx, pk, data)
+}
+
 // load reads the book dataset from book.fsLocation
 func (book *Book) load(ctx context.Context) error {
 	if al, ok := book.store.(oplog.AuthorLogstore); ok {
@@ -446,6 +509,101 @@ func (book *Book) WriteDatasetRename(ctx context.Context, initID string, newName
 	return book.save(ctx)
 }
 
+// Lifecycle describes the lifecycle state of a dataset
+type Lifecycle struct {
+	// State is one of LifecycleActive, LifecycleDeprecated or LifecycleArchived
+	State string `json:"state"`
+	// Reason is a human-readable explanation for the state change
+	Reason string `json:"reason,omitempty"`
+	// Successor is an optional reference to a dataset that replaces this one
+	Successor string `json:"successor,omitempty"`
+	// Timestamp records when the state was set
+	Timestamp time.Time `json:"timestamp,omitempty"`
+}
+
+// IsValidLifecycleState returns true if state is a known lifecycle state
+func IsValidLifecycleState(state string) bool {
+	switch state {
+	case LifecycleActive, LifecycleDeprecated, LifecycleArchived:
+		return true
+	default:
+		return false
+	}
+}
+
+// WriteDatasetLifecycle records a change in the lifecycle state of a dataset
+func (book *Book) WriteDatasetLifecycle(ctx context.Context, initID string, lc Lifecycle) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	if !IsValidLifecycleState(lc.State) {
+		return fmt.Errorf("logbook: invalid lifecycle state %q", lc.State)
+	}
+
+	log.Debugf("WriteDatasetLifecycle: '%s' -> '%s'", initID, lc.State)
+
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+
+	if err := book.hasWriteAccess(dsLog.l); err != nil {
+		return err
+	}
+
+	op := oplog.Op{
+		Type:      oplog.OpTypeAmend,
+		Model:     LifecycleModel,
+		Ref:       lc.State,
+		Note:      lc.Reason,
+		Timestamp: NewTimestamp(),
+	}
+	if lc.Successor != "" {
+		op.Relations = []string{successorRelPrefix + lc.Successor}
+	}
+	dsLog.Append(op)
+
+	return book.save(ctx)
+}
+
+// DatasetLifecycle returns the current lifecycle state of a dataset. Datasets
+// that have never had their state set are active
+func (book *Book) DatasetLifecycle(ctx context.Context, initID string) (Lifecycle, error) {
+	if book == nil {
+		return Lifecycle{}, ErrNoLogbook
+	}
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		if err == oplog.ErrNotFound {
+			return Lifecycle{}, ErrNotFound
+		}
+		return Lifecycle{}, err
+	}
+	return lifecycleFromLog(dsLog.l), nil
+}
+
+// lifecycleFromLog returns the state set by the last lifecycle op in a
+// dataset log
+func lifecycleFromLog(l *oplog.Log) Lifecycle {
+	lc := Lifecycle{State: LifecycleActive}
+	for _, op := range l.Ops {
+		if op.Model != LifecycleModel {
+			continue
+		}
+		lc = Lifecycle{
+			State:     op.Ref,
+			Reason:    op.Note,
+			Timestamp: time.Unix(0, op.Timestamp),
+		}
+		for _, rel := range op.Relations {
+			if strings.HasPrefix(rel, successorRelPrefix) {
+				lc.Successor = strings.TrimPrefix(rel, successorRelPrefix)
+			}
+		}
+	}
+	return lc
+}
+
 // RefToInitID converts a dsref to an initID by iterating the entire logbook looking for a match.
 // This function is inefficient, iterating the entire set of operations in a log. Replacing this
 // function call with mechanisms in dscache will fix this problem.
@@ -530,6 +688,9 @@ func (book *Book) WriteDatasetDelete(ctx context.Context, initID string) error {
 	if err := book.hasWriteAccess(dsLog.l); err != nil {
 		return err
 	}
+	if err := protectionFromLog(dsLog.l).CheckDelete(); err != nil {
+		return err
+	}
 
 	dsLog.Append(oplog.Op{
 		Type:      oplog.OpTypeRemove,
@@ -567,6 +728,11 @@ func (book *Book) WriteVersionSave(ctx context.Context, initID string, ds *datas
 	if err := book.hasWriteAccess(branchLog.l); err != nil {
 		return err
 	}
+	if err := book.checkProtection(ctx, initID, func(p Protection) error {
+		return p.CheckRun(rs)
+	}); err != nil {
+		return err
+	}
 
 	if rs != nil {
 		if rs.ID != ds.Commit.RunID {
@@ -625,6 +791,11 @@ func (book *Book) WriteTransformRun(ctx context.Context, initID string, rs *run.
 }
 
 func (book *Book) appendVersionSave(blog *BranchLog, ds *dataset.Dataset) int {
+	blog.Append(versionSaveOp(ds))
+	return blog.Size() - 1
+}
+
+func versionSaveOp(ds *dataset.Dataset) oplog.Op {
 	op := oplog.Op{
 		Type:  oplog.OpTypeInit,
 		Model: CommitModel,
@@ -641,14 +812,18 @@ func (book *Book) appendVersionSave(blog *BranchLog, ds *dataset.Dataset) int {
 	if ds.Commit.RunID != "" {
 		op.Relations = []string{fmt.Sprintf("%s%s", runIDRelPrefix, ds.Commit.RunID)}
 	}
+	return op
+}
 
-	blog.Append(op)
-
+// appendTransformRun maps fields from run.State to an operation. The op Ref
+// is the runID, which also keys the run's log in the local run log store
+// (see run.LogStore)
+func (book *Book) appendTransformRun(blog *BranchLog, rs *run.State) int {
+	blog.Append(transformRunOp(rs))
 	return blog.Size() - 1
 }
 
-// appendTransformRun maps fields from run.State to an operation.
-func (book *Book) appendTransformRun(blog *BranchLog, rs *run.State) int {
+func transformRunOp(rs *run.State) oplog.Op {
 	op := oplog.Op{
 		Type:  oplog.OpTypeInit,
 		Model: RunModel,
@@ -662,10 +837,7 @@ func (book *Book) appendTransformRun(blog *BranchLog, rs *run.State) int {
 	if rs.StartTime != nil {
 		op.Timestamp = rs.StartTime.UnixNano()
 	}
-
-	blog.Append(op)
-
-	return blog.Size() - 1
+	return op
 }
 
 // WriteVersionAmend adds an operation to a log when a dataset amends a commit
@@ -683,6 +855,9 @@ func (book *Book) WriteVersionAmend(ctx context.Context, initID string, ds *data
 	if err := book.hasWriteAccess(branchLog.l); err != nil {
 		return err
 	}
+	if err := book.checkProtection(ctx, initID, Protection.CheckRewrite); err != nil {
+		return err
+	}
 
 	branchLog.Append(oplog.Op{
 		Type:  oplog.OpTypeAmend,
@@ -713,6 +888,9 @@ func (book *Book) WriteVersionDelete(ctx context.Context, initID string, revisio
 	if err := book.hasWriteAccess(branchLog.l); err != nil {
 		return err
 	}
+	if err := book.checkProtection(ctx, initID, Protection.CheckRewrite); err != nil {
+		return err
+	}
 
 	branchLog.Append(oplog.Op{
 		Type:  oplog.OpTypeRemove,
@@ -857,6 +1035,58 @@ func (book *Book) WriteRemoteDelete(ctx context.Context, initID string, revision
 	return sparseLog, rollback, nil
 }
 
+// WriteRemoteReceipt records a receipt issued by a remote on the latest push
+// or unpublish operation of a dataset. Receipts are opaque strings to the
+// logbook
+func (book *Book) WriteRemoteReceipt(ctx context.Context, initID, receipt string) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	log.Debugf("WriteRemoteReceipt: %s", initID)
+
+	branchLog, err := book.branchLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	if err := book.hasWriteAccess(branchLog.l); err != nil {
+		return err
+	}
+
+	ops := branchLog.l.Ops
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].Model == PushModel {
+			ops[i].Relations = append(ops[i].Relations, receiptRelPrefix+receipt)
+			return book.save(ctx)
+		}
+	}
+	return fmt.Errorf("dataset has no push operations to record a receipt for")
+}
+
+// RemoteReceipts lists the receipts recorded on push & unpublish operations of
+// a dataset, oldest first
+func (book *Book) RemoteReceipts(ctx context.Context, initID string) ([]string, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	branchLog, err := book.branchLog(ctx, initID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := []string{}
+	for _, op := range branchLog.l.Ops {
+		if op.Model != PushModel {
+			continue
+		}
+		for _, rel := range op.Relations {
+			if strings.HasPrefix(rel, receiptRelPrefix) {
+				receipts = append(receipts, strings.TrimPrefix(rel, receiptRelPrefix))
+			}
+		}
+	}
+	return receipts, nil
+}
+
 // ListAllLogs lists all of the logs in the logbook
 func (book Book) ListAllLogs(ctx context.Context) ([]*oplog.Log, error) {
 	return book.store.Logs(ctx, 0, -1)
@@ -971,11 +1201,12 @@ func (book *Book) latestSavePath(branchLog *oplog.Log) string {
 // UserDatasetBranchesLog gets a user's log and a dataset reference.
 // the returned log will be a user log with only one dataset log containing all
 // known branches:
-//   user
-//     dataset
-//       branch
-//       branch
-//       ...
+//
+//	user
+//	  dataset
+//	    branch
+//	    branch
+//	    ...
 func (book Book) UserDatasetBranchesLog(ctx context.Context, datasetInitID string) (*oplog.Log, error) {
 	log.Debugf("UserDatasetBranchesLog datasetInitID=%q", datasetInitID)
 	if datasetInitID == "" {
@@ -1035,8 +1266,14 @@ func (book Book) SignLog(log *oplog.Log) error {
 	return log.Sign(book.pk)
 }
 
-// LogBytes signs a log with this book's private key and writes to a flatbuffer
+// LogBytes signs a log with this book's private key and writes to a flatbuffer.
+// Dataset logs this book holds an encryption key for are encrypted before
+// signing, leaving the passed-in log unmodified
 func (book Book) LogBytes(log *oplog.Log) ([]byte, error) {
+	log, err := book.encryptForSync(log)
+	if err != nil {
+		return nil, err
+	}
 	if err := book.SignLog(log); err != nil {
 		return nil, err
 	}
@@ -1080,6 +1317,22 @@ func (book *Book) MergeLog(ctx context.Context, sender profile.Author, lg *oplog
 	if err := lg.Verify(sender.AuthorPubKey()); err != nil {
 		return err
 	}
+	// signatures cover ciphertext, decrypt only after verifying
+	if err := book.DecryptLog(lg); err != nil {
+		return err
+	}
+	// incoming dataset logs must follow the protection rules of logs on file
+	for _, dsLog := range lg.Logs {
+		existing, err := book.store.Get(ctx, dsLog.ID())
+		if errors.Is(err, oplog.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := checkProtectedMerge(existing, dsLog); err != nil {
+			return err
+		}
+	}
 
 	if err := book.store.MergeLog(ctx, lg); err != nil {
 		return err
@@ -1109,32 +1362,11 @@ func dsRefToLogPath(ref dsref.Ref) (path []string) {
 
 // ConstructDatasetLog creates a sparse log from a connected dataset history
 // where no prior log exists
-// the given history MUST be ordered from oldest to newest commits
-// TODO (b5) - this presently only works for datasets in an author's user
-// namespace
+// the given history MUST be ordered from oldest to newest commits. Use
+// ImportDatasetLog to include runs & publishes, or to construct the log of a
+// dataset owned by another user
 func (book *Book) ConstructDatasetLog(ctx context.Context, ref dsref.Ref, history []*dataset.Dataset) error {
-	if book == nil {
-		return ErrNoLogbook
-	}
-
-	if _, err := book.RefToInitID(ref); err == nil {
-		// if the log already exists, it will either as-or-more rich than this log,
-		// refuse to overwrite
-		return ErrLogTooShort
-	}
-
-	initID, err := book.WriteDatasetInit(ctx, ref.Name)
-	if err != nil {
-		return err
-	}
-	branchLog, err := book.branchLog(ctx, initID)
-	if err != nil {
-		return err
-	}
-	for _, ds := range history {
-		book.appendVersionSave(branchLog, ds)
-	}
-	return book.save(ctx)
+	return book.ImportDatasetLog(ctx, ref, &DatasetImport{Versions: history})
 }
 
 func commitOpRunID(op oplog.Op) string {
@@ -1196,6 +1428,23 @@ func (book Book) Items(ctx context.Context, ref dsref.Ref, offset, limit int) ([
 	return branchToVersionInfos(branchLog, ref, offset, limit, true), nil
 }
 
+// VersionAtTime finds the latest version of a dataset committed at or before
+// a point in time. Deleted versions are skipped. It returns an error wrapping
+// dsref.ErrNoHistory if no version existed at that time
+func (book Book) VersionAtTime(ctx context.Context, ref dsref.Ref, at time.Time) (dsref.VersionInfo, error) {
+	items, err := book.Items(ctx, ref, 0, -1)
+	if err != nil {
+		return dsref.VersionInfo{}, err
+	}
+	for _, item := range items {
+		// items are ordered newest first. skip runs that didn't save a version
+		if item.Path != "" && !item.CommitTime.After(at) {
+			return item, nil
+		}
+	}
+	return dsref.VersionInfo{}, fmt.Errorf("%w: %s has no versions at or before %s", dsref.ErrNoHistory, ref.Human(), at.Format(time.RFC3339))
+}
+
 // ConvertLogsToVersionInfos collapses the history of a dataset branch into linear log items
 func ConvertLogsToVersionInfos(l *oplog.Log, ref dsref.Ref) []dsref.VersionInfo {
 	return branchToVersionInfos(newBranchLog(l), ref, 0, -1, true)
@@ -1320,6 +1569,10 @@ var actionStrings = map[uint32][3]string{
 	CommitModel:  {"save commit", "amend commit", "remove commit"},
 	PushModel:    {"publish", "", "unpublish"},
 	ACLModel:     {"update access", "update access", "remove all access"},
+	// lifecycle ops are only ever "amend" op type
+	LifecycleModel: {"", "set lifecycle", ""},
+	// gap ops are only ever "init" op type
+	GapModel: {"missing history", "", ""},
 }
 
 func logEntryFromOp(author string, op oplog.Op) LogEntry {
@@ -226,6 +226,12 @@ func TestNilCallable(t *testing.T) {
 	if err = book.WriteDatasetDelete(ctx, initID); err != logbook.ErrNoLogbook {
 		t.Errorf("expected '%s', got: %v", logbook.ErrNoLogbook, err)
 	}
+	if err = book.WriteDatasetLifecycle(ctx, initID, logbook.Lifecycle{}); err != logbook.ErrNoLogbook {
+		t.Errorf("expected '%s', got: %v", logbook.ErrNoLogbook, err)
+	}
+	if _, err = book.DatasetLifecycle(ctx, initID); err != logbook.ErrNoLogbook {
+		t.Errorf("expected '%s', got: %v", logbook.ErrNoLogbook, err)
+	}
 	if _, _, err = book.WriteRemotePush(ctx, initID, 0, ""); err != logbook.ErrNoLogbook {
 		t.Errorf("expected '%s', got: %v", logbook.ErrNoLogbook, err)
 	}
@@ -416,6 +422,9 @@ func TestWritePermissions(t *testing.T) {
 	if err := tr.Book.WriteDatasetDelete(ctx, initID); !errors.Is(err, logbook.ErrAccessDenied) {
 		t.Errorf("WriteDatasetDelete to an oplog the book author doesn't own must return a wrap of logbook.ErrAccessDenied")
 	}
+	if err := tr.Book.WriteDatasetLifecycle(ctx, initID, logbook.Lifecycle{State: logbook.LifecycleArchived}); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("WriteDatasetLifecycle to an oplog the book author doesn't own must return a wrap of logbook.ErrAccessDenied")
+	}
 
 	ds := &dataset.Dataset{
 		Peername: tr.Username,
@@ -529,7 +538,42 @@ func TestPushModel(t *testing.T) {
 	if len(lg.Logs[0].Logs[0].Ops) != 3 {
 		t.Errorf("expected branch log to have 3 operations after writing push & delete push. got: %d", len(lg.Logs[0].Logs[0].Ops))
 	}
+}
 
+func TestRemoteReceipts(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+	ctx := tr.Ctx
+
+	initID, err := tr.Book.WriteDatasetInit(ctx, "receipt_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.WriteRemoteReceipt(ctx, initID, "no_push"); err == nil {
+		t.Error("expected writing a receipt without a push to fail")
+	}
+
+	if _, _, err = tr.Book.WriteRemotePush(ctx, initID, 1, "example/remote/address"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.WriteRemoteReceipt(ctx, initID, "push_receipt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = tr.Book.WriteRemoteDelete(ctx, initID, 1, "example/remote/address"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.WriteRemoteReceipt(ctx, initID, "remove_receipt"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := tr.Book.RemoteReceipts(ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"push_receipt", "remove_receipt"}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("receipts mismatch (-want +got):\n%s", diff)
+	}
 }
 
 func TestDatasetLogNaming(t *testing.T) {
@@ -848,6 +892,56 @@ func TestRenameDataset(t *testing.T) {
 	}
 }
 
+func TestDatasetLifecycle(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+
+	lc, err := tr.Book.DatasetLifecycle(tr.Ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lc.State != logbook.LifecycleActive {
+		t.Errorf("expected new dataset to be active, got %q", lc.State)
+	}
+
+	if err := tr.Book.WriteDatasetLifecycle(tr.Ctx, initID, logbook.Lifecycle{State: "retired"}); err == nil {
+		t.Error("expected invalid lifecycle state to error")
+	}
+
+	expect := logbook.Lifecycle{
+		State:     logbook.LifecycleDeprecated,
+		Reason:    "superseded",
+		Successor: "test_author/world_bank_population_v2",
+	}
+	if err := tr.Book.WriteDatasetLifecycle(tr.Ctx, initID, expect); err != nil {
+		t.Fatal(err)
+	}
+	if lc, err = tr.Book.DatasetLifecycle(tr.Ctx, initID); err != nil {
+		t.Fatal(err)
+	}
+	lc.Timestamp = time.Time{}
+	if diff := cmp.Diff(expect, lc); diff != "" {
+		t.Errorf("lifecycle mismatch (-want +got):\n%s", diff)
+	}
+
+	// lifecycle ops must not change the dataset name
+	if _, err := tr.Book.RefToInitID(tr.WorldBankRef()); err != nil {
+		t.Errorf("expected dataset to resolve after lifecycle change, got: %s", err)
+	}
+
+	if err := tr.Book.WriteDatasetLifecycle(tr.Ctx, initID, logbook.Lifecycle{State: logbook.LifecycleActive}); err != nil {
+		t.Fatal(err)
+	}
+	if lc, err = tr.Book.DatasetLifecycle(tr.Ctx, initID); err != nil {
+		t.Fatal(err)
+	}
+	if lc.State != logbook.LifecycleActive || lc.Successor != "" {
+		t.Errorf("expected reactivated dataset to clear lifecycle state, got: %#v", lc)
+	}
+}
+
 func TestItems(t *testing.T) {
 	tr, cleanup := newTestRunner(t)
 	defer cleanup()
@@ -908,6 +1002,106 @@ func TestItems(t *testing.T) {
 	}
 }
 
+func TestVersionAtTime(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+	tr.WriteMoreWorldBankCommits(t, initID)
+	book := tr.Book
+
+	cases := []struct {
+		at         string
+		expectPath string
+	}{
+		{"2000-01-02T19:00:00-05:00", "QmHashOfVersion3"},
+		{"2000-01-03T20:00:00-05:00", "QmHashOfVersion4"},
+		{"2020-01-01T00:00:00Z", "QmHashOfVersion5"},
+	}
+	for _, c := range cases {
+		vi, err := book.VersionAtTime(tr.Ctx, tr.WorldBankRef(), mustTime(c.at))
+		if err != nil {
+			t.Errorf("%s: %s", c.at, err)
+			continue
+		}
+		if vi.Path != c.expectPath {
+			t.Errorf("%s: path mismatch. want: %q, got: %q", c.at, c.expectPath, vi.Path)
+		}
+	}
+
+	if _, err := book.VersionAtTime(tr.Ctx, tr.WorldBankRef(), mustTime("1999-01-01T00:00:00Z")); !errors.Is(err, dsref.ErrNoHistory) {
+		t.Errorf("expected time before the first version to return ErrNoHistory, got: %v", err)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+	tr.WriteMoreWorldBankCommits(t, initID)
+	book := tr.Book
+
+	type entry struct {
+		Type      string
+		Path      string
+		Deleted   bool
+		Revisions int
+	}
+	summarize := func(entries []logbook.GraphEntry) []entry {
+		res := make([]entry, len(entries))
+		for i, e := range entries {
+			res[i] = entry{e.Type, e.Path, e.Deleted, e.Revisions}
+		}
+		return res
+	}
+
+	got, err := book.Graph(tr.Ctx, tr.WorldBankRef(), 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []entry{
+		{logbook.GraphSave, "QmHashOfVersion5", false, 0},
+		{logbook.GraphSave, "QmHashOfVersion4", false, 0},
+		{logbook.GraphAmend, "QmHashOfVersion3", false, 0},
+		{logbook.GraphRemove, "", false, 1},
+		{logbook.GraphUnpublish, "", false, 2},
+		{logbook.GraphPublish, "", false, 2},
+		{logbook.GraphSave, "QmHashOfVersion2", true, 0},
+		{logbook.GraphSave, "QmHashOfVersion1", true, 0},
+	}
+	if diff := cmp.Diff(expect, summarize(got)); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+	if got[5].Remote != "registry.qri.cloud" {
+		t.Errorf("expected publish entry remote to be %q, got: %q", "registry.qri.cloud", got[5].Remote)
+	}
+
+	got, err = book.Graph(tr.Ctx, tr.WorldBankRef(), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect[1:3], summarize(got)); diff != "" {
+		t.Errorf("paginated result mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := book.WriteDatasetDelete(tr.Ctx, initID); err != nil {
+		t.Fatal(err)
+	}
+	got, err = book.Graph(tr.Ctx, tr.WorldBankRef(), 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Type != logbook.GraphDelete {
+		t.Errorf("expected newest entry to be a delete, got: %q", got[0].Type)
+	}
+	for _, e := range got {
+		if e.Path != "" && !e.Deleted {
+			t.Errorf("expected all versions to be tombstoned after dataset delete, %q isn't", e.Path)
+		}
+	}
+}
+
 func TestConstructDatasetLog(t *testing.T) {
 	tr, cleanup := newTestRunner(t)
 	defer cleanup()
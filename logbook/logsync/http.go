@@ -101,10 +101,14 @@ func (c *httpClient) get(ctx context.Context, author profile.Author, ref dsref.R
 
 	if res.StatusCode != http.StatusOK {
 		log.Debugf("httpClient.get statusCode=%d", res.StatusCode)
-		if errmsg, err := ioutil.ReadAll(res.Body); err == nil {
-			return nil, nil, fmt.Errorf(string(errmsg))
+		errmsg, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, nil, err
 		}
-		return nil, nil, err
+		if res.StatusCode == http.StatusNotFound {
+			return nil, nil, notFoundError(errmsg)
+		}
+		return nil, nil, fmt.Errorf(string(errmsg))
 	}
 
 	sender, err := senderFromHTTPHeaders(res.Header)
@@ -115,6 +119,14 @@ func (c *httpClient) get(ctx context.Context, author profile.Author, ref dsref.R
 	return sender, res.Body, nil
 }
 
+// notFoundError is the message of a not found response from the other end of
+// the wire. notFoundErrors match logbook.ErrNotFound
+type notFoundError string
+
+func (e notFoundError) Error() string { return string(e) }
+
+func (e notFoundError) Is(target error) bool { return target == logbook.ErrNotFound }
+
 func (c *httpClient) del(ctx context.Context, author profile.Author, ref dsref.Ref) error {
 	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s?ref=%s", c.URL, ref), nil)
 	if err != nil {
@@ -207,7 +219,7 @@ func HTTPHandler(lsync *Logsync) http.HandlerFunc {
 			if err != nil {
 				log.Debugf("GET error=%q ref=%q", err, ref)
 				// TODO (ramfox): implement a robust error response strategy
-				if errors.Is(err, logbook.ErrNotFound) {
+				if errors.Is(err, logbook.ErrNotFound) || errors.Is(err, dsref.ErrRefNotFound) {
 					w.WriteHeader(http.StatusNotFound)
 					w.Write([]byte(err.Error()))
 					return
@@ -406,6 +406,8 @@ func (p *Pull) Do(ctx context.Context) (*oplog.Log, error) {
 		if err := p.book.MergeLog(ctx, sender, l); err != nil {
 			return nil, err
 		}
+	} else if err := p.book.DecryptLog(l); err != nil {
+		return nil, err
 	}
 
 	return l, nil
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -346,6 +347,80 @@ func TestWrongProfileID(t *testing.T) {
 	}
 }
 
+func TestEncryptedPush(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	worldBankRef, err := writeWorldBankLogs(tr.Ctx, tr.B)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := logbook.NewDatasetKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.B.SetDatasetKey(worldBankRef.InitID, key); err != nil {
+		t.Fatal(err)
+	}
+
+	s := httptest.NewServer(HTTPHandler(New(tr.A)))
+	defer s.Close()
+
+	push, err := New(tr.B).NewPush(worldBankRef, s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := push.Do(tr.Ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	expect, err := tr.B.Items(tr.Ctx, worldBankRef, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the remote can resolve the dataset, but can't read version details
+	items, err := tr.A.Items(tr.Ctx, worldBankRef, 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != len(expect) {
+		t.Fatalf("expected remote to have %d versions, got %d", len(expect), len(items))
+	}
+	for _, item := range items {
+		if !strings.HasPrefix(item.Path, "enc:") || item.CommitTitle == expect[0].CommitTitle {
+			t.Errorf("expected remote version info to be encrypted, got path=%q title=%q", item.Path, item.CommitTitle)
+		}
+	}
+
+	// a collaborator holding the key reads the log pulled from the remote
+	pk, err := decodePk(testkeys.GetKeyData(8).EncodedPrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := newTestbook("c", pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetDatasetKey(worldBankRef.InitID, key); err != nil {
+		t.Fatal(err)
+	}
+	pull, err := New(c).NewPull(worldBankRef, s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pull.Merge = true
+	if _, err := pull.Do(tr.Ctx); err != nil {
+		t.Fatal(err)
+	}
+	if items, err = c.Items(tr.Ctx, worldBankRef, 0, 100); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect, items); diff != "" {
+		t.Errorf("decrypted versions mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestNilCallable(t *testing.T) {
 	var logsync *Logsync
 
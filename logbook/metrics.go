@@ -0,0 +1,130 @@
+package logbook
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+// MetricsRecentPeriod is the window of time LogMetrics.Recent counts
+// operations in
+var MetricsRecentPeriod = time.Hour * 24 * 30
+
+// Metrics describes the size of a logbook & how quickly it's growing
+type Metrics struct {
+	// Logs is the number of dataset logs in the book
+	Logs int `json:"logs"`
+	// Ops is the number of operations in the book, across all logs
+	Ops int `json:"ops"`
+	// Bytes is the encoded size of the book
+	Bytes int `json:"bytes"`
+	// Growth counts operations written each month, oldest first
+	Growth []GrowthPeriod `json:"growth"`
+	// Datasets describes each dataset log, largest first
+	Datasets []LogMetrics `json:"datasets"`
+}
+
+// LogMetrics describes the size of a dataset log, including its branches
+type LogMetrics struct {
+	ID      string `json:"id"`
+	Ref     string `json:"ref"`
+	Removed bool   `json:"removed,omitempty"`
+	Ops     int    `json:"ops"`
+	Bytes   int    `json:"bytes"`
+	// Recent is the number of operations written in the last
+	// MetricsRecentPeriod
+	Recent int       `json:"recent"`
+	First  time.Time `json:"first,omitempty"`
+	Last   time.Time `json:"last,omitempty"`
+}
+
+// GrowthPeriod counts operations written to a book in a calendar month
+type GrowthPeriod struct {
+	Start time.Time `json:"start"`
+	Ops   int       `json:"ops"`
+	// Logs is the number of dataset logs created in the period
+	Logs int `json:"logs"`
+}
+
+// Metrics reports op counts & encoded sizes for each dataset log in the book.
+// Logs that grow large are candidates for compaction. Op timestamps are
+// annotations, so growth is only as accurate as the clocks of the authors
+// that wrote each operation
+func (book *Book) Metrics(ctx context.Context) (*Metrics, error) {
+	if book == nil {
+		return nil, ErrNoLogbook
+	}
+	logs, err := book.store.Logs(ctx, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	recent := time.Now().Add(-MetricsRecentPeriod)
+	months := map[time.Time]*GrowthPeriod{}
+	period := func(ts int64) *GrowthPeriod {
+		t := time.Unix(0, ts).UTC()
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if p, ok := months[start]; ok {
+			return p
+		}
+		p := &GrowthPeriod{Start: start}
+		months[start] = p
+		return p
+	}
+
+	m := &Metrics{Growth: []GrowthPeriod{}, Datasets: []LogMetrics{}}
+	for _, user := range logs {
+		m.Ops += len(user.Ops)
+		m.Bytes += len(user.FlatbufferBytes())
+		for _, op := range user.Ops {
+			period(op.Timestamp).Ops++
+		}
+
+		for _, ds := range user.Logs {
+			lm := LogMetrics{
+				ID:      ds.ID(),
+				Ref:     user.Name() + "/" + ds.Name(),
+				Removed: ds.Removed(),
+				Bytes:   len(ds.FlatbufferBytes()),
+			}
+			if len(ds.Ops) > 0 {
+				period(ds.Ops[0].Timestamp).Logs++
+			}
+
+			var walk func(l *oplog.Log)
+			walk = func(l *oplog.Log) {
+				for _, op := range l.Ops {
+					lm.Ops++
+					period(op.Timestamp).Ops++
+					t := time.Unix(0, op.Timestamp)
+					if lm.First.IsZero() || t.Before(lm.First) {
+						lm.First = t
+					}
+					if t.After(lm.Last) {
+						lm.Last = t
+					}
+					if t.After(recent) {
+						lm.Recent++
+					}
+				}
+				for _, child := range l.Logs {
+					walk(child)
+				}
+			}
+			walk(ds)
+
+			m.Logs++
+			m.Ops += lm.Ops
+			m.Datasets = append(m.Datasets, lm)
+		}
+	}
+
+	for _, p := range months {
+		m.Growth = append(m.Growth, *p)
+	}
+	sort.Slice(m.Growth, func(i, j int) bool { return m.Growth[i].Start.Before(m.Growth[j].Start) })
+	sort.SliceStable(m.Datasets, func(i, j int) bool { return m.Datasets[i].Bytes > m.Datasets[j].Bytes })
+	return m, nil
+}
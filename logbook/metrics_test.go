@@ -0,0 +1,80 @@
+package logbook_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logbook"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	book, err := logbook.NewJournal(testPrivKey(t), "metrics", event.NilBus, qfs.NewMemFS(), "/mem/logbook.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := book.WriteDatasetInit(ctx, "small"); err != nil {
+		t.Fatal(err)
+	}
+	initID, err := book.WriteDatasetInit(ctx, "big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, path := range []string{"/ipfs/QmOne", "/ipfs/QmTwo", "/ipfs/QmThree"} {
+		err = book.WriteVersionSave(ctx, initID, &dataset.Dataset{
+			Peername: "metrics",
+			Name:     "big",
+			Path:     path,
+			Commit: &dataset.Commit{
+				Timestamp: time.Date(2000, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC),
+				Title:     "commit",
+			},
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := book.Metrics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Logs != 2 {
+		t.Errorf("expected 2 dataset logs, got %d", m.Logs)
+	}
+	if len(m.Datasets) != 2 || m.Datasets[0].Ref != "metrics/big" {
+		t.Fatalf("expected largest dataset first, got: %#v", m.Datasets)
+	}
+	big := m.Datasets[0]
+	// dataset init, branch init & 3 commits
+	if big.Ops != 5 {
+		t.Errorf("expected 5 ops, got %d", big.Ops)
+	}
+	if big.Bytes <= m.Datasets[1].Bytes || m.Bytes < big.Bytes+m.Datasets[1].Bytes {
+		t.Errorf("unexpected sizes. book: %d datasets: %d, %d", m.Bytes, big.Bytes, m.Datasets[1].Bytes)
+	}
+	if big.Recent != 2 {
+		t.Errorf("expected only init ops to be recent, got %d", big.Recent)
+	}
+	if !big.First.Equal(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected first op time: %s", big.First)
+	}
+
+	if len(m.Growth) < 4 {
+		t.Fatalf("expected growth for 3 commit months & the current month, got: %#v", m.Growth)
+	}
+	for i, g := range m.Growth[:3] {
+		if g.Start.Month() != time.Month(i+1) || g.Ops != 1 || g.Logs != 0 {
+			t.Errorf("growth period %d mismatch: %#v", i, g)
+		}
+	}
+	last := m.Growth[len(m.Growth)-1]
+	if last.Logs != 2 {
+		t.Errorf("expected 2 logs created this month, got %d", last.Logs)
+	}
+}
@@ -0,0 +1,159 @@
+package logbook
+
+import (
+	"context"
+	"sync"
+
+	"github.com/qri-io/qri/logbook/oplog"
+)
+
+// OpNotificationBufferSize is the number of notifications a subscription
+// channel holds before the book waits for the subscriber to read
+const OpNotificationBufferSize = 128
+
+// OpNotification describes a single operation persisted to a logbook
+type OpNotification struct {
+	// LogID is the ID of the log the operation was written to
+	LogID string
+	// Path is the names of the log and all its ancestors, starting from the
+	// root log. For operations on a dataset branch this is
+	// [username, dataset name, branch name]
+	Path []string
+	// Index is the position of the operation in the log
+	Index int
+	// Op is the persisted operation
+	Op oplog.Op
+}
+
+// observers tracks logbook subscriptions. ops is the number of operations
+// in each log as of the last notification, used to find newly written ops
+type observers struct {
+	lk   sync.Mutex
+	subs map[int]*subscription
+	next int
+	ops  map[string]int
+}
+
+func newObservers() *observers {
+	return &observers{subs: map[int]*subscription{}}
+}
+
+type subscription struct {
+	ch   chan OpNotification
+	done <-chan struct{}
+}
+
+// Subscribe returns a channel that receives a notification for each
+// operation persisted to the book after the call, including operations merged
+// from other authors. Notifications are sent in the order operations are
+// saved. The subscription ends & the channel is closed when ctx is cancelled
+// or the returned cancel function is called.
+//
+// Subscribers must drain the channel promptly: once a subscriber's buffer is
+// full, writes to the book wait for it to read
+func (book *Book) Subscribe(ctx context.Context) (<-chan OpNotification, func()) {
+	ch := make(chan OpNotification, OpNotificationBufferSize)
+	if book == nil {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if book.observers == nil {
+		book.observers = newObservers()
+	}
+	obs := book.observers
+
+	ctx, cancel := context.WithCancel(ctx)
+	obs.lk.Lock()
+	if len(obs.subs) == 0 {
+		// take a baseline so only operations written from here on are sent
+		obs.ops = book.opCounts(ctx)
+	}
+	id := obs.next
+	obs.next++
+	obs.subs[id] = &subscription{ch: ch, done: ctx.Done()}
+	obs.lk.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		obs.lk.Lock()
+		delete(obs.subs, id)
+		obs.lk.Unlock()
+		close(ch)
+	}()
+
+	return ch, cancel
+}
+
+// notifyObservers sends notifications for any operations written since the
+// last call to all subscribers
+func (book *Book) notifyObservers(ctx context.Context) {
+	obs := book.observers
+	if obs == nil {
+		return
+	}
+	obs.lk.Lock()
+	defer obs.lk.Unlock()
+	if len(obs.subs) == 0 {
+		return
+	}
+
+	logs, err := book.store.Logs(ctx, 0, -1)
+	if err != nil {
+		log.Debugw("notifying logbook observers", "err", err)
+		return
+	}
+
+	var notes []OpNotification
+	counts := map[string]int{}
+	var walk func(l *oplog.Log, path []string)
+	walk = func(l *oplog.Log, path []string) {
+		path = append(path[:len(path):len(path)], l.Name())
+		id := l.ID()
+		counts[id] = len(l.Ops)
+		for i := obs.ops[id]; i < len(l.Ops); i++ {
+			notes = append(notes, OpNotification{
+				LogID: id,
+				Path:  path,
+				Index: i,
+				Op:    l.Ops[i],
+			})
+		}
+		for _, child := range l.Logs {
+			walk(child, path)
+		}
+	}
+	for _, l := range logs {
+		walk(l, nil)
+	}
+	obs.ops = counts
+
+	for _, note := range notes {
+		for _, sub := range obs.subs {
+			select {
+			case sub.ch <- note:
+			case <-sub.done:
+			}
+		}
+	}
+}
+
+// opCounts maps the ID of each log in the book to its number of operations
+func (book *Book) opCounts(ctx context.Context) map[string]int {
+	counts := map[string]int{}
+	logs, err := book.store.Logs(ctx, 0, -1)
+	if err != nil {
+		return counts
+	}
+	var walk func(l *oplog.Log)
+	walk = func(l *oplog.Log) {
+		counts[l.ID()] = len(l.Ops)
+		for _, child := range l.Logs {
+			walk(child)
+		}
+	}
+	for _, l := range logs {
+		walk(l)
+	}
+	return counts
+}
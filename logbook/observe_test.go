@@ -0,0 +1,87 @@
+package logbook_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/logbook"
+)
+
+func TestSubscribe(t *testing.T) {
+	ctx, done := context.WithCancel(context.Background())
+	defer done()
+
+	book, err := logbook.NewJournal(testPrivKey(t), "observer", event.NilBus, qfs.NewMemFS(), "/mem/logbook.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// operations written before subscribing aren't sent
+	if _, err := book.WriteDatasetInit(ctx, "before"); err != nil {
+		t.Fatal(err)
+	}
+
+	notes, cancel := book.Subscribe(ctx)
+
+	initID, err := book.WriteDatasetInit(ctx, "cities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = book.WriteVersionSave(ctx, initID, &dataset.Dataset{
+		Peername: "observer",
+		Name:     "cities",
+		Path:     "/ipfs/QmVersion",
+		Commit: &dataset.Commit{
+			Timestamp: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			Title:     "initial commit",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for i := 0; i < 3; i++ {
+		select {
+		case n := <-notes:
+			got = append(got, logbook.ModelString(n.Op.Model)+":"+strings.Join(n.Path, "/"))
+			if n.Op.Model == logbook.CommitModel && n.Op.Ref != "/ipfs/QmVersion" {
+				t.Errorf("commit op ref mismatch. got: %q", n.Op.Ref)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for notification %d", i)
+		}
+	}
+
+	// the dataset & branch inits are written together, their order follows the
+	// log tree
+	expect := []string{
+		"dataset:observer/cities",
+		"branch:observer/cities/main",
+		"commit:observer/cities/main",
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-notes:
+		if ok {
+			t.Error("expected no more notifications after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Error("expected channel to close after cancel")
+	}
+
+	// writes after cancelling don't block
+	if _, err := book.WriteDatasetInit(ctx, "after"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,250 @@
+package logbook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/qri-io/qri/logbook/oplog"
+	"github.com/qri-io/qri/transform/run"
+)
+
+const (
+	// protectionOpName names ACL ops that set dataset protection
+	protectionOpName = "protection"
+	// protectRelPrefix is a string prefix for op.Relations on protection ops,
+	// each relation enables one rule
+	protectRelPrefix = "protect:"
+
+	protectNoRewrite             = "no-rewrite"
+	protectRequireSuccessfulRuns = "require-successful-runs"
+	protectNoDelete              = "no-delete"
+)
+
+// ErrProtected indicates a logbook operation is forbidden by the protection
+// rules of a dataset
+var ErrProtected = fmt.Errorf("%w: dataset is protected", ErrAccessDenied)
+
+// Protection is a set of rules that guard the history of a dataset. Rules are
+// checked when writing operations & when merging logs from other authors
+type Protection struct {
+	// NoRewrite forbids amending & deleting versions
+	NoRewrite bool `json:"noRewrite,omitempty"`
+	// RequireSuccessfulRuns only allows commits created by a transform run
+	// that succeeded
+	RequireSuccessfulRuns bool `json:"requireSuccessfulRuns,omitempty"`
+	// NoDelete forbids deleting the dataset
+	NoDelete bool `json:"noDelete,omitempty"`
+	// Timestamp records when the rules were set
+	Timestamp time.Time `json:"timestamp,omitempty"`
+}
+
+// IsZero returns true if no rules are enabled
+func (p Protection) IsZero() bool {
+	return !p.NoRewrite && !p.RequireSuccessfulRuns && !p.NoDelete
+}
+
+// WriteDatasetProtection sets the protection rules of a dataset, replacing
+// any earlier rules. Rules are stored as an ACL operation in the dataset log
+func (book *Book) WriteDatasetProtection(ctx context.Context, initID string, p Protection) error {
+	if book == nil {
+		return ErrNoLogbook
+	}
+	log.Debugf("WriteDatasetProtection: '%s'", initID)
+
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+
+	if err := book.hasWriteAccess(dsLog.l); err != nil {
+		return err
+	}
+
+	op := oplog.Op{
+		Type:      oplog.OpTypeAmend,
+		Model:     ACLModel,
+		Name:      protectionOpName,
+		Timestamp: NewTimestamp(),
+	}
+	if p.NoRewrite {
+		op.Relations = append(op.Relations, protectRelPrefix+protectNoRewrite)
+	}
+	if p.RequireSuccessfulRuns {
+		op.Relations = append(op.Relations, protectRelPrefix+protectRequireSuccessfulRuns)
+	}
+	if p.NoDelete {
+		op.Relations = append(op.Relations, protectRelPrefix+protectNoDelete)
+	}
+	dsLog.Append(op)
+
+	return book.save(ctx)
+}
+
+// DatasetProtection returns the protection rules of a dataset. Datasets that
+// have never set rules are unprotected
+func (book *Book) DatasetProtection(ctx context.Context, initID string) (Protection, error) {
+	if book == nil {
+		return Protection{}, ErrNoLogbook
+	}
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		if errors.Is(err, oplog.ErrNotFound) {
+			return Protection{}, ErrNotFound
+		}
+		return Protection{}, err
+	}
+	return protectionFromLog(dsLog.l), nil
+}
+
+// protectionFromLog returns the rules set by the last protection op in a
+// dataset log
+func protectionFromLog(l *oplog.Log) Protection {
+	p := Protection{}
+	for _, op := range l.Ops {
+		if op.Model != ACLModel || op.Name != protectionOpName {
+			continue
+		}
+		p = Protection{Timestamp: time.Unix(0, op.Timestamp)}
+		for _, rel := range op.Relations {
+			switch strings.TrimPrefix(rel, protectRelPrefix) {
+			case protectNoRewrite:
+				p.NoRewrite = true
+			case protectRequireSuccessfulRuns:
+				p.RequireSuccessfulRuns = true
+			case protectNoDelete:
+				p.NoDelete = true
+			}
+		}
+	}
+	return p
+}
+
+// checkProtection checks a rule against the protection of a dataset
+func (book *Book) checkProtection(ctx context.Context, initID string, rule func(Protection) error) error {
+	dsLog, err := book.datasetLog(ctx, initID)
+	if err != nil {
+		return err
+	}
+	return rule(protectionFromLog(dsLog.l))
+}
+
+// CheckRewrite returns ErrProtected if versions can't be amended or deleted
+func (p Protection) CheckRewrite() error {
+	if p.NoRewrite {
+		return fmt.Errorf("%w: versions can't be amended or deleted", ErrProtected)
+	}
+	return nil
+}
+
+// CheckDelete returns ErrProtected if the dataset can't be deleted
+func (p Protection) CheckDelete() error {
+	if p.NoDelete {
+		return fmt.Errorf("%w: the dataset can't be deleted", ErrProtected)
+	}
+	return nil
+}
+
+// CheckRun returns ErrProtected if a version created by a run isn't allowed.
+// rs is nil for versions that weren't created by a transform
+func (p Protection) CheckRun(rs *run.State) error {
+	if !p.RequireSuccessfulRuns {
+		return nil
+	}
+	if rs == nil {
+		return fmt.Errorf("%w: versions must be created by a transform run", ErrProtected)
+	}
+	if rs.Status != run.RSSucceeded {
+		return fmt.Errorf("%w: run %s has status %q, versions require a successful run", ErrProtected, rs.ID, rs.Status)
+	}
+	return nil
+}
+
+// checkProtectedMerge compares an incoming dataset log with the log on file,
+// returning ErrProtected if the incoming log breaks the rules of the log on
+// file. Logs are merged by replacing shorter operation lists with longer ones,
+// so the incoming log must extend the operations on file
+func checkProtectedMerge(existing, incoming *oplog.Log) error {
+	p := protectionFromLog(existing)
+	if p.IsZero() {
+		return nil
+	}
+
+	if p.NoDelete {
+		for _, op := range newOps(existing, incoming) {
+			if op.Model == DatasetModel && op.Type == oplog.OpTypeRemove {
+				return p.CheckDelete()
+			}
+		}
+	}
+
+	for _, in := range incoming.Logs {
+		var branch *oplog.Log
+		for _, l := range existing.Logs {
+			if l.ID() == in.ID() {
+				branch = l
+				break
+			}
+		}
+		if branch == nil {
+			continue
+		}
+
+		if p.NoRewrite {
+			if !extendsOps(branch, in) {
+				return fmt.Errorf("%w: history can't be rewritten", ErrProtected)
+			}
+			for _, op := range newOps(branch, in) {
+				if op.Model == CommitModel && op.Type != oplog.OpTypeInit {
+					return p.CheckRewrite()
+				}
+			}
+		}
+
+		if p.RequireSuccessfulRuns {
+			runs := map[string]string{}
+			for _, op := range in.Ops {
+				if op.Model == RunModel {
+					runs[op.Ref] = op.Note
+				}
+			}
+			for _, op := range newOps(branch, in) {
+				if op.Model != CommitModel || op.Type != oplog.OpTypeInit {
+					continue
+				}
+				runID := commitOpRunID(op)
+				if runID == "" {
+					return fmt.Errorf("%w: version %s wasn't created by a transform run", ErrProtected, op.Ref)
+				}
+				if status := runs[runID]; status != string(run.RSSucceeded) {
+					return fmt.Errorf("%w: version %s was created by run %s with status %q, versions require a successful run", ErrProtected, op.Ref, runID, status)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// extendsOps returns true if merging leaves every operation on file unchanged.
+// Incoming logs that aren't longer than the log on file are ignored by merges
+func extendsOps(existing, incoming *oplog.Log) bool {
+	if len(incoming.Ops) <= len(existing.Ops) {
+		return true
+	}
+	for i, op := range existing.Ops {
+		if !op.Equal(incoming.Ops[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// newOps returns incoming operations past the end of the log on file
+func newOps(existing, incoming *oplog.Log) []oplog.Op {
+	if len(incoming.Ops) <= len(existing.Ops) {
+		return nil
+	}
+	return incoming.Ops[len(existing.Ops):]
+}
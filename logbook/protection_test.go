@@ -0,0 +1,170 @@
+package logbook_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/logbook/oplog"
+	"github.com/qri-io/qri/transform/run"
+)
+
+func TestDatasetProtection(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+
+	p, err := tr.Book.DatasetProtection(tr.Ctx, initID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsZero() {
+		t.Errorf("expected new dataset to be unprotected, got: %#v", p)
+	}
+
+	if err := tr.Book.WriteDatasetProtection(tr.Ctx, initID, logbook.Protection{NoRewrite: true, NoDelete: true}); err != nil {
+		t.Fatal(err)
+	}
+	if p, err = tr.Book.DatasetProtection(tr.Ctx, initID); err != nil {
+		t.Fatal(err)
+	}
+	if !p.NoRewrite || !p.NoDelete || p.RequireSuccessfulRuns {
+		t.Errorf("protection mismatch, got: %#v", p)
+	}
+
+	ds := &dataset.Dataset{
+		Commit: &dataset.Commit{
+			Timestamp: time.Date(2000, time.January, 4, 0, 0, 0, 0, time.UTC),
+			Title:     "amended",
+		},
+		Path:         "QmHashOfVersion4",
+		PreviousPath: "QmHashOfVersion3",
+	}
+	if err := tr.Book.WriteVersionAmend(tr.Ctx, initID, ds); !errors.Is(err, logbook.ErrProtected) {
+		t.Errorf("expected amend to fail with ErrProtected, got: %v", err)
+	}
+	if err := tr.Book.WriteVersionDelete(tr.Ctx, initID, 1); !errors.Is(err, logbook.ErrAccessDenied) {
+		t.Errorf("expected version delete to fail with ErrAccessDenied, got: %v", err)
+	}
+	if err := tr.Book.WriteDatasetDelete(tr.Ctx, initID); !errors.Is(err, logbook.ErrProtected) {
+		t.Errorf("expected dataset delete to fail with ErrProtected, got: %v", err)
+	}
+	// protection doesn't block new versions
+	if err := tr.Book.WriteVersionSave(tr.Ctx, initID, ds, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tr.Book.WriteDatasetProtection(tr.Ctx, initID, logbook.Protection{RequireSuccessfulRuns: true}); err != nil {
+		t.Fatal(err)
+	}
+	ds = &dataset.Dataset{
+		Commit: &dataset.Commit{
+			Timestamp: time.Date(2000, time.January, 5, 0, 0, 0, 0, time.UTC),
+			Title:     "transformed",
+			RunID:     "run-1",
+		},
+		Path:         "QmHashOfVersion5",
+		PreviousPath: "QmHashOfVersion4",
+	}
+	if err := tr.Book.WriteVersionSave(tr.Ctx, initID, ds, nil); !errors.Is(err, logbook.ErrProtected) {
+		t.Errorf("expected save without a run to fail with ErrProtected, got: %v", err)
+	}
+	if err := tr.Book.WriteVersionSave(tr.Ctx, initID, ds, &run.State{ID: "run-1", Status: run.RSFailed}); !errors.Is(err, logbook.ErrProtected) {
+		t.Errorf("expected save with a failed run to fail with ErrProtected, got: %v", err)
+	}
+	if err := tr.Book.WriteVersionSave(tr.Ctx, initID, ds, &run.State{ID: "run-1", Status: run.RSSucceeded}); err != nil {
+		t.Fatal(err)
+	}
+
+	// later rules replace earlier ones
+	if err := tr.Book.WriteVersionDelete(tr.Ctx, initID, 1); err != nil {
+		t.Errorf("expected version delete to succeed once rules are replaced, got: %v", err)
+	}
+
+	if err := tr.Book.WriteDatasetProtection(tr.Ctx, initID, logbook.Protection{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Book.WriteDatasetDelete(tr.Ctx, initID); err != nil {
+		t.Errorf("expected dataset delete to succeed once protection is cleared, got: %v", err)
+	}
+}
+
+func TestMergeProtectedLog(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	initID := tr.WriteWorldBankExample(t)
+	if err := tr.Book.WriteDatasetProtection(tr.Ctx, initID, logbook.Protection{NoRewrite: true, RequireSuccessfulRuns: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	book2, err := logbook.NewJournal(testPrivKey2(t), "user2", tr.bus, qfs.NewMemFS(), "/mem/fs2_location.qfb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedLog := func(edit func(branch *oplog.Log)) *oplog.Log {
+		t.Helper()
+		lg, err := tr.Book.UserDatasetBranchesLog(tr.Ctx, initID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lg = lg.DeepCopy()
+		if edit != nil {
+			edit(lg.Logs[0].Logs[0])
+		}
+		if err := tr.Book.SignLog(lg); err != nil {
+			t.Fatal(err)
+		}
+		return lg
+	}
+
+	if err := book2.MergeLog(tr.Ctx, tr.Book.Author(), signedLog(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		description string
+		edit        func(branch *oplog.Log)
+	}{
+		{"version delete", func(branch *oplog.Log) {
+			branch.Append(oplog.Op{Type: oplog.OpTypeRemove, Model: logbook.CommitModel, Size: 1})
+		}},
+		{"rewritten history", func(branch *oplog.Log) {
+			branch.Ops[1].Note = "rewritten"
+			branch.Append(oplog.Op{Type: oplog.OpTypeInit, Model: logbook.RunModel, Ref: "run-1", Note: string(run.RSSucceeded)})
+		}},
+		{"commit without a run", func(branch *oplog.Log) {
+			branch.Append(oplog.Op{Type: oplog.OpTypeInit, Model: logbook.CommitModel, Ref: "QmHashOfVersion4"})
+		}},
+		{"commit from a failed run", func(branch *oplog.Log) {
+			branch.Append(oplog.Op{Type: oplog.OpTypeInit, Model: logbook.RunModel, Ref: "run-1", Note: string(run.RSFailed)})
+			branch.Append(oplog.Op{Type: oplog.OpTypeInit, Model: logbook.CommitModel, Ref: "QmHashOfVersion4", Relations: []string{"runID:run-1"}})
+		}},
+	}
+	for _, c := range cases {
+		if err := book2.MergeLog(tr.Ctx, tr.Book.Author(), signedLog(c.edit)); !errors.Is(err, logbook.ErrProtected) {
+			t.Errorf("case %q: expected merge to fail with ErrProtected, got: %v", c.description, err)
+		}
+	}
+
+	ds := &dataset.Dataset{
+		Commit: &dataset.Commit{
+			Timestamp: time.Date(2000, time.January, 4, 0, 0, 0, 0, time.UTC),
+			Title:     "transformed",
+			RunID:     "run-1",
+		},
+		Path:         "QmHashOfVersion4",
+		PreviousPath: "QmHashOfVersion3",
+	}
+	if err := tr.Book.WriteVersionSave(tr.Ctx, initID, ds, &run.State{ID: "run-1", Status: run.RSSucceeded}); err != nil {
+		t.Fatal(err)
+	}
+	if err := book2.MergeLog(tr.Ctx, tr.Book.Author(), signedLog(nil)); err != nil {
+		t.Errorf("expected merging new versions from successful runs to succeed, got: %v", err)
+	}
+}
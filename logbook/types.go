@@ -48,7 +48,7 @@ func newDatasetLog(log *oplog.Log) *DatasetLog {
 
 // Append adds an op to the DatasetLog
 func (dlog *DatasetLog) Append(op oplog.Op) {
-	if op.Model != DatasetModel {
+	if op.Model != DatasetModel && op.Model != LifecycleModel && op.Model != ACLModel {
 		log.Errorf("cannot Append, incorrect model %d for DatasetLog", op.Model)
 		return
 	}
@@ -76,7 +76,7 @@ func newBranchLog(l *oplog.Log) *BranchLog {
 
 // Append adds an op to the BranchLog
 func (blog *BranchLog) Append(op oplog.Op) {
-	if op.Model != BranchModel && op.Model != CommitModel && op.Model != PushModel && op.Model != RunModel {
+	if op.Model != BranchModel && op.Model != CommitModel && op.Model != PushModel && op.Model != RunModel && op.Model != GapModel {
 		log.Errorf("cannot Append, incorrect model %d for BranchLog", op.Model)
 		return
 	}
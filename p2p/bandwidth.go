@@ -0,0 +1,351 @@
+package p2p
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	host "github.com/libp2p/go-libp2p-core/host"
+	metrics "github.com/libp2p/go-libp2p-core/metrics"
+	net "github.com/libp2p/go-libp2p-core/network"
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	protocol "github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/qri-io/qri/config"
+)
+
+// BandwidthStats is the amount of data transferred, in bytes
+type BandwidthStats struct {
+	TotalIn  int64 `json:"totalIn"`
+	TotalOut int64 `json:"totalOut"`
+	// RateIn & RateOut are bytes per second, averaged over recent transfers
+	RateIn  float64 `json:"rateIn"`
+	RateOut float64 `json:"rateOut"`
+	// Cap is the configured limit in bytes per second, zero is unlimited
+	Cap int64 `json:"cap,omitempty"`
+}
+
+// PeerBandwidth is the data transferred with a single peer
+type PeerBandwidth struct {
+	PeerID string `json:"peerID"`
+	BandwidthStats
+}
+
+// ProtocolBandwidth is the data transferred over a single protocol
+type ProtocolBandwidth struct {
+	Protocol string `json:"protocol"`
+	BandwidthStats
+}
+
+// BandwidthReport is the data a node has transferred since going online.
+// Peers & Protocols are ordered by total bytes transferred, highest first
+type BandwidthReport struct {
+	Totals    BandwidthStats      `json:"totals"`
+	Peers     []PeerBandwidth     `json:"peers"`
+	Protocols []ProtocolBandwidth `json:"protocols"`
+}
+
+// Bandwidth accounts for data a node transfers & throttles transfers to the
+// caps set in p2p config. libp2p traffic is counted by the host, throttled
+// transfers that don't use libp2p, like remote HTTP handlers, are counted as
+// they're read & written
+type Bandwidth struct {
+	reporter metrics.Reporter
+
+	lk        sync.Mutex
+	peerCap   int64
+	protoCaps map[string]int64
+	peers     map[string]*tokenBucket
+	protos    map[string]*tokenBucket
+}
+
+// newBandwidth creates bandwidth accounting with caps from p2p config
+func newBandwidth(cfg *config.P2P) *Bandwidth {
+	b := &Bandwidth{
+		reporter:  metrics.NewBandwidthCounter(),
+		protoCaps: map[string]int64{},
+		peers:     map[string]*tokenBucket{},
+		protos:    map[string]*tokenBucket{},
+	}
+	if cfg != nil {
+		b.peerCap = cfg.BandwidthPeerCap
+		for proto, limit := range cfg.BandwidthProtocolCaps {
+			b.protoCaps[proto] = limit
+		}
+	}
+	return b
+}
+
+// Reporter returns the metrics reporter libp2p traffic is counted with
+func (b *Bandwidth) Reporter() metrics.Reporter {
+	return b.reporter
+}
+
+// setReporter swaps the metrics reporter for one that's already counting
+// host traffic, as is the case when qri uses the IPFS host
+func (b *Bandwidth) setReporter(r metrics.Reporter) {
+	if r != nil {
+		b.reporter = r
+	}
+}
+
+// Report summarizes data transferred
+func (b *Bandwidth) Report() *BandwidthReport {
+	b.lk.Lock()
+	peerCap := b.peerCap
+	protoCaps := b.protoCaps
+	b.lk.Unlock()
+
+	rep := &BandwidthReport{
+		Totals:    bandwidthStats(b.reporter.GetBandwidthTotals(), 0),
+		Peers:     []PeerBandwidth{},
+		Protocols: []ProtocolBandwidth{},
+	}
+	for pid, s := range b.reporter.GetBandwidthByPeer() {
+		// traffic that didn't come from a libp2p peer is only counted by
+		// protocol
+		if pid == "" {
+			continue
+		}
+		rep.Peers = append(rep.Peers, PeerBandwidth{PeerID: pid.Pretty(), BandwidthStats: bandwidthStats(s, peerCap)})
+	}
+	for proto, s := range b.reporter.GetBandwidthByProtocol() {
+		rep.Protocols = append(rep.Protocols, ProtocolBandwidth{Protocol: string(proto), BandwidthStats: bandwidthStats(s, protoCaps[string(proto)])})
+	}
+
+	sort.Slice(rep.Peers, func(i, j int) bool {
+		return rep.Peers[i].TotalIn+rep.Peers[i].TotalOut > rep.Peers[j].TotalIn+rep.Peers[j].TotalOut
+	})
+	sort.Slice(rep.Protocols, func(i, j int) bool {
+		return rep.Protocols[i].TotalIn+rep.Protocols[i].TotalOut > rep.Protocols[j].TotalIn+rep.Protocols[j].TotalOut
+	})
+	return rep
+}
+
+func bandwidthStats(s metrics.Stats, limit int64) BandwidthStats {
+	return BandwidthStats{
+		TotalIn:  s.TotalIn,
+		TotalOut: s.TotalOut,
+		RateIn:   s.RateIn,
+		RateOut:  s.RateOut,
+		Cap:      limit,
+	}
+}
+
+// reserve takes n bytes from the peer & protocol caps, returning how long the
+// caller must wait before transferring them
+func (b *Bandwidth) reserve(now time.Time, peerKey, proto string, n int) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	var wait time.Duration
+	if b.peerCap > 0 && peerKey != "" {
+		if d := b.bucket(b.peers, peerKey, b.peerCap).reserve(now, n); d > wait {
+			wait = d
+		}
+	}
+	if limit := b.protoCaps[proto]; limit > 0 {
+		if d := b.bucket(b.protos, proto, limit).reserve(now, n); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+func (b *Bandwidth) bucket(buckets map[string]*tokenBucket, key string, limit int64) *tokenBucket {
+	tb, ok := buckets[key]
+	if !ok {
+		tb = newTokenBucket(limit)
+		buckets[key] = tb
+	}
+	return tb
+}
+
+// throttle blocks until n bytes may be transferred
+func (b *Bandwidth) throttle(ctx context.Context, peerKey, proto string, n int) error {
+	d := b.reserve(time.Now(), peerKey, proto, n)
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reader throttles & counts data received over a protocol that isn't
+// carried by libp2p. peerKey identifies the sender, eg: an address
+func (b *Bandwidth) Reader(ctx context.Context, peerKey, proto string, r io.Reader) io.Reader {
+	return &throttledReader{ctx: ctx, bw: b, peerKey: peerKey, proto: proto, r: r}
+}
+
+// Writer throttles & counts data sent over a protocol that isn't carried by
+// libp2p. peerKey identifies the receiver, eg: an address
+func (b *Bandwidth) Writer(ctx context.Context, peerKey, proto string, w io.Writer) io.Writer {
+	return &throttledWriter{ctx: ctx, bw: b, peerKey: peerKey, proto: proto, w: w}
+}
+
+// Host wraps a libp2p host, throttling streams opened by & handled with the
+// returned host. Data transferred is already counted by the host
+func (b *Bandwidth) Host(h host.Host) host.Host {
+	if h == nil {
+		return nil
+	}
+	return &throttledHost{Host: h, bw: b}
+}
+
+// throttleChunk is the most bytes a throttled writer sends at once
+const throttleChunk = 32 * 1024
+
+type throttledReader struct {
+	ctx     context.Context
+	bw      *Bandwidth
+	peerKey string
+	proto   string
+	r       io.Reader
+}
+
+func (tr *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > throttleChunk {
+		p = p[:throttleChunk]
+	}
+	n, err := tr.r.Read(p)
+	if n > 0 {
+		tr.bw.reporter.LogRecvMessage(int64(n))
+		tr.bw.reporter.LogRecvMessageStream(int64(n), protocol.ID(tr.proto), "")
+		if terr := tr.bw.throttle(tr.ctx, tr.peerKey, tr.proto, n); terr != nil && err == nil {
+			err = terr
+		}
+	}
+	return n, err
+}
+
+type throttledWriter struct {
+	ctx     context.Context
+	bw      *Bandwidth
+	peerKey string
+	proto   string
+	w       io.Writer
+}
+
+func (tw *throttledWriter) Write(p []byte) (written int, err error) {
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > throttleChunk {
+			chunk = chunk[:throttleChunk]
+		}
+		if err = tw.bw.throttle(tw.ctx, tw.peerKey, tw.proto, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := tw.w.Write(chunk)
+		written += n
+		tw.bw.reporter.LogSentMessage(int64(n))
+		tw.bw.reporter.LogSentMessageStream(int64(n), protocol.ID(tw.proto), "")
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// throttledHost wraps the streams of a libp2p host
+type throttledHost struct {
+	host.Host
+	bw *Bandwidth
+}
+
+func (h *throttledHost) SetStreamHandler(pid protocol.ID, handler net.StreamHandler) {
+	h.Host.SetStreamHandler(pid, func(s net.Stream) {
+		handler(h.bw.stream(s))
+	})
+}
+
+func (h *throttledHost) SetStreamHandlerMatch(pid protocol.ID, match func(string) bool, handler net.StreamHandler) {
+	h.Host.SetStreamHandlerMatch(pid, match, func(s net.Stream) {
+		handler(h.bw.stream(s))
+	})
+}
+
+func (h *throttledHost) NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (net.Stream, error) {
+	s, err := h.Host.NewStream(ctx, p, pids...)
+	if err != nil {
+		return nil, err
+	}
+	return h.bw.stream(s), nil
+}
+
+func (b *Bandwidth) stream(s net.Stream) net.Stream {
+	return &throttledStream{Stream: s, bw: b}
+}
+
+// throttledStream limits the rate data is read from & written to a stream
+type throttledStream struct {
+	net.Stream
+	bw *Bandwidth
+}
+
+func (s *throttledStream) Read(p []byte) (int, error) {
+	if len(p) > throttleChunk {
+		p = p[:throttleChunk]
+	}
+	n, err := s.Stream.Read(p)
+	if n > 0 {
+		s.bw.throttle(context.Background(), s.Conn().RemotePeer().Pretty(), string(s.Protocol()), n)
+	}
+	return n, err
+}
+
+func (s *throttledStream) Write(p []byte) (written int, err error) {
+	peerKey, proto := s.Conn().RemotePeer().Pretty(), string(s.Protocol())
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > throttleChunk {
+			chunk = chunk[:throttleChunk]
+		}
+		s.bw.throttle(context.Background(), peerKey, proto, len(chunk))
+		n, err := s.Stream.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// tokenBucket allows limit bytes per second, with bursts of up to one
+// second's worth of bytes. Reservations past the available tokens go into
+// debt, making later callers wait longer
+type tokenBucket struct {
+	limit  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit int64) *tokenBucket {
+	return &tokenBucket{limit: float64(limit), tokens: float64(limit)}
+}
+
+func (tb *tokenBucket) reserve(now time.Time, n int) time.Duration {
+	if !tb.last.IsZero() {
+		tb.tokens += now.Sub(tb.last).Seconds() * tb.limit
+		if tb.tokens > tb.limit {
+			tb.tokens = tb.limit
+		}
+	}
+	tb.last = now
+	tb.tokens -= float64(n)
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / tb.limit * float64(time.Second))
+}
@@ -0,0 +1,97 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/config"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Now()
+	tb := newTokenBucket(100)
+
+	if d := tb.reserve(start, 100); d != 0 {
+		t.Errorf("expected a full bucket to allow a one second burst. got wait: %s", d)
+	}
+	if d := tb.reserve(start, 50); d != time.Millisecond*500 {
+		t.Errorf("expected reserving past an empty bucket to wait. want: 500ms got: %s", d)
+	}
+	// a second later the bucket has refilled the debt & another 50 bytes
+	if d := tb.reserve(start.Add(time.Second), 50); d != 0 {
+		t.Errorf("expected refilled bucket to allow reservation. got wait: %s", d)
+	}
+	// buckets don't fill past one second's worth of bytes
+	if d := tb.reserve(start.Add(time.Hour), 200); d != time.Second {
+		t.Errorf("expected burst to be capped. want: 1s got: %s", d)
+	}
+}
+
+func TestBandwidthReserve(t *testing.T) {
+	bw := newBandwidth(&config.P2P{
+		BandwidthPeerCap:      1000,
+		BandwidthProtocolCaps: map[string]int64{"/remote/dsync": 100},
+	})
+	now := time.Now()
+
+	if d := bw.reserve(now, "a", "/other", 1000); d != 0 {
+		t.Errorf("expected uncapped protocol to only use peer cap. got wait: %s", d)
+	}
+	if d := bw.reserve(now, "a", "/other", 500); d != time.Millisecond*500 {
+		t.Errorf("expected peer cap to apply. want: 500ms got: %s", d)
+	}
+	if d := bw.reserve(now, "b", "/remote/dsync", 200); d != time.Second {
+		t.Errorf("expected the stricter protocol cap to apply. want: 1s got: %s", d)
+	}
+	if d := bw.reserve(now, "c", "/remote/dsync", 100); d != time.Second*2 {
+		t.Errorf("expected protocol cap to be shared across peers. want: 2s got: %s", d)
+	}
+
+	uncapped := newBandwidth(nil)
+	if d := uncapped.reserve(now, "a", "/remote/dsync", 1<<30); d != 0 {
+		t.Errorf("expected no caps to never wait. got: %s", d)
+	}
+}
+
+func TestBandwidthReaderWriter(t *testing.T) {
+	ctx := context.Background()
+	bw := newBandwidth(nil)
+
+	data, err := ioutil.ReadAll(bw.Reader(ctx, "client", "/remote/dsync", strings.NewReader("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("read mismatch. got: %q", data)
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := bw.Writer(ctx, "client", "/remote/dsync", buf).Write([]byte("hi there")); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "hi there" {
+		t.Errorf("write mismatch. got: %q", buf.String())
+	}
+
+	// libp2p meters update totals in the background, only check transfers
+	// were attributed
+	rep := bw.Report()
+	if len(rep.Protocols) != 1 || rep.Protocols[0].Protocol != "/remote/dsync" {
+		t.Fatalf("expected transfers to be counted by protocol. got: %#v", rep.Protocols)
+	}
+	if len(rep.Peers) != 0 {
+		t.Errorf("expected HTTP clients not to be listed as peers. got: %#v", rep.Peers)
+	}
+
+	// throttled transfers stop when the context is cancelled
+	capped := newBandwidth(&config.P2P{BandwidthPeerCap: 1})
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := capped.Writer(cctx, "client", "/remote/dsync", buf).Write([]byte("too much data")); err != context.Canceled {
+		t.Errorf("expected cancelled write to error. got: %v", err)
+	}
+}
@@ -31,7 +31,7 @@ func (n *QriNode) Bootstrap(boostrapAddrs []string) {
 		go func(p peer.AddrInfo) {
 			log.Debugf("boostrapping to: %s", p.ID.Pretty())
 			if err := n.host.Connect(context.Background(), p); err != nil {
-				log.Infof("error connecting to host: %s", err.Error())
+				log.Infof("bootstrapping: %s", n.connectError(p.ID, err))
 			}
 		}(p)
 	}
@@ -0,0 +1,375 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	peer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/qri-io/qri/config"
+)
+
+const (
+	// DefaultConnMgrLowWater is the number of connections the connection
+	// manager trims down to when config doesn't specify a value
+	DefaultConnMgrLowWater = 100
+	// DefaultConnMgrHighWater is the number of connections that triggers a
+	// trim when config doesn't specify a value
+	DefaultConnMgrHighWater = 200
+	// connMgrGracePeriod is how long a new connection is exempt from trimming
+	connMgrGracePeriod = time.Second * 30
+	// connMgrTrimInterval is how often the connection manager checks the
+	// number of open connections
+	connMgrTrimInterval = time.Minute
+
+	// qriScoreTag is the ConnManager tag peer scores are stored under
+	qriScoreTag = "qri-score"
+	// qriRemoteKey protects connections to configured remotes
+	qriRemoteKey = "qri-remote"
+
+	// scoreQriPeer is the base score for peers that speak the qri protocol
+	scoreQriPeer = 20
+	// scorePerDatasetShared is added for each dataset a peer has shared
+	scorePerDatasetShared = 5
+	// maxDatasetSharedScore caps the score a peer can earn by sharing datasets
+	maxDatasetSharedScore = 50
+	// maxResponsiveScore is the score earned by a peer that has answered
+	// every request
+	maxResponsiveScore = 30
+)
+
+// PeerScore describes how valuable a connection to a peer is. When the
+// number of open connections passes the high watermark, the connection
+// manager closes connections to the lowest-scoring peers first
+type PeerScore struct {
+	PeerID string `json:"peerID"`
+	Score  int    `json:"score"`
+	// QriPeer is true if the peer speaks the qri protocol
+	QriPeer bool `json:"qriPeer"`
+	// DatasetsShared counts the dataset references this peer has resolved
+	// for us
+	DatasetsShared int `json:"datasetsShared"`
+	// Responses & Failures count requests this peer has & hasn't answered
+	Responses int           `json:"responses"`
+	Failures  int           `json:"failures"`
+	Latency   time.Duration `json:"latency"`
+	// Protected peers are never trimmed
+	Protected bool      `json:"protected"`
+	Connected time.Time `json:"connected"`
+}
+
+// peerStats are the raw observations a score is calculated from
+type peerStats struct {
+	connected      time.Time
+	datasetsShared int
+	responses      int
+	failures       int
+}
+
+// score calculates a peer score
+func (s *peerStats) score(qriPeer bool, latency time.Duration) int {
+	score := 0
+	if qriPeer {
+		score += scoreQriPeer
+	}
+
+	shared := s.datasetsShared * scorePerDatasetShared
+	if shared > maxDatasetSharedScore {
+		shared = maxDatasetSharedScore
+	}
+	score += shared
+
+	if total := s.responses + s.failures; total > 0 {
+		score += maxResponsiveScore * s.responses / total
+	}
+
+	switch {
+	case latency <= 0:
+	case latency < time.Millisecond*100:
+		score += 10
+	case latency < time.Millisecond*500:
+		score += 5
+	}
+	return score
+}
+
+// connManager limits the number of open peer connections, closing
+// connections to the lowest-scoring peers when the number of connections
+// exceeds a high watermark
+type connManager struct {
+	node        *QriNode
+	lowWater    int
+	highWater   int
+	gracePeriod time.Duration
+
+	sync.Mutex
+	stats     map[peer.ID]*peerStats
+	protected map[peer.ID]bool
+}
+
+func newConnManager(node *QriNode, cfg *config.P2P) *connManager {
+	low, high := connMgrWatermarks(cfg)
+	return &connManager{
+		node:        node,
+		lowWater:    low,
+		highWater:   high,
+		gracePeriod: connMgrGracePeriod,
+		stats:       map[peer.ID]*peerStats{},
+		protected:   map[peer.ID]bool{},
+	}
+}
+
+// connMgrWatermarks reads connection limits from config, falling back to
+// defaults for unset values
+func connMgrWatermarks(cfg *config.P2P) (low, high int) {
+	low, high = DefaultConnMgrLowWater, DefaultConnMgrHighWater
+	if cfg != nil {
+		if cfg.ConnMgrLowWater > 0 {
+			low = cfg.ConnMgrLowWater
+		}
+		if cfg.ConnMgrHighWater > 0 {
+			high = cfg.ConnMgrHighWater
+		}
+	}
+	if high < low {
+		high = low
+	}
+	return low, high
+}
+
+// stat fetches stats for a peer, creating them if none exist. callers must
+// hold the lock
+func (cm *connManager) stat(pid peer.ID) *peerStats {
+	s, ok := cm.stats[pid]
+	if !ok {
+		s = &peerStats{connected: time.Now()}
+		cm.stats[pid] = s
+	}
+	return s
+}
+
+func (cm *connManager) connected(pid peer.ID) {
+	if cm == nil {
+		return
+	}
+	cm.Lock()
+	cm.stat(pid).connected = time.Now()
+	cm.Unlock()
+}
+
+func (cm *connManager) disconnected(pid peer.ID) {
+	if cm == nil {
+		return
+	}
+	// keep stats while other connections to the peer remain open
+	if cm.node.host != nil && len(cm.node.host.Network().ConnsToPeer(pid)) > 0 {
+		return
+	}
+	cm.Lock()
+	delete(cm.stats, pid)
+	cm.Unlock()
+}
+
+// recordDatasetShared notes that a peer has shared a dataset with us
+func (cm *connManager) recordDatasetShared(pid peer.ID) {
+	if cm == nil {
+		return
+	}
+	cm.Lock()
+	cm.stat(pid).datasetsShared++
+	cm.Unlock()
+	cm.updateScore(pid)
+}
+
+// recordResponse notes weather a peer answered a request
+func (cm *connManager) recordResponse(pid peer.ID, ok bool) {
+	if cm == nil {
+		return
+	}
+	cm.Lock()
+	if ok {
+		cm.stat(pid).responses++
+	} else {
+		cm.stat(pid).failures++
+	}
+	cm.Unlock()
+	cm.updateScore(pid)
+}
+
+// updateScore writes a peer's score to the host ConnManager, so libp2p
+// trimming respects qri scores as well
+func (cm *connManager) updateScore(pid peer.ID) {
+	if cm.node.host == nil {
+		return
+	}
+	ps := cm.peerScore(pid)
+	cm.node.host.ConnManager().TagPeer(pid, qriScoreTag, ps.Score)
+}
+
+func (cm *connManager) isQriPeer(pid peer.ID) bool {
+	if cm.node.host == nil {
+		return false
+	}
+	protocols, err := cm.node.host.Peerstore().SupportsProtocols(pid, string(depQriProtocolID))
+	return err == nil && len(protocols) > 0
+}
+
+func (cm *connManager) peerScore(pid peer.ID) PeerScore {
+	qriPeer := cm.isQriPeer(pid)
+	var latency time.Duration
+	if cm.node.host != nil {
+		latency = cm.node.host.Peerstore().LatencyEWMA(pid)
+	}
+
+	cm.Lock()
+	defer cm.Unlock()
+	s := cm.stat(pid)
+	return PeerScore{
+		PeerID:         pid.Pretty(),
+		Score:          s.score(qriPeer, latency),
+		QriPeer:        qriPeer,
+		DatasetsShared: s.datasetsShared,
+		Responses:      s.responses,
+		Failures:       s.failures,
+		Latency:        latency,
+		Protected:      cm.protected[pid],
+		Connected:      s.connected,
+	}
+}
+
+// protect exempts a peer from trimming
+func (cm *connManager) protect(pid peer.ID) {
+	cm.Lock()
+	cm.protected[pid] = true
+	cm.Unlock()
+	if cm.node.host != nil {
+		cm.node.host.ConnManager().Protect(pid, qriRemoteKey)
+	}
+}
+
+// applyProtections protects all peers registered before the node had a host
+func (cm *connManager) applyProtections() {
+	if cm.node.host == nil {
+		return
+	}
+	cm.Lock()
+	defer cm.Unlock()
+	for pid := range cm.protected {
+		cm.node.host.ConnManager().Protect(pid, qriRemoteKey)
+	}
+}
+
+// scores lists scores for all connected peers, highest score first
+func (cm *connManager) scores() []PeerScore {
+	if cm.node.host == nil {
+		return []PeerScore{}
+	}
+	pids := cm.node.host.Network().Peers()
+	scores := make([]PeerScore, 0, len(pids))
+	for _, pid := range pids {
+		scores = append(scores, cm.peerScore(pid))
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Score > scores[j].Score
+	})
+	return scores
+}
+
+// trim closes connections to the lowest-scoring peers until the number of
+// connected peers is at the low watermark. Protected peers and peers within
+// the grace period are never trimmed. trim returns the number of peers it
+// disconnected from
+func (cm *connManager) trim() int {
+	if cm.node.host == nil {
+		return 0
+	}
+	scores := cm.scores()
+	if len(scores) <= cm.highWater {
+		return 0
+	}
+
+	log.Debugf("trimming peer connections. connected=%d lowWater=%d highWater=%d", len(scores), cm.lowWater, cm.highWater)
+	excess := len(scores) - cm.lowWater
+	closed := 0
+	for i := len(scores) - 1; i >= 0 && closed < excess; i-- {
+		s := scores[i]
+		if s.Protected || time.Since(s.Connected) < cm.gracePeriod {
+			continue
+		}
+		pid, err := peer.Decode(s.PeerID)
+		if err != nil {
+			continue
+		}
+		if err := cm.node.host.Network().ClosePeer(pid); err != nil {
+			log.Debugf("closing connection to peer %s: %s", pid, err)
+			continue
+		}
+		closed++
+	}
+	return closed
+}
+
+// run trims connections on an interval until the context is cancelled
+func (cm *connManager) run(ctx context.Context) {
+	t := time.NewTicker(connMgrTrimInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			cm.trim()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ProtectPeers exempts peers from connection trimming. addrs must be
+// multiaddrs that include a peer ID, or base58-encoded peer IDs. Other
+// addresses (like the HTTP URL of a remote) are skipped
+func (n *QriNode) ProtectPeers(addrs ...string) {
+	for _, addr := range addrs {
+		pid, err := addrPeerID(addr)
+		if err != nil {
+			log.Debugf("not protecting address %q: %s", addr, err)
+			continue
+		}
+		n.connMgr.protect(pid)
+	}
+}
+
+// PeerScores lists scores for connected peers, highest score first
+func (n *QriNode) PeerScores() []PeerScore {
+	if n == nil || n.connMgr == nil {
+		return []PeerScore{}
+	}
+	return n.connMgr.scores()
+}
+
+// TrimConnections closes connections to the lowest-scoring peers if the
+// number of open connections exceeds the configured high watermark,
+// returning the number of peers disconnected
+func (n *QriNode) TrimConnections() int {
+	if n == nil || n.connMgr == nil {
+		return 0
+	}
+	return n.connMgr.trim()
+}
+
+// addrPeerID gets a peer ID from a multiaddr or base58 peer ID string
+func addrPeerID(addr string) (peer.ID, error) {
+	if maddr, err := ma.NewMultiaddr(addr); err == nil {
+		pinfo, err := peer.AddrInfoFromP2pAddr(maddr)
+		if err != nil {
+			return "", err
+		}
+		return pinfo.ID, nil
+	}
+	pid, err := peer.Decode(addr)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a peer address", addr)
+	}
+	return pid, nil
+}
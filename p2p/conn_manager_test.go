@@ -0,0 +1,88 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/config"
+)
+
+func TestPeerStatsScore(t *testing.T) {
+	cases := []struct {
+		description string
+		stats       peerStats
+		qriPeer     bool
+		latency     time.Duration
+		expect      int
+	}{
+		{"unknown peer", peerStats{}, false, 0, 0},
+		{"qri peer", peerStats{}, true, 0, scoreQriPeer},
+		{"shared datasets", peerStats{datasetsShared: 2}, false, 0, 2 * scorePerDatasetShared},
+		{"shared dataset score is capped", peerStats{datasetsShared: 1000}, false, 0, maxDatasetSharedScore},
+		{"always responsive", peerStats{responses: 4}, false, 0, maxResponsiveScore},
+		{"half responsive", peerStats{responses: 2, failures: 2}, false, 0, maxResponsiveScore / 2},
+		{"low latency", peerStats{}, false, time.Millisecond * 20, 10},
+		{"medium latency", peerStats{}, false, time.Millisecond * 200, 5},
+		{"high latency", peerStats{}, false, time.Second, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			got := c.stats.score(c.qriPeer, c.latency)
+			if got != c.expect {
+				t.Errorf("score mismatch. want: %d got: %d", c.expect, got)
+			}
+		})
+	}
+}
+
+func TestConnMgrWatermarks(t *testing.T) {
+	cases := []struct {
+		cfg       *config.P2P
+		low, high int
+	}{
+		{nil, DefaultConnMgrLowWater, DefaultConnMgrHighWater},
+		{&config.P2P{}, DefaultConnMgrLowWater, DefaultConnMgrHighWater},
+		{&config.P2P{ConnMgrLowWater: 10, ConnMgrHighWater: 20}, 10, 20},
+		{&config.P2P{ConnMgrLowWater: 500}, 500, 500},
+	}
+
+	for i, c := range cases {
+		low, high := connMgrWatermarks(c.cfg)
+		if low != c.low || high != c.high {
+			t.Errorf("case %d: expected watermarks (%d, %d), got (%d, %d)", i, c.low, c.high, low, high)
+		}
+	}
+}
+
+func TestAddrPeerID(t *testing.T) {
+	pid := testkeys.GetKeyData(0).PeerID
+
+	good := []string{
+		pid.Pretty(),
+		"/ip4/127.0.0.1/tcp/4001/p2p/" + pid.Pretty(),
+		"/ip4/127.0.0.1/tcp/4001/ipfs/" + pid.Pretty(),
+	}
+	for _, addr := range good {
+		got, err := addrPeerID(addr)
+		if err != nil {
+			t.Errorf("addr %q unexpected error: %s", addr, err)
+			continue
+		}
+		if got != pid {
+			t.Errorf("addr %q peer ID mismatch. want: %s got: %s", addr, pid, got)
+		}
+	}
+
+	bad := []string{
+		"",
+		"https://registry.qri.cloud",
+		"/ip4/127.0.0.1/tcp/4001",
+	}
+	for _, addr := range bad {
+		if _, err := addrPeerID(addr); err == nil {
+			t.Errorf("addr %q expected error, got none", addr)
+		}
+	}
+}
@@ -61,8 +61,10 @@ func (n *QriNode) RequestDatasetsList(ctx context.Context, pid peer.ID, p Datase
 
 	res, err := ws.receiveMessage()
 	if err != nil {
+		n.connMgr.recordResponse(pid, false)
 		return nil, err
 	}
+	n.connMgr.recordResponse(pid, true)
 
 	ref := []reporef.DatasetRef{}
 	err = json.Unmarshal(res.Body, &ref)
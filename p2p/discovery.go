@@ -2,10 +2,14 @@ package p2p
 
 import (
 	"context"
+	"sort"
+	"sync"
 	"time"
 
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	discovery "github.com/libp2p/go-libp2p/p2p/discovery"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
 )
 
 const (
@@ -13,6 +17,42 @@ const (
 	discoveryInterval    = time.Second * 5
 )
 
+// LocalPeer is a peer discovered on the local network
+type LocalPeer struct {
+	ID      string    `json:"id"`
+	Addrs   []string  `json:"addrs"`
+	FoundAt time.Time `json:"foundAt"`
+	// Profile is the qri profile of the peer, nil if the peer hasn't shared a
+	// profile, or doesn't speak the qri protocol
+	Profile *config.ProfilePod `json:"profile,omitempty"`
+	// Trusted peers may exchange logbook data with this node
+	Trusted bool `json:"trusted"`
+}
+
+// localPeers tracks peers discovered on the local network & which of them are
+// trusted to exchange logbook data
+type localPeers struct {
+	sync.Mutex
+	found   map[peer.ID]LocalPeer
+	trusted map[peer.ID]bool
+}
+
+func newLocalPeers(cfg *config.P2P) *localPeers {
+	lp := &localPeers{
+		found:   map[peer.ID]LocalPeer{},
+		trusted: map[peer.ID]bool{},
+	}
+	for _, idStr := range cfg.TrustedPeers {
+		id, err := peer.Decode(idStr)
+		if err != nil {
+			log.Debugf("invalid trusted peer ID %q: %s", idStr, err)
+			continue
+		}
+		lp.trusted[id] = true
+	}
+	return lp
+}
+
 // setupDiscovery initiates local peer discovery, allocating a discovery service
 // if one doesn't exist, then registering to be notified on peer discovery
 func (n *QriNode) setupDiscovery(ctx context.Context) error {
@@ -29,7 +69,65 @@ func (n *QriNode) setupDiscovery(ctx context.Context) error {
 // support the qri protocol
 func (n *QriNode) HandlePeerFound(pinfo peer.AddrInfo) {
 	log.Debugf("found peer %s", pinfo.ID)
+	addrs := make([]string, len(pinfo.Addrs))
+	for i, a := range pinfo.Addrs {
+		addrs[i] = a.String()
+	}
+
+	n.local.Lock()
+	_, seen := n.local.found[pinfo.ID]
+	n.local.found[pinfo.ID] = LocalPeer{ID: pinfo.ID.Pretty(), Addrs: addrs, FoundAt: time.Now()}
+	n.local.Unlock()
+	if !seen {
+		n.pub.Publish(context.Background(), event.ETP2PLocalPeerFound, pinfo)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), discoveryConnTimeout)
 	defer cancel()
 	n.Host().Connect(ctx, pinfo)
 }
+
+// LocalPeers lists peers discovered on the local network, oldest first
+func (n *QriNode) LocalPeers() []LocalPeer {
+	n.local.Lock()
+	defer n.local.Unlock()
+
+	peers := make([]LocalPeer, 0, len(n.local.found))
+	for id, lp := range n.local.found {
+		if pro, err := n.Repo.Profiles().PeerProfile(id); err == nil {
+			lp.Profile, _ = pro.Encode()
+		}
+		lp.Trusted = n.localTrusted(id)
+		peers = append(peers, lp)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].FoundAt.Before(peers[j].FoundAt) })
+	return peers
+}
+
+// PeerTrusted reports whether a peer may exchange logbook data with this
+// node. Only peers discovered on the local network need to be trusted
+func (n *QriNode) PeerTrusted(id peer.ID) bool {
+	n.local.Lock()
+	defer n.local.Unlock()
+	if _, ok := n.local.found[id]; !ok {
+		return true
+	}
+	return n.localTrusted(id)
+}
+
+// SetPeerTrusted trusts or distrusts a local peer to exchange logbook data.
+// trust isn't persisted, callers should record trusted peers in config
+func (n *QriNode) SetPeerTrusted(id peer.ID, trusted bool) {
+	n.local.Lock()
+	defer n.local.Unlock()
+	if trusted {
+		n.local.trusted[id] = true
+	} else {
+		delete(n.local.trusted, id)
+	}
+}
+
+// localTrusted must be called with the local peers lock held
+func (n *QriNode) localTrusted(id peer.ID) bool {
+	return n.cfg.LocalPeerTrust == config.LocalPeerTrustAuto || n.local.trusted[id]
+}
@@ -0,0 +1,43 @@
+package p2p
+
+import (
+	"testing"
+
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/config"
+)
+
+func TestPeerTrusted(t *testing.T) {
+	local := testkeys.GetKeyData(0).PeerID
+	trusted := testkeys.GetKeyData(1).PeerID
+	remote := testkeys.GetKeyData(2).PeerID
+
+	cfg := &config.P2P{TrustedPeers: []string{trusted.Pretty(), "not-a-peer-id"}}
+	n := &QriNode{cfg: cfg, local: newLocalPeers(cfg)}
+	n.local.found[local] = LocalPeer{ID: local.Pretty()}
+	n.local.found[trusted] = LocalPeer{ID: trusted.Pretty()}
+
+	if !n.PeerTrusted(remote) {
+		t.Error("expected peers not found on the local network to be trusted")
+	}
+	if !n.PeerTrusted(trusted) {
+		t.Error("expected configured local peer to be trusted")
+	}
+	if n.PeerTrusted(local) {
+		t.Error("expected unconfigured local peer not to be trusted")
+	}
+
+	n.SetPeerTrusted(local, true)
+	if !n.PeerTrusted(local) {
+		t.Error("expected local peer to be trusted after trusting")
+	}
+	n.SetPeerTrusted(local, false)
+	if n.PeerTrusted(local) {
+		t.Error("expected local peer not to be trusted after distrusting")
+	}
+
+	cfg.LocalPeerTrust = config.LocalPeerTrustAuto
+	if !n.PeerTrusted(local) {
+		t.Error("expected auto trust policy to trust every local peer")
+	}
+}
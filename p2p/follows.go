@@ -0,0 +1,115 @@
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/helpers"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	protocol "github.com/libp2p/go-libp2p-core/protocol"
+)
+
+const (
+	// FollowsProtocolID is the protocol on which qri nodes exchange the lists
+	// of datasets they follow
+	FollowsProtocolID = protocol.ID("/qri/follows/0.1.0")
+	// followsTimeout is the length of time we will wait for a follows exchange
+	followsTimeout = time.Second * 20
+)
+
+// FollowsFunc lists the aliases of datasets a node follows, like
+// "peer/dataset"
+type FollowsFunc func(ctx context.Context) ([]string, error)
+
+// SetFollowsFunc sets the function a node uses to list followed datasets
+// when a peer asks. Nodes without a follows func answer with an empty list
+func (n *QriNode) SetFollowsFunc(fn FollowsFunc) {
+	n.follows = fn
+}
+
+// localFollows lists datasets this node follows, logging instead of failing
+// if follows can't be listed
+func (n *QriNode) localFollows(ctx context.Context) []string {
+	if n.follows == nil {
+		return []string{}
+	}
+	follows, err := n.follows(ctx)
+	if err != nil {
+		log.Debugf("p2p.follows - error listing follows: %s", err)
+		return []string{}
+	}
+	return follows
+}
+
+// ExchangeFollows sends the list of datasets this node follows to a peer,
+// returning the list of datasets the peer follows
+func (n *QriNode) ExchangeFollows(ctx context.Context, pid peer.ID) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, followsTimeout)
+	defer cancel()
+
+	s, err := n.host.NewStream(ctx, pid, FollowsProtocolID)
+	if err != nil {
+		return nil, fmt.Errorf("opening follows stream to peer %q: %w", pid, err)
+	}
+	// close the stream from this end and wait until the other end has closed
+	defer func() { go helpers.FullClose(s) }()
+
+	if err := sendFollows(s, n.localFollows(ctx)); err != nil {
+		return nil, err
+	}
+	follows, err := receiveFollows(s)
+	if err != nil {
+		n.connMgr.recordResponse(pid, false)
+		return nil, err
+	}
+	n.connMgr.recordResponse(pid, true)
+	return follows, nil
+}
+
+// followsHandler answers follows exchange requests with the list of datasets
+// this node follows
+func (n *QriNode) followsHandler(s network.Stream) {
+	defer helpers.FullClose(s)
+	ctx, cancel := context.WithTimeout(context.Background(), followsTimeout)
+	defer cancel()
+
+	p := s.Conn().RemotePeer()
+	theirs, err := receiveFollows(s)
+	if err != nil {
+		log.Debugf("p2p.followsHandler - error reading follows from %q: %s", p, err)
+		return
+	}
+	log.Debugf("p2p.followsHandler - peer %q follows %d datasets", p, len(theirs))
+
+	follows := []string{}
+	if n.PeerTrusted(p) {
+		follows = n.localFollows(ctx)
+	} else {
+		log.Debugf("p2p.followsHandler - peer %q isn't trusted, sending no follows", p)
+	}
+	if err := sendFollows(s, follows); err != nil {
+		log.Debugf("p2p.followsHandler - error sending follows to %q: %s", p, err)
+	}
+}
+
+func sendFollows(s network.Stream, follows []string) error {
+	ws := WrapStream(s)
+	if err := ws.enc.Encode(follows); err != nil {
+		return fmt.Errorf("error encoding follows to wrapped stream: %s", err)
+	}
+	if err := ws.w.Flush(); err != nil {
+		return fmt.Errorf("error flushing stream: %s", err)
+	}
+	return nil
+}
+
+func receiveFollows(s network.Stream) ([]string, error) {
+	ws := WrapStream(s)
+	follows := []string{}
+	if err := ws.dec.Decode(&follows); err != nil {
+		return nil, fmt.Errorf("error decoding follows from wrapped stream: %s", err)
+	}
+	return follows, nil
+}
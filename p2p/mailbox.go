@@ -0,0 +1,394 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/helpers"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	protocol "github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+)
+
+const (
+	// MailboxProtocolID is the protocol on which qri nodes deliver letters, &
+	// leave letters with a relay for peers that are offline
+	MailboxProtocolID = protocol.ID("/qri/mailbox/0.1.0")
+	// mailboxTimeout is the length of time we will wait for a mailbox exchange
+	mailboxTimeout = time.Second * 20
+	// defaultMailboxMaxMessages is the number of letters queued for each peer
+	// when config doesn't set a limit
+	defaultMailboxMaxMessages = 100
+	// defaultMailboxMaxMessageSize is the size of a letter body in bytes when
+	// config doesn't set a limit
+	defaultMailboxMaxMessageSize = 64 << 10
+
+	mailboxOpDeliver = "deliver"
+	mailboxOpStore   = "store"
+)
+
+var (
+	// ErrMailboxFull is returned when a peer already has the maximum number of
+	// letters queued
+	ErrMailboxFull = fmt.Errorf("mailbox is full")
+	// ErrLetterTooLarge is returned when a letter body exceeds the configured
+	// maximum message size
+	ErrLetterTooLarge = fmt.Errorf("letter is too large")
+)
+
+// Letter is a message for a peer. Letters to unreachable peers are queued,
+// and delivered when the peer connects, either to this node or to the
+// configured mailbox relay
+type Letter struct {
+	ID   string  `json:"id"`
+	Type string  `json:"type"`
+	From peer.ID `json:"from"`
+	To   peer.ID `json:"to"`
+	// Body is the JSON-encoded content of the letter
+	Body    json.RawMessage `json:"body"`
+	Created time.Time       `json:"created"`
+	// Expires is when an undelivered letter is dropped
+	Expires time.Time `json:"expires"`
+}
+
+// Expired reports whether a letter can no longer be delivered
+func (l Letter) Expired(now time.Time) bool {
+	return !now.Before(l.Expires)
+}
+
+type mailboxRequest struct {
+	Op      string   `json:"op"`
+	Letters []Letter `json:"letters"`
+}
+
+type mailboxResponse struct {
+	// Accepted is the number of letters the peer accepted, in order
+	Accepted int    `json:"accepted"`
+	Error    string `json:"error,omitempty"`
+}
+
+// mailbox queues letters for peers that can't be reached
+type mailbox struct {
+	sync.Mutex
+	ttl         time.Duration
+	maxMessages int
+	maxSize     int
+	// relay is the peer that holds letters while peers are offline, empty if
+	// no relay is configured
+	relay  peer.ID
+	queues map[peer.ID][]Letter
+}
+
+func newMailbox(cfg *config.P2P) *mailbox {
+	mb := &mailbox{
+		ttl:         config.DefaultMailboxTTL,
+		maxMessages: defaultMailboxMaxMessages,
+		maxSize:     defaultMailboxMaxMessageSize,
+		queues:      map[peer.ID][]Letter{},
+	}
+	if ttl, err := cfg.MailboxTTLDuration(); err == nil {
+		mb.ttl = ttl
+	} else {
+		log.Debugf("mailbox: %s, using default ttl", err)
+	}
+	if cfg.MailboxMaxMessages > 0 {
+		mb.maxMessages = cfg.MailboxMaxMessages
+	}
+	if cfg.MailboxMaxMessageSize > 0 {
+		mb.maxSize = cfg.MailboxMaxMessageSize
+	}
+	if cfg.MailboxRelay != "" {
+		addrs, err := ParseMultiaddrs([]string{cfg.MailboxRelay})
+		if pinfos := toPeerInfos(addrs); err == nil && len(pinfos) == 1 {
+			mb.relay = pinfos[0].ID
+		} else {
+			log.Debugf("mailbox: invalid relay address %q", cfg.MailboxRelay)
+		}
+	}
+	return mb
+}
+
+// letter creates a letter, checking the body against the size limit
+func (mb *mailbox) letter(from, to peer.ID, typ string, body interface{}) (Letter, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return Letter{}, fmt.Errorf("encoding letter body: %w", err)
+	}
+	if len(data) > mb.maxSize {
+		return Letter{}, fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrLetterTooLarge, len(data), mb.maxSize)
+	}
+	now := time.Now()
+	return Letter{
+		ID:      fmt.Sprintf("%s.%d", from.Pretty(), now.UnixNano()),
+		Type:    typ,
+		From:    from,
+		To:      to,
+		Body:    data,
+		Created: now,
+		Expires: now.Add(mb.ttl),
+	}, nil
+}
+
+// put queues a letter. Letters that would outlive this mailbox's TTL expire
+// early
+func (mb *mailbox) put(l Letter) error {
+	mb.Lock()
+	defer mb.Unlock()
+	now := time.Now()
+	if l.Expired(now) {
+		return nil
+	}
+	if len(l.Body) > mb.maxSize {
+		return fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrLetterTooLarge, len(l.Body), mb.maxSize)
+	}
+	if max := now.Add(mb.ttl); l.Expires.After(max) {
+		l.Expires = max
+	}
+	queue := unexpired(mb.queues[l.To], now)
+	if len(queue) >= mb.maxMessages {
+		mb.queues[l.To] = queue
+		return fmt.Errorf("%w: %d letters queued for peer %q", ErrMailboxFull, len(queue), l.To)
+	}
+	mb.queues[l.To] = append(queue, l)
+	return nil
+}
+
+// take removes & returns unexpired letters queued for a peer
+func (mb *mailbox) take(to peer.ID) []Letter {
+	mb.Lock()
+	defer mb.Unlock()
+	queue := unexpired(mb.queues[to], time.Now())
+	delete(mb.queues, to)
+	return queue
+}
+
+// takeAll removes & returns every unexpired letter except letters for a peer
+func (mb *mailbox) takeAll(except peer.ID) []Letter {
+	mb.Lock()
+	defer mb.Unlock()
+	now := time.Now()
+	res := []Letter{}
+	for to, queue := range mb.queues {
+		if to == except {
+			continue
+		}
+		res = append(res, unexpired(queue, now)...)
+		delete(mb.queues, to)
+	}
+	return res
+}
+
+// putBack requeues letters that couldn't be delivered, dropping letters that
+// no longer fit
+func (mb *mailbox) putBack(letters []Letter) {
+	for _, l := range letters {
+		if err := mb.put(l); err != nil {
+			log.Debugf("mailbox: dropping letter %q to %q: %s", l.ID, l.To, err)
+		}
+	}
+}
+
+// list returns queued letters, oldest first
+func (mb *mailbox) list() []Letter {
+	mb.Lock()
+	defer mb.Unlock()
+	now := time.Now()
+	res := []Letter{}
+	for _, queue := range mb.queues {
+		res = append(res, unexpired(queue, now)...)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Created.Before(res[j].Created) })
+	return res
+}
+
+func unexpired(letters []Letter, now time.Time) []Letter {
+	res := make([]Letter, 0, len(letters))
+	for _, l := range letters {
+		if !l.Expired(now) {
+			res = append(res, l)
+		}
+	}
+	return res
+}
+
+// SendLetter sends a letter to a peer. Letters to peers that can't be reached
+// are left with the mailbox relay if one is configured, and queued on this
+// node otherwise. queued is true when the letter wasn't delivered right away.
+// Delivered letters are published to the recipient's event bus as
+// event.ETP2PLetterReceived
+func (n *QriNode) SendLetter(ctx context.Context, to peer.ID, typ string, body interface{}) (queued bool, err error) {
+	l, err := n.mailbox.letter(n.ID, to, typ, body)
+	if err != nil {
+		return false, err
+	}
+	letters := []Letter{l}
+
+	if n.Online {
+		if _, err := n.sendLetters(ctx, to, mailboxOpDeliver, letters); err == nil {
+			return false, nil
+		}
+		log.Debugf("p2p.SendLetter - peer %q is unreachable, queuing letter %q", to, l.ID)
+		if relay := n.mailbox.relay; relay != "" && relay != to {
+			if _, err := n.sendLetters(ctx, relay, mailboxOpStore, letters); err == nil {
+				return true, nil
+			}
+			log.Debugf("p2p.SendLetter - mailbox relay %q is unreachable", relay)
+		}
+	}
+	return true, n.mailbox.put(l)
+}
+
+// Outbox lists letters queued on this node for peers that couldn't be
+// reached, oldest first
+func (n *QriNode) Outbox() []Letter {
+	return n.mailbox.list()
+}
+
+// flushMailbox delivers letters queued for a newly connected peer. When the
+// peer is the mailbox relay, letters for every other peer are left with the
+// relay
+func (n *QriNode) flushMailbox(pid peer.ID) {
+	if n.mailbox == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), mailboxTimeout)
+	defer cancel()
+
+	if letters := n.mailbox.take(pid); len(letters) > 0 {
+		accepted, err := n.sendLetters(ctx, pid, mailboxOpDeliver, letters)
+		if err != nil {
+			log.Debugf("p2p.flushMailbox - delivering to %q: %s", pid, err)
+			n.mailbox.putBack(letters[accepted:])
+		}
+	}
+	if pid == n.mailbox.relay {
+		if letters := n.mailbox.takeAll(pid); len(letters) > 0 {
+			accepted, err := n.sendLetters(ctx, pid, mailboxOpStore, letters)
+			if err != nil {
+				log.Debugf("p2p.flushMailbox - leaving letters with relay %q: %s", pid, err)
+				n.mailbox.putBack(letters[accepted:])
+			}
+		}
+	}
+}
+
+// sendLetters opens a mailbox stream to a peer, returning the number of
+// letters the peer accepted
+func (n *QriNode) sendLetters(ctx context.Context, pid peer.ID, op string, letters []Letter) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, mailboxTimeout)
+	defer cancel()
+
+	s, err := n.host.NewStream(ctx, pid, MailboxProtocolID)
+	if err != nil {
+		return 0, fmt.Errorf("opening mailbox stream to peer %q: %w", pid, err)
+	}
+	// close the stream from this end and wait until the other end has closed
+	defer func() { go helpers.FullClose(s) }()
+
+	ws := WrapStream(s)
+	if err := ws.enc.Encode(mailboxRequest{Op: op, Letters: letters}); err != nil {
+		return 0, fmt.Errorf("error encoding letters to wrapped stream: %s", err)
+	}
+	if err := ws.w.Flush(); err != nil {
+		return 0, fmt.Errorf("error flushing stream: %s", err)
+	}
+	res := mailboxResponse{}
+	if err := ws.dec.Decode(&res); err != nil {
+		n.connMgr.recordResponse(pid, false)
+		return 0, fmt.Errorf("error decoding mailbox response from wrapped stream: %s", err)
+	}
+	n.connMgr.recordResponse(pid, true)
+	if res.Error != "" {
+		return res.Accepted, fmt.Errorf("peer %q: %s", pid, res.Error)
+	}
+	return res.Accepted, nil
+}
+
+// mailboxHandler accepts letters for this node, and holds letters for other
+// peers when this node hosts a mailbox
+func (n *QriNode) mailboxHandler(s network.Stream) {
+	defer helpers.FullClose(s)
+	ctx, cancel := context.WithTimeout(context.Background(), mailboxTimeout)
+	defer cancel()
+
+	p := s.Conn().RemotePeer()
+	ws := WrapStream(s)
+	req := mailboxRequest{}
+	if err := ws.dec.Decode(&req); err != nil {
+		log.Debugf("p2p.mailboxHandler - error reading letters from %q: %s", p, err)
+		return
+	}
+
+	res := mailboxResponse{}
+	switch req.Op {
+	case mailboxOpDeliver:
+		res.Accepted = n.receiveLetters(ctx, p, req.Letters)
+	case mailboxOpStore:
+		res.Accepted, res.Error = n.holdLetters(ctx, p, req.Letters)
+	default:
+		res.Error = fmt.Sprintf("unknown mailbox operation %q", req.Op)
+	}
+
+	if err := ws.enc.Encode(res); err != nil {
+		log.Debugf("p2p.mailboxHandler - error encoding response to %q: %s", p, err)
+		return
+	}
+	if err := ws.w.Flush(); err != nil {
+		log.Debugf("p2p.mailboxHandler - error flushing stream to %q: %s", p, err)
+	}
+}
+
+// receiveLetters publishes letters delivered to this node. Letters are only
+// accepted from their sender, or from the configured mailbox relay. Letters
+// that aren't accepted are counted as delivered so senders don't retry them
+func (n *QriNode) receiveLetters(ctx context.Context, from peer.ID, letters []Letter) int {
+	now := time.Now()
+	for _, l := range letters {
+		if l.To != n.ID || l.Expired(now) {
+			continue
+		}
+		if l.From != from && from != n.mailbox.relay {
+			log.Debugf("p2p.mailboxHandler - dropping letter %q from %q delivered by %q", l.ID, l.From, from)
+			continue
+		}
+		n.pub.Publish(ctx, event.ETP2PLetterReceived, l)
+	}
+	return len(letters)
+}
+
+// holdLetters keeps letters for peers until they connect, stopping at the
+// first letter that can't be held
+func (n *QriNode) holdLetters(ctx context.Context, from peer.ID, letters []Letter) (int, string) {
+	if !n.cfg.MailboxHost {
+		return 0, "peer doesn't host a mailbox"
+	}
+	recipients := map[peer.ID]bool{}
+	accepted := 0
+	for _, l := range letters {
+		if l.From != from {
+			return accepted, fmt.Sprintf("letter %q isn't from the sending peer", l.ID)
+		}
+		if l.To == n.ID {
+			n.receiveLetters(ctx, from, []Letter{l})
+		} else if err := n.mailbox.put(l); err != nil {
+			return accepted, err.Error()
+		} else {
+			recipients[l.To] = true
+		}
+		accepted++
+	}
+
+	// recipients that are already connected get their letters right away
+	for pid := range recipients {
+		if n.host.Network().Connectedness(pid) == network.Connected {
+			go n.flushMailbox(pid)
+		}
+	}
+	return accepted, ""
+}
@@ -0,0 +1,120 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+)
+
+func TestMailboxQueue(t *testing.T) {
+	from := testkeys.GetKeyData(0).PeerID
+	to := testkeys.GetKeyData(1).PeerID
+	other := testkeys.GetKeyData(2).PeerID
+
+	mb := newMailbox(&config.P2P{MailboxMaxMessages: 2, MailboxMaxMessageSize: 16, MailboxTTL: "1h"})
+
+	if _, err := mb.letter(from, to, "invite", strings.Repeat("a", 32)); !errors.Is(err, ErrLetterTooLarge) {
+		t.Errorf("expected too large error, got: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		l, err := mb.letter(from, to, "invite", "hi")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mb.put(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l, _ := mb.letter(from, to, "invite", "hi")
+	if err := mb.put(l); !errors.Is(err, ErrMailboxFull) {
+		t.Errorf("expected full mailbox error, got: %v", err)
+	}
+
+	// letters that outlive the mailbox ttl expire early
+	l, _ = mb.letter(from, other, "invite", "hi")
+	l.Expires = time.Now().Add(time.Hour * 48)
+	if err := mb.put(l); err != nil {
+		t.Fatal(err)
+	}
+	if queued := mb.list(); len(queued) != 3 {
+		t.Errorf("expected 3 queued letters, got %d", len(queued))
+	} else if queued[2].Expires.After(time.Now().Add(time.Hour)) {
+		t.Errorf("expected letter expiry to be capped to the mailbox ttl")
+	}
+
+	// expired letters are dropped
+	l, _ = mb.letter(from, other, "invite", "hi")
+	l.Expires = time.Now().Add(-time.Second)
+	if err := mb.put(l); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := mb.take(to); len(got) != 2 {
+		t.Errorf("expected to take 2 letters, got %d", len(got))
+	}
+	if got := mb.take(to); len(got) != 0 {
+		t.Errorf("expected taking letters to empty the queue, got %d", len(got))
+	}
+	if got := mb.takeAll(""); len(got) != 1 {
+		t.Errorf("expected 1 remaining unexpired letter, got %d", len(got))
+	}
+}
+
+func TestMailboxRelay(t *testing.T) {
+	relay := testkeys.GetKeyData(3).PeerID
+	mb := newMailbox(&config.P2P{MailboxRelay: "/ip4/127.0.0.1/tcp/4001/ipfs/" + relay.Pretty()})
+	if mb.relay != relay {
+		t.Errorf("expected relay %q, got %q", relay, mb.relay)
+	}
+	mb = newMailbox(&config.P2P{MailboxRelay: "/ip4/127.0.0.1/tcp/4001"})
+	if mb.relay != "" {
+		t.Errorf("expected address without a peer ID to set no relay, got %q", mb.relay)
+	}
+}
+
+func TestReceiveLetters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	self := testkeys.GetKeyData(0).PeerID
+	sender := testkeys.GetKeyData(1).PeerID
+	relay := testkeys.GetKeyData(2).PeerID
+	stranger := testkeys.GetKeyData(3).PeerID
+
+	bus := event.NewBus(ctx)
+	received := []Letter{}
+	bus.SubscribeTypes(func(_ context.Context, e event.Event) error {
+		received = append(received, e.Payload.(Letter))
+		return nil
+	}, event.ETP2PLetterReceived)
+
+	cfg := &config.P2P{MailboxRelay: "/ip4/127.0.0.1/tcp/4001/ipfs/" + relay.Pretty()}
+	n := &QriNode{ID: self, cfg: cfg, pub: bus, mailbox: newMailbox(cfg)}
+
+	direct, _ := n.mailbox.letter(sender, self, "invite", "direct")
+	relayed, _ := n.mailbox.letter(sender, self, "invite", "relayed")
+	misaddressed, _ := n.mailbox.letter(sender, stranger, "invite", "misaddressed")
+	forged, _ := n.mailbox.letter(sender, self, "invite", "forged")
+
+	n.receiveLetters(ctx, sender, []Letter{direct, misaddressed})
+	n.receiveLetters(ctx, relay, []Letter{relayed})
+	n.receiveLetters(ctx, stranger, []Letter{forged})
+
+	if len(received) != 2 {
+		t.Fatalf("expected 2 received letters, got %d", len(received))
+	}
+	if string(received[0].Body) != `"direct"` || string(received[1].Body) != `"relayed"` {
+		t.Errorf("expected direct & relayed letters to be received, got: %v", received)
+	}
+
+	if _, msg := n.holdLetters(ctx, sender, []Letter{misaddressed}); msg == "" {
+		t.Error("expected node that doesn't host a mailbox to refuse letters")
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"sync"
-	"time"
 
 	core "github.com/ipfs/go-ipfs/core"
 	namesys "github.com/ipfs/go-ipfs/namesys"
@@ -15,6 +14,7 @@ import (
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	libp2pevent "github.com/libp2p/go-libp2p-core/event"
 	host "github.com/libp2p/go-libp2p-core/host"
+	metrics "github.com/libp2p/go-libp2p-core/metrics"
 	net "github.com/libp2p/go-libp2p-core/network"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	peerstore "github.com/libp2p/go-libp2p-peerstore"
@@ -46,6 +46,8 @@ type QriNode struct {
 	host host.Host
 	// Discovery service, can be provided by an ipfs node
 	Discovery discovery.Service
+	// local tracks peers found by discovery & trust for logbook exchange
+	local *localPeers
 
 	// Repo is a repository of this node's qri data
 	// note that repo's are built upon a qfs.MuxFS, which
@@ -62,6 +64,8 @@ type QriNode struct {
 
 	// localResolver allows the node to resolve local dataset references
 	localResolver dsref.Resolver
+	// follows lists datasets this node follows for peers that ask
+	follows FollowsFunc
 
 	// msgState keeps a "scratch pad" of message IDS & timeouts
 	msgState *sync.Map
@@ -71,6 +75,13 @@ type QriNode struct {
 	// receiversMu is the lock for the receivers list
 	receiversMu sync.Mutex
 
+	// connMgr limits open connections, trimming the lowest-scoring peers
+	connMgr *connManager
+	// bandwidth accounts for & throttles data transfers
+	bandwidth *Bandwidth
+	// mailbox queues letters for peers that can't be reached
+	mailbox *mailbox
+
 	// pub is the event publisher on which to publish p2p events
 	pub     event.Publisher
 	notifee *net.NotifyBundle
@@ -113,6 +124,7 @@ func NewQriNode(r repo.Repo, p2pconf *config.P2P, pub event.Publisher, localReso
 		pub:           pub,
 		receiversMu:   sync.Mutex{},
 		localResolver: localResolver,
+		local:         newLocalPeers(p2pconf),
 		// Make sure we always have proper IOStreams, this can be set later
 		LocalStreams: ioes.NewDiscardIOStreams(),
 	}
@@ -123,6 +135,9 @@ func NewQriNode(r repo.Repo, p2pconf *config.P2P, pub event.Publisher, localReso
 	}
 
 	node.qis = NewQriProfileService(node.Repo.Profiles(), node.pub)
+	node.connMgr = newConnManager(node, p2pconf)
+	node.bandwidth = newBandwidth(p2pconf)
+	node.mailbox = newMailbox(p2pconf)
 	return node, nil
 }
 
@@ -131,6 +146,20 @@ func (n *QriNode) Host() host.Host {
 	return n.host
 }
 
+// ThrottledHost returns the node's Host, limiting streams to the bandwidth
+// caps set in config. Use the throttled host for transferring dataset data
+func (n *QriNode) ThrottledHost() host.Host {
+	if n.bandwidth == nil {
+		return n.host
+	}
+	return n.bandwidth.Host(n.host)
+}
+
+// Bandwidth returns the node's bandwidth accounting
+func (n *QriNode) Bandwidth() *Bandwidth {
+	return n.bandwidth
+}
+
 // GoOnline puts QriNode on the distributed web, ensuring there's an active peer-2-peer host
 // participating in a peer-2-peer network, and kicks off requests to connect to known bootstrap
 // peers that support the QriProtocol
@@ -161,9 +190,19 @@ func (n *QriNode) GoOnline(c context.Context) (err error) {
 		}
 
 		ipfsnode := ipfsfs.Node()
+		if n.cfg.PrivateNetwork() && len(ipfsnode.PNetFingerprint) == 0 {
+			// IPFS reads the pre-shared key from swarm.key in the IPFS repo. refuse
+			// to go online on the public network if that key is missing
+			cancel()
+			return fmt.Errorf("p2p swarm key is configured, but the IPFS node isn't running on a private network. check that swarm.key exists in the IPFS repo")
+		}
 		if ipfsnode.PeerHost != nil {
 			n.host = ipfsnode.PeerHost
 		}
+		// the IPFS host counts traffic with its own reporter
+		if ipfsnode.Reporter != nil {
+			n.bandwidth.setReporter(ipfsnode.Reporter)
+		}
 
 		if ipfsnode.Discovery != nil {
 			n.Discovery = ipfsnode.Discovery
@@ -171,7 +210,7 @@ func (n *QriNode) GoOnline(c context.Context) (err error) {
 	} else if n.host == nil {
 		log.Debugf("creating p2p Host")
 		ps := pstoremem.NewPeerstore()
-		n.host, err = makeBasicHost(ctx, ps, n.cfg)
+		n.host, err = makeBasicHost(ctx, ps, n.cfg, n.bandwidth.Reporter())
 		if err != nil {
 			cancel()
 			return fmt.Errorf("error creating host: %s", err.Error())
@@ -186,6 +225,8 @@ func (n *QriNode) GoOnline(c context.Context) (err error) {
 	}
 
 	n.qis.Start(n.host)
+	n.connMgr.applyProtections()
+	go n.connMgr.run(ctx)
 
 	// add multistream handler for qri protocol to the host
 	// setting a stream handler for the QriPrtocolID indicates to peers on
@@ -201,6 +242,10 @@ func (n *QriNode) GoOnline(c context.Context) (err error) {
 
 	// add ref resolution capabilities:
 	n.host.SetStreamHandler(ResolveRefProtocolID, n.resolveRefHandler)
+	// add follow list exchange
+	n.host.SetStreamHandler(FollowsProtocolID, n.followsHandler)
+	// add letter delivery for peers that are offline
+	n.host.SetStreamHandler(MailboxProtocolID, n.mailboxHandler)
 
 	// register ourselves as a notifee on connected
 	n.host.Network().Notify(n.notifee)
@@ -232,6 +277,19 @@ func (n *QriNode) startOnlineServices(ctx context.Context) error {
 	}
 	log.Debugf("starting online services")
 
+	// stay connected to the mailbox relay to collect letters left while this
+	// node was offline
+	if n.cfg.MailboxRelay != "" {
+		go n.Bootstrap([]string{n.cfg.MailboxRelay})
+	}
+
+	// nodes on a private network can only reach peers that share a swarm key,
+	// restrict bootstrapping to explicitly configured peers
+	if n.cfg.PrivateNetwork() {
+		go n.Bootstrap(n.cfg.BootstrapAddrs)
+		return nil
+	}
+
 	// Boostrap off of default addresses
 	go n.Bootstrap(n.cfg.QriBootstrapAddrs)
 	// Bootstrap to IPFS network if this node is using an IPFS fs
@@ -318,7 +376,7 @@ func (n *QriNode) EncapsulatedAddresses() []ma.Multiaddr {
 }
 
 // makeBasicHost creates a LibP2P host from a NodeCfg
-func makeBasicHost(ctx context.Context, ps peerstore.Peerstore, p2pconf *config.P2P) (host.Host, error) {
+func makeBasicHost(ctx context.Context, ps peerstore.Peerstore, p2pconf *config.P2P, reporter metrics.Reporter) (host.Host, error) {
 	pk, err := p2pconf.DecodePrivateKey()
 	if err != nil {
 		return nil, err
@@ -337,6 +395,15 @@ func makeBasicHost(ctx context.Context, ps peerstore.Peerstore, p2pconf *config.
 		libp2p.Identity(pk),
 		libp2p.Peerstore(ps),
 		libp2p.EnableRelay(circuit.OptHop),
+		libp2p.BandwidthReporter(reporter),
+	}
+
+	psk, err := p2pconf.DecodeSwarmKey()
+	if err != nil {
+		return nil, err
+	}
+	if psk != nil {
+		opts = append(opts, libp2p.PrivateNetwork(psk))
 	}
 
 	// Let's talk about these options a bit. Most of the time, we will never
@@ -346,17 +413,31 @@ func makeBasicHost(ctx context.Context, ps peerstore.Peerstore, p2pconf *config.
 	// create our own host. If we do not explicitly pass the host the options
 	// for a ConnManager, it will use the NullConnManager, which doesn't actually
 	// tag or manage any conns.
-	// So instead, we pass in the libp2p basic ConnManager:
-	opts = append(opts, libp2p.ConnectionManager(connmgr.NewConnManager(1000, 0, time.Millisecond)))
+	// So instead, we pass in the libp2p basic ConnManager, using the same
+	// watermarks as the qri connection manager:
+	low, high := connMgrWatermarks(p2pconf)
+	opts = append(opts, libp2p.ConnectionManager(connmgr.NewConnManager(low, high, connMgrGracePeriod)))
 
 	return libp2p.New(ctx, opts...)
 }
 
+// connectError annotates a failure to connect to a peer. On a private network
+// a failed connection most likely means swarm keys don't match, which libp2p
+// only reports as a failed handshake
+func (n *QriNode) connectError(pid peer.ID, err error) error {
+	if n.cfg.PrivateNetwork() {
+		return fmt.Errorf("host connect %s failure: %w: %s", pid.Pretty(), ErrPrivateNetworkConnect, err)
+	}
+	return fmt.Errorf("host connect %s failure: %s", pid.Pretty(), err)
+}
+
 // connected is called when a connection opened via the network notifee bundle
 func (n *QriNode) connected(_ net.Network, conn net.Conn) {
 	log.Debugf("connected to peer: %s", conn.RemotePeer())
+	n.connMgr.connected(conn.RemotePeer())
 	pi := n.Host().Peerstore().PeerInfo(conn.RemotePeer())
 	n.pub.Publish(context.Background(), event.ETP2PPeerConnected, pi)
+	go n.flushMailbox(conn.RemotePeer())
 }
 
 func (n *QriNode) disconnected(_ net.Network, conn net.Conn) {
@@ -364,6 +445,7 @@ func (n *QriNode) disconnected(_ net.Network, conn net.Conn) {
 	n.pub.Publish(context.Background(), event.ETP2PPeerDisconnected, pi)
 
 	n.qis.HandleQriPeerDisconnect(pi.ID)
+	n.connMgr.disconnected(pi.ID)
 }
 
 func (n *QriNode) libp2pSubscribe(ctx context.Context) error {
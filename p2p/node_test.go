@@ -2,7 +2,9 @@ package p2p
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -167,3 +169,47 @@ func TestNodeEvents(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestPrivateNetwork(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	swarmKey := func(hex string) string {
+		return fmt.Sprintf("/key/swarm/psk/1.0.0/\n/base16/\n%s", strings.Repeat(hex, 32))
+	}
+	newNode := func(i int, key string) *QriNode {
+		kd := testkeys.GetKeyData(i)
+		r, err := test.NewTestRepoFromProfileID(profile.IDFromPeerID(kd.PeerID), 0, -1)
+		if err != nil {
+			t.Fatalf("error creating test repo: %s", err)
+		}
+		p2pconf := testcfg.DefaultP2PForTesting()
+		p2pconf.PeerID = kd.EncodedPeerID
+		p2pconf.PrivKey = kd.EncodedPrivKey
+		p2pconf.Addrs = []ma.Multiaddr{ma.StringCast("/ip4/127.0.0.1/tcp/0")}
+		p2pconf.SwarmKey = key
+		n, err := NewQriNode(r, p2pconf, event.NilBus, nil)
+		if err != nil {
+			t.Fatalf("error creating qri node: %s", err)
+		}
+		if err := n.GoOnline(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	a := newNode(0, swarmKey("0f"))
+	b := newNode(1, swarmKey("0f"))
+	c := newNode(2, swarmKey("f0"))
+
+	if err := a.Host().Connect(ctx, b.SimpleAddrInfo()); err != nil {
+		t.Errorf("expected nodes that share a swarm key to connect, got: %s", err)
+	}
+
+	cInfo := c.SimpleAddrInfo()
+	a.Host().Peerstore().AddAddrs(cInfo.ID, cInfo.Addrs, time.Minute)
+	_, err := a.ConnectToPeer(ctx, PeerConnectionParams{PeerID: cInfo.ID})
+	if !errors.Is(err, ErrPrivateNetworkConnect) {
+		t.Errorf("expected connecting with mismatched swarm keys to return ErrPrivateNetworkConnect, got: %v", err)
+	}
+}
@@ -20,6 +20,10 @@ var (
 	ErrQriProtocolNotSupported = fmt.Errorf("peer doesn't support the qri protocol")
 	// ErrNoQriNode indicates a qri node doesn't exist
 	ErrNoQriNode = fmt.Errorf("p2p: no qri node")
+	// ErrPrivateNetworkConnect is returned when a node configured with a swarm
+	// key fails to connect to a peer. The most common cause is a peer that
+	// doesn't share the same swarm key
+	ErrPrivateNetworkConnect = fmt.Errorf("cannot connect to peer on private network. check that both peers are configured with the same swarm key")
 )
 
 const (
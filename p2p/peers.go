@@ -172,7 +172,7 @@ func (n *QriNode) ConnectToPeer(ctx context.Context, p PeerConnectionParams) (*p
 	}
 
 	if err := n.host.Connect(ctx, pinfo); err != nil {
-		return nil, fmt.Errorf("host connect %s failure: %s", pinfo.ID.Pretty(), err)
+		return nil, n.connectError(pinfo.ID, err)
 	}
 
 	// do an explicit connection upgrade
@@ -183,7 +183,7 @@ func (n *QriNode) ConnectToPeer(ctx context.Context, p PeerConnectionParams) (*p
 
 	// ConnectedPeerProfile will return nil if the profile is not found
 	pro := n.qis.ConnectedPeerProfile(pinfo.ID)
-	if err == nil {
+	if pro == nil {
 		return nil, fmt.Errorf("unable to get profile from peer %q", pinfo.ID)
 	}
 
@@ -106,8 +106,13 @@ func (rr *p2pRefResolver) resolveRefRequest(ctx context.Context, pid peer.ID, re
 	receivedRef, err := receiveRef(s)
 	if err != nil {
 		log.Debugf("p2p.ResolveRef - error reading ref message from %q: %s", pid, err)
+		rr.node.connMgr.recordResponse(pid, false)
 		return ""
 	}
+	rr.node.connMgr.recordResponse(pid, true)
+	if receivedRef.Complete() {
+		rr.node.connMgr.recordDatasetShared(pid)
+	}
 	*ref = *receivedRef
 	return pid.Pretty()
 }
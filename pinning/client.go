@@ -0,0 +1,128 @@
+package pinning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/qri-io/qri/config"
+)
+
+const (
+	// PinQueued marks a pin request the service hasn't started on
+	PinQueued = "queued"
+	// PinPinning marks a pin request the service is fetching blocks for
+	PinPinning = "pinning"
+	// PinPinned marks a pin request with every block stored by the service
+	PinPinned = "pinned"
+	// PinFailed marks a pin request the service couldn't complete
+	PinFailed = "failed"
+)
+
+// Pin is a pin object of the IPFS pinning service API
+type Pin struct {
+	CID     string            `json:"cid"`
+	Name    string            `json:"name,omitempty"`
+	Origins []string          `json:"origins,omitempty"`
+	Meta    map[string]string `json:"meta,omitempty"`
+}
+
+// PinStatus is a pin status object of the IPFS pinning service API
+type PinStatus struct {
+	RequestID string    `json:"requestid"`
+	Status    string    `json:"status"`
+	Created   time.Time `json:"created"`
+	Pin       Pin       `json:"pin"`
+	Delegates []string  `json:"delegates"`
+}
+
+// Client makes requests to a service implementing the IPFS pinning service
+// API. See https://ipfs.github.io/pinning-services-api-spec
+type Client struct {
+	cfg    *config.PinningService
+	client *http.Client
+}
+
+// NewClient creates a client for a pinning service
+func NewClient(cfg *config.PinningService) *Client {
+	return &Client{cfg: cfg, client: http.DefaultClient}
+}
+
+// Add asks the service to pin a CID
+func (c *Client) Add(ctx context.Context, pin Pin) (*PinStatus, error) {
+	res := &PinStatus{}
+	if err := c.do(ctx, http.MethodPost, c.url("/pins"), pin, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Get fetches the status of a pin request
+func (c *Client) Get(ctx context.Context, requestID string) (*PinStatus, error) {
+	res := &PinStatus{}
+	if err := c.do(ctx, http.MethodGet, c.url("/pins/"+url.PathEscape(requestID)), nil, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *Client) url(path string) string {
+	return strings.TrimSuffix(c.cfg.Endpoint, "/") + path
+}
+
+func (c *Client) do(ctx context.Context, method, u string, body, res interface{}) error {
+	var r *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	} else {
+		r = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, u, r)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := struct {
+			Error struct {
+				Reason  string `json:"reason"`
+				Details string `json:"details"`
+			} `json:"error"`
+		}{}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Reason != "" {
+			if apiErr.Error.Details != "" {
+				return fmt.Errorf("%s: %s: %s", c.cfg.Name, apiErr.Error.Reason, apiErr.Error.Details)
+			}
+			return fmt.Errorf("%s: %s", c.cfg.Name, apiErr.Error.Reason)
+		}
+		return fmt.Errorf("%s: unexpected response status %d", c.cfg.Name, resp.StatusCode)
+	}
+	if res != nil {
+		return json.Unmarshal(data, res)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Package pinning replicates published dataset versions to remote pinning
+// services that implement the IPFS pinning service API, like Pinata or
+// web3.storage. Services fetch the blocks of a pinned version from the IPFS
+// network, keeping versions available when the publishing node is offline.
+// The status of every pin request is recorded per version
+package pinning
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+)
+
+var log = golog.Logger("pinning")
+
+type target struct {
+	cfg    *config.PinningService
+	client *Client
+}
+
+// Service pins dataset versions to configured pinning services
+type Service struct {
+	targets []*target
+	store   *StatusStore
+}
+
+// New creates a pinning service from integrations configuration. Statuses
+// are persisted to filename, an empty filename keeps them in memory. Disabled
+// services are skipped
+func New(cfg *config.Integrations, filename string) (*Service, error) {
+	targets := []*target{}
+	if cfg != nil {
+		for _, ps := range cfg.PinningServices {
+			if ps.Disabled {
+				continue
+			}
+			targets = append(targets, &target{cfg: ps, client: NewClient(ps)})
+		}
+	}
+	store, err := NewStatusStore(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{targets: targets, store: store}, nil
+}
+
+// CID returns the content identifier of a version stored on IPFS
+func CID(path string) (string, error) {
+	if !strings.HasPrefix(path, "/ipfs/") {
+		return "", fmt.Errorf("only versions stored on IPFS can be pinned, got path %q", path)
+	}
+	return strings.TrimPrefix(path, "/ipfs/"), nil
+}
+
+// Pin asks every service enabled for a dataset to pin a version. origins are
+// multiaddrs of peers that have the version's blocks, usually this node.
+// Failed requests are recorded in the returned statuses, not returned as
+// errors
+func (s *Service) Pin(ctx context.Context, ref dsref.Ref, origins []string) []Status {
+	res := []Status{}
+	for _, t := range s.targets {
+		if !t.cfg.EnabledFor(ref.Alias()) {
+			continue
+		}
+		st := Status{
+			Service: t.cfg.Name,
+			Dataset: ref.Alias(),
+			Path:    ref.Path,
+			State:   PinQueued,
+		}
+		ps, err := s.pin(ctx, t, ref, origins)
+		if err != nil {
+			log.Debugw("pinning version", "service", t.cfg.Name, "ref", ref.String(), "err", err)
+			st.State = PinFailed
+			st.Error = err.Error()
+		} else {
+			st.CID = ps.Pin.CID
+			st.RequestID = ps.RequestID
+			st.State = ps.Status
+		}
+		st.Time = time.Now()
+		s.store.Put(st)
+		res = append(res, st)
+	}
+	return res
+}
+
+func (s *Service) pin(ctx context.Context, t *target, ref dsref.Ref, origins []string) (*PinStatus, error) {
+	cid, err := CID(ref.Path)
+	if err != nil {
+		return nil, err
+	}
+	ps, err := t.client.Add(ctx, Pin{
+		CID:     cid,
+		Name:    ref.Alias(),
+		Origins: origins,
+		Meta:    map[string]string{"qri.ref": ref.String()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ps.Pin.CID == "" {
+		ps.Pin.CID = cid
+	}
+	return ps, nil
+}
+
+// Statuses lists pin statuses for a dataset, newest first. An empty alias
+// lists statuses for all datasets
+func (s *Service) Statuses(alias string) []Status {
+	return s.store.List(alias)
+}
+
+// Refresh asks services for the current state of pin requests that aren't
+// done, returning updated statuses for a dataset. An empty alias refreshes
+// all datasets
+func (s *Service) Refresh(ctx context.Context, alias string) []Status {
+	clients := map[string]*Client{}
+	for _, t := range s.targets {
+		clients[t.cfg.Name] = t.client
+	}
+	for _, st := range s.store.List(alias) {
+		client, ok := clients[st.Service]
+		if st.Done() || st.RequestID == "" || !ok {
+			continue
+		}
+		ps, err := client.Get(ctx, st.RequestID)
+		if err != nil {
+			log.Debugw("refreshing pin status", "service", st.Service, "path", st.Path, "err", err)
+			continue
+		}
+		if ps.Status != st.State {
+			st.State = ps.Status
+			st.Time = time.Now()
+			s.store.Put(st)
+		}
+	}
+	return s.store.List(alias)
+}
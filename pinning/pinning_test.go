@@ -0,0 +1,129 @@
+package pinning
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+)
+
+// fakePinService implements the parts of the pinning service API qri uses
+type fakePinService struct {
+	lk     sync.Mutex
+	token  string
+	pins   map[string]*PinStatus
+	status string
+}
+
+func (f *fakePinService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+	if r.Header.Get("Authorization") != "Bearer "+f.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"reason":"UNAUTHORIZED","details":"bad token"}}`))
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/pins":
+		pin := Pin{}
+		if err := json.NewDecoder(r.Body).Decode(&pin); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		ps := &PinStatus{RequestID: "req-" + pin.CID, Status: PinQueued, Pin: pin}
+		f.pins[ps.RequestID] = ps
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(ps)
+	case r.Method == http.MethodGet:
+		ps, ok := f.pins[filepath.Base(r.URL.Path)]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"reason":"NOT_FOUND"}}`))
+			return
+		}
+		ps.Status = f.status
+		json.NewEncoder(w).Encode(ps)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestPin(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakePinService{token: "secret", pins: map[string]*PinStatus{}, status: PinPinned}
+	s := httptest.NewServer(fake)
+	defer s.Close()
+
+	tmp, err := ioutil.TempDir("", "pinning_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "pin_status.json")
+
+	cfg := &config.Integrations{
+		PinningServices: []*config.PinningService{
+			{Name: "good", Endpoint: s.URL, Token: "secret"},
+			{Name: "badtoken", Endpoint: s.URL, Token: "wrong"},
+			{Name: "other", Endpoint: s.URL, Token: "secret", Datasets: []string{"peer/other"}},
+			{Name: "off", Endpoint: s.URL, Token: "secret", Disabled: true},
+		},
+	}
+	svc, err := New(cfg, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := dsref.Ref{Username: "peer", Name: "cities", Path: "/ipfs/QmCities"}
+	got := svc.Pin(ctx, ref, []string{"/ip4/127.0.0.1/tcp/4001/p2p/QmPeer"})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 statuses, got %d: %v", len(got), got)
+	}
+	states := map[string]Status{}
+	for _, st := range got {
+		states[st.Service] = st
+	}
+	if st := states["good"]; st.State != PinQueued || st.CID != "QmCities" || st.RequestID != "req-QmCities" {
+		t.Errorf("unexpected status for good service: %#v", st)
+	}
+	if st := states["badtoken"]; st.State != PinFailed || st.Error != "badtoken: UNAUTHORIZED: bad token" {
+		t.Errorf("unexpected status for badtoken service: %#v", st)
+	}
+	if origins := fake.pins["req-QmCities"].Pin.Origins; len(origins) != 1 {
+		t.Errorf("expected origins to be sent, got: %v", origins)
+	}
+
+	refreshed := svc.Refresh(ctx, "peer/cities")
+	for _, st := range refreshed {
+		if st.Service == "good" && st.State != PinPinned {
+			t.Errorf("expected refreshed state to be %q, got %q", PinPinned, st.State)
+		}
+	}
+
+	// statuses persist
+	reopened, err := New(cfg, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(reopened.Statuses("peer/cities")); n != 2 {
+		t.Errorf("expected 2 persisted statuses, got %d", n)
+	}
+	if n := len(reopened.Statuses("peer/other")); n != 0 {
+		t.Errorf("expected no statuses for unpinned dataset, got %d", n)
+	}
+
+	notIPFS := dsref.Ref{Username: "peer", Name: "cities", Path: "/mem/QmCities"}
+	for _, st := range svc.Pin(ctx, notIPFS, nil) {
+		if st.State != PinFailed {
+			t.Errorf("expected pinning a non-IPFS path to fail, got state %q", st.State)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package pinning
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+// Status records pinning one dataset version to a pinning service
+type Status struct {
+	Service string `json:"service"`
+	Dataset string `json:"dataset"`
+	Path    string `json:"path"`
+	CID     string `json:"cid"`
+	// RequestID identifies the pin request with the service
+	RequestID string `json:"requestID,omitempty"`
+	// State is one of "queued", "pinning", "pinned", or "failed"
+	State string    `json:"state"`
+	Error string    `json:"error,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// Done returns true if the status won't change
+func (s Status) Done() bool {
+	return s.State == PinPinned || s.State == PinFailed
+}
+
+func (s Status) key() string {
+	return s.Service + " " + s.Path
+}
+
+// StatusStore keeps pin statuses, persisted as JSON to filename. An empty
+// filename keeps statuses in memory
+type StatusStore struct {
+	file jsonfile.File
+
+	lk       sync.Mutex
+	statuses map[string]Status
+}
+
+// NewStatusStore creates a status store, loading any statuses persisted to
+// filename
+func NewStatusStore(filename string) (*StatusStore, error) {
+	s := &StatusStore{
+		file:     jsonfile.New(filename),
+		statuses: map[string]Status{},
+	}
+	statuses := []Status{}
+	if err := s.file.Load(&statuses); err != nil {
+		return nil, fmt.Errorf("reading pin statuses: %w", err)
+	}
+	for _, st := range statuses {
+		s.statuses[st.key()] = st
+	}
+	return s, nil
+}
+
+// Put records a status, replacing any status for the same service & version
+func (s *StatusStore) Put(st Status) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.statuses[st.key()] = st
+	if err := s.save(); err != nil {
+		log.Debugw("saving pin statuses", "err", err)
+	}
+}
+
+// List returns statuses for a dataset alias, newest first. An empty alias
+// lists all statuses
+func (s *StatusStore) List(alias string) []Status {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	res := []Status{}
+	for _, st := range s.statuses {
+		if alias == "" || st.Dataset == alias {
+			res = append(res, st)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Time.After(res[j].Time)
+	})
+	return res
+}
+
+// save writes statuses. must be called with the lock held
+func (s *StatusStore) save() error {
+	statuses := make([]Status, 0, len(s.statuses))
+	for _, st := range s.statuses {
+		statuses = append(statuses, st)
+	}
+	return s.file.Save(statuses)
+}
@@ -0,0 +1,123 @@
+package profile
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/qri-io/qri/config"
+)
+
+// nowFunc is the function that generates timestamps (tests may override)
+var nowFunc = time.Now
+
+// profileRecord is the stored form of a single profile. Alongside profile
+// data, records track when each field was last written so concurrent updates
+// to a profile can be merged field-by-field
+type profileRecord struct {
+	Profile *config.ProfilePod `json:"profile"`
+	// Fields maps JSON field names to the Updated timestamp of the write that
+	// last set them
+	Fields map[string]time.Time `json:"fields"`
+}
+
+// mergeSkipFields are fields that aren't merged last-writer-wins
+var mergeSkipFields = map[string]bool{
+	"id":      true,
+	"created": true,
+	"updated": true,
+	"online":  true,
+	"peerIDs": true,
+}
+
+// merge folds a profile into the record. Each field present in pod that
+// differs from the stored value replaces it, unless the stored value was
+// written by a later update. pod.Updated is the time of the write, a zero
+// Updated value is the current time. Peer IDs are merged as a set, the
+// earliest created time and latest updated time are kept
+func (rec *profileRecord) merge(pod *config.ProfilePod) error {
+	ts := pod.Updated
+	if ts.IsZero() {
+		ts = nowFunc().UTC()
+	}
+
+	if rec.Profile == nil {
+		rec.Profile = &config.ProfilePod{ID: pod.ID}
+	}
+	if rec.Fields == nil {
+		rec.Fields = map[string]time.Time{}
+	}
+
+	fields, err := podFields(rec.Profile)
+	if err != nil {
+		return err
+	}
+	in, err := podFields(pod)
+	if err != nil {
+		return err
+	}
+	for key, val := range in {
+		if mergeSkipFields[key] || reflect.DeepEqual(fields[key], val) {
+			continue
+		}
+		if prev, ok := rec.Fields[key]; ok && prev.After(ts) {
+			continue
+		}
+		fields[key] = val
+		rec.Fields[key] = ts
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	merged := &config.ProfilePod{}
+	if err := json.Unmarshal(data, merged); err != nil {
+		return err
+	}
+
+	merged.ID = rec.Profile.ID
+	merged.Created = rec.Profile.Created
+	if merged.Created.IsZero() || (!pod.Created.IsZero() && pod.Created.Before(merged.Created)) {
+		merged.Created = pod.Created
+	}
+	merged.Updated = rec.Profile.Updated
+	if ts.After(merged.Updated) {
+		merged.Updated = ts
+	}
+	merged.PeerIDs = unionStrings(rec.Profile.PeerIDs, pod.PeerIDs)
+	merged.Online = false
+
+	rec.Profile = merged
+	return nil
+}
+
+// podFields gets the JSON fields of a profile pod
+func podFields(pod *config.ProfilePod) (map[string]interface{}, error) {
+	data, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// unionStrings combines two lists of strings, dropping duplicates & keeping
+// order
+func unionStrings(a, b []string) []string {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	seen := map[string]bool{}
+	res := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, s := range list {
+			if !seen[s] {
+				seen[s] = true
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
@@ -287,17 +287,24 @@ func (m *MemStore) DeleteProfile(id ID) error {
 	return nil
 }
 
-// LocalStore is an on-disk json file implementation of the
-// repo.Peers interface
+// LocalStore is an on-disk implementation of the profile Store interface.
+// Each profile is stored as a record in its own file in a directory named
+// after filename (eg: peers.json stores records in peers/). Writes merge into
+// the stored record while holding a file lock, so concurrent updates to
+// different fields of a profile aren't lost. Records that can't be read are
+// skipped, leaving the rest of the store usable
 type LocalStore struct {
 	sync.Mutex
 	owner    *Profile
 	keyStore key.Store
 	filename string
+	dir      string
 	flock    *flock.Flock
 }
 
-// NewLocalStore allocates a LocalStore
+// NewLocalStore allocates a LocalStore. Profiles stored in a single
+// filename JSON file by earlier versions are moved into per-profile records,
+// and the file is renamed with a ".bak" extension
 func NewLocalStore(filename string, owner *Profile, ks key.Store) (Store, error) {
 	if err := owner.ValidOwnerProfile(); err != nil {
 		return nil, err
@@ -311,9 +318,14 @@ func NewLocalStore(filename string, owner *Profile, ks key.Store) (Store, error)
 		owner:    owner,
 		keyStore: ks,
 		filename: filename,
+		dir:      strings.TrimSuffix(filename, filepath.Ext(filename)),
 		flock:    flock.NewFlock(lockPath(filename)),
 	}
 
+	if err := s.migrateFile(); err != nil {
+		return nil, err
+	}
+
 	err := s.PutProfile(owner)
 	return s, err
 }
@@ -367,13 +379,7 @@ func (r *LocalStore) PutProfile(p *Profile) error {
 
 	r.Lock()
 	defer r.Unlock()
-
-	ps, err := r.profiles()
-	if err != nil {
-		return err
-	}
-	ps[p.ID.String()] = enc
-	return r.saveFile(ps)
+	return r.putRecord(enc)
 }
 
 // PeerIDs gives the peer.IDs list for a given peername
@@ -531,33 +537,78 @@ func (r *LocalStore) DeleteProfile(id ID) error {
 	r.Lock()
 	defer r.Unlock()
 
-	ps, err := r.profiles()
-	if err != nil {
+	if err := r.flock.Lock(); err != nil {
 		return err
 	}
-	delete(ps, id.String())
-	return r.saveFile(ps)
+	defer r.flock.Unlock()
+
+	if err := os.Remove(r.recordPath(id.String())); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
-func (r *LocalStore) saveFile(ps map[string]*config.ProfilePod) error {
+func (r *LocalStore) recordPath(id string) string {
+	return filepath.Join(r.dir, fmt.Sprintf("%s.json", id))
+}
+
+// putRecord merges a profile into its stored record. The file lock is held
+// from reading the record until the merged record is written
+func (r *LocalStore) putRecord(pod *config.ProfilePod) error {
+	if err := r.flock.Lock(); err != nil {
+		return err
+	}
+	defer r.flock.Unlock()
 
-	data, err := json.Marshal(ps)
+	rec, err := r.readRecord(r.recordPath(pod.ID))
 	if err != nil {
-		log.Debug(err.Error())
+		if !os.IsNotExist(err) {
+			log.Warnf("replacing unreadable profile record %q: %s", pod.ID, err)
+		}
+		rec = &profileRecord{}
+	}
+	if err := rec.merge(pod); err != nil {
 		return err
 	}
+	return r.writeRecord(rec)
+}
 
-	log.Debugf("writing profiles: %s", r.filename)
-	if err := r.flock.Lock(); err != nil {
+func (r *LocalStore) readRecord(path string) (*profileRecord, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rec := &profileRecord{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, err
+	}
+	if rec.Profile == nil || rec.Profile.ID == "" {
+		return nil, fmt.Errorf("record has no profile ID")
+	}
+	return rec, nil
+}
+
+// writeRecord writes a record to a temp file & renames it into place, so
+// readers never see a partially written record
+func (r *LocalStore) writeRecord(rec *profileRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
 		return err
 	}
-	defer func() {
-		r.flock.Unlock()
-		log.Debugf("profiles written")
-	}()
-	return ioutil.WriteFile(r.filename, data, 0644)
+	if err := os.MkdirAll(r.dir, os.ModePerm); err != nil {
+		return err
+	}
+	path := r.recordPath(rec.Profile.ID)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	log.Debugf("writing profile record: %s", path)
+	return os.Rename(tmp, path)
 }
 
+// profiles reads all profile records. Records that can't be read are logged
+// & skipped
 func (r *LocalStore) profiles() (map[string]*config.ProfilePod, error) {
 	log.Debug("reading profiles")
 
@@ -570,7 +621,7 @@ func (r *LocalStore) profiles() (map[string]*config.ProfilePod, error) {
 	}()
 
 	pp := map[string]*config.ProfilePod{}
-	data, err := ioutil.ReadFile(r.filename)
+	infos, err := ioutil.ReadDir(r.dir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return pp, nil
@@ -579,11 +630,47 @@ func (r *LocalStore) profiles() (map[string]*config.ProfilePod, error) {
 		return pp, fmt.Errorf("error loading peers: %s", err.Error())
 	}
 
-	if err := json.Unmarshal(data, &pp); err != nil {
-		log.Error(err.Error())
-		// TODO - this is totally screwed for some reason, so for now when things fail,
-		// let's just return an empty list of peers
-		return pp, nil
+	for _, fi := range infos {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".json" {
+			continue
+		}
+		rec, err := r.readRecord(filepath.Join(r.dir, fi.Name()))
+		if err != nil {
+			log.Warnf("skipping unreadable profile record %q: %s", fi.Name(), err)
+			continue
+		}
+		pp[rec.Profile.ID] = rec.Profile
 	}
 	return pp, nil
 }
+
+// migrateFile moves profiles from a single JSON file written by earlier
+// versions of LocalStore into per-profile records. Entries that can't be
+// read are skipped
+func (r *LocalStore) migrateFile() error {
+	data, err := ioutil.ReadFile(r.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	entries := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.Errorf("reading profiles file %q: %s", r.filename, err)
+	}
+	for id, entry := range entries {
+		pod := &config.ProfilePod{}
+		if err := json.Unmarshal(entry, pod); err != nil || pod.ID == "" {
+			log.Warnf("skipping unreadable profile %q: %v", id, err)
+			continue
+		}
+		if err := r.putRecord(pod); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("migrated %d profiles from %s", len(entries), r.filename)
+	return os.Rename(r.filename, r.filename+".bak")
+}
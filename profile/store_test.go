@@ -34,10 +34,11 @@ func TestPutProfileWithAddresses(t *testing.T) {
 		pid,
 	}
 
-	path := filepath.Join(os.TempDir(), "profile")
-	if err := os.MkdirAll(path, os.ModePerm); err != nil {
-		t.Errorf("error creating tmp directory: %s", err.Error())
+	path, err := ioutil.TempDir("", "profile")
+	if err != nil {
+		t.Fatal(err)
 	}
+	defer os.RemoveAll(path)
 
 	kd0 := testkeys.GetKeyData(0)
 
@@ -66,20 +67,21 @@ func TestPutProfileWithAddresses(t *testing.T) {
 	if err != nil {
 		t.Errorf("error reading golden file: %s", err.Error())
 	}
-	golden := map[string]interface{}{}
+	var golden interface{}
 	if err := json.Unmarshal(gf, &golden); err != nil {
 		t.Fatal(err)
 	}
 
-	path = filepath.Join(path, "profiles.json")
+	path = filepath.Join(path, "profiles", pro.ID.String()+".json")
 	f, err := ioutil.ReadFile(path)
 	if err != nil {
 		t.Errorf("error reading written file: %s", err.Error())
 	}
-	got := map[string]interface{}{}
-	if err := json.Unmarshal(f, &got); err != nil {
+	rec := map[string]interface{}{}
+	if err := json.Unmarshal(f, &rec); err != nil {
 		t.Fatal(err)
 	}
+	got := rec["profile"]
 
 	t.Log(string(f))
 	if diff := cmp.Diff(golden, got); diff != "" {
@@ -213,3 +215,130 @@ func TestResolveUsername(t *testing.T) {
 		t.Errorf("expected duplicated username to return ErrAmbiguousUsername or wrap of that error. got: %#v", err)
 	}
 }
+
+func TestProfileRecordMerge(t *testing.T) {
+	t1 := time.Unix(1234567890, 0).In(time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	rec := &profileRecord{}
+	if err := rec.merge(&config.ProfilePod{ID: "a", Peername: "peer", Email: "old@example.com", Name: "old name", Created: t1, Updated: t1, PeerIDs: []string{"/ipfs/a"}}); err != nil {
+		t.Fatal(err)
+	}
+	// a later write changes the name
+	if err := rec.merge(&config.ProfilePod{ID: "a", Peername: "peer", Email: "old@example.com", Name: "new name", Updated: t2, PeerIDs: []string{"/ipfs/b"}}); err != nil {
+		t.Fatal(err)
+	}
+	// a concurrent write from a stale copy changes the email. the stale copy's
+	// name must not replace the newer name
+	if err := rec.merge(&config.ProfilePod{ID: "a", Peername: "peer", Email: "new@example.com", Name: "old name", Updated: t1.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := &config.ProfilePod{
+		ID:       "a",
+		Peername: "peer",
+		Email:    "new@example.com",
+		Name:     "new name",
+		Created:  t1,
+		Updated:  t2,
+		PeerIDs:  []string{"/ipfs/a", "/ipfs/b"},
+	}
+	if diff := cmp.Diff(expect, rec.Profile); diff != "" {
+		t.Errorf("merged profile mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLocalStoreConcurrentWriters(t *testing.T) {
+	path, err := ioutil.TempDir("", "profile_writers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	kd0 := testkeys.GetKeyData(0)
+	kd1 := testkeys.GetKeyData(1)
+	filename := filepath.Join(path, "peers.json")
+	owner := &Profile{ID: IDFromPeerID(kd0.PeerID), Peername: "user", PrivKey: kd0.PrivKey}
+
+	newStore := func() Store {
+		ks, err := key.NewMemStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewLocalStore(filename, owner, ks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	// two stores backed by the same files, as in two qri processes
+	a, b := newStore(), newStore()
+
+	t1 := time.Unix(1234567890, 0).In(time.UTC)
+	id := IDFromPeerID(kd1.PeerID)
+	if err := a.PutProfile(&Profile{ID: id, Peername: "marjorie", Email: "marjorie@aol.com", Updated: t1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.PutProfile(&Profile{ID: id, Peername: "marjorie", Twitter: "marjorie", Updated: t1.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := a.GetProfile(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Twitter != "marjorie" {
+		t.Errorf("expected twitter field written by the later update. got: %q", got.Twitter)
+	}
+
+	// a corrupted record doesn't break the store
+	if err := ioutil.WriteFile(filepath.Join(path, "peers", "QmCorrupted.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pros, err := b.List()
+	if err != nil {
+		t.Fatalf("listing with a corrupted record: %s", err)
+	}
+	if len(pros) != 2 {
+		t.Errorf("expected 2 profiles, got %d", len(pros))
+	}
+}
+
+func TestLocalStoreMigratesFile(t *testing.T) {
+	path, err := ioutil.TempDir("", "profile_migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	kd0 := testkeys.GetKeyData(0)
+	kd1 := testkeys.GetKeyData(1)
+	filename := filepath.Join(path, "peers.json")
+	legacy := `{
+		"` + kd1.PeerID.Pretty() + `": { "id": "` + kd1.PeerID.Pretty() + `", "peername": "marjorie", "type": "peer" },
+		"broken": "not a profile"
+	}`
+	if err := ioutil.WriteFile(filename, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ks, err := key.NewMemStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := &Profile{ID: IDFromPeerID(kd0.PeerID), Peername: "user", PrivKey: kd0.PrivKey}
+	s, err := NewLocalStore(filename, owner, ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ResolveUsername(s, "marjorie"); err != nil {
+		t.Errorf("expected migrated profile to resolve, got: %s", err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("expected legacy profiles file to be moved")
+	}
+	if _, err := os.Stat(filename + ".bak"); err != nil {
+		t.Errorf("expected legacy profiles file backup: %s", err)
+	}
+}
@@ -0,0 +1,390 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/remote"
+)
+
+var (
+	// ErrNotOrgOwner is returned when a profile without the owner role tries
+	// to manage an organization
+	ErrNotOrgOwner = fmt.Errorf("only organization owners can manage members")
+	// ErrNamespaceForbidden is returned when a profile pushes to a namespace it
+	// doesn't own or belong to
+	ErrNamespaceForbidden = fmt.Errorf("profile can't push to this namespace")
+)
+
+const (
+	// RoleOwner members manage an organization's membership
+	RoleOwner = "owner"
+	// RoleMember members may push datasets to an organization's namespace
+	RoleMember = "member"
+)
+
+// Organization is a registry account shared by a team of profiles.
+// Organizations own a username namespace the same way profiles do. Any
+// member may push datasets to that namespace
+type Organization struct {
+	Created     time.Time `json:"created"`
+	Username    string    `json:"username"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Photo       string    `json:"photo"`
+	HomeURL     string    `json:"homeurl"`
+	Members     []*Member `json:"members"`
+}
+
+// Member is a profile that belongs to an organization
+type Member struct {
+	Username  string    `json:"username"`
+	ProfileID string    `json:"profileid"`
+	Role      string    `json:"role"`
+	Added     time.Time `json:"added"`
+}
+
+// Member returns the member with a given profileID, nil if none exists
+func (o *Organization) Member(profileID string) *Member {
+	for _, m := range o.Members {
+		if m.ProfileID == profileID {
+			return m
+		}
+	}
+	return nil
+}
+
+// owners counts members with the owner role
+func (o *Organization) owners() (n int) {
+	for _, m := range o.Members {
+		if m.Role == RoleOwner {
+			n++
+		}
+	}
+	return n
+}
+
+// Organizations is the interface for working with a set of *Organization's,
+// keyed by username
+type Organizations interface {
+	// Len returns the number of records in the set
+	Len() (int, error)
+	// Load fetches an organization by key
+	Load(key string) (value *Organization, err error)
+	// SortedRange calls an iteration function on each element in key order
+	// until the end of the list is reached or iter returns false
+	SortedRange(iter func(key string, o *Organization) (kontinue bool, err error)) error
+	// Create adds an entry
+	Create(key string, value *Organization) error
+	// Update modifies an existing organization
+	Update(key string, value *Organization) error
+	// Delete removes an organization from the set at key
+	Delete(key string) error
+}
+
+// OrganizationRequest is a signed request to create or remove an
+// organization. Requester proves ownership of a registered profile
+type OrganizationRequest struct {
+	Organization *Organization `json:"organization"`
+	Requester    *Profile      `json:"requester"`
+}
+
+// MembershipRequest is a signed request to change the members of an
+// organization
+type MembershipRequest struct {
+	Org string `json:"org"`
+	// Member is the username of the profile to add or remove
+	Member string `json:"member"`
+	// Role to give the member when adding, defaults to RoleMember
+	Role      string   `json:"role,omitempty"`
+	Requester *Profile `json:"requester"`
+}
+
+// authenticate checks a requester's proof of key ownership, returning the
+// registered profile that matches
+func authenticate(profiles Profiles, p *Profile) (*Profile, error) {
+	if p == nil {
+		return nil, fmt.Errorf("requester is required")
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if err := p.Verify(); err != nil {
+		return nil, err
+	}
+	pro, err := profiles.Load(p.Username)
+	if err != nil {
+		return nil, fmt.Errorf("requester %q isn't registered", p.Username)
+	}
+	if pro.ProfileID != p.ProfileID {
+		return nil, fmt.Errorf("requester %q doesn't match the registered profile", p.Username)
+	}
+	return pro, nil
+}
+
+// CreateOrganization registers an organization, making the requester its
+// first owner. Organization usernames share a namespace with profiles
+func CreateOrganization(orgs Organizations, profiles Profiles, req *OrganizationRequest) (*Organization, error) {
+	requester, err := authenticate(profiles, req.Requester)
+	if err != nil {
+		return nil, err
+	}
+	o := req.Organization
+	if o == nil || o.Username == "" {
+		return nil, fmt.Errorf("organization username is required")
+	}
+	if err := dsref.EnsureValidUsername(o.Username); err != nil {
+		return nil, err
+	}
+	if _, err := profiles.Load(o.Username); err == nil {
+		return nil, ErrUsernameTaken
+	}
+	if _, err := orgs.Load(o.Username); err == nil {
+		return nil, ErrUsernameTaken
+	}
+
+	now := nowFunc()
+	o.Created = now
+	o.Members = []*Member{{
+		Username:  requester.Username,
+		ProfileID: requester.ProfileID,
+		Role:      RoleOwner,
+		Added:     now,
+	}}
+	return o, orgs.Create(o.Username, o)
+}
+
+// DeleteOrganization removes an organization. Only owners may delete
+func DeleteOrganization(orgs Organizations, profiles Profiles, req *OrganizationRequest) error {
+	if req.Organization == nil {
+		return fmt.Errorf("organization username is required")
+	}
+	o, err := loadAsOwner(orgs, profiles, req.Organization.Username, req.Requester)
+	if err != nil {
+		return err
+	}
+	return orgs.Delete(o.Username)
+}
+
+// AddMember adds a registered profile to an organization, or changes the
+// role of an existing member. Only owners may add members
+func AddMember(orgs Organizations, profiles Profiles, req *MembershipRequest) (*Organization, error) {
+	o, err := loadAsOwner(orgs, profiles, req.Org, req.Requester)
+	if err != nil {
+		return nil, err
+	}
+	role := req.Role
+	if role == "" {
+		role = RoleMember
+	}
+	if role != RoleOwner && role != RoleMember {
+		return nil, fmt.Errorf("invalid role %q, must be one of %q or %q", role, RoleOwner, RoleMember)
+	}
+	pro, err := profiles.Load(req.Member)
+	if err != nil {
+		return nil, fmt.Errorf("member %q isn't registered", req.Member)
+	}
+
+	if m := o.Member(pro.ProfileID); m != nil {
+		if m.Role == RoleOwner && role != RoleOwner && o.owners() == 1 {
+			return nil, fmt.Errorf("organization must have at least one owner")
+		}
+		m.Role = role
+	} else {
+		o.Members = append(o.Members, &Member{
+			Username:  pro.Username,
+			ProfileID: pro.ProfileID,
+			Role:      role,
+			Added:     nowFunc(),
+		})
+	}
+	return o, orgs.Update(o.Username, o)
+}
+
+// RemoveMember removes a profile from an organization. Owners may remove
+// any member, and members may remove themselves
+func RemoveMember(orgs Organizations, profiles Profiles, req *MembershipRequest) (*Organization, error) {
+	requester, err := authenticate(profiles, req.Requester)
+	if err != nil {
+		return nil, err
+	}
+	o, err := orgs.Load(req.Org)
+	if err != nil {
+		return nil, err
+	}
+	pro, err := profiles.Load(req.Member)
+	if err != nil {
+		return nil, fmt.Errorf("member %q isn't registered", req.Member)
+	}
+	if pro.ProfileID != requester.ProfileID {
+		if m := o.Member(requester.ProfileID); m == nil || m.Role != RoleOwner {
+			return nil, ErrNotOrgOwner
+		}
+	}
+
+	for i, m := range o.Members {
+		if m.ProfileID == pro.ProfileID {
+			if m.Role == RoleOwner && o.owners() == 1 {
+				return nil, fmt.Errorf("organization must have at least one owner")
+			}
+			o.Members = append(o.Members[:i], o.Members[i+1:]...)
+			return o, orgs.Update(o.Username, o)
+		}
+	}
+	return nil, fmt.Errorf("%q isn't a member of %q", req.Member, req.Org)
+}
+
+func loadAsOwner(orgs Organizations, profiles Profiles, orgName string, requester *Profile) (*Organization, error) {
+	pro, err := authenticate(profiles, requester)
+	if err != nil {
+		return nil, err
+	}
+	o, err := orgs.Load(orgName)
+	if err != nil {
+		return nil, err
+	}
+	if m := o.Member(pro.ProfileID); m == nil || m.Role != RoleOwner {
+		return nil, ErrNotOrgOwner
+	}
+	return o, nil
+}
+
+// CanPush checks a profile may push datasets to a username namespace.
+// Profiles own their own namespace, and organization members may push to
+// the organization's namespace. Namespaces the registry doesn't know are
+// open to anyone
+func CanPush(profiles Profiles, orgs Organizations, profileID, username string) error {
+	if orgs != nil {
+		if o, err := orgs.Load(username); err == nil {
+			if o.Member(profileID) == nil {
+				return fmt.Errorf("%w: %q isn't a member of %q", ErrNamespaceForbidden, profileID, username)
+			}
+			return nil
+		}
+	}
+	if profiles != nil {
+		if pro, err := profiles.Load(username); err == nil && pro.ProfileID != profileID {
+			return fmt.Errorf("%w: %q belongs to another profile", ErrNamespaceForbidden, username)
+		}
+	}
+	return nil
+}
+
+// NamespacePushCheck returns a remote hook that admits pushes only from
+// profiles that own the namespace of the pushed reference
+func NamespacePushCheck(profiles Profiles, orgs Organizations) remote.Hook {
+	return func(ctx context.Context, pid profile.ID, ref dsref.Ref) error {
+		return CanPush(profiles, orgs, pid.String(), ref.Username)
+	}
+}
+
+// NamespacePushChecks configures a remote to check namespace ownership
+// before accepting dataset & log pushes
+func NamespacePushChecks(profiles Profiles, orgs Organizations) remote.OptionsFunc {
+	check := NamespacePushCheck(profiles, orgs)
+	return func(o *remote.Options) {
+		o.DatasetPushPreCheck = chainHooks(check, o.DatasetPushPreCheck)
+		o.LogPushPreCheck = chainHooks(check, o.LogPushPreCheck)
+	}
+}
+
+func chainHooks(hooks ...remote.Hook) remote.Hook {
+	return func(ctx context.Context, pid profile.ID, ref dsref.Ref) error {
+		for _, h := range hooks {
+			if h == nil {
+				continue
+			}
+			if err := h(ctx, pid, ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// MemOrganizations is a map of organizations safe for concurrent use
+type MemOrganizations struct {
+	sync.RWMutex
+	orgs map[string]*Organization
+}
+
+var _ Organizations = (*MemOrganizations)(nil)
+
+// NewMemOrganizations allocates a new *MemOrganizations map
+func NewMemOrganizations() *MemOrganizations {
+	return &MemOrganizations{
+		orgs: make(map[string]*Organization),
+	}
+}
+
+// Len returns the number of records in the map
+func (mo *MemOrganizations) Len() (int, error) {
+	mo.RLock()
+	defer mo.RUnlock()
+	return len(mo.orgs), nil
+}
+
+// Load fetches an organization by key
+func (mo *MemOrganizations) Load(key string) (*Organization, error) {
+	mo.RLock()
+	defer mo.RUnlock()
+	o, ok := mo.orgs[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return o, nil
+}
+
+// SortedRange calls iter on each organization in key order
+func (mo *MemOrganizations) SortedRange(iter func(key string, o *Organization) (kontinue bool, err error)) error {
+	mo.RLock()
+	defer mo.RUnlock()
+	keys := make([]string, 0, len(mo.orgs))
+	for key := range mo.orgs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kontinue, err := iter(key, mo.orgs[key])
+		if err != nil {
+			return err
+		}
+		if !kontinue {
+			break
+		}
+	}
+	return nil
+}
+
+// Create adds an organization
+func (mo *MemOrganizations) Create(key string, value *Organization) error {
+	mo.Lock()
+	mo.orgs[key] = value
+	mo.Unlock()
+	return nil
+}
+
+// Update modifies an existing organization
+func (mo *MemOrganizations) Update(key string, value *Organization) error {
+	mo.Lock()
+	defer mo.Unlock()
+	if _, ok := mo.orgs[key]; !ok {
+		return ErrNotFound
+	}
+	mo.orgs[key] = value
+	return nil
+}
+
+// Delete removes an organization at key
+func (mo *MemOrganizations) Delete(key string) error {
+	mo.Lock()
+	delete(mo.orgs, key)
+	mo.Unlock()
+	return nil
+}
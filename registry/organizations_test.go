@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	testkeys "github.com/qri-io/qri/auth/key/test"
+)
+
+func TestOrganizations(t *testing.T) {
+	profiles := NewMemProfiles()
+	orgs := NewMemOrganizations()
+
+	signed := func(username string, keyIndex int) *Profile {
+		p, err := ProfileFromPrivateKey(&Profile{Username: username}, testkeys.GetKeyData(keyIndex).PrivKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	register := func(username string, keyIndex int) *Profile {
+		p := signed(username, keyIndex)
+		if err := RegisterProfile(profiles, p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	alice := register("alice", 0)
+	bob := register("bob", 1)
+	carol := register("carol", 2)
+
+	if _, err := CreateOrganization(orgs, profiles, &OrganizationRequest{
+		Organization: &Organization{Username: "bob"},
+		Requester:    alice,
+	}); !errors.Is(err, ErrUsernameTaken) {
+		t.Errorf("expected org with a profile's username to fail with ErrUsernameTaken, got: %v", err)
+	}
+	if _, err := CreateOrganization(orgs, profiles, &OrganizationRequest{
+		Organization: &Organization{Username: "acme"},
+		Requester:    signed("alice", 1),
+	}); err == nil {
+		t.Error("expected request signed with the wrong key to fail")
+	}
+
+	o, err := CreateOrganization(orgs, profiles, &OrganizationRequest{
+		Organization: &Organization{Username: "acme", Name: "Acme Corp"},
+		Requester:    alice,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := o.Member(alice.ProfileID); m == nil || m.Role != RoleOwner {
+		t.Errorf("expected creator to be an owner, got: %#v", m)
+	}
+
+	if _, err := AddMember(orgs, profiles, &MembershipRequest{Org: "acme", Member: "carol", Requester: bob}); !errors.Is(err, ErrNotOrgOwner) {
+		t.Errorf("expected non-owner add to fail with ErrNotOrgOwner, got: %v", err)
+	}
+	if _, err := AddMember(orgs, profiles, &MembershipRequest{Org: "acme", Member: "bob", Requester: alice}); err != nil {
+		t.Fatal(err)
+	}
+
+	pushCases := []struct {
+		profileID, namespace string
+		allowed              bool
+	}{
+		{alice.ProfileID, "acme", true},
+		{bob.ProfileID, "acme", true},
+		{carol.ProfileID, "acme", false},
+		{bob.ProfileID, "bob", true},
+		{bob.ProfileID, "alice", false},
+		{carol.ProfileID, "unregistered", true},
+	}
+	for _, c := range pushCases {
+		err := CanPush(profiles, orgs, c.profileID, c.namespace)
+		if c.allowed && err != nil {
+			t.Errorf("expected %s to push to %q, got: %s", c.profileID, c.namespace, err)
+		} else if !c.allowed && !errors.Is(err, ErrNamespaceForbidden) {
+			t.Errorf("expected %s push to %q to fail with ErrNamespaceForbidden, got: %v", c.profileID, c.namespace, err)
+		}
+	}
+
+	if _, err := RemoveMember(orgs, profiles, &MembershipRequest{Org: "acme", Member: "alice", Requester: alice}); err == nil {
+		t.Error("expected removing the last owner to fail")
+	}
+	// members can remove themselves
+	if o, err = RemoveMember(orgs, profiles, &MembershipRequest{Org: "acme", Member: "bob", Requester: bob}); err != nil {
+		t.Fatal(err)
+	}
+	if o.Member(bob.ProfileID) != nil {
+		t.Error("expected bob to be removed")
+	}
+	if err := CanPush(profiles, orgs, bob.ProfileID, "acme"); !errors.Is(err, ErrNamespaceForbidden) {
+		t.Errorf("expected removed member push to fail, got: %v", err)
+	}
+}
@@ -29,6 +29,10 @@ type Profile struct {
 	ProfileID string `json:"profileid"`
 	PublicKey string `json:"publickey"`
 	Signature string `json:"signature"`
+
+	// Deactivated is set when the profile owner has deactivated their account.
+	// deactivated profiles are tombstones that keep the username reserved
+	Deactivated *time.Time `json:"deactivated,omitempty"`
 }
 
 // Validate is a sanity check that all required values are present
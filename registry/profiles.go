@@ -3,6 +3,7 @@ package registry
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -52,6 +53,9 @@ func RegisterProfile(store Profiles, p *Profile) (err error) {
 
 	pro, err := store.Load(p.Username)
 	if err == nil {
+		if pro.Deactivated != nil {
+			return fmt.Errorf("username '%s': %w", p.Username, ErrProfileDeactivated)
+		}
 		// if peer is registring a name they already own, we're good
 		if pro.ProfileID == p.ProfileID {
 			return nil
@@ -104,6 +108,61 @@ func DeregisterProfile(store Profiles, p *Profile) error {
 	return nil
 }
 
+// DeactivateProfile replaces a profile with a tombstone that keeps the
+// username reserved, confirming the user has the authority to do so. Any
+// dataset snapshots in the profile namespace are removed. snaps may be nil
+func DeactivateProfile(store Profiles, snaps Snapshots, p *Profile) (*Profile, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if err := p.Verify(); err != nil {
+		return nil, err
+	}
+
+	pro, err := store.Load(p.Username)
+	if err != nil {
+		return nil, err
+	}
+	if pro.ProfileID != p.ProfileID {
+		return nil, fmt.Errorf("profile %q belongs to a different key", p.Username)
+	}
+	if pro.Deactivated != nil {
+		return pro, nil
+	}
+
+	if snaps != nil {
+		prefix := p.Username + "/"
+		keys := []string{}
+		err = snaps.SortedRange(func(key string, s *Snapshot) (bool, error) {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if err := snaps.Delete(key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	deactivated := nowFunc()
+	tombstone := &Profile{
+		Created:     pro.Created,
+		Username:    pro.Username,
+		ProfileID:   pro.ProfileID,
+		PublicKey:   pro.PublicKey,
+		Deactivated: &deactivated,
+	}
+	if err := store.Update(p.Username, tombstone); err != nil {
+		return nil, err
+	}
+	return tombstone, nil
+}
+
 // MemProfiles is a map of profile data safe for concurrent use
 // heavily inspired by sync.Map
 type MemProfiles struct {
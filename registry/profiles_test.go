@@ -2,6 +2,7 @@ package registry
 
 import (
 	"encoding/base64"
+	"errors"
 	"math/rand"
 	"testing"
 
@@ -128,3 +129,57 @@ func TestProfilesSortedRange(t *testing.T) {
 		break
 	}
 }
+
+func TestDeactivateProfile(t *testing.T) {
+	ps := NewMemProfiles()
+	snaps := NewMemSnapshots()
+
+	src := rand.New(rand.NewSource(0))
+	key0, _, err := crypto.GenerateSecp256k1Key(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key1, _, err := crypto.GenerateSecp256k1Key(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := ProfileFromPrivateKey(&Profile{Username: "leaving", Email: "leaving@example.com"}, key0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterProfile(ps, p); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"leaving/a", "leaving/b", "staying/a"} {
+		if err := snaps.Store(key, &Snapshot{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	imposter, err := ProfileFromPrivateKey(&Profile{Username: "leaving"}, key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DeactivateProfile(ps, snaps, imposter); err == nil {
+		t.Error("expected deactivating another user's profile to error")
+	}
+
+	tombstone, err := DeactivateProfile(ps, snaps, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tombstone.Deactivated == nil || tombstone.Email != "" {
+		t.Errorf("expected a tombstone without personal details, got: %#v", tombstone)
+	}
+	if n, _ := snaps.Len(); n != 1 {
+		t.Errorf("expected snapshots of the deactivated profile to be removed, %d remain", n)
+	}
+
+	p, err = ProfileFromPrivateKey(&Profile{Username: "leaving"}, key0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterProfile(ps, p); !errors.Is(err, ErrProfileDeactivated) {
+		t.Errorf("expected registering a deactivated username to fail with ErrProfileDeactivated, got: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook"
+	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/remote"
+)
+
+// Redirect records the rename of a dataset, pointing links to the previous
+// name at the current one
+type Redirect struct {
+	// From & To are dataset aliases like "username/name"
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	InitID    string    `json:"initID"`
+	ProfileID string    `json:"profileID"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Redirects is the interface for working with a set of *Redirect's, keyed
+// by the alias redirected from
+type Redirects interface {
+	// Len returns the number of records in the set
+	Len() (int, error)
+	// Load fetches a redirect by key
+	Load(key string) (value *Redirect, err error)
+	// SortedRange calls an iteration function on each element in key order
+	// until the end of the list is reached or iter returns false
+	SortedRange(iter func(key string, r *Redirect) (kontinue bool, err error)) error
+	// Store adds or replaces an entry
+	Store(key string, value *Redirect) error
+	// Delete removes a redirect from the set at key
+	Delete(key string) error
+}
+
+// PutRedirect records a dataset rename. Redirects always point at the current
+// name: earlier redirects to the previous name are updated, and a redirect
+// away from the new name is dropped, because the name is in use again
+func PutRedirect(rs Redirects, r *Redirect) error {
+	if r == nil || r.From == "" || r.To == "" {
+		return fmt.Errorf("redirect from & to aliases are required")
+	}
+	if r.From == r.To {
+		return nil
+	}
+
+	updated := []*Redirect{}
+	err := rs.SortedRange(func(key string, prev *Redirect) (bool, error) {
+		if prev.To == r.From {
+			moved := *prev
+			moved.To = r.To
+			updated = append(updated, &moved)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, u := range updated {
+		if u.From == r.To {
+			// renamed back to an earlier name
+			if err := rs.Delete(u.From); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := rs.Store(u.From, u); err != nil {
+			return err
+		}
+	}
+
+	if _, err := rs.Load(r.To); err == nil {
+		if err := rs.Delete(r.To); err != nil {
+			return err
+		}
+	}
+	return rs.Store(r.From, r)
+}
+
+// RedirectResolver implements remote.Redirector over a set of redirects
+type RedirectResolver struct {
+	Redirects Redirects
+}
+
+var _ remote.Redirector = (*RedirectResolver)(nil)
+
+// Redirect returns the current alias of a renamed dataset
+func (rr RedirectResolver) Redirect(alias string) (string, error) {
+	r, err := rr.Redirects.Load(alias)
+	if err != nil {
+		return "", err
+	}
+	return r.To, nil
+}
+
+// RenameRedirects configures a remote to record a redirect when a log push
+// renames a dataset, and to redirect resolution of previous names. Snapshots
+// listed under the previous name are removed when snaps is non-nil
+func RenameRedirects(book *logbook.Book, rs Redirects, snaps Snapshots) remote.OptionsFunc {
+	record := func(ctx context.Context, pid profile.ID, ref dsref.Ref) error {
+		l, ok := remote.OplogFromContext(ctx)
+		if !ok || len(l.Logs) != 1 {
+			return nil
+		}
+		initID := l.Logs[0].ID()
+		userLog, err := book.UserDatasetBranchesLog(ctx, initID)
+		if err != nil {
+			// first push of a dataset, a new dataset takes over any redirect
+			// away from its name
+			if _, err := rs.Load(ref.Alias()); err == nil {
+				return rs.Delete(ref.Alias())
+			}
+			return nil
+		}
+		dsLog, err := userLog.Log(initID)
+		if err != nil {
+			return nil
+		}
+
+		prev := dsref.Ref{Username: userLog.Name(), Name: dsLog.Name()}
+		if prev.Alias() == ref.Alias() {
+			return nil
+		}
+		r := &Redirect{
+			From:      prev.Alias(),
+			To:        ref.Alias(),
+			InitID:    initID,
+			ProfileID: pid.String(),
+			Timestamp: nowFunc().UTC(),
+		}
+		if err := PutRedirect(rs, r); err != nil {
+			return err
+		}
+		if snaps != nil {
+			return snaps.Delete(r.From)
+		}
+		return nil
+	}
+
+	return func(o *remote.Options) {
+		o.LogPushFinalCheck = chainHooks(o.LogPushFinalCheck, record)
+		o.Redirector = RedirectResolver{Redirects: rs}
+	}
+}
+
+// MemRedirects is a map of redirects safe for concurrent use
+type MemRedirects struct {
+	sync.RWMutex
+	redirects map[string]*Redirect
+}
+
+var _ Redirects = (*MemRedirects)(nil)
+
+// NewMemRedirects allocates a new *MemRedirects map
+func NewMemRedirects() *MemRedirects {
+	return &MemRedirects{
+		redirects: make(map[string]*Redirect),
+	}
+}
+
+// Len returns the number of records in the map
+func (mr *MemRedirects) Len() (int, error) {
+	mr.RLock()
+	defer mr.RUnlock()
+	return len(mr.redirects), nil
+}
+
+// Load fetches a redirect by key
+func (mr *MemRedirects) Load(key string) (*Redirect, error) {
+	mr.RLock()
+	defer mr.RUnlock()
+	r, ok := mr.redirects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return r, nil
+}
+
+// SortedRange calls iter on each redirect in key order
+func (mr *MemRedirects) SortedRange(iter func(key string, r *Redirect) (kontinue bool, err error)) error {
+	mr.RLock()
+	defer mr.RUnlock()
+	keys := make([]string, 0, len(mr.redirects))
+	for key := range mr.redirects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kontinue, err := iter(key, mr.redirects[key])
+		if err != nil {
+			return err
+		}
+		if !kontinue {
+			break
+		}
+	}
+	return nil
+}
+
+// Store adds or replaces a redirect
+func (mr *MemRedirects) Store(key string, value *Redirect) error {
+	mr.Lock()
+	mr.redirects[key] = value
+	mr.Unlock()
+	return nil
+}
+
+// Delete removes a redirect at key
+func (mr *MemRedirects) Delete(key string) error {
+	mr.Lock()
+	delete(mr.redirects, key)
+	mr.Unlock()
+	return nil
+}
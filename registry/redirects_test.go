@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"testing"
+)
+
+func TestPutRedirect(t *testing.T) {
+	rs := NewMemRedirects()
+	rr := RedirectResolver{Redirects: rs}
+
+	if err := PutRedirect(rs, &Redirect{From: "alice/rain"}); err == nil {
+		t.Error("expected redirect without a destination to error")
+	}
+
+	if err := PutRedirect(rs, &Redirect{From: "alice/rain", To: "alice/rainfall"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := PutRedirect(rs, &Redirect{From: "alice/rainfall", To: "alice/monthly_rainfall"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// chains of renames point at the current name
+	for _, from := range []string{"alice/rain", "alice/rainfall"} {
+		to, err := rr.Redirect(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if to != "alice/monthly_rainfall" {
+			t.Errorf("redirect from %q mismatch. want: %q, got: %q", from, "alice/monthly_rainfall", to)
+		}
+	}
+
+	// renaming back to an earlier name drops redirects away from that name
+	if err := PutRedirect(rs, &Redirect{From: "alice/monthly_rainfall", To: "alice/rain"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rr.Redirect("alice/rain"); err != ErrNotFound {
+		t.Errorf("expected current name to have no redirect, got: %v", err)
+	}
+	to, err := rr.Redirect("alice/rainfall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to != "alice/rain" {
+		t.Errorf("redirect mismatch. want: %q, got: %q", "alice/rain", to)
+	}
+	if n, _ := rs.Len(); n != 2 {
+		t.Errorf("expected 2 redirects, got: %d", n)
+	}
+}
@@ -32,9 +32,21 @@ type Client struct {
 type Config struct {
 	// Location is the URL base to call to
 	Location string
+	// Token authenticates requests to the registry when set, sent as a bearer
+	// token in the Authorization header
+	Token string
 }
 
 // NewClient creates a registry from a provided Registry configuration
 func NewClient(cfg *Config) *Client {
 	return &Client{cfg, HTTPClient}
 }
+
+// do sends an HTTP request to the registry, adding credentials if the client
+// has any
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+	return c.httpClient.Do(req)
+}
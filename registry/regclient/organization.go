@@ -0,0 +1,131 @@
+package regclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qri/registry"
+)
+
+// GetOrganization fetches an organization by username
+func (c *Client) GetOrganization(username string) (*registry.Organization, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+	o := &registry.Organization{}
+	path := fmt.Sprintf("/registry/org?username=%s", url.QueryEscape(username))
+	if err := c.doJSONOrgReq("GET", path, nil, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// CreateOrganization registers an organization, making the profile that
+// signs the request its owner
+func (c *Client) CreateOrganization(o *registry.Organization, requester *registry.Profile, pk crypto.PrivKey) (*registry.Organization, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+	requester, err := registry.ProfileFromPrivateKey(requester, pk)
+	if err != nil {
+		return nil, err
+	}
+	req := &registry.OrganizationRequest{Organization: o, Requester: requester}
+	res := &registry.Organization{}
+	if err := c.doJSONOrgReq("POST", "/registry/org", req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// AddOrganizationMember adds a profile to an organization, or changes the
+// role of an existing member. The requester must be an organization owner
+func (c *Client) AddOrganizationMember(org, member, role string, requester *registry.Profile, pk crypto.PrivKey) (*registry.Organization, error) {
+	return c.doMembershipReq("POST", org, member, role, requester, pk)
+}
+
+// RemoveOrganizationMember removes a profile from an organization
+func (c *Client) RemoveOrganizationMember(org, member string, requester *registry.Profile, pk crypto.PrivKey) (*registry.Organization, error) {
+	return c.doMembershipReq("DELETE", org, member, "", requester, pk)
+}
+
+func (c *Client) doMembershipReq(method, org, member, role string, requester *registry.Profile, pk crypto.PrivKey) (*registry.Organization, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+	requester, err := registry.ProfileFromPrivateKey(requester, pk)
+	if err != nil {
+		return nil, err
+	}
+	req := &registry.MembershipRequest{
+		Org:       org,
+		Member:    member,
+		Role:      role,
+		Requester: requester,
+	}
+	res := &registry.Organization{}
+	if err := c.doJSONOrgReq(method, "/registry/org/members", req, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// doJSONOrgReq is a common wrapper for organization endpoint requests
+func (c Client) doJSONOrgReq(method, path string, input, output interface{}) error {
+	if c.cfg.Location == "" {
+		return ErrNoRegistry
+	}
+
+	var body *bytes.Reader
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, c.cfg.Location+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such host") {
+			return ErrNoRegistry
+		}
+		return err
+	}
+	defer res.Body.Close()
+
+	env := struct {
+		Data interface{}
+		Meta struct {
+			Error  string
+			Status string
+			Code   int
+		}
+	}{Data: output}
+
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		return err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return registry.ErrNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		if strings.Contains(env.Meta.Error, "taken") {
+			return registry.ErrUsernameTaken
+		}
+		return fmt.Errorf("registry: %s", env.Meta.Error)
+	}
+	return nil
+}
@@ -119,6 +119,24 @@ func (c *Client) DeleteProfile(p *registry.Profile, privKey crypto.PrivKey) erro
 	return err
 }
 
+// DeactivateProfile asks the registry to replace a profile with a tombstone
+// that keeps the username reserved, removing the profile's dataset listings
+func (c *Client) DeactivateProfile(p *registry.Profile, privKey crypto.PrivKey) (*registry.Profile, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+
+	p, err := registry.ProfileFromPrivateKey(p, privKey)
+	if err != nil {
+		return nil, err
+	}
+	res := &registry.Profile{}
+	if err := c.doJSONOrgReq("POST", "/registry/profile/deactivate", p, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 // doJSONProfileReq is a common wrapper for /profile endpoint requests
 func (c Client) doJSONProfileReq(method string, p *registry.Profile) (*registry.Profile, error) {
 	if c.cfg.Location == "" {
@@ -135,7 +153,7 @@ func (c Client) doJSONProfileReq(method string, p *registry.Profile) (*registry.
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	res, err := c.httpClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		if strings.Contains(err.Error(), "no such host") {
 			return nil, ErrNoRegistry
@@ -202,7 +220,7 @@ func (c Client) doJSONRegistryRequest(method, url string, input interface{}, out
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	res, err := c.httpClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		if strings.Contains(err.Error(), "no such host") {
 			return ErrNoRegistry
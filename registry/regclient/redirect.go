@@ -0,0 +1,22 @@
+package regclient
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/qri-io/qri/registry"
+)
+
+// GetRedirect fetches the redirect the registry keeps for the previous alias
+// of a renamed dataset, like "username/name"
+func (c *Client) GetRedirect(alias string) (*registry.Redirect, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+	r := &registry.Redirect{}
+	path := fmt.Sprintf("/registry/redirect?ref=%s", url.QueryEscape(alias))
+	if err := c.doJSONOrgReq("GET", path, nil, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,51 @@
+package regclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qri-io/qri/registry"
+	"github.com/qri-io/qri/registry/regserver/handlers"
+)
+
+func TestRedirectRequests(t *testing.T) {
+	reg := registry.Registry{
+		Snapshots: registry.NewMemSnapshots(),
+		Redirects: registry.NewMemRedirects(),
+	}
+	if err := registry.PutRedirect(reg.Redirects, &registry.Redirect{From: "alice/rain", To: "alice/rainfall"}); err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(handlers.NewRoutes(reg))
+	defer s.Close()
+	c := NewClient(&Config{Location: s.URL})
+
+	got, err := c.GetRedirect("alice/rain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.To != "alice/rainfall" {
+		t.Errorf("redirect destination mismatch. want: %q, got: %q", "alice/rainfall", got.To)
+	}
+	if _, err := c.GetRedirect("alice/rainfall"); err != registry.ErrNotFound {
+		t.Errorf("expected missing redirect to return ErrNotFound, got: %v", err)
+	}
+
+	cli := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := cli.Get(s.URL + "/alice/rain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMovedPermanently {
+		t.Errorf("expected previous dataset URL to respond with status %d, got: %d", http.StatusMovedPermanently, res.StatusCode)
+	}
+	if loc := res.Header.Get("Location"); loc != "/alice/rainfall" {
+		t.Errorf("location mismatch. want: %q, got: %q", "/alice/rainfall", loc)
+	}
+}
@@ -97,7 +97,7 @@ func (c Client) doJSONSearchReq(method string, s *registry.SearchParams) (result
 		return nil, err
 	}
 
-	res, err := c.httpClient.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		if strings.Contains(err.Error(), "no such host") {
 			return nil, ErrNoRegistry
@@ -0,0 +1,42 @@
+package regclient
+
+import (
+	"fmt"
+	"net/url"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/qri-io/qri/registry"
+)
+
+// PutSnapshot signs & uploads a dataset snapshot, replacing the registry's
+// listing for the dataset
+func (c *Client) PutSnapshot(s *registry.Snapshot, pk crypto.PrivKey) (*registry.Snapshot, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+	if err := s.Sign(pk); err != nil {
+		return nil, err
+	}
+	res := &registry.Snapshot{}
+	if err := c.doJSONOrgReq("POST", "/registry/snapshot", s, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetSnapshot fetches the snapshot the registry lists for a dataset alias
+// like "username/name", checking the snapshot signature
+func (c *Client) GetSnapshot(alias string) (*registry.Snapshot, error) {
+	if c == nil {
+		return nil, registry.ErrNoRegistry
+	}
+	s := &registry.Snapshot{}
+	path := fmt.Sprintf("/registry/snapshot?ref=%s", url.QueryEscape(alias))
+	if err := c.doJSONOrgReq("GET", path, nil, s); err != nil {
+		return nil, err
+	}
+	if err := s.Verify(); err != nil {
+		return nil, fmt.Errorf("registry snapshot of %q: %w", alias, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,58 @@
+package regclient
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+	"github.com/qri-io/qri/registry"
+	"github.com/qri-io/qri/registry/regserver/handlers"
+)
+
+func TestSnapshotRequests(t *testing.T) {
+	reg := registry.Registry{
+		Profiles:  registry.NewMemProfiles(),
+		Snapshots: registry.NewMemSnapshots(),
+	}
+	s := httptest.NewServer(handlers.NewRoutes(reg))
+	defer s.Close()
+	c := NewClient(&Config{Location: s.URL})
+
+	pk := testkeys.GetKeyData(0).PrivKey
+	pro, err := registry.ProfileFromPrivateKey(&registry.Profile{Username: "alice"}, pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap := registry.NewSnapshot(&dataset.Dataset{
+		Peername:  "alice",
+		Name:      "rainfall",
+		ProfileID: pro.ProfileID,
+		Path:      "/ipfs/QmHead",
+		Meta:      &dataset.Meta{Title: "Monthly Rainfall"},
+	})
+	if _, err := c.PutSnapshot(snap, pk); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.GetSnapshot("alice/rainfall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/ipfs/QmHead" || got.Meta == nil || got.Meta.Title != "Monthly Rainfall" {
+		t.Errorf("unexpected snapshot: %#v", got)
+	}
+
+	if _, err := c.GetSnapshot("alice/missing"); err != registry.ErrNotFound {
+		t.Errorf("expected missing snapshot to return ErrNotFound, got: %v", err)
+	}
+
+	res, err := c.Search(&SearchParams{Query: "rainfall"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 {
+		t.Errorf("expected registry without a search index to search snapshots, got %d results", len(res))
+	}
+}
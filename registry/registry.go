@@ -30,10 +30,13 @@ import (
 
 // Registry a collection of interfaces that together form a registry service
 type Registry struct {
-	Remote   *remote.Remote
-	Profiles Profiles
-	Search   Searchable
-	Indexer  Indexer
+	Remote        *remote.Remote
+	Profiles      Profiles
+	Organizations Organizations
+	Snapshots     Snapshots
+	Redirects     Redirects
+	Search        Searchable
+	Indexer       Indexer
 }
 
 var (
@@ -43,4 +46,6 @@ var (
 	ErrNoRegistry = fmt.Errorf("no registry is configured")
 	// ErrNotFound represents a missing record
 	ErrNotFound = fmt.Errorf("not found")
+	// ErrProfileDeactivated is for when a profile has been deactivated
+	ErrProfileDeactivated = fmt.Errorf("profile is deactivated")
 )
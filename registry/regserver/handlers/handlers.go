@@ -56,12 +56,34 @@ func NewRoutes(reg registry.Registry, opts ...func(o *RouteOptions)) *http.Serve
 
 	if ps := reg.Profiles; ps != nil {
 		mux.HandleFunc("/registry/profile", logReq(NewProfileHandler(ps)))
+		mux.HandleFunc("/registry/profile/deactivate", logReq(NewDeactivateProfileHandler(ps, reg.Snapshots)))
 		mux.HandleFunc("/registry/profiles", pro.ProtectMethods("POST")(logReq(NewProfilesHandler(ps))))
 		mux.HandleFunc("/registry/provekey", NewProveKeyHandler(ps))
 	}
 
-	if s := reg.Search; s != nil {
-		mux.HandleFunc("/registry/search", logReq(NewSearchHandler(s)))
+	if orgs := reg.Organizations; orgs != nil && reg.Profiles != nil {
+		mux.HandleFunc("/registry/orgs", logReq(NewOrganizationsHandler(orgs)))
+		mux.HandleFunc("/registry/org", logReq(NewOrganizationHandler(orgs, reg.Profiles)))
+		mux.HandleFunc("/registry/org/members", logReq(NewMembersHandler(orgs, reg.Profiles)))
+	}
+
+	if snaps := reg.Snapshots; snaps != nil {
+		mux.HandleFunc("/registry/snapshot", logReq(NewSnapshotHandler(snaps, reg.Profiles, reg.Organizations)))
+	}
+
+	if rs := reg.Redirects; rs != nil {
+		mux.HandleFunc("/registry/redirect", logReq(NewRedirectHandler(rs)))
+		// vanity dataset URLs
+		mux.HandleFunc("/", logReq(NewDatasetURLHandler(reg.Snapshots, rs)))
+	}
+
+	search := reg.Search
+	if search == nil && reg.Snapshots != nil {
+		// without a search index, search published snapshots
+		search = registry.SnapshotSearch{Snapshots: reg.Snapshots}
+	}
+	if search != nil {
+		mux.HandleFunc("/registry/search", logReq(NewSearchHandler(search)))
 	}
 
 	return mux
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	apiutil "github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/registry"
+)
+
+// NewOrganizationsHandler creates a handler that lists organizations
+func NewOrganizationsHandler(orgs registry.Organizations) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			apiutil.NotFoundHandler(w, r)
+			return
+		}
+
+		res := []*registry.Organization{}
+		err := orgs.SortedRange(func(key string, o *registry.Organization) (bool, error) {
+			res = append(res, o)
+			return true, nil
+		})
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+			return
+		}
+		apiutil.WriteResponse(w, res)
+	}
+}
+
+// NewOrganizationHandler creates a handler that fetches, creates & deletes
+// organizations. GET requests take a username query param, POST and DELETE
+// requests require a signed registry.OrganizationRequest body
+func NewOrganizationHandler(orgs registry.Organizations, profiles registry.Profiles) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			o, err := orgs.Load(r.FormValue("username"))
+			if err != nil {
+				apiutil.NotFoundHandler(w, r)
+				return
+			}
+			apiutil.WriteResponse(w, o)
+		case "POST", "DELETE":
+			req := &registry.OrganizationRequest{}
+			if !decodeJSONBody(w, r, req) {
+				return
+			}
+			if r.Method == "POST" {
+				o, err := registry.CreateOrganization(orgs, profiles, req)
+				if err != nil {
+					writeOrgErr(w, err)
+					return
+				}
+				apiutil.WriteResponse(w, o)
+				return
+			}
+			if err := registry.DeleteOrganization(orgs, profiles, req); err != nil {
+				writeOrgErr(w, err)
+				return
+			}
+			apiutil.WriteResponse(w, req.Organization)
+		default:
+			apiutil.NotFoundHandler(w, r)
+		}
+	}
+}
+
+// NewMembersHandler creates a handler that manages organization members.
+// POST adds a member, DELETE removes one. Both require a signed
+// registry.MembershipRequest body
+func NewMembersHandler(orgs registry.Organizations, profiles registry.Profiles) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var fn func(registry.Organizations, registry.Profiles, *registry.MembershipRequest) (*registry.Organization, error)
+		switch r.Method {
+		case "POST":
+			fn = registry.AddMember
+		case "DELETE":
+			fn = registry.RemoveMember
+		default:
+			apiutil.NotFoundHandler(w, r)
+			return
+		}
+
+		req := &registry.MembershipRequest{}
+		if !decodeJSONBody(w, r, req) {
+			return
+		}
+		o, err := fn(orgs, profiles, req)
+		if err != nil {
+			writeOrgErr(w, err)
+			return
+		}
+		apiutil.WriteResponse(w, o)
+	}
+}
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Header.Get("Content-Type") != "application/json" {
+		apiutil.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("Content-Type must be application/json"))
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+func writeOrgErr(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, registry.ErrNotOrgOwner):
+		apiutil.WriteErrResponse(w, http.StatusForbidden, err)
+	case errors.Is(err, registry.ErrNotFound):
+		apiutil.WriteErrResponse(w, http.StatusNotFound, err)
+	default:
+		apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -120,6 +121,32 @@ func NewProfileHandler(profiles registry.Profiles) http.HandlerFunc {
 	}
 }
 
+// NewDeactivateProfileHandler creates a handler that replaces a profile with
+// a tombstone, removing the dataset snapshots it published. POST requests
+// require a profile body signed by the profile key
+func NewDeactivateProfileHandler(profiles registry.Profiles, snaps registry.Snapshots) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			apiutil.NotFoundHandler(w, r)
+			return
+		}
+		p := &registry.Profile{}
+		if !decodeJSONBody(w, r, p) {
+			return
+		}
+		tombstone, err := registry.DeactivateProfile(profiles, snaps, p)
+		if err != nil {
+			if errors.Is(err, registry.ErrNotFound) {
+				apiutil.NotFoundHandler(w, r)
+				return
+			}
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		apiutil.WriteResponse(w, tombstone)
+	}
+}
+
 // NewProveKeyHandler creates a handler that implements provekey
 func NewProveKeyHandler(profiles registry.Profiles) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
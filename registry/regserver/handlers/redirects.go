@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	apiutil "github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/registry"
+)
+
+// NewRedirectHandler creates a handler that serves the redirects of renamed
+// datasets. GET requests take a ref query param in the form "username/name"
+func NewRedirectHandler(rs registry.Redirects) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			rd, err := rs.Load(r.FormValue("ref"))
+			if err != nil {
+				apiutil.NotFoundHandler(w, r)
+				return
+			}
+			apiutil.WriteResponse(w, rd)
+		default:
+			apiutil.NotFoundHandler(w, r)
+		}
+	}
+}
+
+// NewDatasetURLHandler creates a handler for vanity dataset URLs in the form
+// "/username/name". Listed datasets respond with their snapshot, previous
+// names of renamed datasets redirect permanently to the current name
+func NewDatasetURLHandler(snaps registry.Snapshots, rs registry.Redirects) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			apiutil.NotFoundHandler(w, r)
+			return
+		}
+		alias := strings.Trim(r.URL.Path, "/")
+		if strings.Count(alias, "/") != 1 {
+			apiutil.NotFoundHandler(w, r)
+			return
+		}
+
+		if snaps != nil {
+			if s, err := snaps.Load(alias); err == nil {
+				apiutil.WriteResponse(w, s)
+				return
+			}
+		}
+		rd, err := rs.Load(alias)
+		if err != nil {
+			apiutil.NotFoundHandler(w, r)
+			return
+		}
+		http.Redirect(w, r, "/"+rd.To, http.StatusMovedPermanently)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	apiutil "github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/registry"
+)
+
+// NewSnapshotHandler creates a handler that serves & accepts dataset
+// snapshots. GET requests take a ref query param in the form
+// "username/name", POST requests require a signed registry.Snapshot body
+func NewSnapshotHandler(snaps registry.Snapshots, profiles registry.Profiles, orgs registry.Organizations) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			s, err := snaps.Load(r.FormValue("ref"))
+			if err != nil {
+				apiutil.NotFoundHandler(w, r)
+				return
+			}
+			apiutil.WriteResponse(w, s)
+		case "POST":
+			s := &registry.Snapshot{}
+			if !decodeJSONBody(w, r, s) {
+				return
+			}
+			if err := registry.PutSnapshot(snaps, profiles, orgs, s); err != nil {
+				if errors.Is(err, registry.ErrNamespaceForbidden) {
+					apiutil.WriteErrResponse(w, http.StatusForbidden, err)
+					return
+				}
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+				return
+			}
+			apiutil.WriteResponse(w, s)
+		default:
+			apiutil.NotFoundHandler(w, r)
+		}
+	}
+}
@@ -42,8 +42,11 @@ func NewMockServerRegistry(reg registry.Registry) (*regclient.Client, *httptest.
 // NewMemRegistry creates a new in-memory registry
 func NewMemRegistry(rem *remote.Remote) registry.Registry {
 	return registry.Registry{
-		Remote:   rem,
-		Profiles: registry.NewMemProfiles(),
+		Remote:        rem,
+		Profiles:      registry.NewMemProfiles(),
+		Organizations: registry.NewMemOrganizations(),
+		Snapshots:     registry.NewMemSnapshots(),
+		Redirects:     registry.NewMemRedirects(),
 	}
 }
 
@@ -84,15 +87,25 @@ func NewTempRegistry(ctx context.Context, peername, tmpDirPrefix string, g key.C
 		AllowRemoves:     true,
 	}
 
-	rem, err := remote.NewRemote(node, remoteCfg, node.Repo.Logbook())
+	profiles := registry.NewMemProfiles()
+	orgs := registry.NewMemOrganizations()
+	snaps := registry.NewMemSnapshots()
+	redirects := registry.NewMemRedirects()
+	rem, err := remote.NewRemote(node, remoteCfg, node.Repo.Logbook(),
+		registry.NamespacePushChecks(profiles, orgs),
+		registry.RenameRedirects(node.Repo.Logbook(), redirects, snaps),
+	)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	reg := &registry.Registry{
-		Remote:   rem,
-		Profiles: registry.NewMemProfiles(),
-		Search:   MockRepoSearch{Repo: r},
+		Remote:        rem,
+		Profiles:      profiles,
+		Organizations: orgs,
+		Snapshots:     snaps,
+		Redirects:     redirects,
+		Search:        MockRepoSearch{Repo: r},
 	}
 
 	return reg, teardown, nil
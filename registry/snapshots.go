@@ -0,0 +1,300 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/multiformats/go-multihash"
+	"github.com/qri-io/dataset"
+)
+
+// Snapshot is a signed summary of the latest version of a dataset published
+// to a registry. Snapshots let a registry serve search & previews without
+// pulling dataset blocks, and let clients check a registry's listing matches
+// what the dataset author published
+type Snapshot struct {
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	ProfileID string    `json:"profileID"`
+	Path      string    `json:"path"`
+	Timestamp time.Time `json:"timestamp"`
+	// Meta is the complete meta component of the version
+	Meta *dataset.Meta `json:"meta,omitempty"`
+	// Structure summarizes the version's structure component
+	Structure *StructureSummary `json:"structure,omitempty"`
+	// StatsDigest is the content address of the version's stats component
+	StatsDigest string `json:"statsDigest,omitempty"`
+
+	// PublicKey & Signature are base64-encoded. The signature covers every
+	// other field
+	PublicKey string `json:"publicKey"`
+	Signature string `json:"signature"`
+}
+
+// StructureSummary is the subset of a structure component shown in listings
+type StructureSummary struct {
+	Format   string `json:"format"`
+	Entries  int    `json:"entries"`
+	Length   int    `json:"length"`
+	Depth    int    `json:"depth"`
+	ErrCount int    `json:"errCount"`
+}
+
+// NewSnapshot creates an unsigned snapshot of a dataset version
+func NewSnapshot(ds *dataset.Dataset) *Snapshot {
+	s := &Snapshot{
+		Username:  ds.Peername,
+		Name:      ds.Name,
+		ProfileID: ds.ProfileID,
+		Path:      ds.Path,
+		Timestamp: nowFunc().UTC(),
+		Meta:      ds.Meta,
+	}
+	if st := ds.Structure; st != nil {
+		s.Structure = &StructureSummary{
+			Format:   st.Format,
+			Entries:  st.Entries,
+			Length:   st.Length,
+			Depth:    st.Depth,
+			ErrCount: st.ErrCount,
+		}
+	}
+	if ds.Stats != nil {
+		s.StatsDigest = ds.Stats.Path
+	}
+	return s
+}
+
+// Alias is the human-friendly reference of the snapshot dataset, like
+// "username/name"
+func (s *Snapshot) Alias() string {
+	return fmt.Sprintf("%s/%s", s.Username, s.Name)
+}
+
+// SigningBytes is the data a snapshot signature covers
+func (s *Snapshot) SigningBytes() ([]byte, error) {
+	unsigned := *s
+	unsigned.PublicKey = ""
+	unsigned.Signature = ""
+	return json.Marshal(unsigned)
+}
+
+// Sign sets the public key & signature of a snapshot. The signing key must
+// belong to the snapshot profile
+func (s *Snapshot) Sign(pk crypto.PrivKey) error {
+	data, err := s.SigningBytes()
+	if err != nil {
+		return err
+	}
+	sigbytes, err := pk.Sign(data)
+	if err != nil {
+		return fmt.Errorf("error signing %s", err.Error())
+	}
+	pubkeybytes, err := pk.GetPublic().Bytes()
+	if err != nil {
+		return fmt.Errorf("error getting pubkey bytes: %s", err.Error())
+	}
+	s.PublicKey = base64.StdEncoding.EncodeToString(pubkeybytes)
+	s.Signature = base64.StdEncoding.EncodeToString(sigbytes)
+	return nil
+}
+
+// Verify checks a snapshot is signed by the key of the profile it names
+func (s *Snapshot) Verify() error {
+	if s.Username == "" || s.Name == "" {
+		return fmt.Errorf("snapshot username & name are required")
+	}
+	if s.Path == "" {
+		return fmt.Errorf("snapshot path is required")
+	}
+	pkbytes, err := base64.StdEncoding.DecodeString(s.PublicKey)
+	if err != nil {
+		return fmt.Errorf("publickey base64 encoding: %s", err.Error())
+	}
+	mh, err := multihash.Sum(pkbytes, multihash.SHA2_256, 32)
+	if err != nil {
+		return fmt.Errorf("error summing pubkey: %s", err.Error())
+	}
+	if mh.B58String() != s.ProfileID {
+		return fmt.Errorf("snapshot public key doesn't match profile %q", s.ProfileID)
+	}
+	data, err := s.SigningBytes()
+	if err != nil {
+		return err
+	}
+	return verify(s.PublicKey, s.Signature, data)
+}
+
+// Snapshots is the interface for working with a set of *Snapshot's, keyed
+// by dataset alias
+type Snapshots interface {
+	// Len returns the number of records in the set
+	Len() (int, error)
+	// Load fetches a snapshot by key
+	Load(key string) (value *Snapshot, err error)
+	// SortedRange calls an iteration function on each element in key order
+	// until the end of the list is reached or iter returns false
+	SortedRange(iter func(key string, s *Snapshot) (kontinue bool, err error)) error
+	// Store adds or replaces an entry
+	Store(key string, value *Snapshot) error
+	// Delete removes a snapshot from the set at key
+	Delete(key string) error
+}
+
+// PutSnapshot stores a snapshot after checking its signature, and that the
+// signing profile may publish to the snapshot namespace. Snapshots older
+// than the stored snapshot for a dataset are rejected
+func PutSnapshot(snaps Snapshots, profiles Profiles, orgs Organizations, s *Snapshot) error {
+	if s == nil {
+		return fmt.Errorf("snapshot is required")
+	}
+	if err := s.Verify(); err != nil {
+		return err
+	}
+	if err := CanPush(profiles, orgs, s.ProfileID, s.Username); err != nil {
+		return err
+	}
+	if prev, err := snaps.Load(s.Alias()); err == nil && prev.Timestamp.After(s.Timestamp) {
+		return fmt.Errorf("a newer snapshot of %q exists", s.Alias())
+	}
+	return snaps.Store(s.Alias(), s)
+}
+
+// SnapshotSearch implements Searchable over a set of snapshots, matching
+// search terms against dataset names, titles, descriptions & keywords
+type SnapshotSearch struct {
+	Snapshots Snapshots
+}
+
+var _ Searchable = (*SnapshotSearch)(nil)
+
+// Search matches snapshots that contain the query term
+func (ss SnapshotSearch) Search(p SearchParams) ([]*dataset.Dataset, error) {
+	q := strings.ToLower(p.Q)
+	results := []*dataset.Dataset{}
+	skipped := 0
+	err := ss.Snapshots.SortedRange(func(key string, s *Snapshot) (bool, error) {
+		if !s.matches(q) {
+			return true, nil
+		}
+		if skipped < p.Offset {
+			skipped++
+			return true, nil
+		}
+		results = append(results, s.Dataset())
+		return p.Limit <= 0 || len(results) < p.Limit, nil
+	})
+	return results, err
+}
+
+func (s *Snapshot) matches(q string) bool {
+	text := []string{s.Username, s.Name}
+	if s.Meta != nil {
+		text = append(text, s.Meta.Title, s.Meta.Description)
+		text = append(text, s.Meta.Keywords...)
+	}
+	for _, t := range text {
+		if strings.Contains(strings.ToLower(t), q) {
+			return true
+		}
+	}
+	return false
+}
+
+// Dataset converts a snapshot into a dataset preview
+func (s *Snapshot) Dataset() *dataset.Dataset {
+	ds := &dataset.Dataset{
+		Peername:  s.Username,
+		Name:      s.Name,
+		ProfileID: s.ProfileID,
+		Path:      s.Path,
+		Meta:      s.Meta,
+	}
+	if st := s.Structure; st != nil {
+		ds.Structure = &dataset.Structure{
+			Format:   st.Format,
+			Entries:  st.Entries,
+			Length:   st.Length,
+			Depth:    st.Depth,
+			ErrCount: st.ErrCount,
+		}
+	}
+	return ds
+}
+
+// MemSnapshots is a map of snapshots safe for concurrent use
+type MemSnapshots struct {
+	sync.RWMutex
+	snaps map[string]*Snapshot
+}
+
+var _ Snapshots = (*MemSnapshots)(nil)
+
+// NewMemSnapshots allocates a new *MemSnapshots map
+func NewMemSnapshots() *MemSnapshots {
+	return &MemSnapshots{
+		snaps: make(map[string]*Snapshot),
+	}
+}
+
+// Len returns the number of records in the map
+func (ms *MemSnapshots) Len() (int, error) {
+	ms.RLock()
+	defer ms.RUnlock()
+	return len(ms.snaps), nil
+}
+
+// Load fetches a snapshot by key
+func (ms *MemSnapshots) Load(key string) (*Snapshot, error) {
+	ms.RLock()
+	defer ms.RUnlock()
+	s, ok := ms.snaps[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s, nil
+}
+
+// SortedRange calls iter on each snapshot in key order
+func (ms *MemSnapshots) SortedRange(iter func(key string, s *Snapshot) (kontinue bool, err error)) error {
+	ms.RLock()
+	defer ms.RUnlock()
+	keys := make([]string, 0, len(ms.snaps))
+	for key := range ms.snaps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kontinue, err := iter(key, ms.snaps[key])
+		if err != nil {
+			return err
+		}
+		if !kontinue {
+			break
+		}
+	}
+	return nil
+}
+
+// Store adds or replaces a snapshot
+func (ms *MemSnapshots) Store(key string, value *Snapshot) error {
+	ms.Lock()
+	ms.snaps[key] = value
+	ms.Unlock()
+	return nil
+}
+
+// Delete removes a snapshot at key
+func (ms *MemSnapshots) Delete(key string) error {
+	ms.Lock()
+	delete(ms.snaps, key)
+	ms.Unlock()
+	return nil
+}
@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+)
+
+func TestSnapshots(t *testing.T) {
+	profiles := NewMemProfiles()
+	snaps := NewMemSnapshots()
+
+	kd := testkeys.GetKeyData(0)
+	alice, err := ProfileFromPrivateKey(&Profile{Username: "alice"}, kd.PrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterProfile(profiles, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	ds := &dataset.Dataset{
+		Peername:  "alice",
+		Name:      "rainfall",
+		ProfileID: alice.ProfileID,
+		Path:      "/ipfs/QmHead",
+		Meta:      &dataset.Meta{Title: "Monthly Rainfall", Keywords: []string{"weather"}},
+		Structure: &dataset.Structure{Format: "csv", Entries: 12, Length: 240},
+		Stats:     &dataset.Stats{Path: "/ipfs/QmStats"},
+	}
+	s := NewSnapshot(ds)
+	if s.StatsDigest != "/ipfs/QmStats" {
+		t.Errorf("stats digest mismatch. got %q", s.StatsDigest)
+	}
+
+	if err := PutSnapshot(snaps, profiles, nil, s); err == nil {
+		t.Error("expected unsigned snapshot to fail")
+	}
+	if err := s.Sign(kd.PrivKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := PutSnapshot(snaps, profiles, nil, s); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := *s
+	tampered.Path = "/ipfs/QmOther"
+	if err := tampered.Verify(); err == nil {
+		t.Error("expected tampered snapshot to fail verification")
+	}
+
+	// another key can't publish to alice's namespace
+	bobKey := testkeys.GetKeyData(1)
+	bob, _ := ProfileFromPrivateKey(&Profile{Username: "bob"}, bobKey.PrivKey)
+	forged := NewSnapshot(ds)
+	forged.ProfileID = bob.ProfileID
+	if err := forged.Sign(bobKey.PrivKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := PutSnapshot(snaps, profiles, nil, forged); !errors.Is(err, ErrNamespaceForbidden) {
+		t.Errorf("expected snapshot from another profile to fail with ErrNamespaceForbidden, got: %v", err)
+	}
+
+	stale := NewSnapshot(ds)
+	stale.Timestamp = s.Timestamp.Add(-time.Hour)
+	if err := stale.Sign(kd.PrivKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := PutSnapshot(snaps, profiles, nil, stale); err == nil {
+		t.Error("expected snapshot older than the stored snapshot to fail")
+	}
+
+	results, err := SnapshotSearch{Snapshots: snaps}.Search(SearchParams{Q: "weather"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Path != "/ipfs/QmHead" || results[0].Structure.Entries != 12 {
+		t.Errorf("unexpected search results: %v", results)
+	}
+	if results, _ = (SnapshotSearch{Snapshots: snaps}).Search(SearchParams{Q: "snow"}); len(results) != 0 {
+		t.Errorf("expected no results, got: %d", len(results))
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/qri-io/qri/base"
 	"github.com/qri-io/qri/base/dsfs"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/logbook"
 	"github.com/qri-io/qri/repo"
 )
 
@@ -73,6 +74,10 @@ type Previews interface {
 type LocalPreviews struct {
 	fs            qfs.Filesystem
 	localResolver dsref.Resolver
+	// book is optional, used to add lifecycle state to previews
+	book *logbook.Book
+	// heads is optional, previews versions pushed in metadata-only mode
+	heads *HeadStore
 }
 
 // assert at compile time that LocalPreviews implements the Previews interface
@@ -89,11 +94,22 @@ func (rp LocalPreviews) Preview(ctx context.Context, _, refStr string) (*dataset
 		return nil, err
 	}
 
-	ds, err := dsfs.LoadDataset(ctx, rp.fs, ref.Path)
+	ds, unavailable, err := dsfs.LoadDatasetPartial(ctx, rp.fs, ref.Path)
 	if err != nil {
+		// versions pushed metadata-only preview without body data
+		if h, headErr := rp.heads.Get(ref.Path); headErr == nil {
+			pview := &dataset.Dataset{}
+			pview.Assign(h.Dataset)
+			pview.Name = ref.Name
+			pview.Peername = ref.Username
+			return pview, nil
+		}
 		log.Errorf("remote.Preview loading dataset: %w", err.Error())
 		return nil, err
 	}
+	for _, e := range unavailable {
+		log.Debugw("remote.Preview component unavailable", "ref", ref, "component", e.Component, "err", e.Err)
+	}
 
 	ds.Name = ref.Name
 	ds.Peername = ref.Username
@@ -103,7 +119,58 @@ func (rp LocalPreviews) Preview(ctx context.Context, _, refStr string) (*dataset
 		return nil, err
 	}
 
-	return preview.Create(ctx, ds)
+	pview, err := preview.Create(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.addLifecycle(ctx, ref, pview); err != nil {
+		log.Debugw("remote.Preview adding lifecycle", "ref", ref, "err", err)
+	}
+	if err := renderPreviewReadme(ctx, pview); err != nil {
+		log.Debugw("remote.Preview rendering readme", "ref", ref, "err", err)
+	}
+	return pview, nil
+}
+
+// renderPreviewReadme converts html & notebook readmes in a preview to
+// sanitized html, so previews never carry unsafe markup or notebook JSON.
+// markdown readmes are left for clients to render
+func renderPreviewReadme(ctx context.Context, pview *dataset.Dataset) error {
+	rm := pview.Readme
+	if rm == nil || len(rm.ScriptBytes) == 0 || rm.Format == "" || rm.Format == "md" {
+		return nil
+	}
+	f := qfs.NewMemfileBytes("readme."+rm.Format, rm.ScriptBytes)
+	rendered, err := base.RenderReadmeFormat(ctx, f, rm.Format)
+	if err != nil {
+		return err
+	}
+	rm.Format = "html"
+	rm.ScriptBytes = []byte(rendered)
+	return nil
+}
+
+// addLifecycle records the lifecycle state of deprecated & archived datasets
+// in the meta component of a preview
+func (rp LocalPreviews) addLifecycle(ctx context.Context, ref dsref.Ref, pview *dataset.Dataset) error {
+	if rp.book == nil || ref.InitID == "" {
+		return nil
+	}
+	lc, err := rp.book.DatasetLifecycle(ctx, ref.InitID)
+	if err != nil {
+		return err
+	}
+	if lc.State == logbook.LifecycleActive {
+		return nil
+	}
+	if pview.Meta == nil {
+		pview.Meta = &dataset.Meta{}
+	}
+	return pview.Meta.Set("lifecycle", map[string]interface{}{
+		"state":     lc.State,
+		"reason":    lc.Reason,
+		"successor": lc.Successor,
+	})
 }
 
 // PreviewComponent gets a component for a reference & component name
@@ -0,0 +1,233 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo/jsonfile"
+	reporef "github.com/qri-io/qri/repo/ref"
+)
+
+// ErrNotCacheable indicates a dataset doesn't match the allowlist of a
+// pull-through cache
+var ErrNotCacheable = errors.New("dataset is not allowed in the pull-through cache")
+
+// cacheEntry records a dataset pulled through the cache
+type cacheEntry struct {
+	Ref      dsref.Ref `json:"ref"`
+	Size     uint64    `json:"size"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// pullThroughCache tracks datasets a remote pulled from an origin on behalf of
+// clients, evicting the least-recently-used datasets when the total size of
+// cached datasets exceeds sizeMax. Only datasets the cache pulled are ever
+// evicted. Cache state is persisted as JSON to indexPath, an empty indexPath
+// keeps state in memory
+type pullThroughCache struct {
+	client  Client
+	origin  string
+	sizeMax int64
+	allow   []string
+	file    jsonfile.File
+
+	// pullLk serializes pulls so concurrent requests for the same dataset don't
+	// fetch it twice
+	pullLk  sync.Mutex
+	lk      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+func newPullThroughCache(cfg *config.Remote, client Client, origin, indexPath string) (*pullThroughCache, error) {
+	if client == nil {
+		return nil, fmt.Errorf("pull-through mode requires a remote client")
+	}
+	if origin == "" {
+		return nil, fmt.Errorf("pull-through mode requires an origin address")
+	}
+
+	c := &pullThroughCache{
+		client:  client,
+		origin:  origin,
+		sizeMax: cfg.CacheSizeMax,
+		allow:   cfg.CacheAllow,
+		file:    jsonfile.New(indexPath),
+		entries: map[string]*cacheEntry{},
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Allowed returns true if a reference matches the cache allowlist
+func (c *pullThroughCache) Allowed(ref dsref.Ref) bool {
+	if len(c.allow) == 0 {
+		return true
+	}
+	alias := ref.Alias()
+	for _, pattern := range c.allow {
+		if ok, _ := path.Match(pattern, alias); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Touch marks a cached dataset as recently used. Touching a dataset that
+// isn't in the cache is a no-op
+func (c *pullThroughCache) Touch(ref dsref.Ref) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	if e, ok := c.entries[ref.Alias()]; ok {
+		e.LastUsed = time.Now()
+	}
+}
+
+// Forget drops a dataset from the cache without removing its data. Used when
+// a dataset is pushed to the remote, making it no longer a cached copy
+func (c *pullThroughCache) Forget(ref dsref.Ref) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	if _, ok := c.entries[ref.Alias()]; ok {
+		delete(c.entries, ref.Alias())
+		if err := c.save(); err != nil {
+			log.Debugw("saving pull-through cache index", "err", err)
+		}
+	}
+}
+
+// pullThrough fetches a dataset from the origin, stores it in the remote's
+// repo and records it in the cache, evicting other datasets as needed
+func (r *Remote) pullThrough(ctx context.Context, ref *dsref.Ref) error {
+	c := r.cache
+	if !c.Allowed(*ref) {
+		return fmt.Errorf("%w: %s", ErrNotCacheable, ref.Alias())
+	}
+
+	c.pullLk.Lock()
+	defer c.pullLk.Unlock()
+
+	// another request may have pulled this dataset while we waited
+	if _, err := r.localResolver.ResolveRef(ctx, ref); err == nil {
+		c.Touch(*ref)
+		return nil
+	}
+
+	log.Debugw("pull-through", "ref", ref.String(), "origin", c.origin)
+	if _, err := c.client.PullDataset(ctx, ref, c.origin); err != nil {
+		return err
+	}
+
+	var size uint64
+	if info, err := r.node.NewDAGInfo(ctx, ref.Path, ""); err == nil {
+		for _, s := range info.Sizes {
+			size += s
+		}
+	} else {
+		log.Debugw("pull-through calculating dataset size", "ref", ref.String(), "err", err)
+	}
+
+	c.lk.Lock()
+	c.entries[ref.Alias()] = &cacheEntry{
+		Ref:      dsref.Ref{Username: ref.Username, Name: ref.Name, ProfileID: ref.ProfileID},
+		Size:     size,
+		LastUsed: time.Now(),
+	}
+	evict := c.evictable(ref.Alias())
+	for _, e := range evict {
+		delete(c.entries, e.Ref.Alias())
+	}
+	err := c.save()
+	c.lk.Unlock()
+	if err != nil {
+		log.Debugw("saving pull-through cache index", "err", err)
+	}
+
+	for _, e := range evict {
+		if err := r.evict(ctx, e.Ref); err != nil {
+			log.Debugw("evicting cached dataset", "ref", e.Ref.String(), "err", err)
+		}
+	}
+
+	_, err = r.localResolver.ResolveRef(ctx, ref)
+	return err
+}
+
+// evictable returns the least-recently-used entries that must be removed to
+// bring the cache under its size limit. keep is never evicted. must be called
+// with the lock held
+func (c *pullThroughCache) evictable(keep string) []*cacheEntry {
+	if c.sizeMax <= 0 {
+		return nil
+	}
+
+	var total uint64
+	entries := make([]*cacheEntry, 0, len(c.entries))
+	for alias, e := range c.entries {
+		total += e.Size
+		if alias != keep {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+
+	var evict []*cacheEntry
+	for _, e := range entries {
+		if total <= uint64(c.sizeMax) {
+			break
+		}
+		evict = append(evict, e)
+		total -= e.Size
+	}
+	return evict
+}
+
+// evict removes all versions of a cached dataset from the remote
+func (r *Remote) evict(ctx context.Context, ref dsref.Ref) error {
+	if _, err := r.localResolver.ResolveRef(ctx, &ref); err != nil {
+		return err
+	}
+	if _, err := base.RemoveNVersionsFromStore(ctx, r.node.Repo, ref, -1); err != nil {
+		return err
+	}
+	if err := r.node.Repo.DeleteRef(reporef.RefFromDsref(ref)); err != nil {
+		log.Debugw("evict deleting ref", "ref", ref.String(), "err", err)
+	}
+	if r.logbook != nil {
+		if err := r.logbook.RemoveLog(ctx, ref); err != nil {
+			log.Debugw("evict removing log", "ref", ref.String(), "err", err)
+		}
+	}
+	return nil
+}
+
+func (c *pullThroughCache) load() error {
+	entries := []*cacheEntry{}
+	if err := c.file.Load(&entries); err != nil {
+		return fmt.Errorf("reading pull-through cache index: %w", err)
+	}
+	for _, e := range entries {
+		c.entries[e.Ref.Alias()] = e
+	}
+	return nil
+}
+
+// save writes the cache index. must be called with the lock held
+func (c *pullThroughCache) save() error {
+	entries := make([]*cacheEntry, 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, e)
+	}
+	return c.file.Save(entries)
+}
@@ -0,0 +1,89 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+func TestPullThroughCacheAllowed(t *testing.T) {
+	cfg := &config.Remote{CacheAllow: []string{"peer/*", "other/cities"}}
+	cache := &pullThroughCache{allow: cfg.CacheAllow, entries: map[string]*cacheEntry{}}
+
+	cases := []struct {
+		ref    dsref.Ref
+		expect bool
+	}{
+		{dsref.Ref{Username: "peer", Name: "movies"}, true},
+		{dsref.Ref{Username: "other", Name: "cities"}, true},
+		{dsref.Ref{Username: "other", Name: "movies"}, false},
+	}
+	for _, c := range cases {
+		if got := cache.Allowed(c.ref); got != c.expect {
+			t.Errorf("%s: expected allowed=%t, got %t", c.ref.Alias(), c.expect, got)
+		}
+	}
+
+	cache.allow = nil
+	if !cache.Allowed(dsref.Ref{Username: "other", Name: "movies"}) {
+		t.Error("expected empty allowlist to allow all datasets")
+	}
+}
+
+func TestPullThroughCacheEvictable(t *testing.T) {
+	now := time.Now()
+	c := &pullThroughCache{
+		sizeMax: 100,
+		entries: map[string]*cacheEntry{
+			"peer/a": {Ref: dsref.Ref{Username: "peer", Name: "a"}, Size: 40, LastUsed: now.Add(-time.Hour)},
+			"peer/b": {Ref: dsref.Ref{Username: "peer", Name: "b"}, Size: 40, LastUsed: now.Add(-time.Minute)},
+			"peer/c": {Ref: dsref.Ref{Username: "peer", Name: "c"}, Size: 40, LastUsed: now.Add(-time.Second)},
+		},
+	}
+
+	evict := c.evictable("peer/c")
+	if len(evict) != 1 || evict[0].Ref.Alias() != "peer/a" {
+		t.Fatalf("expected least recently used dataset peer/a to be evicted, got: %v", evict)
+	}
+
+	// the dataset being kept is never evicted, even when it's the oldest
+	evict = c.evictable("peer/a")
+	if len(evict) != 1 || evict[0].Ref.Alias() != "peer/b" {
+		t.Errorf("expected peer/b to be evicted, got: %v", evict)
+	}
+
+	c.sizeMax = 0
+	if evict = c.evictable("peer/c"); len(evict) != 0 {
+		t.Errorf("expected no size limit to evict nothing, got: %v", evict)
+	}
+}
+
+func TestPullThroughCacheIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pull_through_cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	indexPath := filepath.Join(dir, "remote_cache.json")
+	c := &pullThroughCache{file: jsonfile.New(indexPath), entries: map[string]*cacheEntry{
+		"peer/a": {Ref: dsref.Ref{Username: "peer", Name: "a"}, Size: 40},
+	}}
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := &pullThroughCache{file: jsonfile.New(indexPath), entries: map[string]*cacheEntry{}}
+	if err := loaded.load(); err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := loaded.entries["peer/a"]; !ok || e.Size != 40 {
+		t.Errorf("expected index to round trip, got: %v", loaded.entries)
+	}
+}
@@ -2,12 +2,14 @@ package remote
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	coreiface "github.com/ipfs/interface-go-ipfs-core"
@@ -60,12 +62,30 @@ type Client interface {
 	// PullDataset fetches & stores a dataset from a remote, synchronizing logbook
 	// data and pulling the dataset version data associated with ref.Path
 	PullDataset(ctx context.Context, ref *dsref.Ref, remoteAddr string) (*dataset.Dataset, error)
+	// PushDatasetHead synchronizes logbook data & the head components of the
+	// dataset version specified by ref.Path with a remote, skipping body data
+	PushDatasetHead(ctx context.Context, ref dsref.Ref, remoteAddr string) error
+	// PullDatasetHead fetches logbook data & the head components of a dataset
+	// version from a remote, skipping body data. Heads aren't stored
+	PullDatasetHead(ctx context.Context, ref *dsref.Ref, remoteAddr string) (*dataset.Dataset, error)
+	// PushLogs sends logbook data for a dataset to a remote without
+	// transferring any version data
+	PushLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error
+	// PullLogs fetches logbook data for a dataset from a remote & merges it
+	// into the local logbook without transferring any version data
+	PullLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error
 	// RemoveDataset removes a dataset from a remote entirely, delete logbook data
 	// on the remote and requesting the remote drop all stored dataset versions
 	RemoveDataset(ctx context.Context, ref dsref.Ref, remoteAddr string) error
 	// RemoveDatasetVersion asks a remote to stop storing version data for a
 	// dataset
 	RemoveDatasetVersion(ctx context.Context, ref dsref.Ref, remoteAddr string) error
+	// DatasetUsage fetches pull stats for a dataset the client owns from a
+	// remote that records usage stats
+	DatasetUsage(ctx context.Context, ref dsref.Ref, remoteAddr string) (*DatasetUsage, error)
+	// HookStatuses fetches the status of processing hooks a remote ran after
+	// the client pushed or pulled a dataset version
+	HookStatuses(ctx context.Context, ref dsref.Ref, remoteAddr string) ([]HookStatus, error)
 
 	// Done returns a channel that the client will send on when the client is
 	// closed
@@ -85,14 +105,32 @@ type client struct {
 	capi    coreiface.CoreAPI
 	node    *p2p.QriNode
 	events  event.Publisher
+	// credentials looks up an authentication token for a remote address
+	credentials func(remoteAddr string) string
+	// bookLk serializes logbook writes, letting pushes to many remotes run
+	// concurrently
+	bookLk sync.Mutex
 
 	doneCh   chan struct{}
 	doneErr  error
 	shutdown context.CancelFunc
 }
 
+// ClientOptions configures a remote client
+type ClientOptions struct {
+	// Credentials returns an authentication token for a remote address, or
+	// the empty string if the client has no credentials for the remote.
+	// Tokens are sent as bearer tokens with HTTP requests
+	Credentials func(remoteAddr string) string
+}
+
 // NewClient creates a remote client suitable for syncing peers
-func NewClient(ctx context.Context, node *p2p.QriNode, pub event.Publisher) (c Client, err error) {
+func NewClient(ctx context.Context, node *p2p.QriNode, pub event.Publisher, opts ...func(o *ClientOptions)) (c Client, err error) {
+	o := &ClientOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	var ds *dsync.Dsync
 	capi, capiErr := node.IPFSCoreAPI()
@@ -104,7 +142,7 @@ func NewClient(ctx context.Context, node *p2p.QriNode, pub event.Publisher) (c C
 		}
 
 		ds, err = dsync.New(lng, capi.Block(), func(dsyncConfig *dsync.Config) {
-			if host := node.Host(); host != nil {
+			if host := node.ThrottledHost(); host != nil {
 				dsyncConfig.Libp2pHost = host
 			}
 
@@ -138,6 +176,8 @@ func NewClient(ctx context.Context, node *p2p.QriNode, pub event.Publisher) (c C
 		node:    node,
 		events:  pub,
 
+		credentials: o.Credentials,
+
 		doneCh:   make(chan struct{}),
 		shutdown: cancel,
 	}
@@ -323,14 +363,22 @@ func (rr *remoteRefResolver) ResolveRef(ctx context.Context, ref *dsref.Ref) (st
 
 	switch addressType(rr.remoteAddr) {
 	case "http":
-		err := resolveRefHTTP(ctx, ref, rr.remoteAddr)
+		err := rr.cli.resolveRefHTTP(ctx, ref, rr.remoteAddr)
 		return rr.remoteAddr, err
 	default:
 		return rr.remoteAddr, fmt.Errorf("dataset name resolution currently only works over HTTP")
 	}
 }
 
-func resolveRefHTTP(ctx context.Context, ref *dsref.Ref, remoteAddr string) error {
+// refsHTTPClient resolves references without following redirects, so
+// responses for renamed datasets can be read as the current reference
+var refsHTTPClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func (c *client) resolveRefHTTP(ctx context.Context, ref *dsref.Ref, remoteAddr string) error {
 	u, err := url.Parse(remoteAddr)
 	if err != nil {
 		return err
@@ -351,11 +399,22 @@ func resolveRefHTTP(ctx context.Context, ref *dsref.Ref, remoteAddr string) erro
 	}
 
 	req = req.WithContext(ctx)
-	res, err := http.DefaultClient.Do(req)
+	c.addCredentials(req)
+	res, err := refsHTTPClient.Do(req)
 	if err != nil {
 		return err
 	}
 
+	if res.StatusCode == http.StatusMovedPermanently {
+		// the dataset was renamed, the response is the current reference
+		prev := ref.Alias()
+		if err := json.NewDecoder(res.Body).Decode(ref); err != nil {
+			return err
+		}
+		log.Infow("dataset was renamed", "from", prev, "to", ref.Alias(), "remote", remoteAddr)
+		return nil
+	}
+
 	if res.StatusCode != http.StatusOK {
 		errBytes, _ := ioutil.ReadAll(res.Body)
 		errMsg := string(errBytes)
@@ -402,12 +461,32 @@ func (c *client) PushDataset(ctx context.Context, ref dsref.Ref, addr string) er
 		return err
 	}
 
+	// receipts are only issued over HTTP. the push has succeeded, failing to
+	// get a receipt shouldn't fail it
+	if addressType(addr) == "http" {
+		rcpt, err := fetchPushReceipt(ctx, ref, addr)
+		if err == nil {
+			err = c.recordReceipt(ctx, ref, rcpt)
+		}
+		if err != nil {
+			log.Debugw("recording push receipt", "ref", ref, "addr", addr, "err", err)
+		}
+	}
+
 	return c.events.Publish(ctx, event.ETRemoteClientPushDatasetCompleted, event.RemoteEvent{
 		Ref:        ref,
 		RemoteAddr: addr,
 	})
 }
 
+// PushLogs pushes logbook data for a dataset to a remote address
+func (c *client) PushLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
+	if c == nil {
+		return ErrNoRemoteClient
+	}
+	return c.pushLogs(ctx, ref, remoteAddr)
+}
+
 // pushLogs pushes logbook data to a remote address
 func (c *client) pushLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
 	log.Debugf("client.pushLogs ref=%q remoteAddr=%q", ref, remoteAddr)
@@ -419,6 +498,10 @@ func (c *client) pushLogs(ctx context.Context, ref dsref.Ref, remoteAddr string)
 		return err
 	}
 
+	// a push writes & may roll back a push operation, hold the lock so
+	// rollbacks only drop their own operation
+	c.bookLk.Lock()
+	defer c.bookLk.Unlock()
 	return push.Do(ctx)
 }
 
@@ -544,6 +627,15 @@ func (c *client) PullDataset(ctx context.Context, ref *dsref.Ref, remoteAddr str
 	return ds, nil
 }
 
+// PullLogs fetches logbook data for a dataset from a remote & merges it into
+// the local logbook
+func (c *client) PullLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
+	if c == nil {
+		return ErrNoRemoteClient
+	}
+	return c.pullLogs(ctx, ref, remoteAddr)
+}
+
 // pullLogs fetches logbook data from a remote & stores it locally
 func (c *client) pullLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
 	log.Debugf("client.pullLogs ref=%q remoteAddr=%q", ref, remoteAddr)
@@ -673,16 +765,27 @@ func (c *client) RemoveDatasetVersion(ctx context.Context, ref dsref.Ref, remote
 
 	switch addressType(remoteAddr) {
 	case "http":
-		return removeDatasetHTTP(ctx, params, remoteAddr)
+		rcpt, err := c.removeDatasetHTTP(ctx, params, remoteAddr)
+		if err != nil {
+			return err
+		}
+		if rcpt != nil {
+			if err := c.recordReceipt(ctx, ref, rcpt); err != nil {
+				log.Debugw("recording remove receipt", "ref", ref, "addr", remoteAddr, "err", err)
+			}
+		}
+		return nil
 	default:
 		return fmt.Errorf("dataset remove requests currently only work over HTTP")
 	}
 }
 
-func removeDatasetHTTP(ctx context.Context, params map[string]string, remoteAddr string) error {
+// removeDatasetHTTP requests a remote remove a dataset, returning the
+// remote's receipt. Remotes that don't issue receipts return a nil receipt
+func (c *client) removeDatasetHTTP(ctx context.Context, params map[string]string, remoteAddr string) (*Receipt, error) {
 	u, err := url.Parse(remoteAddr)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// TODO (b5) - need to document this convention
@@ -696,24 +799,96 @@ func removeDatasetHTTP(ctx context.Context, params map[string]string, remoteAddr
 
 	req, err := http.NewRequest("DELETE", u.String(), nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req = req.WithContext(ctx)
+	c.addCredentials(req)
 
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
 		if data, err := ioutil.ReadAll(res.Body); err == nil {
 			log.Error("HTTP server remove error response: ", string(data))
 		}
-		return fmt.Errorf("failed to remove dataset from remote")
+		return nil, fmt.Errorf("failed to remove dataset from remote")
 	}
 
-	return nil
+	rcpt, err := decodeReceiptResponse(res)
+	if err != nil {
+		log.Debugw("remote didn't issue a remove receipt", "err", err)
+		return nil, nil
+	}
+	return rcpt, nil
+}
+
+// DatasetUsage fetches pull stats for a dataset from a remote. Only the
+// dataset owner can view usage stats
+func (c *client) DatasetUsage(ctx context.Context, ref dsref.Ref, remoteAddr string) (*DatasetUsage, error) {
+	log.Debugf("client.DatasetUsage ref=%q remoteAddr=%q", ref, remoteAddr)
+	if c == nil {
+		return nil, ErrNoRemoteClient
+	}
+	if at := addressType(remoteAddr); at != "http" {
+		return nil, fmt.Errorf("usage stats are only supported over HTTP")
+	}
+
+	u, err := url.Parse(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/remote/usage"
+	q := u.Query()
+	q.Set("username", ref.Username)
+	q.Set("name", ref.Name)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.signHTTPRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	// the remote may not know our public key, send it along so the remote can
+	// check the request signature
+	pkBytes, err := crypto.MarshalPublicKey(c.node.Repo.Profiles().Owner().PrivKey.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("pubkey", base64.StdEncoding.EncodeToString(pkBytes))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such host") {
+			return nil, ErrNoRemoteClient
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	env := struct {
+		Data *DatasetUsage
+		Meta struct {
+			Error  string
+			Status string
+			Code   int
+		}
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error %d: %s", res.StatusCode, env.Meta.Error)
+	}
+
+	return env.Data, nil
 }
 
 // TODO (b5) - this should return an enumeration
@@ -747,9 +922,22 @@ func (c *client) signHTTPRequest(ctx context.Context, req *http.Request) error {
 	req.Header.Add("pid", peerID)
 	req.Header.Add("signature", b64Sig)
 	req.Header.Add("qri-version", version.Version)
+	c.addCredentials(req)
 	return nil
 }
 
+// addCredentials sets the Authorization header of a request if the client
+// has a token for the requested remote
+func (c *client) addCredentials(req *http.Request) {
+	if c.credentials == nil {
+		return
+	}
+	addr := fmt.Sprintf("%s://%s", req.URL.Scheme, req.URL.Host)
+	if token := c.credentials(addr); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
 // Done returns a channel that the client will send on when finished closing
 func (c *client) Done() <-chan struct{} {
 	return c.doneCh
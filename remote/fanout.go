@@ -0,0 +1,112 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/qri-io/qri/dsref"
+)
+
+var (
+	// ErrPartialPush indicates a push to many remotes failed for some of them
+	ErrPartialPush = errors.New("push failed for some remotes")
+
+	// FanoutConcurrency caps the number of remotes pushed to at once
+	FanoutConcurrency = 4
+)
+
+// PushTarget names a remote address to push to
+type PushTarget struct {
+	// Name of the remote, "registry" for the registry
+	Name string `json:"name"`
+	Addr string `json:"addr"`
+}
+
+// PushResult is the outcome of pushing a dataset to one remote of a fanout
+type PushResult struct {
+	Remote string `json:"remote"`
+	Addr   string `json:"addr"`
+	// Queued is true when the remote couldn't be reached & the push was added
+	// to the push queue
+	Queued bool   `json:"queued,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	err error
+}
+
+// Err returns the error that failed the push, nil for successful pushes
+func (r PushResult) Err() error {
+	if r.err == nil && r.Error != "" {
+		return errors.New(r.Error)
+	}
+	return r.err
+}
+
+// FanoutReport describes pushing a dataset version to a set of remotes
+type FanoutReport struct {
+	Ref     dsref.Ref    `json:"ref"`
+	Results []PushResult `json:"results"`
+}
+
+// Pushed returns the number of remotes that accepted the push
+func (r *FanoutReport) Pushed() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Error == "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns an error wrapping ErrPartialPush if any remote failed to accept
+// the push, nil if every remote accepted it. Queued pushes count as failures
+func (r *FanoutReport) Err() error {
+	if len(r.Results) == r.Pushed() {
+		return nil
+	}
+	return fmt.Errorf("%w: pushed %s to %d of %d remotes", ErrPartialPush, r.Ref.Human(), r.Pushed(), len(r.Results))
+}
+
+// FanoutPush calls push once for each target, pushing to up to
+// FanoutConcurrency targets at a time. Failing to push to one remote doesn't
+// stop pushes to the others, each outcome is recorded in the returned report,
+// ordered by remote name
+func FanoutPush(ctx context.Context, ref dsref.Ref, targets []PushTarget, push func(ctx context.Context, t PushTarget) error) *FanoutReport {
+	report := &FanoutReport{
+		Ref:     ref,
+		Results: make([]PushResult, len(targets)),
+	}
+
+	limit := FanoutConcurrency
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	wg := sync.WaitGroup{}
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t PushTarget) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res := PushResult{Remote: t.Name, Addr: t.Addr}
+			if err := push(ctx, t); err != nil {
+				log.Debugw("fanout push failed", "ref", ref.String(), "remote", t.Name, "addr", t.Addr, "err", err)
+				res.err = err
+				res.Error = err.Error()
+			}
+			report.Results[i] = res
+		}(i, t)
+	}
+	wg.Wait()
+
+	sort.Slice(report.Results, func(i, j int) bool {
+		return report.Results[i].Remote < report.Results[j].Remote
+	})
+	return report
+}
@@ -0,0 +1,72 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+)
+
+func TestFanoutPush(t *testing.T) {
+	ctx := context.Background()
+	ref := dsref.Ref{Username: "peer", Name: "movies", Path: "/ipfs/QmHash"}
+	targets := []PushTarget{
+		{Name: "staging", Addr: "http://staging"},
+		{Name: "backup", Addr: "http://backup"},
+		{Name: "registry", Addr: "http://registry"},
+	}
+
+	var running, maxRunning int32
+	prev := FanoutConcurrency
+	FanoutConcurrency = 2
+	defer func() { FanoutConcurrency = prev }()
+
+	report := FanoutPush(ctx, ref, targets, func(ctx context.Context, t PushTarget) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 10)
+		if t.Name == "staging" {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent pushes, got %d", maxRunning)
+	}
+	names := []string{}
+	for _, res := range report.Results {
+		names = append(names, res.Remote)
+	}
+	if expect := []string{"backup", "registry", "staging"}; len(names) != 3 || names[0] != expect[0] || names[1] != expect[1] || names[2] != expect[2] {
+		t.Errorf("expected results ordered by remote name %v, got %v", expect, names)
+	}
+	if report.Pushed() != 2 {
+		t.Errorf("expected 2 successful pushes, got %d", report.Pushed())
+	}
+	if report.Results[2].Error != "rejected" || report.Results[2].Err() == nil {
+		t.Errorf("expected staging push error to be recorded, got: %#v", report.Results[2])
+	}
+
+	err := report.Err()
+	if !errors.Is(err, ErrPartialPush) {
+		t.Fatalf("expected partial push error, got: %v", err)
+	}
+	if expect := "push failed for some remotes: pushed peer/movies to 2 of 3 remotes"; err.Error() != expect {
+		t.Errorf("error mismatch.\nwant: %q\ngot:  %q", expect, err.Error())
+	}
+
+	report = FanoutPush(ctx, ref, targets[1:], func(ctx context.Context, t PushTarget) error { return nil })
+	if err := report.Err(); err != nil {
+		t.Errorf("expected no error when every push succeeds, got: %s", err)
+	}
+}
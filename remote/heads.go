@@ -0,0 +1,351 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/qri-io/dataset"
+	apiutil "github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/base/dsfs"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/remote/access"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+// ErrNoHead indicates a head store has no head for a dataset version
+var ErrNoHead = errors.New("no head stored for dataset version")
+
+// Head is the head components of a dataset version: every component except
+// the body. Heads are kept for versions synced in metadata-only mode, where
+// body data is left on a remote until it's needed
+type Head struct {
+	// Source is the address of the remote the head was pulled from, which
+	// body data can be fetched from. Pushed heads have no source
+	Source string `json:"source,omitempty"`
+	// Dataset is the version with all components except the body
+	Dataset *dataset.Dataset `json:"dataset"`
+}
+
+// HeadStore keeps dataset heads keyed by version path. Heads are persisted as
+// JSON to filename, an empty filename keeps heads in memory
+type HeadStore struct {
+	lk    sync.Mutex
+	file  jsonfile.File
+	heads map[string]*Head
+}
+
+// NewHeadStore creates a head store, loading any heads persisted to filename
+func NewHeadStore(filename string) *HeadStore {
+	return &HeadStore{file: jsonfile.New(filename), heads: map[string]*Head{}}
+}
+
+// Get returns the head for a version path, returning ErrNoHead if the store
+// has no head for path
+func (s *HeadStore) Get(path string) (*Head, error) {
+	if s == nil {
+		return nil, ErrNoHead
+	}
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	h, ok := s.heads[path]
+	if !ok {
+		return nil, ErrNoHead
+	}
+	return h, nil
+}
+
+// Put adds a head to the store, keyed by the path of the head dataset
+func (s *HeadStore) Put(h *Head) error {
+	if h == nil || h.Dataset == nil || h.Dataset.Path == "" {
+		return fmt.Errorf("head dataset path is required")
+	}
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.heads[h.Dataset.Path] = h
+	return s.save()
+}
+
+// Delete drops a head from the store. Heads are deleted once the body of a
+// version is stored locally
+func (s *HeadStore) Delete(path string) error {
+	if s == nil {
+		return nil
+	}
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.heads[path]; !ok {
+		return nil
+	}
+	delete(s.heads, path)
+	return s.save()
+}
+
+func (s *HeadStore) load() error {
+	if s.file.Filename() == "" {
+		return nil
+	}
+	heads := map[string]*Head{}
+	if err := s.file.Load(&heads); err != nil {
+		return fmt.Errorf("reading head store: %w", err)
+	}
+	s.heads = heads
+	return nil
+}
+
+// save writes the store to disk. must be called with the lock held
+func (s *HeadStore) save() error {
+	return s.file.Save(s.heads)
+}
+
+// LoadHead reads the head components of a dataset version stored in r,
+// falling back to a head store for versions that were synced metadata-only
+func LoadHead(ctx context.Context, r repo.Repo, heads *HeadStore, path string) (*dataset.Dataset, error) {
+	ds, err := dsfs.LoadDataset(ctx, r.Filesystem(), path)
+	if err != nil {
+		if h, headErr := heads.Get(path); headErr == nil {
+			return h.Dataset, nil
+		}
+		return nil, err
+	}
+	ds.Body = nil
+	ds.BodyBytes = nil
+	return ds, nil
+}
+
+// PullDatasetHead fetches logbook data & the head components of a dataset
+// version from a remote, skipping body data. The head isn't stored,
+// callers are expected to keep heads in a HeadStore
+func (c *client) PullDatasetHead(ctx context.Context, ref *dsref.Ref, remoteAddr string) (*dataset.Dataset, error) {
+	log.Debugf("client.PullDatasetHead ref=%q addr=%q", ref, remoteAddr)
+	if c == nil {
+		return nil, ErrNoRemoteClient
+	}
+	if addressType(remoteAddr) != "http" {
+		return nil, fmt.Errorf("metadata-only pulls currently only work over HTTP")
+	}
+
+	if err := c.pullLogs(ctx, *ref, remoteAddr); err != nil {
+		return nil, err
+	}
+	if ref.Path == "" {
+		if _, err := c.NewRemoteRefResolver(remoteAddr).ResolveRef(ctx, ref); err != nil {
+			return nil, err
+		}
+	}
+
+	u, err := url.Parse(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/remote/dataset/head"
+	q := u.Query()
+	q.Set("username", ref.Username)
+	q.Set("name", ref.Name)
+	q.Set("path", ref.Path)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.signHTTPRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	env := struct {
+		Data *dataset.Dataset
+		Meta struct {
+			Error string
+		}
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error %d: %s", res.StatusCode, env.Meta.Error)
+	}
+	if env.Data == nil || env.Data.Path != ref.Path {
+		return nil, fmt.Errorf("remote returned the wrong head for %s", ref)
+	}
+	return env.Data, nil
+}
+
+// PushDatasetHead sends logbook data & the head components of a dataset
+// version to a remote, skipping body data
+func (c *client) PushDatasetHead(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
+	log.Debugf("client.PushDatasetHead ref=%q addr=%q", ref, remoteAddr)
+	if c == nil {
+		return ErrNoRemoteClient
+	}
+	if addressType(remoteAddr) != "http" {
+		return fmt.Errorf("metadata-only pushes currently only work over HTTP")
+	}
+
+	ds, err := LoadHead(ctx, c.node.Repo, nil, ref.Path)
+	if err != nil {
+		return err
+	}
+	if err := c.pushLogs(ctx, ref, remoteAddr); err != nil {
+		return err
+	}
+
+	params, err := sigParams(c.pk, c.profile.Peername, ref)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(remoteAddr)
+	if err != nil {
+		return err
+	}
+	u.Path = "/remote/dataset/head"
+	q := u.Query()
+	for key, val := range params {
+		q.Set(key, val)
+	}
+	u.RawQuery = q.Encode()
+
+	data, err := json.Marshal(ds)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	c.addCredentials(req)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("pushing head to %s failed: %s", remoteAddr, msg)
+	}
+
+	return c.events.Publish(ctx, event.ETRemoteClientPushDatasetCompleted, event.RemoteEvent{
+		Ref:        ref,
+		RemoteAddr: remoteAddr,
+	})
+}
+
+// HeadHTTPHandler serves & accepts dataset heads over HTTP. GET requests
+// fetch the head of a dataset version, POST requests push a head to the
+// remote without body data
+func (r *Remote) HeadHTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		switch req.Method {
+		case "GET":
+			ref := &dsref.Ref{
+				Username: req.FormValue("username"),
+				Name:     req.FormValue("name"),
+				Path:     req.FormValue("path"),
+			}
+			if err := r.resolveOrPullThrough(ctx, ref); err != nil {
+				apiutil.WriteErrResponse(w, http.StatusNotFound, err)
+				return
+			}
+			ds, err := LoadHead(ctx, r.node.Repo, r.heads, ref.Path)
+			if err != nil {
+				apiutil.WriteErrResponse(w, http.StatusNotFound, err)
+				return
+			}
+			apiutil.WriteResponse(w, ds)
+		case "POST":
+			if err := r.acceptHead(ctx, req); err != nil {
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+				return
+			}
+			apiutil.WriteResponse(w, "ok")
+		default:
+			apiutil.NotFoundHandler(w, req)
+		}
+	}
+}
+
+// acceptHead stores a head pushed by a client. Pushed heads are subject to
+// the same checks as dataset pushes, and must match a version the remote's
+// logbook references
+func (r *Remote) acceptHead(ctx context.Context, req *http.Request) error {
+	if r.heads == nil {
+		return fmt.Errorf("not accepting metadata-only pushes")
+	}
+	params := map[string]string{}
+	for key := range req.URL.Query() {
+		params[key] = req.URL.Query().Get(key)
+	}
+	subj, ref, err := r.subjAndRefFromMeta(params)
+	if err != nil {
+		return err
+	}
+	if r.policy != nil {
+		if err := r.policy.Enforce(subj, access.ResourceStrFromRef(ref), "remote:push"); err != nil {
+			return err
+		}
+	}
+	if r.acceptSizeMax == 0 {
+		return fmt.Errorf("not accepting any datasets")
+	}
+	if r.datasetPushPreCheck != nil {
+		if err := r.datasetPushPreCheck(ctx, subj.ID, ref); err != nil {
+			return err
+		}
+	}
+
+	ds := &dataset.Dataset{}
+	if err := json.NewDecoder(req.Body).Decode(ds); err != nil {
+		return err
+	}
+	if ds.Path == "" || ds.Path != ref.Path {
+		return fmt.Errorf("head path doesn't match the pushed reference")
+	}
+	ds.Body = nil
+	ds.BodyBytes = nil
+
+	resolved := dsref.Ref{Username: ref.Username, Name: ref.Name}
+	if _, err := r.localResolver.ResolveRef(ctx, &resolved); err != nil {
+		return fmt.Errorf("logs must be pushed before a head: %w", err)
+	}
+	if resolved.Path != ref.Path {
+		return fmt.Errorf("head %q isn't the latest version of %s", ref.Path, resolved.Alias())
+	}
+
+	if err := r.heads.Put(&Head{Dataset: ds}); err != nil {
+		return err
+	}
+	if r.datasetPushed != nil {
+		if err := r.datasetPushed(ctx, subj.ID, ref); err != nil {
+			log.Debugw("head pushed hook", "ref", ref.String(), "err", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestHeadStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "head_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "heads.json")
+
+	s := NewHeadStore(filename)
+	if _, err := s.Get("/ipfs/QmFoo"); err != ErrNoHead {
+		t.Errorf("expected ErrNoHead for an empty store. got: %v", err)
+	}
+	if err := s.Put(&Head{Dataset: &dataset.Dataset{}}); err == nil {
+		t.Errorf("expected putting a head without a path to error")
+	}
+
+	h := &Head{
+		Source: "https://remote.qri.io",
+		Dataset: &dataset.Dataset{
+			Path:     "/ipfs/QmFoo",
+			Commit:   &dataset.Commit{Title: "initial commit"},
+			BodyPath: "/ipfs/QmFoo/body.csv",
+		},
+	}
+	if err := s.Put(h); err != nil {
+		t.Fatal(err)
+	}
+
+	// heads persist across stores
+	s = NewHeadStore(filename)
+	got, err := s.Get("/ipfs/QmFoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Source != h.Source || got.Dataset.Commit.Title != "initial commit" || got.Dataset.BodyPath != h.Dataset.BodyPath {
+		t.Errorf("head mismatch. got: %#v", got)
+	}
+
+	if err := s.Delete("/ipfs/QmFoo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewHeadStore(filename).Get("/ipfs/QmFoo"); err != ErrNoHead {
+		t.Errorf("expected deleted head to be removed. got: %v", err)
+	}
+
+	var nilStore *HeadStore
+	if _, err := nilStore.Get("/ipfs/QmFoo"); err != ErrNoHead {
+		t.Errorf("expected nil store to have no heads. got: %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	apiutil "github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/profile"
+)
+
+const (
+	// HookStagePush marks hooks run after a push is accepted
+	HookStagePush = "push"
+	// HookStagePull marks hooks run after a client pulls a version
+	HookStagePull = "pull"
+
+	// HookStatusRunning is the status of a hook that hasn't finished
+	HookStatusRunning = "running"
+	// HookStatusSucceeded is the status of a hook that finished without error
+	HookStatusSucceeded = "succeeded"
+	// HookStatusFailed is the status of a hook that returned an error
+	HookStatusFailed = "failed"
+)
+
+var (
+	// HookTimeout caps the time a single processing hook may run
+	HookTimeout = time.Minute * 10
+	// HookHistoryMax is the number of dataset versions hook statuses are kept
+	// for. Statuses of the oldest versions are dropped first
+	HookHistoryMax = 500
+	// ErrNotPusher is returned when a profile requests hook statuses of a
+	// version it didn't push
+	ErrNotPusher = errors.New("only the profile that pushed a version can view its hook status")
+)
+
+// ProcessHook is server-side processing a remote runs after a push or pull,
+// like reindexing search, regenerating previews or notifying a webhook
+type ProcessHook struct {
+	Name string
+	Run  Hook
+}
+
+// OptPostReceiveHook adds a hook the remote runs in the background after each
+// accepted push. Hooks never fail a push, pushers can check hook status
+func OptPostReceiveHook(name string, h Hook) OptionsFunc {
+	return func(o *Options) {
+		o.PostReceiveHooks = append(o.PostReceiveHooks, ProcessHook{Name: name, Run: h})
+	}
+}
+
+// OptPostPullHook adds a hook the remote runs in the background after a
+// client pulls a dataset version
+func OptPostPullHook(name string, h Hook) OptionsFunc {
+	return func(o *Options) {
+		o.PostPullHooks = append(o.PostPullHooks, ProcessHook{Name: name, Run: h})
+	}
+}
+
+// HookStatus is the state of a single processing hook run for a dataset
+// version
+type HookStatus struct {
+	Hook  string `json:"hook"`
+	Stage string `json:"stage"`
+	Ref   string `json:"ref"`
+	Path  string `json:"path"`
+	// Requester is the profile ID that pushed or pulled the version
+	Requester string    `json:"requester"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Started   time.Time `json:"started"`
+	Finished  time.Time `json:"finished,omitempty"`
+}
+
+// hookRunner runs processing hooks asynchronously, keeping the status of
+// each run in memory
+type hookRunner struct {
+	push []ProcessHook
+	pull []ProcessHook
+
+	lk sync.Mutex
+	// statuses of hooks, keyed by version path
+	statuses map[string][]*HookStatus
+	// order lists version paths, oldest first
+	order []string
+	wg    sync.WaitGroup
+}
+
+func newHookRunner(push, pull []ProcessHook) *hookRunner {
+	if len(push) == 0 && len(pull) == 0 {
+		return nil
+	}
+	return &hookRunner{
+		push:     push,
+		pull:     pull,
+		statuses: map[string][]*HookStatus{},
+	}
+}
+
+// Run starts hooks of a stage for a dataset version, returning immediately
+func (hr *hookRunner) Run(stage string, pid profile.ID, ref dsref.Ref) {
+	hooks := hr.push
+	if stage == HookStagePull {
+		hooks = hr.pull
+	}
+	for _, h := range hooks {
+		st := &HookStatus{
+			Hook:      h.Name,
+			Stage:     stage,
+			Ref:       ref.Alias(),
+			Path:      ref.Path,
+			Requester: pid.String(),
+			Status:    HookStatusRunning,
+			Started:   nowFunc().In(time.UTC),
+		}
+		hr.add(st)
+
+		hr.wg.Add(1)
+		go func(h ProcessHook, st *HookStatus) {
+			defer hr.wg.Done()
+			// hooks outlive the request that triggered them
+			ctx, cancel := context.WithTimeout(context.Background(), HookTimeout)
+			defer cancel()
+			err := h.Run(ctx, pid, ref)
+
+			hr.lk.Lock()
+			defer hr.lk.Unlock()
+			st.Finished = nowFunc().In(time.UTC)
+			if err != nil {
+				log.Debugw("processing hook failed", "hook", h.Name, "stage", stage, "ref", ref, "err", err)
+				st.Status, st.Error = HookStatusFailed, err.Error()
+			} else {
+				st.Status = HookStatusSucceeded
+			}
+		}(h, st)
+	}
+}
+
+func (hr *hookRunner) add(st *HookStatus) {
+	hr.lk.Lock()
+	defer hr.lk.Unlock()
+	if _, ok := hr.statuses[st.Path]; !ok {
+		hr.order = append(hr.order, st.Path)
+		for len(hr.order) > HookHistoryMax {
+			delete(hr.statuses, hr.order[0])
+			hr.order = hr.order[1:]
+		}
+	}
+	hr.statuses[st.Path] = append(hr.statuses[st.Path], st)
+}
+
+// Wait blocks until all running hooks finish
+func (hr *hookRunner) Wait() {
+	hr.wg.Wait()
+}
+
+// Statuses lists hook statuses for a dataset version requested by a profile,
+// oldest first. ok is false if hooks ran for the version, but none were
+// requested by the profile
+func (hr *hookRunner) Statuses(pid profile.ID, path string) (res []HookStatus, ok bool) {
+	hr.lk.Lock()
+	defer hr.lk.Unlock()
+	res = []HookStatus{}
+	for _, st := range hr.statuses[path] {
+		if st.Requester == pid.String() {
+			res = append(res, *st)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Started.Before(res[j].Started) })
+	return res, len(res) > 0 || len(hr.statuses[path]) == 0
+}
+
+// HookStatuses lists processing hook statuses for a dataset version on behalf
+// of the profile that pushed or pulled it. A reference without a path uses
+// the latest version
+func (r *Remote) HookStatuses(ctx context.Context, pid profile.ID, ref dsref.Ref) ([]HookStatus, error) {
+	if r.hooks == nil {
+		return []HookStatus{}, nil
+	}
+	if ref.Path == "" {
+		if _, err := r.localResolver.ResolveRef(ctx, &ref); err != nil {
+			return nil, err
+		}
+	}
+	res, ok := r.hooks.Statuses(pid, ref.Path)
+	if !ok {
+		return nil, ErrNotPusher
+	}
+	return res, nil
+}
+
+// HooksHTTPHandler serves processing hook statuses. Requests must be signed &
+// include the public key of the requesting profile
+func (r *Remote) HooksHTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			apiutil.NotFoundHandler(w, req)
+			return
+		}
+
+		pid, err := verifySignedHTTPRequest(req)
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		ref := dsref.Ref{
+			Username: req.FormValue("username"),
+			Name:     req.FormValue("name"),
+			Path:     req.FormValue("path"),
+		}
+		res, err := r.HookStatuses(req.Context(), pid, ref)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrNotPusher):
+				apiutil.WriteErrResponse(w, http.StatusForbidden, err)
+			case errors.Is(err, dsref.ErrRefNotFound):
+				apiutil.WriteErrResponse(w, http.StatusNotFound, err)
+			default:
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			}
+			return
+		}
+		apiutil.WriteResponse(w, res)
+	}
+}
+
+// WebhookHook creates a hook that notifies a URL of a push or pull with a
+// JSON POST request. Any response status other than 2xx fails the hook
+func WebhookHook(stage, webhookURL string) Hook {
+	return func(ctx context.Context, pid profile.ID, ref dsref.Ref) error {
+		return PostWebhook(ctx, webhookURL, map[string]string{
+			"stage":     stage,
+			"ref":       ref.Alias(),
+			"path":      ref.Path,
+			"initID":    ref.InitID,
+			"requester": pid.String(),
+		})
+	}
+}
+
+// PostWebhook notifies a URL with a JSON POST request of payload. Any response
+// status other than 2xx is an error
+func PostWebhook(ctx context.Context, webhookURL string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// HookStatuses fetches the status of processing hooks a remote ran for a
+// dataset version this client pushed or pulled
+func (c *client) HookStatuses(ctx context.Context, ref dsref.Ref, remoteAddr string) ([]HookStatus, error) {
+	log.Debugf("client.HookStatuses ref=%q remoteAddr=%q", ref, remoteAddr)
+	if c == nil {
+		return nil, ErrNoRemoteClient
+	}
+	if at := addressType(remoteAddr); at != "http" {
+		return nil, fmt.Errorf("hook status is only supported over HTTP")
+	}
+
+	u, err := url.Parse(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/remote/hooks"
+	q := u.Query()
+	q.Set("username", ref.Username)
+	q.Set("name", ref.Name)
+	q.Set("path", ref.Path)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.signHTTPRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	pkBytes, err := crypto.MarshalPublicKey(c.node.Repo.Profiles().Owner().PrivKey.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("pubkey", base64.StdEncoding.EncodeToString(pkBytes))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such host") {
+			return nil, ErrNoRemoteClient
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	env := struct {
+		Data []HookStatus
+		Meta struct {
+			Error string
+		}
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error %d: %s", res.StatusCode, env.Meta.Error)
+	}
+	return env.Data, nil
+}
@@ -0,0 +1,96 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/profile"
+)
+
+func TestHookRunner(t *testing.T) {
+	if hr := newHookRunner(nil, nil); hr != nil {
+		t.Error("expected no runner without hooks")
+	}
+
+	release := make(chan struct{})
+	hr := newHookRunner([]ProcessHook{
+		{Name: "index", Run: func(ctx context.Context, pid profile.ID, ref dsref.Ref) error {
+			<-release
+			return nil
+		}},
+		{Name: "validate", Run: func(ctx context.Context, pid profile.ID, ref dsref.Ref) error {
+			return fmt.Errorf("invalid body")
+		}},
+	}, nil)
+
+	pusher := profile.ID("pusher")
+	ref := dsref.Ref{Username: "peer", Name: "cities", Path: "/mem/v1"}
+	hr.Run(HookStagePush, pusher, ref)
+	// no pull hooks are registered
+	hr.Run(HookStagePull, profile.ID("puller"), ref)
+
+	statuses, ok := hr.Statuses(pusher, ref.Path)
+	if !ok || len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses, got: %#v", statuses)
+	}
+	if statuses[0].Hook != "index" || statuses[0].Status != HookStatusRunning {
+		t.Errorf("expected index hook to be running, got: %#v", statuses[0])
+	}
+
+	close(release)
+	hr.Wait()
+	statuses, _ = hr.Statuses(pusher, ref.Path)
+	if statuses[0].Status != HookStatusSucceeded || statuses[0].Finished.IsZero() {
+		t.Errorf("expected index hook to succeed, got: %#v", statuses[0])
+	}
+	if statuses[1].Status != HookStatusFailed || statuses[1].Error != "invalid body" {
+		t.Errorf("expected validate hook to fail, got: %#v", statuses[1])
+	}
+
+	if _, ok := hr.Statuses(profile.ID("other"), ref.Path); ok {
+		t.Error("expected statuses to be hidden from profiles that didn't push the version")
+	}
+	if res, ok := hr.Statuses(pusher, "/mem/unknown"); !ok || len(res) != 0 {
+		t.Errorf("expected empty statuses for an unknown version, got: %#v", res)
+	}
+
+	prevMax := HookHistoryMax
+	HookHistoryMax = 1
+	defer func() { HookHistoryMax = prevMax }()
+	hr.Run(HookStagePush, pusher, dsref.Ref{Username: "peer", Name: "cities", Path: "/mem/v2"})
+	hr.Wait()
+	if res, _ := hr.Statuses(pusher, ref.Path); len(res) != 0 {
+		t.Errorf("expected statuses of the oldest version to be dropped, got: %#v", res)
+	}
+}
+
+func TestWebhookHook(t *testing.T) {
+	var got map[string]string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if got["ref"] == "peer/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer s.Close()
+
+	ctx := context.Background()
+	hook := WebhookHook(HookStagePush, s.URL)
+	if err := hook(ctx, profile.ID("pusher"), dsref.Ref{Username: "peer", Name: "cities", Path: "/mem/v1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got["ref"] != "peer/cities" || got["path"] != "/mem/v1" || got["stage"] != HookStagePush {
+		t.Errorf("unexpected webhook payload: %v", got)
+	}
+
+	if err := hook(ctx, profile.ID("pusher"), dsref.Ref{Username: "peer", Name: "broken"}); err == nil {
+		t.Error("expected error status to fail the hook")
+	}
+}
@@ -0,0 +1,165 @@
+package remote
+
+import (
+	"context"
+	"sort"
+
+	"github.com/qri-io/qri/base"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/repo"
+)
+
+const (
+	// HostingStored marks a dataset with its latest version stored in full
+	HostingStored = "stored"
+	// HostingHeadOnly marks a dataset with its latest version pushed in
+	// metadata-only mode, without body data
+	HostingHeadOnly = "head-only"
+	// HostingMissing marks a dataset reference with no stored data for the
+	// latest version
+	HostingMissing = "missing"
+)
+
+// HostedDataset describes a dataset stored by a remote
+type HostedDataset struct {
+	Ref    dsref.Ref `json:"ref"`
+	Status string    `json:"status"`
+	// Versions is the number of versions in the dataset's history
+	Versions int `json:"versions"`
+	// StoredVersions is the number of versions stored by the remote
+	StoredVersions int `json:"storedVersions"`
+	// Size is the total size of stored versions in bytes. Versions often
+	// share blocks, making this an upper bound on storage used
+	Size uint64 `json:"size"`
+	// Cached is true for datasets held by the pull-through cache
+	Cached bool `json:"cached,omitempty"`
+}
+
+// HostingStorage summarizes storage consumed by datasets hosted on a remote
+type HostingStorage struct {
+	Datasets       int    `json:"datasets"`
+	StoredVersions int    `json:"storedVersions"`
+	Size           uint64 `json:"size"`
+	CachedSize     uint64 `json:"cachedSize"`
+	// Largest lists hosted datasets by size, largest first
+	Largest []HostedDataset `json:"largest"`
+}
+
+// HostedDatasets lists datasets stored by the remote. A negative limit lists
+// all datasets
+func (r *Remote) HostedDatasets(ctx context.Context, offset, limit int) ([]HostedDataset, error) {
+	if limit < 0 {
+		num, err := r.node.Repo.RefCount()
+		if err != nil {
+			return nil, err
+		}
+		limit = num
+	}
+	infos, err := repo.ListVersionInfoShim(r.node.Repo, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]HostedDataset, 0, len(infos))
+	for _, vi := range infos {
+		res = append(res, r.hostedDataset(ctx, vi.SimpleRef()))
+	}
+	return res, nil
+}
+
+// HostingStatus reports how a single dataset is stored by the remote
+func (r *Remote) HostingStatus(ctx context.Context, ref dsref.Ref) (*HostedDataset, error) {
+	if _, err := r.localResolver.ResolveRef(ctx, &ref); err != nil {
+		return nil, err
+	}
+	hd := r.hostedDataset(ctx, ref)
+	return &hd, nil
+}
+
+// Storage summarizes storage used by hosted datasets, listing up to limit
+// of the largest datasets. A negative limit lists all datasets
+func (r *Remote) Storage(ctx context.Context, limit int) (*HostingStorage, error) {
+	hosted, err := r.HostedDatasets(ctx, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	res := &HostingStorage{Datasets: len(hosted)}
+	for _, hd := range hosted {
+		res.StoredVersions += hd.StoredVersions
+		res.Size += hd.Size
+		if hd.Cached {
+			res.CachedSize += hd.Size
+		}
+	}
+	sort.SliceStable(hosted, func(i, j int) bool { return hosted[i].Size > hosted[j].Size })
+	if limit >= 0 && limit < len(hosted) {
+		hosted = hosted[:limit]
+	}
+	res.Largest = hosted
+	return res, nil
+}
+
+// DropDataset removes every stored version, the reference & history of a
+// hosted dataset. Unlike RemoveDataset, dropping is done by the remote's
+// operator & isn't subject to the access policy
+func (r *Remote) DropDataset(ctx context.Context, ref dsref.Ref) error {
+	if _, err := r.localResolver.ResolveRef(ctx, &ref); err != nil {
+		return err
+	}
+	if r.heads != nil {
+		if items, err := base.DatasetLog(ctx, r.node.Repo, ref, -1, 0, false); err == nil {
+			for _, item := range items {
+				if err := r.heads.Delete(item.Path); err != nil {
+					log.Debugw("drop deleting head", "path", item.Path, "err", err)
+				}
+			}
+		}
+	}
+	if err := r.evict(ctx, ref); err != nil {
+		return err
+	}
+	if r.cache != nil {
+		r.cache.Forget(ref)
+	}
+	log.Infow("dropped hosted dataset", "ref", ref.Alias())
+	return nil
+}
+
+func (r *Remote) hostedDataset(ctx context.Context, ref dsref.Ref) HostedDataset {
+	hd := HostedDataset{Ref: ref, Status: HostingMissing}
+	if r.cache != nil {
+		r.cache.lk.Lock()
+		_, hd.Cached = r.cache.entries[ref.Alias()]
+		r.cache.lk.Unlock()
+	}
+
+	items, err := base.DatasetLog(ctx, r.node.Repo, ref, -1, 0, false)
+	if err != nil {
+		log.Debugw("hosted dataset history", "ref", ref.String(), "err", err)
+		items = []dsref.VersionInfo{{Path: ref.Path}}
+	} else {
+		hd.Versions = len(items)
+	}
+
+	fs := r.node.Repo.Filesystem()
+	for _, item := range items {
+		if item.Path == "" {
+			continue
+		}
+		if local, err := fs.Has(ctx, item.Path); err != nil || !local {
+			continue
+		}
+		hd.StoredVersions++
+		if info, err := r.node.NewDAGInfo(ctx, item.Path, ""); err == nil && len(info.Sizes) > 0 {
+			hd.Size += info.Sizes[0]
+		}
+		if item.Path == ref.Path {
+			hd.Status = HostingStored
+		}
+	}
+	if hd.Status == HostingMissing && ref.Path != "" {
+		if _, err := r.heads.Get(ref.Path); err == nil {
+			hd.Status = HostingHeadOnly
+		}
+	}
+	return hd
+}
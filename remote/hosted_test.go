@@ -0,0 +1,59 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/qri-io/qri/dsref"
+)
+
+func TestHostedDatasets(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem := tr.NodeARemote(t)
+	wbp := writeWorldBankPopulation(tr.Ctx, t, tr.NodeA.Repo)
+
+	hosted, err := rem.HostedDatasets(tr.Ctx, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosted) != 1 {
+		t.Fatalf("expected 1 hosted dataset, got %d", len(hosted))
+	}
+	hd := hosted[0]
+	if hd.Ref.Alias() != wbp.Alias() || hd.Status != HostingStored {
+		t.Errorf("unexpected hosted dataset: %#v", hd)
+	}
+	if hd.Versions != 1 || hd.StoredVersions != 1 || hd.Size == 0 {
+		t.Errorf("expected one stored version with a size, got: %#v", hd)
+	}
+
+	status, err := rem.HostingStatus(tr.Ctx, dsref.Ref{Username: wbp.Username, Name: wbp.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Ref.Path != wbp.Path || status.Status != HostingStored {
+		t.Errorf("unexpected hosting status: %#v", status)
+	}
+
+	storage, err := rem.Storage(tr.Ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storage.Datasets != 1 || storage.Size != hd.Size || len(storage.Largest) != 1 {
+		t.Errorf("unexpected storage summary: %#v", storage)
+	}
+
+	if err := rem.DropDataset(tr.Ctx, dsref.Ref{Username: wbp.Username, Name: wbp.Name}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rem.HostingStatus(tr.Ctx, dsref.Ref{Username: wbp.Username, Name: wbp.Name}); err == nil {
+		t.Error("expected dropped dataset not to resolve")
+	}
+	if hosted, err = rem.HostedDatasets(tr.Ctx, 0, -1); err != nil {
+		t.Fatal(err)
+	}
+	if len(hosted) != 0 {
+		t.Errorf("expected no hosted datasets after drop, got: %#v", hosted)
+	}
+}
@@ -109,6 +109,21 @@ func (c *MockClient) PushDataset(ctx context.Context, ref dsref.Ref, remoteAddr
 	return ErrNotImplemented
 }
 
+// PushDatasetHead is not implemented
+func (c *MockClient) PushDatasetHead(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
+	return ErrNotImplemented
+}
+
+// PullDatasetHead is not implemented
+func (c *MockClient) PullDatasetHead(ctx context.Context, ref *dsref.Ref, remoteAddr string) (*dataset.Dataset, error) {
+	return nil, ErrNotImplemented
+}
+
+// PullLogs is not implemented
+func (c *MockClient) PullLogs(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
+	return ErrNotImplemented
+}
+
 // RemoveDataset is not implemented
 func (c *MockClient) RemoveDataset(ctx context.Context, ref dsref.Ref, remoteAddr string) error {
 	return ErrNotImplemented
@@ -119,6 +134,16 @@ func (c *MockClient) RemoveDatasetVersion(ctx context.Context, ref dsref.Ref, re
 	return ErrNotImplemented
 }
 
+// DatasetUsage is not implemented
+func (c *MockClient) DatasetUsage(ctx context.Context, ref dsref.Ref, remoteAddr string) (*DatasetUsage, error) {
+	return nil, ErrNotImplemented
+}
+
+// HookStatuses is not implemented
+func (c *MockClient) HookStatuses(ctx context.Context, ref dsref.Ref, remoteAddr string) ([]HookStatus, error) {
+	return nil, ErrNotImplemented
+}
+
 // PullDataset adds a reference to a dataset using test peer info
 func (c *MockClient) PullDataset(ctx context.Context, ref *dsref.Ref, remoteAddr string) (*dataset.Dataset, error) {
 	log.Debugf("MockClient.PullDataset ref=%q", ref)
@@ -0,0 +1,283 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/p2p"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+var (
+	// ErrPushQueued indicates a push couldn't reach its remote & was queued to
+	// be retried when connectivity returns
+	ErrPushQueued = errors.New("remote unreachable, push queued")
+
+	// PushRetryMin is the delay before the first retry of a queued push
+	PushRetryMin = time.Second * 30
+	// PushRetryMax caps the delay between retries of a queued push
+	PushRetryMax = time.Hour
+)
+
+// IsUnreachable returns true for errors that indicate a remote couldn't be
+// contacted, as opposed to a remote rejecting a request
+func IsUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, p2p.ErrNotConnected) || errors.Is(err, ErrNoRemoteClient) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// QueuedPush is a push waiting for its remote to become reachable
+type QueuedPush struct {
+	Ref dsref.Ref `json:"ref"`
+	// Remote is the name of the remote, empty for the registry
+	Remote      string    `json:"remote,omitempty"`
+	Addr        string    `json:"addr"`
+	Queued      time.Time `json:"queued"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+func (q *QueuedPush) key() string {
+	return q.Addr + " " + q.Ref.InitID
+}
+
+// PushFunc performs a push for a PushQueue
+type PushFunc func(ctx context.Context, ref dsref.Ref, addr string) error
+
+// PushQueue is a durable queue of pushes that failed because their remote
+// couldn't be reached. Queued pushes are retried with exponential backoff, and
+// immediately when the node reconnects to the network. Pushes for the same
+// dataset & remote are collapsed, keeping the most recent reference. The queue
+// is persisted as JSON to filename, an empty filename keeps the queue in
+// memory
+type PushQueue struct {
+	file jsonfile.File
+	push PushFunc
+
+	lk     sync.Mutex
+	pushes map[string]*QueuedPush
+	wake   chan struct{}
+}
+
+// NewPushQueue creates a push queue, loading any pushes persisted to filename
+func NewPushQueue(filename string, push PushFunc) (*PushQueue, error) {
+	q := &PushQueue{
+		file:   jsonfile.New(filename),
+		push:   push,
+		pushes: map[string]*QueuedPush{},
+		wake:   make(chan struct{}, 1),
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Enqueue records a push to retry later. cause is the error that prevented
+// the push
+func (q *PushQueue) Enqueue(ref dsref.Ref, remoteName, addr string, cause error) error {
+	qp := &QueuedPush{
+		Ref:         ref,
+		Remote:      remoteName,
+		Addr:        addr,
+		Queued:      time.Now(),
+		NextAttempt: time.Now().Add(PushRetryMin),
+	}
+	if cause != nil {
+		qp.LastError = cause.Error()
+	}
+
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	if prev, ok := q.pushes[qp.key()]; ok {
+		qp.Queued = prev.Queued
+	}
+	q.pushes[qp.key()] = qp
+	return q.save()
+}
+
+// List returns queued pushes, oldest first
+func (q *PushQueue) List() []QueuedPush {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	res := make([]QueuedPush, 0, len(q.pushes))
+	for _, qp := range q.pushes {
+		res = append(res, *qp)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Queued.Before(res[j].Queued)
+	})
+	return res
+}
+
+// Rename updates the name of queued pushes of a dataset, returning the
+// number of pushes changed
+func (q *PushQueue) Rename(initID, name string) int {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	n := 0
+	for _, qp := range q.pushes {
+		if qp.Ref.InitID == initID && qp.Ref.Name != name {
+			qp.Ref.Name = name
+			n++
+		}
+	}
+	if n > 0 {
+		if err := q.save(); err != nil {
+			log.Debugw("saving push queue", "err", err)
+		}
+	}
+	return n
+}
+
+// Len returns the number of queued pushes
+func (q *PushQueue) Len() int {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	return len(q.pushes)
+}
+
+// Wake retries all queued pushes now, regardless of backoff
+func (q *PushQueue) Wake() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run retries queued pushes until the context is cancelled. Run retries all
+// pushes when the node goes online or connects to a qri peer
+func (q *PushQueue) Run(ctx context.Context, bus event.Bus) {
+	if bus != nil {
+		bus.SubscribeTypes(func(ctx context.Context, e event.Event) error {
+			q.Wake()
+			return nil
+		}, event.ETP2PGoneOnline, event.ETP2PQriPeerConnected)
+	}
+
+	for {
+		timer := time.NewTimer(q.untilNext())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-q.wake:
+			timer.Stop()
+			q.Flush(ctx, true)
+		case <-timer.C:
+			q.Flush(ctx, false)
+		}
+	}
+}
+
+// Flush attempts queued pushes that are due, or all pushes if force is true.
+// Successful pushes are removed from the queue. Pushes that fail because the
+// remote is still unreachable are rescheduled with backoff, other failures
+// are dropped from the queue
+func (q *PushQueue) Flush(ctx context.Context, force bool) {
+	now := time.Now()
+	q.lk.Lock()
+	due := []QueuedPush{}
+	for _, qp := range q.pushes {
+		if force || !now.Before(qp.NextAttempt) {
+			due = append(due, *qp)
+		}
+	}
+	q.lk.Unlock()
+
+	for _, qp := range due {
+		err := q.push(ctx, qp.Ref, qp.Addr)
+
+		q.lk.Lock()
+		cur, ok := q.pushes[qp.key()]
+		if !ok || cur.Ref.Path != qp.Ref.Path {
+			// the push was replaced or removed while we were pushing
+			q.lk.Unlock()
+			continue
+		}
+		switch {
+		case err == nil:
+			log.Debugw("queued push succeeded", "ref", qp.Ref.String(), "addr", qp.Addr)
+			delete(q.pushes, qp.key())
+		case IsUnreachable(err):
+			cur.Attempts++
+			cur.LastError = err.Error()
+			cur.NextAttempt = time.Now().Add(pushBackoff(cur.Attempts))
+		default:
+			log.Errorf("dropping queued push of %s to %s: %s", qp.Ref.Alias(), qp.Addr, err)
+			delete(q.pushes, qp.key())
+		}
+		if err := q.save(); err != nil {
+			log.Debugw("saving push queue", "err", err)
+		}
+		q.lk.Unlock()
+	}
+}
+
+// untilNext returns the duration until the next queued push is due
+func (q *PushQueue) untilNext() time.Duration {
+	q.lk.Lock()
+	defer q.lk.Unlock()
+	next := PushRetryMax
+	for _, qp := range q.pushes {
+		if d := time.Until(qp.NextAttempt); d < next {
+			next = d
+		}
+	}
+	if next < 0 {
+		return 0
+	}
+	return next
+}
+
+// pushBackoff doubles the retry delay with each attempt, up to PushRetryMax
+func pushBackoff(attempts int) time.Duration {
+	d := PushRetryMin
+	for i := 0; i < attempts && d < PushRetryMax; i++ {
+		d *= 2
+	}
+	if d > PushRetryMax {
+		return PushRetryMax
+	}
+	return d
+}
+
+func (q *PushQueue) load() error {
+	pushes := []*QueuedPush{}
+	if err := q.file.Load(&pushes); err != nil {
+		return fmt.Errorf("reading push queue: %w", err)
+	}
+	for _, qp := range pushes {
+		q.pushes[qp.key()] = qp
+	}
+	return nil
+}
+
+// save writes the queue. must be called with the lock held
+func (q *PushQueue) save() error {
+	pushes := make([]*QueuedPush, 0, len(q.pushes))
+	for _, qp := range q.pushes {
+		pushes = append(pushes, qp)
+	}
+	return q.file.Save(pushes)
+}
@@ -0,0 +1,135 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/p2p"
+)
+
+func TestPushQueue(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "push_queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "push_queue.json")
+
+	var pushErr error
+	pushed := []string{}
+	push := func(ctx context.Context, ref dsref.Ref, addr string) error {
+		if pushErr != nil {
+			return pushErr
+		}
+		pushed = append(pushed, ref.Path)
+		return nil
+	}
+
+	q, err := NewPushQueue(filename, push)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := dsref.Ref{InitID: "a", Username: "peer", Name: "a", Path: "/mem/a1"}
+	b := dsref.Ref{InitID: "b", Username: "peer", Name: "b", Path: "/mem/b1"}
+	if err := q.Enqueue(a, "", "https://registry.qri.cloud", p2p.ErrNotConnected); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Enqueue(b, "origin", "/ipfs/QmRemote", p2p.ErrNotConnected); err != nil {
+		t.Fatal(err)
+	}
+	// enqueueing a newer version replaces the queued version
+	a.Path = "/mem/a2"
+	if err := q.Enqueue(a, "", "https://registry.qri.cloud", p2p.ErrNotConnected); err != nil {
+		t.Fatal(err)
+	}
+
+	// pushes should survive a restart
+	if q, err = NewPushQueue(filename, push); err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 2 {
+		t.Fatalf("expected 2 queued pushes, got %d", q.Len())
+	}
+
+	// nothing is due yet
+	ctx := context.Background()
+	q.Flush(ctx, false)
+	if len(pushed) != 0 {
+		t.Errorf("expected no pushes before retry is due, got %v", pushed)
+	}
+
+	pushErr = fmt.Errorf("dialing: %w", p2p.ErrNotConnected)
+	q.Flush(ctx, true)
+	if q.Len() != 2 {
+		t.Fatalf("expected unreachable pushes to remain queued, got %d", q.Len())
+	}
+	for _, qp := range q.List() {
+		if qp.Attempts != 1 {
+			t.Errorf("expected 1 attempt, got %d", qp.Attempts)
+		}
+		if qp.LastError != pushErr.Error() {
+			t.Errorf("last error mismatch. want %q got %q", pushErr, qp.LastError)
+		}
+	}
+
+	pushErr = nil
+	q.Flush(ctx, true)
+	if q.Len() != 0 {
+		t.Errorf("expected queue to be empty after successful pushes, got %d", q.Len())
+	}
+	if len(pushed) != 2 || (pushed[0] != "/mem/a2" && pushed[1] != "/mem/a2") {
+		t.Errorf("expected most recently queued version to be pushed, got %v", pushed)
+	}
+
+	// other errors drop the push
+	if err := q.Enqueue(b, "origin", "/ipfs/QmRemote", p2p.ErrNotConnected); err != nil {
+		t.Fatal(err)
+	}
+	pushErr = fmt.Errorf("permission denied")
+	q.Flush(ctx, true)
+	if q.Len() != 0 {
+		t.Errorf("expected rejected push to be dropped, got %d queued", q.Len())
+	}
+}
+
+func TestPushBackoff(t *testing.T) {
+	cases := []struct {
+		attempts int
+		expect   time.Duration
+	}{
+		{0, PushRetryMin},
+		{1, PushRetryMin * 2},
+		{3, PushRetryMin * 8},
+		{100, PushRetryMax},
+	}
+	for _, c := range cases {
+		if got := pushBackoff(c.attempts); got != c.expect {
+			t.Errorf("attempts %d: expected %s, got %s", c.attempts, c.expect, got)
+		}
+	}
+}
+
+func TestIsUnreachable(t *testing.T) {
+	cases := []struct {
+		err    error
+		expect bool
+	}{
+		{nil, false},
+		{p2p.ErrNotConnected, true},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), true},
+		{ErrNoRemoteClient, true},
+		{fmt.Errorf("dataset not found"), false},
+	}
+	for _, c := range cases {
+		if got := IsUnreachable(c.err); got != c.expect {
+			t.Errorf("%v: expected %t, got %t", c.err, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package remote
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	crypto "github.com/libp2p/go-libp2p-core/crypto"
+	apiutil "github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/dsref"
+)
+
+const (
+	// ReceiptActionPush is the receipt action for an accepted push
+	ReceiptActionPush = "push"
+	// ReceiptActionRemove is the receipt action for an accepted unpublish
+	ReceiptActionRemove = "remove"
+)
+
+// Receipt is a remote's signed acknowledgement that it accepted a push or
+// unpublish of a dataset version. Receipts carry the remote's public key,
+// anyone holding a receipt can check it was signed by the remote it names
+type Receipt struct {
+	// Action is the accepted action, one of "push" or "remove"
+	Action string `json:"action"`
+	// InitID is the dataset identifier
+	InitID string `json:"initID"`
+	// Head is the path of the dataset version the action applied to
+	Head string `json:"head"`
+	// Timestamp is the time the remote issued the receipt
+	Timestamp time.Time `json:"timestamp"`
+	// RemoteID is the profile ID of the remote
+	RemoteID string `json:"remoteID"`
+	// PubKey is the base64 encoded public key of the remote
+	PubKey string `json:"pubKey"`
+	// Signature is a base64 encoded signature of the action, InitID, Head &
+	// Timestamp made with the remote's private key
+	Signature string `json:"signature"`
+}
+
+// NewReceipt creates a receipt for an action on a dataset version, signed
+// with a remote's private key
+func NewReceipt(pk crypto.PrivKey, action, initID, head string) (*Receipt, error) {
+	if pk == nil {
+		return nil, fmt.Errorf("a private key is required to sign a receipt")
+	}
+	pid, err := calcProfileID(pk)
+	if err != nil {
+		return nil, err
+	}
+	pkBytes, err := crypto.MarshalPublicKey(pk.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+
+	rcpt := &Receipt{
+		Action:    action,
+		InitID:    initID,
+		Head:      head,
+		Timestamp: nowFunc().In(time.UTC),
+		RemoteID:  pid,
+		PubKey:    base64.StdEncoding.EncodeToString(pkBytes),
+	}
+	if rcpt.Signature, err = signString(pk, rcpt.signingString()); err != nil {
+		return nil, err
+	}
+	return rcpt, nil
+}
+
+func (rcpt *Receipt) signingString() string {
+	return fmt.Sprintf("%s.%s.%s.%d", rcpt.Action, rcpt.InitID, rcpt.Head, rcpt.Timestamp.UnixNano())
+}
+
+// Verify checks the receipt signature was made by the key of the remote the
+// receipt names
+func (rcpt *Receipt) Verify() error {
+	pkBytes, err := base64.StdEncoding.DecodeString(rcpt.PubKey)
+	if err != nil {
+		return fmt.Errorf("decoding public key: %w", err)
+	}
+	pubKey, err := crypto.UnmarshalPublicKey(pkBytes)
+	if err != nil {
+		return fmt.Errorf("decoding public key: %w", err)
+	}
+	if keyID, err := calcPubKeyProfileID(pubKey); err != nil || keyID != rcpt.RemoteID {
+		return fmt.Errorf("public key doesn't match remote ID")
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(rcpt.Signature)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+	if ok, err := pubKey.Verify([]byte(rcpt.signingString()), sigBytes); err != nil || !ok {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// Encode serializes a receipt to a string for storage
+func (rcpt *Receipt) Encode() (string, error) {
+	data, err := json.Marshal(rcpt)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeReceipt deserializes a receipt created with Encode
+func DecodeReceipt(s string) (*Receipt, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decoding receipt: %w", err)
+	}
+	rcpt := &Receipt{}
+	if err := json.Unmarshal(data, rcpt); err != nil {
+		return nil, fmt.Errorf("decoding receipt: %w", err)
+	}
+	return rcpt, nil
+}
+
+// PushReceipt issues a receipt for a pushed dataset version. The remote must
+// have the version the reference points to
+func (r *Remote) PushReceipt(ctx context.Context, ref dsref.Ref) (*Receipt, error) {
+	path := ref.Path
+	if _, err := r.localResolver.ResolveRef(ctx, &ref); err != nil {
+		return nil, err
+	}
+	if path != "" {
+		ref.Path = path
+	}
+	if has, err := r.node.Repo.Filesystem().Has(ctx, ref.Path); err != nil || !has {
+		return nil, fmt.Errorf("%w: version %q", dsref.ErrRefNotFound, ref.Path)
+	}
+	return NewReceipt(r.node.Repo.Profiles().Owner().PrivKey, ReceiptActionPush, ref.InitID, ref.Path)
+}
+
+// removeReceipt issues a receipt for an unpublished dataset. The dataset is
+// no longer held by the remote, the receipt records the reference the
+// requester asked to remove
+func (r *Remote) removeReceipt(params map[string]string) (*Receipt, error) {
+	return NewReceipt(r.node.Repo.Profiles().Owner().PrivKey, ReceiptActionRemove, params["initID"], params["path"])
+}
+
+// ReceiptsHTTPHandler issues receipts for pushed dataset versions
+func (r *Remote) ReceiptsHTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			apiutil.NotFoundHandler(w, req)
+			return
+		}
+
+		ref := dsref.Ref{
+			Username: req.FormValue("username"),
+			Name:     req.FormValue("name"),
+			Path:     req.FormValue("path"),
+		}
+		rcpt, err := r.PushReceipt(req.Context(), ref)
+		if err != nil {
+			if errors.Is(err, dsref.ErrRefNotFound) {
+				apiutil.WriteErrResponse(w, http.StatusNotFound, err)
+				return
+			}
+			apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		apiutil.WriteResponse(w, rcpt)
+	}
+}
+
+// fetchPushReceipt requests a receipt for a pushed dataset version
+func fetchPushReceipt(ctx context.Context, ref dsref.Ref, remoteAddr string) (*Receipt, error) {
+	u, err := url.Parse(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/remote/receipts"
+	q := u.Query()
+	q.Set("username", ref.Username)
+	q.Set("name", ref.Name)
+	q.Set("path", ref.Path)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return decodeReceiptResponse(res)
+}
+
+// decodeReceiptResponse reads a receipt from an API response envelope
+func decodeReceiptResponse(res *http.Response) (*Receipt, error) {
+	env := struct {
+		Data *Receipt
+		Meta struct {
+			Error string
+		}
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&env); err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error %d: %s", res.StatusCode, env.Meta.Error)
+	}
+	if env.Data == nil {
+		return nil, fmt.Errorf("remote didn't issue a receipt")
+	}
+	return env.Data, nil
+}
+
+// recordReceipt checks a receipt matches a dataset version & stores it on
+// the latest push or unpublish operation in the dataset log
+func (c *client) recordReceipt(ctx context.Context, ref dsref.Ref, rcpt *Receipt) error {
+	if err := rcpt.Verify(); err != nil {
+		return err
+	}
+	if rcpt.InitID != ref.InitID || (ref.Path != "" && rcpt.Head != ref.Path) {
+		return fmt.Errorf("receipt doesn't match dataset %s", ref)
+	}
+	enc, err := rcpt.Encode()
+	if err != nil {
+		return err
+	}
+	c.bookLk.Lock()
+	defer c.bookLk.Unlock()
+	return c.node.Repo.Logbook().WriteRemoteReceipt(ctx, ref.InitID, enc)
+}
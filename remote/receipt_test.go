@@ -0,0 +1,58 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	testkeys "github.com/qri-io/qri/auth/key/test"
+)
+
+func TestReceipt(t *testing.T) {
+	kd0 := testkeys.GetKeyData(0)
+	rcpt, err := NewReceipt(kd0.PrivKey, ReceiptActionPush, "init_id", "/ipfs/QmHead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rcpt.Verify(); err != nil {
+		t.Errorf("expected receipt to verify, got: %s", err)
+	}
+	if rcpt.RemoteID != kd0.EncodedPeerID {
+		t.Errorf("remote ID mismatch. want: %q got: %q", kd0.EncodedPeerID, rcpt.RemoteID)
+	}
+
+	enc, err := rcpt.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeReceipt(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(rcpt, got); diff != "" {
+		t.Errorf("decoded receipt mismatch (-want +got):\n%s", diff)
+	}
+	if err := got.Verify(); err != nil {
+		t.Errorf("expected decoded receipt to verify, got: %s", err)
+	}
+
+	tampered := *rcpt
+	tampered.Head = "/ipfs/QmOtherHead"
+	if err := tampered.Verify(); err == nil {
+		t.Error("expected receipt with a modified head to fail verification")
+	}
+
+	kd1 := testkeys.GetKeyData(1)
+	other, err := NewReceipt(kd1.PrivKey, ReceiptActionPush, "init_id", "/ipfs/QmHead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	swapped := *rcpt
+	swapped.PubKey = other.PubKey
+	if err := swapped.Verify(); err == nil {
+		t.Error("expected receipt with a swapped public key to fail verification")
+	}
+
+	if _, err := DecodeReceipt("not a receipt"); err == nil {
+		t.Error("expected decoding an invalid receipt to fail")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -86,6 +87,32 @@ type Options struct {
 	Previews
 	// Policy defines the access control for the remote
 	Policy *access.Policy
+	// Redirector maps previous names of renamed datasets to current names.
+	// A nil Redirector doesn't resolve previous names
+	Redirector Redirector
+
+	// PullThroughClient fetches datasets from PullThroughOrigin when the remote
+	// is configured in pull-through mode
+	PullThroughClient Client
+	// PullThroughOrigin is the address pull-through datasets are fetched from
+	PullThroughOrigin string
+	// PullThroughIndexPath is the file pull-through cache state is stored in.
+	// The empty string keeps cache state in memory
+	PullThroughIndexPath string
+
+	// UsageStatsPath is the file usage stats are stored in when usage stats
+	// are enabled in the remote configuration. The empty string keeps usage
+	// stats in memory
+	UsageStatsPath string
+
+	// Heads stores dataset heads pushed in metadata-only mode. A nil store
+	// refuses metadata-only pushes
+	Heads *HeadStore
+
+	// PostReceiveHooks run in the background after each accepted push
+	PostReceiveHooks []ProcessHook
+	// PostPullHooks run in the background after a client pulls a version
+	PostPullHooks []ProcessHook
 }
 
 // Remote receives requests from other qri nodes to perform actions on their
@@ -117,6 +144,23 @@ type Remote struct {
 
 	// policy defines the access control for the remote
 	policy *access.Policy
+	// cache is non-nil when the remote is in pull-through mode
+	cache *pullThroughCache
+	// usage is non-nil when the remote records usage stats
+	usage *usageTracker
+	// heads keeps dataset heads pushed in metadata-only mode
+	heads *HeadStore
+	// hooks is non-nil when the remote has processing hooks
+	hooks *hookRunner
+	// redirector is non-nil when the remote resolves previous dataset names
+	redirector Redirector
+}
+
+// Redirector maps the previous alias of a renamed dataset to its current alias
+type Redirector interface {
+	// Redirect returns the current alias for a previous alias like
+	// "username/name"
+	Redirect(alias string) (string, error)
 }
 
 // OptPolicy adds a policy to the remote options
@@ -126,6 +170,33 @@ func OptPolicy(p *access.Policy) OptionsFunc {
 	}
 }
 
+// OptPullThrough supplies the client, origin address, and cache index file
+// a remote uses in pull-through mode. Pull-through mode must also be enabled
+// in the remote configuration
+func OptPullThrough(client Client, origin, indexPath string) OptionsFunc {
+	return func(o *Options) {
+		o.PullThroughClient = client
+		o.PullThroughOrigin = origin
+		o.PullThroughIndexPath = indexPath
+	}
+}
+
+// OptUsageStats sets the file a remote stores usage stats in. Usage stats
+// must also be enabled in the remote configuration
+func OptUsageStats(filename string) OptionsFunc {
+	return func(o *Options) {
+		o.UsageStatsPath = filename
+	}
+}
+
+// OptHeads sets the store a remote keeps heads pushed in metadata-only mode
+// in, enabling metadata-only pushes
+func OptHeads(heads *HeadStore) OptionsFunc {
+	return func(o *Options) {
+		o.Heads = heads
+	}
+}
+
 // OptLoadPolicyFileIfExists checks for a policy at the given path and populates
 // the remote.Options.Policy if so
 func OptLoadPolicyFileIfExists(filename string) OptionsFunc {
@@ -176,6 +247,9 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, localResolver dsref.Resolv
 		datasetPullPreCheck:   o.DatasetPullPreCheck,
 		datasetPulled:         o.DatasetPulled,
 		policy:                o.Policy,
+		heads:                 o.Heads,
+		hooks:                 newHookRunner(o.PostReceiveHooks, o.PostPullHooks),
+		redirector:            o.Redirector,
 
 		FeedPreCheck:    o.FeedPreCheck,
 		PreviewPreCheck: o.PreviewPreCheck,
@@ -193,6 +267,22 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, localResolver dsref.Resolv
 		r.Previews = LocalPreviews{
 			fs:            node.Repo.Filesystem(),
 			localResolver: localResolver,
+			book:          node.Repo.Logbook(),
+			heads:         o.Heads,
+		}
+	}
+
+	if cfg.PullThrough {
+		var err error
+		if r.cache, err = newPullThroughCache(cfg, o.PullThroughClient, o.PullThroughOrigin, o.PullThroughIndexPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.UsageStats {
+		var err error
+		if r.usage, err = newUsageTracker(o.UsageStatsPath); err != nil {
+			return nil, err
 		}
 	}
 
@@ -207,7 +297,7 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, localResolver dsref.Resolv
 	}
 
 	r.dsync, err = dsync.New(lng, capi.Block(), func(dsyncConfig *dsync.Config) {
-		if host := r.node.Host(); host != nil {
+		if host := r.node.ThrottledHost(); host != nil {
 			dsyncConfig.Libp2pHost = host
 		}
 
@@ -230,7 +320,7 @@ func NewRemote(node *p2p.QriNode, cfg *config.Remote, localResolver dsref.Resolv
 			lso.PushPreCheck = r.logPreCheckHook("PushPreCheck", "remote:push", o.LogPushPreCheck)
 			lso.PushFinalCheck = r.logHook("PushFinalCheck", o.LogPushFinalCheck)
 			lso.Pushed = r.logHook("Pushed", o.LogPushed)
-			lso.PullPreCheck = r.logPreCheckHook("PullPreCheck", "remote:pull", o.LogPullPreCheck)
+			lso.PullPreCheck = r.pullThroughHook(r.logPreCheckHook("PullPreCheck", "remote:pull", o.LogPullPreCheck))
 			lso.Pulled = r.logHook("Pulled", o.LogPulled)
 			lso.RemovePreCheck = r.logPreCheckHook("RemovePreCheck", "remote:remove", o.LogRemovePreCheck)
 			lso.Removed = r.logHook("Removed", o.LogRemoved)
@@ -419,13 +509,25 @@ func (r *Remote) dsPushComplete(ctx context.Context, info dag.Info, meta map[str
 		}
 	}
 
+	if r.cache != nil {
+		// pushed datasets are no longer cached copies, never evict them
+		r.cache.Forget(ref)
+	}
+
 	vi := ref.VersionInfo()
 	// mark ref as published b/c someone just published to us
 	vi.Published = true
 
 	// TODO (b5) - this could overwrite any FSI links & other ref details,
 	// need to investigate
-	return repo.PutVersionInfoShim(ctx, r.node.Repo, &vi)
+	if err := repo.PutVersionInfoShim(ctx, r.node.Repo, &vi); err != nil {
+		return err
+	}
+
+	if r.hooks != nil {
+		r.hooks.Run(HookStagePush, pid, ref)
+	}
+	return nil
 }
 
 func (r *Remote) dsRemovePreCheck(ctx context.Context, info dag.Info, meta map[string]string) error {
@@ -459,12 +561,30 @@ func (r *Remote) dsGetDagInfo(ctx context.Context, into dag.Info, meta map[strin
 	pid := subj.ID
 	log.Debugf("pid %s pulling ref %s", pid.String(), ref.String())
 
+	if r.cache != nil {
+		r.cache.Touch(ref)
+	}
+
+	if r.usage != nil {
+		// clients may already have some blocks, making the size of the DAG an
+		// upper bound on bytes actually transferred
+		var size uint64
+		for _, s := range into.Sizes {
+			size += s
+		}
+		r.usage.Record(ref, pid, size)
+	}
+
 	if r.datasetPulled != nil {
 		if err = r.datasetPulled(ctx, pid, ref); err != nil {
 			log.Errorf("dataset pulled hook: %s", err.Error())
 			return err
 		}
 	}
+
+	if r.hooks != nil {
+		r.hooks.Run(HookStagePull, pid, ref)
+	}
 	return nil
 }
 
@@ -494,6 +614,37 @@ func (r *Remote) subjAndRefFromMeta(meta map[string]string) (*profile.Profile, d
 	return pro, ref, err
 }
 
+// resolveOrPullThrough resolves a reference locally. In pull-through mode,
+// references that can't be resolved are pulled from the origin
+func (r *Remote) resolveOrPullThrough(ctx context.Context, ref *dsref.Ref) error {
+	_, err := r.localResolver.ResolveRef(ctx, ref)
+	if r.cache == nil {
+		return err
+	}
+	if err == nil {
+		r.cache.Touch(*ref)
+		return nil
+	}
+	if errors.Is(err, dsref.ErrRefNotFound) || errors.Is(err, logbook.ErrNotFound) {
+		return r.pullThrough(ctx, ref)
+	}
+	return err
+}
+
+// pullThroughHook wraps a log pull check, ensuring the requested dataset is
+// present before logs are served. Checks in h run before any data is pulled
+func (r *Remote) pullThroughHook(h logsync.Hook) logsync.Hook {
+	return func(ctx context.Context, author profile.Author, ref dsref.Ref, l *oplog.Log) error {
+		if err := h(ctx, author, ref, l); err != nil {
+			return err
+		}
+		if r.cache == nil {
+			return nil
+		}
+		return r.resolveOrPullThrough(ctx, &ref)
+	}
+}
+
 func (r *Remote) logHook(name string, h Hook) logsync.Hook {
 	return func(ctx context.Context, author profile.Author, ref dsref.Ref, l *oplog.Log) error {
 		if h != nil {
@@ -561,6 +712,10 @@ func (r *Remote) AddDefaultRoutes(mux *http.ServeMux) {
 	mux.Handle("/remote/dsync", r.DsyncHTTPHandler())
 	mux.Handle("/remote/logsync", r.LogsyncHTTPHandler())
 	mux.Handle("/remote/refs", r.RefsHTTPHandler())
+	mux.Handle("/remote/usage", r.UsageHTTPHandler())
+	mux.Handle("/remote/receipts", r.ReceiptsHTTPHandler())
+	mux.Handle("/remote/hooks", r.HooksHTTPHandler())
+	mux.Handle("/remote/dataset/head", r.HeadHTTPHandler())
 
 	if fs := r.Feeds; fs != nil {
 		mux.Handle("/remote/feeds", r.FeedsHTTPHandler())
@@ -574,12 +729,12 @@ func (r *Remote) AddDefaultRoutes(mux *http.ServeMux) {
 
 // DsyncHTTPHandler provides an http handler for dsync
 func (r *Remote) DsyncHTTPHandler() http.HandlerFunc {
-	return dsync.HTTPRemoteHandler(r.dsync)
+	return r.throttleHTTP("/remote/dsync", dsync.HTTPRemoteHandler(r.dsync))
 }
 
 // LogsyncHTTPHandler provides an http handler for synchronizing logs
 func (r *Remote) LogsyncHTTPHandler() http.HandlerFunc {
-	return logsync.HTTPHandler(r.logsync)
+	return r.throttleHTTP("/remote/logsync", logsync.HTTPHandler(r.logsync))
 }
 
 // FeedsHTTPHandler provides access to the home feed
@@ -681,10 +836,17 @@ func (r *Remote) RefsHTTPHandler() http.HandlerFunc {
 				Path:     req.FormValue("path"),
 			}
 
-			if _, err := r.localResolver.ResolveRef(req.Context(), ref); err != nil {
-				w.WriteHeader(http.StatusNotFound)
-				w.Write([]byte(err.Error()))
-				return
+			status := http.StatusOK
+			if err := r.resolveOrPullThrough(req.Context(), ref); err != nil {
+				if !r.resolveRedirect(req.Context(), ref, err) {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(err.Error()))
+					return
+				}
+				// the dataset was renamed. respond with the current reference,
+				// marked as moved
+				status = http.StatusMovedPermanently
+				w.Header().Set("Location", refsPath(*ref))
 			}
 
 			res, err := json.Marshal(ref)
@@ -695,7 +857,7 @@ func (r *Remote) RefsHTTPHandler() http.HandlerFunc {
 			}
 
 			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
+			w.WriteHeader(status)
 			w.Write(res)
 			return
 		case "DELETE":
@@ -709,10 +871,49 @@ func (r *Remote) RefsHTTPHandler() http.HandlerFunc {
 				return
 			}
 
-			w.WriteHeader(http.StatusOK)
+			rcpt, err := r.removeReceipt(params)
+			if err != nil {
+				log.Debugw("issuing remove receipt", "err", err)
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			apiutil.WriteResponse(w, rcpt)
 			return
 		default:
 			w.WriteHeader(http.StatusNotFound)
 		}
 	}
 }
+
+// resolveRedirect resolves the current name of a dataset that couldn't be
+// found under a previous name, replacing ref on success
+func (r *Remote) resolveRedirect(ctx context.Context, ref *dsref.Ref, err error) bool {
+	if r.redirector == nil || !(errors.Is(err, dsref.ErrRefNotFound) || errors.Is(err, logbook.ErrNotFound)) {
+		return false
+	}
+	to, err := r.redirector.Redirect(ref.Alias())
+	if err != nil {
+		return false
+	}
+	moved, err := dsref.Parse(to)
+	if err != nil {
+		log.Debugw("parsing redirect", "from", ref.Alias(), "to", to, "err", err)
+		return false
+	}
+	moved.Path = ref.Path
+	if err := r.resolveOrPullThrough(ctx, &moved); err != nil {
+		return false
+	}
+	log.Debugw("redirecting renamed dataset", "from", ref.Alias(), "to", moved.Alias())
+	*ref = moved
+	return true
+}
+
+// refsPath is the refs route that resolves a dataset reference
+func refsPath(ref dsref.Ref) string {
+	q := url.Values{}
+	q.Set("username", ref.Username)
+	q.Set("name", ref.Name)
+	q.Set("path", ref.Path)
+	return "/remote/refs?" + q.Encode()
+}
@@ -3,11 +3,13 @@ package remote
 import (
 	"encoding/base64"
 	"fmt"
+	"net/http"
 	"time"
 
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/multiformats/go-multihash"
 	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/profile"
 )
 
 var (
@@ -33,6 +35,7 @@ func sigParams(pk crypto.PrivKey, subjectUsername string, ref dsref.Ref) (map[st
 		"peername":  ref.Username,
 		"name":      ref.Name,
 		"profileID": ref.ProfileID,
+		"initID":    ref.InitID,
 		"path":      ref.Path,
 
 		"pid": pid,
@@ -96,7 +99,11 @@ func signString(privKey crypto.PrivKey, str string) (b64Sig string, err error) {
 }
 
 func calcProfileID(privKey crypto.PrivKey) (string, error) {
-	pubkeybytes, err := privKey.GetPublic().Bytes()
+	return calcPubKeyProfileID(privKey.GetPublic())
+}
+
+func calcPubKeyProfileID(pubKey crypto.PubKey) (string, error) {
+	pubkeybytes, err := pubKey.Bytes()
 	if err != nil {
 		return "", fmt.Errorf("error getting pubkey bytes: %s", err.Error())
 	}
@@ -108,3 +115,37 @@ func calcProfileID(privKey crypto.PrivKey) (string, error) {
 
 	return mh.B58String(), nil
 }
+
+// verifySignedHTTPRequest checks the signature of a request signed with
+// signHTTPRequest that also carries the signer's public key in a "pubkey"
+// header, returning the ID of the signing profile
+func verifySignedHTTPRequest(req *http.Request) (profile.ID, error) {
+	pid := req.Header.Get("pid")
+	timestamp := req.Header.Get("timestamp")
+	signature := req.Header.Get("signature")
+	if pid == "" || timestamp == "" || signature == "" {
+		return "", fmt.Errorf("missing signature details")
+	}
+
+	pkBytes, err := base64.StdEncoding.DecodeString(req.Header.Get("pubkey"))
+	if err != nil {
+		return "", fmt.Errorf("decoding public key: %w", err)
+	}
+	pubKey, err := crypto.UnmarshalPublicKey(pkBytes)
+	if err != nil {
+		return "", fmt.Errorf("decoding public key: %w", err)
+	}
+	if keyID, err := calcPubKeyProfileID(pubKey); err != nil || keyID != pid {
+		return "", fmt.Errorf("public key doesn't match profile ID")
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return "", fmt.Errorf("decoding signature: %w", err)
+	}
+	if ok, err := pubKey.Verify([]byte(requestSigningString(timestamp, pid, req.URL.Path)), sigBytes); err != nil || !ok {
+		return "", fmt.Errorf("invalid signature")
+	}
+
+	return profile.IDB58Decode(pid)
+}
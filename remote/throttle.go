@@ -0,0 +1,44 @@
+package remote
+
+import (
+	"io"
+	"net"
+	"net/http"
+)
+
+// throttleHTTP limits a handler to the bandwidth caps of the remote's node,
+// counting data transferred under the proto protocol. HTTP clients aren't
+// libp2p peers, per-peer caps apply to each client address
+func (r *Remote) throttleHTTP(proto string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		bw := r.node.Bandwidth()
+		if bw == nil {
+			handler(w, req)
+			return
+		}
+
+		client := req.RemoteAddr
+		if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+			client = host
+		}
+		ctx := req.Context()
+		if req.Body != nil {
+			req.Body = readCloser{Reader: bw.Reader(ctx, client, proto, req.Body), Closer: req.Body}
+		}
+		handler(throttledResponseWriter{ResponseWriter: w, w: bw.Writer(ctx, client, proto, w)}, req)
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type throttledResponseWriter struct {
+	http.ResponseWriter
+	w io.Writer
+}
+
+func (tw throttledResponseWriter) Write(p []byte) (int, error) {
+	return tw.w.Write(p)
+}
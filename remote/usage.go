@@ -0,0 +1,193 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	apiutil "github.com/qri-io/qri/api/util"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/profile"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+var (
+	// ErrUsageStatsDisabled indicates a remote isn't recording usage stats
+	ErrUsageStatsDisabled = errors.New("remote isn't collecting usage stats")
+	// ErrNotDatasetOwner indicates a request for usage stats was made by a
+	// profile that doesn't own the dataset
+	ErrNotDatasetOwner = errors.New("only the dataset owner can view usage stats")
+)
+
+// VersionUsage aggregates pulls of a single dataset version
+type VersionUsage struct {
+	Path        string    `json:"path"`
+	Pulls       int       `json:"pulls"`
+	UniquePeers int       `json:"uniquePeers"`
+	BytesServed uint64    `json:"bytesServed"`
+	LastPulled  time.Time `json:"lastPulled"`
+}
+
+// DatasetUsage aggregates pulls of all versions of a dataset. UniquePeers
+// counts each peer once, no matter how many versions they've pulled
+type DatasetUsage struct {
+	Ref         string         `json:"ref"`
+	Pulls       int            `json:"pulls"`
+	UniquePeers int            `json:"uniquePeers"`
+	BytesServed uint64         `json:"bytesServed"`
+	LastPulled  time.Time      `json:"lastPulled,omitempty"`
+	Versions    []VersionUsage `json:"versions"`
+}
+
+// usageRecord accumulates pulls of a dataset version
+type usageRecord struct {
+	Pulls       int                 `json:"pulls"`
+	BytesServed uint64              `json:"bytesServed"`
+	LastPulled  time.Time           `json:"lastPulled"`
+	Peers       map[string]struct{} `json:"peers"`
+}
+
+// usageTracker records dataset pulls served by a remote, keyed by dataset
+// alias, then version path. Usage is persisted as JSON to indexPath, an empty
+// indexPath keeps usage in memory
+type usageTracker struct {
+	file jsonfile.File
+
+	lk       sync.Mutex
+	datasets map[string]map[string]*usageRecord
+}
+
+func newUsageTracker(indexPath string) (*usageTracker, error) {
+	u := &usageTracker{
+		file:     jsonfile.New(indexPath),
+		datasets: map[string]map[string]*usageRecord{},
+	}
+	if err := u.load(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Record adds a pull of a dataset version by a peer
+func (u *usageTracker) Record(ref dsref.Ref, pid profile.ID, size uint64) {
+	u.lk.Lock()
+	defer u.lk.Unlock()
+
+	versions, ok := u.datasets[ref.Alias()]
+	if !ok {
+		versions = map[string]*usageRecord{}
+		u.datasets[ref.Alias()] = versions
+	}
+	rec, ok := versions[ref.Path]
+	if !ok {
+		rec = &usageRecord{Peers: map[string]struct{}{}}
+		versions[ref.Path] = rec
+	}
+	rec.Pulls++
+	rec.BytesServed += size
+	rec.LastPulled = time.Now()
+	rec.Peers[pid.String()] = struct{}{}
+
+	if err := u.save(); err != nil {
+		log.Debugw("saving usage stats", "err", err)
+	}
+}
+
+// Usage aggregates recorded pulls of a dataset. Versions are ordered most
+// recently pulled first
+func (u *usageTracker) Usage(alias string) *DatasetUsage {
+	u.lk.Lock()
+	defer u.lk.Unlock()
+
+	res := &DatasetUsage{Ref: alias, Versions: []VersionUsage{}}
+	peers := map[string]struct{}{}
+	for path, rec := range u.datasets[alias] {
+		res.Versions = append(res.Versions, VersionUsage{
+			Path:        path,
+			Pulls:       rec.Pulls,
+			UniquePeers: len(rec.Peers),
+			BytesServed: rec.BytesServed,
+			LastPulled:  rec.LastPulled,
+		})
+		res.Pulls += rec.Pulls
+		res.BytesServed += rec.BytesServed
+		if rec.LastPulled.After(res.LastPulled) {
+			res.LastPulled = rec.LastPulled
+		}
+		for pid := range rec.Peers {
+			peers[pid] = struct{}{}
+		}
+	}
+	res.UniquePeers = len(peers)
+	sort.Slice(res.Versions, func(i, j int) bool {
+		return res.Versions[i].LastPulled.After(res.Versions[j].LastPulled)
+	})
+	return res
+}
+
+func (u *usageTracker) load() error {
+	if err := u.file.Load(&u.datasets); err != nil {
+		return fmt.Errorf("reading usage stats: %w", err)
+	}
+	return nil
+}
+
+// save writes usage stats. must be called with the lock held
+func (u *usageTracker) save() error {
+	return u.file.Save(u.datasets)
+}
+
+// DatasetUsage returns usage stats for a dataset on behalf of a requesting
+// profile. Only the dataset owner may view usage stats
+func (r *Remote) DatasetUsage(ctx context.Context, pid profile.ID, ref dsref.Ref) (*DatasetUsage, error) {
+	if r.usage == nil {
+		return nil, ErrUsageStatsDisabled
+	}
+	if _, err := r.localResolver.ResolveRef(ctx, &ref); err != nil {
+		return nil, err
+	}
+	if ref.ProfileID != pid.String() {
+		return nil, ErrNotDatasetOwner
+	}
+	return r.usage.Usage(ref.Alias()), nil
+}
+
+// UsageHTTPHandler serves dataset usage stats to dataset owners. Requests
+// must be signed & include the public key of the requesting profile
+func (r *Remote) UsageHTTPHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			apiutil.NotFoundHandler(w, req)
+			return
+		}
+
+		pid, err := verifySignedHTTPRequest(req)
+		if err != nil {
+			apiutil.WriteErrResponse(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		ref := dsref.Ref{
+			Username: req.FormValue("username"),
+			Name:     req.FormValue("name"),
+		}
+		res, err := r.DatasetUsage(req.Context(), pid, ref)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrNotDatasetOwner):
+				apiutil.WriteErrResponse(w, http.StatusForbidden, err)
+			case errors.Is(err, dsref.ErrRefNotFound):
+				apiutil.WriteErrResponse(w, http.StatusNotFound, err)
+			default:
+				apiutil.WriteErrResponse(w, http.StatusBadRequest, err)
+			}
+			return
+		}
+
+		apiutil.WriteResponse(w, res)
+	}
+}
@@ -0,0 +1,98 @@
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/profile"
+)
+
+func TestUsageTracker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "remote_usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "usage.json")
+
+	u, err := newUsageTracker(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := dsref.Ref{Username: "peer", Name: "cities", Path: "/mem/v1"}
+	v2 := dsref.Ref{Username: "peer", Name: "cities", Path: "/mem/v2"}
+	u.Record(v1, profile.ID("a"), 100)
+	u.Record(v1, profile.ID("b"), 100)
+	u.Record(v1, profile.ID("a"), 100)
+	u.Record(v2, profile.ID("a"), 50)
+	u.Record(dsref.Ref{Username: "peer", Name: "movies", Path: "/mem/v3"}, profile.ID("c"), 10)
+
+	// reload to check usage is persisted
+	if u, err = newUsageTracker(filename); err != nil {
+		t.Fatal(err)
+	}
+	usage := u.Usage("peer/cities")
+	if usage.Pulls != 4 || usage.UniquePeers != 2 || usage.BytesServed != 350 {
+		t.Errorf("unexpected dataset usage: %#v", usage)
+	}
+	if len(usage.Versions) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(usage.Versions))
+	}
+	if v := usage.Versions[0]; v.Path != "/mem/v2" || v.Pulls != 1 || v.UniquePeers != 1 {
+		t.Errorf("expected most recently pulled version first, got: %#v", v)
+	}
+	if v := usage.Versions[1]; v.Pulls != 3 || v.UniquePeers != 2 || v.BytesServed != 300 {
+		t.Errorf("unexpected version usage: %#v", v)
+	}
+
+	if usage := u.Usage("peer/unknown"); usage.Pulls != 0 || len(usage.Versions) != 0 {
+		t.Errorf("expected empty usage for unknown dataset, got: %#v", usage)
+	}
+}
+
+func TestDatasetUsageHTTP(t *testing.T) {
+	tr, cleanup := newTestRunner(t)
+	defer cleanup()
+
+	rem, err := NewRemote(tr.NodeA, &config.Remote{Enabled: true, UsageStats: true}, tr.NodeA.Repo.Logbook())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := tr.RemoteTestServer(rem)
+	defer server.Close()
+
+	wbp := writeWorldBankPopulation(tr.Ctx, t, tr.NodeA.Repo)
+	cli := tr.NodeBClient(t)
+	if _, err := cli.PullDataset(tr.Ctx, &wbp, server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cli.DatasetUsage(tr.Ctx, wbp, server.URL); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected usage request from non-owner to be forbidden, got: %v", err)
+	}
+
+	owner, err := NewClient(tr.Ctx, tr.NodeA, tr.NodeA.Repo.Bus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage, err := owner.DatasetUsage(tr.Ctx, wbp, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Pulls != 1 || usage.UniquePeers != 1 || usage.BytesServed == 0 {
+		t.Errorf("unexpected usage: %#v", usage)
+	}
+	if len(usage.Versions) != 1 || usage.Versions[0].Path != wbp.Path {
+		t.Errorf("expected usage for pulled version, got: %#v", usage.Versions)
+	}
+
+	noStats := tr.NodeARemote(t)
+	if _, err := noStats.DatasetUsage(tr.Ctx, profile.ID("a"), wbp); err != ErrUsageStatsDisabled {
+		t.Errorf("expected ErrUsageStatsDisabled, got: %v", err)
+	}
+}
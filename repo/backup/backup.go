@@ -0,0 +1,140 @@
+// Package backup keeps rotated copies of repo files that are rewritten in
+// place, like the logbook & dscache, and restores them when a crash leaves a
+// file partially written
+package backup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// DefaultCount is the number of backups kept when a count isn't configured
+const DefaultCount = 3
+
+// Filename returns the name of the i'th backup of a file. Backup 1 is the
+// most recent
+func Filename(filename string, i int) string {
+	return fmt.Sprintf("%s.%d", filename, i)
+}
+
+// Rotate copies a file to its first backup, shifting older backups along &
+// keeping n. Rotate should be called before the file is overwritten, so
+// backups hold complete, previously written versions. Missing files are
+// ignored
+func Rotate(filename string, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for i := n - 1; i >= 1; i-- {
+		if err := os.Rename(Filename(filename, i), Filename(filename, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return writeAtomic(Filename(filename, 1), data)
+}
+
+// Status describes the outcome of checking a file
+type Status string
+
+const (
+	// StatusOK means a file is intact, or doesn't exist yet
+	StatusOK = Status("ok")
+	// StatusRecovered means a corrupt file was replaced with a backup
+	StatusRecovered = Status("recovered")
+	// StatusUnrecoverable means a file is corrupt & no backup is intact
+	StatusUnrecoverable = Status("unrecoverable")
+)
+
+// Check is the result of checking the integrity of a file
+type Check struct {
+	Filename string `json:"filename"`
+	Status   Status `json:"status"`
+	// Error is the reason the file failed to validate
+	Error string `json:"error,omitempty"`
+	// Backup is the backup a recovered file was restored from
+	Backup string `json:"backup,omitempty"`
+	// Moved is where a corrupt file was moved to, so it can be inspected
+	Moved string `json:"moved,omitempty"`
+}
+
+// Recover validates a file, restoring the most recent intact backup of up to
+// n backups if it's corrupt. Corrupt files are kept with a ".corrupt" suffix.
+// Temporary files left by an interrupted rotation are removed
+func Recover(filename string, n int, validate func(data []byte) error) (Check, error) {
+	chk := Check{Filename: filename, Status: StatusOK}
+	tmps := []string{filename + tmpSuffix}
+	for i := 1; i <= n; i++ {
+		tmps = append(tmps, Filename(filename, i)+tmpSuffix)
+	}
+	for _, tmp := range tmps {
+		if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+			return chk, err
+		}
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return chk, nil
+	} else if err != nil {
+		return chk, err
+	}
+	if err = validateBytes(data, validate); err == nil {
+		return chk, nil
+	}
+	chk.Error = err.Error()
+
+	chk.Moved = filename + ".corrupt"
+	if err := os.Rename(filename, chk.Moved); err != nil {
+		return chk, err
+	}
+
+	for i := 1; i <= n; i++ {
+		name := Filename(filename, i)
+		data, err := ioutil.ReadFile(name)
+		if err != nil || validateBytes(data, validate) != nil {
+			continue
+		}
+		if err := writeAtomic(filename, data); err != nil {
+			return chk, err
+		}
+		chk.Status = StatusRecovered
+		chk.Backup = name
+		return chk, nil
+	}
+	chk.Status = StatusUnrecoverable
+	return chk, nil
+}
+
+// validateBytes runs a validation func, treating empty files & panics as
+// invalid. Decoding truncated flatbuffers can panic
+func validateBytes(data []byte, validate func([]byte) error) (err error) {
+	if len(data) == 0 {
+		return fmt.Errorf("file is empty")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("decoding file: %v", r)
+		}
+	}()
+	return validate(data)
+}
+
+const tmpSuffix = ".tmp"
+
+// writeAtomic writes to a temporary file that's renamed into place, so
+// interrupted writes never leave a partial file at filename
+func writeAtomic(filename string, data []byte) error {
+	tmp := filename + tmpSuffix
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
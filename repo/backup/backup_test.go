@@ -0,0 +1,105 @@
+package backup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotateAndRecover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "logbook.qfb")
+
+	validate := func(data []byte) error {
+		if string(data[len(data)-1:]) != "." {
+			return fmt.Errorf("truncated")
+		}
+		return nil
+	}
+
+	if err := Rotate(filename, 2); err != nil {
+		t.Fatalf("rotating a missing file shouldn't error: %s", err)
+	}
+	chk, err := Recover(filename, 2, validate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chk.Status != StatusOK {
+		t.Errorf("expected missing file to be ok, got: %#v", chk)
+	}
+
+	for _, content := range []string{"one.", "two.", "three."} {
+		if err := Rotate(filename, 2); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectContent(t, Filename(filename, 1), "two.")
+	expectContent(t, Filename(filename, 2), "one.")
+	if _, err := os.Stat(Filename(filename, 3)); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept")
+	}
+
+	// a crash mid-write, with a corrupt first backup
+	if err := ioutil.WriteFile(filename, []byte("fo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(Filename(filename, 1), []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(Filename(filename, 1)+tmpSuffix, []byte("th"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if chk, err = Recover(filename, 2, validate); err != nil {
+		t.Fatal(err)
+	}
+	if chk.Status != StatusRecovered || chk.Backup != Filename(filename, 2) || chk.Error != "truncated" {
+		t.Errorf("expected recovery from the second backup, got: %#v", chk)
+	}
+	expectContent(t, filename, "one.")
+	expectContent(t, chk.Moved, "fo")
+	if _, err := os.Stat(Filename(filename, 1) + tmpSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected temporary file to be removed")
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("bad"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if chk, err = Recover(filename, 0, validate); err != nil {
+		t.Fatal(err)
+	}
+	if chk.Status != StatusUnrecoverable {
+		t.Errorf("expected unrecoverable without backups, got: %#v", chk)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("ok."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	panics := func([]byte) error { panic("index out of range") }
+	if chk, err = Recover(filename, 0, panics); err != nil {
+		t.Fatal(err)
+	}
+	if chk.Status != StatusUnrecoverable {
+		t.Errorf("expected a panicking validator to mark the file corrupt, got: %#v", chk)
+	}
+}
+
+func expectContent(t *testing.T, filename, expect string) {
+	t.Helper()
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != expect {
+		t.Errorf("%s: expected %q, got %q", filepath.Base(filename), expect, string(data))
+	}
+}
@@ -4,6 +4,8 @@ package buildrepo
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -101,7 +103,13 @@ func NewFilesystem(ctx context.Context, cfg *config.Config) (*muxfs.Mux, error)
 			if path, ok := fsCfg.Config["path"].(string); ok {
 				if !filepath.IsAbs(path) {
 					// resolve relative filepaths
-					cfg.Filesystems[i].Config["path"] = filepath.Join(qriPath, path)
+					path = filepath.Join(qriPath, path)
+					cfg.Filesystems[i].Config["path"] = path
+				}
+				if cfg.P2P != nil && cfg.P2P.PrivateNetwork() {
+					if err := writeSwarmKey(path, cfg.P2P.SwarmKey); err != nil {
+						return nil, err
+					}
 				}
 			}
 		}
@@ -122,6 +130,27 @@ func NewFilesystem(ctx context.Context, cfg *config.Config) (*muxfs.Mux, error)
 	return muxfs.New(ctx, cfg.Filesystems)
 }
 
+// writeSwarmKey places a swarm key in an IPFS repo, which IPFS reads to join
+// a private network. An existing swarm key that doesn't match is an error
+func writeSwarmKey(ipfsPath, swarmKey string) error {
+	if _, err := os.Stat(ipfsPath); os.IsNotExist(err) {
+		// repo hasn't been initialized yet
+		return nil
+	}
+
+	keyPath := filepath.Join(ipfsPath, "swarm.key")
+	existing, err := ioutil.ReadFile(keyPath)
+	if err == nil {
+		if strings.TrimSpace(string(existing)) != strings.TrimSpace(swarmKey) {
+			return fmt.Errorf("IPFS swarm key at %q doesn't match the configured p2p swarm key", keyPath)
+		}
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return ioutil.WriteFile(keyPath, []byte(swarmKey), 0600)
+}
+
 func newLogbook(fs qfs.Filesystem, bus event.Bus, pro *profile.Profile, repoPath string) (book *logbook.Book, err error) {
 	logbookPath := filepath.Join(repoPath, "logbook.qfb")
 	return logbook.NewJournal(pro.PrivKey, pro.Peername, bus, fs, logbookPath)
@@ -0,0 +1,90 @@
+// Package jsonfile persists JSON-encoded state to a single file, like the
+// stores kept at the root of a qri repo directory. Writes are atomic: readers
+// see either the previous or the next version of a file, never a partial one
+package jsonfile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// File is a JSON document stored at a path on the local filesystem. A File
+// with an empty filename stores nothing: Load leaves values untouched & Save
+// is a no-op, which lets stores built on a File run entirely in memory
+type File struct {
+	filename string
+	perm     os.FileMode
+}
+
+// New creates a File that everyone can read & only the owner can write
+func New(filename string) File {
+	return File{filename: filename, perm: 0644}
+}
+
+// NewPrivate creates a File only the owner can read & write, for state that
+// holds secrets
+func NewPrivate(filename string) File {
+	return File{filename: filename, perm: 0600}
+}
+
+// Filename returns the path of the file, empty for in-memory files
+func (f File) Filename() string {
+	return f.filename
+}
+
+// Load decodes the file into v. A file that doesn't exist isn't an error, and
+// leaves v untouched
+func (f File) Load(v interface{}) error {
+	if f.filename == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(f.filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Save encodes v & writes it to a temp file in the same directory that's
+// renamed into place
+func (f File) Save(v interface{}) error {
+	if f.filename == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteAtomic(f.filename, data, f.perm)
+}
+
+// WriteAtomic writes data to a temp file that's renamed to filename, so an
+// interrupted write never leaves a partial file behind
+func WriteAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,64 @@
+package jsonfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonfile_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := New(filepath.Join(dir, "state.json"))
+	got := map[string]int{"untouched": 1}
+	if err := f.Load(&got); err != nil {
+		t.Fatalf("loading a missing file shouldn't error: %s", err)
+	}
+	if diff := cmp.Diff(map[string]int{"untouched": 1}, got); diff != "" {
+		t.Errorf("loading a missing file changed the value (-want +got):\n%s", diff)
+	}
+
+	expect := map[string]int{"a": 1, "b": 2}
+	if err := f.Save(expect); err != nil {
+		t.Fatal(err)
+	}
+	got = map[string]int{}
+	if err := f.Load(&got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 {
+		t.Errorf("expected save to leave no temp files behind, got %d files", len(infos))
+	}
+
+	p := NewPrivate(filepath.Join(dir, "secrets.json"))
+	if err := p.Save(expect); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(p.Filename())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("expected private file to have mode 0600, got %o", fi.Mode().Perm())
+	}
+
+	mem := New("")
+	if err := mem.Save(expect); err != nil {
+		t.Errorf("saving an in-memory file shouldn't error: %s", err)
+	}
+}
@@ -0,0 +1,252 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/config"
+)
+
+// Timeout bounds a single publish attempt
+var Timeout = time.Second * 10
+
+// natsPublisher publishes to a NATS subject using the NATS text protocol. The
+// connection is opened on first publish & reused, a publish that fails on a
+// reused connection reconnects once before giving up
+type natsPublisher struct {
+	cfg *config.Sink
+
+	lk   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, key string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	p.lk.Lock()
+	defer p.lk.Unlock()
+
+	reused := p.conn != nil
+	err := p.publish(ctx, data)
+	if err != nil && reused {
+		// the server may have closed an idle connection
+		log.Debugw("nats publish failed, reconnecting", "sink", p.cfg.Name, "err", err)
+		err = p.publish(ctx, data)
+	}
+	return err
+}
+
+// Close closes the connection to the NATS server
+func (p *natsPublisher) Close() error {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+	return p.disconnect()
+}
+
+// publish sends a message, connecting first if needed. the connection is
+// dropped on error. must be called with the lock held
+func (p *natsPublisher) publish(ctx context.Context, data []byte) (err error) {
+	if p.conn == nil {
+		if err = p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		if err != nil {
+			p.disconnect()
+		}
+	}()
+
+	deadline, _ := ctx.Deadline()
+	if err = p.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	// PING after publishing, the server replies with PONG once it has processed
+	// everything before it, or -ERR if the publish was rejected
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "PUB %s %d\r\n", p.cfg.Topic, len(data))
+	buf.Write(data)
+	buf.WriteString("\r\nPING\r\n")
+	if _, err = p.conn.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	for {
+		line, err := p.r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			// clear the deadline so the idle connection isn't timed out
+			return p.conn.SetDeadline(time.Time{})
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// connect dials the server & introduces the client. must be called with the
+// lock held
+func (p *natsPublisher) connect(ctx context.Context) error {
+	addr := strings.TrimPrefix(p.cfg.Address, "nats://")
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	r := bufio.NewReader(conn)
+	info, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(info, "INFO") {
+		conn.Close()
+		return fmt.Errorf("nats: unexpected greeting %q", strings.TrimSpace(info))
+	}
+
+	opts := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "qri",
+	}
+	if p.cfg.Username != "" {
+		opts["user"] = p.cfg.Username
+		opts["pass"] = p.cfg.Password
+	}
+	connect, err := json.Marshal(opts)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", connect); err != nil {
+		conn.Close()
+		return err
+	}
+
+	p.conn = conn
+	p.r = r
+	return nil
+}
+
+// disconnect closes & drops the connection. must be called with the lock held
+func (p *natsPublisher) disconnect() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	p.r = nil
+	return err
+}
+
+// kafkaRESTPublisher produces to a kafka topic by posting to a Kafka REST
+// Proxy over HTTP. It's a bridge, not a kafka client: the proxy holds the
+// connection to the kafka cluster
+type kafkaRESTPublisher struct {
+	cfg *config.Sink
+}
+
+func (p kafkaRESTPublisher) Publish(ctx context.Context, key string, data []byte) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"records": []map[string]interface{}{
+			{"key": key, "value": json.RawMessage(data)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	u := strings.TrimSuffix(p.cfg.Address, "/") + "/topics/" + url.PathEscape(p.cfg.Topic)
+	_, err = postJSON(ctx, p.cfg, u, "application/vnd.kafka.json.v2+json", body)
+	return err
+}
+
+// rabbitMQHTTPPublisher publishes to a RabbitMQ exchange by posting to the
+// management HTTP API. It's a bridge, not an AMQP client. RabbitMQ documents
+// the API for low message volumes, which suits qri's dataset events
+type rabbitMQHTTPPublisher struct {
+	cfg *config.Sink
+}
+
+func (p rabbitMQHTTPPublisher) Publish(ctx context.Context, key string, data []byte) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"properties":       map[string]interface{}{"content_type": "application/json"},
+		"routing_key":      key,
+		"payload":          string(data),
+		"payload_encoding": "string",
+	})
+	if err != nil {
+		return err
+	}
+	vhost := p.cfg.VHost
+	if vhost == "" {
+		vhost = "/"
+	}
+	u := fmt.Sprintf("%s/api/exchanges/%s/%s/publish", strings.TrimSuffix(p.cfg.Address, "/"), url.PathEscape(vhost), url.PathEscape(p.cfg.Topic))
+	res, err := postJSON(ctx, p.cfg, u, "application/json", body)
+	if err != nil {
+		return err
+	}
+
+	routed := struct {
+		Routed bool `json:"routed"`
+	}{}
+	if err := json.Unmarshal(res, &routed); err != nil {
+		return fmt.Errorf("rabbitmq: invalid response: %w", err)
+	}
+	if !routed.Routed {
+		return fmt.Errorf("rabbitmq: message wasn't routed to any queue")
+	}
+	return nil
+}
+
+func postJSON(ctx context.Context, cfg *config.Sink, u, contentType string, body []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", contentType)
+	if cfg.Username != "" {
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("%s: %s", res.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
@@ -0,0 +1,240 @@
+// Package sink forwards dataset events to message queues, wiring qri into
+// existing data platforms. Sinks are configured in config.Integrations, and
+// each sink is enabled for a list of datasets. NATS sinks speak the NATS text
+// protocol over a reused connection. kafka-rest & rabbitmq-http sinks are HTTP
+// bridges: they post to a Kafka REST Proxy & the RabbitMQ management API, and
+// don't speak the kafka or AMQP protocols
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/transform"
+)
+
+var (
+	log = golog.Logger("sink")
+
+	// QueueSize is the number of undelivered messages a Service buffers.
+	// Messages are dropped when the buffer is full
+	QueueSize = 256
+	// Attempts is the number of times delivery of a message is attempted
+	Attempts = 3
+	// RetryDelay is the delay before the first retry, doubling with each
+	// attempt
+	RetryDelay = time.Second
+)
+
+// Message is the record published to a sink
+type Message struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	Dataset   string    `json:"dataset"`
+	Path      string    `json:"path,omitempty"`
+	RunID     string    `json:"runID,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Encode serializes a message in a sink format
+func Encode(format string, msg Message) ([]byte, error) {
+	switch format {
+	case "", config.SinkFormatJSON:
+		return json.Marshal(msg)
+	case config.SinkFormatCloudEvents:
+		return json.Marshal(map[string]interface{}{
+			"specversion":     "1.0",
+			"id":              msg.ID,
+			"source":          "qri://" + msg.Dataset,
+			"type":            "io.qri." + msg.Event,
+			"time":            msg.Timestamp.UTC().Format(time.RFC3339Nano),
+			"datacontenttype": "application/json",
+			"data":            msg,
+		})
+	default:
+		return nil, fmt.Errorf("unknown sink format %q", format)
+	}
+}
+
+// Publisher sends encoded messages to a queue. key identifies the dataset a
+// message is about, queues that support keys use it to partition or route
+// messages
+type Publisher interface {
+	Publish(ctx context.Context, key string, data []byte) error
+}
+
+// NewPublisher creates a publisher for a sink configuration
+func NewPublisher(cfg *config.Sink) (Publisher, error) {
+	switch cfg.Type {
+	case config.SinkTypeNATS:
+		return &natsPublisher{cfg: cfg}, nil
+	case config.SinkTypeKafkaREST:
+		return kafkaRESTPublisher{cfg: cfg}, nil
+	case config.SinkTypeRabbitMQHTTP:
+		return rabbitMQHTTPPublisher{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", cfg.Type)
+	}
+}
+
+type sink struct {
+	cfg *config.Sink
+	pub Publisher
+}
+
+type delivery struct {
+	sink *sink
+	msg  Message
+}
+
+// Service forwards events from an event bus to configured sinks. Events are
+// delivered in the background, so slow or unreachable queues don't hold up
+// the operations that publish events
+type Service struct {
+	sinks []*sink
+	queue chan delivery
+
+	lk sync.Mutex
+	// runs maps transform run IDs to the dataset being transformed
+	runs map[string]string
+}
+
+// New creates a sink service from integrations configuration. Disabled sinks
+// are skipped
+func New(cfg *config.Integrations) (*Service, error) {
+	sinks := []*sink{}
+	if cfg != nil {
+		for _, sc := range cfg.Sinks {
+			if sc.Disabled {
+				continue
+			}
+			pub, err := NewPublisher(sc)
+			if err != nil {
+				return nil, fmt.Errorf("sink %q: %w", sc.Name, err)
+			}
+			sinks = append(sinks, &sink{cfg: sc, pub: pub})
+		}
+	}
+	return newService(sinks), nil
+}
+
+func newService(sinks []*sink) *Service {
+	return &Service{
+		sinks: sinks,
+		queue: make(chan delivery, QueueSize),
+		runs:  map[string]string{},
+	}
+}
+
+// Subscribe listens for events on a bus
+func (s *Service) Subscribe(bus event.Bus) {
+	bus.SubscribeTypes(s.handle, event.ETDatasetSaveCompleted, event.ETTransformStop)
+}
+
+// TrackRun associates a transform run with a dataset, so events for the run
+// can be forwarded to sinks enabled for the dataset
+func (s *Service) TrackRun(runID, alias string) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.runs[runID] = alias
+}
+
+// Run delivers queued messages until the context is cancelled, closing
+// publisher connections when it returns
+func (s *Service) Run(ctx context.Context) {
+	defer s.close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-s.queue:
+			if err := s.deliver(ctx, d); err != nil {
+				log.Errorf("sink %q: dropping %s message for %s: %s", d.sink.cfg.Name, d.msg.Event, d.msg.Dataset, err)
+			}
+		}
+	}
+}
+
+// close closes publishers that hold connections
+func (s *Service) close() {
+	for _, sk := range s.sinks {
+		if c, ok := sk.pub.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				log.Debugw("closing sink", "sink", sk.cfg.Name, "err", err)
+			}
+		}
+	}
+}
+
+func (s *Service) handle(ctx context.Context, e event.Event) error {
+	msg := Message{Timestamp: time.Unix(0, e.Timestamp)}
+	switch e.Type {
+	case event.ETDatasetSaveCompleted:
+		p, ok := e.Payload.(event.DsSaveEvent)
+		if !ok || p.Error != nil || p.Path == "" {
+			return nil
+		}
+		msg.Event = config.SinkEventDatasetSaved
+		msg.Dataset = fmt.Sprintf("%s/%s", p.Username, p.Name)
+		msg.Path = p.Path
+	case event.ETTransformStop:
+		s.lk.Lock()
+		alias, ok := s.runs[e.SessionID]
+		delete(s.runs, e.SessionID)
+		s.lk.Unlock()
+		p, isLifecycle := e.Payload.(event.TransformLifecycle)
+		if !ok || !isLifecycle || p.Status != transform.StatusFailed {
+			return nil
+		}
+		msg.Event = config.SinkEventRunFailed
+		msg.Dataset = alias
+		msg.RunID = e.SessionID
+		msg.Error = "transform run failed"
+	default:
+		return nil
+	}
+
+	for _, sk := range s.sinks {
+		if !sk.cfg.EnabledFor(msg.Dataset) || !sk.cfg.WantsEvent(msg.Event) {
+			continue
+		}
+		m := msg
+		m.ID = uuid.New().String()
+		select {
+		case s.queue <- delivery{sink: sk, msg: m}:
+		default:
+			log.Errorf("sink %q: queue full, dropping %s message for %s", sk.cfg.Name, m.Event, m.Dataset)
+		}
+	}
+	return nil
+}
+
+func (s *Service) deliver(ctx context.Context, d delivery) error {
+	data, err := Encode(d.sink.cfg.Format, d.msg)
+	if err != nil {
+		return err
+	}
+	delay := RetryDelay
+	for i := 1; ; i++ {
+		err = d.sink.pub.Publish(ctx, d.msg.Dataset, data)
+		if err == nil || i >= Attempts {
+			return err
+		}
+		log.Debugw("sink publish failed, retrying", "sink", d.sink.cfg.Name, "attempt", i, "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
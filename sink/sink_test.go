@@ -0,0 +1,248 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/transform"
+)
+
+func TestEncode(t *testing.T) {
+	msg := Message{
+		ID:        "abc",
+		Event:     config.SinkEventDatasetSaved,
+		Dataset:   "peer/ds",
+		Path:      "/ipfs/QmFoo",
+		Timestamp: time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC),
+	}
+
+	data, err := Encode("", msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Message{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(msg, got); diff != "" {
+		t.Errorf("json message mismatch (-want +got):\n%s", diff)
+	}
+
+	data, err = Encode(config.SinkFormatCloudEvents, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ce := map[string]interface{}{}
+	if err := json.Unmarshal(data, &ce); err != nil {
+		t.Fatal(err)
+	}
+	if ce["specversion"] != "1.0" || ce["type"] != "io.qri.dataset:saved" || ce["source"] != "qri://peer/ds" {
+		t.Errorf("unexpected cloudevent: %s", data)
+	}
+
+	if _, err := Encode("xml", msg); err == nil {
+		t.Error("expected unknown format to error")
+	}
+}
+
+func TestKafkaRESTPublisher(t *testing.T) {
+	var body map[string]interface{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/datasets" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/vnd.kafka.json.v2+json" {
+			t.Errorf("unexpected content type %q", ct)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":1}]}`))
+	}))
+	defer s.Close()
+
+	pub := kafkaRESTPublisher{cfg: &config.Sink{Address: s.URL, Topic: "datasets"}}
+	if err := pub.Publish(context.Background(), "peer/ds", []byte(`{"a":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{
+		"records": []interface{}{
+			map[string]interface{}{"key": "peer/ds", "value": map[string]interface{}{"a": float64(1)}},
+		},
+	}
+	if diff := cmp.Diff(expect, body); diff != "" {
+		t.Errorf("request body mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRabbitMQHTTPPublisher(t *testing.T) {
+	routed := true
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/exchanges/%2F/qri/publish" {
+			t.Errorf("unexpected path %q", r.URL.EscapedPath())
+		}
+		if user, pass, _ := r.BasicAuth(); user != "guest" || pass != "secret" {
+			t.Errorf("expected basic auth credentials")
+		}
+		fmt.Fprintf(w, `{"routed":%t}`, routed)
+	}))
+	defer s.Close()
+
+	pub := rabbitMQHTTPPublisher{cfg: &config.Sink{Address: s.URL, Topic: "qri", Username: "guest", Password: "secret"}}
+	if err := pub.Publish(context.Background(), "peer/ds", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	routed = false
+	if err := pub.Publish(context.Background(), "peer/ds", []byte(`{}`)); err == nil {
+		t.Error("expected unrouted message to error")
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	accepted := make(chan struct{}, 2)
+	received := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- struct{}{}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+				r := bufio.NewReader(conn)
+				lines := []string{}
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					line = strings.TrimSpace(line)
+					if line == "PING" {
+						conn.Write([]byte("PONG\r\n"))
+						received <- strings.Join(lines, "\n")
+						lines = nil
+						continue
+					}
+					lines = append(lines, line)
+				}
+			}(conn)
+		}
+	}()
+
+	pub := &natsPublisher{cfg: &config.Sink{Address: "nats://" + l.Addr().String(), Topic: "qri.datasets"}}
+	defer pub.Close()
+	for _, msg := range []string{`{"a":1}`, `{"b":2}`} {
+		if err := pub.Publish(context.Background(), "peer/ds", []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-received; !strings.Contains(got, fmt.Sprintf("PUB qri.datasets 7\n%s", msg)) {
+			t.Errorf("unexpected protocol messages:\n%s", got)
+		}
+	}
+	if n := len(accepted); n != 1 {
+		t.Errorf("expected publishes to reuse one connection, got %d connections", n)
+	}
+}
+
+type fakePublisher struct {
+	lk   sync.Mutex
+	msgs []Message
+	got  chan struct{}
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, key string, data []byte) error {
+	msg := Message{}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	p.lk.Lock()
+	p.msgs = append(p.msgs, msg)
+	p.lk.Unlock()
+	p.got <- struct{}{}
+	return nil
+}
+
+func TestService(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pub := &fakePublisher{got: make(chan struct{}, 10)}
+	svc := newService([]*sink{
+		{cfg: &config.Sink{Name: "a", Datasets: []string{"peer/ds"}}, pub: pub},
+		{cfg: &config.Sink{Name: "b", Datasets: []string{"peer/other"}}, pub: pub},
+	})
+	bus := event.NewBus(ctx)
+	svc.Subscribe(bus)
+	go svc.Run(ctx)
+
+	svc.TrackRun("run1", "peer/ds")
+	bus.PublishID(ctx, event.ETTransformStop, "run1", event.TransformLifecycle{Status: transform.StatusFailed})
+	bus.Publish(ctx, event.ETDatasetSaveCompleted, event.DsSaveEvent{Username: "peer", Name: "ds", Path: "/ipfs/QmFoo"})
+	// failed saves & untracked runs aren't forwarded
+	bus.Publish(ctx, event.ETDatasetSaveCompleted, event.DsSaveEvent{Username: "peer", Name: "ds", Error: fmt.Errorf("oh noes")})
+	bus.PublishID(ctx, event.ETTransformStop, "run2", event.TransformLifecycle{Status: transform.StatusFailed})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-pub.got:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for messages")
+		}
+	}
+
+	pub.lk.Lock()
+	defer pub.lk.Unlock()
+	if len(pub.msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(pub.msgs))
+	}
+	if m := pub.msgs[0]; m.Event != config.SinkEventRunFailed || m.Dataset != "peer/ds" || m.RunID != "run1" {
+		t.Errorf("unexpected run message: %#v", m)
+	}
+	if m := pub.msgs[1]; m.Event != config.SinkEventDatasetSaved || m.Path != "/ipfs/QmFoo" {
+		t.Errorf("unexpected save message: %#v", m)
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	prevDelay := RetryDelay
+	RetryDelay = time.Millisecond
+	defer func() { RetryDelay = prevDelay }()
+
+	calls := 0
+	pub := publisherFunc(func(ctx context.Context, key string, data []byte) error {
+		calls++
+		return fmt.Errorf("unavailable")
+	})
+	svc := newService(nil)
+	err := svc.deliver(context.Background(), delivery{sink: &sink{cfg: &config.Sink{Name: "a"}, pub: pub}, msg: Message{}})
+	if err == nil {
+		t.Error("expected error")
+	}
+	if calls != Attempts {
+		t.Errorf("expected %d attempts, got %d", Attempts, calls)
+	}
+}
+
+type publisherFunc func(ctx context.Context, key string, data []byte) error
+
+func (f publisherFunc) Publish(ctx context.Context, key string, data []byte) error {
+	return f(ctx, key, data)
+}
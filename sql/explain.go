@@ -0,0 +1,242 @@
+// +build !arm
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/cube2222/octosql/parser/sqlparser"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/sql/preprocess"
+)
+
+// Explain reports how a query will be executed without running it. Datasets
+// the query references are loaded to read their size, but bodies aren't read
+func (svc *Service) Explain(ctx context.Context, query string) (*Plan, error) {
+	processedQuery, sources, err := preprocess.Query(query)
+	if err != nil {
+		log.Errorf("mapping query: %s", err)
+		return nil, err
+	}
+	stmt, err := parseSelect(processedQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &explainer{
+		ctx:    ctx,
+		svc:    svc,
+		refs:   sources,
+		loaded: map[string]*dataset.Dataset{},
+		plan:   &Plan{Query: query, Sources: []*SourcePlan{}, Steps: []Step{}},
+	}
+	e.plan.EstimatedRows = e.statement(stmt, 0)
+	return e.plan, nil
+}
+
+// explainer walks a parsed query, building a plan
+type explainer struct {
+	ctx    context.Context
+	svc    *Service
+	refs   map[string]string
+	loaded map[string]*dataset.Dataset
+	plan   *Plan
+}
+
+func (e *explainer) step(depth int, op, detail string, rows int) {
+	e.plan.Steps = append(e.plan.Steps, Step{Depth: depth, Op: op, Detail: detail, Rows: rows})
+}
+
+func (e *explainer) note(format string, args ...interface{}) {
+	e.plan.Notes = append(e.plan.Notes, fmt.Sprintf(format, args...))
+}
+
+// statement adds the steps of a select statement to the plan, returning the
+// estimated number of rows it emits
+func (e *explainer) statement(stmt sqlparser.SelectStatement, depth int) int {
+	switch s := stmt.(type) {
+	case *sqlparser.Select:
+		return e.selectStatement(s, depth)
+	case *sqlparser.Union:
+		rows := e.statement(s.Left, depth+1) + e.statement(s.Right, depth+1)
+		e.step(depth, "union", s.Type, rows)
+		if len(s.OrderBy) > 0 {
+			e.step(depth, "order", sqlparser.String(s.OrderBy), rows)
+		}
+		return e.limit(s.Limit, rows, depth)
+	case *sqlparser.ParenSelect:
+		return e.statement(s.Select, depth)
+	default:
+		e.step(depth, "unknown", sqlparser.String(stmt), 0)
+		return 0
+	}
+}
+
+func (e *explainer) selectStatement(s *sqlparser.Select, depth int) int {
+	rows := 0
+	var only *SourcePlan
+	for i, expr := range s.From {
+		n, src := e.tableExpr(expr, depth)
+		if i == 0 {
+			rows, only = n, src
+			continue
+		}
+		// comma separated tables are cross joined
+		only = nil
+		rows *= n
+		e.step(depth, "join", "CROSS JOIN "+sqlparser.String(expr), rows)
+		e.note("%s is cross joined, producing every combination of rows", sqlparser.String(expr))
+	}
+
+	if s.Where != nil {
+		e.step(depth, "filter", sqlparser.String(s.Where.Expr), rows)
+		if only != nil {
+			e.note("filters aren't pushed down to datasets, every row of %s is read to apply WHERE", only.Ref)
+		}
+	}
+	aggregate := len(s.GroupBy) > 0 || hasAggregate(s.SelectExprs)
+	if len(s.GroupBy) > 0 {
+		e.step(depth, "group", sqlparser.String(s.GroupBy), rows)
+	} else if aggregate {
+		rows = 1
+		e.step(depth, "aggregate", sqlparser.String(s.SelectExprs), rows)
+	}
+	if s.Having != nil {
+		e.step(depth, "filter", sqlparser.String(s.Having.Expr), rows)
+	}
+	if s.Distinct != "" {
+		e.step(depth, "distinct", "", rows)
+	}
+	if len(s.OrderBy) > 0 {
+		e.step(depth, "order", sqlparser.String(s.OrderBy), rows)
+	}
+
+	limited := e.limit(s.Limit, rows, depth)
+	// a limit ends reading a single dataset early unless every row is needed
+	// to sort, group or deduplicate results
+	if only != nil && limited < rows && !aggregate && s.Distinct == "" && len(s.OrderBy) == 0 {
+		only.Scan = ScanLimit
+		only.Limit = limited
+	}
+	return limited
+}
+
+// tableExpr adds the steps of a FROM clause table expression, returning the
+// estimated number of rows it emits & the source it reads, if it reads
+// exactly one dataset
+func (e *explainer) tableExpr(expr sqlparser.TableExpr, depth int) (int, *SourcePlan) {
+	switch t := expr.(type) {
+	case *sqlparser.AliasedTableExpr:
+		switch te := t.Expr.(type) {
+		case sqlparser.TableName:
+			src := e.source(te.Name.String(), t.As.String())
+			e.step(depth, "scan", fmt.Sprintf("%s as %s", src.Ref, src.Alias), src.Rows)
+			return src.Rows, src
+		case *sqlparser.Subquery:
+			rows := e.statement(te.Select, depth+1)
+			e.step(depth, "subquery", "as "+t.As.String(), rows)
+			return rows, nil
+		}
+	case *sqlparser.JoinTableExpr:
+		left, _ := e.tableExpr(t.LeftExpr, depth)
+		right, rsrc := e.tableExpr(t.RightExpr, depth)
+		rows := left * right
+		detail := t.Join + " " + sqlparser.String(t.RightExpr)
+		if t.Condition.On != nil {
+			detail += " ON " + sqlparser.String(t.Condition.On)
+			if left > right {
+				rows = left
+			} else {
+				rows = right
+			}
+		}
+		e.step(depth, "join", detail, rows)
+		if rsrc != nil {
+			e.note("joins are nested loops, %s is read once for each row on the left side of the join. list the larger dataset first", rsrc.Ref)
+		}
+		return rows, nil
+	case *sqlparser.ParenTableExpr:
+		rows := 0
+		for i, expr := range t.Exprs {
+			n, _ := e.tableExpr(expr, depth)
+			if i == 0 {
+				rows = n
+			} else {
+				rows *= n
+			}
+		}
+		return rows, nil
+	}
+	e.step(depth, "unknown", sqlparser.String(expr), 0)
+	return 0, nil
+}
+
+// source loads a dataset referenced by the query, adding it to the plan
+func (e *explainer) source(name, alias string) *SourcePlan {
+	src := &SourcePlan{Alias: alias, Ref: name, Scan: ScanFull}
+	if ref, ok := e.refs[name]; ok {
+		src.Ref = ref
+	}
+	e.plan.Sources = append(e.plan.Sources, src)
+
+	ds, ok := e.loaded[src.Ref]
+	if !ok {
+		var err error
+		if ds, err = e.svc.loadDataset(e.ctx, src.Ref); err != nil {
+			log.Debugf("explain loading %q: %s", src.Ref, err)
+			src.Error = err.Error()
+			return src
+		}
+		e.loaded[src.Ref] = ds
+	}
+
+	src.Path = ds.Path
+	if st := ds.Structure; st != nil {
+		src.Format = st.Format
+		src.Rows = st.Entries
+		src.Length = st.Length
+		if st.Format != dataset.CSVDataFormat.String() {
+			src.Error = "sql queries only support CSV-formatted data"
+		}
+	} else {
+		src.Error = "dataset has no structure component"
+	}
+	return src
+}
+
+// limit adds a limit step, returning the capped number of rows
+func (e *explainer) limit(l *sqlparser.Limit, rows, depth int) int {
+	if l == nil || l.Rowcount == nil {
+		return rows
+	}
+	detail := sqlparser.String(l)
+	n, ok := intVal(l.Rowcount)
+	if ok && n < rows {
+		rows = n
+	}
+	e.step(depth, "limit", detail, rows)
+	return rows
+}
+
+func intVal(expr sqlparser.Expr) (int, bool) {
+	v, ok := expr.(*sqlparser.SQLVal)
+	if !ok || v.Type != sqlparser.IntVal {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(v.Val))
+	return n, err == nil
+}
+
+func hasAggregate(exprs sqlparser.SelectExprs) bool {
+	found := false
+	sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if f, ok := node.(*sqlparser.FuncExpr); ok && f.IsAggregate() {
+			found = true
+			return false, nil
+		}
+		return true, nil
+	}, exprs)
+	return found
+}
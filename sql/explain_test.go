@@ -0,0 +1,79 @@
+// +build !arm
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+func TestExplain(t *testing.T) {
+	datasets := map[string]*dataset.Dataset{
+		"me/movies":  {Path: "/mem/movies", Structure: &dataset.Structure{Format: "csv", Entries: 100, Length: 2000}},
+		"me/ratings": {Path: "/mem/ratings", Structure: &dataset.Structure{Format: "csv", Entries: 10, Length: 200}},
+		"me/json":    {Path: "/mem/json", Structure: &dataset.Structure{Format: "json", Entries: 5}},
+	}
+	load := func(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+		if ds, ok := datasets[refstr]; ok {
+			return ds, nil
+		}
+		return nil, fmt.Errorf("not found: %s", refstr)
+	}
+	svc := New(nil, load)
+	ctx := context.Background()
+
+	plan, err := svc.Explain(ctx, "SELECT * FROM me/movies as m LIMIT 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Sources) != 1 || plan.Sources[0].Ref != "me/movies" || plan.Sources[0].Rows != 100 {
+		t.Fatalf("unexpected sources: %#v", plan.Sources)
+	}
+	if plan.Sources[0].Scan != ScanLimit || plan.Sources[0].Limit != 5 {
+		t.Errorf("expected limit to end the scan early, got scan %q limit %d", plan.Sources[0].Scan, plan.Sources[0].Limit)
+	}
+	if plan.EstimatedRows != 5 {
+		t.Errorf("expected 5 estimated rows, got %d", plan.EstimatedRows)
+	}
+
+	plan, err = svc.Explain(ctx, "SELECT * FROM me/movies as m ORDER BY m.title LIMIT 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Sources[0].Scan != ScanFull {
+		t.Errorf("expected sorted query to scan in full, got %q", plan.Sources[0].Scan)
+	}
+
+	plan, err = svc.Explain(ctx, "SELECT * FROM me/movies as m LEFT JOIN me/ratings as r ON m.id = r.movie_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Sources) != 2 || plan.Sources[0].Alias != "m" || plan.Sources[1].Alias != "r" {
+		t.Fatalf("expected sources in join order, got: %#v", plan.Sources)
+	}
+	ops := []string{}
+	for _, s := range plan.Steps {
+		ops = append(ops, s.Op)
+	}
+	if fmt.Sprint(ops) != "[scan scan join]" {
+		t.Errorf("unexpected steps: %v", ops)
+	}
+	if plan.EstimatedRows != 100 || len(plan.Notes) == 0 {
+		t.Errorf("expected 100 estimated rows & a join note, got %d rows, notes: %v", plan.EstimatedRows, plan.Notes)
+	}
+
+	plan, err = svc.Explain(ctx, "SELECT * FROM me/json as j")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Sources[0].Error == "" {
+		t.Error("expected non-CSV dataset to report an error")
+	}
+
+	if _, err := svc.Explain(ctx, "DELETE FROM me/movies"); err == nil {
+		t.Error("expected explaining a non-SELECT statement to fail")
+	}
+}
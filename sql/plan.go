@@ -0,0 +1,49 @@
+package sql
+
+const (
+	// ScanFull marks a dataset that's read in its entirety
+	ScanFull = "full"
+	// ScanLimit marks a dataset read stops once a query limit is reached
+	ScanLimit = "limit"
+)
+
+// Plan describes how a query will be executed, without running it
+type Plan struct {
+	Query string `json:"query"`
+	// Sources lists the datasets the query loads, in the order they appear
+	Sources []*SourcePlan `json:"sources"`
+	// Steps lists query operations in execution order. Steps of subqueries
+	// are nested one level deeper than the query that uses them
+	Steps []Step `json:"steps"`
+	// EstimatedRows is an upper bound on the number of result rows
+	EstimatedRows int `json:"estimatedRows"`
+	// Notes point out parts of the query that are likely to be slow
+	Notes []string `json:"notes,omitempty"`
+}
+
+// SourcePlan describes a dataset loaded by a query
+type SourcePlan struct {
+	Alias  string `json:"alias"`
+	Ref    string `json:"ref"`
+	Path   string `json:"path,omitempty"`
+	Format string `json:"format,omitempty"`
+	// Rows is the number of entries in the dataset body, read from the
+	// dataset structure
+	Rows int `json:"rows"`
+	// Length is the size of the dataset body in bytes
+	Length int `json:"length"`
+	// Scan is ScanFull or ScanLimit
+	Scan  string `json:"scan"`
+	Limit int    `json:"limit,omitempty"`
+	// Error is set when the dataset can't be queried
+	Error string `json:"error,omitempty"`
+}
+
+// Step is a single operation in a query plan
+type Step struct {
+	Depth  int    `json:"depth"`
+	Op     string `json:"op"`
+	Detail string `json:"detail,omitempty"`
+	// Rows is an estimated upper bound on the number of rows the step emits
+	Rows int `json:"rows"`
+}
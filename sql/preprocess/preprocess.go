@@ -280,10 +280,13 @@ func (p *processor) newTableName() string {
 	}
 }
 
+// illegalNameChars replaces characters that can appear in dataset references,
+// including version timestamps, but not in SQL table names
+var illegalNameChars = strings.NewReplacer("/", "_", "-", "_", ":", "_", ".", "_", "+", "_")
+
 func toLegalName(refStr string) string {
 	refStr = strings.Replace(refStr, "@", "_at_", 1)
-	refStr = strings.ReplaceAll(refStr, "/", "_")
-	return strings.ReplaceAll(refStr, "-", "_")
+	return illegalNameChars.Replace(refStr)
 }
 
 // scan reads one token from the input stream
@@ -18,6 +18,13 @@ func TestPrepropcess(t *testing.T) {
 				"b5_country_codes_at__ipfs_QmFoo": "b5/country_codes@/ipfs/QmFoo",
 			},
 		},
+		{
+			"select * from b5/country_codes@2023-06-01T12:00:00Z t1",
+			"select * from b5_country_codes_at_2023_06_01T12_00_00Z t1",
+			map[string]string{
+				"b5_country_codes_at_2023_06_01T12_00_00Z": "b5/country_codes@2023-06-01T12:00:00Z",
+			},
+		},
 		{
 			"select foo from b5/country_codes",
 			"select ds.foo from b5_country_codes ds",
@@ -98,16 +98,9 @@ func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query stri
 	app := app.NewApp(cfg, dataSourceRepository, out, false)
 
 	// Parse query
-	stmt, err := sqlparser.Parse(processedQuery)
+	typed, err := parseSelect(processedQuery)
 	if err != nil {
-		log.Debugf("couldn't parse query: %s", err)
-		return qrierr.New(err, fmt.Sprintf("Parsing SQL:\n%s", err.Error()))
-	}
-	typed, ok := stmt.(sqlparser.SelectStatement)
-	if !ok {
-		log.Debugf("%v is not a select statement", reflect.TypeOf(stmt))
-		err := fmt.Errorf("invalid statement type, wanted sqlparser.SelectStatement got %v", reflect.TypeOf(stmt))
-		return qrierr.New(err, "only SELECT statements are supported")
+		return err
 	}
 	plan, err := parser.ParseNode(typed)
 	if err != nil {
@@ -127,6 +120,22 @@ Error:
 	return unwrapErr(err)
 }
 
+// parseSelect parses a preprocessed query, which must be a SELECT statement
+func parseSelect(processedQuery string) (sqlparser.SelectStatement, error) {
+	stmt, err := sqlparser.Parse(processedQuery)
+	if err != nil {
+		log.Debugf("couldn't parse query: %s", err)
+		return nil, qrierr.New(err, fmt.Sprintf("Parsing SQL:\n%s", err.Error()))
+	}
+	typed, ok := stmt.(sqlparser.SelectStatement)
+	if !ok {
+		log.Debugf("%v is not a select statement", reflect.TypeOf(stmt))
+		err := fmt.Errorf("invalid statement type, wanted sqlparser.SelectStatement got %v", reflect.TypeOf(stmt))
+		return nil, qrierr.New(err, "only SELECT statements are supported")
+	}
+	return typed, nil
+}
+
 // octosql uses the errors package, which doesn't support errors.Unwrap,
 // so we unwrap before returning
 func unwrapErr(err error) error {
@@ -23,3 +23,8 @@ func New(r repo.Repo, loadDataset dsref.ParseResolveLoad) *Service {
 func (svc *Service) Exec(ctx context.Context, w io.Writer, outFormat, query string) error {
 	return errors.New("sql command is not available on 32-bit systems")
 }
+
+// Explain fails on 32-bit systems
+func (svc *Service) Explain(ctx context.Context, query string) (*Plan, error) {
+	return nil, errors.New("sql command is not available on 32-bit systems")
+}
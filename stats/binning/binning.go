@@ -0,0 +1,423 @@
+// Package binning reshapes the histograms & frequency counts of a stats
+// component. The dataset package bins numeric columns with a fixed heuristic &
+// counts every distinct string, which explodes on high-cardinality columns.
+// Binners in this package collect numeric values while a body is read, then
+// replace histograms using a configured strategy & cap frequency counts to the
+// most common values
+package binning
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+const (
+	// StrategyDefault leaves histograms as calculated by the dataset package.
+	// This is the default
+	StrategyDefault = "default"
+	// StrategySturges bins values into ceil(log2(n))+1 bins of equal width
+	StrategySturges = "sturges"
+	// StrategyFixed bins values into bins of BinWidth, or into Bins bins of
+	// equal width
+	StrategyFixed = "fixed"
+	// StrategyQuantile bins values into Bins bins holding roughly the same
+	// number of values
+	StrategyQuantile = "quantile"
+
+	// DefaultBins is the number of bins used when Config.Bins is unset
+	DefaultBins = 10
+	// MaxBins is the largest number of bins a histogram can have. Fixed width
+	// histograms that would need more bins fall back to Sturges' rule
+	MaxBins = 1000
+
+	// OtherKey is the frequency key counts of values outside the top K are
+	// summed under
+	OtherKey = "__other__"
+	// SchemaKey is the schema keyword per-dataset & per-column binning
+	// overrides are stored under, eg:
+	// {"x-qri-stats": {"strategy": "quantile", "bins": 5, "topK": 20}}
+	SchemaKey = "x-qri-stats"
+)
+
+// Config selects a binning strategy & frequency cap
+type Config struct {
+	// Strategy is one of "default", "sturges", "fixed", or "quantile"
+	Strategy string `json:"strategy,omitempty"`
+	// Bins is the number of bins for the fixed & quantile strategies
+	Bins int `json:"bins,omitempty"`
+	// BinWidth is the width of each bin for the fixed strategy, taking
+	// precedence over Bins
+	BinWidth float64 `json:"binWidth,omitempty"`
+	// TopK caps frequency counts to the K most common values, summing the
+	// rest under OtherKey. zero counts every value
+	TopK int `json:"topK,omitempty"`
+}
+
+// Validate returns an error if the config names an unknown strategy or has a
+// negative or too large size
+func (c Config) Validate() error {
+	switch c.Strategy {
+	case "", StrategyDefault, StrategySturges, StrategyFixed, StrategyQuantile:
+	default:
+		return fmt.Errorf("unknown binning strategy %q, must be one of %q, %q, %q or %q", c.Strategy, StrategyDefault, StrategySturges, StrategyFixed, StrategyQuantile)
+	}
+	if c.Bins < 0 || c.BinWidth < 0 || c.TopK < 0 {
+		return fmt.Errorf("bins, bin width & top k can't be negative")
+	}
+	if c.Bins > MaxBins {
+		return fmt.Errorf("histograms can't have more than %d bins", MaxBins)
+	}
+	return nil
+}
+
+// rebins returns true if the config replaces histograms
+func (c Config) rebins() bool {
+	return c.Strategy != "" && c.Strategy != StrategyDefault
+}
+
+// Enabled returns true if the config changes the stats component
+func (c Config) Enabled() bool {
+	return c.rebins() || c.TopK > 0
+}
+
+// Override returns a copy of c with the fields set in o replacing those of c
+func (c Config) Override(o Config) Config {
+	if o.Strategy != "" {
+		c.Strategy = o.Strategy
+	}
+	if o.Bins != 0 {
+		c.Bins = o.Bins
+	}
+	if o.BinWidth != 0 {
+		c.BinWidth = o.BinWidth
+	}
+	if o.TopK != 0 {
+		c.TopK = o.TopK
+	}
+	return c
+}
+
+// SchemaOverride reads a config override stored in a schema under SchemaKey.
+// ok is false if the schema has no override
+func SchemaOverride(schema map[string]interface{}) (cfg Config, ok bool, err error) {
+	v, ok := schema[SchemaKey]
+	if !ok {
+		return cfg, false, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return cfg, false, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, false, fmt.Errorf("invalid %s schema keyword: %w", SchemaKey, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, false, fmt.Errorf("invalid %s schema keyword: %w", SchemaKey, err)
+	}
+	return cfg, true, nil
+}
+
+// Binner collects the numeric values of a body's columns while entries are
+// written, applying binning settings to a stats component once the body has
+// been read. Columns are keyed by index for array rows & by name for object
+// rows
+type Binner struct {
+	cfg     Config
+	columns map[string]Config
+	values  map[string][]float64
+}
+
+// NewBinner creates a binner for a structure. Overrides stored in the
+// structure's schema replace cfg for the whole dataset or a single column
+func NewBinner(st *dataset.Structure, cfg Config) (*Binner, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	b := &Binner{
+		cfg:     cfg,
+		columns: map[string]Config{},
+		values:  map[string][]float64{},
+	}
+	if st == nil || st.Schema == nil {
+		return b, nil
+	}
+
+	o, ok, err := SchemaOverride(st.Schema)
+	if err != nil {
+		return nil, err
+	} else if ok {
+		b.cfg = b.cfg.Override(o)
+	}
+
+	items, _ := st.Schema["items"].(map[string]interface{})
+	cols := map[string]interface{}{}
+	if list, ok := items["items"].([]interface{}); ok {
+		for i, col := range list {
+			cols[strconv.Itoa(i)] = col
+		}
+	}
+	if props, ok := items["properties"].(map[string]interface{}); ok {
+		for name, col := range props {
+			cols[name] = col
+		}
+	}
+	for key, col := range cols {
+		sch, ok := col.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		o, ok, err := SchemaOverride(sch)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", key, err)
+		} else if ok {
+			b.columns[key] = b.cfg.Override(o)
+		}
+	}
+	return b, nil
+}
+
+// Enabled returns true if the binner changes the stats component
+func (b *Binner) Enabled() bool {
+	if b == nil {
+		return false
+	}
+	if b.cfg.Enabled() {
+		return true
+	}
+	for _, cfg := range b.columns {
+		if cfg.Enabled() {
+			return true
+		}
+	}
+	return false
+}
+
+// column returns the settings for a column
+func (b *Binner) column(key string) Config {
+	if cfg, ok := b.columns[key]; ok {
+		return cfg
+	}
+	return b.cfg
+}
+
+// WriteEntry collects the numeric values of a body entry
+func (b *Binner) WriteEntry(ent dsio.Entry) error {
+	if !b.Enabled() {
+		return nil
+	}
+	switch row := ent.Value.(type) {
+	case []interface{}:
+		for i, v := range row {
+			b.collect(strconv.Itoa(i), v)
+		}
+	case map[string]interface{}:
+		for k, v := range row {
+			b.collect(k, v)
+		}
+	}
+	return nil
+}
+
+func (b *Binner) collect(key string, v interface{}) {
+	if !b.column(key).rebins() {
+		return
+	}
+	if f, ok := toFloat(v); ok {
+		b.values[key] = append(b.values[key], f)
+	}
+}
+
+// Apply replaces the histograms & caps the frequency counts of stats, a
+// stats component value as calculated by the dataset package. stats are
+// modified in place
+func (b *Binner) Apply(stats interface{}) interface{} {
+	if !b.Enabled() {
+		return stats
+	}
+	switch cols := stats.(type) {
+	case []map[string]interface{}:
+		for i, col := range cols {
+			b.apply(strconv.Itoa(i), col)
+		}
+	case []interface{}:
+		for i, col := range cols {
+			if col, ok := col.(map[string]interface{}); ok {
+				b.apply(strconv.Itoa(i), col)
+			}
+		}
+	case map[string]interface{}:
+		for key, col := range cols {
+			if col, ok := col.(map[string]interface{}); ok {
+				b.apply(key, col)
+			}
+		}
+	}
+	return stats
+}
+
+func (b *Binner) apply(key string, col map[string]interface{}) {
+	cfg := b.column(key)
+	if vals := b.values[key]; cfg.rebins() && len(vals) > 0 && col["type"] == "numeric" {
+		col["histogram"] = Histogram(vals, cfg)
+	}
+	if freqs, ok := col["frequencies"].(map[string]interface{}); ok && cfg.TopK > 0 {
+		col["frequencies"] = TopK(freqs, cfg.TopK)
+	}
+}
+
+// Histogram bins values, returning a map in the shape of dataset package
+// histograms: bin edges under "bins" & a count for each bin under
+// "frequencies". Each bin includes its lower edge, the last bin also includes
+// its upper edge
+func Histogram(values []float64, cfg Config) map[string]interface{} {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	edges := binEdges(sorted, cfg)
+	freqs := make([]int, len(edges)-1)
+	for _, v := range sorted {
+		i := sort.SearchFloat64s(edges, v)
+		if i == len(edges) || edges[i] != v {
+			// v falls inside the bin starting at the previous edge
+			i--
+		}
+		if i >= len(freqs) {
+			i = len(freqs) - 1
+		}
+		freqs[i]++
+	}
+	return map[string]interface{}{
+		"bins":        edges,
+		"frequencies": freqs,
+	}
+}
+
+// binEdges calculates the edges of histogram bins for sorted values
+func binEdges(sorted []float64, cfg Config) []float64 {
+	min, max := sorted[0], sorted[len(sorted)-1]
+	if min == max {
+		return []float64{min, max}
+	}
+
+	bins := cfg.Bins
+	if bins == 0 {
+		bins = DefaultBins
+	}
+	switch cfg.Strategy {
+	case StrategyFixed:
+		if cfg.BinWidth > 0 {
+			start := math.Floor(min/cfg.BinWidth) * cfg.BinWidth
+			n := int(math.Floor((max-start)/cfg.BinWidth)) + 1
+			if n <= MaxBins {
+				edges := make([]float64, n+1)
+				for i := range edges {
+					edges[i] = start + float64(i)*cfg.BinWidth
+				}
+				return edges
+			}
+			return equalWidth(min, max, sturges(len(sorted)))
+		}
+		return equalWidth(min, max, bins)
+	case StrategyQuantile:
+		edges := []float64{min}
+		for i := 1; i < bins; i++ {
+			q := quantile(sorted, float64(i)/float64(bins))
+			if q > edges[len(edges)-1] && q < max {
+				edges = append(edges, q)
+			}
+		}
+		return append(edges, max)
+	default:
+		return equalWidth(min, max, sturges(len(sorted)))
+	}
+}
+
+// sturges returns the number of bins Sturges' rule gives for n values
+func sturges(n int) int {
+	return int(math.Ceil(math.Log2(float64(n)))) + 1
+}
+
+func equalWidth(min, max float64, bins int) []float64 {
+	edges := make([]float64, bins+1)
+	width := (max - min) / float64(bins)
+	for i := range edges {
+		edges[i] = min + float64(i)*width
+	}
+	// avoid floating point error at the upper edge
+	edges[bins] = max
+	return edges
+}
+
+// quantile returns the q-th quantile of sorted values, interpolating
+// between values
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// TopK keeps the k most common values of a frequency map, summing the counts
+// of every other value under OtherKey. Ties are broken by value
+func TopK(freqs map[string]interface{}, k int) map[string]interface{} {
+	if len(freqs) <= k {
+		return freqs
+	}
+	type count struct {
+		key string
+		n   float64
+	}
+	counts := make([]count, 0, len(freqs))
+	for key, v := range freqs {
+		n, _ := toFloat(v)
+		counts = append(counts, count{key, n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].n != counts[j].n {
+			return counts[i].n > counts[j].n
+		}
+		return counts[i].key < counts[j].key
+	})
+
+	res := make(map[string]interface{}, k+1)
+	other := 0.0
+	for i, c := range counts {
+		if i < k {
+			res[c.key] = freqs[c.key]
+		} else {
+			other += c.n
+		}
+	}
+	if other == math.Trunc(other) {
+		res[OtherKey] = int(other)
+	} else {
+		res[OtherKey] = other
+	}
+	return res
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case int:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case float32:
+		return float64(x), true
+	case float64:
+		return x, !math.IsNaN(x) && !math.IsInf(x, 0)
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
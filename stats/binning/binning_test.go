@@ -0,0 +1,132 @@
+package binning
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+func TestConfigValidate(t *testing.T) {
+	good := []Config{
+		{},
+		{Strategy: StrategySturges},
+		{Strategy: StrategyFixed, BinWidth: 2.5},
+		{Strategy: StrategyQuantile, Bins: 4, TopK: 10},
+	}
+	for i, c := range good {
+		if err := c.Validate(); err != nil {
+			t.Errorf("case %d unexpected error: %s", i, err)
+		}
+	}
+
+	bad := []Config{
+		{Strategy: "logarithmic"},
+		{Bins: -1},
+		{TopK: -1},
+		{Bins: MaxBins + 1},
+	}
+	for i, c := range bad {
+		if err := c.Validate(); err == nil {
+			t.Errorf("case %d expected error, got nil", i)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	values := []float64{1, 2, 2, 3, 4, 5, 6, 7, 8, 10}
+	cases := []struct {
+		cfg   Config
+		bins  []float64
+		freqs []int
+	}{
+		// ceil(log2(10)) + 1 = 5 bins
+		{Config{Strategy: StrategySturges}, []float64{1, 2.8, 4.6, 6.4, 8.2, 10}, []int{3, 2, 2, 2, 1}},
+		{Config{Strategy: StrategyFixed, BinWidth: 5}, []float64{0, 5, 10, 15}, []int{5, 4, 1}},
+		{Config{Strategy: StrategyFixed, Bins: 3}, []float64{1, 4, 7, 10}, []int{4, 3, 3}},
+		{Config{Strategy: StrategyQuantile, Bins: 2}, []float64{1, 4.5, 10}, []int{5, 5}},
+	}
+	for i, c := range cases {
+		h := Histogram(values, c.cfg)
+		approx := cmp.Comparer(func(a, b float64) bool { return a-b < 1e-9 && b-a < 1e-9 })
+		if diff := cmp.Diff(c.bins, h["bins"], approx); diff != "" {
+			t.Errorf("case %d bins mismatch (-want +got):\n%s", i, diff)
+		}
+		if diff := cmp.Diff(c.freqs, h["frequencies"]); diff != "" {
+			t.Errorf("case %d frequencies mismatch (-want +got):\n%s", i, diff)
+		}
+	}
+
+	h := Histogram([]float64{3, 3, 3}, Config{Strategy: StrategyQuantile})
+	if diff := cmp.Diff([]int{3}, h["frequencies"]); diff != "" {
+		t.Errorf("constant values mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	freqs := map[string]interface{}{"a": 5, "b": 3, "c": 3, "d": 1, "e": 1}
+	got := TopK(freqs, 2)
+	expect := map[string]interface{}{"a": 5, "b": 3, OtherKey: 5}
+	if diff := cmp.Diff(expect, got); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := TopK(freqs, 10); len(got) != len(freqs) {
+		t.Errorf("expected frequencies under the cap to be left as is, got: %v", got)
+	}
+}
+
+func TestBinnerSchemaOverrides(t *testing.T) {
+	st := &dataset.Structure{
+		Format: "json",
+		Schema: map[string]interface{}{
+			"type": "array",
+			SchemaKey: map[string]interface{}{
+				"strategy": StrategyFixed,
+				"bins":     2,
+			},
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "name", "type": "string", SchemaKey: map[string]interface{}{"topK": 1}},
+					map[string]interface{}{"title": "n", "type": "integer"},
+				},
+			},
+		},
+	}
+
+	b, err := NewBinner(st, Config{Strategy: StrategySturges})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{{"a", 1}, {"a", 2}, {"b", 3}, {"c", 4}}
+	for i, row := range rows {
+		if err := b.WriteEntry(dsio.Entry{Index: i, Value: []interface{}(row)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := []map[string]interface{}{
+		{"type": "string", "frequencies": map[string]interface{}{"a": 2, "b": 1, "c": 1}},
+		{"type": "numeric", "histogram": map[string]interface{}{}},
+	}
+	b.Apply(stats)
+
+	expectFreqs := map[string]interface{}{"a": 2, OtherKey: 2}
+	if diff := cmp.Diff(expectFreqs, stats[0]["frequencies"]); diff != "" {
+		t.Errorf("frequencies mismatch (-want +got):\n%s", diff)
+	}
+	expectHist := map[string]interface{}{
+		"bins":        []float64{1, 2.5, 4},
+		"frequencies": []int{2, 2},
+	}
+	if diff := cmp.Diff(expectHist, stats[1]["histogram"]); diff != "" {
+		t.Errorf("histogram mismatch (-want +got):\n%s", diff)
+	}
+
+	st.Schema[SchemaKey] = map[string]interface{}{"strategy": "logarithmic"}
+	if _, err := NewBinner(st, Config{}); err == nil {
+		t.Error("expected invalid schema override to error")
+	}
+}
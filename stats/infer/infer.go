@@ -0,0 +1,301 @@
+// Package infer determines the column types of tabular dataset bodies by
+// sampling rows. The dataset package infers schemas from a fixed prefix of the
+// body, which mis-types sparse columns whose first values are empty. Samplers
+// in this package can scan the full body, a reservoir sample, or a sample
+// stratified across chunks of the body
+package infer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset"
+)
+
+const (
+	// SamplerPrefix leaves inference to the dataset package, which reads a
+	// fixed prefix of the body. This is the default
+	SamplerPrefix = "prefix"
+	// SamplerFull scans every row of the body
+	SamplerFull = "full"
+	// SamplerReservoir reads a uniform random sample of SampleSize rows
+	SamplerReservoir = "reservoir"
+	// SamplerStratified reads the first SampleSize rows of each chunk of
+	// ChunkSize rows
+	SamplerStratified = "stratified"
+
+	// DefaultSampleSize is the sample size used when Config.SampleSize is unset
+	DefaultSampleSize = 1000
+	// DefaultChunkSize is the chunk size used when Config.ChunkSize is unset
+	DefaultChunkSize = 10000
+
+	// ProvenanceKey is the schema keyword inference provenance is recorded
+	// under
+	ProvenanceKey = "x-qri-inference"
+)
+
+// Config selects a sampling strategy
+type Config struct {
+	// Sampler is one of "prefix", "full", "reservoir", or "stratified"
+	Sampler string
+	// SampleSize is the number of rows in a reservoir sample, or the number of
+	// rows sampled per chunk when stratified
+	SampleSize int
+	// ChunkSize is the number of rows in each chunk of a stratified sample
+	ChunkSize int
+	// Seed seeds the reservoir sampler. A fixed seed keeps inferred schemas, and
+	// with them dataset checksums, stable between saves of the same body
+	Seed int64
+}
+
+// Validate returns an error if the config names an unknown sampler or has a
+// negative size
+func (c Config) Validate() error {
+	switch c.Sampler {
+	case "", SamplerPrefix, SamplerFull, SamplerReservoir, SamplerStratified:
+	default:
+		return fmt.Errorf("unknown sampler %q, must be one of %q, %q, %q or %q", c.Sampler, SamplerPrefix, SamplerFull, SamplerReservoir, SamplerStratified)
+	}
+	if c.SampleSize < 0 || c.ChunkSize < 0 {
+		return fmt.Errorf("sample & chunk sizes can't be negative")
+	}
+	return nil
+}
+
+// Enabled returns true if the config selects a sampler from this package
+func (c Config) Enabled() bool {
+	return c.Sampler != "" && c.Sampler != SamplerPrefix
+}
+
+// Provenance records how a schema was inferred
+type Provenance struct {
+	Sampler     string `json:"sampler"`
+	SampleSize  int    `json:"sampleSize,omitempty"`
+	ChunkSize   int    `json:"chunkSize,omitempty"`
+	Seed        int64  `json:"seed,omitempty"`
+	RowsSampled int    `json:"rowsSampled"`
+	RowsTotal   int    `json:"rowsTotal"`
+}
+
+// Map converts provenance to a schema-compatible map
+func (p Provenance) Map() map[string]interface{} {
+	m := map[string]interface{}{
+		"sampler":     p.Sampler,
+		"rowsSampled": p.RowsSampled,
+		"rowsTotal":   p.RowsTotal,
+	}
+	if p.SampleSize != 0 {
+		m["sampleSize"] = p.SampleSize
+	}
+	if p.ChunkSize != 0 {
+		m["chunkSize"] = p.ChunkSize
+	}
+	if p.Seed != 0 {
+		m["seed"] = p.Seed
+	}
+	return m
+}
+
+// Supported returns true if column types for a structure can be inferred by
+// sampling. Only CSV bodies with an array-of-arrays schema are supported
+func Supported(st *dataset.Structure) bool {
+	if st == nil || st.Format != dataset.CSVDataFormat.String() {
+		return false
+	}
+	_, ok := schemaColumns(st.Schema)
+	return ok
+}
+
+// Structure infers the column types of a CSV body, replacing the column types
+// in st.Schema & recording provenance in the schema under ProvenanceKey.
+// Column titles & format configuration are expected to already be set
+func Structure(st *dataset.Structure, body io.Reader, cfg Config) (*Provenance, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled() {
+		return nil, fmt.Errorf("inference with the %q sampler isn't supported", cfg.Sampler)
+	}
+	cols, ok := schemaColumns(st.Schema)
+	if !ok || st.Format != dataset.CSVDataFormat.String() {
+		return nil, fmt.Errorf("sampled inference requires a tabular csv body")
+	}
+
+	r, headerRow, err := csvReader(st, body)
+	if err != nil {
+		return nil, err
+	}
+	if headerRow {
+		if _, err := r.Read(); err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+
+	prov := &Provenance{Sampler: cfg.Sampler}
+	sample, err := sampleRows(r, cfg, prov)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]string, len(cols))
+	for _, row := range sample {
+		for i, val := range row {
+			if i < len(types) {
+				types[i] = Widen(types[i], ValueType(val))
+			}
+		}
+	}
+	for i, col := range cols {
+		t := types[i]
+		if t == "" {
+			// a column with no values in the sample can hold anything
+			t = "string"
+		}
+		col["type"] = t
+	}
+
+	st.Schema[ProvenanceKey] = prov.Map()
+	return prov, nil
+}
+
+func sampleRows(r *csv.Reader, cfg Config, prov *Provenance) ([][]string, error) {
+	var (
+		sample [][]string
+		rng    *rand.Rand
+	)
+
+	switch cfg.Sampler {
+	case SamplerReservoir:
+		prov.SampleSize = cfg.SampleSize
+		if prov.SampleSize == 0 {
+			prov.SampleSize = DefaultSampleSize
+		}
+		prov.Seed = cfg.Seed
+		rng = rand.New(rand.NewSource(cfg.Seed))
+	case SamplerStratified:
+		prov.SampleSize, prov.ChunkSize = cfg.SampleSize, cfg.ChunkSize
+		if prov.SampleSize == 0 {
+			prov.SampleSize = DefaultSampleSize
+		}
+		if prov.ChunkSize == 0 {
+			prov.ChunkSize = DefaultChunkSize
+		}
+	}
+
+	for i := 0; ; i++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		prov.RowsTotal++
+
+		switch cfg.Sampler {
+		case SamplerFull:
+			sample = append(sample, row)
+		case SamplerReservoir:
+			// algorithm R: keep the first SampleSize rows, then replace a random
+			// row with decreasing probability
+			if len(sample) < prov.SampleSize {
+				sample = append(sample, row)
+			} else if j := rng.Intn(i + 1); j < prov.SampleSize {
+				sample[j] = row
+			}
+		case SamplerStratified:
+			if i%prov.ChunkSize < prov.SampleSize {
+				sample = append(sample, row)
+			}
+		}
+	}
+
+	prov.RowsSampled = len(sample)
+	return sample, nil
+}
+
+// csvReader creates a reader configured with a structure's format config
+func csvReader(st *dataset.Structure, body io.Reader) (*csv.Reader, bool, error) {
+	r := csv.NewReader(body)
+	r.FieldsPerRecord = -1
+	if st.FormatConfig == nil {
+		return r, false, nil
+	}
+
+	fc, err := dataset.ParseFormatConfigMap(dataset.CSVDataFormat, st.FormatConfig)
+	if err != nil {
+		return nil, false, err
+	}
+	opts, ok := fc.(*dataset.CSVOptions)
+	if !ok {
+		return r, false, nil
+	}
+	r.LazyQuotes = opts.LazyQuotes
+	if opts.Separator != 0 {
+		r.Comma = opts.Separator
+	}
+	return r, opts.HeaderRow, nil
+}
+
+// schemaColumns returns the column definitions of an array-of-arrays schema
+func schemaColumns(sch map[string]interface{}) ([]map[string]interface{}, bool) {
+	if sch == nil {
+		return nil, false
+	}
+	items, ok := sch["items"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	colItems, ok := items["items"].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	cols := make([]map[string]interface{}, 0, len(colItems))
+	for _, c := range colItems {
+		col, ok := c.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cols = append(cols, col)
+	}
+	return cols, true
+}
+
+// ValueType classifies a raw csv value as one of "boolean", "integer",
+// "number" or "string". Empty values return the empty string, they don't
+// constrain a column's type
+func ValueType(val string) string {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return ""
+	}
+	if _, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return "integer"
+	}
+	if _, err := strconv.ParseFloat(val, 64); err == nil {
+		return "number"
+	}
+	if lower := strings.ToLower(val); lower == "true" || lower == "false" {
+		return "boolean"
+	}
+	return "string"
+}
+
+// Widen combines two column types into the narrowest type that can hold
+// values of both. An empty type widens to the other type, integer & number
+// widen to number, and any other mix of types widens to string
+func Widen(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "" || a == b:
+		return a
+	case (a == "integer" && b == "number") || (a == "number" && b == "integer"):
+		return "number"
+	default:
+		return "string"
+	}
+}
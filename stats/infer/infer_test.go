@@ -0,0 +1,131 @@
+package infer
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/qri-io/dataset"
+)
+
+// sparseBody has a column "b" that's empty for the first 1500 rows
+func sparseBody() []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("a,b,c\n")
+	for i := 0; i < 2000; i++ {
+		b := ""
+		if i >= 1500 {
+			b = fmt.Sprintf("%d", i)
+		}
+		c := "1"
+		if i%2 == 0 {
+			c = "1.5"
+		}
+		fmt.Fprintf(buf, "row_%d,%s,%s\n", i, b, c)
+	}
+	return buf.Bytes()
+}
+
+func sparseStructure() *dataset.Structure {
+	return &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "a", "type": "string"},
+					map[string]interface{}{"title": "b", "type": "string"},
+					map[string]interface{}{"title": "c", "type": "string"},
+				},
+			},
+		},
+	}
+}
+
+func columnTypes(st *dataset.Structure) []string {
+	cols, _ := schemaColumns(st.Schema)
+	types := make([]string, len(cols))
+	for i, col := range cols {
+		types[i], _ = col["type"].(string)
+	}
+	return types
+}
+
+func TestStructure(t *testing.T) {
+	cases := []struct {
+		cfg         Config
+		rowsSampled int
+	}{
+		{Config{Sampler: SamplerFull}, 2000},
+		{Config{Sampler: SamplerReservoir, SampleSize: 200, Seed: 1}, 200},
+		{Config{Sampler: SamplerStratified, SampleSize: 10, ChunkSize: 250}, 80},
+	}
+
+	for _, c := range cases {
+		t.Run(c.cfg.Sampler, func(t *testing.T) {
+			st := sparseStructure()
+			prov, err := Structure(st, bytes.NewReader(sparseBody()), c.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expect := []string{"string", "integer", "number"}
+			got := columnTypes(st)
+			for i := range expect {
+				if got[i] != expect[i] {
+					t.Errorf("column %d type mismatch. want %q, got %q", i, expect[i], got[i])
+				}
+			}
+			if prov.RowsTotal != 2000 || prov.RowsSampled != c.rowsSampled {
+				t.Errorf("expected %d of 2000 rows sampled, got %d of %d", c.rowsSampled, prov.RowsSampled, prov.RowsTotal)
+			}
+			if _, ok := st.Schema[ProvenanceKey]; !ok {
+				t.Errorf("expected provenance to be recorded in the schema")
+			}
+		})
+	}
+
+	if _, err := Structure(sparseStructure(), bytes.NewReader(sparseBody()), Config{Sampler: "nope"}); err == nil {
+		t.Error("expected unknown sampler to error")
+	}
+	if _, err := Structure(&dataset.Structure{Format: "json"}, bytes.NewReader([]byte("[]")), Config{Sampler: SamplerFull}); err == nil {
+		t.Error("expected json body to error")
+	}
+}
+
+func TestWiden(t *testing.T) {
+	cases := []struct {
+		a, b, expect string
+	}{
+		{"", "integer", "integer"},
+		{"integer", "", "integer"},
+		{"integer", "integer", "integer"},
+		{"integer", "number", "number"},
+		{"number", "integer", "number"},
+		{"boolean", "integer", "string"},
+		{"string", "number", "string"},
+	}
+	for _, c := range cases {
+		if got := Widen(c.a, c.b); got != c.expect {
+			t.Errorf("Widen(%q, %q) mismatch. want %q, got %q", c.a, c.b, c.expect, got)
+		}
+	}
+}
+
+func TestValueType(t *testing.T) {
+	cases := map[string]string{
+		"":      "",
+		" ":     "",
+		"12":    "integer",
+		"-1.5":  "number",
+		"TRUE":  "boolean",
+		"false": "boolean",
+		"hello": "string",
+	}
+	for val, expect := range cases {
+		if got := ValueType(val); got != expect {
+			t.Errorf("ValueType(%q) mismatch. want %q, got %q", val, expect, got)
+		}
+	}
+}
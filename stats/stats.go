@@ -17,13 +17,15 @@ import (
 	"github.com/qri-io/dataset/dsstats"
 	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/fsi"
+	"github.com/qri-io/qri/stats/binning"
 )
 
 var log = logger.Logger("stats")
 
 // Service can generate an array of statistical info for a dataset
 type Service struct {
-	cache Cache
+	cache   Cache
+	binning binning.Config
 }
 
 // New allocates a Stats service
@@ -37,6 +39,12 @@ func New(cache Cache) *Service {
 	}
 }
 
+// SetBinning sets how calculated stats bin histograms & cap frequency counts.
+// Overrides stored in a dataset's structure take precedence
+func (s *Service) SetBinning(cfg binning.Config) {
+	s.binning = cfg
+}
+
 // Stats gets the stats component for a dataset, possibly calculating
 // by consuming the open dataset body file
 func (s *Service) Stats(ctx context.Context, ds *dataset.Dataset) (*dataset.Stats, error) {
@@ -87,8 +95,15 @@ func (s *Service) Stats(ctx context.Context, ds *dataset.Dataset) (*dataset.Stat
 	}
 
 	acc := dsstats.NewAccumulator(ds.Structure)
+	binner, err := binning.NewBinner(ds.Structure, s.binning)
+	if err != nil {
+		return nil, err
+	}
 	err = dsio.EachEntry(rdr, func(i int, ent dsio.Entry, e error) error {
-		return acc.WriteEntry(ent)
+		if err := acc.WriteEntry(ent); err != nil {
+			return err
+		}
+		return binner.WriteEntry(ent)
 	})
 	if err != nil {
 		return nil, err
@@ -99,7 +114,7 @@ func (s *Service) Stats(ctx context.Context, ds *dataset.Dataset) (*dataset.Stat
 
 	sa := &dataset.Stats{
 		Qri:   dataset.KindStats.String(),
-		Stats: dsstats.ToMap(acc),
+		Stats: binner.Apply(dsstats.ToMap(acc)),
 	}
 
 	if cacheErr := s.cache.PutStats(ctx, key, sa); cacheErr != nil {
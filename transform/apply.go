@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
 
 	golog "github.com/ipfs/go-log"
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/transform/startf"
@@ -41,6 +43,10 @@ type Transformer struct {
 	appCtx   context.Context
 	loadFunc dsref.ParseResolveLoad
 	pub      event.Publisher
+	// directory the fetch module caches responses in
+	httpCacheDir string
+	// directory local starlark modules are loaded from
+	moduleDir string
 }
 
 // NewTransformer returns a new transformer
@@ -52,6 +58,19 @@ func NewTransformer(appCtx context.Context, loadFunc dsref.ParseResolveLoad, pub
 	}
 }
 
+// SetHTTPCacheDir sets the directory transform scripts cache fetched responses
+// in. Caching is disabled by default
+func (t *Transformer) SetHTTPCacheDir(dir string) {
+	t.httpCacheDir = dir
+}
+
+// SetModuleDir sets the directory transform scripts load local modules from,
+// usually the working directory of a linked dataset. Defaults to the
+// directory of a transform script read from the local filesystem
+func (t *Transformer) SetModuleDir(dir string) {
+	t.moduleDir = dir
+}
+
 // Apply applies the transform script to a target dataset
 func (t Transformer) Apply(
 	ctx context.Context,
@@ -101,6 +120,14 @@ func (t Transformer) Apply(
 		startf.SetSecrets(secrets),
 		startf.AddDatasetLoader(t.loadFunc),
 		startf.AddEventsChannel(eventsCh),
+		startf.SetHTTPCacheDir(t.httpCacheDir),
+	}
+	moduleDir := t.moduleDir
+	if sp := target.Transform.ScriptPath; moduleDir == "" && sp != "" && qfs.PathKind(sp) == "local" {
+		moduleDir = filepath.Dir(sp)
+	}
+	if moduleDir != "" {
+		opts = append(opts, startf.SetModuleDir(moduleDir))
 	}
 
 	doneCh := make(chan error)
@@ -170,6 +197,7 @@ func (t Transformer) Apply(
 
 		// Run each step using a StepRunner
 		stepRunner := startf.NewStepRunner(head, opts...)
+		defer stepRunner.Close()
 		for i, step := range target.Transform.Steps {
 			// If the transform has failed at some step, emit skip events for remaining steps.
 			if status != StatusSucceeded {
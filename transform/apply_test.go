@@ -43,6 +43,30 @@ func TestApply(t *testing.T) {
 			},
 		},
 
+		{"pipeline_step_outputs",
+			&dataset.Transform{
+				Steps: []*dataset.TransformStep{
+					{Syntax: "starlark", Category: "step", Name: "nums", Script: "def step(ctx):\n\treturn [1,2,3]"},
+					{Syntax: "starlark", Category: "step", Name: "frame", Script: "load(\"dataframe.star\", \"dataframe\")\ndef step(ctx):\n\treturn dataframe.new([[n] for n in ctx.output(\"nums\")], columns=[\"n\"])"},
+					{Syntax: "starlark", Category: "step", Name: "total", Script: "def step(ctx):\n\tprint(str(ctx.output(\"frame\").count()))\n\treturn len(ctx.output(\"nums\"))"},
+				},
+			},
+			[]event.Event{
+				{Type: event.ETTransformStart, Payload: event.TransformLifecycle{StepCount: 3}},
+				{Type: event.ETTransformStepStart, Payload: event.TransformStepLifecycle{Name: "nums", Category: "step"}},
+				{Type: event.ETTransformStepOutput, Payload: event.TransformStepOutput{Step: "nums", Type: "list"}},
+				{Type: event.ETTransformStepStop, Payload: event.TransformStepLifecycle{Name: "nums", Category: "step", Status: StatusSucceeded}},
+				{Type: event.ETTransformStepStart, Payload: event.TransformStepLifecycle{Name: "frame", Category: "step"}},
+				{Type: event.ETTransformStepOutput, Payload: event.TransformStepOutput{Step: "frame", Type: "DataFrame", Rows: 3, Storage: "memory"}},
+				{Type: event.ETTransformStepStop, Payload: event.TransformStepLifecycle{Name: "frame", Category: "step", Status: StatusSucceeded}},
+				{Type: event.ETTransformStepStart, Payload: event.TransformStepLifecycle{Name: "total", Category: "step"}},
+				{Type: event.ETTransformPrint, Payload: event.TransformMessage{Msg: "3"}},
+				{Type: event.ETTransformStepOutput, Payload: event.TransformStepOutput{Step: "total", Type: "int"}},
+				{Type: event.ETTransformStepStop, Payload: event.TransformStepLifecycle{Name: "total", Category: "step", Status: StatusSucceeded}},
+				{Type: event.ETTransformStop, Payload: event.TransformLifecycle{Status: StatusSucceeded}},
+			},
+		},
+
 		{"one_step_error",
 			&dataset.Transform{
 				Steps: []*dataset.TransformStep{
@@ -0,0 +1,235 @@
+package run
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+// ErrLogNotFound is returned when a requested run log doesn't exist
+var ErrLogNotFound = errors.New("run log not found")
+
+const (
+	// DefaultMaxLogs is the default number of run logs a LogStore retains
+	DefaultMaxLogs = 200
+	// DefaultMaxLogAge is the default duration a LogStore retains run logs
+	DefaultMaxLogAge = time.Hour * 24 * 30
+)
+
+// Log is the persisted record of a transform run: the collapsed run state
+// plus the raw events, printed output, and errors the run produced. Log acts
+// as a sink of transform events, wrapping a State
+type Log struct {
+	lk sync.Mutex
+
+	ID      string        `json:"id"`
+	InitID  string        `json:"initID,omitempty"`
+	Created time.Time     `json:"created"`
+	State   *State        `json:"state"`
+	Events  []event.Event `json:"events"`
+	Stdout  string        `json:"stdout"`
+	Errors  []string      `json:"errors,omitempty"`
+}
+
+// NewLog creates a log for a run state, associated with a dataset initID.
+// initID may be empty for runs that aren't associated with a dataset
+func NewLog(rs *State, initID string) *Log {
+	return &Log{
+		ID:      rs.ID,
+		InitID:  initID,
+		Created: time.Now(),
+		State:   rs,
+	}
+}
+
+// AddTransformEvent records an event and applies it to the log's run state
+func (l *Log) AddTransformEvent(e event.Event) error {
+	l.lk.Lock()
+	defer l.lk.Unlock()
+	if l.ID != e.SessionID {
+		// silently ignore session ID mismatch
+		return nil
+	}
+
+	l.Events = append(l.Events, e)
+	if msg, ok := e.Payload.(event.TransformMessage); ok {
+		switch e.Type {
+		case event.ETTransformPrint:
+			l.Stdout += msg.Msg + "\n"
+		case event.ETTransformError:
+			l.Errors = append(l.Errors, msg.Msg)
+		}
+	}
+	return l.State.AddTransformEvent(e)
+}
+
+// AddError records an error that occurred outside of transform execution
+func (l *Log) AddError(err error) {
+	l.lk.Lock()
+	defer l.lk.Unlock()
+	l.Errors = append(l.Errors, err.Error())
+}
+
+// LogStore persists run logs as one JSON file per run in a directory, keyed
+// by runID. Whenever a log is written the store drops logs beyond the
+// retention limits, oldest first. A store with an empty directory keeps logs
+// in memory
+type LogStore struct {
+	lk  sync.Mutex
+	dir string
+	mem map[string][]byte
+
+	// MaxLogs caps the number of logs retained. zero means no limit
+	MaxLogs int
+	// MaxAge drops logs older than this duration. zero means no limit
+	MaxAge time.Duration
+}
+
+// NewLogStore creates a LogStore with default retention limits. The
+// directory is created on first write
+func NewLogStore(dir string) *LogStore {
+	return &LogStore{
+		dir:     dir,
+		mem:     map[string][]byte{},
+		MaxLogs: DefaultMaxLogs,
+		MaxAge:  DefaultMaxLogAge,
+	}
+}
+
+// Put writes a run log to the store, applying retention limits
+func (s *LogStore) Put(l *Log) error {
+	if l == nil || l.ID == "" {
+		return fmt.Errorf("run log ID is required")
+	}
+	l.lk.Lock()
+	data, err := json.Marshal(l)
+	l.lk.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	if s.dir == "" {
+		s.mem[l.ID] = data
+		return s.prune()
+	}
+
+	if err := os.MkdirAll(s.dir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := jsonfile.WriteAtomic(s.filename(l.ID), data, 0644); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// Get reads a run log by runID
+func (s *LogStore) Get(id string) (*Log, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	var data []byte
+	if s.dir == "" {
+		var ok bool
+		if data, ok = s.mem[id]; !ok {
+			return nil, ErrLogNotFound
+		}
+	} else {
+		// guard against path traversal, IDs are never file paths
+		if id == "" || strings.ContainsAny(id, `/\.`) {
+			return nil, ErrLogNotFound
+		}
+		var err error
+		if data, err = ioutil.ReadFile(s.filename(id)); err != nil {
+			if os.IsNotExist(err) {
+				return nil, ErrLogNotFound
+			}
+			return nil, err
+		}
+	}
+
+	l := &Log{}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (s *LogStore) filename(id string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s.json", id))
+}
+
+type logEntry struct {
+	id      string
+	created time.Time
+}
+
+// prune drops logs that exceed retention limits. must be called with the
+// store lock held
+func (s *LogStore) prune() error {
+	entries, err := s.entries()
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].created.After(entries[j].created)
+	})
+
+	now := time.Now()
+	for i, e := range entries {
+		expired := s.MaxAge > 0 && now.Sub(e.created) > s.MaxAge
+		overflow := s.MaxLogs > 0 && i >= s.MaxLogs
+		if !expired && !overflow {
+			continue
+		}
+		if s.dir == "" {
+			delete(s.mem, e.id)
+		} else if err := os.Remove(s.filename(e.id)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LogStore) entries() ([]logEntry, error) {
+	if s.dir == "" {
+		entries := make([]logEntry, 0, len(s.mem))
+		for id, data := range s.mem {
+			l := struct {
+				Created time.Time `json:"created"`
+			}{}
+			if err := json.Unmarshal(data, &l); err != nil {
+				return nil, err
+			}
+			entries = append(entries, logEntry{id: id, created: l.Created})
+		}
+		return entries, nil
+	}
+
+	infos, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]logEntry, 0, len(infos))
+	for _, fi := range infos {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".json" {
+			continue
+		}
+		entries = append(entries, logEntry{
+			id:      strings.TrimSuffix(fi.Name(), ".json"),
+			created: fi.ModTime(),
+		})
+	}
+	return entries, nil
+}
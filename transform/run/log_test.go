@@ -0,0 +1,87 @@
+package run
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/event"
+)
+
+func TestLogAddTransformEvent(t *testing.T) {
+	runID := NewID()
+	l := NewLog(NewState(runID), "init_id")
+
+	events := []event.Event{
+		{Type: event.ETTransformStart, Timestamp: 1609460600090, SessionID: runID, Payload: event.TransformLifecycle{Status: "running"}},
+		{Type: event.ETTransformStepStart, Timestamp: 1609460700090, SessionID: runID, Payload: event.TransformStepLifecycle{Name: "setup"}},
+		{Type: event.ETTransformPrint, Timestamp: 1609460800090, SessionID: runID, Payload: event.TransformMessage{Msg: "oh hai there"}},
+		{Type: event.ETTransformError, Timestamp: 1609460900090, SessionID: runID, Payload: event.TransformMessage{Msg: "oh no"}},
+		{Type: event.ETTransformPrint, Timestamp: 1609460900090, SessionID: "other_run", Payload: event.TransformMessage{Msg: "ignored"}},
+	}
+	for _, e := range events {
+		if err := l.AddTransformEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(l.Events) != 4 {
+		t.Errorf("expected 4 events, got %d", len(l.Events))
+	}
+	if l.Stdout != "oh hai there\n" {
+		t.Errorf("stdout mismatch. got: %q", l.Stdout)
+	}
+	if len(l.Errors) != 1 || l.Errors[0] != "oh no" {
+		t.Errorf("errors mismatch. got: %v", l.Errors)
+	}
+	if l.State.Status != RSRunning {
+		t.Errorf("expected state to be updated by events, got status %q", l.State.Status)
+	}
+}
+
+func TestLogStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "run_log_store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, d := range []string{"", dir} {
+		s := NewLogStore(d)
+		s.MaxLogs = 2
+
+		if _, err := s.Get("missing"); !errors.Is(err, ErrLogNotFound) {
+			t.Errorf("dir %q: expected missing log to return ErrLogNotFound, got: %v", d, err)
+		}
+
+		ids := []string{NewID(), NewID(), NewID()}
+		for i, id := range ids {
+			l := NewLog(NewState(id), "init_id")
+			l.Created = time.Now().Add(time.Duration(i) * time.Second)
+			l.Stdout = "hello\n"
+			if err := s.Put(l); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// writing triggers retention, add one more log to prune the oldest
+		last := NewLog(NewState(NewID()), "")
+		last.Created = time.Now().Add(time.Minute)
+		if err := s.Put(last); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.Get(ids[0]); !errors.Is(err, ErrLogNotFound) {
+			t.Errorf("dir %q: expected oldest log to be pruned, got: %v", d, err)
+		}
+		got, err := s.Get(last.ID)
+		if err != nil {
+			t.Fatalf("dir %q: %s", d, err)
+		}
+		if got.ID != last.ID {
+			t.Errorf("dir %q: ID mismatch. want %q, got %q", d, last.ID, got.ID)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package run
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"sort"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+)
+
+// Provenance is a machine-readable record of what went into a run & what came
+// out of it. Data fetched over HTTP is recorded in State.Fetches
+type Provenance struct {
+	// ScriptHash is the hex-encoded sha256 hash of the transform script. For
+	// transforms with steps the hash covers every step script, in order
+	ScriptHash string `json:"scriptHash,omitempty"`
+	// Parameters is the transform configuration the script was run with
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	// Secrets lists the names of secrets made available to the script.
+	// secret values are never recorded
+	Secrets []string `json:"secrets,omitempty"`
+	// Inputs lists datasets loaded with load_dataset, in the order they were
+	// first loaded
+	Inputs []DatasetVersion `json:"inputs,omitempty"`
+	// Output is the version the run saved, nil if the run didn't save a version
+	Output *DatasetVersion `json:"output,omitempty"`
+}
+
+// DatasetVersion identifies a single version of a dataset
+type DatasetVersion struct {
+	Ref  string `json:"ref"`
+	Path string `json:"path"`
+}
+
+// NewProvenance records the script, parameters & secret names of a transform.
+// a transform script file is read to hash it, and replaced with an in-memory
+// copy
+func NewProvenance(tf *dataset.Transform, secrets map[string]string) (*Provenance, error) {
+	p := &Provenance{}
+	if tf == nil {
+		return p, nil
+	}
+	p.Parameters = tf.Config
+
+	for name := range secrets {
+		p.Secrets = append(p.Secrets, name)
+	}
+	sort.Strings(p.Secrets)
+
+	h := sha256.New()
+	if len(tf.Steps) > 0 {
+		for _, step := range tf.Steps {
+			if str, ok := step.Script.(string); ok {
+				h.Write([]byte(str))
+			}
+		}
+	} else if f := tf.ScriptFile(); f != nil {
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		tf.SetScriptFile(qfs.NewMemfileBytes(f.FileName(), data))
+		h.Write(data)
+	} else {
+		return p, nil
+	}
+	p.ScriptHash = hex.EncodeToString(h.Sum(nil))
+	return p, nil
+}
+
+// AddInput records a loaded dataset, ignoring versions already recorded
+func (p *Provenance) AddInput(ref, path string) {
+	for _, in := range p.Inputs {
+		if in.Ref == ref && in.Path == path {
+			return
+		}
+	}
+	p.Inputs = append(p.Inputs, DatasetVersion{Ref: ref, Path: path})
+}
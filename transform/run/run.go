@@ -62,6 +62,10 @@ type State struct {
 	StopTime  *time.Time   `json:"stopTime"`
 	Duration  int          `json:"duration"`
 	Steps     []*StepState `json:"steps"`
+	// Fetches records HTTP requests made with the fetch module, in order
+	Fetches []event.TransformFetch `json:"fetches,omitempty"`
+	// Provenance records the inputs, parameters & output of the run
+	Provenance *Provenance `json:"provenance,omitempty"`
 }
 
 // NewState is a simple constructor to remind package consumers that state
@@ -126,9 +130,23 @@ func (rs *State) AddTransformEvent(e event.Event) error {
 		s.Status = RSSkipped
 		rs.Steps = append(rs.Steps, s)
 		return nil
+	case event.ETTransformFetch:
+		if tf, ok := e.Payload.(event.TransformFetch); ok {
+			rs.Fetches = append(rs.Fetches, tf)
+		}
+		return nil
+	case event.ETTransformDatasetLoad:
+		if dl, ok := e.Payload.(event.TransformDatasetLoad); ok {
+			if rs.Provenance == nil {
+				rs.Provenance = &Provenance{}
+			}
+			rs.Provenance.AddInput(dl.Ref, dl.Path)
+		}
+		return nil
 	case event.ETTransformPrint,
 		event.ETTransformError,
-		event.ETTransformDatasetPreview:
+		event.ETTransformDatasetPreview,
+		event.ETTransformStepOutput:
 		return rs.appendStepOutputLog(e)
 	}
 	return fmt.Errorf("unexpected event type: %q", e.Type)
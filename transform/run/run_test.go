@@ -2,10 +2,13 @@ package run
 
 import (
 	"fmt"
+	"io/ioutil"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
 	"github.com/qri-io/qri/event"
 )
 
@@ -129,3 +132,74 @@ func TestStateAddTransformEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestStateRecordsFetches(t *testing.T) {
+	runID := NewID()
+	fetch := event.TransformFetch{URL: "https://example.com/data.csv", Method: "GET", Status: 200, Attempts: 2, Hash: "abc"}
+	rs := NewState(runID)
+	if err := rs.AddTransformEvent(event.Event{Type: event.ETTransformFetch, SessionID: runID, Payload: fetch}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]event.TransformFetch{fetch}, rs.Fetches); diff != "" {
+		t.Errorf("fetches mismatch. (-want +got):\n%s", diff)
+	}
+}
+
+func TestStateRecordsProvenance(t *testing.T) {
+	tf := &dataset.Transform{
+		Config: map[string]interface{}{"year": "2020"},
+		Steps: []*dataset.TransformStep{
+			{Syntax: "starlark", Category: "setup", Script: "x = 1"},
+			{Syntax: "starlark", Category: "transform", Script: "def transform(ds, ctx):\n\tpass"},
+		},
+	}
+	prov, err := NewProvenance(tf, map[string]string{"token": "shhh", "api_key": "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prov.ScriptHash == "" {
+		t.Error("expected a script hash")
+	}
+	if diff := cmp.Diff([]string{"api_key", "token"}, prov.Secrets); diff != "" {
+		t.Errorf("secret names mismatch. (-want +got):\n%s", diff)
+	}
+
+	tf.Steps[0].Script = "x = 2"
+	changed, err := NewProvenance(tf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.ScriptHash == prov.ScriptHash {
+		t.Error("expected changing a step script to change the script hash")
+	}
+
+	tf = &dataset.Transform{}
+	tf.SetScriptFile(qfs.NewMemfileBytes("transform.star", []byte("x = 1")))
+	if prov, err = NewProvenance(tf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if prov.ScriptHash == "" {
+		t.Error("expected a script file hash")
+	}
+	data, err := ioutil.ReadAll(tf.ScriptFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "x = 1" {
+		t.Errorf("expected script file to be restored, got %q", string(data))
+	}
+
+	runID := NewID()
+	rs := NewState(runID)
+	rs.Provenance = prov
+	for _, ref := range []string{"me/a", "me/b", "me/a"} {
+		e := event.Event{Type: event.ETTransformDatasetLoad, SessionID: runID, Payload: event.TransformDatasetLoad{Ref: ref, Path: "/mem/" + ref}}
+		if err := rs.AddTransformEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := []DatasetVersion{{Ref: "me/a", Path: "/mem/me/a"}, {Ref: "me/b", Path: "/mem/me/b"}}
+	if diff := cmp.Diff(expect, rs.Provenance.Inputs); diff != "" {
+		t.Errorf("inputs mismatch. (-want +got):\n%s", diff)
+	}
+}
@@ -13,6 +13,9 @@ type Context struct {
 	// Results carries the return values of special function calls
 	results starlark.StringDict
 	values  starlark.StringDict
+	// outputs are values transform steps pass to later steps, keyed by step
+	// name
+	outputs starlark.StringDict
 	config  map[string]interface{}
 	secrets map[string]interface{}
 }
@@ -22,6 +25,7 @@ func NewContext(config, secrets map[string]interface{}) *Context {
 	return &Context{
 		results: starlark.StringDict{},
 		values:  starlark.StringDict{},
+		outputs: starlark.StringDict{},
 		config:  config,
 		secrets: secrets,
 	}
@@ -34,6 +38,7 @@ func (c *Context) Struct() *starlarkstruct.Struct {
 		"get":        starlark.NewBuiltin("get", c.getValue),
 		"get_config": starlark.NewBuiltin("get_config", c.GetConfig),
 		"get_secret": starlark.NewBuiltin("get_secret", c.GetSecret),
+		"output":     starlark.NewBuiltin("output", c.getOutput),
 	}
 
 	for k, v := range c.results {
@@ -49,6 +54,24 @@ func (c *Context) SetResult(name string, value starlark.Value) {
 	c.results[name] = value
 }
 
+// SetOutput records the output of a transform step. Outputs are frozen, later
+// steps read them with ctx.output(step_name)
+func (c *Context) SetOutput(step string, value starlark.Value) {
+	value.Freeze()
+	c.outputs[step] = value
+}
+
+func (c *Context) getOutput(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var step starlark.String
+	if err := starlark.UnpackArgs("output", args, kwargs, "step", &step); err != nil {
+		return starlark.None, err
+	}
+	if v, ok := c.outputs[string(step)]; ok {
+		return v, nil
+	}
+	return starlark.None, fmt.Errorf("no output from step %q", string(step))
+}
+
 func (c *Context) setValue(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var (
 		key   starlark.String
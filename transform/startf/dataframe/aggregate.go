@@ -0,0 +1,160 @@
+package dataframe
+
+import (
+	"fmt"
+)
+
+// aggregation is an output column of a group_by
+type aggregation struct {
+	title string
+	col   int
+	fn    string
+}
+
+// aggFuncs are the supported aggregation functions
+var aggFuncs = map[string]bool{
+	"count": true,
+	"sum":   true,
+	"mean":  true,
+	"min":   true,
+	"max":   true,
+	"first": true,
+	"last":  true,
+}
+
+// aggType gives the JSON schema type of an aggregation result
+func aggType(fn string, src interface{}) interface{} {
+	switch fn {
+	case "count":
+		return "integer"
+	case "mean":
+		return "number"
+	case "sum":
+		if src == "integer" {
+			return "integer"
+		}
+		return "number"
+	default:
+		return src
+	}
+}
+
+// aggregator accumulates the values of a column for one group. null values
+// are skipped
+type aggregator struct {
+	fn string
+
+	n     int
+	isum  int
+	fsum  float64
+	isInt bool
+	val   interface{}
+}
+
+func (a *aggregator) add(v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	a.n++
+
+	switch a.fn {
+	case "count":
+	case "sum", "mean":
+		i, f, isInt, ok := number(v)
+		if !ok {
+			return fmt.Errorf("can't %s non-numeric value %v", a.fn, v)
+		}
+		if a.n == 1 {
+			a.isInt = true
+		}
+		a.isInt = a.isInt && isInt
+		a.isum += i
+		a.fsum += f
+	case "first":
+		if a.n == 1 {
+			a.val = v
+		}
+	case "last":
+		a.val = v
+	case "min", "max":
+		if a.n == 1 {
+			a.val = v
+			return nil
+		}
+		c, err := compare(v, a.val)
+		if err != nil {
+			return err
+		}
+		if (a.fn == "min" && c < 0) || (a.fn == "max" && c > 0) {
+			a.val = v
+		}
+	}
+	return nil
+}
+
+func (a *aggregator) result() interface{} {
+	switch a.fn {
+	case "count":
+		return a.n
+	case "sum":
+		if a.isInt || a.n == 0 {
+			return a.isum
+		}
+		return a.fsum
+	case "mean":
+		if a.n == 0 {
+			return nil
+		}
+		return a.fsum / float64(a.n)
+	default:
+		return a.val
+	}
+}
+
+// number converts a numeric value to both int & float forms. isInt is true
+// if the value is a whole number type
+func number(v interface{}) (i int, f float64, isInt, ok bool) {
+	switch x := v.(type) {
+	case int:
+		return x, float64(x), true, true
+	case int32:
+		return int(x), float64(x), true, true
+	case int64:
+		return int(x), float64(x), true, true
+	case float32:
+		return int(x), float64(x), false, true
+	case float64:
+		return int(x), x, false, true
+	default:
+		return 0, 0, false, false
+	}
+}
+
+// compare orders two numbers or two strings
+func compare(a, b interface{}) (int, error) {
+	if _, af, _, ok := number(a); ok {
+		if _, bf, _, ok := number(b); ok {
+			switch {
+			case af < bf:
+				return -1, nil
+			case af > bf:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	}
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if aok && bok {
+		switch {
+		case as < bs:
+			return -1, nil
+		case as > bs:
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("can't compare %v and %v", a, b)
+}
@@ -0,0 +1,581 @@
+package dataframe
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// ModuleName defines the expected name for this Module when used
+// in starlark's load() function, eg: load('dataframe.star', 'dataframe')
+const ModuleName = "dataframe.star"
+
+var (
+	once            sync.Once
+	dataframeModule starlark.StringDict
+)
+
+// LoadModule loads the dataframe module.
+// It is concurrency-safe and idempotent.
+func LoadModule() (starlark.StringDict, error) {
+	once.Do(func() {
+		dataframeModule = starlark.StringDict{
+			"dataframe": starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+				"new": starlark.NewBuiltin("new", New),
+			}),
+		}
+	})
+	return dataframeModule, nil
+}
+
+// DataFrame is a lazily-evaluated table. Methods that transform a DataFrame
+// return a new DataFrame with an extended plan, leaving the original as-is
+type DataFrame struct {
+	n node
+}
+
+var (
+	_ starlark.Value    = (*DataFrame)(nil)
+	_ starlark.HasAttrs = (*DataFrame)(nil)
+)
+
+// New creates a DataFrame from starlark rows
+func New(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		rowsx starlark.Iterable
+		colsx *starlark.List
+	)
+	if err := starlark.UnpackArgs("new", args, kwargs, "rows", &rowsx, "columns?", &colsx); err != nil {
+		return nil, err
+	}
+
+	var titles []string
+	if colsx != nil {
+		for i := 0; i < colsx.Len(); i++ {
+			s, ok := starlark.AsString(colsx.Index(i))
+			if !ok {
+				return nil, fmt.Errorf("new: column names must be strings, got %s", colsx.Index(i).Type())
+			}
+			titles = append(titles, s)
+		}
+	}
+
+	var rows [][]interface{}
+	iter := rowsx.Iterate()
+	defer iter.Done()
+	var x starlark.Value
+	for i := 0; iter.Next(&x); i++ {
+		switch r := x.(type) {
+		case *starlark.Dict:
+			if titles == nil {
+				for _, k := range r.Keys() {
+					s, ok := starlark.AsString(k)
+					if !ok {
+						return nil, fmt.Errorf("new: row %d has a non-string key", i)
+					}
+					titles = append(titles, s)
+				}
+			}
+			row := make([]interface{}, len(titles))
+			for j, t := range titles {
+				v, found, err := r.Get(starlark.String(t))
+				if err != nil {
+					return nil, err
+				}
+				if found {
+					if row[j], err = util.Unmarshal(v); err != nil {
+						return nil, err
+					}
+				}
+			}
+			rows = append(rows, row)
+		case starlark.String:
+			return nil, fmt.Errorf("new: row %d must be a list or dict, got string", i)
+		case starlark.Indexable:
+			if titles == nil {
+				return nil, fmt.Errorf("new: columns are required when rows are lists")
+			}
+			if r.Len() != len(titles) {
+				return nil, fmt.Errorf("new: row %d has %d values, expected %d", i, r.Len(), len(titles))
+			}
+			row := make([]interface{}, r.Len())
+			for j := range row {
+				v, err := util.Unmarshal(r.Index(j))
+				if err != nil {
+					return nil, err
+				}
+				row[j] = v
+			}
+			rows = append(rows, row)
+		default:
+			return nil, fmt.Errorf("new: row %d must be a list or dict, got %s", i, x.Type())
+		}
+	}
+
+	cols := make([]column, len(titles))
+	for i, t := range titles {
+		cols[i] = column{Title: t}
+	}
+	return &DataFrame{n: &memNode{cols: cols, rows: rows}}, nil
+}
+
+// FromBody creates a DataFrame from a tabular dataset body. The body is
+// copied to a spill file the frame reads from each time its plan runs, and
+// the path of the copy is returned so callers can replace a consumed body
+func FromBody(thread *starlark.Thread, st *dataset.Structure, body io.Reader) (*DataFrame, string, error) {
+	if st == nil {
+		return nil, "", fmt.Errorf("error: no structure for dataset")
+	}
+	tcols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		return nil, "", fmt.Errorf("dataframes require a tabular body: %w", err)
+	}
+	cols := make([]column, len(tcols))
+	for i, tc := range tcols {
+		cols[i] = column{Title: tc.Title}
+		if tc.Type != nil && len(*tc.Type) == 1 {
+			cols[i].Type = (*tc.Type)[0]
+		} else if tc.Type != nil && len(*tc.Type) > 1 {
+			cols[i].Type = []string(*tc.Type)
+		}
+	}
+
+	f, err := spillFor(thread).create()
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		return nil, "", err
+	}
+	if err := f.Close(); err != nil {
+		return nil, "", err
+	}
+	return &DataFrame{n: &bodyNode{cols: cols, st: st, path: f.Name()}}, f.Name(), nil
+}
+
+// String implements the starlark.Value interface
+func (df *DataFrame) String() string {
+	return fmt.Sprintf("DataFrame(columns=[%s])", strings.Join(df.titles(), ", "))
+}
+
+// Type implements the starlark.Value interface
+func (df *DataFrame) Type() string { return "DataFrame" }
+
+// Freeze implements the starlark.Value interface. DataFrames are immutable
+func (df *DataFrame) Freeze() {}
+
+// Truth implements the starlark.Value interface
+func (df *DataFrame) Truth() starlark.Bool { return starlark.True }
+
+// Hash implements the starlark.Value interface
+func (df *DataFrame) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: DataFrame")
+}
+
+type dataFrameMethod func(df *DataFrame, thread *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error)
+
+var dataFrameMethods = map[string]dataFrameMethod{
+	"select":   dfSelect,
+	"filter":   dfFilter,
+	"group_by": dfGroupBy,
+	"join":     dfJoin,
+	"head":     dfHead,
+	"collect":  dfCollect,
+	"count":    dfCount,
+}
+
+// Attr implements the starlark.HasAttrs interface
+func (df *DataFrame) Attr(name string) (starlark.Value, error) {
+	if name == "columns" {
+		titles := df.titles()
+		vals := make([]starlark.Value, len(titles))
+		for i, t := range titles {
+			vals[i] = starlark.String(t)
+		}
+		return starlark.NewList(vals), nil
+	}
+	m, ok := dataFrameMethods[name]
+	if !ok {
+		return nil, nil
+	}
+	return starlark.NewBuiltin(name, func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		return m(df, thread, args, kwargs)
+	}), nil
+}
+
+// AttrNames implements the starlark.HasAttrs interface
+func (df *DataFrame) AttrNames() []string {
+	names := []string{"columns"}
+	for name := range dataFrameMethods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (df *DataFrame) titles() []string {
+	cols := df.n.columns()
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = c.Title
+	}
+	return titles
+}
+
+// Schema gives a tabular JSON schema for the frame's columns
+func (df *DataFrame) Schema() map[string]interface{} {
+	cols := df.n.columns()
+	items := make([]interface{}, len(cols))
+	for i, c := range cols {
+		col := map[string]interface{}{"title": c.Title}
+		if c.Type != nil {
+			col["type"] = c.Type
+		}
+		items[i] = col
+	}
+	return map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		},
+	}
+}
+
+// WriteBody runs the frame's plan, writing rows to a spill file in the format
+// described by st. It returns the path of the written file, which is removed
+// when the thread's Spill is closed
+func (df *DataFrame) WriteBody(thread *starlark.Thread, st *dataset.Structure) (string, error) {
+	x := newExecCtx(thread)
+	f, err := x.spill.create()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	w, err := dsio.NewEntryWriter(st, f)
+	if err != nil {
+		return "", err
+	}
+	it, err := df.n.open(x)
+	if err != nil {
+		return "", err
+	}
+	defer it.Close()
+
+	for i := 0; ; i++ {
+		row, err := it.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if err := w.WriteEntry(dsio.Entry{Index: i, Value: row}); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Materialize runs the frame's plan once, returning a frame that reads the
+// results instead of running the plan again. Results are held in memory up to
+// the row limit of the thread's Spill, larger results are written to a spill
+// file. storage is "memory" or "disk"
+func (df *DataFrame) Materialize(thread *starlark.Thread) (res *DataFrame, rows int, storage string, err error) {
+	x := newExecCtx(thread)
+	it, err := df.n.open(x)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	defer it.Close()
+
+	var (
+		mem [][]interface{}
+		w   *rowWriter
+	)
+	discard := func() {
+		if w != nil {
+			path, _ := w.Close()
+			x.spill.remove(path)
+		}
+	}
+	for {
+		row, err := it.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			discard()
+			return nil, 0, "", err
+		}
+		rows++
+
+		if w == nil {
+			mem = append(mem, row)
+			if len(mem) <= x.spill.maxRows {
+				continue
+			}
+			if w, err = x.spill.newRowWriter(); err != nil {
+				return nil, 0, "", err
+			}
+			for _, r := range mem {
+				if err := w.Write(r); err != nil {
+					discard()
+					return nil, 0, "", err
+				}
+			}
+			mem = nil
+			continue
+		}
+		if err := w.Write(row); err != nil {
+			discard()
+			return nil, 0, "", err
+		}
+	}
+
+	cols := df.n.columns()
+	if w == nil {
+		return &DataFrame{n: &memNode{cols: cols, rows: mem}}, rows, "memory", nil
+	}
+	path, err := w.Close()
+	if err != nil {
+		x.spill.remove(path)
+		return nil, 0, "", err
+	}
+	return &DataFrame{n: &rowFileNode{cols: cols, path: path}}, rows, "disk", nil
+}
+
+// columnIndex finds the position of a column by name
+func columnIndex(cols []column, name string) (int, error) {
+	for i, c := range cols {
+		if c.Title == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no column named %q", name)
+}
+
+// columnNames unpacks a list of column names from positional arguments
+func columnNames(fn string, args starlark.Tuple, kwargs []starlark.Tuple) ([]string, error) {
+	if len(kwargs) > 0 {
+		return nil, fmt.Errorf("%s: unexpected keyword arguments", fn)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s: at least one column is required", fn)
+	}
+	names := make([]string, len(args))
+	for i, a := range args {
+		s, ok := starlark.AsString(a)
+		if !ok {
+			return nil, fmt.Errorf("%s: column names must be strings, got %s", fn, a.Type())
+		}
+		names[i] = s
+	}
+	return names, nil
+}
+
+func dfSelect(df *DataFrame, thread *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	names, err := columnNames("select", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	src := df.n.columns()
+	n := &selectNode{src: df.n, idx: make([]int, len(names)), cols: make([]column, len(names))}
+	for i, name := range names {
+		if n.idx[i], err = columnIndex(src, name); err != nil {
+			return nil, fmt.Errorf("select: %w", err)
+		}
+		n.cols[i] = src[n.idx[i]]
+	}
+	return &DataFrame{n: n}, nil
+}
+
+func dfFilter(df *DataFrame, thread *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var fn starlark.Callable
+	if err := starlark.UnpackPositionalArgs("filter", args, kwargs, 1, &fn); err != nil {
+		return nil, err
+	}
+	return &DataFrame{n: &filterNode{src: df.n, fn: fn}}, nil
+}
+
+func dfHead(df *DataFrame, thread *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var n int
+	if err := starlark.UnpackPositionalArgs("head", args, kwargs, 1, &n); err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("head: n must be positive")
+	}
+	return &DataFrame{n: &limitNode{src: df.n, n: n}}, nil
+}
+
+func dfGroupBy(df *DataFrame, thread *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	names, err := columnNames("group_by", args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	src := df.n.columns()
+	keys := make([]int, len(names))
+	for i, name := range names {
+		if keys[i], err = columnIndex(src, name); err != nil {
+			return nil, fmt.Errorf("group_by: %w", err)
+		}
+	}
+
+	agg := func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("agg: aggregations must be keyword arguments")
+		}
+		n := &groupNode{src: df.n, keys: keys}
+		for _, k := range keys {
+			n.cols = append(n.cols, src[k])
+		}
+		for _, kw := range kwargs {
+			title := string(kw[0].(starlark.String))
+			spec, ok := kw[1].(starlark.Tuple)
+			if !ok || len(spec) != 2 {
+				return nil, fmt.Errorf("agg: %s must be a (column, function) tuple", title)
+			}
+			colName, ok := starlark.AsString(spec[0])
+			if !ok {
+				return nil, fmt.Errorf("agg: %s column must be a string", title)
+			}
+			fn, ok := starlark.AsString(spec[1])
+			if !ok || !aggFuncs[fn] {
+				return nil, fmt.Errorf("agg: %s has unknown function %s", title, spec[1])
+			}
+			col, err := columnIndex(src, colName)
+			if err != nil {
+				return nil, fmt.Errorf("agg: %w", err)
+			}
+			n.aggs = append(n.aggs, aggregation{title: title, col: col, fn: fn})
+			n.cols = append(n.cols, column{Title: title, Type: aggType(fn, src[col].Type)})
+		}
+		return &DataFrame{n: n}, nil
+	}
+
+	return starlarkstruct.FromStringDict(starlark.String("GroupBy"), starlark.StringDict{
+		"agg": starlark.NewBuiltin("agg", agg),
+	}), nil
+}
+
+func dfJoin(df *DataFrame, thread *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var (
+		other *DataFrame
+		onx   starlark.Value
+		how   = "inner"
+	)
+	if err := starlark.UnpackArgs("join", args, kwargs, "other", &other, "on", &onx, "how?", &how); err != nil {
+		return nil, err
+	}
+	if how != "inner" && how != "left" {
+		return nil, fmt.Errorf("join: how must be \"inner\" or \"left\", got %q", how)
+	}
+
+	var on []string
+	if s, ok := starlark.AsString(onx); ok {
+		on = []string{s}
+	} else if l, ok := onx.(*starlark.List); ok {
+		for i := 0; i < l.Len(); i++ {
+			s, ok := starlark.AsString(l.Index(i))
+			if !ok {
+				return nil, fmt.Errorf("join: on must be a column name or list of column names")
+			}
+			on = append(on, s)
+		}
+	}
+	if len(on) == 0 {
+		return nil, fmt.Errorf("join: on must be a column name or list of column names")
+	}
+
+	leftCols, rightCols := df.n.columns(), other.n.columns()
+	n := &joinNode{
+		left:      df.n,
+		right:     other.n,
+		leftKeys:  make([]int, len(on)),
+		rightKeys: make([]int, len(on)),
+		outer:     how == "left",
+		cols:      append([]column{}, leftCols...),
+	}
+	var err error
+	isKey := map[int]bool{}
+	for i, name := range on {
+		if n.leftKeys[i], err = columnIndex(leftCols, name); err != nil {
+			return nil, fmt.Errorf("join: left %w", err)
+		}
+		if n.rightKeys[i], err = columnIndex(rightCols, name); err != nil {
+			return nil, fmt.Errorf("join: right %w", err)
+		}
+		isKey[n.rightKeys[i]] = true
+	}
+	for i, c := range rightCols {
+		if isKey[i] {
+			continue
+		}
+		if _, err := columnIndex(leftCols, c.Title); err == nil {
+			c.Title += "_right"
+		}
+		n.rightIdx = append(n.rightIdx, i)
+		n.cols = append(n.cols, c)
+	}
+	return &DataFrame{n: n}, nil
+}
+
+func dfCollect(df *DataFrame, thread *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs("collect", args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	it, err := df.n.open(newExecCtx(thread))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var rows []starlark.Value
+	for {
+		row, err := it.Next()
+		if err == io.EOF {
+			return starlark.NewList(rows), nil
+		} else if err != nil {
+			return nil, err
+		}
+		v, err := util.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, v)
+	}
+}
+
+func dfCount(df *DataFrame, thread *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs("count", args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	it, err := df.n.open(newExecCtx(thread))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	count := 0
+	for {
+		if _, err := it.Next(); err == io.EOF {
+			return starlark.MakeInt(count), nil
+		} else if err != nil {
+			return nil, err
+		}
+		count++
+	}
+}
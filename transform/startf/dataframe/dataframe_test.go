@@ -0,0 +1,246 @@
+package dataframe
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+)
+
+const planScript = `
+load("dataframe.star", "dataframe")
+
+cities = dataframe.new([
+  ["toronto", "ca", 2731571],
+  ["vancouver", "ca", 631486],
+  ["chicago", "us", 2705994],
+  ["new york", "us", 8398748],
+  ["chatham", None, 40000],
+], columns=["city", "country", "pop"])
+
+countries = dataframe.new([
+  {"country": "ca", "name": "canada"},
+  {"country": "us", "name": "united states"},
+])
+
+big = cities.filter(lambda r: r["pop"] > 1000000).select("city", "pop")
+by_country = cities.group_by("country").agg(n=("city", "count"), total=("pop", "sum"), largest=("pop", "max"))
+joined = cities.join(countries, on="country").select("city", "name")
+left = cities.join(countries, on="country", how="left")
+
+columns = by_country.columns
+big_rows = big.collect()
+big_count = big.count()
+by_country_rows = by_country.collect()
+joined_rows = joined.collect()
+left_count = left.count()
+head_rows = cities.head(2).select("city").collect()
+`
+
+func runPlanScript(t *testing.T, maxRows int) starlark.StringDict {
+	resolve.AllowLambda = true
+	dir, err := ioutil.TempDir("", "dataframe_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	thread := &starlark.Thread{Load: func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		return LoadModule()
+	}}
+	spill := NewSpill(dir, maxRows)
+	SetSpill(thread, spill)
+
+	globals, err := starlark.ExecFile(thread, "plan.star", planScript, nil)
+	if err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			t.Fatal(evalErr.Backtrace())
+		}
+		t.Fatal(err)
+	}
+	if err := spill.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("expected spill to remove all files, %d remain", len(files))
+	}
+	return globals
+}
+
+func TestPlan(t *testing.T) {
+	cases := []struct {
+		description string
+		maxRows     int
+	}{
+		{"in memory", DefaultMaxRows},
+		{"spill to disk", 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			g := runPlanScript(t, c.maxRows)
+
+			expect := map[string]string{
+				"columns":         `["country", "n", "total", "largest"]`,
+				"big_rows":        `[["toronto", 2731571], ["chicago", 2705994], ["new york", 8398748]]`,
+				"big_count":       `3`,
+				"by_country_rows": `[["ca", 2, 3363057, 2731571], ["us", 2, 11104742, 8398748], [None, 1, 40000, 40000]]`,
+				"joined_rows":     `[["toronto", "canada"], ["vancouver", "canada"], ["chicago", "united states"], ["new york", "united states"]]`,
+				"left_count":      `5`,
+				"head_rows":       `[["toronto"], ["vancouver"]]`,
+			}
+			for name, exp := range expect {
+				got := g[name].String()
+				if c.maxRows == 1 && strings.HasSuffix(name, "_rows") && name != "big_rows" && name != "head_rows" {
+					// spilled partitions don't preserve row order
+					if !sameRows(t, exp, g[name]) {
+						t.Errorf("%s mismatch. expected rows of: %s, got: %s", name, exp, got)
+					}
+					continue
+				}
+				if got != exp {
+					t.Errorf("%s mismatch.\nwant: %s\ngot:  %s", name, exp, got)
+				}
+			}
+		})
+	}
+}
+
+// sameRows compares lists of rows, ignoring order
+func sameRows(t *testing.T, expect string, got starlark.Value) bool {
+	l, ok := got.(*starlark.List)
+	if !ok {
+		t.Fatalf("expected list, got %s", got.Type())
+	}
+	remaining := expect
+	for i := 0; i < l.Len(); i++ {
+		row := l.Index(i).String()
+		if !strings.Contains(remaining, row) {
+			return false
+		}
+		remaining = strings.Replace(remaining, row, "", 1)
+	}
+	return strings.Trim(remaining, "[], ") == ""
+}
+
+func TestFromBody(t *testing.T) {
+	st := &dataset.Structure{
+		Format:       "csv",
+		FormatConfig: map[string]interface{}{"headerRow": true},
+		Schema: map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": []interface{}{
+					map[string]interface{}{"title": "city", "type": "string"},
+					map[string]interface{}{"title": "pop", "type": "integer"},
+				},
+			},
+		},
+	}
+	thread := &starlark.Thread{}
+	spill := NewSpill("", DefaultMaxRows)
+	defer spill.Close()
+	SetSpill(thread, spill)
+
+	df, _, err := FromBody(thread, st, strings.NewReader("city,pop\ntoronto,2731571\nchatham,40000\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if df.String() != "DataFrame(columns=[city, pop])" {
+		t.Errorf("unexpected string: %s", df.String())
+	}
+	// plans built from a body can run more than once
+	for i := 0; i < 2; i++ {
+		count, err := dfCount(df, thread, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count.String() != "2" {
+			t.Errorf("run %d: expected count of 2, got %s", i, count)
+		}
+	}
+
+	out := &dataset.Structure{Format: "json", Schema: df.Schema()}
+	path, err := df.WriteBody(thread, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `[["toronto",2731571],["chatham",40000]]`
+	if got := strings.Join(strings.Fields(string(data)), ""); got != expect {
+		t.Errorf("body mismatch.\nwant: %s\ngot:  %s", expect, got)
+	}
+}
+
+func TestMaterialize(t *testing.T) {
+	cases := []struct {
+		description string
+		maxRows     int
+		storage     string
+	}{
+		{"in memory", DefaultMaxRows, "memory"},
+		{"spill to disk", 2, "disk"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "dataframe_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			resolve.AllowLambda = true
+			thread := &starlark.Thread{Load: func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+				return LoadModule()
+			}}
+			spill := NewSpill(dir, c.maxRows)
+			SetSpill(thread, spill)
+
+			globals, err := starlark.ExecFile(thread, "materialize.star", `
+load("dataframe.star", "dataframe")
+df = dataframe.new([["a", 1], ["b", 2], ["c", 3], ["d", 4]], columns=["letter", "n"]).filter(lambda r: r["n"] > 1)
+`, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, rows, storage, err := globals["df"].(*DataFrame).Materialize(thread)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rows != 3 {
+				t.Errorf("rows mismatch. want: 3, got: %d", rows)
+			}
+			if storage != c.storage {
+				t.Errorf("storage mismatch. want: %q, got: %q", c.storage, storage)
+			}
+			// materialized frames can be read more than once
+			for i := 0; i < 2; i++ {
+				got, err := dfCollect(res, thread, nil, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if expect := `[["b", 2], ["c", 3], ["d", 4]]`; got.String() != expect {
+					t.Errorf("rows mismatch.\nwant: %s\ngot:  %s", expect, got)
+				}
+			}
+
+			if err := spill.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+				t.Errorf("expected spill to remove all files, %d remain", len(files))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+/*Package dataframe defines a lazily-evaluated table type for starlark
+
+  outline: dataframe
+    dataframe defines a table type for working with dataset bodies too large to
+    hold in memory as lists. Operations on a DataFrame build a plan instead of
+    computing results. Plans run when results are needed: calling collect() or
+    count(), or passing the frame to a dataset's set_body method. Rows stream
+    through each step of the plan, and steps that need to see every row before
+    producing output (group_by & join) spill to disk once they hold more than a
+    set number of rows. The input of a DataFrame built from a dataset body is
+    copied to disk, so plans can run more than once without reloading the body
+
+    functions:
+      new(rows list, columns? list) DataFrame
+        create a DataFrame from a list of rows. rows can be lists or dicts.
+        columns is required when rows are lists
+
+    types:
+      DataFrame
+        a table with named columns
+        fields:
+          columns list
+            column names, in order
+        methods:
+          select(*columns) DataFrame
+            keep only the named columns, in the given order
+          filter(fn) DataFrame
+            keep rows where fn(row) is truthy. rows are passed as dicts
+          group_by(*columns) GroupBy
+            group rows that share values in columns
+          join(other DataFrame, on string|list, how? string) DataFrame
+            join rows with equal values in the on columns. how is "inner"
+            (the default) or "left"
+          head(n int) DataFrame
+            keep the first n rows
+          collect() list
+            run the plan, returning rows as lists
+          count() int
+            run the plan, returning the number of rows
+      GroupBy
+        rows of a DataFrame grouped by column values
+        methods:
+          agg(**aggregations) DataFrame
+            aggregate each group, one row per group. each keyword is an output
+            column, and each value a (column, function) tuple. functions are
+            count, sum, mean, min, max, first, and last
+*/
+package dataframe
@@ -0,0 +1,563 @@
+package dataframe
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+)
+
+// column is a named column with an optional JSON schema type
+type column struct {
+	Title string
+	Type  interface{}
+}
+
+// rowIter produces rows one at a time, returning io.EOF when done
+type rowIter interface {
+	Next() ([]interface{}, error)
+	Close() error
+}
+
+// node is a step in a dataframe plan. opening a node runs the plan up to it
+type node interface {
+	columns() []column
+	open(x *execCtx) (rowIter, error)
+}
+
+// execCtx is the state of a running plan
+type execCtx struct {
+	thread *starlark.Thread
+	spill  *Spill
+}
+
+func newExecCtx(thread *starlark.Thread) *execCtx {
+	return &execCtx{thread: thread, spill: spillFor(thread)}
+}
+
+// sliceIter iterates rows held in memory
+type sliceIter struct {
+	rows [][]interface{}
+	i    int
+}
+
+func (it *sliceIter) Next() ([]interface{}, error) {
+	if it.i >= len(it.rows) {
+		return nil, io.EOF
+	}
+	row := it.rows[it.i]
+	it.i++
+	return row, nil
+}
+
+func (it *sliceIter) Close() error { return nil }
+
+// memNode is a plan input of rows held in memory
+type memNode struct {
+	cols []column
+	rows [][]interface{}
+}
+
+func (n *memNode) columns() []column { return n.cols }
+
+func (n *memNode) open(x *execCtx) (rowIter, error) {
+	return &sliceIter{rows: n.rows}, nil
+}
+
+// bodyNode is a plan input of a dataset body file on disk
+type bodyNode struct {
+	cols []column
+	st   *dataset.Structure
+	path string
+}
+
+func (n *bodyNode) columns() []column { return n.cols }
+
+func (n *bodyNode) open(x *execCtx) (rowIter, error) {
+	f, err := os.Open(n.path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := dsio.NewEntryReader(n.st, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &entryIter{f: f, r: r}, nil
+}
+
+// rowFileNode is a plan input of materialized rows in a spill file
+type rowFileNode struct {
+	cols []column
+	path string
+}
+
+func (n *rowFileNode) columns() []column { return n.cols }
+
+func (n *rowFileNode) open(x *execCtx) (rowIter, error) {
+	return openRowFile(n.path)
+}
+
+// entryIter reads rows from a dataset body
+type entryIter struct {
+	f *os.File
+	r dsio.EntryReader
+}
+
+func (it *entryIter) Next() ([]interface{}, error) {
+	ent, err := it.r.ReadEntry()
+	if err != nil {
+		if err.Error() == io.EOF.Error() {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	row, ok := ent.Value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("body row %d isn't a list", ent.Index)
+	}
+	return row, nil
+}
+
+func (it *entryIter) Close() error {
+	it.r.Close()
+	return it.f.Close()
+}
+
+// selectNode keeps a subset of columns
+type selectNode struct {
+	src  node
+	idx  []int
+	cols []column
+}
+
+func (n *selectNode) columns() []column { return n.cols }
+
+func (n *selectNode) open(x *execCtx) (rowIter, error) {
+	src, err := n.src.open(x)
+	if err != nil {
+		return nil, err
+	}
+	return &selectIter{src: src, idx: n.idx}, nil
+}
+
+type selectIter struct {
+	src rowIter
+	idx []int
+}
+
+func (it *selectIter) Next() ([]interface{}, error) {
+	row, err := it.src.Next()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(it.idx))
+	for i, j := range it.idx {
+		out[i] = row[j]
+	}
+	return out, nil
+}
+
+func (it *selectIter) Close() error { return it.src.Close() }
+
+// filterNode keeps rows a starlark function returns a truthy value for
+type filterNode struct {
+	src node
+	fn  starlark.Callable
+}
+
+func (n *filterNode) columns() []column { return n.src.columns() }
+
+func (n *filterNode) open(x *execCtx) (rowIter, error) {
+	src, err := n.src.open(x)
+	if err != nil {
+		return nil, err
+	}
+	return &filterIter{x: x, src: src, cols: n.src.columns(), fn: n.fn}, nil
+}
+
+type filterIter struct {
+	x    *execCtx
+	src  rowIter
+	cols []column
+	fn   starlark.Callable
+}
+
+func (it *filterIter) Next() ([]interface{}, error) {
+	for {
+		row, err := it.src.Next()
+		if err != nil {
+			return nil, err
+		}
+		d, err := rowDict(it.cols, row)
+		if err != nil {
+			return nil, err
+		}
+		keep, err := starlark.Call(it.x.thread, it.fn, starlark.Tuple{d}, nil)
+		if err != nil {
+			return nil, err
+		}
+		if keep.Truth() {
+			return row, nil
+		}
+	}
+}
+
+func (it *filterIter) Close() error { return it.src.Close() }
+
+// rowDict converts a row to a starlark dict keyed by column title
+func rowDict(cols []column, row []interface{}) (*starlark.Dict, error) {
+	d := starlark.NewDict(len(cols))
+	for i, c := range cols {
+		var v interface{}
+		if i < len(row) {
+			v = row[i]
+		}
+		val, err := util.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.SetKey(starlark.String(c.Title), val); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// limitNode keeps the first n rows
+type limitNode struct {
+	src node
+	n   int
+}
+
+func (n *limitNode) columns() []column { return n.src.columns() }
+
+func (n *limitNode) open(x *execCtx) (rowIter, error) {
+	src, err := n.src.open(x)
+	if err != nil {
+		return nil, err
+	}
+	return &limitIter{src: src, left: n.n}, nil
+}
+
+type limitIter struct {
+	src  rowIter
+	left int
+}
+
+func (it *limitIter) Next() ([]interface{}, error) {
+	if it.left <= 0 {
+		return nil, io.EOF
+	}
+	it.left--
+	return it.src.Next()
+}
+
+func (it *limitIter) Close() error { return it.src.Close() }
+
+// groupNode aggregates rows grouped by key columns. Groups are held in memory
+// until there are more than the spill row limit, at which point input is
+// partitioned to disk by key & each partition is aggregated separately
+type groupNode struct {
+	src  node
+	keys []int
+	aggs []aggregation
+	cols []column
+}
+
+func (n *groupNode) columns() []column { return n.cols }
+
+func (n *groupNode) open(x *execCtx) (rowIter, error) {
+	src, err := n.src.open(x)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := n.aggregate(src, x.spill.maxRows)
+	src.Close()
+	if err == nil {
+		return &sliceIter{rows: rows}, nil
+	} else if err != errTooManyRows {
+		return nil, err
+	}
+
+	if src, err = n.src.open(x); err != nil {
+		return nil, err
+	}
+	paths, err := partition(x, src, n.keys)
+	src.Close()
+	if err != nil {
+		return nil, err
+	}
+	return &partitionIter{x: x, paths: paths, run: func(parts []rowIter) (rowIter, error) {
+		rows, err := n.aggregate(parts[0], 0)
+		if err != nil {
+			return nil, err
+		}
+		return &sliceIter{rows: rows}, nil
+	}}, nil
+}
+
+// aggregate groups rows, returning one row per group in the order groups are
+// first seen. a limit above zero caps the number of groups
+func (n *groupNode) aggregate(it rowIter, limit int) ([][]interface{}, error) {
+	type group struct {
+		key  []interface{}
+		aggs []*aggregator
+	}
+	groups := map[string]*group{}
+	order := []*group{}
+
+	for {
+		row, err := it.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		k := groupKey(row, n.keys)
+		g, ok := groups[k]
+		if !ok {
+			if limit > 0 && len(order) >= limit {
+				return nil, errTooManyRows
+			}
+			g = &group{key: make([]interface{}, len(n.keys)), aggs: make([]*aggregator, len(n.aggs))}
+			for i, k := range n.keys {
+				g.key[i] = row[k]
+			}
+			for i, a := range n.aggs {
+				g.aggs[i] = &aggregator{fn: a.fn}
+			}
+			groups[k] = g
+			order = append(order, g)
+		}
+		for i, a := range n.aggs {
+			if err := g.aggs[i].add(row[a.col]); err != nil {
+				return nil, fmt.Errorf("aggregating %q: %w", a.title, err)
+			}
+		}
+	}
+
+	rows := make([][]interface{}, len(order))
+	for i, g := range order {
+		row := append([]interface{}{}, g.key...)
+		for _, a := range g.aggs {
+			row = append(row, a.result())
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// joinNode combines rows from two nodes with equal key values. The right
+// side is held in a hash table unless it has more rows than the spill row
+// limit, in which case both sides are partitioned to disk & joined one
+// partition at a time
+type joinNode struct {
+	left, right         node
+	leftKeys, rightKeys []int
+	// rightIdx are the columns of the right side kept in output
+	rightIdx []int
+	outer    bool
+	cols     []column
+}
+
+func (n *joinNode) columns() []column { return n.cols }
+
+func (n *joinNode) open(x *execCtx) (rowIter, error) {
+	right, err := n.right.open(x)
+	if err != nil {
+		return nil, err
+	}
+	table, err := buildTable(right, n.rightKeys, x.spill.maxRows)
+	right.Close()
+	if err == nil {
+		left, err := n.left.open(x)
+		if err != nil {
+			return nil, err
+		}
+		return n.hashJoin(left, table), nil
+	} else if err != errTooManyRows {
+		return nil, err
+	}
+
+	if right, err = n.right.open(x); err != nil {
+		return nil, err
+	}
+	rightPaths, err := partition(x, right, n.rightKeys)
+	right.Close()
+	if err != nil {
+		return nil, err
+	}
+	left, err := n.left.open(x)
+	if err != nil {
+		return nil, err
+	}
+	leftPaths, err := partition(x, left, n.leftKeys)
+	left.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(leftPaths)*2)
+	for i := range leftPaths {
+		paths = append(paths, leftPaths[i], rightPaths[i])
+	}
+	return &partitionIter{x: x, paths: paths, width: 2, run: func(parts []rowIter) (rowIter, error) {
+		table, err := buildTable(parts[1], n.rightKeys, 0)
+		if err != nil {
+			return nil, err
+		}
+		return n.hashJoin(parts[0], table), nil
+	}}, nil
+}
+
+func (n *joinNode) hashJoin(left rowIter, table map[string][][]interface{}) rowIter {
+	return &hashJoinIter{
+		left:     left,
+		table:    table,
+		keys:     n.leftKeys,
+		rightIdx: n.rightIdx,
+		outer:    n.outer,
+	}
+}
+
+// buildTable reads rows into a hash table by key. a limit above zero caps the
+// number of rows read
+func buildTable(it rowIter, keys []int, limit int) (map[string][][]interface{}, error) {
+	table := map[string][][]interface{}{}
+	count := 0
+	for {
+		row, err := it.Next()
+		if err == io.EOF {
+			return table, nil
+		} else if err != nil {
+			return nil, err
+		}
+		count++
+		if limit > 0 && count > limit {
+			return nil, errTooManyRows
+		}
+		if k, ok := keyString(row, keys); ok {
+			table[k] = append(table[k], row)
+		}
+	}
+}
+
+type hashJoinIter struct {
+	left     rowIter
+	table    map[string][][]interface{}
+	keys     []int
+	rightIdx []int
+	outer    bool
+
+	pending [][]interface{}
+}
+
+func (it *hashJoinIter) Next() ([]interface{}, error) {
+	for len(it.pending) == 0 {
+		row, err := it.left.Next()
+		if err != nil {
+			return nil, err
+		}
+		var matches [][]interface{}
+		if k, ok := keyString(row, it.keys); ok {
+			matches = it.table[k]
+		}
+		if len(matches) == 0 && it.outer {
+			matches = [][]interface{}{nil}
+		}
+		for _, m := range matches {
+			out := make([]interface{}, len(row), len(row)+len(it.rightIdx))
+			copy(out, row)
+			for _, j := range it.rightIdx {
+				if m == nil {
+					out = append(out, nil)
+				} else {
+					out = append(out, m[j])
+				}
+			}
+			it.pending = append(it.pending, out)
+		}
+	}
+	row := it.pending[0]
+	it.pending = it.pending[1:]
+	return row, nil
+}
+
+func (it *hashJoinIter) Close() error { return it.left.Close() }
+
+// partitionIter runs a step over groups of spilled partition files, removing
+// each group of files once it's consumed
+type partitionIter struct {
+	x     *execCtx
+	paths []string
+	// width is the number of files per partition, defaulting to one
+	width int
+	run   func(parts []rowIter) (rowIter, error)
+
+	parts     []rowIter
+	partPaths []string
+	cur       rowIter
+}
+
+func (it *partitionIter) Next() ([]interface{}, error) {
+	width := it.width
+	if width == 0 {
+		width = 1
+	}
+	for {
+		if it.cur != nil {
+			row, err := it.cur.Next()
+			if err != io.EOF {
+				return row, err
+			}
+			it.closeCurrent()
+		}
+		if len(it.paths) == 0 {
+			return nil, io.EOF
+		}
+
+		it.partPaths = it.paths[:width]
+		it.paths = it.paths[width:]
+		it.parts = make([]rowIter, 0, width)
+		for _, path := range it.partPaths {
+			f, err := openRowFile(path)
+			if err != nil {
+				it.closeCurrent()
+				return nil, err
+			}
+			it.parts = append(it.parts, f)
+		}
+		cur, err := it.run(it.parts)
+		if err != nil {
+			it.closeCurrent()
+			return nil, err
+		}
+		it.cur = cur
+	}
+}
+
+func (it *partitionIter) closeCurrent() {
+	for _, p := range it.parts {
+		p.Close()
+	}
+	for _, path := range it.partPaths {
+		it.x.spill.remove(path)
+	}
+	it.parts = nil
+	it.partPaths = nil
+	it.cur = nil
+}
+
+func (it *partitionIter) Close() error {
+	it.closeCurrent()
+	for _, path := range it.paths {
+		it.x.spill.remove(path)
+	}
+	it.paths = nil
+	return nil
+}
@@ -0,0 +1,257 @@
+package dataframe
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"go.starlark.net/starlark"
+)
+
+// DefaultMaxRows is the number of rows a plan step holds in memory before
+// spilling to disk
+const DefaultMaxRows = 100000
+
+// partitions is the number of files rows are split into when a step spills
+const partitions = 16
+
+// spillThreadKey is the starlark thread-local key for a Spill
+const spillThreadKey = "dataframe.spill"
+
+// errTooManyRows is returned when an in-memory step exceeds its row limit
+var errTooManyRows = errors.New("too many rows to hold in memory")
+
+// Spill tracks temporary files dataframes write to disk. Plans running on a
+// thread use the Spill assigned with SetSpill
+type Spill struct {
+	dir     string
+	maxRows int
+
+	lk    sync.Mutex
+	paths map[string]struct{}
+}
+
+// NewSpill creates a Spill that writes files to dir, holding at most maxRows
+// rows in memory per plan step. An empty dir uses the OS temp directory
+func NewSpill(dir string, maxRows int) *Spill {
+	if maxRows <= 0 {
+		maxRows = DefaultMaxRows
+	}
+	return &Spill{
+		dir:     dir,
+		maxRows: maxRows,
+		paths:   map[string]struct{}{},
+	}
+}
+
+// SetSpill assigns the Spill dataframes on a thread write to
+func SetSpill(thread *starlark.Thread, s *Spill) {
+	thread.SetLocal(spillThreadKey, s)
+}
+
+// spillFor gets the Spill assigned to a thread, falling back to defaults
+func spillFor(thread *starlark.Thread) *Spill {
+	if thread != nil {
+		if s, ok := thread.Local(spillThreadKey).(*Spill); ok {
+			return s
+		}
+	}
+	return NewSpill("", DefaultMaxRows)
+}
+
+// Close removes all files the Spill created
+func (s *Spill) Close() (err error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	for path := range s.paths {
+		if rmErr := os.Remove(path); rmErr != nil && !os.IsNotExist(rmErr) && err == nil {
+			err = rmErr
+		}
+		delete(s.paths, path)
+	}
+	return err
+}
+
+// create opens a new temp file
+func (s *Spill) create() (*os.File, error) {
+	f, err := ioutil.TempFile(s.dir, "qri_dataframe_")
+	if err != nil {
+		return nil, err
+	}
+	s.lk.Lock()
+	s.paths[f.Name()] = struct{}{}
+	s.lk.Unlock()
+	return f, nil
+}
+
+// remove deletes a file the Spill created
+func (s *Spill) remove(path string) {
+	s.lk.Lock()
+	delete(s.paths, path)
+	s.lk.Unlock()
+	os.Remove(path)
+}
+
+// rowWriter writes rows to a spill file as lines of JSON arrays
+type rowWriter struct {
+	f   *os.File
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (s *Spill) newRowWriter() (*rowWriter, error) {
+	f, err := s.create()
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(f)
+	return &rowWriter{f: f, buf: buf, enc: json.NewEncoder(buf)}, nil
+}
+
+func (w *rowWriter) Write(row []interface{}) error {
+	return w.enc.Encode(row)
+}
+
+// Close flushes & closes the file, returning its path
+func (w *rowWriter) Close() (string, error) {
+	if err := w.buf.Flush(); err != nil {
+		w.f.Close()
+		return "", err
+	}
+	return w.f.Name(), w.f.Close()
+}
+
+// rowFileIter reads rows written by a rowWriter
+type rowFileIter struct {
+	f   *os.File
+	dec *json.Decoder
+}
+
+func openRowFile(path string) (*rowFileIter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bufio.NewReader(f))
+	dec.UseNumber()
+	return &rowFileIter{f: f, dec: dec}, nil
+}
+
+func (it *rowFileIter) Next() ([]interface{}, error) {
+	var row []interface{}
+	if err := it.dec.Decode(&row); err != nil {
+		return nil, err
+	}
+	for i, v := range row {
+		row[i] = fromJSON(v)
+	}
+	return row, nil
+}
+
+func (it *rowFileIter) Close() error {
+	return it.f.Close()
+}
+
+// fromJSON converts json.Number values back to ints & floats
+func fromJSON(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := x.Float64()
+		return f
+	case []interface{}:
+		for i, el := range x {
+			x[i] = fromJSON(el)
+		}
+	case map[string]interface{}:
+		for k, el := range x {
+			x[k] = fromJSON(el)
+		}
+	}
+	return v
+}
+
+// keyString encodes the values of key columns in a row as a map key. ok is
+// false if any key value is null, which never matches in a join
+func keyString(row []interface{}, keys []int) (key string, ok bool) {
+	for _, k := range keys {
+		if row[k] == nil {
+			return "", false
+		}
+	}
+	return groupKey(row, keys), true
+}
+
+// groupKey encodes the values of key columns in a row as a map key, treating
+// null as a value
+func groupKey(row []interface{}, keys []int) string {
+	vals := make([]interface{}, len(keys))
+	for i, k := range keys {
+		vals[i] = row[k]
+	}
+	data, _ := json.Marshal(vals)
+	return string(data)
+}
+
+// partition splits rows into spill files by a hash of key columns, so all
+// rows with the same key land in the same file. Rows with null keys go in the
+// first file
+func partition(x *execCtx, it rowIter, keys []int) ([]string, error) {
+	writers := make([]*rowWriter, partitions)
+	closeAll := func() {
+		for _, w := range writers {
+			if w != nil {
+				path, _ := w.Close()
+				x.spill.remove(path)
+			}
+		}
+	}
+	for i := range writers {
+		w, err := x.spill.newRowWriter()
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		writers[i] = w
+	}
+
+	for {
+		row, err := it.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			closeAll()
+			return nil, err
+		}
+		p := 0
+		if key, ok := keyString(row, keys); ok {
+			h := fnv.New32a()
+			h.Write([]byte(key))
+			p = int(h.Sum32() % partitions)
+		}
+		if err := writers[p].Write(row); err != nil {
+			closeAll()
+			return nil, err
+		}
+	}
+
+	paths := make([]string, len(writers))
+	for i, w := range writers {
+		path, err := w.Close()
+		if err != nil {
+			for _, p := range paths[:i] {
+				x.spill.remove(p)
+			}
+			return nil, err
+		}
+		paths[i] = path
+	}
+	return paths, nil
+}
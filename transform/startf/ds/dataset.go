@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"sync"
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/detect"
 	"github.com/qri-io/dataset/dsio"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/transform/startf/dataframe"
 	"github.com/qri-io/starlib/util"
 	"go.starlark.net/starlark"
 	"go.starlark.net/starlarkstruct"
@@ -82,6 +84,7 @@ func (d *Dataset) Methods() *starlarkstruct.Struct {
 		"get_structure": starlark.NewBuiltin("get_structure", d.GetStructure),
 		"set_structure": starlark.NewBuiltin("set_structure", d.SetStructure),
 		"get_body":      starlark.NewBuiltin("get_body", d.GetBody),
+		"get_frame":     starlark.NewBuiltin("get_frame", d.GetFrame),
 		"set_body":      starlark.NewBuiltin("set_body", d.SetBody),
 	})
 }
@@ -275,6 +278,40 @@ func (d *Dataset) GetBody(thread *starlark.Thread, _ *starlark.Builtin, args sta
 	return starlark.None, fmt.Errorf("value is not iterable")
 }
 
+// GetFrame returns the body of the dataset as a DataFrame. Unlike GetBody,
+// the body isn't loaded into memory. It's copied to disk & read as the
+// frame's plan runs
+func (d *Dataset) GetFrame(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs("get_frame", args, kwargs, 0); err != nil {
+		return starlark.None, err
+	}
+
+	var provider *dataset.Dataset
+	if d.read != nil {
+		provider = d.read
+	}
+	if d.modBody && d.write != nil {
+		provider = d.write
+	}
+
+	if provider == nil || provider.BodyFile() == nil {
+		return starlark.None, nil
+	}
+
+	body := provider.BodyFile()
+	df, path, err := dataframe.FromBody(thread, provider.Structure, body)
+	if err != nil {
+		return starlark.None, err
+	}
+	// the body reader has been consumed, replace it with the copy on disk
+	f, err := os.Open(path)
+	if err != nil {
+		return starlark.None, err
+	}
+	provider.SetBodyFile(qfs.NewMemfileReader(body.FileName(), f))
+	return df, nil
+}
+
 // SetBody assigns the dataset body. Future calls to GetBody will return this newly mutated body,
 // even if assigned value is the same as what was already there.
 func (d *Dataset) SetBody(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -298,6 +335,10 @@ func (d *Dataset) SetBody(thread *starlark.Thread, _ *starlark.Builtin, args sta
 		return starlark.None, err
 	}
 
+	if frame, ok := data.(*dataframe.DataFrame); ok {
+		return starlark.None, d.setBodyFrame(thread, frame)
+	}
+
 	if df := parseAs.GoString(); df != "" {
 		if _, err := dataset.ParseDataFormatString(df); err != nil {
 			return starlark.None, fmt.Errorf("invalid parse_as format: %q", df)
@@ -345,6 +386,33 @@ func (d *Dataset) SetBody(thread *starlark.Thread, _ *starlark.Builtin, args sta
 	return starlark.None, nil
 }
 
+// setBodyFrame runs a DataFrame plan, writing results to disk as the body.
+// Structure keeps the format of the write structure, with a schema from the
+// frame's columns
+func (d *Dataset) setBodyFrame(thread *starlark.Thread, frame *dataframe.DataFrame) error {
+	base := d.writeStructure(frame)
+	st := &dataset.Structure{
+		Format:       base.Format,
+		FormatConfig: base.FormatConfig,
+		Schema:       frame.Schema(),
+	}
+
+	path, err := frame.WriteBody(thread, st)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	d.write.Structure = st
+	d.write.SetBodyFile(qfs.NewMemfileReader(fmt.Sprintf("body.%s", st.Format), f))
+	d.modBody = true
+	d.bodyCache = nil
+	return nil
+}
+
 // writeStructure determines the destination data structure for writing a
 // dataset body, falling back to a default json structure based on input values
 // if no prior structure exists
@@ -6,6 +6,7 @@ import (
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/transform/startf/dataframe"
 	"github.com/qri-io/starlib/testdata"
 	"go.starlark.net/resolve"
 	"go.starlark.net/starlark"
@@ -172,6 +173,35 @@ func TestChangeBodyEvenIfTheSame(t *testing.T) {
 	}
 }
 
+func TestFrameBody(t *testing.T) {
+	resolve.AllowLambda = true
+	thread := &starlark.Thread{}
+	spill := dataframe.NewSpill("", dataframe.DefaultMaxRows)
+	defer spill.Close()
+	dataframe.SetSpill(thread, spill)
+
+	script := `
+frame = ds.get_frame()
+ds.set_body(frame.filter(lambda r: r["count"] > 1).select("title"))
+body = ds.get_body()
+`
+	d := csvDataset()
+	globals, err := starlark.ExecFile(thread, "frame.star", script, starlark.StringDict{"ds": d.Methods()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.IsBodyModified() {
+		t.Errorf("expected body to have been modified")
+	}
+	expect := `[["bar"], ["bat"]]`
+	if got := globals["body"].String(); got != expect {
+		t.Errorf("expected body: %s, got: %s", expect, got)
+	}
+	if d.write.Structure.Format != "csv" {
+		t.Errorf("expected frame body to keep csv format, got: %q", d.write.Structure.Format)
+	}
+}
+
 func TestFile(t *testing.T) {
 	resolve.AllowFloat = true
 	thread := &starlark.Thread{Load: newLoader()}
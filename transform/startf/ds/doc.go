@@ -18,11 +18,15 @@
             set dataset structure component
           get_body() dict|list|None
             get dataset body component if one is defined
-          set_body(data dict|list, parse_as? string) body
+          get_frame() DataFrame|None
+            get dataset body component as a DataFrame from the dataframe module. the body must be tabular.
+            unlike get_body, the body isn't loaded into memory
+          set_body(data dict|list|DataFrame, parse_as? string) body
             set dataset body component. set_body has only one optional argument: 'parse_as', which defaults to the
             empty string. By default qri assumes the data value provided to set_body is an iterable starlark data
             structure (tuple, set, list, dict). When parse_as is set, set_body assumes the provided body value will
             be a string of serialized structured data in the given format. valid parse_as values are "json", "csv",
-            "cbor", "xlsx".
+            "cbor", "xlsx". When data is a DataFrame, the frame's plan is run & results are written to disk
+            instead of memory, with a schema built from the frame's columns.
 */
 package ds
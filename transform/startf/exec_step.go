@@ -12,7 +12,9 @@ import (
 	"github.com/qri-io/qri/dsref"
 	"github.com/qri-io/qri/event"
 	skyctx "github.com/qri-io/qri/transform/startf/context"
+	"github.com/qri-io/qri/transform/startf/dataframe"
 	skyds "github.com/qri-io/qri/transform/startf/ds"
+	"github.com/qri-io/qri/transform/startf/fetch"
 	"go.starlark.net/resolve"
 	"go.starlark.net/starlark"
 )
@@ -32,6 +34,9 @@ type StepRunner struct {
 	bodyFile    qfs.File
 	eventsCh    chan event.Event
 	thread      *starlark.Thread
+	spill       *dataframe.Spill
+	loader      func(thread *starlark.Thread, module string) (starlark.StringDict, error)
+	modules     *moduleImporter
 
 	download starlark.Iterable
 }
@@ -57,8 +62,6 @@ func NewStepRunner(prev *dataset.Dataset, opts ...func(o *ExecOpts)) *StepRunner
 		starlark.Universe[key] = val
 	}
 
-	thread := &starlark.Thread{Load: o.ModuleLoader}
-
 	// starCtx := skyctx.NewContext(o.Config, o.Secrets)
 	starCtx := skyctx.NewContext(nil, o.Secrets)
 
@@ -68,23 +71,63 @@ func NewStepRunner(prev *dataset.Dataset, opts ...func(o *ExecOpts)) *StepRunner
 		eventsCh:    o.EventsCh,
 		prev:        prev,
 		checkFunc:   o.MutateFieldCheck,
-		thread:      thread,
 		globals:     starlark.StringDict{},
+		loader:      o.ModuleLoader,
+		modules:     newModuleImporter(context.Background(), o.ModuleDir, o.DatasetLoader, nil),
+		// dataframes & step outputs spill rows to disk until the runner is
+		// closed
+		spill: dataframe.NewSpill("", dataframe.DefaultMaxRows),
 	}
+	r.thread = &starlark.Thread{Load: r.moduleLoader}
+	dataframe.SetSpill(r.thread, r.spill)
+	fetch.SetClient(r.thread, newFetchClient(o))
 
 	return r
 }
 
+// Close removes any files the runner spilled to disk
+func (r *StepRunner) Close() error {
+	return r.spill.Close()
+}
+
+func (r *StepRunner) moduleLoader(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	if module == dataframe.ModuleName {
+		return dataframe.LoadModule()
+	}
+	if module == fetch.ModuleName {
+		return fetch.LoadModule()
+	}
+	if isImportedModule(module) {
+		return r.modules.Load(thread, module)
+	}
+	if r.loader == nil {
+		return nil, fmt.Errorf("couldn't load module: %s", module)
+	}
+	return r.loader(thread, module)
+}
+
 // RunStep runs the single transform step using the dataset
 func (r *StepRunner) RunStep(ctx context.Context, ds *dataset.Dataset, st *dataset.TransformStep) error {
 	r.globals["print"] = starlark.NewBuiltin("print", r.print)
 	r.globals["load_dataset"] = starlark.NewBuiltin("load_dataset", r.LoadDatasetFunc(ctx, ds))
+	// modules are shared by every step, loaded versions are pinned in the
+	// dataset the step transforms
+	r.modules.ctx = ctx
+	r.modules.target = ds
 
 	script, ok := st.Script.(string)
 	if !ok {
 		return fmt.Errorf("starlark step Script must be a string. got %T", st.Script)
 	}
 
+	if st.Category == "step" {
+		if st.Name == "" {
+			return fmt.Errorf("pipeline steps must be named")
+		}
+		// every pipeline step defines its own step function
+		delete(r.globals, "step")
+	}
+
 	globals, err := starlark.ExecFile(r.thread, fmt.Sprintf("%s.star", st.Name), strings.NewReader(script), r.globals)
 	if err != nil {
 		if evalErr, ok := err.(*starlark.EvalError); ok {
@@ -97,14 +140,15 @@ func (r *StepRunner) RunStep(ctx context.Context, ds *dataset.Dataset, st *datas
 		r.globals[key] = val
 	}
 
-	if err := r.callStepFunc(ctx, r.thread, st.Category, ds); err != nil {
+	if err := r.callStepFunc(ctx, r.thread, st, ds); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (r *StepRunner) callStepFunc(ctx context.Context, thread *starlark.Thread, stepType string, ds *dataset.Dataset) error {
+func (r *StepRunner) callStepFunc(ctx context.Context, thread *starlark.Thread, st *dataset.TransformStep, ds *dataset.Dataset) error {
+	stepType := st.Category
 	if stepType == "setup" {
 		return nil
 	}
@@ -119,6 +163,8 @@ func (r *StepRunner) callStepFunc(ctx context.Context, thread *starlark.Thread,
 		return r.callDownloadFunc(thread, stepFunc)
 	case "transform":
 		return r.callTransformFunc(ctx, thread, stepFunc, ds)
+	case "step":
+		return r.callPipelineFunc(thread, stepFunc, st.Name)
 	default:
 		return fmt.Errorf("unrecognized starlark step type %q", stepType)
 	}
@@ -151,6 +197,29 @@ func (r *StepRunner) callDownloadFunc(thread *starlark.Thread, download *starlar
 	return nil
 }
 
+// callPipelineFunc runs a pipeline step, keeping the returned value as the
+// step's output. DataFrame outputs are computed once here, so later steps
+// read rows instead of re-running the plan that produced them
+func (r *StepRunner) callPipelineFunc(thread *starlark.Thread, step *starlark.Function, name string) error {
+	val, err := starlark.Call(thread, step, starlark.Tuple{r.starCtx.Struct()}, nil)
+	if err != nil {
+		return err
+	}
+
+	out := event.TransformStepOutput{Step: name, Type: val.Type()}
+	if df, ok := val.(*dataframe.DataFrame); ok {
+		if val, out.Rows, out.Storage, err = df.Materialize(thread); err != nil {
+			return err
+		}
+	}
+	r.starCtx.SetOutput(name, val)
+
+	if r.eventsCh != nil {
+		r.eventsCh <- event.Event{Type: event.ETTransformStepOutput, Payload: out}
+	}
+	return nil
+}
+
 func (r *StepRunner) callTransformFunc(ctx context.Context, thread *starlark.Thread, transform *starlark.Function, ds *dataset.Dataset) (err error) {
 	d := skyds.NewDataset(r.prev, r.checkFunc)
 	d.SetMutable(ds)
@@ -198,6 +267,13 @@ func (r *StepRunner) LoadDatasetFunc(ctx context.Context, target *dataset.Datase
 			Path: fmt.Sprintf("%s/%s@%s", ds.Peername, ds.Name, ds.Path),
 		}
 
+		if r.eventsCh != nil {
+			r.eventsCh <- event.Event{
+				Type:    event.ETTransformDatasetLoad,
+				Payload: event.TransformDatasetLoad{Ref: refstr.GoString(), Path: ds.Path},
+			}
+		}
+
 		return skyds.NewDataset(ds, nil).Methods(), nil
 	}
 }
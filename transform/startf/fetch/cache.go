@@ -0,0 +1,128 @@
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheEntry is a stored response
+type cacheEntry struct {
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	// Expires is when the response must be revalidated. The zero time always
+	// revalidates
+	Expires time.Time `json:"expires"`
+}
+
+// fresh reports if an entry can be used without contacting the server
+func (e *cacheEntry) fresh(now time.Time) bool {
+	return !e.Expires.IsZero() && now.Before(e.Expires)
+}
+
+// validators reports if an entry can be revalidated with a conditional request
+func (e *cacheEntry) validators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// Cache stores responses on disk, keyed by request URL & headers. Responses
+// are stored & reused following their cache-control headers
+type Cache struct {
+	dir string
+}
+
+// NewCache creates a cache that stores responses in dir
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// cacheKey hashes a request method, URL & headers
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.String() + "\n"))
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Write([]byte(name + ": " + strings.Join(req.Header[name], ",") + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) get(key string) (*cacheEntry, bool) {
+	data, err := ioutil.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	e := &cacheEntry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		log.Debugw("reading cached response", "key", key, "err", err)
+		return nil, false
+	}
+	return e, true
+}
+
+func (c *Cache) put(key string, e *cacheEntry) error {
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(c.dir, key+".json"), data, 0644)
+}
+
+// cacheDirectives parses a Cache-Control header into a map of directives
+func cacheDirectives(h http.Header) map[string]string {
+	d := map[string]string{}
+	for _, part := range strings.Split(h.Get("Cache-Control"), ",") {
+		part = strings.TrimSpace(strings.ToLower(part))
+		if part == "" {
+			continue
+		}
+		if pos := strings.Index(part, "="); pos != -1 {
+			d[part[:pos]] = strings.Trim(part[pos+1:], `"`)
+		} else {
+			d[part] = ""
+		}
+	}
+	return d
+}
+
+// expires determines when a response becomes stale. ok is false for responses
+// that must not be stored
+func expires(res *http.Response, now time.Time) (exp time.Time, ok bool) {
+	d := cacheDirectives(res.Header)
+	if _, noStore := d["no-store"]; noStore {
+		return exp, false
+	}
+	if _, noCache := d["no-cache"]; noCache {
+		return exp, true
+	}
+	if age, ok := d["max-age"]; ok {
+		secs, err := strconv.Atoi(age)
+		if err != nil || secs <= 0 {
+			return exp, true
+		}
+		return now.Add(time.Duration(secs) * time.Second), true
+	}
+	if h := res.Header.Get("Expires"); h != "" {
+		if t, err := http.ParseTime(h); err == nil && t.After(now) {
+			return t, true
+		}
+	}
+	return exp, true
+}
@@ -0,0 +1,381 @@
+// Package fetch defines a starlark module for making HTTP requests from
+// transform scripts. Requests are retried on failure, rate limited per host
+// & cached on disk, and every request is reported for provenance
+package fetch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+var log = golog.Logger("startf")
+
+// ModuleName defines the expected name for this module when used
+// in starlark's load() function, eg: load('fetch.star', 'fetch')
+const ModuleName = "fetch.star"
+
+// clientThreadKey is the starlark thread-local key for a Client
+const clientThreadKey = "fetch.client"
+
+const (
+	// DefaultMaxRetries is the number of times a failed request is retried
+	DefaultMaxRetries = 3
+	// DefaultHostInterval is the minimum time between requests to a host
+	DefaultHostInterval = 250 * time.Millisecond
+	// DefaultTimeout is the longest a single request attempt can take
+	DefaultTimeout = 30 * time.Second
+)
+
+// retryBackoff is the wait before the first retry, doubling with each retry
+var retryBackoff = time.Second
+
+// Client makes requests for the fetch module. Scripts running on a thread use
+// the client assigned with SetClient
+type Client struct {
+	// Guard errors for requests that aren't allowed
+	Guard func(req *http.Request) error
+	// OnFetch is called with a record of every completed request
+	OnFetch func(rec event.TransformFetch)
+	// MaxRetries is the number of times requests that fail with a network
+	// error, a 429 or a 5XX status are retried
+	MaxRetries int
+	// HostInterval is the minimum time between requests to a host
+	HostInterval time.Duration
+
+	cache *Cache
+	http  *http.Client
+
+	lk   sync.Mutex
+	next map[string]time.Time
+}
+
+// NewClient creates a client. Responses are cached in cacheDir, an empty
+// cacheDir disables caching
+func NewClient(cacheDir string) *Client {
+	c := &Client{
+		MaxRetries:   DefaultMaxRetries,
+		HostInterval: DefaultHostInterval,
+		http:         &http.Client{Timeout: DefaultTimeout},
+		next:         map[string]time.Time{},
+	}
+	if cacheDir != "" {
+		c.cache = NewCache(cacheDir)
+	}
+	return c
+}
+
+// SetClient assigns the client fetch requests on a thread use
+func SetClient(thread *starlark.Thread, c *Client) {
+	thread.SetLocal(clientThreadKey, c)
+}
+
+// clientFor gets the client assigned to a thread, falling back to a client
+// without a cache
+func clientFor(thread *starlark.Thread) *Client {
+	if thread != nil {
+		if c, ok := thread.Local(clientThreadKey).(*Client); ok {
+			return c
+		}
+	}
+	return NewClient("")
+}
+
+// Response is the result of a completed request
+type Response struct {
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Cached is true if the response was read from the cache
+	Cached bool
+}
+
+// Do sends a request, reading from & writing to the cache, waiting for the
+// host rate limit & retrying failures
+func (c *Client) Do(req *http.Request) (*Response, error) {
+	rec := event.TransformFetch{
+		URL:    req.URL.String(),
+		Method: req.Method,
+	}
+	start := time.Now()
+	res, err := c.do(req, &rec)
+	rec.Duration = int(time.Since(start))
+	if err != nil {
+		rec.Error = err.Error()
+	} else {
+		rec.Status = res.StatusCode
+		rec.Cached = res.Cached
+		sum := sha256.Sum256(res.Body)
+		rec.Hash = hex.EncodeToString(sum[:])
+	}
+	if c.OnFetch != nil {
+		c.OnFetch(rec)
+	}
+	return res, err
+}
+
+func (c *Client) do(req *http.Request, rec *event.TransformFetch) (*Response, error) {
+	if c.Guard != nil {
+		if err := c.Guard(req); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		key   string
+		entry *cacheEntry
+	)
+	if c.cache != nil && req.Method == http.MethodGet {
+		key = cacheKey(req)
+		if e, ok := c.cache.get(key); ok {
+			if e.fresh(time.Now()) && !noCacheRequest(req) {
+				return e.response(), nil
+			}
+			if e.validators() {
+				entry = e
+			}
+		}
+	}
+
+	res, err := c.send(req, entry, rec)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	now := time.Now()
+	if entry != nil && res.StatusCode == http.StatusNotModified {
+		if exp, ok := expires(res, now); ok {
+			entry.Expires = exp
+			if err := c.cache.put(key, entry); err != nil {
+				log.Debugw("updating cached response", "url", entry.URL, "err", err)
+			}
+		}
+		return entry.response(), nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	r := &Response{
+		URL:        req.URL.String(),
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       body,
+	}
+
+	if key != "" && res.StatusCode == http.StatusOK {
+		if exp, ok := expires(res, now); ok {
+			e := &cacheEntry{URL: r.URL, Status: r.StatusCode, Header: r.Header, Body: body, Expires: exp}
+			if e.fresh(now) || e.validators() {
+				if err := c.cache.put(key, e); err != nil {
+					log.Debugw("caching response", "url", r.URL, "err", err)
+				}
+			}
+		}
+	}
+	return r, nil
+}
+
+// send performs a request, retrying failures. A cache entry adds conditional
+// request headers
+func (c *Client) send(req *http.Request, entry *cacheEntry, rec *event.TransformFetch) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	for attempt := 0; ; attempt++ {
+		rec.Attempts = attempt + 1
+		r := req.Clone(req.Context())
+		if body != nil {
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		if entry != nil {
+			if etag := entry.Header.Get("ETag"); etag != "" {
+				r.Header.Set("If-None-Match", etag)
+			}
+			if lm := entry.Header.Get("Last-Modified"); lm != "" {
+				r.Header.Set("If-Modified-Since", lm)
+			}
+		}
+
+		c.wait(r.URL.Host)
+		res, err := c.http.Do(r)
+		retry := err != nil || res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+		if !retry || attempt >= c.MaxRetries {
+			return res, err
+		}
+
+		delay := retryBackoff << uint(attempt)
+		if res != nil {
+			if secs, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && secs >= 0 {
+				delay = time.Duration(secs) * time.Second
+			}
+			res.Body.Close()
+		}
+		log.Debugw("retrying fetch", "url", r.URL.String(), "attempt", attempt+1, "err", err, "delay", delay)
+		time.Sleep(delay)
+	}
+}
+
+// wait blocks until a request to host is allowed by the rate limit
+func (c *Client) wait(host string) {
+	if c.HostInterval <= 0 {
+		return
+	}
+	c.lk.Lock()
+	now := time.Now()
+	at := c.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	c.next[host] = at.Add(c.HostInterval)
+	c.lk.Unlock()
+	time.Sleep(at.Sub(now))
+}
+
+func noCacheRequest(req *http.Request) bool {
+	_, ok := cacheDirectives(req.Header)["no-cache"]
+	return ok
+}
+
+func (e *cacheEntry) response() *Response {
+	return &Response{
+		URL:        e.URL,
+		StatusCode: e.Status,
+		Header:     e.Header,
+		Body:       e.Body,
+		Cached:     true,
+	}
+}
+
+// LoadModule loads the fetch module
+func LoadModule() (starlark.StringDict, error) {
+	return starlark.StringDict{
+		"fetch": starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+			"get":  starlark.NewBuiltin("get", reqMethod(http.MethodGet)),
+			"post": starlark.NewBuiltin("post", reqMethod(http.MethodPost)),
+		}),
+	}, nil
+}
+
+// reqMethod creates a builtin for an HTTP method. Builtins accept a url, and
+// optional params, headers, body & json_body arguments
+func reqMethod(method string) func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var (
+			urlv     starlark.String
+			params   = &starlark.Dict{}
+			headers  = &starlark.Dict{}
+			body     starlark.String
+			jsonBody starlark.Value
+		)
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "url", &urlv, "params?", &params, "headers?", &headers, "body?", &body, "json_body?", &jsonBody); err != nil {
+			return nil, err
+		}
+
+		u, err := url.Parse(urlv.GoString())
+		if err != nil {
+			return nil, err
+		}
+		q := u.Query()
+		for _, kv := range params.Items() {
+			k, v, err := stringPair(kv)
+			if err != nil {
+				return nil, fmt.Errorf("%s: params %w", b.Name(), err)
+			}
+			q.Add(k, v)
+		}
+		u.RawQuery = q.Encode()
+
+		data := []byte(body.GoString())
+		if jsonBody != nil && jsonBody != starlark.None {
+			v, err := util.Marshal(jsonBody)
+			if err != nil {
+				return nil, err
+			}
+			if data, err = json.Marshal(v); err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := http.NewRequest(method, u.String(), bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if jsonBody != nil && jsonBody != starlark.None {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for _, kv := range headers.Items() {
+			k, v, err := stringPair(kv)
+			if err != nil {
+				return nil, fmt.Errorf("%s: headers %w", b.Name(), err)
+			}
+			req.Header.Add(k, v)
+		}
+
+		res, err := clientFor(thread).Do(req)
+		if err != nil {
+			return nil, err
+		}
+		return res.Struct(), nil
+	}
+}
+
+func stringPair(kv starlark.Tuple) (string, string, error) {
+	k, ok := starlark.AsString(kv[0])
+	if !ok {
+		return "", "", fmt.Errorf("keys must be strings, got %s", kv[0].Type())
+	}
+	v, ok := starlark.AsString(kv[1])
+	if !ok {
+		return "", "", fmt.Errorf("value for %q must be a string, got %s", k, kv[1].Type())
+	}
+	return k, v, nil
+}
+
+// Struct returns a response as a starlark struct
+func (r *Response) Struct() *starlarkstruct.Struct {
+	headers := &starlark.Dict{}
+	for name, vals := range r.Header {
+		headers.SetKey(starlark.String(name), starlark.String(strings.Join(vals, ",")))
+	}
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"url":         starlark.String(r.URL),
+		"status_code": starlark.MakeInt(r.StatusCode),
+		"headers":     headers,
+		"body":        starlark.String(r.Body),
+		"cached":      starlark.Bool(r.Cached),
+		"json":        starlark.NewBuiltin("json", r.json),
+	})
+}
+
+func (r *Response) json(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var v interface{}
+	if err := json.Unmarshal(r.Body, &v); err != nil {
+		return nil, err
+	}
+	return util.Marshal(v)
+}
@@ -0,0 +1,168 @@
+package fetch
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/event"
+	"go.starlark.net/starlark"
+)
+
+func TestClientCache(t *testing.T) {
+	hits := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		w.Write([]byte(`{"foo":"bar"}`))
+	}))
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "fetch_cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var recs []event.TransformFetch
+	c := NewClient(dir)
+	c.HostInterval = 0
+	c.OnFetch = func(rec event.TransformFetch) { recs = append(recs, rec) }
+
+	cases := []struct {
+		path       string
+		wantHits   int
+		wantCached bool
+	}{
+		{"/fresh", 1, true},
+		{"/etag", 2, true},
+		{"/nostore", 2, false},
+	}
+
+	for _, c2 := range cases {
+		t.Run(c2.path, func(t *testing.T) {
+			hits = 0
+			var res *Response
+			for i := 0; i < 2; i++ {
+				req, _ := http.NewRequest("GET", s.URL+c2.path, nil)
+				if res, err = c.Do(req); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if hits != c2.wantHits {
+				t.Errorf("server hits mismatch. want: %d, got: %d", c2.wantHits, hits)
+			}
+			if res.Cached != c2.wantCached {
+				t.Errorf("cached mismatch. want: %t, got: %t", c2.wantCached, res.Cached)
+			}
+			if string(res.Body) != `{"foo":"bar"}` {
+				t.Errorf("body mismatch. got: %q", res.Body)
+			}
+		})
+	}
+
+	if len(recs) != 6 {
+		t.Fatalf("expected 6 fetch records, got: %d", len(recs))
+	}
+	if recs[0].Hash == "" || recs[0].Status != 200 || recs[0].Attempts != 1 {
+		t.Errorf("unexpected fetch record: %#v", recs[0])
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	prev := retryBackoff
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = prev }()
+
+	hits := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer s.Close()
+
+	var rec event.TransformFetch
+	c := NewClient("")
+	c.HostInterval = 0
+	c.OnFetch = func(r event.TransformFetch) { rec = r }
+
+	req, _ := http.NewRequest("GET", s.URL, nil)
+	res, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got: %d", res.StatusCode)
+	}
+	if rec.Attempts != 3 {
+		t.Errorf("expected 3 attempts, got: %d", rec.Attempts)
+	}
+
+	hits = 0
+	c.MaxRetries = 1
+	req, _ = http.NewRequest("GET", s.URL, nil)
+	if res, err = c.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 after retries run out, got: %d", res.StatusCode)
+	}
+}
+
+func TestClientGuard(t *testing.T) {
+	c := NewClient("")
+	c.Guard = func(req *http.Request) error { return os.ErrPermission }
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	if _, err := c.Do(req); err != os.ErrPermission {
+		t.Errorf("expected guard error, got: %v", err)
+	}
+}
+
+func TestModule(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"q":"` + r.URL.Query().Get("q") + `"}`))
+	}))
+	defer s.Close()
+
+	thread := &starlark.Thread{Load: func(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+		return LoadModule()
+	}}
+	c := NewClient("")
+	c.HostInterval = 0
+	SetClient(thread, c)
+
+	script := `
+load("fetch.star", "fetch")
+res = fetch.get(url, params={"q": "hello"})
+status = res.status_code
+q = res.json()["q"]
+`
+	globals, err := starlark.ExecFile(thread, "test.star", script, starlark.StringDict{"url": starlark.String(s.URL)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if globals["status"].String() != "200" {
+		t.Errorf("expected status 200, got: %s", globals["status"])
+	}
+	if globals["q"].String() != `"hello"` {
+		t.Errorf("expected q to be \"hello\", got: %s", globals["q"])
+	}
+}
@@ -0,0 +1,191 @@
+package startf
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/dsref"
+	"go.starlark.net/starlark"
+)
+
+// QriModulePrefix marks modules loaded from the transform script of another
+// dataset, like load("qri://peer/utils_lib", "clean"). Versions can be given
+// explicitly: "qri://peer/utils_lib@/ipfs/Qm...". Without a version, the
+// version pinned in the transform's resources is used, or the latest version
+// when the transform doesn't pin one. Every loaded version is pinned in
+// Transform.Resources, so re-running a saved transform loads the same code
+const QriModulePrefix = "qri://"
+
+// LocalModulePrefix marks modules loaded from files in the transform's module
+// directory, like load("./helpers.star", "clean"). The prefix keeps local
+// files from shadowing built in modules like "assert.star"
+const LocalModulePrefix = "./"
+
+// isImportedModule reports whether a module is loaded from a file or dataset
+// instead of being built in
+func isImportedModule(module string) bool {
+	return strings.HasPrefix(module, QriModulePrefix) || strings.HasPrefix(module, LocalModulePrefix)
+}
+
+// moduleImporter loads starlark modules from files in the transform's module
+// directory & from the transform scripts of other datasets. Each module is
+// executed once per run
+type moduleImporter struct {
+	ctx         context.Context
+	dir         string
+	loadDataset dsref.ParseResolveLoad
+	// target is the dataset being transformed. Loaded dataset versions are
+	// pinned in its transform resources
+	target  *dataset.Dataset
+	modules map[string]*importedModule
+}
+
+type importedModule struct {
+	globals starlark.StringDict
+	err     error
+}
+
+func newModuleImporter(ctx context.Context, dir string, loadDataset dsref.ParseResolveLoad, target *dataset.Dataset) *moduleImporter {
+	return &moduleImporter{
+		ctx:         ctx,
+		dir:         dir,
+		loadDataset: loadDataset,
+		target:      target,
+		modules:     map[string]*importedModule{},
+	}
+}
+
+// Load executes a module, returning its globals. Loads made by the module are
+// resolved with the loader of the calling thread
+func (m *moduleImporter) Load(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	if e, ok := m.modules[module]; ok {
+		if e == nil {
+			return nil, fmt.Errorf("cycle in load graph loading module %q", module)
+		}
+		return e.globals, e.err
+	}
+
+	if strings.HasPrefix(thread.Name, QriModulePrefix) && strings.HasPrefix(module, LocalModulePrefix) {
+		return nil, fmt.Errorf("can't load module %q: modules loaded from datasets can't load local modules", module)
+	}
+
+	// mark the module as loading to detect cycles
+	m.modules[module] = nil
+	filename, src, err := m.source(module)
+	e := &importedModule{err: err}
+	if err == nil {
+		child := &starlark.Thread{Name: module, Load: thread.Load, Print: thread.Print}
+		e.globals, e.err = starlark.ExecFile(child, filename, src, nil)
+		if evalErr, ok := e.err.(*starlark.EvalError); ok {
+			e.err = fmt.Errorf(evalErr.Backtrace())
+		}
+	}
+	m.modules[module] = e
+	return e.globals, e.err
+}
+
+func (m *moduleImporter) source(module string) (filename string, src []byte, err error) {
+	if strings.HasPrefix(module, QriModulePrefix) {
+		return m.datasetSource(strings.TrimPrefix(module, QriModulePrefix))
+	}
+
+	if m.dir == "" {
+		return "", nil, fmt.Errorf("can't load module %q: local modules are only available to transform scripts read from a directory", module)
+	}
+	rel := filepath.Clean(filepath.FromSlash(module))
+	if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", nil, fmt.Errorf("can't load module %q: local modules must be inside the transform directory", module)
+	}
+	filename = filepath.Join(m.dir, rel)
+	src, err = ioutil.ReadFile(filename)
+	if err != nil {
+		return "", nil, fmt.Errorf("loading module %q: %w", module, err)
+	}
+	return filename, src, nil
+}
+
+// datasetSource reads the transform script of a dataset as a module, pinning
+// the loaded version in the target's transform resources
+func (m *moduleImporter) datasetSource(refstr string) (string, []byte, error) {
+	if m.loadDataset == nil {
+		return "", nil, fmt.Errorf("can't load module %q: loading datasets is not enabled", QriModulePrefix+refstr)
+	}
+	ref, err := dsref.Parse(refstr)
+	if err != nil {
+		return "", nil, err
+	}
+	if ref.Path == "" {
+		if pinned := m.pinnedVersion(ref); pinned != "" {
+			refstr = fmt.Sprintf("%s@%s", ref.Alias(), pinned)
+		}
+	}
+
+	ds, err := m.loadDataset(m.ctx, refstr)
+	if err != nil {
+		return "", nil, fmt.Errorf("loading module %q: %w", QriModulePrefix+refstr, err)
+	}
+	src, err := moduleScript(ds)
+	if err != nil {
+		return "", nil, fmt.Errorf("loading module %q: %w", QriModulePrefix+refstr, err)
+	}
+
+	if m.target != nil && m.target.Transform != nil {
+		if m.target.Transform.Resources == nil {
+			m.target.Transform.Resources = map[string]*dataset.TransformResource{}
+		}
+		m.target.Transform.Resources[ds.Path] = &dataset.TransformResource{
+			Path: fmt.Sprintf("%s/%s@%s", ds.Peername, ds.Name, ds.Path),
+		}
+	}
+	return fmt.Sprintf("%s/%s.star", ds.Peername, ds.Name), src, nil
+}
+
+// pinnedVersion returns the path of a dataset version pinned in the target's
+// transform resources, or the empty string if none is pinned
+func (m *moduleImporter) pinnedVersion(ref dsref.Ref) string {
+	if m.target == nil || m.target.Transform == nil {
+		return ""
+	}
+	for _, r := range m.target.Transform.Resources {
+		if r == nil {
+			continue
+		}
+		pinned, err := dsref.Parse(r.Path)
+		if err != nil || pinned.Name != ref.Name {
+			continue
+		}
+		if pinned.Username == ref.Username || ref.Username == "me" {
+			return pinned.Path
+		}
+	}
+	return ""
+}
+
+// moduleScript returns the transform script of a dataset. Transforms with
+// steps are joined into a single script
+func moduleScript(ds *dataset.Dataset) ([]byte, error) {
+	if ds.Transform == nil {
+		return nil, fmt.Errorf("dataset %s/%s has no transform", ds.Peername, ds.Name)
+	}
+	if f := ds.Transform.ScriptFile(); f != nil {
+		defer f.Close()
+		return ioutil.ReadAll(f)
+	}
+	if len(ds.Transform.ScriptBytes) > 0 {
+		return ds.Transform.ScriptBytes, nil
+	}
+	scripts := make([]string, 0, len(ds.Transform.Steps))
+	for _, st := range ds.Transform.Steps {
+		if s, ok := st.Script.(string); ok && st.Syntax == "starlark" {
+			scripts = append(scripts, s)
+		}
+	}
+	if len(scripts) == 0 {
+		return nil, fmt.Errorf("dataset %s/%s has no transform script", ds.Peername, ds.Name)
+	}
+	return []byte(strings.Join(scripts, "\n")), nil
+}
@@ -0,0 +1,99 @@
+package startf
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/dsref"
+)
+
+const moduleTestScript = `
+load("%s", "clean")
+
+def transform(ds, ctx):
+	if clean(" a ") != "a":
+		error("unexpected clean result")
+	ds.set_body([[clean(" b ")]])
+`
+
+func moduleTestDataset(module string) *dataset.Dataset {
+	ds := &dataset.Dataset{Transform: &dataset.Transform{}}
+	script := strings.Replace(moduleTestScript, "%s", module, 1)
+	ds.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.star", []byte(script)))
+	return ds
+}
+
+func TestLoadLocalModule(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "local_module")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	helpers := "def clean(s):\n\treturn s.strip()\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "helpers.star"), []byte(helpers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ExecScript(ctx, moduleTestDataset("./helpers.star"), nil, SetModuleDir(dir)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ExecScript(ctx, moduleTestDataset("./helpers.star"), nil); err == nil {
+		t.Error("expected loading a local module without a module directory to fail")
+	}
+	if err := ExecScript(ctx, moduleTestDataset("./../helpers.star"), nil, SetModuleDir(dir)); err == nil {
+		t.Error("expected loading a module outside the module directory to fail")
+	}
+}
+
+func TestLoadDatasetModule(t *testing.T) {
+	ctx := context.Background()
+	loaded := ""
+	loader := func(ctx context.Context, refstr string) (*dataset.Dataset, error) {
+		loaded = refstr
+		ds := &dataset.Dataset{
+			Peername:  "peer",
+			Name:      "utils_lib",
+			Path:      "/mem/QmUtils",
+			Transform: &dataset.Transform{},
+		}
+		ds.Transform.SetScriptFile(qfs.NewMemfileBytes("transform.star", []byte("def clean(s):\n\treturn s.strip()\n")))
+		return ds, nil
+	}
+
+	ds := moduleTestDataset("qri://peer/utils_lib")
+	if err := ExecScript(ctx, ds, nil, func(o *ExecOpts) {
+		o.DatasetLoader = dsref.ParseResolveLoad(loader)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if loaded != "peer/utils_lib" {
+		t.Errorf("expected latest version to load, loaded %q", loaded)
+	}
+	res := ds.Transform.Resources["/mem/QmUtils"]
+	if res == nil || res.Path != "peer/utils_lib@/mem/QmUtils" {
+		t.Errorf("expected loaded version to be pinned in transform resources, got: %v", ds.Transform.Resources)
+	}
+
+	// re-running a transform loads the pinned version
+	ds = moduleTestDataset("qri://peer/utils_lib")
+	ds.Transform.Resources = map[string]*dataset.TransformResource{
+		"/mem/QmPinned": {Path: "peer/utils_lib@/mem/QmPinned"},
+	}
+	if err := ExecScript(ctx, ds, nil, func(o *ExecOpts) {
+		o.DatasetLoader = dsref.ParseResolveLoad(loader)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if loaded != "peer/utils_lib@/mem/QmPinned" {
+		t.Errorf("expected pinned version to load, loaded %q", loaded)
+	}
+}
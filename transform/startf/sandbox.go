@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/qri-io/qri/event"
+	"github.com/qri-io/qri/transform/startf/fetch"
 	starhttp "github.com/qri-io/starlib/http"
 )
 
@@ -36,6 +38,19 @@ func (h *HTTPGuard) DisableNtwk() {
 	h.NetworkEnabled = false
 }
 
+// newFetchClient creates a fetch module client that's subject to httpGuard,
+// sending a record of each request on the events channel
+func newFetchClient(o *ExecOpts) *fetch.Client {
+	c := fetch.NewClient(o.HTTPCacheDir)
+	c.Guard = httpGuard.Allowed
+	if o.EventsCh != nil {
+		c.OnFetch = func(rec event.TransformFetch) {
+			o.EventsCh <- event.Event{Type: event.ETTransformFetch, Payload: rec}
+		}
+	}
+	return c
+}
+
 func init() {
 	// connect httpGuard instance to starlib http guard
 	starhttp.Guard = httpGuard
@@ -15,7 +15,9 @@ import (
 	"github.com/qri-io/qri/event"
 	"github.com/qri-io/qri/repo"
 	skyctx "github.com/qri-io/qri/transform/startf/context"
+	"github.com/qri-io/qri/transform/startf/dataframe"
 	skyds "github.com/qri-io/qri/transform/startf/ds"
+	"github.com/qri-io/qri/transform/startf/fetch"
 	skyqri "github.com/qri-io/qri/transform/startf/qri"
 	"github.com/qri-io/qri/version"
 	"github.com/qri-io/starlib"
@@ -52,6 +54,11 @@ type ExecOpts struct {
 	ModuleLoader ModuleLoader
 	// channel to send events on
 	EventsCh chan event.Event
+	// directory to cache fetch module responses in. empty disables caching
+	HTTPCacheDir string
+	// directory local modules like "./helpers.star" are loaded from. empty
+	// disables loading local modules
+	ModuleDir string
 }
 
 // AddDatasetLoader is required to enable the load_dataset starlark builtin
@@ -76,6 +83,21 @@ func AddEventsChannel(eventsCh chan event.Event) func(o *ExecOpts) {
 	}
 }
 
+// SetHTTPCacheDir sets the directory the fetch module caches responses in
+func SetHTTPCacheDir(dir string) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.HTTPCacheDir = dir
+	}
+}
+
+// SetModuleDir sets the directory local modules are loaded from, usually the
+// directory that holds the transform script
+func SetModuleDir(dir string) func(o *ExecOpts) {
+	return func(o *ExecOpts) {
+		o.ModuleDir = dir
+	}
+}
+
 // AddMutateFieldCheck provides a checkFunc to ExecScript
 func AddMutateFieldCheck(check func(path ...string) error) func(o *ExecOpts) {
 	return func(o *ExecOpts) {
@@ -131,6 +153,7 @@ type transform struct {
 	bodyFile     qfs.File
 	stderr       io.Writer
 	moduleLoader ModuleLoader
+	modules      *moduleImporter
 
 	download starlark.Iterable
 }
@@ -193,10 +216,17 @@ func ExecScript(ctx context.Context, next, prev *dataset.Dataset, opts ...func(o
 		checkFunc:    o.MutateFieldCheck,
 		stderr:       o.ErrWriter,
 		moduleLoader: o.ModuleLoader,
+		modules:      newModuleImporter(ctx, o.ModuleDir, o.DatasetLoader, next),
 	}
 
 	skyCtx := skyctx.NewContext(next.Transform.Config, o.Secrets)
 	thread := &starlark.Thread{Load: t.ModuleLoader}
+	// dataframes spill rows to disk for the duration of the script. bodies
+	// written from dataframes are already open, and stay readable once removed
+	spill := dataframe.NewSpill("", dataframe.DefaultMaxRows)
+	defer spill.Close()
+	dataframe.SetSpill(thread, spill)
+	fetch.SetClient(thread, newFetchClient(o))
 
 	// execute the transformation
 	t.globals, err = starlark.ExecFile(thread, pipeScript.FileName(), pipeScript, t.locals())
@@ -326,6 +356,15 @@ func (t *transform) ModuleLoader(thread *starlark.Thread, module string) (dict s
 	if module == skyqri.ModuleName && t.skyqri != nil {
 		return t.skyqri.Namespace(), nil
 	}
+	if module == dataframe.ModuleName {
+		return dataframe.LoadModule()
+	}
+	if module == fetch.ModuleName {
+		return fetch.LoadModule()
+	}
+	if isImportedModule(module) {
+		return t.modules.Load(thread, module)
+	}
 
 	if t.moduleLoader == nil {
 		return nil, fmt.Errorf("couldn't load module: %s", module)
@@ -362,6 +401,13 @@ func (t *transform) LoadDataset(thread *starlark.Thread, _ *starlark.Builtin, ar
 		Path: fmt.Sprintf("%s/%s@%s", ds.Peername, ds.Name, ds.Path),
 	}
 
+	if t.eventsCh != nil {
+		t.eventsCh <- event.Event{
+			Type:    event.ETTransformDatasetLoad,
+			Payload: event.TransformDatasetLoad{Ref: refstr.GoString(), Path: ds.Path},
+		}
+	}
+
 	return skyds.NewDataset(ds, nil).Methods(), nil
 }
 
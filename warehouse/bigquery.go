@@ -0,0 +1,184 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/qri-io/qri/config"
+)
+
+// BigQueryAPI is the default base URL of the BigQuery REST API
+const BigQueryAPI = "https://bigquery.googleapis.com/bigquery/v2"
+
+// bigQueryBatchRows caps the number of rows sent in one insertAll request
+var bigQueryBatchRows = 500
+
+// errNotFound is returned by bigquery requests that get a 404 response
+var errNotFound = fmt.Errorf("not found")
+
+// bigQuery writes to BigQuery tables through the REST API, authenticating with
+// an OAuth access token
+type bigQuery struct {
+	cfg    *config.Warehouse
+	client *http.Client
+}
+
+func newBigQuery(cfg *config.Warehouse) *bigQuery {
+	return &bigQuery{cfg: cfg, client: http.DefaultClient}
+}
+
+// Replace deletes the table & creates it again with the current schema before
+// inserting rows
+func (b *bigQuery) Replace(ctx context.Context, table string, cols []Column, rows [][]interface{}) error {
+	if err := b.do(ctx, http.MethodDelete, b.tablesURL()+"/"+url.PathEscape(table), nil, nil); err != nil && err != errNotFound {
+		return err
+	}
+	if err := b.createTable(ctx, table, cols); err != nil {
+		return err
+	}
+	return b.insert(ctx, table, cols, rows)
+}
+
+// Append inserts rows, creating the table if it doesn't exist
+func (b *bigQuery) Append(ctx context.Context, table string, cols []Column, rows [][]interface{}) error {
+	err := b.do(ctx, http.MethodGet, b.tablesURL()+"/"+url.PathEscape(table), nil, nil)
+	if err == errNotFound {
+		err = b.createTable(ctx, table, cols)
+	}
+	if err != nil {
+		return err
+	}
+	return b.insert(ctx, table, cols, rows)
+}
+
+func (b *bigQuery) tablesURL() string {
+	base := b.cfg.Address
+	if base == "" {
+		base = BigQueryAPI
+	}
+	return fmt.Sprintf("%s/projects/%s/datasets/%s/tables", strings.TrimSuffix(base, "/"), url.PathEscape(b.cfg.Project), url.PathEscape(b.cfg.DatasetID))
+}
+
+func (b *bigQuery) createTable(ctx context.Context, table string, cols []Column) error {
+	fields := make([]map[string]string, len(cols))
+	for i, c := range cols {
+		fields[i] = map[string]string{"name": c.Name, "type": bqType(c.Type), "mode": "NULLABLE"}
+	}
+	body := map[string]interface{}{
+		"tableReference": map[string]string{
+			"projectId": b.cfg.Project,
+			"datasetId": b.cfg.DatasetID,
+			"tableId":   table,
+		},
+		"schema": map[string]interface{}{"fields": fields},
+	}
+	return b.do(ctx, http.MethodPost, b.tablesURL(), body, nil)
+}
+
+func (b *bigQuery) insert(ctx context.Context, table string, cols []Column, rows [][]interface{}) error {
+	u := b.tablesURL() + "/" + url.PathEscape(table) + "/insertAll"
+	for start := 0; start < len(rows); start += bigQueryBatchRows {
+		end := start + bigQueryBatchRows
+		if end > len(rows) {
+			end = len(rows)
+		}
+		records := make([]map[string]interface{}, 0, end-start)
+		for _, row := range rows[start:end] {
+			rec := map[string]interface{}{}
+			for i, c := range cols {
+				rec[c.Name] = row[i]
+			}
+			records = append(records, map[string]interface{}{"json": rec})
+		}
+
+		res := struct {
+			InsertErrors []struct {
+				Index  int `json:"index"`
+				Errors []struct {
+					Message string `json:"message"`
+				} `json:"errors"`
+			} `json:"insertErrors"`
+		}{}
+		if err := b.do(ctx, http.MethodPost, u, map[string]interface{}{"rows": records}, &res); err != nil {
+			return err
+		}
+		if len(res.InsertErrors) > 0 {
+			ie := res.InsertErrors[0]
+			msg := "unknown error"
+			if len(ie.Errors) > 0 {
+				msg = ie.Errors[0].Message
+			}
+			return fmt.Errorf("inserting row %d: %s", start+ie.Index, msg)
+		}
+	}
+	return nil
+}
+
+func (b *bigQuery) do(ctx context.Context, method, u string, body, res interface{}) error {
+	var r *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	} else {
+		r = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, u, r)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if b.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.cfg.Token)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}{}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("bigquery: %s", apiErr.Error.Message)
+		}
+		return fmt.Errorf("bigquery: %s", resp.Status)
+	}
+	if res != nil && len(data) > 0 {
+		return json.Unmarshal(data, res)
+	}
+	return nil
+}
+
+func bqType(colType string) string {
+	switch colType {
+	case ColumnInteger:
+		return "INT64"
+	case ColumnNumber:
+		return "FLOAT64"
+	case ColumnBoolean:
+		return "BOOL"
+	default:
+		return "STRING"
+	}
+}
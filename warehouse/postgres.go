@@ -0,0 +1,167 @@
+package warehouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	// register the "postgres" database/sql driver
+	_ "github.com/lib/pq"
+	"github.com/qri-io/qri/config"
+)
+
+// maxPostgresParams is the limit on bind parameters in one postgres statement
+const maxPostgresParams = 65535
+
+// postgresBatchRows caps the number of rows written by a single INSERT
+var postgresBatchRows = 1000
+
+// postgres writes to a postgres database through database/sql, using the
+// lib/pq driver linked into qri unless the warehouse config names another
+// registered driver. The connection is opened on first use, so an unreachable
+// database is recorded as a sync failure
+type postgres struct {
+	cfg *config.Warehouse
+
+	lk sync.Mutex
+	db *sql.DB
+}
+
+func newPostgres(cfg *config.Warehouse) *postgres {
+	return &postgres{cfg: cfg}
+}
+
+func (p *postgres) open() (*sql.DB, error) {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+	if p.db != nil {
+		return p.db, nil
+	}
+	driver := p.cfg.Driver
+	if driver == "" {
+		driver = "postgres"
+	}
+	db, err := sql.Open(driver, p.cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+	p.db = db
+	return db, nil
+}
+
+// Replace drops & recreates the table inside a transaction, so readers never
+// see a partially written table
+func (p *postgres) Replace(ctx context.Context, table string, cols []Column, rows [][]interface{}) error {
+	return p.write(ctx, []string{
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", pgIdent(table)),
+		pgCreateTable(table, cols, false),
+	}, table, cols, rows)
+}
+
+// Append inserts rows, creating the table if it doesn't exist
+func (p *postgres) Append(ctx context.Context, table string, cols []Column, rows [][]interface{}) error {
+	return p.write(ctx, []string{pgCreateTable(table, cols, true)}, table, cols, rows)
+}
+
+func (p *postgres) write(ctx context.Context, stmts []string, table string, cols []Column, rows [][]interface{}) error {
+	db, err := p.open()
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	batch := postgresBatchRows
+	if len(cols) > 0 && batch*len(cols) > maxPostgresParams {
+		batch = maxPostgresParams / len(cols)
+	}
+	for start := 0; start < len(rows); start += batch {
+		end := start + batch
+		if end > len(rows) {
+			end = len(rows)
+		}
+		query, args := pgInsert(table, cols, rows[start:end])
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func pgIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func pgType(colType string) string {
+	switch colType {
+	case ColumnInteger:
+		return "BIGINT"
+	case ColumnNumber:
+		return "DOUBLE PRECISION"
+	case ColumnBoolean:
+		return "BOOLEAN"
+	case ColumnString:
+		return "TEXT"
+	default:
+		return "JSONB"
+	}
+}
+
+func pgCreateTable(table string, cols []Column, ifNotExists bool) string {
+	b := &strings.Builder{}
+	b.WriteString("CREATE TABLE ")
+	if ifNotExists {
+		b.WriteString("IF NOT EXISTS ")
+	}
+	b.WriteString(pgIdent(table))
+	b.WriteString(" (")
+	for i, c := range cols {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(b, "%s %s", pgIdent(c.Name), pgType(c.Type))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// pgInsert builds a multi-row INSERT statement with positional parameters
+func pgInsert(table string, cols []Column, rows [][]interface{}) (string, []interface{}) {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "INSERT INTO %s (", pgIdent(table))
+	for i, c := range cols {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(pgIdent(c.Name))
+	}
+	b.WriteString(") VALUES ")
+
+	args := make([]interface{}, 0, len(rows)*len(cols))
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for j := range cols {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			args = append(args, row[j])
+			fmt.Fprintf(b, "$%d", len(args))
+		}
+		b.WriteString(")")
+	}
+	return b.String(), args
+}
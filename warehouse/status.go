@@ -0,0 +1,124 @@
+package warehouse
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/repo/jsonfile"
+)
+
+const (
+	// StatePending marks a version waiting to be synced
+	StatePending = "pending"
+	// StateSynced marks a version written to the warehouse
+	StateSynced = "synced"
+	// StateFailed marks a version that couldn't be synced
+	StateFailed = "failed"
+)
+
+// Status records the outcome of syncing one dataset version to a warehouse
+type Status struct {
+	Warehouse string `json:"warehouse"`
+	Dataset   string `json:"dataset"`
+	Path      string `json:"path"`
+	Table     string `json:"table,omitempty"`
+	State     string `json:"state"`
+	// Mode is "replace" or "append"
+	Mode string `json:"mode,omitempty"`
+	// Rows is the number of rows in the version body
+	Rows int `json:"rows"`
+	// Written is the number of rows written to the warehouse
+	Written      int       `json:"written"`
+	Digest       string    `json:"digest,omitempty"`
+	SchemaDigest string    `json:"schemaDigest,omitempty"`
+	Attempts     int       `json:"attempts,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	Time         time.Time `json:"time"`
+}
+
+func (s Status) key() string {
+	return s.Warehouse + " " + s.Path
+}
+
+// StatusStore keeps sync statuses, persisted as JSON to filename. An empty
+// filename keeps statuses in memory
+type StatusStore struct {
+	file jsonfile.File
+
+	lk       sync.Mutex
+	statuses map[string]Status
+}
+
+// NewStatusStore creates a status store, loading any statuses persisted to
+// filename
+func NewStatusStore(filename string) (*StatusStore, error) {
+	s := &StatusStore{
+		file:     jsonfile.New(filename),
+		statuses: map[string]Status{},
+	}
+	statuses := []Status{}
+	if err := s.file.Load(&statuses); err != nil {
+		return nil, fmt.Errorf("reading warehouse sync statuses: %w", err)
+	}
+	for _, st := range statuses {
+		s.statuses[st.key()] = st
+	}
+	return s, nil
+}
+
+// Put records a status, replacing any status for the same warehouse & version
+func (s *StatusStore) Put(st Status) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.statuses[st.key()] = st
+	if err := s.save(); err != nil {
+		log.Debugw("saving warehouse sync statuses", "err", err)
+	}
+}
+
+// List returns statuses for a dataset alias, newest first. An empty alias
+// lists all statuses
+func (s *StatusStore) List(alias string) []Status {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	res := []Status{}
+	for _, st := range s.statuses {
+		if alias == "" || st.Dataset == alias {
+			res = append(res, st)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Time.After(res[j].Time)
+	})
+	return res
+}
+
+// LastSynced returns the most recent successful sync of a dataset to a
+// warehouse, ignoring the version at path. Returns nil if no version has been
+// synced
+func (s *StatusStore) LastSynced(warehouse, alias, path string) *Status {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	var last *Status
+	for _, st := range s.statuses {
+		if st.Warehouse != warehouse || st.Dataset != alias || st.Path == path || st.State != StateSynced {
+			continue
+		}
+		if last == nil || st.Time.After(last.Time) {
+			st := st
+			last = &st
+		}
+	}
+	return last
+}
+
+// save writes statuses. must be called with the lock held
+func (s *StatusStore) save() error {
+	statuses := make([]Status, 0, len(s.statuses))
+	for _, st := range s.statuses {
+		statuses = append(statuses, st)
+	}
+	return s.file.Save(statuses)
+}
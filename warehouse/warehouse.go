@@ -0,0 +1,447 @@
+// Package warehouse mirrors dataset bodies into SQL warehouse tables. Each
+// new version of a dataset enabled for a warehouse is synced in the
+// background, either replacing the table or appending rows added since the
+// last synced version. The outcome of every sync is recorded per version
+package warehouse
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/dataset/tabular"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+	"github.com/qri-io/qri/event"
+)
+
+var (
+	log = golog.Logger("warehouse")
+
+	// QueueSize is the number of pending syncs a Service buffers. Syncs are
+	// dropped when the buffer is full
+	QueueSize = 64
+	// Attempts is the number of times a sync is attempted
+	Attempts = 3
+	// RetryDelay is the delay before the first retry, doubling with each
+	// attempt
+	RetryDelay = time.Second * 5
+)
+
+const (
+	// ColumnInteger holds whole numbers
+	ColumnInteger = "integer"
+	// ColumnNumber holds floating point numbers
+	ColumnNumber = "number"
+	// ColumnBoolean holds true/false values
+	ColumnBoolean = "boolean"
+	// ColumnString holds text
+	ColumnString = "string"
+	// ColumnJSON holds objects, arrays, and values of mixed type, serialized as
+	// JSON text
+	ColumnJSON = "json"
+)
+
+// Column is a warehouse table column
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Driver writes rows to a warehouse
+type Driver interface {
+	// Replace creates or recreates a table, writing all rows
+	Replace(ctx context.Context, table string, cols []Column, rows [][]interface{}) error
+	// Append writes rows to a table, creating the table if it doesn't exist
+	Append(ctx context.Context, table string, cols []Column, rows [][]interface{}) error
+}
+
+// NewDriver creates a driver for a warehouse configuration
+func NewDriver(cfg *config.Warehouse) (Driver, error) {
+	switch cfg.Type {
+	case config.WarehouseTypePostgres:
+		return newPostgres(cfg), nil
+	case config.WarehouseTypeBigQuery:
+		return newBigQuery(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown warehouse type %q", cfg.Type)
+	}
+}
+
+// Columns maps the schema of a tabular dataset to warehouse columns
+func Columns(st *dataset.Structure) ([]Column, error) {
+	if st == nil {
+		return nil, fmt.Errorf("dataset has no structure")
+	}
+	tcols, _, err := tabular.ColumnsFromJSONSchema(st.Schema)
+	if err != nil {
+		return nil, err
+	}
+	untyped := untypedColumns(st.Schema)
+	cols := make([]Column, len(tcols))
+	seen := map[string]bool{}
+	for i, tc := range tcols {
+		name := Identifier(tc.Title)
+		if name == "" || seen[name] {
+			name = fmt.Sprintf("col_%d", i+1)
+		}
+		seen[name] = true
+		typ := columnType(tc.Type)
+		if untyped[i] {
+			// tabular defaults columns without a type to string, but they can hold
+			// any value
+			typ = ColumnJSON
+		}
+		cols[i] = Column{Name: name, Type: typ}
+	}
+	return cols, nil
+}
+
+// untypedColumns returns the indexes of columns that don't declare a type in
+// a tabular schema
+func untypedColumns(sch map[string]interface{}) map[int]bool {
+	untyped := map[int]bool{}
+	items, _ := sch["items"].(map[string]interface{})
+	colSchemas, _ := items["items"].([]interface{})
+	for i, cs := range colSchemas {
+		if colSch, ok := cs.(map[string]interface{}); !ok || colSch["type"] == nil {
+			untyped[i] = true
+		}
+	}
+	return untyped
+}
+
+func columnType(t *tabular.ColType) string {
+	if t == nil {
+		return ColumnJSON
+	}
+	// a column that only adds null to a single type keeps that type, columns
+	// with several non-null types are stored as JSON
+	types := []string{}
+	for _, ct := range *t {
+		if ct != "null" {
+			types = append(types, ct)
+		}
+	}
+	if len(types) == 2 && t.HasType("integer") && t.HasType("number") {
+		return ColumnNumber
+	}
+	if len(types) != 1 {
+		return ColumnJSON
+	}
+	switch types[0] {
+	case "integer":
+		return ColumnInteger
+	case "number":
+		return ColumnNumber
+	case "boolean":
+		return ColumnBoolean
+	case "string":
+		return ColumnString
+	default:
+		return ColumnJSON
+	}
+}
+
+var nonIdentChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// Identifier converts a name to a lowercase SQL identifier
+func Identifier(name string) string {
+	id := strings.Trim(nonIdentChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if id != "" && id[0] >= '0' && id[0] <= '9' {
+		id = "_" + id
+	}
+	return id
+}
+
+// ReadRows reads the body of an open dataset into rows ordered by cols.
+// Object entries are mapped to columns by name
+func ReadRows(ds *dataset.Dataset, cols []Column) ([][]interface{}, error) {
+	if ds.BodyFile() == nil {
+		return nil, fmt.Errorf("dataset has no body")
+	}
+	r, err := dsio.NewEntryReader(ds.Structure, ds.BodyFile())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	rows := [][]interface{}{}
+	for {
+		ent, err := r.ReadEntry()
+		if err != nil {
+			if err == io.EOF || err.Error() == "EOF" {
+				break
+			}
+			return nil, err
+		}
+		row := make([]interface{}, len(cols))
+		switch v := ent.Value.(type) {
+		case []interface{}:
+			copy(row, v)
+		case map[string]interface{}:
+			for k, val := range v {
+				name := Identifier(k)
+				for i, c := range cols {
+					if c.Name == name {
+						row[i] = val
+					}
+				}
+			}
+		default:
+			return nil, fmt.Errorf("body entry %d isn't a row", len(rows))
+		}
+		for i, c := range cols {
+			row[i] = columnValue(c, row[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// columnValue converts a body value to the representation drivers write
+func columnValue(c Column, v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	switch v.(type) {
+	case []interface{}, map[string]interface{}:
+		// drivers only write scalars, nested values are stored as JSON text
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		return string(data)
+	}
+	switch c.Type {
+	case ColumnInteger:
+		switch n := v.(type) {
+		case float64:
+			return int64(n)
+		case int:
+			return int64(n)
+		}
+	case ColumnJSON:
+		if s, ok := v.(string); ok {
+			return s
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		return string(data)
+	}
+	return v
+}
+
+// digest hashes rows, so later syncs can check if a previously synced body is
+// unchanged
+func digest(rows [][]interface{}) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, row := range rows {
+		enc.Encode(row)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func schemaDigest(cols []Column) string {
+	data, _ := json.Marshal(cols)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type target struct {
+	cfg *config.Warehouse
+	drv Driver
+}
+
+type job struct {
+	target *target
+	ref    dsref.Ref
+}
+
+// Service syncs dataset versions to configured warehouses as they're saved
+type Service struct {
+	loader  dsref.Loader
+	targets []*target
+	queue   chan job
+	store   *StatusStore
+}
+
+// New creates a warehouse service from integrations configuration. Statuses
+// are persisted to filename, an empty filename keeps them in memory. Disabled
+// warehouses are skipped
+func New(cfg *config.Integrations, loader dsref.Loader, filename string) (*Service, error) {
+	targets := []*target{}
+	if cfg != nil {
+		for _, wc := range cfg.Warehouses {
+			if wc.Disabled {
+				continue
+			}
+			drv, err := NewDriver(wc)
+			if err != nil {
+				return nil, fmt.Errorf("warehouse %q: %w", wc.Name, err)
+			}
+			targets = append(targets, &target{cfg: wc, drv: drv})
+		}
+	}
+	store, err := NewStatusStore(filename)
+	if err != nil {
+		return nil, err
+	}
+	return newService(loader, targets, store), nil
+}
+
+func newService(loader dsref.Loader, targets []*target, store *StatusStore) *Service {
+	return &Service{
+		loader:  loader,
+		targets: targets,
+		queue:   make(chan job, QueueSize),
+		store:   store,
+	}
+}
+
+// Subscribe listens for saved versions on a bus
+func (s *Service) Subscribe(bus event.Bus) {
+	bus.SubscribeTypes(s.handle, event.ETDatasetSaveCompleted)
+}
+
+// Statuses lists sync statuses for a dataset, newest first. An empty alias
+// lists statuses for all datasets
+func (s *Service) Statuses(alias string) []Status {
+	return s.store.List(alias)
+}
+
+// Run syncs queued versions until the context is cancelled
+func (s *Service) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-s.queue:
+			if _, err := s.sync(ctx, j.target, j.ref); err != nil {
+				log.Errorf("warehouse %q: syncing %s: %s", j.target.cfg.Name, j.ref.Alias(), err)
+			}
+		}
+	}
+}
+
+func (s *Service) handle(ctx context.Context, e event.Event) error {
+	p, ok := e.Payload.(event.DsSaveEvent)
+	if !ok || p.Error != nil || p.Path == "" {
+		return nil
+	}
+	ref := dsref.Ref{Username: p.Username, Name: p.Name, Path: p.Path}
+	for _, t := range s.targets {
+		if !t.cfg.EnabledFor(ref.Alias()) {
+			continue
+		}
+		s.store.Put(Status{
+			Warehouse: t.cfg.Name,
+			Dataset:   ref.Alias(),
+			Path:      ref.Path,
+			State:     StatePending,
+			Time:      time.Now(),
+		})
+		select {
+		case s.queue <- job{target: t, ref: ref}:
+		default:
+			log.Errorf("warehouse %q: queue full, dropping sync of %s", t.cfg.Name, ref.Alias())
+		}
+	}
+	return nil
+}
+
+// sync writes a version to a warehouse, recording the outcome
+func (s *Service) sync(ctx context.Context, t *target, ref dsref.Ref) (st Status, err error) {
+	st = Status{
+		Warehouse: t.cfg.Name,
+		Dataset:   ref.Alias(),
+		Path:      ref.Path,
+		Table:     t.cfg.Table,
+		State:     StatePending,
+	}
+	if st.Table == "" {
+		st.Table = Identifier(ref.Name)
+	}
+	defer func() {
+		st.Time = time.Now()
+		if err != nil {
+			st.State = StateFailed
+			st.Error = err.Error()
+		}
+		s.store.Put(st)
+	}()
+
+	ds, err := s.loader.LoadDataset(ctx, ref, "")
+	if err != nil {
+		return st, err
+	}
+	cols, err := Columns(ds.Structure)
+	if err != nil {
+		return st, err
+	}
+	rows, err := ReadRows(ds, cols)
+	if err != nil {
+		return st, err
+	}
+	st.Rows = len(rows)
+	st.Digest = digest(rows)
+	st.SchemaDigest = schemaDigest(cols)
+
+	write := rows
+	st.Mode = config.WarehouseModeReplace
+	if prev := s.store.LastSynced(t.cfg.Name, ref.Alias(), ref.Path); prev != nil && canAppend(t.cfg.Mode, prev, st.SchemaDigest, rows) {
+		st.Mode = config.WarehouseModeAppend
+		write = rows[prev.Rows:]
+	}
+	st.Written = len(write)
+
+	delay := RetryDelay
+	for st.Attempts = 1; ; st.Attempts++ {
+		if st.Mode == config.WarehouseModeAppend {
+			err = t.drv.Append(ctx, st.Table, cols, write)
+		} else {
+			err = t.drv.Replace(ctx, st.Table, cols, write)
+		}
+		if err == nil || st.Attempts >= Attempts {
+			break
+		}
+		log.Debugw("warehouse sync failed, retrying", "warehouse", t.cfg.Name, "attempt", st.Attempts, "err", err)
+		select {
+		case <-ctx.Done():
+			return st, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	if err != nil {
+		return st, err
+	}
+	st.State = StateSynced
+	return st, nil
+}
+
+// canAppend decides if a version can be synced by appending to the table
+// written by a previous sync. In auto mode rows are only appended when the
+// schema is unchanged and the previously synced rows are an unchanged prefix
+// of the new body, otherwise the table is replaced
+func canAppend(mode string, prev *Status, schema string, rows [][]interface{}) bool {
+	if mode == config.WarehouseModeReplace || prev.SchemaDigest != schema || prev.Rows > len(rows) {
+		return false
+	}
+	if mode == config.WarehouseModeAppend {
+		return true
+	}
+	return digest(rows[:prev.Rows]) == prev.Digest
+}
@@ -0,0 +1,242 @@
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qfs"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/dsref"
+)
+
+var testSchema = map[string]interface{}{
+	"type": "array",
+	"items": map[string]interface{}{
+		"type": "array",
+		"items": []interface{}{
+			map[string]interface{}{"title": "City Name", "type": "string"},
+			map[string]interface{}{"title": "pop", "type": "integer"},
+			map[string]interface{}{"title": "avg_age", "type": []interface{}{"number", "null"}},
+			map[string]interface{}{"title": "in_usa", "type": "boolean"},
+			map[string]interface{}{"title": "tags"},
+		},
+	},
+}
+
+func TestColumns(t *testing.T) {
+	cols, err := Columns(&dataset.Structure{Format: "json", Schema: testSchema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Column{
+		{Name: "city_name", Type: ColumnString},
+		{Name: "pop", Type: ColumnInteger},
+		{Name: "avg_age", Type: ColumnNumber},
+		{Name: "in_usa", Type: ColumnBoolean},
+		{Name: "tags", Type: ColumnJSON},
+	}
+	if diff := cmp.Diff(expect, cols); diff != "" {
+		t.Errorf("columns mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := Columns(&dataset.Structure{Format: "json", Schema: map[string]interface{}{"type": "object"}}); err == nil {
+		t.Error("expected non-tabular schema to error")
+	}
+}
+
+type memLoader map[string]string
+
+func (l memLoader) LoadDataset(ctx context.Context, ref dsref.Ref, source string) (*dataset.Dataset, error) {
+	body, ok := l[ref.Path]
+	if !ok {
+		return nil, dsref.ErrRefNotFound
+	}
+	ds := &dataset.Dataset{
+		Peername:  ref.Username,
+		Name:      ref.Name,
+		Path:      ref.Path,
+		Structure: &dataset.Structure{Format: "json", Schema: testSchema},
+	}
+	ds.SetBodyFile(qfs.NewMemfileBytes("body.json", []byte(body)))
+	return ds, nil
+}
+
+type call struct {
+	Method string
+	Table  string
+	Rows   [][]interface{}
+}
+
+type fakeDriver struct {
+	calls []call
+	fail  int
+}
+
+func (d *fakeDriver) Replace(ctx context.Context, table string, cols []Column, rows [][]interface{}) error {
+	return d.record("replace", table, rows)
+}
+
+func (d *fakeDriver) Append(ctx context.Context, table string, cols []Column, rows [][]interface{}) error {
+	return d.record("append", table, rows)
+}
+
+func (d *fakeDriver) record(method, table string, rows [][]interface{}) error {
+	if d.fail > 0 {
+		d.fail--
+		return fmt.Errorf("warehouse unavailable")
+	}
+	d.calls = append(d.calls, call{method, table, rows})
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	prevDelay := RetryDelay
+	RetryDelay = 0
+	defer func() { RetryDelay = prevDelay }()
+
+	ctx := context.Background()
+	loader := memLoader{
+		"/mem/v1": `[["toronto",40000000,55.5,false,["a"]]]`,
+		"/mem/v2": `[["toronto",40000000,55.5,false,["a"]],["new york",8500000,44.4,true,null]]`,
+		"/mem/v3": `[["new york",8500000,44.4,true,null]]`,
+	}
+	store, err := NewStatusStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drv := &fakeDriver{}
+	tgt := &target{cfg: &config.Warehouse{Name: "pg"}, drv: drv}
+	svc := newService(loader, []*target{tgt}, store)
+
+	for _, path := range []string{"/mem/v1", "/mem/v2", "/mem/v3"} {
+		if _, err := svc.sync(ctx, tgt, dsref.Ref{Username: "peer", Name: "cities", Path: path}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	toronto := []interface{}{"toronto", int64(40000000), 55.5, false, `["a"]`}
+	newYork := []interface{}{"new york", int64(8500000), 44.4, true, nil}
+	expect := []call{
+		{"replace", "cities", [][]interface{}{toronto}},
+		{"append", "cities", [][]interface{}{newYork}},
+		{"replace", "cities", [][]interface{}{newYork}},
+	}
+	if diff := cmp.Diff(expect, drv.calls); diff != "" {
+		t.Errorf("driver calls mismatch (-want +got):\n%s", diff)
+	}
+
+	statuses := svc.Statuses("peer/cities")
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 statuses, got %d", len(statuses))
+	}
+	if st := statuses[1]; st.Path != "/mem/v2" || st.State != StateSynced || st.Mode != config.WarehouseModeAppend || st.Rows != 2 || st.Written != 1 {
+		t.Errorf("unexpected status for v2: %#v", st)
+	}
+
+	// failures are retried, then recorded
+	drv.fail = Attempts
+	if _, err := svc.sync(ctx, tgt, dsref.Ref{Username: "peer", Name: "cities", Path: "/mem/v1"}); err == nil {
+		t.Error("expected sync to fail")
+	}
+	st := svc.Statuses("peer/cities")[0]
+	if st.State != StateFailed || st.Attempts != Attempts || st.Error != "warehouse unavailable" {
+		t.Errorf("unexpected failed status: %#v", st)
+	}
+
+	drv.fail = 1
+	if _, err := svc.sync(ctx, tgt, dsref.Ref{Username: "peer", Name: "cities", Path: "/mem/v1"}); err != nil {
+		t.Errorf("expected retried sync to succeed, got: %s", err)
+	}
+}
+
+func TestStatusStorePersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "warehouse_status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "sync.json")
+
+	s, err := NewStatusStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Put(Status{Warehouse: "pg", Dataset: "peer/cities", Path: "/mem/v1", State: StateSynced, Rows: 2})
+
+	s, err = NewStatusStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := s.LastSynced("pg", "peer/cities", ""); last == nil || last.Rows != 2 {
+		t.Errorf("expected persisted status, got: %#v", last)
+	}
+	if last := s.LastSynced("pg", "peer/cities", "/mem/v1"); last != nil {
+		t.Errorf("expected LastSynced to ignore the version being synced")
+	}
+}
+
+func TestPostgresStatements(t *testing.T) {
+	cols := []Column{{Name: "name", Type: ColumnString}, {Name: "pop", Type: ColumnInteger}}
+	if got, expect := pgCreateTable("cities", cols, true), `CREATE TABLE IF NOT EXISTS "cities" ("name" TEXT, "pop" BIGINT)`; got != expect {
+		t.Errorf("create table mismatch.\nwant: %s\ngot:  %s", expect, got)
+	}
+
+	query, args := pgInsert("cities", cols, [][]interface{}{{"toronto", 1}, {"new york", 2}})
+	if expect := `INSERT INTO "cities" ("name", "pop") VALUES ($1, $2), ($3, $4)`; query != expect {
+		t.Errorf("insert mismatch.\nwant: %s\ngot:  %s", expect, query)
+	}
+	if diff := cmp.Diff([]interface{}{"toronto", 1, "new york", 2}, args); diff != "" {
+		t.Errorf("insert args mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBigQuery(t *testing.T) {
+	requests := []string{}
+	inserted := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("expected bearer token")
+		}
+		switch {
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPost && filepath.Base(r.URL.Path) == "insertAll":
+			body := struct {
+				Rows []map[string]interface{} `json:"rows"`
+			}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			inserted += len(body.Rows)
+			w.Write([]byte(`{}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer s.Close()
+
+	bq := newBigQuery(&config.Warehouse{Address: s.URL, Project: "proj", DatasetID: "qri", Token: "token"})
+	cols := []Column{{Name: "name", Type: ColumnString}}
+	if err := bq.Append(context.Background(), "cities", cols, [][]interface{}{{"toronto"}, {"new york"}}); err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{
+		"GET /projects/proj/datasets/qri/tables/cities",
+		"POST /projects/proj/datasets/qri/tables",
+		"POST /projects/proj/datasets/qri/tables/cities/insertAll",
+	}
+	if diff := cmp.Diff(expect, requests); diff != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", diff)
+	}
+	if inserted != 2 {
+		t.Errorf("expected 2 inserted rows, got %d", inserted)
+	}
+}